@@ -9,23 +9,145 @@ import (
 var (
 	Net  string = ""
 	Port string = ""
+	// 可信的反向代理（IP 或 CIDR），只有来自这些地址的请求才按 X-Forwarded-For 取客户端 IP
+	TrustedProxies []string
 
 	RdsDsn          string = ""
 	RdsMaxOpenConns int    = 0
 	RdsMaxIgleConns int    = 0
 
 	// API Key for authentication
-	APIKey = ""
+	APIKey  = ""
+	APIKeys []APIKeyConf
+	// 令牌、屏蔽聊天、偏好等用户接口是否也要求 API Key（默认要求，显式关闭后开放给客户端）
+	ProtectUserEndpoints bool = true
+	// 修改用户数据的自助接口是否要求该用户的私钥签名（默认要求），以及签名时间戳允许的偏差
+	UserSignatureEnabled bool   = true
+	UserSignatureMaxSkew string = ""
+
+	// HTTP API 限流（令牌桶：每个窗口补充 limit 个令牌，最多累积 burst 个）
+	RateLimitEnabled     bool   = false
+	RateLimitWindow      string = ""
+	RateLimitIPLimit     int    = 0
+	RateLimitIPBurst     int    = 0
+	RateLimitAPIKeyLimit int    = 0
+	RateLimitAPIKeyBurst int    = 0
+
+	// 配置文件保存后是否自动热加载（SIGHUP 和管理接口始终可用）
+	ConfigWatch bool = false
+
+	// 预发环境：注册模拟推送提供者（平台 mock）和测试数据接口，生产环境不要开启
+	StagingEnabled     bool   = false
+	StagingMockLatency string = ""
+
+	// HTTP 进件接口：不使用 Socket.IO 的上游通过 HMAC 签名的 HTTP 请求投递聊天消息
+	IngestEnabled bool   = false
+	IngestSecret  string = ""
+	IngestMaxSkew string = ""
+
+	// Field naming of /v2 responses (camel / snake)
+	APIV2FieldNaming string = ""
 
 	// Push Center Configuration
-	PushCenterEnabled bool   = false
-	PushCenterDBPath  string = ""
+	PushCenterEnabled  bool     = false
+	PushCenterDBPath   string   = ""
+	PushCenterKeyspace string   = ""
+	EnabledTypes       []string = nil
+	IdempotencyTTL     string   = ""
+	TokenCacheSize     int      = 0
+	TokenCacheTTL      string   = ""
+	WarmupEnabled      bool     = false
+	WarmupSize         int      = 0
+	WarmupSaveInterval string   = ""
+	IntakeEnabled      bool     = false
+	IntakeMaxAttempts  int      = 0
+
+	// Delivery Tracking Configuration
+	DeliveryEnabled       bool   = false
+	DeliveryRetention     string = ""
+	DeliveryReceiptDelay  string = ""
+	DeliveryCheckInterval string = ""
+
+	// Shutdown Configuration
+	ShutdownSourceTimeout  string = ""
+	ShutdownDrainTimeout   string = ""
+	ShutdownReceiptTimeout string = ""
+	ShutdownStageTimeout   string = ""
+
+	// Chat Ordering Configuration
+	OrderingEnabled   bool = false
+	OrderingQueues    int  = 0
+	OrderingQueueSize int  = 0
+
+	// Backpressure Configuration
+	BackpressureEnabled        bool = false
+	BackpressureHighWatermark  int  = 0
+	BackpressureLowWatermark   int  = 0
+	BackpressureSignalUpstream bool = false
+
+	// Push Ack Configuration
+	PushAckEnabled bool = false
+
+	// Stale Token GC Configuration
+	TokenGCEnabled         bool   = false
+	TokenGCStaleAfterDays  int    = 0
+	TokenGCDeleteAfterDays int    = 0
+	TokenGCInterval        string = ""
+
+	// Push Audit Log Configuration
+	AuditEnabled      bool   = false
+	AuditRetention    string = ""
+	AuditPerUserLimit int    = 0
+	AuditQueueSize    int    = 0
+
+	// Background Job Configuration
+	JobRetention string = ""
+
+	// Disk Monitor Configuration
+	DiskMonitorEnabled  bool    = false
+	DiskWarningPercent  float64 = 0
+	DiskCriticalPercent float64 = 0
+	DiskCheckInterval   string  = ""
+
+	// Storage Backend Configuration
+	StorageBackend        string = ""
+	StorageRedisAddr      string = ""
+	StorageRedisPassword  string = ""
+	StorageRedisDB        int    = 0
+	StorageRedisKeyPrefix string = ""
+	StorageRedisPinTTL    string = ""
+	StoragePinMaxAge      string = ""
+
+	// Notified-Pin Bloom Filter Configuration
+	PinFilterEnabled           bool    = false
+	PinFilterExpectedItems     int     = 0
+	PinFilterFalsePositiveRate float64 = 0
+	PinFilterRebuildInterval   string  = ""
+
+	// Multi-Instance PIN Dedup Configuration
+	DedupMode           string = ""
+	DedupInstanceID     string = ""
+	DedupClaimTTL       string = ""
+	DedupRedisAddr      string = ""
+	DedupRedisPassword  string = ""
+	DedupRedisDB        int    = 0
+	DedupRedisKeyPrefix string = ""
+
+	// Pebble Value Compression Configuration
+	CompressionEnabled     bool     = false
+	CompressionThreshold   int      = 0
+	CompressionCollections []string = nil
 
 	// Socket Client Configuration
 	SocketServerURL        string = ""
 	SocketExtraPushAuthKey string = ""
 	SocketPath             string = ""
 	SocketTimeout          int    = 0
+	SocketAuthTokenURL     string = ""
+	SocketAuthTokenSecret  string = ""
+	SocketAuthRefresh      int    = 0
+	SocketStaleTimeout     int    = 0
+	SocketServers          []SocketServerConf
 
 	// Push Service Configuration
 	PushDefaultProvider     string = ""
@@ -40,6 +162,7 @@ var (
 	PushEnableStats         bool   = false
 	PushStatsInterval       string = ""
 	PushHealthCheckInterval string = ""
+	PushDryRun              bool   = false
 
 	// Expo Provider Configuration
 	ExpoAccessToken     string = ""
@@ -51,8 +174,223 @@ var (
 	ExpoDefaultPriority string = ""
 	ExpoBatchSize       int    = 0
 	ExpoMaxConcurrency  int    = 0
+	ExpoBaseURL         string = ""
+	ExpoEgress          EgressConf
+
+	// Email Fallback Provider Configuration
+	EmailEnabled          bool   = false
+	EmailBackend          string = ""
+	EmailFrom             string = ""
+	EmailFromName         string = ""
+	EmailSubjectPrefix    string = ""
+	EmailTimeout          string = ""
+	EmailSMTPHost         string = ""
+	EmailSMTPPort         int    = 0
+	EmailSMTPUsername     string = ""
+	EmailSMTPPassword     string = ""
+	EmailSendGridAPIKey   string = ""
+	EmailSendGridEndpoint string = ""
+
+	// Desktop Provider Configuration (macOS via APNs, Windows via WNS)
+	MacOSEnabled    bool   = false
+	MacOSTeamID     string = ""
+	MacOSKeyID      string = ""
+	MacOSKeyFile    string = ""
+	MacOSBundleID   string = ""
+	MacOSProduction bool   = false
+	MacOSTimeout    string = ""
+	WNSEnabled      bool   = false
+	WNSPackageSID   string = ""
+	WNSClientSecret string = ""
+	WNSTimeout      string = ""
+	MacOSEgress     EgressConf
+	WNSEgress       EgressConf
+
+	// Push Fallback Configuration（通知优先级 -> 兜底提供者列表）
+	FallbackChains map[string][]string
+
+	// Scheduled Push Configuration
+	SchedulePollInterval string = ""
+	ScheduleBatchSize    int    = 0
+	ScheduleSendTimeout  string = ""
+
+	// Push Throttle Configuration
+	ThrottleEnabled        bool   = false
+	ThrottleBackend        string = ""
+	ThrottleLimit          int    = 0
+	ThrottleWindow         string = ""
+	ThrottleRedisAddr      string = ""
+	ThrottleRedisPassword  string = ""
+	ThrottleRedisDB        int    = 0
+	ThrottleRedisKeyPrefix string = ""
+
+	// Tenant Quota Configuration
+	QuotaEnabled         bool   = false
+	QuotaDefaultLimit    int64  = 0
+	QuotaOverQuotaAction string = ""
+	QuotaWarnPercents    []int  = nil
+
+	// QA Virtual Inbox Configuration
+	QAEnabled    bool     = false
+	QAMetaIDs    []string = nil
+	QAInboxLimit int      = 0
+
+	// Upstream Recipient Membership Verification Configuration
+	MembershipEnabled      bool     = false
+	MembershipEndpoint     string   = ""
+	MembershipAPIKey       string   = ""
+	MembershipTimeout      string   = ""
+	MembershipCacheTTL     string   = ""
+	MembershipMessageTypes []string = nil
+	MembershipFailClosed   bool     = false
+
+	// Reconnect Catch-up Configuration
+	CatchupEnabled     bool   = false
+	CatchupEndpoint    string = ""
+	CatchupAPIKey      string = ""
+	CatchupTimeout     string = ""
+	CatchupPageSize    int    = 0
+	CatchupMaxMessages int    = 0
+
+	// Message Preview & Translation Configuration
+	PreviewEnabled      bool   = false
+	HideEncrypted       bool   = false
+	CollapseMode        string = ""
+	CandyBagEnabled     bool   = false
+	CandyBagSound       string = ""
+	CandyBagChannelID   string = ""
+	CandyBagRoute       string = ""
+	CandyBagClaimWindow string = ""
+	TextMaxTitle        int    = 0
+	TextMaxBody         int    = 0
+	TextMaxName         int    = 0
+	TextMaxPreview      int    = 0
+	GroupingEnabled     bool   = false
+	SummaryEnabled      bool   = false
+	SummaryMinChats     int    = 0
+	SummaryWindow       string = ""
+	ActionsEnabled      bool   = false
+	TranslationEnabled  bool   = false
+	TranslationProvider string = ""
+	TranslationEndpoint string = ""
+	TranslationAPIKey   string = ""
+	TranslationTimeout  string = ""
+	TranslationCacheTTL string = ""
+
+	// Pebble Backup Configuration
+	BackupDir      string = ""
+	BackupSchedule bool   = false
+	BackupInterval string = ""
+	BackupKeep     int    = 0
+
+	// Deploy Handoff Configuration
+	HandoffEnabled      bool   = false
+	HandoffMode         string = ""
+	HandoffBackend      string = ""
+	HandoffRedisAddr    string = ""
+	HandoffRedisPass    string = ""
+	HandoffRedisDB      int    = 0
+	HandoffRedisKey     string = ""
+	HandoffDir          string = ""
+	HandoffInstanceID   string = ""
+	HandoffLeaseTTL     string = ""
+	HandoffPollInterval string = ""
+	HandoffBufferSize   int    = 0
+	HandoffBufferWindow string = ""
+
+	// Delivery Webhook Configuration
+	WebhookEnabled    bool   = false
+	WebhookTimeout    string = ""
+	WebhookMaxRetries int    = 0
+	WebhookBaseDelay  string = ""
+	WebhookQueueSize  int    = 0
+	WebhookWorkers    int    = 0
+
+	// Outbound Notification Sampling Configuration
+	SamplingEnabled      bool     = false
+	SamplingRate         float64  = 0
+	SamplingWebhookURL   string   = ""
+	SamplingSecret       string   = ""
+	SamplingTimeout      string   = ""
+	SamplingQueueSize    int      = 0
+	SamplingRedactFields []string = nil
+
+	// OpenTelemetry Tracing Configuration
+	TracingEnabled       bool              = false
+	TracingEndpoint      string            = ""
+	TracingURLPath       string            = ""
+	TracingInsecure      bool              = false
+	TracingHeaders       map[string]string = nil
+	TracingServiceName   string            = ""
+	TracingSampleRate    float64           = 0
+	TracingInjectPayload bool              = false
+
+	// gRPC API Configuration
+	GRPCEnabled      bool   = false
+	GRPCPort         string = ""
+	GRPCStreamBuffer int    = 0
+
+	// Admin Dashboard Configuration
+	AdminDashboardEnabled bool = false
+
+	// Notification Routing Configuration
+	RoutingRules []RoutingRuleConf
 )
 
+// APIKeyConf 具名 API Key，用于区分不同的调用方
+type APIKeyConf struct {
+	Name   string   `mapstructure:"name"`
+	Key    string   `mapstructure:"key"`
+	Scopes []string `mapstructure:"scopes"` // 权限范围 read / write / admin，为空时为 admin
+	Tenant string   `mapstructure:"tenant"` // 调用方所属租户，通过该 Key 发送的推送计入该租户的配额
+}
+
+// EgressConf 推送平台客户端的出站连接配置（代理和 TLS）
+type EgressConf struct {
+	ProxyURL           string `mapstructure:"proxy_url"` // http/https/socks5/socks5h 代理地址，为空时沿用环境变量，direct 表示直连
+	CAFile             string `mapstructure:"ca_file"`
+	ClientCertFile     string `mapstructure:"client_cert_file"`
+	ClientKeyFile      string `mapstructure:"client_key_file"`
+	InsecureSkipVerify bool   `mapstructure:"insecure_skip_verify"`
+}
+
+// readEgressConf 读取 key 下的出站连接配置
+func readEgressConf(key string) EgressConf {
+	var egress EgressConf
+	if err := viper.UnmarshalKey(key, &egress); err != nil {
+		panic(fmt.Errorf("Fatal error config %s: %s \n", key, err))
+	}
+	return egress
+}
+
+// SocketServerConf 单个上游 Socket.IO 服务器配置
+type SocketServerConf struct {
+	Name             string `mapstructure:"name"`
+	ServerURL        string `mapstructure:"server_url"`
+	ExtraPushAuthKey string `mapstructure:"extra_push_auth_key"`
+	Path             string `mapstructure:"path"`
+	Timeout          int    `mapstructure:"timeout"`
+
+	AuthTokenURL      string `mapstructure:"auth_token_url"`
+	AuthTokenSecret   string `mapstructure:"auth_token_secret"`
+	AuthRefreshBefore int    `mapstructure:"auth_refresh_before"`
+	StaleTimeout      int    `mapstructure:"stale_timeout"`
+}
+
+// RoutingRuleConf 通知路由规则配置
+type RoutingRuleConf struct {
+	Name          string            `mapstructure:"name"`
+	MessageTypes  []string          `mapstructure:"message_types"`
+	ChatInfoTypes []int64           `mapstructure:"chat_info_types"`
+	Mention       *bool             `mapstructure:"mention"`
+	DataFields    map[string]string `mapstructure:"data_fields"`
+	Priority      string            `mapstructure:"priority"`
+	Sound         string            `mapstructure:"sound"`
+	TTL           int               `mapstructure:"ttl"`
+	ChannelID     string            `mapstructure:"channel_id"`
+	Providers     []string          `mapstructure:"providers"`
+}
+
 func InitConfig(configPath string) {
 	if configPath == "" {
 		configPath = GetYaml()
@@ -60,12 +398,16 @@ func InitConfig(configPath string) {
 	// Set the file name of the configurations file
 	fmt.Printf("configPath:%s\n", configPath)
 	viper.SetConfigFile(configPath)
+	// 用户接口默认要求 API Key，未配置该项时不能静默开放
+	viper.SetDefault("protect_user_endpoints", true)
+	viper.SetDefault("user_signature.enabled", true)
 	if err := viper.ReadInConfig(); err != nil {
 		panic(fmt.Errorf("Fatal error config file: %s \n", err))
 	}
 
 	Net = viper.GetString("net")
 	Port = viper.GetString("port")
+	TrustedProxies = viper.GetStringSlice("trusted_proxies")
 
 	RdsDsn = viper.GetString("rds.dsn")
 	RdsMaxOpenConns = viper.GetInt("rds.max_open_conns")
@@ -73,16 +415,79 @@ func InitConfig(configPath string) {
 
 	// 读取 API Key 配置
 	APIKey = viper.GetString("api_key")
+	ProtectUserEndpoints = viper.GetBool("protect_user_endpoints")
+	UserSignatureEnabled = viper.GetBool("user_signature.enabled")
+	UserSignatureMaxSkew = viper.GetString("user_signature.max_skew")
+	APIV2FieldNaming = viper.GetString("api_v2.field_naming")
+	StagingEnabled = viper.GetBool("staging.enabled")
+	StagingMockLatency = viper.GetString("staging.mock_latency")
+	IngestEnabled = viper.GetBool("ingest.enabled")
+	IngestSecret = viper.GetString("ingest.secret")
+	IngestMaxSkew = viper.GetString("ingest.max_skew")
+	APIKeys = nil
+	if err := viper.UnmarshalKey("api_keys", &APIKeys); err != nil {
+		panic(fmt.Errorf("Fatal error config api_keys: %s \n", err))
+	}
 
 	// 读取推送中心配置
 	PushCenterEnabled = viper.GetBool("push_center.enabled")
 	PushCenterDBPath = viper.GetString("push_center.db_path")
+	PushCenterKeyspace = viper.GetString("push_center.keyspace")
+	EnabledTypes = viper.GetStringSlice("push_center.enabled_types")
+	IdempotencyTTL = viper.GetString("push_center.idempotency_ttl")
+	TokenCacheSize = viper.GetInt("push_center.token_cache_size")
+	TokenCacheTTL = viper.GetString("push_center.token_cache_ttl")
+	WarmupEnabled = viper.GetBool("push_center.warmup.enabled")
+	WarmupSize = viper.GetInt("push_center.warmup.size")
+	WarmupSaveInterval = viper.GetString("push_center.warmup.save_interval")
+	IntakeEnabled = viper.GetBool("push_center.intake.enabled")
+	IntakeMaxAttempts = viper.GetInt("push_center.intake.max_attempts")
+	DeliveryEnabled = viper.GetBool("push_center.delivery.enabled")
+	DeliveryRetention = viper.GetString("push_center.delivery.retention")
+	DeliveryReceiptDelay = viper.GetString("push_center.delivery.receipt_delay")
+	DeliveryCheckInterval = viper.GetString("push_center.delivery.check_interval")
+	ShutdownSourceTimeout = viper.GetString("push_center.shutdown.source_timeout")
+	ShutdownDrainTimeout = viper.GetString("push_center.shutdown.drain_timeout")
+	ShutdownReceiptTimeout = viper.GetString("push_center.shutdown.receipt_timeout")
+	ShutdownStageTimeout = viper.GetString("push_center.shutdown.stage_timeout")
+	OrderingEnabled = viper.GetBool("push_center.ordering.enabled")
+	OrderingQueues = viper.GetInt("push_center.ordering.queues")
+	OrderingQueueSize = viper.GetInt("push_center.ordering.queue_size")
+	BackpressureEnabled = viper.GetBool("push_center.backpressure.enabled")
+	BackpressureHighWatermark = viper.GetInt("push_center.backpressure.high_watermark")
+	BackpressureLowWatermark = viper.GetInt("push_center.backpressure.low_watermark")
+	BackpressureSignalUpstream = viper.GetBool("push_center.backpressure.signal_upstream")
+	PushAckEnabled = viper.GetBool("push_center.ack.enabled")
+	TokenGCEnabled = viper.GetBool("push_center.token_gc.enabled")
+	TokenGCStaleAfterDays = viper.GetInt("push_center.token_gc.stale_after_days")
+	TokenGCDeleteAfterDays = viper.GetInt("push_center.token_gc.delete_after_days")
+	TokenGCInterval = viper.GetString("push_center.token_gc.interval")
+	AuditEnabled = viper.GetBool("push_center.audit.enabled")
+	AuditRetention = viper.GetString("push_center.audit.retention")
+	AuditPerUserLimit = viper.GetInt("push_center.audit.per_user_limit")
+	AuditQueueSize = viper.GetInt("push_center.audit.queue_size")
+	JobRetention = viper.GetString("push_center.jobs.retention")
+	DiskMonitorEnabled = viper.GetBool("push_center.disk_monitor.enabled")
+	DiskWarningPercent = viper.GetFloat64("push_center.disk_monitor.warning_percent")
+	DiskCriticalPercent = viper.GetFloat64("push_center.disk_monitor.critical_percent")
+	DiskCheckInterval = viper.GetString("push_center.disk_monitor.check_interval")
+	CompressionEnabled = viper.GetBool("push_center.compression.enabled")
+	CompressionThreshold = viper.GetInt("push_center.compression.threshold")
+	CompressionCollections = viper.GetStringSlice("push_center.compression.collections")
 
 	// 读取 Socket 客户端配置
 	SocketServerURL = viper.GetString("socket_client.server_url")
 	SocketExtraPushAuthKey = viper.GetString("socket_client.extra_push_auth_key")
 	SocketPath = viper.GetString("socket_client.path")
 	SocketTimeout = viper.GetInt("socket_client.timeout")
+	SocketAuthTokenURL = viper.GetString("socket_client.auth_token_url")
+	SocketAuthTokenSecret = viper.GetString("socket_client.auth_token_secret")
+	SocketAuthRefresh = viper.GetInt("socket_client.auth_refresh_before")
+	SocketStaleTimeout = viper.GetInt("socket_client.stale_timeout")
+	SocketServers = nil
+	if err := viper.UnmarshalKey("socket_client.servers", &SocketServers); err != nil {
+		panic(fmt.Errorf("Fatal error config socket_client.servers: %s \n", err))
+	}
 
 	// 读取推送服务配置
 	PushDefaultProvider = viper.GetString("push.default_provider")
@@ -97,6 +502,203 @@ func InitConfig(configPath string) {
 	PushEnableStats = viper.GetBool("push.enable_stats")
 	PushStatsInterval = viper.GetString("push.stats_interval")
 	PushHealthCheckInterval = viper.GetString("push.health_check_interval")
+	PushDryRun = viper.GetBool("push.dry_run")
+
+	// 读取邮件兜底提供者配置
+	EmailEnabled = viper.GetBool("push.providers.email.enabled")
+	EmailBackend = viper.GetString("push.providers.email.backend")
+	EmailFrom = viper.GetString("push.providers.email.from")
+	EmailFromName = viper.GetString("push.providers.email.from_name")
+	EmailSubjectPrefix = viper.GetString("push.providers.email.subject_prefix")
+	EmailTimeout = viper.GetString("push.providers.email.timeout")
+	EmailSMTPHost = viper.GetString("push.providers.email.smtp.host")
+	EmailSMTPPort = viper.GetInt("push.providers.email.smtp.port")
+	EmailSMTPUsername = viper.GetString("push.providers.email.smtp.username")
+	EmailSMTPPassword = viper.GetString("push.providers.email.smtp.password")
+	EmailSendGridAPIKey = viper.GetString("push.providers.email.sendgrid.api_key")
+	EmailSendGridEndpoint = viper.GetString("push.providers.email.sendgrid.endpoint")
+
+	// 读取桌面客户端推送提供者配置
+	MacOSEnabled = viper.GetBool("push.providers.macos.enabled")
+	MacOSTeamID = viper.GetString("push.providers.macos.team_id")
+	MacOSKeyID = viper.GetString("push.providers.macos.key_id")
+	MacOSKeyFile = viper.GetString("push.providers.macos.key_file")
+	MacOSBundleID = viper.GetString("push.providers.macos.bundle_id")
+	MacOSProduction = viper.GetBool("push.providers.macos.production")
+	MacOSTimeout = viper.GetString("push.providers.macos.timeout")
+	WNSEnabled = viper.GetBool("push.providers.windows.enabled")
+	WNSPackageSID = viper.GetString("push.providers.windows.package_sid")
+	WNSClientSecret = viper.GetString("push.providers.windows.client_secret")
+	WNSTimeout = viper.GetString("push.providers.windows.timeout")
+	MacOSEgress = readEgressConf("push.providers.macos.egress")
+	WNSEgress = readEgressConf("push.providers.windows.egress")
+
+	// 读取兜底链配置
+	FallbackChains = viper.GetStringMapStringSlice("push.fallback.chains")
+
+	// 读取存储后端配置
+	StorageBackend = viper.GetString("storage.backend")
+	StorageRedisAddr = viper.GetString("storage.redis.addr")
+	StorageRedisPassword = viper.GetString("storage.redis.password")
+	StorageRedisDB = viper.GetInt("storage.redis.db")
+	StorageRedisKeyPrefix = viper.GetString("storage.redis.key_prefix")
+	StorageRedisPinTTL = viper.GetString("storage.redis.pin_ttl")
+	StoragePinMaxAge = viper.GetString("storage.pin_max_age")
+	PinFilterEnabled = viper.GetBool("storage.pin_filter.enabled")
+	PinFilterExpectedItems = viper.GetInt("storage.pin_filter.expected_items")
+	PinFilterFalsePositiveRate = viper.GetFloat64("storage.pin_filter.false_positive_rate")
+	PinFilterRebuildInterval = viper.GetString("storage.pin_filter.rebuild_interval")
+
+	// 读取多实例 PIN 去重配置
+	DedupMode = viper.GetString("dedup.mode")
+	DedupInstanceID = viper.GetString("dedup.instance_id")
+	DedupClaimTTL = viper.GetString("dedup.claim_ttl")
+	DedupRedisAddr = viper.GetString("dedup.redis.addr")
+	DedupRedisPassword = viper.GetString("dedup.redis.password")
+	DedupRedisDB = viper.GetInt("dedup.redis.db")
+	DedupRedisKeyPrefix = viper.GetString("dedup.redis.key_prefix")
+
+	// 读取定时推送配置
+	SchedulePollInterval = viper.GetString("schedule.poll_interval")
+	ScheduleBatchSize = viper.GetInt("schedule.batch_size")
+	ScheduleSendTimeout = viper.GetString("schedule.send_timeout")
+
+	// 读取部署交接配置
+	ThrottleEnabled = viper.GetBool("throttle.enabled")
+	ThrottleBackend = viper.GetString("throttle.backend")
+	ThrottleLimit = viper.GetInt("throttle.limit")
+	ThrottleWindow = viper.GetString("throttle.window")
+	ThrottleRedisAddr = viper.GetString("throttle.redis.addr")
+	ThrottleRedisPassword = viper.GetString("throttle.redis.password")
+	ThrottleRedisDB = viper.GetInt("throttle.redis.db")
+	ThrottleRedisKeyPrefix = viper.GetString("throttle.redis.key_prefix")
+
+	// 读取租户配额配置
+	QuotaEnabled = viper.GetBool("quota.enabled")
+	QuotaDefaultLimit = viper.GetInt64("quota.default_limit")
+	QuotaOverQuotaAction = viper.GetString("quota.over_quota_action")
+	QuotaWarnPercents = viper.GetIntSlice("quota.warn_percents")
+
+	QAEnabled = viper.GetBool("qa.enabled")
+	QAMetaIDs = viper.GetStringSlice("qa.meta_ids")
+	QAInboxLimit = viper.GetInt("qa.inbox_limit")
+
+	// 读取上游接收用户校验配置
+	MembershipEnabled = viper.GetBool("membership.enabled")
+	MembershipEndpoint = viper.GetString("membership.endpoint")
+	MembershipAPIKey = viper.GetString("membership.api_key")
+	MembershipTimeout = viper.GetString("membership.timeout")
+	MembershipCacheTTL = viper.GetString("membership.cache_ttl")
+	MembershipMessageTypes = viper.GetStringSlice("membership.message_types")
+	MembershipFailClosed = viper.GetBool("membership.fail_closed")
+
+	// 读取断线补拉配置
+	CatchupEnabled = viper.GetBool("catchup.enabled")
+	CatchupEndpoint = viper.GetString("catchup.endpoint")
+	CatchupAPIKey = viper.GetString("catchup.api_key")
+	CatchupTimeout = viper.GetString("catchup.timeout")
+	CatchupPageSize = viper.GetInt("catchup.page_size")
+	CatchupMaxMessages = viper.GetInt("catchup.max_messages")
+
+	// 读取消息预览与翻译配置
+	PreviewEnabled = viper.GetBool("notification.preview_enabled")
+	HideEncrypted = viper.GetBool("notification.hide_encrypted")
+	CollapseMode = viper.GetString("notification.collapse_mode")
+	CandyBagEnabled = viper.GetBool("notification.candy_bag.enabled")
+	CandyBagSound = viper.GetString("notification.candy_bag.sound")
+	CandyBagChannelID = viper.GetString("notification.candy_bag.channel_id")
+	CandyBagRoute = viper.GetString("notification.candy_bag.route")
+	CandyBagClaimWindow = viper.GetString("notification.candy_bag.claim_window")
+	TextMaxTitle = viper.GetInt("notification.text_limits.max_title")
+	TextMaxBody = viper.GetInt("notification.text_limits.max_body")
+	TextMaxName = viper.GetInt("notification.text_limits.max_name")
+	TextMaxPreview = viper.GetInt("notification.text_limits.max_preview")
+	GroupingEnabled = viper.GetBool("notification.grouping.enabled")
+	SummaryEnabled = viper.GetBool("notification.grouping.summary.enabled")
+	SummaryMinChats = viper.GetInt("notification.grouping.summary.min_chats")
+	SummaryWindow = viper.GetString("notification.grouping.summary.window")
+	ActionsEnabled = viper.GetBool("notification.actions.enabled")
+	TranslationEnabled = viper.GetBool("translation.enabled")
+	TranslationProvider = viper.GetString("translation.provider")
+	TranslationEndpoint = viper.GetString("translation.endpoint")
+	TranslationAPIKey = viper.GetString("translation.api_key")
+	TranslationTimeout = viper.GetString("translation.timeout")
+	TranslationCacheTTL = viper.GetString("translation.cache_ttl")
+
+	// 读取 Pebble 备份配置
+	BackupDir = viper.GetString("backup.dir")
+	BackupSchedule = viper.GetBool("backup.schedule")
+	BackupInterval = viper.GetString("backup.interval")
+	BackupKeep = viper.GetInt("backup.keep")
+
+	HandoffEnabled = viper.GetBool("handoff.enabled")
+	HandoffMode = viper.GetString("handoff.mode")
+	HandoffBackend = viper.GetString("handoff.backend")
+	HandoffRedisAddr = viper.GetString("handoff.redis.addr")
+	HandoffRedisPass = viper.GetString("handoff.redis.password")
+	HandoffRedisDB = viper.GetInt("handoff.redis.db")
+	HandoffRedisKey = viper.GetString("handoff.redis.key")
+	HandoffDir = viper.GetString("handoff.dir")
+	HandoffInstanceID = viper.GetString("handoff.instance_id")
+	HandoffLeaseTTL = viper.GetString("handoff.lease_ttl")
+	HandoffPollInterval = viper.GetString("handoff.poll_interval")
+	HandoffBufferSize = viper.GetInt("handoff.buffer_size")
+	HandoffBufferWindow = viper.GetString("handoff.buffer_window")
+
+	// 读取租户投递 Webhook 配置
+	WebhookEnabled = viper.GetBool("webhook.enabled")
+	WebhookTimeout = viper.GetString("webhook.timeout")
+	WebhookMaxRetries = viper.GetInt("webhook.max_retries")
+	WebhookBaseDelay = viper.GetString("webhook.base_delay")
+	WebhookQueueSize = viper.GetInt("webhook.queue_size")
+	WebhookWorkers = viper.GetInt("webhook.workers")
+
+	// 读取外发通知抽样配置
+	SamplingEnabled = viper.GetBool("sampling.enabled")
+	SamplingRate = viper.GetFloat64("sampling.rate")
+	SamplingWebhookURL = viper.GetString("sampling.webhook_url")
+	SamplingSecret = viper.GetString("sampling.secret")
+	SamplingTimeout = viper.GetString("sampling.timeout")
+	SamplingQueueSize = viper.GetInt("sampling.queue_size")
+	SamplingRedactFields = viper.GetStringSlice("sampling.redact_fields")
+
+	// 读取链路追踪配置
+	TracingEnabled = viper.GetBool("tracing.enabled")
+	TracingEndpoint = viper.GetString("tracing.endpoint")
+	TracingURLPath = viper.GetString("tracing.url_path")
+	TracingInsecure = viper.GetBool("tracing.insecure")
+	TracingHeaders = viper.GetStringMapString("tracing.headers")
+	TracingServiceName = viper.GetString("tracing.service_name")
+	TracingSampleRate = viper.GetFloat64("tracing.sample_rate")
+	TracingInjectPayload = viper.GetBool("tracing.inject_payload")
+
+	// 读取 gRPC 接口配置
+	GRPCEnabled = viper.GetBool("grpc.enabled")
+	GRPCPort = viper.GetString("grpc.port")
+	GRPCStreamBuffer = viper.GetInt("grpc.stream_buffer")
+
+	// 读取管理看板配置
+	AdminDashboardEnabled = viper.GetBool("admin_dashboard.enabled")
+
+	// 读取通知路由规则
+	RoutingRules = nil
+	if err := viper.UnmarshalKey("routing.rules", &RoutingRules); err != nil {
+		panic(fmt.Errorf("Fatal error config routing.rules: %s \n", err))
+	}
+
+	ConfigWatch = viper.GetBool("config_reload.watch")
+	loadReloadableConfig()
+	appliedSettings = currentSettings()
+}
+
+// loadReloadableConfig 读取可热加载的配置（HTTP 限流、Expo 提供者），启动和热加载时调用
+func loadReloadableConfig() {
+	RateLimitEnabled = viper.GetBool("rate_limit.enabled")
+	RateLimitWindow = viper.GetString("rate_limit.window")
+	RateLimitIPLimit = viper.GetInt("rate_limit.ip.limit")
+	RateLimitIPBurst = viper.GetInt("rate_limit.ip.burst")
+	RateLimitAPIKeyLimit = viper.GetInt("rate_limit.api_key.limit")
+	RateLimitAPIKeyBurst = viper.GetInt("rate_limit.api_key.burst")
 
 	// 读取 Expo 提供者配置
 	ExpoAccessToken = viper.GetString("push.providers.expo.access_token")
@@ -108,4 +710,6 @@ func InitConfig(configPath string) {
 	ExpoDefaultPriority = viper.GetString("push.providers.expo.default_priority")
 	ExpoBatchSize = viper.GetInt("push.providers.expo.batch_size")
 	ExpoMaxConcurrency = viper.GetInt("push.providers.expo.max_concurrency")
+	ExpoBaseURL = viper.GetString("push.providers.expo.base_url")
+	ExpoEgress = readEgressConf("push.providers.expo.egress")
 }
@@ -0,0 +1,137 @@
+package conf
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+)
+
+// reloadablePrefixes 可热加载的配置项前缀，其余配置修改后需要重启才能生效
+var reloadablePrefixes = []string{
+	"rate_limit.",
+	"push.providers.expo.",
+}
+
+// ReloadResult 配置热加载结果
+type ReloadResult struct {
+	Applied         []string `json:"applied"`         // 已生效的配置项
+	RestartRequired []string `json:"restartRequired"` // 已修改但需要重启才能生效的配置项
+}
+
+var (
+	reloadMu sync.Mutex
+	// reloadHooks 热加载后依次调用的回调，用于将新配置应用到运行中的组件
+	reloadHooks []func()
+	// appliedSettings 最近一次加载的配置（扁平化的键值），用于找出本次修改的配置项
+	appliedSettings map[string]string
+)
+
+// OnReload 注册配置热加载后的回调（仅在可热加载的配置有变化时调用）
+func OnReload(hook func()) {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+
+	reloadHooks = append(reloadHooks, hook)
+}
+
+// ReloadConfig 重新读取配置文件，更新可热加载的配置并调用 OnReload 注册的回调
+func ReloadConfig() (*ReloadResult, error) {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+
+	if err := viper.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("读取配置文件失败: %w", err)
+	}
+	return applyReloadedConfig(), nil
+}
+
+// WatchConfig 监听配置文件，保存后自动热加载
+func WatchConfig() {
+	viper.OnConfigChange(func(event fsnotify.Event) {
+		reloadMu.Lock()
+		defer reloadMu.Unlock()
+
+		// viper 已在回调前重新读取了配置文件
+		applyReloadedConfig()
+	})
+	viper.WatchConfig()
+	log.Printf("👀 已开启配置文件监听: %s", viper.ConfigFileUsed())
+}
+
+// applyReloadedConfig 对比上次加载的配置，应用可热加载的修改（调用方需持有 reloadMu）
+func applyReloadedConfig() *ReloadResult {
+	settings := currentSettings()
+	result := &ReloadResult{Applied: []string{}, RestartRequired: []string{}}
+	for _, key := range changedKeys(appliedSettings, settings) {
+		if isReloadableKey(key) {
+			result.Applied = append(result.Applied, key)
+		} else {
+			result.RestartRequired = append(result.RestartRequired, key)
+		}
+	}
+
+	// 需要重启的配置保持旧值，下次对比时仍会提示
+	for _, key := range result.RestartRequired {
+		if value, ok := appliedSettings[key]; ok {
+			settings[key] = value
+		} else {
+			delete(settings, key)
+		}
+	}
+	appliedSettings = settings
+
+	if len(result.Applied) > 0 {
+		loadReloadableConfig()
+		for _, hook := range reloadHooks {
+			hook()
+		}
+		log.Printf("🔄 配置已热加载: %v", result.Applied)
+	} else {
+		log.Printf("🔄 配置热加载：没有可热加载的配置发生变化")
+	}
+	if len(result.RestartRequired) > 0 {
+		log.Printf("⚠️ 以下配置需要重启才能生效: %v", result.RestartRequired)
+	}
+	return result
+}
+
+// currentSettings 将 viper 中的配置扁平化为 键 → 值 的字符串形式
+func currentSettings() map[string]string {
+	settings := make(map[string]string)
+	for _, key := range viper.AllKeys() {
+		settings[key] = fmt.Sprint(viper.Get(key))
+	}
+	return settings
+}
+
+// changedKeys 返回新增、删除或值发生变化的配置项，按名称排序
+func changedKeys(before, after map[string]string) []string {
+	var keys []string
+	for key, value := range after {
+		if old, ok := before[key]; !ok || old != value {
+			keys = append(keys, key)
+		}
+	}
+	for key := range before {
+		if _, ok := after[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// isReloadableKey 配置项是否可热加载
+func isReloadableKey(key string) bool {
+	for _, prefix := range reloadablePrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+package conf
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestReloadConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "conf.yaml")
+	write := func(content string) {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("write config: %v", err)
+		}
+	}
+
+	write(`
+port: "8080"
+rate_limit:
+  enabled: false
+push:
+  providers:
+    expo:
+      batch_size: 100
+`)
+	InitConfig(path)
+	hookCalls := 0
+	OnReload(func() { hookCalls++ })
+	t.Cleanup(func() {
+		reloadHooks = nil
+		RateLimitEnabled = false
+		ExpoBatchSize, ExpoTimeout = 0, ""
+	})
+
+	// 未修改时不调用回调
+	result, err := ReloadConfig()
+	if err != nil {
+		t.Fatalf("ReloadConfig: %v", err)
+	}
+	if len(result.Applied) != 0 || len(result.RestartRequired) != 0 || hookCalls != 0 {
+		t.Fatalf("unchanged reload = %+v, hook calls = %d", result, hookCalls)
+	}
+
+	write(`
+port: "9090"
+rate_limit:
+  enabled: true
+push:
+  providers:
+    expo:
+      batch_size: 50
+      timeout: 10s
+`)
+	result, err = ReloadConfig()
+	if err != nil {
+		t.Fatalf("ReloadConfig: %v", err)
+	}
+	wantApplied := []string{"push.providers.expo.batch_size", "push.providers.expo.timeout", "rate_limit.enabled"}
+	if !slices.Equal(result.Applied, wantApplied) {
+		t.Errorf("Applied = %v, want %v", result.Applied, wantApplied)
+	}
+	if !slices.Equal(result.RestartRequired, []string{"port"}) {
+		t.Errorf("RestartRequired = %v, want [port]", result.RestartRequired)
+	}
+	if hookCalls != 1 {
+		t.Errorf("hook calls = %d, want 1", hookCalls)
+	}
+	if !RateLimitEnabled || ExpoBatchSize != 50 || ExpoTimeout != "10s" {
+		t.Errorf("reloaded values: rate limit = %v, batch size = %d, timeout = %q", RateLimitEnabled, ExpoBatchSize, ExpoTimeout)
+	}
+	// 需要重启的配置保持启动时的值
+	if Port != "8080" {
+		t.Errorf("Port = %q, want unchanged 8080", Port)
+	}
+
+	// 需要重启的修改持续提示，已生效的修改不再重复应用
+	result, err = ReloadConfig()
+	if err != nil {
+		t.Fatalf("ReloadConfig: %v", err)
+	}
+	if len(result.Applied) != 0 || !slices.Equal(result.RestartRequired, []string{"port"}) || hookCalls != 1 {
+		t.Errorf("repeated reload = %+v, hook calls = %d", result, hookCalls)
+	}
+}
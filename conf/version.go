@@ -0,0 +1,4 @@
+package conf
+
+// Version 服务版本，构建时通过 -ldflags "-X push-base-service/conf.Version=v1.2.3" 注入
+var Version = "dev"
@@ -0,0 +1,888 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"push-base-service/conf"
+	"push-base-service/controller/auth"
+	"push-base-service/controller/request"
+	"push-base-service/controller/respond"
+	"push-base-service/models"
+	"push-base-service/service/job_service"
+	"push-base-service/service/pebble_service"
+	pushcenter "push-base-service/service/push_center"
+	"push-base-service/service/push_service"
+	"push-base-service/service/socket_client_service"
+	"push-base-service/service/storage_service"
+	"push-base-service/service/webhook_service"
+	"push-base-service/tool"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetTenantWebhook godoc
+// @Summary 设置租户投递事件 Webhook
+// @Description 为指定租户注册投递事件回调地址，推送成功/失败时会以 POST 方式回调该地址。设置 secret 后回调会携带 X-Webhook-Signature 签名头（sha256=HMAC-SHA256(timestamp + "." + body)）。
+// @Tags Admin API
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body request.SetTenantWebhookReq true "请求参数（tenantId、url，可选 secret、enabled）"
+// @Success 200 {object} respond.Response "成功响应"
+// @Failure 400 {object} respond.Response "参数错误"
+// @Failure 401 {object} respond.Response "认证失败"
+// @Failure 500 {object} respond.Response "服务器内部错误"
+// @Router /v1/admin/set_tenant_webhook [post]
+func SetTenantWebhook(c *gin.Context) {
+	var (
+		t            int64 = tool.MakeTimestamp()
+		requestModel *request.SetTenantWebhookReq
+	)
+
+	if c.ShouldBindJSON(&requestModel) == nil {
+		if !strings.HasPrefix(requestModel.URL, "http://") && !strings.HasPrefix(requestModel.URL, "https://") {
+			respond.JSONP(c, http.StatusOK, respond.RespErr(errors.New("url 必须以 http:// 或 https:// 开头"), tool.MakeTimestamp()-t, respond.HttpsCodeError))
+			return
+		}
+
+		enabled := true
+		if requestModel.Enabled != nil {
+			enabled = *requestModel.Enabled
+		}
+
+		webhook := &models.TenantWebhook{
+			TenantID: requestModel.TenantID,
+			URL:      requestModel.URL,
+			Secret:   requestModel.Secret,
+			Enabled:  enabled,
+		}
+		if err := pebble_service.SaveTenantWebhook(webhook); err != nil {
+			respond.JSONP(c, http.StatusOK, respond.RespErr(err, tool.MakeTimestamp()-t, respond.HttpsCodeError))
+			return
+		}
+
+		responseData := map[string]interface{}{
+			"success": true,
+			"message": "租户 Webhook 设置成功",
+		}
+
+		respond.JSONP(c, http.StatusOK, respond.RespSuccess(responseData, tool.MakeTimestamp()-t))
+		return
+	}
+
+	respond.JSONP(c, http.StatusInternalServerError, respond.RespErr(errors.New("参数错误"), tool.MakeTimestamp()-t, respond.HttpsCodeError))
+}
+
+// GetTenantWebhooks godoc
+// @Summary 获取租户投递事件 Webhook 列表
+// @Description 获取所有已注册的租户 Webhook 配置（secret 已脱敏）
+// @Tags Admin API
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} respond.Response{data=[]models.TenantWebhook} "成功响应"
+// @Failure 401 {object} respond.Response "认证失败"
+// @Failure 500 {object} respond.Response "服务器内部错误"
+// @Router /v1/admin/get_tenant_webhooks [get]
+func GetTenantWebhooks(c *gin.Context) {
+	var t int64 = tool.MakeTimestamp()
+
+	webhooks, err := pebble_service.ListTenantWebhooks()
+	if err != nil {
+		respond.JSONP(c, http.StatusOK, respond.RespErr(err, tool.MakeTimestamp()-t, respond.HttpsCodeError))
+		return
+	}
+
+	// 返回前对 secret 脱敏
+	for _, webhook := range webhooks {
+		if webhook.Secret != "" {
+			webhook.Secret = "******"
+		}
+	}
+
+	respond.JSONP(c, http.StatusOK, respond.RespSuccess(webhooks, tool.MakeTimestamp()-t))
+}
+
+// RemoveTenantWebhook godoc
+// @Summary 移除租户投递事件 Webhook
+// @Description 删除指定租户的 Webhook 配置，删除后不再回调该租户的投递事件
+// @Tags Admin API
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body request.RemoveTenantWebhookReq true "请求参数（tenantId）"
+// @Success 200 {object} respond.Response "成功响应"
+// @Failure 400 {object} respond.Response "参数错误"
+// @Failure 401 {object} respond.Response "认证失败"
+// @Failure 500 {object} respond.Response "服务器内部错误"
+// @Router /v1/admin/remove_tenant_webhook [post]
+func RemoveTenantWebhook(c *gin.Context) {
+	var (
+		t            int64 = tool.MakeTimestamp()
+		requestModel *request.RemoveTenantWebhookReq
+	)
+
+	if c.ShouldBindJSON(&requestModel) == nil {
+		if err := pebble_service.DeleteTenantWebhook(requestModel.TenantID); err != nil {
+			respond.JSONP(c, http.StatusOK, respond.RespErr(err, tool.MakeTimestamp()-t, respond.HttpsCodeError))
+			return
+		}
+
+		responseData := map[string]interface{}{
+			"success": true,
+			"message": "租户 Webhook 移除成功",
+		}
+
+		respond.JSONP(c, http.StatusOK, respond.RespSuccess(responseData, tool.MakeTimestamp()-t))
+		return
+	}
+
+	respond.JSONP(c, http.StatusInternalServerError, respond.RespErr(errors.New("参数错误"), tool.MakeTimestamp()-t, respond.HttpsCodeError))
+}
+
+// SetUserTenant godoc
+// @Summary 设置用户所属租户
+// @Description 将用户归入指定租户，该用户的投递事件回调到租户 Webhook。tenantId 为空时将用户移出租户。租户归属只能通过此管理接口设置，登记令牌接口不接受 tenantId。
+// @Tags Admin API
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body request.SetUserTenantReq true "请求参数（metaId、tenantId）"
+// @Success 200 {object} respond.Response "成功响应"
+// @Failure 400 {object} respond.Response "参数错误"
+// @Failure 401 {object} respond.Response "认证失败"
+// @Failure 500 {object} respond.Response "服务器内部错误"
+// @Router /v1/admin/set_user_tenant [post]
+func SetUserTenant(c *gin.Context) {
+	var (
+		t            int64 = tool.MakeTimestamp()
+		requestModel *request.SetUserTenantReq
+	)
+
+	if err := c.ShouldBindJSON(&requestModel); err != nil {
+		respond.Fail(c, respond.BindError(err), tool.MakeTimestamp()-t)
+		return
+	}
+
+	if err := storage_service.SetUserTenant(requestModel.MetaID, requestModel.TenantID); err != nil {
+		respond.Fail(c, err, tool.MakeTimestamp()-t)
+		return
+	}
+
+	responseData := map[string]interface{}{
+		"success": true,
+		"message": "用户租户设置成功",
+	}
+
+	respond.JSONP(c, http.StatusOK, respond.RespSuccess(responseData, tool.MakeTimestamp()-t))
+}
+
+// SetTenantQuota godoc
+// @Summary 设置租户月度推送配额
+// @Description 设置指定租户每月的推送上限（按设备投递计数，UTC 自然月），0 表示不限制。未单独设置的租户使用配置文件中的默认上限。超出配额后按配置拒绝或降级发送。
+// @Tags Admin API
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body request.SetTenantQuotaReq true "请求参数（tenantId、monthlyLimit）"
+// @Success 200 {object} respond.Response{data=models.TenantQuota} "成功响应"
+// @Failure 400 {object} respond.Response "参数错误"
+// @Failure 401 {object} respond.Response "认证失败"
+// @Failure 500 {object} respond.Response "服务器内部错误"
+// @Router /v1/admin/set_tenant_quota [post]
+func SetTenantQuota(c *gin.Context) {
+	var (
+		t            int64 = tool.MakeTimestamp()
+		requestModel *request.SetTenantQuotaReq
+	)
+
+	if c.ShouldBindJSON(&requestModel) == nil {
+		quota, err := pebble_service.SaveTenantQuota(requestModel.TenantID, requestModel.MonthlyLimit)
+		if err != nil {
+			respond.JSONP(c, http.StatusOK, respond.RespErr(err, tool.MakeTimestamp()-t, respond.HttpsCodeError))
+			return
+		}
+
+		respond.JSONP(c, http.StatusOK, respond.RespSuccess(quota, tool.MakeTimestamp()-t))
+		return
+	}
+
+	respond.JSONP(c, http.StatusInternalServerError, respond.RespErr(errors.New("参数错误"), tool.MakeTimestamp()-t, respond.HttpsCodeError))
+}
+
+// GetTenantUsage godoc
+// @Summary 获取租户月度推送用量
+// @Description 获取租户某月的推送用量（已发送、降级、拒绝数量以及配额使用百分比）。不传 tenantId 时返回该月所有有用量的租户。需要在配置中启用租户配额。
+// @Tags Admin API
+// @Produce json
+// @Security ApiKeyAuth
+// @Param tenantId query string false "租户ID（不传则返回所有租户）"
+// @Param month query string false "月份（UTC，格式 2006-01，默认当月）"
+// @Success 200 {object} respond.Response{data=[]models.TenantUsage} "成功响应"
+// @Failure 401 {object} respond.Response "认证失败"
+// @Failure 500 {object} respond.Response "服务器内部错误"
+// @Router /v1/admin/get_tenant_usage [get]
+func GetTenantUsage(c *gin.Context) {
+	var t int64 = tool.MakeTimestamp()
+
+	guard := pebble_service.GetGlobalQuotaGuard()
+	if guard == nil {
+		respond.JSONP(c, http.StatusOK, respond.RespErr(errors.New("租户配额未启用"), tool.MakeTimestamp()-t, respond.HttpsCodeError))
+		return
+	}
+
+	month := c.Query("month")
+	if month != "" {
+		if _, err := time.Parse("2006-01", month); err != nil {
+			respond.JSONP(c, http.StatusOK, respond.RespErr(errors.New("month 格式应为 2006-01"), tool.MakeTimestamp()-t, respond.HttpsCodeError))
+			return
+		}
+	}
+
+	var usages []*models.TenantUsage
+	if tenantId := c.Query("tenantId"); tenantId != "" {
+		usage, err := guard.GetUsage(tenantId, month)
+		if err != nil {
+			respond.JSONP(c, http.StatusOK, respond.RespErr(err, tool.MakeTimestamp()-t, respond.HttpsCodeError))
+			return
+		}
+		usages = []*models.TenantUsage{usage}
+	} else {
+		var err error
+		usages, err = guard.ListUsage(month)
+		if err != nil {
+			respond.JSONP(c, http.StatusOK, respond.RespErr(err, tool.MakeTimestamp()-t, respond.HttpsCodeError))
+			return
+		}
+	}
+
+	respond.JSONP(c, http.StatusOK, respond.RespSuccess(usages, tool.MakeTimestamp()-t))
+}
+
+// GetAPIKeys godoc
+// @Summary 获取 API Key 列表及使用统计
+// @Description 列出所有 API Key（配置文件中的 api_key、api_keys 以及通过管理接口创建的 Key，只返回名称、指纹、来源和权限范围），以及各 Key 的请求数、失败率、最后使用时间和最后请求来源，便于发现长期未使用或异常调用的 Key。未匹配任何 Key 的请求统计在 unknown 中。统计为进程内数据，重启后清零。
+// @Tags Admin API
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} respond.Response{data=[]auth.APIKeyStats} "成功响应"
+// @Failure 401 {object} respond.Response "认证失败"
+// @Router /v1/admin/get_api_keys [get]
+func GetAPIKeys(c *gin.Context) {
+	var t int64 = tool.MakeTimestamp()
+
+	respond.JSONP(c, http.StatusOK, respond.RespSuccess(auth.GetAPIKeyStats(), tool.MakeTimestamp()-t))
+}
+
+// CreateAPIKey godoc
+// @Summary 创建 API Key
+// @Description 生成新的具名 API Key 并指定权限范围（read：查询接口；write：发送推送、修改用户数据，包含 read；admin：管理接口，包含全部）和可选的所属租户（通过该 Key 发送的推送计入该租户的配额）。Key 只在本次响应中返回，服务端只保存其哈希
+// @Tags Admin API
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body request.CreateAPIKeyReq true "请求参数"
+// @Success 200 {object} respond.Response "成功响应"
+// @Failure 401 {object} respond.Response "认证失败"
+// @Failure 403 {object} respond.Response "权限不足"
+// @Failure 500 {object} respond.Response "服务器内部错误"
+// @Router /v1/admin/create_api_key [post]
+func CreateAPIKey(c *gin.Context) {
+	var (
+		t            int64 = tool.MakeTimestamp()
+		requestModel *request.CreateAPIKeyReq
+	)
+
+	if c.ShouldBindJSON(&requestModel) == nil {
+		key, record, err := auth.CreateAPIKey(requestModel.Name, requestModel.Scopes, requestModel.TenantID)
+		if err != nil {
+			respond.JSONP(c, http.StatusOK, respond.RespErr(err, tool.MakeTimestamp()-t, respond.HttpsCodeError))
+			return
+		}
+
+		responseData := map[string]interface{}{
+			"name":        record.Name,
+			"key":         key,
+			"fingerprint": record.Fingerprint,
+			"scopes":      record.Scopes,
+			"tenantId":    record.TenantID,
+			"createdAt":   record.CreatedAt,
+		}
+
+		respond.JSONP(c, http.StatusOK, respond.RespSuccess(responseData, tool.MakeTimestamp()-t))
+		return
+	}
+
+	respond.JSONP(c, http.StatusInternalServerError, respond.RespErr(errors.New("参数错误"), tool.MakeTimestamp()-t, respond.HttpsCodeError))
+}
+
+// RevokeAPIKey godoc
+// @Summary 吊销 API Key
+// @Description 吊销通过管理接口创建的 API Key，立即生效；配置文件中的 Key 需从配置中移除
+// @Tags Admin API
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body request.RevokeAPIKeyReq true "请求参数"
+// @Success 200 {object} respond.Response "成功响应"
+// @Failure 401 {object} respond.Response "认证失败"
+// @Failure 403 {object} respond.Response "权限不足"
+// @Failure 500 {object} respond.Response "服务器内部错误"
+// @Router /v1/admin/revoke_api_key [post]
+func RevokeAPIKey(c *gin.Context) {
+	var (
+		t            int64 = tool.MakeTimestamp()
+		requestModel *request.RevokeAPIKeyReq
+	)
+
+	if c.ShouldBindJSON(&requestModel) == nil {
+		if err := auth.RevokeAPIKey(requestModel.Name); err != nil {
+			respond.JSONP(c, http.StatusOK, respond.RespErr(err, tool.MakeTimestamp()-t, respond.HttpsCodeError))
+			return
+		}
+
+		responseData := map[string]interface{}{
+			"success": true,
+			"message": "API Key 已吊销",
+			"name":    requestModel.Name,
+		}
+
+		respond.JSONP(c, http.StatusOK, respond.RespSuccess(responseData, tool.MakeTimestamp()-t))
+		return
+	}
+
+	respond.JSONP(c, http.StatusInternalServerError, respond.RespErr(errors.New("参数错误"), tool.MakeTimestamp()-t, respond.HttpsCodeError))
+}
+
+// MergeUsers godoc
+// @Summary 合并两个 MetaID 的推送状态
+// @Description 身份服务将两个 MetaID 关联后调用，把源用户的推送令牌、租户、偏好、屏蔽聊天和 QA 收件箱合并到目标用户，并写入审计记录。冲突规则：同一平台的令牌和不同租户保留 prefer 指定的一方（默认 target）；偏好保留 prefer 一方，语言为空时取另一方；同一聊天的屏蔽取更严格者（永久屏蔽优先，否则取更晚的截止时间）。合并不回滚，中途失败时审计记录中会写明错误，修复后可再次合并。
+// @Tags Admin API
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body request.MergeUsersReq true "合并请求"
+// @Success 200 {object} respond.Response{data=models.UserMergeRecord} "成功响应"
+// @Failure 401 {object} respond.Response "认证失败"
+// @Failure 500 {object} respond.Response "服务器内部错误"
+// @Router /v1/admin/merge_users [post]
+func MergeUsers(c *gin.Context) {
+	var (
+		t            int64 = tool.MakeTimestamp()
+		requestModel *request.MergeUsersReq
+	)
+
+	if c.ShouldBindJSON(&requestModel) == nil {
+		record, err := storage_service.MergeUserState(&storage_service.MergeRequest{
+			SourceMetaID: requestModel.SourceMetaID,
+			TargetMetaID: requestModel.TargetMetaID,
+			Prefer:       requestModel.Prefer,
+			Reason:       requestModel.Reason,
+		})
+		if err != nil {
+			respond.JSONP(c, http.StatusOK, respond.RespErr(err, tool.MakeTimestamp()-t, respond.HttpsCodeError))
+			return
+		}
+
+		respond.JSONP(c, http.StatusOK, respond.RespSuccess(record, tool.MakeTimestamp()-t))
+		return
+	}
+
+	respond.JSONP(c, http.StatusInternalServerError, respond.RespErr(errors.New("参数错误"), tool.MakeTimestamp()-t, respond.HttpsCodeError))
+}
+
+// GetRoutingRules godoc
+// @Summary 获取通知路由规则
+// @Description 获取当前生效的通知路由规则及其来源（config：配置文件，api：管理接口设置）
+// @Tags Admin API
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} respond.Response "成功响应，data 为 {source, rules}"
+// @Failure 401 {object} respond.Response "认证失败"
+// @Failure 500 {object} respond.Response "服务器内部错误"
+// @Router /v1/admin/get_routing_rules [get]
+func GetRoutingRules(c *gin.Context) {
+	var t int64 = tool.MakeTimestamp()
+
+	router := pushcenter.GetGlobalRouter()
+	if router == nil {
+		respond.JSONP(c, http.StatusOK, respond.RespErr(errors.New("推送中心未启用"), tool.MakeTimestamp()-t, respond.HttpsCodeError))
+		return
+	}
+
+	rules, source := router.Rules()
+	responseData := map[string]interface{}{
+		"source": source,
+		"rules":  rules,
+	}
+	respond.JSONP(c, http.StatusOK, respond.RespSuccess(responseData, tool.MakeTimestamp()-t))
+}
+
+// SetRoutingRules godoc
+// @Summary 设置通知路由规则
+// @Description 整体替换通知路由规则，立即生效并保存（重启后仍生效），覆盖配置文件中的规则。规则按顺序匹配，命中第一条即止；匹配条件（messageTypes、chatInfoTypes、mention、dataFields）之间为“且”关系，未设置的条件不参与匹配；命中后按规则设置通知的 priority（high/normal）、sound、ttl（秒）、channelId（Android 通知渠道）和 providers（限定推送提供者），未设置的项保持默认。
+// @Tags Admin API
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body request.SetRoutingRulesReq true "路由规则"
+// @Success 200 {object} respond.Response{data=[]models.RoutingRule} "成功响应"
+// @Failure 401 {object} respond.Response "认证失败"
+// @Failure 500 {object} respond.Response "服务器内部错误"
+// @Router /v1/admin/set_routing_rules [post]
+func SetRoutingRules(c *gin.Context) {
+	var (
+		t            int64 = tool.MakeTimestamp()
+		requestModel *request.SetRoutingRulesReq
+	)
+
+	if c.ShouldBindJSON(&requestModel) == nil {
+		if err := pushcenter.UpdateRoutingRules(requestModel.Rules); err != nil {
+			respond.JSONP(c, http.StatusOK, respond.RespErr(err, tool.MakeTimestamp()-t, respond.HttpsCodeError))
+			return
+		}
+
+		rules, _ := pushcenter.GetGlobalRouter().Rules()
+		respond.JSONP(c, http.StatusOK, respond.RespSuccess(rules, tool.MakeTimestamp()-t))
+		return
+	}
+
+	respond.JSONP(c, http.StatusInternalServerError, respond.RespErr(errors.New("参数错误"), tool.MakeTimestamp()-t, respond.HttpsCodeError))
+}
+
+// ResetRoutingRules godoc
+// @Summary 恢复配置文件中的通知路由规则
+// @Description 删除通过管理接口设置的路由规则，恢复使用配置文件 routing.rules 中的规则
+// @Tags Admin API
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} respond.Response{data=[]models.RoutingRule} "成功响应"
+// @Failure 401 {object} respond.Response "认证失败"
+// @Failure 500 {object} respond.Response "服务器内部错误"
+// @Router /v1/admin/reset_routing_rules [post]
+func ResetRoutingRules(c *gin.Context) {
+	var t int64 = tool.MakeTimestamp()
+
+	if err := pushcenter.ResetRoutingRules(); err != nil {
+		respond.JSONP(c, http.StatusOK, respond.RespErr(err, tool.MakeTimestamp()-t, respond.HttpsCodeError))
+		return
+	}
+
+	rules, _ := pushcenter.GetGlobalRouter().Rules()
+	respond.JSONP(c, http.StatusOK, respond.RespSuccess(rules, tool.MakeTimestamp()-t))
+}
+
+// GetEnabledTypes godoc
+// @Summary 获取启用的消息类型
+// @Description 获取推送中心当前启用的消息类型及其来源（config：配置文件 push_center.enabled_types，api：管理接口设置）
+// @Tags Admin API
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} respond.Response "成功响应，data 为 {types, source, updatedAt, supportedTypes}"
+// @Failure 401 {object} respond.Response "认证失败"
+// @Failure 500 {object} respond.Response "服务器内部错误"
+// @Router /v1/admin/enabled_types [get]
+func GetEnabledTypes(c *gin.Context) {
+	var t int64 = tool.MakeTimestamp()
+
+	messageTypes := pushcenter.GetGlobalMessageTypes()
+	if messageTypes == nil {
+		respond.JSONP(c, http.StatusOK, respond.RespErr(errors.New("推送中心未启用"), tool.MakeTimestamp()-t, respond.HttpsCodeError))
+		return
+	}
+
+	respond.JSONP(c, http.StatusOK, respond.RespSuccess(enabledTypesResponse(messageTypes), tool.MakeTimestamp()-t))
+}
+
+// SetEnabledTypes godoc
+// @Summary 设置启用的消息类型
+// @Description 整体替换推送中心启用的消息类型，立即生效并保存（重启后仍生效），覆盖配置文件 push_center.enabled_types。未启用类型的消息直接忽略，至少需要启用一种类型。
+// @Tags Admin API
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body request.SetEnabledTypesReq true "启用的消息类型"
+// @Success 200 {object} respond.Response "成功响应，data 为 {types, source, updatedAt, supportedTypes}"
+// @Failure 401 {object} respond.Response "认证失败"
+// @Failure 500 {object} respond.Response "服务器内部错误"
+// @Router /v1/admin/enabled_types [put]
+func SetEnabledTypes(c *gin.Context) {
+	var (
+		t            int64 = tool.MakeTimestamp()
+		requestModel *request.SetEnabledTypesReq
+	)
+
+	if c.ShouldBindJSON(&requestModel) == nil {
+		if err := pushcenter.UpdateEnabledTypes(requestModel.Types); err != nil {
+			respond.JSONP(c, http.StatusOK, respond.RespErr(err, tool.MakeTimestamp()-t, respond.HttpsCodeError))
+			return
+		}
+
+		respond.JSONP(c, http.StatusOK, respond.RespSuccess(enabledTypesResponse(pushcenter.GetGlobalMessageTypes()), tool.MakeTimestamp()-t))
+		return
+	}
+
+	respond.JSONP(c, http.StatusInternalServerError, respond.RespErr(errors.New("参数错误"), tool.MakeTimestamp()-t, respond.HttpsCodeError))
+}
+
+// ReloadConfig godoc
+// @Summary 热加载配置文件
+// @Description 重新读取配置文件，HTTP 限流（rate_limit）和 Expo 提供者（push.providers.expo：超时、重试、批量大小等）的修改立即生效，无需重启。其他已修改的配置项在 restartRequired 中返回，需要重启才能生效。向进程发送 SIGHUP 效果相同。
+// @Tags Admin API
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} respond.Response{data=conf.ReloadResult} "成功响应"
+// @Failure 401 {object} respond.Response "认证失败"
+// @Failure 500 {object} respond.Response "服务器内部错误"
+// @Router /v1/admin/reload_config [post]
+func ReloadConfig(c *gin.Context) {
+	var t int64 = tool.MakeTimestamp()
+
+	result, err := conf.ReloadConfig()
+	if err != nil {
+		respond.JSONP(c, http.StatusOK, respond.RespErr(err, tool.MakeTimestamp()-t, respond.HttpsCodeError))
+		return
+	}
+
+	respond.JSONP(c, http.StatusOK, respond.RespSuccess(result, tool.MakeTimestamp()-t))
+}
+
+// enabledTypesResponse 生成启用消息类型的响应数据
+func enabledTypesResponse(messageTypes *pushcenter.MessageTypeSwitch) map[string]interface{} {
+	types, source, updatedAt := messageTypes.Types()
+	return map[string]interface{}{
+		"types":          types,
+		"source":         source,
+		"updatedAt":      updatedAt,
+		"supportedTypes": pushcenter.SupportedMessageTypes,
+	}
+}
+
+// 合并审计记录查询条数
+const (
+	defaultUserMergesLimit = 50
+	maxUserMergesLimit     = 500
+)
+
+// GetUserMerges godoc
+// @Summary 获取用户合并审计记录
+// @Description 按时间倒序返回用户合并审计记录，传 metaId 时只返回该 MetaID 作为源或目标的记录
+// @Tags Admin API
+// @Produce json
+// @Security ApiKeyAuth
+// @Param metaId query string false "MetaID（不传则返回全部）"
+// @Param limit query int false "返回条数（默认50，最大500）"
+// @Success 200 {object} respond.Response{data=[]models.UserMergeRecord} "成功响应"
+// @Failure 401 {object} respond.Response "认证失败"
+// @Failure 500 {object} respond.Response "服务器内部错误"
+// @Router /v1/admin/get_user_merges [get]
+func GetUserMerges(c *gin.Context) {
+	var t int64 = tool.MakeTimestamp()
+
+	limit := defaultUserMergesLimit
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
+			limit = min(l, maxUserMergesLimit)
+		}
+	}
+
+	records, err := pebble_service.ListUserMergeRecords(c.Query("metaId"), limit)
+	if err != nil {
+		respond.JSONP(c, http.StatusOK, respond.RespErr(err, tool.MakeTimestamp()-t, respond.HttpsCodeError))
+		return
+	}
+
+	respond.JSONP(c, http.StatusOK, respond.RespSuccess(records, tool.MakeTimestamp()-t))
+}
+
+// 隔离消息查询条数
+const (
+	defaultQuarantineLimit = 100
+	maxQuarantineLimit     = 1000
+)
+
+// GetQuarantinedMessages godoc
+// @Summary 获取隔离的上游消息
+// @Description 按隔离时间倒序返回解析或校验失败的上游聊天消息原文及错误原因，用于排查上游消息格式变化。严格解析失败（未知字段、类型不匹配等）的消息仍按已解析的字段正常推送；结构校验失败（消息内容不是对象、缺少群聊ID或私聊对象等必填字段）的消息未推送（rejected=true），可通过 requeue_quarantined 重新入队
+// @Tags Admin API
+// @Produce json
+// @Security ApiKeyAuth
+// @Param limit query int false "返回条数（默认100，最大1000）"
+// @Success 200 {object} respond.Response{data=[]models.QuarantinedMessage} "成功响应"
+// @Failure 401 {object} respond.Response "认证失败"
+// @Failure 500 {object} respond.Response "服务器内部错误"
+// @Router /v1/admin/get_quarantined_messages [get]
+func GetQuarantinedMessages(c *gin.Context) {
+	var t int64 = tool.MakeTimestamp()
+
+	limit := defaultQuarantineLimit
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
+			limit = min(l, maxQuarantineLimit)
+		}
+	}
+
+	messages, err := pebble_service.ListQuarantinedMessages(limit)
+	if err != nil {
+		respond.JSONP(c, http.StatusOK, respond.RespErr(err, tool.MakeTimestamp()-t, respond.HttpsCodeError))
+		return
+	}
+
+	respond.JSONP(c, http.StatusOK, respond.RespSuccess(messages, tool.MakeTimestamp()-t))
+}
+
+// ClearQuarantine godoc
+// @Summary 清空隔离的上游消息
+// @Description 删除所有隔离消息，通常在上游格式问题处理完成后调用
+// @Tags Admin API
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} respond.Response "成功响应，data 为 {cleared}"
+// @Failure 401 {object} respond.Response "认证失败"
+// @Failure 500 {object} respond.Response "服务器内部错误"
+// @Router /v1/admin/clear_quarantine [post]
+func ClearQuarantine(c *gin.Context) {
+	var t int64 = tool.MakeTimestamp()
+
+	cleared, err := pebble_service.ClearQuarantine()
+	if err != nil {
+		respond.JSONP(c, http.StatusOK, respond.RespErr(err, tool.MakeTimestamp()-t, respond.HttpsCodeError))
+		return
+	}
+
+	respond.JSONP(c, http.StatusOK, respond.RespSuccess(map[string]interface{}{"cleared": cleared}, tool.MakeTimestamp()-t))
+}
+
+// RequeueQuarantined godoc
+// @Summary 隔离消息重新入队
+// @Description 将因结构校验失败未推送的隔离消息按隔离时间顺序重新交给推送中心处理（通常在上游修复或放宽校验后调用），入队前从隔离集合删除，仍校验失败的消息会重新隔离为新条目。ids 为空时重新入队所有未推送的消息；已照常推送过的消息不会重复推送，记入 skipped
+// @Tags Admin API
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body request.RequeueQuarantinedReq true "隔离条目ID"
+// @Success 200 {object} respond.Response{data=pushcenter.RequeueResult} "成功响应"
+// @Failure 401 {object} respond.Response "认证失败"
+// @Failure 500 {object} respond.Response "服务器内部错误"
+// @Router /v1/admin/requeue_quarantined [post]
+func RequeueQuarantined(c *gin.Context) {
+	var (
+		t            int64 = tool.MakeTimestamp()
+		requestModel *request.RequeueQuarantinedReq
+	)
+
+	if c.ShouldBindJSON(&requestModel) == nil {
+		result, err := pushcenter.RequeueQuarantined(requestModel.IDs)
+		if err != nil {
+			respond.JSONP(c, http.StatusOK, respond.RespErr(err, tool.MakeTimestamp()-t, respond.HttpsCodeError))
+			return
+		}
+
+		respond.JSONP(c, http.StatusOK, respond.RespSuccess(result, tool.MakeTimestamp()-t))
+		return
+	}
+
+	respond.JSONP(c, http.StatusInternalServerError, respond.RespErr(errors.New("参数错误"), tool.MakeTimestamp()-t, respond.HttpsCodeError))
+}
+
+// ReplayMessage godoc
+// @Summary 回放上游消息
+// @Description 将抓取到的原始 SocketData 载荷重新交给推送中心处理，用于复现线上的解析和推送问题。回放不做 PIN 去重、严格解析失败不写入隔离集合，同步返回解析结果、生成的通知以及每个用户的投递结果或跳过原因；dryRun 时不调用推送平台，也不记录通知状态和投递结果
+// @Tags Admin API
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body request.ReplayMessageReq true "原始载荷"
+// @Success 200 {object} respond.Response{data=pushcenter.ReplayResult} "成功响应"
+// @Failure 401 {object} respond.Response "认证失败"
+// @Failure 500 {object} respond.Response "服务器内部错误"
+// @Router /v1/admin/replay_message [post]
+func ReplayMessage(c *gin.Context) {
+	var (
+		t            int64 = tool.MakeTimestamp()
+		requestModel *request.ReplayMessageReq
+	)
+
+	if c.ShouldBindJSON(&requestModel) == nil {
+		result, err := pushcenter.ReplayMessage(requestModel.Payload, requestModel.DryRun)
+		if err != nil {
+			respond.JSONP(c, http.StatusOK, respond.RespErr(err, tool.MakeTimestamp()-t, respond.HttpsCodeError))
+			return
+		}
+
+		respond.JSONP(c, http.StatusOK, respond.RespSuccess(result, tool.MakeTimestamp()-t))
+		return
+	}
+
+	respond.JSONP(c, http.StatusInternalServerError, respond.RespErr(errors.New("参数错误"), tool.MakeTimestamp()-t, respond.HttpsCodeError))
+}
+
+// 令牌统计查询天数
+const (
+	defaultTokenMetricsDays = 30
+	maxTokenMetricsDays     = 365
+)
+
+// AdminStats godoc
+// @Description 获取各集合记录数、各租户 Webhook 投递统计、各上游 Socket 连接状态及按方法分类的入站消息数/字节数、最近若干天按平台的令牌注册/移除/转移统计，以及过期令牌清理统计（tokenGC，从未清理时为 null）
+// @Tags Admin API
+// @Produce json
+// @Security ApiKeyAuth
+// @Param days query int false "令牌统计天数（默认30，最大365）"
+// @Success 200 {object} respond.Response "成功响应"
+// @Failure 401 {object} respond.Response "认证失败"
+// @Failure 500 {object} respond.Response "服务器内部错误"
+// @Router /v1/admin/stats [get]
+func AdminStats(c *gin.Context) {
+	var t int64 = tool.MakeTimestamp()
+
+	collections, err := pebble_service.ListCollectionsGlobal()
+	if err != nil {
+		respond.JSONP(c, http.StatusOK, respond.RespErr(err, tool.MakeTimestamp()-t, respond.HttpsCodeError))
+		return
+	}
+
+	days := defaultTokenMetricsDays
+	if daysStr := c.Query("days"); daysStr != "" {
+		if d, err := strconv.Atoi(daysStr); err == nil && d > 0 {
+			days = d
+		}
+	}
+	if days > maxTokenMetricsDays {
+		days = maxTokenMetricsDays
+	}
+
+	tokenMetrics, err := pebble_service.GetTokenMetrics(days)
+	if err != nil {
+		respond.JSONP(c, http.StatusOK, respond.RespErr(err, tool.MakeTimestamp()-t, respond.HttpsCodeError))
+		return
+	}
+
+	tokenGCStats, err := pebble_service.GetTokenGCStats()
+	if err != nil {
+		respond.JSONP(c, http.StatusOK, respond.RespErr(err, tool.MakeTimestamp()-t, respond.HttpsCodeError))
+		return
+	}
+
+	webhookStats := map[string]interface{}{
+		"enabled": false,
+	}
+	if dispatcher := webhook_service.GetGlobalDispatcher(); dispatcher != nil {
+		webhookStats["enabled"] = true
+		webhookStats["queueLength"] = dispatcher.QueueLength()
+		webhookStats["tenants"] = dispatcher.GetStats()
+	}
+
+	socketStats := map[string]interface{}{
+		"enabled": false,
+	}
+	if manager := socket_client_service.GetGlobalManager(); manager != nil {
+		socketStats["enabled"] = true
+		socketStats["upstreams"] = manager.GetUpstreamHealth()
+	}
+
+	responseData := map[string]interface{}{
+		"collections": collections,
+		"webhook":     webhookStats,
+		"socket":      socketStats,
+		"tokenMetrics": map[string]interface{}{
+			"days":  days,
+			"daily": tokenMetrics,
+		},
+		"tokenGC": tokenGCStats,
+	}
+
+	respond.JSONP(c, http.StatusOK, respond.RespSuccess(responseData, tool.MakeTimestamp()-t))
+}
+
+// GetDBStats godoc
+// @Summary 获取 Pebble 数据库内部统计
+// @Description 获取每个已打开的 Pebble 实例（独立布局下每个集合一个实例，共享布局下只有共享实例 _keyspace）的磁盘占用、SST 文件数和大小、读放大、内存表占用、WAL 大小、刷盘和压缩次数、压缩欠账以及块缓存命中情况
+// @Tags Admin API
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} respond.Response{data=[]pebble_service.CollectionDBStats} "成功响应"
+// @Failure 401 {object} respond.Response "认证失败"
+// @Failure 500 {object} respond.Response "服务器内部错误"
+// @Router /v1/admin/db_stats [get]
+func GetDBStats(c *gin.Context) {
+	var t int64 = tool.MakeTimestamp()
+
+	stats, err := pebble_service.GetDBStats()
+	if err != nil {
+		respond.JSONP(c, http.StatusOK, respond.RespErr(err, tool.MakeTimestamp()-t, respond.HttpsCodeError))
+		return
+	}
+
+	respond.JSONP(c, http.StatusOK, respond.RespSuccess(stats, tool.MakeTimestamp()-t))
+}
+
+// CompactDB godoc
+// @Summary 手动压缩 Pebble 数据库
+// @Description 对指定集合（默认所有已打开的集合）执行全量压缩，回收已删除数据占用的空间并降低读放大。压缩期间会占用较多磁盘 IO；async=true 时作为后台任务执行并立即返回任务信息，通过 /v1/admin/jobs/{id} 查询结果
+// @Tags Admin API
+// @Produce json
+// @Security ApiKeyAuth
+// @Param collections query string false "要压缩的集合，多个用逗号分隔，默认所有已打开的集合"
+// @Param async query bool false "是否作为后台任务执行"
+// @Success 200 {object} respond.Response{data=[]pebble_service.CompactionResult} "成功响应（async=true 时 data 为 models.Job）"
+// @Failure 401 {object} respond.Response "认证失败"
+// @Failure 500 {object} respond.Response "服务器内部错误"
+// @Router /v1/admin/compact [post]
+func CompactDB(c *gin.Context) {
+	var t int64 = tool.MakeTimestamp()
+
+	var collections []string
+	for _, name := range strings.Split(c.Query("collections"), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			collections = append(collections, name)
+		}
+	}
+
+	if c.Query("async") == "true" {
+		runner := job_service.GetGlobalRunner()
+		if runner == nil {
+			respond.JSONP(c, http.StatusOK, respond.RespErr(errors.New("推送中心未启用"), tool.MakeTimestamp()-t, respond.HttpsCodeError))
+			return
+		}
+		job, err := runner.Submit(models.JobTypeCompact, func(ctx context.Context, progress *job_service.Progress) (interface{}, error) {
+			progress.Report(0, "正在压缩数据库")
+			return pebble_service.CompactCollections(ctx, collections, func(done, total int, name string) {
+				progress.Report(done*100/total, fmt.Sprintf("已压缩集合 %s（%d/%d）", name, done, total))
+			})
+		})
+		if err != nil {
+			respond.JSONP(c, http.StatusOK, respond.RespErr(err, tool.MakeTimestamp()-t, respond.HttpsCodeError))
+			return
+		}
+		respond.JSONP(c, http.StatusOK, respond.RespSuccess(job, tool.MakeTimestamp()-t))
+		return
+	}
+
+	results, err := pebble_service.CompactCollections(c.Request.Context(), collections, nil)
+	if err != nil {
+		respond.JSONP(c, http.StatusOK, respond.RespErr(err, tool.MakeTimestamp()-t, respond.HttpsCodeError))
+		return
+	}
+
+	respond.JSONP(c, http.StatusOK, respond.RespSuccess(results, tool.MakeTimestamp()-t))
+}
+
+// GetProviderStats godoc
+// @Summary 获取推送提供者发送统计
+// @Description 获取进程启动以来各推送提供者（expo、apns、wns、email 等）的发送成功/失败次数、内部重试次数、平均耗时、最近一次失败原因和时间、最近一次成功时间，用于判断某个推送平台是否异常。同样的数据以 push_provider_* 指标暴露在 /metrics
+// @Tags Admin API
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} respond.Response{data=[]push_service.ProviderStats} "成功响应"
+// @Failure 401 {object} respond.Response "认证失败"
+// @Router /v1/admin/provider_stats [get]
+func GetProviderStats(c *gin.Context) {
+	var t int64 = tool.MakeTimestamp()
+
+	respond.JSONP(c, http.StatusOK, respond.RespSuccess(push_service.GetProviderStats(), tool.MakeTimestamp()-t))
+}
@@ -0,0 +1,131 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"push-base-service/conf"
+	"push-base-service/models"
+	"push-base-service/service/pebble_service"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// managedAPIKeyPrefix 管理接口生成的 Key 前缀，便于在日志和配置中识别
+const managedAPIKeyPrefix = "pbs_"
+
+// scopeImplies 各权限范围额外包含的权限
+var scopeImplies = map[string][]string{
+	models.APIKeyScopeAdmin: {models.APIKeyScopeWrite, models.APIKeyScopeRead},
+	models.APIKeyScopeWrite: {models.APIKeyScopeRead},
+}
+
+// ValidateScopes 校验权限范围，至少需要一项
+func ValidateScopes(scopes []string) error {
+	if len(scopes) == 0 {
+		return errors.New("权限范围不能为空")
+	}
+	for _, scope := range scopes {
+		switch scope {
+		case models.APIKeyScopeRead, models.APIKeyScopeWrite, models.APIKeyScopeAdmin:
+		default:
+			return fmt.Errorf("未知的权限范围: %s（可选 read/write/admin）", scope)
+		}
+	}
+	return nil
+}
+
+// scopeAllowed 判断已授予的权限范围是否包含所需权限
+func scopeAllowed(granted []string, required string) bool {
+	for _, scope := range granted {
+		if scope == required || slices.Contains(scopeImplies[scope], required) {
+			return true
+		}
+	}
+	return false
+}
+
+// configScopes 配置文件中 Key 的权限范围，未配置时为 admin（与引入权限范围之前的行为一致）
+func configScopes(scopes []string) []string {
+	if len(scopes) == 0 {
+		return []string{models.APIKeyScopeAdmin}
+	}
+	return scopes
+}
+
+// lookupManagedAPIKey 按哈希查找管理接口创建的 Key，推送中心未启用时返回错误
+func lookupManagedAPIKey(presented string) (*apiKeyEntry, error) {
+	record, err := pebble_service.GetAPIKeyByHash(apiKeyHash(presented))
+	if err != nil || record == nil {
+		return nil, err
+	}
+	return &apiKeyEntry{name: record.Name, scopes: record.Scopes, tenant: record.TenantID}, nil
+}
+
+// CreateAPIKey 创建 API Key 并保存其哈希，返回只展示这一次的 Key；tenantID 为调用方所属租户（可为空）
+func CreateAPIKey(name string, scopes []string, tenantID string) (string, *models.APIKeyRecord, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || name == UnknownAPIKeyName {
+		return "", nil, fmt.Errorf("无效的 Key 名称: %q", name)
+	}
+	if err := ValidateScopes(scopes); err != nil {
+		return "", nil, err
+	}
+	for _, entry := range configuredAPIKeys() {
+		if entry.name == name {
+			return "", nil, fmt.Errorf("API Key 名称已在配置文件中使用: %s", name)
+		}
+	}
+
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return "", nil, fmt.Errorf("生成 API Key 失败: %w", err)
+	}
+	key := managedAPIKeyPrefix + hex.EncodeToString(random)
+
+	record := &models.APIKeyRecord{
+		Name:        name,
+		KeyHash:     apiKeyHash(key),
+		Fingerprint: apiKeyFingerprint(key),
+		Scopes:      slices.Compact(slices.Sorted(slices.Values(scopes))),
+		TenantID:    strings.TrimSpace(tenantID),
+	}
+	if err := pebble_service.SaveAPIKey(record); err != nil {
+		return "", nil, err
+	}
+	return key, record, nil
+}
+
+// RevokeAPIKey 吊销管理接口创建的 API Key，配置文件中的 Key 需从配置中移除
+func RevokeAPIKey(name string) error {
+	for _, entry := range configuredAPIKeys() {
+		if entry.name == name {
+			return fmt.Errorf("配置文件中的 API Key 需从配置中移除: %s", name)
+		}
+	}
+
+	deleted, err := pebble_service.DeleteAPIKey(name)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return fmt.Errorf("API Key 不存在: %s", name)
+	}
+	return nil
+}
+
+// UserEndpointScope 用户自助接口（令牌、屏蔽聊天、偏好）的鉴权：
+// 默认要求具有指定权限范围的 API Key，显式关闭 protect_user_endpoints 后开放给客户端
+func UserEndpointScope(scope string) gin.HandlerFunc {
+	required := RequireScope(scope)
+	return func(c *gin.Context) {
+		if !conf.ProtectUserEndpoints {
+			c.Next()
+			return
+		}
+		required(c)
+	}
+}
@@ -0,0 +1,130 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"push-base-service/conf"
+	"push-base-service/models"
+	"push-base-service/service/pebble_service"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestScopeAllowed(t *testing.T) {
+	tests := []struct {
+		granted  []string
+		required string
+		want     bool
+	}{
+		{[]string{models.APIKeyScopeAdmin}, models.APIKeyScopeRead, true},
+		{[]string{models.APIKeyScopeWrite}, models.APIKeyScopeRead, true},
+		{[]string{models.APIKeyScopeWrite}, models.APIKeyScopeAdmin, false},
+		{[]string{models.APIKeyScopeRead}, models.APIKeyScopeWrite, false},
+		{nil, models.APIKeyScopeRead, false},
+	}
+	for _, tt := range tests {
+		if got := scopeAllowed(tt.granted, tt.required); got != tt.want {
+			t.Errorf("scopeAllowed(%v, %s) = %v, want %v", tt.granted, tt.required, got, tt.want)
+		}
+	}
+}
+
+func TestRequireScopeWithManagedKeys(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	if err := pebble_service.InitializeGlobalService(&pebble_service.Config{DBPath: t.TempDir()}); err != nil {
+		t.Fatalf("初始化 Pebble 服务失败: %v", err)
+	}
+	t.Cleanup(func() { pebble_service.CloseGlobalService() })
+	conf.APIKeys = []conf.APIKeyConf{{Name: "reader", Key: "reader-key", Scopes: []string{models.APIKeyScopeRead}}}
+	t.Cleanup(func() { conf.APIKeys = nil })
+
+	writerKey, _, err := CreateAPIKey("writer", []string{models.APIKeyScopeWrite}, "")
+	if err != nil {
+		t.Fatalf("CreateAPIKey() failed, err: %v", err)
+	}
+	if _, _, err := CreateAPIKey("reader", []string{models.APIKeyScopeRead}, ""); err == nil {
+		t.Errorf("CreateAPIKey() should reject a name used in config")
+	}
+
+	router := gin.New()
+	ok := func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"code": 0}) }
+	router.GET("/read", RequireScope(models.APIKeyScopeRead), ok)
+	router.POST("/write", RequireScope(models.APIKeyScopeWrite), ok)
+	router.POST("/admin", RequireScope(models.APIKeyScopeAdmin), ok)
+
+	send := func(method, path, key string) int {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("X-API-KEY", key)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder.Code
+	}
+
+	checks := []struct {
+		method, path, key string
+		want              int
+	}{
+		{http.MethodGet, "/read", "reader-key", http.StatusOK},
+		{http.MethodPost, "/write", "reader-key", http.StatusForbidden},
+		{http.MethodGet, "/read", writerKey, http.StatusOK},
+		{http.MethodPost, "/write", writerKey, http.StatusOK},
+		{http.MethodPost, "/admin", writerKey, http.StatusForbidden},
+	}
+	for _, check := range checks {
+		if got := send(check.method, check.path, check.key); got != check.want {
+			t.Errorf("%s %s with %s = %d, want %d", check.method, check.path, check.key, got, check.want)
+		}
+	}
+
+	if err := RevokeAPIKey("writer"); err != nil {
+		t.Fatalf("RevokeAPIKey() failed, err: %v", err)
+	}
+	if got := send(http.MethodGet, "/read", writerKey); got != http.StatusUnauthorized {
+		t.Errorf("revoked key = %d, want 401", got)
+	}
+}
+
+func TestUserEndpointScopeDefault(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	configPath := filepath.Join(t.TempDir(), "conf.yaml")
+	if err := os.WriteFile(configPath, []byte("api_keys:\n  - name: \"writer\"\n    key: \"writer-key\"\n    scopes: [\"write\"]\n"), 0o600); err != nil {
+		t.Fatalf("写入配置失败: %v", err)
+	}
+	conf.InitConfig(configPath)
+	t.Cleanup(func() {
+		conf.APIKeys = nil
+		conf.ProtectUserEndpoints = true
+	})
+	if !conf.ProtectUserEndpoints {
+		t.Fatalf("protect_user_endpoints should default to true")
+	}
+	if !conf.UserSignatureEnabled {
+		t.Fatalf("user_signature.enabled should default to true")
+	}
+
+	router := gin.New()
+	router.POST("/push/add_blocked_chat", UserEndpointScope(models.APIKeyScopeWrite), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"code": 0})
+	})
+	send := func(key string) int {
+		req := httptest.NewRequest(http.MethodPost, "/push/add_blocked_chat", nil)
+		req.Header.Set("X-API-KEY", key)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder.Code
+	}
+	if got := send(""); got != http.StatusUnauthorized {
+		t.Errorf("request without key = %d, want 401", got)
+	}
+	if got := send("writer-key"); got != http.StatusOK {
+		t.Errorf("request with write key = %d, want 200", got)
+	}
+
+	conf.ProtectUserEndpoints = false
+	if got := send(""); got != http.StatusOK {
+		t.Errorf("request without key after opting out = %d, want 200", got)
+	}
+}
@@ -0,0 +1,216 @@
+package auth
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"net/http"
+	"push-base-service/conf"
+	"push-base-service/models"
+	"push-base-service/service/metrics_service"
+	"push-base-service/service/pebble_service"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// DefaultAPIKeyName 配置项 api_key 对应的 Key 名称
+	DefaultAPIKeyName = "default"
+	// UnknownAPIKeyName 未匹配任何已配置 Key 的请求统计名称
+	UnknownAPIKeyName = "unknown"
+
+	// apiKeyNameContextKey 请求上下文中记录已鉴权 Key 名称的键
+	apiKeyNameContextKey = "apiKeyName"
+	// apiKeyTenantContextKey 请求上下文中记录已鉴权 Key 所属租户的键
+	apiKeyTenantContextKey = "apiKeyTenant"
+	// apiKeyScopeContextKey 请求上下文中记录 RequireScope 已校验通过的权限范围的键
+	apiKeyScopeContextKey = "apiKeyScope"
+)
+
+// apiKeyRequestsCounter 按 API Key 统计的请求数
+var apiKeyRequestsCounter = metrics_service.NewCounterVec(
+	"push_api_key_requests_total", "Number of API requests by API key and result", "key", "result")
+
+// APIKeyStats 单个 API Key 的使用统计（进程内统计，重启后清零）
+type APIKeyStats struct {
+	Name         string   `json:"name"`                   // Key 名称
+	Fingerprint  string   `json:"fingerprint,omitempty"`  // Key 的 SHA-256 前 8 位，用于核对而不暴露 Key
+	Source       string   `json:"source,omitempty"`       // Key 来源：config（配置文件）或 managed（管理接口创建）
+	Scopes       []string `json:"scopes,omitempty"`       // 权限范围
+	Tenant       string   `json:"tenant,omitempty"`       // 调用方所属租户
+	Requests     int64    `json:"requests"`               // 请求总数
+	Errors       int64    `json:"errors"`                 // 失败请求数（HTTP 状态码 >= 400 或响应 code 非 0）
+	ErrorRate    float64  `json:"errorRate"`              // 失败率
+	LastUsedAt   int64    `json:"lastUsedAt"`             // 最后使用时间（Unix 秒），0 表示启动后未使用
+	LastPath     string   `json:"lastPath,omitempty"`     // 最后请求的路径
+	LastClientIP string   `json:"lastClientIp,omitempty"` // 最后请求的客户端 IP
+}
+
+var (
+	apiKeyStats   = make(map[string]*APIKeyStats)
+	apiKeyStatsMu sync.Mutex
+)
+
+// API Key 来源
+const (
+	APIKeySourceConfig  = "config"  // 配置文件中的 api_key / api_keys
+	APIKeySourceManaged = "managed" // 通过管理接口创建，保存在 Pebble 中
+)
+
+// apiKeyEntry 已配置的 API Key
+type apiKeyEntry struct {
+	name   string
+	key    string
+	scopes []string
+	tenant string // 调用方所属租户
+}
+
+// configuredAPIKeys 返回所有已配置的 API Key（api_key 以及 api_keys 中的具名 Key）
+func configuredAPIKeys() []apiKeyEntry {
+	var keys []apiKeyEntry
+	if conf.APIKey != "" {
+		keys = append(keys, apiKeyEntry{name: DefaultAPIKeyName, key: conf.APIKey, scopes: []string{models.APIKeyScopeAdmin}})
+	}
+	for _, apiKey := range conf.APIKeys {
+		if apiKey.Key == "" {
+			continue
+		}
+		name := apiKey.Name
+		if name == "" {
+			name = apiKeyFingerprint(apiKey.Key)
+		}
+		keys = append(keys, apiKeyEntry{name: name, key: apiKey.Key, scopes: configScopes(apiKey.Scopes), tenant: apiKey.Tenant})
+	}
+	return keys
+}
+
+// matchAPIKey 按常量时间比较查找请求携带的 Key
+func matchAPIKey(keys []apiKeyEntry, presented string) (*apiKeyEntry, bool) {
+	var matched *apiKeyEntry
+	for i := range keys {
+		if subtle.ConstantTimeCompare([]byte(presented), []byte(keys[i].key)) == 1 && matched == nil {
+			matched = &keys[i]
+		}
+	}
+	return matched, matched != nil
+}
+
+// apiKeyFingerprint 计算 Key 的指纹
+func apiKeyFingerprint(key string) string {
+	return apiKeyHash(key)[:8]
+}
+
+// apiKeyHash 计算 Key 的 SHA-256
+func apiKeyHash(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// recordAPIKeyRequest 记录一次请求
+func recordAPIKeyRequest(name, path, clientIP string, failed bool) {
+	result := "success"
+	if failed {
+		result = "error"
+	}
+	apiKeyRequestsCounter.Inc(name, result)
+
+	apiKeyStatsMu.Lock()
+	defer apiKeyStatsMu.Unlock()
+
+	stats, exists := apiKeyStats[name]
+	if !exists {
+		stats = &APIKeyStats{Name: name}
+		apiKeyStats[name] = stats
+	}
+	stats.Requests++
+	if failed {
+		stats.Errors++
+	}
+	stats.LastUsedAt = time.Now().Unix()
+	stats.LastPath = path
+	stats.LastClientIP = clientIP
+}
+
+// GetAPIKeyStats 获取所有已配置和管理接口创建的 Key 的使用统计（包括启动后未使用的 Key），以及未匹配 Key 的请求统计
+func GetAPIKeyStats() []*APIKeyStats {
+	// 推送中心未启用时没有管理接口创建的 Key
+	managed, _ := pebble_service.ListAPIKeys()
+
+	apiKeyStatsMu.Lock()
+	defer apiKeyStatsMu.Unlock()
+
+	var result []*APIKeyStats
+	appendStats := func(name, fingerprint, source string, scopes []string, tenant string) {
+		stats := APIKeyStats{Name: name}
+		if recorded, exists := apiKeyStats[name]; exists {
+			stats = *recorded
+		}
+		stats.Fingerprint = fingerprint
+		stats.Source = source
+		stats.Scopes = scopes
+		stats.Tenant = tenant
+		if stats.Requests > 0 {
+			stats.ErrorRate = float64(stats.Errors) / float64(stats.Requests)
+		}
+		result = append(result, &stats)
+	}
+	for _, entry := range configuredAPIKeys() {
+		appendStats(entry.name, apiKeyFingerprint(entry.key), APIKeySourceConfig, entry.scopes, entry.tenant)
+	}
+	for _, record := range managed {
+		appendStats(record.Name, record.Fingerprint, APIKeySourceManaged, record.Scopes, record.TenantID)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+
+	if recorded, exists := apiKeyStats[UnknownAPIKeyName]; exists {
+		stats := *recorded
+		stats.ErrorRate = 1
+		result = append(result, &stats)
+	}
+	return result
+}
+
+// GetRequestAPIKeyName 获取当前请求通过鉴权的 Key 名称
+func GetRequestAPIKeyName(c *gin.Context) string {
+	return c.GetString(apiKeyNameContextKey)
+}
+
+// GetRequestAPIKeyTenant 获取当前请求通过鉴权的 Key 所属租户，未绑定租户或未经 API Key 鉴权时为空
+func GetRequestAPIKeyTenant(c *gin.Context) string {
+	return c.GetString(apiKeyTenantContextKey)
+}
+
+// responseCodeWriter 记录响应是否失败：接口出错时通常返回 HTTP 200 且响应体 code 非 0
+type responseCodeWriter struct {
+	gin.ResponseWriter
+	checked bool
+	failed  bool
+}
+
+// Write 根据首次写入的响应体判断 code 是否为 0
+func (w *responseCodeWriter) Write(data []byte) (int, error) {
+	if !w.checked {
+		w.checked = true
+		trimmed := bytes.TrimLeft(data, " \t\r\n")
+		if code, ok := bytes.CutPrefix(trimmed, []byte(`{"code":`)); ok {
+			w.failed = !bytes.HasPrefix(code, []byte("0,")) && !bytes.HasPrefix(code, []byte("0}"))
+		}
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+// trackAPIKeyRequest 执行后续处理并记录本次请求的结果
+func trackAPIKeyRequest(c *gin.Context, name string) {
+	writer := &responseCodeWriter{ResponseWriter: c.Writer}
+	c.Writer = writer
+	c.Set(apiKeyNameContextKey, name)
+
+	c.Next()
+
+	failed := writer.failed || c.Writer.Status() >= http.StatusBadRequest
+	recordAPIKeyRequest(name, c.FullPath(), c.ClientIP(), failed)
+}
@@ -0,0 +1,71 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"push-base-service/conf"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestMatchAPIKey(t *testing.T) {
+	keys := []apiKeyEntry{{name: "default", key: "k1"}, {name: "mobile", key: "k2"}}
+
+	if entry, ok := matchAPIKey(keys, "k2"); !ok || entry.name != "mobile" {
+		t.Fatalf("matchAPIKey(k2) = %+v, %v; want mobile", entry, ok)
+	}
+	if _, ok := matchAPIKey(keys, "k3"); ok {
+		t.Fatalf("未配置的 Key 不应匹配")
+	}
+	if _, ok := matchAPIKey(keys, ""); ok {
+		t.Fatalf("空 Key 不应匹配")
+	}
+}
+
+func TestAPIKeyMiddlewareTracksRequests(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	conf.APIKey = "default-key"
+	conf.APIKeys = []conf.APIKeyConf{{Name: "mobile", Key: "mobile-key"}, {Name: "idle", Key: "idle-key"}}
+	apiKeyStats = make(map[string]*APIKeyStats)
+	t.Cleanup(func() {
+		conf.APIKey, conf.APIKeys = "", nil
+		apiKeyStats = make(map[string]*APIKeyStats)
+	})
+
+	router := gin.New()
+	router.Use(APIKeyMiddleware())
+	router.GET("/ok", func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"code": 0}) })
+	router.GET("/fail", func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"code": 1}) })
+
+	send := func(path, key string) int {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("X-API-KEY", key)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder.Code
+	}
+	send("/ok", "mobile-key")
+	send("/fail", "mobile-key")
+	send("/ok", "default-key")
+	if code := send("/ok", "wrong-key"); code != http.StatusUnauthorized {
+		t.Fatalf("错误 Key 的状态码 = %d, want 401", code)
+	}
+
+	stats := make(map[string]*APIKeyStats)
+	for _, s := range GetAPIKeyStats() {
+		stats[s.Name] = s
+	}
+	if s := stats["mobile"]; s == nil || s.Requests != 2 || s.Errors != 1 || s.ErrorRate != 0.5 || s.LastPath != "/fail" {
+		t.Errorf("mobile 统计不符合预期: %+v", s)
+	}
+	if s := stats[DefaultAPIKeyName]; s == nil || s.Requests != 1 || s.Errors != 0 {
+		t.Errorf("default 统计不符合预期: %+v", s)
+	}
+	if s := stats["idle"]; s == nil || s.Requests != 0 || s.LastUsedAt != 0 || s.Fingerprint == "" {
+		t.Errorf("未使用的 Key 应列出且无请求: %+v", s)
+	}
+	if s := stats[UnknownAPIKeyName]; s == nil || s.Requests != 1 {
+		t.Errorf("unknown 统计不符合预期: %+v", s)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"push-base-service/controller/respond"
+	"push-base-service/models"
 	"push-base-service/tool"
 
 	"github.com/gin-gonic/gin"
@@ -19,6 +20,10 @@ var (
 	AuthErrParams2                 error = errors.New("Auth params is empty(public-key)")
 	AuthErrParamsVerifiedSignErr   error = errors.New("Auth verified signature err")
 	AuthErrParamsVerifiedSignWrong error = errors.New("Auth verified signature wrong")
+	AuthErrAPIKeyNotConfigured     error = errors.New("Auth api key is not configured")
+	AuthErrAPIKeyEmpty             error = errors.New("Auth params is empty(api-key)")
+	AuthErrAPIKeyWrong             error = errors.New("Auth api key wrong")
+	AuthErrAPIKeyScope             error = errors.New("Auth api key scope insufficient")
 )
 
 func AuthSignMiddleware() gin.HandlerFunc {
@@ -27,26 +32,26 @@ func AuthSignMiddleware() gin.HandlerFunc {
 
 		signatureStr := c.Request.Header.Get("X-Signature")
 		if signatureStr == "" {
-			c.JSON(http.StatusUnauthorized, respond.RespErr(AuthErrParams1, tool.MakeTimestamp()-t, respond.HttpsCodeErrorAuth))
+			respond.JSON(c, http.StatusUnauthorized, respond.RespErr(AuthErrParams1, tool.MakeTimestamp()-t, respond.HttpsCodeErrorAuth))
 			c.Abort()
 			return
 		}
 		publicKeyStr := c.Request.Header.Get("X-Public-Key")
 		if publicKeyStr == "" {
-			c.JSON(http.StatusUnauthorized, respond.RespErr(AuthErrParams2, tool.MakeTimestamp()-t, respond.HttpsCodeErrorAuth))
+			respond.JSON(c, http.StatusUnauthorized, respond.RespErr(AuthErrParams2, tool.MakeTimestamp()-t, respond.HttpsCodeErrorAuth))
 			c.Abort()
 			return
 		}
 
 		verified, err := VerifyTextSign(verifyMessage, signatureStr, publicKeyStr)
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, respond.RespErr(AuthErrParamsVerifiedSignErr, tool.MakeTimestamp()-t, respond.HttpsCodeErrorAuth))
+			respond.JSON(c, http.StatusUnauthorized, respond.RespErr(AuthErrParamsVerifiedSignErr, tool.MakeTimestamp()-t, respond.HttpsCodeErrorAuth))
 			c.Abort()
 			return
 		}
 
 		if !verified {
-			c.JSON(http.StatusUnauthorized, respond.RespErr(AuthErrParamsVerifiedSignWrong, tool.MakeTimestamp()-t, respond.HttpsCodeErrorAuth))
+			respond.JSON(c, http.StatusUnauthorized, respond.RespErr(AuthErrParamsVerifiedSignWrong, tool.MakeTimestamp()-t, respond.HttpsCodeErrorAuth))
 			c.Abort()
 			return
 		}
@@ -56,3 +61,85 @@ func AuthSignMiddleware() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// APIKeyMiddleware 校验 X-API-KEY 请求头，用于管理类接口（要求 admin 权限）
+func APIKeyMiddleware() gin.HandlerFunc {
+	return RequireScope(models.APIKeyScopeAdmin)
+}
+
+// RequireScope 校验 X-API-KEY 请求头，并要求 Key 具有指定的权限范围（admin 包含 write，write 包含 read）
+// 支持 api_key、api_keys 中配置的具名 Key 以及通过管理接口创建的 Key，并按 Key 记录请求数、失败数和最后使用时间
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		t := tool.MakeTimestamp()
+
+		entry, err := authorizeAPIKey(c.Request.Header.Get("X-API-KEY"), scope)
+		if err != nil {
+			status := http.StatusUnauthorized
+			if errors.Is(err, AuthErrAPIKeyScope) {
+				status = http.StatusForbidden
+			}
+			if entry != nil {
+				recordAPIKeyRequest(entry.name, c.FullPath(), c.ClientIP(), true)
+			}
+			respond.JSON(c, status, respond.RespErr(err, tool.MakeTimestamp()-t, respond.HttpsCodeErrorAuth))
+			c.Abort()
+			return
+		}
+
+		c.Set(apiKeyTenantContextKey, entry.tenant)
+		c.Set(apiKeyScopeContextKey, scope)
+		trackAPIKeyRequest(c, entry.name)
+	}
+}
+
+// authorizeAPIKey 查找 Key 并校验权限范围；Key 错误时返回 unknown 条目，权限不足时返回匹配的条目，便于调用方记录失败请求
+func authorizeAPIKey(apiKey, scope string) (*apiKeyEntry, error) {
+	entry, err := findAPIKey(apiKey)
+	if err != nil {
+		return entry, err
+	}
+
+	if !scopeAllowed(entry.scopes, scope) {
+		return entry, AuthErrAPIKeyScope
+	}
+	return entry, nil
+}
+
+// findAPIKey 在配置文件和管理接口创建的 Key 中查找，Key 错误时返回 unknown 条目
+func findAPIKey(apiKey string) (*apiKeyEntry, error) {
+	if apiKey == "" {
+		return nil, AuthErrAPIKeyEmpty
+	}
+
+	keys := configuredAPIKeys()
+	if entry, ok := matchAPIKey(keys, apiKey); ok {
+		return entry, nil
+	}
+	managed, err := lookupManagedAPIKey(apiKey)
+	if managed == nil {
+		if len(keys) == 0 && err != nil {
+			return nil, AuthErrAPIKeyNotConfigured
+		}
+		return &apiKeyEntry{name: UnknownAPIKeyName}, AuthErrAPIKeyWrong
+	}
+	return managed, nil
+}
+
+// AuthorizeAPIKey 校验 API Key 及其权限范围并返回 Key 名称和所属租户，供 gRPC 等非 HTTP 接口使用
+// 校验失败时记录一次失败请求；校验通过后由调用方在请求结束时调用 RecordAPIKeyRequest 记录结果
+func AuthorizeAPIKey(apiKey, scope, path, clientIP string) (string, string, error) {
+	entry, err := authorizeAPIKey(apiKey, scope)
+	if err != nil {
+		if entry != nil {
+			recordAPIKeyRequest(entry.name, path, clientIP, true)
+		}
+		return "", "", err
+	}
+	return entry.name, entry.tenant, nil
+}
+
+// RecordAPIKeyRequest 记录一次已通过 AuthorizeAPIKey 鉴权的请求结果
+func RecordAPIKeyRequest(name, path, clientIP string, failed bool) {
+	recordAPIKeyRequest(name, path, clientIP, failed)
+}
@@ -0,0 +1,64 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"push-base-service/conf"
+	"push-base-service/service/webhook_service"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestIngestSignatureMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	conf.IngestSecret = "ingest-secret"
+	t.Cleanup(func() { conf.IngestSecret = "" })
+
+	router := gin.New()
+	router.POST("/ingest/chat_message", IngestSignatureMiddleware(), func(c *gin.Context) {
+		body, _ := c.GetRawData()
+		c.String(http.StatusOK, string(body))
+	})
+
+	send := func(body, secret string, signedAt time.Time) *httptest.ResponseRecorder {
+		timestamp := strconv.FormatInt(signedAt.Unix(), 10)
+		req := httptest.NewRequest(http.MethodPost, "/ingest/chat_message", strings.NewReader(body))
+		req.Header.Set("X-Timestamp", timestamp)
+		req.Header.Set("X-Signature", webhook_service.Sign(secret, timestamp, []byte(body)))
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	body := `{"M":"WS_SERVER_NOTIFY_GROUP_CHAT","D":{}}`
+	signedAt := time.Now()
+	recorder := send(body, "ingest-secret", signedAt)
+	if recorder.Code != http.StatusOK || recorder.Body.String() != body {
+		t.Errorf("signed request: status = %d, body = %q", recorder.Code, recorder.Body.String())
+	}
+	if code := send(body, "ingest-secret", signedAt).Code; code != http.StatusUnauthorized {
+		t.Errorf("replayed signature: status = %d, want %d", code, http.StatusUnauthorized)
+	}
+	if code := send(body, "wrong-secret", time.Now()).Code; code != http.StatusUnauthorized {
+		t.Errorf("wrong secret: status = %d, want %d", code, http.StatusUnauthorized)
+	}
+	if code := send(`{"M":"other"}`, "ingest-secret", time.Now().Add(-time.Hour)).Code; code != http.StatusUnauthorized {
+		t.Errorf("expired timestamp: status = %d, want %d", code, http.StatusUnauthorized)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/ingest/chat_message", strings.NewReader(body))
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	if recorder.Code != http.StatusUnauthorized {
+		t.Errorf("unsigned request: status = %d, want %d", recorder.Code, http.StatusUnauthorized)
+	}
+
+	conf.IngestSecret = ""
+	if code := send(`{"M":"another"}`, "", time.Now()).Code; code != http.StatusUnauthorized {
+		t.Errorf("no secret configured: status = %d, want %d", code, http.StatusUnauthorized)
+	}
+}
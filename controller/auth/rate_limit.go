@@ -0,0 +1,132 @@
+package auth
+
+import (
+	"errors"
+	"log"
+	"math"
+	"net/http"
+	"push-base-service/conf"
+	"push-base-service/controller/respond"
+	"push-base-service/service/metrics_service"
+	"push-base-service/service/throttle_service"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 限流默认值
+const (
+	DefaultRateLimitWindow      = time.Second
+	DefaultRateLimitIPLimit     = 20
+	DefaultRateLimitAPIKeyLimit = 100
+)
+
+var AuthErrRateLimited error = errors.New("Too many requests, please retry later")
+
+// rateLimitedCounter 被限流拒绝的请求数
+var rateLimitedCounter = metrics_service.NewCounterVec(
+	"push_http_rate_limited_total", "Number of HTTP requests rejected by the rate limiter", "limiter")
+
+// httpRateLimiter 按客户端 IP 与 API Key 分别计数的令牌桶，/v1 与 /v2 共用
+type httpRateLimiter struct {
+	ip     *throttle_service.MemoryThrottler
+	apiKey *throttle_service.MemoryThrottler
+}
+
+var (
+	rateLimiter   *httpRateLimiter
+	rateLimiterMu sync.Mutex
+)
+
+// currentRateLimiter 返回当前限流器，首次使用或重新加载配置后按配置创建
+func currentRateLimiter() *httpRateLimiter {
+	rateLimiterMu.Lock()
+	defer rateLimiterMu.Unlock()
+
+	if rateLimiter == nil {
+		rateLimiter = newHTTPRateLimiter()
+	}
+	return rateLimiter
+}
+
+// ResetRateLimiter 丢弃现有的令牌桶，下一个请求按当前 rate_limit 配置重建（配置热加载时调用）
+func ResetRateLimiter() {
+	rateLimiterMu.Lock()
+	defer rateLimiterMu.Unlock()
+
+	rateLimiter = nil
+}
+
+// newHTTPRateLimiter 按配置创建限流器，limit 为负数时关闭对应维度
+func newHTTPRateLimiter() *httpRateLimiter {
+	window := DefaultRateLimitWindow
+	if d, err := time.ParseDuration(conf.RateLimitWindow); err == nil && d > 0 {
+		window = d
+	}
+
+	ipLimit := rateLimitValue(conf.RateLimitIPLimit, DefaultRateLimitIPLimit)
+	keyLimit := rateLimitValue(conf.RateLimitAPIKeyLimit, DefaultRateLimitAPIKeyLimit)
+
+	limiter := &httpRateLimiter{}
+	if ipLimit > 0 {
+		limiter.ip = throttle_service.NewBurstThrottler(ipLimit, window, conf.RateLimitIPBurst)
+	}
+	if keyLimit > 0 {
+		limiter.apiKey = throttle_service.NewBurstThrottler(keyLimit, window, conf.RateLimitAPIKeyBurst)
+	}
+	log.Printf("🚦 HTTP 限流已启用: 窗口=%v, IP=%d/窗口, API Key=%d/窗口", window, ipLimit, keyLimit)
+	return limiter
+}
+
+// rateLimitValue 0 表示使用默认值
+func rateLimitValue(value, defaultValue int) int {
+	if value == 0 {
+		return defaultValue
+	}
+	return value
+}
+
+// ConfigureTrustedProxies 只信任 trusted_proxies 中的反向代理转发的客户端 IP（X-Forwarded-For / X-Real-IP），
+// 未配置时按 IP 限流和 Key 统计使用连接的远端地址，防止客户端伪造请求头绕过按 IP 限流
+func ConfigureTrustedProxies(router *gin.Engine) error {
+	return router.SetTrustedProxies(conf.TrustedProxies)
+}
+
+// RateLimitMiddleware HTTP 接口限流：每个客户端 IP 一个令牌桶，携带有效 X-API-KEY 的请求另按 Key 计数
+// （无效的 Key 只计入所在 IP，不能用随机 Key 占用或绕过配额），任一维度超限时返回 429 和 Retry-After；
+// 未开启 rate_limit.enabled 时不做限制
+func RateLimitMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !conf.RateLimitEnabled {
+			c.Next()
+			return
+		}
+		limiter := currentRateLimiter()
+
+		if limiter.ip != nil && !rateLimitAllow(c, limiter.ip, "ip", c.ClientIP()) {
+			return
+		}
+		if apiKey := c.Request.Header.Get("X-API-KEY"); apiKey != "" && limiter.apiKey != nil {
+			if _, err := findAPIKey(apiKey); err == nil && !rateLimitAllow(c, limiter.apiKey, "api_key", apiKeyHash(apiKey)) {
+				return
+			}
+		}
+		c.Next()
+	}
+}
+
+// rateLimitAllow 从桶中取一个令牌，超限时响应 429 并中止请求
+func rateLimitAllow(c *gin.Context, throttler *throttle_service.MemoryThrottler, limiter, key string) bool {
+	decision, err := throttler.Allow(c.Request.Context(), key)
+	if err != nil || decision.Allowed {
+		return true
+	}
+
+	rateLimitedCounter.Inc(limiter)
+	c.Header("Retry-After", strconv.Itoa(int(math.Ceil(decision.RetryAfter.Seconds()))))
+	respond.JSON(c, http.StatusTooManyRequests, respond.RespAPIErr(respond.NewAPIError(http.StatusTooManyRequests, respond.ErrCodeRateLimited, AuthErrRateLimited), 0))
+	c.Abort()
+	return false
+}
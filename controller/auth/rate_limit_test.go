@@ -0,0 +1,162 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"push-base-service/conf"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRateLimitMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	conf.RateLimitEnabled = true
+	conf.RateLimitWindow = "1h"
+	conf.RateLimitIPLimit, conf.RateLimitIPBurst = 3, 0
+	conf.RateLimitAPIKeyLimit, conf.RateLimitAPIKeyBurst = 2, 0
+	conf.APIKeys = []conf.APIKeyConf{{Name: "partner", Key: "key-a"}}
+	ResetRateLimiter()
+	t.Cleanup(func() {
+		conf.RateLimitEnabled = false
+		conf.RateLimitWindow = ""
+		conf.RateLimitIPLimit, conf.RateLimitAPIKeyLimit = 0, 0
+		conf.APIKeys = nil
+		ResetRateLimiter()
+	})
+
+	router := gin.New()
+	router.GET("/push/get_user_token", RateLimitMiddleware(), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"code": 0})
+	})
+	send := func(ip, apiKey string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/push/get_user_token", nil)
+		req.RemoteAddr = ip + ":1234"
+		if apiKey != "" {
+			req.Header.Set("X-API-KEY", apiKey)
+		}
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	// 同一 API Key 跨 IP 共享配额
+	if code := send("10.0.0.1", "key-a").Code; code != http.StatusOK {
+		t.Fatalf("first keyed request: status = %d", code)
+	}
+	if code := send("10.0.0.2", "key-a").Code; code != http.StatusOK {
+		t.Fatalf("second keyed request: status = %d", code)
+	}
+	recorder := send("10.0.0.3", "key-a")
+	if recorder.Code != http.StatusTooManyRequests || recorder.Header().Get("Retry-After") == "" {
+		t.Errorf("keyed request over limit: status = %d, Retry-After = %q", recorder.Code, recorder.Header().Get("Retry-After"))
+	}
+
+	// 未通过校验的 Key 不单独计数，也不会耗尽有效 Key 的配额
+	for i := 0; i < 3; i++ {
+		if code := send("10.0.0.4", "forged-key").Code; code != http.StatusOK {
+			t.Fatalf("forged key request %d: status = %d, want only the ip bucket to apply", i, code)
+		}
+	}
+
+	// 单个 IP 超限后被拒绝，其他 IP 不受影响
+	for i := 0; i < 3; i++ {
+		send("10.0.0.9", "")
+	}
+	if code := send("10.0.0.9", "").Code; code != http.StatusTooManyRequests {
+		t.Errorf("ip over limit: status = %d, want %d", code, http.StatusTooManyRequests)
+	}
+	if code := send("10.0.0.8", "").Code; code != http.StatusOK {
+		t.Errorf("other ip: status = %d, want %d", code, http.StatusOK)
+	}
+	if got := rateLimitedCounter.Get("ip"); got < 1 {
+		t.Errorf("push_http_rate_limited_total{limiter=ip} = %v, want >= 1", got)
+	}
+}
+
+func TestRateLimitTrustedProxies(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	conf.RateLimitEnabled = true
+	conf.RateLimitWindow = "1h"
+	conf.RateLimitIPLimit, conf.RateLimitIPBurst = 1, 0
+	t.Cleanup(func() {
+		conf.RateLimitEnabled = false
+		conf.RateLimitWindow = ""
+		conf.RateLimitIPLimit = 0
+		conf.TrustedProxies = nil
+		ResetRateLimiter()
+	})
+
+	send := func(router *gin.Engine, forwardedFor string) int {
+		req := httptest.NewRequest(http.MethodGet, "/push/get_user_token", nil)
+		req.RemoteAddr = "10.0.2.1:1234"
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder.Code
+	}
+	newRouter := func() *gin.Engine {
+		ResetRateLimiter()
+		router := gin.New()
+		if err := ConfigureTrustedProxies(router); err != nil {
+			t.Fatalf("ConfigureTrustedProxies() failed, err: %v", err)
+		}
+		router.GET("/push/get_user_token", RateLimitMiddleware(), func(c *gin.Context) {
+			c.JSON(http.StatusOK, gin.H{"code": 0})
+		})
+		return router
+	}
+
+	// 未配置可信代理时忽略客户端伪造的 X-Forwarded-For，按连接地址限流
+	router := newRouter()
+	send(router, "192.0.2.1")
+	if code := send(router, "192.0.2.2"); code != http.StatusTooManyRequests {
+		t.Errorf("spoofed X-Forwarded-For: status = %d, want %d", code, http.StatusTooManyRequests)
+	}
+
+	// 请求来自可信代理时按转发的客户端 IP 分别限流
+	conf.TrustedProxies = []string{"10.0.2.0/24"}
+	router = newRouter()
+	send(router, "192.0.2.1")
+	if code := send(router, "192.0.2.2"); code != http.StatusOK {
+		t.Errorf("forwarded client behind trusted proxy: status = %d, want %d", code, http.StatusOK)
+	}
+}
+
+func TestResetRateLimiter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	conf.RateLimitEnabled = true
+	conf.RateLimitWindow = "1h"
+	conf.RateLimitIPLimit, conf.RateLimitIPBurst = 1, 0
+	ResetRateLimiter()
+	t.Cleanup(func() {
+		conf.RateLimitEnabled = false
+		conf.RateLimitWindow = ""
+		conf.RateLimitIPLimit = 0
+		ResetRateLimiter()
+	})
+
+	router := gin.New()
+	router.GET("/push/get_user_token", RateLimitMiddleware(), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"code": 0})
+	})
+	send := func() int {
+		req := httptest.NewRequest(http.MethodGet, "/push/get_user_token", nil)
+		req.RemoteAddr = "10.0.1.1:1234"
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder.Code
+	}
+
+	send()
+	if code := send(); code != http.StatusTooManyRequests {
+		t.Fatalf("ip over limit: status = %d, want %d", code, http.StatusTooManyRequests)
+	}
+
+	// 调高限额并重建限流器后立即按新配置放行
+	conf.RateLimitIPLimit = 5
+	ResetRateLimiter()
+	if code := send(); code != http.StatusOK {
+		t.Errorf("after reset: status = %d, want %d", code, http.StatusOK)
+	}
+}
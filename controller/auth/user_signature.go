@@ -0,0 +1,163 @@
+package auth
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"push-base-service/conf"
+	"push-base-service/controller/respond"
+	"push-base-service/models"
+	"push-base-service/tool"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultUserSignatureMaxSkew 签名时间戳与服务器时间允许的默认偏差，同时也是 nonce 的保留时长
+const DefaultUserSignatureMaxSkew = 5 * time.Minute
+
+var (
+	AuthErrUserSignParams    error = errors.New("Auth params is empty(signature/public-key/timestamp/nonce)")
+	AuthErrUserSignTimestamp error = errors.New("Auth signature timestamp expired")
+	AuthErrUserSignReplay    error = errors.New("Auth signature nonce already used")
+	AuthErrUserSignMetaID    error = errors.New("Auth public key does not match metaId")
+)
+
+// usedNonces 有效期内已使用的 nonce（公钥+nonce → 过期时间），防止签名被重放
+var usedNonces = &nonceCache{entries: make(map[string]time.Time)}
+
+// nonceCache 进程内 nonce 记录，多实例部署时各实例独立记录（时间戳窗口仍限制了重放范围）
+type nonceCache struct {
+	mu          sync.Mutex
+	entries     map[string]time.Time
+	lastCleanup time.Time
+}
+
+// use 记录 nonce，有效期内已使用过时返回 false
+func (n *nonceCache) use(key string, now time.Time, ttl time.Duration) bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if now.Sub(n.lastCleanup) > ttl {
+		for k, expireAt := range n.entries {
+			if now.After(expireAt) {
+				delete(n.entries, k)
+			}
+		}
+		n.lastCleanup = now
+	}
+
+	if expireAt, ok := n.entries[key]; ok && now.Before(expireAt) {
+		return false
+	}
+	n.entries[key] = now.Add(ttl)
+	return true
+}
+
+// UserSignatureMessage 返回客户端需要签名的内容：
+// 请求方法、路径、metaId、时间戳（Unix 秒）、nonce 和请求体 SHA256 十六进制，以换行连接
+func UserSignatureMessage(method, path, metaId, timestamp, nonce string, body []byte) string {
+	bodyHash := sha256.Sum256(body)
+	return strings.Join([]string{method, path, metaId, timestamp, nonce, hex.EncodeToString(bodyHash[:])}, "\n")
+}
+
+// MetaIDFromPublicKey 由公钥计算 metaId（地址的 SHA256 十六进制）
+func MetaIDFromPublicKey(publicKey string) string {
+	hash := sha256.Sum256([]byte(tool.ToAddress(publicKey)))
+	return hex.EncodeToString(hash[:])
+}
+
+// UserSignatureMiddleware 用户自助接口的签名校验（user_signature.enabled 默认开启）：
+// 修改某个 metaId 数据的请求必须由该用户的私钥签名（X-Public-Key、X-Signature、X-Timestamp、X-Nonce），
+// 时间戳超出允许偏差或 nonce 重复使用时拒绝；只有前置 RequireScope 已校验 write 权限的服务端调用不需要签名，
+// 仅携带 X-API-KEY 请求头而未经权限校验（如关闭 protect_user_endpoints 时）的请求仍需签名
+func UserSignatureMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !conf.UserSignatureEnabled || scopeAllowed([]string{c.GetString(apiKeyScopeContextKey)}, models.APIKeyScopeWrite) {
+			c.Next()
+			return
+		}
+		t := tool.MakeTimestamp()
+		reject := func(err error) {
+			respond.JSON(c, http.StatusUnauthorized, respond.RespErr(err, tool.MakeTimestamp()-t, respond.HttpsCodeErrorAuth))
+			c.Abort()
+		}
+
+		publicKey := c.Request.Header.Get("X-Public-Key")
+		signature := c.Request.Header.Get("X-Signature")
+		timestamp := c.Request.Header.Get("X-Timestamp")
+		nonce := c.Request.Header.Get("X-Nonce")
+		if publicKey == "" || signature == "" || timestamp == "" || nonce == "" {
+			reject(AuthErrUserSignParams)
+			return
+		}
+
+		maxSkew := DefaultUserSignatureMaxSkew
+		if skew, err := time.ParseDuration(conf.UserSignatureMaxSkew); err == nil && skew > 0 {
+			maxSkew = skew
+		}
+		signedAt, err := strconv.ParseInt(timestamp, 10, 64)
+		now := time.Now()
+		if err != nil || now.Sub(time.Unix(signedAt, 0)).Abs() > maxSkew {
+			reject(AuthErrUserSignTimestamp)
+			return
+		}
+
+		var body []byte
+		if c.Request.Body != nil {
+			if body, err = io.ReadAll(c.Request.Body); err != nil {
+				reject(AuthErrUserSignParams)
+				return
+			}
+			c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		}
+
+		metaId := requestMetaID(c, body)
+		if metaId == "" || MetaIDFromPublicKey(publicKey) != metaId {
+			reject(AuthErrUserSignMetaID)
+			return
+		}
+
+		message := UserSignatureMessage(c.Request.Method, c.Request.URL.Path, metaId, timestamp, nonce, body)
+		verified, err := tool.VerifySign(message, signature, publicKey)
+		if err != nil {
+			reject(AuthErrParamsVerifiedSignErr)
+			return
+		}
+		if !verified {
+			reject(AuthErrParamsVerifiedSignWrong)
+			return
+		}
+
+		// 签名有效后才记录 nonce，避免伪造请求占用合法客户端的 nonce
+		if !usedNonces.use(publicKey+":"+nonce, now, 2*maxSkew) {
+			reject(AuthErrUserSignReplay)
+			return
+		}
+
+		c.Set("publicKey", publicKey)
+		c.Next()
+	}
+}
+
+// requestMetaID 读取请求操作的 metaId：取自 JSON 请求体中的 metaId 字段（与处理函数绑定的来源一致），
+// 查询参数中同时带有不同的 metaId 时返回空，避免签名校验的 metaId 与实际操作的 metaId 不一致
+func requestMetaID(c *gin.Context, body []byte) string {
+	var payload struct {
+		MetaID string `json:"metaId"`
+	}
+	if len(body) == 0 || json.Unmarshal(body, &payload) != nil {
+		return ""
+	}
+	if query, ok := c.GetQuery("metaId"); ok && query != payload.MetaID {
+		return ""
+	}
+	return payload.MetaID
+}
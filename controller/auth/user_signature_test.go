@@ -0,0 +1,104 @@
+package auth
+
+import (
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"push-base-service/conf"
+	"push-base-service/models"
+	"push-base-service/tool"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/gin-gonic/gin"
+)
+
+func TestUserSignatureMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	conf.UserSignatureEnabled = true
+	conf.APIKeys = []conf.APIKeyConf{
+		{Name: "reader", Key: "reader-key", Scopes: []string{models.APIKeyScopeRead}},
+		{Name: "writer", Key: "writer-key", Scopes: []string{models.APIKeyScopeWrite}},
+	}
+	t.Cleanup(func() { conf.APIKeys = nil })
+
+	privateKeyHex := "8170940a65bda743704be89096ce6d292f052dbb897f4b7aa5d92aa1d0e64531"
+	privateKeyBytes, _ := hex.DecodeString(privateKeyHex)
+	privateKey, _ := btcec.PrivKeyFromBytes(privateKeyBytes)
+	publicKey := hex.EncodeToString(privateKey.PubKey().SerializeCompressed())
+	metaId := MetaIDFromPublicKey(publicKey)
+
+	router := gin.New()
+	ok := func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"code": 0}) }
+	router.POST("/push/add_blocked_chat", UserSignatureMiddleware(), ok)
+	router.POST("/scoped/read", RequireScope(models.APIKeyScopeRead), UserSignatureMiddleware(), ok)
+	router.POST("/scoped/write", RequireScope(models.APIKeyScopeWrite), UserSignatureMiddleware(), ok)
+
+	sendTo := func(target, body, nonce string, signedAt time.Time) int {
+		timestamp := strconv.FormatInt(signedAt.Unix(), 10)
+		signature, err := tool.SignMessage(UserSignatureMessage(http.MethodPost, "/push/add_blocked_chat", metaId, timestamp, nonce, []byte(body)), privateKeyHex)
+		if err != nil {
+			t.Fatalf("SignMessage() failed, err: %v", err)
+		}
+		req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+		req.Header.Set("X-Public-Key", publicKey)
+		req.Header.Set("X-Signature", signature)
+		req.Header.Set("X-Timestamp", timestamp)
+		req.Header.Set("X-Nonce", nonce)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder.Code
+	}
+	send := func(body, nonce string, signedAt time.Time) int {
+		return sendTo("/push/add_blocked_chat", body, nonce, signedAt)
+	}
+
+	body := `{"metaId":"` + metaId + `","chatId":"group1"}`
+	if code := send(body, "nonce-1", time.Now()); code != http.StatusOK {
+		t.Errorf("signed request: status = %d, want %d", code, http.StatusOK)
+	}
+	if code := send(body, "nonce-1", time.Now()); code != http.StatusUnauthorized {
+		t.Errorf("replayed nonce: status = %d, want %d", code, http.StatusUnauthorized)
+	}
+	if code := send(body, "nonce-2", time.Now().Add(-time.Hour)); code != http.StatusUnauthorized {
+		t.Errorf("expired timestamp: status = %d, want %d", code, http.StatusUnauthorized)
+	}
+	if code := send(`{"metaId":"someone-else","chatId":"group1"}`, "nonce-3", time.Now()); code != http.StatusUnauthorized {
+		t.Errorf("foreign metaId: status = %d, want %d", code, http.StatusUnauthorized)
+	}
+	// 查询参数带自己的 metaId、请求体（处理函数实际绑定的来源）带他人的 metaId
+	if code := sendTo("/push/add_blocked_chat?metaId="+metaId, `{"metaId":"victim","chatId":"group1"}`, "nonce-4", time.Now()); code != http.StatusUnauthorized {
+		t.Errorf("query/body metaId mismatch: status = %d, want %d", code, http.StatusUnauthorized)
+	}
+	if code := sendTo("/push/add_blocked_chat?metaId="+metaId, body, "nonce-5", time.Now()); code != http.StatusOK {
+		t.Errorf("matching query metaId: status = %d, want %d", code, http.StatusOK)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/push/add_blocked_chat", strings.NewReader(body))
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	if recorder.Code != http.StatusUnauthorized {
+		t.Errorf("unsigned request: status = %d, want %d", recorder.Code, http.StatusUnauthorized)
+	}
+
+	// 只有通过 write 权限校验的 API Key 调用免签名，仅携带 Key 请求头或只校验了 read 权限时仍需签名
+	withKey := func(path, key string) int {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set("X-API-KEY", key)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder.Code
+	}
+	if code := withKey("/push/add_blocked_chat", "writer-key"); code != http.StatusUnauthorized {
+		t.Errorf("unchecked key header: status = %d, want %d", code, http.StatusUnauthorized)
+	}
+	if code := withKey("/scoped/read", "reader-key"); code != http.StatusUnauthorized {
+		t.Errorf("read-scoped key: status = %d, want %d", code, http.StatusUnauthorized)
+	}
+	if code := withKey("/scoped/write", "writer-key"); code != http.StatusOK {
+		t.Errorf("write-scoped key: status = %d, want %d", code, http.StatusOK)
+	}
+}
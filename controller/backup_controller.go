@@ -0,0 +1,161 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"push-base-service/controller/request"
+	"push-base-service/controller/respond"
+	"push-base-service/models"
+	"push-base-service/service/backup_service"
+	"push-base-service/service/job_service"
+	"push-base-service/service/pebble_service"
+	"push-base-service/tool"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateBackup godoc
+// @Summary 创建 Pebble 备份
+// @Description 对所有集合创建一致的检查点并打包为 tar.gz。默认保存到服务端备份目录（backup.dir）并返回文件信息；download=true 时直接以附件形式下载，不落盘；async=true 时作为后台任务执行并立即返回任务信息，通过 /v1/admin/jobs/{id} 查询结果
+// @Tags Admin API
+// @Produce json
+// @Produce application/gzip
+// @Param download query bool false "是否直接下载备份归档"
+// @Param async query bool false "是否作为后台任务执行"
+// @Success 200 {object} respond.Response{data=models.BackupFile} "成功响应（async=true 时 data 为 models.Job）"
+// @Failure 401 {object} respond.Response "认证失败"
+// @Failure 500 {object} respond.Response "服务器内部错误"
+// @Router /v1/admin/backup [post]
+func CreateBackup(c *gin.Context) {
+	var t int64 = tool.MakeTimestamp()
+
+	if c.Query("download") == "true" {
+		name := fmt.Sprintf("pebble-backup-%s.tar.gz", time.Now().UTC().Format("20060102-150405"))
+		c.Header("Content-Type", "application/gzip")
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+		if _, err := pebble_service.Backup(c.Writer); err != nil {
+			// 已开始写入响应体时无法再返回 JSON 错误，只能中断连接
+			if !c.Writer.Written() {
+				c.Writer.Header().Del("Content-Type")
+				c.Writer.Header().Del("Content-Disposition")
+				respond.JSONP(c, http.StatusOK, respond.RespErr(err, tool.MakeTimestamp()-t, respond.HttpsCodeError))
+			} else {
+				c.Abort()
+			}
+		}
+		return
+	}
+
+	if c.Query("async") == "true" {
+		runner := job_service.GetGlobalRunner()
+		if runner == nil {
+			respond.JSONP(c, http.StatusOK, respond.RespErr(errors.New("推送中心未启用"), tool.MakeTimestamp()-t, respond.HttpsCodeError))
+			return
+		}
+		config := backup_service.GetGlobalConfig()
+		job, err := runner.Submit(models.JobTypeBackup, func(ctx context.Context, progress *job_service.Progress) (interface{}, error) {
+			progress.Report(0, "正在创建备份")
+			return backup_service.RunBackup(config)
+		})
+		if err != nil {
+			respond.JSONP(c, http.StatusOK, respond.RespErr(err, tool.MakeTimestamp()-t, respond.HttpsCodeError))
+			return
+		}
+		respond.JSONP(c, http.StatusOK, respond.RespSuccess(job, tool.MakeTimestamp()-t))
+		return
+	}
+
+	backup, err := backup_service.RunBackup(backup_service.GetGlobalConfig())
+	if err != nil {
+		respond.JSONP(c, http.StatusOK, respond.RespErr(err, tool.MakeTimestamp()-t, respond.HttpsCodeError))
+		return
+	}
+
+	respond.JSONP(c, http.StatusOK, respond.RespSuccess(backup, tool.MakeTimestamp()-t))
+}
+
+// GetBackups godoc
+// @Summary 获取备份列表
+// @Description 列出服务端备份目录中的备份文件，按时间从旧到新排序
+// @Tags Admin API
+// @Produce json
+// @Success 200 {object} respond.Response{data=[]models.BackupFile} "成功响应"
+// @Failure 401 {object} respond.Response "认证失败"
+// @Failure 500 {object} respond.Response "服务器内部错误"
+// @Router /v1/admin/get_backups [get]
+func GetBackups(c *gin.Context) {
+	var t int64 = tool.MakeTimestamp()
+
+	backups, err := pebble_service.ListBackupFiles(backup_service.GetGlobalConfig().Dir)
+	if err != nil {
+		respond.JSONP(c, http.StatusOK, respond.RespErr(err, tool.MakeTimestamp()-t, respond.HttpsCodeError))
+		return
+	}
+
+	respond.JSONP(c, http.StatusOK, respond.RespSuccess(backups, tool.MakeTimestamp()-t))
+}
+
+// RestoreBackup godoc
+// @Summary 从备份恢复
+// @Description 从 tar.gz 备份归档恢复所有集合。可通过 multipart 表单字段 file 上传归档，或以 JSON 传入服务端备份目录中的文件名。恢复会替换整个数据目录（原目录保留为 <db_path>.pre-restore-<时间戳>），并清空令牌缓存
+// @Tags Admin API
+// @Accept json
+// @Accept multipart/form-data
+// @Produce json
+// @Param request body request.RestoreBackupReq false "备份文件名（JSON 方式）"
+// @Param file formData file false "备份归档（上传方式）"
+// @Success 200 {object} respond.Response{data=models.BackupManifest} "成功响应"
+// @Failure 400 {object} respond.Response "参数错误"
+// @Failure 401 {object} respond.Response "认证失败"
+// @Failure 500 {object} respond.Response "服务器内部错误"
+// @Router /v1/admin/restore [post]
+func RestoreBackup(c *gin.Context) {
+	var t int64 = tool.MakeTimestamp()
+
+	// 上传方式
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			respond.JSONP(c, http.StatusOK, respond.RespErr(errors.New("缺少备份文件 file"), tool.MakeTimestamp()-t, respond.HttpsCodeError))
+			return
+		}
+		file, err := fileHeader.Open()
+		if err != nil {
+			respond.JSONP(c, http.StatusOK, respond.RespErr(err, tool.MakeTimestamp()-t, respond.HttpsCodeError))
+			return
+		}
+		defer file.Close()
+
+		manifest, err := pebble_service.Restore(file)
+		if err != nil {
+			respond.JSONP(c, http.StatusOK, respond.RespErr(err, tool.MakeTimestamp()-t, respond.HttpsCodeError))
+			return
+		}
+		respond.JSONP(c, http.StatusOK, respond.RespSuccess(manifest, tool.MakeTimestamp()-t))
+		return
+	}
+
+	// 备份目录中的文件
+	var requestModel *request.RestoreBackupReq
+	if c.ShouldBindJSON(&requestModel) == nil {
+		if filepath.Base(requestModel.Name) != requestModel.Name {
+			respond.JSONP(c, http.StatusOK, respond.RespErr(errors.New("备份文件名不合法"), tool.MakeTimestamp()-t, respond.HttpsCodeError))
+			return
+		}
+
+		manifest, err := pebble_service.RestoreFromFile(filepath.Join(backup_service.GetGlobalConfig().Dir, requestModel.Name))
+		if err != nil {
+			respond.JSONP(c, http.StatusOK, respond.RespErr(err, tool.MakeTimestamp()-t, respond.HttpsCodeError))
+			return
+		}
+		respond.JSONP(c, http.StatusOK, respond.RespSuccess(manifest, tool.MakeTimestamp()-t))
+		return
+	}
+
+	respond.JSONP(c, http.StatusInternalServerError, respond.RespErr(errors.New("参数错误"), tool.MakeTimestamp()-t, respond.HttpsCodeError))
+}
@@ -0,0 +1,72 @@
+package controller
+
+import (
+	_ "embed"
+	"net/http"
+	"push-base-service/controller/respond"
+	"push-base-service/models"
+	"push-base-service/service/event_service"
+	pushcenter "push-base-service/service/push_center"
+	"push-base-service/service/push_service"
+	"push-base-service/service/socket_client_service"
+	"push-base-service/service/webhook_service"
+	"push-base-service/tool"
+
+	"github.com/gin-gonic/gin"
+)
+
+// dashboardRecentEvents 看板展示的最近推送和失败条数
+const dashboardRecentEvents = 50
+
+// dashboardPage 内置管理看板页面，数据全部通过管理接口获取
+//
+//go:embed dashboard/index.html
+var dashboardPage []byte
+
+// AdminDashboard 返回内置管理看板页面（需开启 admin_dashboard.enabled）
+// 页面本身不含数据，各面板使用浏览器中输入的 admin 权限 API Key 调用管理接口
+func AdminDashboard(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+	c.Data(http.StatusOK, "text/html; charset=utf-8", dashboardPage)
+}
+
+// GetDashboard godoc
+// @Summary 获取管理看板数据
+// @Description 汇总管理看板展示的运行状态：各上游 Socket 连接状态（socket）、推送积压和 Webhook 投递队列长度（queue，推送中心未启用时 push 为 null）、各推送提供者的发送统计（providers）、最近的推送成功（recentPushes）和失败（recentFailures）事件，每类最多 50 条，按时间倒序
+// @Tags Admin API
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} respond.Response "成功响应"
+// @Failure 401 {object} respond.Response "认证失败"
+// @Router /v1/admin/dashboard [get]
+func GetDashboard(c *gin.Context) {
+	var t int64 = tool.MakeTimestamp()
+
+	socketStats := map[string]interface{}{
+		"enabled": false,
+	}
+	if manager := socket_client_service.GetGlobalManager(); manager != nil {
+		socketStats["enabled"] = true
+		socketStats["upstreams"] = manager.GetUpstreamHealth()
+	}
+
+	queueStats := map[string]interface{}{
+		"push": nil,
+	}
+	if pc := pushcenter.GetGlobalPushCenter(); pc != nil {
+		queueStats["push"] = pc.QueueStatus()
+	}
+	if dispatcher := webhook_service.GetGlobalDispatcher(); dispatcher != nil {
+		queueStats["webhook"] = dispatcher.QueueLength()
+	}
+
+	responseData := map[string]interface{}{
+		"socket":         socketStats,
+		"queue":          queueStats,
+		"providers":      push_service.GetProviderStats(),
+		"recentPushes":   event_service.Recent(models.PipelineEventSent, dashboardRecentEvents),
+		"recentFailures": event_service.Recent(models.PipelineEventFailed, dashboardRecentEvents),
+	}
+
+	respond.JSONP(c, http.StatusOK, respond.RespSuccess(responseData, tool.MakeTimestamp()-t))
+}
@@ -0,0 +1,131 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"push-base-service/controller/respond"
+	"push-base-service/service/pebble_service"
+	pushcenter "push-base-service/service/push_center"
+	"push-base-service/tool"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetDeliveryStatus godoc
+// @Summary 获取消息投递状态
+// @Description 按 PinId 获取消息对每个接收用户各平台的投递状态：是否尝试推送、推送平台受理状态和最终回执（需开启 push_center.delivery.enabled）
+// @Tags Push API
+// @Produce json
+// @Security ApiKeyAuth
+// @Param pinId query string true "消息PIN ID"
+// @Success 200 {object} respond.Response{data=[]models.DeliveryRecord} "成功响应"
+// @Failure 400 {object} respond.Response "参数错误"
+// @Failure 401 {object} respond.Response "认证失败"
+// @Failure 404 {object} respond.Response "没有该消息的投递记录"
+// @Failure 500 {object} respond.Response "服务器内部错误"
+// @Router /v1/push/delivery_status [get]
+func GetDeliveryStatus(c *gin.Context) {
+	var t int64 = tool.MakeTimestamp()
+
+	pinId := c.Query("pinId")
+	if pinId == "" {
+		respond.Fail(c, respond.MissingParam("pinId"), tool.MakeTimestamp()-t)
+		return
+	}
+
+	records, err := pebble_service.GetDeliveryRecords(pinId)
+	if err != nil {
+		respond.Fail(c, err, tool.MakeTimestamp()-t)
+		return
+	}
+	if len(records) == 0 {
+		respond.Fail(c, respond.NotFound(fmt.Errorf("没有该消息的投递记录: %s", pinId)), tool.MakeTimestamp()-t)
+		return
+	}
+
+	respond.JSONP(c, http.StatusOK, respond.RespSuccess(records, tool.MakeTimestamp()-t))
+}
+
+// GetUserPushHistory godoc
+// @Summary 获取用户推送历史
+// @Description 按时间倒序获取发给该用户的外发通知审计记录：标题、内容哈希、推送平台、推送结果、关联 PinId 和推送时间，用于排查用户未收到推送的原因（需开启 push_center.audit.enabled）。翻页时以上一页最后一条的 timestamp 作为 before
+// @Tags Push API
+// @Produce json
+// @Security ApiKeyAuth
+// @Param metaId query string true "用户MetaID"
+// @Param before query int false "只返回推送时间早于该值的记录（Unix 毫秒）"
+// @Param limit query int false "返回条数，默认且最多 500"
+// @Success 200 {object} respond.Response{data=[]models.PushAuditEntry} "成功响应"
+// @Failure 400 {object} respond.Response "参数错误"
+// @Failure 401 {object} respond.Response "认证失败"
+// @Failure 500 {object} respond.Response "服务器内部错误"
+// @Router /v1/push/user_push_history [get]
+func GetUserPushHistory(c *gin.Context) {
+	var t int64 = tool.MakeTimestamp()
+
+	metaId := c.Query("metaId")
+	if metaId == "" {
+		respond.Fail(c, respond.MissingParam("metaId"), tool.MakeTimestamp()-t)
+		return
+	}
+	before, err := strconv.ParseInt(c.DefaultQuery("before", "0"), 10, 64)
+	if err != nil {
+		respond.Fail(c, respond.InvalidField("before", fmt.Errorf("before 无效: %s（Unix 毫秒）", c.Query("before"))), tool.MakeTimestamp()-t)
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "0"))
+	if err != nil {
+		respond.Fail(c, respond.InvalidField("limit", fmt.Errorf("limit 无效: %s", c.Query("limit"))), tool.MakeTimestamp()-t)
+		return
+	}
+
+	entries, err := pebble_service.GetPushAudits(metaId, before, limit)
+	if err != nil {
+		respond.Fail(c, err, tool.MakeTimestamp()-t)
+		return
+	}
+
+	respond.JSONP(c, http.StatusOK, respond.RespSuccess(entries, tool.MakeTimestamp()-t))
+}
+
+// 投递统计的时间范围
+const (
+	defaultDeliveryBreakdownHours = 24
+	maxDeliveryBreakdownHours     = 7 * 24 // 投递记录默认保留 7 天
+)
+
+// GetDeliveryBreakdown godoc
+// @Summary 按客户端版本统计投递送达率
+// @Description 将最近 hours 小时的投递记录按推送平台、客户端版本和系统主版本分组，统计推送数、受理失败、回执送达/失败/等待/不可用数以及送达率（已送达 / (已送达 + 回执失败 + 受理失败)），用于发现某个客户端版本的推送异常（如令牌刷新失效）。客户端版本和系统版本取自推送时接收设备上报的设备信息，需开启 push_center.delivery.enabled。同样的数据以 push_delivery_outcomes_total 指标暴露在 /metrics
+// @Tags Admin API
+// @Produce json
+// @Security ApiKeyAuth
+// @Param hours query int false "统计最近多少小时（默认24，最大168）"
+// @Param platform query string false "只统计该推送平台"
+// @Success 200 {object} respond.Response{data=[]models.DeliveryBreakdown} "成功响应"
+// @Failure 401 {object} respond.Response "认证失败"
+// @Failure 500 {object} respond.Response "服务器内部错误"
+// @Router /v1/admin/delivery_breakdown [get]
+func GetDeliveryBreakdown(c *gin.Context) {
+	var t int64 = tool.MakeTimestamp()
+
+	hours := defaultDeliveryBreakdownHours
+	if hoursStr := c.Query("hours"); hoursStr != "" {
+		if h, err := strconv.Atoi(hoursStr); err == nil && h > 0 {
+			hours = h
+		}
+	}
+	if hours > maxDeliveryBreakdownHours {
+		hours = maxDeliveryBreakdownHours
+	}
+
+	breakdown, err := pushcenter.DeliveryBreakdown(time.Now().Add(-time.Duration(hours)*time.Hour), c.Query("platform"))
+	if err != nil {
+		respond.JSONP(c, http.StatusOK, respond.RespErr(err, tool.MakeTimestamp()-t, respond.HttpsCodeError))
+		return
+	}
+
+	respond.JSONP(c, http.StatusOK, respond.RespSuccess(breakdown, tool.MakeTimestamp()-t))
+}
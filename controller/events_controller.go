@@ -0,0 +1,82 @@
+package controller
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"push-base-service/controller/respond"
+	"push-base-service/models"
+	"push-base-service/service/event_service"
+	"push-base-service/tool"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// eventsHeartbeatInterval SSE 心跳间隔，避免代理因连接空闲而断开
+const eventsHeartbeatInterval = 15 * time.Second
+
+// pipelineEventTypes 可订阅的流水线事件类型
+var pipelineEventTypes = []string{
+	models.PipelineEventMessageReceived,
+	models.PipelineEventFiltered,
+	models.PipelineEventSent,
+	models.PipelineEventFailed,
+	models.PipelineEventReceiptUpdated,
+}
+
+// StreamPipelineEvents godoc
+// @Summary 订阅推送流水线实时事件
+// @Description 以 Server-Sent Events 推送流水线实时事件，供运维看板展示实时流量而无需轮询日志：message_received（收到消息，count 为接收用户数）、filtered（用户被跳过或消息被去重，reason 为原因）、sent / failed（推送平台受理或失败，包括发送接口的推送）、receipt_updated（查询到投递回执，reason 为回执状态，需开启 delivery）。
+// @Description 每个事件的 event 字段为事件类型，data 为 JSON；每 15 秒发送一次心跳注释。客户端处理过慢时丢弃事件，不影响推送。
+// @Tags Admin API
+// @Produce text/event-stream
+// @Security ApiKeyAuth
+// @Param types query string false "只订阅这些类型的事件，逗号分隔，默认全部"
+// @Success 200 {object} models.PipelineEvent "事件流"
+// @Failure 400 {object} respond.Response "参数错误"
+// @Failure 401 {object} respond.Response "认证失败"
+// @Router /v1/admin/events [get]
+func StreamPipelineEvents(c *gin.Context) {
+	var t int64 = tool.MakeTimestamp()
+
+	var types []string
+	if raw := c.Query("types"); raw != "" {
+		for _, eventType := range strings.Split(raw, ",") {
+			eventType = strings.TrimSpace(eventType)
+			if !slices.Contains(pipelineEventTypes, eventType) {
+				respond.Fail(c, respond.InvalidField("types", fmt.Errorf("未知的事件类型: %s（可选 %s）", eventType, strings.Join(pipelineEventTypes, "、"))), tool.MakeTimestamp()-t)
+				return
+			}
+			types = append(types, eventType)
+		}
+	}
+
+	sub := event_service.Subscribe(event_service.DefaultBuffer, types...)
+	defer event_service.Unsubscribe(sub)
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // 关闭 nginx 缓冲，事件即时送达
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(eventsHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case <-heartbeat.C:
+			_, err := io.WriteString(w, ": ping\n\n")
+			return err == nil
+		case event := <-sub.Events():
+			c.SSEvent(event.Type, respond.Payload(c, event))
+			return true
+		}
+	})
+}
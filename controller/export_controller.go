@@ -0,0 +1,135 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"push-base-service/controller/respond"
+	"push-base-service/models"
+	"push-base-service/service/pebble_service"
+	"push-base-service/tool"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxImportSize 导入文件大小上限
+const maxImportSize = 256 << 20
+
+// ExportData godoc
+// @Summary 导出用户数据
+// @Description 导出用户令牌、设备和屏蔽聊天，用于在环境之间（如 testnet/mainnet）迁移数据。JSON 格式默认导出全部数据集；CSV 格式每次导出一个数据集，需指定 dataset。结果以附件形式下载
+// @Tags Admin API
+// @Produce json
+// @Produce text/csv
+// @Param format query string false "导出格式：json（默认）或 csv"
+// @Param dataset query string false "数据集：tokens、devices、blocked_chats（CSV 必填，JSON 可选，多个用逗号分隔）"
+// @Success 200 {object} models.DataExport "导出内容"
+// @Failure 401 {object} respond.Response "认证失败"
+// @Failure 500 {object} respond.Response "服务器内部错误"
+// @Router /v1/admin/export [get]
+func ExportData(c *gin.Context) {
+	var t int64 = tool.MakeTimestamp()
+
+	format := c.DefaultQuery("format", "json")
+	var datasets []string
+	if dataset := c.Query("dataset"); dataset != "" {
+		datasets = strings.Split(dataset, ",")
+	}
+	if format == "csv" && len(datasets) != 1 {
+		respond.JSONP(c, http.StatusOK, respond.RespErr(errors.New("CSV 导出需要指定一个 dataset"), tool.MakeTimestamp()-t, respond.HttpsCodeError))
+		return
+	}
+	if format != "json" && format != "csv" {
+		respond.JSONP(c, http.StatusOK, respond.RespErr(fmt.Errorf("不支持的导出格式: %s", format), tool.MakeTimestamp()-t, respond.HttpsCodeError))
+		return
+	}
+
+	data, err := pebble_service.ExportData(datasets)
+	if err != nil {
+		respond.JSONP(c, http.StatusOK, respond.RespErr(err, tool.MakeTimestamp()-t, respond.HttpsCodeError))
+		return
+	}
+
+	name := "push-export-" + time.Now().UTC().Format("20060102-150405")
+	if format == "csv" {
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name+"-"+datasets[0]+".csv"))
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		if err := pebble_service.WriteDatasetCSV(c.Writer, datasets[0], data); err != nil {
+			c.Abort()
+		}
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name+".json"))
+	c.JSON(http.StatusOK, data)
+}
+
+// ImportData godoc
+// @Summary 导入用户数据
+// @Description 批量导入用户令牌、设备和屏蔽聊天（如从旧推送系统或其他环境迁移）。请求体可以是 export 接口导出的 JSON，或单个数据集的 CSV（需指定 dataset），也可以通过 multipart 表单字段 file 上传。令牌按平台合并到现有用户，同一令牌会从原用户转移；屏蔽聊天合并，已过期的临时静音会被跳过
+// @Tags Admin API
+// @Accept json
+// @Accept text/csv
+// @Accept multipart/form-data
+// @Produce json
+// @Param format query string false "导入格式：json（默认）或 csv"
+// @Param dataset query string false "数据集（CSV 必填）：tokens、devices、blocked_chats"
+// @Param file formData file false "导入文件（上传方式）"
+// @Success 200 {object} respond.Response{data=models.ImportResult} "成功响应"
+// @Failure 401 {object} respond.Response "认证失败"
+// @Failure 500 {object} respond.Response "服务器内部错误"
+// @Router /v1/admin/import [post]
+func ImportData(c *gin.Context) {
+	var t int64 = tool.MakeTimestamp()
+
+	format := c.DefaultQuery("format", "json")
+	dataset := c.Query("dataset")
+
+	var body io.Reader = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize)
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			respond.JSONP(c, http.StatusOK, respond.RespErr(errors.New("缺少导入文件 file"), tool.MakeTimestamp()-t, respond.HttpsCodeError))
+			return
+		}
+		file, err := fileHeader.Open()
+		if err != nil {
+			respond.JSONP(c, http.StatusOK, respond.RespErr(err, tool.MakeTimestamp()-t, respond.HttpsCodeError))
+			return
+		}
+		defer file.Close()
+		body = file
+	}
+
+	var data *models.DataExport
+	switch format {
+	case "json":
+		data = &models.DataExport{}
+		if err := json.NewDecoder(body).Decode(data); err != nil {
+			respond.JSONP(c, http.StatusOK, respond.RespErr(fmt.Errorf("解析 JSON 失败: %w", err), tool.MakeTimestamp()-t, respond.HttpsCodeError))
+			return
+		}
+	case "csv":
+		parsed, err := pebble_service.ReadDatasetCSV(body, dataset)
+		if err != nil {
+			respond.JSONP(c, http.StatusOK, respond.RespErr(err, tool.MakeTimestamp()-t, respond.HttpsCodeError))
+			return
+		}
+		data = parsed
+	default:
+		respond.JSONP(c, http.StatusOK, respond.RespErr(fmt.Errorf("不支持的导入格式: %s", format), tool.MakeTimestamp()-t, respond.HttpsCodeError))
+		return
+	}
+
+	result, err := pebble_service.ImportData(data)
+	if err != nil {
+		respond.JSONP(c, http.StatusOK, respond.RespErr(err, tool.MakeTimestamp()-t, respond.HttpsCodeError))
+		return
+	}
+
+	respond.JSONP(c, http.StatusOK, respond.RespSuccess(result, tool.MakeTimestamp()-t))
+}
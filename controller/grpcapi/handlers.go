@@ -0,0 +1,236 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"push-base-service/controller/grpcapi/pushpb"
+	"push-base-service/controller/respond"
+	"push-base-service/models"
+	"push-base-service/service/pebble_service"
+	"push-base-service/service/push_service"
+	"push-base-service/service/storage_service"
+	"push-base-service/service/translate_service"
+	"time"
+)
+
+// sendTimeout SendToUsers 的推送超时时间，与 HTTP 发送接口一致
+const sendTimeout = 30 * time.Second
+
+// errPushCenterDisabled 推送中心未启用时发送和订阅类方法返回的错误
+var errPushCenterDisabled = respond.Unavailable(errors.New("推送中心未启用"))
+
+// SetUserToken 为用户登记推送令牌，并记录设备信息和最近活跃时间
+func (s *Server) SetUserToken(ctx context.Context, req *pushpb.SetUserTokenRequest) (*pushpb.SetUserTokenResponse, error) {
+	switch {
+	case req.MetaId == "":
+		return nil, respond.MissingParam("meta_id")
+	case req.Platform == "":
+		return nil, respond.MissingParam("platform")
+	case req.Token == "":
+		return nil, respond.MissingParam("token")
+	case req.TenantId != "":
+		// 租户归属决定投递事件回调给谁，只能由管理接口 set_user_tenant 设置
+		return nil, respond.InvalidField("tenant_id", errors.New("不能通过登记令牌设置租户，请使用管理接口 /v1/admin/set_user_tenant"))
+	}
+	if req.Timezone != "" {
+		if _, err := time.LoadLocation(req.Timezone); err != nil {
+			return nil, respond.InvalidField("timezone", fmt.Errorf("timezone 无效: %s（IANA 时区名，如 Asia/Shanghai）", req.Timezone))
+		}
+	}
+
+	if err := storage_service.SetUserToken(req.MetaId, req.Platform, req.Token); err != nil {
+		return nil, err
+	}
+
+	metadata := models.DeviceMetadata{
+		AppVersion:  req.AppVersion,
+		OSVersion:   req.OsVersion,
+		DeviceModel: req.DeviceModel,
+		Locale:      translate_service.NormalizeLocale(req.Locale),
+		Timezone:    req.Timezone,
+	}
+	if _, err := pebble_service.SetDeviceMetadata(req.Token, req.Platform, req.MetaId, metadata); err != nil {
+		return nil, err
+	}
+	return &pushpb.SetUserTokenResponse{}, nil
+}
+
+// SendToUsers 向指定用户发送推送通知，幂等键与 HTTP 发送接口共用，相同幂等键只推送一次
+func (s *Server) SendToUsers(ctx context.Context, req *pushpb.SendToUsersRequest) (*pushpb.SendToUsersResponse, error) {
+	switch {
+	case len(req.MetaIds) == 0:
+		return nil, respond.MissingParam("meta_ids")
+	case req.Title == "":
+		return nil, respond.MissingParam("title")
+	case req.Body == "":
+		return nil, respond.MissingParam("body")
+	}
+
+	pushManager := push_service.GetGlobalManager()
+	if pushManager == nil {
+		return nil, errPushCenterDisabled
+	}
+
+	notification := &push_service.PushNotification{
+		Title:      req.Title,
+		Body:       req.Body,
+		Sound:      req.Sound,
+		Priority:   req.Priority,
+		CollapseID: req.CollapseId,
+		ThreadID:   req.ThreadId,
+		DryRun:     req.DryRun,
+		TenantID:   callerTenant(ctx),
+	}
+	if len(req.Data) > 0 {
+		notification.Data = make(map[string]interface{}, len(req.Data))
+		for key, value := range req.Data {
+			notification.Data[key] = value
+		}
+	}
+	if notification.Sound == "" {
+		notification.Sound = "default"
+	}
+
+	// 幂等检查：已处理过的请求直接返回首次结果；演练请求不占用幂等键
+	var storeKey string
+	if req.IdempotencyKey != "" && !req.DryRun {
+		storeKey = "api:" + req.IdempotencyKey
+		record, claimed, err := pebble_service.ClaimIdempotencyKey(storeKey, "grpc")
+		if err != nil {
+			return nil, err
+		}
+		if !claimed {
+			response := sendResponse(record.Result)
+			response.Duplicate = true
+			return response, nil
+		}
+	}
+
+	sendCtx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+	defer cancel()
+
+	batchResult, err := pushManager.SendCustomNotificationToUsers(sendCtx, req.MetaIds, notification)
+	if err == nil && batchResult.SuccessCount == 0 && batchResult.FailureCount > 0 && batchResult.FallbackCount == 0 {
+		// 所有推送平台调用都失败
+		err = respond.ProviderError(firstPushError(batchResult.Results))
+	}
+	if err != nil {
+		// 推送失败时释放幂等键，允许调用方重试
+		if storeKey != "" {
+			if releaseErr := pebble_service.ReleaseIdempotencyKey(storeKey); releaseErr != nil {
+				log.Printf("⚠️ 释放幂等键失败: %v", releaseErr)
+			}
+		}
+		return nil, err
+	}
+
+	result := map[string]interface{}{
+		"totalUsers":     batchResult.TotalUsers,
+		"totalPlatforms": batchResult.TotalPlatforms,
+		"successCount":   batchResult.SuccessCount,
+		"failureCount":   batchResult.FailureCount,
+		"quotaRejected":  batchResult.QuotaRejected,
+		"downgraded":     batchResult.Downgraded,
+		"dryRun":         batchResult.DryRun,
+	}
+	if storeKey != "" {
+		if err := pebble_service.CompleteIdempotencyKey(storeKey, result); err != nil {
+			log.Printf("⚠️ 记录幂等键结果失败: %v", err)
+		}
+	}
+	return sendResponse(result), nil
+}
+
+// GetDeliveryStatus 按 PinId 查询消息对每个接收用户各平台的投递状态
+func (s *Server) GetDeliveryStatus(ctx context.Context, req *pushpb.GetDeliveryStatusRequest) (*pushpb.GetDeliveryStatusResponse, error) {
+	if req.PinId == "" {
+		return nil, respond.MissingParam("pin_id")
+	}
+
+	records, err := pebble_service.GetDeliveryRecords(req.PinId)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, respond.NotFound(fmt.Errorf("没有该消息的投递记录: %s", req.PinId))
+	}
+
+	response := &pushpb.GetDeliveryStatusResponse{Records: make([]*pushpb.DeliveryRecord, 0, len(records))}
+	for _, record := range records {
+		response.Records = append(response.Records, &pushpb.DeliveryRecord{
+			PinId:         record.PinID,
+			MetaId:        record.MetaID,
+			Platform:      record.Platform,
+			Attempted:     record.Attempted,
+			SkipReason:    record.SkipReason,
+			TicketStatus:  record.TicketStatus,
+			ReceiptId:     record.ReceiptID,
+			ReceiptStatus: record.ReceiptStatus,
+			Error:         record.Error,
+			AppVersion:    record.AppVersion,
+			OsVersion:     record.OSVersion,
+			CreatedAt:     record.CreatedAt,
+			UpdatedAt:     record.UpdatedAt,
+		})
+	}
+	return response, nil
+}
+
+// StreamPushResults 订阅推送结果，直到客户端取消或服务停止
+func (s *Server) StreamPushResults(req *pushpb.StreamPushResultsRequest, stream pushpb.PushService_StreamPushResultsServer) error {
+	if push_service.GetGlobalManager() == nil {
+		return errPushCenterDisabled
+	}
+
+	sub := s.hub.subscribe(req.MetaIds, req.FailuresOnly)
+	defer s.hub.unsubscribe(sub)
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-s.hub.closed:
+			return nil
+		case result := <-sub.results:
+			if err := stream.Send(result); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// sendResponse 将推送结果摘要转换为响应，摘要可能来自幂等记录（JSON 反序列化后数字为 float64）
+func sendResponse(result map[string]interface{}) *pushpb.SendToUsersResponse {
+	count := func(key string) int32 {
+		switch value := result[key].(type) {
+		case int:
+			return int32(value)
+		case float64:
+			return int32(value)
+		}
+		return 0
+	}
+	dryRun, _ := result["dryRun"].(bool)
+
+	return &pushpb.SendToUsersResponse{
+		TotalUsers:     count("totalUsers"),
+		TotalPlatforms: count("totalPlatforms"),
+		SuccessCount:   count("successCount"),
+		FailureCount:   count("failureCount"),
+		QuotaRejected:  count("quotaRejected"),
+		Downgraded:     count("downgraded"),
+		DryRun:         dryRun,
+	}
+}
+
+// firstPushError 返回第一个失败的推送结果的错误
+func firstPushError(results []*push_service.PushResult) error {
+	for _, result := range results {
+		if !result.Success && result.Error != nil {
+			return fmt.Errorf("推送平台 %s 调用失败: %w", result.Platform, result.Error)
+		}
+	}
+	return errors.New("推送平台调用失败")
+}
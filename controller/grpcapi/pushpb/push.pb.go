@@ -0,0 +1,932 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        v5.29.3
+// source: push/v1/push.proto
+
+// 推送基础服务 gRPC 接口，与 HTTP 接口共用同一套存储和推送流程
+// 修改后在仓库根目录执行 go generate ./controller/grpcapi 重新生成 Go 代码
+
+package pushpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type SetUserTokenRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MetaId        string                 `protobuf:"bytes,1,opt,name=meta_id,json=metaId,proto3" json:"meta_id,omitempty"`                // 用户唯一标识
+	Platform      string                 `protobuf:"bytes,2,opt,name=platform,proto3" json:"platform,omitempty"`                          // 推送平台：expo、email、macos、windows
+	Token         string                 `protobuf:"bytes,3,opt,name=token,proto3" json:"token,omitempty"`                                // 推送令牌
+	TenantId      string                 `protobuf:"bytes,4,opt,name=tenant_id,json=tenantId,proto3" json:"tenant_id,omitempty"`          // 已废弃：租户由管理接口 set_user_tenant 设置，非空时返回参数错误
+	AppVersion    string                 `protobuf:"bytes,5,opt,name=app_version,json=appVersion,proto3" json:"app_version,omitempty"`    // 客户端版本
+	OsVersion     string                 `protobuf:"bytes,6,opt,name=os_version,json=osVersion,proto3" json:"os_version,omitempty"`       // 系统版本
+	DeviceModel   string                 `protobuf:"bytes,7,opt,name=device_model,json=deviceModel,proto3" json:"device_model,omitempty"` // 设备型号
+	Locale        string                 `protobuf:"bytes,8,opt,name=locale,proto3" json:"locale,omitempty"`                              // 设备语言
+	Timezone      string                 `protobuf:"bytes,9,opt,name=timezone,proto3" json:"timezone,omitempty"`                          // 设备时区（IANA 时区名）
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetUserTokenRequest) Reset() {
+	*x = SetUserTokenRequest{}
+	mi := &file_push_v1_push_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetUserTokenRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetUserTokenRequest) ProtoMessage() {}
+
+func (x *SetUserTokenRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_push_v1_push_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetUserTokenRequest.ProtoReflect.Descriptor instead.
+func (*SetUserTokenRequest) Descriptor() ([]byte, []int) {
+	return file_push_v1_push_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *SetUserTokenRequest) GetMetaId() string {
+	if x != nil {
+		return x.MetaId
+	}
+	return ""
+}
+
+func (x *SetUserTokenRequest) GetPlatform() string {
+	if x != nil {
+		return x.Platform
+	}
+	return ""
+}
+
+func (x *SetUserTokenRequest) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *SetUserTokenRequest) GetTenantId() string {
+	if x != nil {
+		return x.TenantId
+	}
+	return ""
+}
+
+func (x *SetUserTokenRequest) GetAppVersion() string {
+	if x != nil {
+		return x.AppVersion
+	}
+	return ""
+}
+
+func (x *SetUserTokenRequest) GetOsVersion() string {
+	if x != nil {
+		return x.OsVersion
+	}
+	return ""
+}
+
+func (x *SetUserTokenRequest) GetDeviceModel() string {
+	if x != nil {
+		return x.DeviceModel
+	}
+	return ""
+}
+
+func (x *SetUserTokenRequest) GetLocale() string {
+	if x != nil {
+		return x.Locale
+	}
+	return ""
+}
+
+func (x *SetUserTokenRequest) GetTimezone() string {
+	if x != nil {
+		return x.Timezone
+	}
+	return ""
+}
+
+type SetUserTokenResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetUserTokenResponse) Reset() {
+	*x = SetUserTokenResponse{}
+	mi := &file_push_v1_push_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetUserTokenResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetUserTokenResponse) ProtoMessage() {}
+
+func (x *SetUserTokenResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_push_v1_push_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetUserTokenResponse.ProtoReflect.Descriptor instead.
+func (*SetUserTokenResponse) Descriptor() ([]byte, []int) {
+	return file_push_v1_push_proto_rawDescGZIP(), []int{1}
+}
+
+type SendToUsersRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	MetaIds        []string               `protobuf:"bytes,1,rep,name=meta_ids,json=metaIds,proto3" json:"meta_ids,omitempty"`                                                      // 接收用户
+	Title          string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`                                                                         // 通知标题
+	Body           string                 `protobuf:"bytes,3,opt,name=body,proto3" json:"body,omitempty"`                                                                           // 通知内容
+	Data           map[string]string      `protobuf:"bytes,4,rep,name=data,proto3" json:"data,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // 自定义数据
+	Sound          string                 `protobuf:"bytes,5,opt,name=sound,proto3" json:"sound,omitempty"`                                                                         // 声音，默认 default
+	Priority       string                 `protobuf:"bytes,6,opt,name=priority,proto3" json:"priority,omitempty"`                                                                   // 优先级：normal、high
+	CollapseId     string                 `protobuf:"bytes,7,opt,name=collapse_id,json=collapseId,proto3" json:"collapse_id,omitempty"`                                             // 折叠ID
+	ThreadId       string                 `protobuf:"bytes,8,opt,name=thread_id,json=threadId,proto3" json:"thread_id,omitempty"`                                                   // 分组ID
+	DryRun         bool                   `protobuf:"varint,9,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`                                                        // 演练模式，不调用推送平台
+	IdempotencyKey string                 `protobuf:"bytes,10,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`                                // 幂等键，相同幂等键在保留期内重复请求不会重复推送
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *SendToUsersRequest) Reset() {
+	*x = SendToUsersRequest{}
+	mi := &file_push_v1_push_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SendToUsersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SendToUsersRequest) ProtoMessage() {}
+
+func (x *SendToUsersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_push_v1_push_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SendToUsersRequest.ProtoReflect.Descriptor instead.
+func (*SendToUsersRequest) Descriptor() ([]byte, []int) {
+	return file_push_v1_push_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *SendToUsersRequest) GetMetaIds() []string {
+	if x != nil {
+		return x.MetaIds
+	}
+	return nil
+}
+
+func (x *SendToUsersRequest) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *SendToUsersRequest) GetBody() string {
+	if x != nil {
+		return x.Body
+	}
+	return ""
+}
+
+func (x *SendToUsersRequest) GetData() map[string]string {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *SendToUsersRequest) GetSound() string {
+	if x != nil {
+		return x.Sound
+	}
+	return ""
+}
+
+func (x *SendToUsersRequest) GetPriority() string {
+	if x != nil {
+		return x.Priority
+	}
+	return ""
+}
+
+func (x *SendToUsersRequest) GetCollapseId() string {
+	if x != nil {
+		return x.CollapseId
+	}
+	return ""
+}
+
+func (x *SendToUsersRequest) GetThreadId() string {
+	if x != nil {
+		return x.ThreadId
+	}
+	return ""
+}
+
+func (x *SendToUsersRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+func (x *SendToUsersRequest) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
+type SendToUsersResponse struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Duplicate      bool                   `protobuf:"varint,1,opt,name=duplicate,proto3" json:"duplicate,omitempty"`                                 // 相同幂等键的请求已处理过，以下为首次推送的结果
+	TotalUsers     int32                  `protobuf:"varint,2,opt,name=total_users,json=totalUsers,proto3" json:"total_users,omitempty"`             // 总用户数
+	TotalPlatforms int32                  `protobuf:"varint,3,opt,name=total_platforms,json=totalPlatforms,proto3" json:"total_platforms,omitempty"` // 总平台数
+	SuccessCount   int32                  `protobuf:"varint,4,opt,name=success_count,json=successCount,proto3" json:"success_count,omitempty"`       // 成功数
+	FailureCount   int32                  `protobuf:"varint,5,opt,name=failure_count,json=failureCount,proto3" json:"failure_count,omitempty"`       // 失败数
+	QuotaRejected  int32                  `protobuf:"varint,6,opt,name=quota_rejected,json=quotaRejected,proto3" json:"quota_rejected,omitempty"`    // 租户超出配额被拒绝的用户数
+	Downgraded     int32                  `protobuf:"varint,7,opt,name=downgraded,proto3" json:"downgraded,omitempty"`                               // 租户超出配额降级发送的用户数
+	DryRun         bool                   `protobuf:"varint,8,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`                         // 是否为演练
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *SendToUsersResponse) Reset() {
+	*x = SendToUsersResponse{}
+	mi := &file_push_v1_push_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SendToUsersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SendToUsersResponse) ProtoMessage() {}
+
+func (x *SendToUsersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_push_v1_push_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SendToUsersResponse.ProtoReflect.Descriptor instead.
+func (*SendToUsersResponse) Descriptor() ([]byte, []int) {
+	return file_push_v1_push_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *SendToUsersResponse) GetDuplicate() bool {
+	if x != nil {
+		return x.Duplicate
+	}
+	return false
+}
+
+func (x *SendToUsersResponse) GetTotalUsers() int32 {
+	if x != nil {
+		return x.TotalUsers
+	}
+	return 0
+}
+
+func (x *SendToUsersResponse) GetTotalPlatforms() int32 {
+	if x != nil {
+		return x.TotalPlatforms
+	}
+	return 0
+}
+
+func (x *SendToUsersResponse) GetSuccessCount() int32 {
+	if x != nil {
+		return x.SuccessCount
+	}
+	return 0
+}
+
+func (x *SendToUsersResponse) GetFailureCount() int32 {
+	if x != nil {
+		return x.FailureCount
+	}
+	return 0
+}
+
+func (x *SendToUsersResponse) GetQuotaRejected() int32 {
+	if x != nil {
+		return x.QuotaRejected
+	}
+	return 0
+}
+
+func (x *SendToUsersResponse) GetDowngraded() int32 {
+	if x != nil {
+		return x.Downgraded
+	}
+	return 0
+}
+
+func (x *SendToUsersResponse) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+type GetDeliveryStatusRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PinId         string                 `protobuf:"bytes,1,opt,name=pin_id,json=pinId,proto3" json:"pin_id,omitempty"` // 消息PIN ID
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetDeliveryStatusRequest) Reset() {
+	*x = GetDeliveryStatusRequest{}
+	mi := &file_push_v1_push_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetDeliveryStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetDeliveryStatusRequest) ProtoMessage() {}
+
+func (x *GetDeliveryStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_push_v1_push_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetDeliveryStatusRequest.ProtoReflect.Descriptor instead.
+func (*GetDeliveryStatusRequest) Descriptor() ([]byte, []int) {
+	return file_push_v1_push_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetDeliveryStatusRequest) GetPinId() string {
+	if x != nil {
+		return x.PinId
+	}
+	return ""
+}
+
+type GetDeliveryStatusResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Records       []*DeliveryRecord      `protobuf:"bytes,1,rep,name=records,proto3" json:"records,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetDeliveryStatusResponse) Reset() {
+	*x = GetDeliveryStatusResponse{}
+	mi := &file_push_v1_push_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetDeliveryStatusResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetDeliveryStatusResponse) ProtoMessage() {}
+
+func (x *GetDeliveryStatusResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_push_v1_push_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetDeliveryStatusResponse.ProtoReflect.Descriptor instead.
+func (*GetDeliveryStatusResponse) Descriptor() ([]byte, []int) {
+	return file_push_v1_push_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetDeliveryStatusResponse) GetRecords() []*DeliveryRecord {
+	if x != nil {
+		return x.Records
+	}
+	return nil
+}
+
+type DeliveryRecord struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PinId         string                 `protobuf:"bytes,1,opt,name=pin_id,json=pinId,proto3" json:"pin_id,omitempty"`                         // 消息PIN ID
+	MetaId        string                 `protobuf:"bytes,2,opt,name=meta_id,json=metaId,proto3" json:"meta_id,omitempty"`                      // 接收用户
+	Platform      string                 `protobuf:"bytes,3,opt,name=platform,proto3" json:"platform,omitempty"`                                // 推送平台，未发送时为空
+	Attempted     bool                   `protobuf:"varint,4,opt,name=attempted,proto3" json:"attempted,omitempty"`                             // 是否尝试推送
+	SkipReason    string                 `protobuf:"bytes,5,opt,name=skip_reason,json=skipReason,proto3" json:"skip_reason,omitempty"`          // 未发送的原因
+	TicketStatus  string                 `protobuf:"bytes,6,opt,name=ticket_status,json=ticketStatus,proto3" json:"ticket_status,omitempty"`    // 推送平台受理状态
+	ReceiptId     string                 `protobuf:"bytes,7,opt,name=receipt_id,json=receiptId,proto3" json:"receipt_id,omitempty"`             // 回执ID
+	ReceiptStatus string                 `protobuf:"bytes,8,opt,name=receipt_status,json=receiptStatus,proto3" json:"receipt_status,omitempty"` // 最终回执状态
+	Error         string                 `protobuf:"bytes,9,opt,name=error,proto3" json:"error,omitempty"`                                      // 失败原因
+	AppVersion    string                 `protobuf:"bytes,10,opt,name=app_version,json=appVersion,proto3" json:"app_version,omitempty"`         // 接收设备推送时的客户端版本
+	OsVersion     string                 `protobuf:"bytes,11,opt,name=os_version,json=osVersion,proto3" json:"os_version,omitempty"`            // 接收设备推送时的系统版本
+	CreatedAt     int64                  `protobuf:"varint,12,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`           // 推送时间（Unix 毫秒）
+	UpdatedAt     int64                  `protobuf:"varint,13,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`           // 最后更新时间（Unix 毫秒）
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeliveryRecord) Reset() {
+	*x = DeliveryRecord{}
+	mi := &file_push_v1_push_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeliveryRecord) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeliveryRecord) ProtoMessage() {}
+
+func (x *DeliveryRecord) ProtoReflect() protoreflect.Message {
+	mi := &file_push_v1_push_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeliveryRecord.ProtoReflect.Descriptor instead.
+func (*DeliveryRecord) Descriptor() ([]byte, []int) {
+	return file_push_v1_push_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *DeliveryRecord) GetPinId() string {
+	if x != nil {
+		return x.PinId
+	}
+	return ""
+}
+
+func (x *DeliveryRecord) GetMetaId() string {
+	if x != nil {
+		return x.MetaId
+	}
+	return ""
+}
+
+func (x *DeliveryRecord) GetPlatform() string {
+	if x != nil {
+		return x.Platform
+	}
+	return ""
+}
+
+func (x *DeliveryRecord) GetAttempted() bool {
+	if x != nil {
+		return x.Attempted
+	}
+	return false
+}
+
+func (x *DeliveryRecord) GetSkipReason() string {
+	if x != nil {
+		return x.SkipReason
+	}
+	return ""
+}
+
+func (x *DeliveryRecord) GetTicketStatus() string {
+	if x != nil {
+		return x.TicketStatus
+	}
+	return ""
+}
+
+func (x *DeliveryRecord) GetReceiptId() string {
+	if x != nil {
+		return x.ReceiptId
+	}
+	return ""
+}
+
+func (x *DeliveryRecord) GetReceiptStatus() string {
+	if x != nil {
+		return x.ReceiptStatus
+	}
+	return ""
+}
+
+func (x *DeliveryRecord) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *DeliveryRecord) GetAppVersion() string {
+	if x != nil {
+		return x.AppVersion
+	}
+	return ""
+}
+
+func (x *DeliveryRecord) GetOsVersion() string {
+	if x != nil {
+		return x.OsVersion
+	}
+	return ""
+}
+
+func (x *DeliveryRecord) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+func (x *DeliveryRecord) GetUpdatedAt() int64 {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return 0
+}
+
+type StreamPushResultsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MetaIds       []string               `protobuf:"bytes,1,rep,name=meta_ids,json=metaIds,proto3" json:"meta_ids,omitempty"`                 // 只订阅这些用户的推送结果，空表示全部
+	FailuresOnly  bool                   `protobuf:"varint,2,opt,name=failures_only,json=failuresOnly,proto3" json:"failures_only,omitempty"` // 只订阅失败的推送结果
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamPushResultsRequest) Reset() {
+	*x = StreamPushResultsRequest{}
+	mi := &file_push_v1_push_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamPushResultsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamPushResultsRequest) ProtoMessage() {}
+
+func (x *StreamPushResultsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_push_v1_push_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamPushResultsRequest.ProtoReflect.Descriptor instead.
+func (*StreamPushResultsRequest) Descriptor() ([]byte, []int) {
+	return file_push_v1_push_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *StreamPushResultsRequest) GetMetaIds() []string {
+	if x != nil {
+		return x.MetaIds
+	}
+	return nil
+}
+
+func (x *StreamPushResultsRequest) GetFailuresOnly() bool {
+	if x != nil {
+		return x.FailuresOnly
+	}
+	return false
+}
+
+type PushResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MetaId        string                 `protobuf:"bytes,1,opt,name=meta_id,json=metaId,proto3" json:"meta_id,omitempty"`          // 用户MetaID
+	TenantId      string                 `protobuf:"bytes,2,opt,name=tenant_id,json=tenantId,proto3" json:"tenant_id,omitempty"`    // 用户所属租户ID
+	PinId         string                 `protobuf:"bytes,3,opt,name=pin_id,json=pinId,proto3" json:"pin_id,omitempty"`             // 关联的消息PIN ID
+	Platform      string                 `protobuf:"bytes,4,opt,name=platform,proto3" json:"platform,omitempty"`                    // 推送平台
+	Success       bool                   `protobuf:"varint,5,opt,name=success,proto3" json:"success,omitempty"`                     // 是否成功
+	ReceiptId     string                 `protobuf:"bytes,6,opt,name=receipt_id,json=receiptId,proto3" json:"receipt_id,omitempty"` // 回执ID
+	Error         string                 `protobuf:"bytes,7,opt,name=error,proto3" json:"error,omitempty"`                          // 失败原因
+	Simulated     bool                   `protobuf:"varint,8,opt,name=simulated,proto3" json:"simulated,omitempty"`                 // 演练模式下的模拟结果
+	Timestamp     int64                  `protobuf:"varint,9,opt,name=timestamp,proto3" json:"timestamp,omitempty"`                 // 推送时间（Unix 毫秒）
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PushResult) Reset() {
+	*x = PushResult{}
+	mi := &file_push_v1_push_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PushResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PushResult) ProtoMessage() {}
+
+func (x *PushResult) ProtoReflect() protoreflect.Message {
+	mi := &file_push_v1_push_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PushResult.ProtoReflect.Descriptor instead.
+func (*PushResult) Descriptor() ([]byte, []int) {
+	return file_push_v1_push_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *PushResult) GetMetaId() string {
+	if x != nil {
+		return x.MetaId
+	}
+	return ""
+}
+
+func (x *PushResult) GetTenantId() string {
+	if x != nil {
+		return x.TenantId
+	}
+	return ""
+}
+
+func (x *PushResult) GetPinId() string {
+	if x != nil {
+		return x.PinId
+	}
+	return ""
+}
+
+func (x *PushResult) GetPlatform() string {
+	if x != nil {
+		return x.Platform
+	}
+	return ""
+}
+
+func (x *PushResult) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *PushResult) GetReceiptId() string {
+	if x != nil {
+		return x.ReceiptId
+	}
+	return ""
+}
+
+func (x *PushResult) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *PushResult) GetSimulated() bool {
+	if x != nil {
+		return x.Simulated
+	}
+	return false
+}
+
+func (x *PushResult) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+var File_push_v1_push_proto protoreflect.FileDescriptor
+
+const file_push_v1_push_proto_rawDesc = "" +
+	"\n" +
+	"\x12push/v1/push.proto\x12\apush.v1\"\x94\x02\n" +
+	"\x13SetUserTokenRequest\x12\x17\n" +
+	"\ameta_id\x18\x01 \x01(\tR\x06metaId\x12\x1a\n" +
+	"\bplatform\x18\x02 \x01(\tR\bplatform\x12\x14\n" +
+	"\x05token\x18\x03 \x01(\tR\x05token\x12\x1b\n" +
+	"\ttenant_id\x18\x04 \x01(\tR\btenantId\x12\x1f\n" +
+	"\vapp_version\x18\x05 \x01(\tR\n" +
+	"appVersion\x12\x1d\n" +
+	"\n" +
+	"os_version\x18\x06 \x01(\tR\tosVersion\x12!\n" +
+	"\fdevice_model\x18\a \x01(\tR\vdeviceModel\x12\x16\n" +
+	"\x06locale\x18\b \x01(\tR\x06locale\x12\x1a\n" +
+	"\btimezone\x18\t \x01(\tR\btimezone\"\x16\n" +
+	"\x14SetUserTokenResponse\"\xff\x02\n" +
+	"\x12SendToUsersRequest\x12\x19\n" +
+	"\bmeta_ids\x18\x01 \x03(\tR\ametaIds\x12\x14\n" +
+	"\x05title\x18\x02 \x01(\tR\x05title\x12\x12\n" +
+	"\x04body\x18\x03 \x01(\tR\x04body\x129\n" +
+	"\x04data\x18\x04 \x03(\v2%.push.v1.SendToUsersRequest.DataEntryR\x04data\x12\x14\n" +
+	"\x05sound\x18\x05 \x01(\tR\x05sound\x12\x1a\n" +
+	"\bpriority\x18\x06 \x01(\tR\bpriority\x12\x1f\n" +
+	"\vcollapse_id\x18\a \x01(\tR\n" +
+	"collapseId\x12\x1b\n" +
+	"\tthread_id\x18\b \x01(\tR\bthreadId\x12\x17\n" +
+	"\adry_run\x18\t \x01(\bR\x06dryRun\x12'\n" +
+	"\x0fidempotency_key\x18\n" +
+	" \x01(\tR\x0eidempotencyKey\x1a7\n" +
+	"\tDataEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xa7\x02\n" +
+	"\x13SendToUsersResponse\x12\x1c\n" +
+	"\tduplicate\x18\x01 \x01(\bR\tduplicate\x12\x1f\n" +
+	"\vtotal_users\x18\x02 \x01(\x05R\n" +
+	"totalUsers\x12'\n" +
+	"\x0ftotal_platforms\x18\x03 \x01(\x05R\x0etotalPlatforms\x12#\n" +
+	"\rsuccess_count\x18\x04 \x01(\x05R\fsuccessCount\x12#\n" +
+	"\rfailure_count\x18\x05 \x01(\x05R\ffailureCount\x12%\n" +
+	"\x0equota_rejected\x18\x06 \x01(\x05R\rquotaRejected\x12\x1e\n" +
+	"\n" +
+	"downgraded\x18\a \x01(\x05R\n" +
+	"downgraded\x12\x17\n" +
+	"\adry_run\x18\b \x01(\bR\x06dryRun\"1\n" +
+	"\x18GetDeliveryStatusRequest\x12\x15\n" +
+	"\x06pin_id\x18\x01 \x01(\tR\x05pinId\"N\n" +
+	"\x19GetDeliveryStatusResponse\x121\n" +
+	"\arecords\x18\x01 \x03(\v2\x17.push.v1.DeliveryRecordR\arecords\"\x9a\x03\n" +
+	"\x0eDeliveryRecord\x12\x15\n" +
+	"\x06pin_id\x18\x01 \x01(\tR\x05pinId\x12\x17\n" +
+	"\ameta_id\x18\x02 \x01(\tR\x06metaId\x12\x1a\n" +
+	"\bplatform\x18\x03 \x01(\tR\bplatform\x12\x1c\n" +
+	"\tattempted\x18\x04 \x01(\bR\tattempted\x12\x1f\n" +
+	"\vskip_reason\x18\x05 \x01(\tR\n" +
+	"skipReason\x12#\n" +
+	"\rticket_status\x18\x06 \x01(\tR\fticketStatus\x12\x1d\n" +
+	"\n" +
+	"receipt_id\x18\a \x01(\tR\treceiptId\x12%\n" +
+	"\x0ereceipt_status\x18\b \x01(\tR\rreceiptStatus\x12\x14\n" +
+	"\x05error\x18\t \x01(\tR\x05error\x12\x1f\n" +
+	"\vapp_version\x18\n" +
+	" \x01(\tR\n" +
+	"appVersion\x12\x1d\n" +
+	"\n" +
+	"os_version\x18\v \x01(\tR\tosVersion\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\f \x01(\x03R\tcreatedAt\x12\x1d\n" +
+	"\n" +
+	"updated_at\x18\r \x01(\x03R\tupdatedAt\"Z\n" +
+	"\x18StreamPushResultsRequest\x12\x19\n" +
+	"\bmeta_ids\x18\x01 \x03(\tR\ametaIds\x12#\n" +
+	"\rfailures_only\x18\x02 \x01(\bR\ffailuresOnly\"\x80\x02\n" +
+	"\n" +
+	"PushResult\x12\x17\n" +
+	"\ameta_id\x18\x01 \x01(\tR\x06metaId\x12\x1b\n" +
+	"\ttenant_id\x18\x02 \x01(\tR\btenantId\x12\x15\n" +
+	"\x06pin_id\x18\x03 \x01(\tR\x05pinId\x12\x1a\n" +
+	"\bplatform\x18\x04 \x01(\tR\bplatform\x12\x18\n" +
+	"\asuccess\x18\x05 \x01(\bR\asuccess\x12\x1d\n" +
+	"\n" +
+	"receipt_id\x18\x06 \x01(\tR\treceiptId\x12\x14\n" +
+	"\x05error\x18\a \x01(\tR\x05error\x12\x1c\n" +
+	"\tsimulated\x18\b \x01(\bR\tsimulated\x12\x1c\n" +
+	"\ttimestamp\x18\t \x01(\x03R\ttimestamp2\xcf\x02\n" +
+	"\vPushService\x12K\n" +
+	"\fSetUserToken\x12\x1c.push.v1.SetUserTokenRequest\x1a\x1d.push.v1.SetUserTokenResponse\x12H\n" +
+	"\vSendToUsers\x12\x1b.push.v1.SendToUsersRequest\x1a\x1c.push.v1.SendToUsersResponse\x12Z\n" +
+	"\x11GetDeliveryStatus\x12!.push.v1.GetDeliveryStatusRequest\x1a\".push.v1.GetDeliveryStatusResponse\x12M\n" +
+	"\x11StreamPushResults\x12!.push.v1.StreamPushResultsRequest\x1a\x13.push.v1.PushResult0\x01B-Z+push-base-service/controller/grpcapi/pushpbb\x06proto3"
+
+var (
+	file_push_v1_push_proto_rawDescOnce sync.Once
+	file_push_v1_push_proto_rawDescData []byte
+)
+
+func file_push_v1_push_proto_rawDescGZIP() []byte {
+	file_push_v1_push_proto_rawDescOnce.Do(func() {
+		file_push_v1_push_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_push_v1_push_proto_rawDesc), len(file_push_v1_push_proto_rawDesc)))
+	})
+	return file_push_v1_push_proto_rawDescData
+}
+
+var file_push_v1_push_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
+var file_push_v1_push_proto_goTypes = []any{
+	(*SetUserTokenRequest)(nil),       // 0: push.v1.SetUserTokenRequest
+	(*SetUserTokenResponse)(nil),      // 1: push.v1.SetUserTokenResponse
+	(*SendToUsersRequest)(nil),        // 2: push.v1.SendToUsersRequest
+	(*SendToUsersResponse)(nil),       // 3: push.v1.SendToUsersResponse
+	(*GetDeliveryStatusRequest)(nil),  // 4: push.v1.GetDeliveryStatusRequest
+	(*GetDeliveryStatusResponse)(nil), // 5: push.v1.GetDeliveryStatusResponse
+	(*DeliveryRecord)(nil),            // 6: push.v1.DeliveryRecord
+	(*StreamPushResultsRequest)(nil),  // 7: push.v1.StreamPushResultsRequest
+	(*PushResult)(nil),                // 8: push.v1.PushResult
+	nil,                               // 9: push.v1.SendToUsersRequest.DataEntry
+}
+var file_push_v1_push_proto_depIdxs = []int32{
+	9, // 0: push.v1.SendToUsersRequest.data:type_name -> push.v1.SendToUsersRequest.DataEntry
+	6, // 1: push.v1.GetDeliveryStatusResponse.records:type_name -> push.v1.DeliveryRecord
+	0, // 2: push.v1.PushService.SetUserToken:input_type -> push.v1.SetUserTokenRequest
+	2, // 3: push.v1.PushService.SendToUsers:input_type -> push.v1.SendToUsersRequest
+	4, // 4: push.v1.PushService.GetDeliveryStatus:input_type -> push.v1.GetDeliveryStatusRequest
+	7, // 5: push.v1.PushService.StreamPushResults:input_type -> push.v1.StreamPushResultsRequest
+	1, // 6: push.v1.PushService.SetUserToken:output_type -> push.v1.SetUserTokenResponse
+	3, // 7: push.v1.PushService.SendToUsers:output_type -> push.v1.SendToUsersResponse
+	5, // 8: push.v1.PushService.GetDeliveryStatus:output_type -> push.v1.GetDeliveryStatusResponse
+	8, // 9: push.v1.PushService.StreamPushResults:output_type -> push.v1.PushResult
+	6, // [6:10] is the sub-list for method output_type
+	2, // [2:6] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_push_v1_push_proto_init() }
+func file_push_v1_push_proto_init() {
+	if File_push_v1_push_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_push_v1_push_proto_rawDesc), len(file_push_v1_push_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   10,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_push_v1_push_proto_goTypes,
+		DependencyIndexes: file_push_v1_push_proto_depIdxs,
+		MessageInfos:      file_push_v1_push_proto_msgTypes,
+	}.Build()
+	File_push_v1_push_proto = out.File
+	file_push_v1_push_proto_goTypes = nil
+	file_push_v1_push_proto_depIdxs = nil
+}
@@ -0,0 +1,256 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.29.3
+// source: push/v1/push.proto
+
+// 推送基础服务 gRPC 接口，与 HTTP 接口共用同一套存储和推送流程
+// 修改后在仓库根目录执行 go generate ./controller/grpcapi 重新生成 Go 代码
+
+package pushpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	PushService_SetUserToken_FullMethodName      = "/push.v1.PushService/SetUserToken"
+	PushService_SendToUsers_FullMethodName       = "/push.v1.PushService/SendToUsers"
+	PushService_GetDeliveryStatus_FullMethodName = "/push.v1.PushService/GetDeliveryStatus"
+	PushService_StreamPushResults_FullMethodName = "/push.v1.PushService/StreamPushResults"
+)
+
+// PushServiceClient is the client API for PushService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// PushService 推送服务：登记令牌、发送推送、查询投递状态和订阅推送结果
+// 调用时在 metadata 中携带 x-api-key，权限范围与 HTTP 接口相同（SetUserToken、SendToUsers 需要 write，其余需要 read）
+type PushServiceClient interface {
+	// SetUserToken 为用户登记推送令牌，并记录设备信息
+	SetUserToken(ctx context.Context, in *SetUserTokenRequest, opts ...grpc.CallOption) (*SetUserTokenResponse, error)
+	// SendToUsers 向指定用户发送推送通知
+	SendToUsers(ctx context.Context, in *SendToUsersRequest, opts ...grpc.CallOption) (*SendToUsersResponse, error)
+	// GetDeliveryStatus 按 PinId 查询消息的投递状态
+	GetDeliveryStatus(ctx context.Context, in *GetDeliveryStatusRequest, opts ...grpc.CallOption) (*GetDeliveryStatusResponse, error)
+	// StreamPushResults 订阅推送结果，每产生一条推送结果推送一次，客户端处理过慢时丢弃
+	StreamPushResults(ctx context.Context, in *StreamPushResultsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[PushResult], error)
+}
+
+type pushServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPushServiceClient(cc grpc.ClientConnInterface) PushServiceClient {
+	return &pushServiceClient{cc}
+}
+
+func (c *pushServiceClient) SetUserToken(ctx context.Context, in *SetUserTokenRequest, opts ...grpc.CallOption) (*SetUserTokenResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetUserTokenResponse)
+	err := c.cc.Invoke(ctx, PushService_SetUserToken_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pushServiceClient) SendToUsers(ctx context.Context, in *SendToUsersRequest, opts ...grpc.CallOption) (*SendToUsersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SendToUsersResponse)
+	err := c.cc.Invoke(ctx, PushService_SendToUsers_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pushServiceClient) GetDeliveryStatus(ctx context.Context, in *GetDeliveryStatusRequest, opts ...grpc.CallOption) (*GetDeliveryStatusResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetDeliveryStatusResponse)
+	err := c.cc.Invoke(ctx, PushService_GetDeliveryStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pushServiceClient) StreamPushResults(ctx context.Context, in *StreamPushResultsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[PushResult], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &PushService_ServiceDesc.Streams[0], PushService_StreamPushResults_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamPushResultsRequest, PushResult]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type PushService_StreamPushResultsClient = grpc.ServerStreamingClient[PushResult]
+
+// PushServiceServer is the server API for PushService service.
+// All implementations must embed UnimplementedPushServiceServer
+// for forward compatibility.
+//
+// PushService 推送服务：登记令牌、发送推送、查询投递状态和订阅推送结果
+// 调用时在 metadata 中携带 x-api-key，权限范围与 HTTP 接口相同（SetUserToken、SendToUsers 需要 write，其余需要 read）
+type PushServiceServer interface {
+	// SetUserToken 为用户登记推送令牌，并记录设备信息
+	SetUserToken(context.Context, *SetUserTokenRequest) (*SetUserTokenResponse, error)
+	// SendToUsers 向指定用户发送推送通知
+	SendToUsers(context.Context, *SendToUsersRequest) (*SendToUsersResponse, error)
+	// GetDeliveryStatus 按 PinId 查询消息的投递状态
+	GetDeliveryStatus(context.Context, *GetDeliveryStatusRequest) (*GetDeliveryStatusResponse, error)
+	// StreamPushResults 订阅推送结果，每产生一条推送结果推送一次，客户端处理过慢时丢弃
+	StreamPushResults(*StreamPushResultsRequest, grpc.ServerStreamingServer[PushResult]) error
+	mustEmbedUnimplementedPushServiceServer()
+}
+
+// UnimplementedPushServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedPushServiceServer struct{}
+
+func (UnimplementedPushServiceServer) SetUserToken(context.Context, *SetUserTokenRequest) (*SetUserTokenResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetUserToken not implemented")
+}
+func (UnimplementedPushServiceServer) SendToUsers(context.Context, *SendToUsersRequest) (*SendToUsersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SendToUsers not implemented")
+}
+func (UnimplementedPushServiceServer) GetDeliveryStatus(context.Context, *GetDeliveryStatusRequest) (*GetDeliveryStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDeliveryStatus not implemented")
+}
+func (UnimplementedPushServiceServer) StreamPushResults(*StreamPushResultsRequest, grpc.ServerStreamingServer[PushResult]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamPushResults not implemented")
+}
+func (UnimplementedPushServiceServer) mustEmbedUnimplementedPushServiceServer() {}
+func (UnimplementedPushServiceServer) testEmbeddedByValue()                     {}
+
+// UnsafePushServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to PushServiceServer will
+// result in compilation errors.
+type UnsafePushServiceServer interface {
+	mustEmbedUnimplementedPushServiceServer()
+}
+
+func RegisterPushServiceServer(s grpc.ServiceRegistrar, srv PushServiceServer) {
+	// If the following call pancis, it indicates UnimplementedPushServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&PushService_ServiceDesc, srv)
+}
+
+func _PushService_SetUserToken_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetUserTokenRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PushServiceServer).SetUserToken(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PushService_SetUserToken_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PushServiceServer).SetUserToken(ctx, req.(*SetUserTokenRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PushService_SendToUsers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SendToUsersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PushServiceServer).SendToUsers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PushService_SendToUsers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PushServiceServer).SendToUsers(ctx, req.(*SendToUsersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PushService_GetDeliveryStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetDeliveryStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PushServiceServer).GetDeliveryStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PushService_GetDeliveryStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PushServiceServer).GetDeliveryStatus(ctx, req.(*GetDeliveryStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PushService_StreamPushResults_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamPushResultsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(PushServiceServer).StreamPushResults(m, &grpc.GenericServerStream[StreamPushResultsRequest, PushResult]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type PushService_StreamPushResultsServer = grpc.ServerStreamingServer[PushResult]
+
+// PushService_ServiceDesc is the grpc.ServiceDesc for PushService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var PushService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "push.v1.PushService",
+	HandlerType: (*PushServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "SetUserToken",
+			Handler:    _PushService_SetUserToken_Handler,
+		},
+		{
+			MethodName: "SendToUsers",
+			Handler:    _PushService_SendToUsers_Handler,
+		},
+		{
+			MethodName: "GetDeliveryStatus",
+			Handler:    _PushService_GetDeliveryStatus_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamPushResults",
+			Handler:       _PushService_StreamPushResults_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "push/v1/push.proto",
+}
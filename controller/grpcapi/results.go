@@ -0,0 +1,126 @@
+package grpcapi
+
+import (
+	"push-base-service/controller/grpcapi/pushpb"
+	"push-base-service/service/metrics_service"
+	"push-base-service/service/push_service"
+	"sync"
+)
+
+// grpcStreamDroppedCounter 订阅者缓冲已满而丢弃的推送结果数
+var grpcStreamDroppedCounter = metrics_service.NewCounterVec(
+	"push_grpc_stream_dropped_total", "Number of push results dropped because a StreamPushResults subscriber fell behind")
+
+// resultSubscriber 一个 StreamPushResults 订阅
+type resultSubscriber struct {
+	results      chan *pushpb.PushResult
+	metaIds      map[string]bool // 只接收这些用户的结果，空表示全部
+	failuresOnly bool            // 只接收失败的结果
+}
+
+// matches 判断推送结果是否符合订阅条件
+func (sub *resultSubscriber) matches(result *pushpb.PushResult) bool {
+	if sub.failuresOnly && result.Success {
+		return false
+	}
+	return len(sub.metaIds) == 0 || sub.metaIds[result.MetaId]
+}
+
+// resultHub 将推送结果分发给所有订阅者，订阅者缓冲已满时丢弃，不阻塞推送
+type resultHub struct {
+	mu          sync.RWMutex
+	buffer      int
+	subscribers map[*resultSubscriber]struct{}
+	closed      chan struct{}
+	closeOnce   sync.Once
+}
+
+// newResultHub 创建推送结果分发器
+func newResultHub(buffer int) *resultHub {
+	return &resultHub{
+		buffer:      buffer,
+		subscribers: make(map[*resultSubscriber]struct{}),
+		closed:      make(chan struct{}),
+	}
+}
+
+// subscribe 添加订阅，调用方结束后需调用 unsubscribe
+func (h *resultHub) subscribe(metaIds []string, failuresOnly bool) *resultSubscriber {
+	sub := &resultSubscriber{
+		results:      make(chan *pushpb.PushResult, h.buffer),
+		failuresOnly: failuresOnly,
+	}
+	if len(metaIds) > 0 {
+		sub.metaIds = make(map[string]bool, len(metaIds))
+		for _, metaId := range metaIds {
+			sub.metaIds[metaId] = true
+		}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.subscribers[sub] = struct{}{}
+	return sub
+}
+
+// unsubscribe 移除订阅
+func (h *resultHub) unsubscribe(sub *resultSubscriber) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	delete(h.subscribers, sub)
+}
+
+// publish 将推送结果发给所有符合条件的订阅者
+func (h *resultHub) publish(notification *push_service.PushNotification, result *push_service.PushResult) {
+	if result == nil {
+		return
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	if len(h.subscribers) == 0 {
+		return
+	}
+
+	message := buildPushResult(notification, result)
+	for sub := range h.subscribers {
+		if !sub.matches(message) {
+			continue
+		}
+		select {
+		case sub.results <- message:
+		default:
+			grpcStreamDroppedCounter.Inc()
+		}
+	}
+}
+
+// close 关闭分发器，所有订阅随之结束
+func (h *resultHub) close() {
+	h.closeOnce.Do(func() { close(h.closed) })
+}
+
+// buildPushResult 将推送结果转换为 gRPC 消息，PinId 取自通知数据
+func buildPushResult(notification *push_service.PushNotification, result *push_service.PushResult) *pushpb.PushResult {
+	message := &pushpb.PushResult{
+		MetaId:    result.MetaID,
+		TenantId:  result.TenantID,
+		Platform:  result.Platform,
+		Success:   result.Success,
+		ReceiptId: result.ReceiptID,
+		Simulated: result.Simulated,
+		Timestamp: result.Timestamp.UnixMilli(),
+	}
+	if result.Error != nil {
+		message.Error = result.Error.Error()
+	}
+	if notification != nil && notification.Data != nil {
+		if pinId, ok := notification.Data["pinId"].(string); ok {
+			message.PinId = pinId
+		}
+	}
+	return message
+}
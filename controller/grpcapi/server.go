@@ -0,0 +1,232 @@
+// Package grpcapi 与 HTTP 接口并行的 gRPC 接口：登记令牌、发送推送、查询投递状态和订阅推送结果
+package grpcapi
+
+//go:generate protoc -I ../../proto --go_out=../.. --go_opt=module=push-base-service --go-grpc_out=../.. --go-grpc_opt=module=push-base-service push/v1/push.proto
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"push-base-service/controller/auth"
+	"push-base-service/controller/grpcapi/pushpb"
+	"push-base-service/controller/respond"
+	"push-base-service/models"
+	"push-base-service/service/metrics_service"
+	"push-base-service/service/push_service"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// DefaultPort 默认监听端口
+	DefaultPort = "9090"
+	// DefaultStreamBuffer 每个推送结果订阅者默认缓冲的结果数
+	DefaultStreamBuffer = 256
+
+	// apiKeyMetadataKey 携带 API Key 的 metadata 键（与 HTTP 的 X-API-KEY 请求头对应）
+	apiKeyMetadataKey = "x-api-key"
+)
+
+// methodScopes 各方法要求的 API Key 权限范围
+var methodScopes = map[string]string{
+	pushpb.PushService_SetUserToken_FullMethodName:      models.APIKeyScopeWrite,
+	pushpb.PushService_SendToUsers_FullMethodName:       models.APIKeyScopeWrite,
+	pushpb.PushService_GetDeliveryStatus_FullMethodName: models.APIKeyScopeRead,
+	pushpb.PushService_StreamPushResults_FullMethodName: models.APIKeyScopeRead,
+}
+
+// grpcRequestsCounter 按方法和状态码统计的 gRPC 请求数
+var grpcRequestsCounter = metrics_service.NewCounterVec(
+	"push_grpc_requests_total", "Number of gRPC requests by method and status code", "method", "code")
+
+// Config gRPC 接口配置
+type Config struct {
+	Port         string // 监听端口
+	StreamBuffer int    // 每个推送结果订阅者缓冲的结果数，订阅者处理过慢、缓冲已满时丢弃新结果
+}
+
+// Server gRPC 接口服务
+type Server struct {
+	pushpb.UnimplementedPushServiceServer
+
+	config *Config
+	hub    *resultHub
+	server *grpc.Server
+}
+
+// NewServer 创建 gRPC 接口服务，未配置的项使用默认值
+func NewServer(config *Config) *Server {
+	if config == nil {
+		config = &Config{}
+	}
+	if config.Port == "" {
+		config.Port = DefaultPort
+	}
+	if config.StreamBuffer <= 0 {
+		config.StreamBuffer = DefaultStreamBuffer
+	}
+
+	s := &Server{
+		config: config,
+		hub:    newResultHub(config.StreamBuffer),
+	}
+	s.server = grpc.NewServer(
+		grpc.ChainUnaryInterceptor(s.unaryInterceptor),
+		grpc.ChainStreamInterceptor(s.streamInterceptor),
+	)
+	pushpb.RegisterPushServiceServer(s.server, s)
+	return s
+}
+
+// HandleResult 推送结果监听器，将推送结果分发给 StreamPushResults 的订阅者
+func (s *Server) HandleResult(notification *push_service.PushNotification, result *push_service.PushResult) {
+	s.hub.publish(notification, result)
+}
+
+// Start 监听配置的端口并在后台处理请求
+func (s *Server) Start() error {
+	listener, err := net.Listen("tcp", fmt.Sprintf("0.0.0.0:%s", s.config.Port))
+	if err != nil {
+		return fmt.Errorf("监听 gRPC 端口失败: %w", err)
+	}
+
+	go func() {
+		if err := s.Serve(listener); err != nil {
+			log.Printf("❌ gRPC 接口服务退出: %v", err)
+		}
+	}()
+	log.Printf("✅ gRPC 接口已启动，端口: %s", s.config.Port)
+	return nil
+}
+
+// Serve 在指定的 listener 上处理请求，直到 Stop 被调用
+func (s *Server) Serve(listener net.Listener) error {
+	return s.server.Serve(listener)
+}
+
+// Stop 停止接收新请求，等待处理中的请求结束；推送结果订阅随之关闭
+func (s *Server) Stop() {
+	s.hub.close()
+	s.server.GracefulStop()
+}
+
+// unaryInterceptor 校验 API Key 并记录请求结果，接口错误转换为 gRPC 状态码
+func (s *Server) unaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	name, tenant, clientIP, err := authorize(ctx, info.FullMethod)
+	if err != nil {
+		grpcRequestsCounter.Inc(info.FullMethod, status.Code(err).String())
+		return nil, err
+	}
+
+	resp, err := handler(context.WithValue(ctx, tenantContextKey{}, tenant), req)
+	err = toStatusError(err)
+	auth.RecordAPIKeyRequest(name, info.FullMethod, clientIP, err != nil)
+	grpcRequestsCounter.Inc(info.FullMethod, status.Code(err).String())
+	return resp, err
+}
+
+// streamInterceptor 流式方法的 API Key 校验和请求记录
+func (s *Server) streamInterceptor(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	name, _, clientIP, err := authorize(stream.Context(), info.FullMethod)
+	if err != nil {
+		grpcRequestsCounter.Inc(info.FullMethod, status.Code(err).String())
+		return err
+	}
+
+	err = toStatusError(handler(srv, stream))
+	auth.RecordAPIKeyRequest(name, info.FullMethod, clientIP, err != nil)
+	grpcRequestsCounter.Inc(info.FullMethod, status.Code(err).String())
+	return err
+}
+
+// tenantContextKey 请求上下文中记录 API Key 所属租户的键
+type tenantContextKey struct{}
+
+// callerTenant 当前请求的 API Key 所属租户，未绑定租户时为空
+func callerTenant(ctx context.Context) string {
+	tenant, _ := ctx.Value(tenantContextKey{}).(string)
+	return tenant
+}
+
+// authorize 校验 metadata 中的 API Key 是否具有方法要求的权限范围，返回 Key 名称、所属租户和客户端地址
+func authorize(ctx context.Context, method string) (string, string, string, error) {
+	var apiKey string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(apiKeyMetadataKey); len(values) > 0 {
+			apiKey = values[0]
+		}
+	}
+
+	var clientIP string
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		clientIP = p.Addr.String()
+		if host, _, err := net.SplitHostPort(clientIP); err == nil {
+			clientIP = host
+		}
+	}
+
+	scope, ok := methodScopes[method]
+	if !ok {
+		scope = models.APIKeyScopeAdmin
+	}
+	name, tenant, err := auth.AuthorizeAPIKey(apiKey, scope, method, clientIP)
+	if err != nil {
+		if errors.Is(err, auth.AuthErrAPIKeyScope) {
+			return "", "", clientIP, status.Error(codes.PermissionDenied, err.Error())
+		}
+		return "", "", clientIP, status.Error(codes.Unauthenticated, err.Error())
+	}
+	return name, tenant, clientIP, nil
+}
+
+// toStatusError 将接口错误转换为 gRPC 状态：错误码对应到 gRPC 状态码，字段校验错误放入 BadRequest 详情
+// 已经是 gRPC 状态的错误原样返回，其他错误视为服务器内部错误
+func toStatusError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	var apiErr *respond.APIError
+	if !errors.As(err, &apiErr) {
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	code := codes.Internal
+	switch apiErr.Code {
+	case respond.ErrCodeInvalidParam:
+		code = codes.InvalidArgument
+	case respond.ErrCodeNotFound:
+		code = codes.NotFound
+	case respond.ErrCodeUnauthorized:
+		code = codes.PermissionDenied
+	case respond.ErrCodeRateLimited:
+		code = codes.ResourceExhausted
+	case respond.ErrCodeProviderError, respond.ErrCodeUnavailable:
+		code = codes.Unavailable
+	}
+
+	st := status.New(code, apiErr.Message)
+	if len(apiErr.Details) > 0 {
+		badRequest := &errdetails.BadRequest{}
+		for _, detail := range apiErr.Details {
+			badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       detail.Field,
+				Description: detail.Message,
+			})
+		}
+		if withDetails, err := st.WithDetails(badRequest); err == nil {
+			st = withDetails
+		}
+	}
+	return st.Err()
+}
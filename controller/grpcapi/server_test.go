@@ -0,0 +1,150 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"net"
+	"push-base-service/conf"
+	"push-base-service/controller/grpcapi/pushpb"
+	"push-base-service/models"
+	"push-base-service/service/pebble_service"
+	"push-base-service/service/push_service"
+	"testing"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// startTestServer 在内存连接上启动 gRPC 服务，返回服务和客户端
+func startTestServer(t *testing.T) (*Server, pushpb.PushServiceClient) {
+	t.Helper()
+	conf.APIKeys = []conf.APIKeyConf{
+		{Name: "reader", Key: "reader-key", Scopes: []string{models.APIKeyScopeRead}},
+		{Name: "writer", Key: "writer-key", Scopes: []string{models.APIKeyScopeWrite}},
+	}
+	t.Cleanup(func() { conf.APIKeys = nil })
+
+	server := NewServer(&Config{StreamBuffer: 4})
+	listener := bufconn.Listen(1 << 20)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("创建客户端失败: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return server, pushpb.NewPushServiceClient(conn)
+}
+
+func withKey(key string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), apiKeyMetadataKey, key)
+}
+
+func TestAuthorizeByScope(t *testing.T) {
+	_, client := startTestServer(t)
+
+	checks := []struct {
+		name string
+		ctx  context.Context
+		want codes.Code
+	}{
+		{"缺少 API Key", context.Background(), codes.Unauthenticated},
+		{"错误的 API Key", withKey("wrong-key"), codes.Unauthenticated},
+		{"read 权限调用发送", withKey("reader-key"), codes.PermissionDenied},
+		{"推送中心未启用", withKey("writer-key"), codes.Unavailable},
+	}
+	for _, check := range checks {
+		_, err := client.SendToUsers(check.ctx, &pushpb.SendToUsersRequest{MetaIds: []string{"alice"}, Title: "t", Body: "b"})
+		if got := status.Code(err); got != check.want {
+			t.Errorf("%s: code = %v, want %v (err: %v)", check.name, got, check.want, err)
+		}
+	}
+}
+
+func TestInvalidArgumentCarriesFieldViolations(t *testing.T) {
+	_, client := startTestServer(t)
+
+	_, err := client.SetUserToken(withKey("writer-key"), &pushpb.SetUserTokenRequest{Platform: "expo", Token: "t"})
+	st := status.Convert(err)
+	if st.Code() != codes.InvalidArgument {
+		t.Fatalf("code = %v, want InvalidArgument (err: %v)", st.Code(), err)
+	}
+	var field string
+	for _, detail := range st.Details() {
+		if badRequest, ok := detail.(*errdetails.BadRequest); ok && len(badRequest.FieldViolations) > 0 {
+			field = badRequest.FieldViolations[0].Field
+		}
+	}
+	if field != "meta_id" {
+		t.Errorf("field violation = %q, want meta_id (details: %v)", field, st.Details())
+	}
+}
+
+func TestSetUserTokenRejectsTenant(t *testing.T) {
+	_, client := startTestServer(t)
+
+	_, err := client.SetUserToken(withKey("writer-key"), &pushpb.SetUserTokenRequest{MetaId: "alice", Platform: "expo", Token: "t", TenantId: "acme"})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("code = %v, want InvalidArgument: tenant must not be client-settable (err: %v)", status.Code(err), err)
+	}
+}
+
+func TestGetDeliveryStatusNotFound(t *testing.T) {
+	if err := pebble_service.InitializeGlobalService(&pebble_service.Config{DBPath: t.TempDir()}); err != nil {
+		t.Fatalf("初始化 Pebble 服务失败: %v", err)
+	}
+	t.Cleanup(func() { pebble_service.CloseGlobalService() })
+	_, client := startTestServer(t)
+
+	_, err := client.GetDeliveryStatus(withKey("reader-key"), &pushpb.GetDeliveryStatusRequest{PinId: "missing"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("code = %v, want NotFound (err: %v)", status.Code(err), err)
+	}
+}
+
+func TestStreamPushResults(t *testing.T) {
+	push_service.SetGlobalManager(push_service.NewManager())
+	t.Cleanup(func() { push_service.SetGlobalManager(nil) })
+	server, client := startTestServer(t)
+
+	ctx, cancel := context.WithTimeout(withKey("reader-key"), 5*time.Second)
+	defer cancel()
+	stream, err := client.StreamPushResults(ctx, &pushpb.StreamPushResultsRequest{MetaIds: []string{"alice"}, FailuresOnly: true})
+	if err != nil {
+		t.Fatalf("订阅推送结果失败: %v", err)
+	}
+
+	// 等待订阅登记后再发布结果
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		server.hub.mu.RLock()
+		subscribed := len(server.hub.subscribers) > 0
+		server.hub.mu.RUnlock()
+		if subscribed || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	notification := &push_service.PushNotification{Data: map[string]interface{}{"pinId": "pin-1"}}
+	server.HandleResult(notification, &push_service.PushResult{MetaID: "alice", Platform: "expo", Success: true, Timestamp: time.Now()})
+	server.HandleResult(notification, &push_service.PushResult{MetaID: "bob", Platform: "expo", Error: errors.New("boom"), Timestamp: time.Now()})
+	server.HandleResult(notification, &push_service.PushResult{MetaID: "alice", Platform: "expo", Error: errors.New("DeviceNotRegistered"), Timestamp: time.Now()})
+
+	result, err := stream.Recv()
+	if err != nil {
+		t.Fatalf("接收推送结果失败: %v", err)
+	}
+	if result.MetaId != "alice" || result.Success || result.PinId != "pin-1" || result.Error != "DeviceNotRegistered" {
+		t.Errorf("收到的推送结果 = %+v, want alice 的失败结果", result)
+	}
+}
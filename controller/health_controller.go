@@ -0,0 +1,34 @@
+package controller
+
+import (
+	"net/http"
+	"push-base-service/conf"
+	"push-base-service/service/disk_service"
+	"push-base-service/service/pebble_service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Readyz 就绪检查：推送中心存储未初始化或数据目录磁盘空间紧急（降级模式）时返回 503
+func Readyz(c *gin.Context) {
+	body := gin.H{"status": "ready"}
+	status := http.StatusOK
+
+	if conf.PushCenterEnabled {
+		if service := pebble_service.GetGlobalService(); service == nil || !service.IsInitialized() {
+			body["status"] = "unavailable"
+			body["reason"] = "Pebble 服务未初始化"
+			status = http.StatusServiceUnavailable
+		}
+	}
+	if disk := disk_service.CurrentStatus(); disk != nil {
+		body["disk"] = disk
+		if disk.State == disk_service.StateCritical && status == http.StatusOK {
+			body["status"] = "degraded"
+			body["reason"] = "数据目录磁盘空间紧急，已跳过非关键写入"
+			status = http.StatusServiceUnavailable
+		}
+	}
+
+	c.JSON(status, body)
+}
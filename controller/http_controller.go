@@ -2,9 +2,12 @@ package controller
 
 import (
 	"fmt"
+	"log"
 	"net/http"
 	"push-base-service/conf"
 	"push-base-service/controller/auth"
+	"push-base-service/controller/respond"
+	"push-base-service/models"
 
 	_ "push-base-service/docs" // 导入生成的 swagger 文档
 
@@ -21,37 +24,156 @@ func Run() {
 	//fmt.Println("Redis connect success for ip rate.")
 
 	router := gin.Default()
+	if err := auth.ConfigureTrustedProxies(router); err != nil {
+		log.Fatalf("❌ trusted_proxies 配置无效: %v", err)
+	}
+	if !conf.ProtectUserEndpoints {
+		log.Printf("⚠️ protect_user_endpoints 已关闭，令牌、屏蔽聊天和偏好接口不校验 API Key")
+	}
 	router.Use(Cors())
 	router.Use(Logger())
 	//router.Use(middleware.ResponseTime())
 
-	//limiter := middleware.NewIPRateLimiter(100*time.Millisecond, 1000*1000*1000*1000)
-	//router.Use(middleware.IPRateLimitMiddleware(limiter))
-
 	// Swagger 文档路由
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
-	v1 := router.Group("/v1")
-	{
-		// 应用 API Key 鉴权中间件到所有 Push API 路由
-		pushGroup := v1.Group("/push")
-		{
-			pushGroup.POST("/set_user_tokens", auth.AuthSignMiddleware(), SetUserTokens)
-			// pushGroup.POST("/set_user_tokens", SetUserTokens)
-			pushGroup.GET("/get_user_token", GetUserTokenByMetaID)
-			pushGroup.GET("/get_user_tokens_list", GetUserTokensList)
-			pushGroup.POST("/remove_user_token", RemoveUserToken)
-			pushGroup.POST("/remove_user_all_tokens", RemoveUserAllTokens)
-
-			pushGroup.GET("/get_user_blocked_chats", GetUserBlockedChats)
-			pushGroup.POST("/add_blocked_chat", AddBlockedChat)
-			pushGroup.POST("/remove_blocked_chat", RemoveBlockedChat)
-		}
+	// Prometheus 监控指标
+	router.GET("/metrics", Metrics)
+
+	// 就绪检查（存储未初始化或磁盘空间紧急时返回 503）
+	router.GET("/readyz", Readyz)
+
+	// 内置管理看板页面（数据通过管理接口获取，需 admin 权限 API Key）
+	if conf.AdminDashboardEnabled {
+		router.GET("/admin", AdminDashboard)
 	}
 
+	// v1 保持原有响应格式；v2 与 v1 接口相同，响应使用 v2 信封并统一字段命名
+	registerAPIRoutes(router.Group("/v1"))
+	registerAPIRoutes(router.Group("/v2", respond.V2(conf.APIV2FieldNaming)))
+
 	_ = router.Run(fmt.Sprintf("0.0.0.0:%s", conf.Port))
 }
 
+// registerAPIRoutes 注册推送与管理接口
+func registerAPIRoutes(api *gin.RouterGroup) {
+	// Push API 按权限范围鉴权：read 查询、write 修改与发送、admin 管理
+	// 令牌、屏蔽聊天、偏好等用户自助接口默认同样要求 API Key，显式关闭 protect_user_endpoints 后开放给客户端，
+	// 修改类接口默认还需由对应用户签名（已通过 write 权限校验的 API Key 调用除外）；开启 rate_limit 后按 IP 和 API Key 限流
+	pushGroup := api.Group("/push", auth.RateLimitMiddleware())
+	{
+		pushGroup.POST("/set_user_tokens", auth.AuthSignMiddleware(), SetUserTokens)
+		// pushGroup.POST("/set_user_tokens", SetUserTokens)
+
+		userReadGroup := pushGroup.Group("", auth.UserEndpointScope(models.APIKeyScopeRead))
+		userReadGroup.GET("/get_user_token", GetUserTokenByMetaID)
+		userReadGroup.GET("/get_user_tokens_list", GetUserTokensList)
+		userReadGroup.GET("/get_user_blocked_chats", GetUserBlockedChats)
+		userReadGroup.GET("/get_user_blocked_senders", GetUserBlockedSenders)
+		userReadGroup.GET("/get_all_groups_block", GetAllGroupsBlock)
+		userReadGroup.GET("/get_user_chat_sounds", GetUserChatSounds)
+		userReadGroup.GET("/get_user_preferences", GetUserPreferences)
+		userReadGroup.GET("/get_notification_categories", GetNotificationCategories)
+		userReadGroup.GET("/get_subscription", GetSubscription)
+
+		userWriteGroup := pushGroup.Group("", auth.UserEndpointScope(models.APIKeyScopeWrite), auth.UserSignatureMiddleware())
+		userWriteGroup.POST("/remove_user_token", RemoveUserToken)
+		userWriteGroup.POST("/remove_user_all_tokens", RemoveUserAllTokens)
+		userWriteGroup.POST("/add_blocked_chat", AddBlockedChat)
+		userWriteGroup.POST("/remove_blocked_chat", RemoveBlockedChat)
+		userWriteGroup.POST("/batch_blocked_chats", BatchBlockedChats)
+		userWriteGroup.POST("/add_blocked_sender", AddBlockedSender)
+		userWriteGroup.POST("/remove_blocked_sender", RemoveBlockedSender)
+		userWriteGroup.POST("/block_all_groups", BlockAllGroups)
+		userWriteGroup.POST("/unblock_all_groups", UnblockAllGroups)
+		userWriteGroup.POST("/set_chat_sound", SetChatSound)
+		userWriteGroup.POST("/set_user_preferences", SetUserPreferences)
+		userWriteGroup.POST("/pause_notifications", PauseNotifications)
+		userWriteGroup.POST("/resume_notifications", ResumeNotifications)
+		userWriteGroup.POST("/set_notification_categories", SetNotificationCategories)
+		userWriteGroup.POST("/unsubscribe", Unsubscribe)
+		userWriteGroup.POST("/resubscribe", Resubscribe)
+
+		readGroup := pushGroup.Group("", auth.RequireScope(models.APIKeyScopeRead))
+		readGroup.GET("/get_scheduled_pushes", GetScheduledPushes)
+		readGroup.GET("/delivery_status", GetDeliveryStatus)
+		readGroup.GET("/user_push_history", GetUserPushHistory)
+
+		writeGroup := pushGroup.Group("", auth.RequireScope(models.APIKeyScopeWrite))
+		writeGroup.POST("/send", SendPush)
+		writeGroup.POST("/send_data", SendDataPush)
+		writeGroup.POST("/test_push", TestPush)
+		writeGroup.POST("/schedule", SchedulePush)
+		writeGroup.POST("/schedule_local_time", SchedulePushLocalTime)
+		writeGroup.POST("/cancel_schedule", CancelScheduledPush)
+		// 被遗忘权删除不可恢复，只对持有 write 权限 Key 的后台开放，不走用户自助接口
+		writeGroup.POST("/delete_user_data", DeleteUserData)
+	}
+
+	// HTTP 进件接口，使用 HMAC 签名代替 X-API-KEY，不限流（上游以 Socket 相同的速率投递），未开启时不注册
+	if conf.IngestEnabled {
+		ingestGroup := api.Group("/ingest", auth.IngestSignatureMiddleware())
+		ingestGroup.POST("/chat_message", IngestChatMessage)
+	}
+
+	// 管理类接口，要求 admin 权限的 X-API-KEY
+	adminGroup := api.Group("/admin", auth.RequireScope(models.APIKeyScopeAdmin))
+	{
+		adminGroup.GET("/events", StreamPipelineEvents)
+		adminGroup.GET("/dashboard", GetDashboard)
+		adminGroup.POST("/set_tenant_webhook", SetTenantWebhook)
+		adminGroup.GET("/get_tenant_webhooks", GetTenantWebhooks)
+		adminGroup.POST("/remove_tenant_webhook", RemoveTenantWebhook)
+		adminGroup.POST("/set_user_tenant", SetUserTenant)
+		adminGroup.POST("/set_tenant_quota", SetTenantQuota)
+		adminGroup.GET("/get_tenant_usage", GetTenantUsage)
+		adminGroup.GET("/stats", AdminStats)
+		adminGroup.GET("/provider_stats", GetProviderStats)
+		adminGroup.GET("/delivery_breakdown", GetDeliveryBreakdown)
+		adminGroup.GET("/db_stats", GetDBStats)
+		adminGroup.POST("/compact", CompactDB)
+		adminGroup.GET("/version", GetVersion)
+		adminGroup.GET("/get_api_keys", GetAPIKeys)
+		adminGroup.POST("/create_api_key", CreateAPIKey)
+		adminGroup.POST("/revoke_api_key", RevokeAPIKey)
+		adminGroup.POST("/merge_users", MergeUsers)
+		adminGroup.GET("/get_user_merges", GetUserMerges)
+		adminGroup.GET("/get_quarantined_messages", GetQuarantinedMessages)
+		adminGroup.POST("/start_user_trace", StartUserTrace)
+		adminGroup.POST("/stop_user_trace", StopUserTrace)
+		adminGroup.GET("/get_user_traces", GetUserTraces)
+		adminGroup.GET("/get_user_trace", GetUserTrace)
+		adminGroup.POST("/clear_quarantine", ClearQuarantine)
+		adminGroup.POST("/requeue_quarantined", RequeueQuarantined)
+		adminGroup.POST("/replay_message", ReplayMessage)
+		adminGroup.GET("/get_routing_rules", GetRoutingRules)
+		adminGroup.POST("/set_routing_rules", SetRoutingRules)
+		adminGroup.POST("/reset_routing_rules", ResetRoutingRules)
+		adminGroup.GET("/enabled_types", GetEnabledTypes)
+		adminGroup.PUT("/enabled_types", SetEnabledTypes)
+		adminGroup.POST("/reload_config", ReloadConfig)
+		adminGroup.POST("/set_qa_account", SetQAAccount)
+		adminGroup.POST("/remove_qa_account", RemoveQAAccount)
+		adminGroup.GET("/get_qa_accounts", GetQAAccounts)
+		adminGroup.GET("/get_qa_inbox", GetQAInbox)
+		adminGroup.POST("/clear_qa_inbox", ClearQAInbox)
+		adminGroup.GET("/jobs", GetJobs)
+		adminGroup.GET("/jobs/:id", GetJob)
+		adminGroup.POST("/jobs/:id/cancel", CancelJob)
+		adminGroup.POST("/backup", CreateBackup)
+		adminGroup.GET("/get_backups", GetBackups)
+		adminGroup.POST("/restore", RestoreBackup)
+		adminGroup.GET("/export", ExportData)
+		adminGroup.GET("/export_unsubscribes", ExportUnsubscribes)
+		adminGroup.POST("/import", ImportData)
+
+		// 预发环境的测试数据接口，生产环境不注册
+		if conf.StagingEnabled {
+			adminGroup.POST("/seed_test_data", SeedTestData)
+		}
+	}
+}
+
 func Cors() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		method := c.Request.Method
@@ -59,7 +181,7 @@ func Cors() gin.HandlerFunc {
 		//if origin != "" {
 		c.Header("Access-Control-Allow-Origin", "*")
 		c.Header("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, DELETE")
-		c.Header("Access-Control-Allow-Headers", "Content-Type,AccessToken,X-CSRF-Token, Authorization,X-API-KEY,X-Signature,X-Public-Key")
+		c.Header("Access-Control-Allow-Headers", "Content-Type,AccessToken,X-CSRF-Token, Authorization,X-API-KEY,X-Signature,X-Public-Key,X-Timestamp,X-Nonce")
 		c.Header("Access-Control-Allow-Credentials", "true")
 		c.Set("content-type", "application/json")
 		//}
@@ -0,0 +1,42 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"push-base-service/controller/respond"
+	pushcenter "push-base-service/service/push_center"
+	"push-base-service/tool"
+
+	"github.com/gin-gonic/gin"
+)
+
+// IngestChatMessage godoc
+// @Summary HTTP 进件聊天消息
+// @Description 供没有 Socket.IO 的上游投递聊天消息，也可在 Socket 断开时作为备用通道。请求体与 Socket 消息相同（SocketData：{"M": "WS_SERVER_NOTIFY_GROUP_CHAT", "D": {...}}），处理流程与 Socket 消息一致（去重、过滤、推送、记录），推送为异步执行。请求需 HMAC 签名：X-Timestamp 为 Unix 秒，X-Signature 为 "sha256=" + HMAC-SHA256(ingest.secret, timestamp + "." + 请求体) 的十六进制，同一签名只能使用一次
+// @Tags Ingest API
+// @Accept json
+// @Produce json
+// @Param X-Timestamp header string true "签名时间戳（Unix 秒）"
+// @Param X-Signature header string true "HMAC 签名"
+// @Param request body object true "SocketData 载荷"
+// @Success 200 {object} respond.Response{data=pushcenter.IngestResult} "成功响应"
+// @Failure 401 {object} respond.Response "签名校验失败"
+// @Failure 500 {object} respond.Response "服务器内部错误"
+// @Router /v1/ingest/chat_message [post]
+func IngestChatMessage(c *gin.Context) {
+	var t int64 = tool.MakeTimestamp()
+
+	payload, err := c.GetRawData()
+	if err != nil || len(payload) == 0 {
+		respond.JSONP(c, http.StatusInternalServerError, respond.RespErr(errors.New("参数错误"), tool.MakeTimestamp()-t, respond.HttpsCodeError))
+		return
+	}
+
+	result, err := pushcenter.IngestMessage(payload)
+	if err != nil {
+		respond.JSONP(c, http.StatusOK, respond.RespErr(err, tool.MakeTimestamp()-t, respond.HttpsCodeError))
+		return
+	}
+
+	respond.JSONP(c, http.StatusOK, respond.RespSuccess(result, tool.MakeTimestamp()-t))
+}
@@ -0,0 +1,95 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"push-base-service/controller/respond"
+	"push-base-service/service/job_service"
+	"push-base-service/service/pebble_service"
+	"push-base-service/tool"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetJobs godoc
+// @Summary 获取后台任务列表
+// @Description 按创建时间倒序返回后台任务（异步备份等）的状态、进度和结果，可按类型和状态过滤
+// @Tags Admin API
+// @Produce json
+// @Security ApiKeyAuth
+// @Param type query string false "任务类型（如 backup）"
+// @Param status query string false "任务状态（pending/running/succeeded/failed/canceled）"
+// @Param limit query int false "返回条数（默认10，最大100）"
+// @Success 200 {object} respond.Response{data=[]models.Job} "成功响应"
+// @Failure 401 {object} respond.Response "认证失败"
+// @Failure 500 {object} respond.Response "服务器内部错误"
+// @Router /v1/admin/jobs [get]
+func GetJobs(c *gin.Context) {
+	var t int64 = tool.MakeTimestamp()
+
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	jobs, err := pebble_service.ListJobs(c.Query("type"), c.Query("status"), limit)
+	if err != nil {
+		respond.JSONP(c, http.StatusOK, respond.RespErr(err, tool.MakeTimestamp()-t, respond.HttpsCodeError))
+		return
+	}
+
+	respond.JSONP(c, http.StatusOK, respond.RespSuccess(jobs, tool.MakeTimestamp()-t))
+}
+
+// GetJob godoc
+// @Summary 获取后台任务
+// @Description 按任务ID获取后台任务的状态、进度和结果
+// @Tags Admin API
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "任务ID"
+// @Success 200 {object} respond.Response{data=models.Job} "成功响应"
+// @Failure 401 {object} respond.Response "认证失败"
+// @Failure 500 {object} respond.Response "服务器内部错误"
+// @Router /v1/admin/jobs/{id} [get]
+func GetJob(c *gin.Context) {
+	var t int64 = tool.MakeTimestamp()
+
+	job, err := pebble_service.GetJob(c.Param("id"))
+	if err != nil {
+		respond.JSONP(c, http.StatusOK, respond.RespErr(err, tool.MakeTimestamp()-t, respond.HttpsCodeError))
+		return
+	}
+	if job == nil {
+		respond.JSONP(c, http.StatusOK, respond.RespErr(job_service.ErrJobNotFound, tool.MakeTimestamp()-t, respond.HttpsCodeError))
+		return
+	}
+
+	respond.JSONP(c, http.StatusOK, respond.RespSuccess(job, tool.MakeTimestamp()-t))
+}
+
+// CancelJob godoc
+// @Summary 取消后台任务
+// @Description 请求取消执行中的后台任务，任务在执行到可中断的位置后结束并标记为 canceled；已结束的任务不能取消
+// @Tags Admin API
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "任务ID"
+// @Success 200 {object} respond.Response{data=models.Job} "成功响应"
+// @Failure 401 {object} respond.Response "认证失败"
+// @Failure 500 {object} respond.Response "服务器内部错误"
+// @Router /v1/admin/jobs/{id}/cancel [post]
+func CancelJob(c *gin.Context) {
+	var t int64 = tool.MakeTimestamp()
+
+	runner := job_service.GetGlobalRunner()
+	if runner == nil {
+		respond.JSONP(c, http.StatusOK, respond.RespErr(errors.New("推送中心未启用"), tool.MakeTimestamp()-t, respond.HttpsCodeError))
+		return
+	}
+
+	job, err := runner.Cancel(c.Param("id"))
+	if err != nil {
+		respond.JSONP(c, http.StatusOK, respond.RespErr(err, tool.MakeTimestamp()-t, respond.HttpsCodeError))
+		return
+	}
+
+	respond.JSONP(c, http.StatusOK, respond.RespSuccess(job, tool.MakeTimestamp()-t))
+}
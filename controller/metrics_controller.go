@@ -0,0 +1,17 @@
+package controller
+
+import (
+	"net/http"
+	"push-base-service/service/metrics_service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Metrics 以 Prometheus 文本格式输出服务监控指标
+func Metrics(c *gin.Context) {
+	c.Header("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	c.Status(http.StatusOK)
+	if err := metrics_service.DefaultRegistry().WritePrometheus(c.Writer); err != nil {
+		_ = c.Error(err)
+	}
+}
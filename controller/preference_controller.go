@@ -0,0 +1,282 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"push-base-service/controller/request"
+	"push-base-service/controller/respond"
+	"push-base-service/models"
+	"push-base-service/service/pebble_service"
+	pushcenter "push-base-service/service/push_center"
+	"push-base-service/service/translate_service"
+	"push-base-service/tool"
+	"slices"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetUserPreferences godoc
+// @Summary 设置用户推送偏好
+// @Description 设置用户语言区域及是否翻译消息预览，muteCandyBags、muteFriendRequests、mutePayments 可分别关闭红包、好友请求和付款通知。开启 translatePreviews 后，推送中的消息预览会被翻译为 locale 指定的语言（需服务端启用 notification.preview_enabled 和 translation.enabled）
+// @Tags Push API
+// @Accept json
+// @Produce json
+// @Param request body request.SetUserPreferencesReq true "请求参数"
+// @Success 200 {object} respond.Response{data=models.UserPreferences} "成功响应"
+// @Failure 400 {object} respond.Response "参数错误"
+// @Failure 500 {object} respond.Response "服务器内部错误"
+// @Router /v1/push/set_user_preferences [post]
+func SetUserPreferences(c *gin.Context) {
+	var (
+		t            int64 = tool.MakeTimestamp()
+		requestModel *request.SetUserPreferencesReq
+	)
+
+	if err := c.ShouldBindJSON(&requestModel); err != nil {
+		respond.Fail(c, respond.BindError(err), tool.MakeTimestamp()-t)
+		return
+	}
+
+	locale := translate_service.NormalizeLocale(requestModel.Locale)
+	if requestModel.TranslatePreviews && locale == "" {
+		respond.Fail(c, respond.InvalidField("locale", errors.New("开启预览翻译时 locale 不能为空")), tool.MakeTimestamp()-t)
+		return
+	}
+
+	preferences := &models.UserPreferences{
+		MetaID:             requestModel.MetaID,
+		Locale:             locale,
+		TranslatePreviews:  requestModel.TranslatePreviews,
+		HidePreviews:       requestModel.HidePreviews,
+		MuteCandyBags:      requestModel.MuteCandyBags,
+		MuteFriendRequests: requestModel.MuteFriendRequests,
+		MutePayments:       requestModel.MutePayments,
+	}
+	// 保留通知暂停状态和通知类别，分别通过 pause_notifications / resume_notifications 和 set_notification_categories 设置
+	if existing, err := pebble_service.GetUserPreferences(requestModel.MetaID); err == nil && existing != nil {
+		preferences.PausedUntil = existing.PausedUntil
+		preferences.PauseSummary = existing.PauseSummary
+		preferences.PausedMissed = existing.PausedMissed
+		preferences.Categories = existing.Categories
+	}
+	if err := pebble_service.SaveUserPreferences(preferences); err != nil {
+		respond.Fail(c, err, tool.MakeTimestamp()-t)
+		return
+	}
+
+	respond.JSONP(c, http.StatusOK, respond.RespSuccess(preferences, tool.MakeTimestamp()-t))
+}
+
+// GetUserPreferences godoc
+// @Summary 获取用户推送偏好
+// @Description 根据用户 metaId 获取推送偏好，未设置时返回默认值（不翻译预览）
+// @Tags Push API
+// @Produce json
+// @Param metaId query string true "用户唯一标识"
+// @Success 200 {object} respond.Response{data=models.UserPreferences} "成功响应"
+// @Failure 400 {object} respond.Response "参数错误"
+// @Failure 500 {object} respond.Response "服务器内部错误"
+// @Router /v1/push/get_user_preferences [get]
+func GetUserPreferences(c *gin.Context) {
+	var t int64 = tool.MakeTimestamp()
+
+	metaId := c.Query("metaId")
+	if metaId == "" {
+		respond.Fail(c, respond.MissingParam("metaId"), tool.MakeTimestamp()-t)
+		return
+	}
+
+	preferences, err := pebble_service.GetUserPreferences(metaId)
+	if err != nil {
+		respond.Fail(c, err, tool.MakeTimestamp()-t)
+		return
+	}
+	if preferences == nil {
+		preferences = &models.UserPreferences{MetaID: metaId}
+	}
+
+	respond.JSONP(c, http.StatusOK, respond.RespSuccess(preferences, tool.MakeTimestamp()-t))
+}
+
+// 通知暂停参数
+const (
+	pauseUntilTomorrow = "tomorrow"          // 暂停到次日早上 8 点
+	maxPauseDuration   = 30 * 24 * time.Hour // 单次暂停的最长时间
+)
+
+// PauseNotifications godoc
+// @Summary 暂停用户通知
+// @Description 暂停用户的聊天通知直到指定时间后自动恢复（区别于永久屏蔽聊天）。duration 可为 1h、8h 等时间间隔或 tomorrow（次日早上 8 点，按 utcOffsetMinutes 换算用户时区），也可直接传 until（Unix 秒）。暂停期间的消息不推送，开启 summary 时恢复后发送"错过 N 条消息"的汇总通知
+// @Tags Push API
+// @Accept json
+// @Produce json
+// @Param request body request.PauseNotificationsReq true "请求参数"
+// @Success 200 {object} respond.Response{data=models.UserPreferences} "成功响应"
+// @Failure 500 {object} respond.Response "服务器内部错误"
+// @Router /v1/push/pause_notifications [post]
+func PauseNotifications(c *gin.Context) {
+	var (
+		t            int64 = tool.MakeTimestamp()
+		requestModel *request.PauseNotificationsReq
+	)
+
+	if err := c.ShouldBindJSON(&requestModel); err != nil {
+		respond.Fail(c, respond.BindError(err), tool.MakeTimestamp()-t)
+		return
+	}
+
+	until, err := pauseUntil(requestModel, time.Now())
+	if err != nil {
+		respond.Fail(c, respond.InvalidParam(err), tool.MakeTimestamp()-t)
+		return
+	}
+
+	preferences, err := pebble_service.PauseNotifications(requestModel.MetaID, until, requestModel.Summary)
+	if err != nil {
+		respond.Fail(c, err, tool.MakeTimestamp()-t)
+		return
+	}
+
+	respond.JSONP(c, http.StatusOK, respond.RespSuccess(preferences, tool.MakeTimestamp()-t))
+}
+
+// ResumeNotifications godoc
+// @Summary 立即恢复用户通知
+// @Description 取消通知暂停；手动恢复时不发送错过消息的汇总通知
+// @Tags Push API
+// @Accept json
+// @Produce json
+// @Param request body request.ResumeNotificationsReq true "请求参数"
+// @Success 200 {object} respond.Response "成功响应，data 为 {resumed, missed}"
+// @Failure 500 {object} respond.Response "服务器内部错误"
+// @Router /v1/push/resume_notifications [post]
+func ResumeNotifications(c *gin.Context) {
+	var (
+		t            int64 = tool.MakeTimestamp()
+		requestModel *request.ResumeNotificationsReq
+	)
+
+	if err := c.ShouldBindJSON(&requestModel); err != nil {
+		respond.Fail(c, respond.BindError(err), tool.MakeTimestamp()-t)
+		return
+	}
+
+	previous, err := pebble_service.ResumeNotifications(requestModel.MetaID)
+	if err != nil {
+		respond.Fail(c, err, tool.MakeTimestamp()-t)
+		return
+	}
+
+	responseData := map[string]interface{}{
+		"resumed": previous != nil,
+		"missed":  0,
+	}
+	if previous != nil {
+		responseData["missed"] = previous.PausedMissed
+	}
+
+	respond.JSONP(c, http.StatusOK, respond.RespSuccess(responseData, tool.MakeTimestamp()-t))
+}
+
+// pauseUntil 计算暂停截止时间：until 优先，其次 duration（时间间隔或 tomorrow）
+func pauseUntil(requestModel *request.PauseNotificationsReq, now time.Time) (int64, error) {
+	var until time.Time
+	switch {
+	case requestModel.Until > 0:
+		until = time.Unix(requestModel.Until, 0)
+	case requestModel.Duration == pauseUntilTomorrow:
+		// 次日早上 8 点（用户本地时间）
+		offset := time.Duration(requestModel.UTCOffsetMinutes) * time.Minute
+		local := now.UTC().Add(offset)
+		until = time.Date(local.Year(), local.Month(), local.Day()+1, 8, 0, 0, 0, time.UTC).Add(-offset)
+	case requestModel.Duration != "":
+		duration, err := time.ParseDuration(requestModel.Duration)
+		if err != nil || duration <= 0 {
+			return 0, fmt.Errorf("duration 无效: %s（如 1h、8h 或 tomorrow）", requestModel.Duration)
+		}
+		until = now.Add(duration)
+	default:
+		return 0, errors.New("duration 和 until 不能同时为空")
+	}
+
+	if !until.After(now) {
+		return 0, errors.New("暂停截止时间必须晚于当前时间")
+	}
+	if until.After(now.Add(maxPauseDuration)) {
+		return 0, fmt.Errorf("暂停时长不能超过 %v", maxPauseDuration)
+	}
+	return until.Unix(), nil
+}
+
+// GetNotificationCategories godoc
+// @Summary 获取可操作通知的类别
+// @Description 返回服务端定义的通知类别及操作按钮（message：回复、标为已读、静音；mention：回复、标为已读；candy_bag：打开、静音），客户端据此向系统注册类别。传 metaId 时同时返回该用户已注册的类别。静音操作由客户端调用 /v1/push/add_blocked_chat 屏蔽通知 data 中的 groupId 或 metaId
+// @Tags Push API
+// @Produce json
+// @Param metaId query string false "用户唯一标识"
+// @Success 200 {object} respond.Response "成功响应，data 为 {categories, registered}"
+// @Failure 500 {object} respond.Response "服务器内部错误"
+// @Router /v1/push/get_notification_categories [get]
+func GetNotificationCategories(c *gin.Context) {
+	var t int64 = tool.MakeTimestamp()
+
+	registered := []string{}
+	if metaId := c.Query("metaId"); metaId != "" {
+		preferences, err := pebble_service.GetUserPreferences(metaId)
+		if err != nil {
+			respond.Fail(c, err, tool.MakeTimestamp()-t)
+			return
+		}
+		if preferences != nil && preferences.Categories != nil {
+			registered = preferences.Categories
+		}
+	}
+
+	respond.JSONP(c, http.StatusOK, respond.RespSuccess(gin.H{
+		"categories": pushcenter.NotificationCategories(),
+		"registered": registered,
+	}, tool.MakeTimestamp()-t))
+}
+
+// SetNotificationCategories godoc
+// @Summary 注册客户端可处理的通知类别
+// @Description 客户端向系统注册通知类别后调用，服务端开启 notification.actions 时只为已注册类别的通知设置 categoryId（iOS aps.category / Expo categoryId），用户可直接在通知上回复或静音。整体替换已注册的类别，传空数组表示不再接收带操作的通知
+// @Tags Push API
+// @Accept json
+// @Produce json
+// @Param request body request.SetNotificationCategoriesReq true "请求参数"
+// @Success 200 {object} respond.Response{data=models.UserPreferences} "成功响应"
+// @Failure 500 {object} respond.Response "服务器内部错误"
+// @Router /v1/push/set_notification_categories [post]
+func SetNotificationCategories(c *gin.Context) {
+	var (
+		t            int64 = tool.MakeTimestamp()
+		requestModel *request.SetNotificationCategoriesReq
+	)
+
+	if err := c.ShouldBindJSON(&requestModel); err != nil {
+		respond.Fail(c, respond.BindError(err), tool.MakeTimestamp()-t)
+		return
+	}
+
+	categories := make([]string, 0, len(requestModel.Categories))
+	for _, category := range requestModel.Categories {
+		if !pushcenter.IsNotificationCategory(category) {
+			respond.Fail(c, respond.InvalidField("categories", fmt.Errorf("未知的通知类别: %s", category)), tool.MakeTimestamp()-t)
+			return
+		}
+		if !slices.Contains(categories, category) {
+			categories = append(categories, category)
+		}
+	}
+
+	preferences, err := pebble_service.SetNotificationCategories(requestModel.MetaID, categories)
+	if err != nil {
+		respond.Fail(c, err, tool.MakeTimestamp()-t)
+		return
+	}
+
+	respond.JSONP(c, http.StatusOK, respond.RespSuccess(preferences, tool.MakeTimestamp()-t))
+}
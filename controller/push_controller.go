@@ -2,24 +2,31 @@ package controller
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 	"push-base-service/controller/request"
 	"push-base-service/controller/respond"
+	"push-base-service/models"
 	"push-base-service/service/pebble_service"
+	"push-base-service/service/storage_service"
+	"push-base-service/service/translate_service"
 	"push-base-service/tool"
+	"regexp"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
 // SetUserTokens godoc
 // @Summary 设置用户推送令牌
-// @Description 为指定用户在指定平台设置推送令牌，支持Token唯一性检查。Token本身就是设备的唯一标识，如果Token已被其他用户使用，会自动从原用户中移除该平台的令牌。
+// @Description 为指定用户在指定平台设置推送令牌，支持Token唯一性检查。platform 可为 expo、email，桌面客户端使用 macos（APNs 设备令牌）或 windows（WNS 通道 URI）。Token本身就是设备的唯一标识，如果Token已被其他用户使用，会自动从原用户中移除该平台的令牌。用户所属租户不能通过此接口设置，由管理接口 set_user_tenant 指定。
+// @Description 可同时上报设备信息（appVersion、osVersion、deviceModel、locale、timezone），每次调用都会更新设备的 lastSeenAt；用户未设置语言偏好时，预览翻译使用最近活跃设备的 locale。
 // @Tags Push API
 // @Accept json
 // @Produce json
 // @Security ApiKeyAuth
-// @Param request body request.SetUserTokensReq true "请求参数（metaId、platform、token）"
+// @Param request body request.SetUserTokensReq true "请求参数（metaId、platform、token，可选设备信息）"
 // @Success 200 {object} respond.Response "成功响应"
 // @Failure 400 {object} respond.Response "参数错误"
 // @Failure 401 {object} respond.Response "认证失败"
@@ -31,25 +38,45 @@ func SetUserTokens(c *gin.Context) {
 		requestModel *request.SetUserTokensReq
 	)
 
-	if c.ShouldBindJSON(&requestModel) == nil {
-		// 调用 push_service 的方法（token作为设备ID）
-		err := pebble_service.SetUserToken(requestModel.MetaID, requestModel.Platform, requestModel.Token)
-		if err != nil {
-			c.JSONP(http.StatusOK, respond.RespErr(err, tool.MakeTimestamp()-t, respond.HttpsCodeError))
+	if err := c.ShouldBindJSON(&requestModel); err != nil {
+		respond.Fail(c, respond.BindError(err), tool.MakeTimestamp()-t)
+		return
+	}
+
+	if requestModel.Timezone != "" {
+		if _, err := time.LoadLocation(requestModel.Timezone); err != nil {
+			respond.Fail(c, respond.InvalidField("timezone", fmt.Errorf("timezone 无效: %s（IANA 时区名，如 Asia/Shanghai）", requestModel.Timezone)), tool.MakeTimestamp()-t)
 			return
 		}
+	}
 
-		// 构造成功响应
-		responseData := map[string]interface{}{
-			"success": true,
-			"message": "用户令牌设置成功",
-		}
+	// 调用 push_service 的方法（token作为设备ID）
+	err := storage_service.SetUserToken(requestModel.MetaID, requestModel.Platform, requestModel.Token)
+	if err != nil {
+		respond.Fail(c, err, tool.MakeTimestamp()-t)
+		return
+	}
 
-		c.JSONP(http.StatusOK, respond.RespSuccess(responseData, tool.MakeTimestamp()-t))
+	// 记录设备信息和最近活跃时间
+	metadata := models.DeviceMetadata{
+		AppVersion:  requestModel.AppVersion,
+		OSVersion:   requestModel.OSVersion,
+		DeviceModel: requestModel.DeviceModel,
+		Locale:      translate_service.NormalizeLocale(requestModel.Locale),
+		Timezone:    requestModel.Timezone,
+	}
+	if _, err := pebble_service.SetDeviceMetadata(requestModel.Token, requestModel.Platform, requestModel.MetaID, metadata); err != nil {
+		respond.Fail(c, err, tool.MakeTimestamp()-t)
 		return
 	}
 
-	c.JSONP(http.StatusInternalServerError, respond.RespErr(errors.New("参数错误"), tool.MakeTimestamp()-t, respond.HttpsCodeError))
+	// 构造成功响应
+	responseData := map[string]interface{}{
+		"success": true,
+		"message": "用户令牌设置成功",
+	}
+
+	respond.JSONP(c, http.StatusOK, respond.RespSuccess(responseData, tool.MakeTimestamp()-t))
 }
 
 // GetUserTokenByMetaID godoc
@@ -61,6 +88,7 @@ func SetUserTokens(c *gin.Context) {
 // @Success 200 {object} respond.Response{data=models.UserPushTokens} "成功响应"
 // @Failure 400 {object} respond.Response "参数错误"
 // @Failure 401 {object} respond.Response "认证失败"
+// @Failure 404 {object} respond.Response "用户没有登记推送令牌"
 // @Failure 500 {object} respond.Response "服务器内部错误"
 // @Router /v1/push/get_user_token [get]
 func GetUserTokenByMetaID(c *gin.Context) {
@@ -69,27 +97,37 @@ func GetUserTokenByMetaID(c *gin.Context) {
 	// 从 query 参数获取 metaId
 	metaId := c.Query("metaId")
 	if metaId == "" {
-		c.JSONP(http.StatusOK, respond.RespErr(errors.New("metaId 参数不能为空"), tool.MakeTimestamp()-t, respond.HttpsCodeError))
+		respond.Fail(c, respond.MissingParam("metaId"), tool.MakeTimestamp()-t)
 		return
 	}
 
-	// 调用 pebble_service 的方法
-	userTokens, err := pebble_service.GetUserTokenByMetaID(metaId)
+	// 调用 storage_service 的方法
+	userTokens, err := storage_service.GetUserTokenByMetaID(metaId)
 	if err != nil {
-		c.JSONP(http.StatusOK, respond.RespErr(err, tool.MakeTimestamp()-t, respond.HttpsCodeError))
+		respond.Fail(c, err, tool.MakeTimestamp()-t)
+		return
+	}
+	if len(userTokens.Tokens) == 0 && len(userTokens.StaleTokens) == 0 {
+		respond.Fail(c, respond.NotFound(fmt.Errorf("用户 %s 没有登记推送令牌", metaId)), tool.MakeTimestamp()-t)
 		return
 	}
 
-	c.JSONP(http.StatusOK, respond.RespSuccess(userTokens, tool.MakeTimestamp()-t))
+	respond.JSONP(c, http.StatusOK, respond.RespSuccess(userTokens, tool.MakeTimestamp()-t))
 }
 
 // GetUserTokensList godoc
 // @Summary 获取用户推送令牌列表（分页）
-// @Description 分页获取所有用户的推送令牌列表
+// @Description 分页获取所有用户的推送令牌列表，按 metaId 排序。传 cursor 参数时使用游标分页：首页传空 cursor，之后传上一页返回的 nextCursor，直到 hasNext 为 false；游标分页不统计总数，适合用户量大时遍历。页码分页的响应同样返回 nextCursor，可随时切换为游标分页。
+// @Description 传任一筛选参数（platform、metaIdPrefix、updatedAfter、updatedBefore）时使用游标分页，多个条件同时满足；带更新时间条件时按更新时间排序。翻页时需保持筛选参数不变
 // @Tags Push API
 // @Produce json
-// @Param page query int false "页码，默认为1" default(1)
-// @Param pageSize query int false "每页大小，默认为10" default(10)
+// @Param page query int false "页码，默认为1（游标分页时忽略）" default(1)
+// @Param pageSize query int false "每页大小，默认为10，最大100" default(10)
+// @Param cursor query string false "游标，传空字符串表示从头开始"
+// @Param platform query string false "只返回拥有该平台令牌的用户，如 expo、fcm"
+// @Param metaIdPrefix query string false "metaId 前缀"
+// @Param updatedAfter query int false "更新时间晚于（Unix 秒）"
+// @Param updatedBefore query int false "更新时间早于（Unix 秒）"
 // @Success 200 {object} respond.Response{data=pebble_service.PaginatedUserTokens} "成功响应"
 // @Failure 400 {object} respond.Response "参数错误"
 // @Failure 401 {object} respond.Response "认证失败"
@@ -114,14 +152,40 @@ func GetUserTokensList(c *gin.Context) {
 		}
 	}
 
-	// 调用 pebble_service 的方法
-	result, err := pebble_service.GetUserTokensList(page, pageSize)
+	filter := pebble_service.UserTokensFilter{
+		Platform:     c.Query("platform"),
+		MetaIDPrefix: c.Query("metaIdPrefix"),
+	}
+	for _, param := range []struct {
+		name   string
+		target *int64
+	}{{"updatedAfter", &filter.UpdatedAfter}, {"updatedBefore", &filter.UpdatedBefore}} {
+		if value := c.Query(param.name); value != "" {
+			timestamp, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || timestamp < 0 {
+				respond.Fail(c, respond.InvalidField(param.name, fmt.Errorf("%s 无效: %s（Unix 秒）", param.name, value)), tool.MakeTimestamp()-t)
+				return
+			}
+			*param.target = timestamp
+		}
+	}
+
+	// 调用 storage_service 的方法，带 cursor 参数或筛选条件时使用游标分页
+	var (
+		result *pebble_service.PaginatedUserTokens
+		err    error
+	)
+	if cursor, ok := c.GetQuery("cursor"); ok || !filter.IsEmpty() {
+		result, err = storage_service.GetUserTokensAfter(filter, cursor, pageSize)
+	} else {
+		result, err = storage_service.GetUserTokensList(page, pageSize)
+	}
 	if err != nil {
-		c.JSONP(http.StatusOK, respond.RespErr(err, tool.MakeTimestamp()-t, respond.HttpsCodeError))
+		respond.Fail(c, err, tool.MakeTimestamp()-t)
 		return
 	}
 
-	c.JSONP(http.StatusOK, respond.RespSuccess(result, tool.MakeTimestamp()-t))
+	respond.JSONP(c, http.StatusOK, respond.RespSuccess(result, tool.MakeTimestamp()-t))
 }
 
 // RemoveUserToken godoc
@@ -142,25 +206,25 @@ func RemoveUserToken(c *gin.Context) {
 		requestModel *request.RemoveUserTokenReq
 	)
 
-	if c.ShouldBindJSON(&requestModel) == nil {
-		// 调用 pebble_service 的方法
-		err := pebble_service.RemoveUserToken(requestModel.MetaID, requestModel.Platform)
-		if err != nil {
-			c.JSONP(http.StatusOK, respond.RespErr(err, tool.MakeTimestamp()-t, respond.HttpsCodeError))
-			return
-		}
-
-		// 构造成功响应
-		responseData := map[string]interface{}{
-			"success": true,
-			"message": "用户令牌移除成功",
-		}
+	if err := c.ShouldBindJSON(&requestModel); err != nil {
+		respond.Fail(c, respond.BindError(err), tool.MakeTimestamp()-t)
+		return
+	}
 
-		c.JSONP(http.StatusOK, respond.RespSuccess(responseData, tool.MakeTimestamp()-t))
+	// 调用 storage_service 的方法
+	err := storage_service.RemoveUserToken(requestModel.MetaID, requestModel.Platform)
+	if err != nil {
+		respond.Fail(c, err, tool.MakeTimestamp()-t)
 		return
 	}
 
-	c.JSONP(http.StatusInternalServerError, respond.RespErr(errors.New("参数错误"), tool.MakeTimestamp()-t, respond.HttpsCodeError))
+	// 构造成功响应
+	responseData := map[string]interface{}{
+		"success": true,
+		"message": "用户令牌移除成功",
+	}
+
+	respond.JSONP(c, http.StatusOK, respond.RespSuccess(responseData, tool.MakeTimestamp()-t))
 }
 
 // RemoveUserAllTokens godoc
@@ -181,36 +245,72 @@ func RemoveUserAllTokens(c *gin.Context) {
 		requestModel *request.RemoveUserAllTokensReq
 	)
 
-	if c.ShouldBindJSON(&requestModel) == nil {
-		// 调用 pebble_service 的方法
-		err := pebble_service.RemoveUserAllTokens(requestModel.MetaID)
-		if err != nil {
-			c.JSONP(http.StatusOK, respond.RespErr(err, tool.MakeTimestamp()-t, respond.HttpsCodeError))
-			return
-		}
+	if err := c.ShouldBindJSON(&requestModel); err != nil {
+		respond.Fail(c, respond.BindError(err), tool.MakeTimestamp()-t)
+		return
+	}
 
-		// 构造成功响应
-		responseData := map[string]interface{}{
-			"success": true,
-			"message": "用户所有令牌移除成功",
-		}
+	// 调用 storage_service 的方法
+	err := storage_service.RemoveUserAllTokens(requestModel.MetaID)
+	if err != nil {
+		respond.Fail(c, err, tool.MakeTimestamp()-t)
+		return
+	}
+
+	// 构造成功响应
+	responseData := map[string]interface{}{
+		"success": true,
+		"message": "用户所有令牌移除成功",
+	}
+
+	respond.JSONP(c, http.StatusOK, respond.RespSuccess(responseData, tool.MakeTimestamp()-t))
+}
+
+// DeleteUserData godoc
+// @Summary 删除用户全部数据
+// @Description 用于被遗忘权请求：一次删除用户在所有集合中的数据，包括推送令牌和设备、屏蔽聊天、屏蔽发送者、聊天通知声音、推送偏好、退订原因和历史（仍处于退订状态时保留只含退订标记的记录，suppressionKept 为 true，删除后不会恢复推送）、投递记录（含提及通知的投递历史）、推送审计、推送追踪、QA 收件箱、最近活跃用户列表中的记录和该用户参与的合并记录，返回各类数据的删除数量。删除不回滚，中途失败时返回错误，可再次调用（删除是幂等的）。需要 write 权限的 API Key（不受 protect_user_endpoints 影响）。
+// @Tags Push API
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body request.DeleteUserDataReq true "请求参数"
+// @Success 200 {object} respond.Response{data=models.UserDataDeletionReport} "成功响应，data 为删除报告"
+// @Failure 400 {object} respond.Response "参数错误"
+// @Failure 401 {object} respond.Response "认证失败"
+// @Failure 403 {object} respond.Response "权限不足"
+// @Failure 500 {object} respond.Response "服务器内部错误"
+// @Router /v1/push/delete_user_data [post]
+func DeleteUserData(c *gin.Context) {
+	var (
+		t            int64 = tool.MakeTimestamp()
+		requestModel *request.DeleteUserDataReq
+	)
 
-		c.JSONP(http.StatusOK, respond.RespSuccess(responseData, tool.MakeTimestamp()-t))
+	if err := c.ShouldBindJSON(&requestModel); err != nil {
+		respond.Fail(c, respond.BindError(err), tool.MakeTimestamp()-t)
 		return
 	}
 
-	c.JSONP(http.StatusInternalServerError, respond.RespErr(errors.New("参数错误"), tool.MakeTimestamp()-t, respond.HttpsCodeError))
+	report, err := storage_service.DeleteUserState(requestModel.MetaID)
+	if err != nil {
+		respond.Fail(c, err, tool.MakeTimestamp()-t)
+		return
+	}
+
+	respond.JSONP(c, http.StatusOK, respond.RespSuccess(report, tool.MakeTimestamp()-t))
 }
 
 // ===== 屏蔽聊天相关API接口 =====
 
 // GetUserBlockedChats godoc
 // @Summary 获取用户屏蔽聊天列表
-// @Description 根据用户 metaId 获取该用户屏蔽的聊天列表
+// @Description 根据用户 metaId 分页获取该用户屏蔽的聊天列表，按聊天ID排序。首页不传 cursor，之后传上一页返回的 nextCursor，直到 hasNext 为 false
 // @Tags Push API
 // @Produce json
 // @Param metaId query string true "用户唯一标识"
-// @Success 200 {object} respond.Response{data=models.UserBlockedChats} "成功响应"
+// @Param pageSize query int false "每页大小，默认为100，最大500" default(100)
+// @Param cursor query string false "游标，为空表示从头开始"
+// @Success 200 {object} respond.Response{data=models.BlockedChatsPage} "成功响应"
 // @Failure 400 {object} respond.Response "参数错误"
 // @Failure 401 {object} respond.Response "认证失败"
 // @Failure 500 {object} respond.Response "服务器内部错误"
@@ -221,23 +321,30 @@ func GetUserBlockedChats(c *gin.Context) {
 	// 从 query 参数获取 metaId
 	metaId := c.Query("metaId")
 	if metaId == "" {
-		c.JSONP(http.StatusOK, respond.RespErr(errors.New("metaId 参数不能为空"), tool.MakeTimestamp()-t, respond.HttpsCodeError))
+		respond.Fail(c, respond.MissingParam("metaId"), tool.MakeTimestamp()-t)
 		return
 	}
 
-	// 调用 pebble_service 的方法
-	userBlockedChats, err := pebble_service.GetUserBlockedChats(metaId)
+	pageSize := 0
+	if pageSizeStr := c.Query("pageSize"); pageSizeStr != "" {
+		if ps, err := strconv.Atoi(pageSizeStr); err == nil && ps > 0 {
+			pageSize = ps
+		}
+	}
+
+	// 调用 storage_service 的方法
+	page, err := storage_service.ListBlockedChats(metaId, c.Query("cursor"), pageSize)
 	if err != nil {
-		c.JSONP(http.StatusOK, respond.RespErr(err, tool.MakeTimestamp()-t, respond.HttpsCodeError))
+		respond.Fail(c, err, tool.MakeTimestamp()-t)
 		return
 	}
 
-	c.JSONP(http.StatusOK, respond.RespSuccess(userBlockedChats, tool.MakeTimestamp()-t))
+	respond.JSONP(c, http.StatusOK, respond.RespSuccess(page, tool.MakeTimestamp()-t))
 }
 
 // AddBlockedChat godoc
 // @Summary 添加屏蔽聊天
-// @Description 为用户添加屏蔽某个群聊或私聊
+// @Description 为用户添加屏蔽某个群聊或私聊。可通过 muteUntil（Unix 秒）或 muteDuration（秒）设置临时静音，到期后自动恢复推送；都不设置时为永久屏蔽。对已屏蔽的聊天再次调用会更新静音截止时间
 // @Tags Push API
 // @Accept json
 // @Produce json
@@ -253,31 +360,108 @@ func AddBlockedChat(c *gin.Context) {
 		requestModel *request.AddBlockedChatReq
 	)
 
-	if c.ShouldBindJSON(&requestModel) == nil {
-		// 调用 pebble_service 的方法
-		err := pebble_service.AddBlockedChat(requestModel.MetaID, requestModel.ChatID, requestModel.ChatType, requestModel.Reason)
+	if err := c.ShouldBindJSON(&requestModel); err != nil {
+		respond.Fail(c, respond.BindError(err), tool.MakeTimestamp()-t)
+		return
+	}
+
+	muteUntil, err := resolveMuteUntil(requestModel.MuteUntil, requestModel.MuteDuration)
+	if err != nil {
+		respond.Fail(c, respond.InvalidField("muteUntil", err), tool.MakeTimestamp()-t)
+		return
+	}
+
+	// 调用 storage_service 的方法
+	err = storage_service.AddBlockedChat(requestModel.MetaID, requestModel.ChatID, requestModel.ChatType, requestModel.Reason, muteUntil)
+	if err != nil {
+		respond.Fail(c, err, tool.MakeTimestamp()-t)
+		return
+	}
+
+	// 构造成功响应
+	responseData := map[string]interface{}{
+		"success": true,
+		"message": "屏蔽聊天添加成功",
+		"data": map[string]interface{}{
+			"metaId":    requestModel.MetaID,
+			"chatId":    requestModel.ChatID,
+			"chatType":  requestModel.ChatType,
+			"reason":    requestModel.Reason,
+			"muteUntil": muteUntil,
+		},
+	}
+
+	respond.JSONP(c, http.StatusOK, respond.RespSuccess(responseData, tool.MakeTimestamp()-t))
+}
+
+// resolveMuteUntil 根据静音截止时间或静音时长计算截止时间，返回 0 表示永久屏蔽
+func resolveMuteUntil(muteUntil, muteDuration int64) (int64, error) {
+	now := time.Now().Unix()
+	if muteUntil != 0 {
+		if muteUntil <= now {
+			return 0, errors.New("muteUntil 必须晚于当前时间")
+		}
+		return muteUntil, nil
+	}
+	if muteDuration < 0 {
+		return 0, errors.New("muteDuration 不能为负数")
+	}
+	if muteDuration > 0 {
+		return now + muteDuration, nil
+	}
+	return 0, nil
+}
+
+// BatchBlockedChats godoc
+// @Summary 批量屏蔽/取消屏蔽聊天
+// @Description 一次请求屏蔽和取消屏蔽多个聊天，用于客户端重装后同步本地屏蔽列表。block 中的每项可设置 muteUntil 或 muteDuration（同 add_blocked_chat），unblock 为要取消屏蔽的聊天ID；同一聊天同时出现在两个列表中时以取消屏蔽为准，单次最多 500 个聊天
+// @Tags Push API
+// @Accept json
+// @Produce json
+// @Param request body request.BatchBlockedChatsReq true "请求参数"
+// @Success 200 {object} respond.Response{data=models.BlockedChatsBatchResult} "成功响应"
+// @Failure 400 {object} respond.Response "参数错误"
+// @Failure 401 {object} respond.Response "认证失败"
+// @Failure 500 {object} respond.Response "服务器内部错误"
+// @Router /v1/push/batch_blocked_chats [post]
+func BatchBlockedChats(c *gin.Context) {
+	var (
+		t            int64 = tool.MakeTimestamp()
+		requestModel *request.BatchBlockedChatsReq
+	)
+
+	if err := c.ShouldBindJSON(&requestModel); err != nil {
+		respond.Fail(c, respond.BindError(err), tool.MakeTimestamp()-t)
+		return
+	}
+
+	block := make([]models.BlockedChat, 0, len(requestModel.Block))
+	for i, item := range requestModel.Block {
+		muteUntil, err := resolveMuteUntil(item.MuteUntil, item.MuteDuration)
 		if err != nil {
-			c.JSONP(http.StatusOK, respond.RespErr(err, tool.MakeTimestamp()-t, respond.HttpsCodeError))
+			respond.Fail(c, respond.InvalidField(fmt.Sprintf("block[%d].muteUntil", i), fmt.Errorf("%s: %w", item.ChatID, err)), tool.MakeTimestamp()-t)
 			return
 		}
+		block = append(block, models.BlockedChat{
+			ChatID:    item.ChatID,
+			ChatType:  item.ChatType,
+			Reason:    item.Reason,
+			MuteUntil: muteUntil,
+		})
+	}
 
-		// 构造成功响应
-		responseData := map[string]interface{}{
-			"success": true,
-			"message": "屏蔽聊天添加成功",
-			"data": map[string]interface{}{
-				"metaId":   requestModel.MetaID,
-				"chatId":   requestModel.ChatID,
-				"chatType": requestModel.ChatType,
-				"reason":   requestModel.Reason,
-			},
-		}
+	if err := pebble_service.ValidateBlockedChatsBatch(block, requestModel.Unblock); err != nil {
+		respond.Fail(c, respond.InvalidParam(err), tool.MakeTimestamp()-t)
+		return
+	}
 
-		c.JSONP(http.StatusOK, respond.RespSuccess(responseData, tool.MakeTimestamp()-t))
+	result, err := storage_service.BatchUpdateBlockedChats(requestModel.MetaID, block, requestModel.Unblock)
+	if err != nil {
+		respond.Fail(c, err, tool.MakeTimestamp()-t)
 		return
 	}
 
-	c.JSONP(http.StatusInternalServerError, respond.RespErr(errors.New("参数错误"), tool.MakeTimestamp()-t, respond.HttpsCodeError))
+	respond.JSONP(c, http.StatusOK, respond.RespSuccess(result, tool.MakeTimestamp()-t))
 }
 
 // RemoveBlockedChat godoc
@@ -298,27 +482,313 @@ func RemoveBlockedChat(c *gin.Context) {
 		requestModel *request.RemoveBlockedChatReq
 	)
 
-	if c.ShouldBindJSON(&requestModel) == nil {
-		// 调用 pebble_service 的方法
-		err := pebble_service.RemoveBlockedChat(requestModel.MetaID, requestModel.ChatID)
-		if err != nil {
-			c.JSONP(http.StatusOK, respond.RespErr(err, tool.MakeTimestamp()-t, respond.HttpsCodeError))
-			return
-		}
+	if err := c.ShouldBindJSON(&requestModel); err != nil {
+		respond.Fail(c, respond.BindError(err), tool.MakeTimestamp()-t)
+		return
+	}
 
-		// 构造成功响应
-		responseData := map[string]interface{}{
-			"success": true,
-			"message": "屏蔽聊天移除成功",
-			"data": map[string]interface{}{
-				"metaId": requestModel.MetaID,
-				"chatId": requestModel.ChatID,
-			},
-		}
+	// 调用 storage_service 的方法
+	err := storage_service.RemoveBlockedChat(requestModel.MetaID, requestModel.ChatID)
+	if err != nil {
+		respond.Fail(c, err, tool.MakeTimestamp()-t)
+		return
+	}
+
+	// 构造成功响应
+	responseData := map[string]interface{}{
+		"success": true,
+		"message": "屏蔽聊天移除成功",
+		"data": map[string]interface{}{
+			"metaId": requestModel.MetaID,
+			"chatId": requestModel.ChatID,
+		},
+	}
+
+	respond.JSONP(c, http.StatusOK, respond.RespSuccess(responseData, tool.MakeTimestamp()-t))
+}
+
+// AddBlockedSender godoc
+// @Summary 屏蔽发送者
+// @Description 屏蔽某个发送者，屏蔽后用户不再收到该发送者在任何私聊和群聊中的消息推送；已屏蔽时更新屏蔽原因
+// @Tags Push API
+// @Accept json
+// @Produce json
+// @Param request body request.AddBlockedSenderReq true "请求参数"
+// @Success 200 {object} respond.Response "成功响应"
+// @Failure 400 {object} respond.Response "参数错误"
+// @Failure 401 {object} respond.Response "认证失败"
+// @Failure 500 {object} respond.Response "服务器内部错误"
+// @Router /v1/push/add_blocked_sender [post]
+func AddBlockedSender(c *gin.Context) {
+	var (
+		t            int64 = tool.MakeTimestamp()
+		requestModel *request.AddBlockedSenderReq
+	)
+
+	if err := c.ShouldBindJSON(&requestModel); err != nil {
+		respond.Fail(c, respond.BindError(err), tool.MakeTimestamp()-t)
+		return
+	}
+
+	err := storage_service.AddBlockedSender(requestModel.MetaID, requestModel.SenderID, requestModel.Reason)
+	if err != nil {
+		respond.Fail(c, err, tool.MakeTimestamp()-t)
+		return
+	}
+
+	responseData := map[string]interface{}{
+		"success": true,
+		"message": "发送者屏蔽成功",
+		"data": map[string]interface{}{
+			"metaId":   requestModel.MetaID,
+			"senderId": requestModel.SenderID,
+			"reason":   requestModel.Reason,
+		},
+	}
+
+	respond.JSONP(c, http.StatusOK, respond.RespSuccess(responseData, tool.MakeTimestamp()-t))
+}
+
+// RemoveBlockedSender godoc
+// @Summary 取消屏蔽发送者
+// @Description 取消用户对某个发送者的屏蔽
+// @Tags Push API
+// @Accept json
+// @Produce json
+// @Param request body request.RemoveBlockedSenderReq true "请求参数"
+// @Success 200 {object} respond.Response "成功响应"
+// @Failure 400 {object} respond.Response "参数错误"
+// @Failure 401 {object} respond.Response "认证失败"
+// @Failure 500 {object} respond.Response "服务器内部错误"
+// @Router /v1/push/remove_blocked_sender [post]
+func RemoveBlockedSender(c *gin.Context) {
+	var (
+		t            int64 = tool.MakeTimestamp()
+		requestModel *request.RemoveBlockedSenderReq
+	)
+
+	if err := c.ShouldBindJSON(&requestModel); err != nil {
+		respond.Fail(c, respond.BindError(err), tool.MakeTimestamp()-t)
+		return
+	}
+
+	err := storage_service.RemoveBlockedSender(requestModel.MetaID, requestModel.SenderID)
+	if err != nil {
+		respond.Fail(c, err, tool.MakeTimestamp()-t)
+		return
+	}
+
+	responseData := map[string]interface{}{
+		"success": true,
+		"message": "发送者取消屏蔽成功",
+		"data": map[string]interface{}{
+			"metaId":   requestModel.MetaID,
+			"senderId": requestModel.SenderID,
+		},
+	}
+
+	respond.JSONP(c, http.StatusOK, respond.RespSuccess(responseData, tool.MakeTimestamp()-t))
+}
+
+// GetUserBlockedSenders godoc
+// @Summary 获取用户屏蔽的发送者列表
+// @Description 根据用户 metaId 获取该用户屏蔽的所有发送者，按屏蔽时间排序
+// @Tags Push API
+// @Produce json
+// @Param metaId query string true "用户唯一标识"
+// @Success 200 {object} respond.Response{data=models.UserBlockedSenders} "成功响应"
+// @Failure 400 {object} respond.Response "参数错误"
+// @Failure 401 {object} respond.Response "认证失败"
+// @Failure 500 {object} respond.Response "服务器内部错误"
+// @Router /v1/push/get_user_blocked_senders [get]
+func GetUserBlockedSenders(c *gin.Context) {
+	var t int64 = tool.MakeTimestamp()
+
+	metaId := c.Query("metaId")
+	if metaId == "" {
+		respond.Fail(c, respond.MissingParam("metaId"), tool.MakeTimestamp()-t)
+		return
+	}
+
+	userBlockedSenders, err := storage_service.GetUserBlockedSenders(metaId)
+	if err != nil {
+		respond.Fail(c, err, tool.MakeTimestamp()-t)
+		return
+	}
+
+	respond.JSONP(c, http.StatusOK, respond.RespSuccess(userBlockedSenders, tool.MakeTimestamp()-t))
+}
+
+// BlockAllGroups godoc
+// @Summary 屏蔽所有群聊
+// @Description 开启后用户不再收到任何群聊消息推送（私聊不受影响），单个群聊的屏蔽记录保持不变
+// @Tags Push API
+// @Accept json
+// @Produce json
+// @Param request body request.AllGroupsBlockReq true "请求参数"
+// @Success 200 {object} respond.Response "成功响应"
+// @Failure 400 {object} respond.Response "参数错误"
+// @Failure 401 {object} respond.Response "认证失败"
+// @Failure 500 {object} respond.Response "服务器内部错误"
+// @Router /v1/push/block_all_groups [post]
+func BlockAllGroups(c *gin.Context) {
+	setAllGroupsBlocked(c, true)
+}
+
+// UnblockAllGroups godoc
+// @Summary 取消屏蔽所有群聊
+// @Description 关闭"屏蔽所有群聊"，恢复群聊消息推送（单独屏蔽的群聊仍然屏蔽）
+// @Tags Push API
+// @Accept json
+// @Produce json
+// @Param request body request.AllGroupsBlockReq true "请求参数"
+// @Success 200 {object} respond.Response "成功响应"
+// @Failure 400 {object} respond.Response "参数错误"
+// @Failure 401 {object} respond.Response "认证失败"
+// @Failure 500 {object} respond.Response "服务器内部错误"
+// @Router /v1/push/unblock_all_groups [post]
+func UnblockAllGroups(c *gin.Context) {
+	setAllGroupsBlocked(c, false)
+}
+
+// setAllGroupsBlocked 开启或关闭屏蔽所有群聊
+func setAllGroupsBlocked(c *gin.Context, blocked bool) {
+	var (
+		t            int64 = tool.MakeTimestamp()
+		requestModel *request.AllGroupsBlockReq
+	)
+
+	if err := c.ShouldBindJSON(&requestModel); err != nil {
+		respond.Fail(c, respond.BindError(err), tool.MakeTimestamp()-t)
+		return
+	}
+
+	if err := storage_service.SetAllGroupsBlocked(requestModel.MetaID, blocked); err != nil {
+		respond.Fail(c, err, tool.MakeTimestamp()-t)
+		return
+	}
+
+	message := "已屏蔽所有群聊"
+	if !blocked {
+		message = "已取消屏蔽所有群聊"
+	}
+	responseData := map[string]interface{}{
+		"success": true,
+		"message": message,
+		"data": map[string]interface{}{
+			"metaId":  requestModel.MetaID,
+			"blocked": blocked,
+		},
+	}
+
+	respond.JSONP(c, http.StatusOK, respond.RespSuccess(responseData, tool.MakeTimestamp()-t))
+}
+
+// GetAllGroupsBlock godoc
+// @Summary 获取屏蔽所有群聊设置
+// @Description 根据用户 metaId 获取该用户是否开启了"屏蔽所有群聊"及开启时间
+// @Tags Push API
+// @Produce json
+// @Param metaId query string true "用户唯一标识"
+// @Success 200 {object} respond.Response{data=models.AllGroupsBlock} "成功响应"
+// @Failure 400 {object} respond.Response "参数错误"
+// @Failure 401 {object} respond.Response "认证失败"
+// @Failure 500 {object} respond.Response "服务器内部错误"
+// @Router /v1/push/get_all_groups_block [get]
+func GetAllGroupsBlock(c *gin.Context) {
+	var t int64 = tool.MakeTimestamp()
+
+	metaId := c.Query("metaId")
+	if metaId == "" {
+		respond.Fail(c, respond.MissingParam("metaId"), tool.MakeTimestamp()-t)
+		return
+	}
+
+	allGroupsBlock, err := storage_service.GetAllGroupsBlock(metaId)
+	if err != nil {
+		respond.Fail(c, err, tool.MakeTimestamp()-t)
+		return
+	}
+
+	respond.JSONP(c, http.StatusOK, respond.RespSuccess(allGroupsBlock, tool.MakeTimestamp()-t))
+}
+
+// chatSoundPattern 聊天通知声音名称：客户端内置的声音文件名，不允许路径
+var chatSoundPattern = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.-]{0,63}$`)
+
+// SetChatSound godoc
+// @Summary 设置聊天通知声音
+// @Description 为用户的某个群聊或私聊设置自定义通知声音。sound 为客户端内置的声音文件名（字母、数字、下划线、点和连字符，最长 64 个字符），silent 表示该聊天的通知不播放声音，为空时恢复默认声音。屏蔽的聊天不会推送，声音设置不影响屏蔽
+// @Tags Push API
+// @Accept json
+// @Produce json
+// @Param request body request.SetChatSoundReq true "请求参数"
+// @Success 200 {object} respond.Response "成功响应"
+// @Failure 400 {object} respond.Response "参数错误"
+// @Failure 401 {object} respond.Response "认证失败"
+// @Failure 500 {object} respond.Response "服务器内部错误"
+// @Router /v1/push/set_chat_sound [post]
+func SetChatSound(c *gin.Context) {
+	var (
+		t            int64 = tool.MakeTimestamp()
+		requestModel *request.SetChatSoundReq
+	)
+
+	if err := c.ShouldBindJSON(&requestModel); err != nil {
+		respond.Fail(c, respond.BindError(err), tool.MakeTimestamp()-t)
+		return
+	}
+
+	if requestModel.Sound != "" && !chatSoundPattern.MatchString(requestModel.Sound) {
+		respond.Fail(c, respond.InvalidField("sound", fmt.Errorf("sound 无效: %s", requestModel.Sound)), tool.MakeTimestamp()-t)
+		return
+	}
+
+	err := storage_service.SetChatSound(requestModel.MetaID, requestModel.ChatID, requestModel.ChatType, requestModel.Sound)
+	if err != nil {
+		respond.Fail(c, err, tool.MakeTimestamp()-t)
+		return
+	}
+
+	responseData := map[string]interface{}{
+		"success": true,
+		"message": "聊天通知声音设置成功",
+		"data": map[string]interface{}{
+			"metaId":   requestModel.MetaID,
+			"chatId":   requestModel.ChatID,
+			"chatType": requestModel.ChatType,
+			"sound":    requestModel.Sound,
+		},
+	}
+
+	respond.JSONP(c, http.StatusOK, respond.RespSuccess(responseData, tool.MakeTimestamp()-t))
+}
 
-		c.JSONP(http.StatusOK, respond.RespSuccess(responseData, tool.MakeTimestamp()-t))
+// GetUserChatSounds godoc
+// @Summary 获取用户聊天通知声音列表
+// @Description 根据用户 metaId 获取该用户为各个聊天设置的通知声音，未设置的聊天使用默认声音
+// @Tags Push API
+// @Produce json
+// @Param metaId query string true "用户唯一标识"
+// @Success 200 {object} respond.Response{data=models.UserChatSounds} "成功响应"
+// @Failure 400 {object} respond.Response "参数错误"
+// @Failure 401 {object} respond.Response "认证失败"
+// @Failure 500 {object} respond.Response "服务器内部错误"
+// @Router /v1/push/get_user_chat_sounds [get]
+func GetUserChatSounds(c *gin.Context) {
+	var t int64 = tool.MakeTimestamp()
+
+	metaId := c.Query("metaId")
+	if metaId == "" {
+		respond.Fail(c, respond.MissingParam("metaId"), tool.MakeTimestamp()-t)
+		return
+	}
+
+	userChatSounds, err := storage_service.GetUserChatSounds(metaId)
+	if err != nil {
+		respond.Fail(c, err, tool.MakeTimestamp()-t)
 		return
 	}
 
-	c.JSONP(http.StatusInternalServerError, respond.RespErr(errors.New("参数错误"), tool.MakeTimestamp()-t, respond.HttpsCodeError))
+	respond.JSONP(c, http.StatusOK, respond.RespSuccess(userChatSounds, tool.MakeTimestamp()-t))
 }
@@ -0,0 +1,185 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"push-base-service/controller/request"
+	"push-base-service/controller/respond"
+	"push-base-service/service/pebble_service"
+	"push-base-service/tool"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetQAAccount godoc
+// @Summary 设置 QA 账号
+// @Description 将指定 MetaID 标记为 QA 账号，此后发给该用户的推送写入虚拟收件箱，不再发送到真实设备（需开启 qa.enabled）
+// @Tags Admin API
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body request.SetQAAccountReq true "请求参数"
+// @Success 200 {object} respond.Response{data=models.QAAccount} "成功响应"
+// @Failure 401 {object} respond.Response "认证失败"
+// @Failure 500 {object} respond.Response "服务器内部错误"
+// @Router /v1/admin/set_qa_account [post]
+func SetQAAccount(c *gin.Context) {
+	var (
+		t            int64 = tool.MakeTimestamp()
+		requestModel *request.SetQAAccountReq
+	)
+
+	if c.ShouldBindJSON(&requestModel) == nil {
+		account, err := pebble_service.SaveQAAccount(requestModel.MetaID, requestModel.Note)
+		if err != nil {
+			respond.JSONP(c, http.StatusOK, respond.RespErr(err, tool.MakeTimestamp()-t, respond.HttpsCodeError))
+			return
+		}
+
+		respond.JSONP(c, http.StatusOK, respond.RespSuccess(account, tool.MakeTimestamp()-t))
+		return
+	}
+
+	respond.JSONP(c, http.StatusInternalServerError, respond.RespErr(errors.New("参数错误"), tool.MakeTimestamp()-t, respond.HttpsCodeError))
+}
+
+// RemoveQAAccount godoc
+// @Summary 移除 QA 账号
+// @Description 取消 QA 账号标记并清空其虚拟收件箱，此后恢复正常推送
+// @Tags Admin API
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body request.RemoveQAAccountReq true "请求参数"
+// @Success 200 {object} respond.Response "成功响应"
+// @Failure 401 {object} respond.Response "认证失败"
+// @Failure 500 {object} respond.Response "服务器内部错误"
+// @Router /v1/admin/remove_qa_account [post]
+func RemoveQAAccount(c *gin.Context) {
+	var (
+		t            int64 = tool.MakeTimestamp()
+		requestModel *request.RemoveQAAccountReq
+	)
+
+	if c.ShouldBindJSON(&requestModel) == nil {
+		if err := pebble_service.RemoveQAAccount(requestModel.MetaID); err != nil {
+			respond.JSONP(c, http.StatusOK, respond.RespErr(err, tool.MakeTimestamp()-t, respond.HttpsCodeError))
+			return
+		}
+
+		responseData := map[string]interface{}{
+			"success": true,
+			"message": "QA账号移除成功",
+			"metaId":  requestModel.MetaID,
+		}
+
+		respond.JSONP(c, http.StatusOK, respond.RespSuccess(responseData, tool.MakeTimestamp()-t))
+		return
+	}
+
+	respond.JSONP(c, http.StatusInternalServerError, respond.RespErr(errors.New("参数错误"), tool.MakeTimestamp()-t, respond.HttpsCodeError))
+}
+
+// GetQAAccounts godoc
+// @Summary 获取 QA 账号列表
+// @Description 获取所有 QA 账号
+// @Tags Admin API
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} respond.Response{data=[]models.QAAccount} "成功响应"
+// @Failure 401 {object} respond.Response "认证失败"
+// @Failure 500 {object} respond.Response "服务器内部错误"
+// @Router /v1/admin/get_qa_accounts [get]
+func GetQAAccounts(c *gin.Context) {
+	var t int64 = tool.MakeTimestamp()
+
+	accounts, err := pebble_service.ListQAAccounts()
+	if err != nil {
+		respond.JSONP(c, http.StatusOK, respond.RespErr(err, tool.MakeTimestamp()-t, respond.HttpsCodeError))
+		return
+	}
+
+	respond.JSONP(c, http.StatusOK, respond.RespSuccess(accounts, tool.MakeTimestamp()-t))
+}
+
+// GetQAInbox godoc
+// @Summary 获取 QA 账号虚拟收件箱
+// @Description 获取 QA 账号收到的推送，用于端到端测试断言"用户 X 会收到 Y"。since 为 Unix 毫秒，只返回之后收到的消息
+// @Tags Admin API
+// @Produce json
+// @Security ApiKeyAuth
+// @Param metaId query string true "QA 账号 MetaID"
+// @Param since query int false "只返回该时间（Unix 毫秒）之后收到的消息"
+// @Param limit query int false "最多返回最新的条数"
+// @Success 200 {object} respond.Response{data=[]models.QAInboxMessage} "成功响应"
+// @Failure 401 {object} respond.Response "认证失败"
+// @Failure 500 {object} respond.Response "服务器内部错误"
+// @Router /v1/admin/get_qa_inbox [get]
+func GetQAInbox(c *gin.Context) {
+	var t int64 = tool.MakeTimestamp()
+
+	metaId := c.Query("metaId")
+	if metaId == "" {
+		respond.JSONP(c, http.StatusOK, respond.RespErr(errors.New("metaId 参数不能为空"), tool.MakeTimestamp()-t, respond.HttpsCodeError))
+		return
+	}
+
+	var since int64
+	if sinceStr := c.Query("since"); sinceStr != "" {
+		if s, err := strconv.ParseInt(sinceStr, 10, 64); err == nil && s > 0 {
+			since = s
+		}
+	}
+	limit := 0
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
+			limit = l
+		}
+	}
+
+	messages, err := pebble_service.GetQAInboxMessages(metaId, since, limit)
+	if err != nil {
+		respond.JSONP(c, http.StatusOK, respond.RespErr(err, tool.MakeTimestamp()-t, respond.HttpsCodeError))
+		return
+	}
+
+	respond.JSONP(c, http.StatusOK, respond.RespSuccess(messages, tool.MakeTimestamp()-t))
+}
+
+// ClearQAInbox godoc
+// @Summary 清空 QA 账号虚拟收件箱
+// @Description 清空 QA 账号收件箱中的所有消息，通常在每个测试用例开始前调用
+// @Tags Admin API
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body request.ClearQAInboxReq true "请求参数"
+// @Success 200 {object} respond.Response "成功响应"
+// @Failure 401 {object} respond.Response "认证失败"
+// @Failure 500 {object} respond.Response "服务器内部错误"
+// @Router /v1/admin/clear_qa_inbox [post]
+func ClearQAInbox(c *gin.Context) {
+	var (
+		t            int64 = tool.MakeTimestamp()
+		requestModel *request.ClearQAInboxReq
+	)
+
+	if c.ShouldBindJSON(&requestModel) == nil {
+		cleared, err := pebble_service.ClearQAInbox(requestModel.MetaID)
+		if err != nil {
+			respond.JSONP(c, http.StatusOK, respond.RespErr(err, tool.MakeTimestamp()-t, respond.HttpsCodeError))
+			return
+		}
+
+		responseData := map[string]interface{}{
+			"metaId":  requestModel.MetaID,
+			"cleared": cleared,
+		}
+
+		respond.JSONP(c, http.StatusOK, respond.RespSuccess(responseData, tool.MakeTimestamp()-t))
+		return
+	}
+
+	respond.JSONP(c, http.StatusInternalServerError, respond.RespErr(errors.New("参数错误"), tool.MakeTimestamp()-t, respond.HttpsCodeError))
+}
@@ -0,0 +1,119 @@
+package request
+
+import (
+	"encoding/json"
+	"push-base-service/models"
+)
+
+// ===== 租户 Webhook 相关请求参数 =====
+
+// SetTenantWebhookReq 设置租户投递事件 Webhook 请求参数
+type SetTenantWebhookReq struct {
+	TenantID string `json:"tenantId" binding:"required"`
+	URL      string `json:"url" binding:"required"`
+	Secret   string `json:"secret"`  // 签名密钥（可选，设置后回调携带 X-Webhook-Signature）
+	Enabled  *bool  `json:"enabled"` // 是否启用（可选，默认启用）
+}
+
+// RemoveTenantWebhookReq 移除租户投递事件 Webhook 请求参数
+type RemoveTenantWebhookReq struct {
+	TenantID string `json:"tenantId" binding:"required"`
+}
+
+// SetUserTenantReq 设置用户所属租户请求参数
+type SetUserTenantReq struct {
+	MetaID   string `json:"metaId" binding:"required"`
+	TenantID string `json:"tenantId"` // 租户ID，为空表示移出租户
+}
+
+// SetTenantQuotaReq 设置租户月度推送配额请求参数
+type SetTenantQuotaReq struct {
+	TenantID     string `json:"tenantId" binding:"required"`
+	MonthlyLimit int64  `json:"monthlyLimit"` // 每月推送上限（按设备投递计数），0 表示不限制
+}
+
+// MergeUsersReq 合并两个 MetaID 推送状态请求参数
+type MergeUsersReq struct {
+	SourceMetaID string `json:"sourceMetaId" binding:"required"` // 被合并的旧 MetaID
+	TargetMetaID string `json:"targetMetaId" binding:"required"` // 保留的 MetaID
+	Prefer       string `json:"prefer"`                          // 冲突时保留的一方：target（默认）/ source
+	Reason       string `json:"reason"`                          // 合并原因（如身份服务事件ID），写入审计记录
+}
+
+// SetEnabledTypesReq 设置启用的消息类型请求参数（整体替换）
+type SetEnabledTypesReq struct {
+	Types []string `json:"types" binding:"required"` // 启用的消息类型：private_chat / group_chat / friend_request / payment
+}
+
+// SetRoutingRulesReq 设置通知路由规则请求参数（整体替换）
+type SetRoutingRulesReq struct {
+	Rules []models.RoutingRule `json:"rules"` // 路由规则（按顺序匹配，命中第一条即止），空列表表示不使用任何规则
+}
+
+// ReplayMessageReq 回放上游消息请求参数
+type ReplayMessageReq struct {
+	Payload json.RawMessage `json:"payload" binding:"required" swaggertype:"object"` // 原始 SocketData 载荷（JSON 对象或 JSON 字符串）
+	DryRun  bool            `json:"dryRun"`                                          // 演练：不调用推送平台，也不记录通知状态和投递结果
+}
+
+// RequeueQuarantinedReq 隔离消息重新入队请求参数
+type RequeueQuarantinedReq struct {
+	IDs []string `json:"ids"` // 隔离条目ID（见 get_quarantined_messages），为空时重新入队所有未推送的消息
+}
+
+// ===== QA 虚拟收件箱相关请求参数 =====
+
+// SetQAAccountReq 设置 QA 账号请求参数
+type SetQAAccountReq struct {
+	MetaID string `json:"metaId" binding:"required"`
+	Note   string `json:"note"` // 备注（可选）
+}
+
+// RemoveQAAccountReq 移除 QA 账号请求参数
+type RemoveQAAccountReq struct {
+	MetaID string `json:"metaId" binding:"required"`
+}
+
+// ClearQAInboxReq 清空 QA 收件箱请求参数
+type ClearQAInboxReq struct {
+	MetaID string `json:"metaId" binding:"required"`
+}
+
+// ===== 备份与恢复相关请求参数 =====
+
+// RestoreBackupReq 从备份目录中的备份文件恢复请求参数
+type RestoreBackupReq struct {
+	Name string `json:"name" binding:"required"` // 备份文件名（见 get_backups）
+}
+
+// CreateAPIKeyReq 创建 API Key 请求参数
+type CreateAPIKeyReq struct {
+	Name     string   `json:"name" binding:"required"`   // Key 名称，不能与已有 Key 重复
+	Scopes   []string `json:"scopes" binding:"required"` // 权限范围：read、write、admin
+	TenantID string   `json:"tenantId"`                  // 调用方所属租户（可选），通过该 Key 发送的推送计入该租户的配额
+}
+
+// RevokeAPIKeyReq 吊销 API Key 请求参数
+type RevokeAPIKeyReq struct {
+	Name string `json:"name" binding:"required"`
+}
+
+// SeedTestDataReq 生成测试数据请求参数（仅预发环境）
+type SeedTestDataReq struct {
+	Count           int    `json:"count" binding:"required,min=1"` // 生成的用户数
+	Prefix          string `json:"prefix"`                         // 用户 MetaID 前缀，默认 seed-
+	MaxBlockedChats *int   `json:"maxBlockedChats"`                // 每个用户最多屏蔽的聊天数，默认 3
+	Seed            int64  `json:"seed"`                           // 随机种子，相同种子生成相同数据
+}
+
+// StartUserTraceReq 开始推送追踪请求参数
+type StartUserTraceReq struct {
+	MetaID   string `json:"metaId" binding:"required"`
+	Duration string `json:"duration"` // 追踪时长，如 30m、2h，默认 1h，最长 24h
+	Note     string `json:"note"`     // 备注（如工单号，可选）
+}
+
+// StopUserTraceReq 停止推送追踪请求参数
+type StopUserTraceReq struct {
+	MetaID string `json:"metaId" binding:"required"`
+}
@@ -0,0 +1,67 @@
+package request
+
+// ===== 推送发送相关请求参数 =====
+
+// SendPushReq 发送推送请求参数
+type SendPushReq struct {
+	MetaIDs        []string               `json:"metaIds" binding:"required,min=1"` // 接收用户列表
+	Title          string                 `json:"title" binding:"required"`         // 通知标题
+	Body           string                 `json:"body" binding:"required"`          // 通知内容
+	Data           map[string]interface{} `json:"data"`                             // 自定义数据（可选）
+	Sound          string                 `json:"sound"`                            // 声音（可选，默认 default）
+	Priority       string                 `json:"priority"`                         // 优先级（可选，normal/high）
+	CollapseID     string                 `json:"collapseId"`                       // 折叠ID（可选，相同ID的新通知替换旧通知）
+	ThreadID       string                 `json:"threadId"`                         // 分组ID（可选，相同ID的通知归为一组）
+	IdempotencyKey string                 `json:"idempotencyKey"`                   // 幂等键（可选，也可通过 Idempotency-Key 请求头传入）
+	DryRun         bool                   `json:"dryRun"`                           // 演练模式（可选，完整执行推送流程但不调用推送平台）
+}
+
+// SendDataPushReq 发送静默数据推送请求参数
+type SendDataPushReq struct {
+	MetaIDs        []string               `json:"metaIds" binding:"required,min=1"` // 接收用户列表
+	Data           map[string]interface{} `json:"data" binding:"required"`          // 推送数据
+	Priority       string                 `json:"priority"`                         // 优先级（可选，默认 normal；iOS 后台推送需使用 normal）
+	TTL            int                    `json:"ttl"`                              // 有效期（秒，可选）
+	IdempotencyKey string                 `json:"idempotencyKey"`                   // 幂等键（可选，也可通过 Idempotency-Key 请求头传入）
+	DryRun         bool                   `json:"dryRun"`                           // 演练模式（可选，完整执行推送流程但不调用推送平台）
+}
+
+// TestPushReq 测试推送请求参数（metaId 和 token 二选一）
+type TestPushReq struct {
+	MetaID   string `json:"metaId"`   // 接收用户（发送到该用户登记的所有平台）
+	Token    string `json:"token"`    // 推送令牌（只发送到该令牌）
+	Platform string `json:"platform"` // 令牌所属平台（可选，为空时按令牌格式识别）
+	Title    string `json:"title"`    // 通知标题（可选，默认测试文案）
+	Body     string `json:"body"`     // 通知内容（可选，默认测试文案）
+	Wait     int    `json:"wait"`     // 等待投递回执的秒数（可选，0 表示不等待，最多 60）
+}
+
+// ===== 定时推送相关请求参数 =====
+
+// SchedulePushReq 创建定时推送请求参数
+type SchedulePushReq struct {
+	MetaIDs  []string               `json:"metaIds" binding:"required,min=1"` // 接收用户列表
+	Title    string                 `json:"title" binding:"required"`         // 通知标题
+	Body     string                 `json:"body" binding:"required"`          // 通知内容
+	Data     map[string]interface{} `json:"data"`                             // 自定义数据（可选）
+	Sound    string                 `json:"sound"`                            // 声音（可选，默认 default）
+	Priority string                 `json:"priority"`                         // 优先级（可选，normal/high）
+	SendAt   int64                  `json:"sendAt" binding:"required"`        // 计划发送时间（Unix 秒）
+}
+
+// SchedulePushLocalTimeReq 按当地时间发送的定时推送请求参数
+type SchedulePushLocalTimeReq struct {
+	MetaIDs         []string               `json:"metaIds" binding:"required,min=1"` // 接收用户列表
+	Title           string                 `json:"title" binding:"required"`         // 通知标题
+	Body            string                 `json:"body" binding:"required"`          // 通知内容
+	Data            map[string]interface{} `json:"data"`                             // 自定义数据（可选）
+	Sound           string                 `json:"sound"`                            // 声音（可选，默认 default）
+	Priority        string                 `json:"priority"`                         // 优先级（可选，normal/high）
+	LocalHour       *int                   `json:"localHour" binding:"required"`     // 当地发送时间（0-23 点）
+	DefaultTimezone string                 `json:"defaultTimezone"`                  // 未上报时区的用户使用的时区（可选，IANA 时区名，默认 UTC）
+}
+
+// CancelScheduledPushReq 取消定时推送请求参数
+type CancelScheduledPushReq struct {
+	ID string `json:"id" binding:"required"`
+}
@@ -5,6 +5,13 @@ type SetUserTokensReq struct {
 	MetaID   string `json:"metaId" binding:"required"`
 	Platform string `json:"platform" binding:"required"`
 	Token    string `json:"token" binding:"required"` // Token本身就是设备的唯一标识
+
+	// 设备信息（均为可选，未传的字段保留上次上报的值）
+	AppVersion  string `json:"appVersion"`  // 客户端版本，如 1.4.2
+	OSVersion   string `json:"osVersion"`   // 系统版本，如 iOS 17.5
+	DeviceModel string `json:"deviceModel"` // 设备型号，如 iPhone15,2
+	Locale      string `json:"locale"`      // 设备语言区域，如 zh-CN
+	Timezone    string `json:"timezone"`    // 设备时区（IANA 名称），如 Asia/Shanghai
 }
 
 // GetUserTokenByMetaIDReq 根据 metaId 获取用户令牌请求参数
@@ -14,8 +21,14 @@ type GetUserTokenByMetaIDReq struct {
 
 // GetUserTokensListReq 获取用户令牌列表请求参数（分页）
 type GetUserTokensListReq struct {
-	Page     int `json:"page" binding:"min=1"`     // 页码，从1开始
-	PageSize int `json:"pageSize" binding:"min=1"` // 每页大小
+	Page     int    `json:"page" binding:"min=1"`     // 页码，从1开始
+	PageSize int    `json:"pageSize" binding:"min=1"` // 每页大小
+	Cursor   string `json:"cursor"`                   // 游标分页时传上一页返回的 nextCursor
+
+	Platform      string `json:"platform"`      // 只返回拥有该平台令牌的用户
+	MetaIDPrefix  string `json:"metaIdPrefix"`  // metaId 前缀
+	UpdatedAfter  int64  `json:"updatedAfter"`  // 更新时间晚于（Unix 秒）
+	UpdatedBefore int64  `json:"updatedBefore"` // 更新时间早于（Unix 秒）
 }
 
 // RemoveUserTokenReq 移除用户推送令牌请求参数
@@ -29,6 +42,11 @@ type RemoveUserAllTokensReq struct {
 	MetaID string `json:"metaId" binding:"required"`
 }
 
+// DeleteUserDataReq 删除用户全部数据请求参数
+type DeleteUserDataReq struct {
+	MetaID string `json:"metaId" binding:"required"`
+}
+
 // ===== 屏蔽聊天相关请求参数 =====
 
 // GetUserBlockedChatsReq 获取用户屏蔽聊天列表请求参数
@@ -42,6 +60,10 @@ type AddBlockedChatReq struct {
 	ChatID   string `json:"chatId" binding:"required"`
 	ChatType string `json:"chatType" binding:"required"` // 聊天类型：group, private
 	Reason   string `json:"reason"`                      // 屏蔽原因（可选）
+	// MuteUntil 静音截止时间（Unix 秒，可选），为空且未设置 MuteDuration 时为永久屏蔽
+	MuteUntil int64 `json:"muteUntil"`
+	// MuteDuration 静音时长（秒，可选），如 3600（1小时）、28800（8小时）、604800（1周），优先级低于 MuteUntil
+	MuteDuration int64 `json:"muteDuration"`
 }
 
 // RemoveBlockedChatReq 移除屏蔽聊天请求参数
@@ -49,3 +71,90 @@ type RemoveBlockedChatReq struct {
 	MetaID string `json:"metaId" binding:"required"`
 	ChatID string `json:"chatId" binding:"required"`
 }
+
+// BatchBlockedChatsReq 批量屏蔽/取消屏蔽聊天请求参数
+type BatchBlockedChatsReq struct {
+	MetaID  string            `json:"metaId" binding:"required"`
+	Block   []BlockedChatItem `json:"block" binding:"dive"` // 要屏蔽的聊天
+	Unblock []string          `json:"unblock"`              // 要取消屏蔽的聊天ID
+}
+
+// BlockedChatItem 批量屏蔽中的单个聊天
+type BlockedChatItem struct {
+	ChatID       string `json:"chatId" binding:"required"`
+	ChatType     string `json:"chatType"`     // 聊天类型：group, private
+	Reason       string `json:"reason"`       // 屏蔽原因（可选）
+	MuteUntil    int64  `json:"muteUntil"`    // 静音截止时间（Unix 秒，可选）
+	MuteDuration int64  `json:"muteDuration"` // 静音时长（秒，可选），优先级低于 MuteUntil
+}
+
+// AddBlockedSenderReq 屏蔽发送者请求参数
+type AddBlockedSenderReq struct {
+	MetaID   string `json:"metaId" binding:"required"`
+	SenderID string `json:"senderId" binding:"required"` // 被屏蔽的发送者MetaID
+	Reason   string `json:"reason"`                      // 屏蔽原因
+}
+
+// RemoveBlockedSenderReq 取消屏蔽发送者请求参数
+type RemoveBlockedSenderReq struct {
+	MetaID   string `json:"metaId" binding:"required"`
+	SenderID string `json:"senderId" binding:"required"`
+}
+
+// AllGroupsBlockReq 开启/关闭屏蔽所有群聊请求参数
+type AllGroupsBlockReq struct {
+	MetaID string `json:"metaId" binding:"required"`
+}
+
+// SetChatSoundReq 设置聊天通知声音请求参数
+type SetChatSoundReq struct {
+	MetaID   string `json:"metaId" binding:"required"`
+	ChatID   string `json:"chatId" binding:"required"`
+	ChatType string `json:"chatType"` // 聊天类型：group, private
+	// Sound 客户端内置的声音文件名（如 chime.wav），silent 表示静音，为空时恢复默认声音
+	Sound string `json:"sound"`
+}
+
+// ===== 用户偏好相关请求参数 =====
+
+// SetUserPreferencesReq 设置用户推送偏好请求参数
+type SetUserPreferencesReq struct {
+	MetaID             string `json:"metaId" binding:"required"`
+	Locale             string `json:"locale"`             // 用户语言区域，如 en、zh-CN、ja
+	TranslatePreviews  bool   `json:"translatePreviews"`  // 是否将消息预览翻译为用户语言（需服务端启用预览翻译）
+	HidePreviews       bool   `json:"hidePreviews"`       // 是否隐藏通知中的消息内容和发送者名称
+	MuteCandyBags      bool   `json:"muteCandyBags"`      // 是否关闭红包（Candy Bag）通知
+	MuteFriendRequests bool   `json:"muteFriendRequests"` // 是否关闭好友请求通知
+	MutePayments       bool   `json:"mutePayments"`       // 是否关闭付款通知
+}
+
+// PauseNotificationsReq 暂停用户通知请求参数
+type PauseNotificationsReq struct {
+	MetaID           string `json:"metaId" binding:"required"`
+	Duration         string `json:"duration"`         // 暂停时长：1h、8h 等，或 tomorrow（次日早上 8 点）
+	Until            int64  `json:"until"`            // 暂停截止时间（Unix 秒），优先于 duration
+	UTCOffsetMinutes int    `json:"utcOffsetMinutes"` // 用户时区相对 UTC 的分钟数，用于 tomorrow，如东八区为 480
+	Summary          bool   `json:"summary"`          // 恢复时是否发送"错过 N 条消息"的汇总通知
+}
+
+// SetNotificationCategoriesReq 注册客户端可处理的通知类别请求参数
+type SetNotificationCategoriesReq struct {
+	MetaID     string   `json:"metaId" binding:"required"`
+	Categories []string `json:"categories"` // 客户端已向系统注册的通知类别：message、mention、candy_bag，为空表示不再接收带操作的通知
+}
+
+// UnsubscribeReq 退订全部推送请求参数
+type UnsubscribeReq struct {
+	MetaID string `json:"metaId" binding:"required"`
+	Reason string `json:"reason"` // 退订原因，记入审计记录
+}
+
+// ResubscribeReq 重新订阅推送请求参数
+type ResubscribeReq struct {
+	MetaID string `json:"metaId" binding:"required"`
+}
+
+// ResumeNotificationsReq 恢复用户通知请求参数
+type ResumeNotificationsReq struct {
+	MetaID string `json:"metaId" binding:"required"`
+}
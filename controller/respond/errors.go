@@ -0,0 +1,183 @@
+package respond
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// 错误码：错误响应的 errorCode，调用方据此区分错误类型，message 仅用于展示和排查
+const (
+	ErrCodeInvalidParam  = "invalid_param"  // 参数错误（400），details 中按字段列出原因
+	ErrCodeNotFound      = "not_found"      // 资源不存在（404）
+	ErrCodeUnauthorized  = "unauthorized"   // 认证失败（401）或权限不足（403）
+	ErrCodeRateLimited   = "rate_limited"   // 请求过于频繁（429）
+	ErrCodeProviderError = "provider_error" // 推送平台调用失败（502）
+	ErrCodeUnavailable   = "unavailable"    // 推送中心等依赖未启用（503）
+	ErrCodeInternal      = "internal_error" // 服务器内部错误（500）
+)
+
+// FieldError 单个字段的校验错误
+type FieldError struct {
+	Field   string `json:"field"`          // 字段名（请求体中的 json 字段名或查询参数名），嵌套字段如 block[0].chatId
+	Rule    string `json:"rule,omitempty"` // 未通过的校验规则，如 required、oneof、type
+	Message string `json:"message"`        // 错误说明
+}
+
+// APIError 带 HTTP 状态码和错误码的接口错误
+type APIError struct {
+	Status  int          // HTTP 状态码
+	Code    string       // 错误码
+	Message string       // 错误说明
+	Details []FieldError // 字段校验错误
+}
+
+// Error 实现 error 接口
+func (e *APIError) Error() string {
+	return e.Message
+}
+
+// NewAPIError 创建接口错误
+func NewAPIError(status int, code string, err error) *APIError {
+	return &APIError{Status: status, Code: code, Message: err.Error()}
+}
+
+// InvalidParam 参数错误，可附带字段校验错误
+func InvalidParam(err error, details ...FieldError) *APIError {
+	apiErr := NewAPIError(http.StatusBadRequest, ErrCodeInvalidParam, err)
+	apiErr.Details = details
+	return apiErr
+}
+
+// MissingParam 缺少必填参数
+func MissingParam(field string) *APIError {
+	return InvalidParam(fmt.Errorf("%s 参数不能为空", field), FieldError{Field: field, Rule: "required", Message: "不能为空"})
+}
+
+// InvalidField 单个字段取值无效
+func InvalidField(field string, err error) *APIError {
+	return InvalidParam(err, FieldError{Field: field, Message: err.Error()})
+}
+
+// NotFound 资源不存在
+func NotFound(err error) *APIError {
+	return NewAPIError(http.StatusNotFound, ErrCodeNotFound, err)
+}
+
+// ProviderError 推送平台调用失败
+func ProviderError(err error) *APIError {
+	return NewAPIError(http.StatusBadGateway, ErrCodeProviderError, err)
+}
+
+// Unavailable 依赖的服务未启用
+func Unavailable(err error) *APIError {
+	return NewAPIError(http.StatusServiceUnavailable, ErrCodeUnavailable, err)
+}
+
+// Internal 服务器内部错误
+func Internal(err error) *APIError {
+	return NewAPIError(http.StatusInternalServerError, ErrCodeInternal, err)
+}
+
+// BindError 将请求绑定错误转换为参数错误：校验失败时按字段列出原因，JSON 类型不匹配时指出字段和期望类型
+func BindError(err error) *APIError {
+	var validationErrs validator.ValidationErrors
+	var typeErr *json.UnmarshalTypeError
+	var syntaxErr *json.SyntaxError
+	switch {
+	case errors.As(err, &validationErrs):
+		details := make([]FieldError, 0, len(validationErrs))
+		for _, fieldErr := range validationErrs {
+			details = append(details, FieldError{
+				Field:   fieldPath(fieldErr),
+				Rule:    fieldErr.Tag(),
+				Message: ruleMessage(fieldErr),
+			})
+		}
+		return InvalidParam(errors.New("参数错误"), details...)
+	case errors.As(err, &typeErr):
+		return InvalidParam(errors.New("参数错误"), FieldError{
+			Field:   typeErr.Field,
+			Rule:    "type",
+			Message: fmt.Sprintf("类型应为 %s", typeErr.Type),
+		})
+	case errors.As(err, &syntaxErr), errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		return InvalidParam(errors.New("请求体不是有效的 JSON"))
+	default:
+		return InvalidParam(fmt.Errorf("参数错误: %w", err))
+	}
+}
+
+// fieldPath 校验错误的字段路径，去掉最外层请求结构体名
+func fieldPath(fieldErr validator.FieldError) string {
+	namespace := fieldErr.Namespace()
+	if i := strings.Index(namespace, "."); i >= 0 {
+		return namespace[i+1:]
+	}
+	return fieldErr.Field()
+}
+
+// ruleMessage 校验规则的错误说明
+func ruleMessage(fieldErr validator.FieldError) string {
+	switch fieldErr.Tag() {
+	case "required":
+		return "不能为空"
+	case "min", "gte":
+		return "不能小于 " + fieldErr.Param()
+	case "max", "lte":
+		return "不能大于 " + fieldErr.Param()
+	case "len":
+		return "长度应为 " + fieldErr.Param()
+	case "oneof":
+		return "应为以下值之一: " + fieldErr.Param()
+	case "dive":
+		return "元素无效"
+	default:
+		return fmt.Sprintf("未通过 %s 校验", fieldErr.Tag())
+	}
+}
+
+// RespAPIErr 构造错误响应：code 保持原有的数字错误码，errorCode 和 details 标明错误类型和字段原因
+func RespAPIErr(apiErr *APIError, time int64) Message {
+	code := HttpsCodeError
+	if apiErr.Code == ErrCodeUnauthorized {
+		code = HttpsCodeErrorAuth
+	}
+	message := RespErr(apiErr, time, code)
+	message.ErrorCode = apiErr.Code
+	message.Details = apiErr.Details
+	return message
+}
+
+// Fail 输出错误响应：*APIError 按其状态码和错误码输出，其他错误视为服务器内部错误
+func Fail(c *gin.Context, err error, time int64) {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		apiErr = Internal(err)
+	}
+	JSONP(c, apiErr.Status, RespAPIErr(apiErr, time))
+}
+
+func init() {
+	// 校验错误中的字段名使用 json 标签，与请求体中的字段名一致
+	if validate, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+			name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+			if name == "" {
+				name = strings.SplitN(field.Tag.Get("form"), ",", 2)[0]
+			}
+			if name == "-" || name == "" {
+				return field.Name
+			}
+			return name
+		})
+	}
+}
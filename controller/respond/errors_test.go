@@ -0,0 +1,122 @@
+package respond
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+type bindTestItem struct {
+	ChatID string `json:"chatId" binding:"required"`
+}
+
+type bindTestReq struct {
+	MetaIDs []string       `json:"metaIds" binding:"required,min=1"`
+	Title   string         `json:"title" binding:"required"`
+	Count   int            `json:"count"`
+	Block   []bindTestItem `json:"block" binding:"dive"`
+}
+
+func failBind(t *testing.T, body string, router func(*gin.RouterGroup)) (int, Message) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	router(engine.Group(""))
+
+	recorder := httptest.NewRecorder()
+	engine.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/bind", strings.NewReader(body)))
+
+	var message Message
+	if err := json.Unmarshal(recorder.Body.Bytes(), &message); err != nil {
+		t.Fatalf("解析响应失败: %v, body=%s", err, recorder.Body.String())
+	}
+	return recorder.Code, message
+}
+
+func bindHandler(c *gin.Context) {
+	var req *bindTestReq
+	if err := c.ShouldBindJSON(&req); err != nil {
+		Fail(c, BindError(err), 0)
+		return
+	}
+	JSONP(c, http.StatusOK, RespSuccess(nil, 0))
+}
+
+func TestBindErrorListsFieldDetails(t *testing.T) {
+	status, message := failBind(t, `{"metaIds":[],"block":[{"chatId":""}]}`, func(g *gin.RouterGroup) { g.POST("/bind", bindHandler) })
+
+	if status != http.StatusBadRequest || message.ErrorCode != ErrCodeInvalidParam || message.Code != HttpsCodeError {
+		t.Fatalf("status=%d, errorCode=%q, code=%d, want 400 invalid_param", status, message.ErrorCode, message.Code)
+	}
+	got := map[string]string{}
+	for _, detail := range message.Details {
+		got[detail.Field] = detail.Rule
+	}
+	want := map[string]string{"metaIds": "min", "title": "required", "block[0].chatId": "required"}
+	for field, rule := range want {
+		if got[field] != rule {
+			t.Errorf("details[%s] = %q, want %q (all: %+v)", field, got[field], rule, message.Details)
+		}
+	}
+}
+
+func TestBindErrorTypeMismatchAndBadJSON(t *testing.T) {
+	route := func(g *gin.RouterGroup) { g.POST("/bind", bindHandler) }
+
+	status, message := failBind(t, `{"metaIds":["a"],"title":"t","count":"x"}`, route)
+	if status != http.StatusBadRequest || len(message.Details) != 1 || message.Details[0].Field != "count" || message.Details[0].Rule != "type" {
+		t.Errorf("类型错误: status=%d, details=%+v", status, message.Details)
+	}
+
+	status, message = failBind(t, `{"metaIds":`, route)
+	if status != http.StatusBadRequest || message.ErrorCode != ErrCodeInvalidParam {
+		t.Errorf("无效 JSON: status=%d, errorCode=%q", status, message.ErrorCode)
+	}
+}
+
+func TestFailStatusByErrorType(t *testing.T) {
+	cases := []struct {
+		err    error
+		status int
+		code   string
+	}{
+		{NotFound(errors.New("missing")), http.StatusNotFound, ErrCodeNotFound},
+		{ProviderError(errors.New("apns down")), http.StatusBadGateway, ErrCodeProviderError},
+		{Unavailable(errors.New("disabled")), http.StatusServiceUnavailable, ErrCodeUnavailable},
+		{errors.New("disk full"), http.StatusInternalServerError, ErrCodeInternal},
+	}
+	for _, tc := range cases {
+		status, message := failBind(t, `{}`, func(g *gin.RouterGroup) {
+			g.POST("/bind", func(c *gin.Context) { Fail(c, tc.err, 0) })
+		})
+		if status != tc.status || message.ErrorCode != tc.code || message.Message != tc.err.Error() {
+			t.Errorf("Fail(%v) = %d %q %q, want %d %q", tc.err, status, message.ErrorCode, message.Message, tc.status, tc.code)
+		}
+	}
+}
+
+func TestFailV2EnvelopeCarriesErrorCode(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Group("/v2", V2(NamingSnake)).GET("/missing", func(c *gin.Context) {
+		Fail(c, MissingParam("metaId"), 0)
+	})
+
+	recorder := httptest.NewRecorder()
+	engine.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/v2/missing", nil))
+
+	var envelope map[string]interface{}
+	json.Unmarshal(recorder.Body.Bytes(), &envelope)
+	if recorder.Code != http.StatusBadRequest || envelope["error_code"] != ErrCodeInvalidParam || envelope["success"] != false {
+		t.Fatalf("v2 错误响应 = %d %v", recorder.Code, envelope)
+	}
+	details, _ := envelope["details"].([]interface{})
+	if len(details) != 1 || details[0].(map[string]interface{})["field"] != "metaId" {
+		t.Errorf("details = %v", envelope["details"])
+	}
+}
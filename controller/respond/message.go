@@ -3,19 +3,23 @@ package respond
 // Message 通用响应结构
 // @Description 统一的 API 响应格式
 type Message struct {
-	Code           int         `json:"code" example:"0" description:"响应代码，0表示成功"`
-	Message        string      `json:"message" example:"success" description:"响应消息"`
-	ProcessingTime int64       `json:"processingTime" example:"123" description:"处理时间（毫秒）"`
-	Data           interface{} `json:"data" description:"响应数据"`
+	Code           int          `json:"code" example:"0" description:"响应代码，0表示成功"`
+	Message        string       `json:"message" example:"success" description:"响应消息"`
+	ProcessingTime int64        `json:"processingTime" example:"123" description:"处理时间（毫秒）"`
+	Data           interface{}  `json:"data" description:"响应数据"`
+	ErrorCode      string       `json:"errorCode,omitempty" description:"错误码：invalid_param、not_found、unauthorized、provider_error 等"`
+	Details        []FieldError `json:"details,omitempty" description:"参数错误时按字段列出的校验原因"`
 }
 
 // Response 通用响应结构（用于 Swagger 文档）
 // @Description 统一的 API 响应格式
 type Response struct {
-	Code           int         `json:"code" example:"0" description:"响应代码，0表示成功"`
-	Message        string      `json:"message" example:"success" description:"响应消息"`
-	ProcessingTime int64       `json:"processingTime" example:"123" description:"处理时间（毫秒）"`
-	Data           interface{} `json:"data" description:"响应数据"`
+	Code           int          `json:"code" example:"0" description:"响应代码，0表示成功"`
+	Message        string       `json:"message" example:"success" description:"响应消息"`
+	ProcessingTime int64        `json:"processingTime" example:"123" description:"处理时间（毫秒）"`
+	Data           interface{}  `json:"data" description:"响应数据"`
+	ErrorCode      string       `json:"errorCode,omitempty" example:"invalid_param" description:"错误码：invalid_param、not_found、unauthorized、provider_error 等"`
+	Details        []FieldError `json:"details,omitempty" description:"参数错误时按字段列出的校验原因"`
 }
 
 // AuthError 认证错误
@@ -46,10 +50,14 @@ func RespErr(err error, time int64, code int) Message {
 	if code == 0 {
 		code = HttpsCodeError
 	}
-	return Message{
+	message := Message{
 		Code:           code,
 		Message:        err.Error(),
 		ProcessingTime: time,
 		Data:           nil,
 	}
+	if code == HttpsCodeErrorAuth {
+		message.ErrorCode = ErrCodeUnauthorized
+	}
+	return message
 }
@@ -0,0 +1,211 @@
+package respond
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// 字段命名风格
+const (
+	NamingCamel = "camel" // processingTime、metaId
+	NamingSnake = "snake" // processing_time、meta_id
+)
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// ValidateNaming 校验字段命名风格
+func ValidateNaming(naming string) bool {
+	return naming == NamingCamel || naming == NamingSnake
+}
+
+// ConvertName 将字段名转换为指定的命名风格，包含字母、数字和下划线以外字符的名称保持不变
+func ConvertName(name, naming string) string {
+	words := splitWords(name)
+	if len(words) == 0 {
+		return name
+	}
+
+	if naming == NamingSnake {
+		for i, word := range words {
+			words[i] = strings.ToLower(word)
+		}
+		return strings.Join(words, "_")
+	}
+
+	var b strings.Builder
+	for i, word := range words {
+		word = strings.ToLower(word)
+		if i > 0 {
+			word = strings.ToUpper(word[:1]) + word[1:]
+		}
+		b.WriteString(word)
+	}
+	return b.String()
+}
+
+// splitWords 按下划线和大小写边界拆分名称，连续大写视为一个缩写词（MetaID → Meta、ID）
+func splitWords(name string) []string {
+	runes := []rune(name)
+	var words []string
+	start := -1
+	for i, r := range runes {
+		if r == '_' {
+			if start >= 0 {
+				words = append(words, string(runes[start:i]))
+				start = -1
+			}
+			continue
+		}
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return nil
+		}
+		if start < 0 {
+			start = i
+			continue
+		}
+		if unicode.IsUpper(r) {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				words = append(words, string(runes[start:i]))
+				start = i
+			}
+		}
+	}
+	if start >= 0 {
+		words = append(words, string(runes[start:]))
+	}
+	return words
+}
+
+// Normalize 将响应数据转换为字段名统一为指定风格的通用结构
+// 结构体字段名取自 json 标签（无标签时取字段名），可通过 v2 标签单独指定；
+// 直接构造的 map[string]interface{} 视为响应对象，键同样转换；
+// 结构体字段中的 map（如令牌按平台、通知自定义数据）键为数据而非字段名，保持不变
+func Normalize(value interface{}, naming string) interface{} {
+	return normalizeValue(reflect.ValueOf(value), naming, false)
+}
+
+func normalizeValue(v reflect.Value, naming string, inField bool) interface{} {
+	if !v.IsValid() || !v.CanInterface() {
+		return nil
+	}
+	for v.Kind() == reflect.Interface || v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil
+		}
+		if implementsMarshaler(v.Type()) {
+			return v.Interface()
+		}
+		v = v.Elem()
+	}
+	if implementsMarshaler(v.Type()) || (v.CanAddr() && implementsMarshaler(v.Addr().Type())) {
+		return v.Interface()
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		fields := make(map[string]interface{}, v.NumField())
+		normalizeStruct(v, naming, fields)
+		return fields
+	case reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+		if v.Type().Key().Kind() != reflect.String {
+			return v.Interface()
+		}
+		renameKeys := !inField && v.Type().Elem().Kind() == reflect.Interface
+		result := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			key := iter.Key().String()
+			if renameKeys {
+				key = ConvertName(key, naming)
+			}
+			result[key] = normalizeValue(iter.Value(), naming, inField)
+		}
+		return result
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return v.Interface() // []byte 按 base64 编码
+		}
+		items := make([]interface{}, v.Len())
+		for i := range items {
+			items[i] = normalizeValue(v.Index(i), naming, inField)
+		}
+		return items
+	default:
+		return v.Interface()
+	}
+}
+
+// normalizeStruct 按 json/v2 标签写入结构体字段，匿名嵌入的结构体字段展开到同一层
+func normalizeStruct(v reflect.Value, naming string, fields map[string]interface{}) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		fieldValue := v.Field(i)
+
+		if field.Anonymous && name == "" {
+			embedded := fieldValue
+			if embedded.Kind() == reflect.Pointer {
+				if embedded.IsNil() {
+					continue
+				}
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				normalizeStruct(embedded, naming, fields)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if strings.Contains(options, "omitempty") && isEmptyValue(fieldValue) {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+		if override := field.Tag.Get("v2"); override != "" {
+			name = override
+		} else {
+			name = ConvertName(name, naming)
+		}
+		fields[name] = normalizeValue(fieldValue, naming, true)
+	}
+}
+
+// isEmptyValue 与 encoding/json 的 omitempty 判断一致
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Interface, reflect.Pointer:
+		return v.IsZero()
+	}
+	return false
+}
+
+func implementsMarshaler(t reflect.Type) bool {
+	return t.Implements(jsonMarshalerType) || t.Implements(textMarshalerType)
+}
@@ -0,0 +1,130 @@
+package respond
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestConvertName(t *testing.T) {
+	cases := []struct {
+		name, camel, snake string
+	}{
+		{"processingTime", "processingTime", "processing_time"},
+		{"db_path", "dbPath", "db_path"},
+		{"MetaID", "metaId", "meta_id"},
+		{"ServerURL", "serverUrl", "server_url"},
+		{"totalUsers", "totalUsers", "total_users"},
+		{"user2fa", "user2fa", "user2fa"},
+		{"2024-01-02", "2024-01-02", "2024-01-02"},
+		{"ExponentPushToken[x]", "ExponentPushToken[x]", "ExponentPushToken[x]"},
+	}
+	for _, c := range cases {
+		if got := ConvertName(c.name, NamingCamel); got != c.camel {
+			t.Errorf("ConvertName(%q, camel) = %q, want %q", c.name, got, c.camel)
+		}
+		if got := ConvertName(c.name, NamingSnake); got != c.snake {
+			t.Errorf("ConvertName(%q, snake) = %q, want %q", c.name, got, c.snake)
+		}
+	}
+}
+
+type testTokens struct {
+	MetaID    string            `json:"metaId"`
+	DBPath    string            `json:"db_path,omitempty"`
+	Tokens    map[string]string `json:"tokens"`
+	Note      string            `json:"note,omitempty"`
+	Secret    string            `json:"-"`
+	UpdatedAt time.Time         `json:"updatedAt"`
+	Untagged  int
+	Renamed   string `json:"rn" v2:"renamedField"`
+}
+
+func TestNormalizeKeepsDataKeys(t *testing.T) {
+	data := map[string]interface{}{
+		"total_count": 1,
+		"users": []*testTokens{{
+			MetaID: "m1",
+			DBPath: "/data",
+			Tokens: map[string]string{"expo_token": "t"},
+			Secret: "hidden",
+		}},
+	}
+
+	raw, err := json.Marshal(Normalize(data, NamingSnake))
+	if err != nil {
+		t.Fatalf("Marshal() failed, err: %v", err)
+	}
+	var got map[string]interface{}
+	json.Unmarshal(raw, &got)
+
+	if got["total_count"] == nil {
+		t.Fatalf("response object keys should use snake naming: %s", raw)
+	}
+	user := got["users"].([]interface{})[0].(map[string]interface{})
+	for _, key := range []string{"meta_id", "db_path", "updated_at", "untagged", "renamedField"} {
+		if _, exists := user[key]; !exists {
+			t.Errorf("field %s missing: %s", key, raw)
+		}
+	}
+	for _, key := range []string{"note", "Secret", "secret"} {
+		if _, exists := user[key]; exists {
+			t.Errorf("field %s should be omitted: %s", key, raw)
+		}
+	}
+	if tokens := user["tokens"].(map[string]interface{}); tokens["expo_token"] != "t" {
+		t.Errorf("map keys under struct fields are data and should be kept: %v", tokens)
+	}
+}
+
+func TestJSONPVersions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := func(c *gin.Context) {
+		JSONP(c, http.StatusOK, RespSuccess(map[string]interface{}{"totalUsers": 2}, 5))
+	}
+	failing := func(c *gin.Context) {
+		JSON(c, http.StatusUnauthorized, RespErr(errors.New("denied"), 1, HttpsCodeErrorAuth))
+	}
+
+	router := gin.New()
+	router.GET("/v1/ok", handler)
+	v2 := router.Group("/v2", V2(NamingSnake))
+	v2.GET("/ok", handler)
+	v2.GET("/fail", failing)
+
+	get := func(path string) map[string]interface{} {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+		var body map[string]interface{}
+		if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+			t.Fatalf("GET %s: invalid JSON %q", path, recorder.Body.String())
+		}
+		return body
+	}
+
+	v1 := get("/v1/ok")
+	if v1["processingTime"] != float64(5) || v1["data"].(map[string]interface{})["totalUsers"] != float64(2) {
+		t.Errorf("v1 response changed: %v", v1)
+	}
+	if _, exists := v1["success"]; exists {
+		t.Errorf("v1 response should not use the v2 envelope: %v", v1)
+	}
+
+	ok := get("/v2/ok")
+	if ok["api_version"] != APIVersionV2 || ok["success"] != true || ok["processing_time_ms"] != float64(5) {
+		t.Errorf("v2 envelope = %v", ok)
+	}
+	if ok["data"].(map[string]interface{})["total_users"] != float64(2) {
+		t.Errorf("v2 data = %v", ok["data"])
+	}
+
+	fail := get("/v2/fail")
+	if fail["success"] != false || fail["code"] != float64(HttpsCodeErrorAuth) || fail["message"] != "denied" || fail["data"] != nil {
+		t.Errorf("v2 error envelope = %v", fail)
+	}
+}
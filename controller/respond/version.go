@@ -0,0 +1,88 @@
+package respond
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// 上下文中记录接口版本和字段命名风格的键
+const (
+	contextKeyAPIVersion = "respond.apiVersion"
+	contextKeyNaming     = "respond.naming"
+)
+
+// APIVersionV2 v2 接口版本
+const APIVersionV2 = "v2"
+
+// MessageV2 v2 响应信封：字段命名统一，并显式标明是否成功
+type MessageV2 struct {
+	APIVersion       string       `json:"apiVersion"`
+	Success          bool         `json:"success"`
+	Code             int          `json:"code"`
+	Message          string       `json:"message"`
+	ProcessingTimeMs int64        `json:"processingTimeMs"`
+	Data             interface{}  `json:"data"`
+	ErrorCode        string       `json:"errorCode,omitempty"`
+	Details          []FieldError `json:"details,omitempty"`
+}
+
+// V2 v2 路由组中间件：响应使用 v2 信封，字段名统一为 naming 风格（无效时使用 camel）
+func V2(naming string) gin.HandlerFunc {
+	if !ValidateNaming(naming) {
+		naming = NamingCamel
+	}
+	return func(c *gin.Context) {
+		c.Set(contextKeyAPIVersion, APIVersionV2)
+		c.Set(contextKeyNaming, naming)
+		c.Next()
+	}
+}
+
+// JSONP 输出响应：v1 保持原有格式，v2 转换为 v2 信封
+func JSONP(c *gin.Context, status int, obj interface{}) {
+	if converted, ok := toV2(c, obj); ok {
+		c.JSON(status, converted)
+		return
+	}
+	c.JSONP(status, obj)
+}
+
+// JSON 输出响应（同 JSONP，v1 不支持 callback 参数）
+func JSON(c *gin.Context, status int, obj interface{}) {
+	if converted, ok := toV2(c, obj); ok {
+		c.JSON(status, converted)
+		return
+	}
+	c.JSON(status, obj)
+}
+
+// Payload 不带响应信封输出的数据（如 SSE 事件）：v2 按统一命名转换字段，v1 原样返回
+func Payload(c *gin.Context, obj interface{}) interface{} {
+	if c.GetString(contextKeyAPIVersion) != APIVersionV2 {
+		return obj
+	}
+	return Normalize(obj, c.GetString(contextKeyNaming))
+}
+
+// toV2 v2 请求时将响应转换为 v2 信封并统一字段命名
+func toV2(c *gin.Context, obj interface{}) (interface{}, bool) {
+	if c.GetString(contextKeyAPIVersion) != APIVersionV2 {
+		return nil, false
+	}
+	naming := c.GetString(contextKeyNaming)
+
+	message, ok := obj.(Message)
+	if !ok {
+		return Normalize(obj, naming), true
+	}
+	envelope := Normalize(MessageV2{
+		APIVersion:       APIVersionV2,
+		Success:          message.Code == HttpsCodeSuccess,
+		Code:             message.Code,
+		Message:          message.Message,
+		ProcessingTimeMs: message.ProcessingTime,
+		ErrorCode:        message.ErrorCode,
+		Details:          message.Details,
+	}, naming).(map[string]interface{})
+	envelope[ConvertName("data", naming)] = Normalize(message.Data, naming)
+	return envelope, true
+}
@@ -0,0 +1,226 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"push-base-service/controller/auth"
+	"push-base-service/controller/request"
+	"push-base-service/controller/respond"
+	"push-base-service/models"
+	"push-base-service/service/pebble_service"
+	"push-base-service/service/schedule_service"
+	"push-base-service/tool"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SchedulePush godoc
+// @Summary 创建定时推送
+// @Description 创建一个在 sendAt（Unix 秒）时刻发送的推送任务，返回任务ID
+// @Tags Push API
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body request.SchedulePushReq true "请求参数（metaIds、title、body、sendAt，可选 data、sound、priority）"
+// @Success 200 {object} respond.Response{data=models.ScheduledPush} "成功响应"
+// @Failure 400 {object} respond.Response "参数错误"
+// @Failure 401 {object} respond.Response "认证失败"
+// @Failure 500 {object} respond.Response "服务器内部错误"
+// @Router /v1/push/schedule [post]
+func SchedulePush(c *gin.Context) {
+	var (
+		t            int64 = tool.MakeTimestamp()
+		requestModel *request.SchedulePushReq
+	)
+
+	if err := c.ShouldBindJSON(&requestModel); err != nil {
+		respond.Fail(c, respond.BindError(err), tool.MakeTimestamp()-t)
+		return
+	}
+
+	if requestModel.SendAt <= time.Now().Unix() {
+		respond.Fail(c, respond.InvalidField("sendAt", errors.New("sendAt 必须晚于当前时间")), tool.MakeTimestamp()-t)
+		return
+	}
+
+	id, err := tool.GetUUID()
+	if err != nil {
+		respond.Fail(c, err, tool.MakeTimestamp()-t)
+		return
+	}
+
+	job := &models.ScheduledPush{
+		ID:       id,
+		MetaIDs:  requestModel.MetaIDs,
+		Title:    requestModel.Title,
+		Body:     requestModel.Body,
+		Data:     requestModel.Data,
+		Sound:    requestModel.Sound,
+		Priority: requestModel.Priority,
+		SendAt:   requestModel.SendAt,
+		TenantID: auth.GetRequestAPIKeyTenant(c),
+		Status:   models.ScheduleStatusPending,
+	}
+	if err := pebble_service.SaveScheduledPush(job); err != nil {
+		respond.Fail(c, err, tool.MakeTimestamp()-t)
+		return
+	}
+
+	respond.JSONP(c, http.StatusOK, respond.RespSuccess(job, tool.MakeTimestamp()-t))
+}
+
+// SchedulePushLocalTime godoc
+// @Summary 创建按当地时间发送的定时推送
+// @Description 公告类推送按接收用户的当地时间发送：按用户设备上报的时区（登记令牌时的 timezone，多台设备取最近活跃的）将用户分组，每组在当地下一次到达 localHour 点整时发送，而不是同一时刻发给所有人。
+// @Description 未上报时区的用户使用 defaultTimezone（默认 UTC）。同一时刻到达该整点的时区合并为一个定时推送任务，各任务共用 batchId，可通过 get_scheduled_pushes 查看、cancel_schedule 逐个取消
+// @Tags Push API
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body request.SchedulePushLocalTimeReq true "请求参数（metaIds、title、body、localHour，可选 defaultTimezone、data、sound、priority）"
+// @Success 200 {object} respond.Response{data=models.LocalTimeSchedule} "成功响应"
+// @Failure 400 {object} respond.Response "参数错误"
+// @Failure 401 {object} respond.Response "认证失败"
+// @Failure 500 {object} respond.Response "服务器内部错误"
+// @Router /v1/push/schedule_local_time [post]
+func SchedulePushLocalTime(c *gin.Context) {
+	var (
+		t            int64 = tool.MakeTimestamp()
+		requestModel *request.SchedulePushLocalTimeReq
+	)
+
+	if err := c.ShouldBindJSON(&requestModel); err != nil {
+		respond.Fail(c, respond.BindError(err), tool.MakeTimestamp()-t)
+		return
+	}
+
+	localHour := *requestModel.LocalHour
+	if localHour < 0 || localHour > 23 {
+		respond.Fail(c, respond.InvalidField("localHour", fmt.Errorf("localHour 必须在 0-23 之间: %d", localHour)), tool.MakeTimestamp()-t)
+		return
+	}
+	fallback := time.UTC
+	if requestModel.DefaultTimezone != "" {
+		location, err := time.LoadLocation(requestModel.DefaultTimezone)
+		if err != nil {
+			respond.Fail(c, respond.InvalidField("defaultTimezone", fmt.Errorf("defaultTimezone 无效: %s（IANA 时区名，如 Asia/Shanghai）", requestModel.DefaultTimezone)), tool.MakeTimestamp()-t)
+			return
+		}
+		fallback = location
+	}
+
+	batches, err := schedule_service.PlanLocalTime(requestModel.MetaIDs, localHour, fallback, time.Now())
+	if err != nil {
+		respond.Fail(c, err, tool.MakeTimestamp()-t)
+		return
+	}
+
+	batchId, err := tool.GetUUID()
+	if err != nil {
+		respond.Fail(c, err, tool.MakeTimestamp()-t)
+		return
+	}
+
+	schedule := &models.LocalTimeSchedule{BatchID: batchId, LocalHour: localHour, Jobs: make([]*models.ScheduledPush, 0, len(batches))}
+	for _, batch := range batches {
+		id, err := tool.GetUUID()
+		if err != nil {
+			respond.Fail(c, err, tool.MakeTimestamp()-t)
+			return
+		}
+		job := &models.ScheduledPush{
+			ID:        id,
+			MetaIDs:   batch.MetaIDs,
+			Title:     requestModel.Title,
+			Body:      requestModel.Body,
+			Data:      requestModel.Data,
+			Sound:     requestModel.Sound,
+			Priority:  requestModel.Priority,
+			SendAt:    batch.SendAt.Unix(),
+			BatchID:   batchId,
+			Timezones: batch.Timezones,
+			TenantID:  auth.GetRequestAPIKeyTenant(c),
+			Status:    models.ScheduleStatusPending,
+		}
+		if err := pebble_service.SaveScheduledPush(job); err != nil {
+			// 部分任务保存失败时取消已保存的任务，避免只有部分时区收到推送
+			for _, saved := range schedule.Jobs {
+				if _, cancelErr := pebble_service.CancelScheduledPush(saved.ID); cancelErr != nil {
+					log.Printf("⚠️ 取消已保存的定时推送任务失败: ID=%s, 错误: %v", saved.ID, cancelErr)
+				}
+			}
+			respond.Fail(c, err, tool.MakeTimestamp()-t)
+			return
+		}
+		schedule.Jobs = append(schedule.Jobs, job)
+	}
+
+	respond.JSONP(c, http.StatusOK, respond.RespSuccess(schedule, tool.MakeTimestamp()-t))
+}
+
+// CancelScheduledPush godoc
+// @Summary 取消定时推送
+// @Description 取消尚未发送的定时推送任务，已发送或发送中的任务无法取消
+// @Tags Push API
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body request.CancelScheduledPushReq true "请求参数（id）"
+// @Success 200 {object} respond.Response{data=models.ScheduledPush} "成功响应"
+// @Failure 400 {object} respond.Response "参数错误"
+// @Failure 401 {object} respond.Response "认证失败"
+// @Failure 404 {object} respond.Response "任务不存在"
+// @Failure 500 {object} respond.Response "服务器内部错误"
+// @Router /v1/push/cancel_schedule [post]
+func CancelScheduledPush(c *gin.Context) {
+	var (
+		t            int64 = tool.MakeTimestamp()
+		requestModel *request.CancelScheduledPushReq
+	)
+
+	if err := c.ShouldBindJSON(&requestModel); err != nil {
+		respond.Fail(c, respond.BindError(err), tool.MakeTimestamp()-t)
+		return
+	}
+
+	job, err := pebble_service.CancelScheduledPush(requestModel.ID)
+	switch {
+	case errors.Is(err, pebble_service.ErrScheduledPushNotFound):
+		respond.Fail(c, respond.NotFound(err), tool.MakeTimestamp()-t)
+		return
+	case errors.Is(err, pebble_service.ErrScheduledPushNotCancelable):
+		respond.Fail(c, respond.InvalidField("id", err), tool.MakeTimestamp()-t)
+		return
+	case err != nil:
+		respond.Fail(c, err, tool.MakeTimestamp()-t)
+		return
+	}
+
+	respond.JSONP(c, http.StatusOK, respond.RespSuccess(job, tool.MakeTimestamp()-t))
+}
+
+// GetScheduledPushes godoc
+// @Summary 获取定时推送列表
+// @Description 获取定时推送任务列表（按计划发送时间排序），可按状态过滤
+// @Tags Push API
+// @Produce json
+// @Security ApiKeyAuth
+// @Param status query string false "任务状态：pending、sending、sent、failed、canceled"
+// @Success 200 {object} respond.Response{data=[]models.ScheduledPush} "成功响应"
+// @Failure 401 {object} respond.Response "认证失败"
+// @Failure 500 {object} respond.Response "服务器内部错误"
+// @Router /v1/push/get_scheduled_pushes [get]
+func GetScheduledPushes(c *gin.Context) {
+	var t int64 = tool.MakeTimestamp()
+
+	jobs, err := pebble_service.ListScheduledPushes(c.Query("status"))
+	if err != nil {
+		respond.Fail(c, err, tool.MakeTimestamp()-t)
+		return
+	}
+
+	respond.JSONP(c, http.StatusOK, respond.RespSuccess(jobs, tool.MakeTimestamp()-t))
+}
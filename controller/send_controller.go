@@ -0,0 +1,329 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"push-base-service/controller/auth"
+	"push-base-service/controller/request"
+	"push-base-service/controller/respond"
+	"push-base-service/service/pebble_service"
+	"push-base-service/service/push_service"
+	"push-base-service/tool"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// errPushCenterDisabled 推送中心未启用时发送类接口返回的错误
+var errPushCenterDisabled = respond.Unavailable(errors.New("推送中心未启用"))
+
+// SendPush godoc
+// @Summary 发送推送通知
+// @Description 向指定用户列表发送推送通知。支持幂等键（请求体 idempotencyKey 或请求头 Idempotency-Key），相同幂等键在保留期内重复请求不会重复推送，而是返回首次推送的结果。dryRun 为 true 时完整执行推送流程但不调用推送平台，结果记为模拟，且不占用幂等键。
+// @Tags Push API
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param Idempotency-Key header string false "幂等键"
+// @Param request body request.SendPushReq true "请求参数（metaIds、title、body，可选 data、sound、priority、idempotencyKey、dryRun）"
+// @Success 200 {object} respond.Response "成功响应"
+// @Failure 400 {object} respond.Response "参数错误"
+// @Failure 401 {object} respond.Response "认证失败"
+// @Failure 502 {object} respond.Response "所有推送平台调用都失败"
+// @Failure 503 {object} respond.Response "推送中心未启用"
+// @Failure 500 {object} respond.Response "服务器内部错误"
+// @Router /v1/push/send [post]
+func SendPush(c *gin.Context) {
+	var (
+		t            int64 = tool.MakeTimestamp()
+		requestModel *request.SendPushReq
+	)
+
+	if err := c.ShouldBindJSON(&requestModel); err != nil {
+		respond.Fail(c, respond.BindError(err), tool.MakeTimestamp()-t)
+		return
+	}
+
+	pushManager := push_service.GetGlobalManager()
+	if pushManager == nil {
+		respond.Fail(c, errPushCenterDisabled, tool.MakeTimestamp()-t)
+		return
+	}
+
+	notification := &push_service.PushNotification{
+		Title:      requestModel.Title,
+		Body:       requestModel.Body,
+		Data:       requestModel.Data,
+		Sound:      requestModel.Sound,
+		Priority:   requestModel.Priority,
+		CollapseID: requestModel.CollapseID,
+		ThreadID:   requestModel.ThreadID,
+		DryRun:     requestModel.DryRun,
+	}
+	if notification.Sound == "" {
+		notification.Sound = "default"
+	}
+
+	idempotencyKey := requestModel.IdempotencyKey
+	if idempotencyKey == "" {
+		idempotencyKey = c.GetHeader("Idempotency-Key")
+	}
+	sendWithIdempotency(c, t, pushManager, requestModel.MetaIDs, notification, idempotencyKey)
+}
+
+// SendDataPush godoc
+// @Summary 发送静默数据推送
+// @Description 向指定用户列表发送不展示通知的后台数据推送（iOS content-available），用于触发客户端后台同步。默认普通优先级（iOS 后台推送要求），不走邮件等兜底渠道。支持与 /v1/push/send 相同的幂等键和 dryRun 演练模式。
+// @Tags Push API
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param Idempotency-Key header string false "幂等键"
+// @Param request body request.SendDataPushReq true "请求参数（metaIds、data，可选 priority、ttl、idempotencyKey、dryRun）"
+// @Success 200 {object} respond.Response "成功响应"
+// @Failure 400 {object} respond.Response "参数错误"
+// @Failure 401 {object} respond.Response "认证失败"
+// @Failure 502 {object} respond.Response "所有推送平台调用都失败"
+// @Failure 503 {object} respond.Response "推送中心未启用"
+// @Failure 500 {object} respond.Response "服务器内部错误"
+// @Router /v1/push/send_data [post]
+func SendDataPush(c *gin.Context) {
+	var (
+		t            int64 = tool.MakeTimestamp()
+		requestModel *request.SendDataPushReq
+	)
+
+	if err := c.ShouldBindJSON(&requestModel); err != nil {
+		respond.Fail(c, respond.BindError(err), tool.MakeTimestamp()-t)
+		return
+	}
+
+	pushManager := push_service.GetGlobalManager()
+	if pushManager == nil {
+		respond.Fail(c, errPushCenterDisabled, tool.MakeTimestamp()-t)
+		return
+	}
+
+	notification := &push_service.PushNotification{
+		Data:             requestModel.Data,
+		Priority:         requestModel.Priority,
+		TTL:              requestModel.TTL,
+		ContentAvailable: true,
+		DryRun:           requestModel.DryRun,
+	}
+	if notification.Priority == "" {
+		notification.Priority = push_service.PriorityNormal
+	}
+
+	idempotencyKey := requestModel.IdempotencyKey
+	if idempotencyKey == "" {
+		idempotencyKey = c.GetHeader("Idempotency-Key")
+	}
+	sendWithIdempotency(c, t, pushManager, requestModel.MetaIDs, notification, idempotencyKey)
+}
+
+// 测试推送默认文案和最长回执等待时间
+const (
+	testPushTitle   = "测试推送"
+	testPushBody    = "这是一条测试推送，收到说明设备推送配置正常"
+	testPushMaxWait = 60
+)
+
+// TestPush godoc
+// @Summary 发送测试推送
+// @Description 向指定用户（metaId，发送到该用户登记的所有平台）或指定令牌（token）发送一条测试通知，用于客服和测试排查设备推送配置。直接调用推送平台，不经过去重、屏蔽、限流和配额，也不计入投递记录和审计日志。同步返回每个平台的受理结果（ticket）；wait 大于 0 时在该秒数内轮询投递回执（receipt），回执状态为 delivered / failed / pending（未等到）/ unavailable（平台不支持回执或未受理）。
+// @Tags Push API
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body request.TestPushReq true "请求参数（metaId 或 token，可选 platform、title、body、wait）"
+// @Success 200 {object} respond.Response "成功响应"
+// @Failure 400 {object} respond.Response "参数错误"
+// @Failure 401 {object} respond.Response "认证失败"
+// @Failure 404 {object} respond.Response "用户没有登记推送令牌"
+// @Failure 503 {object} respond.Response "推送中心未启用"
+// @Failure 500 {object} respond.Response "服务器内部错误"
+// @Router /v1/push/test_push [post]
+func TestPush(c *gin.Context) {
+	var (
+		t            int64 = tool.MakeTimestamp()
+		requestModel *request.TestPushReq
+	)
+
+	if err := c.ShouldBindJSON(&requestModel); err != nil {
+		respond.Fail(c, respond.BindError(err), tool.MakeTimestamp()-t)
+		return
+	}
+	if requestModel.MetaID == "" && requestModel.Token == "" {
+		respond.Fail(c, respond.InvalidParam(errors.New("需要指定 metaId 或 token"),
+			respond.FieldError{Field: "metaId", Rule: "required_without", Message: "metaId 和 token 至少指定一个"},
+			respond.FieldError{Field: "token", Rule: "required_without", Message: "metaId 和 token 至少指定一个"}), tool.MakeTimestamp()-t)
+		return
+	}
+	if requestModel.Wait < 0 {
+		respond.Fail(c, respond.InvalidField("wait", errors.New("wait 不能小于 0")), tool.MakeTimestamp()-t)
+		return
+	}
+
+	pushManager := push_service.GetGlobalManager()
+	if pushManager == nil {
+		respond.Fail(c, errPushCenterDisabled, tool.MakeTimestamp()-t)
+		return
+	}
+
+	notification := &push_service.PushNotification{
+		Title:    requestModel.Title,
+		Body:     requestModel.Body,
+		Data:     map[string]interface{}{"type": "test_push"},
+		Sound:    "default",
+		Priority: push_service.PriorityHigh,
+	}
+	if notification.Title == "" {
+		notification.Title = testPushTitle
+	}
+	if notification.Body == "" {
+		notification.Body = testPushBody
+	}
+	wait := time.Duration(min(requestModel.Wait, testPushMaxWait)) * time.Second
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second+wait)
+	defer cancel()
+
+	results, err := pushManager.SendTestPush(ctx, &push_service.TestPushTarget{
+		MetaID:   requestModel.MetaID,
+		Platform: requestModel.Platform,
+		Token:    requestModel.Token,
+	}, notification)
+	if err != nil {
+		respond.Fail(c, testPushError(err), tool.MakeTimestamp()-t)
+		return
+	}
+	receipts := pushManager.WaitForReceipts(ctx, results, wait)
+
+	items := make([]map[string]interface{}, 0, len(results))
+	for _, result := range results {
+		item := map[string]interface{}{
+			"platform":   result.Platform,
+			"token":      result.Token,
+			"accepted":   result.Success,
+			"receiptId":  result.ReceiptID,
+			"simulated":  result.Simulated,
+			"durationMs": result.Duration.Milliseconds(),
+		}
+		if result.Error != nil {
+			item["error"] = result.Error.Error()
+		}
+
+		receiptStatus := "unavailable"
+		if outcome, ok := receipts[result.ReceiptID]; ok && result.ReceiptID != "" {
+			item["receipt"] = outcome
+			receiptStatus = "failed"
+			if outcome.Delivered {
+				receiptStatus = "delivered"
+			}
+		} else if result.Success && result.ReceiptID != "" {
+			receiptStatus = "pending"
+		}
+		item["receiptStatus"] = receiptStatus
+		items = append(items, item)
+	}
+
+	respond.JSONP(c, http.StatusOK, respond.RespSuccess(map[string]interface{}{"results": items}, tool.MakeTimestamp()-t))
+}
+
+// sendWithIdempotency 发送通知并输出结果：已处理过的幂等键直接返回首次结果，推送失败时释放幂等键
+// 演练请求不占用幂等键，避免之后使用相同幂等键的正式推送被当作重复请求
+func sendWithIdempotency(c *gin.Context, t int64, pushManager *push_service.Manager, metaIds []string, notification *push_service.PushNotification, idempotencyKey string) {
+	// 租户配额计入调用方 API Key 绑定的租户
+	notification.TenantID = auth.GetRequestAPIKeyTenant(c)
+
+	// 幂等检查：已处理过的请求直接返回首次结果
+	var storeKey string
+	if idempotencyKey != "" && !notification.DryRun {
+		storeKey = "api:" + idempotencyKey
+		record, claimed, err := pebble_service.ClaimIdempotencyKey(storeKey, "api")
+		if err != nil {
+			respond.Fail(c, err, tool.MakeTimestamp()-t)
+			return
+		}
+		if !claimed {
+			responseData := map[string]interface{}{
+				"duplicate": true,
+				"result":    record.Result,
+			}
+			if record.Result == nil {
+				responseData["message"] = "相同幂等键的请求正在处理中"
+			}
+			respond.JSONP(c, http.StatusOK, respond.RespSuccess(responseData, tool.MakeTimestamp()-t))
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	batchResult, err := pushManager.SendCustomNotificationToUsers(ctx, metaIds, notification)
+	if err == nil && batchResult.SuccessCount == 0 && batchResult.FailureCount > 0 && batchResult.FallbackCount == 0 {
+		// 所有推送平台调用都失败
+		err = respond.ProviderError(firstPushError(batchResult.Results))
+	}
+	if err != nil {
+		// 推送失败时释放幂等键，允许调用方重试
+		if storeKey != "" {
+			if releaseErr := pebble_service.ReleaseIdempotencyKey(storeKey); releaseErr != nil {
+				log.Printf("⚠️ 释放幂等键失败: %v", releaseErr)
+			}
+		}
+		respond.Fail(c, err, tool.MakeTimestamp()-t)
+		return
+	}
+
+	result := map[string]interface{}{
+		"totalUsers":     batchResult.TotalUsers,
+		"totalPlatforms": batchResult.TotalPlatforms,
+		"successCount":   batchResult.SuccessCount,
+		"failureCount":   batchResult.FailureCount,
+		"quotaRejected":  batchResult.QuotaRejected,
+		"downgraded":     batchResult.Downgraded,
+		"dryRun":         batchResult.DryRun,
+	}
+	if storeKey != "" {
+		if err := pebble_service.CompleteIdempotencyKey(storeKey, result); err != nil {
+			log.Printf("⚠️ 记录幂等键结果失败: %v", err)
+		}
+	}
+
+	responseData := map[string]interface{}{
+		"duplicate": false,
+		"result":    result,
+	}
+
+	respond.JSONP(c, http.StatusOK, respond.RespSuccess(responseData, tool.MakeTimestamp()-t))
+}
+
+// firstPushError 返回第一个失败的推送结果的错误
+func firstPushError(results []*push_service.PushResult) error {
+	for _, result := range results {
+		if !result.Success && result.Error != nil {
+			return fmt.Errorf("推送平台 %s 调用失败: %w", result.Platform, result.Error)
+		}
+	}
+	return errors.New("推送平台调用失败")
+}
+
+// testPushError 将测试推送目标错误转换为对应的接口错误
+func testPushError(err error) error {
+	switch {
+	case errors.Is(err, push_service.ErrInvalidTestTarget):
+		return respond.InvalidParam(err)
+	case errors.Is(err, push_service.ErrUserUnsubscribed):
+		return respond.InvalidField("metaId", err)
+	case errors.Is(err, push_service.ErrNoRegisteredTokens):
+		return respond.NotFound(err)
+	default:
+		return err
+	}
+}
@@ -0,0 +1,54 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"push-base-service/controller/request"
+	"push-base-service/controller/respond"
+	"push-base-service/service/storage_service"
+	"push-base-service/tool"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SeedTestData godoc
+// @Summary 生成测试数据（仅预发环境）
+// @Description 生成指定数量的测试用户（MetaID 为 前缀+序号），每个用户登记一个 mock 平台令牌（由模拟推送提供者直接返回成功）、随机的屏蔽聊天和语言偏好，用于压测和界面演示。仅在 staging.enabled 开启时注册该接口；重复执行会覆盖同名用户。
+// @Tags Admin API
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body request.SeedTestDataReq true "请求参数（count，可选 prefix、maxBlockedChats、seed）"
+// @Success 200 {object} respond.Response{data=storage_service.SeedResult} "成功响应"
+// @Failure 401 {object} respond.Response "认证失败"
+// @Failure 500 {object} respond.Response "服务器内部错误"
+// @Router /v1/admin/seed_test_data [post]
+func SeedTestData(c *gin.Context) {
+	var (
+		t            int64 = tool.MakeTimestamp()
+		requestModel *request.SeedTestDataReq
+	)
+
+	if c.ShouldBindJSON(&requestModel) == nil {
+		maxBlocked := storage_service.DefaultSeedBlockedMax
+		if requestModel.MaxBlockedChats != nil {
+			maxBlocked = *requestModel.MaxBlockedChats
+		}
+
+		result, err := storage_service.SeedTestData(&storage_service.SeedRequest{
+			Count:           requestModel.Count,
+			Prefix:          requestModel.Prefix,
+			MaxBlockedChats: maxBlocked,
+			Seed:            requestModel.Seed,
+		})
+		if err != nil {
+			respond.JSONP(c, http.StatusOK, respond.RespErr(err, tool.MakeTimestamp()-t, respond.HttpsCodeError))
+			return
+		}
+
+		respond.JSONP(c, http.StatusOK, respond.RespSuccess(result, tool.MakeTimestamp()-t))
+		return
+	}
+
+	respond.JSONP(c, http.StatusInternalServerError, respond.RespErr(errors.New("参数错误"), tool.MakeTimestamp()-t, respond.HttpsCodeError))
+}
@@ -0,0 +1,176 @@
+package controller
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"push-base-service/controller/auth"
+	"push-base-service/controller/request"
+	"push-base-service/controller/respond"
+	"push-base-service/models"
+	"push-base-service/service/pebble_service"
+	"push-base-service/tool"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// subscriptionSourceUser 用户本人（签名调用）操作退订时记录的来源
+const subscriptionSourceUser = "user"
+
+// subscriptionSource 退订操作的来源：通过 API Key 调用时为 api_key:<Key 名称>，否则为用户本人
+func subscriptionSource(c *gin.Context) string {
+	if name := auth.GetRequestAPIKeyName(c); name != "" {
+		return "api_key:" + name
+	}
+	return subscriptionSourceUser
+}
+
+// Unsubscribe godoc
+// @Summary 退订全部推送
+// @Description 用户退订后，聊天通知、send/send_data、定时推送和测试推送等所有发送接口都不再向该用户推送（投递记录的跳过原因为 unsubscribed），直到调用 resubscribe。每次退订和重新订阅都记入审计记录，可通过 /v1/admin/export_unsubscribes 导出
+// @Tags Push API
+// @Accept json
+// @Produce json
+// @Param request body request.UnsubscribeReq true "请求参数"
+// @Success 200 {object} respond.Response{data=models.PushSubscription} "成功响应"
+// @Failure 500 {object} respond.Response "服务器内部错误"
+// @Router /v1/push/unsubscribe [post]
+func Unsubscribe(c *gin.Context) {
+	var (
+		t            int64 = tool.MakeTimestamp()
+		requestModel *request.UnsubscribeReq
+	)
+
+	if err := c.ShouldBindJSON(&requestModel); err != nil {
+		respond.Fail(c, respond.BindError(err), tool.MakeTimestamp()-t)
+		return
+	}
+
+	subscription, err := pebble_service.Unsubscribe(requestModel.MetaID, requestModel.Reason, subscriptionSource(c))
+	if err != nil {
+		respond.Fail(c, err, tool.MakeTimestamp()-t)
+		return
+	}
+
+	respond.JSONP(c, http.StatusOK, respond.RespSuccess(subscription, tool.MakeTimestamp()-t))
+}
+
+// Resubscribe godoc
+// @Summary 重新订阅推送
+// @Description 取消退订，恢复向用户推送；之前的退订记录保留在审计记录中
+// @Tags Push API
+// @Accept json
+// @Produce json
+// @Param request body request.ResubscribeReq true "请求参数"
+// @Success 200 {object} respond.Response{data=models.PushSubscription} "成功响应"
+// @Failure 500 {object} respond.Response "服务器内部错误"
+// @Router /v1/push/resubscribe [post]
+func Resubscribe(c *gin.Context) {
+	var (
+		t            int64 = tool.MakeTimestamp()
+		requestModel *request.ResubscribeReq
+	)
+
+	if err := c.ShouldBindJSON(&requestModel); err != nil {
+		respond.Fail(c, respond.BindError(err), tool.MakeTimestamp()-t)
+		return
+	}
+
+	subscription, err := pebble_service.Resubscribe(requestModel.MetaID, subscriptionSource(c))
+	if err != nil {
+		respond.Fail(c, err, tool.MakeTimestamp()-t)
+		return
+	}
+
+	respond.JSONP(c, http.StatusOK, respond.RespSuccess(subscription, tool.MakeTimestamp()-t))
+}
+
+// GetSubscription godoc
+// @Summary 获取用户推送订阅状态
+// @Description 获取用户是否已退订推送及退订和重新订阅记录，从未退订时返回 unsubscribed=false
+// @Tags Push API
+// @Produce json
+// @Param metaId query string true "用户唯一标识"
+// @Success 200 {object} respond.Response{data=models.PushSubscription} "成功响应"
+// @Failure 500 {object} respond.Response "服务器内部错误"
+// @Router /v1/push/get_subscription [get]
+func GetSubscription(c *gin.Context) {
+	var t int64 = tool.MakeTimestamp()
+
+	metaId := c.Query("metaId")
+	if metaId == "" {
+		respond.Fail(c, respond.MissingParam("metaId"), tool.MakeTimestamp()-t)
+		return
+	}
+
+	subscription, err := pebble_service.GetSubscription(metaId)
+	if err != nil {
+		respond.Fail(c, err, tool.MakeTimestamp()-t)
+		return
+	}
+	if subscription == nil {
+		subscription = &models.PushSubscription{MetaID: metaId, History: []models.SubscriptionEvent{}}
+	}
+
+	respond.JSONP(c, http.StatusOK, respond.RespSuccess(subscription, tool.MakeTimestamp()-t))
+}
+
+// unsubscribesCSVHeader 退订审计导出的 CSV 表头，每次操作一行
+var unsubscribesCSVHeader = []string{"metaId", "action", "reason", "source", "at", "unsubscribed"}
+
+// ExportUnsubscribes godoc
+// @Summary 导出退订审计记录
+// @Description 导出所有有过退订记录的用户（含已重新订阅的）及每次退订和重新订阅的时间、原因和来源，用于合规审计。CSV 格式每次操作一行，unsubscribed 列为用户当前是否仍退订。结果以附件形式下载
+// @Tags Admin API
+// @Produce json
+// @Produce text/csv
+// @Security ApiKeyAuth
+// @Param format query string false "导出格式：json（默认）或 csv"
+// @Success 200 {array} models.PushSubscription "导出内容"
+// @Failure 401 {object} respond.Response "认证失败"
+// @Failure 500 {object} respond.Response "服务器内部错误"
+// @Router /v1/admin/export_unsubscribes [get]
+func ExportUnsubscribes(c *gin.Context) {
+	var t int64 = tool.MakeTimestamp()
+
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "csv" {
+		respond.JSONP(c, http.StatusOK, respond.RespErr(fmt.Errorf("不支持的导出格式: %s", format), tool.MakeTimestamp()-t, respond.HttpsCodeError))
+		return
+	}
+
+	subscriptions, err := pebble_service.ListSubscriptions()
+	if err != nil {
+		respond.JSONP(c, http.StatusOK, respond.RespErr(err, tool.MakeTimestamp()-t, respond.HttpsCodeError))
+		return
+	}
+
+	name := "push-unsubscribes-" + time.Now().UTC().Format("20060102-150405")
+	if format == "json" {
+		if subscriptions == nil {
+			subscriptions = []*models.PushSubscription{}
+		}
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name+".json"))
+		c.JSON(http.StatusOK, subscriptions)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name+".csv"))
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	writer := csv.NewWriter(c.Writer)
+	writer.Write(unsubscribesCSVHeader)
+	for _, subscription := range subscriptions {
+		for _, event := range subscription.History {
+			writer.Write([]string{
+				subscription.MetaID, event.Action, event.Reason, event.Source,
+				strconv.FormatInt(event.At, 10), strconv.FormatBool(subscription.Unsubscribed),
+			})
+		}
+	}
+	writer.Flush()
+	if writer.Error() != nil {
+		c.Abort()
+	}
+}
@@ -0,0 +1,170 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"push-base-service/controller/request"
+	"push-base-service/controller/respond"
+	"push-base-service/service/pebble_service"
+	pushcenter "push-base-service/service/push_center"
+	"push-base-service/tool"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StartUserTrace godoc
+// @Summary 开始追踪用户推送
+// @Description 在限定时间内记录该用户的每个推送决策：被接收用户校验剔除、因屏蔽聊天/暂停通知/关闭该类通知未发送、发送到哪个平台及结果、回执状态（回执需开启 delivery）。已在追踪时更新截止时间
+// @Tags Admin API
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body request.StartUserTraceReq true "请求参数"
+// @Success 200 {object} respond.Response{data=models.UserTrace} "成功响应"
+// @Failure 401 {object} respond.Response "认证失败"
+// @Failure 500 {object} respond.Response "服务器内部错误"
+// @Router /v1/admin/start_user_trace [post]
+func StartUserTrace(c *gin.Context) {
+	var (
+		t            int64 = tool.MakeTimestamp()
+		requestModel *request.StartUserTraceReq
+	)
+
+	if c.ShouldBindJSON(&requestModel) == nil {
+		var duration time.Duration
+		if requestModel.Duration != "" {
+			parsed, err := time.ParseDuration(requestModel.Duration)
+			if err != nil || parsed <= 0 {
+				respond.JSONP(c, http.StatusOK, respond.RespErr(fmt.Errorf("duration 无效: %s（如 30m、2h）", requestModel.Duration), tool.MakeTimestamp()-t, respond.HttpsCodeError))
+				return
+			}
+			duration = parsed
+		}
+
+		trace, err := pushcenter.StartUserTrace(requestModel.MetaID, requestModel.Note, duration)
+		if err != nil {
+			respond.JSONP(c, http.StatusOK, respond.RespErr(err, tool.MakeTimestamp()-t, respond.HttpsCodeError))
+			return
+		}
+
+		respond.JSONP(c, http.StatusOK, respond.RespSuccess(trace, tool.MakeTimestamp()-t))
+		return
+	}
+
+	respond.JSONP(c, http.StatusInternalServerError, respond.RespErr(errors.New("参数错误"), tool.MakeTimestamp()-t, respond.HttpsCodeError))
+}
+
+// StopUserTrace godoc
+// @Summary 停止追踪用户推送
+// @Description 立即停止记录该用户的推送决策，已记录的事件在追踪结束 7 天后清理
+// @Tags Admin API
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param request body request.StopUserTraceReq true "请求参数"
+// @Success 200 {object} respond.Response "成功响应"
+// @Failure 401 {object} respond.Response "认证失败"
+// @Failure 500 {object} respond.Response "服务器内部错误"
+// @Router /v1/admin/stop_user_trace [post]
+func StopUserTrace(c *gin.Context) {
+	var (
+		t            int64 = tool.MakeTimestamp()
+		requestModel *request.StopUserTraceReq
+	)
+
+	if c.ShouldBindJSON(&requestModel) == nil {
+		if err := pushcenter.StopUserTrace(requestModel.MetaID); err != nil {
+			respond.JSONP(c, http.StatusOK, respond.RespErr(err, tool.MakeTimestamp()-t, respond.HttpsCodeError))
+			return
+		}
+
+		responseData := map[string]interface{}{
+			"success": true,
+			"metaId":  requestModel.MetaID,
+		}
+
+		respond.JSONP(c, http.StatusOK, respond.RespSuccess(responseData, tool.MakeTimestamp()-t))
+		return
+	}
+
+	respond.JSONP(c, http.StatusInternalServerError, respond.RespErr(errors.New("参数错误"), tool.MakeTimestamp()-t, respond.HttpsCodeError))
+}
+
+// GetUserTraces godoc
+// @Summary 获取推送追踪列表
+// @Description 获取所有推送追踪（含已结束但事件尚未清理的），until 早于当前时间表示已结束
+// @Tags Admin API
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} respond.Response{data=[]models.UserTrace} "成功响应"
+// @Failure 401 {object} respond.Response "认证失败"
+// @Failure 500 {object} respond.Response "服务器内部错误"
+// @Router /v1/admin/get_user_traces [get]
+func GetUserTraces(c *gin.Context) {
+	var t int64 = tool.MakeTimestamp()
+
+	traces, err := pebble_service.ListUserTraces()
+	if err != nil {
+		respond.JSONP(c, http.StatusOK, respond.RespErr(err, tool.MakeTimestamp()-t, respond.HttpsCodeError))
+		return
+	}
+
+	respond.JSONP(c, http.StatusOK, respond.RespSuccess(traces, tool.MakeTimestamp()-t))
+}
+
+// GetUserTrace godoc
+// @Summary 获取用户推送追踪事件
+// @Description 获取用户的推送追踪及按时间升序的追踪事件，stage 为 rejected/skipped/sent/failed/receipt。since 为 Unix 毫秒，只返回之后发生的事件
+// @Tags Admin API
+// @Produce json
+// @Security ApiKeyAuth
+// @Param metaId query string true "用户MetaID"
+// @Param since query int false "只返回该时间（Unix 毫秒）之后的事件"
+// @Param limit query int false "最多返回最新的条数"
+// @Success 200 {object} respond.Response "成功响应，data 为 {trace, events}"
+// @Failure 401 {object} respond.Response "认证失败"
+// @Failure 500 {object} respond.Response "服务器内部错误"
+// @Router /v1/admin/get_user_trace [get]
+func GetUserTrace(c *gin.Context) {
+	var t int64 = tool.MakeTimestamp()
+
+	metaId := c.Query("metaId")
+	if metaId == "" {
+		respond.JSONP(c, http.StatusOK, respond.RespErr(errors.New("metaId 参数不能为空"), tool.MakeTimestamp()-t, respond.HttpsCodeError))
+		return
+	}
+
+	var since int64
+	if sinceStr := c.Query("since"); sinceStr != "" {
+		if s, err := strconv.ParseInt(sinceStr, 10, 64); err == nil && s > 0 {
+			since = s
+		}
+	}
+	limit := 0
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
+			limit = l
+		}
+	}
+
+	trace, err := pebble_service.GetUserTrace(metaId)
+	if err != nil {
+		respond.JSONP(c, http.StatusOK, respond.RespErr(err, tool.MakeTimestamp()-t, respond.HttpsCodeError))
+		return
+	}
+	events, err := pebble_service.GetTraceEvents(metaId, since, limit)
+	if err != nil {
+		respond.JSONP(c, http.StatusOK, respond.RespErr(err, tool.MakeTimestamp()-t, respond.HttpsCodeError))
+		return
+	}
+
+	responseData := map[string]interface{}{
+		"trace":  trace,
+		"events": events,
+	}
+
+	respond.JSONP(c, http.StatusOK, respond.RespSuccess(responseData, tool.MakeTimestamp()-t))
+}
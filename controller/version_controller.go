@@ -0,0 +1,52 @@
+package controller
+
+import (
+	"net/http"
+	"push-base-service/conf"
+	"push-base-service/controller/respond"
+	"push-base-service/major"
+	"push-base-service/models"
+	"push-base-service/service/dedup_service"
+	"push-base-service/service/storage_service"
+	"push-base-service/service/throttle_service"
+	"push-base-service/tool"
+	"runtime"
+	"runtime/debug"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetVersion godoc
+// @Summary 获取服务版本和构建能力
+// @Description 返回服务版本、Go 版本、Git 提交、构建标签，以及可选集成是否编译进当前构建。使用 noredis 构建时 redis 为 false，配置 redis 存储后端、PIN 去重或限流后端会在启动时报错；使用 nomysql 构建时 mysql 为 false
+// @Tags Admin API
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} respond.Response{data=models.VersionInfo} "成功响应"
+// @Failure 401 {object} respond.Response "认证失败"
+// @Router /v1/admin/version [get]
+func GetVersion(c *gin.Context) {
+	var t int64 = tool.MakeTimestamp()
+
+	info := &models.VersionInfo{
+		Version:   conf.Version,
+		GoVersion: runtime.Version(),
+		Capabilities: map[string]bool{
+			// 三个 Redis 后端使用同一个 noredis 构建标签
+			"redis": storage_service.RedisEnabled && dedup_service.RedisEnabled && throttle_service.RedisEnabled,
+			"mysql": major.MySQLEnabled,
+		},
+	}
+	if buildInfo, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range buildInfo.Settings {
+			switch setting.Key {
+			case "-tags":
+				info.BuildTags = setting.Value
+			case "vcs.revision":
+				info.Revision = setting.Value
+			}
+		}
+	}
+
+	respond.JSONP(c, http.StatusOK, respond.RespSuccess(info, tool.MakeTimestamp()-t))
+}
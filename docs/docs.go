@@ -16,41 +16,48 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/v1/push/add_blocked_chat": {
+        "/v1/admin/backup": {
             "post": {
-                "description": "为用户添加屏蔽某个群聊或私聊",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "对所有集合创建一致的检查点并打包为 tar.gz。默认保存到服务端备份目录（backup.dir）并返回文件信息；download=true 时直接以附件形式下载，不落盘；async=true 时作为后台任务执行并立即返回任务信息，通过 /v1/admin/jobs/{id} 查询结果",
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/gzip"
                 ],
                 "tags": [
-                    "Push API"
+                    "Admin API"
                 ],
-                "summary": "添加屏蔽聊天",
+                "summary": "创建 Pebble 备份",
                 "parameters": [
                     {
-                        "description": "请求参数",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/request.AddBlockedChatReq"
-                        }
+                        "type": "boolean",
+                        "description": "是否直接下载备份归档",
+                        "name": "download",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否作为后台任务执行",
+                        "name": "async",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "成功响应",
-                        "schema": {
-                            "$ref": "#/definitions/respond.Response"
-                        }
-                    },
-                    "400": {
-                        "description": "参数错误",
+                        "description": "成功响应（async=true 时 data 为 models.Job）",
                         "schema": {
-                            "$ref": "#/definitions/respond.Response"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.BackupFile"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "401": {
@@ -68,46 +75,38 @@ const docTemplate = `{
                 }
             }
         },
-        "/v1/push/get_user_blocked_chats": {
-            "get": {
-                "description": "根据用户 metaId 获取该用户屏蔽的聊天列表",
+        "/v1/admin/clear_qa_inbox": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "清空 QA 账号收件箱中的所有消息，通常在每个测试用例开始前调用",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Push API"
+                    "Admin API"
                 ],
-                "summary": "获取用户屏蔽聊天列表",
+                "summary": "清空 QA 账号虚拟收件箱",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "用户唯一标识",
-                        "name": "metaId",
-                        "in": "query",
-                        "required": true
+                        "description": "请求参数",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.ClearQAInboxReq"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "成功响应",
-                        "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/respond.Response"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "data": {
-                                            "$ref": "#/definitions/models.UserBlockedChats"
-                                        }
-                                    }
-                                }
-                            ]
-                        }
-                    },
-                    "400": {
-                        "description": "参数错误",
                         "schema": {
                             "$ref": "#/definitions/respond.Response"
                         }
@@ -127,46 +126,24 @@ const docTemplate = `{
                 }
             }
         },
-        "/v1/push/get_user_token": {
-            "get": {
-                "description": "根据用户 metaId 获取该用户的所有推送令牌",
+        "/v1/admin/clear_quarantine": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "删除所有隔离消息，通常在上游格式问题处理完成后调用",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Push API"
-                ],
-                "summary": "根据 metaId 获取用户推送令牌",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "用户唯一标识",
-                        "name": "metaId",
-                        "in": "query",
-                        "required": true
-                    }
+                    "Admin API"
                 ],
+                "summary": "清空隔离的上游消息",
                 "responses": {
                     "200": {
-                        "description": "成功响应",
-                        "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/respond.Response"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "data": {
-                                            "$ref": "#/definitions/models.UserPushTokens"
-                                        }
-                                    }
-                                }
-                            ]
-                        }
-                    },
-                    "400": {
-                        "description": "参数错误",
+                        "description": "成功响应，data 为 {cleared}",
                         "schema": {
                             "$ref": "#/definitions/respond.Response"
                         }
@@ -186,35 +163,38 @@ const docTemplate = `{
                 }
             }
         },
-        "/v1/push/get_user_tokens_list": {
-            "get": {
-                "description": "分页获取所有用户的推送令牌列表",
+        "/v1/admin/compact": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "对指定集合（默认所有已打开的集合）执行全量压缩，回收已删除数据占用的空间并降低读放大。压缩期间会占用较多磁盘 IO；async=true 时作为后台任务执行并立即返回任务信息，通过 /v1/admin/jobs/{id} 查询结果",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Push API"
+                    "Admin API"
                 ],
-                "summary": "获取用户推送令牌列表（分页）",
+                "summary": "手动压缩 Pebble 数据库",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "default": 1,
-                        "description": "页码，默认为1",
-                        "name": "page",
+                        "type": "string",
+                        "description": "要压缩的集合，多个用逗号分隔，默认所有已打开的集合",
+                        "name": "collections",
                         "in": "query"
                     },
                     {
-                        "type": "integer",
-                        "default": 10,
-                        "description": "每页大小，默认为10",
-                        "name": "pageSize",
+                        "type": "boolean",
+                        "description": "是否作为后台任务执行",
+                        "name": "async",
                         "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "成功响应",
+                        "description": "成功响应（async=true 时 data 为 models.Job）",
                         "schema": {
                             "allOf": [
                                 {
@@ -224,19 +204,16 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/pebble_service.PaginatedUserTokens"
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/pebble_service.CompactionResult"
+                                            }
                                         }
                                     }
                                 }
                             ]
                         }
                     },
-                    "400": {
-                        "description": "参数错误",
-                        "schema": {
-                            "$ref": "#/definitions/respond.Response"
-                        }
-                    },
                     "401": {
                         "description": "认证失败",
                         "schema": {
@@ -252,9 +229,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/v1/push/remove_blocked_chat": {
+        "/v1/admin/create_api_key": {
             "post": {
-                "description": "移除用户对某个群聊或私聊的屏蔽",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "生成新的具名 API Key 并指定权限范围（read：查询接口；write：发送推送、修改用户数据，包含 read；admin：管理接口，包含全部）和可选的所属租户（通过该 Key 发送的推送计入该租户的配额）。Key 只在本次响应中返回，服务端只保存其哈希",
                 "consumes": [
                     "application/json"
                 ],
@@ -262,9 +244,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Push API"
+                    "Admin API"
                 ],
-                "summary": "移除屏蔽聊天",
+                "summary": "创建 API Key",
                 "parameters": [
                     {
                         "description": "请求参数",
@@ -272,7 +254,7 @@ const docTemplate = `{
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/request.RemoveBlockedChatReq"
+                            "$ref": "#/definitions/request.CreateAPIKeyReq"
                         }
                     }
                 ],
@@ -283,14 +265,14 @@ const docTemplate = `{
                             "$ref": "#/definitions/respond.Response"
                         }
                     },
-                    "400": {
-                        "description": "参数错误",
+                    "401": {
+                        "description": "认证失败",
                         "schema": {
                             "$ref": "#/definitions/respond.Response"
                         }
                     },
-                    "401": {
-                        "description": "认证失败",
+                    "403": {
+                        "description": "权限不足",
                         "schema": {
                             "$ref": "#/definitions/respond.Response"
                         }
@@ -304,30 +286,21 @@ const docTemplate = `{
                 }
             }
         },
-        "/v1/push/remove_user_all_tokens": {
-            "post": {
-                "description": "移除指定用户的所有推送令牌",
-                "consumes": [
-                    "application/json"
+        "/v1/admin/dashboard": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
                 ],
+                "description": "汇总管理看板展示的运行状态：各上游 Socket 连接状态（socket）、推送积压和 Webhook 投递队列长度（queue，推送中心未启用时 push 为 null）、各推送提供者的发送统计（providers）、最近的推送成功（recentPushes）和失败（recentFailures）事件，每类最多 50 条，按时间倒序",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Push API"
-                ],
-                "summary": "移除用户所有推送令牌",
-                "parameters": [
-                    {
-                        "description": "请求参数",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/request.RemoveUserAllTokensReq"
-                        }
-                    }
+                    "Admin API"
                 ],
+                "summary": "获取管理看板数据",
                 "responses": {
                     "200": {
                         "description": "成功响应",
@@ -335,62 +308,50 @@ const docTemplate = `{
                             "$ref": "#/definitions/respond.Response"
                         }
                     },
-                    "400": {
-                        "description": "参数错误",
-                        "schema": {
-                            "$ref": "#/definitions/respond.Response"
-                        }
-                    },
                     "401": {
                         "description": "认证失败",
                         "schema": {
                             "$ref": "#/definitions/respond.Response"
                         }
-                    },
-                    "500": {
-                        "description": "服务器内部错误",
-                        "schema": {
-                            "$ref": "#/definitions/respond.Response"
-                        }
                     }
                 }
             }
         },
-        "/v1/push/remove_user_token": {
-            "post": {
-                "description": "移除指定用户在指定平台的推送令牌",
-                "consumes": [
-                    "application/json"
+        "/v1/admin/db_stats": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
                 ],
+                "description": "获取每个已打开的 Pebble 实例（独立布局下每个集合一个实例，共享布局下只有共享实例 _keyspace）的磁盘占用、SST 文件数和大小、读放大、内存表占用、WAL 大小、刷盘和压缩次数、压缩欠账以及块缓存命中情况",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Push API"
-                ],
-                "summary": "移除用户推送令牌",
-                "parameters": [
-                    {
-                        "description": "请求参数",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/request.RemoveUserTokenReq"
-                        }
-                    }
+                    "Admin API"
                 ],
+                "summary": "获取 Pebble 数据库内部统计",
                 "responses": {
                     "200": {
                         "description": "成功响应",
                         "schema": {
-                            "$ref": "#/definitions/respond.Response"
-                        }
-                    },
-                    "400": {
-                        "description": "参数错误",
-                        "schema": {
-                            "$ref": "#/definitions/respond.Response"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/pebble_service.CollectionDBStats"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "401": {
@@ -408,41 +369,187 @@ const docTemplate = `{
                 }
             }
         },
-        "/v1/push/set_user_tokens": {
-            "post": {
+        "/v1/admin/delivery_breakdown": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "为指定用户在指定平台设置推送令牌，支持Token唯一性检查。Token本身就是设备的唯一标识，如果Token已被其他用户使用，会自动从原用户中移除该平台的令牌。",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "将最近 hours 小时的投递记录按推送平台、客户端版本和系统主版本分组，统计推送数、受理失败、回执送达/失败/等待/不可用数以及送达率（已送达 / (已送达 + 回执失败 + 受理失败)），用于发现某个客户端版本的推送异常（如令牌刷新失效）。客户端版本和系统版本取自推送时接收设备上报的设备信息，需开启 push_center.delivery.enabled。同样的数据以 push_delivery_outcomes_total 指标暴露在 /metrics",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Push API"
+                    "Admin API"
                 ],
-                "summary": "设置用户推送令牌",
+                "summary": "按客户端版本统计投递送达率",
                 "parameters": [
                     {
-                        "description": "请求参数（metaId、platform、token）",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/request.SetUserTokensReq"
-                        }
+                        "type": "integer",
+                        "description": "统计最近多少小时（默认24，最大168）",
+                        "name": "hours",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "只统计该推送平台",
+                        "name": "platform",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "成功响应",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.DeliveryBreakdown"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "认证失败",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/admin/enabled_types": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "获取推送中心当前启用的消息类型及其来源（config：配置文件 push_center.enabled_types，api：管理接口设置）",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin API"
+                ],
+                "summary": "获取启用的消息类型",
+                "responses": {
+                    "200": {
+                        "description": "成功响应，data 为 {types, source, updatedAt, supportedTypes}",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "认证失败",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "整体替换推送中心启用的消息类型，立即生效并保存（重启后仍生效），覆盖配置文件 push_center.enabled_types。未启用类型的消息直接忽略，至少需要启用一种类型。",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin API"
+                ],
+                "summary": "设置启用的消息类型",
+                "parameters": [
+                    {
+                        "description": "启用的消息类型",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.SetEnabledTypesReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功响应，data 为 {types, source, updatedAt, supportedTypes}",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "认证失败",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
                         "schema": {
                             "$ref": "#/definitions/respond.Response"
                         }
+                    }
+                }
+            }
+        },
+        "/v1/admin/events": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "以 Server-Sent Events 推送流水线实时事件，供运维看板展示实时流量而无需轮询日志：message_received（收到消息，count 为接收用户数）、filtered（用户被跳过或消息被去重，reason 为原因）、sent / failed（推送平台受理或失败，包括发送接口的推送）、receipt_updated（查询到投递回执，reason 为回执状态，需开启 delivery）。\n每个事件的 event 字段为事件类型，data 为 JSON；每 15 秒发送一次心跳注释。客户端处理过慢时丢弃事件，不影响推送。",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "Admin API"
+                ],
+                "summary": "订阅推送流水线实时事件",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "只订阅这些类型的事件，逗号分隔，默认全部",
+                        "name": "types",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "事件流",
+                        "schema": {
+                            "$ref": "#/definitions/models.PipelineEvent"
+                        }
                     },
                     "400": {
                         "description": "参数错误",
@@ -450,6 +557,96 @@ const docTemplate = `{
                             "$ref": "#/definitions/respond.Response"
                         }
                     },
+                    "401": {
+                        "description": "认证失败",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/admin/export": {
+            "get": {
+                "description": "导出用户令牌、设备和屏蔽聊天，用于在环境之间（如 testnet/mainnet）迁移数据。JSON 格式默认导出全部数据集；CSV 格式每次导出一个数据集，需指定 dataset。结果以附件形式下载",
+                "produces": [
+                    "application/json",
+                    "text/csv"
+                ],
+                "tags": [
+                    "Admin API"
+                ],
+                "summary": "导出用户数据",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "导出格式：json（默认）或 csv",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "数据集：tokens、devices、blocked_chats（CSV 必填，JSON 可选，多个用逗号分隔）",
+                        "name": "dataset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "导出内容",
+                        "schema": {
+                            "$ref": "#/definitions/models.DataExport"
+                        }
+                    },
+                    "401": {
+                        "description": "认证失败",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/admin/export_unsubscribes": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "导出所有有过退订记录的用户（含已重新订阅的）及每次退订和重新订阅的时间、原因和来源，用于合规审计。CSV 格式每次操作一行，unsubscribed 列为用户当前是否仍退订。结果以附件形式下载",
+                "produces": [
+                    "application/json",
+                    "text/csv"
+                ],
+                "tags": [
+                    "Admin API"
+                ],
+                "summary": "导出退订审计记录",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "导出格式：json（默认）或 csv",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "导出内容",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.PushSubscription"
+                            }
+                        }
+                    },
                     "401": {
                         "description": "认证失败",
                         "schema": {
@@ -464,156 +661,6886 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "models.BlockedChat": {
+        },
+        "/v1/admin/get_api_keys": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "列出所有 API Key（配置文件中的 api_key、api_keys 以及通过管理接口创建的 Key，只返回名称、指纹、来源和权限范围），以及各 Key 的请求数、失败率、最后使用时间和最后请求来源，便于发现长期未使用或异常调用的 Key。未匹配任何 Key 的请求统计在 unknown 中。统计为进程内数据，重启后清零。",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin API"
+                ],
+                "summary": "获取 API Key 列表及使用统计",
+                "responses": {
+                    "200": {
+                        "description": "成功响应",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/auth.APIKeyStats"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "认证失败",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/admin/get_backups": {
+            "get": {
+                "description": "列出服务端备份目录中的备份文件，按时间从旧到新排序",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin API"
+                ],
+                "summary": "获取备份列表",
+                "responses": {
+                    "200": {
+                        "description": "成功响应",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.BackupFile"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "认证失败",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/admin/get_qa_accounts": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "获取所有 QA 账号",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin API"
+                ],
+                "summary": "获取 QA 账号列表",
+                "responses": {
+                    "200": {
+                        "description": "成功响应",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.QAAccount"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "认证失败",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/admin/get_qa_inbox": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "获取 QA 账号收到的推送，用于端到端测试断言\"用户 X 会收到 Y\"。since 为 Unix 毫秒，只返回之后收到的消息",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin API"
+                ],
+                "summary": "获取 QA 账号虚拟收件箱",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "QA 账号 MetaID",
+                        "name": "metaId",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "只返回该时间（Unix 毫秒）之后收到的消息",
+                        "name": "since",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "最多返回最新的条数",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功响应",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.QAInboxMessage"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "认证失败",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/admin/get_quarantined_messages": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "按隔离时间倒序返回解析或校验失败的上游聊天消息原文及错误原因，用于排查上游消息格式变化。严格解析失败（未知字段、类型不匹配等）的消息仍按已解析的字段正常推送；结构校验失败（消息内容不是对象、缺少群聊ID或私聊对象等必填字段）的消息未推送（rejected=true），可通过 requeue_quarantined 重新入队",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin API"
+                ],
+                "summary": "获取隔离的上游消息",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "返回条数（默认100，最大1000）",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功响应",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.QuarantinedMessage"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "认证失败",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/admin/get_routing_rules": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "获取当前生效的通知路由规则及其来源（config：配置文件，api：管理接口设置）",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin API"
+                ],
+                "summary": "获取通知路由规则",
+                "responses": {
+                    "200": {
+                        "description": "成功响应，data 为 {source, rules}",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "认证失败",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/admin/get_tenant_usage": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "获取租户某月的推送用量（已发送、降级、拒绝数量以及配额使用百分比）。不传 tenantId 时返回该月所有有用量的租户。需要在配置中启用租户配额。",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin API"
+                ],
+                "summary": "获取租户月度推送用量",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "租户ID（不传则返回所有租户）",
+                        "name": "tenantId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "月份（UTC，格式 2006-01，默认当月）",
+                        "name": "month",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功响应",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.TenantUsage"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "认证失败",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/admin/get_tenant_webhooks": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "获取所有已注册的租户 Webhook 配置（secret 已脱敏）",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin API"
+                ],
+                "summary": "获取租户投递事件 Webhook 列表",
+                "responses": {
+                    "200": {
+                        "description": "成功响应",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.TenantWebhook"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "认证失败",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/admin/get_user_merges": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "按时间倒序返回用户合并审计记录，传 metaId 时只返回该 MetaID 作为源或目标的记录",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin API"
+                ],
+                "summary": "获取用户合并审计记录",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "MetaID（不传则返回全部）",
+                        "name": "metaId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "返回条数（默认50，最大500）",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功响应",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.UserMergeRecord"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "认证失败",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/admin/get_user_trace": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "获取用户的推送追踪及按时间升序的追踪事件，stage 为 rejected/skipped/sent/failed/receipt。since 为 Unix 毫秒，只返回之后发生的事件",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin API"
+                ],
+                "summary": "获取用户推送追踪事件",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "用户MetaID",
+                        "name": "metaId",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "只返回该时间（Unix 毫秒）之后的事件",
+                        "name": "since",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "最多返回最新的条数",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功响应，data 为 {trace, events}",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "认证失败",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/admin/get_user_traces": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "获取所有推送追踪（含已结束但事件尚未清理的），until 早于当前时间表示已结束",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin API"
+                ],
+                "summary": "获取推送追踪列表",
+                "responses": {
+                    "200": {
+                        "description": "成功响应",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.UserTrace"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "认证失败",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/admin/import": {
+            "post": {
+                "description": "批量导入用户令牌、设备和屏蔽聊天（如从旧推送系统或其他环境迁移）。请求体可以是 export 接口导出的 JSON，或单个数据集的 CSV（需指定 dataset），也可以通过 multipart 表单字段 file 上传。令牌按平台合并到现有用户，同一令牌会从原用户转移；屏蔽聊天合并，已过期的临时静音会被跳过",
+                "consumes": [
+                    "application/json",
+                    "text/csv",
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin API"
+                ],
+                "summary": "导入用户数据",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "导入格式：json（默认）或 csv",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "数据集（CSV 必填）：tokens、devices、blocked_chats",
+                        "name": "dataset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "file",
+                        "description": "导入文件（上传方式）",
+                        "name": "file",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功响应",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.ImportResult"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "认证失败",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/admin/jobs": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "按创建时间倒序返回后台任务（异步备份等）的状态、进度和结果，可按类型和状态过滤",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin API"
+                ],
+                "summary": "获取后台任务列表",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "任务类型（如 backup）",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "任务状态（pending/running/succeeded/failed/canceled）",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "返回条数（默认10，最大100）",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功响应",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.Job"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "认证失败",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/admin/jobs/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "按任务ID获取后台任务的状态、进度和结果",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin API"
+                ],
+                "summary": "获取后台任务",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "任务ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功响应",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.Job"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "认证失败",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/admin/jobs/{id}/cancel": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "请求取消执行中的后台任务，任务在执行到可中断的位置后结束并标记为 canceled；已结束的任务不能取消",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin API"
+                ],
+                "summary": "取消后台任务",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "任务ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功响应",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.Job"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "认证失败",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/admin/merge_users": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "身份服务将两个 MetaID 关联后调用，把源用户的推送令牌、租户、偏好、屏蔽聊天和 QA 收件箱合并到目标用户，并写入审计记录。冲突规则：同一平台的令牌和不同租户保留 prefer 指定的一方（默认 target）；偏好保留 prefer 一方，语言为空时取另一方；同一聊天的屏蔽取更严格者（永久屏蔽优先，否则取更晚的截止时间）。合并不回滚，中途失败时审计记录中会写明错误，修复后可再次合并。",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin API"
+                ],
+                "summary": "合并两个 MetaID 的推送状态",
+                "parameters": [
+                    {
+                        "description": "合并请求",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.MergeUsersReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功响应",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.UserMergeRecord"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "认证失败",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/admin/provider_stats": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "获取进程启动以来各推送提供者（expo、apns、wns、email 等）的发送成功/失败次数、内部重试次数、平均耗时、最近一次失败原因和时间、最近一次成功时间，用于判断某个推送平台是否异常。同样的数据以 push_provider_* 指标暴露在 /metrics",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin API"
+                ],
+                "summary": "获取推送提供者发送统计",
+                "responses": {
+                    "200": {
+                        "description": "成功响应",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/push_service.ProviderStats"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "认证失败",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/admin/reload_config": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "重新读取配置文件，HTTP 限流（rate_limit）和 Expo 提供者（push.providers.expo：超时、重试、批量大小等）的修改立即生效，无需重启。其他已修改的配置项在 restartRequired 中返回，需要重启才能生效。向进程发送 SIGHUP 效果相同。",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin API"
+                ],
+                "summary": "热加载配置文件",
+                "responses": {
+                    "200": {
+                        "description": "成功响应",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/conf.ReloadResult"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "认证失败",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/admin/remove_qa_account": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "取消 QA 账号标记并清空其虚拟收件箱，此后恢复正常推送",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin API"
+                ],
+                "summary": "移除 QA 账号",
+                "parameters": [
+                    {
+                        "description": "请求参数",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.RemoveQAAccountReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功响应",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "认证失败",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/admin/remove_tenant_webhook": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "删除指定租户的 Webhook 配置，删除后不再回调该租户的投递事件",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin API"
+                ],
+                "summary": "移除租户投递事件 Webhook",
+                "parameters": [
+                    {
+                        "description": "请求参数（tenantId）",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.RemoveTenantWebhookReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功响应",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "认证失败",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/admin/replay_message": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "将抓取到的原始 SocketData 载荷重新交给推送中心处理，用于复现线上的解析和推送问题。回放不做 PIN 去重、严格解析失败不写入隔离集合，同步返回解析结果、生成的通知以及每个用户的投递结果或跳过原因；dryRun 时不调用推送平台，也不记录通知状态和投递结果",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin API"
+                ],
+                "summary": "回放上游消息",
+                "parameters": [
+                    {
+                        "description": "原始载荷",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.ReplayMessageReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功响应",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/pushcenter.ReplayResult"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "认证失败",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/admin/requeue_quarantined": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "将因结构校验失败未推送的隔离消息按隔离时间顺序重新交给推送中心处理（通常在上游修复或放宽校验后调用），入队前从隔离集合删除，仍校验失败的消息会重新隔离为新条目。ids 为空时重新入队所有未推送的消息；已照常推送过的消息不会重复推送，记入 skipped",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin API"
+                ],
+                "summary": "隔离消息重新入队",
+                "parameters": [
+                    {
+                        "description": "隔离条目ID",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.RequeueQuarantinedReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功响应",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/pushcenter.RequeueResult"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "认证失败",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/admin/reset_routing_rules": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "删除通过管理接口设置的路由规则，恢复使用配置文件 routing.rules 中的规则",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin API"
+                ],
+                "summary": "恢复配置文件中的通知路由规则",
+                "responses": {
+                    "200": {
+                        "description": "成功响应",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.RoutingRule"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "认证失败",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/admin/restore": {
+            "post": {
+                "description": "从 tar.gz 备份归档恢复所有集合。可通过 multipart 表单字段 file 上传归档，或以 JSON 传入服务端备份目录中的文件名。恢复会替换整个数据目录（原目录保留为 \u003cdb_path\u003e.pre-restore-\u003c时间戳\u003e），并清空令牌缓存",
+                "consumes": [
+                    "application/json",
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin API"
+                ],
+                "summary": "从备份恢复",
+                "parameters": [
+                    {
+                        "description": "备份文件名（JSON 方式）",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/request.RestoreBackupReq"
+                        }
+                    },
+                    {
+                        "type": "file",
+                        "description": "备份归档（上传方式）",
+                        "name": "file",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功响应",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.BackupManifest"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "认证失败",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/admin/revoke_api_key": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "吊销通过管理接口创建的 API Key，立即生效；配置文件中的 Key 需从配置中移除",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin API"
+                ],
+                "summary": "吊销 API Key",
+                "parameters": [
+                    {
+                        "description": "请求参数",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.RevokeAPIKeyReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功响应",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "认证失败",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/admin/seed_test_data": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "生成指定数量的测试用户（MetaID 为 前缀+序号），每个用户登记一个 mock 平台令牌（由模拟推送提供者直接返回成功）、随机的屏蔽聊天和语言偏好，用于压测和界面演示。仅在 staging.enabled 开启时注册该接口；重复执行会覆盖同名用户。",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin API"
+                ],
+                "summary": "生成测试数据（仅预发环境）",
+                "parameters": [
+                    {
+                        "description": "请求参数（count，可选 prefix、maxBlockedChats、seed）",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.SeedTestDataReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功响应",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/storage_service.SeedResult"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "认证失败",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/admin/set_qa_account": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "将指定 MetaID 标记为 QA 账号，此后发给该用户的推送写入虚拟收件箱，不再发送到真实设备（需开启 qa.enabled）",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin API"
+                ],
+                "summary": "设置 QA 账号",
+                "parameters": [
+                    {
+                        "description": "请求参数",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.SetQAAccountReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功响应",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.QAAccount"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "认证失败",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/admin/set_routing_rules": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "整体替换通知路由规则，立即生效并保存（重启后仍生效），覆盖配置文件中的规则。规则按顺序匹配，命中第一条即止；匹配条件（messageTypes、chatInfoTypes、mention、dataFields）之间为“且”关系，未设置的条件不参与匹配；命中后按规则设置通知的 priority（high/normal）、sound、ttl（秒）、channelId（Android 通知渠道）和 providers（限定推送提供者），未设置的项保持默认。",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin API"
+                ],
+                "summary": "设置通知路由规则",
+                "parameters": [
+                    {
+                        "description": "路由规则",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.SetRoutingRulesReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功响应",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.RoutingRule"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "认证失败",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/admin/set_tenant_quota": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "设置指定租户每月的推送上限（按设备投递计数，UTC 自然月），0 表示不限制。未单独设置的租户使用配置文件中的默认上限。超出配额后按配置拒绝或降级发送。",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin API"
+                ],
+                "summary": "设置租户月度推送配额",
+                "parameters": [
+                    {
+                        "description": "请求参数（tenantId、monthlyLimit）",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.SetTenantQuotaReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功响应",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.TenantQuota"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "认证失败",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/admin/set_tenant_webhook": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "为指定租户注册投递事件回调地址，推送成功/失败时会以 POST 方式回调该地址。设置 secret 后回调会携带 X-Webhook-Signature 签名头（sha256=HMAC-SHA256(timestamp + \".\" + body)）。",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin API"
+                ],
+                "summary": "设置租户投递事件 Webhook",
+                "parameters": [
+                    {
+                        "description": "请求参数（tenantId、url，可选 secret、enabled）",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.SetTenantWebhookReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功响应",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "认证失败",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/admin/set_user_tenant": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "将用户归入指定租户，该用户的投递事件回调到租户 Webhook。tenantId 为空时将用户移出租户。租户归属只能通过此管理接口设置，登记令牌接口不接受 tenantId。",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin API"
+                ],
+                "summary": "设置用户所属租户",
+                "parameters": [
+                    {
+                        "description": "请求参数（metaId、tenantId）",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.SetUserTenantReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功响应",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "认证失败",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/admin/start_user_trace": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "在限定时间内记录该用户的每个推送决策：被接收用户校验剔除、因屏蔽聊天/暂停通知/关闭该类通知未发送、发送到哪个平台及结果、回执状态（回执需开启 delivery）。已在追踪时更新截止时间",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin API"
+                ],
+                "summary": "开始追踪用户推送",
+                "parameters": [
+                    {
+                        "description": "请求参数",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.StartUserTraceReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功响应",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.UserTrace"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "认证失败",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/admin/stats": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "获取各集合记录数、各租户 Webhook 投递统计、各上游 Socket 连接状态及按方法分类的入站消息数/字节数、最近若干天按平台的令牌注册/移除/转移统计，以及过期令牌清理统计（tokenGC，从未清理时为 null）",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin API"
+                ],
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "令牌统计天数（默认30，最大365）",
+                        "name": "days",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功响应",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "认证失败",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/admin/stop_user_trace": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "立即停止记录该用户的推送决策，已记录的事件在追踪结束 7 天后清理",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin API"
+                ],
+                "summary": "停止追踪用户推送",
+                "parameters": [
+                    {
+                        "description": "请求参数",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.StopUserTraceReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功响应",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "认证失败",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/admin/version": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "返回服务版本、Go 版本、Git 提交、构建标签，以及可选集成是否编译进当前构建。使用 noredis 构建时 redis 为 false，配置 redis 存储后端、PIN 去重或限流后端会在启动时报错；使用 nomysql 构建时 mysql 为 false",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin API"
+                ],
+                "summary": "获取服务版本和构建能力",
+                "responses": {
+                    "200": {
+                        "description": "成功响应",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.VersionInfo"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "认证失败",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/ingest/chat_message": {
+            "post": {
+                "description": "供没有 Socket.IO 的上游投递聊天消息，也可在 Socket 断开时作为备用通道。请求体与 Socket 消息相同（SocketData：{\"M\": \"WS_SERVER_NOTIFY_GROUP_CHAT\", \"D\": {...}}），处理流程与 Socket 消息一致（去重、过滤、推送、记录），推送为异步执行。请求需 HMAC 签名：X-Timestamp 为 Unix 秒，X-Signature 为 \"sha256=\" + HMAC-SHA256(ingest.secret, timestamp + \".\" + 请求体) 的十六进制，同一签名只能使用一次",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Ingest API"
+                ],
+                "summary": "HTTP 进件聊天消息",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "签名时间戳（Unix 秒）",
+                        "name": "X-Timestamp",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "HMAC 签名",
+                        "name": "X-Signature",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "SocketData 载荷",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功响应",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/pushcenter.IngestResult"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "签名校验失败",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/push/add_blocked_chat": {
+            "post": {
+                "description": "为用户添加屏蔽某个群聊或私聊。可通过 muteUntil（Unix 秒）或 muteDuration（秒）设置临时静音，到期后自动恢复推送；都不设置时为永久屏蔽。对已屏蔽的聊天再次调用会更新静音截止时间",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Push API"
+                ],
+                "summary": "添加屏蔽聊天",
+                "parameters": [
+                    {
+                        "description": "请求参数",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.AddBlockedChatReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功响应",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "认证失败",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/push/add_blocked_sender": {
+            "post": {
+                "description": "屏蔽某个发送者，屏蔽后用户不再收到该发送者在任何私聊和群聊中的消息推送；已屏蔽时更新屏蔽原因",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Push API"
+                ],
+                "summary": "屏蔽发送者",
+                "parameters": [
+                    {
+                        "description": "请求参数",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.AddBlockedSenderReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功响应",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "认证失败",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/push/batch_blocked_chats": {
+            "post": {
+                "description": "一次请求屏蔽和取消屏蔽多个聊天，用于客户端重装后同步本地屏蔽列表。block 中的每项可设置 muteUntil 或 muteDuration（同 add_blocked_chat），unblock 为要取消屏蔽的聊天ID；同一聊天同时出现在两个列表中时以取消屏蔽为准，单次最多 500 个聊天",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Push API"
+                ],
+                "summary": "批量屏蔽/取消屏蔽聊天",
+                "parameters": [
+                    {
+                        "description": "请求参数",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.BatchBlockedChatsReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功响应",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.BlockedChatsBatchResult"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "认证失败",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/push/block_all_groups": {
+            "post": {
+                "description": "开启后用户不再收到任何群聊消息推送（私聊不受影响），单个群聊的屏蔽记录保持不变",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Push API"
+                ],
+                "summary": "屏蔽所有群聊",
+                "parameters": [
+                    {
+                        "description": "请求参数",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.AllGroupsBlockReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功响应",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "认证失败",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/push/cancel_schedule": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "取消尚未发送的定时推送任务，已发送或发送中的任务无法取消",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Push API"
+                ],
+                "summary": "取消定时推送",
+                "parameters": [
+                    {
+                        "description": "请求参数（id）",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.CancelScheduledPushReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功响应",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.ScheduledPush"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "认证失败",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "任务不存在",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/push/delete_user_data": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "用于被遗忘权请求：一次删除用户在所有集合中的数据，包括推送令牌和设备、屏蔽聊天、屏蔽发送者、聊天通知声音、推送偏好、退订原因和历史（仍处于退订状态时保留只含退订标记的记录，suppressionKept 为 true，删除后不会恢复推送）、投递记录（含提及通知的投递历史）、推送审计、推送追踪、QA 收件箱、最近活跃用户列表中的记录和该用户参与的合并记录，返回各类数据的删除数量。删除不回滚，中途失败时返回错误，可再次调用（删除是幂等的）。需要 write 权限的 API Key（不受 protect_user_endpoints 影响）。",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Push API"
+                ],
+                "summary": "删除用户全部数据",
+                "parameters": [
+                    {
+                        "description": "请求参数",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.DeleteUserDataReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功响应，data 为删除报告",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.UserDataDeletionReport"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "认证失败",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "403": {
+                        "description": "权限不足",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/push/delivery_status": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "按 PinId 获取消息对每个接收用户各平台的投递状态：是否尝试推送、推送平台受理状态和最终回执（需开启 push_center.delivery.enabled）",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Push API"
+                ],
+                "summary": "获取消息投递状态",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "消息PIN ID",
+                        "name": "pinId",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功响应",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.DeliveryRecord"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "认证失败",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "没有该消息的投递记录",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/push/get_all_groups_block": {
+            "get": {
+                "description": "根据用户 metaId 获取该用户是否开启了\"屏蔽所有群聊\"及开启时间",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Push API"
+                ],
+                "summary": "获取屏蔽所有群聊设置",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "用户唯一标识",
+                        "name": "metaId",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功响应",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.AllGroupsBlock"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "认证失败",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/push/get_notification_categories": {
+            "get": {
+                "description": "返回服务端定义的通知类别及操作按钮（message：回复、标为已读、静音；mention：回复、标为已读；candy_bag：打开、静音），客户端据此向系统注册类别。传 metaId 时同时返回该用户已注册的类别。静音操作由客户端调用 /v1/push/add_blocked_chat 屏蔽通知 data 中的 groupId 或 metaId",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Push API"
+                ],
+                "summary": "获取可操作通知的类别",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "用户唯一标识",
+                        "name": "metaId",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功响应，data 为 {categories, registered}",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/push/get_scheduled_pushes": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "获取定时推送任务列表（按计划发送时间排序），可按状态过滤",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Push API"
+                ],
+                "summary": "获取定时推送列表",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "任务状态：pending、sending、sent、failed、canceled",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功响应",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.ScheduledPush"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "认证失败",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/push/get_subscription": {
+            "get": {
+                "description": "获取用户是否已退订推送及退订和重新订阅记录，从未退订时返回 unsubscribed=false",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Push API"
+                ],
+                "summary": "获取用户推送订阅状态",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "用户唯一标识",
+                        "name": "metaId",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功响应",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.PushSubscription"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/push/get_user_blocked_chats": {
+            "get": {
+                "description": "根据用户 metaId 分页获取该用户屏蔽的聊天列表，按聊天ID排序。首页不传 cursor，之后传上一页返回的 nextCursor，直到 hasNext 为 false",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Push API"
+                ],
+                "summary": "获取用户屏蔽聊天列表",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "用户唯一标识",
+                        "name": "metaId",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 100,
+                        "description": "每页大小，默认为100，最大500",
+                        "name": "pageSize",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "游标，为空表示从头开始",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功响应",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.BlockedChatsPage"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "认证失败",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/push/get_user_blocked_senders": {
+            "get": {
+                "description": "根据用户 metaId 获取该用户屏蔽的所有发送者，按屏蔽时间排序",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Push API"
+                ],
+                "summary": "获取用户屏蔽的发送者列表",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "用户唯一标识",
+                        "name": "metaId",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功响应",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.UserBlockedSenders"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "认证失败",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/push/get_user_chat_sounds": {
+            "get": {
+                "description": "根据用户 metaId 获取该用户为各个聊天设置的通知声音，未设置的聊天使用默认声音",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Push API"
+                ],
+                "summary": "获取用户聊天通知声音列表",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "用户唯一标识",
+                        "name": "metaId",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功响应",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.UserChatSounds"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "认证失败",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/push/get_user_preferences": {
+            "get": {
+                "description": "根据用户 metaId 获取推送偏好，未设置时返回默认值（不翻译预览）",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Push API"
+                ],
+                "summary": "获取用户推送偏好",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "用户唯一标识",
+                        "name": "metaId",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功响应",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.UserPreferences"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/push/get_user_token": {
+            "get": {
+                "description": "根据用户 metaId 获取该用户的所有推送令牌",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Push API"
+                ],
+                "summary": "根据 metaId 获取用户推送令牌",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "用户唯一标识",
+                        "name": "metaId",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功响应",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.UserPushTokens"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "认证失败",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "用户没有登记推送令牌",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/push/get_user_tokens_list": {
+            "get": {
+                "description": "分页获取所有用户的推送令牌列表，按 metaId 排序。传 cursor 参数时使用游标分页：首页传空 cursor，之后传上一页返回的 nextCursor，直到 hasNext 为 false；游标分页不统计总数，适合用户量大时遍历。页码分页的响应同样返回 nextCursor，可随时切换为游标分页。\n传任一筛选参数（platform、metaIdPrefix、updatedAfter、updatedBefore）时使用游标分页，多个条件同时满足；带更新时间条件时按更新时间排序。翻页时需保持筛选参数不变",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Push API"
+                ],
+                "summary": "获取用户推送令牌列表（分页）",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "页码，默认为1（游标分页时忽略）",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "每页大小，默认为10，最大100",
+                        "name": "pageSize",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "游标，传空字符串表示从头开始",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "只返回拥有该平台令牌的用户，如 expo、fcm",
+                        "name": "platform",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "metaId 前缀",
+                        "name": "metaIdPrefix",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "更新时间晚于（Unix 秒）",
+                        "name": "updatedAfter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "更新时间早于（Unix 秒）",
+                        "name": "updatedBefore",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功响应",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/pebble_service.PaginatedUserTokens"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "认证失败",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/push/pause_notifications": {
+            "post": {
+                "description": "暂停用户的聊天通知直到指定时间后自动恢复（区别于永久屏蔽聊天）。duration 可为 1h、8h 等时间间隔或 tomorrow（次日早上 8 点，按 utcOffsetMinutes 换算用户时区），也可直接传 until（Unix 秒）。暂停期间的消息不推送，开启 summary 时恢复后发送\"错过 N 条消息\"的汇总通知",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Push API"
+                ],
+                "summary": "暂停用户通知",
+                "parameters": [
+                    {
+                        "description": "请求参数",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.PauseNotificationsReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功响应",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.UserPreferences"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/push/remove_blocked_chat": {
+            "post": {
+                "description": "移除用户对某个群聊或私聊的屏蔽",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Push API"
+                ],
+                "summary": "移除屏蔽聊天",
+                "parameters": [
+                    {
+                        "description": "请求参数",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.RemoveBlockedChatReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功响应",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "认证失败",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/push/remove_blocked_sender": {
+            "post": {
+                "description": "取消用户对某个发送者的屏蔽",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Push API"
+                ],
+                "summary": "取消屏蔽发送者",
+                "parameters": [
+                    {
+                        "description": "请求参数",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.RemoveBlockedSenderReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功响应",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "认证失败",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/push/remove_user_all_tokens": {
+            "post": {
+                "description": "移除指定用户的所有推送令牌",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Push API"
+                ],
+                "summary": "移除用户所有推送令牌",
+                "parameters": [
+                    {
+                        "description": "请求参数",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.RemoveUserAllTokensReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功响应",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "认证失败",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/push/remove_user_token": {
+            "post": {
+                "description": "移除指定用户在指定平台的推送令牌",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Push API"
+                ],
+                "summary": "移除用户推送令牌",
+                "parameters": [
+                    {
+                        "description": "请求参数",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.RemoveUserTokenReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功响应",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "认证失败",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/push/resubscribe": {
+            "post": {
+                "description": "取消退订，恢复向用户推送；之前的退订记录保留在审计记录中",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Push API"
+                ],
+                "summary": "重新订阅推送",
+                "parameters": [
+                    {
+                        "description": "请求参数",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.ResubscribeReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功响应",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.PushSubscription"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/push/resume_notifications": {
+            "post": {
+                "description": "取消通知暂停；手动恢复时不发送错过消息的汇总通知",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Push API"
+                ],
+                "summary": "立即恢复用户通知",
+                "parameters": [
+                    {
+                        "description": "请求参数",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.ResumeNotificationsReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功响应，data 为 {resumed, missed}",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/push/schedule": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "创建一个在 sendAt（Unix 秒）时刻发送的推送任务，返回任务ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Push API"
+                ],
+                "summary": "创建定时推送",
+                "parameters": [
+                    {
+                        "description": "请求参数（metaIds、title、body、sendAt，可选 data、sound、priority）",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.SchedulePushReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功响应",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.ScheduledPush"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "认证失败",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/push/schedule_local_time": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "公告类推送按接收用户的当地时间发送：按用户设备上报的时区（登记令牌时的 timezone，多台设备取最近活跃的）将用户分组，每组在当地下一次到达 localHour 点整时发送，而不是同一时刻发给所有人。\n未上报时区的用户使用 defaultTimezone（默认 UTC）。同一时刻到达该整点的时区合并为一个定时推送任务，各任务共用 batchId，可通过 get_scheduled_pushes 查看、cancel_schedule 逐个取消",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Push API"
+                ],
+                "summary": "创建按当地时间发送的定时推送",
+                "parameters": [
+                    {
+                        "description": "请求参数（metaIds、title、body、localHour，可选 defaultTimezone、data、sound、priority）",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.SchedulePushLocalTimeReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功响应",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.LocalTimeSchedule"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "认证失败",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/push/send": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "向指定用户列表发送推送通知。支持幂等键（请求体 idempotencyKey 或请求头 Idempotency-Key），相同幂等键在保留期内重复请求不会重复推送，而是返回首次推送的结果。dryRun 为 true 时完整执行推送流程但不调用推送平台，结果记为模拟，且不占用幂等键。",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Push API"
+                ],
+                "summary": "发送推送通知",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "幂等键",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "请求参数（metaIds、title、body，可选 data、sound、priority、idempotencyKey、dryRun）",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.SendPushReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功响应",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "认证失败",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "502": {
+                        "description": "所有推送平台调用都失败",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "503": {
+                        "description": "推送中心未启用",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/push/send_data": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "向指定用户列表发送不展示通知的后台数据推送（iOS content-available），用于触发客户端后台同步。默认普通优先级（iOS 后台推送要求），不走邮件等兜底渠道。支持与 /v1/push/send 相同的幂等键和 dryRun 演练模式。",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Push API"
+                ],
+                "summary": "发送静默数据推送",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "幂等键",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "请求参数（metaIds、data，可选 priority、ttl、idempotencyKey、dryRun）",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.SendDataPushReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功响应",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "认证失败",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "502": {
+                        "description": "所有推送平台调用都失败",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "503": {
+                        "description": "推送中心未启用",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/push/set_chat_sound": {
+            "post": {
+                "description": "为用户的某个群聊或私聊设置自定义通知声音。sound 为客户端内置的声音文件名（字母、数字、下划线、点和连字符，最长 64 个字符），silent 表示该聊天的通知不播放声音，为空时恢复默认声音。屏蔽的聊天不会推送，声音设置不影响屏蔽",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Push API"
+                ],
+                "summary": "设置聊天通知声音",
+                "parameters": [
+                    {
+                        "description": "请求参数",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.SetChatSoundReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功响应",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "认证失败",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/push/set_notification_categories": {
+            "post": {
+                "description": "客户端向系统注册通知类别后调用，服务端开启 notification.actions 时只为已注册类别的通知设置 categoryId（iOS aps.category / Expo categoryId），用户可直接在通知上回复或静音。整体替换已注册的类别，传空数组表示不再接收带操作的通知",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Push API"
+                ],
+                "summary": "注册客户端可处理的通知类别",
+                "parameters": [
+                    {
+                        "description": "请求参数",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.SetNotificationCategoriesReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功响应",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.UserPreferences"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/push/set_user_preferences": {
+            "post": {
+                "description": "设置用户语言区域及是否翻译消息预览，muteCandyBags、muteFriendRequests、mutePayments 可分别关闭红包、好友请求和付款通知。开启 translatePreviews 后，推送中的消息预览会被翻译为 locale 指定的语言（需服务端启用 notification.preview_enabled 和 translation.enabled）",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Push API"
+                ],
+                "summary": "设置用户推送偏好",
+                "parameters": [
+                    {
+                        "description": "请求参数",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.SetUserPreferencesReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功响应",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.UserPreferences"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/push/set_user_tokens": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "为指定用户在指定平台设置推送令牌，支持Token唯一性检查。platform 可为 expo、email，桌面客户端使用 macos（APNs 设备令牌）或 windows（WNS 通道 URI）。Token本身就是设备的唯一标识，如果Token已被其他用户使用，会自动从原用户中移除该平台的令牌。用户所属租户不能通过此接口设置，由管理接口 set_user_tenant 指定。\n可同时上报设备信息（appVersion、osVersion、deviceModel、locale、timezone），每次调用都会更新设备的 lastSeenAt；用户未设置语言偏好时，预览翻译使用最近活跃设备的 locale。",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Push API"
+                ],
+                "summary": "设置用户推送令牌",
+                "parameters": [
+                    {
+                        "description": "请求参数（metaId、platform、token，可选设备信息）",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.SetUserTokensReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功响应",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "认证失败",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/push/test_push": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "向指定用户（metaId，发送到该用户登记的所有平台）或指定令牌（token）发送一条测试通知，用于客服和测试排查设备推送配置。直接调用推送平台，不经过去重、屏蔽、限流和配额，也不计入投递记录和审计日志。同步返回每个平台的受理结果（ticket）；wait 大于 0 时在该秒数内轮询投递回执（receipt），回执状态为 delivered / failed / pending（未等到）/ unavailable（平台不支持回执或未受理）。",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Push API"
+                ],
+                "summary": "发送测试推送",
+                "parameters": [
+                    {
+                        "description": "请求参数（metaId 或 token，可选 platform、title、body、wait）",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.TestPushReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功响应",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "认证失败",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "用户没有登记推送令牌",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "503": {
+                        "description": "推送中心未启用",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/push/unblock_all_groups": {
+            "post": {
+                "description": "关闭\"屏蔽所有群聊\"，恢复群聊消息推送（单独屏蔽的群聊仍然屏蔽）",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Push API"
+                ],
+                "summary": "取消屏蔽所有群聊",
+                "parameters": [
+                    {
+                        "description": "请求参数",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.AllGroupsBlockReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功响应",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "认证失败",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/push/unsubscribe": {
+            "post": {
+                "description": "用户退订后，聊天通知、send/send_data、定时推送和测试推送等所有发送接口都不再向该用户推送（投递记录的跳过原因为 unsubscribed），直到调用 resubscribe。每次退订和重新订阅都记入审计记录，可通过 /v1/admin/export_unsubscribes 导出",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Push API"
+                ],
+                "summary": "退订全部推送",
+                "parameters": [
+                    {
+                        "description": "请求参数",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.UnsubscribeReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功响应",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/models.PushSubscription"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/push/user_push_history": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "按时间倒序获取发给该用户的外发通知审计记录：标题、内容哈希、推送平台、推送结果、关联 PinId 和推送时间，用于排查用户未收到推送的原因（需开启 push_center.audit.enabled）。翻页时以上一页最后一条的 timestamp 作为 before",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Push API"
+                ],
+                "summary": "获取用户推送历史",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "用户MetaID",
+                        "name": "metaId",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "只返回推送时间早于该值的记录（Unix 毫秒）",
+                        "name": "before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "返回条数，默认且最多 500",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功响应",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/respond.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/models.PushAuditEntry"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "401": {
+                        "description": "认证失败",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/respond.Response"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "auth.APIKeyStats": {
+            "type": "object",
+            "properties": {
+                "errorRate": {
+                    "description": "失败率",
+                    "type": "number"
+                },
+                "errors": {
+                    "description": "失败请求数（HTTP 状态码 \u003e= 400 或响应 code 非 0）",
+                    "type": "integer"
+                },
+                "fingerprint": {
+                    "description": "Key 的 SHA-256 前 8 位，用于核对而不暴露 Key",
+                    "type": "string"
+                },
+                "lastClientIp": {
+                    "description": "最后请求的客户端 IP",
+                    "type": "string"
+                },
+                "lastPath": {
+                    "description": "最后请求的路径",
+                    "type": "string"
+                },
+                "lastUsedAt": {
+                    "description": "最后使用时间（Unix 秒），0 表示启动后未使用",
+                    "type": "integer"
+                },
+                "name": {
+                    "description": "Key 名称",
+                    "type": "string"
+                },
+                "requests": {
+                    "description": "请求总数",
+                    "type": "integer"
+                },
+                "scopes": {
+                    "description": "权限范围",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "source": {
+                    "description": "Key 来源：config（配置文件）或 managed（管理接口创建）",
+                    "type": "string"
+                },
+                "tenant": {
+                    "description": "调用方所属租户",
+                    "type": "string"
+                }
+            }
+        },
+        "conf.ReloadResult": {
+            "type": "object",
+            "properties": {
+                "applied": {
+                    "description": "已生效的配置项",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "restartRequired": {
+                    "description": "已修改但需要重启才能生效的配置项",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.AllGroupsBlock": {
+            "type": "object",
+            "properties": {
+                "blocked": {
+                    "description": "是否屏蔽所有群聊",
+                    "type": "boolean"
+                },
+                "blockedAt": {
+                    "description": "开启时间",
+                    "type": "integer"
+                },
+                "userId": {
+                    "description": "用户ID",
+                    "type": "string"
+                }
+            }
+        },
+        "models.BackupFile": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "description": "创建时间",
+                    "type": "integer"
+                },
+                "name": {
+                    "description": "文件名",
+                    "type": "string"
+                },
+                "size": {
+                    "description": "文件大小（字节）",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.BackupManifest": {
+            "type": "object",
+            "properties": {
+                "collections": {
+                    "description": "包含的集合",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "createdAt": {
+                    "description": "备份时间",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.BlockedChat": {
+            "type": "object",
+            "required": [
+                "chatId",
+                "userId"
+            ],
+            "properties": {
+                "blockedAt": {
+                    "description": "屏蔽时间",
+                    "type": "integer"
+                },
+                "chatId": {
+                    "description": "群ID或私聊ID",
+                    "type": "string"
+                },
+                "chatType": {
+                    "description": "聊天类型 (group, private)",
+                    "type": "string"
+                },
+                "muteUntil": {
+                    "description": "静音截止时间 (Unix 秒)，0 表示永久屏蔽",
+                    "type": "integer"
+                },
+                "reason": {
+                    "description": "屏蔽原因",
+                    "type": "string"
+                },
+                "userId": {
+                    "description": "用户ID",
+                    "type": "string"
+                }
+            }
+        },
+        "models.BlockedChatsBatchResult": {
+            "type": "object",
+            "properties": {
+                "blocked": {
+                    "description": "屏蔽（含更新截止时间）的聊天数",
+                    "type": "integer"
+                },
+                "unblocked": {
+                    "description": "取消屏蔽的聊天数",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.BlockedChatsPage": {
+            "type": "object",
+            "properties": {
+                "blockedChats": {
+                    "description": "当前页的屏蔽聊天",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.BlockedChat"
+                    }
+                },
+                "hasNext": {
+                    "description": "是否有下一页",
+                    "type": "boolean"
+                },
+                "nextCursor": {
+                    "description": "下一页游标（有下一页时返回）",
+                    "type": "string"
+                },
+                "pageSize": {
+                    "description": "每页大小",
+                    "type": "integer"
+                },
+                "userId": {
+                    "description": "用户ID",
+                    "type": "string"
+                }
+            }
+        },
+        "models.BlockedSender": {
+            "type": "object",
+            "properties": {
+                "blockedAt": {
+                    "description": "屏蔽时间",
+                    "type": "integer"
+                },
+                "reason": {
+                    "description": "屏蔽原因",
+                    "type": "string"
+                },
+                "senderId": {
+                    "description": "被屏蔽的发送者MetaID",
+                    "type": "string"
+                },
+                "userId": {
+                    "description": "用户ID",
+                    "type": "string"
+                }
+            }
+        },
+        "models.ChatSound": {
+            "type": "object",
+            "properties": {
+                "chatId": {
+                    "description": "群ID或私聊ID",
+                    "type": "string"
+                },
+                "chatType": {
+                    "description": "聊天类型 (group, private)",
+                    "type": "string"
+                },
+                "sound": {
+                    "description": "客户端内置的声音文件名，silent 表示静音",
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "description": "设置时间",
+                    "type": "integer"
+                },
+                "userId": {
+                    "description": "用户ID",
+                    "type": "string"
+                }
+            }
+        },
+        "models.DataExport": {
+            "type": "object",
+            "properties": {
+                "blockedChats": {
+                    "description": "用户屏蔽聊天",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.UserBlockedChats"
+                    }
+                },
+                "devices": {
+                    "description": "设备信息",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.DeviceInfo"
+                    }
+                },
+                "exportedAt": {
+                    "description": "导出时间",
+                    "type": "integer"
+                },
+                "userTokens": {
+                    "description": "用户令牌",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.UserPushTokens"
+                    }
+                }
+            }
+        },
+        "models.DeliveryBreakdown": {
+            "type": "object",
+            "properties": {
+                "appVersion": {
+                    "description": "客户端版本，未上报时为 unknown",
+                    "type": "string"
+                },
+                "delivered": {
+                    "description": "回执为已送达的数量",
+                    "type": "integer"
+                },
+                "deliveryRate": {
+                    "description": "送达率（0-1），没有已确定结果时为 0",
+                    "type": "number"
+                },
+                "failed": {
+                    "description": "回执为送达失败的数量",
+                    "type": "integer"
+                },
+                "os": {
+                    "description": "系统及主版本，如 iOS 17，未上报时为 unknown",
+                    "type": "string"
+                },
+                "pending": {
+                    "description": "等待查询回执的数量",
+                    "type": "integer"
+                },
+                "platform": {
+                    "description": "推送平台",
+                    "type": "string"
+                },
+                "sent": {
+                    "description": "尝试推送数",
+                    "type": "integer"
+                },
+                "ticketErrors": {
+                    "description": "推送平台拒绝或发送失败数",
+                    "type": "integer"
+                },
+                "unavailable": {
+                    "description": "回执已过期或推送平台不支持查询的数量",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.DeliveryRecord": {
+            "type": "object",
+            "properties": {
+                "appVersion": {
+                    "description": "接收设备推送时的客户端版本",
+                    "type": "string"
+                },
+                "attempted": {
+                    "description": "是否尝试推送",
+                    "type": "boolean"
+                },
+                "createdAt": {
+                    "description": "推送时间",
+                    "type": "integer"
+                },
+                "error": {
+                    "description": "失败原因",
+                    "type": "string"
+                },
+                "metaId": {
+                    "description": "接收用户",
+                    "type": "string"
+                },
+                "osVersion": {
+                    "description": "接收设备推送时的系统版本",
+                    "type": "string"
+                },
+                "pinId": {
+                    "description": "消息PIN ID",
+                    "type": "string"
+                },
+                "platform": {
+                    "description": "推送平台，未发送时为空",
+                    "type": "string"
+                },
+                "receiptId": {
+                    "description": "回执ID",
+                    "type": "string"
+                },
+                "receiptStatus": {
+                    "description": "最终回执状态",
+                    "type": "string"
+                },
+                "skipReason": {
+                    "description": "未发送的原因",
+                    "type": "string"
+                },
+                "ticketStatus": {
+                    "description": "推送平台受理状态",
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "description": "最后更新时间",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.DeviceInfo": {
+            "type": "object",
+            "required": [
+                "deviceId",
+                "metaId",
+                "platform"
+            ],
+            "properties": {
+                "appVersion": {
+                    "description": "客户端版本，如 1.4.2",
+                    "type": "string"
+                },
+                "deviceId": {
+                    "description": "设备唯一标识",
+                    "type": "string"
+                },
+                "deviceModel": {
+                    "description": "设备型号，如 iPhone15,2",
+                    "type": "string"
+                },
+                "lastSeenAt": {
+                    "description": "最后一次注册或刷新令牌的时间",
+                    "type": "integer"
+                },
+                "locale": {
+                    "description": "设备语言区域，如 zh-CN，用户未设置语言偏好时用于预览翻译",
+                    "type": "string"
+                },
+                "metaId": {
+                    "description": "关联的用户ID",
+                    "type": "string"
+                },
+                "osVersion": {
+                    "description": "系统版本，如 iOS 17.5",
+                    "type": "string"
+                },
+                "platform": {
+                    "description": "平台 (expo, fcm, apns)",
+                    "type": "string"
+                },
+                "timezone": {
+                    "description": "设备时区（IANA 名称），如 Asia/Shanghai",
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "description": "最后更新时间",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ImportResult": {
+            "type": "object",
+            "properties": {
+                "blockedChats": {
+                    "description": "导入的屏蔽聊天数",
+                    "type": "integer"
+                },
+                "devices": {
+                    "description": "导入的设备数",
+                    "type": "integer"
+                },
+                "errors": {
+                    "description": "导入失败的记录（最多保留 100 条）",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "skipped": {
+                    "description": "跳过的记录数（无效或已过期）",
+                    "type": "integer"
+                },
+                "tokens": {
+                    "description": "导入的令牌数（按平台计）",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Job": {
+            "type": "object",
+            "properties": {
+                "cancelRequested": {
+                    "description": "是否已请求取消",
+                    "type": "boolean"
+                },
+                "createdAt": {
+                    "description": "创建时间",
+                    "type": "integer"
+                },
+                "error": {
+                    "description": "失败原因",
+                    "type": "string"
+                },
+                "finishedAt": {
+                    "description": "结束时间",
+                    "type": "integer"
+                },
+                "id": {
+                    "description": "任务唯一标识（按创建时间有序）",
+                    "type": "string"
+                },
+                "message": {
+                    "description": "当前进度说明",
+                    "type": "string"
+                },
+                "progress": {
+                    "description": "执行进度（0-100）",
+                    "type": "integer"
+                },
+                "result": {
+                    "description": "执行结果"
+                },
+                "startedAt": {
+                    "description": "开始执行时间",
+                    "type": "integer"
+                },
+                "status": {
+                    "description": "任务状态",
+                    "type": "string"
+                },
+                "type": {
+                    "description": "任务类型",
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "description": "最后更新时间",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.LocalTimeSchedule": {
+            "type": "object",
+            "properties": {
+                "batchId": {
+                    "description": "批次ID",
+                    "type": "string"
+                },
+                "jobs": {
+                    "description": "拆分出的定时推送任务，按计划发送时间排序",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ScheduledPush"
+                    }
+                },
+                "localHour": {
+                    "description": "当地发送时间（0-23 点）",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.MergeConflict": {
+            "type": "object",
+            "properties": {
+                "detail": {
+                    "description": "说明（不包含令牌原文）",
+                    "type": "string"
+                },
+                "kept": {
+                    "description": "保留的一方：target / source / merged",
+                    "type": "string"
+                },
+                "key": {
+                    "description": "冲突项，如平台名、聊天ID",
+                    "type": "string"
+                },
+                "type": {
+                    "description": "冲突类型",
+                    "type": "string"
+                }
+            }
+        },
+        "models.PipelineEvent": {
+            "type": "object",
+            "properties": {
+                "chatType": {
+                    "description": "聊天类型：private_chat 或 group_chat",
+                    "type": "string"
+                },
+                "count": {
+                    "description": "接收用户数（message_received）",
+                    "type": "integer"
+                },
+                "error": {
+                    "description": "失败原因",
+                    "type": "string"
+                },
+                "messageType": {
+                    "description": "消息类型",
+                    "type": "string"
+                },
+                "metaId": {
+                    "description": "用户MetaID",
+                    "type": "string"
+                },
+                "pinId": {
+                    "description": "消息PIN ID",
+                    "type": "string"
+                },
+                "platform": {
+                    "description": "推送平台",
+                    "type": "string"
+                },
+                "reason": {
+                    "description": "跳过原因或回执状态",
+                    "type": "string"
+                },
+                "simulated": {
+                    "description": "演练模式下的模拟结果",
+                    "type": "boolean"
+                },
+                "timestamp": {
+                    "description": "发生时间 (Unix 毫秒)",
+                    "type": "integer"
+                },
+                "type": {
+                    "description": "事件类型",
+                    "type": "string"
+                }
+            }
+        },
+        "models.PushAuditEntry": {
+            "type": "object",
+            "properties": {
+                "bodyHash": {
+                    "description": "通知内容的 SHA-256（十六进制），静默推送为空",
+                    "type": "string"
+                },
+                "error": {
+                    "description": "失败原因",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "记录ID（按时间递增）",
+                    "type": "string"
+                },
+                "metaId": {
+                    "description": "接收用户",
+                    "type": "string"
+                },
+                "pinId": {
+                    "description": "关联的消息PIN ID",
+                    "type": "string"
+                },
+                "provider": {
+                    "description": "推送平台",
+                    "type": "string"
+                },
+                "receiptId": {
+                    "description": "回执ID",
+                    "type": "string"
+                },
+                "simulated": {
+                    "description": "演练模式下的模拟推送",
+                    "type": "boolean"
+                },
+                "success": {
+                    "description": "推送平台是否受理",
+                    "type": "boolean"
+                },
+                "tenantId": {
+                    "description": "所属租户ID",
+                    "type": "string"
+                },
+                "timestamp": {
+                    "description": "推送时间 (Unix 毫秒)",
+                    "type": "integer"
+                },
+                "title": {
+                    "description": "通知标题",
+                    "type": "string"
+                }
+            }
+        },
+        "models.PushSubscription": {
+            "type": "object",
+            "properties": {
+                "history": {
+                    "description": "退订和重新订阅记录",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.SubscriptionEvent"
+                    }
+                },
+                "metaId": {
+                    "description": "用户MetaID",
+                    "type": "string"
+                },
+                "reason": {
+                    "description": "最近一次退订的原因",
+                    "type": "string"
+                },
+                "unsubscribed": {
+                    "description": "是否已退订",
+                    "type": "boolean"
+                },
+                "unsubscribedAt": {
+                    "description": "最近一次退订时间，重新订阅后保留",
+                    "type": "integer"
+                },
+                "updatedAt": {
+                    "description": "最后更新时间",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.QAAccount": {
+            "type": "object",
+            "required": [
+                "metaId"
+            ],
+            "properties": {
+                "createdAt": {
+                    "description": "创建时间",
+                    "type": "integer"
+                },
+                "metaId": {
+                    "description": "用户MetaID",
+                    "type": "string"
+                },
+                "note": {
+                    "description": "备注（如所属测试用例）",
+                    "type": "string"
+                }
+            }
+        },
+        "models.QAInboxMessage": {
+            "type": "object",
+            "properties": {
+                "badge": {
+                    "description": "徽章数字",
+                    "type": "integer"
+                },
+                "body": {
+                    "description": "通知内容",
+                    "type": "string"
+                },
+                "data": {
+                    "description": "自定义数据",
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "id": {
+                    "description": "消息ID（按接收时间递增）",
+                    "type": "string"
+                },
+                "imageUrl": {
+                    "description": "图片URL",
+                    "type": "string"
+                },
+                "metaId": {
+                    "description": "接收用户MetaID",
+                    "type": "string"
+                },
+                "priority": {
+                    "description": "优先级",
+                    "type": "string"
+                },
+                "receivedAt": {
+                    "description": "接收时间 (Unix 毫秒)",
+                    "type": "integer"
+                },
+                "sound": {
+                    "description": "声音",
+                    "type": "string"
+                },
+                "title": {
+                    "description": "通知标题",
+                    "type": "string"
+                }
+            }
+        },
+        "models.QuarantinedMessage": {
+            "type": "object",
+            "properties": {
+                "chatType": {
+                    "description": "聊天类型",
+                    "type": "string"
+                },
+                "data": {
+                    "description": "完整的消息数据（ExtraServiceMessage 的 JSON，含接收用户），仅未推送的消息保存，用于重新入队",
+                    "type": "object"
+                },
+                "error": {
+                    "description": "解析或校验错误",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "条目ID（按隔离时间排序）",
+                    "type": "string"
+                },
+                "message": {
+                    "description": "原始消息内容（ExtraServiceMessage.Message 的 JSON）",
+                    "type": "object"
+                },
+                "quarantinedAt": {
+                    "description": "隔离时间 (Unix 毫秒)",
+                    "type": "integer"
+                },
+                "rejected": {
+                    "description": "是否因结构校验失败未推送",
+                    "type": "boolean"
+                }
+            }
+        },
+        "models.RoutingRule": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "channelId": {
+                    "description": "Android 通知渠道ID",
+                    "type": "string"
+                },
+                "chatInfoTypes": {
+                    "description": "聊天信息类型，如 1/23 红包，空表示不限",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "dataFields": {
+                    "description": "通知自定义数据字段（支持 message.contentType 形式的嵌套路径），值按字符串比较",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "mention": {
+                    "description": "是否为提及通知，不设置表示不限",
+                    "type": "boolean"
+                },
+                "messageTypes": {
+                    "description": "匹配条件",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "name": {
+                    "description": "规则名称（唯一）",
+                    "type": "string"
+                },
+                "priority": {
+                    "description": "路由结果，未设置的项保持默认值",
+                    "type": "string"
+                },
+                "providers": {
+                    "description": "限定发送的推送提供者，如 [\"expo\"]",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "sound": {
+                    "description": "声音",
+                    "type": "string"
+                },
+                "ttl": {
+                    "description": "通知有效期（秒）",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ScheduledPush": {
+            "type": "object",
+            "required": [
+                "id",
+                "metaIds"
+            ],
+            "properties": {
+                "batchId": {
+                    "description": "按当地时间发送时，同一请求拆分出的各任务共用的批次ID",
+                    "type": "string"
+                },
+                "body": {
+                    "description": "通知内容",
+                    "type": "string"
+                },
+                "createdAt": {
+                    "description": "创建时间",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "自定义数据",
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "error": {
+                    "description": "失败原因",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "任务唯一标识",
+                    "type": "string"
+                },
+                "metaIds": {
+                    "description": "接收用户列表",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "priority": {
+                    "description": "优先级 (normal/high)",
+                    "type": "string"
+                },
+                "result": {
+                    "description": "发送结果摘要",
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "sendAt": {
+                    "description": "计划发送时间（Unix 秒）",
+                    "type": "integer"
+                },
+                "sentAt": {
+                    "description": "实际发送时间",
+                    "type": "integer"
+                },
+                "sound": {
+                    "description": "声音",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "任务状态",
+                    "type": "string"
+                },
+                "tenantId": {
+                    "description": "创建任务的 API Key 绑定的租户，发送时按该租户计入配额",
+                    "type": "string"
+                },
+                "timezones": {
+                    "description": "按当地时间发送时，该任务覆盖的时区",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "title": {
+                    "description": "通知标题",
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "description": "最后更新时间",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.StaleToken": {
+            "type": "object",
+            "properties": {
+                "staleAt": {
+                    "description": "标记为过期的时间",
+                    "type": "integer"
+                },
+                "token": {
+                    "description": "令牌",
+                    "type": "string"
+                }
+            }
+        },
+        "models.SubscriptionEvent": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "description": "操作类型：unsubscribe / resubscribe",
+                    "type": "string"
+                },
+                "at": {
+                    "description": "操作时间",
+                    "type": "integer"
+                },
+                "reason": {
+                    "description": "退订原因",
+                    "type": "string"
+                },
+                "source": {
+                    "description": "操作来源：user（用户本人）或 api_key:\u003cKey 名称\u003e",
+                    "type": "string"
+                }
+            }
+        },
+        "models.TenantQuota": {
+            "type": "object",
+            "required": [
+                "tenantId"
+            ],
+            "properties": {
+                "createdAt": {
+                    "description": "创建时间",
+                    "type": "integer"
+                },
+                "monthlyLimit": {
+                    "description": "每月推送上限（按设备投递计数），0 表示不限制",
+                    "type": "integer"
+                },
+                "tenantId": {
+                    "description": "租户ID",
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "description": "最后更新时间",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.TenantUsage": {
+            "type": "object",
+            "properties": {
+                "downgraded": {
+                    "description": "超出配额后降级发送的投递数",
+                    "type": "integer"
+                },
+                "limit": {
+                    "description": "当前生效的每月上限，0 表示不限制",
+                    "type": "integer"
+                },
+                "month": {
+                    "description": "月份（UTC），如 2026-01",
+                    "type": "string"
+                },
+                "rejected": {
+                    "description": "超出配额被拒绝的投递数",
+                    "type": "integer"
+                },
+                "sent": {
+                    "description": "已计入配额的投递数（含降级发送）",
+                    "type": "integer"
+                },
+                "tenantId": {
+                    "description": "租户ID",
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "description": "最后更新时间",
+                    "type": "integer"
+                },
+                "usagePercent": {
+                    "description": "已用百分比，不限制时为 0",
+                    "type": "number"
+                },
+                "warnedPercent": {
+                    "description": "本月已发出的最高告警阈值",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.TenantWebhook": {
+            "type": "object",
+            "required": [
+                "tenantId",
+                "url"
+            ],
+            "properties": {
+                "createdAt": {
+                    "description": "创建时间",
+                    "type": "integer"
+                },
+                "enabled": {
+                    "description": "是否启用",
+                    "type": "boolean"
+                },
+                "secret": {
+                    "description": "签名密钥（HMAC-SHA256）",
+                    "type": "string"
+                },
+                "tenantId": {
+                    "description": "租户ID",
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "description": "最后更新时间",
+                    "type": "integer"
+                },
+                "url": {
+                    "description": "回调地址",
+                    "type": "string"
+                }
+            }
+        },
+        "models.UserBlockedChats": {
+            "type": "object",
+            "required": [
+                "userId"
+            ],
+            "properties": {
+                "blockedChats": {
+                    "description": "屏蔽的聊天列表",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.BlockedChat"
+                    }
+                },
+                "updatedAt": {
+                    "description": "最后更新时间",
+                    "type": "integer"
+                },
+                "userId": {
+                    "description": "用户ID",
+                    "type": "string"
+                }
+            }
+        },
+        "models.UserBlockedSenders": {
+            "type": "object",
+            "properties": {
+                "blockedSenders": {
+                    "description": "按屏蔽时间排序的发送者",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.BlockedSender"
+                    }
+                },
+                "userId": {
+                    "description": "用户ID",
+                    "type": "string"
+                }
+            }
+        },
+        "models.UserChatSounds": {
+            "type": "object",
+            "properties": {
+                "chatSounds": {
+                    "description": "按设置时间排序的聊天声音",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ChatSound"
+                    }
+                },
+                "userId": {
+                    "description": "用户ID",
+                    "type": "string"
+                }
+            }
+        },
+        "models.UserDataDeletionReport": {
+            "type": "object",
+            "properties": {
+                "allGroupsBlock": {
+                    "description": "是否删除了屏蔽所有群聊设置",
+                    "type": "boolean"
+                },
+                "auditEntries": {
+                    "description": "删除的推送审计记录数",
+                    "type": "integer"
+                },
+                "blockedChats": {
+                    "description": "删除的屏蔽聊天数",
+                    "type": "integer"
+                },
+                "blockedSenders": {
+                    "description": "删除的屏蔽发送者数",
+                    "type": "integer"
+                },
+                "chatSounds": {
+                    "description": "删除的聊天通知声音数",
+                    "type": "integer"
+                },
+                "deletedAt": {
+                    "description": "删除时间",
+                    "type": "integer"
+                },
+                "deliveryRecords": {
+                    "description": "删除的投递记录数（含提及通知的投递历史）",
+                    "type": "integer"
+                },
+                "devices": {
+                    "description": "删除的设备数",
+                    "type": "integer"
+                },
+                "error": {
+                    "description": "中途失败时的错误，已完成的步骤不会回滚",
+                    "type": "string"
+                },
+                "hotUser": {
+                    "description": "是否从最近活跃用户列表中移除",
+                    "type": "boolean"
+                },
+                "inboxMessages": {
+                    "description": "删除的 QA 收件箱消息数",
+                    "type": "integer"
+                },
+                "mergeRecords": {
+                    "description": "删除的用户合并记录数（源或目标为该用户）",
+                    "type": "integer"
+                },
+                "metaId": {
+                    "description": "被删除数据的用户",
+                    "type": "string"
+                },
+                "pendingReceipts": {
+                    "description": "删除的待查询回执数",
+                    "type": "integer"
+                },
+                "preferences": {
+                    "description": "是否删除了推送偏好",
+                    "type": "boolean"
+                },
+                "qaAccount": {
+                    "description": "是否移除了 QA 账号登记",
+                    "type": "boolean"
+                },
+                "subscription": {
+                    "description": "是否删除了退订原因和历史",
+                    "type": "boolean"
+                },
+                "suppressionKept": {
+                    "description": "用户仍处于退订状态，保留了只含退订标记的记录（删除后不会恢复推送）",
+                    "type": "boolean"
+                },
+                "tokens": {
+                    "description": "删除的令牌平台",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "traceEvents": {
+                    "description": "删除的推送追踪事件数",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.UserMergeRecord": {
+            "type": "object",
+            "properties": {
+                "blockedChatsMerged": {
+                    "description": "迁移的屏蔽聊天数",
+                    "type": "integer"
+                },
+                "conflicts": {
+                    "description": "冲突及处理结果",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.MergeConflict"
+                    }
+                },
+                "error": {
+                    "description": "合并中途失败时的错误，已完成的步骤不会回滚",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "记录ID（按合并时间递增）",
+                    "type": "string"
+                },
+                "inboxMessagesMoved": {
+                    "description": "迁移的 QA 收件箱消息数",
+                    "type": "integer"
+                },
+                "mergedAt": {
+                    "description": "合并时间",
+                    "type": "integer"
+                },
+                "movedTokens": {
+                    "description": "迁移到目标用户的令牌平台",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "prefer": {
+                    "description": "冲突时保留的一方：target / source",
+                    "type": "string"
+                },
+                "preferencesMerged": {
+                    "description": "是否迁移了偏好",
+                    "type": "boolean"
+                },
+                "qaAccountMerged": {
+                    "description": "源用户是否为 QA 账号（目标用户随之成为 QA 账号）",
+                    "type": "boolean"
+                },
+                "reason": {
+                    "description": "合并原因（如身份服务事件ID）",
+                    "type": "string"
+                },
+                "sourceMetaId": {
+                    "description": "被合并的旧 MetaID",
+                    "type": "string"
+                },
+                "targetMetaId": {
+                    "description": "保留的 MetaID",
+                    "type": "string"
+                },
+                "tenantId": {
+                    "description": "合并后目标用户的租户",
+                    "type": "string"
+                }
+            }
+        },
+        "models.UserPreferences": {
+            "type": "object",
+            "required": [
+                "metaId"
+            ],
+            "properties": {
+                "categories": {
+                    "description": "客户端已注册的通知类别（带操作按钮），只有注册过的类别才在通知中设置 categoryId",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "hidePreviews": {
+                    "description": "是否隐藏通知中的消息内容和发送者（只显示\"New message\"）",
+                    "type": "boolean"
+                },
+                "locale": {
+                    "description": "用户语言区域，如 en、zh-CN、ja",
+                    "type": "string"
+                },
+                "metaId": {
+                    "description": "用户MetaID",
+                    "type": "string"
+                },
+                "muteCandyBags": {
+                    "description": "是否关闭红包（Candy Bag）通知",
+                    "type": "boolean"
+                },
+                "muteFriendRequests": {
+                    "description": "是否关闭好友请求通知",
+                    "type": "boolean"
+                },
+                "mutePayments": {
+                    "description": "是否关闭付款通知",
+                    "type": "boolean"
+                },
+                "pauseSummary": {
+                    "description": "恢复时是否发送\"错过 N 条消息\"的汇总通知",
+                    "type": "boolean"
+                },
+                "pausedMissed": {
+                    "description": "暂停期间未推送的消息数",
+                    "type": "integer"
+                },
+                "pausedUntil": {
+                    "description": "暂停通知至该时间（Unix 秒），到期自动恢复；0 表示未暂停",
+                    "type": "integer"
+                },
+                "translatePreviews": {
+                    "description": "是否将消息预览翻译为用户语言",
+                    "type": "boolean"
+                },
+                "updatedAt": {
+                    "description": "最后更新时间",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.UserPushTokens": {
+            "type": "object",
+            "required": [
+                "metaId"
+            ],
+            "properties": {
+                "metaId": {
+                    "description": "用户唯一标识",
+                    "type": "string"
+                },
+                "staleTokens": {
+                    "description": "长期未刷新、已停止推送的令牌（平台-\u003e令牌），重新注册时恢复，超过保留期后删除",
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/models.StaleToken"
+                    }
+                },
+                "tenantId": {
+                    "description": "所属租户ID（多租户部署时使用）",
+                    "type": "string"
+                },
+                "tokens": {
+                    "description": "平台-\u003e令牌映射 {\"expo\": \"ExponentPushToken[...]\", \"fcm\": \"fcm_token_123\"}",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "updatedAt": {
+                    "description": "最后更新时间",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.UserTrace": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "description": "开始时间",
+                    "type": "integer"
+                },
+                "metaId": {
+                    "description": "用户MetaID",
+                    "type": "string"
+                },
+                "note": {
+                    "description": "备注（如工单号）",
+                    "type": "string"
+                },
+                "until": {
+                    "description": "追踪截止时间 (Unix 秒)",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.VersionInfo": {
+            "type": "object",
+            "properties": {
+                "buildTags": {
+                    "description": "构建标签，如 noredis,nomysql",
+                    "type": "string"
+                },
+                "capabilities": {
+                    "description": "可选集成是否编译进当前构建，如 redis、mysql",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "boolean"
+                    }
+                },
+                "goVersion": {
+                    "description": "编译使用的 Go 版本",
+                    "type": "string"
+                },
+                "revision": {
+                    "description": "构建时的 Git 提交",
+                    "type": "string"
+                },
+                "version": {
+                    "description": "服务版本，构建时注入，未注入时为 dev",
+                    "type": "string"
+                }
+            }
+        },
+        "pebble_service.CollectionDBStats": {
+            "type": "object",
+            "properties": {
+                "blockCacheHits": {
+                    "description": "块缓存命中次数",
+                    "type": "integer"
+                },
+                "blockCacheMisses": {
+                    "description": "块缓存未命中次数",
+                    "type": "integer"
+                },
+                "blockCacheSize": {
+                    "description": "块缓存占用（字节）",
+                    "type": "integer"
+                },
+                "compactionDebt": {
+                    "description": "估计还需压缩的字节数",
+                    "type": "integer"
+                },
+                "compactionDurationMs": {
+                    "description": "压缩累计耗时（毫秒）",
+                    "type": "integer"
+                },
+                "compactions": {
+                    "description": "压缩次数",
+                    "type": "integer"
+                },
+                "compactionsInProgress": {
+                    "description": "正在进行的压缩数",
+                    "type": "integer"
+                },
+                "diskSize": {
+                    "description": "磁盘占用（字节，含 SST、WAL 和清单文件）",
+                    "type": "integer"
+                },
+                "flushes": {
+                    "description": "内存表刷盘次数",
+                    "type": "integer"
+                },
+                "memTableCount": {
+                    "description": "内存表数量",
+                    "type": "integer"
+                },
+                "memTableSize": {
+                    "description": "内存表占用（字节）",
+                    "type": "integer"
+                },
+                "name": {
+                    "description": "集合名称或共享实例目录名",
+                    "type": "string"
+                },
+                "obsoleteTablesSize": {
+                    "description": "待删除的 SST 文件大小（字节）",
+                    "type": "integer"
+                },
+                "readAmp": {
+                    "description": "读放大（查询最多需要读取的层数）",
+                    "type": "integer"
+                },
+                "tables": {
+                    "description": "SST 文件数",
+                    "type": "integer"
+                },
+                "tablesSize": {
+                    "description": "SST 文件总大小（字节）",
+                    "type": "integer"
+                },
+                "walFiles": {
+                    "description": "WAL 文件数",
+                    "type": "integer"
+                },
+                "walPhysicalSize": {
+                    "description": "WAL 文件在磁盘上的大小（字节）",
+                    "type": "integer"
+                },
+                "walSize": {
+                    "description": "WAL 中的数据大小（字节）",
+                    "type": "integer"
+                }
+            }
+        },
+        "pebble_service.CompactionResult": {
+            "type": "object",
+            "properties": {
+                "durationMs": {
+                    "description": "耗时（毫秒）",
+                    "type": "integer"
+                },
+                "error": {
+                    "description": "压缩失败原因",
+                    "type": "string"
+                },
+                "name": {
+                    "description": "集合名称",
+                    "type": "string"
+                },
+                "sizeAfter": {
+                    "description": "压缩后集合所在实例的磁盘占用（字节）",
+                    "type": "integer"
+                },
+                "sizeBefore": {
+                    "description": "压缩前集合所在实例的磁盘占用（字节）",
+                    "type": "integer"
+                },
+                "skipped": {
+                    "description": "集合为空，无需压缩",
+                    "type": "boolean"
+                }
+            }
+        },
+        "pebble_service.PaginatedUserTokens": {
+            "type": "object",
+            "properties": {
+                "hasNext": {
+                    "description": "是否有下一页",
+                    "type": "boolean"
+                },
+                "nextCursor": {
+                    "description": "下一页游标（有下一页时返回）",
+                    "type": "string"
+                },
+                "page": {
+                    "description": "当前页码",
+                    "type": "integer"
+                },
+                "pageSize": {
+                    "description": "每页大小",
+                    "type": "integer"
+                },
+                "total": {
+                    "description": "总数量",
+                    "type": "integer"
+                },
+                "totalPages": {
+                    "description": "总页数",
+                    "type": "integer"
+                },
+                "users": {
+                    "description": "用户令牌列表",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.UserPushTokens"
+                    }
+                }
+            }
+        },
+        "push_service.ProviderStats": {
+            "type": "object",
+            "properties": {
+                "avgLatencyMs": {
+                    "description": "平均耗时（毫秒）",
+                    "type": "number"
+                },
+                "failed": {
+                    "description": "发送失败次数",
+                    "type": "integer"
+                },
+                "lastError": {
+                    "description": "最近一次失败原因",
+                    "type": "string"
+                },
+                "lastErrorAt": {
+                    "description": "最近一次失败时间（Unix 秒）",
+                    "type": "integer"
+                },
+                "lastSuccessAt": {
+                    "description": "最近一次成功时间（Unix 秒）",
+                    "type": "integer"
+                },
+                "provider": {
+                    "description": "提供者名称",
+                    "type": "string"
+                },
+                "retried": {
+                    "description": "提供者内部重试次数",
+                    "type": "integer"
+                },
+                "sent": {
+                    "description": "发送成功次数",
+                    "type": "integer"
+                }
+            }
+        },
+        "pushcenter.CandyBagInfo": {
+            "type": "object",
+            "properties": {
+                "amountHint": {
+                    "description": "金额提示（消息中的 amount，原样透传）",
+                    "type": "string"
+                },
+                "claimDeadline": {
+                    "description": "领取截止时间（Unix 秒）",
+                    "type": "integer"
+                }
+            }
+        },
+        "pushcenter.IngestResult": {
+            "type": "object",
+            "properties": {
+                "accepted": {
+                    "description": "是否已交给推送中心处理（处理为异步，结果见投递记录）",
+                    "type": "boolean"
+                },
+                "chatType": {
+                    "description": "消息类型",
+                    "type": "string"
+                },
+                "reason": {
+                    "description": "未处理的原因",
+                    "type": "string"
+                }
+            }
+        },
+        "pushcenter.NotifyMessageInfo": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "description": "付款金额（仅付款通知）",
+                    "type": "string"
+                },
+                "currency": {
+                    "description": "付款币种（仅付款通知）",
+                    "type": "string"
+                },
+                "fromMetaId": {
+                    "description": "发起用户",
+                    "type": "string"
+                },
+                "fromName": {
+                    "description": "发起用户名称",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "好友请求ID或付款交易ID，用于去重",
+                    "type": "string"
+                },
+                "pinId": {
+                    "description": "PIN ID",
+                    "type": "string"
+                },
+                "recipients": {
+                    "description": "接收用户：消息中的 to 与 repostMetaIds 合并",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "type": {
+                    "description": "消息类型：friend_request / payment",
+                    "type": "string"
+                }
+            }
+        },
+        "pushcenter.ParsedMessageInfo": {
+            "type": "object",
+            "properties": {
+                "candyBag": {
+                    "description": "红包附加信息（红包消息时使用）",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/pushcenter.CandyBagInfo"
+                        }
+                    ]
+                },
+                "chatInfoType": {
+                    "description": "聊天信息类型：1/23-红包",
+                    "type": "integer"
+                },
+                "chatType": {
+                    "description": "聊天类型：private_chat 或 group_chat",
+                    "type": "string"
+                },
+                "encrypted": {
+                    "description": "消息是否加密",
+                    "type": "boolean"
+                },
+                "groupId": {
+                    "description": "群聊ID（群聊消息时使用）",
+                    "type": "string"
+                },
+                "metaId": {
+                    "description": "私聊的MetaId（私聊消息时使用）",
+                    "type": "string"
+                },
+                "notify": {
+                    "description": "好友请求、付款通知的附加信息",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/pushcenter.NotifyMessageInfo"
+                        }
+                    ]
+                },
+                "pinId": {
+                    "description": "PIN ID",
+                    "type": "string"
+                },
+                "preview": {
+                    "description": "消息预览（启用预览且消息未加密时）",
+                    "type": "string"
+                },
+                "senderId": {
+                    "description": "消息发送者MetaId（用于屏蔽发送者检查）",
+                    "type": "string"
+                },
+                "userName": {
+                    "description": "用户名",
+                    "type": "string"
+                }
+            }
+        },
+        "pushcenter.ReplayNotification": {
+            "type": "object",
+            "properties": {
+                "body": {
+                    "description": "应用路由规则后的内容",
+                    "type": "string"
+                },
+                "data": {
+                    "description": "自定义数据",
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "mention": {
+                    "description": "是否为提及通知",
+                    "type": "boolean"
+                },
+                "title": {
+                    "description": "应用路由规则后的标题",
+                    "type": "string"
+                },
+                "users": {
+                    "description": "接收用户",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "pushcenter.ReplayResult": {
+            "type": "object",
+            "properties": {
+                "alreadyNotified": {
+                    "description": "PIN 此前已推送过（回放不做去重，照常处理）",
+                    "type": "boolean"
+                },
+                "chatType": {
+                    "description": "消息类型",
+                    "type": "string"
+                },
+                "deliveries": {
+                    "description": "每个接收用户的投递结果或跳过原因",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.DeliveryRecord"
+                    }
+                },
+                "dryRun": {
+                    "description": "是否为演练",
+                    "type": "boolean"
+                },
+                "info": {
+                    "description": "解析出的消息信息",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/pushcenter.ParsedMessageInfo"
+                        }
+                    ]
+                },
+                "notifications": {
+                    "description": "生成的通知及其接收用户",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/pushcenter.ReplayNotification"
+                    }
+                },
+                "parseError": {
+                    "description": "严格解析失败的原因（回放不写入隔离集合）",
+                    "type": "string"
+                },
+                "validationError": {
+                    "description": "结构校验失败的原因（校验失败时不经过流水线，也不写入隔离集合）",
+                    "type": "string"
+                }
+            }
+        },
+        "pushcenter.RequeueResult": {
+            "type": "object",
+            "properties": {
+                "notFound": {
+                    "description": "不存在的条目ID",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "requeued": {
+                    "description": "已重新入队的条目ID",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "skipped": {
+                    "description": "已照常推送过（严格解析失败）、无需重新入队的条目ID",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "request.AddBlockedChatReq": {
+            "type": "object",
+            "required": [
+                "chatId",
+                "chatType",
+                "metaId"
+            ],
+            "properties": {
+                "chatId": {
+                    "type": "string"
+                },
+                "chatType": {
+                    "description": "聊天类型：group, private",
+                    "type": "string"
+                },
+                "metaId": {
+                    "type": "string"
+                },
+                "muteDuration": {
+                    "description": "MuteDuration 静音时长（秒，可选），如 3600（1小时）、28800（8小时）、604800（1周），优先级低于 MuteUntil",
+                    "type": "integer"
+                },
+                "muteUntil": {
+                    "description": "MuteUntil 静音截止时间（Unix 秒，可选），为空且未设置 MuteDuration 时为永久屏蔽",
+                    "type": "integer"
+                },
+                "reason": {
+                    "description": "屏蔽原因（可选）",
+                    "type": "string"
+                }
+            }
+        },
+        "request.AddBlockedSenderReq": {
+            "type": "object",
+            "required": [
+                "metaId",
+                "senderId"
+            ],
+            "properties": {
+                "metaId": {
+                    "type": "string"
+                },
+                "reason": {
+                    "description": "屏蔽原因",
+                    "type": "string"
+                },
+                "senderId": {
+                    "description": "被屏蔽的发送者MetaID",
+                    "type": "string"
+                }
+            }
+        },
+        "request.AllGroupsBlockReq": {
+            "type": "object",
+            "required": [
+                "metaId"
+            ],
+            "properties": {
+                "metaId": {
+                    "type": "string"
+                }
+            }
+        },
+        "request.BatchBlockedChatsReq": {
+            "type": "object",
+            "required": [
+                "metaId"
+            ],
+            "properties": {
+                "block": {
+                    "description": "要屏蔽的聊天",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/request.BlockedChatItem"
+                    }
+                },
+                "metaId": {
+                    "type": "string"
+                },
+                "unblock": {
+                    "description": "要取消屏蔽的聊天ID",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "request.BlockedChatItem": {
+            "type": "object",
+            "required": [
+                "chatId"
+            ],
+            "properties": {
+                "chatId": {
+                    "type": "string"
+                },
+                "chatType": {
+                    "description": "聊天类型：group, private",
+                    "type": "string"
+                },
+                "muteDuration": {
+                    "description": "静音时长（秒，可选），优先级低于 MuteUntil",
+                    "type": "integer"
+                },
+                "muteUntil": {
+                    "description": "静音截止时间（Unix 秒，可选）",
+                    "type": "integer"
+                },
+                "reason": {
+                    "description": "屏蔽原因（可选）",
+                    "type": "string"
+                }
+            }
+        },
+        "request.CancelScheduledPushReq": {
+            "type": "object",
+            "required": [
+                "id"
+            ],
+            "properties": {
+                "id": {
+                    "type": "string"
+                }
+            }
+        },
+        "request.ClearQAInboxReq": {
+            "type": "object",
+            "required": [
+                "metaId"
+            ],
+            "properties": {
+                "metaId": {
+                    "type": "string"
+                }
+            }
+        },
+        "request.CreateAPIKeyReq": {
+            "type": "object",
+            "required": [
+                "name",
+                "scopes"
+            ],
+            "properties": {
+                "name": {
+                    "description": "Key 名称，不能与已有 Key 重复",
+                    "type": "string"
+                },
+                "scopes": {
+                    "description": "权限范围：read、write、admin",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "tenantId": {
+                    "description": "调用方所属租户（可选），通过该 Key 发送的推送计入该租户的配额",
+                    "type": "string"
+                }
+            }
+        },
+        "request.DeleteUserDataReq": {
+            "type": "object",
+            "required": [
+                "metaId"
+            ],
+            "properties": {
+                "metaId": {
+                    "type": "string"
+                }
+            }
+        },
+        "request.MergeUsersReq": {
+            "type": "object",
+            "required": [
+                "sourceMetaId",
+                "targetMetaId"
+            ],
+            "properties": {
+                "prefer": {
+                    "description": "冲突时保留的一方：target（默认）/ source",
+                    "type": "string"
+                },
+                "reason": {
+                    "description": "合并原因（如身份服务事件ID），写入审计记录",
+                    "type": "string"
+                },
+                "sourceMetaId": {
+                    "description": "被合并的旧 MetaID",
+                    "type": "string"
+                },
+                "targetMetaId": {
+                    "description": "保留的 MetaID",
+                    "type": "string"
+                }
+            }
+        },
+        "request.PauseNotificationsReq": {
+            "type": "object",
+            "required": [
+                "metaId"
+            ],
+            "properties": {
+                "duration": {
+                    "description": "暂停时长：1h、8h 等，或 tomorrow（次日早上 8 点）",
+                    "type": "string"
+                },
+                "metaId": {
+                    "type": "string"
+                },
+                "summary": {
+                    "description": "恢复时是否发送\"错过 N 条消息\"的汇总通知",
+                    "type": "boolean"
+                },
+                "until": {
+                    "description": "暂停截止时间（Unix 秒），优先于 duration",
+                    "type": "integer"
+                },
+                "utcOffsetMinutes": {
+                    "description": "用户时区相对 UTC 的分钟数，用于 tomorrow，如东八区为 480",
+                    "type": "integer"
+                }
+            }
+        },
+        "request.RemoveBlockedChatReq": {
+            "type": "object",
+            "required": [
+                "chatId",
+                "metaId"
+            ],
+            "properties": {
+                "chatId": {
+                    "type": "string"
+                },
+                "metaId": {
+                    "type": "string"
+                }
+            }
+        },
+        "request.RemoveBlockedSenderReq": {
+            "type": "object",
+            "required": [
+                "metaId",
+                "senderId"
+            ],
+            "properties": {
+                "metaId": {
+                    "type": "string"
+                },
+                "senderId": {
+                    "type": "string"
+                }
+            }
+        },
+        "request.RemoveQAAccountReq": {
+            "type": "object",
+            "required": [
+                "metaId"
+            ],
+            "properties": {
+                "metaId": {
+                    "type": "string"
+                }
+            }
+        },
+        "request.RemoveTenantWebhookReq": {
+            "type": "object",
+            "required": [
+                "tenantId"
+            ],
+            "properties": {
+                "tenantId": {
+                    "type": "string"
+                }
+            }
+        },
+        "request.RemoveUserAllTokensReq": {
+            "type": "object",
+            "required": [
+                "metaId"
+            ],
+            "properties": {
+                "metaId": {
+                    "type": "string"
+                }
+            }
+        },
+        "request.RemoveUserTokenReq": {
+            "type": "object",
+            "required": [
+                "metaId",
+                "platform"
+            ],
+            "properties": {
+                "metaId": {
+                    "type": "string"
+                },
+                "platform": {
+                    "type": "string"
+                }
+            }
+        },
+        "request.ReplayMessageReq": {
+            "type": "object",
+            "required": [
+                "payload"
+            ],
+            "properties": {
+                "dryRun": {
+                    "description": "演练：不调用推送平台，也不记录通知状态和投递结果",
+                    "type": "boolean"
+                },
+                "payload": {
+                    "description": "原始 SocketData 载荷（JSON 对象或 JSON 字符串）",
+                    "type": "object"
+                }
+            }
+        },
+        "request.RequeueQuarantinedReq": {
+            "type": "object",
+            "properties": {
+                "ids": {
+                    "description": "隔离条目ID（见 get_quarantined_messages），为空时重新入队所有未推送的消息",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "request.RestoreBackupReq": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "description": "备份文件名（见 get_backups）",
+                    "type": "string"
+                }
+            }
+        },
+        "request.ResubscribeReq": {
+            "type": "object",
+            "required": [
+                "metaId"
+            ],
+            "properties": {
+                "metaId": {
+                    "type": "string"
+                }
+            }
+        },
+        "request.ResumeNotificationsReq": {
+            "type": "object",
+            "required": [
+                "metaId"
+            ],
+            "properties": {
+                "metaId": {
+                    "type": "string"
+                }
+            }
+        },
+        "request.RevokeAPIKeyReq": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "request.SchedulePushLocalTimeReq": {
+            "type": "object",
+            "required": [
+                "body",
+                "localHour",
+                "metaIds",
+                "title"
+            ],
+            "properties": {
+                "body": {
+                    "description": "通知内容",
+                    "type": "string"
+                },
+                "data": {
+                    "description": "自定义数据（可选）",
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "defaultTimezone": {
+                    "description": "未上报时区的用户使用的时区（可选，IANA 时区名，默认 UTC）",
+                    "type": "string"
+                },
+                "localHour": {
+                    "description": "当地发送时间（0-23 点）",
+                    "type": "integer"
+                },
+                "metaIds": {
+                    "description": "接收用户列表",
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "priority": {
+                    "description": "优先级（可选，normal/high）",
+                    "type": "string"
+                },
+                "sound": {
+                    "description": "声音（可选，默认 default）",
+                    "type": "string"
+                },
+                "title": {
+                    "description": "通知标题",
+                    "type": "string"
+                }
+            }
+        },
+        "request.SchedulePushReq": {
+            "type": "object",
+            "required": [
+                "body",
+                "metaIds",
+                "sendAt",
+                "title"
+            ],
+            "properties": {
+                "body": {
+                    "description": "通知内容",
+                    "type": "string"
+                },
+                "data": {
+                    "description": "自定义数据（可选）",
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "metaIds": {
+                    "description": "接收用户列表",
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "priority": {
+                    "description": "优先级（可选，normal/high）",
+                    "type": "string"
+                },
+                "sendAt": {
+                    "description": "计划发送时间（Unix 秒）",
+                    "type": "integer"
+                },
+                "sound": {
+                    "description": "声音（可选，默认 default）",
+                    "type": "string"
+                },
+                "title": {
+                    "description": "通知标题",
+                    "type": "string"
+                }
+            }
+        },
+        "request.SeedTestDataReq": {
+            "type": "object",
+            "required": [
+                "count"
+            ],
+            "properties": {
+                "count": {
+                    "description": "生成的用户数",
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "maxBlockedChats": {
+                    "description": "每个用户最多屏蔽的聊天数，默认 3",
+                    "type": "integer"
+                },
+                "prefix": {
+                    "description": "用户 MetaID 前缀，默认 seed-",
+                    "type": "string"
+                },
+                "seed": {
+                    "description": "随机种子，相同种子生成相同数据",
+                    "type": "integer"
+                }
+            }
+        },
+        "request.SendDataPushReq": {
+            "type": "object",
+            "required": [
+                "data",
+                "metaIds"
+            ],
+            "properties": {
+                "data": {
+                    "description": "推送数据",
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "dryRun": {
+                    "description": "演练模式（可选，完整执行推送流程但不调用推送平台）",
+                    "type": "boolean"
+                },
+                "idempotencyKey": {
+                    "description": "幂等键（可选，也可通过 Idempotency-Key 请求头传入）",
+                    "type": "string"
+                },
+                "metaIds": {
+                    "description": "接收用户列表",
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "priority": {
+                    "description": "优先级（可选，默认 normal；iOS 后台推送需使用 normal）",
+                    "type": "string"
+                },
+                "ttl": {
+                    "description": "有效期（秒，可选）",
+                    "type": "integer"
+                }
+            }
+        },
+        "request.SendPushReq": {
             "type": "object",
             "required": [
-                "chatId",
-                "userId"
+                "body",
+                "metaIds",
+                "title"
             ],
             "properties": {
-                "blockedAt": {
-                    "description": "屏蔽时间",
-                    "type": "integer"
+                "body": {
+                    "description": "通知内容",
+                    "type": "string"
+                },
+                "collapseId": {
+                    "description": "折叠ID（可选，相同ID的新通知替换旧通知）",
+                    "type": "string"
+                },
+                "data": {
+                    "description": "自定义数据（可选）",
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "dryRun": {
+                    "description": "演练模式（可选，完整执行推送流程但不调用推送平台）",
+                    "type": "boolean"
+                },
+                "idempotencyKey": {
+                    "description": "幂等键（可选，也可通过 Idempotency-Key 请求头传入）",
+                    "type": "string"
                 },
+                "metaIds": {
+                    "description": "接收用户列表",
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "priority": {
+                    "description": "优先级（可选，normal/high）",
+                    "type": "string"
+                },
+                "sound": {
+                    "description": "声音（可选，默认 default）",
+                    "type": "string"
+                },
+                "threadId": {
+                    "description": "分组ID（可选，相同ID的通知归为一组）",
+                    "type": "string"
+                },
+                "title": {
+                    "description": "通知标题",
+                    "type": "string"
+                }
+            }
+        },
+        "request.SetChatSoundReq": {
+            "type": "object",
+            "required": [
+                "chatId",
+                "metaId"
+            ],
+            "properties": {
                 "chatId": {
-                    "description": "群ID或私聊ID",
                     "type": "string"
                 },
                 "chatType": {
-                    "description": "聊天类型 (group, private)",
+                    "description": "聊天类型：group, private",
                     "type": "string"
                 },
-                "reason": {
-                    "description": "屏蔽原因",
+                "metaId": {
                     "type": "string"
                 },
-                "userId": {
-                    "description": "用户ID",
+                "sound": {
+                    "description": "Sound 客户端内置的声音文件名（如 chime.wav），silent 表示静音，为空时恢复默认声音",
                     "type": "string"
                 }
             }
         },
-        "models.UserBlockedChats": {
+        "request.SetEnabledTypesReq": {
             "type": "object",
             "required": [
-                "userId"
+                "types"
             ],
             "properties": {
-                "blockedChats": {
-                    "description": "屏蔽的聊天列表",
+                "types": {
+                    "description": "启用的消息类型：private_chat / group_chat / friend_request / payment",
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/models.BlockedChat"
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "request.SetNotificationCategoriesReq": {
+            "type": "object",
+            "required": [
+                "metaId"
+            ],
+            "properties": {
+                "categories": {
+                    "description": "客户端已向系统注册的通知类别：message、mention、candy_bag，为空表示不再接收带操作的通知",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
                     }
                 },
-                "updatedAt": {
-                    "description": "最后更新时间",
-                    "type": "integer"
-                },
-                "userId": {
-                    "description": "用户ID",
+                "metaId": {
                     "type": "string"
                 }
             }
         },
-        "models.UserPushTokens": {
+        "request.SetQAAccountReq": {
             "type": "object",
             "required": [
                 "metaId"
             ],
             "properties": {
                 "metaId": {
-                    "description": "用户唯一标识",
                     "type": "string"
                 },
-                "tokens": {
-                    "description": "平台-\u003e令牌映射 {\"expo\": \"ExponentPushToken[...]\", \"fcm\": \"fcm_token_123\"}",
-                    "type": "object",
-                    "additionalProperties": {
-                        "type": "string"
+                "note": {
+                    "description": "备注（可选）",
+                    "type": "string"
+                }
+            }
+        },
+        "request.SetRoutingRulesReq": {
+            "type": "object",
+            "properties": {
+                "rules": {
+                    "description": "路由规则（按顺序匹配，命中第一条即止），空列表表示不使用任何规则",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.RoutingRule"
                     }
-                },
-                "updatedAt": {
-                    "description": "最后更新时间",
+                }
+            }
+        },
+        "request.SetTenantQuotaReq": {
+            "type": "object",
+            "required": [
+                "tenantId"
+            ],
+            "properties": {
+                "monthlyLimit": {
+                    "description": "每月推送上限（按设备投递计数），0 表示不限制",
                     "type": "integer"
+                },
+                "tenantId": {
+                    "type": "string"
                 }
             }
         },
-        "pebble_service.PaginatedUserTokens": {
+        "request.SetTenantWebhookReq": {
             "type": "object",
+            "required": [
+                "tenantId",
+                "url"
+            ],
             "properties": {
-                "hasNext": {
-                    "description": "是否有下一页",
+                "enabled": {
+                    "description": "是否启用（可选，默认启用）",
                     "type": "boolean"
                 },
-                "page": {
-                    "description": "当前页码",
-                    "type": "integer"
+                "secret": {
+                    "description": "签名密钥（可选，设置后回调携带 X-Webhook-Signature）",
+                    "type": "string"
                 },
-                "pageSize": {
-                    "description": "每页大小",
-                    "type": "integer"
+                "tenantId": {
+                    "type": "string"
                 },
-                "total": {
-                    "description": "总数量",
-                    "type": "integer"
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "request.SetUserPreferencesReq": {
+            "type": "object",
+            "required": [
+                "metaId"
+            ],
+            "properties": {
+                "hidePreviews": {
+                    "description": "是否隐藏通知中的消息内容和发送者名称",
+                    "type": "boolean"
                 },
-                "totalPages": {
-                    "description": "总页数",
-                    "type": "integer"
+                "locale": {
+                    "description": "用户语言区域，如 en、zh-CN、ja",
+                    "type": "string"
                 },
-                "users": {
-                    "description": "用户令牌列表",
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/models.UserPushTokens"
-                    }
+                "metaId": {
+                    "type": "string"
+                },
+                "muteCandyBags": {
+                    "description": "是否关闭红包（Candy Bag）通知",
+                    "type": "boolean"
+                },
+                "muteFriendRequests": {
+                    "description": "是否关闭好友请求通知",
+                    "type": "boolean"
+                },
+                "mutePayments": {
+                    "description": "是否关闭付款通知",
+                    "type": "boolean"
+                },
+                "translatePreviews": {
+                    "description": "是否将消息预览翻译为用户语言（需服务端启用预览翻译）",
+                    "type": "boolean"
                 }
             }
         },
-        "request.AddBlockedChatReq": {
+        "request.SetUserTenantReq": {
             "type": "object",
             "required": [
-                "chatId",
-                "chatType",
                 "metaId"
             ],
             "properties": {
-                "chatId": {
+                "metaId": {
                     "type": "string"
                 },
-                "chatType": {
-                    "description": "聊天类型：group, private",
+                "tenantId": {
+                    "description": "租户ID，为空表示移出租户",
+                    "type": "string"
+                }
+            }
+        },
+        "request.SetUserTokensReq": {
+            "type": "object",
+            "required": [
+                "metaId",
+                "platform",
+                "token"
+            ],
+            "properties": {
+                "appVersion": {
+                    "description": "设备信息（均为可选，未传的字段保留上次上报的值）",
+                    "type": "string"
+                },
+                "deviceModel": {
+                    "description": "设备型号，如 iPhone15,2",
+                    "type": "string"
+                },
+                "locale": {
+                    "description": "设备语言区域，如 zh-CN",
                     "type": "string"
                 },
                 "metaId": {
                     "type": "string"
                 },
-                "reason": {
-                    "description": "屏蔽原因（可选）",
+                "osVersion": {
+                    "description": "系统版本，如 iOS 17.5",
+                    "type": "string"
+                },
+                "platform": {
+                    "type": "string"
+                },
+                "timezone": {
+                    "description": "设备时区（IANA 名称），如 Asia/Shanghai",
+                    "type": "string"
+                },
+                "token": {
+                    "description": "Token本身就是设备的唯一标识",
                     "type": "string"
                 }
             }
         },
-        "request.RemoveBlockedChatReq": {
+        "request.StartUserTraceReq": {
             "type": "object",
             "required": [
-                "chatId",
                 "metaId"
             ],
             "properties": {
-                "chatId": {
+                "duration": {
+                    "description": "追踪时长，如 30m、2h，默认 1h，最长 24h",
                     "type": "string"
                 },
                 "metaId": {
                     "type": "string"
+                },
+                "note": {
+                    "description": "备注（如工单号，可选）",
+                    "type": "string"
                 }
             }
         },
-        "request.RemoveUserAllTokensReq": {
+        "request.StopUserTraceReq": {
             "type": "object",
             "required": [
                 "metaId"
@@ -624,37 +7551,63 @@ const docTemplate = `{
                 }
             }
         },
-        "request.RemoveUserTokenReq": {
+        "request.TestPushReq": {
             "type": "object",
-            "required": [
-                "metaId",
-                "platform"
-            ],
             "properties": {
+                "body": {
+                    "description": "通知内容（可选，默认测试文案）",
+                    "type": "string"
+                },
                 "metaId": {
+                    "description": "接收用户（发送到该用户登记的所有平台）",
                     "type": "string"
                 },
                 "platform": {
+                    "description": "令牌所属平台（可选，为空时按令牌格式识别）",
+                    "type": "string"
+                },
+                "title": {
+                    "description": "通知标题（可选，默认测试文案）",
                     "type": "string"
+                },
+                "token": {
+                    "description": "推送令牌（只发送到该令牌）",
+                    "type": "string"
+                },
+                "wait": {
+                    "description": "等待投递回执的秒数（可选，0 表示不等待，最多 60）",
+                    "type": "integer"
                 }
             }
         },
-        "request.SetUserTokensReq": {
+        "request.UnsubscribeReq": {
             "type": "object",
             "required": [
-                "metaId",
-                "platform",
-                "token"
+                "metaId"
             ],
             "properties": {
                 "metaId": {
                     "type": "string"
                 },
-                "platform": {
+                "reason": {
+                    "description": "退订原因，记入审计记录",
+                    "type": "string"
+                }
+            }
+        },
+        "respond.FieldError": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "description": "字段名（请求体中的 json 字段名或查询参数名），嵌套字段如 block[0].chatId",
                     "type": "string"
                 },
-                "token": {
-                    "description": "Token本身就是设备的唯一标识",
+                "message": {
+                    "description": "错误说明",
+                    "type": "string"
+                },
+                "rule": {
+                    "description": "未通过的校验规则，如 required、oneof、type",
                     "type": "string"
                 }
             }
@@ -668,6 +7621,16 @@ const docTemplate = `{
                     "example": 0
                 },
                 "data": {},
+                "details": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/respond.FieldError"
+                    }
+                },
+                "errorCode": {
+                    "type": "string",
+                    "example": "invalid_param"
+                },
                 "message": {
                     "type": "string",
                     "example": "success"
@@ -677,6 +7640,34 @@ const docTemplate = `{
                     "example": 123
                 }
             }
+        },
+        "storage_service.SeedResult": {
+            "type": "object",
+            "properties": {
+                "blockedChats": {
+                    "description": "写入的屏蔽聊天数",
+                    "type": "integer"
+                },
+                "metaIds": {
+                    "description": "生成的用户",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "preferences": {
+                    "description": "写入的偏好设置数",
+                    "type": "integer"
+                },
+                "seed": {
+                    "description": "实际使用的随机种子",
+                    "type": "integer"
+                },
+                "tokens": {
+                    "description": "写入的令牌数（平台 mock）",
+                    "type": "integer"
+                }
+            }
         }
     },
     "securityDefinitions": {
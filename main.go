@@ -1,15 +1,43 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"log"
+	"os"
+	"os/signal"
 	"push-base-service/conf"
 	"push-base-service/controller"
+	"push-base-service/controller/auth"
+	"push-base-service/controller/grpcapi"
+	"push-base-service/models"
+	"push-base-service/service/apns_service"
+	"push-base-service/service/backup_service"
+	"push-base-service/service/catchup_service"
+	"push-base-service/service/dedup_service"
+	"push-base-service/service/disk_service"
+	"push-base-service/service/egress_service"
+	"push-base-service/service/email_service"
 	"push-base-service/service/expo_service"
+	"push-base-service/service/handoff_service"
+	"push-base-service/service/job_service"
+	"push-base-service/service/lock_service"
+	"push-base-service/service/membership_service"
 	"push-base-service/service/pebble_service"
 	pushcenter "push-base-service/service/push_center"
+	"push-base-service/service/push_service"
+	"push-base-service/service/sampling_service"
+	"push-base-service/service/schedule_service"
 	"push-base-service/service/socket_client_service"
+	"push-base-service/service/storage_service"
+	"push-base-service/service/throttle_service"
+	"push-base-service/service/tracing_service"
+	"push-base-service/service/translate_service"
+	"push-base-service/service/webhook_service"
+	"push-base-service/service/wns_service"
+	"syscall"
 	"time"
 )
 
@@ -22,12 +50,117 @@ func initPushCenter() {
 
 	log.Printf("🚀 开始初始化推送中心...")
 
+	// 1-3. 根据配置文件构建推送中心配置
+	pushCenterConfig := buildPushCenterConfig()
+
+	// 4. 创建推送中心实例
+	pushCenter := pushcenter.NewPushCenter(pushCenterConfig)
+
+	// 5. 初始化推送中心
+	if err := pushCenter.Initialize(); err != nil {
+		log.Fatalf("❌ 初始化推送中心失败: %v", err)
+	}
+
+	// 6. 创建并注册 Expo 推送提供者
+	expoConfig := buildExpoConfig()
+
+	if err := pushCenter.GetPushManager().RegisterExpoProvider(expoConfig); err != nil {
+		log.Printf("⚠️ 注册 Expo 推送提供者失败: %v", err)
+	} else {
+		log.Printf("✅ 已注册 Expo 推送提供者")
+		// 配置热加载后更新超时、重试和批量大小等设置，无需重启
+		conf.OnReload(func() {
+			if err := pushCenter.GetPushManager().UpdateExpoConfig(buildExpoConfig()); err != nil {
+				log.Printf("⚠️ 更新 Expo 推送提供者配置失败: %v", err)
+			} else {
+				log.Printf("✅ 已更新 Expo 推送提供者配置")
+			}
+		})
+	}
+
+	// 注册邮件兜底提供者并设置兜底链
+	if conf.EmailEnabled {
+		if err := pushCenter.GetPushManager().RegisterEmailProvider(buildEmailConfig()); err != nil {
+			log.Printf("⚠️ 注册邮件兜底提供者失败: %v", err)
+		} else {
+			log.Printf("✅ 已注册邮件兜底提供者")
+		}
+	}
+	// 注册桌面客户端推送提供者（macOS 通过 APNs，Windows 通过 WNS）
+	if conf.MacOSEnabled {
+		if err := pushCenter.GetPushManager().RegisterAPNSProvider(push_service.PlatformMacOS, buildMacOSConfig()); err != nil {
+			log.Printf("⚠️ 注册 macOS 推送提供者失败: %v", err)
+		} else {
+			log.Printf("✅ 已注册 macOS 推送提供者（APNs）")
+		}
+	}
+	if conf.WNSEnabled {
+		if err := pushCenter.GetPushManager().RegisterWNSProvider(buildWNSConfig()); err != nil {
+			log.Printf("⚠️ 注册 Windows 推送提供者失败: %v", err)
+		} else {
+			log.Printf("✅ 已注册 Windows 推送提供者（WNS）")
+		}
+	}
+	// 预发环境注册模拟推送提供者，测试数据的 mock 令牌由其直接返回成功
+	if conf.StagingEnabled {
+		if err := pushCenter.GetPushManager().RegisterMockProvider(parseDuration(conf.StagingMockLatency, 0)); err != nil {
+			log.Printf("⚠️ 注册模拟推送提供者失败: %v", err)
+		} else {
+			log.Printf("🧪 预发环境：已注册模拟推送提供者，测试数据接口已开启")
+		}
+	}
+	if len(conf.FallbackChains) > 0 {
+		pushCenter.GetPushManager().SetFallbackChains(conf.FallbackChains)
+		log.Printf("📧 推送兜底链: %v", conf.FallbackChains)
+	}
+	if conf.PushDryRun {
+		pushCenter.GetPushManager().SetDryRun(true)
+		log.Printf("🧪 演练模式已开启：推送流程照常执行，但不会调用推送平台")
+	}
+
+	// 7. 启动推送中心
+	go func() {
+		if err := pushCenter.Run(); err != nil {
+			log.Fatalf("❌ 启动推送中心失败: %v", err)
+		}
+	}()
+
+	// 8. 等待推送中心启动
+	time.Sleep(2 * time.Second)
+
+	if pushCenter.IsRunning() {
+		log.Printf("✅ 推送中心已成功启动")
+		for _, health := range pushCenter.GetUpstreamHealth() {
+			log.Printf("🔗 Socket 服务器 [%s]: %s (connected=%v)", health.Name, health.ServerURL, health.Connected)
+		}
+		log.Printf("🗄️ 数据库路径: %s", conf.PushCenterDBPath)
+		if conf.SocketAuthTokenURL != "" {
+			log.Printf("🔑 Socket 认证令牌接口: %s", conf.SocketAuthTokenURL)
+		} else {
+			log.Printf("🔑 SocketExtraPushAuthKey: %s", conf.SocketExtraPushAuthKey)
+		}
+	} else {
+		log.Printf("⚠️ 推送中心启动状态检查失败")
+	}
+
+	// 注册优雅关闭处理
+	// 注意：这里只是示例，实际项目中可能需要更完善的信号处理
+	log.Printf("💡 提示：推送中心将在应用程序退出时自动关闭")
+}
+
+// buildPushCenterConfig 根据配置文件构建推送中心配置（启动与自检共用）
+func buildPushCenterConfig() *pushcenter.Config {
 	// 1. 创建 Socket 客户端配置
 	socketConfig := &socket_client_service.Config{
 		ServerURL:        conf.SocketServerURL,
 		ExtraPushAuthKey: conf.SocketExtraPushAuthKey,
 		Path:             conf.SocketPath,
 		Timeout:          conf.SocketTimeout,
+		StaleTimeout:     conf.SocketStaleTimeout,
+
+		AuthTokenURL:      conf.SocketAuthTokenURL,
+		AuthTokenSecret:   conf.SocketAuthTokenSecret,
+		AuthRefreshBefore: conf.SocketAuthRefresh,
 	}
 
 	// 设置默认值
@@ -38,9 +171,38 @@ func initPushCenter() {
 		socketConfig.Timeout = 10
 	}
 
+	// 额外的上游 Socket 服务器（如聊天集群分片），未配置的字段沿用主配置
+	var socketConfigs []*socket_client_service.Config
+	for _, server := range conf.SocketServers {
+		if server.ServerURL == "" {
+			log.Printf("⚠️ 忽略未配置 server_url 的上游 Socket 服务器: %s", server.Name)
+			continue
+		}
+		socketConfigs = append(socketConfigs, &socket_client_service.Config{
+			Name:             server.Name,
+			ServerURL:        server.ServerURL,
+			ExtraPushAuthKey: getStringWithDefault(server.ExtraPushAuthKey, socketConfig.ExtraPushAuthKey),
+			Path:             getStringWithDefault(server.Path, socketConfig.Path),
+			Timeout:          getIntWithDefault(server.Timeout, socketConfig.Timeout),
+			StaleTimeout:     getIntWithDefault(server.StaleTimeout, socketConfig.StaleTimeout),
+
+			AuthTokenURL:      getStringWithDefault(server.AuthTokenURL, socketConfig.AuthTokenURL),
+			AuthTokenSecret:   getStringWithDefault(server.AuthTokenSecret, socketConfig.AuthTokenSecret),
+			AuthRefreshBefore: getIntWithDefault(server.AuthRefreshBefore, socketConfig.AuthRefreshBefore),
+		})
+	}
+
 	// 2. 创建 Pebble 数据库配置
 	pebbleConfig := &pebble_service.Config{
-		DBPath: conf.PushCenterDBPath,
+		DBPath:   conf.PushCenterDBPath,
+		Keyspace: getStringWithDefault(conf.PushCenterKeyspace, pebble_service.KeyspaceMulti),
+		Compression: &pebble_service.CompressionConfig{
+			Enabled:     conf.CompressionEnabled,
+			Threshold:   getIntWithDefault(conf.CompressionThreshold, pebble_service.DefaultCompressionThreshold),
+			Collections: conf.CompressionCollections,
+		},
+		// 同一数据目录只允许一个进程；部署交接和主备选举的各实例使用各自的数据目录
+		ProcessLock: true,
 	}
 
 	// 设置默认数据库路径
@@ -50,21 +212,246 @@ func initPushCenter() {
 
 	// 3. 创建推送中心配置
 	pushCenterConfig := &pushcenter.Config{
-		SocketConfig: socketConfig,
-		PebbleConfig: pebbleConfig,
-		EnabledTypes: []string{"private_chat", "group_chat"}, // 启用私聊和群聊消息
+		SocketConfig:   socketConfig,
+		SocketConfigs:  socketConfigs,
+		PebbleConfig:   pebbleConfig,
+		EnabledTypes:   conf.EnabledTypes, // 未配置时启用私聊和群聊消息
+		IdempotencyTTL: parseDuration(conf.IdempotencyTTL, pebble_service.DefaultIdempotencyTTL),
+		TokenCacheSize: getIntWithDefault(conf.TokenCacheSize, pebble_service.DefaultTokenCacheSize),
+		TokenCacheTTL:  parseDuration(conf.TokenCacheTTL, pebble_service.DefaultTokenCacheTTL),
+		WarmupConfig: &pushcenter.WarmupConfig{
+			Enabled:      conf.WarmupEnabled,
+			Size:         getIntWithDefault(conf.WarmupSize, pebble_service.DefaultHotUsersSize),
+			SaveInterval: parseDuration(conf.WarmupSaveInterval, pushcenter.DefaultHotUsersSaveInterval),
+		},
+		IntakeConfig: &pushcenter.IntakeConfig{
+			Enabled:     conf.IntakeEnabled,
+			MaxAttempts: getIntWithDefault(conf.IntakeMaxAttempts, pushcenter.DefaultIntakeMaxAttempts),
+		},
+		DeliveryConfig: &pushcenter.DeliveryConfig{
+			Enabled:       conf.DeliveryEnabled,
+			Retention:     parseDuration(conf.DeliveryRetention, pushcenter.DefaultDeliveryRetention),
+			ReceiptDelay:  parseDuration(conf.DeliveryReceiptDelay, pushcenter.DefaultReceiptDelay),
+			CheckInterval: parseDuration(conf.DeliveryCheckInterval, pushcenter.DefaultReceiptCheckInterval),
+		},
+		ShutdownConfig: &pushcenter.ShutdownConfig{
+			SourceTimeout:  parseDuration(conf.ShutdownSourceTimeout, pushcenter.DefaultShutdownSourceTimeout),
+			DrainTimeout:   parseDuration(conf.ShutdownDrainTimeout, pushcenter.DefaultShutdownDrainTimeout),
+			ReceiptTimeout: parseDuration(conf.ShutdownReceiptTimeout, pushcenter.DefaultShutdownReceiptTimeout),
+			StageTimeout:   parseDuration(conf.ShutdownStageTimeout, pushcenter.DefaultShutdownStageTimeout),
+		},
+		OrderingConfig: &pushcenter.OrderingConfig{
+			Enabled:   conf.OrderingEnabled,
+			Queues:    getIntWithDefault(conf.OrderingQueues, pushcenter.DefaultOrderingQueues),
+			QueueSize: getIntWithDefault(conf.OrderingQueueSize, pushcenter.DefaultOrderingQueueSize),
+		},
+		Backpressure: &pushcenter.BackpressureConfig{
+			Enabled:        conf.BackpressureEnabled,
+			HighWatermark:  getIntWithDefault(conf.BackpressureHighWatermark, pushcenter.DefaultBackpressureHighWatermark),
+			LowWatermark:   conf.BackpressureLowWatermark,
+			SignalUpstream: conf.BackpressureSignalUpstream,
+		},
+		AckConfig: &pushcenter.AckConfig{
+			Enabled: conf.PushAckEnabled,
+		},
+		TokenGCConfig: &pushcenter.TokenGCConfig{
+			Enabled:         conf.TokenGCEnabled,
+			StaleAfterDays:  getIntWithDefault(conf.TokenGCStaleAfterDays, pushcenter.DefaultTokenStaleAfterDays),
+			DeleteAfterDays: getIntWithDefault(conf.TokenGCDeleteAfterDays, pushcenter.DefaultTokenDeleteAfterDays),
+			Interval:        parseDuration(conf.TokenGCInterval, pushcenter.DefaultTokenGCInterval),
+		},
+		AuditConfig: &pushcenter.AuditConfig{
+			Enabled:      conf.AuditEnabled,
+			Retention:    parseDuration(conf.AuditRetention, pushcenter.DefaultAuditRetention),
+			PerUserLimit: getIntWithDefault(conf.AuditPerUserLimit, pebble_service.DefaultPushAuditLimit),
+			QueueSize:    getIntWithDefault(conf.AuditQueueSize, pushcenter.DefaultAuditQueueSize),
+		},
+		JobConfig: &job_service.Config{
+			Retention: parseDuration(conf.JobRetention, job_service.DefaultConfig().Retention),
+		},
+		DiskConfig: &disk_service.Config{
+			Enabled:         conf.DiskMonitorEnabled,
+			WarningPercent:  conf.DiskWarningPercent,
+			CriticalPercent: conf.DiskCriticalPercent,
+			CheckInterval:   parseDuration(conf.DiskCheckInterval, disk_service.DefaultConfig().CheckInterval),
+		},
+		ScheduleConfig: &schedule_service.Config{
+			PollInterval: parseDuration(conf.SchedulePollInterval, 5*time.Second),
+			BatchSize:    getIntWithDefault(conf.ScheduleBatchSize, 100),
+			SendTimeout:  parseDuration(conf.ScheduleSendTimeout, 30*time.Second),
+		},
+		ThrottleConfig: &throttle_service.Config{
+			Enabled: conf.ThrottleEnabled,
+			Backend: getStringWithDefault(conf.ThrottleBackend, throttle_service.BackendMemory),
+			Limit:   getIntWithDefault(conf.ThrottleLimit, 30),
+			Window:  parseDuration(conf.ThrottleWindow, time.Minute),
+			Redis: throttle_service.RedisConfig{
+				Addr:      getStringWithDefault(conf.ThrottleRedisAddr, "127.0.0.1:6379"),
+				Password:  conf.ThrottleRedisPassword,
+				DB:        conf.ThrottleRedisDB,
+				KeyPrefix: getStringWithDefault(conf.ThrottleRedisKeyPrefix, "push:throttle:"),
+			},
+		},
+		StorageConfig: &storage_service.Config{
+			Backend: getStringWithDefault(conf.StorageBackend, storage_service.BackendPebble),
+			Redis: storage_service.RedisConfig{
+				Addr:      getStringWithDefault(conf.StorageRedisAddr, "127.0.0.1:6379"),
+				Password:  conf.StorageRedisPassword,
+				DB:        conf.StorageRedisDB,
+				KeyPrefix: getStringWithDefault(conf.StorageRedisKeyPrefix, "push:store:"),
+				PinTTL:    parseDuration(conf.StorageRedisPinTTL, 30*24*time.Hour),
+			},
+			PinMaxAge: parseDuration(conf.StoragePinMaxAge, 30*24*time.Hour),
+			PinFilter: storage_service.PinFilterConfig{
+				Enabled:           conf.PinFilterEnabled,
+				ExpectedItems:     getIntWithDefault(conf.PinFilterExpectedItems, 1000000),
+				FalsePositiveRate: conf.PinFilterFalsePositiveRate,
+				RebuildInterval:   parseDuration(conf.PinFilterRebuildInterval, time.Hour),
+			},
+		},
+		DedupConfig: &dedup_service.Config{
+			Mode:       getStringWithDefault(conf.DedupMode, dedup_service.ModeLocal),
+			InstanceID: conf.DedupInstanceID,
+			ClaimTTL:   parseDuration(conf.DedupClaimTTL, 24*time.Hour),
+			Redis: dedup_service.RedisConfig{
+				Addr:      getStringWithDefault(conf.DedupRedisAddr, "127.0.0.1:6379"),
+				Password:  conf.DedupRedisPassword,
+				DB:        conf.DedupRedisDB,
+				KeyPrefix: getStringWithDefault(conf.DedupRedisKeyPrefix, "push:pin_claim:"),
+			},
+		},
+		QuotaConfig: &pushcenter.QuotaConfig{
+			Enabled:         conf.QuotaEnabled,
+			DefaultLimit:    conf.QuotaDefaultLimit,
+			OverQuotaAction: getStringWithDefault(conf.QuotaOverQuotaAction, push_service.QuotaActionReject),
+			WarnPercents:    conf.QuotaWarnPercents,
+		},
+		QAConfig: &pushcenter.QAConfig{
+			Enabled:    conf.QAEnabled,
+			MetaIDs:    conf.QAMetaIDs,
+			InboxLimit: getIntWithDefault(conf.QAInboxLimit, pebble_service.DefaultQAInboxLimit),
+		},
+		CatchupConfig: &catchup_service.Config{
+			Enabled:     conf.CatchupEnabled,
+			Endpoint:    conf.CatchupEndpoint,
+			APIKey:      conf.CatchupAPIKey,
+			Timeout:     parseDuration(conf.CatchupTimeout, 10*time.Second),
+			PageSize:    conf.CatchupPageSize,
+			MaxMessages: conf.CatchupMaxMessages,
+		},
+		MembershipConfig: &membership_service.Config{
+			Enabled:      conf.MembershipEnabled,
+			Endpoint:     conf.MembershipEndpoint,
+			APIKey:       conf.MembershipAPIKey,
+			Timeout:      parseDuration(conf.MembershipTimeout, 3*time.Second),
+			CacheTTL:     parseDuration(conf.MembershipCacheTTL, 5*time.Minute),
+			MessageTypes: conf.MembershipMessageTypes,
+			FailClosed:   conf.MembershipFailClosed,
+		},
+		PreviewEnabled: conf.PreviewEnabled,
+		HideEncrypted:  conf.HideEncrypted,
+		CollapseMode:   getStringWithDefault(conf.CollapseMode, pushcenter.CollapseModeNone),
+		TextLimits: &pushcenter.TextLimitsConfig{
+			MaxTitle:   getIntWithDefault(conf.TextMaxTitle, pushcenter.DefaultMaxTitleLength),
+			MaxBody:    getIntWithDefault(conf.TextMaxBody, pushcenter.DefaultMaxBodyLength),
+			MaxName:    getIntWithDefault(conf.TextMaxName, pushcenter.DefaultMaxNameLength),
+			MaxPreview: getIntWithDefault(conf.TextMaxPreview, pushcenter.DefaultMaxPreviewLength),
+		},
+		CandyBagConfig: &pushcenter.CandyBagConfig{
+			Enabled:     conf.CandyBagEnabled,
+			Sound:       getStringWithDefault(conf.CandyBagSound, pushcenter.DefaultCandyBagSound),
+			ChannelID:   getStringWithDefault(conf.CandyBagChannelID, pushcenter.DefaultCandyBagChannelID),
+			Route:       getStringWithDefault(conf.CandyBagRoute, pushcenter.DefaultCandyBagRoute),
+			ClaimWindow: parseDuration(conf.CandyBagClaimWindow, pushcenter.DefaultCandyBagClaimWindow),
+		},
+		GroupingConfig: &pushcenter.GroupingConfig{
+			Enabled: conf.GroupingEnabled,
+			Summary: &pushcenter.SummaryConfig{
+				Enabled:  conf.SummaryEnabled,
+				MinChats: getIntWithDefault(conf.SummaryMinChats, pushcenter.DefaultSummaryMinChats),
+				Window:   parseDuration(conf.SummaryWindow, pushcenter.DefaultSummaryWindow),
+			},
+		},
+		ActionsConfig: &pushcenter.ActionsConfig{
+			Enabled: conf.ActionsEnabled,
+		},
+		TranslationConfig: &translate_service.Config{
+			Enabled:  conf.TranslationEnabled,
+			Provider: getStringWithDefault(conf.TranslationProvider, translate_service.ProviderLibreTranslate),
+			Endpoint: conf.TranslationEndpoint,
+			APIKey:   conf.TranslationAPIKey,
+			Timeout:  parseDuration(conf.TranslationTimeout, 3*time.Second),
+			CacheTTL: parseDuration(conf.TranslationCacheTTL, 24*time.Hour),
+		},
+		BackupConfig: &backup_service.Config{
+			Dir:      getStringWithDefault(conf.BackupDir, "./data/backups"),
+			Schedule: conf.BackupSchedule,
+			Interval: parseDuration(conf.BackupInterval, 24*time.Hour),
+			Keep:     getIntWithDefault(conf.BackupKeep, 7),
+		},
+		HandoffConfig: &handoff_service.Config{
+			Enabled:      conf.HandoffEnabled,
+			Mode:         getStringWithDefault(conf.HandoffMode, handoff_service.ModeHandoff),
+			Backend:      getStringWithDefault(conf.HandoffBackend, handoff_service.BackendFile),
+			Dir:          getStringWithDefault(conf.HandoffDir, "./data/handoff"),
+			InstanceID:   conf.HandoffInstanceID,
+			LeaseTTL:     parseDuration(conf.HandoffLeaseTTL, 15*time.Second),
+			PollInterval: parseDuration(conf.HandoffPollInterval, time.Second),
+			BufferSize:   getIntWithDefault(conf.HandoffBufferSize, 1000),
+			BufferWindow: parseDuration(conf.HandoffBufferWindow, 2*time.Minute),
+			Redis: lock_service.RedisConfig{
+				Addr:     conf.HandoffRedisAddr,
+				Password: conf.HandoffRedisPass,
+				DB:       conf.HandoffRedisDB,
+				Key:      conf.HandoffRedisKey,
+			},
+		},
+		WebhookConfig: &webhook_service.Config{
+			Enabled:    conf.WebhookEnabled,
+			Timeout:    parseDuration(conf.WebhookTimeout, 10*time.Second),
+			MaxRetries: getIntWithDefault(conf.WebhookMaxRetries, 3),
+			BaseDelay:  parseDuration(conf.WebhookBaseDelay, 2*time.Second),
+			QueueSize:  getIntWithDefault(conf.WebhookQueueSize, 1000),
+			Workers:    getIntWithDefault(conf.WebhookWorkers, 4),
+		},
+		SamplingConfig: &sampling_service.Config{
+			Enabled:      conf.SamplingEnabled,
+			Rate:         conf.SamplingRate,
+			WebhookURL:   conf.SamplingWebhookURL,
+			Secret:       conf.SamplingSecret,
+			Timeout:      parseDuration(conf.SamplingTimeout, 5*time.Second),
+			QueueSize:    getIntWithDefault(conf.SamplingQueueSize, 100),
+			RedactFields: conf.SamplingRedactFields,
+		},
+		RoutingRules: buildRoutingRules(),
 	}
 
-	// 4. 创建推送中心实例
-	pushCenter := pushcenter.NewPushCenter(pushCenterConfig)
+	return pushCenterConfig
+}
 
-	// 5. 初始化推送中心
-	if err := pushCenter.Initialize(); err != nil {
-		log.Fatalf("❌ 初始化推送中心失败: %v", err)
+// buildRoutingRules 根据配置文件构建通知路由规则
+func buildRoutingRules() []models.RoutingRule {
+	rules := make([]models.RoutingRule, 0, len(conf.RoutingRules))
+	for _, rule := range conf.RoutingRules {
+		rules = append(rules, models.RoutingRule{
+			Name:          rule.Name,
+			MessageTypes:  rule.MessageTypes,
+			ChatInfoTypes: rule.ChatInfoTypes,
+			Mention:       rule.Mention,
+			DataFields:    rule.DataFields,
+			Priority:      rule.Priority,
+			Sound:         rule.Sound,
+			TTL:           rule.TTL,
+			ChannelID:     rule.ChannelID,
+			Providers:     rule.Providers,
+		})
 	}
+	return rules
+}
 
-	// 6. 创建并注册 Expo 推送提供者
-	expoConfig := &expo_service.Config{
+// buildExpoConfig 根据配置文件构建 Expo 推送提供者配置
+func buildExpoConfig() *expo_service.Config {
+	return &expo_service.Config{
 		AccessToken:     conf.ExpoAccessToken, // 🔑 添加 Access Token
 		Timeout:         parseDuration(conf.ExpoTimeout, 30*time.Second),
 		MaxRetries:      getIntWithDefault(conf.ExpoMaxRetries, 3),
@@ -74,36 +461,64 @@ func initPushCenter() {
 		DefaultPriority: getStringWithDefault(conf.ExpoDefaultPriority, "normal"),
 		BatchSize:       getIntWithDefault(conf.ExpoBatchSize, 100),
 		MaxConcurrency:  getIntWithDefault(conf.ExpoMaxConcurrency, 6),
+		BaseURL:         conf.ExpoBaseURL,
+		Egress:          buildEgressConfig(conf.ExpoEgress),
 	}
+}
 
-	if err := pushCenter.GetPushManager().RegisterExpoProvider(expoConfig); err != nil {
-		log.Printf("⚠️ 注册 Expo 推送提供者失败: %v", err)
-	} else {
-		log.Printf("✅ 已注册 Expo 推送提供者")
+// buildEgressConfig 根据配置文件构建推送平台客户端的出站连接配置
+func buildEgressConfig(egress conf.EgressConf) *egress_service.Config {
+	return &egress_service.Config{
+		ProxyURL:           egress.ProxyURL,
+		CAFile:             egress.CAFile,
+		ClientCertFile:     egress.ClientCertFile,
+		ClientKeyFile:      egress.ClientKeyFile,
+		InsecureSkipVerify: egress.InsecureSkipVerify,
 	}
+}
 
-	// 7. 启动推送中心
-	go func() {
-		if err := pushCenter.Run(); err != nil {
-			log.Fatalf("❌ 启动推送中心失败: %v", err)
-		}
-	}()
-
-	// 8. 等待推送中心启动
-	time.Sleep(2 * time.Second)
+// buildMacOSConfig 根据配置文件构建 macOS 桌面客户端的 APNs 配置
+func buildMacOSConfig() *apns_service.Config {
+	return &apns_service.Config{
+		TeamID:     conf.MacOSTeamID,
+		KeyID:      conf.MacOSKeyID,
+		KeyFile:    conf.MacOSKeyFile,
+		BundleID:   conf.MacOSBundleID,
+		Production: conf.MacOSProduction,
+		Timeout:    parseDuration(conf.MacOSTimeout, 10*time.Second),
+		Egress:     buildEgressConfig(conf.MacOSEgress),
+	}
+}
 
-	if pushCenter.IsRunning() {
-		log.Printf("✅ 推送中心已成功启动")
-		log.Printf("🔗 Socket 服务器: %s", conf.SocketServerURL)
-		log.Printf("🗄️ 数据库路径: %s", conf.PushCenterDBPath)
-		log.Printf("🔑 SocketExtraPushAuthKey: %s", conf.SocketExtraPushAuthKey)
-	} else {
-		log.Printf("⚠️ 推送中心启动状态检查失败")
+// buildWNSConfig 根据配置文件构建 Windows 桌面客户端的 WNS 配置
+func buildWNSConfig() *wns_service.Config {
+	return &wns_service.Config{
+		PackageSID:   conf.WNSPackageSID,
+		ClientSecret: conf.WNSClientSecret,
+		Timeout:      parseDuration(conf.WNSTimeout, 10*time.Second),
+		Egress:       buildEgressConfig(conf.WNSEgress),
 	}
+}
 
-	// 注册优雅关闭处理
-	// 注意：这里只是示例，实际项目中可能需要更完善的信号处理
-	log.Printf("💡 提示：推送中心将在应用程序退出时自动关闭")
+// buildEmailConfig 根据配置文件构建邮件兜底提供者配置
+func buildEmailConfig() *email_service.Config {
+	return &email_service.Config{
+		Backend:       getStringWithDefault(conf.EmailBackend, email_service.BackendSMTP),
+		From:          conf.EmailFrom,
+		FromName:      conf.EmailFromName,
+		SubjectPrefix: conf.EmailSubjectPrefix,
+		Timeout:       parseDuration(conf.EmailTimeout, 10*time.Second),
+		SMTP: email_service.SMTPConfig{
+			Host:     conf.EmailSMTPHost,
+			Port:     getIntWithDefault(conf.EmailSMTPPort, 587),
+			Username: conf.EmailSMTPUsername,
+			Password: conf.EmailSMTPPassword,
+		},
+		SendGrid: email_service.SendGridConfig{
+			APIKey:   conf.EmailSendGridAPIKey,
+			Endpoint: getStringWithDefault(conf.EmailSendGridEndpoint, email_service.DefaultSendGridEndpoint),
+		},
+	}
 }
 
 // 辅助函数：解析时间间隔字符串
@@ -146,7 +561,13 @@ func getIntWithDefault(value, defaultValue int) int {
 // @name X-API-KEY
 func main() {
 	var env string
+	var selftest bool
+	var selftestTimeout time.Duration
+	var migrateKeyspace bool
 	flag.StringVar(&env, "env", "mainnet", "env config: testnet, mainnet")
+	flag.BoolVar(&selftest, "selftest", false, "run startup self-test, print a JSON report and exit (exit code 1 on failure)")
+	flag.DurationVar(&selftestTimeout, "selftest-timeout", 15*time.Second, "timeout for each self-test check")
+	flag.BoolVar(&migrateKeyspace, "migrate-keyspace", false, "move push_center.db_path from one Pebble instance per collection to the shared keyspace layout and exit (stop the service first)")
 	flag.Parse()
 
 	switch env {
@@ -158,11 +579,104 @@ func main() {
 		conf.SystemEnvironmentEnum = conf.ExampleEnvironmentEnum
 	}
 
+	if selftest {
+		os.Exit(runSelfTest(selftestTimeout))
+	}
+	if migrateKeyspace {
+		os.Exit(runKeyspaceMigration())
+	}
+
 	conf.InitConfig("")
 
 	fmt.Printf("run push-base-service service, env: %s\n", env)
 
+	shutdownTracing := initTracing()
+	defer shutdownTracing()
+
 	initPushCenter()
+	initGRPC()
+	initConfigReload()
 
 	controller.Run()
 }
+
+// runKeyspaceMigration 将数据目录从每个集合一个 Pebble 实例迁移到共享布局，结果以 JSON 写到标准输出，返回进程退出码
+// 迁移完成后需要将 push_center.keyspace 设置为 shared 再启动服务
+func runKeyspaceMigration() int {
+	conf.InitConfig("")
+
+	dbPath := buildPushCenterConfig().PebbleConfig.DBPath
+	result, err := pebble_service.MigrateToSharedKeyspace(dbPath)
+	if err != nil {
+		log.Printf("❌ 迁移集合存储布局失败: %v", err)
+		return 1
+	}
+
+	data, _ := json.MarshalIndent(result, "", "  ")
+	fmt.Println(string(data))
+	log.Printf("✅ 迁移完成，请将 push_center.keyspace 设置为 %s 后重新启动服务", pebble_service.KeyspaceShared)
+	return 0
+}
+
+// initTracing 按配置开启 OpenTelemetry 链路追踪，返回退出时导出剩余 span 的关闭函数
+func initTracing() func() {
+	shutdown, err := tracing_service.Init(&tracing_service.Config{
+		Enabled:       conf.TracingEnabled,
+		Endpoint:      conf.TracingEndpoint,
+		URLPath:       conf.TracingURLPath,
+		Insecure:      conf.TracingInsecure,
+		Headers:       conf.TracingHeaders,
+		ServiceName:   conf.TracingServiceName,
+		SampleRate:    conf.TracingSampleRate,
+		InjectPayload: conf.TracingInjectPayload,
+	})
+	if err != nil {
+		log.Printf("⚠️ 开启链路追踪失败: %v", err)
+	}
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdown(ctx); err != nil {
+			log.Printf("⚠️ 关闭链路追踪失败: %v", err)
+		}
+	}
+}
+
+// initGRPC 按配置启动 gRPC 接口，推送中心启用时将推送结果分发给 StreamPushResults 的订阅者
+func initGRPC() {
+	if !conf.GRPCEnabled {
+		return
+	}
+
+	server := grpcapi.NewServer(&grpcapi.Config{
+		Port:         conf.GRPCPort,
+		StreamBuffer: conf.GRPCStreamBuffer,
+	})
+	if manager := push_service.GetGlobalManager(); manager != nil {
+		manager.AddResultListener(server.HandleResult)
+	}
+	if err := server.Start(); err != nil {
+		log.Fatalf("❌ 启动 gRPC 接口失败: %v", err)
+	}
+}
+
+// initConfigReload 开启配置热加载：收到 SIGHUP 或调用管理接口时重新读取配置文件，
+// 开启 config_reload.watch 时保存配置文件后自动加载
+func initConfigReload() {
+	conf.OnReload(auth.ResetRateLimiter)
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+	go func() {
+		for range signals {
+			log.Printf("🔄 收到 SIGHUP，重新加载配置文件")
+			if _, err := conf.ReloadConfig(); err != nil {
+				log.Printf("❌ 重新加载配置文件失败: %v", err)
+			}
+		}
+	}()
+
+	if conf.ConfigWatch {
+		conf.WatchConfig()
+	}
+}
@@ -1,3 +1,5 @@
+//go:build !nomysql
+
 package major
 
 import (
@@ -8,6 +10,9 @@ import (
 	"push-base-service/conf"
 )
 
+// MySQLEnabled 当前构建是否包含 MySQL 支持（使用 nomysql 构建标签时为 false）
+const MySQLEnabled = true
+
 var (
 	db    *gorm.DB
 	sqlDB *sql.DB
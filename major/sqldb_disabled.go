@@ -0,0 +1,12 @@
+//go:build nomysql
+
+package major
+
+import "fmt"
+
+// MySQLEnabled 当前构建是否包含 MySQL 支持（使用 nomysql 构建标签时为 false）
+const MySQLEnabled = false
+
+func InitSqlConfig() {
+	panic(fmt.Errorf("DB init error 当前构建未包含 MySQL 支持（nomysql）"))
+}
@@ -0,0 +1,18 @@
+package models
+
+// API Key 权限范围：admin 可访问所有接口，write 包含 read
+const (
+	APIKeyScopeRead  = "read"  // 查询类接口
+	APIKeyScopeWrite = "write" // 修改令牌/屏蔽/偏好、发送推送等接口
+	APIKeyScopeAdmin = "admin" // 管理类接口
+)
+
+// APIKeyRecord 通过管理接口创建的 API Key，只保存 Key 的哈希
+type APIKeyRecord struct {
+	Name        string   `json:"name"`               // Key 名称
+	KeyHash     string   `json:"keyHash"`            // Key 的 SHA-256
+	Fingerprint string   `json:"fingerprint"`        // Key 的 SHA-256 前 8 位，用于核对而不暴露 Key
+	Scopes      []string `json:"scopes"`             // 权限范围
+	TenantID    string   `json:"tenantId,omitempty"` // 调用方所属租户，通过该 Key 发送的推送计入该租户的配额
+	CreatedAt   int64    `json:"createdAt"`          // 创建时间
+}
@@ -0,0 +1,18 @@
+package models
+
+// PushAuditEntry 一条外发通知的审计记录，供客服排查"为什么没收到推送"
+// 只保存通知内容的哈希，不保存推送令牌
+type PushAuditEntry struct {
+	ID        string `json:"id"`                  // 记录ID（按时间递增）
+	MetaID    string `json:"metaId"`              // 接收用户
+	TenantID  string `json:"tenantId,omitempty"`  // 所属租户ID
+	PinID     string `json:"pinId,omitempty"`     // 关联的消息PIN ID
+	Title     string `json:"title"`               // 通知标题
+	BodyHash  string `json:"bodyHash,omitempty"`  // 通知内容的 SHA-256（十六进制），静默推送为空
+	Provider  string `json:"provider"`            // 推送平台
+	Success   bool   `json:"success"`             // 推送平台是否受理
+	Simulated bool   `json:"simulated,omitempty"` // 演练模式下的模拟推送
+	ReceiptID string `json:"receiptId,omitempty"` // 回执ID
+	Error     string `json:"error,omitempty"`     // 失败原因
+	Timestamp int64  `json:"timestamp"`           // 推送时间 (Unix 毫秒)
+}
@@ -0,0 +1,14 @@
+package models
+
+// BackupManifest 备份归档清单，写入归档根目录的 manifest.json
+type BackupManifest struct {
+	CreatedAt   int64    `json:"createdAt"`   // 备份时间
+	Collections []string `json:"collections"` // 包含的集合
+}
+
+// BackupFile 备份目录中的归档文件
+type BackupFile struct {
+	Name      string `json:"name"`      // 文件名
+	Size      int64  `json:"size"`      // 文件大小（字节）
+	CreatedAt int64  `json:"createdAt"` // 创建时间
+}
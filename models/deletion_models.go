@@ -0,0 +1,25 @@
+package models
+
+// UserDataDeletionReport 删除用户数据（被遗忘权请求）的报告，记录各集合中删除的数据
+type UserDataDeletionReport struct {
+	MetaID          string   `json:"metaId"`          // 被删除数据的用户
+	Tokens          []string `json:"tokens"`          // 删除的令牌平台
+	Devices         int      `json:"devices"`         // 删除的设备数
+	BlockedChats    int      `json:"blockedChats"`    // 删除的屏蔽聊天数
+	BlockedSenders  int      `json:"blockedSenders"`  // 删除的屏蔽发送者数
+	AllGroupsBlock  bool     `json:"allGroupsBlock"`  // 是否删除了屏蔽所有群聊设置
+	ChatSounds      int      `json:"chatSounds"`      // 删除的聊天通知声音数
+	Preferences     bool     `json:"preferences"`     // 是否删除了推送偏好
+	Subscription    bool     `json:"subscription"`    // 是否删除了退订原因和历史
+	SuppressionKept bool     `json:"suppressionKept"` // 用户仍处于退订状态，保留了只含退订标记的记录（删除后不会恢复推送）
+	DeliveryRecords int      `json:"deliveryRecords"` // 删除的投递记录数（含提及通知的投递历史）
+	PendingReceipts int      `json:"pendingReceipts"` // 删除的待查询回执数
+	AuditEntries    int      `json:"auditEntries"`    // 删除的推送审计记录数
+	TraceEvents     int      `json:"traceEvents"`     // 删除的推送追踪事件数
+	InboxMessages   int      `json:"inboxMessages"`   // 删除的 QA 收件箱消息数
+	QAAccount       bool     `json:"qaAccount"`       // 是否移除了 QA 账号登记
+	HotUser         bool     `json:"hotUser"`         // 是否从最近活跃用户列表中移除
+	MergeRecords    int      `json:"mergeRecords"`    // 删除的用户合并记录数（源或目标为该用户）
+	Error           string   `json:"error,omitempty"` // 中途失败时的错误，已完成的步骤不会回滚
+	DeletedAt       int64    `json:"deletedAt"`       // 删除时间
+}
@@ -0,0 +1,68 @@
+package models
+
+// 投递记录的推送平台受理状态
+const (
+	DeliveryTicketOK      = "ok"      // 推送平台已受理
+	DeliveryTicketError   = "error"   // 推送平台拒绝或发送失败
+	DeliveryTicketSkipped = "skipped" // 未发送
+)
+
+// 投递记录的最终回执状态（推送平台不提供回执时为空）
+const (
+	DeliveryReceiptPending     = "pending"     // 等待查询回执
+	DeliveryReceiptDelivered   = "delivered"   // 已送达设备
+	DeliveryReceiptFailed      = "failed"      // 送达失败
+	DeliveryReceiptUnavailable = "unavailable" // 回执已过期或推送平台不支持查询
+)
+
+// 未发送的原因
+const (
+	DeliverySkipBlocked      = "blocked"      // 用户屏蔽了该聊天
+	DeliverySkipNotSent      = "not_sent"     // 没有可用令牌、被限流或超出租户配额
+	DeliverySkipPaused       = "paused"       // 用户暂停了通知
+	DeliverySkipOptedOut     = "opted_out"    // 用户关闭了该类通知（如红包通知）
+	DeliverySkipUnsubscribed = "unsubscribed" // 用户退订了全部推送
+)
+
+// DeliveryRecord 一条消息（PIN）对一个接收用户某个平台的投递记录
+type DeliveryRecord struct {
+	PinID         string `json:"pinId"`                   // 消息PIN ID
+	MetaID        string `json:"metaId"`                  // 接收用户
+	Platform      string `json:"platform,omitempty"`      // 推送平台，未发送时为空
+	Attempted     bool   `json:"attempted"`               // 是否尝试推送
+	SkipReason    string `json:"skipReason,omitempty"`    // 未发送的原因
+	TicketStatus  string `json:"ticketStatus"`            // 推送平台受理状态
+	ReceiptID     string `json:"receiptId,omitempty"`     // 回执ID
+	ReceiptStatus string `json:"receiptStatus,omitempty"` // 最终回执状态
+	Error         string `json:"error,omitempty"`         // 失败原因
+	AppVersion    string `json:"appVersion,omitempty"`    // 接收设备推送时的客户端版本
+	OSVersion     string `json:"osVersion,omitempty"`     // 接收设备推送时的系统版本
+	CreatedAt     int64  `json:"createdAt"`               // 推送时间
+	UpdatedAt     int64  `json:"updatedAt"`               // 最后更新时间
+}
+
+// PendingReceipt 等待查询回执的投递记录索引
+type PendingReceipt struct {
+	ReceiptID  string `json:"receiptId"`            // 回执ID
+	PinID      string `json:"pinId"`                // 消息PIN ID
+	MetaID     string `json:"metaId"`               // 接收用户
+	Platform   string `json:"platform"`             // 推送平台
+	AppVersion string `json:"appVersion,omitempty"` // 接收设备的客户端版本，回执指标按此分组
+	OSVersion  string `json:"osVersion,omitempty"`  // 接收设备的系统版本
+	SentAt     int64  `json:"sentAt"`               // 推送时间
+}
+
+// DeliveryBreakdown 按平台、客户端版本和系统分组的投递统计
+// 送达率 = 已送达 / (已送达 + 回执失败 + 受理失败)，等待回执和回执不可用的不计入
+type DeliveryBreakdown struct {
+	Platform     string  `json:"platform"`     // 推送平台
+	AppVersion   string  `json:"appVersion"`   // 客户端版本，未上报时为 unknown
+	OS           string  `json:"os"`           // 系统及主版本，如 iOS 17，未上报时为 unknown
+	Sent         int     `json:"sent"`         // 尝试推送数
+	TicketErrors int     `json:"ticketErrors"` // 推送平台拒绝或发送失败数
+	Delivered    int     `json:"delivered"`    // 回执为已送达的数量
+	Failed       int     `json:"failed"`       // 回执为送达失败的数量
+	Pending      int     `json:"pending"`      // 等待查询回执的数量
+	Unavailable  int     `json:"unavailable"`  // 回执已过期或推送平台不支持查询的数量
+	DeliveryRate float64 `json:"deliveryRate"` // 送达率（0-1），没有已确定结果时为 0
+}
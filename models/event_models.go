@@ -0,0 +1,28 @@
+package models
+
+// 推送流水线事件类型
+const (
+	PipelineEventMessageReceived = "message_received" // 收到聊天消息并解析出接收用户，Count 为接收用户数
+	PipelineEventFiltered        = "filtered"         // 用户被跳过或消息被去重，Reason 为原因（blocked / paused / opted_out / unsubscribed / duplicate）
+	PipelineEventSent            = "sent"             // 推送平台已受理
+	PipelineEventFailed          = "failed"           // 推送平台拒绝或发送失败，Error 为错误信息
+	PipelineEventReceiptUpdated  = "receipt_updated"  // 查询到投递回执，Reason 为回执状态
+)
+
+// PipelineFilterDuplicate 消息已处理过（PIN 已通知或幂等键已占用）时 filtered 事件的原因
+const PipelineFilterDuplicate = "duplicate"
+
+// PipelineEvent 推送流水线实时事件，供运维看板通过 /v1/admin/events 订阅
+type PipelineEvent struct {
+	Type        string `json:"type"`                  // 事件类型
+	PinID       string `json:"pinId,omitempty"`       // 消息PIN ID
+	MetaID      string `json:"metaId,omitempty"`      // 用户MetaID
+	ChatType    string `json:"chatType,omitempty"`    // 聊天类型：private_chat 或 group_chat
+	MessageType string `json:"messageType,omitempty"` // 消息类型
+	Platform    string `json:"platform,omitempty"`    // 推送平台
+	Reason      string `json:"reason,omitempty"`      // 跳过原因或回执状态
+	Error       string `json:"error,omitempty"`       // 失败原因
+	Count       int    `json:"count,omitempty"`       // 接收用户数（message_received）
+	Simulated   bool   `json:"simulated,omitempty"`   // 演练模式下的模拟结果
+	Timestamp   int64  `json:"timestamp"`             // 发生时间 (Unix 毫秒)
+}
@@ -0,0 +1,25 @@
+package models
+
+// 导入导出数据集
+const (
+	DatasetTokens       = "tokens"        // 用户令牌
+	DatasetDevices      = "devices"       // 设备信息
+	DatasetBlockedChats = "blocked_chats" // 屏蔽聊天
+)
+
+// DataExport 用户数据导出内容（JSON 格式），用于在环境之间迁移数据
+type DataExport struct {
+	ExportedAt   int64              `json:"exportedAt"`             // 导出时间
+	UserTokens   []UserPushTokens   `json:"userTokens,omitempty"`   // 用户令牌
+	Devices      []DeviceInfo       `json:"devices,omitempty"`      // 设备信息
+	BlockedChats []UserBlockedChats `json:"blockedChats,omitempty"` // 用户屏蔽聊天
+}
+
+// ImportResult 数据导入结果
+type ImportResult struct {
+	Tokens       int      `json:"tokens"`           // 导入的令牌数（按平台计）
+	Devices      int      `json:"devices"`          // 导入的设备数
+	BlockedChats int      `json:"blockedChats"`     // 导入的屏蔽聊天数
+	Skipped      int      `json:"skipped"`          // 跳过的记录数（无效或已过期）
+	Errors       []string `json:"errors,omitempty"` // 导入失败的记录（最多保留 100 条）
+}
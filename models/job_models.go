@@ -0,0 +1,37 @@
+package models
+
+// 后台任务状态
+const (
+	JobStatusPending   = "pending"   // 等待执行
+	JobStatusRunning   = "running"   // 执行中
+	JobStatusSucceeded = "succeeded" // 执行成功
+	JobStatusFailed    = "failed"    // 执行失败
+	JobStatusCanceled  = "canceled"  // 已取消
+)
+
+// 后台任务类型
+const (
+	JobTypeBackup  = "backup"  // Pebble 备份
+	JobTypeCompact = "compact" // Pebble 手动压缩
+)
+
+// Job 后台任务，记录执行进度和结果
+type Job struct {
+	ID              string      `json:"id"`                        // 任务唯一标识（按创建时间有序）
+	Type            string      `json:"type"`                      // 任务类型
+	Status          string      `json:"status"`                    // 任务状态
+	Progress        int         `json:"progress"`                  // 执行进度（0-100）
+	Message         string      `json:"message,omitempty"`         // 当前进度说明
+	Result          interface{} `json:"result,omitempty"`          // 执行结果
+	Error           string      `json:"error,omitempty"`           // 失败原因
+	CancelRequested bool        `json:"cancelRequested,omitempty"` // 是否已请求取消
+	CreatedAt       int64       `json:"createdAt"`                 // 创建时间
+	StartedAt       int64       `json:"startedAt,omitempty"`       // 开始执行时间
+	FinishedAt      int64       `json:"finishedAt,omitempty"`      // 结束时间
+	UpdatedAt       int64       `json:"updatedAt"`                 // 最后更新时间
+}
+
+// Finished 任务是否已结束（成功、失败或取消）
+func (j *Job) Finished() bool {
+	return j.Status == JobStatusSucceeded || j.Status == JobStatusFailed || j.Status == JobStatusCanceled
+}
@@ -0,0 +1,41 @@
+package models
+
+// 合并冲突时保留哪一方的数据
+const (
+	MergePreferTarget = "target" // 保留目标 MetaID 的数据（默认）
+	MergePreferSource = "source" // 保留源 MetaID 的数据
+)
+
+// 合并冲突类型
+const (
+	MergeConflictToken       = "token"        // 同一平台两个用户都有令牌
+	MergeConflictTenant      = "tenant"       // 两个用户属于不同租户
+	MergeConflictPreferences = "preferences"  // 两个用户的偏好不同
+	MergeConflictBlockedChat = "blocked_chat" // 两个用户屏蔽了同一聊天但截止时间不同
+)
+
+// UserMergeRecord 用户合并审计记录：源 MetaID 的推送状态合并到目标 MetaID
+type UserMergeRecord struct {
+	ID                 string          `json:"id"`                 // 记录ID（按合并时间递增）
+	SourceMetaID       string          `json:"sourceMetaId"`       // 被合并的旧 MetaID
+	TargetMetaID       string          `json:"targetMetaId"`       // 保留的 MetaID
+	Prefer             string          `json:"prefer"`             // 冲突时保留的一方：target / source
+	Reason             string          `json:"reason,omitempty"`   // 合并原因（如身份服务事件ID）
+	MovedTokens        []string        `json:"movedTokens"`        // 迁移到目标用户的令牌平台
+	TenantID           string          `json:"tenantId,omitempty"` // 合并后目标用户的租户
+	PreferencesMerged  bool            `json:"preferencesMerged"`  // 是否迁移了偏好
+	BlockedChatsMerged int             `json:"blockedChatsMerged"` // 迁移的屏蔽聊天数
+	InboxMessagesMoved int             `json:"inboxMessagesMoved"` // 迁移的 QA 收件箱消息数
+	QAAccountMerged    bool            `json:"qaAccountMerged"`    // 源用户是否为 QA 账号（目标用户随之成为 QA 账号）
+	Conflicts          []MergeConflict `json:"conflicts"`          // 冲突及处理结果
+	Error              string          `json:"error,omitempty"`    // 合并中途失败时的错误，已完成的步骤不会回滚
+	MergedAt           int64           `json:"mergedAt"`           // 合并时间
+}
+
+// MergeConflict 合并冲突及处理结果
+type MergeConflict struct {
+	Type   string `json:"type"`   // 冲突类型
+	Key    string `json:"key"`    // 冲突项，如平台名、聊天ID
+	Kept   string `json:"kept"`   // 保留的一方：target / source / merged
+	Detail string `json:"detail"` // 说明（不包含令牌原文）
+}
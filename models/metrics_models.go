@@ -0,0 +1,22 @@
+package models
+
+// TokenDailyMetrics 每日令牌增长/流失统计（按平台）
+type TokenDailyMetrics struct {
+	Date          string `json:"date"`          // 日期 (YYYY-MM-DD, UTC)
+	Platform      string `json:"platform"`      // 平台 (expo, fcm, apns)
+	Registrations int64  `json:"registrations"` // 新注册令牌数
+	Removals      int64  `json:"removals"`      // 移除令牌数
+	Transfers     int64  `json:"transfers"`     // 令牌在用户间转移次数
+	UpdatedAt     int64  `json:"updatedAt"`     // 最后更新时间
+}
+
+// TokenGCStats 过期令牌清理统计
+type TokenGCStats struct {
+	LastRunAt   int64 `json:"lastRunAt"`   // 最近一次清理时间
+	LastScanned int64 `json:"lastScanned"` // 最近一次扫描的用户数
+	LastMarked  int64 `json:"lastMarked"`  // 最近一次标记为过期的令牌数
+	LastPurged  int64 `json:"lastPurged"`  // 最近一次删除的令牌数
+	StaleTokens int64 `json:"staleTokens"` // 最近一次清理后已停止推送、等待删除的令牌数
+	TotalMarked int64 `json:"totalMarked"` // 累计标记为过期的令牌数
+	TotalPurged int64 `json:"totalPurged"` // 累计删除的令牌数
+}
@@ -0,0 +1,48 @@
+package models
+
+// UserPreferences 用户推送偏好设置
+type UserPreferences struct {
+	MetaID             string   `json:"metaId" binding:"required"` // 用户MetaID
+	Locale             string   `json:"locale"`                    // 用户语言区域，如 en、zh-CN、ja
+	TranslatePreviews  bool     `json:"translatePreviews"`         // 是否将消息预览翻译为用户语言
+	HidePreviews       bool     `json:"hidePreviews"`              // 是否隐藏通知中的消息内容和发送者（只显示"New message"）
+	MuteCandyBags      bool     `json:"muteCandyBags"`             // 是否关闭红包（Candy Bag）通知
+	MuteFriendRequests bool     `json:"muteFriendRequests"`        // 是否关闭好友请求通知
+	MutePayments       bool     `json:"mutePayments"`              // 是否关闭付款通知
+	PausedUntil        int64    `json:"pausedUntil,omitempty"`     // 暂停通知至该时间（Unix 秒），到期自动恢复；0 表示未暂停
+	PauseSummary       bool     `json:"pauseSummary,omitempty"`    // 恢复时是否发送"错过 N 条消息"的汇总通知
+	PausedMissed       int      `json:"pausedMissed,omitempty"`    // 暂停期间未推送的消息数
+	Categories         []string `json:"categories,omitempty"`      // 客户端已注册的通知类别（带操作按钮），只有注册过的类别才在通知中设置 categoryId
+	UpdatedAt          int64    `json:"updatedAt"`                 // 最后更新时间
+}
+
+// NotificationCategory 可操作通知的类别，客户端按此向系统注册类别和操作按钮
+type NotificationCategory struct {
+	ID      string               `json:"id"`      // 类别ID：message、mention、candy_bag
+	Actions []NotificationAction `json:"actions"` // 通知上显示的操作按钮
+}
+
+// NotificationAction 通知上的操作按钮
+type NotificationAction struct {
+	ID          string `json:"id"`                    // 操作ID：reply、mark_read、mute、open
+	Title       string `json:"title"`                 // 按钮文字
+	TextInput   bool   `json:"textInput,omitempty"`   // 是否弹出输入框（直接回复）
+	Foreground  bool   `json:"foreground,omitempty"`  // 是否打开应用处理
+	Destructive bool   `json:"destructive,omitempty"` // 是否为破坏性操作（iOS 显示为红色）
+}
+
+// CachedTranslation 已缓存的消息预览翻译，按 (消息, 语言) 缓存
+type CachedTranslation struct {
+	MessageID string `json:"messageId"` // 消息ID（pinId 或预览内容哈希）
+	Locale    string `json:"locale"`    // 目标语言
+	Text      string `json:"text"`      // 翻译结果
+	Provider  string `json:"provider"`  // 翻译服务提供者
+	CreatedAt int64  `json:"createdAt"` // 创建时间
+	ExpiresAt int64  `json:"expiresAt"` // 过期时间
+}
+
+// HotUserSet 最近活跃用户列表，启动时用于预热令牌缓存
+type HotUserSet struct {
+	MetaIDs   []string `json:"metaIds"`   // 用户MetaID（最近活跃的在前）
+	UpdatedAt int64    `json:"updatedAt"` // 保存时间
+}
@@ -1,9 +1,20 @@
 package models
 
+import "encoding/json"
+
 type UserPushTokens struct {
 	MetaID    string            `json:"metaId" binding:"required"` // 用户唯一标识
 	Tokens    map[string]string `json:"tokens"`                    // 平台->令牌映射 {"expo": "ExponentPushToken[...]", "fcm": "fcm_token_123"}
+	TenantID  string            `json:"tenantId,omitempty"`        // 所属租户ID（多租户部署时使用）
 	UpdatedAt int64             `json:"updatedAt"`                 // 最后更新时间
+
+	StaleTokens map[string]*StaleToken `json:"staleTokens,omitempty"` // 长期未刷新、已停止推送的令牌（平台->令牌），重新注册时恢复，超过保留期后删除
+}
+
+// StaleToken 被过期令牌清理标记、不再推送的令牌
+type StaleToken struct {
+	Token   string `json:"token"`   // 令牌
+	StaleAt int64  `json:"staleAt"` // 标记为过期的时间
 }
 
 // DeviceInfo 设备信息结构
@@ -12,6 +23,36 @@ type DeviceInfo struct {
 	Platform  string `json:"platform" binding:"required"` // 平台 (expo, fcm, apns)
 	MetaID    string `json:"metaId" binding:"required"`   // 关联的用户ID
 	UpdatedAt int64  `json:"updatedAt"`                   // 最后更新时间
+	DeviceMetadata
+	LastSeenAt int64 `json:"lastSeenAt,omitempty"` // 最后一次注册或刷新令牌的时间
+}
+
+// DeviceMetadata 客户端注册令牌时上报的设备信息，均为可选
+type DeviceMetadata struct {
+	AppVersion  string `json:"appVersion,omitempty"`  // 客户端版本，如 1.4.2
+	OSVersion   string `json:"osVersion,omitempty"`   // 系统版本，如 iOS 17.5
+	DeviceModel string `json:"deviceModel,omitempty"` // 设备型号，如 iPhone15,2
+	Locale      string `json:"locale,omitempty"`      // 设备语言区域，如 zh-CN，用户未设置语言偏好时用于预览翻译
+	Timezone    string `json:"timezone,omitempty"`    // 设备时区（IANA 名称），如 Asia/Shanghai
+}
+
+// Merge 用非空字段覆盖当前设备信息
+func (m *DeviceMetadata) Merge(other DeviceMetadata) {
+	if other.AppVersion != "" {
+		m.AppVersion = other.AppVersion
+	}
+	if other.OSVersion != "" {
+		m.OSVersion = other.OSVersion
+	}
+	if other.DeviceModel != "" {
+		m.DeviceModel = other.DeviceModel
+	}
+	if other.Locale != "" {
+		m.Locale = other.Locale
+	}
+	if other.Timezone != "" {
+		m.Timezone = other.Timezone
+	}
 }
 
 // BlockedChat 屏蔽聊天信息结构
@@ -21,6 +62,12 @@ type BlockedChat struct {
 	ChatType  string `json:"chatType"`                  // 聊天类型 (group, private)
 	BlockedAt int64  `json:"blockedAt"`                 // 屏蔽时间
 	Reason    string `json:"reason"`                    // 屏蔽原因
+	MuteUntil int64  `json:"muteUntil,omitempty"`       // 静音截止时间 (Unix 秒)，0 表示永久屏蔽
+}
+
+// IsExpired 临时静音是否已过期，永久屏蔽永不过期
+func (b *BlockedChat) IsExpired(now int64) bool {
+	return b.MuteUntil > 0 && b.MuteUntil <= now
 }
 
 // UserBlockedChats 用户屏蔽聊天列表结构
@@ -30,6 +77,60 @@ type UserBlockedChats struct {
 	UpdatedAt    int64         `json:"updatedAt"`                 // 最后更新时间
 }
 
+// BlockedChatsPage 用户屏蔽聊天分页结果，按聊天ID排序
+type BlockedChatsPage struct {
+	UserID       string        `json:"userId"`               // 用户ID
+	BlockedChats []BlockedChat `json:"blockedChats"`         // 当前页的屏蔽聊天
+	PageSize     int           `json:"pageSize"`             // 每页大小
+	HasNext      bool          `json:"hasNext"`              // 是否有下一页
+	NextCursor   string        `json:"nextCursor,omitempty"` // 下一页游标（有下一页时返回）
+}
+
+// BlockedChatsBatchResult 批量屏蔽/取消屏蔽结果
+type BlockedChatsBatchResult struct {
+	Blocked   int `json:"blocked"`   // 屏蔽（含更新截止时间）的聊天数
+	Unblocked int `json:"unblocked"` // 取消屏蔽的聊天数
+}
+
+// ChatSoundSilent 聊天通知静音（不播放声音，仍正常显示通知）
+const ChatSoundSilent = "silent"
+
+// ChatSound 用户为某个聊天设置的通知声音
+type ChatSound struct {
+	UserID    string `json:"userId"`             // 用户ID
+	ChatID    string `json:"chatId"`             // 群ID或私聊ID
+	ChatType  string `json:"chatType,omitempty"` // 聊天类型 (group, private)
+	Sound     string `json:"sound"`              // 客户端内置的声音文件名，silent 表示静音
+	UpdatedAt int64  `json:"updatedAt"`          // 设置时间
+}
+
+// UserChatSounds 用户的聊天通知声音列表
+type UserChatSounds struct {
+	UserID     string      `json:"userId"`     // 用户ID
+	ChatSounds []ChatSound `json:"chatSounds"` // 按设置时间排序的聊天声音
+}
+
+// BlockedSender 用户屏蔽的消息发送者，屏蔽后不再收到该发送者在任何私聊和群聊中的消息推送
+type BlockedSender struct {
+	UserID    string `json:"userId"`           // 用户ID
+	SenderID  string `json:"senderId"`         // 被屏蔽的发送者MetaID
+	Reason    string `json:"reason,omitempty"` // 屏蔽原因
+	BlockedAt int64  `json:"blockedAt"`        // 屏蔽时间
+}
+
+// UserBlockedSenders 用户屏蔽的发送者列表
+type UserBlockedSenders struct {
+	UserID         string          `json:"userId"`         // 用户ID
+	BlockedSenders []BlockedSender `json:"blockedSenders"` // 按屏蔽时间排序的发送者
+}
+
+// AllGroupsBlock 用户"屏蔽所有群聊"设置，开启后不再收到任何群聊消息推送
+type AllGroupsBlock struct {
+	UserID    string `json:"userId"`              // 用户ID
+	Blocked   bool   `json:"blocked"`             // 是否屏蔽所有群聊
+	BlockedAt int64  `json:"blockedAt,omitempty"` // 开启时间
+}
+
 // NotifiedPin 已通知的PIN信息结构
 type NotifiedPin struct {
 	PinID       string `json:"pinId" binding:"required"` // PIN唯一标识
@@ -38,3 +139,64 @@ type NotifiedPin struct {
 	NotifiedAt  int64  `json:"notifiedAt"`               // 通知时间
 	MessageHash string `json:"messageHash"`              // 消息哈希（用于去重）
 }
+
+// IdempotencyRecord 幂等键记录结构
+type IdempotencyRecord struct {
+	Key       string                 `json:"key" binding:"required"` // 幂等键（带来源前缀，如 api:xxx、pin:xxx、msg:xxx）
+	Source    string                 `json:"source"`                 // 来源 (api, socket)
+	Result    map[string]interface{} `json:"result,omitempty"`       // 首次处理的结果摘要（处理中时为空）
+	CreatedAt int64                  `json:"createdAt"`              // 创建时间
+	ExpiresAt int64                  `json:"expiresAt"`              // 过期时间
+	// ClaimExpiresAt 处理中的占用截止时间：超过该时间仍未记录结果，说明处理中途退出，键可以被重新占用
+	ClaimExpiresAt int64 `json:"claimExpiresAt,omitempty"`
+}
+
+// IntakeEntry 进件日志条目：收到后、处理前写入的原始聊天消息，推送完成后删除
+type IntakeEntry struct {
+	ID         string          `json:"id"`         // 条目ID（按接收时间排序）
+	Message    json.RawMessage `json:"message"`    // 原始聊天消息（ChatNotificationMessage 的 JSON）
+	ReceivedAt int64           `json:"receivedAt"` // 接收时间
+	Attempts   int             `json:"attempts"`   // 重启后恢复处理的次数
+}
+
+// HandoffState 部署交接时旧实例交给接管实例的本地状态：新旧实例各自使用独立的数据目录，
+// 这些记录只保存在本地 Pebble 中，不通过共享的存储后端同步
+type HandoffState struct {
+	InstanceID         string               `json:"instanceId"`                   // 交出状态的实例ID
+	ExportedAt         int64                `json:"exportedAt"`                   // 导出时间
+	ScheduledPushes    []*ScheduledPush     `json:"scheduledPushes,omitempty"`    // 尚未发送的定时推送任务
+	Intake             []*IntakeEntry       `json:"intake,omitempty"`             // 未确认的进件日志
+	IdempotencyRecords []*IdempotencyRecord `json:"idempotencyRecords,omitempty"` // 未过期的幂等键
+}
+
+// QuarantinedMessage 隔离的上游消息：严格解析失败（未知字段、类型不匹配等）或结构校验失败的原始消息，保留备查。
+// 严格解析失败的消息仍照常推送；结构校验失败的消息未推送（Rejected），可在修复后重新入队
+type QuarantinedMessage struct {
+	ID            string          `json:"id"`                                  // 条目ID（按隔离时间排序）
+	ChatType      string          `json:"chatType"`                            // 聊天类型
+	Message       json.RawMessage `json:"message" swaggertype:"object"`        // 原始消息内容（ExtraServiceMessage.Message 的 JSON）
+	Data          json.RawMessage `json:"data,omitempty" swaggertype:"object"` // 完整的消息数据（ExtraServiceMessage 的 JSON，含接收用户），仅未推送的消息保存，用于重新入队
+	Error         string          `json:"error"`                               // 解析或校验错误
+	Rejected      bool            `json:"rejected"`                            // 是否因结构校验失败未推送
+	QuarantinedAt int64           `json:"quarantinedAt"`                       // 隔离时间 (Unix 毫秒)
+}
+
+// CatchupCheckpoint 上游消息补拉检查点：从该上游收到的最新消息，重新连接后从此处开始补拉断线期间的消息
+type CatchupCheckpoint struct {
+	Upstream  string `json:"upstream"`  // 上游名称
+	Timestamp int64  `json:"timestamp"` // 最新消息的时间戳（消息内容中的 timestamp，单位与上游一致）
+	PinId     string `json:"pinId"`     // 最新消息的 PIN ID
+	UpdatedAt int64  `json:"updatedAt"` // 更新时间 (Unix 秒)
+}
+
+// ThrottleWindow 推送限流滑动窗口记录
+type ThrottleWindow struct {
+	Key  string  `json:"key"`  // 限流键（接收用户 metaId）
+	Hits []int64 `json:"hits"` // 窗口内每次推送的时间 (Unix 毫秒)
+}
+
+// EnabledTypesSetting 通过管理接口设置的启用消息类型，存在时覆盖配置文件 push_center.enabled_types
+type EnabledTypesSetting struct {
+	Types     []string `json:"types"`     // 启用的消息类型
+	UpdatedAt int64    `json:"updatedAt"` // 最后更新时间
+}
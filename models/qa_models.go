@@ -0,0 +1,22 @@
+package models
+
+// QAAccount QA 测试账号，推送写入虚拟收件箱而不是真实设备
+type QAAccount struct {
+	MetaID    string `json:"metaId" binding:"required"` // 用户MetaID
+	Note      string `json:"note"`                      // 备注（如所属测试用例）
+	CreatedAt int64  `json:"createdAt"`                 // 创建时间
+}
+
+// QAInboxMessage QA 虚拟收件箱中的一条推送
+type QAInboxMessage struct {
+	ID         string                 `json:"id"`                 // 消息ID（按接收时间递增）
+	MetaID     string                 `json:"metaId"`             // 接收用户MetaID
+	Title      string                 `json:"title"`              // 通知标题
+	Body       string                 `json:"body"`               // 通知内容
+	Data       map[string]interface{} `json:"data,omitempty"`     // 自定义数据
+	Sound      string                 `json:"sound,omitempty"`    // 声音
+	Badge      *int                   `json:"badge,omitempty"`    // 徽章数字
+	ImageURL   string                 `json:"imageUrl,omitempty"` // 图片URL
+	Priority   string                 `json:"priority,omitempty"` // 优先级
+	ReceivedAt int64                  `json:"receivedAt"`         // 接收时间 (Unix 毫秒)
+}
@@ -0,0 +1,26 @@
+package models
+
+// RoutingRule 通知路由规则：按消息特征匹配，决定通知的优先级、声音、有效期、Android 渠道和推送提供者
+// 匹配条件之间为“且”关系，未设置的条件不参与匹配；规则按顺序匹配，命中第一条即止
+type RoutingRule struct {
+	Name string `json:"name" binding:"required"` // 规则名称（唯一）
+
+	// 匹配条件
+	MessageTypes  []string          `json:"messageTypes,omitempty"`  // 消息类型：private_chat / group_chat / friend_request / payment，空表示不限
+	ChatInfoTypes []int64           `json:"chatInfoTypes,omitempty"` // 聊天信息类型，如 1/23 红包，空表示不限
+	Mention       *bool             `json:"mention,omitempty"`       // 是否为提及通知，不设置表示不限
+	DataFields    map[string]string `json:"dataFields,omitempty"`    // 通知自定义数据字段（支持 message.contentType 形式的嵌套路径），值按字符串比较
+
+	// 路由结果，未设置的项保持默认值
+	Priority  string   `json:"priority,omitempty"`  // 优先级：high / normal
+	Sound     string   `json:"sound,omitempty"`     // 声音
+	TTL       int      `json:"ttl,omitempty"`       // 通知有效期（秒）
+	ChannelID string   `json:"channelId,omitempty"` // Android 通知渠道ID
+	Providers []string `json:"providers,omitempty"` // 限定发送的推送提供者，如 ["expo"]
+}
+
+// RoutingRuleSet 通过管理接口设置的路由规则，存在时覆盖配置文件中的规则
+type RoutingRuleSet struct {
+	Rules     []RoutingRule `json:"rules"`     // 规则列表（按顺序匹配）
+	UpdatedAt int64         `json:"updatedAt"` // 最后更新时间
+}
@@ -0,0 +1,38 @@
+package models
+
+// 定时推送任务状态
+const (
+	ScheduleStatusPending  = "pending"  // 等待发送
+	ScheduleStatusSending  = "sending"  // 发送中
+	ScheduleStatusSent     = "sent"     // 已发送
+	ScheduleStatusFailed   = "failed"   // 发送失败
+	ScheduleStatusCanceled = "canceled" // 已取消
+)
+
+// ScheduledPush 定时推送任务结构
+type ScheduledPush struct {
+	ID        string                 `json:"id" binding:"required"`      // 任务唯一标识
+	MetaIDs   []string               `json:"metaIds" binding:"required"` // 接收用户列表
+	Title     string                 `json:"title"`                      // 通知标题
+	Body      string                 `json:"body"`                       // 通知内容
+	Data      map[string]interface{} `json:"data,omitempty"`             // 自定义数据
+	Sound     string                 `json:"sound,omitempty"`            // 声音
+	Priority  string                 `json:"priority,omitempty"`         // 优先级 (normal/high)
+	SendAt    int64                  `json:"sendAt"`                     // 计划发送时间（Unix 秒）
+	BatchID   string                 `json:"batchId,omitempty"`          // 按当地时间发送时，同一请求拆分出的各任务共用的批次ID
+	Timezones []string               `json:"timezones,omitempty"`        // 按当地时间发送时，该任务覆盖的时区
+	TenantID  string                 `json:"tenantId,omitempty"`         // 创建任务的 API Key 绑定的租户，发送时按该租户计入配额
+	Status    string                 `json:"status"`                     // 任务状态
+	Result    map[string]interface{} `json:"result,omitempty"`           // 发送结果摘要
+	Error     string                 `json:"error,omitempty"`            // 失败原因
+	SentAt    int64                  `json:"sentAt,omitempty"`           // 实际发送时间
+	CreatedAt int64                  `json:"createdAt"`                  // 创建时间
+	UpdatedAt int64                  `json:"updatedAt"`                  // 最后更新时间
+}
+
+// LocalTimeSchedule 按当地时间发送的定时推送：接收用户按时区分组，各组在当地到达指定整点时发送
+type LocalTimeSchedule struct {
+	BatchID   string           `json:"batchId"`   // 批次ID
+	LocalHour int              `json:"localHour"` // 当地发送时间（0-23 点）
+	Jobs      []*ScheduledPush `json:"jobs"`      // 拆分出的定时推送任务，按计划发送时间排序
+}
@@ -0,0 +1,25 @@
+package models
+
+// 退订记录的操作类型
+const (
+	SubscriptionActionUnsubscribe = "unsubscribe" // 退订全部推送
+	SubscriptionActionResubscribe = "resubscribe" // 重新订阅
+)
+
+// SubscriptionEvent 一次退订或重新订阅操作，按时间顺序保留用于合规审计
+type SubscriptionEvent struct {
+	Action string `json:"action"`           // 操作类型：unsubscribe / resubscribe
+	Reason string `json:"reason,omitempty"` // 退订原因
+	Source string `json:"source"`           // 操作来源：user（用户本人）或 api_key:<Key 名称>
+	At     int64  `json:"at"`               // 操作时间
+}
+
+// PushSubscription 用户的推送订阅状态，退订后聊天通知和所有发送接口都不再向该用户推送
+type PushSubscription struct {
+	MetaID         string              `json:"metaId"`                   // 用户MetaID
+	Unsubscribed   bool                `json:"unsubscribed"`             // 是否已退订
+	Reason         string              `json:"reason,omitempty"`         // 最近一次退订的原因
+	UnsubscribedAt int64               `json:"unsubscribedAt,omitempty"` // 最近一次退订时间，重新订阅后保留
+	UpdatedAt      int64               `json:"updatedAt"`                // 最后更新时间
+	History        []SubscriptionEvent `json:"history"`                  // 退订和重新订阅记录
+}
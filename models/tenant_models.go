@@ -0,0 +1,32 @@
+package models
+
+// TenantWebhook 租户投递事件 Webhook 配置
+type TenantWebhook struct {
+	TenantID  string `json:"tenantId" binding:"required"` // 租户ID
+	URL       string `json:"url" binding:"required"`      // 回调地址
+	Secret    string `json:"secret"`                      // 签名密钥（HMAC-SHA256）
+	Enabled   bool   `json:"enabled"`                     // 是否启用
+	CreatedAt int64  `json:"createdAt"`                   // 创建时间
+	UpdatedAt int64  `json:"updatedAt"`                   // 最后更新时间
+}
+
+// TenantQuota 租户每月推送配额
+type TenantQuota struct {
+	TenantID     string `json:"tenantId" binding:"required"` // 租户ID
+	MonthlyLimit int64  `json:"monthlyLimit"`                // 每月推送上限（按设备投递计数），0 表示不限制
+	CreatedAt    int64  `json:"createdAt"`                   // 创建时间
+	UpdatedAt    int64  `json:"updatedAt"`                   // 最后更新时间
+}
+
+// TenantUsage 租户某月的推送用量
+type TenantUsage struct {
+	TenantID      string  `json:"tenantId"`      // 租户ID
+	Month         string  `json:"month"`         // 月份（UTC），如 2026-01
+	Sent          int64   `json:"sent"`          // 已计入配额的投递数（含降级发送）
+	Downgraded    int64   `json:"downgraded"`    // 超出配额后降级发送的投递数
+	Rejected      int64   `json:"rejected"`      // 超出配额被拒绝的投递数
+	Limit         int64   `json:"limit"`         // 当前生效的每月上限，0 表示不限制
+	UsagePercent  float64 `json:"usagePercent"`  // 已用百分比，不限制时为 0
+	WarnedPercent int     `json:"warnedPercent"` // 本月已发出的最高告警阈值
+	UpdatedAt     int64   `json:"updatedAt"`     // 最后更新时间
+}
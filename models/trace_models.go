@@ -0,0 +1,29 @@
+package models
+
+// 推送追踪事件阶段
+const (
+	TraceStageRejected = "rejected" // 不属于该聊天，被接收用户校验剔除
+	TraceStageSkipped  = "skipped"  // 未发送，Detail 为原因（blocked / paused / opted_out / not_sent）
+	TraceStageSent     = "sent"     // 推送平台已受理
+	TraceStageFailed   = "failed"   // 推送平台拒绝或发送失败，Detail 为错误信息
+	TraceStageReceipt  = "receipt"  // 回执结果，Detail 为回执状态
+)
+
+// UserTrace 用户推送追踪：追踪期间该用户的每个推送决策都会记录为 TraceEvent，供排查"为什么没收到推送"
+type UserTrace struct {
+	MetaID    string `json:"metaId"`    // 用户MetaID
+	Note      string `json:"note"`      // 备注（如工单号）
+	Until     int64  `json:"until"`     // 追踪截止时间 (Unix 秒)
+	CreatedAt int64  `json:"createdAt"` // 开始时间
+}
+
+// TraceEvent 一条推送追踪事件
+type TraceEvent struct {
+	ID        string `json:"id"`                 // 事件ID（按时间递增）
+	MetaID    string `json:"metaId"`             // 用户MetaID
+	PinID     string `json:"pinId,omitempty"`    // 消息PIN ID
+	Stage     string `json:"stage"`              // 决策阶段
+	Platform  string `json:"platform,omitempty"` // 推送平台
+	Detail    string `json:"detail,omitempty"`   // 原因、错误或回执状态
+	Timestamp int64  `json:"timestamp"`          // 发生时间 (Unix 毫秒)
+}
@@ -0,0 +1,10 @@
+package models
+
+// VersionInfo 服务版本和构建信息
+type VersionInfo struct {
+	Version      string          `json:"version"`             // 服务版本，构建时注入，未注入时为 dev
+	GoVersion    string          `json:"goVersion"`           // 编译使用的 Go 版本
+	Revision     string          `json:"revision,omitempty"`  // 构建时的 Git 提交
+	BuildTags    string          `json:"buildTags,omitempty"` // 构建标签，如 noredis,nomysql
+	Capabilities map[string]bool `json:"capabilities"`        // 可选集成是否编译进当前构建，如 redis、mysql
+}
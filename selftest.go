@@ -0,0 +1,274 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"push-base-service/conf"
+	"push-base-service/controller/respond"
+	"push-base-service/service/apns_service"
+	"push-base-service/service/dedup_service"
+	"push-base-service/service/disk_service"
+	"push-base-service/service/email_service"
+	"push-base-service/service/membership_service"
+	"push-base-service/service/pebble_service"
+	pushcenter "push-base-service/service/push_center"
+	"push-base-service/service/push_service"
+	"push-base-service/service/selftest_service"
+	"push-base-service/service/storage_service"
+	"push-base-service/service/throttle_service"
+	"push-base-service/service/translate_service"
+	"push-base-service/service/wns_service"
+	"sort"
+	"time"
+)
+
+// runSelfTest 执行启动自检并将 JSON 报告写到标准输出，返回进程退出码（0 通过，1 失败）
+// 用于部署流水线的冒烟检查：加载并校验配置、打开 Pebble 并读写一次、Expo 空跑、上游 Socket 握手
+func runSelfTest(timeout time.Duration) int {
+	// 自检期间的日志和第三方库输出全部转到标准错误，保证标准输出只有报告本身
+	stdout := os.Stdout
+	os.Stdout = os.Stderr
+	defer func() { os.Stdout = stdout }()
+
+	loadErr := loadConfig()
+	checks := []selftest_service.Check{
+		{Name: "config", Run: func(ctx context.Context) error {
+			if loadErr != nil {
+				return loadErr
+			}
+			return validateConfig()
+		}},
+	}
+
+	switch {
+	case loadErr != nil:
+		checks = append(checks, skippedChecks("配置加载失败")...)
+	case !conf.PushCenterEnabled:
+		checks = append(checks, skippedChecks("推送中心未启用")...)
+	default:
+		pushCenterConfig := buildPushCenterConfig()
+		checks = append(checks,
+			selftest_service.NewPebbleCheck(pushCenterConfig.PebbleConfig),
+			selftest_service.NewProviderCheck(buildExpoConfig()),
+			selftest_service.NewSocketCheck(pushCenterConfig.SocketConfig),
+		)
+		for _, socketConfig := range pushCenterConfig.SocketConfigs {
+			checks = append(checks, selftest_service.NewSocketCheck(socketConfig))
+		}
+	}
+
+	report := selftest_service.Run(context.Background(), checks, timeout)
+	if err := report.Write(stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "写出自检报告失败: %v\n", err)
+		return 1
+	}
+	if !report.Passed {
+		return 1
+	}
+	return 0
+}
+
+// loadConfig 加载配置文件，将 InitConfig 的 panic 转为错误
+func loadConfig() (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("加载配置失败: %v", r)
+		}
+	}()
+	conf.InitConfig("")
+	return nil
+}
+
+// skippedChecks 返回以 reason 跳过的依赖推送中心的检查
+func skippedChecks(reason string) []selftest_service.Check {
+	skip := func(ctx context.Context) error { return selftest_service.Skip(reason) }
+	return []selftest_service.Check{
+		{Name: "pebble", Run: skip},
+		{Name: "provider:expo", Run: skip},
+		{Name: "socket", Run: skip},
+	}
+}
+
+// validateConfig 校验配置文件中容易写错且启动时只会静默回退默认值的配置项
+func validateConfig() error {
+	var errs []error
+
+	if conf.Port == "" {
+		errs = append(errs, fmt.Errorf("port 未配置"))
+	}
+	if conf.APIKey == "" {
+		errs = append(errs, fmt.Errorf("api_key 未配置，管理接口将无法鉴权"))
+	}
+
+	durations := []struct {
+		key   string
+		value string
+	}{
+		{"push_center.idempotency_ttl", conf.IdempotencyTTL},
+		{"push_center.token_cache_ttl", conf.TokenCacheTTL},
+		{"push_center.warmup.save_interval", conf.WarmupSaveInterval},
+		{"push_center.delivery.retention", conf.DeliveryRetention},
+		{"push_center.delivery.receipt_delay", conf.DeliveryReceiptDelay},
+		{"push_center.delivery.check_interval", conf.DeliveryCheckInterval},
+		{"push_center.shutdown.source_timeout", conf.ShutdownSourceTimeout},
+		{"push_center.shutdown.drain_timeout", conf.ShutdownDrainTimeout},
+		{"push_center.shutdown.receipt_timeout", conf.ShutdownReceiptTimeout},
+		{"push_center.shutdown.stage_timeout", conf.ShutdownStageTimeout},
+		{"push_center.disk_monitor.check_interval", conf.DiskCheckInterval},
+		{"user_signature.max_skew", conf.UserSignatureMaxSkew},
+		{"rate_limit.window", conf.RateLimitWindow},
+		{"staging.mock_latency", conf.StagingMockLatency},
+		{"storage.redis.pin_ttl", conf.StorageRedisPinTTL},
+		{"storage.pin_max_age", conf.StoragePinMaxAge},
+		{"storage.pin_filter.rebuild_interval", conf.PinFilterRebuildInterval},
+		{"dedup.claim_ttl", conf.DedupClaimTTL},
+		{"push.providers.expo.timeout", conf.ExpoTimeout},
+		{"push.providers.expo.base_delay", conf.ExpoBaseDelay},
+		{"push.providers.email.timeout", conf.EmailTimeout},
+		{"push.providers.macos.timeout", conf.MacOSTimeout},
+		{"push.providers.windows.timeout", conf.WNSTimeout},
+		{"schedule.poll_interval", conf.SchedulePollInterval},
+		{"schedule.send_timeout", conf.ScheduleSendTimeout},
+		{"throttle.window", conf.ThrottleWindow},
+		{"notification.candy_bag.claim_window", conf.CandyBagClaimWindow},
+		{"membership.timeout", conf.MembershipTimeout},
+		{"membership.cache_ttl", conf.MembershipCacheTTL},
+		{"translation.timeout", conf.TranslationTimeout},
+		{"translation.cache_ttl", conf.TranslationCacheTTL},
+		{"backup.interval", conf.BackupInterval},
+		{"handoff.lease_ttl", conf.HandoffLeaseTTL},
+		{"handoff.poll_interval", conf.HandoffPollInterval},
+		{"handoff.buffer_window", conf.HandoffBufferWindow},
+		{"webhook.timeout", conf.WebhookTimeout},
+		{"webhook.base_delay", conf.WebhookBaseDelay},
+		{"sampling.timeout", conf.SamplingTimeout},
+	}
+	for _, duration := range durations {
+		if duration.value == "" {
+			continue
+		}
+		if _, err := time.ParseDuration(duration.value); err != nil {
+			errs = append(errs, fmt.Errorf("%s 不是有效的时间间隔: %q", duration.key, duration.value))
+		}
+	}
+
+	switch getStringWithDefault(conf.StorageBackend, storage_service.BackendPebble) {
+	case storage_service.BackendPebble, storage_service.BackendRedis:
+	default:
+		errs = append(errs, fmt.Errorf("未知的存储后端 storage.backend: %s", conf.StorageBackend))
+	}
+	switch getStringWithDefault(conf.PushCenterKeyspace, pebble_service.KeyspaceMulti) {
+	case pebble_service.KeyspaceMulti, pebble_service.KeyspaceShared:
+	default:
+		errs = append(errs, fmt.Errorf("未知的集合存储布局 push_center.keyspace: %s（可选 multi/shared）", conf.PushCenterKeyspace))
+	}
+	if conf.PinFilterEnabled {
+		if rate := conf.PinFilterFalsePositiveRate; rate != 0 && (rate < 0 || rate >= 1) {
+			errs = append(errs, fmt.Errorf("storage.pin_filter.false_positive_rate 必须在 (0, 1) 之间: %g", rate))
+		}
+		if conf.PinFilterExpectedItems < 0 {
+			errs = append(errs, fmt.Errorf("storage.pin_filter.expected_items 不能为负数"))
+		}
+	}
+	if conf.APIV2FieldNaming != "" && !respond.ValidateNaming(conf.APIV2FieldNaming) {
+		errs = append(errs, fmt.Errorf("api_v2.field_naming 无效: %s（可选 camel/snake）", conf.APIV2FieldNaming))
+	}
+	if err := pushcenter.ValidateMessageTypes(conf.EnabledTypes); err != nil {
+		errs = append(errs, fmt.Errorf("push_center.enabled_types: %w", err))
+	}
+	if conf.MembershipEnabled {
+		membershipConfig := &membership_service.Config{Endpoint: conf.MembershipEndpoint, MessageTypes: conf.MembershipMessageTypes}
+		membershipConfig.ApplyDefaults()
+		if err := membershipConfig.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("membership: %w", err))
+		}
+	}
+	if err := pushcenter.ValidateCollapseMode(conf.CollapseMode); err != nil {
+		errs = append(errs, fmt.Errorf("notification.collapse_mode: %w", err))
+	}
+	diskConfig := &disk_service.Config{WarningPercent: conf.DiskWarningPercent, CriticalPercent: conf.DiskCriticalPercent}
+	if err := diskConfig.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("push_center.disk_monitor: %w", err))
+	}
+	switch getStringWithDefault(conf.DedupMode, dedup_service.ModeLocal) {
+	case dedup_service.ModeLocal, dedup_service.ModeRedis:
+	default:
+		errs = append(errs, fmt.Errorf("未知的 PIN 去重模式 dedup.mode: %s", conf.DedupMode))
+	}
+	switch getStringWithDefault(conf.ThrottleBackend, throttle_service.BackendMemory) {
+	case throttle_service.BackendMemory, throttle_service.BackendPebble, throttle_service.BackendRedis:
+	default:
+		errs = append(errs, fmt.Errorf("未知的限流后端 throttle.backend: %s", conf.ThrottleBackend))
+	}
+
+	if conf.PushCenterEnabled {
+		if conf.SocketServerURL == "" {
+			errs = append(errs, fmt.Errorf("已启用推送中心但 socket_client.server_url 未配置"))
+		}
+		if conf.SocketExtraPushAuthKey == "" && conf.SocketAuthTokenURL == "" {
+			errs = append(errs, fmt.Errorf("已启用推送中心但 socket_client.extra_push_auth_key 和 auth_token_url 均未配置"))
+		}
+	}
+	if conf.QuotaEnabled {
+		switch getStringWithDefault(conf.QuotaOverQuotaAction, push_service.QuotaActionReject) {
+		case push_service.QuotaActionReject, push_service.QuotaActionDowngrade:
+		default:
+			errs = append(errs, fmt.Errorf("未知的超出配额处理方式 quota.over_quota_action: %s", conf.QuotaOverQuotaAction))
+		}
+		if conf.QuotaDefaultLimit < 0 {
+			errs = append(errs, fmt.Errorf("quota.default_limit 不能为负数"))
+		}
+	}
+	if conf.EmailEnabled {
+		if _, err := email_service.NewSender(buildEmailConfig()); err != nil {
+			errs = append(errs, fmt.Errorf("邮件兜底配置无效 push.providers.email: %w", err))
+		}
+	}
+	if conf.MacOSEnabled {
+		if _, err := apns_service.NewClient(buildMacOSConfig()); err != nil {
+			errs = append(errs, fmt.Errorf("macOS 推送配置无效 push.providers.macos: %w", err))
+		}
+	}
+	if conf.WNSEnabled {
+		if _, err := wns_service.NewClient(buildWNSConfig()); err != nil {
+			errs = append(errs, fmt.Errorf("Windows 推送配置无效 push.providers.windows: %w", err))
+		}
+	}
+	priorities := make([]string, 0, len(conf.FallbackChains))
+	for priority := range conf.FallbackChains {
+		priorities = append(priorities, priority)
+	}
+	sort.Strings(priorities)
+	for _, priority := range priorities {
+		for _, name := range conf.FallbackChains[priority] {
+			if name != push_service.ProviderTypeEmail {
+				errs = append(errs, fmt.Errorf("未知的兜底提供者 push.fallback.chains.%s: %s", priority, name))
+			} else if !conf.EmailEnabled {
+				errs = append(errs, fmt.Errorf("push.fallback.chains.%s 使用了 email，但 push.providers.email 未启用", priority))
+			}
+		}
+	}
+	if err := pushcenter.ValidateRoutingRules(buildRoutingRules()); err != nil {
+		errs = append(errs, fmt.Errorf("通知路由规则 routing.rules 无效: %w", err))
+	}
+	if conf.SamplingEnabled {
+		if conf.SamplingRate <= 0 || conf.SamplingRate > 1 {
+			errs = append(errs, fmt.Errorf("sampling.rate 必须在 (0, 1] 之间: %g", conf.SamplingRate))
+		}
+		if conf.SamplingWebhookURL == "" {
+			errs = append(errs, fmt.Errorf("已启用外发通知抽样但 sampling.webhook_url 未配置"))
+		}
+	}
+	if conf.TranslationEnabled {
+		if getStringWithDefault(conf.TranslationProvider, translate_service.ProviderLibreTranslate) != translate_service.ProviderLibreTranslate {
+			errs = append(errs, fmt.Errorf("未知的翻译服务 translation.provider: %s", conf.TranslationProvider))
+		}
+		if conf.TranslationEndpoint == "" {
+			errs = append(errs, fmt.Errorf("已启用翻译但 translation.endpoint 未配置"))
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,233 @@
+package apns_service
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"push-base-service/service/egress_service"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// authTokenTTL 认证令牌的复用时长（APNs 要求 20 到 60 分钟之间刷新）
+const authTokenTTL = 50 * time.Minute
+
+// 推送类型
+const (
+	PushTypeAlert      = "alert"      // 展示通知
+	PushTypeBackground = "background" // 后台静默推送
+)
+
+// Request 一次 APNs 推送请求
+type Request struct {
+	DeviceToken string    // 设备令牌
+	Payload     []byte    // JSON 负载（含 aps 字典）
+	PushType    string    // 推送类型：alert / background
+	Priority    int       // 优先级：10 立即发送，5 节能发送；0 表示 APNs 默认
+	Expiration  time.Time // 过期时间，零值表示 APNs 只尝试一次
+	CollapseID  string    // 折叠 ID，相同折叠 ID 的通知相互替换
+}
+
+// Error APNs 拒绝推送时返回的错误
+type Error struct {
+	StatusCode int    // HTTP 状态码
+	Reason     string // 失败原因，如 BadDeviceToken、Unregistered
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("APNs 推送失败: HTTP %d %s", e.StatusCode, e.Reason)
+}
+
+// IsUnregistered 设备令牌是否已失效（应用已卸载或令牌无效）
+func (e *Error) IsUnregistered() bool {
+	return e.StatusCode == http.StatusGone || e.Reason == "BadDeviceToken" || e.Reason == "Unregistered"
+}
+
+// Client APNs HTTP/2 客户端，使用 .p8 密钥签发的令牌认证
+type Client struct {
+	config *Config
+	key    *ecdsa.PrivateKey
+	client *http.Client
+	now    func() time.Time
+
+	mu         sync.Mutex
+	authToken  string
+	authIssued time.Time
+}
+
+// NewClient 创建 APNs 客户端
+func NewClient(config *Config) (*Client, error) {
+	if config == nil {
+		return nil, fmt.Errorf("APNs 配置不能为空")
+	}
+	config.ApplyDefaults()
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	keyPEM := []byte(config.PrivateKey)
+	if len(keyPEM) == 0 {
+		data, err := os.ReadFile(config.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("读取 APNs 密钥文件失败: %w", err)
+		}
+		keyPEM = data
+	}
+	key, err := ParsePrivateKey(keyPEM)
+	if err != nil {
+		return nil, err
+	}
+
+	transport, err := egress_service.NewTransport(config.Egress)
+	if err != nil {
+		return nil, fmt.Errorf("创建 APNs 出站连接失败: %w", err)
+	}
+
+	return &Client{
+		config: config,
+		key:    key,
+		client: &http.Client{Timeout: config.Timeout, Transport: transport},
+		now:    time.Now,
+	}, nil
+}
+
+// ParsePrivateKey 解析 PEM 格式的 .p8 密钥（PKCS#8 ECDSA P-256）
+func ParsePrivateKey(keyPEM []byte) (*ecdsa.PrivateKey, error) {
+	block, _ := pem.Decode(keyPEM)
+	if block == nil {
+		return nil, fmt.Errorf("APNs 密钥不是有效的 PEM 格式")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("解析 APNs 密钥失败: %w", err)
+	}
+	key, ok := parsed.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("APNs 密钥不是 ECDSA 密钥")
+	}
+	return key, nil
+}
+
+// Topic 推送主题（应用 Bundle ID）
+func (c *Client) Topic() string {
+	return c.config.BundleID
+}
+
+// Send 发送推送，成功时返回 apns-id
+func (c *Client) Send(ctx context.Context, request *Request) (string, error) {
+	authToken, err := c.currentAuthToken()
+	if err != nil {
+		return "", err
+	}
+
+	url := c.config.Endpoint + "/3/device/" + request.DeviceToken
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(request.Payload))
+	if err != nil {
+		return "", fmt.Errorf("创建 APNs 请求失败: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "bearer "+authToken)
+	req.Header.Set("apns-topic", c.config.BundleID)
+	if request.PushType != "" {
+		req.Header.Set("apns-push-type", request.PushType)
+	}
+	if request.Priority > 0 {
+		req.Header.Set("apns-priority", strconv.Itoa(request.Priority))
+	}
+	if !request.Expiration.IsZero() {
+		req.Header.Set("apns-expiration", strconv.FormatInt(request.Expiration.Unix(), 10))
+	}
+	if request.CollapseID != "" {
+		req.Header.Set("apns-collapse-id", request.CollapseID)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("发送 APNs 请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK {
+		return resp.Header.Get("apns-id"), nil
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	var failure struct {
+		Reason string `json:"reason"`
+	}
+	_ = json.Unmarshal(body, &failure)
+	if failure.Reason == "ExpiredProviderToken" {
+		c.resetAuthToken()
+	}
+	return "", &Error{StatusCode: resp.StatusCode, Reason: failure.Reason}
+}
+
+// HealthCheck 检查密钥能否签发认证令牌
+func (c *Client) HealthCheck(ctx context.Context) error {
+	_, err := c.currentAuthToken()
+	return err
+}
+
+// currentAuthToken 返回当前认证令牌，过期时重新签发
+func (c *Client) currentAuthToken() (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	if c.authToken != "" && now.Sub(c.authIssued) < authTokenTTL {
+		return c.authToken, nil
+	}
+
+	token, err := signAuthToken(c.key, c.config.KeyID, c.config.TeamID, now)
+	if err != nil {
+		return "", err
+	}
+	c.authToken = token
+	c.authIssued = now
+	return token, nil
+}
+
+// resetAuthToken APNs 提示认证令牌过期时丢弃缓存
+func (c *Client) resetAuthToken() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.authToken = ""
+}
+
+// signAuthToken 签发 ES256 JWT 认证令牌
+func signAuthToken(key *ecdsa.PrivateKey, keyID, teamID string, issuedAt time.Time) (string, error) {
+	header, _ := json.Marshal(map[string]string{"alg": "ES256", "kid": keyID})
+	claims, _ := json.Marshal(map[string]interface{}{"iss": teamID, "iat": issuedAt.Unix()})
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+
+	digest := sha256.Sum256([]byte(signingInput))
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("签发 APNs 认证令牌失败: %w", err)
+	}
+	signature := make([]byte, 64)
+	r.FillBytes(signature[:32])
+	s.FillBytes(signature[32:])
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// ValidateDeviceToken 验证设备令牌格式（十六进制，至少 32 字节）
+func ValidateDeviceToken(token string) bool {
+	if len(token) < 64 || len(token)%2 != 0 {
+		return false
+	}
+	_, err := hex.DecodeString(token)
+	return err == nil
+}
@@ -0,0 +1,108 @@
+package apns_service
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newTestKey 生成测试用的 .p8 密钥
+func newTestKey(t *testing.T) (*ecdsa.PrivateKey, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("生成密钥失败: %v", err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("编码密钥失败: %v", err)
+	}
+	return key, string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+}
+
+// verifyAuthToken 校验 ES256 认证令牌签名
+func verifyAuthToken(key *ecdsa.PublicKey, token string) error {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return errors.New("JWT 格式无效")
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil || len(signature) != 64 {
+		return errors.New("JWT 签名无效")
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if !ecdsa.Verify(key, digest[:], new(big.Int).SetBytes(signature[:32]), new(big.Int).SetBytes(signature[32:])) {
+		return errors.New("JWT 签名校验失败")
+	}
+	return nil
+}
+
+func TestClientSend(t *testing.T) {
+	key, keyPEM := newTestKey(t)
+	deviceToken := strings.Repeat("ab", 32)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/3/device/"+strings.Repeat("cd", 32) {
+			w.WriteHeader(http.StatusGone)
+			w.Write([]byte(`{"reason":"Unregistered"}`))
+			return
+		}
+		if r.URL.Path != "/3/device/"+deviceToken {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		if err := verifyAuthToken(&key.PublicKey, strings.TrimPrefix(r.Header.Get("Authorization"), "bearer ")); err != nil {
+			t.Errorf("invalid auth token: %v", err)
+		}
+		if r.Header.Get("apns-topic") != "com.example.desktop" || r.Header.Get("apns-push-type") != PushTypeAlert || r.Header.Get("apns-priority") != "10" {
+			t.Errorf("unexpected headers: %v", r.Header)
+		}
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != `{"aps":{"alert":"hi"}}` {
+			t.Errorf("unexpected payload: %s", body)
+		}
+		w.Header().Set("apns-id", "apns-1")
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&Config{TeamID: "TEAM", KeyID: "KEY", PrivateKey: keyPEM, BundleID: "com.example.desktop", Endpoint: server.URL})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	id, err := client.Send(context.Background(), &Request{DeviceToken: deviceToken, Payload: []byte(`{"aps":{"alert":"hi"}}`), PushType: PushTypeAlert, Priority: 10})
+	if err != nil || id != "apns-1" {
+		t.Fatalf("Send() = %q, %v", id, err)
+	}
+
+	_, err = client.Send(context.Background(), &Request{DeviceToken: strings.Repeat("cd", 32), Payload: []byte(`{}`)})
+	var apnsErr *Error
+	if !errors.As(err, &apnsErr) || !apnsErr.IsUnregistered() {
+		t.Errorf("Send() to unregistered token error = %v", err)
+	}
+}
+
+func TestValidateDeviceToken(t *testing.T) {
+	cases := map[string]bool{
+		strings.Repeat("ab", 32): true,
+		strings.Repeat("AB", 50): true,
+		strings.Repeat("ab", 16): false,
+		strings.Repeat("zz", 32): false,
+		"ExponentPushToken[abc]": false,
+	}
+	for token, want := range cases {
+		if got := ValidateDeviceToken(token); got != want {
+			t.Errorf("ValidateDeviceToken(%q) = %v, want %v", token, got, want)
+		}
+	}
+}
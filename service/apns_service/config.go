@@ -0,0 +1,65 @@
+package apns_service
+
+import (
+	"fmt"
+	"push-base-service/service/egress_service"
+	"time"
+)
+
+// APNs 接口地址
+const (
+	ProductionEndpoint  = "https://api.push.apple.com"
+	DevelopmentEndpoint = "https://api.sandbox.push.apple.com"
+)
+
+// Config APNs 推送配置（基于 .p8 密钥的令牌认证）
+type Config struct {
+	TeamID     string                 `yaml:"team_id" json:"team_id"`         // Apple 开发者团队 ID
+	KeyID      string                 `yaml:"key_id" json:"key_id"`           // .p8 密钥 ID
+	KeyFile    string                 `yaml:"key_file" json:"key_file"`       // .p8 密钥文件路径
+	PrivateKey string                 `yaml:"private_key" json:"private_key"` // .p8 密钥内容（PEM），优先于 key_file
+	BundleID   string                 `yaml:"bundle_id" json:"bundle_id"`     // 应用 Bundle ID，作为 apns-topic
+	Production bool                   `yaml:"production" json:"production"`   // 是否使用生产环境（否则使用沙盒环境）
+	Endpoint   string                 `yaml:"endpoint" json:"endpoint"`       // 接口地址，为空时按 production 选择
+	Timeout    time.Duration          `yaml:"timeout" json:"timeout"`         // 单次推送超时
+	Egress     *egress_service.Config `yaml:"egress" json:"egress"`           // 出站代理和 TLS 配置，为空时使用默认传输层（沿用 HTTP_PROXY 等环境变量）
+}
+
+// DefaultConfig 返回默认配置
+func DefaultConfig() *Config {
+	return &Config{
+		Timeout: 10 * time.Second,
+	}
+}
+
+// ApplyDefaults 为缺失的配置项填充默认值
+func (c *Config) ApplyDefaults() {
+	defaults := DefaultConfig()
+
+	if c.Timeout <= 0 {
+		c.Timeout = defaults.Timeout
+	}
+	if c.Endpoint == "" {
+		c.Endpoint = DevelopmentEndpoint
+		if c.Production {
+			c.Endpoint = ProductionEndpoint
+		}
+	}
+}
+
+// Validate 校验配置
+func (c *Config) Validate() error {
+	if c.TeamID == "" || c.KeyID == "" {
+		return fmt.Errorf("APNs 团队 ID 和密钥 ID 不能为空")
+	}
+	if c.PrivateKey == "" && c.KeyFile == "" {
+		return fmt.Errorf("APNs 密钥内容和密钥文件不能同时为空")
+	}
+	if c.BundleID == "" {
+		return fmt.Errorf("APNs Bundle ID 不能为空")
+	}
+	if err := c.Egress.Validate(); err != nil {
+		return fmt.Errorf("APNs 出站连接配置无效: %w", err)
+	}
+	return nil
+}
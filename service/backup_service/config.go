@@ -0,0 +1,35 @@
+package backup_service
+
+import "time"
+
+// Config Pebble 备份配置
+type Config struct {
+	Dir      string        `yaml:"dir" json:"dir"`           // 备份文件目录，管理接口和定时备份共用
+	Schedule bool          `yaml:"schedule" json:"schedule"` // 是否启用定时备份
+	Interval time.Duration `yaml:"interval" json:"interval"` // 定时备份间隔
+	Keep     int           `yaml:"keep" json:"keep"`         // 保留最近的备份数量
+}
+
+// DefaultConfig 返回默认配置
+func DefaultConfig() *Config {
+	return &Config{
+		Dir:      "./data/backups",
+		Interval: 24 * time.Hour,
+		Keep:     7,
+	}
+}
+
+// ApplyDefaults 为缺失的配置项填充默认值
+func (c *Config) ApplyDefaults() {
+	defaults := DefaultConfig()
+
+	if c.Dir == "" {
+		c.Dir = defaults.Dir
+	}
+	if c.Interval <= 0 {
+		c.Interval = defaults.Interval
+	}
+	if c.Keep <= 0 {
+		c.Keep = defaults.Keep
+	}
+}
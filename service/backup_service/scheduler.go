@@ -0,0 +1,118 @@
+package backup_service
+
+import (
+	"log"
+	"push-base-service/models"
+	"push-base-service/service/disk_service"
+	"push-base-service/service/pebble_service"
+	"sync"
+	"time"
+)
+
+// Scheduler 定时备份调度器
+type Scheduler struct {
+	config  *Config
+	stopCh  chan struct{}
+	wg      sync.WaitGroup
+	running bool
+	mu      sync.Mutex
+}
+
+// NewScheduler 创建定时备份调度器
+func NewScheduler(config *Config) *Scheduler {
+	if config == nil {
+		config = DefaultConfig()
+	}
+	config.ApplyDefaults()
+
+	return &Scheduler{config: config}
+}
+
+// Start 启动定时备份
+func (s *Scheduler) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.running {
+		return
+	}
+	s.running = true
+	s.stopCh = make(chan struct{})
+
+	s.wg.Add(1)
+	go s.loop(s.stopCh)
+	log.Printf("💾 定时备份已启动: 间隔=%v, 保留=%d, 目录=%s", s.config.Interval, s.config.Keep, s.config.Dir)
+}
+
+// Stop 停止定时备份，等待进行中的备份完成
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	if !s.running {
+		s.mu.Unlock()
+		return
+	}
+	s.running = false
+	close(s.stopCh)
+	s.mu.Unlock()
+
+	s.wg.Wait()
+	log.Printf("🛑 定时备份已停止")
+}
+
+// loop 按间隔执行备份
+func (s *Scheduler) loop(stopCh chan struct{}) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+			if disk_service.Lossy() {
+				log.Printf("⚠️ 磁盘空间紧急，跳过本次定时备份")
+				continue
+			}
+			if _, err := RunBackup(s.config); err != nil {
+				log.Printf("❌ 定时备份失败: %v", err)
+			}
+		}
+	}
+}
+
+// RunBackup 立即执行一次备份，并清理超出保留数量的旧备份
+func RunBackup(config *Config) (*models.BackupFile, error) {
+	backup, err := pebble_service.BackupToFile(config.Dir)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("💾 已创建备份: %s (%d 字节)", backup.Name, backup.Size)
+
+	if removed, err := pebble_service.PruneBackupFiles(config.Dir, config.Keep); err != nil {
+		log.Printf("⚠️ 清理旧备份失败: %v", err)
+	} else if removed > 0 {
+		log.Printf("🧹 已清理 %d 个旧备份", removed)
+	}
+	return backup, nil
+}
+
+// 全局备份配置
+var globalConfig *Config
+
+// SetGlobalConfig 设置全局备份配置（供管理接口使用）
+func SetGlobalConfig(config *Config) {
+	if config != nil {
+		config.ApplyDefaults()
+	}
+	globalConfig = config
+}
+
+// GetGlobalConfig 获取全局备份配置，未设置时返回默认配置
+func GetGlobalConfig() *Config {
+	if globalConfig == nil {
+		return DefaultConfig()
+	}
+	return globalConfig
+}
@@ -0,0 +1,55 @@
+package catchup_service
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// Config 重新连接后补拉断线期间消息的配置
+// 推送中心记录每个上游最新消息的时间戳和 PIN，上游连接（含重新连接）成功后按检查点调用补拉接口，
+// 取回的消息照常进入推送流水线，已推送过的消息由 PIN 去重和幂等检查跳过
+type Config struct {
+	Enabled     bool          `yaml:"enabled" json:"enabled"`           // 是否启用补拉
+	Endpoint    string        `yaml:"endpoint" json:"endpoint"`         // 补拉接口地址，如 https://api.example.com/push/messages
+	APIKey      string        `yaml:"api_key" json:"api_key"`           // 补拉接口密钥（以 Authorization: Bearer 发送）
+	Timeout     time.Duration `yaml:"timeout" json:"timeout"`           // 单次请求超时
+	PageSize    int           `yaml:"page_size" json:"page_size"`       // 每次请求的消息数
+	MaxMessages int           `yaml:"max_messages" json:"max_messages"` // 单次补拉的消息数上限，超出的消息不再补拉
+}
+
+// DefaultConfig 返回默认配置
+func DefaultConfig() *Config {
+	return &Config{
+		Timeout:     10 * time.Second,
+		PageSize:    100,
+		MaxMessages: 1000,
+	}
+}
+
+// ApplyDefaults 为缺失的配置项填充默认值
+func (c *Config) ApplyDefaults() {
+	defaults := DefaultConfig()
+
+	if c.Timeout <= 0 {
+		c.Timeout = defaults.Timeout
+	}
+	if c.PageSize <= 0 {
+		c.PageSize = defaults.PageSize
+	}
+	if c.MaxMessages <= 0 {
+		c.MaxMessages = defaults.MaxMessages
+	}
+}
+
+// Validate 校验配置
+func (c *Config) Validate() error {
+	if c.Endpoint == "" {
+		return fmt.Errorf("启用补拉需要配置补拉接口地址")
+	}
+	endpoint, err := url.Parse(c.Endpoint)
+	if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+		return fmt.Errorf("补拉接口地址无效: %s", c.Endpoint)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package catchup_service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"push-base-service/models"
+	"strconv"
+)
+
+// Page 补拉接口的一页响应：{"messages": [SocketData 载荷...], "hasMore": true}
+// 每条消息与上游 Socket 下发的载荷格式相同（{"M": "WS_SERVER_NOTIFY_GROUP_CHAT", "C": 0, "D": {...}}，或其 JSON 字符串），按时间升序排列
+type Page struct {
+	Messages []json.RawMessage `json:"messages"`
+	HasMore  bool              `json:"hasMore"`
+}
+
+// Fetcher 补拉接口客户端
+type Fetcher struct {
+	config *Config
+	client *http.Client
+}
+
+// NewFetcher 创建补拉接口客户端
+func NewFetcher(config *Config) *Fetcher {
+	if config == nil {
+		config = DefaultConfig()
+	}
+	config.ApplyDefaults()
+
+	return &Fetcher{
+		config: config,
+		client: &http.Client{Timeout: config.Timeout},
+	}
+}
+
+// Fetch 获取检查点之后的一页消息：GET Endpoint?upstream=上游名称&since=时间戳&sincePinId=PIN&limit=每页数量
+func (f *Fetcher) Fetch(ctx context.Context, checkpoint *models.CatchupCheckpoint) (*Page, error) {
+	endpoint, err := url.Parse(f.config.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("补拉接口地址无效: %w", err)
+	}
+	query := endpoint.Query()
+	query.Set("upstream", checkpoint.Upstream)
+	query.Set("since", strconv.FormatInt(checkpoint.Timestamp, 10))
+	query.Set("sincePinId", checkpoint.PinId)
+	query.Set("limit", strconv.Itoa(f.config.PageSize))
+	endpoint.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if f.config.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+f.config.APIKey)
+	}
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求补拉接口失败: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("补拉接口返回 HTTP %d: %s", resp.StatusCode, body)
+	}
+
+	var page Page
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		return nil, fmt.Errorf("解析补拉接口响应失败: %w", err)
+	}
+	return &page, nil
+}
+
+// MaxMessages 单次补拉的消息数上限
+func (f *Fetcher) MaxMessages() int {
+	return f.config.MaxMessages
+}
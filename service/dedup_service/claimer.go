@@ -0,0 +1,53 @@
+package dedup_service
+
+import (
+	"context"
+	"fmt"
+	"push-base-service/service/metrics_service"
+)
+
+// pinClaimsCounter PIN 抢占结果计数
+var pinClaimsCounter = metrics_service.NewCounterVec(
+	"push_pin_claims_total", "Number of PIN notification claims by result", "result")
+
+// PinClaimer 多实例间的 PIN 推送权抢占
+// 同一个 PinId 只有一个实例能抢占成功，其余实例应跳过推送
+type PinClaimer interface {
+	// Name 返回协调器名称（用于日志）
+	Name() string
+
+	// Claim 尝试抢占 PinId 的推送权，已被任一实例（包括本实例）抢占时返回 false
+	Claim(ctx context.Context, pinId string) (bool, error)
+
+	// Release 释放本实例对 PinId 的抢占（处理失败或重启恢复时），其他实例持有的抢占不受影响
+	Release(ctx context.Context, pinId string) error
+}
+
+// NewPinClaimer 根据配置创建 PIN 抢占协调器，local 模式返回 nil（只依赖本地去重）
+func NewPinClaimer(config *Config) (PinClaimer, error) {
+	if config == nil {
+		return nil, nil
+	}
+	config.ApplyDefaults()
+
+	switch config.Mode {
+	case ModeLocal:
+		return nil, nil
+	case ModeRedis:
+		return newRedisPinClaimer(&config.Redis, config.InstanceID, config.ClaimTTL)
+	default:
+		return nil, fmt.Errorf("不支持的 PIN 去重模式: %s", config.Mode)
+	}
+}
+
+// recordClaim 记录抢占结果指标
+func recordClaim(won bool, err error) {
+	switch {
+	case err != nil:
+		pinClaimsCounter.Inc("error")
+	case won:
+		pinClaimsCounter.Inc("won")
+	default:
+		pinClaimsCounter.Inc("lost")
+	}
+}
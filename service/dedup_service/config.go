@@ -0,0 +1,63 @@
+package dedup_service
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// PIN 去重协调模式
+const (
+	ModeLocal = "local" // 仅本地去重（Pebble 已通知 PIN + 幂等键），适合单实例部署
+	ModeRedis = "redis" // Redis SET NX 抢占，多个实例消费同一上游时只有一个实例推送
+)
+
+// Config PIN 去重协调配置
+type Config struct {
+	Mode       string        `yaml:"mode" json:"mode"`               // 协调模式：local / redis
+	InstanceID string        `yaml:"instance_id" json:"instance_id"` // 本实例ID，写入抢占记录便于排查，默认 主机名-进程号
+	ClaimTTL   time.Duration `yaml:"claim_ttl" json:"claim_ttl"`     // 抢占记录保留时长，需覆盖上游重复投递同一 PIN 的时间范围
+	Redis      RedisConfig   `yaml:"redis" json:"redis"`             // Redis 模式配置
+}
+
+// RedisConfig Redis 协调配置
+type RedisConfig struct {
+	Addr      string `yaml:"addr" json:"addr"`             // Redis 地址，如 127.0.0.1:6379
+	Password  string `yaml:"password" json:"password"`     // Redis 密码
+	DB        int    `yaml:"db" json:"db"`                 // Redis 数据库编号
+	KeyPrefix string `yaml:"key_prefix" json:"key_prefix"` // 键前缀
+}
+
+// DefaultConfig 返回默认配置
+func DefaultConfig() *Config {
+	return &Config{
+		Mode:     ModeLocal,
+		ClaimTTL: 24 * time.Hour,
+		Redis: RedisConfig{
+			Addr:      "127.0.0.1:6379",
+			KeyPrefix: "push:pin_claim:",
+		},
+	}
+}
+
+// ApplyDefaults 为缺失的配置项填充默认值
+func (c *Config) ApplyDefaults() {
+	defaults := DefaultConfig()
+
+	if c.Mode == "" {
+		c.Mode = defaults.Mode
+	}
+	if c.InstanceID == "" {
+		hostname, _ := os.Hostname()
+		c.InstanceID = fmt.Sprintf("%s-%d", hostname, os.Getpid())
+	}
+	if c.ClaimTTL <= 0 {
+		c.ClaimTTL = defaults.ClaimTTL
+	}
+	if c.Redis.Addr == "" {
+		c.Redis.Addr = defaults.Redis.Addr
+	}
+	if c.Redis.KeyPrefix == "" {
+		c.Redis.KeyPrefix = defaults.Redis.KeyPrefix
+	}
+}
@@ -0,0 +1,110 @@
+//go:build !noredis
+
+package dedup_service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisEnabled 当前构建是否包含 Redis 支持（使用 noredis 构建标签时为 false）
+const RedisEnabled = true
+
+// releaseScript 仅在抢占记录仍属于本实例时删除
+var releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+
+// RedisPinClaimer 基于 Redis SET NX 的 PIN 抢占协调器
+// 键为 {prefix}{pinId}，值为抢占成功的实例ID，ClaimTTL 后过期
+type RedisPinClaimer struct {
+	client     *redis.Client
+	keyPrefix  string
+	instanceID string
+	ttl        time.Duration
+}
+
+// newRedisPinClaimer 创建 Redis PIN 抢占协调器
+func newRedisPinClaimer(config *RedisConfig, instanceID string, ttl time.Duration) (PinClaimer, error) {
+	claimer, err := NewRedisPinClaimer(config, instanceID, ttl)
+	if err != nil {
+		return nil, err
+	}
+	return claimer, nil
+}
+
+// NewRedisPinClaimer 创建 Redis PIN 抢占协调器并检查连接
+func NewRedisPinClaimer(config *RedisConfig, instanceID string, ttl time.Duration) (*RedisPinClaimer, error) {
+	client := redis.NewClient(&redis.Options{
+		Addr:     config.Addr,
+		Password: config.Password,
+		DB:       config.DB,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("连接 Redis 失败: %w", err)
+	}
+
+	return &RedisPinClaimer{
+		client:     client,
+		keyPrefix:  config.KeyPrefix,
+		instanceID: instanceID,
+		ttl:        ttl,
+	}, nil
+}
+
+// Name 返回协调器名称
+func (c *RedisPinClaimer) Name() string {
+	return ModeRedis
+}
+
+// Claim 尝试抢占 PinId 的推送权
+func (c *RedisPinClaimer) Claim(ctx context.Context, pinId string) (bool, error) {
+	if pinId == "" {
+		return false, fmt.Errorf("PinID 不能为空")
+	}
+
+	won, err := c.client.SetNX(ctx, c.keyPrefix+pinId, c.instanceID, c.ttl).Result()
+	recordClaim(won, err)
+	if err != nil {
+		return false, fmt.Errorf("抢占PIN推送权失败: %w", err)
+	}
+	return won, nil
+}
+
+// Release 释放本实例对 PinId 的抢占，使其他实例（或本实例恢复后）可以重新抢占并推送
+func (c *RedisPinClaimer) Release(ctx context.Context, pinId string) error {
+	if pinId == "" {
+		return fmt.Errorf("PinID 不能为空")
+	}
+
+	if err := releaseScript.Run(ctx, c.client, []string{c.keyPrefix + pinId}, c.instanceID).Err(); err != nil {
+		return fmt.Errorf("释放PIN推送权失败: %w", err)
+	}
+	return nil
+}
+
+// Holder 获取抢占了 PinId 的实例ID，未被抢占时返回空字符串
+func (c *RedisPinClaimer) Holder(ctx context.Context, pinId string) (string, error) {
+	holder, err := c.client.Get(ctx, c.keyPrefix+pinId).Result()
+	if err == redis.Nil {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("获取PIN抢占记录失败: %w", err)
+	}
+	return holder, nil
+}
+
+// Close 关闭 Redis 连接
+func (c *RedisPinClaimer) Close() error {
+	return c.client.Close()
+}
@@ -0,0 +1,16 @@
+//go:build noredis
+
+package dedup_service
+
+import (
+	"fmt"
+	"time"
+)
+
+// RedisEnabled 当前构建是否包含 Redis 支持（使用 noredis 构建标签时为 false）
+const RedisEnabled = false
+
+// newRedisPinClaimer 当前构建未包含 Redis 支持
+func newRedisPinClaimer(config *RedisConfig, instanceID string, ttl time.Duration) (PinClaimer, error) {
+	return nil, fmt.Errorf("当前构建未包含 Redis 支持（noredis），无法使用 redis PIN 去重模式")
+}
@@ -0,0 +1,98 @@
+//go:build !noredis
+
+package dedup_service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+func newTestClaimer(t *testing.T, server *miniredis.Miniredis, instanceID string) *RedisPinClaimer {
+	t.Helper()
+
+	config := &Config{Mode: ModeRedis, InstanceID: instanceID}
+	config.Redis.Addr = server.Addr()
+	config.ApplyDefaults()
+
+	claimer, err := NewRedisPinClaimer(&config.Redis, config.InstanceID, config.ClaimTTL)
+	if err != nil {
+		t.Fatalf("NewRedisPinClaimer() failed, err: %v", err)
+	}
+	t.Cleanup(func() { claimer.Close() })
+	return claimer
+}
+
+func TestRedisPinClaimerOnlyOneReplicaWins(t *testing.T) {
+	server := miniredis.RunT(t)
+	ctx := context.Background()
+
+	first := newTestClaimer(t, server, "replica-a")
+	second := newTestClaimer(t, server, "replica-b")
+
+	if won, err := first.Claim(ctx, "pin1"); !won || err != nil {
+		t.Fatalf("first.Claim() = %v, %v; want won", won, err)
+	}
+	if won, _ := second.Claim(ctx, "pin1"); won {
+		t.Errorf("second.Claim() should lose an already claimed pin")
+	}
+	if won, _ := first.Claim(ctx, "pin1"); won {
+		t.Errorf("claiming the same pin twice on one replica should lose")
+	}
+	if holder, _ := second.Holder(ctx, "pin1"); holder != "replica-a" {
+		t.Errorf("Holder() = %q, want replica-a", holder)
+	}
+
+	if won, _ := second.Claim(ctx, "pin2"); !won {
+		t.Errorf("second.Claim() should win an unclaimed pin")
+	}
+}
+
+func TestRedisPinClaimerExpiry(t *testing.T) {
+	server := miniredis.RunT(t)
+	ctx := context.Background()
+	claimer := newTestClaimer(t, server, "replica-a")
+
+	claimer.Claim(ctx, "pin1")
+	server.FastForward(25 * time.Hour)
+
+	if won, _ := claimer.Claim(ctx, "pin1"); !won {
+		t.Errorf("claim should be available again after ClaimTTL")
+	}
+}
+
+func TestRedisPinClaimerRelease(t *testing.T) {
+	server := miniredis.RunT(t)
+	ctx := context.Background()
+	first := newTestClaimer(t, server, "replica-a")
+	second := newTestClaimer(t, server, "replica-b")
+
+	first.Claim(ctx, "pin1")
+
+	// 其他实例不能释放不属于自己的抢占
+	if err := second.Release(ctx, "pin1"); err != nil {
+		t.Fatalf("second.Release() failed, err: %v", err)
+	}
+	if holder, _ := first.Holder(ctx, "pin1"); holder != "replica-a" {
+		t.Errorf("Holder() after foreign release = %q, want replica-a", holder)
+	}
+
+	// 持有者释放后可被重新抢占
+	if err := first.Release(ctx, "pin1"); err != nil {
+		t.Fatalf("first.Release() failed, err: %v", err)
+	}
+	if won, _ := second.Claim(ctx, "pin1"); !won {
+		t.Errorf("second.Claim() should win a released pin")
+	}
+}
+
+func TestNewPinClaimer(t *testing.T) {
+	if claimer, err := NewPinClaimer(&Config{Mode: ModeLocal}); claimer != nil || err != nil {
+		t.Errorf("local mode = %v, %v; want nil, nil", claimer, err)
+	}
+	if _, err := NewPinClaimer(&Config{Mode: "mysql"}); err == nil {
+		t.Errorf("unknown mode should return an error")
+	}
+}
@@ -0,0 +1,49 @@
+package disk_service
+
+import (
+	"fmt"
+	"time"
+)
+
+// Config 数据目录磁盘空间监控配置
+type Config struct {
+	Enabled         bool          `yaml:"enabled" json:"enabled"`                   // 是否启用监控
+	WarningPercent  float64       `yaml:"warning_percent" json:"warning_percent"`   // 使用率达到该值时告警
+	CriticalPercent float64       `yaml:"critical_percent" json:"critical_percent"` // 使用率达到该值时进入降级模式
+	CheckInterval   time.Duration `yaml:"check_interval" json:"check_interval"`     // 检查间隔
+}
+
+// DefaultConfig 返回默认配置
+func DefaultConfig() *Config {
+	return &Config{
+		WarningPercent:  85,
+		CriticalPercent: 95,
+		CheckInterval:   30 * time.Second,
+	}
+}
+
+// ApplyDefaults 为缺失的配置项填充默认值
+func (c *Config) ApplyDefaults() {
+	defaults := DefaultConfig()
+
+	if c.WarningPercent <= 0 {
+		c.WarningPercent = defaults.WarningPercent
+	}
+	if c.CriticalPercent <= 0 {
+		c.CriticalPercent = defaults.CriticalPercent
+	}
+	if c.CheckInterval <= 0 {
+		c.CheckInterval = defaults.CheckInterval
+	}
+}
+
+// Validate 校验阈值
+func (c *Config) Validate() error {
+	if c.WarningPercent > 100 || c.CriticalPercent > 100 {
+		return fmt.Errorf("磁盘使用率阈值不能超过 100")
+	}
+	if c.WarningPercent > 0 && c.CriticalPercent > 0 && c.WarningPercent >= c.CriticalPercent {
+		return fmt.Errorf("磁盘告警阈值 (%.1f) 必须小于紧急阈值 (%.1f)", c.WarningPercent, c.CriticalPercent)
+	}
+	return nil
+}
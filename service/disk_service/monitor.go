@@ -0,0 +1,205 @@
+package disk_service
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"push-base-service/service/metrics_service"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// 磁盘空间状态
+const (
+	StateOK       = "ok"       // 正常
+	StateWarning  = "warning"  // 超过告警阈值，需要运维介入
+	StateCritical = "critical" // 超过紧急阈值或写入时磁盘已满，跳过非关键写入
+)
+
+// stateValues 状态对应的指标值
+var stateValues = map[string]float64{StateOK: 0, StateWarning: 1, StateCritical: 2}
+
+var (
+	diskUsageGauge = metrics_service.NewGaugeVec(
+		"push_disk_usage_percent", "Used space of the filesystem holding the Pebble data directory")
+	diskStateGauge = metrics_service.NewGaugeVec(
+		"push_disk_state", "Disk space state of the Pebble data directory: 0=ok, 1=warning, 2=critical")
+)
+
+// Status 磁盘空间检查结果
+type Status struct {
+	State          string  `json:"state"`          // 磁盘空间状态
+	Path           string  `json:"path"`           // 监控的数据目录
+	UsedPercent    float64 `json:"usedPercent"`    // 使用率
+	TotalBytes     uint64  `json:"totalBytes"`     // 文件系统总空间
+	AvailableBytes uint64  `json:"availableBytes"` // 可用空间
+	CheckedAt      int64   `json:"checkedAt"`      // 检查时间
+	Reason         string  `json:"reason,omitempty"`
+}
+
+// current 最近一次检查结果，未启用监控时为 nil
+var current atomic.Pointer[Status]
+
+// CurrentStatus 返回最近一次检查结果，未启用监控时返回 nil
+func CurrentStatus() *Status {
+	return current.Load()
+}
+
+// Lossy 是否处于降级模式：磁盘空间紧急时跳过历史记录、收件箱等非关键写入，保留去重与令牌读写
+func Lossy() bool {
+	status := current.Load()
+	return status != nil && status.State == StateCritical
+}
+
+// ReportDiskFull 写入因磁盘已满失败时立即进入降级模式，下次检查时按实际使用率恢复
+func ReportDiskFull(path string) {
+	status := current.Load()
+	if status != nil && status.State == StateCritical {
+		return
+	}
+
+	next := &Status{State: StateCritical, Path: path, CheckedAt: time.Now().Unix(), Reason: "写入失败：磁盘已满"}
+	if status != nil {
+		next.Path = status.Path
+		next.UsedPercent, next.TotalBytes, next.AvailableBytes = status.UsedPercent, status.TotalBytes, status.AvailableBytes
+	}
+	setStatus(next)
+}
+
+// setStatus 更新检查结果，状态变化时记录日志
+func setStatus(status *Status) {
+	previous := current.Swap(status)
+	diskUsageGauge.Set(status.UsedPercent)
+	diskStateGauge.Set(stateValues[status.State])
+
+	if previous != nil && previous.State == status.State {
+		return
+	}
+	switch status.State {
+	case StateCritical:
+		log.Printf("🚨 数据目录磁盘空间紧急 (%.1f%%, 可用 %d MB)，进入降级模式：跳过历史记录和收件箱写入。%s",
+			status.UsedPercent, status.AvailableBytes>>20, status.Reason)
+	case StateWarning:
+		log.Printf("⚠️ 数据目录磁盘空间告警 (%.1f%%, 可用 %d MB)，请及时清理或扩容: %s",
+			status.UsedPercent, status.AvailableBytes>>20, status.Path)
+	default:
+		if previous != nil {
+			log.Printf("✅ 数据目录磁盘空间已恢复正常 (%.1f%%)", status.UsedPercent)
+		}
+	}
+}
+
+// Monitor 定期检查数据目录所在文件系统的使用率
+type Monitor struct {
+	config  *Config
+	path    string
+	stopCh  chan struct{}
+	wg      sync.WaitGroup
+	running bool
+	mu      sync.Mutex
+}
+
+// NewMonitor 创建磁盘空间监控
+func NewMonitor(config *Config, path string) *Monitor {
+	if config == nil {
+		config = DefaultConfig()
+	}
+	config.ApplyDefaults()
+
+	return &Monitor{config: config, path: path}
+}
+
+// Start 立即检查一次并启动定期检查
+func (m *Monitor) Start() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.running {
+		return
+	}
+	m.running = true
+	m.stopCh = make(chan struct{})
+
+	m.Check()
+	m.wg.Add(1)
+	go m.loop(m.stopCh)
+	log.Printf("💽 磁盘空间监控已启动: 目录=%s, 告警=%.1f%%, 紧急=%.1f%%, 间隔=%v",
+		m.path, m.config.WarningPercent, m.config.CriticalPercent, m.config.CheckInterval)
+}
+
+// Stop 停止定期检查并清除状态
+func (m *Monitor) Stop() {
+	m.mu.Lock()
+	if !m.running {
+		m.mu.Unlock()
+		return
+	}
+	m.running = false
+	close(m.stopCh)
+	m.mu.Unlock()
+
+	m.wg.Wait()
+	current.Store(nil)
+}
+
+// loop 按间隔检查
+func (m *Monitor) loop(stopCh chan struct{}) {
+	defer m.wg.Done()
+
+	ticker := time.NewTicker(m.config.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+			m.Check()
+		}
+	}
+}
+
+// Check 检查一次磁盘使用率并更新状态，返回检查结果
+func (m *Monitor) Check() *Status {
+	total, available, err := diskUsage(existingDir(m.path))
+	if err != nil || total == 0 {
+		log.Printf("⚠️ 检查磁盘空间失败: %v", err)
+		return current.Load()
+	}
+
+	status := &Status{
+		State:          StateOK,
+		Path:           m.path,
+		UsedPercent:    float64(total-available) / float64(total) * 100,
+		TotalBytes:     total,
+		AvailableBytes: available,
+		CheckedAt:      time.Now().Unix(),
+	}
+	switch {
+	case status.UsedPercent >= m.config.CriticalPercent:
+		status.State = StateCritical
+	case status.UsedPercent >= m.config.WarningPercent:
+		status.State = StateWarning
+	}
+	setStatus(status)
+	return status
+}
+
+// existingDir 返回 path 本身或最近的已存在上级目录（数据目录可能尚未创建）
+func existingDir(path string) string {
+	dir, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+	for {
+		if _, err := os.Stat(dir); err == nil {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return dir
+		}
+		dir = parent
+	}
+}
@@ -0,0 +1,55 @@
+package disk_service
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestMonitorCheckThresholds(t *testing.T) {
+	dir := t.TempDir()
+
+	monitor := NewMonitor(&Config{WarningPercent: 99.98, CriticalPercent: 99.99}, filepath.Join(dir, "data"))
+	status := monitor.Check()
+	if status == nil {
+		t.Skip("当前平台不支持磁盘空间检查")
+	}
+	t.Cleanup(func() { current.Store(nil) })
+	if status.UsedPercent < 99.98 && status.State != StateOK {
+		t.Errorf("State = %s at %.2f%%, want %s", status.State, status.UsedPercent, StateOK)
+	}
+
+	monitor = NewMonitor(&Config{WarningPercent: 0.001, CriticalPercent: 0.002}, dir)
+	if status = monitor.Check(); status.UsedPercent > 0.002 && (status.State != StateCritical || !Lossy()) {
+		t.Errorf("State = %s at %.2f%%, want %s", status.State, status.UsedPercent, StateCritical)
+	}
+}
+
+func TestReportDiskFullEntersLossyMode(t *testing.T) {
+	current.Store(nil)
+	t.Cleanup(func() { current.Store(nil) })
+
+	if Lossy() {
+		t.Fatal("Lossy() = true before any report")
+	}
+	ReportDiskFull("/data/pebble")
+	if !Lossy() || CurrentStatus().Path != "/data/pebble" {
+		t.Errorf("after ReportDiskFull: status = %+v", CurrentStatus())
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		config  Config
+		wantErr bool
+	}{
+		{Config{WarningPercent: 85, CriticalPercent: 95}, false},
+		{Config{}, false},
+		{Config{WarningPercent: 95, CriticalPercent: 90}, true},
+		{Config{WarningPercent: 85, CriticalPercent: 101}, true},
+	}
+	for _, tt := range tests {
+		if err := tt.config.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("Validate(%+v) error = %v, wantErr %v", tt.config, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,14 @@
+//go:build !windows
+
+package disk_service
+
+import "syscall"
+
+// diskUsage 返回 path 所在文件系统的总空间和非特权用户可用空间（字节）
+func diskUsage(path string) (total, available uint64, err error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return 0, 0, err
+	}
+	return stat.Blocks * uint64(stat.Bsize), stat.Bavail * uint64(stat.Bsize), nil
+}
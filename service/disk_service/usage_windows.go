@@ -0,0 +1,10 @@
+//go:build windows
+
+package disk_service
+
+import "errors"
+
+// diskUsage Windows 暂不支持磁盘空间监控
+func diskUsage(path string) (total, available uint64, err error) {
+	return 0, 0, errors.New("当前平台不支持磁盘空间监控")
+}
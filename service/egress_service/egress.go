@@ -0,0 +1,127 @@
+package egress_service
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+)
+
+// ProxyDirect 直连，不使用代理（也不读取 HTTP_PROXY 等环境变量）
+const ProxyDirect = "direct"
+
+// 支持的代理协议
+var proxySchemes = map[string]bool{
+	"http":    true,
+	"https":   true,
+	"socks5":  true, // 本地解析域名
+	"socks5h": true, // 由代理解析域名
+}
+
+// Config 推送平台客户端的出站连接配置（代理和 TLS），每个推送平台单独配置
+type Config struct {
+	ProxyURL           string `yaml:"proxy_url" json:"proxy_url"`                       // 代理地址（http/https/socks5/socks5h，可带用户名密码）；为空时沿用 HTTP_PROXY 等环境变量，direct 表示直连
+	CAFile             string `yaml:"ca_file" json:"ca_file"`                           // 额外信任的 CA 证书（PEM），追加到系统根证书，用于解密审计的出口网关
+	ClientCertFile     string `yaml:"client_cert_file" json:"client_cert_file"`         // 客户端证书（PEM），出口网关要求双向 TLS 时使用
+	ClientKeyFile      string `yaml:"client_key_file" json:"client_key_file"`           // 客户端证书私钥（PEM）
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify" json:"insecure_skip_verify"` // 不校验服务端证书（仅用于测试环境）
+}
+
+// IsZero 是否未做任何配置
+func (c *Config) IsZero() bool {
+	return c == nil || *c == Config{}
+}
+
+// Validate 校验代理地址并加载证书文件
+func (c *Config) Validate() error {
+	if c.IsZero() {
+		return nil
+	}
+	if _, err := c.proxyURL(); err != nil {
+		return err
+	}
+	_, err := c.tlsConfig()
+	return err
+}
+
+// proxyURL 解析代理地址，未配置或直连时返回 nil
+func (c *Config) proxyURL() (*url.URL, error) {
+	if c.ProxyURL == "" || c.ProxyURL == ProxyDirect {
+		return nil, nil
+	}
+	proxyURL, err := url.Parse(c.ProxyURL)
+	if err != nil {
+		return nil, fmt.Errorf("代理地址无效: %w", err)
+	}
+	if !proxySchemes[proxyURL.Scheme] || proxyURL.Host == "" {
+		return nil, fmt.Errorf("代理地址无效: %s（支持 http、https、socks5、socks5h）", proxyURL.Redacted())
+	}
+	return proxyURL, nil
+}
+
+// tlsConfig 构建 TLS 配置，未配置证书且校验服务端证书时返回 nil（使用默认配置）
+func (c *Config) tlsConfig() (*tls.Config, error) {
+	if c.CAFile == "" && c.ClientCertFile == "" && c.ClientKeyFile == "" && !c.InsecureSkipVerify {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12, InsecureSkipVerify: c.InsecureSkipVerify}
+	if c.CAFile != "" {
+		pem, err := os.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("读取 CA 证书失败: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("CA 证书文件中没有有效的 PEM 证书: %s", c.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if c.ClientCertFile != "" || c.ClientKeyFile != "" {
+		if c.ClientCertFile == "" || c.ClientKeyFile == "" {
+			return nil, fmt.Errorf("客户端证书和私钥需同时配置")
+		}
+		cert, err := tls.LoadX509KeyPair(c.ClientCertFile, c.ClientKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("加载客户端证书失败: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
+// NewTransport 按配置创建 HTTP 传输层（在默认传输层的基础上设置代理和 TLS，保留 HTTP/2），
+// 未配置时返回 nil，调用方使用 http.DefaultTransport
+func NewTransport(config *Config) (http.RoundTripper, error) {
+	if config.IsZero() {
+		return nil, nil
+	}
+
+	proxyURL, err := config.proxyURL()
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig, err := config.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	switch {
+	case config.ProxyURL == "":
+		transport.Proxy = http.ProxyFromEnvironment
+	case proxyURL == nil:
+		transport.Proxy = nil
+	default:
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+	if tlsConfig != nil {
+		transport.TLSClientConfig = tlsConfig
+	}
+	return transport, nil
+}
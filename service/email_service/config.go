@@ -0,0 +1,69 @@
+package email_service
+
+import "time"
+
+// 邮件发送后端类型
+const (
+	BackendSMTP     = "smtp"     // SMTP 服务器
+	BackendSendGrid = "sendgrid" // SendGrid v3 Mail Send 接口
+)
+
+// DefaultSendGridEndpoint SendGrid 发信接口地址
+const DefaultSendGridEndpoint = "https://api.sendgrid.com/v3/mail/send"
+
+// Config 邮件发送配置
+type Config struct {
+	Backend       string         `yaml:"backend" json:"backend"`               // 发送后端：smtp / sendgrid
+	From          string         `yaml:"from" json:"from"`                     // 发件人地址
+	FromName      string         `yaml:"from_name" json:"from_name"`           // 发件人名称
+	SubjectPrefix string         `yaml:"subject_prefix" json:"subject_prefix"` // 邮件主题前缀
+	Timeout       time.Duration  `yaml:"timeout" json:"timeout"`               // 单封邮件发送超时
+	SMTP          SMTPConfig     `yaml:"smtp" json:"smtp"`                     // SMTP 配置（backend=smtp 时使用）
+	SendGrid      SendGridConfig `yaml:"sendgrid" json:"sendgrid"`             // SendGrid 配置（backend=sendgrid 时使用）
+}
+
+// SMTPConfig SMTP 服务器配置
+type SMTPConfig struct {
+	Host     string `yaml:"host" json:"host"`         // 服务器地址
+	Port     int    `yaml:"port" json:"port"`         // 端口，默认 587（服务器支持时使用 STARTTLS）
+	Username string `yaml:"username" json:"username"` // 用户名，为空时不认证
+	Password string `yaml:"password" json:"password"` // 密码
+}
+
+// SendGridConfig SendGrid 配置
+type SendGridConfig struct {
+	APIKey   string `yaml:"api_key" json:"api_key"`   // API Key
+	Endpoint string `yaml:"endpoint" json:"endpoint"` // 发信接口地址，默认 DefaultSendGridEndpoint
+}
+
+// DefaultConfig 返回默认配置
+func DefaultConfig() *Config {
+	return &Config{
+		Backend: BackendSMTP,
+		Timeout: 10 * time.Second,
+		SMTP: SMTPConfig{
+			Port: 587,
+		},
+		SendGrid: SendGridConfig{
+			Endpoint: DefaultSendGridEndpoint,
+		},
+	}
+}
+
+// ApplyDefaults 为缺失的配置项填充默认值
+func (c *Config) ApplyDefaults() {
+	defaults := DefaultConfig()
+
+	if c.Backend == "" {
+		c.Backend = defaults.Backend
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = defaults.Timeout
+	}
+	if c.SMTP.Port <= 0 {
+		c.SMTP.Port = defaults.SMTP.Port
+	}
+	if c.SendGrid.Endpoint == "" {
+		c.SendGrid.Endpoint = defaults.SendGrid.Endpoint
+	}
+}
@@ -0,0 +1,257 @@
+package email_service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Message 待发送的纯文本邮件
+type Message struct {
+	To      string // 收件人地址
+	Subject string // 主题
+	Text    string // 纯文本正文
+}
+
+// Sender 邮件发送接口，不同发送后端实现该接口即可接入
+type Sender interface {
+	// Name 返回发送后端名称
+	Name() string
+	// Send 发送邮件
+	Send(ctx context.Context, message *Message) error
+	// HealthCheck 检查发送后端是否可用
+	HealthCheck(ctx context.Context) error
+}
+
+// NewSender 根据配置创建邮件发送器
+func NewSender(config *Config) (Sender, error) {
+	if config == nil {
+		return nil, fmt.Errorf("邮件配置不能为空")
+	}
+	config.ApplyDefaults()
+
+	if _, err := mail.ParseAddress(config.From); err != nil {
+		return nil, fmt.Errorf("发件人地址无效: %q", config.From)
+	}
+
+	switch config.Backend {
+	case BackendSMTP:
+		if config.SMTP.Host == "" {
+			return nil, fmt.Errorf("SMTP 服务器地址不能为空")
+		}
+		return NewSMTPSender(config), nil
+	case BackendSendGrid:
+		if config.SendGrid.APIKey == "" {
+			return nil, fmt.Errorf("SendGrid API Key 不能为空")
+		}
+		return NewSendGridSender(config), nil
+	default:
+		return nil, fmt.Errorf("不支持的邮件发送后端: %s", config.Backend)
+	}
+}
+
+// ValidateAddress 验证收件人地址格式
+func ValidateAddress(address string) bool {
+	parsed, err := mail.ParseAddress(address)
+	return err == nil && parsed.Address == address
+}
+
+// SMTPSender 通过 SMTP 服务器发送邮件
+type SMTPSender struct {
+	config *Config
+}
+
+// NewSMTPSender 创建 SMTP 发送器
+func NewSMTPSender(config *Config) *SMTPSender {
+	return &SMTPSender{config: config}
+}
+
+// Name 返回发送后端名称
+func (s *SMTPSender) Name() string {
+	return BackendSMTP
+}
+
+// Send 发送邮件，服务器支持时使用 STARTTLS
+func (s *SMTPSender) Send(ctx context.Context, message *Message) error {
+	client, err := s.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if s.config.SMTP.Username != "" {
+		auth := smtp.PlainAuth("", s.config.SMTP.Username, s.config.SMTP.Password, s.config.SMTP.Host)
+		if err := client.Auth(auth); err != nil {
+			return fmt.Errorf("SMTP 认证失败: %w", err)
+		}
+	}
+	if err := client.Mail(s.config.From); err != nil {
+		return fmt.Errorf("SMTP 设置发件人失败: %w", err)
+	}
+	if err := client.Rcpt(message.To); err != nil {
+		return fmt.Errorf("SMTP 设置收件人失败: %w", err)
+	}
+
+	writer, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("SMTP 开始发送正文失败: %w", err)
+	}
+	if _, err := writer.Write(buildMIMEMessage(s.config, message, time.Now())); err != nil {
+		writer.Close()
+		return fmt.Errorf("SMTP 写入正文失败: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("SMTP 发送正文失败: %w", err)
+	}
+	return client.Quit()
+}
+
+// HealthCheck 检查 SMTP 服务器是否可连接
+func (s *SMTPSender) HealthCheck(ctx context.Context) error {
+	client, err := s.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	return client.Quit()
+}
+
+// dial 连接 SMTP 服务器并在支持时升级为 TLS，整个会话受 Timeout 和 ctx 截止时间约束
+func (s *SMTPSender) dial(ctx context.Context) (*smtp.Client, error) {
+	deadline := time.Now().Add(s.config.Timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+
+	addr := net.JoinHostPort(s.config.SMTP.Host, strconv.Itoa(s.config.SMTP.Port))
+	dialer := net.Dialer{Deadline: deadline}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("连接 SMTP 服务器失败: %w", err)
+	}
+	conn.SetDeadline(deadline)
+
+	client, err := smtp.NewClient(conn, s.config.SMTP.Host)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("SMTP 握手失败: %w", err)
+	}
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(nil); err != nil {
+			client.Close()
+			return nil, fmt.Errorf("SMTP STARTTLS 失败: %w", err)
+		}
+	}
+	return client, nil
+}
+
+// buildMIMEMessage 构建 UTF-8 纯文本邮件
+func buildMIMEMessage(config *Config, message *Message, now time.Time) []byte {
+	from := mail.Address{Name: config.FromName, Address: config.From}
+
+	var buf bytes.Buffer
+	buf.WriteString("From: " + from.String() + "\r\n")
+	buf.WriteString("To: " + message.To + "\r\n")
+	buf.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", message.Subject) + "\r\n")
+	buf.WriteString("Date: " + now.Format(time.RFC1123Z) + "\r\n")
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	buf.WriteString("\r\n")
+	buf.WriteString(strings.ReplaceAll(strings.ReplaceAll(message.Text, "\r\n", "\n"), "\n", "\r\n"))
+	return buf.Bytes()
+}
+
+// SendGridSender 通过 SendGrid v3 接口发送邮件
+type SendGridSender struct {
+	config *Config
+	client *http.Client
+}
+
+// NewSendGridSender 创建 SendGrid 发送器
+func NewSendGridSender(config *Config) *SendGridSender {
+	return &SendGridSender{
+		config: config,
+		client: &http.Client{Timeout: config.Timeout},
+	}
+}
+
+// sendGridAddress SendGrid 地址
+type sendGridAddress struct {
+	Email string `json:"email"`
+	Name  string `json:"name,omitempty"`
+}
+
+// sendGridPersonalization SendGrid 收件人分组
+type sendGridPersonalization struct {
+	To []sendGridAddress `json:"to"`
+}
+
+// sendGridContent SendGrid 邮件正文
+type sendGridContent struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// sendGridRequest SendGrid Mail Send 请求体
+type sendGridRequest struct {
+	Personalizations []sendGridPersonalization `json:"personalizations"`
+	From             sendGridAddress           `json:"from"`
+	Subject          string                    `json:"subject"`
+	Content          []sendGridContent         `json:"content"`
+}
+
+// Name 返回发送后端名称
+func (s *SendGridSender) Name() string {
+	return BackendSendGrid
+}
+
+// Send 调用 SendGrid 接口发送邮件
+func (s *SendGridSender) Send(ctx context.Context, message *Message) error {
+	body, err := json.Marshal(sendGridRequest{
+		Personalizations: []sendGridPersonalization{{To: []sendGridAddress{{Email: message.To}}}},
+		From:             sendGridAddress{Email: s.config.From, Name: s.config.FromName},
+		Subject:          message.Subject,
+		Content:          []sendGridContent{{Type: "text/plain", Value: message.Text}},
+	})
+	if err != nil {
+		return fmt.Errorf("序列化 SendGrid 请求失败: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.config.SendGrid.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("创建 SendGrid 请求失败: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+s.config.SendGrid.APIKey)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("请求 SendGrid 接口失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("SendGrid 接口返回错误 (HTTP %d): %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+	return nil
+}
+
+// HealthCheck SendGrid 没有免费的探活接口，只检查配置是否完整
+func (s *SendGridSender) HealthCheck(ctx context.Context) error {
+	if s.config.SendGrid.APIKey == "" {
+		return fmt.Errorf("SendGrid API Key 未配置")
+	}
+	return nil
+}
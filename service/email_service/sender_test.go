@@ -0,0 +1,90 @@
+package email_service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewSenderValidatesConfig(t *testing.T) {
+	if _, err := NewSender(&Config{Backend: BackendSMTP, From: "noreply@example.com"}); err == nil {
+		t.Errorf("缺少 SMTP 服务器地址时应返回错误")
+	}
+	if _, err := NewSender(&Config{Backend: BackendSendGrid, From: "noreply@example.com"}); err == nil {
+		t.Errorf("缺少 SendGrid API Key 时应返回错误")
+	}
+	if _, err := NewSender(&Config{Backend: "ses", From: "noreply@example.com"}); err == nil {
+		t.Errorf("未知发送后端应返回错误")
+	}
+	if _, err := NewSender(&Config{Backend: BackendSMTP, From: "not-an-address", SMTP: SMTPConfig{Host: "localhost"}}); err == nil {
+		t.Errorf("无效发件人地址应返回错误")
+	}
+}
+
+func TestSendGridSenderSend(t *testing.T) {
+	var received sendGridRequest
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		json.NewDecoder(r.Body).Decode(&received)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	sender, err := NewSender(&Config{
+		Backend:  BackendSendGrid,
+		From:     "noreply@example.com",
+		FromName: "MetaID",
+		SendGrid: SendGridConfig{APIKey: "sg-key", Endpoint: server.URL},
+	})
+	if err != nil {
+		t.Fatalf("NewSender() failed, err: %v", err)
+	}
+
+	if err := sender.Send(context.Background(), &Message{To: "user@example.com", Subject: "新消息", Text: "你好"}); err != nil {
+		t.Fatalf("Send() failed, err: %v", err)
+	}
+	if authorization != "Bearer sg-key" {
+		t.Errorf("Authorization = %q, want Bearer sg-key", authorization)
+	}
+	if received.Personalizations[0].To[0].Email != "user@example.com" || received.Subject != "新消息" || received.Content[0].Value != "你好" {
+		t.Errorf("SendGrid 请求不符合预期: %+v", received)
+	}
+}
+
+func TestSendGridSenderReportsError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"errors":[{"message":"bad key"}]}`, http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	sender := NewSendGridSender(&Config{From: "noreply@example.com", SendGrid: SendGridConfig{APIKey: "k", Endpoint: server.URL}, Timeout: time.Second})
+	err := sender.Send(context.Background(), &Message{To: "user@example.com"})
+	if err == nil || !strings.Contains(err.Error(), "bad key") {
+		t.Errorf("Send() error = %v, want HTTP 401 with body", err)
+	}
+}
+
+func TestBuildMIMEMessage(t *testing.T) {
+	config := &Config{From: "noreply@example.com", FromName: "MetaID"}
+	message := string(buildMIMEMessage(config, &Message{To: "user@example.com", Subject: "新消息", Text: "line1\nline2"}, time.Unix(0, 0)))
+
+	for _, want := range []string{
+		"From: \"MetaID\" <noreply@example.com>\r\n",
+		"To: user@example.com\r\n",
+		"Subject: =?utf-8?q?",
+		"Content-Type: text/plain; charset=UTF-8\r\n",
+		"\r\n\r\nline1\r\nline2",
+	} {
+		if !strings.Contains(message, want) {
+			t.Errorf("邮件内容缺少 %q:\n%s", want, message)
+		}
+	}
+	if !ValidateAddress("user@example.com") || ValidateAddress("User <user@example.com>") || ValidateAddress("user") {
+		t.Errorf("ValidateAddress 结果不符合预期")
+	}
+}
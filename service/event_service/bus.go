@@ -0,0 +1,170 @@
+// Package event_service 进程内的推送流水线事件总线：流水线各环节发布事件，管理接口订阅后实时推给运维看板
+package event_service
+
+import (
+	"push-base-service/models"
+	"push-base-service/service/metrics_service"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultBuffer 每个订阅者默认缓冲的事件数
+	DefaultBuffer = 256
+	// historySize 每种类型保留的最近事件数，供管理看板展示最近的推送和失败
+	historySize = 100
+)
+
+// eventsCounter 按事件类型和结果统计的事件数
+var eventsCounter = metrics_service.NewCounterVec(
+	"push_pipeline_events_total", "Number of pipeline events published to subscribers by type and result", "type", "result")
+
+// Subscription 一个事件订阅
+type Subscription struct {
+	events chan *models.PipelineEvent
+	types  map[string]bool // 只接收这些类型的事件，空表示全部
+}
+
+// Events 事件通道
+func (s *Subscription) Events() <-chan *models.PipelineEvent {
+	return s.events
+}
+
+// Bus 事件总线：订阅者缓冲已满时丢弃新事件，不阻塞推送流程；无论是否有订阅者都保留最近的事件
+type Bus struct {
+	mu          sync.RWMutex
+	subscribers map[*Subscription]struct{}
+
+	historyMu sync.Mutex
+	history   map[string][]*models.PipelineEvent // 事件类型 → 最近的事件（按时间顺序）
+}
+
+// NewBus 创建事件总线
+func NewBus() *Bus {
+	return &Bus{
+		subscribers: make(map[*Subscription]struct{}),
+		history:     make(map[string][]*models.PipelineEvent),
+	}
+}
+
+// Subscribe 订阅事件，types 为空时订阅全部类型；调用方结束后需调用 Unsubscribe
+func (b *Bus) Subscribe(buffer int, types ...string) *Subscription {
+	if buffer <= 0 {
+		buffer = DefaultBuffer
+	}
+	sub := &Subscription{events: make(chan *models.PipelineEvent, buffer)}
+	if len(types) > 0 {
+		sub.types = make(map[string]bool, len(types))
+		for _, eventType := range types {
+			sub.types[eventType] = true
+		}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.subscribers[sub] = struct{}{}
+	return sub
+}
+
+// Unsubscribe 取消订阅
+func (b *Bus) Unsubscribe(sub *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delete(b.subscribers, sub)
+}
+
+// Active 是否有订阅者，没有时发布方可跳过事件构造
+func (b *Bus) Active() bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return len(b.subscribers) > 0
+}
+
+// Publish 记录事件并发给所有订阅了该类型的订阅者，未设置时间的事件使用当前时间
+func (b *Bus) Publish(events ...*models.PipelineEvent) {
+	now := time.Now().UnixMilli()
+	for _, event := range events {
+		if event.Timestamp == 0 {
+			event.Timestamp = now
+		}
+	}
+	b.record(events)
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for _, event := range events {
+		for sub := range b.subscribers {
+			if len(sub.types) > 0 && !sub.types[event.Type] {
+				continue
+			}
+			select {
+			case sub.events <- event:
+				eventsCounter.Inc(event.Type, "delivered")
+			default:
+				eventsCounter.Inc(event.Type, "dropped")
+			}
+		}
+	}
+}
+
+// record 将事件写入对应类型的最近事件缓冲，超出保留数时丢弃最早的
+func (b *Bus) record(events []*models.PipelineEvent) {
+	b.historyMu.Lock()
+	defer b.historyMu.Unlock()
+
+	for _, event := range events {
+		history := append(b.history[event.Type], event)
+		if len(history) > historySize {
+			history = history[len(history)-historySize:]
+		}
+		b.history[event.Type] = history
+	}
+}
+
+// Recent 按时间倒序返回某类型最近的事件，limit 为 0 时返回全部保留的事件
+func (b *Bus) Recent(eventType string, limit int) []*models.PipelineEvent {
+	b.historyMu.Lock()
+	defer b.historyMu.Unlock()
+
+	history := b.history[eventType]
+	if limit <= 0 || limit > len(history) {
+		limit = len(history)
+	}
+	result := make([]*models.PipelineEvent, 0, limit)
+	for i := len(history) - 1; i >= len(history)-limit; i-- {
+		result = append(result, history[i])
+	}
+	return result
+}
+
+// defaultBus 全局事件总线
+var defaultBus = NewBus()
+
+// Subscribe 订阅全局事件总线
+func Subscribe(buffer int, types ...string) *Subscription {
+	return defaultBus.Subscribe(buffer, types...)
+}
+
+// Unsubscribe 取消订阅全局事件总线
+func Unsubscribe(sub *Subscription) {
+	defaultBus.Unsubscribe(sub)
+}
+
+// Active 全局事件总线是否有订阅者
+func Active() bool {
+	return defaultBus.Active()
+}
+
+// Publish 发布事件到全局事件总线
+func Publish(events ...*models.PipelineEvent) {
+	defaultBus.Publish(events...)
+}
+
+// Recent 全局事件总线某类型最近的事件
+func Recent(eventType string, limit int) []*models.PipelineEvent {
+	return defaultBus.Recent(eventType, limit)
+}
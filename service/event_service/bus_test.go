@@ -0,0 +1,62 @@
+package event_service
+
+import (
+	"push-base-service/models"
+	"testing"
+)
+
+func TestBusFiltersByTypeAndDropsWhenFull(t *testing.T) {
+	bus := NewBus()
+	if bus.Active() {
+		t.Fatal("Active() = true without subscribers")
+	}
+
+	all := bus.Subscribe(2)
+	failures := bus.Subscribe(10, models.PipelineEventFailed)
+	defer bus.Unsubscribe(failures)
+
+	bus.Publish(
+		&models.PipelineEvent{Type: models.PipelineEventSent, MetaID: "alice"},
+		&models.PipelineEvent{Type: models.PipelineEventFailed, MetaID: "bob"},
+		&models.PipelineEvent{Type: models.PipelineEventFailed, MetaID: "carol"},
+	)
+
+	// 缓冲为 2 的订阅者只收到前两条，第三条被丢弃
+	if len(all.Events()) != 2 {
+		t.Errorf("all subscriber buffered %d events, want 2", len(all.Events()))
+	}
+	if first := <-all.Events(); first.MetaID != "alice" || first.Timestamp == 0 {
+		t.Errorf("first event = %+v, want alice with timestamp", first)
+	}
+	if len(failures.Events()) != 2 {
+		t.Errorf("failures subscriber buffered %d events, want 2", len(failures.Events()))
+	}
+	if event := <-failures.Events(); event.Type != models.PipelineEventFailed || event.MetaID != "bob" {
+		t.Errorf("failures subscriber got %+v, want bob's failure", event)
+	}
+
+	bus.Unsubscribe(all)
+	bus.Publish(&models.PipelineEvent{Type: models.PipelineEventSent})
+	if len(all.Events()) != 1 {
+		t.Errorf("unsubscribed subscriber still receives events")
+	}
+}
+
+func TestBusKeepsRecentEventsPerType(t *testing.T) {
+	bus := NewBus()
+	for i := 0; i < historySize+10; i++ {
+		bus.Publish(&models.PipelineEvent{Type: models.PipelineEventSent, Count: i})
+	}
+	bus.Publish(&models.PipelineEvent{Type: models.PipelineEventFailed, MetaID: "bob"})
+
+	recent := bus.Recent(models.PipelineEventSent, 3)
+	if len(recent) != 3 || recent[0].Count != historySize+9 || recent[2].Count != historySize+7 {
+		t.Errorf("Recent(sent, 3) = %+v, want newest first", recent)
+	}
+	if all := bus.Recent(models.PipelineEventSent, 0); len(all) != historySize || all[historySize-1].Count != 10 {
+		t.Errorf("Recent(sent, 0) kept %d events, want %d ending at 10", len(all), historySize)
+	}
+	if failed := bus.Recent(models.PipelineEventFailed, 10); len(failed) != 1 || failed[0].MetaID != "bob" {
+		t.Errorf("Recent(failed) = %+v, want bob", failed)
+	}
+}
@@ -7,26 +7,85 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 )
 
 const (
+	// Expo Push API host; override with Client.SetBaseURL (e.g. for a test server)
+	DefaultBaseURL = "https://exp.host"
+
+	// Expo Push API paths, relative to the base URL
+	PushPath    = "/--/api/v2/push/send"
+	ReceiptPath = "/--/api/v2/push/getReceipts"
+
 	// Expo Push API endpoints
-	PushURL    = "https://exp.host/--/api/v2/push/send"
-	ReceiptURL = "https://exp.host/--/api/v2/push/getReceipts"
+	PushURL    = DefaultBaseURL + PushPath
+	ReceiptURL = DefaultBaseURL + ReceiptPath
 
 	// Max messages per request
 	MaxMessagesPerRequest = 100
 
 	// Default timeout
 	DefaultTimeout = 30 * time.Second
+
+	// Pause used when a rate-limit response carries no usable Retry-After header
+	DefaultRateLimitRetryAfter = 5 * time.Second
+
+	// Error code Expo returns (with HTTP 429) when the project exceeds its request rate
+	ErrorCodeTooManyRequests = "TOO_MANY_REQUESTS"
 )
 
+// RateLimitError is returned when Expo rejects a request because of rate limiting
+type RateLimitError struct {
+	RetryAfter time.Duration // How long to wait before sending again
+	Message    string        // Response body or API error message
+}
+
+// Error implements the error interface
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("rate limited by Expo (retry after %v): %s", e.RetryAfter, e.Message)
+}
+
+// parseRetryAfter parses a Retry-After header given in seconds or as an HTTP date
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return DefaultRateLimitRetryAfter
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds <= 0 {
+			return DefaultRateLimitRetryAfter
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil && at.After(now) {
+		return at.Sub(now)
+	}
+	return DefaultRateLimitRetryAfter
+}
+
+// checkRateLimit converts a 429 response, or a TOO_MANY_REQUESTS API error, into a RateLimitError
+func checkRateLimit(resp *http.Response, body []byte, errs []APIError) error {
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return &RateLimitError{RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()), Message: string(body)}
+	}
+	for _, apiErr := range errs {
+		if apiErr.Code == ErrorCodeTooManyRequests {
+			return &RateLimitError{RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()), Message: apiErr.Message}
+		}
+	}
+	return nil
+}
+
 // Client represents the Expo push notification client
 type Client struct {
 	httpClient  *http.Client
 	timeout     time.Duration
 	accessToken string // Expo Access Token
+	pushURL     string // Push endpoint, PushURL unless a base URL is set
+	receiptURL  string // Receipt endpoint, ReceiptURL unless a base URL is set
 }
 
 // NewClient creates a new Expo push notification client
@@ -35,7 +94,9 @@ func NewClient() *Client {
 		httpClient: &http.Client{
 			Timeout: DefaultTimeout,
 		},
-		timeout: DefaultTimeout,
+		timeout:    DefaultTimeout,
+		pushURL:    PushURL,
+		receiptURL: ReceiptURL,
 	}
 }
 
@@ -45,7 +106,9 @@ func NewClientWithTimeout(timeout time.Duration) *Client {
 		httpClient: &http.Client{
 			Timeout: timeout,
 		},
-		timeout: timeout,
+		timeout:    timeout,
+		pushURL:    PushURL,
+		receiptURL: ReceiptURL,
 	}
 }
 
@@ -57,6 +120,8 @@ func NewClientWithAccessToken(accessToken string) *Client {
 		},
 		timeout:     DefaultTimeout,
 		accessToken: accessToken,
+		pushURL:     PushURL,
+		receiptURL:  ReceiptURL,
 	}
 }
 
@@ -68,14 +133,32 @@ func NewClientWithConfig(accessToken string, timeout time.Duration) *Client {
 		},
 		timeout:     timeout,
 		accessToken: accessToken,
+		pushURL:     PushURL,
+		receiptURL:  ReceiptURL,
 	}
 }
 
+// SetBaseURL points the client at another Expo-compatible host (a proxy or a test server);
+// an empty URL restores the default exp.host endpoints
+func (c *Client) SetBaseURL(baseURL string) {
+	baseURL = strings.TrimRight(baseURL, "/")
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	c.pushURL = baseURL + PushPath
+	c.receiptURL = baseURL + ReceiptPath
+}
+
+// SetTransport replaces the HTTP transport used for Expo requests; nil restores http.DefaultTransport
+func (c *Client) SetTransport(transport http.RoundTripper) {
+	c.httpClient.Transport = transport
+}
+
 // PushMessage represents a push notification message
 type PushMessage struct {
 	To                []string               `json:"to,omitempty"`                // Push tokens
 	Title             string                 `json:"title,omitempty"`             // Notification title
-	Body              string                 `json:"body"`                        // Notification body
+	Body              string                 `json:"body,omitempty"`              // Notification body (omitted for data-only pushes)
 	Data              map[string]interface{} `json:"data,omitempty"`              // Custom data
 	Sound             string                 `json:"sound,omitempty"`             // Sound to play
 	TTL               int                    `json:"ttl,omitempty"`               // Time to live in seconds
@@ -85,6 +168,9 @@ type PushMessage struct {
 	Badge             *int                   `json:"badge,omitempty"`             // iOS badge number
 	ChannelID         string                 `json:"channelId,omitempty"`         // Android channel ID
 	CategoryID        string                 `json:"categoryId,omitempty"`        // Notification category
+	CollapseID        string                 `json:"collapseId,omitempty"`        // Replaces earlier notifications with the same ID (APNs collapse-id / FCM collapse key)
+	ThreadID          string                 `json:"threadId,omitempty"`          // Groups notifications with the same ID (iOS thread-id / Android tag)
+	ContentAvailable  bool                   `json:"_contentAvailable,omitempty"` // iOS content-available background push
 	MutableContent    bool                   `json:"mutableContent,omitempty"`    // iOS mutable content
 	InterruptionLevel string                 `json:"interruptionLevel,omitempty"` // iOS interruption level
 	RichContent       *RichContent           `json:"richContent,omitempty"`       // Rich content
@@ -167,7 +253,7 @@ func (c *Client) SendPushNotifications(ctx context.Context, messages []*PushMess
 	}
 
 	// Create request
-	req, err := http.NewRequestWithContext(ctx, "POST", PushURL, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "POST", c.pushURL, bytes.NewBuffer(jsonData))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -196,6 +282,9 @@ func (c *Client) SendPushNotifications(ctx context.Context, messages []*PushMess
 
 	// Check status code
 	if resp.StatusCode != http.StatusOK {
+		if err := checkRateLimit(resp, body, nil); err != nil {
+			return nil, err
+		}
 		return nil, fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(body))
 	}
 
@@ -204,6 +293,9 @@ func (c *Client) SendPushNotifications(ctx context.Context, messages []*PushMess
 	if err := json.Unmarshal(body, &pushResponse); err != nil {
 		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
+	if err := checkRateLimit(resp, body, pushResponse.Errors); err != nil {
+		return nil, err
+	}
 
 	return &pushResponse, nil
 }
@@ -221,7 +313,7 @@ func (c *Client) GetPushReceipts(ctx context.Context, receiptIDs []string) (*Rec
 	}
 
 	// Create request
-	req, err := http.NewRequestWithContext(ctx, "POST", ReceiptURL, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "POST", c.receiptURL, bytes.NewBuffer(jsonData))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -249,6 +341,9 @@ func (c *Client) GetPushReceipts(ctx context.Context, receiptIDs []string) (*Rec
 
 	// Check status code
 	if resp.StatusCode != http.StatusOK {
+		if err := checkRateLimit(resp, body, nil); err != nil {
+			return nil, err
+		}
 		return nil, fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(body))
 	}
 
@@ -0,0 +1,171 @@
+package expo_service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSendTickets(t *testing.T) {
+	server := newFakeExpoServer(t)
+	service := NewServiceWithConfig(server.client(), 0, time.Millisecond)
+
+	tokens := []string{"ExponentPushToken[ok]", "ExponentPushToken[unregistered]"}
+	results := service.SendBulkNotifications(context.Background(), tokens, "title", "body", map[string]interface{}{"pinId": "pin-1"})
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want 2", len(results))
+	}
+	if !results[0].Success || results[0].ReceiptID != "ticket-1" {
+		t.Errorf("results[0] = %+v, want ok ticket", results[0])
+	}
+	if results[1].Success || results[1].Error == nil || !strings.Contains(results[1].Error.Error(), "DeviceNotRegistered") {
+		t.Errorf("results[1] = %+v, want DeviceNotRegistered error", results[1])
+	}
+
+	if len(server.pushed) != 1 || len(server.pushed[0]) != 2 {
+		t.Fatalf("pushed = %v, want one request with two messages", server.pushed)
+	}
+	if got := server.pushed[0][0].Data["pinId"]; got != "pin-1" {
+		t.Errorf("data.pinId = %v, want pin-1", got)
+	}
+	if got := server.authHeaders[0]; got != "Bearer test-access-token" {
+		t.Errorf("Authorization = %q", got)
+	}
+}
+
+func TestCheckReceipts(t *testing.T) {
+	server := newFakeExpoServer(t)
+	server.receipts["r-ok"] = PushReceipt{Status: "ok"}
+	server.receipts["r-gone"] = PushReceipt{
+		Status:  "error",
+		Message: "device gone",
+		Details: &ReceiptDetails{Error: "DeviceNotRegistered"},
+	}
+	service := NewServiceWithConfig(server.client(), 0, time.Millisecond)
+
+	results, err := service.CheckReceipts(context.Background(), []string{"r-ok", "r-gone", "r-pending"})
+	if err != nil {
+		t.Fatalf("CheckReceipts() error = %v", err)
+	}
+	if r := results["r-ok"]; r == nil || !r.Delivered {
+		t.Errorf("r-ok = %+v, want delivered", r)
+	}
+	if r := results["r-gone"]; r == nil || r.Delivered || !r.DeviceUnregistered {
+		t.Errorf("r-gone = %+v, want device unregistered", r)
+	}
+	if _, ok := results["r-pending"]; ok {
+		t.Error("receipts not yet available should be absent")
+	}
+}
+
+func TestAPIErrorStatus(t *testing.T) {
+	server := newFakeExpoServer(t)
+	server.failStatus = http.StatusInternalServerError
+	client := server.client()
+
+	if _, err := client.SendPushNotification(context.Background(), CreateSimpleMessage("ExponentPushToken[x]", "t", "b")); err == nil || !strings.Contains(err.Error(), "status 500") {
+		t.Errorf("SendPushNotification() error = %v, want status 500", err)
+	}
+	if _, err := client.GetPushReceipts(context.Background(), []string{"r-1"}); err == nil || !strings.Contains(err.Error(), "status 500") {
+		t.Errorf("GetPushReceipts() error = %v, want status 500", err)
+	}
+
+	// retries exhaust and the last error is reported
+	service := NewServiceWithConfig(client, 2, time.Millisecond)
+	result := service.SendSingleNotification(context.Background(), "ExponentPushToken[x]", "t", "b", nil, "")
+	if result.Success || result.Error == nil || result.Retry != 2 {
+		t.Errorf("result = %+v, want failure after 2 retries", result)
+	}
+	if n := len(server.authHeaders); n != 5 {
+		t.Errorf("server saw %d requests, want 5", n)
+	}
+}
+
+func TestRateLimitResponse(t *testing.T) {
+	server := newFakeExpoServer(t)
+	server.rateLimited = 1
+	server.retryAfter = 7
+
+	_, err := server.client().SendPushNotification(context.Background(), CreateSimpleMessage("ExponentPushToken[x]", "t", "b"))
+	var rateLimitErr *RateLimitError
+	if !errors.As(err, &rateLimitErr) {
+		t.Fatalf("error = %v, want RateLimitError", err)
+	}
+	if rateLimitErr.RetryAfter != 7*time.Second {
+		t.Errorf("RetryAfter = %v, want 7s", rateLimitErr.RetryAfter)
+	}
+
+	// the limit has passed on the server side; the next request succeeds
+	response, err := server.client().SendPushNotification(context.Background(), CreateSimpleMessage("ExponentPushToken[x]", "t", "b"))
+	if err != nil || len(response.Data) != 1 || response.Data[0].Status != "ok" {
+		t.Errorf("response = %+v, err = %v", response, err)
+	}
+}
+
+// countingTransport counts requests passed to the wrapped transport
+type countingTransport struct {
+	count atomic.Int32
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.count.Add(1)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestManagerUsesConfiguredEndpointAndTransport(t *testing.T) {
+	server := newFakeExpoServer(t)
+	transport := &countingTransport{}
+	manager := NewManagerWithConfig(&Config{
+		AccessToken: "test-access-token",
+		BaseURL:     server.URL + "/",
+		Transport:   transport,
+	})
+
+	result, err := manager.SendNotification(context.Background(), "ExponentPushToken[manager]", "title", "body")
+	if err != nil || !result.Success {
+		t.Fatalf("SendNotification() = %+v, %v", result, err)
+	}
+	if transport.count.Load() != 1 {
+		t.Errorf("transport saw %d requests, want 1", transport.count.Load())
+	}
+
+	// the endpoint and transport survive a config update
+	if err := manager.UpdateConfig(&Config{BaseURL: server.URL, Transport: transport}); err != nil {
+		t.Fatalf("UpdateConfig() error = %v", err)
+	}
+	if result, err := manager.SendNotification(context.Background(), "ExponentPushToken[manager]", "title", "body"); err != nil || !result.Success {
+		t.Fatalf("SendNotification() after update = %+v, %v", result, err)
+	}
+	if transport.count.Load() != 2 {
+		t.Errorf("transport saw %d requests, want 2", transport.count.Load())
+	}
+}
+
+func TestSendCustomMessageKeepsAllFields(t *testing.T) {
+	server := newFakeExpoServer(t)
+	manager := NewManagerWithConfig(&Config{BaseURL: server.URL})
+
+	badge := 3
+	result, err := manager.SendCustomMessage(context.Background(), &PushMessage{
+		To:         []string{"ExponentPushToken[custom]"},
+		Title:      "title",
+		Body:       "body",
+		Priority:   "high",
+		ChannelID:  "candy_bag",
+		CollapseID: "chat-1",
+		ThreadID:   "chat-1",
+		Badge:      &badge,
+	})
+	if err != nil || !result.Success {
+		t.Fatalf("SendCustomMessage() = %+v, %v", result, err)
+	}
+
+	sent := server.pushed[0][0]
+	if sent.Priority != "high" || sent.ChannelID != "candy_bag" || sent.CollapseID != "chat-1" || sent.ThreadID != "chat-1" || sent.Badge == nil || *sent.Badge != 3 {
+		t.Errorf("sent message = %+v", sent)
+	}
+}
@@ -1,6 +1,9 @@
 package expo_service
 
 import (
+	"fmt"
+	"net/http"
+	"push-base-service/service/egress_service"
 	"time"
 )
 
@@ -10,9 +13,13 @@ type Config struct {
 	AccessToken string `yaml:"access_token" json:"access_token"` // Expo Access Token (required for production)
 
 	// HTTP client settings
-	Timeout    time.Duration `yaml:"timeout" json:"timeout"`         // Request timeout
-	MaxRetries int           `yaml:"max_retries" json:"max_retries"` // Maximum number of retries
-	BaseDelay  time.Duration `yaml:"base_delay" json:"base_delay"`   // Base delay for exponential backoff
+	Timeout    time.Duration     `yaml:"timeout" json:"timeout"`         // Request timeout
+	MaxRetries int               `yaml:"max_retries" json:"max_retries"` // Maximum number of retries
+	BaseDelay  time.Duration     `yaml:"base_delay" json:"base_delay"`   // Base delay for exponential backoff
+	BaseURL    string            `yaml:"base_url" json:"base_url"`       // Expo API host, defaults to https://exp.host
+	Transport  http.RoundTripper `yaml:"-" json:"-"`                     // Custom HTTP transport (tests), takes precedence over Egress
+	// Outbound proxy and TLS settings for Expo requests; unset keeps http.DefaultTransport (HTTP_PROXY etc. from the environment)
+	Egress *egress_service.Config `yaml:"egress" json:"egress"`
 
 	// Push notification settings
 	DefaultSound    string `yaml:"default_sound" json:"default_sound"`       // Default sound for notifications
@@ -96,5 +103,9 @@ func (c *Config) Validate() error {
 		c.DefaultPriority = "normal"
 	}
 
+	if err := c.Egress.Validate(); err != nil {
+		return fmt.Errorf("invalid egress config: %w", err)
+	}
+
 	return nil
 }
@@ -0,0 +1,127 @@
+package expo_service
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeExpoServer is an httptest server speaking the Expo push and receipt API.
+// Tokens containing "unregistered" get a DeviceNotRegistered error ticket, every other
+// token gets an ok ticket; receipts are answered from the receipts map
+type fakeExpoServer struct {
+	*httptest.Server
+
+	mu          sync.Mutex
+	rateLimited int                    // Number of upcoming requests answered with 429
+	retryAfter  int                    // Retry-After seconds sent with 429 responses
+	failStatus  int                    // Non-zero: answer every request with this status
+	receipts    map[string]PushReceipt // Receipt ID -> receipt
+	pushed      [][]*PushMessage       // Messages of each accepted push request
+	authHeaders []string               // Authorization header of each request
+	ticketSeq   int
+}
+
+// newFakeExpoServer starts a fake Expo server, closed when the test ends
+func newFakeExpoServer(t *testing.T) *fakeExpoServer {
+	t.Helper()
+	f := &fakeExpoServer{receipts: make(map[string]PushReceipt)}
+	mux := http.NewServeMux()
+	mux.HandleFunc(PushPath, f.handlePush)
+	mux.HandleFunc(ReceiptPath, f.handleReceipts)
+	f.Server = httptest.NewServer(mux)
+	t.Cleanup(f.Close)
+	return f
+}
+
+// client returns a client sending to the fake server
+func (f *fakeExpoServer) client() *Client {
+	client := NewClientWithAccessToken("test-access-token")
+	client.SetBaseURL(f.URL)
+	return client
+}
+
+// reject answers the request with a configured error, if any
+func (f *fakeExpoServer) reject(w http.ResponseWriter, r *http.Request) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.authHeaders = append(f.authHeaders, r.Header.Get("Authorization"))
+	if f.rateLimited > 0 {
+		f.rateLimited--
+		w.Header().Set("Retry-After", strconv.Itoa(f.retryAfter))
+		w.WriteHeader(http.StatusTooManyRequests)
+		io.WriteString(w, `{"errors":[{"code":"TOO_MANY_REQUESTS","message":"rate limited"}]}`)
+		return true
+	}
+	if f.failStatus != 0 {
+		w.WriteHeader(f.failStatus)
+		io.WriteString(w, `{"errors":[{"code":"INTERNAL_SERVER_ERROR","message":"boom"}]}`)
+		return true
+	}
+	return false
+}
+
+func (f *fakeExpoServer) handlePush(w http.ResponseWriter, r *http.Request) {
+	if f.reject(w, r) {
+		return
+	}
+
+	body, _ := io.ReadAll(r.Body)
+	var messages []*PushMessage
+	if err := json.Unmarshal(body, &messages); err != nil {
+		var message PushMessage
+		if err := json.Unmarshal(body, &message); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		messages = []*PushMessage{&message}
+	}
+
+	f.mu.Lock()
+	f.pushed = append(f.pushed, messages)
+	response := PushResponse{}
+	for _, message := range messages {
+		if len(message.To) > 0 && strings.Contains(message.To[0], "unregistered") {
+			response.Data = append(response.Data, PushTicket{
+				Status:  "error",
+				Message: "not a registered push notification recipient",
+				Details: map[string]interface{}{"error": "DeviceNotRegistered"},
+			})
+			continue
+		}
+		f.ticketSeq++
+		response.Data = append(response.Data, PushTicket{Status: "ok", ID: "ticket-" + strconv.Itoa(f.ticketSeq)})
+	}
+	f.mu.Unlock()
+
+	json.NewEncoder(w).Encode(response)
+}
+
+func (f *fakeExpoServer) handleReceipts(w http.ResponseWriter, r *http.Request) {
+	if f.reject(w, r) {
+		return
+	}
+
+	var request ReceiptRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	f.mu.Lock()
+	response := ReceiptResponse{Data: make(map[string]PushReceipt)}
+	for _, id := range request.IDs {
+		if receipt, ok := f.receipts[id]; ok {
+			response.Data[id] = receipt
+		}
+	}
+	f.mu.Unlock()
+
+	json.NewEncoder(w).Encode(response)
+}
@@ -3,6 +3,8 @@ package expo_service
 import (
 	"context"
 	"fmt"
+	"log"
+	"push-base-service/service/egress_service"
 	"sync"
 	"time"
 )
@@ -35,6 +37,24 @@ func NewManagerWithConfig(config *Config) *Manager {
 		config.Validate()
 	}
 
+	service := NewServiceWithConfig(newClientFromConfig(config), config.MaxRetries, config.BaseDelay)
+
+	return &Manager{
+		service: service,
+		config:  config,
+	}
+}
+
+// currentService returns the service in use, safe against a concurrent UpdateConfig
+func (m *Manager) currentService() *Service {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.service
+}
+
+// newClientFromConfig creates a client with the access token, endpoint and transport (or egress settings) from config
+func newClientFromConfig(config *Config) *Client {
 	// 根据是否有 Access Token 创建不同的客户端
 	var client *Client
 	if config.AccessToken != "" {
@@ -42,13 +62,21 @@ func NewManagerWithConfig(config *Config) *Manager {
 	} else {
 		client = NewClientWithTimeout(config.Timeout)
 	}
-
-	service := NewServiceWithConfig(client, config.MaxRetries, config.BaseDelay)
-
-	return &Manager{
-		service: service,
-		config:  config,
+	if config.BaseURL != "" {
+		client.SetBaseURL(config.BaseURL)
+	}
+	if config.Transport != nil {
+		client.SetTransport(config.Transport)
+	} else if !config.Egress.IsZero() {
+		// Egress settings were checked by Validate; a failure here (e.g. a CA file removed since) keeps the default transport
+		transport, err := egress_service.NewTransport(config.Egress)
+		if err != nil {
+			log.Printf("Failed to apply Expo egress config, using default transport: %v", err)
+		} else {
+			client.SetTransport(transport)
+		}
 	}
+	return client
 }
 
 // SendNotification sends a simple notification
@@ -57,7 +85,7 @@ func (m *Manager) SendNotification(ctx context.Context, token, title, body strin
 		return nil, fmt.Errorf("invalid push token: %s", token)
 	}
 
-	result := m.service.SendSingleNotification(ctx, token, title, body, nil, "default")
+	result := m.currentService().SendSingleNotification(ctx, token, title, body, nil, "default")
 	return result, nil
 }
 
@@ -67,7 +95,7 @@ func (m *Manager) SendNotificationWithData(ctx context.Context, token, title, bo
 		return nil, fmt.Errorf("invalid push token: %s", token)
 	}
 
-	result := m.service.SendSingleNotification(ctx, token, title, body, data, "default")
+	result := m.currentService().SendSingleNotification(ctx, token, title, body, data, "default")
 	return result, nil
 }
 
@@ -85,7 +113,7 @@ func (m *Manager) SendBulkNotifications(ctx context.Context, tokens []string, ti
 		return nil, fmt.Errorf("no valid push tokens provided")
 	}
 
-	results := m.service.SendBulkNotifications(ctx, validTokens, title, body, nil)
+	results := m.currentService().SendBulkNotifications(ctx, validTokens, title, body, nil)
 	return results, nil
 }
 
@@ -103,7 +131,7 @@ func (m *Manager) SendBulkNotificationsWithData(ctx context.Context, tokens []st
 		return nil, fmt.Errorf("no valid push tokens provided")
 	}
 
-	results := m.service.SendBulkNotifications(ctx, validTokens, title, body, data)
+	results := m.currentService().SendBulkNotifications(ctx, validTokens, title, body, data)
 	return results, nil
 }
 
@@ -128,20 +156,13 @@ func (m *Manager) SendCustomMessage(ctx context.Context, message *PushMessage) (
 	// Apply default values from config
 	m.applyDefaults(message)
 
-	// For single token, use single notification method
-	if len(validTokens) == 1 {
-		message.To = validTokens
-		return m.service.SendSingleNotification(ctx, validTokens[0], message.Title, message.Body, message.Data, message.Sound), nil
-	}
-
-	// For multiple tokens, we need to send individually or create multiple messages
-	// For simplicity, we'll send to the first token only in this method
-	// Use SendBulkCustomMessages for multiple tokens
+	// Only the first valid token is sent to; use SendBulkCustomMessages for multiple tokens
 	message.To = []string{validTokens[0]}
-	return m.service.SendSingleNotification(ctx, validTokens[0], message.Title, message.Body, message.Data, message.Sound), nil
+	return m.currentService().SendMessage(ctx, message), nil
 }
 
-// SendBulkCustomMessages sends custom messages to multiple recipients
+// SendBulkCustomMessages sends custom messages to multiple recipients; once ctx is done the
+// remaining batches are not sent and their results carry the context error
 func (m *Manager) SendBulkCustomMessages(ctx context.Context, messages []*PushMessage) ([]*SendNotificationResult, error) {
 	if len(messages) == 0 {
 		return nil, fmt.Errorf("no messages provided")
@@ -150,7 +171,7 @@ func (m *Manager) SendBulkCustomMessages(ctx context.Context, messages []*PushMe
 	var allResults []*SendNotificationResult
 
 	// Process messages in batches
-	batchSize := m.config.BatchSize
+	batchSize := m.GetConfig().BatchSize
 	for i := 0; i < len(messages); i += batchSize {
 		end := i + batchSize
 		if end > len(messages) {
@@ -172,8 +193,12 @@ func (m *Manager) SendBulkCustomMessages(ctx context.Context, messages []*PushMe
 			continue
 		}
 
-		// Send batch
-		response, err := m.service.client.SendPushNotifications(ctx, validMessages)
+		// Send batch, unless the caller has already given up
+		err := ctx.Err()
+		var response *PushResponse
+		if err == nil {
+			response, err = m.currentService().send(ctx, validMessages)
+		}
 		if err != nil {
 			// Create error results for all messages in batch
 			for _, msg := range validMessages {
@@ -213,7 +238,7 @@ func (m *Manager) SendBulkCustomMessages(ctx context.Context, messages []*PushMe
 
 // CheckReceipts checks the delivery status of sent notifications
 func (m *Manager) CheckReceipts(ctx context.Context, receiptIDs []string) (map[string]*ReceiptResult, error) {
-	return m.service.CheckReceipts(ctx, receiptIDs)
+	return m.currentService().CheckReceipts(ctx, receiptIDs)
 }
 
 // applyDefaults applies configuration defaults to a message
@@ -250,8 +275,9 @@ func (m *Manager) UpdateConfig(config *Config) error {
 	m.config = config
 
 	// Recreate client and service with new config
-	client := NewClientWithTimeout(config.Timeout)
-	m.service = NewServiceWithConfig(client, config.MaxRetries, config.BaseDelay)
+	service := NewServiceWithConfig(newClientFromConfig(config), config.MaxRetries, config.BaseDelay)
+	service.gate = m.service.gate // Keep an active rate-limit pause
+	m.service = service
 
 	return nil
 }
@@ -266,6 +292,11 @@ func (m *Manager) GetConfig() *Config {
 	return &configCopy
 }
 
+// RateLimitedUntil returns when the current Expo rate-limit pause ends, zero if sending is not paused
+func (m *Manager) RateLimitedUntil() time.Time {
+	return m.currentService().RateLimitedUntil()
+}
+
 // HealthCheck performs a basic health check of the service
 func (m *Manager) HealthCheck(ctx context.Context) error {
 	// Create a test message with invalid token to check API connectivity
@@ -279,7 +310,7 @@ func (m *Manager) HealthCheck(ctx context.Context) error {
 	healthCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
-	_, err := m.service.client.SendPushNotification(healthCtx, testMessage)
+	_, err := m.currentService().client.SendPushNotification(healthCtx, testMessage)
 
 	// We expect this to fail with an API response, not a network error
 	// If we get a response (even an error response), the service is healthy
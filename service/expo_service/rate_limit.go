@@ -0,0 +1,67 @@
+package expo_service
+
+import (
+	"context"
+	"log"
+	"push-base-service/service/metrics_service"
+	"sync"
+	"time"
+)
+
+// Rate-limit metrics
+var (
+	rateLimitedCounter = metrics_service.NewCounterVec(
+		"push_expo_rate_limited_total", "Number of Expo requests rejected with a rate-limit response")
+	rateLimitPausedGauge = metrics_service.NewGaugeVec(
+		"push_expo_rate_limit_paused", "Whether Expo sending is paused because of a rate-limit response (1) or not (0)")
+)
+
+// rateLimitGate pauses every request of a Service after Expo reports a rate limit,
+// so concurrent and queued sends wait out the Retry-After window together and resume automatically
+type rateLimitGate struct {
+	mu          sync.Mutex
+	pausedUntil time.Time
+}
+
+// pause stops sending for d; an existing longer pause is kept
+func (g *rateLimitGate) pause(d time.Duration) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	until := time.Now().Add(d)
+	if until.After(g.pausedUntil) {
+		g.pausedUntil = until
+		rateLimitPausedGauge.Set(1)
+		log.Printf("⏸️ Expo rate limit hit, pausing sends for %v", d)
+	}
+}
+
+// until returns the end of the current pause, zero if sending is not paused
+func (g *rateLimitGate) until() time.Time {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if time.Now().Before(g.pausedUntil) {
+		return g.pausedUntil
+	}
+	return time.Time{}
+}
+
+// wait blocks until the pause (if any) is over or ctx is done
+func (g *rateLimitGate) wait(ctx context.Context) error {
+	for {
+		until := g.until()
+		if until.IsZero() {
+			rateLimitPausedGauge.Set(0)
+			return nil
+		}
+
+		timer := time.NewTimer(time.Until(until))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
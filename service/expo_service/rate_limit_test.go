@@ -0,0 +1,87 @@
+package expo_service
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// scriptedTransport answers Expo requests with a rate-limit response first, then success
+type scriptedTransport struct {
+	mu       sync.Mutex
+	requests []time.Time
+}
+
+func (t *scriptedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.mu.Lock()
+	t.requests = append(t.requests, time.Now())
+	count := len(t.requests)
+	t.mu.Unlock()
+
+	if count == 1 {
+		return &http.Response{
+			StatusCode: http.StatusTooManyRequests,
+			Header:     http.Header{"Retry-After": []string{"1"}},
+			Body:       io.NopCloser(strings.NewReader(`{"errors":[{"code":"TOO_MANY_REQUESTS","message":"rate limited"}]}`)),
+			Request:    req,
+		}, nil
+	}
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{},
+		Body:       io.NopCloser(strings.NewReader(`{"data":[{"status":"ok","id":"receipt-1"}]}`)),
+		Request:    req,
+	}, nil
+}
+
+func TestRateLimitPausesAllSends(t *testing.T) {
+	transport := &scriptedTransport{}
+	client := NewClient()
+	client.httpClient.Transport = transport
+	service := NewServiceWithConfig(client, 2, time.Hour) // exponential backoff would take an hour; rate-limit retries must not use it
+
+	start := time.Now()
+	result := service.SendSingleNotification(context.Background(), "ExponentPushToken[rate-limit]", "title", "body", nil, "")
+	if !result.Success || result.ReceiptID != "receipt-1" {
+		t.Fatalf("result = %+v", result)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second || elapsed > 5*time.Second {
+		t.Errorf("send took %v, want about the 1s Retry-After", elapsed)
+	}
+
+	// once the pause is over, other sends no longer wait
+	if until := service.RateLimitedUntil(); !until.IsZero() {
+		t.Errorf("RateLimitedUntil() = %v after the pause ended", until)
+	}
+}
+
+func TestRateLimitWaitHonorsContext(t *testing.T) {
+	service := NewService()
+	service.gate.pause(time.Minute)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := service.gate.wait(ctx); err != context.DeadlineExceeded {
+		t.Errorf("wait() = %v, want deadline exceeded", err)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	cases := map[string]time.Duration{
+		"":                              DefaultRateLimitRetryAfter,
+		"7":                             7 * time.Second,
+		"-1":                            DefaultRateLimitRetryAfter,
+		"Mon, 01 Jan 2024 00:00:30 GMT": 30 * time.Second,
+		"soon":                          DefaultRateLimitRetryAfter,
+	}
+	for value, want := range cases {
+		if got := parseRetryAfter(value, now); got != want {
+			t.Errorf("parseRetryAfter(%q) = %v, want %v", value, got, want)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package expo_service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// failingTransport fails every request as if Expo were unreachable
+type failingTransport struct {
+	requests atomic.Int32
+}
+
+func (t *failingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.requests.Add(1)
+	return nil, errors.New("connection refused")
+}
+
+func TestRetryBackoffHonorsContext(t *testing.T) {
+	client := NewClient()
+	client.httpClient.Transport = &failingTransport{}
+	service := NewServiceWithConfig(client, 3, time.Hour) // the backoff before the second retry would take an hour
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	result := service.SendSingleNotification(ctx, "ExponentPushToken[backoff]", "title", "body", nil, "")
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("send took %v, want it to stop when the context expires", elapsed)
+	}
+	if !errors.Is(result.Error, context.DeadlineExceeded) {
+		t.Errorf("result.Error = %v, want deadline exceeded", result.Error)
+	}
+}
+
+func TestBulkSendStopsAfterCancel(t *testing.T) {
+	transport := &failingTransport{}
+	client := NewClient()
+	client.httpClient.Transport = transport
+	service := NewServiceWithConfig(client, 3, time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tokens := make([]string, MaxMessagesPerRequest+5)
+	for i := range tokens {
+		tokens[i] = "ExponentPushToken[bulk]"
+	}
+	results := service.SendBulkNotifications(ctx, tokens, "title", "body", nil)
+	if len(results) != len(tokens) {
+		t.Fatalf("got %d results, want %d", len(results), len(tokens))
+	}
+	for _, result := range results {
+		if !errors.Is(result.Error, context.Canceled) {
+			t.Fatalf("result.Error = %v, want canceled", result.Error)
+		}
+	}
+	if n := transport.requests.Load(); n != 0 {
+		t.Errorf("sent %d requests after the context was canceled", n)
+	}
+}
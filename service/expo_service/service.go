@@ -2,6 +2,7 @@ package expo_service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"math"
@@ -13,6 +14,7 @@ type Service struct {
 	client     *Client
 	maxRetries int
 	baseDelay  time.Duration
+	gate       *rateLimitGate // Shared pause after a rate-limit response
 }
 
 // NewService creates a new Expo push notification service
@@ -21,6 +23,7 @@ func NewService() *Service {
 		client:     NewClient(),
 		maxRetries: 3,
 		baseDelay:  time.Second,
+		gate:       &rateLimitGate{},
 	}
 }
 
@@ -30,6 +33,7 @@ func NewServiceWithConfig(client *Client, maxRetries int, baseDelay time.Duratio
 		client:     client,
 		maxRetries: maxRetries,
 		baseDelay:  baseDelay,
+		gate:       &rateLimitGate{},
 	}
 }
 
@@ -44,25 +48,32 @@ type SendNotificationResult struct {
 
 // SendSingleNotification sends a notification to a single token with retry logic
 func (s *Service) SendSingleNotification(ctx context.Context, token, title, body string, data map[string]interface{}, sound string) *SendNotificationResult {
-	message := &PushMessage{
+	return s.SendMessage(ctx, &PushMessage{
 		To:    []string{token},
 		Title: title,
 		Body:  body,
 		Data:  data,
 		Sound: sound,
-	}
+	})
+}
 
+// SendMessage sends a fully built message to its first token with retry logic,
+// keeping every field (priority, channel, collapse/thread IDs, badge, ...)
+func (s *Service) SendMessage(ctx context.Context, message *PushMessage) *SendNotificationResult {
 	result := &SendNotificationResult{
-		Token: token,
+		Token: message.To[0],
 	}
 
 	for retry := 0; retry <= s.maxRetries; retry++ {
 		result.Retry = retry
 
-		response, err := s.client.SendPushNotification(ctx, message)
+		response, err := s.send(ctx, []*PushMessage{message})
 		if err != nil {
 			if s.shouldRetry(err, retry) {
-				s.waitBeforeRetry(retry)
+				if waitErr := s.waitBeforeRetry(ctx, err, retry); waitErr != nil {
+					result.Error = waitErr
+					return result
+				}
 				continue
 			}
 			result.Error = err
@@ -94,12 +105,20 @@ func (s *Service) SendSingleNotification(ctx context.Context, token, title, body
 	return result
 }
 
-// SendBulkNotifications sends notifications to multiple tokens
+// SendBulkNotifications sends notifications to multiple tokens; once ctx is done the
+// remaining batches are not sent and their results carry the context error
 func (s *Service) SendBulkNotifications(ctx context.Context, tokens []string, title, body string, data map[string]interface{}) []*SendNotificationResult {
 	results := make([]*SendNotificationResult, 0, len(tokens))
 
 	// Split tokens into batches of MaxMessagesPerRequest
 	for i := 0; i < len(tokens); i += MaxMessagesPerRequest {
+		if err := ctx.Err(); err != nil {
+			for _, token := range tokens[i:] {
+				results = append(results, &SendNotificationResult{Token: token, Error: err})
+			}
+			break
+		}
+
 		end := i + MaxMessagesPerRequest
 		if end > len(tokens) {
 			end = len(tokens)
@@ -131,12 +150,15 @@ func (s *Service) sendBatch(ctx context.Context, tokens []string, title, body st
 	}
 
 	for retry := 0; retry <= s.maxRetries; retry++ {
-		response, err := s.client.SendPushNotifications(ctx, messages)
-		if err != nil {
-			if s.shouldRetry(err, retry) {
-				s.waitBeforeRetry(retry)
+		response, err := s.send(ctx, messages)
+		if err != nil && s.shouldRetry(err, retry) {
+			waitErr := s.waitBeforeRetry(ctx, err, retry)
+			if waitErr == nil {
 				continue
 			}
+			err = waitErr
+		}
+		if err != nil {
 			// Set error for all tokens
 			for i := range results {
 				if !results[i].Success {
@@ -228,8 +250,12 @@ type ReceiptResult struct {
 
 // checkReceiptsBatch checks a batch of receipts
 func (s *Service) checkReceiptsBatch(ctx context.Context, receiptIDs []string) (map[string]*ReceiptResult, error) {
+	if err := s.gate.wait(ctx); err != nil {
+		return nil, err
+	}
 	response, err := s.client.GetPushReceipts(ctx, receiptIDs)
 	if err != nil {
+		s.observeRateLimit(err)
 		return nil, fmt.Errorf("failed to get receipts: %w", err)
 	}
 
@@ -260,21 +286,59 @@ func (s *Service) checkReceiptsBatch(ctx context.Context, receiptIDs []string) (
 	return results, nil
 }
 
+// send waits out any rate-limit pause, then sends the messages; a rate-limit response pauses
+// all sends of this service until Retry-After has passed
+func (s *Service) send(ctx context.Context, messages []*PushMessage) (*PushResponse, error) {
+	if err := s.gate.wait(ctx); err != nil {
+		return nil, err
+	}
+	response, err := s.client.SendPushNotifications(ctx, messages)
+	if err != nil {
+		s.observeRateLimit(err)
+	}
+	return response, err
+}
+
+// observeRateLimit pauses the service when err is a rate-limit error
+func (s *Service) observeRateLimit(err error) {
+	var rateLimitErr *RateLimitError
+	if errors.As(err, &rateLimitErr) {
+		rateLimitedCounter.Inc()
+		s.gate.pause(rateLimitErr.RetryAfter)
+	}
+}
+
+// RateLimitedUntil returns when the current rate-limit pause ends, zero if sending is not paused
+func (s *Service) RateLimitedUntil() time.Time {
+	return s.gate.until()
+}
+
 // shouldRetry determines if an error should trigger a retry
 func (s *Service) shouldRetry(err error, retryCount int) bool {
 	if retryCount >= s.maxRetries {
 		return false
 	}
 
-	// Add logic to determine if error is retryable
-	// For now, we'll retry on all errors except the last attempt
+	// A canceled or expired context will not succeed on retry
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	// For now, we'll retry on all other errors except the last attempt
 	return true
 }
 
-// waitBeforeRetry implements exponential backoff
-func (s *Service) waitBeforeRetry(retryCount int) {
-	if retryCount == 0 {
-		return
+// waitBeforeRetry implements exponential backoff; rate-limit errors skip it because
+// the next send already waits for the shared Retry-After pause.
+// It returns the context error as soon as ctx is done instead of sleeping out the delay
+func (s *Service) waitBeforeRetry(ctx context.Context, err error, retryCount int) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+
+	var rateLimitErr *RateLimitError
+	if retryCount == 0 || errors.As(err, &rateLimitErr) {
+		return nil
 	}
 
 	// Exponential backoff: baseDelay * 2^(retryCount-1)
@@ -285,7 +349,14 @@ func (s *Service) waitBeforeRetry(retryCount int) {
 	delay += jitter
 
 	log.Printf("Waiting %v before retry %d", delay, retryCount)
-	time.Sleep(delay)
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
 }
 
 // ValidateToken validates if a token looks like a valid Expo push token
@@ -0,0 +1,109 @@
+package handoff_service
+
+import (
+	"fmt"
+	"os"
+	"push-base-service/service/lock_service"
+	"time"
+)
+
+// 协调模式
+const (
+	ModeHandoff  = "handoff"  // 部署交接：就绪的新实例请求接管，旧实例排空后交出（新旧实例需使用共享的 Redis 存储后端）
+	ModeElection = "election" // 主备选举：只有租约持有者消费消息，备用实例保持连接，仅在主实例失联或停止后接管
+)
+
+// 租约后端
+const (
+	BackendFile  = "file"  // 共享目录中的租约文件，适用于同一主机或共享卷
+	BackendRedis = "redis" // Redis 键租约，适用于跨主机部署
+)
+
+// Config 部署交接与主备选举配置
+type Config struct {
+	Enabled      bool                     `yaml:"enabled" json:"enabled"`             // 是否启用交接协议
+	Mode         string                   `yaml:"mode" json:"mode"`                   // 协调模式：handoff（默认）/ election
+	Backend      string                   `yaml:"backend" json:"backend"`             // 租约后端：file（默认）/ redis
+	Redis        lock_service.RedisConfig `yaml:"redis" json:"redis"`                 // redis 后端配置
+	Dir          string                   `yaml:"dir" json:"dir"`                     // 新旧实例共享的协调目录（租约与交接请求文件）
+	Name         string                   `yaml:"name" json:"name"`                   // 锁名称
+	InstanceID   string                   `yaml:"instance_id" json:"instance_id"`     // 本实例ID，默认 主机名-进程号
+	LeaseTTL     time.Duration            `yaml:"lease_ttl" json:"lease_ttl"`         // 租约时长
+	PollInterval time.Duration            `yaml:"poll_interval" json:"poll_interval"` // 续约/检查交接请求的间隔
+	BufferSize   int                      `yaml:"buffer_size" json:"buffer_size"`     // 待命期间缓存的最大消息数
+	BufferWindow time.Duration            `yaml:"buffer_window" json:"buffer_window"` // 缓存消息的最长保留时间
+}
+
+// DefaultConfig 返回默认配置
+func DefaultConfig() *Config {
+	return &Config{
+		Enabled:      false,
+		Mode:         ModeHandoff,
+		Backend:      BackendFile,
+		Redis:        lock_service.RedisConfig{Addr: "127.0.0.1:6379", Key: "push:leader"},
+		Dir:          "./data/handoff",
+		Name:         "push-center",
+		LeaseTTL:     15 * time.Second,
+		PollInterval: time.Second,
+		BufferSize:   1000,
+		BufferWindow: 2 * time.Minute,
+	}
+}
+
+// ApplyDefaults 为缺失的配置项填充默认值
+func (c *Config) ApplyDefaults() {
+	defaults := DefaultConfig()
+
+	if c.Mode == "" {
+		c.Mode = defaults.Mode
+	}
+	if c.Backend == "" {
+		c.Backend = defaults.Backend
+	}
+	if c.Redis.Addr == "" {
+		c.Redis.Addr = defaults.Redis.Addr
+	}
+	if c.Redis.Key == "" {
+		c.Redis.Key = defaults.Redis.Key
+	}
+	if c.Dir == "" {
+		c.Dir = defaults.Dir
+	}
+	if c.Name == "" {
+		c.Name = defaults.Name
+	}
+	if c.InstanceID == "" {
+		hostname, _ := os.Hostname()
+		c.InstanceID = fmt.Sprintf("%s-%d", hostname, os.Getpid())
+	}
+	if c.LeaseTTL <= 0 {
+		c.LeaseTTL = defaults.LeaseTTL
+	}
+	if c.PollInterval <= 0 {
+		c.PollInterval = defaults.PollInterval
+	}
+	if c.BufferSize <= 0 {
+		c.BufferSize = defaults.BufferSize
+	}
+	if c.BufferWindow <= 0 {
+		c.BufferWindow = defaults.BufferWindow
+	}
+}
+
+// Validate 校验配置
+func (c *Config) Validate() error {
+	switch c.Mode {
+	case ModeHandoff, ModeElection:
+	default:
+		return fmt.Errorf("不支持的协调模式: %s（handoff / election）", c.Mode)
+	}
+	switch c.Backend {
+	case BackendFile, BackendRedis:
+	default:
+		return fmt.Errorf("不支持的租约后端: %s（file / redis）", c.Backend)
+	}
+	if c.Mode == ModeHandoff && c.Backend != BackendFile {
+		return fmt.Errorf("部署交接模式只支持 file 租约后端（交接请求通过共享目录传递）")
+	}
+	return nil
+}
@@ -0,0 +1,383 @@
+package handoff_service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"push-base-service/service/lock_service"
+	"push-base-service/service/metrics_service"
+	"sync"
+	"time"
+)
+
+// 实例角色
+const (
+	RoleStandby = "standby" // 待命：保持上游连接但不消费消息
+	RoleLeader  = "leader"  // 主实例：消费消息并发送推送
+)
+
+var leaderGauge = metrics_service.NewGaugeVec(
+	"push_center_leader", "Whether this instance currently consumes upstream messages (1) or stands by (0)", "instance")
+
+// Callbacks 交接过程中的回调
+type Callbacks struct {
+	Ready     func() bool  // 是否已准备好接管（如上游已连接），未就绪时不发起交接请求
+	OnAcquire func() error // 成为主实例时调用：开始消费消息
+	OnRelease func()       // 交出主实例前调用：停止消费、排空在途任务并持久化状态（租约丢失后可能再次接管，不应关闭本地资源）
+}
+
+// handoffRequest 交接请求文件内容
+type handoffRequest struct {
+	Requester   string `json:"requester"`   // 请求接管的实例ID
+	RequestedAt int64  `json:"requestedAt"` // 最近一次刷新时间（Unix 毫秒），超过租约时长未刷新视为失效
+}
+
+// Coordinator 部署交接协调器
+// 新实例连接上游并就绪后写入交接请求；旧实例检测到请求后停止消费、排空并持久化状态，
+// 随后释放分布式锁；新实例拿到锁后开始消费，从而消除滚动发布期间的重复/漏推窗口。
+// 主备选举模式下不发起交接请求，备用实例只在主实例租约过期（失联）或主动释放（停止）后接管
+type Coordinator struct {
+	config    *Config
+	lock      lock_service.Lock
+	callbacks Callbacks
+	role      string
+	handedOff bool // 已将主实例交接给更新的实例，此后不再发起交接请求，仅在主实例失联时接管
+	stopCh    chan struct{}
+	wg        sync.WaitGroup
+	running   bool
+	mu        sync.Mutex
+}
+
+// NewCoordinator 创建交接协调器
+func NewCoordinator(config *Config, lock lock_service.Lock, callbacks Callbacks) *Coordinator {
+	if config == nil {
+		config = DefaultConfig()
+	}
+	config.ApplyDefaults()
+
+	return &Coordinator{
+		config:    config,
+		lock:      lock,
+		callbacks: callbacks,
+		role:      RoleStandby,
+	}
+}
+
+// NewFileCoordinator 创建基于共享目录文件租约的交接协调器
+func NewFileCoordinator(config *Config, callbacks Callbacks) (*Coordinator, error) {
+	if config == nil {
+		config = DefaultConfig()
+	}
+	config.ApplyDefaults()
+
+	lock, err := lock_service.NewFileLock(config.Dir, config.Name, config.InstanceID, config.LeaseTTL)
+	if err != nil {
+		return nil, fmt.Errorf("创建文件租约锁失败: %w", err)
+	}
+	return NewCoordinator(config, lock, callbacks), nil
+}
+
+// NewBackendCoordinator 按配置的租约后端（file / redis）创建协调器
+func NewBackendCoordinator(config *Config, callbacks Callbacks) (*Coordinator, error) {
+	if config == nil {
+		config = DefaultConfig()
+	}
+	config.ApplyDefaults()
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	if config.Backend == BackendRedis {
+		lock, err := lock_service.NewRedisLock(&config.Redis, config.InstanceID, config.LeaseTTL)
+		if err != nil {
+			return nil, fmt.Errorf("创建 Redis 租约锁失败: %w", err)
+		}
+		return NewCoordinator(config, lock, callbacks), nil
+	}
+	return NewFileCoordinator(config, callbacks)
+}
+
+// Mode 获取协调模式
+func (c *Coordinator) Mode() string {
+	return c.config.Mode
+}
+
+// election 是否为主备选举模式
+func (c *Coordinator) election() bool {
+	return c.config.Mode == ModeElection
+}
+
+// Start 启动协调循环
+func (c *Coordinator) Start() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.running {
+		return
+	}
+
+	leaderGauge.Set(0, c.config.InstanceID)
+	c.stopCh = make(chan struct{})
+	c.wg.Add(1)
+	go c.loop()
+
+	c.running = true
+	log.Printf("✅ 部署交接协调器已启动: instance=%s, mode=%s, backend=%s", c.config.InstanceID, c.config.Mode, c.config.Backend)
+}
+
+// Stop 停止协调循环，若为主实例则先交出并释放锁
+func (c *Coordinator) Stop() {
+	c.mu.Lock()
+	if !c.running {
+		c.mu.Unlock()
+		return
+	}
+	close(c.stopCh)
+	c.running = false
+	c.mu.Unlock()
+
+	c.wg.Wait()
+
+	if c.Role() == RoleLeader {
+		c.stepDown("实例停止")
+	} else if !c.election() {
+		c.clearOwnRequest()
+	}
+	if closer, ok := c.lock.(io.Closer); ok {
+		closer.Close()
+	}
+	log.Printf("🛑 部署交接协调器已停止")
+}
+
+// Role 获取当前角色
+func (c *Coordinator) Role() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.role
+}
+
+// IsLeader 是否为主实例
+func (c *Coordinator) IsLeader() bool {
+	return c.Role() == RoleLeader
+}
+
+// InstanceID 获取本实例ID
+func (c *Coordinator) InstanceID() string {
+	return c.config.InstanceID
+}
+
+// loop 协调主循环
+func (c *Coordinator) loop() {
+	defer c.wg.Done()
+
+	c.tick()
+
+	ticker := time.NewTicker(c.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.stopCh:
+			return
+		case <-ticker.C:
+			c.tick()
+		}
+	}
+}
+
+// tick 执行一次状态检查
+func (c *Coordinator) tick() {
+	if c.Role() == RoleLeader {
+		c.tickLeader()
+	} else {
+		c.tickStandby()
+	}
+}
+
+// tickLeader 主实例：续约并检查是否有新实例请求接管
+func (c *Coordinator) tickLeader() {
+	ctx, cancel := context.WithTimeout(context.Background(), c.config.PollInterval)
+	defer cancel()
+
+	if err := c.lock.Renew(ctx); err != nil {
+		if errors.Is(err, lock_service.ErrLockLost) {
+			log.Printf("⚠️ 主实例租约已丢失，转为待命")
+			c.demote()
+			return
+		}
+		log.Printf("⚠️ 主实例续约失败: %v", err)
+		return
+	}
+	if c.election() {
+		return
+	}
+
+	request, err := c.readRequest()
+	if err != nil {
+		log.Printf("⚠️ 读取交接请求失败: %v", err)
+		return
+	}
+	if request != nil && request.Requester != c.config.InstanceID && c.isRequestAlive(request) {
+		log.Printf("🤝 收到实例 %s 的交接请求，开始交接", request.Requester)
+		c.stepDown("交接给 " + request.Requester)
+
+		c.mu.Lock()
+		c.handedOff = true
+		c.mu.Unlock()
+	}
+}
+
+// tickStandby 待命实例：尝试获取锁，锁被占用且已就绪时发起交接请求（主备选举模式下不发起）
+func (c *Coordinator) tickStandby() {
+	ctx, cancel := context.WithTimeout(context.Background(), c.config.PollInterval)
+	defer cancel()
+
+	if !c.election() {
+		request, err := c.readRequest()
+		if err != nil {
+			log.Printf("⚠️ 读取交接请求失败: %v", err)
+			return
+		}
+
+		// 其他实例正在接管时不参与竞争，避免刚交出的旧实例重新抢到锁
+		if request != nil && request.Requester != c.config.InstanceID && c.isRequestAlive(request) {
+			return
+		}
+	}
+
+	acquired, err := c.lock.TryAcquire(ctx)
+	if err != nil {
+		log.Printf("⚠️ 获取主实例锁失败: %v", err)
+		return
+	}
+
+	if acquired {
+		if c.callbacks.OnAcquire != nil {
+			if err := c.callbacks.OnAcquire(); err != nil {
+				log.Printf("❌ 接管失败，释放锁: %v", err)
+				if releaseErr := c.lock.Release(ctx); releaseErr != nil {
+					log.Printf("⚠️ 释放主实例锁失败: %v", releaseErr)
+				}
+				return
+			}
+		}
+
+		c.setRole(RoleLeader)
+		if !c.election() {
+			c.clearOwnRequest()
+		}
+		log.Printf("👑 已成为主实例: instance=%s", c.config.InstanceID)
+		return
+	}
+
+	// 锁被其他实例持有：就绪后发起（或刷新）交接请求
+	if c.election() {
+		return
+	}
+	c.mu.Lock()
+	handedOff := c.handedOff
+	c.mu.Unlock()
+	if handedOff {
+		return
+	}
+	if c.callbacks.Ready == nil || c.callbacks.Ready() {
+		if err := c.writeRequest(); err != nil {
+			log.Printf("⚠️ 写入交接请求失败: %v", err)
+		}
+	}
+}
+
+// stepDown 交出主实例：先执行交出回调（停止消费、排空、持久化），再释放锁
+func (c *Coordinator) stepDown(reason string) {
+	c.demote()
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.config.LeaseTTL)
+	defer cancel()
+
+	if err := c.lock.Release(ctx); err != nil && !errors.Is(err, lock_service.ErrLockNotHeld) {
+		log.Printf("⚠️ 释放主实例锁失败: %v", err)
+		return
+	}
+	log.Printf("👋 已交出主实例: %s", reason)
+}
+
+// demote 转为待命并执行交出回调
+func (c *Coordinator) demote() {
+	if c.callbacks.OnRelease != nil {
+		c.callbacks.OnRelease()
+	}
+	c.setRole(RoleStandby)
+}
+
+// setRole 设置角色并更新指标
+func (c *Coordinator) setRole(role string) {
+	c.mu.Lock()
+	c.role = role
+	c.mu.Unlock()
+
+	if role == RoleLeader {
+		leaderGauge.Set(1, c.config.InstanceID)
+	} else {
+		leaderGauge.Set(0, c.config.InstanceID)
+	}
+}
+
+// isRequestAlive 交接请求是否仍有效（请求方需持续刷新）
+func (c *Coordinator) isRequestAlive(request *handoffRequest) bool {
+	return time.Now().UnixMilli()-request.RequestedAt <= c.config.LeaseTTL.Milliseconds()
+}
+
+// requestPath 交接请求文件路径
+func (c *Coordinator) requestPath() string {
+	return filepath.Join(c.config.Dir, c.config.Name+".handoff")
+}
+
+// readRequest 读取交接请求，不存在时返回 nil
+func (c *Coordinator) readRequest() (*handoffRequest, error) {
+	data, err := os.ReadFile(c.requestPath())
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var request handoffRequest
+	if err := json.Unmarshal(data, &request); err != nil {
+		return nil, nil
+	}
+	return &request, nil
+}
+
+// writeRequest 写入本实例的交接请求
+func (c *Coordinator) writeRequest() error {
+	data, err := json.Marshal(&handoffRequest{
+		Requester:   c.config.InstanceID,
+		RequestedAt: time.Now().UnixMilli(),
+	})
+	if err != nil {
+		return err
+	}
+
+	tmpPath := c.requestPath() + "." + c.config.InstanceID + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, c.requestPath())
+}
+
+// clearOwnRequest 删除本实例发起的交接请求
+func (c *Coordinator) clearOwnRequest() {
+	request, err := c.readRequest()
+	if err != nil || request == nil || request.Requester != c.config.InstanceID {
+		return
+	}
+	if err := os.Remove(c.requestPath()); err != nil && !os.IsNotExist(err) {
+		log.Printf("⚠️ 删除交接请求失败: %v", err)
+	}
+}
@@ -0,0 +1,124 @@
+package handoff_service
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestCoordinator(t *testing.T, dir, instanceID string, consuming *atomic.Bool) *Coordinator {
+	t.Helper()
+
+	coordinator, err := NewFileCoordinator(&Config{
+		Enabled:      true,
+		Dir:          dir,
+		InstanceID:   instanceID,
+		LeaseTTL:     500 * time.Millisecond,
+		PollInterval: 20 * time.Millisecond,
+	}, Callbacks{
+		Ready:     func() bool { return true },
+		OnAcquire: func() error { consuming.Store(true); return nil },
+		OnRelease: func() { consuming.Store(false) },
+	})
+	if err != nil {
+		t.Fatalf("NewFileCoordinator() failed, err: %v", err)
+	}
+	return coordinator
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(3 * time.Second)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for %s", what)
+}
+
+func TestCoordinatorHandoff(t *testing.T) {
+	dir := t.TempDir()
+	var oldConsuming, newConsuming atomic.Bool
+
+	oldInstance := newTestCoordinator(t, dir, "old", &oldConsuming)
+	oldInstance.Start()
+	defer oldInstance.Stop()
+	waitFor(t, "old instance to lead", oldInstance.IsLeader)
+
+	newInstance := newTestCoordinator(t, dir, "new", &newConsuming)
+	newInstance.Start()
+	defer newInstance.Stop()
+	waitFor(t, "new instance to take over", newInstance.IsLeader)
+
+	if oldInstance.IsLeader() || oldConsuming.Load() {
+		t.Errorf("old instance should stop consuming after handoff")
+	}
+	if !newConsuming.Load() {
+		t.Errorf("new instance should consume after handoff")
+	}
+
+	// 旧实例交出后不会重新抢回锁
+	time.Sleep(100 * time.Millisecond)
+	if oldInstance.IsLeader() {
+		t.Errorf("old instance should stay in standby")
+	}
+}
+
+func TestCoordinatorElection(t *testing.T) {
+	dir := t.TempDir()
+	var leaderConsuming, standbyConsuming atomic.Bool
+
+	newElectionCoordinator := func(instanceID string, consuming *atomic.Bool) *Coordinator {
+		coordinator, err := NewBackendCoordinator(&Config{
+			Enabled:      true,
+			Mode:         ModeElection,
+			Dir:          dir,
+			InstanceID:   instanceID,
+			LeaseTTL:     500 * time.Millisecond,
+			PollInterval: 20 * time.Millisecond,
+		}, Callbacks{
+			Ready:     func() bool { return true },
+			OnAcquire: func() error { consuming.Store(true); return nil },
+			OnRelease: func() { consuming.Store(false) },
+		})
+		if err != nil {
+			t.Fatalf("NewBackendCoordinator() failed, err: %v", err)
+		}
+		return coordinator
+	}
+
+	leader := newElectionCoordinator("leader", &leaderConsuming)
+	leader.Start()
+	waitFor(t, "first instance to lead", leader.IsLeader)
+
+	// 备用实例就绪后也不请求接管，主实例保持消费
+	standby := newElectionCoordinator("standby", &standbyConsuming)
+	standby.Start()
+	defer standby.Stop()
+	time.Sleep(200 * time.Millisecond)
+	if !leader.IsLeader() || standby.IsLeader() || standbyConsuming.Load() {
+		t.Fatalf("standby should not take over a live leader")
+	}
+
+	leader.Stop()
+	waitFor(t, "standby to take over", standby.IsLeader)
+	if leaderConsuming.Load() || !standbyConsuming.Load() {
+		t.Errorf("standby should consume after the leader stops")
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	config := &Config{Mode: ModeHandoff, Backend: BackendRedis}
+	config.ApplyDefaults()
+	if err := config.Validate(); err == nil {
+		t.Errorf("handoff mode with redis backend should be rejected")
+	}
+	config = &Config{Mode: "active-active"}
+	config.ApplyDefaults()
+	if err := config.Validate(); err == nil {
+		t.Errorf("unknown mode should be rejected")
+	}
+}
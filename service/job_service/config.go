@@ -0,0 +1,22 @@
+package job_service
+
+import "time"
+
+// Config 后台任务配置
+type Config struct {
+	Retention time.Duration `yaml:"retention" json:"retention"` // 已结束任务的保留时长，超过后定期清理
+}
+
+// DefaultConfig 返回默认配置
+func DefaultConfig() *Config {
+	return &Config{
+		Retention: 7 * 24 * time.Hour,
+	}
+}
+
+// ApplyDefaults 为缺失的配置项填充默认值
+func (c *Config) ApplyDefaults() {
+	if c.Retention <= 0 {
+		c.Retention = DefaultConfig().Retention
+	}
+}
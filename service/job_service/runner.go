@@ -0,0 +1,278 @@
+package job_service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"push-base-service/models"
+	"push-base-service/service/metrics_service"
+	"push-base-service/service/pebble_service"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// 任务提交和取消的错误
+var (
+	ErrRunnerStopped = errors.New("后台任务执行器已停止")
+	ErrJobRunning    = errors.New("同类型任务正在执行")
+	ErrJobNotFound   = errors.New("任务不存在")
+	ErrJobFinished   = errors.New("任务已结束")
+)
+
+// jobsCounter 后台任务结束数
+var jobsCounter = metrics_service.NewCounterVec(
+	"push_jobs_total", "Number of finished background jobs by type and status", "type", "status")
+
+// jobSeq 同一纳秒内的任务序号，保证任务ID唯一且有序
+var jobSeq atomic.Uint64
+
+// RunFunc 任务执行函数，需响应 ctx 取消（返回 ctx.Err()），返回值作为任务结果保存
+type RunFunc func(ctx context.Context, progress *Progress) (interface{}, error)
+
+// Progress 任务进度上报
+type Progress struct {
+	runner *Runner
+	job    *models.Job
+}
+
+// Report 更新任务进度（0-100）和进度说明
+func (p *Progress) Report(percent int, message string) {
+	p.runner.update(p.job, func(job *models.Job) {
+		job.Progress = min(max(percent, 0), 100)
+		job.Message = message
+	})
+}
+
+// runningJob 执行中的任务
+type runningJob struct {
+	job    *models.Job
+	cancel context.CancelFunc
+}
+
+// Runner 后台任务执行器
+// 任务状态、进度和结果保存在 Pebble，可通过管理接口查询和取消；同一类型同时只执行一个任务
+type Runner struct {
+	config  *Config
+	mu      sync.Mutex
+	running map[string]*runningJob
+	wg      sync.WaitGroup
+	stopCh  chan struct{}
+	started bool
+	stopped bool
+}
+
+// NewRunner 创建后台任务执行器
+func NewRunner(config *Config) *Runner {
+	if config == nil {
+		config = DefaultConfig()
+	}
+	config.ApplyDefaults()
+
+	return &Runner{
+		config:  config,
+		running: make(map[string]*runningJob),
+		stopCh:  make(chan struct{}),
+	}
+}
+
+// Start 将上次运行时未结束的任务标记为失败，并启动过期任务清理
+func (r *Runner) Start() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.started || r.stopped {
+		return
+	}
+	r.started = true
+
+	if count, err := pebble_service.InterruptJobs("服务重启，任务中断"); err != nil {
+		log.Printf("⚠️ 标记中断的后台任务失败: %v", err)
+	} else if count > 0 {
+		log.Printf("⚠️ %d 个后台任务因服务重启中断", count)
+	}
+
+	r.wg.Add(1)
+	go r.cleanupLoop()
+}
+
+// Stop 取消执行中的任务并等待其结束
+func (r *Runner) Stop() {
+	r.mu.Lock()
+	if r.stopped {
+		r.mu.Unlock()
+		return
+	}
+	r.stopped = true
+	close(r.stopCh)
+	for _, rj := range r.running {
+		rj.cancel()
+	}
+	r.mu.Unlock()
+
+	r.wg.Wait()
+}
+
+// Submit 提交任务并立即在后台执行，返回任务记录
+func (r *Runner) Submit(jobType string, run RunFunc) (*models.Job, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.stopped {
+		return nil, ErrRunnerStopped
+	}
+	for _, rj := range r.running {
+		if rj.job.Type == jobType {
+			return nil, fmt.Errorf("%w: %s", ErrJobRunning, rj.job.ID)
+		}
+	}
+
+	now := time.Now()
+	job := &models.Job{
+		ID:        fmt.Sprintf("%020d-%05d", now.UnixNano(), jobSeq.Add(1)%100000),
+		Type:      jobType,
+		Status:    models.JobStatusPending,
+		CreatedAt: now.Unix(),
+	}
+	if err := pebble_service.SaveJob(job); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r.running[job.ID] = &runningJob{job: job, cancel: cancel}
+
+	r.wg.Add(1)
+	go r.execute(ctx, cancel, job, run)
+
+	snapshot := *job
+	return &snapshot, nil
+}
+
+// Cancel 请求取消任务，任务在执行函数响应取消后结束
+func (r *Runner) Cancel(id string) (*models.Job, error) {
+	r.mu.Lock()
+	rj, ok := r.running[id]
+	r.mu.Unlock()
+
+	if !ok {
+		job, err := pebble_service.GetJob(id)
+		if err != nil {
+			return nil, err
+		}
+		if job == nil {
+			return nil, ErrJobNotFound
+		}
+		if job.Finished() {
+			return nil, fmt.Errorf("%w: %s", ErrJobFinished, job.Status)
+		}
+		return nil, fmt.Errorf("任务不在本实例执行: %s", id)
+	}
+
+	snapshot := r.update(rj.job, func(job *models.Job) {
+		job.CancelRequested = true
+	})
+	rj.cancel()
+	return snapshot, nil
+}
+
+// execute 执行任务并保存最终状态
+func (r *Runner) execute(ctx context.Context, cancel context.CancelFunc, job *models.Job, run RunFunc) {
+	defer r.wg.Done()
+	defer cancel()
+
+	r.update(job, func(job *models.Job) {
+		job.Status = models.JobStatusRunning
+		job.StartedAt = time.Now().Unix()
+	})
+
+	result, err := r.invoke(ctx, job, run)
+
+	final := r.update(job, func(job *models.Job) {
+		job.FinishedAt = time.Now().Unix()
+		switch {
+		case err == nil:
+			job.Status = models.JobStatusSucceeded
+			job.Progress = 100
+			job.Result = result
+		case ctx.Err() != nil && errors.Is(err, context.Canceled):
+			job.Status = models.JobStatusCanceled
+			job.Error = err.Error()
+		default:
+			job.Status = models.JobStatusFailed
+			job.Error = err.Error()
+		}
+	})
+
+	r.mu.Lock()
+	delete(r.running, job.ID)
+	r.mu.Unlock()
+
+	jobsCounter.Inc(final.Type, final.Status)
+	switch final.Status {
+	case models.JobStatusSucceeded:
+		log.Printf("✅ 后台任务完成: id=%s, type=%s", final.ID, final.Type)
+	case models.JobStatusCanceled:
+		log.Printf("🛑 后台任务已取消: id=%s, type=%s", final.ID, final.Type)
+	default:
+		log.Printf("❌ 后台任务失败: id=%s, type=%s, 错误: %s", final.ID, final.Type, final.Error)
+	}
+}
+
+// invoke 调用任务执行函数，执行函数 panic 时按失败处理
+func (r *Runner) invoke(ctx context.Context, job *models.Job, run RunFunc) (result interface{}, err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("任务执行异常: %v", recovered)
+		}
+	}()
+	return run(ctx, &Progress{runner: r, job: job})
+}
+
+// update 修改任务并保存，返回修改后的任务快照
+func (r *Runner) update(job *models.Job, modify func(job *models.Job)) *models.Job {
+	r.mu.Lock()
+	modify(job)
+	snapshot := *job
+	r.mu.Unlock()
+
+	if err := pebble_service.SaveJob(&snapshot); err != nil {
+		log.Printf("⚠️ 保存后台任务状态失败: id=%s, 错误: %v", job.ID, err)
+	}
+	return &snapshot
+}
+
+// cleanupLoop 定期删除超过保留时长的已结束任务
+func (r *Runner) cleanupLoop() {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.stopCh:
+			return
+		case <-ticker.C:
+			count, err := pebble_service.PurgeJobs(time.Now().Add(-r.config.Retention).Unix())
+			if err != nil {
+				log.Printf("⚠️ 清理过期后台任务失败: %v", err)
+			} else if count > 0 {
+				log.Printf("🧹 已清理 %d 个过期后台任务", count)
+			}
+		}
+	}
+}
+
+// 全局后台任务执行器（供管理接口使用）
+var globalRunner *Runner
+
+// SetGlobalRunner 设置全局后台任务执行器
+func SetGlobalRunner(runner *Runner) {
+	globalRunner = runner
+}
+
+// GetGlobalRunner 获取全局后台任务执行器，未设置时返回 nil
+func GetGlobalRunner() *Runner {
+	return globalRunner
+}
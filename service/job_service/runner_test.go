@@ -0,0 +1,128 @@
+package job_service
+
+import (
+	"context"
+	"errors"
+	"push-base-service/models"
+	"push-base-service/service/pebble_service"
+	"testing"
+	"time"
+)
+
+func newTestRunner(t *testing.T) *Runner {
+	t.Helper()
+
+	if err := pebble_service.InitializeGlobalService(&pebble_service.Config{DBPath: t.TempDir()}); err != nil {
+		t.Fatalf("初始化 Pebble 服务失败: %v", err)
+	}
+	t.Cleanup(func() { pebble_service.CloseGlobalService() })
+
+	runner := NewRunner(nil)
+	runner.Start()
+	t.Cleanup(runner.Stop)
+	return runner
+}
+
+// waitJob 等待任务结束并返回保存的任务记录
+func waitJob(t *testing.T, id string) *models.Job {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		job, err := pebble_service.GetJob(id)
+		if err != nil {
+			t.Fatalf("GetJob() failed, err: %v", err)
+		}
+		if job != nil && job.Finished() {
+			return job
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("任务 %s 未在超时前结束", id)
+	return nil
+}
+
+func TestRunnerRecordsProgressAndResult(t *testing.T) {
+	runner := newTestRunner(t)
+
+	release := make(chan struct{})
+	job, err := runner.Submit("test", func(ctx context.Context, progress *Progress) (interface{}, error) {
+		progress.Report(50, "half way")
+		<-release
+		return map[string]int{"count": 3}, nil
+	})
+	if err != nil {
+		t.Fatalf("Submit() failed, err: %v", err)
+	}
+
+	// 同类型任务同时只执行一个
+	if _, err := runner.Submit("test", nil); !errors.Is(err, ErrJobRunning) {
+		t.Errorf("Submit() duplicate err = %v, want ErrJobRunning", err)
+	}
+
+	close(release)
+	finished := waitJob(t, job.ID)
+	if finished.Status != models.JobStatusSucceeded || finished.Progress != 100 || finished.Message != "half way" {
+		t.Errorf("job = %+v, want succeeded", finished)
+	}
+	if result, ok := finished.Result.(map[string]interface{}); !ok || result["count"] != float64(3) {
+		t.Errorf("result = %#v, want count 3", finished.Result)
+	}
+
+	failed, _ := runner.Submit("test", func(ctx context.Context, progress *Progress) (interface{}, error) {
+		return nil, errors.New("boom")
+	})
+	if job := waitJob(t, failed.ID); job.Status != models.JobStatusFailed || job.Error != "boom" {
+		t.Errorf("job = %+v, want failed with boom", job)
+	}
+
+	jobs, err := pebble_service.ListJobs("test", "", 0)
+	if err != nil || len(jobs) != 2 || jobs[0].ID != failed.ID {
+		t.Errorf("ListJobs() = %v, %v; want newest first", jobs, err)
+	}
+}
+
+func TestRunnerCancel(t *testing.T) {
+	runner := newTestRunner(t)
+
+	started := make(chan struct{})
+	job, err := runner.Submit("test", func(ctx context.Context, progress *Progress) (interface{}, error) {
+		close(started)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	if err != nil {
+		t.Fatalf("Submit() failed, err: %v", err)
+	}
+	<-started
+
+	if canceled, err := runner.Cancel(job.ID); err != nil || !canceled.CancelRequested {
+		t.Fatalf("Cancel() = %+v, %v", canceled, err)
+	}
+	if finished := waitJob(t, job.ID); finished.Status != models.JobStatusCanceled {
+		t.Errorf("job = %+v, want canceled", finished)
+	}
+	if _, err := runner.Cancel(job.ID); !errors.Is(err, ErrJobFinished) {
+		t.Errorf("Cancel() finished job err = %v, want ErrJobFinished", err)
+	}
+	if _, err := runner.Cancel("missing"); !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("Cancel() missing job err = %v, want ErrJobNotFound", err)
+	}
+}
+
+func TestRunnerInterruptsUnfinishedJobsOnStart(t *testing.T) {
+	newTestRunner(t)
+
+	if err := pebble_service.SaveJob(&models.Job{ID: "stale", Type: "test", Status: models.JobStatusRunning}); err != nil {
+		t.Fatalf("SaveJob() failed, err: %v", err)
+	}
+
+	restarted := NewRunner(nil)
+	restarted.Start()
+	defer restarted.Stop()
+
+	job, _ := pebble_service.GetJob("stale")
+	if job.Status != models.JobStatusFailed || job.Error == "" {
+		t.Errorf("job = %+v, want failed after restart", job)
+	}
+}
@@ -0,0 +1,199 @@
+package lock_service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// guardStaleAfter 守护文件超过该时长仍存在视为持有进程已崩溃
+const guardStaleAfter = 10 * time.Second
+
+// fileLease 租约文件内容
+type fileLease struct {
+	Owner     string `json:"owner"`     // 持有者ID
+	ExpiresAt int64  `json:"expiresAt"` // 租约过期时间（Unix 毫秒）
+}
+
+// FileLock 基于共享目录的文件租约锁，适用于同一主机或共享卷上的多个实例
+type FileLock struct {
+	dir     string
+	name    string
+	ownerID string
+	ttl     time.Duration
+	mu      sync.Mutex
+}
+
+// NewFileLock 创建文件租约锁
+func NewFileLock(dir, name, ownerID string, ttl time.Duration) (*FileLock, error) {
+	if dir == "" || name == "" || ownerID == "" {
+		return nil, fmt.Errorf("锁目录、名称和持有者ID不能为空")
+	}
+	if ttl <= 0 {
+		return nil, fmt.Errorf("租约时长必须大于0")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("创建锁目录失败: %w", err)
+	}
+
+	return &FileLock{
+		dir:     dir,
+		name:    name,
+		ownerID: ownerID,
+		ttl:     ttl,
+	}, nil
+}
+
+// OwnerID 获取本实例的ID
+func (l *FileLock) OwnerID() string {
+	return l.ownerID
+}
+
+// TryAcquire 尝试获取锁
+func (l *FileLock) TryAcquire(ctx context.Context) (bool, error) {
+	acquired := false
+	err := l.withGuard(ctx, func() error {
+		lease, err := l.readLease()
+		if err != nil {
+			return err
+		}
+		if lease != nil && lease.Owner != l.ownerID && lease.ExpiresAt > time.Now().UnixMilli() {
+			return nil
+		}
+
+		acquired = true
+		return l.writeLease()
+	})
+	if err != nil {
+		return false, err
+	}
+	return acquired, nil
+}
+
+// Renew 续约
+func (l *FileLock) Renew(ctx context.Context) error {
+	return l.withGuard(ctx, func() error {
+		lease, err := l.readLease()
+		if err != nil {
+			return err
+		}
+		if lease == nil || lease.Owner != l.ownerID {
+			return ErrLockLost
+		}
+		return l.writeLease()
+	})
+}
+
+// Release 主动释放锁
+func (l *FileLock) Release(ctx context.Context) error {
+	return l.withGuard(ctx, func() error {
+		lease, err := l.readLease()
+		if err != nil {
+			return err
+		}
+		if lease == nil || lease.Owner != l.ownerID {
+			return ErrLockNotHeld
+		}
+		if err := os.Remove(l.leasePath()); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("删除租约文件失败: %w", err)
+		}
+		return nil
+	})
+}
+
+// Holder 获取当前持有者ID
+func (l *FileLock) Holder(ctx context.Context) (string, error) {
+	holder := ""
+	err := l.withGuard(ctx, func() error {
+		lease, err := l.readLease()
+		if err != nil {
+			return err
+		}
+		if lease != nil && lease.ExpiresAt > time.Now().UnixMilli() {
+			holder = lease.Owner
+		}
+		return nil
+	})
+	return holder, err
+}
+
+// withGuard 在守护文件保护下执行租约读写，保证多进程间的原子性
+func (l *FileLock) withGuard(ctx context.Context, fn func() error) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	guardPath := filepath.Join(l.dir, l.name+".guard")
+	for {
+		f, err := os.OpenFile(guardPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			f.Close()
+			break
+		}
+		if !os.IsExist(err) {
+			return fmt.Errorf("创建锁守护文件失败: %w", err)
+		}
+
+		// 清理崩溃进程遗留的守护文件
+		if info, statErr := os.Stat(guardPath); statErr == nil && time.Since(info.ModTime()) > guardStaleAfter {
+			os.Remove(guardPath)
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	defer os.Remove(guardPath)
+
+	return fn()
+}
+
+// readLease 读取租约文件，不存在时返回 nil
+func (l *FileLock) readLease() (*fileLease, error) {
+	data, err := os.ReadFile(l.leasePath())
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("读取租约文件失败: %w", err)
+	}
+
+	var lease fileLease
+	if err := json.Unmarshal(data, &lease); err != nil {
+		// 租约文件损坏时视为无人持有
+		return nil, nil
+	}
+	return &lease, nil
+}
+
+// writeLease 写入本实例的租约（先写临时文件再重命名，避免读到半截内容）
+func (l *FileLock) writeLease() error {
+	data, err := json.Marshal(&fileLease{
+		Owner:     l.ownerID,
+		ExpiresAt: time.Now().Add(l.ttl).UnixMilli(),
+	})
+	if err != nil {
+		return fmt.Errorf("序列化租约失败: %w", err)
+	}
+
+	tmpPath := l.leasePath() + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("写入租约文件失败: %w", err)
+	}
+	if err := os.Rename(tmpPath, l.leasePath()); err != nil {
+		return fmt.Errorf("替换租约文件失败: %w", err)
+	}
+	return nil
+}
+
+// leasePath 租约文件路径
+func (l *FileLock) leasePath() string {
+	return filepath.Join(l.dir, l.name+".lease")
+}
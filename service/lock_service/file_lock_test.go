@@ -0,0 +1,58 @@
+package lock_service
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestFileLock(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	first, err := NewFileLock(dir, "leader", "instance-a", time.Second)
+	if err != nil {
+		t.Fatalf("NewFileLock() failed, err: %v", err)
+	}
+	second, _ := NewFileLock(dir, "leader", "instance-b", time.Second)
+
+	if ok, err := first.TryAcquire(ctx); !ok || err != nil {
+		t.Fatalf("first.TryAcquire() = %v, %v; want acquired", ok, err)
+	}
+	if ok, _ := second.TryAcquire(ctx); ok {
+		t.Fatalf("second.TryAcquire() should fail while lease is held")
+	}
+	if holder, _ := second.Holder(ctx); holder != "instance-a" {
+		t.Errorf("Holder() = %q, want instance-a", holder)
+	}
+	if err := second.Renew(ctx); err != ErrLockLost {
+		t.Errorf("second.Renew() = %v, want ErrLockLost", err)
+	}
+
+	if err := first.Release(ctx); err != nil {
+		t.Fatalf("first.Release() failed, err: %v", err)
+	}
+	if ok, _ := second.TryAcquire(ctx); !ok {
+		t.Fatalf("second.TryAcquire() should succeed after release")
+	}
+	if err := first.Renew(ctx); err != ErrLockLost {
+		t.Errorf("first.Renew() after losing lease = %v, want ErrLockLost", err)
+	}
+}
+
+func TestFileLockExpiry(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	first, _ := NewFileLock(dir, "leader", "instance-a", 50*time.Millisecond)
+	second, _ := NewFileLock(dir, "leader", "instance-b", time.Second)
+
+	if ok, _ := first.TryAcquire(ctx); !ok {
+		t.Fatalf("first.TryAcquire() should succeed")
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	if ok, _ := second.TryAcquire(ctx); !ok {
+		t.Errorf("second.TryAcquire() should take over an expired lease")
+	}
+}
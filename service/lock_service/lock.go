@@ -0,0 +1,33 @@
+package lock_service
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	// ErrLockNotHeld 当前实例未持有锁
+	ErrLockNotHeld = errors.New("lock is not held by this instance")
+	// ErrLockLost 锁租约已过期或被其他实例抢占
+	ErrLockLost = errors.New("lock lease lost")
+	// ErrProcessLocked 数据目录已被其他进程使用
+	ErrProcessLocked = errors.New("data directory is locked by another process")
+)
+
+// Lock 分布式锁（租约）接口，持有者需在租约过期前续约
+type Lock interface {
+	// TryAcquire 尝试获取锁，已被其他实例持有时返回 false
+	TryAcquire(ctx context.Context) (bool, error)
+
+	// Renew 续约，租约已丢失时返回 ErrLockLost
+	Renew(ctx context.Context) error
+
+	// Release 主动释放锁
+	Release(ctx context.Context) error
+
+	// Holder 获取当前持有者ID，无人持有时返回空字符串
+	Holder(ctx context.Context) (string, error)
+
+	// OwnerID 获取本实例的ID
+	OwnerID() string
+}
@@ -0,0 +1,69 @@
+package lock_service
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ProcessLockFile 数据目录中启动锁文件的名称
+const ProcessLockFile = "push-base-service.lock"
+
+// ProcessLock 数据目录的进程锁（操作系统文件锁），防止两个进程同时打开同一个 Pebble 数据目录；
+// 进程退出（包括崩溃）时由操作系统自动释放，不会遗留失效的锁
+type ProcessLock struct {
+	file *os.File
+	path string
+}
+
+// AcquireProcessLock 对数据目录加进程锁（目录不存在时创建），已被其他进程持有时立即返回 ErrProcessLocked，
+// 错误信息中带持有进程的 PID
+func AcquireProcessLock(dir string) (*ProcessLock, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("创建数据目录失败: %w", err)
+	}
+
+	path := filepath.Join(dir, ProcessLockFile)
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("打开进程锁文件失败: %w", err)
+	}
+	if err := lockFile(file); err != nil {
+		holder, _ := os.ReadFile(path)
+		file.Close()
+		if pid := strings.TrimSpace(string(holder)); pid != "" {
+			return nil, fmt.Errorf("%w: %s (pid %s)", ErrProcessLocked, dir, pid)
+		}
+		return nil, fmt.Errorf("%w: %s (%v)", ErrProcessLocked, dir, err)
+	}
+
+	// 写入本进程 PID，便于排查是哪个进程占用了数据目录
+	if err := file.Truncate(0); err == nil {
+		file.WriteAt([]byte(strconv.Itoa(os.Getpid())+"\n"), 0)
+	}
+	return &ProcessLock{file: file, path: path}, nil
+}
+
+// Path 锁文件路径
+func (l *ProcessLock) Path() string {
+	return l.path
+}
+
+// Release 释放进程锁，可重复调用
+func (l *ProcessLock) Release() error {
+	if l == nil || l.file == nil {
+		return nil
+	}
+	file := l.file
+	l.file = nil
+
+	// 先清空 PID 再解锁，避免解锁后其他进程读到过期的 PID
+	file.Truncate(0)
+	unlockErr := unlockFile(file)
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("关闭进程锁文件失败: %w", err)
+	}
+	return unlockErr
+}
@@ -0,0 +1,39 @@
+package lock_service
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestProcessLock(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "pebble")
+
+	lock, err := AcquireProcessLock(dir)
+	if err != nil {
+		t.Fatalf("AcquireProcessLock() failed, err: %v", err)
+	}
+	if data, _ := os.ReadFile(lock.Path()); strings.TrimSpace(string(data)) != strconv.Itoa(os.Getpid()) {
+		t.Errorf("lock file = %q, want pid %d", data, os.Getpid())
+	}
+
+	// 文件锁按打开的文件生效，同一进程再次加锁同样被拒绝
+	if _, err := AcquireProcessLock(dir); !errors.Is(err, ErrProcessLocked) {
+		t.Fatalf("second AcquireProcessLock() = %v, want ErrProcessLocked", err)
+	}
+
+	if err := lock.Release(); err != nil {
+		t.Fatalf("Release() failed, err: %v", err)
+	}
+	if err := lock.Release(); err != nil {
+		t.Errorf("second Release() = %v, want nil", err)
+	}
+	relocked, err := AcquireProcessLock(dir)
+	if err != nil {
+		t.Fatalf("AcquireProcessLock() after release failed, err: %v", err)
+	}
+	relocked.Release()
+}
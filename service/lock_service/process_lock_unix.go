@@ -0,0 +1,18 @@
+//go:build !windows
+
+package lock_service
+
+import (
+	"os"
+	"syscall"
+)
+
+// lockFile 对文件加非阻塞排他锁（flock）
+func lockFile(file *os.File) error {
+	return syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+}
+
+// unlockFile 释放文件锁
+func unlockFile(file *os.File) error {
+	return syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+}
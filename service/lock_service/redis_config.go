@@ -0,0 +1,9 @@
+package lock_service
+
+// RedisConfig Redis 租约锁配置
+type RedisConfig struct {
+	Addr     string `yaml:"addr" json:"addr"`         // Redis 地址，如 127.0.0.1:6379
+	Password string `yaml:"password" json:"password"` // Redis 密码
+	DB       int    `yaml:"db" json:"db"`             // Redis 数据库编号
+	Key      string `yaml:"key" json:"key"`           // 租约键，值为持有者ID
+}
@@ -0,0 +1,129 @@
+//go:build !noredis
+
+package lock_service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// renewScript 仅在租约仍由本实例持有时续期
+var renewScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0`)
+
+// releaseScript 仅在租约仍由本实例持有时删除
+var releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+
+// RedisLock 基于 Redis 键的租约锁，适用于跨主机部署的多个实例
+// 键值为持有者ID，租约时长后自动过期；续约和释放通过脚本校验持有者，不会误删其他实例的租约
+type RedisLock struct {
+	client  *redis.Client
+	key     string
+	ownerID string
+	ttl     time.Duration
+}
+
+// NewRedisLock 创建 Redis 租约锁并检查连接
+func NewRedisLock(config *RedisConfig, ownerID string, ttl time.Duration) (*RedisLock, error) {
+	if config.Key == "" || ownerID == "" {
+		return nil, fmt.Errorf("租约键和持有者ID不能为空")
+	}
+	if ttl <= 0 {
+		return nil, fmt.Errorf("租约时长必须大于0")
+	}
+
+	client := redis.NewClient(&redis.Options{
+		Addr:     config.Addr,
+		Password: config.Password,
+		DB:       config.DB,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("连接 Redis 失败: %w", err)
+	}
+
+	return &RedisLock{
+		client:  client,
+		key:     config.Key,
+		ownerID: ownerID,
+		ttl:     ttl,
+	}, nil
+}
+
+// OwnerID 获取本实例的ID
+func (l *RedisLock) OwnerID() string {
+	return l.ownerID
+}
+
+// TryAcquire 尝试获取锁，本实例已持有时续约
+func (l *RedisLock) TryAcquire(ctx context.Context) (bool, error) {
+	acquired, err := l.client.SetNX(ctx, l.key, l.ownerID, l.ttl).Result()
+	if err != nil {
+		return false, fmt.Errorf("获取租约失败: %w", err)
+	}
+	if acquired {
+		return true, nil
+	}
+
+	if err := l.Renew(ctx); err != nil {
+		if err == ErrLockLost {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// Renew 续约
+func (l *RedisLock) Renew(ctx context.Context) error {
+	renewed, err := renewScript.Run(ctx, l.client, []string{l.key}, l.ownerID, l.ttl.Milliseconds()).Int()
+	if err != nil {
+		return fmt.Errorf("续约失败: %w", err)
+	}
+	if renewed == 0 {
+		return ErrLockLost
+	}
+	return nil
+}
+
+// Release 主动释放锁
+func (l *RedisLock) Release(ctx context.Context) error {
+	released, err := releaseScript.Run(ctx, l.client, []string{l.key}, l.ownerID).Int()
+	if err != nil {
+		return fmt.Errorf("释放租约失败: %w", err)
+	}
+	if released == 0 {
+		return ErrLockNotHeld
+	}
+	return nil
+}
+
+// Holder 获取当前持有者ID
+func (l *RedisLock) Holder(ctx context.Context) (string, error) {
+	holder, err := l.client.Get(ctx, l.key).Result()
+	if err == redis.Nil {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("获取租约持有者失败: %w", err)
+	}
+	return holder, nil
+}
+
+// Close 关闭 Redis 连接
+func (l *RedisLock) Close() error {
+	return l.client.Close()
+}
@@ -0,0 +1,23 @@
+//go:build noredis
+
+package lock_service
+
+import (
+	"fmt"
+	"time"
+)
+
+// RedisLock 当前构建未包含 Redis 支持
+type RedisLock struct {
+	Lock
+}
+
+// NewRedisLock 当前构建未包含 Redis 支持
+func NewRedisLock(config *RedisConfig, ownerID string, ttl time.Duration) (*RedisLock, error) {
+	return nil, fmt.Errorf("当前构建未包含 Redis 支持（noredis），无法使用 redis 租约后端")
+}
+
+// Close 当前构建未包含 Redis 支持
+func (l *RedisLock) Close() error {
+	return nil
+}
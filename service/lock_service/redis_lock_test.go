@@ -0,0 +1,60 @@
+//go:build !noredis
+
+package lock_service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+func TestRedisLock(t *testing.T) {
+	server := miniredis.RunT(t)
+	ctx := context.Background()
+
+	newLock := func(ownerID string) *RedisLock {
+		lock, err := NewRedisLock(&RedisConfig{Addr: server.Addr(), Key: "push:leader"}, ownerID, time.Second)
+		if err != nil {
+			t.Fatalf("NewRedisLock() failed, err: %v", err)
+		}
+		t.Cleanup(func() { lock.Close() })
+		return lock
+	}
+	first, second := newLock("instance-a"), newLock("instance-b")
+
+	if ok, err := first.TryAcquire(ctx); !ok || err != nil {
+		t.Fatalf("first.TryAcquire() = %v, %v; want acquired", ok, err)
+	}
+	if ok, _ := first.TryAcquire(ctx); !ok {
+		t.Errorf("first.TryAcquire() should succeed again while holding the lease")
+	}
+	if ok, _ := second.TryAcquire(ctx); ok {
+		t.Fatalf("second.TryAcquire() should fail while lease is held")
+	}
+	if holder, _ := second.Holder(ctx); holder != "instance-a" {
+		t.Errorf("Holder() = %q, want instance-a", holder)
+	}
+	if err := second.Renew(ctx); err != ErrLockLost {
+		t.Errorf("second.Renew() = %v, want ErrLockLost", err)
+	}
+	if err := second.Release(ctx); err != ErrLockNotHeld {
+		t.Errorf("second.Release() = %v, want ErrLockNotHeld", err)
+	}
+
+	// 租约过期后其他实例可以获取
+	server.FastForward(2 * time.Second)
+	if ok, _ := second.TryAcquire(ctx); !ok {
+		t.Fatalf("second.TryAcquire() should succeed after the lease expires")
+	}
+	if err := first.Renew(ctx); err != ErrLockLost {
+		t.Errorf("first.Renew() after losing lease = %v, want ErrLockLost", err)
+	}
+	if err := second.Release(ctx); err != nil {
+		t.Errorf("second.Release() failed, err: %v", err)
+	}
+	if holder, _ := first.Holder(ctx); holder != "" {
+		t.Errorf("Holder() after release = %q, want empty", holder)
+	}
+}
@@ -0,0 +1,157 @@
+package membership_service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"push-base-service/service/metrics_service"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+var memberLookupsCounter = metrics_service.NewCounterVec(
+	"push_membership_lookups_total", "Number of group member lookups by result (cached, fetched, failed)", "result")
+
+// groupMembersResponse 群成员接口响应：{"data": {"list": [{"metaId": "..."}]}}
+type groupMembersResponse struct {
+	Data struct {
+		List []struct {
+			MetaID string `json:"metaId"`
+		} `json:"list"`
+	} `json:"data"`
+}
+
+// cachedMembers 缓存的群成员列表
+type cachedMembers struct {
+	members   map[string]bool
+	expiresAt time.Time
+}
+
+// Checker 接收用户校验器，群成员列表按群缓存
+type Checker struct {
+	config *Config
+	client *http.Client
+	now    func() time.Time
+
+	mu    sync.Mutex
+	cache map[string]*cachedMembers
+}
+
+// NewChecker 创建接收用户校验器
+func NewChecker(config *Config) *Checker {
+	if config == nil {
+		config = DefaultConfig()
+	}
+	config.ApplyDefaults()
+
+	return &Checker{
+		config: config,
+		client: &http.Client{Timeout: config.Timeout},
+		now:    time.Now,
+		cache:  make(map[string]*cachedMembers),
+	}
+}
+
+// Verifies 该消息类型是否需要校验接收用户
+func (c *Checker) Verifies(msgType string) bool {
+	return slices.Contains(c.config.MessageTypes, msgType)
+}
+
+// FailClosed 群组接口不可用时是否跳过推送
+func (c *Checker) FailClosed() bool {
+	return c.config.FailClosed
+}
+
+// FilterGroupMembers 过滤出属于该群的用户，返回群成员和非群成员
+func (c *Checker) FilterGroupMembers(ctx context.Context, groupId string, metaIds []string) (members, rejected []string, err error) {
+	if groupId == "" {
+		return nil, nil, fmt.Errorf("群聊ID为空，无法校验接收用户")
+	}
+	groupMembers, err := c.GetGroupMembers(ctx, groupId)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, metaId := range metaIds {
+		if groupMembers[metaId] {
+			members = append(members, metaId)
+		} else {
+			rejected = append(rejected, metaId)
+		}
+	}
+	return members, rejected, nil
+}
+
+// GetGroupMembers 获取群成员，优先使用缓存
+func (c *Checker) GetGroupMembers(ctx context.Context, groupId string) (map[string]bool, error) {
+	now := c.now()
+	c.mu.Lock()
+	if cached, exists := c.cache[groupId]; exists && now.Before(cached.expiresAt) {
+		c.mu.Unlock()
+		memberLookupsCounter.Inc("cached")
+		return cached.members, nil
+	}
+	c.mu.Unlock()
+
+	members, err := c.fetchGroupMembers(ctx, groupId)
+	if err != nil {
+		memberLookupsCounter.Inc("failed")
+		return nil, err
+	}
+	memberLookupsCounter.Inc("fetched")
+
+	c.mu.Lock()
+	c.cache[groupId] = &cachedMembers{members: members, expiresAt: now.Add(c.config.CacheTTL)}
+	for id, cached := range c.cache {
+		if !now.Before(cached.expiresAt) {
+			delete(c.cache, id)
+		}
+	}
+	c.mu.Unlock()
+	return members, nil
+}
+
+// fetchGroupMembers 调用群成员接口
+func (c *Checker) fetchGroupMembers(ctx context.Context, groupId string) (map[string]bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.config.Timeout)
+	defer cancel()
+
+	endpoint := strings.ReplaceAll(c.config.Endpoint, "{groupId}", url.QueryEscape(groupId))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("创建群成员请求失败: %w", err)
+	}
+	if c.config.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.config.APIKey)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("查询群成员失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 16<<20))
+	if err != nil {
+		return nil, fmt.Errorf("读取群成员响应失败: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("查询群成员失败: HTTP %d", resp.StatusCode)
+	}
+
+	var result groupMembersResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("解析群成员响应失败: %w", err)
+	}
+	members := make(map[string]bool, len(result.Data.List))
+	for _, member := range result.Data.List {
+		if member.MetaID != "" {
+			members[member.MetaID] = true
+		}
+	}
+	return members, nil
+}
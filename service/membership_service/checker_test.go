@@ -0,0 +1,76 @@
+package membership_service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFilterGroupMembersUsesCache(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		if r.URL.Query().Get("groupId") != "group1" || r.Header.Get("Authorization") != "Bearer secret" {
+			t.Errorf("unexpected request: %s %v", r.URL, r.Header)
+		}
+		w.Write([]byte(`{"code":0,"data":{"list":[{"metaId":"alice"},{"metaId":"bob"}]}}`))
+	}))
+	defer server.Close()
+
+	checker := NewChecker(&Config{Enabled: true, Endpoint: server.URL + "?groupId={groupId}", APIKey: "secret"})
+	now := time.Unix(1700000000, 0)
+	checker.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		members, rejected, err := checker.FilterGroupMembers(context.Background(), "group1", []string{"alice", "mallory", "bob"})
+		if err != nil {
+			t.Fatalf("FilterGroupMembers() error = %v", err)
+		}
+		if len(members) != 2 || len(rejected) != 1 || rejected[0] != "mallory" {
+			t.Errorf("FilterGroupMembers() = %v, %v", members, rejected)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("group API called %d times, want 1 (cached)", calls)
+	}
+
+	// 缓存过期后重新查询
+	now = now.Add(DefaultConfig().CacheTTL + time.Second)
+	checker.FilterGroupMembers(context.Background(), "group1", []string{"alice"})
+	if calls != 2 {
+		t.Errorf("group API called %d times after expiry, want 2", calls)
+	}
+}
+
+func TestFilterGroupMembersError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	checker := NewChecker(&Config{Enabled: true, Endpoint: server.URL + "?groupId={groupId}"})
+	if _, _, err := checker.FilterGroupMembers(context.Background(), "group1", []string{"alice"}); err == nil {
+		t.Error("expected error when group API fails")
+	}
+	if _, _, err := checker.FilterGroupMembers(context.Background(), "", []string{"alice"}); err == nil {
+		t.Error("expected error for empty group id")
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	if err := (&Config{MessageTypes: []string{MessageTypeGroupChat}}).Validate(); err == nil {
+		t.Error("group_chat without endpoint should be invalid")
+	}
+	if err := (&Config{MessageTypes: []string{MessageTypeGroupChat}, Endpoint: "https://example.com/members"}).Validate(); err == nil {
+		t.Error("endpoint without {groupId} should be invalid")
+	}
+	if err := (&Config{MessageTypes: []string{"payment"}}).Validate(); err == nil {
+		t.Error("unsupported message type should be invalid")
+	}
+	if err := (&Config{MessageTypes: []string{MessageTypePrivateChat}}).Validate(); err != nil {
+		t.Errorf("private_chat should not require endpoint: %v", err)
+	}
+}
@@ -0,0 +1,68 @@
+package membership_service
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// 支持校验的消息类型
+const (
+	MessageTypePrivateChat = "private_chat" // 接收用户须为会话双方（不调用群组接口）
+	MessageTypeGroupChat   = "group_chat"   // 接收用户须为群成员
+)
+
+// Config 上游接收用户校验配置
+// 上游消息中的 repostMetaIds 默认直接信任，开启后推送前按群组接口（带缓存）校验接收用户，不属于该聊天的用户不推送
+type Config struct {
+	Enabled      bool          `yaml:"enabled" json:"enabled"`             // 是否启用接收用户校验
+	Endpoint     string        `yaml:"endpoint" json:"endpoint"`           // 群成员接口地址，{groupId} 会被替换，如 https://api.example.com/group-member-list?groupId={groupId}
+	APIKey       string        `yaml:"api_key" json:"api_key"`             // 群成员接口密钥（以 Authorization: Bearer 发送）
+	Timeout      time.Duration `yaml:"timeout" json:"timeout"`             // 单次查询超时
+	CacheTTL     time.Duration `yaml:"cache_ttl" json:"cache_ttl"`         // 群成员列表缓存时长
+	MessageTypes []string      `yaml:"message_types" json:"message_types"` // 需要校验的消息类型：private_chat / group_chat
+	FailClosed   bool          `yaml:"fail_closed" json:"fail_closed"`     // 群组接口不可用时跳过该消息的推送（默认照常推送）
+}
+
+// DefaultConfig 返回默认配置
+func DefaultConfig() *Config {
+	return &Config{
+		Timeout:      3 * time.Second,
+		CacheTTL:     5 * time.Minute,
+		MessageTypes: []string{MessageTypeGroupChat},
+	}
+}
+
+// ApplyDefaults 为缺失的配置项填充默认值
+func (c *Config) ApplyDefaults() {
+	defaults := DefaultConfig()
+
+	if c.Timeout <= 0 {
+		c.Timeout = defaults.Timeout
+	}
+	if c.CacheTTL <= 0 {
+		c.CacheTTL = defaults.CacheTTL
+	}
+	if len(c.MessageTypes) == 0 {
+		c.MessageTypes = defaults.MessageTypes
+	}
+}
+
+// Validate 校验配置
+func (c *Config) Validate() error {
+	for _, msgType := range c.MessageTypes {
+		switch msgType {
+		case MessageTypePrivateChat:
+		case MessageTypeGroupChat:
+			if c.Endpoint == "" {
+				return fmt.Errorf("校验群聊接收用户需要配置群成员接口地址")
+			}
+			if !strings.Contains(c.Endpoint, "{groupId}") {
+				return fmt.Errorf("群成员接口地址缺少 {groupId} 占位符: %s", c.Endpoint)
+			}
+		default:
+			return fmt.Errorf("不支持校验的消息类型: %s（可选 private_chat/group_chat）", msgType)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,266 @@
+package metrics_service
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// 指标类型
+const (
+	TypeCounter = "counter"
+	TypeGauge   = "gauge"
+)
+
+// sample 单个标签组合对应的指标值
+type sample struct {
+	labelValues []string
+	value       float64
+}
+
+// metricVec 带标签的指标集合
+type metricVec struct {
+	name       string
+	help       string
+	metricType string
+	labelNames []string
+	samples    map[string]*sample
+	mu         sync.RWMutex
+}
+
+// CounterVec 只增不减的计数器
+type CounterVec struct {
+	*metricVec
+}
+
+// GaugeVec 可增可减的仪表盘指标
+type GaugeVec struct {
+	*metricVec
+}
+
+// Registry 指标注册表
+type Registry struct {
+	metrics map[string]*metricVec
+	mu      sync.RWMutex
+}
+
+// 默认注册表
+var defaultRegistry = NewRegistry()
+
+// NewRegistry 创建指标注册表
+func NewRegistry() *Registry {
+	return &Registry{
+		metrics: make(map[string]*metricVec),
+	}
+}
+
+// DefaultRegistry 获取默认注册表
+func DefaultRegistry() *Registry {
+	return defaultRegistry
+}
+
+// register 注册指标，同名指标已存在时直接复用
+func (r *Registry) register(name, help, metricType string, labelNames []string) *metricVec {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if existing, exists := r.metrics[name]; exists {
+		if existing.metricType != metricType {
+			panic(fmt.Sprintf("metric %s already registered as %s", name, existing.metricType))
+		}
+		return existing
+	}
+
+	vec := &metricVec{
+		name:       name,
+		help:       help,
+		metricType: metricType,
+		labelNames: labelNames,
+		samples:    make(map[string]*sample),
+	}
+	r.metrics[name] = vec
+	return vec
+}
+
+// NewCounterVec 在注册表中创建计数器
+func (r *Registry) NewCounterVec(name, help string, labelNames ...string) *CounterVec {
+	return &CounterVec{r.register(name, help, TypeCounter, labelNames)}
+}
+
+// NewGaugeVec 在注册表中创建仪表盘指标
+func (r *Registry) NewGaugeVec(name, help string, labelNames ...string) *GaugeVec {
+	return &GaugeVec{r.register(name, help, TypeGauge, labelNames)}
+}
+
+// NewCounterVec 在默认注册表中创建计数器
+func NewCounterVec(name, help string, labelNames ...string) *CounterVec {
+	return defaultRegistry.NewCounterVec(name, help, labelNames...)
+}
+
+// NewGaugeVec 在默认注册表中创建仪表盘指标
+func NewGaugeVec(name, help string, labelNames ...string) *GaugeVec {
+	return defaultRegistry.NewGaugeVec(name, help, labelNames...)
+}
+
+// update 按标签值更新指标
+func (v *metricVec) update(labelValues []string, fn func(s *sample)) {
+	if len(labelValues) != len(v.labelNames) {
+		panic(fmt.Sprintf("metric %s expects %d label values, got %d", v.name, len(v.labelNames), len(labelValues)))
+	}
+
+	key := strings.Join(labelValues, "\xff")
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	s, exists := v.samples[key]
+	if !exists {
+		s = &sample{labelValues: append([]string(nil), labelValues...)}
+		v.samples[key] = s
+	}
+	fn(s)
+}
+
+// get 按标签值获取指标当前值
+func (v *metricVec) get(labelValues []string) float64 {
+	key := strings.Join(labelValues, "\xff")
+
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	if s, exists := v.samples[key]; exists {
+		return s.value
+	}
+	return 0
+}
+
+// Inc 计数器加一
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add 计数器增加指定值（负数会被忽略）
+func (c *CounterVec) Add(delta float64, labelValues ...string) {
+	if delta < 0 {
+		return
+	}
+	c.update(labelValues, func(s *sample) { s.value += delta })
+}
+
+// Get 获取计数器当前值
+func (c *CounterVec) Get(labelValues ...string) float64 {
+	return c.get(labelValues)
+}
+
+// Set 设置仪表盘指标值
+func (g *GaugeVec) Set(value float64, labelValues ...string) {
+	g.update(labelValues, func(s *sample) { s.value = value })
+}
+
+// Inc 仪表盘指标加一
+func (g *GaugeVec) Inc(labelValues ...string) {
+	g.Add(1, labelValues...)
+}
+
+// Dec 仪表盘指标减一
+func (g *GaugeVec) Dec(labelValues ...string) {
+	g.Add(-1, labelValues...)
+}
+
+// Add 仪表盘指标增加指定值
+func (g *GaugeVec) Add(delta float64, labelValues ...string) {
+	g.update(labelValues, func(s *sample) { s.value += delta })
+}
+
+// Get 获取仪表盘指标当前值
+func (g *GaugeVec) Get(labelValues ...string) float64 {
+	return g.get(labelValues)
+}
+
+// WritePrometheus 以 Prometheus 文本格式输出所有指标
+func (r *Registry) WritePrometheus(w io.Writer) error {
+	r.mu.RLock()
+	names := make([]string, 0, len(r.metrics))
+	for name := range r.metrics {
+		names = append(names, name)
+	}
+	r.mu.RUnlock()
+	sort.Strings(names)
+
+	for _, name := range names {
+		r.mu.RLock()
+		vec := r.metrics[name]
+		r.mu.RUnlock()
+
+		if err := vec.writeTo(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeTo 输出单个指标
+func (v *metricVec) writeTo(w io.Writer) error {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", v.name, escapeHelp(v.help), v.name, v.metricType); err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(v.samples))
+	for key := range v.samples {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		s := v.samples[key]
+		if _, err := fmt.Fprintf(w, "%s%s %s\n", v.name, formatLabels(v.labelNames, s.labelValues), formatValue(s.value)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// formatLabels 格式化标签
+func formatLabels(names, values []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("{")
+	for i, name := range names {
+		if i > 0 {
+			sb.WriteString(",")
+		}
+		sb.WriteString(name)
+		sb.WriteString(`="`)
+		sb.WriteString(escapeLabelValue(values[i]))
+		sb.WriteString(`"`)
+	}
+	sb.WriteString("}")
+	return sb.String()
+}
+
+// formatValue 格式化指标值
+func formatValue(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+// escapeHelp 转义 HELP 文本
+func escapeHelp(help string) string {
+	help = strings.ReplaceAll(help, `\`, `\\`)
+	return strings.ReplaceAll(help, "\n", `\n`)
+}
+
+// escapeLabelValue 转义标签值
+func escapeLabelValue(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, "\n", `\n`)
+	return strings.ReplaceAll(value, `"`, `\"`)
+}
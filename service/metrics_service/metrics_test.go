@@ -0,0 +1,54 @@
+package metrics_service
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWritePrometheus(t *testing.T) {
+	registry := NewRegistry()
+	counter := registry.NewCounterVec("test_messages_total", "Messages received", "upstream")
+	gauge := registry.NewGaugeVec("test_connected", "Connection state", "upstream")
+
+	counter.Inc("shard-1")
+	counter.Add(2, "shard-1")
+	counter.Inc(`sha"rd`)
+	gauge.Set(1, "shard-1")
+	gauge.Dec("shard-2")
+
+	if got := counter.Get("shard-1"); got != 3 {
+		t.Errorf("counter.Get() = %v, want 3", got)
+	}
+
+	var buf bytes.Buffer
+	if err := registry.WritePrometheus(&buf); err != nil {
+		t.Fatalf("WritePrometheus() failed, err: %v", err)
+	}
+	output := buf.String()
+
+	for _, expected := range []string{
+		"# TYPE test_connected gauge",
+		`test_connected{upstream="shard-1"} 1`,
+		`test_connected{upstream="shard-2"} -1`,
+		"# TYPE test_messages_total counter",
+		`test_messages_total{upstream="shard-1"} 3`,
+		`test_messages_total{upstream="sha\"rd"} 1`,
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("output missing %q:\n%s", expected, output)
+		}
+	}
+}
+
+func TestRegisterReuse(t *testing.T) {
+	registry := NewRegistry()
+	first := registry.NewCounterVec("test_reuse_total", "reuse")
+	second := registry.NewCounterVec("test_reuse_total", "reuse")
+
+	first.Inc()
+	second.Inc()
+	if got := first.Get(); got != 2 {
+		t.Errorf("reused counter = %v, want 2", got)
+	}
+}
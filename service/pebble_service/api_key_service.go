@@ -0,0 +1,121 @@
+package pebble_service
+
+import (
+	"fmt"
+	"push-base-service/models"
+	"sort"
+	"time"
+)
+
+// apiKeysRepo API Key 集合存储
+func (ps *PebbleService) apiKeysRepo() *repository[models.APIKeyRecord] {
+	return newRepository[models.APIKeyRecord](ps, CollectionAPIKeys, "API Key")
+}
+
+// SaveAPIKey 保存 API Key，名称已存在时返回错误
+func (ps *PebbleService) SaveAPIKey(record *models.APIKeyRecord) error {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	if record.Name == "" || record.KeyHash == "" {
+		return fmt.Errorf("Key 名称和哈希不能为空")
+	}
+
+	repo := ps.apiKeysRepo()
+	exists := false
+	if err := repo.ScanPrefix("", func(key string, existing *models.APIKeyRecord) bool {
+		exists = existing.Name == record.Name
+		return !exists
+	}); err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("API Key 名称已存在: %s", record.Name)
+	}
+
+	if record.CreatedAt == 0 {
+		record.CreatedAt = time.Now().Unix()
+	}
+	return repo.Put(record.KeyHash, record)
+}
+
+// GetAPIKeyByHash 按 Key 的哈希获取 API Key，不存在时返回 nil
+func (ps *PebbleService) GetAPIKeyByHash(keyHash string) (*models.APIKeyRecord, error) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	return ps.apiKeysRepo().Get(keyHash)
+}
+
+// ListAPIKeys 按名称列出所有 API Key
+func (ps *PebbleService) ListAPIKeys() ([]*models.APIKeyRecord, error) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	records := make([]*models.APIKeyRecord, 0)
+	err := ps.apiKeysRepo().ScanPrefix("", func(key string, record *models.APIKeyRecord) bool {
+		records = append(records, record)
+		return true
+	})
+	sort.Slice(records, func(i, j int) bool { return records[i].Name < records[j].Name })
+	return records, err
+}
+
+// DeleteAPIKey 按名称删除 API Key，返回是否存在
+func (ps *PebbleService) DeleteAPIKey(name string) (bool, error) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	count, err := ps.apiKeysRepo().DeleteWhere("", func(key string, record *models.APIKeyRecord) bool {
+		return record.Name == name
+	})
+	return count > 0, err
+}
+
+// SaveAPIKey 全局方法：保存 API Key
+func SaveAPIKey(record *models.APIKeyRecord) error {
+	service := GetGlobalService()
+	if service == nil {
+		return fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.SaveAPIKey(record)
+}
+
+// GetAPIKeyByHash 全局方法：按哈希获取 API Key
+func GetAPIKeyByHash(keyHash string) (*models.APIKeyRecord, error) {
+	service := GetGlobalService()
+	if service == nil {
+		return nil, fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return nil, fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.GetAPIKeyByHash(keyHash)
+}
+
+// ListAPIKeys 全局方法：列出 API Key
+func ListAPIKeys() ([]*models.APIKeyRecord, error) {
+	service := GetGlobalService()
+	if service == nil {
+		return nil, fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return nil, fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.ListAPIKeys()
+}
+
+// DeleteAPIKey 全局方法：删除 API Key
+func DeleteAPIKey(name string) (bool, error) {
+	service := GetGlobalService()
+	if service == nil {
+		return false, fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return false, fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.DeleteAPIKey(name)
+}
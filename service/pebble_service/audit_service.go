@@ -0,0 +1,169 @@
+package pebble_service
+
+import (
+	"fmt"
+	"push-base-service/models"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultPushAuditLimit 每个用户默认保留的推送审计记录数
+const DefaultPushAuditLimit = 500
+
+var (
+	// pushAuditMu 保证审计记录"写入-裁剪"的原子性
+	pushAuditMu sync.Mutex
+	// pushAuditSeq 同一毫秒内的记录序号，保证记录ID唯一且有序
+	pushAuditSeq atomic.Uint64
+)
+
+// pushAuditRepo 推送审计日志集合存储
+func (ps *PebbleService) pushAuditRepo() *repository[models.PushAuditEntry] {
+	return newRepository[models.PushAuditEntry](ps, CollectionPushAudit, "推送审计")
+}
+
+// getPushAuditPrefix 生成用户审计记录的键前缀
+func getPushAuditPrefix(metaId string) string {
+	return metaId + ":"
+}
+
+// AddPushAudits 写入推送审计记录，每个用户超过 limit 时丢弃最早的记录
+func (ps *PebbleService) AddPushAudits(entries []*models.PushAuditEntry, limit int) error {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	if skipNonCriticalWrite(CollectionPushAudit) {
+		return nil
+	}
+
+	if limit <= 0 {
+		limit = DefaultPushAuditLimit
+	}
+
+	repo := ps.pushAuditRepo()
+
+	pushAuditMu.Lock()
+	defer pushAuditMu.Unlock()
+
+	now := time.Now()
+	users := make(map[string]bool)
+	for _, entry := range entries {
+		if entry.MetaID == "" {
+			continue
+		}
+		if entry.Timestamp == 0 {
+			entry.Timestamp = now.UnixMilli()
+		}
+		if entry.ID == "" {
+			entry.ID = fmt.Sprintf("%020d-%06d", entry.Timestamp, pushAuditSeq.Add(1)%1000000)
+		}
+		if err := repo.Put(getPushAuditPrefix(entry.MetaID)+entry.ID, entry); err != nil {
+			return err
+		}
+		users[entry.MetaID] = true
+	}
+
+	// 裁剪超出保留数量的旧记录
+	for metaId := range users {
+		prefix := getPushAuditPrefix(metaId)
+		count := 0
+		oldestKept := ""
+		err := repo.ScanPrefixReverse(prefix, func(key string, _ *models.PushAuditEntry) bool {
+			count++
+			if count == limit {
+				oldestKept = key
+				return false
+			}
+			return true
+		})
+		if err != nil {
+			return err
+		}
+		if oldestKept == "" {
+			continue
+		}
+		if _, err := repo.DeleteWhere(prefix, func(key string, _ *models.PushAuditEntry) bool {
+			return key < oldestKept
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetPushAudits 获取用户推送时间早于 before（Unix 毫秒，0 表示不限）的审计记录，按时间倒序，
+// 最多 limit 条（<= 0 或超过 DefaultPushAuditLimit 时为 DefaultPushAuditLimit）
+func (ps *PebbleService) GetPushAudits(metaId string, before int64, limit int) ([]*models.PushAuditEntry, error) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	if metaId == "" {
+		return nil, fmt.Errorf("MetaID 不能为空")
+	}
+	if limit <= 0 || limit > DefaultPushAuditLimit {
+		limit = DefaultPushAuditLimit
+	}
+
+	entries := []*models.PushAuditEntry{}
+	err := ps.pushAuditRepo().ScanPrefixReverse(getPushAuditPrefix(metaId), func(key string, entry *models.PushAuditEntry) bool {
+		if before > 0 && entry.Timestamp >= before {
+			return true
+		}
+		entries = append(entries, entry)
+		return len(entries) < limit
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// PurgePushAudits 删除推送时间早于 before（Unix 毫秒）的审计记录，返回删除的记录数
+func (ps *PebbleService) PurgePushAudits(before int64) (int, error) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	pushAuditMu.Lock()
+	defer pushAuditMu.Unlock()
+
+	return ps.pushAuditRepo().DeleteWhere("", func(_ string, entry *models.PushAuditEntry) bool {
+		return entry.Timestamp < before
+	})
+}
+
+// AddPushAudits 全局方法：写入推送审计记录
+func AddPushAudits(entries []*models.PushAuditEntry, limit int) error {
+	service := GetGlobalService()
+	if service == nil {
+		return fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.AddPushAudits(entries, limit)
+}
+
+// GetPushAudits 全局方法：获取用户的推送审计记录
+func GetPushAudits(metaId string, before int64, limit int) ([]*models.PushAuditEntry, error) {
+	service := GetGlobalService()
+	if service == nil {
+		return nil, fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return nil, fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.GetPushAudits(metaId, before, limit)
+}
+
+// PurgePushAudits 全局方法：删除过期的推送审计记录
+func PurgePushAudits(before int64) (int, error) {
+	service := GetGlobalService()
+	if service == nil {
+		return 0, fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return 0, fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.PurgePushAudits(before)
+}
@@ -0,0 +1,64 @@
+package pebble_service
+
+import (
+	"fmt"
+	"push-base-service/models"
+	"testing"
+)
+
+func TestPushAuditsTrimAndPage(t *testing.T) {
+	service := newTestPebbleService(t)
+
+	var entries []*models.PushAuditEntry
+	for i := 1; i <= 5; i++ {
+		entries = append(entries, &models.PushAuditEntry{MetaID: "alice", Title: fmt.Sprintf("t%d", i), Timestamp: int64(i * 1000)})
+	}
+	entries = append(entries, &models.PushAuditEntry{MetaID: "bob", Title: "b1", Timestamp: 1000})
+	if err := service.AddPushAudits(entries, 3); err != nil {
+		t.Fatalf("AddPushAudits() failed, err: %v", err)
+	}
+
+	// 每个用户只保留最近 3 条，按时间倒序返回
+	got, err := service.GetPushAudits("alice", 0, 0)
+	if err != nil {
+		t.Fatalf("GetPushAudits() failed, err: %v", err)
+	}
+	if len(got) != 3 || got[0].Title != "t5" || got[2].Title != "t3" {
+		t.Fatalf("got %v, want t5..t3", auditTitles(got))
+	}
+
+	// before 翻页
+	got, _ = service.GetPushAudits("alice", 4000, 0)
+	if len(got) != 1 || got[0].Title != "t3" {
+		t.Errorf("before=4000: got %v, want [t3]", auditTitles(got))
+	}
+	got, _ = service.GetPushAudits("alice", 0, 2)
+	if len(got) != 2 || got[1].Title != "t4" {
+		t.Errorf("limit=2: got %v, want [t5 t4]", auditTitles(got))
+	}
+
+	// 其他用户不受裁剪影响
+	if got, _ := service.GetPushAudits("bob", 0, 0); len(got) != 1 {
+		t.Errorf("bob: got %v, want [b1]", auditTitles(got))
+	}
+
+	// 清理过期记录
+	count, err := service.PurgePushAudits(4000)
+	if err != nil || count != 2 {
+		t.Fatalf("PurgePushAudits() = %d, %v, want 2", count, err)
+	}
+	if got, _ := service.GetPushAudits("alice", 0, 0); len(got) != 2 {
+		t.Errorf("after purge: got %v, want [t5 t4]", auditTitles(got))
+	}
+	if got, _ := service.GetPushAudits("bob", 0, 0); len(got) != 0 {
+		t.Errorf("bob after purge: got %v, want none", auditTitles(got))
+	}
+}
+
+func auditTitles(entries []*models.PushAuditEntry) []string {
+	out := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		out = append(out, entry.Title)
+	}
+	return out
+}
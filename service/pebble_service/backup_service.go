@@ -0,0 +1,447 @@
+package pebble_service
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"push-base-service/models"
+	"sort"
+	"strings"
+	"time"
+)
+
+// backupManifestName 归档中的清单文件名
+const backupManifestName = "manifest.json"
+
+// Backup 对所有集合创建一致的 Pebble 检查点，并以 tar.gz 格式写入 w
+// 创建检查点期间持有写锁，所有集合处于同一时刻的状态；检查点基于硬链接，耗时很短
+func (ps *PebbleService) Backup(w io.Writer) (*models.BackupManifest, error) {
+	stagingDir, err := os.MkdirTemp(filepath.Dir(filepath.Clean(ps.path)), ".backup-")
+	if err != nil {
+		return nil, fmt.Errorf("创建备份临时目录失败: %w", err)
+	}
+	defer os.RemoveAll(stagingDir)
+
+	manifest, err := ps.checkpointAll(stagingDir)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := writeBackupArchive(w, stagingDir, manifest); err != nil {
+		return nil, err
+	}
+
+	log.Printf("💾 Pebble 备份完成: 集合数=%d", len(manifest.Collections))
+	return manifest, nil
+}
+
+// checkpointAll 在持有写锁的情况下为所有集合创建检查点
+func (ps *PebbleService) checkpointAll(stagingDir string) (*models.BackupManifest, error) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	if ps.collectionMgr != nil && ps.collectionMgr.shared {
+		return ps.checkpointShared(stagingDir)
+	}
+
+	collections, err := ps.listAllCollections()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, collection := range collections {
+		db, err := ps.getCollectionDB(collection)
+		if err != nil {
+			return nil, fmt.Errorf("打开集合 %s 失败: %w", collection, err)
+		}
+		if err := db.db.Checkpoint(filepath.Join(stagingDir, collection)); err != nil {
+			return nil, fmt.Errorf("创建集合 %s 检查点失败: %w", collection, err)
+		}
+	}
+
+	return &models.BackupManifest{
+		CreatedAt:   time.Now().Unix(),
+		Collections: collections,
+	}, nil
+}
+
+// checkpointShared 共享布局下为共享实例创建检查点，清单中记录共享实例目录，调用方需持有写锁
+func (ps *PebbleService) checkpointShared(stagingDir string) (*models.BackupManifest, error) {
+	ps.collectionMgr.mu.Lock()
+	db, err := ps.collectionMgr.openShared()
+	ps.collectionMgr.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Checkpoint(filepath.Join(stagingDir, sharedKeyspaceDir)); err != nil {
+		return nil, fmt.Errorf("创建共享数据库检查点失败: %w", err)
+	}
+
+	return &models.BackupManifest{
+		CreatedAt:   time.Now().Unix(),
+		Collections: []string{sharedKeyspaceDir},
+	}, nil
+}
+
+// listAllCollections 列出磁盘上及已打开的所有集合，调用方需持有锁
+func (ps *PebbleService) listAllCollections() ([]string, error) {
+	if ps.collectionMgr == nil {
+		return nil, fmt.Errorf("集合管理器未初始化")
+	}
+
+	names := make(map[string]bool)
+	for _, name := range ps.collectionMgr.ListCollections() {
+		names[name] = true
+	}
+
+	entries, err := os.ReadDir(ps.path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("读取数据库目录失败: %w", err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			names[entry.Name()] = true
+		}
+	}
+
+	collections := make([]string, 0, len(names))
+	for name := range names {
+		collections = append(collections, name)
+	}
+	sort.Strings(collections)
+	return collections, nil
+}
+
+// writeBackupArchive 将检查点目录打包为 tar.gz
+func writeBackupArchive(w io.Writer, stagingDir string, manifest *models.BackupManifest) error {
+	gzipWriter := gzip.NewWriter(w)
+	tarWriter := tar.NewWriter(gzipWriter)
+
+	manifestData, err := json.Marshal(manifest)
+	if err != nil {
+		return fmt.Errorf("序列化备份清单失败: %w", err)
+	}
+	if err := tarWriter.WriteHeader(&tar.Header{
+		Name:    backupManifestName,
+		Mode:    0644,
+		Size:    int64(len(manifestData)),
+		ModTime: time.Unix(manifest.CreatedAt, 0),
+	}); err != nil {
+		return fmt.Errorf("写入备份清单失败: %w", err)
+	}
+	if _, err := tarWriter.Write(manifestData); err != nil {
+		return fmt.Errorf("写入备份清单失败: %w", err)
+	}
+
+	err = filepath.Walk(stagingDir, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		relPath, err := filepath.Rel(stagingDir, filePath)
+		if err != nil || relPath == "." {
+			return err
+		}
+
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(relPath)
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		file, err := os.Open(filePath)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		_, err = io.Copy(tarWriter, file)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("打包备份文件失败: %w", err)
+	}
+
+	if err := tarWriter.Close(); err != nil {
+		return fmt.Errorf("关闭备份归档失败: %w", err)
+	}
+	if err := gzipWriter.Close(); err != nil {
+		return fmt.Errorf("关闭备份归档失败: %w", err)
+	}
+	return nil
+}
+
+// Restore 从 tar.gz 备份归档恢复所有集合
+// 先解压到临时目录并校验，再关闭所有集合、替换数据目录；原数据目录保留为 <db_path>.pre-restore-<时间戳>
+func (ps *PebbleService) Restore(r io.Reader) (*models.BackupManifest, error) {
+	dataDir := filepath.Clean(ps.path)
+	stagingDir, err := os.MkdirTemp(filepath.Dir(dataDir), ".restore-")
+	if err != nil {
+		return nil, fmt.Errorf("创建恢复临时目录失败: %w", err)
+	}
+	defer os.RemoveAll(stagingDir)
+
+	manifest, err := extractBackupArchive(r, stagingDir)
+	if err != nil {
+		return nil, err
+	}
+	for _, collection := range manifest.Collections {
+		if info, err := os.Stat(filepath.Join(stagingDir, collection)); err != nil || !info.IsDir() {
+			return nil, fmt.Errorf("备份归档缺少集合 %s", collection)
+		}
+	}
+
+	ps.mu.Lock()
+	if err := ps.collectionMgr.CloseAll(); err != nil {
+		ps.mu.Unlock()
+		return nil, fmt.Errorf("关闭集合数据库失败: %w", err)
+	}
+
+	previousDir := fmt.Sprintf("%s.pre-restore-%d", dataDir, time.Now().Unix())
+	if _, err := os.Stat(dataDir); err == nil {
+		if err := os.Rename(dataDir, previousDir); err != nil {
+			ps.mu.Unlock()
+			return nil, fmt.Errorf("移动原数据目录失败: %w", err)
+		}
+	} else {
+		previousDir = ""
+	}
+	if err := os.Rename(stagingDir, dataDir); err != nil {
+		if previousDir != "" {
+			os.Rename(previousDir, dataDir)
+		}
+		ps.mu.Unlock()
+		return nil, fmt.Errorf("替换数据目录失败: %w", err)
+	}
+	ps.mu.Unlock()
+
+	// 恢复后所有用户的令牌都可能变化
+	ps.notifyUserTokensChanged("")
+
+	log.Printf("♻️ Pebble 恢复完成: 集合数=%d, 原数据目录=%s", len(manifest.Collections), previousDir)
+	return manifest, nil
+}
+
+// extractBackupArchive 解压备份归档到指定目录，拒绝越出目录的路径
+func extractBackupArchive(r io.Reader, destDir string) (*models.BackupManifest, error) {
+	gzipReader, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("读取备份归档失败: %w", err)
+	}
+	defer gzipReader.Close()
+
+	var manifest *models.BackupManifest
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("读取备份归档失败: %w", err)
+		}
+
+		name := path.Clean(header.Name)
+		if path.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+			return nil, fmt.Errorf("备份归档包含非法路径: %s", header.Name)
+		}
+		target := filepath.Join(destDir, filepath.FromSlash(name))
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return nil, fmt.Errorf("创建目录失败: %w", err)
+			}
+		case tar.TypeReg:
+			if name == backupManifestName {
+				manifest = &models.BackupManifest{}
+				if err := json.NewDecoder(tarReader).Decode(manifest); err != nil {
+					return nil, fmt.Errorf("解析备份清单失败: %w", err)
+				}
+				continue
+			}
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return nil, fmt.Errorf("创建目录失败: %w", err)
+			}
+			file, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+			if err != nil {
+				return nil, fmt.Errorf("创建文件失败: %w", err)
+			}
+			_, copyErr := io.Copy(file, tarReader)
+			closeErr := file.Close()
+			if copyErr != nil {
+				return nil, fmt.Errorf("写入文件失败: %w", copyErr)
+			}
+			if closeErr != nil {
+				return nil, fmt.Errorf("写入文件失败: %w", closeErr)
+			}
+		default:
+			return nil, fmt.Errorf("备份归档包含不支持的文件类型: %s", header.Name)
+		}
+	}
+
+	if manifest == nil {
+		return nil, fmt.Errorf("备份归档缺少 %s，不是有效的备份", backupManifestName)
+	}
+	return manifest, nil
+}
+
+// BackupToFile 创建备份并写入目录，返回备份文件信息
+func (ps *PebbleService) BackupToFile(dir string) (*models.BackupFile, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("创建备份目录失败: %w", err)
+	}
+
+	name := fmt.Sprintf("pebble-backup-%s.tar.gz", time.Now().UTC().Format("20060102-150405"))
+	tmpPath := filepath.Join(dir, "."+name+".tmp")
+	file, err := os.Create(tmpPath)
+	if err != nil {
+		return nil, fmt.Errorf("创建备份文件失败: %w", err)
+	}
+
+	manifest, err := ps.Backup(file)
+	closeErr := file.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return nil, err
+	}
+
+	// 写完后再重命名，避免留下不完整的备份
+	if err := os.Rename(tmpPath, filepath.Join(dir, name)); err != nil {
+		os.Remove(tmpPath)
+		return nil, fmt.Errorf("保存备份文件失败: %w", err)
+	}
+
+	info, err := os.Stat(filepath.Join(dir, name))
+	if err != nil {
+		return nil, fmt.Errorf("读取备份文件信息失败: %w", err)
+	}
+	return &models.BackupFile{Name: name, Size: info.Size(), CreatedAt: manifest.CreatedAt}, nil
+}
+
+// RestoreFromFile 从备份文件恢复
+func (ps *PebbleService) RestoreFromFile(filePath string) (*models.BackupManifest, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("打开备份文件失败: %w", err)
+	}
+	defer file.Close()
+
+	return ps.Restore(file)
+}
+
+// ListBackupFiles 列出备份目录中的备份文件，按时间从旧到新排序
+func ListBackupFiles(dir string) ([]*models.BackupFile, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []*models.BackupFile{}, nil
+		}
+		return nil, fmt.Errorf("读取备份目录失败: %w", err)
+	}
+
+	backups := make([]*models.BackupFile, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasPrefix(entry.Name(), "pebble-backup-") || !strings.HasSuffix(entry.Name(), ".tar.gz") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		backups = append(backups, &models.BackupFile{
+			Name:      entry.Name(),
+			Size:      info.Size(),
+			CreatedAt: info.ModTime().Unix(),
+		})
+	}
+
+	// 文件名包含 UTC 时间，按名称排序即按时间排序
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].Name < backups[j].Name
+	})
+	return backups, nil
+}
+
+// PruneBackupFiles 只保留最近 keep 个备份，返回删除数量
+func PruneBackupFiles(dir string, keep int) (int, error) {
+	if keep <= 0 {
+		return 0, nil
+	}
+
+	backups, err := ListBackupFiles(dir)
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for i := 0; i < len(backups)-keep; i++ {
+		if err := os.Remove(filepath.Join(dir, backups[i].Name)); err != nil {
+			log.Printf("⚠️ 删除旧备份 %s 失败: %v", backups[i].Name, err)
+			continue
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// BackupToFile 全局方法：创建备份并写入目录
+func BackupToFile(dir string) (*models.BackupFile, error) {
+	service := GetGlobalService()
+	if service == nil {
+		return nil, fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return nil, fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.BackupToFile(dir)
+}
+
+// RestoreFromFile 全局方法：从备份文件恢复
+func RestoreFromFile(filePath string) (*models.BackupManifest, error) {
+	service := GetGlobalService()
+	if service == nil {
+		return nil, fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return nil, fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.RestoreFromFile(filePath)
+}
+
+// Restore 全局方法：从备份归档恢复
+func Restore(r io.Reader) (*models.BackupManifest, error) {
+	service := GetGlobalService()
+	if service == nil {
+		return nil, fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return nil, fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.Restore(r)
+}
+
+// Backup 全局方法：创建备份并写入 w
+func Backup(w io.Writer) (*models.BackupManifest, error) {
+	service := GetGlobalService()
+	if service == nil {
+		return nil, fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return nil, fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.Backup(w)
+}
@@ -0,0 +1,66 @@
+package pebble_service
+
+import (
+	"bytes"
+	"push-base-service/models"
+	"testing"
+)
+
+func TestBackupAndRestore(t *testing.T) {
+	service := newTestPebbleService(t)
+
+	if err := service.SaveUserTokens(&models.UserPushTokens{MetaID: "user-a", Tokens: map[string]string{"fcm": "token-a"}}); err != nil {
+		t.Fatalf("SaveUserTokens() failed, err: %v", err)
+	}
+	if _, err := service.SaveQAAccount("qa-user", "backup"); err != nil {
+		t.Fatalf("SaveQAAccount() failed, err: %v", err)
+	}
+
+	var archive bytes.Buffer
+	manifest, err := service.Backup(&archive)
+	if err != nil {
+		t.Fatalf("Backup() failed, err: %v", err)
+	}
+	if len(manifest.Collections) < 2 {
+		t.Errorf("Backup() collections = %v, want at least user_tokens and qa_accounts", manifest.Collections)
+	}
+
+	// 备份后的修改应在恢复后消失
+	if err := service.SaveUserTokens(&models.UserPushTokens{MetaID: "user-b", Tokens: map[string]string{"fcm": "token-b"}}); err != nil {
+		t.Fatalf("SaveUserTokens() failed, err: %v", err)
+	}
+	if err := service.DeleteUserTokens("user-a"); err != nil {
+		t.Fatalf("DeleteUserTokens() failed, err: %v", err)
+	}
+
+	var invalidated []string
+	service.OnUserTokensChanged(func(metaId string) {
+		invalidated = append(invalidated, metaId)
+	})
+
+	if _, err := service.Restore(&archive); err != nil {
+		t.Fatalf("Restore() failed, err: %v", err)
+	}
+	if len(invalidated) != 1 || invalidated[0] != "" {
+		t.Errorf("token listeners notified with %v, want a single full invalidation", invalidated)
+	}
+
+	tokens, err := service.GetUserTokens("user-a")
+	if err != nil || tokens == nil || tokens.Tokens["fcm"] != "token-a" {
+		t.Errorf("GetUserTokens(user-a) after restore = %+v, %v; want token-a", tokens, err)
+	}
+	if tokens, _ := service.GetUserTokens("user-b"); tokens != nil && len(tokens.Tokens) > 0 {
+		t.Errorf("GetUserTokens(user-b) after restore = %+v, want none", tokens)
+	}
+	if isQA, err := service.IsQAAccount("qa-user"); err != nil || !isQA {
+		t.Errorf("IsQAAccount() after restore = %v, %v; want true", isQA, err)
+	}
+}
+
+func TestRestoreRejectsInvalidArchive(t *testing.T) {
+	service := newTestPebbleService(t)
+
+	if _, err := service.Restore(bytes.NewReader([]byte("not a backup"))); err == nil {
+		t.Fatal("Restore() should reject invalid archive")
+	}
+}
@@ -0,0 +1,491 @@
+package pebble_service
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"push-base-service/models"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cockroachdb/pebble"
+)
+
+// 屏蔽聊天分页和批量操作限制
+const (
+	DefaultBlockedChatsPageSize = 100 // 屏蔽聊天列表默认每页数量
+	MaxBlockedChatsPageSize     = 500 // 屏蔽聊天列表每页最大数量
+	MaxBlockedChatsBatchSize    = 500 // 批量屏蔽/取消屏蔽单次最多处理的聊天数
+)
+
+// blockedChatsLayoutKey 屏蔽聊天集合已迁移为每个聊天一条记录的标记（以 ":" 开头，不会与 metaId:chatId 冲突）
+const blockedChatsLayoutKey = ":layout"
+
+// blockedChatsMu 保证屏蔽记录"读取-修改-写入"的原子性
+var blockedChatsMu sync.Mutex
+
+// blockedChatsRepo 屏蔽聊天集合存储，键为 metaId:chatId
+func (ps *PebbleService) blockedChatsRepo() *repository[models.BlockedChat] {
+	return newRepository[models.BlockedChat](ps, CollectionBlockedChats, "屏蔽聊天")
+}
+
+// getBlockedChatKey 生成屏蔽记录的键
+func getBlockedChatKey(userId, chatId string) string {
+	return userId + ":" + chatId
+}
+
+// ensureBlockedChatsLayout 将旧版布局（键为 metaId、值为整个屏蔽列表）迁移为每个聊天一条记录，
+// 每个进程只在首次访问屏蔽聊天时检查一次，迁移完成后写入标记
+func (ps *PebbleService) ensureBlockedChatsLayout() error {
+	if ps.blockedChatsMigrated.Load() {
+		return nil
+	}
+
+	blockedChatsMu.Lock()
+	defer blockedChatsMu.Unlock()
+
+	if ps.blockedChatsMigrated.Load() {
+		return nil
+	}
+
+	db, err := ps.getCollectionDB(CollectionBlockedChats)
+	if err != nil {
+		return fmt.Errorf("获取屏蔽聊天集合数据库失败: %w", err)
+	}
+	if _, closer, err := db.Get(buildKey(blockedChatsLayoutKey)); err == nil {
+		closer.Close()
+		ps.blockedChatsMigrated.Store(true)
+		return nil
+	} else if err != pebble.ErrNotFound {
+		return fmt.Errorf("读取屏蔽聊天布局标记失败: %w", err)
+	}
+
+	iter, err := db.NewIter(nil)
+	if err != nil {
+		return fmt.Errorf("创建迭代器失败: %w", err)
+	}
+	defer iter.Close()
+
+	// 旧版记录的键为 metaId，不含 ":"
+	batch := db.NewBatch()
+	defer batch.Close()
+	users, chats := 0, 0
+	now := time.Now().Unix()
+	for iter.First(); iter.Valid(); iter.Next() {
+		key := string(iter.Key())
+		if strings.Contains(key, ":") {
+			continue
+		}
+
+		value, err := decodeValue(iter.Value())
+		if err != nil {
+			return fmt.Errorf("解压旧版屏蔽列表失败: %s, %w", key, err)
+		}
+		var legacy models.UserBlockedChats
+		if err := json.Unmarshal(value, &legacy); err != nil {
+			log.Printf("⚠️ 跳过解析失败的旧版屏蔽列表: UserID=%s, 错误: %v", key, err)
+			continue
+		}
+		for _, blockedChat := range legacy.BlockedChats {
+			if blockedChat.ChatID == "" || blockedChat.IsExpired(now) {
+				continue
+			}
+			blockedChat.UserID = key
+			data, err := ps.encodeBlockedChat(&blockedChat)
+			if err != nil {
+				return fmt.Errorf("序列化屏蔽记录失败: %w", err)
+			}
+			batch.Set(buildKey(getBlockedChatKey(key, blockedChat.ChatID)), data, nil)
+			chats++
+		}
+		batch.Delete(iter.Key(), nil)
+		users++
+	}
+	if err := iter.Error(); err != nil {
+		return fmt.Errorf("迭代器错误: %w", err)
+	}
+	batch.Set(buildKey(blockedChatsLayoutKey), []byte("{}"), nil)
+	if err := batch.Commit(pebble.Sync); err != nil {
+		return fmt.Errorf("迁移旧版屏蔽列表失败: %w", ps.wrapDiskFull(err))
+	}
+
+	if users > 0 {
+		log.Printf("🔧 已迁移旧版屏蔽列表: 用户数=%d, 屏蔽聊天数=%d", users, chats)
+	}
+	ps.blockedChatsMigrated.Store(true)
+	return nil
+}
+
+// AddBlockedChat 添加屏蔽聊天
+// muteUntil 为静音截止时间（Unix 秒），0 表示永久屏蔽；已屏蔽时会更新截止时间
+func (ps *PebbleService) AddBlockedChat(userId, chatId, chatType, reason string, muteUntil int64) error {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	if userId == "" || chatId == "" {
+		return fmt.Errorf("UserID 和 ChatID 不能为空")
+	}
+	if muteUntil < 0 {
+		return fmt.Errorf("静音截止时间不能为负数")
+	}
+	if err := ps.ensureBlockedChatsLayout(); err != nil {
+		return err
+	}
+
+	blockedChatsMu.Lock()
+	defer blockedChatsMu.Unlock()
+
+	blockedChat, updated, err := ps.mergeBlockedChat(userId, models.BlockedChat{ChatID: chatId, ChatType: chatType, Reason: reason, MuteUntil: muteUntil}, time.Now().Unix())
+	if err != nil {
+		return err
+	}
+	if err := ps.blockedChatsRepo().Put(getBlockedChatKey(userId, chatId), blockedChat); err != nil {
+		return err
+	}
+
+	if updated {
+		log.Printf("✅ 已更新屏蔽聊天: UserID=%s, ChatID=%s, MuteUntil=%d", userId, chatId, muteUntil)
+	} else {
+		log.Printf("✅ 已添加屏蔽聊天: UserID=%s, ChatID=%s, ChatType=%s, MuteUntil=%d", userId, chatId, chatType, muteUntil)
+	}
+	return nil
+}
+
+// mergeBlockedChat 生成要写入的屏蔽记录：已屏蔽（且未过期）时保留屏蔽时间和类型，只更新截止时间和非空的原因
+// 调用方持有 blockedChatsMu
+func (ps *PebbleService) mergeBlockedChat(userId string, request models.BlockedChat, now int64) (*models.BlockedChat, bool, error) {
+	existing, err := ps.blockedChatsRepo().Get(getBlockedChatKey(userId, request.ChatID))
+	if err != nil {
+		return nil, false, err
+	}
+	if existing != nil && !existing.IsExpired(now) {
+		existing.MuteUntil = request.MuteUntil
+		if request.Reason != "" {
+			existing.Reason = request.Reason
+		}
+		return existing, true, nil
+	}
+
+	return &models.BlockedChat{
+		UserID:    userId,
+		ChatID:    request.ChatID,
+		ChatType:  request.ChatType,
+		BlockedAt: now,
+		Reason:    request.Reason,
+		MuteUntil: request.MuteUntil,
+	}, false, nil
+}
+
+// IsBlockedChat 检查聊天是否被屏蔽
+// 已过期的临时静音视为未屏蔽，并顺带删除
+func (ps *PebbleService) IsBlockedChat(userId, chatId string) (bool, error) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	if userId == "" || chatId == "" {
+		return false, fmt.Errorf("UserID 和 ChatID 不能为空")
+	}
+	if err := ps.ensureBlockedChatsLayout(); err != nil {
+		return false, err
+	}
+
+	blockedChat, err := ps.blockedChatsRepo().Get(getBlockedChatKey(userId, chatId))
+	if err != nil {
+		return false, fmt.Errorf("获取屏蔽记录失败: %w", err)
+	}
+	if blockedChat == nil {
+		return false, nil
+	}
+	if blockedChat.IsExpired(time.Now().Unix()) {
+		ps.cleanupExpiredBlockedChats(userId, []string{chatId})
+		return false, nil
+	}
+	return true, nil
+}
+
+// RemoveBlockedChat 移除屏蔽聊天
+func (ps *PebbleService) RemoveBlockedChat(userId, chatId string) error {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	if userId == "" || chatId == "" {
+		return fmt.Errorf("UserID 和 ChatID 不能为空")
+	}
+	if err := ps.ensureBlockedChatsLayout(); err != nil {
+		return err
+	}
+
+	repo := ps.blockedChatsRepo()
+	key := getBlockedChatKey(userId, chatId)
+	exists, err := repo.Has(key)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		log.Printf("⚠️ 用户 %s 没有屏蔽聊天 %s", userId, chatId)
+		return nil // 没有屏蔽，直接返回成功
+	}
+	if err := repo.Delete(key); err != nil {
+		return err
+	}
+
+	log.Printf("✅ 已移除屏蔽聊天: UserID=%s, ChatID=%s", userId, chatId)
+	return nil
+}
+
+// BatchUpdateBlockedChats 批量屏蔽和取消屏蔽聊天（如客户端重装后同步本地屏蔽列表），在一个批次中写入
+// 同一聊天同时出现在两个列表中时以取消屏蔽为准
+func (ps *PebbleService) BatchUpdateBlockedChats(userId string, block []models.BlockedChat, unblock []string) (*models.BlockedChatsBatchResult, error) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	if userId == "" {
+		return nil, fmt.Errorf("UserID 不能为空")
+	}
+	if err := ValidateBlockedChatsBatch(block, unblock); err != nil {
+		return nil, err
+	}
+	if err := ps.ensureBlockedChatsLayout(); err != nil {
+		return nil, err
+	}
+
+	db, err := ps.getCollectionDB(CollectionBlockedChats)
+	if err != nil {
+		return nil, fmt.Errorf("获取屏蔽聊天集合数据库失败: %w", err)
+	}
+
+	blockedChatsMu.Lock()
+	defer blockedChatsMu.Unlock()
+
+	unblocked := make(map[string]bool, len(unblock))
+	for _, chatId := range unblock {
+		unblocked[chatId] = true
+	}
+
+	batch := db.NewBatch()
+	defer batch.Close()
+	result := &models.BlockedChatsBatchResult{}
+	now := time.Now().Unix()
+	for _, request := range block {
+		if unblocked[request.ChatID] {
+			continue
+		}
+		blockedChat, _, err := ps.mergeBlockedChat(userId, request, now)
+		if err != nil {
+			return nil, err
+		}
+		data, err := ps.encodeBlockedChat(blockedChat)
+		if err != nil {
+			return nil, fmt.Errorf("序列化屏蔽记录失败: %w", err)
+		}
+		batch.Set(buildKey(getBlockedChatKey(userId, request.ChatID)), data, nil)
+		result.Blocked++
+	}
+	for chatId := range unblocked {
+		batch.Delete(buildKey(getBlockedChatKey(userId, chatId)), nil)
+		result.Unblocked++
+	}
+	if err := batch.Commit(pebble.Sync); err != nil {
+		return nil, fmt.Errorf("批量更新屏蔽聊天失败: %w", ps.wrapDiskFull(err))
+	}
+
+	log.Printf("✅ 已批量更新屏蔽聊天: UserID=%s, 屏蔽=%d, 取消屏蔽=%d", userId, result.Blocked, result.Unblocked)
+	return result, nil
+}
+
+// ValidateBlockedChatsBatch 检查批量屏蔽请求：数量限制、聊天ID非空、截止时间非负
+func ValidateBlockedChatsBatch(block []models.BlockedChat, unblock []string) error {
+	if len(block)+len(unblock) == 0 {
+		return fmt.Errorf("block 和 unblock 不能同时为空")
+	}
+	if len(block)+len(unblock) > MaxBlockedChatsBatchSize {
+		return fmt.Errorf("单次最多处理 %d 个聊天", MaxBlockedChatsBatchSize)
+	}
+	for _, blockedChat := range block {
+		if blockedChat.ChatID == "" {
+			return fmt.Errorf("ChatID 不能为空")
+		}
+		if blockedChat.MuteUntil < 0 {
+			return fmt.Errorf("静音截止时间不能为负数: %s", blockedChat.ChatID)
+		}
+	}
+	for _, chatId := range unblock {
+		if chatId == "" {
+			return fmt.Errorf("ChatID 不能为空")
+		}
+	}
+	return nil
+}
+
+// encodeBlockedChat 按集合配置序列化（及压缩）屏蔽记录，用于批量写入
+func (ps *PebbleService) encodeBlockedChat(blockedChat *models.BlockedChat) ([]byte, error) {
+	data, err := json.Marshal(blockedChat)
+	if err != nil {
+		return nil, err
+	}
+	return ps.encodeValue(CollectionBlockedChats, data), nil
+}
+
+// GetUserBlockedChats 获取用户的所有屏蔽聊天（不含已过期的临时静音），按屏蔽时间排序
+func (ps *PebbleService) GetUserBlockedChats(userId string) (*models.UserBlockedChats, error) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	if userId == "" {
+		return nil, fmt.Errorf("UserID 不能为空")
+	}
+	if err := ps.ensureBlockedChatsLayout(); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().Unix()
+	userBlockedChats := &models.UserBlockedChats{
+		UserID:       userId,
+		BlockedChats: []models.BlockedChat{},
+	}
+	var expired []string
+	err := ps.blockedChatsRepo().ScanPrefix(getBlockedChatKey(userId, ""), func(_ string, blockedChat *models.BlockedChat) bool {
+		if blockedChat.IsExpired(now) {
+			expired = append(expired, blockedChat.ChatID)
+			return true
+		}
+		userBlockedChats.BlockedChats = append(userBlockedChats.BlockedChats, *blockedChat)
+		if blockedChat.BlockedAt > userBlockedChats.UpdatedAt {
+			userBlockedChats.UpdatedAt = blockedChat.BlockedAt
+		}
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("获取用户屏蔽列表失败: %w", err)
+	}
+	if len(expired) > 0 {
+		ps.cleanupExpiredBlockedChats(userId, expired)
+	}
+
+	sort.SliceStable(userBlockedChats.BlockedChats, func(i, j int) bool {
+		return userBlockedChats.BlockedChats[i].BlockedAt < userBlockedChats.BlockedChats[j].BlockedAt
+	})
+
+	log.Printf("📖 已获取用户屏蔽聊天列表: UserID=%s, 数量=%d", userId, len(userBlockedChats.BlockedChats))
+	return userBlockedChats, nil
+}
+
+// ListBlockedChats 按聊天ID顺序分页获取用户的屏蔽聊天（不含已过期的临时静音），cursor 为上一页返回的游标
+func (ps *PebbleService) ListBlockedChats(userId, cursor string, pageSize int) (*models.BlockedChatsPage, error) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	if userId == "" {
+		return nil, fmt.Errorf("UserID 不能为空")
+	}
+	after, err := DecodeTokensCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+	pageSize = NormalizeBlockedChatsPageSize(pageSize)
+	if err := ps.ensureBlockedChatsLayout(); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().Unix()
+	page := &models.BlockedChatsPage{
+		UserID:       userId,
+		BlockedChats: []models.BlockedChat{},
+		PageSize:     pageSize,
+	}
+	var expired []string
+	err = ps.blockedChatsRepo().ScanPrefix(getBlockedChatKey(userId, ""), func(_ string, blockedChat *models.BlockedChat) bool {
+		if after != "" && blockedChat.ChatID <= after {
+			return true
+		}
+		if blockedChat.IsExpired(now) {
+			expired = append(expired, blockedChat.ChatID)
+			return true
+		}
+		// 已满一页时再找到一条有效记录，说明有下一页
+		if len(page.BlockedChats) == pageSize {
+			page.HasNext = true
+			page.NextCursor = EncodeTokensCursor(page.BlockedChats[pageSize-1].ChatID)
+			return false
+		}
+		page.BlockedChats = append(page.BlockedChats, *blockedChat)
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("获取用户屏蔽列表失败: %w", err)
+	}
+	if len(expired) > 0 {
+		ps.cleanupExpiredBlockedChats(userId, expired)
+	}
+	return page, nil
+}
+
+// NormalizeBlockedChatsPageSize 限制屏蔽聊天列表每页数量
+func NormalizeBlockedChatsPageSize(pageSize int) int {
+	if pageSize <= 0 {
+		return DefaultBlockedChatsPageSize
+	}
+	if pageSize > MaxBlockedChatsPageSize {
+		return MaxBlockedChatsPageSize
+	}
+	return pageSize
+}
+
+// cleanupExpiredBlockedChats 删除已过期的临时静音，删除前重新检查，避免覆盖并发写入的新记录；失败只记录日志
+func (ps *PebbleService) cleanupExpiredBlockedChats(userId string, chatIds []string) {
+	blockedChatsMu.Lock()
+	defer blockedChatsMu.Unlock()
+
+	repo := ps.blockedChatsRepo()
+	now := time.Now().Unix()
+	removed := 0
+	for _, chatId := range chatIds {
+		key := getBlockedChatKey(userId, chatId)
+		blockedChat, err := repo.Get(key)
+		if err != nil || blockedChat == nil || !blockedChat.IsExpired(now) {
+			continue
+		}
+		if err := repo.Delete(key); err != nil {
+			log.Printf("⚠️ 清理过期静音失败: UserID=%s, ChatID=%s, 错误=%v", userId, chatId, err)
+			continue
+		}
+		removed++
+	}
+	if removed > 0 {
+		log.Printf("🧹 已清理过期静音: UserID=%s, 数量=%d", userId, removed)
+	}
+}
+
+// scanBlockedChats 按用户遍历所有有效的屏蔽聊天（用于导出），跳过已过期的临时静音
+func (ps *PebbleService) scanBlockedChats(fn func(userBlockedChats *models.UserBlockedChats)) error {
+	if err := ps.ensureBlockedChatsLayout(); err != nil {
+		return err
+	}
+
+	now := time.Now().Unix()
+	var current *models.UserBlockedChats
+	err := ps.blockedChatsRepo().ScanPrefix("", func(key string, blockedChat *models.BlockedChat) bool {
+		userId, _, found := strings.Cut(key, ":")
+		if !found || userId == "" || blockedChat.IsExpired(now) {
+			return true
+		}
+		// 键按 metaId 排序，同一用户的记录相邻
+		if current == nil || current.UserID != userId {
+			if current != nil {
+				fn(current)
+			}
+			current = &models.UserBlockedChats{UserID: userId}
+		}
+		current.BlockedChats = append(current.BlockedChats, *blockedChat)
+		if blockedChat.BlockedAt > current.UpdatedAt {
+			current.UpdatedAt = blockedChat.BlockedAt
+		}
+		return true
+	})
+	if current != nil {
+		fn(current)
+	}
+	return err
+}
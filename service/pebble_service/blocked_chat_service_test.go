@@ -0,0 +1,141 @@
+package pebble_service
+
+import (
+	"encoding/json"
+	"fmt"
+	"push-base-service/models"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/pebble"
+)
+
+func TestTemporaryMuteExpires(t *testing.T) {
+	service := newTestPebbleService(t)
+
+	now := time.Now().Unix()
+	if err := service.AddBlockedChat("user-a", "group-1", "group", "", 0); err != nil {
+		t.Fatalf("AddBlockedChat() failed, err: %v", err)
+	}
+	if err := service.AddBlockedChat("user-a", "group-2", "group", "", now+3600); err != nil {
+		t.Fatalf("AddBlockedChat() failed, err: %v", err)
+	}
+
+	for _, chatID := range []string{"group-1", "group-2"} {
+		blocked, err := service.IsBlockedChat("user-a", chatID)
+		if err != nil || !blocked {
+			t.Fatalf("IsBlockedChat(%s) = %v, %v; want blocked", chatID, blocked, err)
+		}
+	}
+
+	// 将静音截止时间改为已过期
+	if err := service.AddBlockedChat("user-a", "group-2", "group", "", now-1); err != nil {
+		t.Fatalf("AddBlockedChat() failed, err: %v", err)
+	}
+
+	blocked, err := service.IsBlockedChat("user-a", "group-2")
+	if err != nil || blocked {
+		t.Fatalf("IsBlockedChat(group-2) = %v, %v; want expired mute treated as unblocked", blocked, err)
+	}
+
+	// 过期静音已被清理，永久屏蔽保留
+	var stored []string
+	service.blockedChatsRepo().ScanPrefix(getBlockedChatKey("user-a", ""), func(_ string, blockedChat *models.BlockedChat) bool {
+		stored = append(stored, blockedChat.ChatID)
+		return true
+	})
+	if len(stored) != 1 || stored[0] != "group-1" {
+		t.Errorf("stored blocked chats = %v, want only group-1", stored)
+	}
+
+	blocked, err = service.IsBlockedChat("user-a", "group-1")
+	if err != nil || !blocked {
+		t.Errorf("IsBlockedChat(group-1) = %v, %v; want permanent block kept", blocked, err)
+	}
+}
+
+func TestGetUserBlockedChatsSkipsExpiredMutes(t *testing.T) {
+	service := newTestPebbleService(t)
+
+	if err := service.AddBlockedChat("user-a", "group-1", "group", "", time.Now().Unix()-1); err != nil {
+		t.Fatalf("AddBlockedChat() failed, err: %v", err)
+	}
+
+	blockedChats, err := service.GetUserBlockedChats("user-a")
+	if err != nil {
+		t.Fatalf("GetUserBlockedChats() failed, err: %v", err)
+	}
+	if len(blockedChats.BlockedChats) != 0 {
+		t.Errorf("GetUserBlockedChats() = %+v, want no active chats", blockedChats.BlockedChats)
+	}
+}
+
+func TestLegacyBlockedChatsMigrated(t *testing.T) {
+	service := newTestPebbleService(t)
+
+	// 写入旧版布局：键为 metaId，值为整个屏蔽列表
+	db, err := service.getCollectionDB(CollectionBlockedChats)
+	if err != nil {
+		t.Fatalf("getCollectionDB() failed, err: %v", err)
+	}
+	legacy, _ := json.Marshal(models.UserBlockedChats{UserID: "user-a", BlockedChats: []models.BlockedChat{
+		{ChatID: "group-1", ChatType: "group", BlockedAt: 100},
+		{ChatID: "group-2", ChatType: "group", BlockedAt: 200, MuteUntil: 1},
+	}})
+	if err := db.Set([]byte("user-a"), legacy, pebble.Sync); err != nil {
+		t.Fatalf("db.Set() failed, err: %v", err)
+	}
+
+	blocked, err := service.IsBlockedChat("user-a", "group-1")
+	if err != nil || !blocked {
+		t.Fatalf("IsBlockedChat(group-1) = %v, %v; want migrated block", blocked, err)
+	}
+	if _, closer, err := db.Get([]byte("user-a")); err == nil {
+		closer.Close()
+		t.Errorf("旧版屏蔽列表应在迁移后删除")
+	}
+
+	chats, err := service.GetUserBlockedChats("user-a")
+	if err != nil || len(chats.BlockedChats) != 1 || chats.BlockedChats[0].UserID != "user-a" || chats.BlockedChats[0].BlockedAt != 100 {
+		t.Errorf("GetUserBlockedChats() = %+v, %v; want only migrated group-1", chats, err)
+	}
+}
+
+func TestListAndBatchUpdateBlockedChats(t *testing.T) {
+	service := newTestPebbleService(t)
+
+	var block []models.BlockedChat
+	for i := 0; i < 5; i++ {
+		block = append(block, models.BlockedChat{ChatID: fmt.Sprintf("group-%d", i), ChatType: "group"})
+	}
+	result, err := service.BatchUpdateBlockedChats("user-a", block, []string{"group-4", "group-9"})
+	if err != nil || result.Blocked != 4 || result.Unblocked != 2 {
+		t.Fatalf("BatchUpdateBlockedChats() = %+v, %v; want 4 blocked, 2 unblocked", result, err)
+	}
+	service.AddBlockedChat("user-b", "group-0", "group", "", 0)
+	service.AddBlockedChat("user-a", "group-1", "group", "", time.Now().Unix()-1)
+
+	// 按聊天ID分页，过期静音和其他用户的记录不返回
+	var got []string
+	cursor := ""
+	for pages := 0; pages < 5; pages++ {
+		page, err := service.ListBlockedChats("user-a", cursor, 2)
+		if err != nil {
+			t.Fatalf("ListBlockedChats() failed, err: %v", err)
+		}
+		for _, blockedChat := range page.BlockedChats {
+			got = append(got, blockedChat.ChatID)
+		}
+		if !page.HasNext {
+			break
+		}
+		cursor = page.NextCursor
+	}
+	if fmt.Sprint(got) != "[group-0 group-2 group-3]" {
+		t.Errorf("paged chats = %v, want [group-0 group-2 group-3]", got)
+	}
+
+	if _, err := service.BatchUpdateBlockedChats("user-a", nil, nil); err == nil {
+		t.Errorf("空批量请求应返回错误")
+	}
+}
@@ -0,0 +1,53 @@
+package pebble_service
+
+import (
+	"fmt"
+	"push-base-service/models"
+	"time"
+)
+
+// catchupRepo 上游消息补拉检查点集合存储
+func (ps *PebbleService) catchupRepo() *repository[models.CatchupCheckpoint] {
+	return newRepository[models.CatchupCheckpoint](ps, CollectionCatchup, "补拉检查点")
+}
+
+// SaveCatchupCheckpoint 保存上游的补拉检查点
+func (ps *PebbleService) SaveCatchupCheckpoint(checkpoint *models.CatchupCheckpoint) error {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	checkpoint.UpdatedAt = time.Now().Unix()
+	return ps.catchupRepo().Put(checkpoint.Upstream, checkpoint)
+}
+
+// GetCatchupCheckpoint 获取上游的补拉检查点，未保存过时返回 nil
+func (ps *PebbleService) GetCatchupCheckpoint(upstream string) (*models.CatchupCheckpoint, error) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	return ps.catchupRepo().Get(upstream)
+}
+
+// SaveCatchupCheckpoint 全局方法：保存上游的补拉检查点
+func SaveCatchupCheckpoint(checkpoint *models.CatchupCheckpoint) error {
+	service := GetGlobalService()
+	if service == nil {
+		return fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.SaveCatchupCheckpoint(checkpoint)
+}
+
+// GetCatchupCheckpoint 全局方法：获取上游的补拉检查点
+func GetCatchupCheckpoint(upstream string) (*models.CatchupCheckpoint, error) {
+	service := GetGlobalService()
+	if service == nil {
+		return nil, fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return nil, fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.GetCatchupCheckpoint(upstream)
+}
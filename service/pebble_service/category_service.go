@@ -0,0 +1,50 @@
+package pebble_service
+
+import (
+	"fmt"
+	"log"
+	"push-base-service/models"
+	"time"
+)
+
+// SetNotificationCategories 设置用户客户端已注册的通知类别，保留其他偏好设置
+func (ps *PebbleService) SetNotificationCategories(metaId string, categories []string) (*models.UserPreferences, error) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	if metaId == "" {
+		return nil, fmt.Errorf("MetaID 不能为空")
+	}
+
+	pauseMu.Lock()
+	defer pauseMu.Unlock()
+
+	repo := ps.preferencesRepo()
+	preferences, err := repo.Get(metaId)
+	if err != nil {
+		return nil, err
+	}
+	if preferences == nil {
+		preferences = &models.UserPreferences{MetaID: metaId}
+	}
+	preferences.Categories = categories
+	preferences.UpdatedAt = time.Now().Unix()
+	if err := repo.Put(metaId, preferences); err != nil {
+		return nil, err
+	}
+
+	log.Printf("🔘 已设置用户通知类别: MetaID=%s, Categories=%v", metaId, categories)
+	return preferences, nil
+}
+
+// SetNotificationCategories 全局方法：设置用户客户端已注册的通知类别
+func SetNotificationCategories(metaId string, categories []string) (*models.UserPreferences, error) {
+	service := GetGlobalService()
+	if service == nil {
+		return nil, fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return nil, fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.SetNotificationCategories(metaId, categories)
+}
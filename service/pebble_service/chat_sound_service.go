@@ -0,0 +1,103 @@
+package pebble_service
+
+import (
+	"fmt"
+	"log"
+	"push-base-service/models"
+	"sort"
+	"time"
+)
+
+// chatSoundsRepo 聊天通知声音集合存储，键为 metaId:chatId
+func (ps *PebbleService) chatSoundsRepo() *repository[models.ChatSound] {
+	return newRepository[models.ChatSound](ps, CollectionChatSounds, "聊天通知声音")
+}
+
+// getChatSoundKey 生成聊天通知声音的键
+func getChatSoundKey(userId, chatId string) string {
+	return userId + ":" + chatId
+}
+
+// SetChatSound 设置用户某个聊天的通知声音，sound 为空时恢复默认声音
+func (ps *PebbleService) SetChatSound(userId, chatId, chatType, sound string) error {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	if userId == "" || chatId == "" {
+		return fmt.Errorf("UserID 和 ChatID 不能为空")
+	}
+
+	repo := ps.chatSoundsRepo()
+	key := getChatSoundKey(userId, chatId)
+	if sound == "" {
+		if err := repo.Delete(key); err != nil {
+			return err
+		}
+		log.Printf("✅ 已恢复聊天默认通知声音: UserID=%s, ChatID=%s", userId, chatId)
+		return nil
+	}
+
+	chatSound := &models.ChatSound{
+		UserID:    userId,
+		ChatID:    chatId,
+		ChatType:  chatType,
+		Sound:     sound,
+		UpdatedAt: time.Now().Unix(),
+	}
+	if err := repo.Put(key, chatSound); err != nil {
+		return err
+	}
+
+	log.Printf("✅ 已设置聊天通知声音: UserID=%s, ChatID=%s, Sound=%s", userId, chatId, sound)
+	return nil
+}
+
+// GetChatSounds 批量获取用户在某个聊天的通知声音，只返回设置了声音的用户
+func (ps *PebbleService) GetChatSounds(userIds []string, chatId string) (map[string]string, error) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	repo := ps.chatSoundsRepo()
+	result := make(map[string]string)
+	for _, userId := range userIds {
+		if userId == "" {
+			continue
+		}
+		chatSound, err := repo.Get(getChatSoundKey(userId, chatId))
+		if err != nil {
+			log.Printf("⚠️ 获取用户 %s 的聊天通知声音失败: %v", userId, err)
+			continue
+		}
+		if chatSound != nil && chatSound.Sound != "" {
+			result[userId] = chatSound.Sound
+		}
+	}
+	return result, nil
+}
+
+// GetUserChatSounds 获取用户设置的所有聊天通知声音，按设置时间排序
+func (ps *PebbleService) GetUserChatSounds(userId string) (*models.UserChatSounds, error) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	if userId == "" {
+		return nil, fmt.Errorf("UserID 不能为空")
+	}
+
+	userChatSounds := &models.UserChatSounds{
+		UserID:     userId,
+		ChatSounds: []models.ChatSound{},
+	}
+	err := ps.chatSoundsRepo().ScanPrefix(getChatSoundKey(userId, ""), func(_ string, chatSound *models.ChatSound) bool {
+		userChatSounds.ChatSounds = append(userChatSounds.ChatSounds, *chatSound)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(userChatSounds.ChatSounds, func(i, j int) bool {
+		return userChatSounds.ChatSounds[i].UpdatedAt < userChatSounds.ChatSounds[j].UpdatedAt
+	})
+	return userChatSounds, nil
+}
@@ -0,0 +1,134 @@
+package pebble_service
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"push-base-service/service/metrics_service"
+	"sort"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// DefaultCompressionThreshold 默认压缩阈值，小于该大小的值不压缩
+const DefaultCompressionThreshold = 512
+
+// CompressibleCollections 支持透明压缩的集合（存储完整推送内容或上游原始消息的集合）
+var CompressibleCollections = []string{CollectionQAInbox, CollectionScheduled, CollectionPushAudit, CollectionQuarantine}
+
+// zstdMagic zstd 帧头，未压缩的 JSON 值以 '{' 开头，不会与之冲突
+var zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+
+var compressionBytesCounter = metrics_service.NewCounterVec(
+	"push_pebble_compression_bytes_total", "Bytes written to compressed Pebble collections before (raw) and after (stored) compression",
+	"collection", "kind")
+
+// CompressionConfig 值压缩配置
+type CompressionConfig struct {
+	Enabled     bool     `yaml:"enabled" json:"enabled"`         // 是否启用压缩
+	Threshold   int      `yaml:"threshold" json:"threshold"`     // 压缩阈值（字节），小于该大小的值不压缩
+	Collections []string `yaml:"collections" json:"collections"` // 启用压缩的集合，为空时对所有支持的集合启用
+}
+
+// valueCodec 集合值编解码器
+// 读取时根据 zstd 帧头识别压缩值，因此开启或关闭压缩后旧数据仍可读取
+type valueCodec struct {
+	threshold   int
+	collections map[string]bool
+}
+
+var (
+	zstdEncoder  *zstd.Encoder
+	zstdDecoder  *zstd.Decoder
+	zstdInitOnce sync.Once
+	zstdInitErr  error
+)
+
+// initZstd 初始化共享的 zstd 编解码器（EncodeAll/DecodeAll 并发安全）
+func initZstd() error {
+	zstdInitOnce.Do(func() {
+		zstdEncoder, zstdInitErr = zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedDefault))
+		if zstdInitErr != nil {
+			return
+		}
+		zstdDecoder, zstdInitErr = zstd.NewReader(nil)
+	})
+	return zstdInitErr
+}
+
+// newValueCodec 根据配置创建编解码器，未启用时返回 nil
+func newValueCodec(config *CompressionConfig) *valueCodec {
+	if config == nil || !config.Enabled {
+		return nil
+	}
+	if err := initZstd(); err != nil {
+		log.Printf("❌ 初始化 zstd 压缩失败，不启用压缩: %v", err)
+		return nil
+	}
+
+	supported := make(map[string]bool, len(CompressibleCollections))
+	for _, collection := range CompressibleCollections {
+		supported[collection] = true
+	}
+
+	codec := &valueCodec{
+		threshold:   config.Threshold,
+		collections: make(map[string]bool),
+	}
+	if codec.threshold <= 0 {
+		codec.threshold = DefaultCompressionThreshold
+	}
+
+	if len(config.Collections) == 0 {
+		codec.collections = supported
+	}
+	for _, collection := range config.Collections {
+		if !supported[collection] {
+			log.Printf("⚠️ 集合 %s 不支持压缩，已忽略", collection)
+			continue
+		}
+		codec.collections[collection] = true
+	}
+
+	enabled := make([]string, 0, len(codec.collections))
+	for collection := range codec.collections {
+		enabled = append(enabled, collection)
+	}
+	sort.Strings(enabled)
+	log.Printf("🗜️ Pebble 值压缩已启用: 阈值=%d 字节, 集合=%v", codec.threshold, enabled)
+	return codec
+}
+
+// encodeValue 按集合配置压缩值
+func (ps *PebbleService) encodeValue(collection string, data []byte) []byte {
+	codec := ps.codec
+	if codec == nil || !codec.collections[collection] || len(data) < codec.threshold {
+		return data
+	}
+
+	compressed := zstdEncoder.EncodeAll(data, make([]byte, 0, len(data)/2))
+	compressionBytesCounter.Add(float64(len(data)), collection, "raw")
+	// 压缩无收益时保留原值
+	if len(compressed) >= len(data) {
+		compressionBytesCounter.Add(float64(len(data)), collection, "stored")
+		return data
+	}
+	compressionBytesCounter.Add(float64(len(compressed)), collection, "stored")
+	return compressed
+}
+
+// decodeValue 解压值，未压缩的值原样返回
+func decodeValue(data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, zstdMagic) {
+		return data, nil
+	}
+	if err := initZstd(); err != nil {
+		return nil, fmt.Errorf("初始化 zstd 解压失败: %w", err)
+	}
+	decoded, err := zstdDecoder.DecodeAll(data, nil)
+	if err != nil {
+		return nil, fmt.Errorf("解压数据失败: %w", err)
+	}
+	return decoded, nil
+}
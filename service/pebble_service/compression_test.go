@@ -0,0 +1,80 @@
+package pebble_service
+
+import (
+	"bytes"
+	"push-base-service/models"
+	"strings"
+	"testing"
+)
+
+func TestValueCompression(t *testing.T) {
+	service := NewPebbleService(&Config{
+		DBPath:      t.TempDir(),
+		Compression: &CompressionConfig{Enabled: true, Threshold: 64, Collections: []string{CollectionQAInbox, CollectionUserTokens}},
+	})
+	t.Cleanup(func() { service.Close() })
+
+	if !service.codec.collections[CollectionQAInbox] || service.codec.collections[CollectionUserTokens] {
+		t.Fatalf("codec collections = %v, want only qa_inbox (user_tokens is not compressible)", service.codec.collections)
+	}
+
+	payload := []byte(`{"body":"` + strings.Repeat("hello ", 100) + `"}`)
+	encoded := service.encodeValue(CollectionQAInbox, payload)
+	if !bytes.HasPrefix(encoded, zstdMagic) || len(encoded) >= len(payload) {
+		t.Fatalf("encodeValue() len = %d, want compressed value shorter than %d", len(encoded), len(payload))
+	}
+	decoded, err := decodeValue(encoded)
+	if err != nil || !bytes.Equal(decoded, payload) {
+		t.Fatalf("decodeValue() = %q, %v; want original payload", decoded, err)
+	}
+
+	// 小于阈值或未启用的集合原样存储，未压缩的值原样读取
+	small := []byte(`{"a":1}`)
+	if encoded := service.encodeValue(CollectionQAInbox, small); !bytes.Equal(encoded, small) {
+		t.Errorf("encodeValue(small) = %q, want unchanged", encoded)
+	}
+	if encoded := service.encodeValue(CollectionScheduled, payload); !bytes.Equal(encoded, payload) {
+		t.Error("encodeValue() should not compress collections that are not enabled")
+	}
+	if decoded, err := decodeValue(small); err != nil || !bytes.Equal(decoded, small) {
+		t.Errorf("decodeValue(uncompressed) = %q, %v", decoded, err)
+	}
+
+	// 端到端：压缩写入的收件箱消息可以正常读取
+	body := strings.Repeat("payload ", 50)
+	if err := service.AddQAInboxMessage(&models.QAInboxMessage{MetaID: "qa-user", Title: "t", Body: body}, 10); err != nil {
+		t.Fatalf("AddQAInboxMessage() failed, err: %v", err)
+	}
+	messages, err := service.GetQAInboxMessages("qa-user", 0, 0)
+	if err != nil || len(messages) != 1 || messages[0].Body != body {
+		t.Fatalf("GetQAInboxMessages() = %+v, %v", messages, err)
+	}
+}
+
+func TestCompressionDefaultCollections(t *testing.T) {
+	service := NewPebbleService(&Config{
+		DBPath:      t.TempDir(),
+		Compression: &CompressionConfig{Enabled: true, Threshold: 64},
+	})
+	if err := service.Initialize(); err != nil {
+		t.Fatalf("Initialize() failed, err: %v", err)
+	}
+	t.Cleanup(func() { service.Close() })
+
+	// 未指定集合时对所有支持的集合启用，包括审计日志和隔离消息
+	for _, collection := range []string{CollectionQAInbox, CollectionScheduled, CollectionPushAudit, CollectionQuarantine} {
+		if !service.codec.collections[collection] {
+			t.Errorf("compression should be enabled for %s by default", collection)
+		}
+	}
+
+	// 端到端：压缩写入的审计记录可以正常读取
+	title := strings.Repeat("audited ", 50)
+	if err := service.AddPushAudits([]*models.PushAuditEntry{{MetaID: "alice", Title: title}}, 0); err != nil {
+		t.Fatalf("AddPushAudits() failed, err: %v", err)
+	}
+	entries, err := service.GetPushAudits("alice", 0, 0)
+	if err != nil || len(entries) != 1 || entries[0].Title != title {
+		t.Fatalf("GetPushAudits() = %+v, %v", entries, err)
+	}
+}
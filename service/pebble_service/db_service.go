@@ -123,8 +123,8 @@ func GetUserBlockedChats(metaID string) (*models.UserBlockedChats, error) {
 	return service.GetUserBlockedChats(metaID)
 }
 
-// AddBlockedChat 新增屏蔽某个群或某个私聊
-func AddBlockedChat(metaID, chatID, chatType, reason string) error {
+// AddBlockedChat 新增屏蔽某个群或某个私聊，muteUntil 为 0 表示永久屏蔽
+func AddBlockedChat(metaID, chatID, chatType, reason string, muteUntil int64) error {
 	if metaID == "" {
 		return fmt.Errorf("MetaID不能为空")
 	}
@@ -144,7 +144,7 @@ func AddBlockedChat(metaID, chatID, chatType, reason string) error {
 		return fmt.Errorf("Pebble 服务未正确初始化")
 	}
 
-	return service.AddBlockedChat(metaID, chatID, chatType, reason)
+	return service.AddBlockedChat(metaID, chatID, chatType, reason, muteUntil)
 }
 
 // RemoveBlockedChat 取消屏蔽某个群或某个私聊
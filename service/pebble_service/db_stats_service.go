@@ -0,0 +1,199 @@
+package pebble_service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/cockroachdb/pebble"
+)
+
+// CollectionDBStats Pebble 实例的内部统计（独立布局下每个集合一个实例，共享布局下只有共享实例 _keyspace）
+type CollectionDBStats struct {
+	Name                  string `json:"name"`                  // 集合名称或共享实例目录名
+	DiskSize              uint64 `json:"diskSize"`              // 磁盘占用（字节，含 SST、WAL 和清单文件）
+	Tables                int64  `json:"tables"`                // SST 文件数
+	TablesSize            int64  `json:"tablesSize"`            // SST 文件总大小（字节）
+	ObsoleteTablesSize    uint64 `json:"obsoleteTablesSize"`    // 待删除的 SST 文件大小（字节）
+	ReadAmp               int    `json:"readAmp"`               // 读放大（查询最多需要读取的层数）
+	MemTableSize          uint64 `json:"memTableSize"`          // 内存表占用（字节）
+	MemTableCount         int64  `json:"memTableCount"`         // 内存表数量
+	WALFiles              int64  `json:"walFiles"`              // WAL 文件数
+	WALSize               uint64 `json:"walSize"`               // WAL 中的数据大小（字节）
+	WALPhysicalSize       uint64 `json:"walPhysicalSize"`       // WAL 文件在磁盘上的大小（字节）
+	Flushes               int64  `json:"flushes"`               // 内存表刷盘次数
+	Compactions           int64  `json:"compactions"`           // 压缩次数
+	CompactionsInProgress int64  `json:"compactionsInProgress"` // 正在进行的压缩数
+	CompactionDebt        uint64 `json:"compactionDebt"`        // 估计还需压缩的字节数
+	CompactionDurationMs  int64  `json:"compactionDurationMs"`  // 压缩累计耗时（毫秒）
+	BlockCacheSize        int64  `json:"blockCacheSize"`        // 块缓存占用（字节）
+	BlockCacheHits        int64  `json:"blockCacheHits"`        // 块缓存命中次数
+	BlockCacheMisses      int64  `json:"blockCacheMisses"`      // 块缓存未命中次数
+}
+
+// CompactionResult 单个集合的手动压缩结果
+type CompactionResult struct {
+	Name       string `json:"name"`            // 集合名称
+	SizeBefore uint64 `json:"sizeBefore"`      // 压缩前集合所在实例的磁盘占用（字节）
+	SizeAfter  uint64 `json:"sizeAfter"`       // 压缩后集合所在实例的磁盘占用（字节）
+	DurationMs int64  `json:"durationMs"`      // 耗时（毫秒）
+	Skipped    bool   `json:"skipped"`         // 集合为空，无需压缩
+	Error      string `json:"error,omitempty"` // 压缩失败原因
+}
+
+// collectionDBStats 读取 Pebble 实例的统计
+func collectionDBStats(name string, db *pebble.DB) *CollectionDBStats {
+	metrics := db.Metrics()
+	total := metrics.Total()
+	return &CollectionDBStats{
+		Name:                  name,
+		DiskSize:              metrics.DiskSpaceUsage(),
+		Tables:                total.NumFiles,
+		TablesSize:            total.Size,
+		ObsoleteTablesSize:    metrics.Table.ObsoleteSize,
+		ReadAmp:               metrics.ReadAmp(),
+		MemTableSize:          metrics.MemTable.Size,
+		MemTableCount:         metrics.MemTable.Count,
+		WALFiles:              metrics.WAL.Files,
+		WALSize:               metrics.WAL.Size,
+		WALPhysicalSize:       metrics.WAL.PhysicalSize,
+		Flushes:               metrics.Flush.Count,
+		Compactions:           metrics.Compact.Count,
+		CompactionsInProgress: metrics.Compact.NumInProgress,
+		CompactionDebt:        metrics.Compact.EstimatedDebt,
+		CompactionDurationMs:  metrics.Compact.Duration.Milliseconds(),
+		BlockCacheSize:        metrics.BlockCache.Size,
+		BlockCacheHits:        metrics.BlockCache.Hits,
+		BlockCacheMisses:      metrics.BlockCache.Misses,
+	}
+}
+
+// GetDBStats 获取已打开的 Pebble 实例的内部统计，按名称排序
+func (ps *PebbleService) GetDBStats() ([]*CollectionDBStats, error) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	if ps.collectionMgr == nil {
+		return nil, fmt.Errorf("集合管理器未初始化")
+	}
+
+	ps.collectionMgr.mu.RLock()
+	instances := ps.collectionMgr.instances()
+	ps.collectionMgr.mu.RUnlock()
+
+	names := make([]string, 0, len(instances))
+	for name := range instances {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	stats := make([]*CollectionDBStats, 0, len(names))
+	for _, name := range names {
+		stats = append(stats, collectionDBStats(name, instances[name]))
+	}
+	return stats, nil
+}
+
+// CompactCollections 对指定集合（为空时为所有已打开的集合）执行全量手动压缩，
+// 每压缩完一个集合调用一次 progress（可为 nil）；ctx 结束后不再压缩剩余集合
+func (ps *PebbleService) CompactCollections(ctx context.Context, names []string, progress func(done, total int, name string)) ([]*CompactionResult, error) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	if ps.collectionMgr == nil {
+		return nil, fmt.Errorf("集合管理器未初始化")
+	}
+
+	opened := ps.collectionMgr.ListCollections()
+	if len(names) == 0 {
+		names = opened
+		sort.Strings(names)
+	}
+	for _, name := range names {
+		if !slices.Contains(opened, name) {
+			return nil, fmt.Errorf("集合不存在或未打开: %s", name)
+		}
+	}
+
+	results := make([]*CompactionResult, 0, len(names))
+	for i, name := range names {
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+		db, err := ps.getCollectionDB(name)
+		if err != nil {
+			return results, err
+		}
+		results = append(results, compactDB(name, db))
+		if progress != nil {
+			progress(i+1, len(names), name)
+		}
+	}
+	return results, nil
+}
+
+// compactDB 压缩集合中的全部键
+func compactDB(name string, db *CollectionDB) *CompactionResult {
+	start := time.Now()
+	result := &CompactionResult{Name: name, SizeBefore: db.Metrics().DiskSpaceUsage()}
+	defer func() {
+		result.SizeAfter = db.Metrics().DiskSpaceUsage()
+		result.DurationMs = time.Since(start).Milliseconds()
+	}()
+
+	iter, err := db.NewIter(nil)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	var first, last []byte
+	if iter.First() {
+		first = slices.Clone(iter.Key())
+	}
+	if iter.Last() {
+		last = slices.Clone(iter.Key())
+	}
+	if err := iter.Close(); err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	if first == nil {
+		result.Skipped = true
+		return result
+	}
+
+	// Compact 的结束键不包含在内，追加一个字节以覆盖最后一个键
+	if err := db.Compact(first, append(last, 0), true); err != nil {
+		result.Error = err.Error()
+		log.Printf("❌ 压缩集合 %s 失败: %v", name, err)
+		return result
+	}
+	log.Printf("🗜️ 已压缩集合 %s: %d → %d 字节", name, result.SizeBefore, db.Metrics().DiskSpaceUsage())
+	return result
+}
+
+// GetDBStats 全局方法：获取已打开集合的 Pebble 内部统计
+func GetDBStats() ([]*CollectionDBStats, error) {
+	service := GetGlobalService()
+	if service == nil {
+		return nil, fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return nil, fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.GetDBStats()
+}
+
+// CompactCollections 全局方法：手动压缩集合
+func CompactCollections(ctx context.Context, names []string, progress func(done, total int, name string)) ([]*CompactionResult, error) {
+	service := GetGlobalService()
+	if service == nil {
+		return nil, fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return nil, fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.CompactCollections(ctx, names, progress)
+}
@@ -0,0 +1,65 @@
+package pebble_service
+
+import (
+	"context"
+	"fmt"
+	"push-base-service/models"
+	"testing"
+)
+
+func TestDBStatsAndCompact(t *testing.T) {
+	service := newTestPebbleService(t)
+
+	for i := 0; i < 50; i++ {
+		if err := service.SaveUserTokens(&models.UserPushTokens{MetaID: fmt.Sprintf("user-%02d", i), Tokens: map[string]string{"fcm": "token"}}); err != nil {
+			t.Fatalf("SaveUserTokens() failed, err: %v", err)
+		}
+	}
+	// 打开一个空集合
+	if _, err := service.getCollectionDB(CollectionQAInbox); err != nil {
+		t.Fatalf("getCollectionDB() failed, err: %v", err)
+	}
+
+	stats, err := service.GetDBStats()
+	if err != nil {
+		t.Fatalf("GetDBStats() failed, err: %v", err)
+	}
+	var tokens *CollectionDBStats
+	for _, s := range stats {
+		if s.Name == CollectionUserTokens {
+			tokens = s
+		}
+	}
+	if tokens == nil || tokens.DiskSize == 0 || tokens.WALSize == 0 {
+		t.Fatalf("GetDBStats() user_tokens = %+v, want non-empty disk and WAL usage", tokens)
+	}
+
+	var progress []string
+	results, err := service.CompactCollections(context.Background(), []string{CollectionUserTokens, CollectionQAInbox}, func(done, total int, name string) {
+		progress = append(progress, fmt.Sprintf("%d/%d %s", done, total, name))
+	})
+	if err != nil {
+		t.Fatalf("CompactCollections() failed, err: %v", err)
+	}
+	if len(results) != 2 || results[0].Error != "" || results[0].Skipped || !results[1].Skipped {
+		t.Errorf("CompactCollections() results = %+v, %+v", results[0], results[1])
+	}
+	if len(progress) != 2 || progress[1] != "2/2 "+CollectionQAInbox {
+		t.Errorf("progress = %v", progress)
+	}
+
+	// 压缩后刷盘生成了 SST 文件，数据仍可读取
+	stats, _ = service.GetDBStats()
+	for _, s := range stats {
+		if s.Name == CollectionUserTokens && s.Tables == 0 {
+			t.Errorf("user_tokens tables after compaction = 0, want > 0")
+		}
+	}
+	if tokens, err := service.GetUserTokens("user-07"); err != nil || tokens == nil {
+		t.Errorf("GetUserTokens() after compaction = %v, %v", tokens, err)
+	}
+
+	if _, err := service.CompactCollections(context.Background(), []string{"missing"}, nil); err == nil {
+		t.Errorf("CompactCollections(missing) error = nil, want error")
+	}
+}
@@ -0,0 +1,215 @@
+package pebble_service
+
+import (
+	"fmt"
+	"push-base-service/models"
+	"time"
+)
+
+// deliveriesRepo 投递记录集合存储
+func (ps *PebbleService) deliveriesRepo() *repository[models.DeliveryRecord] {
+	return newRepository[models.DeliveryRecord](ps, CollectionDeliveries, "投递记录")
+}
+
+// receiptsRepo 待查询回执集合存储
+func (ps *PebbleService) receiptsRepo() *repository[models.PendingReceipt] {
+	return newRepository[models.PendingReceipt](ps, CollectionReceipts, "待查询回执")
+}
+
+// deliveryKey 投递记录键：pinId:metaId:平台
+func deliveryKey(pinId, metaId, platform string) string {
+	return pinId + ":" + metaId + ":" + platform
+}
+
+// SaveDeliveryRecords 保存一条消息的投递记录，等待回执的记录同时写入待查询回执索引
+func (ps *PebbleService) SaveDeliveryRecords(records []*models.DeliveryRecord) error {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	if skipNonCriticalWrite(CollectionDeliveries) {
+		return nil
+	}
+
+	deliveries := ps.deliveriesRepo()
+	receipts := ps.receiptsRepo()
+	now := time.Now().Unix()
+	for _, record := range records {
+		if record.PinID == "" || record.MetaID == "" {
+			return fmt.Errorf("PinId 和 MetaId 不能为空")
+		}
+		if record.CreatedAt == 0 {
+			record.CreatedAt = now
+		}
+		record.UpdatedAt = now
+
+		if err := deliveries.Put(deliveryKey(record.PinID, record.MetaID, record.Platform), record); err != nil {
+			return err
+		}
+		if record.ReceiptStatus == models.DeliveryReceiptPending && record.ReceiptID != "" {
+			if err := receipts.Put(record.ReceiptID, &models.PendingReceipt{
+				ReceiptID:  record.ReceiptID,
+				PinID:      record.PinID,
+				MetaID:     record.MetaID,
+				Platform:   record.Platform,
+				AppVersion: record.AppVersion,
+				OSVersion:  record.OSVersion,
+				SentAt:     record.CreatedAt,
+			}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// GetDeliveryRecords 获取一条消息所有接收用户的投递记录
+func (ps *PebbleService) GetDeliveryRecords(pinId string) ([]*models.DeliveryRecord, error) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	if pinId == "" {
+		return nil, fmt.Errorf("PinId 不能为空")
+	}
+
+	records := make([]*models.DeliveryRecord, 0)
+	err := ps.deliveriesRepo().ScanPrefix(pinId+":", func(key string, record *models.DeliveryRecord) bool {
+		records = append(records, record)
+		return true
+	})
+	return records, err
+}
+
+// ListDueReceipts 列出推送时间不晚于 sentBefore 的待查询回执，最多 limit 条
+func (ps *PebbleService) ListDueReceipts(sentBefore int64, limit int) ([]*models.PendingReceipt, error) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	var pending []*models.PendingReceipt
+	err := ps.receiptsRepo().ScanPrefix("", func(key string, receipt *models.PendingReceipt) bool {
+		if receipt.SentAt <= sentBefore {
+			pending = append(pending, receipt)
+		}
+		return limit <= 0 || len(pending) < limit
+	})
+	return pending, err
+}
+
+// ResolveReceipt 写入回执结果并移出待查询回执索引
+func (ps *PebbleService) ResolveReceipt(receipt *models.PendingReceipt, status, errMsg string) error {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	deliveries := ps.deliveriesRepo()
+	key := deliveryKey(receipt.PinID, receipt.MetaID, receipt.Platform)
+	record, err := deliveries.Get(key)
+	if err != nil {
+		return err
+	}
+	// 投递记录已过期清理时只移除索引
+	if record != nil {
+		record.ReceiptStatus = status
+		if errMsg != "" {
+			record.Error = errMsg
+		}
+		record.UpdatedAt = time.Now().Unix()
+		if err := deliveries.Put(key, record); err != nil {
+			return err
+		}
+	}
+	return ps.receiptsRepo().Delete(receipt.ReceiptID)
+}
+
+// ScanDeliveryRecords 遍历推送时间不早于 since 的投递记录
+func (ps *PebbleService) ScanDeliveryRecords(since int64, fn func(record *models.DeliveryRecord)) error {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	return ps.deliveriesRepo().ScanPrefix("", func(key string, record *models.DeliveryRecord) bool {
+		if record.CreatedAt >= since {
+			fn(record)
+		}
+		return true
+	})
+}
+
+// PurgeDeliveryRecords 清理推送时间早于 before 的投递记录，返回清理数量
+func (ps *PebbleService) PurgeDeliveryRecords(before int64) (int, error) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	return ps.deliveriesRepo().DeleteWhere("", func(key string, record *models.DeliveryRecord) bool {
+		return record.CreatedAt < before
+	})
+}
+
+// SaveDeliveryRecords 全局方法：保存投递记录
+func SaveDeliveryRecords(records []*models.DeliveryRecord) error {
+	service := GetGlobalService()
+	if service == nil {
+		return fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.SaveDeliveryRecords(records)
+}
+
+// GetDeliveryRecords 全局方法：获取消息的投递记录
+func GetDeliveryRecords(pinId string) ([]*models.DeliveryRecord, error) {
+	service := GetGlobalService()
+	if service == nil {
+		return nil, fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return nil, fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.GetDeliveryRecords(pinId)
+}
+
+// ListDueReceipts 全局方法：列出到期的待查询回执
+func ListDueReceipts(sentBefore int64, limit int) ([]*models.PendingReceipt, error) {
+	service := GetGlobalService()
+	if service == nil {
+		return nil, fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return nil, fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.ListDueReceipts(sentBefore, limit)
+}
+
+// ResolveReceipt 全局方法：写入回执结果
+func ResolveReceipt(receipt *models.PendingReceipt, status, errMsg string) error {
+	service := GetGlobalService()
+	if service == nil {
+		return fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.ResolveReceipt(receipt, status, errMsg)
+}
+
+// ScanDeliveryRecords 全局方法：遍历推送时间不早于 since 的投递记录
+func ScanDeliveryRecords(since int64, fn func(record *models.DeliveryRecord)) error {
+	service := GetGlobalService()
+	if service == nil {
+		return fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.ScanDeliveryRecords(since, fn)
+}
+
+// PurgeDeliveryRecords 全局方法：清理过期的投递记录
+func PurgeDeliveryRecords(before int64) (int, error) {
+	service := GetGlobalService()
+	if service == nil {
+		return 0, fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return 0, fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.PurgeDeliveryRecords(before)
+}
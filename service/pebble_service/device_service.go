@@ -0,0 +1,93 @@
+package pebble_service
+
+import (
+	"fmt"
+	"push-base-service/models"
+	"time"
+)
+
+// devicesRepo 设备信息集合存储，键为设备ID（即令牌）
+func (ps *PebbleService) devicesRepo() *repository[models.DeviceInfo] {
+	return newRepository[models.DeviceInfo](ps, CollectionDevices, "设备信息")
+}
+
+// SetDeviceMetadata 记录设备最近一次注册令牌的时间，并合并客户端上报的设备信息（空字段保留原值）
+// 设备不存在时创建（Redis 存储后端不在 Pebble 中保存设备，设备信息仍保存在 Pebble）
+func (ps *PebbleService) SetDeviceMetadata(deviceId, platform, metaId string, metadata models.DeviceMetadata) (*models.DeviceInfo, error) {
+	if deviceId == "" || platform == "" || metaId == "" {
+		return nil, fmt.Errorf("设备ID、平台和 MetaID 都不能为空")
+	}
+
+	// 设备键锁保证"读取-合并-写入"的原子性
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+	defer ps.keyLocks.lock(deviceLockKey(deviceId))()
+
+	deviceInfo, err := ps.devicesRepo().Get(deviceId)
+	if err != nil {
+		return nil, err
+	}
+	if deviceInfo == nil {
+		deviceInfo = &models.DeviceInfo{DeviceID: deviceId}
+	}
+
+	deviceInfo.Platform = platform
+	deviceInfo.MetaID = metaId
+	deviceInfo.Merge(metadata)
+	deviceInfo.LastSeenAt = time.Now().Unix()
+	if err := ps.saveDeviceInfoLocked(deviceInfo); err != nil {
+		return nil, err
+	}
+	return deviceInfo, nil
+}
+
+// GetDevicesInfo 批量获取设备信息，不存在的设备不在结果中
+func (ps *PebbleService) GetDevicesInfo(deviceIds []string) (map[string]*models.DeviceInfo, error) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	return ps.getDevicesInfoLocked(deviceIds)
+}
+
+// getDevicesInfoLocked 批量获取设备信息，调用方需持有 ps.mu 读锁
+func (ps *PebbleService) getDevicesInfoLocked(deviceIds []string) (map[string]*models.DeviceInfo, error) {
+	repo := ps.devicesRepo()
+	devices := make(map[string]*models.DeviceInfo, len(deviceIds))
+	for _, deviceId := range deviceIds {
+		if deviceId == "" {
+			continue
+		}
+		deviceInfo, err := repo.Get(deviceId)
+		if err != nil {
+			return nil, err
+		}
+		if deviceInfo != nil {
+			devices[deviceId] = deviceInfo
+		}
+	}
+	return devices, nil
+}
+
+// SetDeviceMetadata 全局方法：记录设备信息
+func SetDeviceMetadata(deviceId, platform, metaId string, metadata models.DeviceMetadata) (*models.DeviceInfo, error) {
+	service := GetGlobalService()
+	if service == nil {
+		return nil, fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return nil, fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.SetDeviceMetadata(deviceId, platform, metaId, metadata)
+}
+
+// GetDevicesInfo 全局方法：批量获取设备信息
+func GetDevicesInfo(deviceIds []string) (map[string]*models.DeviceInfo, error) {
+	service := GetGlobalService()
+	if service == nil {
+		return nil, fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return nil, fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.GetDevicesInfo(deviceIds)
+}
@@ -0,0 +1,34 @@
+package pebble_service
+
+import (
+	"push-base-service/models"
+	"testing"
+)
+
+func TestSetDeviceMetadataMerges(t *testing.T) {
+	service := newTestPebbleService(t)
+
+	// 设备不存在时创建
+	device, err := service.SetDeviceMetadata("token-1", "expo", "alice", models.DeviceMetadata{
+		AppVersion: "1.4.2", OSVersion: "iOS 17.5", Locale: "zh-cn", Timezone: "Asia/Shanghai",
+	})
+	if err != nil {
+		t.Fatalf("SetDeviceMetadata() failed, err: %v", err)
+	}
+	if device.LastSeenAt == 0 || device.Locale != "zh-cn" {
+		t.Errorf("created device = %+v", device)
+	}
+
+	// 只上报部分字段时保留其余字段，令牌转移到其他用户时更新归属
+	if _, err := service.SetDeviceMetadata("token-1", "expo", "bob", models.DeviceMetadata{AppVersion: "1.5.0"}); err != nil {
+		t.Fatalf("SetDeviceMetadata() failed, err: %v", err)
+	}
+	devices, err := service.GetDevicesInfo([]string{"token-1", "missing"})
+	if err != nil {
+		t.Fatalf("GetDevicesInfo() failed, err: %v", err)
+	}
+	want := models.DeviceMetadata{AppVersion: "1.5.0", OSVersion: "iOS 17.5", Locale: "zh-cn", Timezone: "Asia/Shanghai"}
+	if len(devices) != 1 || devices["token-1"].MetaID != "bob" || devices["token-1"].DeviceMetadata != want {
+		t.Errorf("GetDevicesInfo() = %+v", devices)
+	}
+}
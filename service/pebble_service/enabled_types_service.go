@@ -0,0 +1,62 @@
+package pebble_service
+
+import (
+	"fmt"
+	"push-base-service/models"
+	"time"
+)
+
+// enabledTypesKey 启用消息类型集合中唯一的记录键
+const enabledTypesKey = "types"
+
+// enabledTypesRepo 启用消息类型集合存储
+func (ps *PebbleService) enabledTypesRepo() *repository[models.EnabledTypesSetting] {
+	return newRepository[models.EnabledTypesSetting](ps, CollectionEnabledTypes, "启用消息类型")
+}
+
+// SaveEnabledTypes 保存通过管理接口设置的启用消息类型（整体替换）
+func (ps *PebbleService) SaveEnabledTypes(types []string) (*models.EnabledTypesSetting, error) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	setting := &models.EnabledTypesSetting{
+		Types:     types,
+		UpdatedAt: time.Now().Unix(),
+	}
+	if err := ps.enabledTypesRepo().Put(enabledTypesKey, setting); err != nil {
+		return nil, err
+	}
+	return setting, nil
+}
+
+// GetEnabledTypes 获取通过管理接口设置的启用消息类型，未设置时返回 nil
+func (ps *PebbleService) GetEnabledTypes() (*models.EnabledTypesSetting, error) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	return ps.enabledTypesRepo().Get(enabledTypesKey)
+}
+
+// SaveEnabledTypes 全局方法：保存启用的消息类型
+func SaveEnabledTypes(types []string) (*models.EnabledTypesSetting, error) {
+	service := GetGlobalService()
+	if service == nil {
+		return nil, fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return nil, fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.SaveEnabledTypes(types)
+}
+
+// GetEnabledTypes 全局方法：获取通过管理接口设置的启用消息类型
+func GetEnabledTypes() (*models.EnabledTypesSetting, error) {
+	service := GetGlobalService()
+	if service == nil {
+		return nil, fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return nil, fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.GetEnabledTypes()
+}
@@ -0,0 +1,193 @@
+package pebble_service
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"push-base-service/models"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// CSV 表头，每个数据集一张表
+var (
+	tokensCSVHeader       = []string{"metaId", "platform", "token", "tenantId", "updatedAt"}
+	devicesCSVHeader      = []string{"deviceId", "platform", "metaId", "updatedAt"}
+	blockedChatsCSVHeader = []string{"userId", "chatId", "chatType", "reason", "blockedAt", "muteUntil"}
+)
+
+// WriteDatasetCSV 将导出数据中的指定数据集写为 CSV，令牌按 (用户, 平台) 每行一条
+func WriteDatasetCSV(w io.Writer, dataset string, data *models.DataExport) error {
+	writer := csv.NewWriter(w)
+
+	switch dataset {
+	case models.DatasetTokens:
+		writer.Write(tokensCSVHeader)
+		for _, userTokens := range data.UserTokens {
+			platforms := make([]string, 0, len(userTokens.Tokens))
+			for platform := range userTokens.Tokens {
+				platforms = append(platforms, platform)
+			}
+			sort.Strings(platforms)
+			for _, platform := range platforms {
+				writer.Write([]string{
+					userTokens.MetaID, platform, userTokens.Tokens[platform], userTokens.TenantID,
+					strconv.FormatInt(userTokens.UpdatedAt, 10),
+				})
+			}
+		}
+	case models.DatasetDevices:
+		writer.Write(devicesCSVHeader)
+		for _, deviceInfo := range data.Devices {
+			writer.Write([]string{
+				deviceInfo.DeviceID, deviceInfo.Platform, deviceInfo.MetaID,
+				strconv.FormatInt(deviceInfo.UpdatedAt, 10),
+			})
+		}
+	case models.DatasetBlockedChats:
+		writer.Write(blockedChatsCSVHeader)
+		for _, userBlockedChats := range data.BlockedChats {
+			for _, blockedChat := range userBlockedChats.BlockedChats {
+				writer.Write([]string{
+					userBlockedChats.UserID, blockedChat.ChatID, blockedChat.ChatType, blockedChat.Reason,
+					strconv.FormatInt(blockedChat.BlockedAt, 10), strconv.FormatInt(blockedChat.MuteUntil, 10),
+				})
+			}
+		}
+	default:
+		return fmt.Errorf("不支持的数据集: %s", dataset)
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("写入 CSV 失败: %w", err)
+	}
+	return nil
+}
+
+// ReadDatasetCSV 读取指定数据集的 CSV，按表头列名取值（列顺序不限，缺失的可选列留空）
+func ReadDatasetCSV(r io.Reader, dataset string) (*models.DataExport, error) {
+	var required []string
+	switch dataset {
+	case models.DatasetTokens:
+		required = []string{"metaId", "platform", "token"}
+	case models.DatasetDevices:
+		required = []string{"deviceId", "platform", "metaId"}
+	case models.DatasetBlockedChats:
+		required = []string{"userId", "chatId"}
+	default:
+		return nil, fmt.Errorf("不支持的数据集: %s", dataset)
+	}
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("读取 CSV 表头失败: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		// 兼容带 BOM 的 CSV（如 Excel 导出）
+		columns[strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))] = i
+	}
+	for _, name := range required {
+		if _, exists := columns[name]; !exists {
+			return nil, fmt.Errorf("CSV 缺少必需列: %s", name)
+		}
+	}
+
+	data := &models.DataExport{}
+	tokensByUser := make(map[string]*models.UserPushTokens)
+	blockedByUser := make(map[string]*models.UserBlockedChats)
+	var userOrder []string
+
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("读取 CSV 第 %d 行失败: %w", line, err)
+		}
+		get := func(name string) string {
+			if i, exists := columns[name]; exists && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		getInt := func(name string) (int64, error) {
+			value := get(name)
+			if value == "" {
+				return 0, nil
+			}
+			parsed, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return 0, fmt.Errorf("CSV 第 %d 行 %s 不是有效数字: %s", line, name, value)
+			}
+			return parsed, nil
+		}
+
+		switch dataset {
+		case models.DatasetTokens:
+			metaId := get("metaId")
+			userTokens, exists := tokensByUser[metaId]
+			if !exists {
+				userTokens = &models.UserPushTokens{MetaID: metaId, Tokens: make(map[string]string)}
+				tokensByUser[metaId] = userTokens
+				userOrder = append(userOrder, metaId)
+			}
+			userTokens.Tokens[get("platform")] = get("token")
+			if tenantId := get("tenantId"); tenantId != "" {
+				userTokens.TenantID = tenantId
+			}
+		case models.DatasetDevices:
+			updatedAt, err := getInt("updatedAt")
+			if err != nil {
+				return nil, err
+			}
+			data.Devices = append(data.Devices, models.DeviceInfo{
+				DeviceID:  get("deviceId"),
+				Platform:  get("platform"),
+				MetaID:    get("metaId"),
+				UpdatedAt: updatedAt,
+			})
+		case models.DatasetBlockedChats:
+			blockedAt, err := getInt("blockedAt")
+			if err != nil {
+				return nil, err
+			}
+			muteUntil, err := getInt("muteUntil")
+			if err != nil {
+				return nil, err
+			}
+			userId := get("userId")
+			userBlockedChats, exists := blockedByUser[userId]
+			if !exists {
+				userBlockedChats = &models.UserBlockedChats{UserID: userId}
+				blockedByUser[userId] = userBlockedChats
+				userOrder = append(userOrder, userId)
+			}
+			userBlockedChats.BlockedChats = append(userBlockedChats.BlockedChats, models.BlockedChat{
+				UserID:    userId,
+				ChatID:    get("chatId"),
+				ChatType:  get("chatType"),
+				Reason:    get("reason"),
+				BlockedAt: blockedAt,
+				MuteUntil: muteUntil,
+			})
+		}
+	}
+
+	for _, userId := range userOrder {
+		if userTokens, exists := tokensByUser[userId]; exists {
+			data.UserTokens = append(data.UserTokens, *userTokens)
+		}
+		if userBlockedChats, exists := blockedByUser[userId]; exists {
+			data.BlockedChats = append(data.BlockedChats, *userBlockedChats)
+		}
+	}
+	return data, nil
+}
@@ -0,0 +1,206 @@
+package pebble_service
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"push-base-service/models"
+	"time"
+)
+
+// maxImportErrors 导入结果中保留的错误条数
+const maxImportErrors = 100
+
+// ExportData 导出指定数据集，datasets 为空时导出全部
+func (ps *PebbleService) ExportData(datasets []string) (*models.DataExport, error) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	include := make(map[string]bool)
+	for _, dataset := range datasets {
+		if !isValidDataset(dataset) {
+			return nil, fmt.Errorf("不支持的数据集: %s", dataset)
+		}
+		include[dataset] = true
+	}
+	all := len(include) == 0
+
+	export := &models.DataExport{ExportedAt: time.Now().Unix()}
+
+	if all || include[models.DatasetTokens] {
+		err := ps.scanCollection(CollectionUserTokens, func(value []byte) error {
+			var userTokens models.UserPushTokens
+			if err := json.Unmarshal(value, &userTokens); err != nil {
+				return err
+			}
+			if len(userTokens.Tokens) > 0 {
+				export.UserTokens = append(export.UserTokens, userTokens)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("导出用户令牌失败: %w", err)
+		}
+	}
+
+	if all || include[models.DatasetDevices] {
+		err := ps.scanCollection(CollectionDevices, func(value []byte) error {
+			var deviceInfo models.DeviceInfo
+			if err := json.Unmarshal(value, &deviceInfo); err != nil {
+				return err
+			}
+			export.Devices = append(export.Devices, deviceInfo)
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("导出设备信息失败: %w", err)
+		}
+	}
+
+	if all || include[models.DatasetBlockedChats] {
+		// 已过期的临时静音不导出
+		err := ps.scanBlockedChats(func(userBlockedChats *models.UserBlockedChats) {
+			export.BlockedChats = append(export.BlockedChats, *userBlockedChats)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("导出屏蔽聊天失败: %w", err)
+		}
+	}
+
+	log.Printf("📤 数据导出完成: 用户令牌=%d, 设备=%d, 屏蔽列表=%d",
+		len(export.UserTokens), len(export.Devices), len(export.BlockedChats))
+	return export, nil
+}
+
+// scanCollection 遍历集合中的所有值，调用方需持有读锁
+func (ps *PebbleService) scanCollection(collectionName string, fn func(value []byte) error) error {
+	db, err := ps.getCollectionDB(collectionName)
+	if err != nil {
+		return fmt.Errorf("获取集合 %s 数据库失败: %w", collectionName, err)
+	}
+
+	iter, err := db.NewIter(nil)
+	if err != nil {
+		return fmt.Errorf("创建迭代器失败: %w", err)
+	}
+	defer iter.Close()
+
+	for iter.First(); iter.Valid(); iter.Next() {
+		if err := fn(iter.Value()); err != nil {
+			return fmt.Errorf("解析键 %s 失败: %w", string(iter.Key()), err)
+		}
+	}
+	return iter.Error()
+}
+
+// ImportData 导入数据：令牌按平台合并到现有用户（同一令牌会从原用户转移），设备覆盖写入，屏蔽聊天合并
+func (ps *PebbleService) ImportData(data *models.DataExport) (*models.ImportResult, error) {
+	if data == nil {
+		return nil, fmt.Errorf("导入数据不能为空")
+	}
+
+	result := &models.ImportResult{}
+	addError := func(format string, args ...interface{}) {
+		if len(result.Errors) < maxImportErrors {
+			result.Errors = append(result.Errors, fmt.Sprintf(format, args...))
+		}
+	}
+
+	for _, userTokens := range data.UserTokens {
+		if userTokens.MetaID == "" {
+			result.Skipped++
+			continue
+		}
+		for platform, token := range userTokens.Tokens {
+			if platform == "" || token == "" {
+				result.Skipped++
+				continue
+			}
+			if err := ps.SetUserToken(userTokens.MetaID, platform, token); err != nil {
+				addError("令牌 %s/%s: %v", userTokens.MetaID, platform, err)
+				continue
+			}
+			result.Tokens++
+		}
+		if userTokens.TenantID != "" {
+			if err := ps.setUserTenant(userTokens.MetaID, userTokens.TenantID); err != nil {
+				addError("租户 %s: %v", userTokens.MetaID, err)
+			}
+		}
+	}
+
+	for i := range data.Devices {
+		deviceInfo := data.Devices[i]
+		if deviceInfo.DeviceID == "" || deviceInfo.Platform == "" || deviceInfo.MetaID == "" {
+			result.Skipped++
+			continue
+		}
+		if err := ps.SaveDeviceInfo(&deviceInfo); err != nil {
+			addError("设备 %s: %v", deviceInfo.DeviceID, err)
+			continue
+		}
+		result.Devices++
+	}
+
+	now := time.Now().Unix()
+	for _, userBlockedChats := range data.BlockedChats {
+		for _, blockedChat := range userBlockedChats.BlockedChats {
+			userId := blockedChat.UserID
+			if userId == "" {
+				userId = userBlockedChats.UserID
+			}
+			if userId == "" || blockedChat.ChatID == "" || blockedChat.IsExpired(now) {
+				result.Skipped++
+				continue
+			}
+			if err := ps.AddBlockedChat(userId, blockedChat.ChatID, blockedChat.ChatType, blockedChat.Reason, blockedChat.MuteUntil); err != nil {
+				addError("屏蔽聊天 %s/%s: %v", userId, blockedChat.ChatID, err)
+				continue
+			}
+			result.BlockedChats++
+		}
+	}
+
+	log.Printf("📥 数据导入完成: 令牌=%d, 设备=%d, 屏蔽聊天=%d, 跳过=%d, 失败=%d",
+		result.Tokens, result.Devices, result.BlockedChats, result.Skipped, len(result.Errors))
+	return result, nil
+}
+
+// setUserTenant 设置用户所属租户
+func (ps *PebbleService) setUserTenant(metaId, tenantId string) error {
+	return ps.SetUserTenant(metaId, tenantId)
+}
+
+// isValidDataset 检查数据集名称是否有效
+func isValidDataset(dataset string) bool {
+	switch dataset {
+	case models.DatasetTokens, models.DatasetDevices, models.DatasetBlockedChats:
+		return true
+	default:
+		return false
+	}
+}
+
+// ExportData 全局方法：导出数据
+func ExportData(datasets []string) (*models.DataExport, error) {
+	service := GetGlobalService()
+	if service == nil {
+		return nil, fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return nil, fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.ExportData(datasets)
+}
+
+// ImportData 全局方法：导入数据
+func ImportData(data *models.DataExport) (*models.ImportResult, error) {
+	service := GetGlobalService()
+	if service == nil {
+		return nil, fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return nil, fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.ImportData(data)
+}
@@ -0,0 +1,92 @@
+package pebble_service
+
+import (
+	"bytes"
+	"push-base-service/models"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExportImportRoundTrip(t *testing.T) {
+	source := newTestPebbleService(t)
+
+	if err := source.SetUserToken("user-a", "fcm", "token-a"); err != nil {
+		t.Fatalf("SetUserToken() failed, err: %v", err)
+	}
+	if err := source.SetUserToken("user-a", "apns", "token-a-ios"); err != nil {
+		t.Fatalf("SetUserToken() failed, err: %v", err)
+	}
+	if err := source.AddBlockedChat("user-a", "group-1", "group", "noisy", 0); err != nil {
+		t.Fatalf("AddBlockedChat() failed, err: %v", err)
+	}
+	if err := source.AddBlockedChat("user-a", "group-2", "group", "", time.Now().Add(time.Hour).Unix()); err != nil {
+		t.Fatalf("AddBlockedChat() failed, err: %v", err)
+	}
+
+	export, err := source.ExportData(nil)
+	if err != nil {
+		t.Fatalf("ExportData() failed, err: %v", err)
+	}
+	if len(export.UserTokens) != 1 || len(export.Devices) != 2 || len(export.BlockedChats) != 1 {
+		t.Fatalf("ExportData() = %d tokens, %d devices, %d blocked; want 1, 2, 1",
+			len(export.UserTokens), len(export.Devices), len(export.BlockedChats))
+	}
+
+	target := newTestPebbleService(t)
+	result, err := target.ImportData(export)
+	if err != nil {
+		t.Fatalf("ImportData() failed, err: %v", err)
+	}
+	if result.Tokens != 2 || result.Devices != 2 || result.BlockedChats != 2 || len(result.Errors) != 0 {
+		t.Errorf("ImportData() = %+v, want 2 tokens, 2 devices, 2 blocked chats", result)
+	}
+
+	tokens, err := target.GetUserTokens("user-a")
+	if err != nil || tokens.Tokens["fcm"] != "token-a" || tokens.Tokens["apns"] != "token-a-ios" {
+		t.Errorf("GetUserTokens() after import = %+v, %v", tokens, err)
+	}
+	if blocked, _ := target.IsBlockedChat("user-a", "group-2"); !blocked {
+		t.Error("group-2 should be muted after import")
+	}
+}
+
+func TestDatasetCSVRoundTrip(t *testing.T) {
+	export := &models.DataExport{
+		UserTokens: []models.UserPushTokens{
+			{MetaID: "user-a", Tokens: map[string]string{"fcm": "token-a", "expo": "ExponentPushToken[a]"}, TenantID: "tenant-1"},
+		},
+		BlockedChats: []models.UserBlockedChats{
+			{UserID: "user-a", BlockedChats: []models.BlockedChat{{ChatID: "group-1", ChatType: "group", Reason: "a, b"}}},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteDatasetCSV(&buf, models.DatasetTokens, export); err != nil {
+		t.Fatalf("WriteDatasetCSV(tokens) failed, err: %v", err)
+	}
+	parsed, err := ReadDatasetCSV(&buf, models.DatasetTokens)
+	if err != nil {
+		t.Fatalf("ReadDatasetCSV(tokens) failed, err: %v", err)
+	}
+	if len(parsed.UserTokens) != 1 || len(parsed.UserTokens[0].Tokens) != 2 || parsed.UserTokens[0].TenantID != "tenant-1" {
+		t.Errorf("ReadDatasetCSV(tokens) = %+v", parsed.UserTokens)
+	}
+
+	buf.Reset()
+	if err := WriteDatasetCSV(&buf, models.DatasetBlockedChats, export); err != nil {
+		t.Fatalf("WriteDatasetCSV(blocked_chats) failed, err: %v", err)
+	}
+	parsed, err = ReadDatasetCSV(&buf, models.DatasetBlockedChats)
+	if err != nil {
+		t.Fatalf("ReadDatasetCSV(blocked_chats) failed, err: %v", err)
+	}
+	if len(parsed.BlockedChats) != 1 || parsed.BlockedChats[0].BlockedChats[0].Reason != "a, b" {
+		t.Errorf("ReadDatasetCSV(blocked_chats) = %+v", parsed.BlockedChats)
+	}
+
+	// 列顺序不限，缺少必需列时报错
+	if _, err := ReadDatasetCSV(strings.NewReader("token,metaId\nt,u\n"), models.DatasetTokens); err == nil {
+		t.Error("ReadDatasetCSV() should fail without platform column")
+	}
+}
@@ -0,0 +1,148 @@
+package pebble_service
+
+import (
+	"fmt"
+	"push-base-service/models"
+	"time"
+)
+
+// ExportHandoffState 导出交给接管实例的本地状态：尚未发送的定时推送、未确认的进件日志和未过期的幂等键
+func (ps *PebbleService) ExportHandoffState(instanceID string) (*models.HandoffState, error) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	state := &models.HandoffState{InstanceID: instanceID, ExportedAt: time.Now().Unix()}
+
+	jobs, err := scanScheduledPushes(ps.scheduledRepo(), func(job *models.ScheduledPush) bool {
+		return job.Status == models.ScheduleStatusPending || job.Status == models.ScheduleStatusSending
+	})
+	if err != nil {
+		return nil, fmt.Errorf("导出定时推送任务失败: %w", err)
+	}
+	state.ScheduledPushes = jobs
+
+	err = ps.intakeRepo().ScanPrefix("", func(key string, entry *models.IntakeEntry) bool {
+		state.Intake = append(state.Intake, entry)
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("导出进件日志失败: %w", err)
+	}
+
+	now := state.ExportedAt
+	err = ps.idempotencyRepo().ScanPrefix("", func(key string, record *models.IdempotencyRecord) bool {
+		if record.ExpiresAt > now {
+			state.IdempotencyRecords = append(state.IdempotencyRecords, record)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("导出幂等键失败: %w", err)
+	}
+
+	return state, nil
+}
+
+// ImportHandoffState 导入旧实例交出的状态
+// 定时推送和进件日志直接写入；幂等键在本地没有已记录结果的同名记录时写入，保证已发送的消息不会被重新发送
+func (ps *PebbleService) ImportHandoffState(state *models.HandoffState) error {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	scheduledRepo := ps.scheduledRepo()
+	scheduleMu.Lock()
+	for _, job := range state.ScheduledPushes {
+		if err := scheduledRepo.Put(job.ID, job); err != nil {
+			scheduleMu.Unlock()
+			return fmt.Errorf("导入定时推送任务失败: %w", err)
+		}
+	}
+	scheduleMu.Unlock()
+
+	intakeRepo := ps.intakeRepo()
+	for _, entry := range state.Intake {
+		if err := intakeRepo.Put(entry.ID, entry); err != nil {
+			return fmt.Errorf("导入进件日志失败: %w", err)
+		}
+	}
+
+	idempotencyRepo := ps.idempotencyRepo()
+	idempotencyMu.Lock()
+	defer idempotencyMu.Unlock()
+	for _, record := range state.IdempotencyRecords {
+		existing, err := idempotencyRepo.Get(record.Key)
+		if err != nil {
+			return fmt.Errorf("导入幂等键失败: %w", err)
+		}
+		if existing != nil && existing.Result != nil {
+			continue
+		}
+		if err := idempotencyRepo.Put(record.Key, record); err != nil {
+			return fmt.Errorf("导入幂等键失败: %w", err)
+		}
+	}
+	return nil
+}
+
+// ClearHandoffState 交出状态后删除本地已交出的定时推送和进件日志，避免本实例重新接管时再次发送；
+// 幂等键保留在本地，直到过期
+func (ps *PebbleService) ClearHandoffState(state *models.HandoffState) error {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	scheduledRepo := ps.scheduledRepo()
+	scheduleMu.Lock()
+	for _, job := range state.ScheduledPushes {
+		if err := scheduledRepo.Delete(job.ID); err != nil {
+			scheduleMu.Unlock()
+			return fmt.Errorf("删除已交出的定时推送任务失败: %w", err)
+		}
+	}
+	scheduleMu.Unlock()
+
+	intakeRepo := ps.intakeRepo()
+	for _, entry := range state.Intake {
+		if err := intakeRepo.Delete(entry.ID); err != nil {
+			return fmt.Errorf("删除已交出的进件日志失败: %w", err)
+		}
+	}
+	return nil
+}
+
+// ===== 部署交接状态全局方法 =====
+
+// ExportHandoffState 全局方法：导出交给接管实例的本地状态
+func ExportHandoffState(instanceID string) (*models.HandoffState, error) {
+	service := GetGlobalService()
+	if service == nil {
+		return nil, fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return nil, fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.ExportHandoffState(instanceID)
+}
+
+// ImportHandoffState 全局方法：导入旧实例交出的状态
+func ImportHandoffState(state *models.HandoffState) error {
+	service := GetGlobalService()
+	if service == nil {
+		return fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.ImportHandoffState(state)
+}
+
+// ClearHandoffState 全局方法：删除本地已交出的状态
+func ClearHandoffState(state *models.HandoffState) error {
+	service := GetGlobalService()
+	if service == nil {
+		return fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.ClearHandoffState(state)
+}
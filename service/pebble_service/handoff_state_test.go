@@ -0,0 +1,70 @@
+package pebble_service
+
+import (
+	"push-base-service/models"
+	"testing"
+	"time"
+)
+
+func TestHandoffState(t *testing.T) {
+	old := newTestPebbleService(t)
+	successor := newTestPebbleService(t)
+	now := time.Now().Unix()
+
+	for _, job := range []*models.ScheduledPush{
+		{ID: "pending", MetaIDs: []string{"a"}, SendAt: now + 3600, Status: models.ScheduleStatusPending},
+		{ID: "sent", MetaIDs: []string{"b"}, SendAt: now - 10, Status: models.ScheduleStatusSent},
+	} {
+		if err := old.SaveScheduledPush(job); err != nil {
+			t.Fatalf("SaveScheduledPush() failed, err: %v", err)
+		}
+	}
+	if _, err := old.AppendIntake([]byte(`{"event":"chat"}`)); err != nil {
+		t.Fatalf("AppendIntake() failed, err: %v", err)
+	}
+	if _, _, err := old.ClaimIdempotencyKey("api:done", "api", time.Hour); err != nil {
+		t.Fatalf("ClaimIdempotencyKey() failed, err: %v", err)
+	}
+	if err := old.CompleteIdempotencyKey("api:done", map[string]interface{}{"sent": 1}); err != nil {
+		t.Fatalf("CompleteIdempotencyKey() failed, err: %v", err)
+	}
+
+	state, err := old.ExportHandoffState("old")
+	if err != nil {
+		t.Fatalf("ExportHandoffState() failed, err: %v", err)
+	}
+	if len(state.ScheduledPushes) != 1 || state.ScheduledPushes[0].ID != "pending" {
+		t.Errorf("exported scheduled pushes = %+v, want only the pending job", state.ScheduledPushes)
+	}
+	if len(state.Intake) != 1 || len(state.IdempotencyRecords) != 1 {
+		t.Errorf("exported intake=%d, idempotency=%d, want 1 and 1", len(state.Intake), len(state.IdempotencyRecords))
+	}
+
+	if err := successor.ImportHandoffState(state); err != nil {
+		t.Fatalf("ImportHandoffState() failed, err: %v", err)
+	}
+	if job, _ := successor.GetScheduledPush("pending"); job == nil || job.Status != models.ScheduleStatusPending {
+		t.Errorf("pending job should be handed over, got %+v", job)
+	}
+	if entries, _ := successor.ListIntake(); len(entries) != 1 {
+		t.Errorf("intake entries after import = %d, want 1", len(entries))
+	}
+	// 已完成的幂等键交接后，重复请求仍返回首次结果
+	record, claimed, err := successor.ClaimIdempotencyKey("api:done", "api", time.Hour)
+	if err != nil || claimed || record.Result == nil {
+		t.Errorf("handed over key should stay completed, got claimed=%v, record=%+v, err=%v", claimed, record, err)
+	}
+
+	if err := old.ClearHandoffState(state); err != nil {
+		t.Fatalf("ClearHandoffState() failed, err: %v", err)
+	}
+	if job, _ := old.GetScheduledPush("pending"); job != nil {
+		t.Errorf("handed over job should be removed locally, got %+v", job)
+	}
+	if job, _ := old.GetScheduledPush("sent"); job == nil {
+		t.Errorf("sent job should stay in the local history")
+	}
+	if entries, _ := old.ListIntake(); len(entries) != 0 {
+		t.Errorf("handed over intake entries should be removed locally, got %d", len(entries))
+	}
+}
@@ -0,0 +1,171 @@
+package pebble_service
+
+import (
+	"container/list"
+	"context"
+	"log"
+	"push-base-service/models"
+	"slices"
+	"sync"
+	"time"
+)
+
+// 最近活跃用户默认配置
+const (
+	DefaultHotUsersSize = 1000
+	hotUsersKey         = "hot_set" // 最近活跃用户集合中唯一的记录键
+)
+
+// hotUserTracker 按最近查询令牌的时间记录活跃用户，容量满时淘汰最久未活跃的用户
+type hotUserTracker struct {
+	size  int
+	items map[string]*list.Element
+	order *list.List // 最近活跃的在前，元素值为 metaId
+	mu    sync.Mutex
+}
+
+// newHotUserTracker 创建活跃用户记录
+func newHotUserTracker(size int) *hotUserTracker {
+	if size <= 0 {
+		size = DefaultHotUsersSize
+	}
+	return &hotUserTracker{
+		size:  size,
+		items: make(map[string]*list.Element),
+		order: list.New(),
+	}
+}
+
+// touch 记录用户活跃，未启用记录（nil）时不做任何事
+func (h *hotUserTracker) touch(metaId string) {
+	if h == nil || metaId == "" {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if element, exists := h.items[metaId]; exists {
+		h.order.MoveToFront(element)
+		return
+	}
+
+	h.items[metaId] = h.order.PushFront(metaId)
+	for h.order.Len() > h.size {
+		oldest := h.order.Back()
+		delete(h.items, oldest.Value.(string))
+		h.order.Remove(oldest)
+	}
+}
+
+// forget 移除用户的活跃记录，未启用记录（nil）时不做任何事
+func (h *hotUserTracker) forget(metaId string) {
+	if h == nil {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if element, exists := h.items[metaId]; exists {
+		h.order.Remove(element)
+		delete(h.items, metaId)
+	}
+}
+
+// snapshot 返回当前记录的用户，最近活跃的在前
+func (h *hotUserTracker) snapshot() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	metaIds := make([]string, 0, h.order.Len())
+	for element := h.order.Front(); element != nil; element = element.Next() {
+		metaIds = append(metaIds, element.Value.(string))
+	}
+	return metaIds
+}
+
+// hotUsersRepo 最近活跃用户集合存储
+func (ps *PebbleService) hotUsersRepo() *repository[models.HotUserSet] {
+	return newRepository[models.HotUserSet](ps, CollectionHotUsers, "活跃用户列表")
+}
+
+// SaveHotUsers 保存最近活跃用户列表（整体替换）
+func (ps *PebbleService) SaveHotUsers(metaIds []string) error {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	if skipNonCriticalWrite(CollectionHotUsers) {
+		return nil
+	}
+
+	return ps.hotUsersRepo().Put(hotUsersKey, &models.HotUserSet{
+		MetaIDs:   metaIds,
+		UpdatedAt: time.Now().Unix(),
+	})
+}
+
+// GetHotUsers 获取保存的最近活跃用户列表，未保存过时返回 nil
+func (ps *PebbleService) GetHotUsers() (*models.HotUserSet, error) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	return ps.hotUsersRepo().Get(hotUsersKey)
+}
+
+// EnableHotUserTracking 启用最近活跃用户记录，查询过令牌的用户计入活跃列表
+func (pts *PebbleTokenStore) EnableHotUserTracking(size int) {
+	pts.hot = newHotUserTracker(size)
+	log.Printf("✅ 活跃用户记录已启用: 容量=%d", pts.hot.size)
+}
+
+// ForgetHotUser 从内存中的活跃用户记录移除用户（删除用户数据时调用），已保存的列表由 RemoveHotUser 处理
+func (pts *PebbleTokenStore) ForgetHotUser(metaId string) {
+	pts.hot.forget(metaId)
+}
+
+// SaveHotUsers 保存当前的活跃用户列表，未启用记录或列表为空时不保存（避免待命实例覆盖主实例的列表）
+func (pts *PebbleTokenStore) SaveHotUsers() (int, error) {
+	if pts.hot == nil {
+		return 0, nil
+	}
+	metaIds := pts.hot.snapshot()
+	if len(metaIds) == 0 {
+		return 0, nil
+	}
+	if err := pts.service.SaveHotUsers(metaIds); err != nil {
+		return 0, err
+	}
+	return len(metaIds), nil
+}
+
+// WarmUp 按保存的活跃用户列表预加载令牌（写入令牌缓存）和推送偏好（预热 Pebble 块缓存）
+// 返回预加载的用户数
+func (pts *PebbleTokenStore) WarmUp(ctx context.Context) (int, error) {
+	hotUsers, err := pts.service.GetHotUsers()
+	if err != nil {
+		return 0, err
+	}
+	if hotUsers == nil || len(hotUsers.MetaIDs) == 0 {
+		return 0, nil
+	}
+
+	metaIds := hotUsers.MetaIDs
+	if pts.hot != nil && len(metaIds) > pts.hot.size {
+		metaIds = metaIds[:pts.hot.size]
+	}
+
+	// 逆序读取：读取时会记录活跃，最后读取的用户排在最前，保持列表原有顺序
+	reversed := slices.Clone(metaIds)
+	slices.Reverse(reversed)
+
+	start := time.Now()
+	if _, err := pts.GetAllUserTokens(ctx, reversed); err != nil {
+		return 0, err
+	}
+	if _, err := pts.service.GetUserPreferencesBatch(metaIds); err != nil {
+		return 0, err
+	}
+	log.Printf("🔥 已预热 %d 个活跃用户的令牌和偏好，耗时=%v", len(metaIds), time.Since(start))
+	return len(metaIds), nil
+}
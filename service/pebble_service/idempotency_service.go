@@ -0,0 +1,219 @@
+package pebble_service
+
+import (
+	"fmt"
+	"push-base-service/models"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultIdempotencyTTL 幂等键默认保留时长
+	DefaultIdempotencyTTL = 24 * time.Hour
+	// IdempotencyClaimTTL 处理中（尚未记录结果）的幂等键保留时长，进程在处理中途退出时，超过该时长后相同的请求可以重新处理
+	IdempotencyClaimTTL = 5 * time.Minute
+)
+
+var (
+	// idempotencyMu 保证"检查-写入"的原子性，避免并发请求同时通过检查
+	idempotencyMu sync.Mutex
+	// idempotencyTTL 全局方法使用的幂等键保留时长
+	idempotencyTTL = DefaultIdempotencyTTL
+)
+
+// idempotencyRepo 幂等键集合存储
+func (ps *PebbleService) idempotencyRepo() *repository[models.IdempotencyRecord] {
+	return newRepository[models.IdempotencyRecord](ps, CollectionIdempotency, "幂等键记录")
+}
+
+// ClaimIdempotencyKey 占用幂等键
+// 首次出现（或已过期、处理中途中断）时写入记录并返回 claimed=true；
+// 已存在且未过期时返回已有记录和 claimed=false。占用后需调用 CompleteIdempotencyKey 记录结果，
+// 否则记录只保留 IdempotencyClaimTTL（不超过 ttl），之后视为处理中断
+func (ps *PebbleService) ClaimIdempotencyKey(key, source string, ttl time.Duration) (*models.IdempotencyRecord, bool, error) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	if key == "" {
+		return nil, false, fmt.Errorf("幂等键不能为空")
+	}
+	if ttl <= 0 {
+		ttl = DefaultIdempotencyTTL
+	}
+
+	repo := ps.idempotencyRepo()
+
+	idempotencyMu.Lock()
+	defer idempotencyMu.Unlock()
+
+	now := time.Now()
+	existing, err := repo.Get(key)
+	if err != nil {
+		return nil, false, err
+	}
+	if existing != nil && existing.ExpiresAt > now.Unix() && !claimAbandoned(existing, now) {
+		return existing, false, nil
+	}
+
+	record := &models.IdempotencyRecord{
+		Key:            key,
+		Source:         source,
+		CreatedAt:      now.Unix(),
+		ExpiresAt:      now.Add(ttl).Unix(),
+		ClaimExpiresAt: now.Add(min(ttl, IdempotencyClaimTTL)).Unix(),
+	}
+	if err := repo.Put(key, record); err != nil {
+		return nil, false, err
+	}
+
+	return record, true, nil
+}
+
+// claimAbandoned 幂等键已占用但超过处理时限仍未记录结果（未记录占用截止时间的旧记录视为已完成）
+func claimAbandoned(record *models.IdempotencyRecord, now time.Time) bool {
+	return record.Result == nil && record.ClaimExpiresAt != 0 && record.ClaimExpiresAt <= now.Unix()
+}
+
+// CompleteIdempotencyKey 记录幂等键对应的处理结果，重复请求时直接返回该结果
+func (ps *PebbleService) CompleteIdempotencyKey(key string, result map[string]interface{}) error {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	if key == "" {
+		return fmt.Errorf("幂等键不能为空")
+	}
+
+	repo := ps.idempotencyRepo()
+
+	idempotencyMu.Lock()
+	defer idempotencyMu.Unlock()
+
+	record, err := repo.Get(key)
+	if err != nil {
+		return err
+	}
+	if record == nil {
+		return fmt.Errorf("幂等键不存在: %s", key)
+	}
+
+	record.Result = result
+	return repo.Put(key, record)
+}
+
+// ReleaseIdempotencyKey 释放幂等键（处理失败时调用，允许调用方重试）
+func (ps *PebbleService) ReleaseIdempotencyKey(key string) error {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	if key == "" {
+		return fmt.Errorf("幂等键不能为空")
+	}
+
+	idempotencyMu.Lock()
+	defer idempotencyMu.Unlock()
+
+	return ps.idempotencyRepo().Delete(key)
+}
+
+// ReleasePendingIdempotencyKey 释放尚未记录结果的幂等键，已完成的键保留，返回是否释放
+// 用于恢复中途退出的处理：无法确定上次是否已发送完成时，以是否记录了结果为准
+func (ps *PebbleService) ReleasePendingIdempotencyKey(key string) (bool, error) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	if key == "" {
+		return false, fmt.Errorf("幂等键不能为空")
+	}
+
+	repo := ps.idempotencyRepo()
+
+	idempotencyMu.Lock()
+	defer idempotencyMu.Unlock()
+
+	record, err := repo.Get(key)
+	if err != nil || record == nil || record.Result != nil {
+		return false, err
+	}
+	return true, repo.Delete(key)
+}
+
+// PurgeExpiredIdempotencyKeys 清理已过期的幂等键，返回清理数量
+func (ps *PebbleService) PurgeExpiredIdempotencyKeys() (int, error) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	now := time.Now().Unix()
+	return ps.idempotencyRepo().DeleteWhere("", func(key string, record *models.IdempotencyRecord) bool {
+		return record.ExpiresAt <= now
+	})
+}
+
+// ===== 幂等键全局方法 =====
+
+// SetIdempotencyTTL 设置全局方法使用的幂等键保留时长
+func SetIdempotencyTTL(ttl time.Duration) {
+	if ttl <= 0 {
+		ttl = DefaultIdempotencyTTL
+	}
+	idempotencyTTL = ttl
+}
+
+// ClaimIdempotencyKey 全局方法：占用幂等键
+func ClaimIdempotencyKey(key, source string) (*models.IdempotencyRecord, bool, error) {
+	service := GetGlobalService()
+	if service == nil {
+		return nil, false, fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return nil, false, fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.ClaimIdempotencyKey(key, source, idempotencyTTL)
+}
+
+// CompleteIdempotencyKey 全局方法：记录幂等键处理结果
+func CompleteIdempotencyKey(key string, result map[string]interface{}) error {
+	service := GetGlobalService()
+	if service == nil {
+		return fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.CompleteIdempotencyKey(key, result)
+}
+
+// ReleaseIdempotencyKey 全局方法：释放幂等键
+func ReleaseIdempotencyKey(key string) error {
+	service := GetGlobalService()
+	if service == nil {
+		return fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.ReleaseIdempotencyKey(key)
+}
+
+// ReleasePendingIdempotencyKey 全局方法：释放尚未记录结果的幂等键
+func ReleasePendingIdempotencyKey(key string) (bool, error) {
+	service := GetGlobalService()
+	if service == nil {
+		return false, fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return false, fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.ReleasePendingIdempotencyKey(key)
+}
+
+// PurgeExpiredIdempotencyKeys 全局方法：清理已过期的幂等键
+func PurgeExpiredIdempotencyKeys() (int, error) {
+	service := GetGlobalService()
+	if service == nil {
+		return 0, fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return 0, fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.PurgeExpiredIdempotencyKeys()
+}
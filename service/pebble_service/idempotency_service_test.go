@@ -0,0 +1,109 @@
+package pebble_service
+
+import (
+	"push-base-service/models"
+	"testing"
+	"time"
+)
+
+func newTestPebbleService(t *testing.T) *PebbleService {
+	t.Helper()
+
+	service := NewPebbleService(&Config{DBPath: t.TempDir()})
+	if err := service.Initialize(); err != nil {
+		t.Fatalf("Initialize() failed, err: %v", err)
+	}
+	t.Cleanup(func() { service.Close() })
+	return service
+}
+
+func TestClaimIdempotencyKey(t *testing.T) {
+	service := newTestPebbleService(t)
+
+	_, claimed, err := service.ClaimIdempotencyKey("api:order-1", "api", time.Hour)
+	if err != nil || !claimed {
+		t.Fatalf("first ClaimIdempotencyKey() = %v, %v; want claimed", claimed, err)
+	}
+
+	if err := service.CompleteIdempotencyKey("api:order-1", map[string]interface{}{"successCount": 1}); err != nil {
+		t.Fatalf("CompleteIdempotencyKey() failed, err: %v", err)
+	}
+
+	record, claimed, err := service.ClaimIdempotencyKey("api:order-1", "api", time.Hour)
+	if err != nil || claimed {
+		t.Fatalf("second ClaimIdempotencyKey() = %v, %v; want duplicate", claimed, err)
+	}
+	if record.Result["successCount"] != float64(1) {
+		t.Errorf("duplicate record result = %v, want stored result", record.Result)
+	}
+
+	if err := service.ReleaseIdempotencyKey("api:order-1"); err != nil {
+		t.Fatalf("ReleaseIdempotencyKey() failed, err: %v", err)
+	}
+	if _, claimed, _ := service.ClaimIdempotencyKey("api:order-1", "api", time.Hour); !claimed {
+		t.Errorf("ClaimIdempotencyKey() after release should claim again")
+	}
+}
+
+func TestPurgeExpiredIdempotencyKeys(t *testing.T) {
+	service := newTestPebbleService(t)
+
+	if _, _, err := service.ClaimIdempotencyKey("msg:expired", "socket", time.Nanosecond); err != nil {
+		t.Fatalf("ClaimIdempotencyKey() failed, err: %v", err)
+	}
+	if _, _, err := service.ClaimIdempotencyKey("msg:alive", "socket", time.Hour); err != nil {
+		t.Fatalf("ClaimIdempotencyKey() failed, err: %v", err)
+	}
+
+	// 过期时间精确到秒，等待其过期
+	time.Sleep(1100 * time.Millisecond)
+
+	count, err := service.PurgeExpiredIdempotencyKeys()
+	if err != nil {
+		t.Fatalf("PurgeExpiredIdempotencyKeys() failed, err: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("PurgeExpiredIdempotencyKeys() = %d, want 1", count)
+	}
+
+	if _, claimed, _ := service.ClaimIdempotencyKey("msg:alive", "socket", time.Hour); claimed {
+		t.Errorf("unexpired key should not be claimable")
+	}
+}
+
+func TestAbandonedIdempotencyClaim(t *testing.T) {
+	service := newTestPebbleService(t)
+
+	// 处理中途退出：占用已超过处理时限且没有结果，可以重新占用
+	now := time.Now()
+	abandoned := &models.IdempotencyRecord{Key: "msg:crashed", CreatedAt: now.Unix(), ExpiresAt: now.Add(time.Hour).Unix(), ClaimExpiresAt: now.Add(-time.Second).Unix()}
+	if err := service.idempotencyRepo().Put(abandoned.Key, abandoned); err != nil {
+		t.Fatalf("Put() failed, err: %v", err)
+	}
+	if _, claimed, err := service.ClaimIdempotencyKey("msg:crashed", "socket", time.Hour); err != nil || !claimed {
+		t.Errorf("ClaimIdempotencyKey() on abandoned claim = %v, %v; want claimed", claimed, err)
+	}
+
+	// 处理中的键在处理时限内仍视为重复
+	if _, claimed, _ := service.ClaimIdempotencyKey("msg:crashed", "socket", time.Hour); claimed {
+		t.Errorf("in-progress key should not be claimable again")
+	}
+}
+
+func TestReleasePendingIdempotencyKey(t *testing.T) {
+	service := newTestPebbleService(t)
+
+	service.ClaimIdempotencyKey("msg:pending", "socket", time.Hour)
+	service.ClaimIdempotencyKey("msg:done", "socket", time.Hour)
+	service.CompleteIdempotencyKey("msg:done", map[string]interface{}{"pinId": ""})
+
+	if released, err := service.ReleasePendingIdempotencyKey("msg:pending"); err != nil || !released {
+		t.Errorf("ReleasePendingIdempotencyKey(pending) = %v, %v; want released", released, err)
+	}
+	if released, err := service.ReleasePendingIdempotencyKey("msg:done"); err != nil || released {
+		t.Errorf("ReleasePendingIdempotencyKey(done) = %v, %v; want kept", released, err)
+	}
+	if _, claimed, _ := service.ClaimIdempotencyKey("msg:done", "socket", time.Hour); claimed {
+		t.Errorf("completed key should stay claimed after recovery")
+	}
+}
@@ -0,0 +1,110 @@
+package pebble_service
+
+import (
+	"fmt"
+	"push-base-service/models"
+	"sync/atomic"
+	"time"
+)
+
+// intakeSeq 同一纳秒内写入多条时区分条目ID
+var intakeSeq atomic.Uint32
+
+// intakeRepo 进件日志集合存储
+func (ps *PebbleService) intakeRepo() *repository[models.IntakeEntry] {
+	return newRepository[models.IntakeEntry](ps, CollectionIntake, "进件日志")
+}
+
+// AppendIntake 写入一条原始聊天消息，返回条目ID
+func (ps *PebbleService) AppendIntake(message []byte) (string, error) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	now := time.Now()
+	entry := &models.IntakeEntry{
+		ID:         fmt.Sprintf("%020d:%05d", now.UnixNano(), intakeSeq.Add(1)%100000),
+		Message:    message,
+		ReceivedAt: now.Unix(),
+	}
+	if err := ps.intakeRepo().Put(entry.ID, entry); err != nil {
+		return "", err
+	}
+	return entry.ID, nil
+}
+
+// UpdateIntake 更新进件日志条目（如恢复次数）
+func (ps *PebbleService) UpdateIntake(entry *models.IntakeEntry) error {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	return ps.intakeRepo().Put(entry.ID, entry)
+}
+
+// DeleteIntake 删除已处理完成的进件日志条目
+func (ps *PebbleService) DeleteIntake(id string) error {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	return ps.intakeRepo().Delete(id)
+}
+
+// ListIntake 按接收顺序列出所有未确认的进件日志条目
+func (ps *PebbleService) ListIntake() ([]*models.IntakeEntry, error) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	var entries []*models.IntakeEntry
+	err := ps.intakeRepo().ScanPrefix("", func(key string, entry *models.IntakeEntry) bool {
+		entries = append(entries, entry)
+		return true
+	})
+	return entries, err
+}
+
+// AppendIntake 全局方法：写入进件日志
+func AppendIntake(message []byte) (string, error) {
+	service := GetGlobalService()
+	if service == nil {
+		return "", fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return "", fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.AppendIntake(message)
+}
+
+// UpdateIntake 全局方法：更新进件日志条目
+func UpdateIntake(entry *models.IntakeEntry) error {
+	service := GetGlobalService()
+	if service == nil {
+		return fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.UpdateIntake(entry)
+}
+
+// DeleteIntake 全局方法：删除进件日志条目
+func DeleteIntake(id string) error {
+	service := GetGlobalService()
+	if service == nil {
+		return fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.DeleteIntake(id)
+}
+
+// ListIntake 全局方法：列出未确认的进件日志条目
+func ListIntake() ([]*models.IntakeEntry, error) {
+	service := GetGlobalService()
+	if service == nil {
+		return nil, fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return nil, fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.ListIntake()
+}
@@ -0,0 +1,162 @@
+package pebble_service
+
+import (
+	"fmt"
+	"push-base-service/models"
+	"time"
+)
+
+// jobsRepo 后台任务集合存储
+func (ps *PebbleService) jobsRepo() *repository[models.Job] {
+	return newRepository[models.Job](ps, CollectionJobs, "后台任务")
+}
+
+// SaveJob 保存后台任务
+func (ps *PebbleService) SaveJob(job *models.Job) error {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	if job.ID == "" {
+		return fmt.Errorf("任务ID 不能为空")
+	}
+
+	now := time.Now().Unix()
+	if job.CreatedAt == 0 {
+		job.CreatedAt = now
+	}
+	job.UpdatedAt = now
+
+	return ps.jobsRepo().Put(job.ID, job)
+}
+
+// GetJob 获取后台任务，不存在时返回 nil
+func (ps *PebbleService) GetJob(id string) (*models.Job, error) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	if id == "" {
+		return nil, fmt.Errorf("任务ID 不能为空")
+	}
+
+	return ps.jobsRepo().Get(id)
+}
+
+// ListJobs 按创建时间倒序列出后台任务，jobType、status 为空时不过滤，最多 limit 条
+func (ps *PebbleService) ListJobs(jobType, status string, limit int) ([]*models.Job, error) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	limit = normalizePageSize(limit)
+
+	jobs := []*models.Job{}
+	err := ps.jobsRepo().ScanPrefixReverse("", func(key string, job *models.Job) bool {
+		if (jobType == "" || job.Type == jobType) && (status == "" || job.Status == status) {
+			jobs = append(jobs, job)
+		}
+		return len(jobs) < limit
+	})
+	if err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// InterruptJobs 将未结束的任务标记为失败（服务重启后调用，上次运行中的任务已无法继续），返回标记的任务数
+func (ps *PebbleService) InterruptJobs(reason string) (int, error) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	repo := ps.jobsRepo()
+	var interrupted []*models.Job
+	err := repo.ScanPrefix("", func(key string, job *models.Job) bool {
+		if !job.Finished() {
+			interrupted = append(interrupted, job)
+		}
+		return true
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now().Unix()
+	for _, job := range interrupted {
+		job.Status = models.JobStatusFailed
+		job.Error = reason
+		job.FinishedAt = now
+		job.UpdatedAt = now
+		if err := repo.Put(job.ID, job); err != nil {
+			return 0, err
+		}
+	}
+	return len(interrupted), nil
+}
+
+// PurgeJobs 删除结束时间早于 before（Unix 秒）的已结束任务，返回删除的任务数
+func (ps *PebbleService) PurgeJobs(before int64) (int, error) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	return ps.jobsRepo().DeleteWhere("", func(_ string, job *models.Job) bool {
+		return job.Finished() && job.FinishedAt < before
+	})
+}
+
+// SaveJob 全局方法：保存后台任务
+func SaveJob(job *models.Job) error {
+	service := GetGlobalService()
+	if service == nil {
+		return fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.SaveJob(job)
+}
+
+// GetJob 全局方法：获取后台任务
+func GetJob(id string) (*models.Job, error) {
+	service := GetGlobalService()
+	if service == nil {
+		return nil, fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return nil, fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.GetJob(id)
+}
+
+// ListJobs 全局方法：列出后台任务
+func ListJobs(jobType, status string, limit int) ([]*models.Job, error) {
+	service := GetGlobalService()
+	if service == nil {
+		return nil, fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return nil, fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.ListJobs(jobType, status, limit)
+}
+
+// InterruptJobs 全局方法：将未结束的任务标记为失败
+func InterruptJobs(reason string) (int, error) {
+	service := GetGlobalService()
+	if service == nil {
+		return 0, fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return 0, fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.InterruptJobs(reason)
+}
+
+// PurgeJobs 全局方法：删除过期的已结束任务
+func PurgeJobs(before int64) (int, error) {
+	service := GetGlobalService()
+	if service == nil {
+		return 0, fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return 0, fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.PurgeJobs(before)
+}
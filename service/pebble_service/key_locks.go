@@ -0,0 +1,48 @@
+package pebble_service
+
+import (
+	"hash/fnv"
+	"slices"
+	"sync"
+)
+
+// keyLockStripes 键锁的分段数
+const keyLockStripes = 256
+
+// keyLocks 分段键锁：键按哈希映射到固定数量的互斥锁上，同一个键上的读-改-写操作串行执行，
+// 不同的键大多落在不同分段上，可以并行。零值可直接使用
+type keyLocks struct {
+	stripes [keyLockStripes]sync.Mutex
+}
+
+// lock 锁定一组键，返回解锁函数
+// 按分段序号升序加锁，同时锁定多个键的调用之间不会死锁；落在同一分段的键只加锁一次
+func (l *keyLocks) lock(keys ...string) func() {
+	indexes := make([]int, 0, len(keys))
+	for _, key := range keys {
+		hash := fnv.New32a()
+		hash.Write([]byte(key))
+		indexes = append(indexes, int(hash.Sum32()%keyLockStripes))
+	}
+	slices.Sort(indexes)
+	indexes = slices.Compact(indexes)
+
+	for _, index := range indexes {
+		l.stripes[index].Lock()
+	}
+	return func() {
+		for i := len(indexes) - 1; i >= 0; i-- {
+			l.stripes[indexes[i]].Unlock()
+		}
+	}
+}
+
+// userLockKey 用户令牌的键锁名称
+func userLockKey(metaId string) string {
+	return "user:" + metaId
+}
+
+// deviceLockKey 设备记录的键锁名称
+func deviceLockKey(deviceId string) string {
+	return "device:" + deviceId
+}
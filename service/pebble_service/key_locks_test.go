@@ -0,0 +1,105 @@
+package pebble_service
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestKeyLocksMultipleKeys(t *testing.T) {
+	var locks keyLocks
+	var wg sync.WaitGroup
+	counter := 0
+
+	// 以相反顺序同时锁定多个键不会死锁，且互斥
+	for i := 0; i < 50; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			defer locks.lock("a", "b", "c")()
+			counter++
+		}()
+		go func() {
+			defer wg.Done()
+			defer locks.lock("c", "b", "a", "a")()
+			counter++
+		}()
+	}
+	wg.Wait()
+	if counter != 100 {
+		t.Errorf("counter = %d, want 100", counter)
+	}
+}
+
+func TestConcurrentSetUserTokenSameUser(t *testing.T) {
+	service := newTestPebbleService(t)
+
+	// 同一用户并发设置不同平台的令牌，读-改-写互不覆盖
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if err := service.SetUserToken("alice", fmt.Sprintf("platform-%02d", i), fmt.Sprintf("token-%02d", i)); err != nil {
+				t.Errorf("SetUserToken() failed, err: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	tokens, err := service.GetUserTokens("alice")
+	if err != nil || len(tokens.Tokens) != 20 {
+		t.Fatalf("GetUserTokens() = %v, %v, want 20 platforms", tokens, err)
+	}
+}
+
+func TestConcurrentTokenTransfer(t *testing.T) {
+	service := newTestPebbleService(t)
+	users := []string{"alice", "bob", "carol", "dave"}
+
+	// 多个用户并发抢同一个令牌，最终只有设备所属的用户持有该令牌
+	var wg sync.WaitGroup
+	for round := 0; round < 10; round++ {
+		for _, user := range users {
+			wg.Add(1)
+			go func(user string) {
+				defer wg.Done()
+				if err := service.SetUserToken(user, "expo", "shared-token"); err != nil {
+					t.Errorf("SetUserToken(%s) failed, err: %v", user, err)
+				}
+			}(user)
+		}
+	}
+	// 同时修改其中一个用户的其他字段
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if err := service.SetUserTenant("alice", fmt.Sprintf("tenant-%d", i)); err != nil {
+				t.Errorf("SetUserTenant() failed, err: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	device, err := service.GetDeviceInfo("shared-token")
+	if err != nil {
+		t.Fatalf("GetDeviceInfo() failed, err: %v", err)
+	}
+	holders := 0
+	for _, user := range users {
+		tokens, err := service.GetUserTokens(user)
+		if err != nil {
+			t.Fatalf("GetUserTokens(%s) failed, err: %v", user, err)
+		}
+		if tokens.Tokens["expo"] == "shared-token" {
+			holders++
+			if user != device.MetaID {
+				t.Errorf("%s holds the token but device belongs to %s", user, device.MetaID)
+			}
+		}
+	}
+	if holders != 1 {
+		t.Errorf("token holders = %d, want 1", holders)
+	}
+}
@@ -0,0 +1,172 @@
+package pebble_service
+
+import (
+	"io"
+
+	"github.com/cockroachdb/pebble"
+)
+
+// 集合的存储布局
+const (
+	KeyspaceMulti  = "multi"  // 每个集合一个独立的 Pebble 实例（默认）
+	KeyspaceShared = "shared" // 所有集合共用一个 Pebble 实例，键以 "集合名\x00" 为前缀
+)
+
+// sharedKeyspaceDir 共享布局下 Pebble 实例所在的子目录（位于 db_path 下）
+const sharedKeyspaceDir = "_keyspace"
+
+// keyspaceSeparator 共享布局中集合名与键之间的分隔符，集合名中不含该字节
+const keyspaceSeparator = 0x00
+
+// keyspacePrefix 共享布局下集合的键前缀
+func keyspacePrefix(collectionName string) []byte {
+	return append([]byte(collectionName), keyspaceSeparator)
+}
+
+// CollectionDB 集合的键空间。独立布局下前缀为空，直接读写集合自己的实例；
+// 共享布局下读写的键自动加上集合前缀，遍历只返回本集合的键，且返回的键不含前缀
+type CollectionDB struct {
+	db     *pebble.DB
+	prefix []byte
+}
+
+// key 在键前加上集合前缀
+func (c *CollectionDB) key(key []byte) []byte {
+	if len(c.prefix) == 0 {
+		return key
+	}
+	prefixed := make([]byte, 0, len(c.prefix)+len(key))
+	return append(append(prefixed, c.prefix...), key...)
+}
+
+// Get 读取键的值，返回的值在调用 closer.Close 之前有效
+func (c *CollectionDB) Get(key []byte) ([]byte, io.Closer, error) {
+	return c.db.Get(c.key(key))
+}
+
+// Set 写入键值
+func (c *CollectionDB) Set(key, value []byte, opts *pebble.WriteOptions) error {
+	return c.db.Set(c.key(key), value, opts)
+}
+
+// Delete 删除键
+func (c *CollectionDB) Delete(key []byte, opts *pebble.WriteOptions) error {
+	return c.db.Delete(c.key(key), opts)
+}
+
+// NewIter 创建集合内的迭代器；上下界为空时分别为集合的第一个和最后一个键
+func (c *CollectionDB) NewIter(o *pebble.IterOptions) (*CollectionIter, error) {
+	if len(c.prefix) == 0 {
+		iter, err := c.db.NewIter(o)
+		if err != nil {
+			return nil, err
+		}
+		return &CollectionIter{iter: iter}, nil
+	}
+
+	var opts pebble.IterOptions
+	if o != nil {
+		opts = *o
+	}
+	opts.LowerBound = c.key(opts.LowerBound)
+	if opts.UpperBound != nil {
+		opts.UpperBound = c.key(opts.UpperBound)
+	} else {
+		opts.UpperBound = prefixUpperBound(c.prefix)
+	}
+	iter, err := c.db.NewIter(&opts)
+	if err != nil {
+		return nil, err
+	}
+	return &CollectionIter{iter: iter, prefix: c.prefix}, nil
+}
+
+// NewBatch 创建集合内的写批次
+func (c *CollectionDB) NewBatch() *CollectionBatch {
+	return &CollectionBatch{batch: c.db.NewBatch(), collection: c}
+}
+
+// Compact 压缩集合内 [start, end) 范围的键
+func (c *CollectionDB) Compact(start, end []byte, parallelize bool) error {
+	return c.db.Compact(c.key(start), c.key(end), parallelize)
+}
+
+// Metrics 返回集合所在 Pebble 实例的统计；共享布局下为所有集合共用实例的统计
+func (c *CollectionDB) Metrics() *pebble.Metrics {
+	return c.db.Metrics()
+}
+
+// CollectionIter 集合内的迭代器，Key 返回不含集合前缀的键
+type CollectionIter struct {
+	iter   *pebble.Iterator
+	prefix []byte
+}
+
+// First 移动到第一个键
+func (it *CollectionIter) First() bool { return it.iter.First() }
+
+// Last 移动到最后一个键
+func (it *CollectionIter) Last() bool { return it.iter.Last() }
+
+// SeekGE 移动到第一个大于等于 key 的键
+func (it *CollectionIter) SeekGE(key []byte) bool {
+	if len(it.prefix) == 0 {
+		return it.iter.SeekGE(key)
+	}
+	return it.iter.SeekGE(append(append([]byte(nil), it.prefix...), key...))
+}
+
+// Next 移动到下一个键
+func (it *CollectionIter) Next() bool { return it.iter.Next() }
+
+// Prev 移动到上一个键
+func (it *CollectionIter) Prev() bool { return it.iter.Prev() }
+
+// Valid 当前是否位于有效的键上
+func (it *CollectionIter) Valid() bool { return it.iter.Valid() }
+
+// Key 当前键（不含集合前缀），在迭代器移动前有效
+func (it *CollectionIter) Key() []byte { return it.iter.Key()[len(it.prefix):] }
+
+// Value 当前值，在迭代器移动前有效
+func (it *CollectionIter) Value() []byte { return it.iter.Value() }
+
+// Error 迭代过程中的错误
+func (it *CollectionIter) Error() error { return it.iter.Error() }
+
+// Close 关闭迭代器
+func (it *CollectionIter) Close() error { return it.iter.Close() }
+
+// CollectionBatch 集合内的写批次
+type CollectionBatch struct {
+	batch      *pebble.Batch
+	collection *CollectionDB
+}
+
+// Set 写入键值
+func (b *CollectionBatch) Set(key, value []byte, opts *pebble.WriteOptions) error {
+	return b.batch.Set(b.collection.key(key), value, opts)
+}
+
+// Delete 删除键
+func (b *CollectionBatch) Delete(key []byte, opts *pebble.WriteOptions) error {
+	return b.batch.Delete(b.collection.key(key), opts)
+}
+
+// DeleteRange 删除 [start, end) 范围的键，end 为空时删除到集合的最后一个键
+func (b *CollectionBatch) DeleteRange(start, end []byte, opts *pebble.WriteOptions) error {
+	upper := b.collection.key(end)
+	if end == nil && len(b.collection.prefix) > 0 {
+		upper = prefixUpperBound(b.collection.prefix)
+	}
+	return b.batch.DeleteRange(b.collection.key(start), upper, opts)
+}
+
+// Empty 批次是否为空
+func (b *CollectionBatch) Empty() bool { return b.batch.Empty() }
+
+// Commit 提交批次
+func (b *CollectionBatch) Commit(opts *pebble.WriteOptions) error { return b.batch.Commit(opts) }
+
+// Close 释放批次
+func (b *CollectionBatch) Close() error { return b.batch.Close() }
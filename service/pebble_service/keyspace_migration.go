@@ -0,0 +1,148 @@
+package pebble_service
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"push-base-service/service/lock_service"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/cockroachdb/pebble"
+)
+
+// migrationBatchSize 迁移时每个写批次的最大字节数
+const migrationBatchSize = 4 << 20
+
+// KeyspaceMigrationResult 独立布局迁移到共享布局的结果
+type KeyspaceMigrationResult struct {
+	Collections map[string]int `json:"collections"` // 集合 → 迁移的键数
+	Keys        int            `json:"keys"`        // 迁移的总键数
+	PreviousDir string         `json:"previousDir"` // 原集合目录的保留位置
+	DurationMs  int64          `json:"durationMs"`  // 耗时（毫秒）
+}
+
+// knownCollections 服务使用的全部集合；迁移只处理这些名称的目录，数据目录中的其他子目录（如备份、临时文件）保持不动
+var knownCollections = []string{
+	CollectionUserTokens, CollectionDevices, CollectionBlockedChats, CollectionNotifiedPins, CollectionTenantHooks,
+	CollectionIdempotency, CollectionScheduled, CollectionTokenMetrics, CollectionThrottle, CollectionQAAccounts,
+	CollectionQAInbox, CollectionPreferences, CollectionTranslations, CollectionTenantQuotas, CollectionTenantUsage,
+	CollectionUserMerges, CollectionRoutingRules, CollectionHotUsers, CollectionIntake, CollectionDeliveries,
+	CollectionReceipts, CollectionAPIKeys, CollectionQuarantine, CollectionUserTraces, CollectionTraceEvents,
+	CollectionTokenIndex, CollectionTokenGC, CollectionPushAudit, CollectionJobs, CollectionChatSounds,
+	CollectionSenderBlocks, CollectionGroupsBlock, CollectionEnabledTypes, CollectionCatchup, CollectionTxnLog,
+	CollectionSubscription, CollectionSelfTest,
+}
+
+// legacyCollectionDirs 数据目录中独立布局的集合目录（只含已知集合，不含共享实例目录），按名称排序
+func legacyCollectionDirs(dbPath string) []string {
+	entries, err := os.ReadDir(dbPath)
+	if err != nil {
+		return nil
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() && slices.Contains(knownCollections, entry.Name()) {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// MigrateToSharedKeyspace 将 dbPath 下独立布局的集合逐个复制到共享实例中，
+// 全部复制成功后把原集合目录移动到 <db_path>.pre-keyspace-<时间戳>；迁移期间持有数据目录的进程锁，服务仍在运行时直接失败
+// 迁移中途失败时原集合目录保持不变，修复后可重新执行（已复制的键会被覆盖）
+func MigrateToSharedKeyspace(dbPath string) (*KeyspaceMigrationResult, error) {
+	start := time.Now()
+	dbPath = filepath.Clean(dbPath)
+	processLock, err := lock_service.AcquireProcessLock(dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("迁移前请先停止使用该数据目录的服务: %w", err)
+	}
+	defer processLock.Release()
+
+	collections := legacyCollectionDirs(dbPath)
+	if len(collections) == 0 {
+		return nil, fmt.Errorf("数据目录 %s 中没有需要迁移的集合", dbPath)
+	}
+
+	shared, err := pebble.Open(filepath.Join(dbPath, sharedKeyspaceDir), newPebbleOptions())
+	if err != nil {
+		return nil, fmt.Errorf("打开共享数据库失败: %w", err)
+	}
+
+	result := &KeyspaceMigrationResult{Collections: make(map[string]int)}
+	for _, collection := range collections {
+		count, err := copyCollectionToShared(shared, filepath.Join(dbPath, collection), collection)
+		if err != nil {
+			shared.Close()
+			return nil, fmt.Errorf("迁移集合 %s 失败: %w", collection, err)
+		}
+		result.Collections[collection] = count
+		result.Keys += count
+		log.Printf("📦 已迁移集合 %s: %d 个键", collection, count)
+	}
+	if err := shared.Close(); err != nil {
+		return nil, fmt.Errorf("关闭共享数据库失败: %w", err)
+	}
+
+	result.PreviousDir = fmt.Sprintf("%s.pre-keyspace-%d", dbPath, time.Now().Unix())
+	if err := os.MkdirAll(result.PreviousDir, 0755); err != nil {
+		return nil, fmt.Errorf("创建原集合保留目录失败: %w", err)
+	}
+	for _, collection := range collections {
+		if err := os.Rename(filepath.Join(dbPath, collection), filepath.Join(result.PreviousDir, collection)); err != nil {
+			return nil, fmt.Errorf("移动原集合目录 %s 失败: %w", collection, err)
+		}
+	}
+
+	result.DurationMs = time.Since(start).Milliseconds()
+	log.Printf("✅ 已迁移到共享布局: 集合数=%d, 键数=%d, 原集合目录=%s", len(collections), result.Keys, result.PreviousDir)
+	return result, nil
+}
+
+// copyCollectionToShared 将独立布局的集合数据库中的全部键加上集合前缀写入共享实例
+func copyCollectionToShared(shared *pebble.DB, srcPath, collection string) (int, error) {
+	opts := newPebbleOptions()
+	opts.ReadOnly = true
+	src, err := pebble.Open(srcPath, opts)
+	if err != nil {
+		return 0, fmt.Errorf("打开集合数据库失败: %w", err)
+	}
+	defer src.Close()
+
+	iter, err := src.NewIter(nil)
+	if err != nil {
+		return 0, err
+	}
+	defer iter.Close()
+
+	target := &CollectionDB{db: shared, prefix: keyspacePrefix(collection)}
+	batch := target.NewBatch()
+	count := 0
+	for iter.First(); iter.Valid(); iter.Next() {
+		if err := batch.Set(iter.Key(), iter.Value(), nil); err != nil {
+			batch.Close()
+			return count, err
+		}
+		count++
+		if batch.batch.Len() >= migrationBatchSize {
+			if err := batch.Commit(pebble.Sync); err != nil {
+				batch.Close()
+				return count, err
+			}
+			batch.Close()
+			batch = target.NewBatch()
+		}
+	}
+	if err := iter.Error(); err != nil {
+		batch.Close()
+		return count, err
+	}
+	defer batch.Close()
+	return count, batch.Commit(pebble.Sync)
+}
@@ -0,0 +1,150 @@
+package pebble_service
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"push-base-service/service/lock_service"
+	"testing"
+
+	"github.com/cockroachdb/pebble"
+)
+
+func TestSharedKeyspaceIsolation(t *testing.T) {
+	service := NewPebbleService(&Config{DBPath: t.TempDir(), Keyspace: KeyspaceShared})
+	if err := service.Initialize(); err != nil {
+		t.Fatalf("Initialize() failed, err: %v", err)
+	}
+	t.Cleanup(func() { service.Close() })
+
+	tokens, _ := service.getCollectionDB(CollectionUserTokens)
+	devices, _ := service.getCollectionDB(CollectionDevices)
+	if tokens.db != devices.db {
+		t.Fatal("collections should share one Pebble instance")
+	}
+
+	// 相同的键在不同集合中互不影响
+	if err := tokens.Set([]byte("k"), []byte("token"), pebble.Sync); err != nil {
+		t.Fatalf("Set() failed, err: %v", err)
+	}
+	if err := devices.Set([]byte("k"), []byte("device"), pebble.Sync); err != nil {
+		t.Fatalf("Set() failed, err: %v", err)
+	}
+	value, closer, err := tokens.Get([]byte("k"))
+	if err != nil || string(value) != "token" {
+		t.Fatalf("Get() = %q, %v, want token", value, err)
+	}
+	closer.Close()
+
+	// 无界遍历只返回本集合的键，且不含前缀
+	batch := devices.NewBatch()
+	batch.Set([]byte("a"), nil, nil)
+	batch.Set([]byte("\xff"), nil, nil)
+	if err := batch.Commit(pebble.Sync); err != nil {
+		t.Fatalf("Commit() failed, err: %v", err)
+	}
+	batch.Close()
+	keys := func(db *CollectionDB) []string {
+		t.Helper()
+		iter, err := db.NewIter(nil)
+		if err != nil {
+			t.Fatalf("NewIter() failed, err: %v", err)
+		}
+		defer iter.Close()
+		var keys []string
+		for iter.First(); iter.Valid(); iter.Next() {
+			keys = append(keys, string(iter.Key()))
+		}
+		return keys
+	}
+	if got := fmt.Sprintf("%q", keys(devices)); got != `["a" "k" "\xff"]` {
+		t.Errorf("devices keys = %s", got)
+	}
+
+	// DeleteRange 不越过集合边界
+	batch = devices.NewBatch()
+	batch.DeleteRange([]byte("a"), nil, nil)
+	if err := batch.Commit(pebble.Sync); err != nil {
+		t.Fatalf("Commit() failed, err: %v", err)
+	}
+	batch.Close()
+	if got := keys(devices); len(got) != 0 {
+		t.Errorf("devices keys after DeleteRange = %v, want none", got)
+	}
+	if got := fmt.Sprint(keys(tokens)); got != "[k]" {
+		t.Errorf("user_tokens keys = %s, want [k]", got)
+	}
+
+	stats, err := service.GetDBStats()
+	if err != nil || len(stats) != 1 || stats[0].Name != sharedKeyspaceDir {
+		t.Errorf("GetDBStats() = %v, %v, want only the shared instance", stats, err)
+	}
+}
+
+func TestMigrateToSharedKeyspace(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "pebble")
+
+	legacy := NewPebbleService(&Config{DBPath: dbPath})
+	if err := legacy.Initialize(); err != nil {
+		t.Fatalf("Initialize() failed, err: %v", err)
+	}
+	for i := 0; i < 20; i++ {
+		if err := legacy.SetUserToken(fmt.Sprintf("user-%02d", i), "expo", fmt.Sprintf("token-%02d", i)); err != nil {
+			t.Fatalf("SetUserToken() failed, err: %v", err)
+		}
+	}
+	legacy.Close()
+	// 数据目录中的非集合子目录不参与迁移
+	if err := os.MkdirAll(filepath.Join(dbPath, "backup-2024"), 0755); err != nil {
+		t.Fatalf("MkdirAll() failed, err: %v", err)
+	}
+
+	running, err := lock_service.AcquireProcessLock(dbPath)
+	if err != nil {
+		t.Fatalf("AcquireProcessLock() failed, err: %v", err)
+	}
+	if _, err := MigrateToSharedKeyspace(dbPath); !errors.Is(err, lock_service.ErrProcessLocked) {
+		t.Errorf("MigrateToSharedKeyspace() while locked = %v, want ErrProcessLocked", err)
+	}
+	running.Release()
+
+	result, err := MigrateToSharedKeyspace(dbPath)
+	if err != nil {
+		t.Fatalf("MigrateToSharedKeyspace() failed, err: %v", err)
+	}
+	if result.Collections[CollectionUserTokens] != 20 || result.Collections[CollectionDevices] != 20 {
+		t.Errorf("migrated collections = %v", result.Collections)
+	}
+	if dirs := legacyCollectionDirs(dbPath); len(dirs) != 0 {
+		t.Errorf("legacy dirs after migration = %v, want none", dirs)
+	}
+	if _, err := os.Stat(filepath.Join(result.PreviousDir, CollectionUserTokens)); err != nil {
+		t.Errorf("previous collection dir missing: %v", err)
+	}
+	if _, ok := result.Collections["backup-2024"]; ok {
+		t.Errorf("unknown dir should not be migrated: %v", result.Collections)
+	}
+	if _, err := os.Stat(filepath.Join(dbPath, "backup-2024")); err != nil {
+		t.Errorf("unknown dir should stay in place: %v", err)
+	}
+
+	shared := NewPebbleService(&Config{DBPath: dbPath, Keyspace: KeyspaceShared})
+	if err := shared.Initialize(); err != nil {
+		t.Fatalf("Initialize() failed, err: %v", err)
+	}
+	t.Cleanup(func() { shared.Close() })
+
+	tokens, err := shared.GetUserTokens("user-07")
+	if err != nil || tokens == nil || tokens.Tokens["expo"] != "token-07" {
+		t.Errorf("GetUserTokens() after migration = %+v, %v", tokens, err)
+	}
+	device, err := shared.GetDeviceInfo("token-01")
+	if err != nil || device == nil || device.MetaID != "user-01" {
+		t.Errorf("GetDeviceInfo() after migration = %+v, %v", device, err)
+	}
+
+	if _, err := MigrateToSharedKeyspace(dbPath); err == nil {
+		t.Error("MigrateToSharedKeyspace() with nothing to migrate should fail")
+	}
+}
@@ -0,0 +1,32 @@
+package pebble_service
+
+import (
+	"errors"
+	"fmt"
+	"push-base-service/service/disk_service"
+	"push-base-service/service/metrics_service"
+	"syscall"
+)
+
+// lossySkippedCounter 磁盘空间紧急时跳过的非关键写入数
+var lossySkippedCounter = metrics_service.NewCounterVec(
+	"push_pebble_lossy_skipped_total", "Number of non-critical Pebble writes skipped while disk space is critical", "collection")
+
+// skipNonCriticalWrite 磁盘空间紧急（降级模式）时跳过历史记录、收件箱、缓存等非关键写入，
+// 把剩余空间留给去重和令牌等核心数据
+func skipNonCriticalWrite(collection string) bool {
+	if !disk_service.Lossy() {
+		return false
+	}
+	lossySkippedCounter.Inc(collection)
+	return true
+}
+
+// wrapDiskFull 写入因磁盘已满失败时进入降级模式，并返回明确的错误信息
+func (ps *PebbleService) wrapDiskFull(err error) error {
+	if !errors.Is(err, syscall.ENOSPC) {
+		return err
+	}
+	disk_service.ReportDiskFull(ps.path)
+	return fmt.Errorf("磁盘空间已满: %w", err)
+}
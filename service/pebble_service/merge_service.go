@@ -0,0 +1,61 @@
+package pebble_service
+
+import (
+	"fmt"
+	"push-base-service/models"
+	"sync/atomic"
+	"time"
+)
+
+// userMergeSeq 同一纳秒内的记录序号，保证记录ID唯一且有序
+var userMergeSeq atomic.Uint64
+
+// userMergesRepo 用户合并审计集合存储
+func (ps *PebbleService) userMergesRepo() *repository[models.UserMergeRecord] {
+	return newRepository[models.UserMergeRecord](ps, CollectionUserMerges, "用户合并记录")
+}
+
+// SaveUserMergeRecord 保存用户合并审计记录，未设置 ID 时按当前时间生成
+func (ps *PebbleService) SaveUserMergeRecord(record *models.UserMergeRecord) error {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	if record == nil || record.SourceMetaID == "" || record.TargetMetaID == "" {
+		return fmt.Errorf("合并记录的源和目标 MetaID 不能为空")
+	}
+	if record.ID == "" {
+		record.ID = fmt.Sprintf("%020d-%06d", time.Now().UnixNano(), userMergeSeq.Add(1)%1000000)
+	}
+
+	return ps.userMergesRepo().Put(record.ID, record)
+}
+
+// ListUserMergeRecords 按时间倒序列出合并记录，metaId 不为空时只返回源或目标为该用户的记录
+func (ps *PebbleService) ListUserMergeRecords(metaId string, limit int) ([]*models.UserMergeRecord, error) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	records := []*models.UserMergeRecord{}
+	err := ps.userMergesRepo().ScanPrefixReverse("", func(key string, record *models.UserMergeRecord) bool {
+		if metaId == "" || record.SourceMetaID == metaId || record.TargetMetaID == metaId {
+			records = append(records, record)
+		}
+		return limit <= 0 || len(records) < limit
+	})
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// ListUserMergeRecords 全局方法：列出用户合并记录
+func ListUserMergeRecords(metaId string, limit int) ([]*models.UserMergeRecord, error) {
+	service := GetGlobalService()
+	if service == nil {
+		return nil, fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return nil, fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.ListUserMergeRecords(metaId, limit)
+}
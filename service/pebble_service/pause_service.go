@@ -0,0 +1,159 @@
+package pebble_service
+
+import (
+	"fmt"
+	"log"
+	"push-base-service/models"
+	"sync"
+	"time"
+)
+
+// pauseMu 保证暂停状态、通知类别等偏好字段"读取-修改-写入"的原子性
+var pauseMu sync.Mutex
+
+// PauseNotifications 暂停用户通知至 until（Unix 秒），保留其他偏好设置，重新暂停时清零错过的消息数
+func (ps *PebbleService) PauseNotifications(metaId string, until int64, summary bool) (*models.UserPreferences, error) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	if metaId == "" {
+		return nil, fmt.Errorf("MetaID 不能为空")
+	}
+
+	pauseMu.Lock()
+	defer pauseMu.Unlock()
+
+	repo := ps.preferencesRepo()
+	preferences, err := repo.Get(metaId)
+	if err != nil {
+		return nil, err
+	}
+	if preferences == nil {
+		preferences = &models.UserPreferences{MetaID: metaId}
+	}
+	preferences.PausedUntil = until
+	preferences.PauseSummary = summary
+	preferences.PausedMissed = 0
+	preferences.UpdatedAt = time.Now().Unix()
+	if err := repo.Put(metaId, preferences); err != nil {
+		return nil, err
+	}
+
+	log.Printf("⏸️ 已暂停用户通知: MetaID=%s, 恢复时间=%s", metaId, time.Unix(until, 0).Format(time.RFC3339))
+	return preferences, nil
+}
+
+// ResumeNotifications 恢复用户通知，返回恢复前的偏好（未暂停时返回 nil）
+func (ps *PebbleService) ResumeNotifications(metaId string) (*models.UserPreferences, error) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	pauseMu.Lock()
+	defer pauseMu.Unlock()
+
+	repo := ps.preferencesRepo()
+	preferences, err := repo.Get(metaId)
+	if err != nil || preferences == nil || preferences.PausedUntil == 0 {
+		return nil, err
+	}
+
+	previous := *preferences
+	preferences.PausedUntil = 0
+	preferences.PauseSummary = false
+	preferences.PausedMissed = 0
+	preferences.UpdatedAt = time.Now().Unix()
+	if err := repo.Put(metaId, preferences); err != nil {
+		return nil, err
+	}
+	return &previous, nil
+}
+
+// RecordPausedMessages 从 metaIds 中找出当前暂停通知的用户并为其累计错过的消息数，返回这些用户
+func (ps *PebbleService) RecordPausedMessages(metaIds []string, now int64) ([]string, error) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	pauseMu.Lock()
+	defer pauseMu.Unlock()
+
+	repo := ps.preferencesRepo()
+	var paused []string
+	for _, metaId := range metaIds {
+		if metaId == "" {
+			continue
+		}
+		preferences, err := repo.Get(metaId)
+		if err != nil || preferences == nil || preferences.PausedUntil <= now {
+			continue
+		}
+		preferences.PausedMissed++
+		if err := repo.Put(metaId, preferences); err != nil {
+			log.Printf("⚠️ 记录用户 %s 暂停期间的消息数失败: %v", metaId, err)
+		}
+		paused = append(paused, metaId)
+	}
+	return paused, nil
+}
+
+// ListDuePauses 列出暂停已到期、等待恢复的用户偏好
+func (ps *PebbleService) ListDuePauses(now int64) ([]*models.UserPreferences, error) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	var due []*models.UserPreferences
+	err := ps.preferencesRepo().ScanPrefix("", func(key string, preferences *models.UserPreferences) bool {
+		if preferences.PausedUntil > 0 && preferences.PausedUntil <= now {
+			due = append(due, preferences)
+		}
+		return true
+	})
+	return due, err
+}
+
+// PauseNotifications 全局方法：暂停用户通知
+func PauseNotifications(metaId string, until int64, summary bool) (*models.UserPreferences, error) {
+	service := GetGlobalService()
+	if service == nil {
+		return nil, fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return nil, fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.PauseNotifications(metaId, until, summary)
+}
+
+// ResumeNotifications 全局方法：恢复用户通知
+func ResumeNotifications(metaId string) (*models.UserPreferences, error) {
+	service := GetGlobalService()
+	if service == nil {
+		return nil, fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return nil, fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.ResumeNotifications(metaId)
+}
+
+// RecordPausedMessages 全局方法：找出暂停通知的用户并累计错过的消息数
+func RecordPausedMessages(metaIds []string, now int64) ([]string, error) {
+	service := GetGlobalService()
+	if service == nil {
+		return nil, fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return nil, fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.RecordPausedMessages(metaIds, now)
+}
+
+// ListDuePauses 全局方法：列出暂停已到期的用户偏好
+func ListDuePauses(now int64) ([]*models.UserPreferences, error) {
+	service := GetGlobalService()
+	if service == nil {
+		return nil, fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return nil, fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.ListDuePauses(now)
+}
@@ -2,14 +2,16 @@ package pebble_service
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"log"
 	"path/filepath"
 	"push-base-service/models"
-	"push-base-service/service/push_service"
+	"push-base-service/service/lock_service"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/cockroachdb/pebble"
@@ -18,48 +20,122 @@ import (
 var Pb map[string]*pebble.DB
 
 const (
-	CollectionUserTokens   = "user_tokens"   // 用户令牌集合
-	CollectionDevices      = "devices"       // 设备信息集合
-	CollectionBlockedChats = "blocked_chats" // 用户屏蔽的群ID或私聊ID集合 key:metaid, value: []{groupId or chatId, type}
-	CollectionNotifiedPins = "notified_pins" // 已经通知的PIN ID集合 key: pinId, value: pinId
+	CollectionUserTokens   = "user_tokens"      // 用户令牌集合
+	CollectionDevices      = "devices"          // 设备信息集合
+	CollectionBlockedChats = "blocked_chats"    // 用户屏蔽的群ID或私聊ID集合 key: metaId:chatId, value: BlockedChat
+	CollectionNotifiedPins = "notified_pins"    // 已经通知的PIN ID集合 key: pinId, value: pinId
+	CollectionTenantHooks  = "tenant_hooks"     // 租户投递事件Webhook集合 key: tenantId, value: TenantWebhook
+	CollectionIdempotency  = "idempotency"      // 幂等键集合 key: 幂等键, value: IdempotencyRecord（带过期时间）
+	CollectionScheduled    = "scheduled_pushes" // 定时推送任务集合 key: 任务ID, value: ScheduledPush
+	CollectionTokenMetrics = "token_metrics"    // 令牌每日统计集合 key: 日期:平台, value: TokenDailyMetrics
+	CollectionThrottle     = "throttle"         // 推送限流集合 key: metaId, value: ThrottleWindow
+	CollectionQAAccounts   = "qa_accounts"      // QA 测试账号集合 key: metaId, value: QAAccount
+	CollectionQAInbox      = "qa_inbox"         // QA 虚拟收件箱集合 key: metaId:消息ID, value: QAInboxMessage
+	CollectionPreferences  = "user_preferences" // 用户推送偏好集合 key: metaId, value: UserPreferences
+	CollectionTranslations = "translations"     // 预览翻译缓存集合 key: 消息ID:语言, value: CachedTranslation
+	CollectionTenantQuotas = "tenant_quotas"    // 租户推送配额集合 key: tenantId, value: TenantQuota
+	CollectionTenantUsage  = "tenant_usage"     // 租户月度用量集合 key: tenantId:月份, value: TenantUsage
+	CollectionUserMerges   = "user_merges"      // 用户合并审计集合 key: 记录ID, value: UserMergeRecord
+	CollectionRoutingRules = "routing_rules"    // 通知路由规则集合 key: rules, value: RoutingRuleSet
+	CollectionHotUsers     = "hot_users"        // 最近活跃用户集合 key: hot_set, value: HotUserSet
+	CollectionIntake       = "intake"           // 进件日志集合 key: 接收时间纳秒:序号, value: IntakeEntry
+	CollectionDeliveries   = "deliveries"       // 投递记录集合 key: pinId:metaId:平台, value: DeliveryRecord
+	CollectionReceipts     = "pending_receipts" // 待查询回执集合 key: 回执ID, value: PendingReceipt
+	CollectionAPIKeys      = "api_keys"         // 管理接口创建的 API Key 集合 key: Key 的 SHA-256, value: APIKeyRecord
+	CollectionQuarantine   = "quarantine"       // 解析失败的上游消息集合 key: 隔离时间纳秒:序号, value: QuarantinedMessage
+	CollectionUserTraces   = "user_traces"      // 推送追踪用户集合 key: metaId, value: UserTrace
+	CollectionTraceEvents  = "trace_events"     // 推送追踪事件集合 key: metaId:事件ID, value: TraceEvent
+	CollectionTokenIndex   = "token_index"      // 用户令牌二级索引集合 key: p:平台:metaId 或 u:更新时间:metaId, value: 空
+	CollectionTokenGC      = "token_gc"         // 过期令牌清理统计集合 key: stats, value: TokenGCStats
+	CollectionPushAudit    = "push_audit"       // 推送审计日志集合 key: metaId:记录ID, value: PushAuditEntry
+	CollectionJobs         = "jobs"             // 后台任务集合 key: 任务ID, value: Job
+	CollectionChatSounds   = "chat_sounds"      // 用户聊天通知声音集合 key: metaId:chatId, value: ChatSound
+	CollectionSenderBlocks = "blocked_senders"  // 用户屏蔽的发送者集合 key: metaId:senderId, value: BlockedSender
+	CollectionGroupsBlock  = "all_groups_block" // 用户屏蔽所有群聊设置集合 key: metaId, value: AllGroupsBlock
+	CollectionEnabledTypes = "enabled_types"    // 启用的消息类型集合 key: types, value: EnabledTypesSetting
+	CollectionCatchup      = "catchup"          // 上游消息补拉检查点集合 key: 上游名称, value: CatchupCheckpoint
+	CollectionTxnLog       = "txn_log"          // 跨集合写事务日志 key: 提交时间纳秒:序号, value: 事务中的写操作列表
+	CollectionSubscription = "subscriptions"    // 用户推送退订集合 key: metaId, value: PushSubscription
 )
 
 // PebbleService Pebble 数据库服务
+//
+// 并发模型：
+//   - mu 保护服务生命周期：读写数据的方法持有读锁，Close、Restore 和创建备份检查点持有写锁，
+//     数据操作不会与关闭数据库或替换数据目录交错
+//   - keyLocks 串行化同一用户或设备上的读-改-写操作（保存令牌时按旧值更新索引、令牌转移、移除令牌等），
+//     同一用户的并发修改不会互相覆盖，不同用户的操作仍然并行
+//   - 加锁顺序固定为先 mu 读锁、再 keyLocks；持有键锁期间只调用 *Locked / load* 等不再加锁的内部方法
+//   - 只读方法不获取键锁，读到的是最近一次提交的完整记录
 type PebbleService struct {
 	collectionMgr *CollectionManager // 集合管理器
 	mu            sync.RWMutex
+	keyLocks      keyLocks // 用户、设备级别的键锁
 	path          string
+	codec         *valueCodec // 值压缩编解码器，未启用压缩时为 nil
+
+	lockProcess bool                      // 是否对数据目录加进程锁
+	processLock *lock_service.ProcessLock // 持有的进程锁，Close 时释放
+
+	// 用户令牌变更监听器（如令牌缓存失效）
+	tokenListeners   []func(metaId string)
+	tokenListenersMu sync.RWMutex
+
+	blockedChatsMigrated atomic.Bool // 旧版屏蔽列表是否已迁移为每个聊天一条记录
+	closed               atomic.Bool // 是否已调用 Close（之后访问集合会按需重新打开）
 }
 
 // Config Pebble 配置
 type Config struct {
-	DBPath string `yaml:"db_path" json:"db_path"` // 数据库文件路径
+	DBPath      string             `yaml:"db_path" json:"db_path"`         // 数据库文件路径
+	Keyspace    string             `yaml:"keyspace" json:"keyspace"`       // 集合存储布局：multi（默认）或 shared
+	Compression *CompressionConfig `yaml:"compression" json:"compression"` // 值压缩配置
+	ProcessLock bool               `yaml:"-" json:"-"`                     // 初始化时对数据目录加进程锁，防止两个进程同时打开同一数据目录
 }
 
 // DefaultConfig 返回默认配置
 func DefaultConfig() *Config {
 	return &Config{
-		DBPath: "./data/pebble", // 默认数据库路径
+		DBPath:   "./data/pebble", // 默认数据库路径
+		Keyspace: KeyspaceMulti,
 	}
 }
 
 // CollectionManager 集合管理器
 type CollectionManager struct {
 	mu          sync.RWMutex
-	collections map[string]*pebble.DB
+	collections map[string]*CollectionDB
 	basePath    string
+	shared      bool       // 所有集合共用一个 Pebble 实例
+	sharedDB    *pebble.DB // 共享布局下的 Pebble 实例，首次使用时打开
 }
 
-// NewCollectionManager 创建集合管理器
-func NewCollectionManager(basePath string) *CollectionManager {
+// NewCollectionManager 创建集合管理器，keyspace 为 KeyspaceShared 时所有集合共用一个 Pebble 实例
+func NewCollectionManager(basePath, keyspace string) *CollectionManager {
 	return &CollectionManager{
-		collections: make(map[string]*pebble.DB),
+		collections: make(map[string]*CollectionDB),
 		basePath:    basePath,
+		shared:      keyspace == KeyspaceShared,
+	}
+}
+
+// newPebbleOptions 集合数据库的 Pebble 选项
+func newPebbleOptions() *pebble.Options {
+	return &pebble.Options{
+		Cache:                       pebble.NewCache(16 << 20), // 16MB 缓存
+		DisableWAL:                  false,                     // 启用 WAL
+		FormatMajorVersion:          pebble.FormatNewest,       // 使用最新格式
+		L0CompactionThreshold:       2,                         // L0 压缩阈值
+		L0StopWritesThreshold:       1000,                      // L0 停止写入阈值
+		LBaseMaxBytes:               16 << 20,                  // 16MB
+		MaxOpenFiles:                4096,                      // 最大打开文件数
+		MemTableSize:                16 << 20,                  // 16MB 内存表
+		MemTableStopWritesThreshold: 4,                         // 内存表停止写入阈值
 	}
 }
 
 // GetCollection 获取指定集合的数据库实例
-func (cm *CollectionManager) GetCollection(collectionName string) (*pebble.DB, error) {
+func (cm *CollectionManager) GetCollection(collectionName string) (*CollectionDB, error) {
 	cm.mu.RLock()
 	if db, exists := cm.collections[collectionName]; exists {
 		cm.mu.RUnlock()
@@ -76,43 +152,65 @@ func (cm *CollectionManager) GetCollection(collectionName string) (*pebble.DB, e
 		return db, nil
 	}
 
+	if cm.shared {
+		return cm.addSharedCollection(collectionName)
+	}
+
 	// 创建集合专用的数据库路径
 	dbPath := filepath.Join(cm.basePath, collectionName)
 
-	// 配置 Pebble 选项
-	opts := &pebble.Options{
-		Cache:                       pebble.NewCache(16 << 20), // 16MB 缓存
-		DisableWAL:                  false,                     // 启用 WAL
-		FormatMajorVersion:          pebble.FormatNewest,       // 使用最新格式
-		L0CompactionThreshold:       2,                         // L0 压缩阈值
-		L0StopWritesThreshold:       1000,                      // L0 停止写入阈值
-		LBaseMaxBytes:               16 << 20,                  // 16MB
-		MaxOpenFiles:                4096,                      // 最大打开文件数
-		MemTableSize:                16 << 20,                  // 16MB 内存表
-		MemTableStopWritesThreshold: 4,                         // 内存表停止写入阈值
-	}
-
 	// 打开数据库
-	db, err := pebble.Open(dbPath, opts)
+	db, err := pebble.Open(dbPath, newPebbleOptions())
 	if err != nil {
 		return nil, fmt.Errorf("打开集合 %s 的数据库失败: %w", collectionName, err)
 	}
 
-	cm.collections[collectionName] = db
+	collection := &CollectionDB{db: db}
+	cm.collections[collectionName] = collection
 	log.Printf("✅ 集合 %s 数据库初始化成功: %s", collectionName, dbPath)
 
+	return collection, nil
+}
+
+// addSharedCollection 在共享实例中为集合分配键空间，调用方需持有写锁
+func (cm *CollectionManager) addSharedCollection(collectionName string) (*CollectionDB, error) {
+	db, err := cm.openShared()
+	if err != nil {
+		return nil, err
+	}
+
+	collection := &CollectionDB{db: db, prefix: keyspacePrefix(collectionName)}
+	cm.collections[collectionName] = collection
+	return collection, nil
+}
+
+// openShared 打开共享实例（已打开时直接返回），调用方需持有写锁
+func (cm *CollectionManager) openShared() (*pebble.DB, error) {
+	if cm.sharedDB != nil {
+		return cm.sharedDB, nil
+	}
+
+	dbPath := filepath.Join(cm.basePath, sharedKeyspaceDir)
+	db, err := pebble.Open(dbPath, newPebbleOptions())
+	if err != nil {
+		return nil, fmt.Errorf("打开共享数据库失败: %w", err)
+	}
+	cm.sharedDB = db
+	log.Printf("✅ 共享数据库初始化成功: %s", dbPath)
 	return db, nil
 }
 
-// CloseCollection 关闭指定集合的数据库
+// CloseCollection 关闭指定集合的数据库（共享布局下只释放集合的键空间，共享实例保持打开）
 func (cm *CollectionManager) CloseCollection(collectionName string) error {
 	cm.mu.Lock()
 	defer cm.mu.Unlock()
 
-	if db, exists := cm.collections[collectionName]; exists {
-		err := db.Close()
+	if collection, exists := cm.collections[collectionName]; exists {
 		delete(cm.collections, collectionName)
-		if err != nil {
+		if cm.shared {
+			return nil
+		}
+		if err := collection.db.Close(); err != nil {
 			return fmt.Errorf("关闭集合 %s 的数据库失败: %w", collectionName, err)
 		}
 		log.Printf("✅ 集合 %s 数据库已关闭", collectionName)
@@ -126,15 +224,16 @@ func (cm *CollectionManager) CloseAll() error {
 	defer cm.mu.Unlock()
 
 	var errors []string
-	for collectionName, db := range cm.collections {
+	for name, db := range cm.instances() {
 		if err := db.Close(); err != nil {
-			errors = append(errors, fmt.Sprintf("关闭集合 %s 失败: %v", collectionName, err))
+			errors = append(errors, fmt.Sprintf("关闭集合 %s 失败: %v", name, err))
 		} else {
-			log.Printf("✅ 集合 %s 数据库已关闭", collectionName)
+			log.Printf("✅ 集合 %s 数据库已关闭", name)
 		}
 	}
 
-	cm.collections = make(map[string]*pebble.DB)
+	cm.collections = make(map[string]*CollectionDB)
+	cm.sharedDB = nil
 
 	if len(errors) > 0 {
 		return fmt.Errorf("关闭数据库时发生错误: %s", strings.Join(errors, "; "))
@@ -142,6 +241,22 @@ func (cm *CollectionManager) CloseAll() error {
 	return nil
 }
 
+// instances 已打开的 Pebble 实例（实例目录名 → 实例），调用方需持有锁
+// 独立布局下每个集合一个实例，共享布局下只有共享实例
+func (cm *CollectionManager) instances() map[string]*pebble.DB {
+	instances := make(map[string]*pebble.DB)
+	if cm.shared {
+		if cm.sharedDB != nil {
+			instances[sharedKeyspaceDir] = cm.sharedDB
+		}
+		return instances
+	}
+	for name, collection := range cm.collections {
+		instances[name] = collection.db
+	}
+	return instances
+}
+
 // ListCollections 列出所有已初始化的集合
 func (cm *CollectionManager) ListCollections() []string {
 	cm.mu.RLock()
@@ -162,7 +277,9 @@ func NewPebbleService(config *Config) *PebbleService {
 
 	return &PebbleService{
 		path:          config.DBPath,
-		collectionMgr: NewCollectionManager(config.DBPath),
+		collectionMgr: NewCollectionManager(config.DBPath, config.Keyspace),
+		codec:         newValueCodec(config.Compression),
+		lockProcess:   config.ProcessLock,
 	}
 }
 
@@ -179,17 +296,52 @@ func (ps *PebbleService) Initialize() error {
 		return fmt.Errorf("获取数据库路径失败: %w", err)
 	}
 
-	log.Printf("✅ Pebble 数据库初始化成功: %s", dbPath)
+	// 同一数据目录只允许一个进程打开，否则 Pebble 会在首次访问某个集合时才报错，或两个进程交替写坏数据
+	if ps.lockProcess && ps.processLock == nil {
+		processLock, err := lock_service.AcquireProcessLock(dbPath)
+		if err != nil {
+			return fmt.Errorf("同一数据目录只能运行一个实例，请为每个实例（包括部署交接的新旧实例）配置独立的 db_path: %w", err)
+		}
+		ps.processLock = processLock
+		log.Printf("🔒 已锁定数据目录: %s", processLock.Path())
+	}
+
+	if ps.collectionMgr.shared {
+		if legacy := legacyCollectionDirs(ps.path); len(legacy) > 0 {
+			log.Printf("⚠️ 数据目录中有 %d 个独立布局的集合未迁移到共享布局，请先停止服务并执行 -migrate-keyspace: %v", len(legacy), legacy)
+		}
+	}
+
+	// 重放上次崩溃时未完成的跨集合写事务
+	recovered, err := ps.recoverPendingTxns()
+	if err != nil {
+		return fmt.Errorf("重放未完成的写事务失败: %w", err)
+	}
+	if recovered > 0 {
+		log.Printf("♻️ 已重放 %d 个未完成的写事务", recovered)
+	}
+
+	ps.closed.Store(false)
+	log.Printf("✅ Pebble 数据库初始化成功: %s, 存储布局=%s", dbPath, ps.keyspace())
 
 	return nil
 }
 
+// keyspace 当前的集合存储布局
+func (ps *PebbleService) keyspace() string {
+	if ps.collectionMgr.shared {
+		return KeyspaceShared
+	}
+	return KeyspaceMulti
+}
+
 // Close 关闭数据库
 func (ps *PebbleService) Close() error {
 	ps.mu.Lock()
 	defer ps.mu.Unlock()
 
 	log.Printf("🛑 正在关闭 Pebble 数据库")
+	ps.closed.Store(true)
 
 	// 关闭所有集合数据库
 	if ps.collectionMgr != nil {
@@ -199,6 +351,14 @@ func (ps *PebbleService) Close() error {
 		}
 	}
 
+	// 集合全部关闭后才释放进程锁，其他进程此时才能打开数据目录
+	if ps.processLock != nil {
+		if err := ps.processLock.Release(); err != nil {
+			log.Printf("⚠️ 释放数据目录进程锁失败: %v", err)
+		}
+		ps.processLock = nil
+	}
+
 	log.Printf("✅ Pebble 数据库已关闭")
 	return nil
 }
@@ -211,14 +371,14 @@ func (ps *PebbleService) IsInitialized() bool {
 }
 
 // getCollectionDB 获取指定集合的数据库实例
-func (ps *PebbleService) getCollectionDB(collectionName string) (*pebble.DB, error) {
+func (ps *PebbleService) getCollectionDB(collectionName string) (*CollectionDB, error) {
 	if ps.collectionMgr == nil {
 		return nil, fmt.Errorf("集合管理器未初始化")
 	}
 	return ps.collectionMgr.GetCollection(collectionName)
 }
 
-// buildKey 构建集合键（现在每个collection都有独立的数据库，所以键可以简化）
+// buildKey 构建集合键（集合之间的隔离由 CollectionDB 负责，键本身不含集合名）
 func buildKey(id string) []byte {
 	return []byte(id)
 }
@@ -233,51 +393,21 @@ func getDeviceKey(deviceId string) []byte {
 	return buildKey(deviceId)
 }
 
-// getUserBlockedChatsKey 生成用户屏蔽聊天列表的键
-func getUserBlockedChatsKey(userId string) []byte {
-	return buildKey(userId)
-}
-
-// getNotifiedPinKey 生成已通知PIN的键
-func getNotifiedPinKey(pinId string) []byte {
-	return buildKey(pinId)
-}
-
-// getUserBlockedChatsFromDB 从数据库获取用户屏蔽聊天列表
-func (ps *PebbleService) getUserBlockedChatsFromDB(db *pebble.DB, userId string) (*models.UserBlockedChats, error) {
-	key := getUserBlockedChatsKey(userId)
-	value, closer, err := db.Get(key)
-	if err != nil {
-		if err == pebble.ErrNotFound {
-			// 用户没有屏蔽列表，返回空列表
-			return &models.UserBlockedChats{
-				UserID:       userId,
-				BlockedChats: []models.BlockedChat{},
-				UpdatedAt:    time.Now().Unix(),
-			}, nil
-		}
-		return nil, fmt.Errorf("获取用户屏蔽列表失败: %w", err)
-	}
-	defer closer.Close()
-
-	// 反序列化 JSON
-	var userBlockedChats models.UserBlockedChats
-	if err := json.Unmarshal(value, &userBlockedChats); err != nil {
-		return nil, fmt.Errorf("反序列化用户屏蔽列表失败: %w", err)
-	}
-
-	return &userBlockedChats, nil
-}
-
 // SaveUserTokens 保存用户推送令牌
 func (ps *PebbleService) SaveUserTokens(userTokens *models.UserPushTokens) error {
-	ps.mu.RLock()
-	defer ps.mu.RUnlock()
-
 	if userTokens.MetaID == "" {
 		return fmt.Errorf("MetaID 不能为空")
 	}
 
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+	defer ps.keyLocks.lock(userLockKey(userTokens.MetaID))()
+
+	return ps.saveUserTokensLocked(userTokens)
+}
+
+// saveUserTokensLocked 保存用户推送令牌并更新索引，调用方需持有 ps.mu 读锁和该用户的键锁
+func (ps *PebbleService) saveUserTokensLocked(userTokens *models.UserPushTokens) error {
 	// 获取用户令牌集合的数据库
 	db, err := ps.getCollectionDB(CollectionUserTokens)
 	if err != nil {
@@ -293,16 +423,52 @@ func (ps *PebbleService) SaveUserTokens(userTokens *models.UserPushTokens) error
 		return fmt.Errorf("序列化用户令牌失败: %w", err)
 	}
 
-	// 保存到数据库
+	// 读取旧值，用于更新二级索引
 	key := getUserTokensKey(userTokens.MetaID)
+	var oldTokens *models.UserPushTokens
+	if value, closer, err := db.Get(key); err == nil {
+		oldTokens = &models.UserPushTokens{}
+		if json.Unmarshal(value, oldTokens) != nil {
+			oldTokens = nil
+		}
+		closer.Close()
+	}
+
+	// 保存到数据库
 	if err := db.Set(key, data, pebble.Sync); err != nil {
 		return fmt.Errorf("保存用户令牌失败: %w", err)
 	}
+	ps.updateTokenIndex(oldTokens, userTokens)
+	ps.notifyUserTokensChanged(userTokens.MetaID)
 
 	log.Printf("✅ 已保存用户令牌: MetaID=%s, 平台数=%d", userTokens.MetaID, len(userTokens.Tokens))
 	return nil
 }
 
+// OnUserTokensChanged 注册用户令牌变更监听器，令牌写入或删除后回调（需快速返回）
+// metaId 为空表示所有用户的令牌都可能已变化（如从备份恢复）
+func (ps *PebbleService) OnUserTokensChanged(listener func(metaId string)) {
+	if listener == nil {
+		return
+	}
+
+	ps.tokenListenersMu.Lock()
+	defer ps.tokenListenersMu.Unlock()
+
+	ps.tokenListeners = append(ps.tokenListeners, listener)
+}
+
+// notifyUserTokensChanged 通知所有用户令牌变更监听器
+func (ps *PebbleService) notifyUserTokensChanged(metaId string) {
+	ps.tokenListenersMu.RLock()
+	listeners := ps.tokenListeners
+	ps.tokenListenersMu.RUnlock()
+
+	for _, listener := range listeners {
+		listener(metaId)
+	}
+}
+
 // GetUserTokens 获取用户推送令牌
 func (ps *PebbleService) GetUserTokens(metaId string) (*models.UserPushTokens, error) {
 	ps.mu.RLock()
@@ -350,75 +516,197 @@ func (ps *PebbleService) UpdateUserTokens(userTokens *models.UserPushTokens) err
 }
 
 // SetUserToken 设置用户在指定平台的推送令牌（Token作为设备ID进行唯一性检查）
+// 设备记录、旧用户和新用户的令牌及令牌索引在同一个写事务中提交，令牌转移不会只完成一半
 func (ps *PebbleService) SetUserToken(metaId, platform, token string) error {
 	if metaId == "" || platform == "" || token == "" {
 		return fmt.Errorf("MetaID、平台和令牌都不能为空")
 	}
 
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
 	// 1. 使用token作为设备ID，检查是否已存在，如果存在且属于不同用户，需要处理冲突
-	existingDevice, err := ps.GetDeviceInfo(token) // 使用token作为deviceId
-	if err == nil {
-		// 设备(token)已存在
-		if existingDevice.MetaID != metaId {
-			// Token属于不同用户，需要从旧用户中移除该平台的令牌
-			log.Printf("⚠️ Token %s 从用户 %s 转移到用户 %s", token, existingDevice.MetaID, metaId)
-
-			// 获取旧用户的令牌
-			oldUserTokens, err := ps.GetUserTokens(existingDevice.MetaID)
-			if err == nil && oldUserTokens.Tokens != nil {
-				// 从旧用户的令牌中移除该平台的令牌
-				if oldToken, exists := oldUserTokens.Tokens[platform]; exists && oldToken == token {
-					delete(oldUserTokens.Tokens, platform)
-					if err := ps.SaveUserTokens(oldUserTokens); err != nil {
-						log.Printf("⚠️ 更新旧用户 %s 令牌失败: %v", existingDevice.MetaID, err)
-					} else {
-						log.Printf("✅ 已从旧用户 %s 中移除平台 %s 的令牌", existingDevice.MetaID, platform)
-					}
-				}
-			}
-		}
-		// 更新设备信息到新用户
-		existingDevice.MetaID = metaId
-		existingDevice.Platform = platform
-		if err := ps.SaveDeviceInfo(existingDevice); err != nil {
-			return fmt.Errorf("更新设备信息失败: %w", err)
-		}
-	} else {
+	device, unlock, err := ps.lockDeviceAndOwners(token, metaId)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	txn := ps.newWriteTxn()
+	event := ""
+	changedUsers := []string{metaId}
+	if device == nil {
 		// Token(设备)不存在，创建新的设备信息
-		deviceInfo := &models.DeviceInfo{
-			DeviceID:  token, // 使用token作为设备ID
-			Platform:  platform,
-			MetaID:    metaId,
-			UpdatedAt: time.Now().Unix(),
+		device = &models.DeviceInfo{DeviceID: token}
+		event = TokenEventRegistration
+	} else if device.MetaID != metaId {
+		// Token属于不同用户，需要从旧用户中移除该平台的令牌
+		log.Printf("⚠️ Token %s 从用户 %s 转移到用户 %s", token, device.MetaID, metaId)
+		event = TokenEventTransfer
+
+		oldUserTokens, err := ps.loadUserTokens(device.MetaID)
+		if err != nil {
+			return fmt.Errorf("获取旧用户令牌失败: %w", err)
 		}
-		if err := ps.SaveDeviceInfo(deviceInfo); err != nil {
-			return fmt.Errorf("创建设备信息失败: %w", err)
+		if oldUserTokens != nil && oldUserTokens.Tokens[platform] == token {
+			oldIndexKeys := tokenIndexKeys(oldUserTokens)
+			delete(oldUserTokens.Tokens, platform)
+			if err := stageUserTokens(txn, oldUserTokens, oldIndexKeys); err != nil {
+				return err
+			}
+			changedUsers = append(changedUsers, device.MetaID)
 		}
 	}
 
+	// 更新设备信息到新用户
+	device.MetaID = metaId
+	device.Platform = platform
+	device.UpdatedAt = time.Now().Unix()
+	deviceData, err := json.Marshal(device)
+	if err != nil {
+		return fmt.Errorf("序列化设备信息失败: %w", err)
+	}
+	txn.Set(CollectionDevices, getDeviceKey(token), deviceData)
+
 	// 2. 获取现有用户令牌
-	userTokens, err := ps.GetUserTokens(metaId)
+	userTokens, err := ps.loadUserTokens(metaId)
 	if err != nil {
 		return fmt.Errorf("获取现有用户令牌失败: %w", err)
 	}
-
-	// 确保 Tokens map 不为 nil
+	if userTokens == nil {
+		userTokens = &models.UserPushTokens{MetaID: metaId}
+	}
+	oldIndexKeys := tokenIndexKeys(userTokens)
 	if userTokens.Tokens == nil {
 		userTokens.Tokens = make(map[string]string)
 	}
 
-	// 3. 设置令牌
+	// 3. 设置令牌（重新注册的令牌不再视为过期）
 	userTokens.Tokens[platform] = token
+	delete(userTokens.StaleTokens, platform)
+	if err := stageUserTokens(txn, userTokens, oldIndexKeys); err != nil {
+		return err
+	}
 
-	// 4. 保存更新后的令牌
-	if err := ps.SaveUserTokens(userTokens); err != nil {
-		return fmt.Errorf("保存更新后的用户令牌失败: %w", err)
+	// 4. 一次性提交所有修改
+	if err := txn.Commit(); err != nil {
+		return fmt.Errorf("保存用户令牌失败: %w", err)
+	}
+
+	if event != "" {
+		ps.recordTokenEventLocked(platform, event)
+	}
+	for _, changed := range changedUsers {
+		ps.notifyUserTokensChanged(changed)
 	}
 
 	log.Printf("✅ 已设置用户令牌: MetaID=%s, 平台=%s, Token(DeviceID)=%s", metaId, platform, token)
 	return nil
 }
 
+// lockDeviceAndOwners 锁定设备、设备当前所属的用户和 metaId，返回加锁后读取的设备记录（不存在时为 nil）和解锁函数
+// 所属用户需要先读取设备才能知道，加锁后重新读取，若所属用户在此期间被修改则重试，调用方需持有 ps.mu 读锁
+func (ps *PebbleService) lockDeviceAndOwners(deviceId, metaId string) (*models.DeviceInfo, func(), error) {
+	for {
+		device, err := ps.loadDeviceInfo(deviceId)
+		if err != nil {
+			return nil, nil, err
+		}
+		keys := []string{deviceLockKey(deviceId), userLockKey(metaId)}
+		if device != nil && device.MetaID != metaId {
+			keys = append(keys, userLockKey(device.MetaID))
+		}
+		unlock := ps.keyLocks.lock(keys...)
+
+		current, err := ps.loadDeviceInfo(deviceId)
+		if err != nil {
+			unlock()
+			return nil, nil, err
+		}
+		if deviceOwner(current) == deviceOwner(device) {
+			return current, unlock, nil
+		}
+		unlock()
+	}
+}
+
+// deviceOwner 设备所属的用户，设备不存在时为空
+func deviceOwner(device *models.DeviceInfo) string {
+	if device == nil {
+		return ""
+	}
+	return device.MetaID
+}
+
+// stageUserTokens 将用户令牌及其索引的变更加入写事务，oldIndexKeys 为修改前的索引键
+func stageUserTokens(txn *writeTxn, userTokens *models.UserPushTokens, oldIndexKeys map[string]bool) error {
+	userTokens.UpdatedAt = time.Now().Unix()
+	data, err := json.Marshal(userTokens)
+	if err != nil {
+		return fmt.Errorf("序列化用户令牌失败: %w", err)
+	}
+	txn.Set(CollectionUserTokens, getUserTokensKey(userTokens.MetaID), data)
+
+	newIndexKeys := tokenIndexKeys(userTokens)
+	for key := range oldIndexKeys {
+		if !newIndexKeys[key] {
+			txn.Delete(CollectionTokenIndex, buildKey(key))
+		}
+	}
+	for key := range newIndexKeys {
+		if !oldIndexKeys[key] {
+			txn.Set(CollectionTokenIndex, buildKey(key), nil)
+		}
+	}
+	return nil
+}
+
+// loadUserTokens 读取用户令牌，不存在时返回 nil，调用方需持有 ps.mu
+func (ps *PebbleService) loadUserTokens(metaId string) (*models.UserPushTokens, error) {
+	db, err := ps.getCollectionDB(CollectionUserTokens)
+	if err != nil {
+		return nil, fmt.Errorf("获取用户令牌集合数据库失败: %w", err)
+	}
+
+	value, closer, err := db.Get(getUserTokensKey(metaId))
+	if err != nil {
+		if err == pebble.ErrNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("获取用户令牌失败: %w", err)
+	}
+	defer closer.Close()
+
+	var userTokens models.UserPushTokens
+	if err := json.Unmarshal(value, &userTokens); err != nil {
+		return nil, fmt.Errorf("反序列化用户令牌失败: %w", err)
+	}
+	return &userTokens, nil
+}
+
+// loadDeviceInfo 读取设备信息，不存在时返回 nil，调用方需持有 ps.mu
+func (ps *PebbleService) loadDeviceInfo(deviceId string) (*models.DeviceInfo, error) {
+	db, err := ps.getCollectionDB(CollectionDevices)
+	if err != nil {
+		return nil, fmt.Errorf("获取设备集合数据库失败: %w", err)
+	}
+
+	value, closer, err := db.Get(getDeviceKey(deviceId))
+	if err != nil {
+		if err == pebble.ErrNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("获取设备信息失败: %w", err)
+	}
+	defer closer.Close()
+
+	var deviceInfo models.DeviceInfo
+	if err := json.Unmarshal(value, &deviceInfo); err != nil {
+		return nil, fmt.Errorf("反序列化设备信息失败: %w", err)
+	}
+	return &deviceInfo, nil
+}
+
 // SetUserTokenWithDevice 设置用户在指定平台的推送令牌，同时管理设备信息
 // 注意：此方法现在直接调用 SetUserToken，因为 SetUserToken 已经使用token作为设备ID
 func (ps *PebbleService) SetUserTokenWithDevice(metaId, platform, token, deviceId string) error {
@@ -433,14 +721,18 @@ func (ps *PebbleService) RemoveUserToken(metaId, platform string) error {
 		return fmt.Errorf("MetaID 和平台不能为空")
 	}
 
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+	defer ps.keyLocks.lock(userLockKey(metaId))()
+
 	// 获取现有令牌
-	userTokens, err := ps.GetUserTokens(metaId)
+	userTokens, err := ps.loadUserTokens(metaId)
 	if err != nil {
 		return fmt.Errorf("获取现有用户令牌失败: %w", err)
 	}
 
-	// 确保 Tokens map 不为 nil
-	if userTokens.Tokens == nil {
+	// 没有令牌记录
+	if userTokens == nil || userTokens.Tokens == nil {
 		log.Printf("⚠️ 用户 %s 没有令牌记录", metaId)
 		return nil
 	}
@@ -455,21 +747,34 @@ func (ps *PebbleService) RemoveUserToken(metaId, platform string) error {
 	delete(userTokens.Tokens, platform)
 
 	// 保存更新后的令牌
-	if err := ps.SaveUserTokens(userTokens); err != nil {
+	if err := ps.saveUserTokensLocked(userTokens); err != nil {
 		return fmt.Errorf("保存更新后的用户令牌失败: %w", err)
 	}
 
+	ps.recordTokenEventLocked(platform, TokenEventRemoval)
 	log.Printf("✅ 已移除用户令牌: MetaID=%s, 平台=%s", metaId, platform)
 	return nil
 }
 
 // DeleteUserTokens 删除用户的所有推送令牌
 func (ps *PebbleService) DeleteUserTokens(metaId string) error {
+	if metaId == "" {
+		return fmt.Errorf("MetaID 不能为空")
+	}
+
 	ps.mu.RLock()
 	defer ps.mu.RUnlock()
+	defer ps.keyLocks.lock(userLockKey(metaId))()
 
-	if metaId == "" {
-		return fmt.Errorf("MetaID 不能为空")
+	return ps.deleteUserTokensLocked(metaId)
+}
+
+// deleteUserTokensLocked 删除用户的所有推送令牌及其索引，调用方需持有 ps.mu 读锁和该用户的键锁
+func (ps *PebbleService) deleteUserTokensLocked(metaId string) error {
+	// 先读取现有令牌，用于更新索引和统计各平台的移除数
+	existingTokens, err := ps.loadUserTokens(metaId)
+	if err != nil {
+		return fmt.Errorf("获取现有用户令牌失败: %w", err)
 	}
 
 	// 获取用户令牌集合的数据库
@@ -482,6 +787,14 @@ func (ps *PebbleService) DeleteUserTokens(metaId string) error {
 	if err := db.Delete(key, pebble.Sync); err != nil {
 		return fmt.Errorf("删除用户令牌失败: %w", err)
 	}
+	ps.updateTokenIndex(existingTokens, nil)
+	ps.notifyUserTokensChanged(metaId)
+
+	if existingTokens != nil {
+		for platform := range existingTokens.Tokens {
+			ps.recordTokenEventLocked(platform, TokenEventRemoval)
+		}
+	}
 
 	log.Printf("🗑️ 已删除用户所有令牌: MetaID=%s", metaId)
 	return nil
@@ -491,7 +804,13 @@ func (ps *PebbleService) DeleteUserTokens(metaId string) error {
 func (ps *PebbleService) SaveDeviceInfo(deviceInfo *models.DeviceInfo) error {
 	ps.mu.RLock()
 	defer ps.mu.RUnlock()
+	defer ps.keyLocks.lock(deviceLockKey(deviceInfo.DeviceID))()
 
+	return ps.saveDeviceInfoLocked(deviceInfo)
+}
+
+// saveDeviceInfoLocked 保存设备信息，调用方需持有 ps.mu 读锁和该设备的键锁
+func (ps *PebbleService) saveDeviceInfoLocked(deviceInfo *models.DeviceInfo) error {
 	if deviceInfo.DeviceID == "" {
 		return fmt.Errorf("DeviceID 不能为空")
 	}
@@ -574,13 +893,20 @@ func (ps *PebbleService) UpdateDeviceInfo(deviceInfo *models.DeviceInfo) error {
 
 // DeleteDeviceInfo 删除设备信息
 func (ps *PebbleService) DeleteDeviceInfo(deviceId string) error {
-	ps.mu.RLock()
-	defer ps.mu.RUnlock()
-
 	if deviceId == "" {
 		return fmt.Errorf("DeviceID 不能为空")
 	}
 
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+	defer ps.keyLocks.lock(deviceLockKey(deviceId))()
+
+	return ps.deleteDeviceInfoLocked(deviceId)
+}
+
+// deleteDeviceInfoLocked 删除设备信息，调用方需持有 ps.mu 读锁和该设备的键锁
+func (ps *PebbleService) deleteDeviceInfoLocked(deviceId string) error {
+
 	// 获取设备集合的数据库
 	db, err := ps.getCollectionDB(CollectionDevices)
 	if err != nil {
@@ -602,20 +928,28 @@ func (ps *PebbleService) SetDeviceInfo(deviceId, platform, metaId string) error
 		return fmt.Errorf("DeviceID、Platform 和 MetaID 都不能为空")
 	}
 
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
 	// 检查设备是否已存在
-	existingDevice, err := ps.GetDeviceInfo(deviceId)
-	if err == nil {
+	existingDevice, unlock, err := ps.lockDeviceAndOwners(deviceId, metaId)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if existingDevice != nil {
 		// 设备存在，检查是否需要更新
 		if existingDevice.MetaID != metaId {
 			log.Printf("⚠️ 设备 %s 的 MetaID 从 %s 更改为 %s", deviceId, existingDevice.MetaID, metaId)
 
 			// 需要从旧用户的令牌中移除该设备的令牌
-			oldUserTokens, err := ps.GetUserTokens(existingDevice.MetaID)
-			if err == nil && oldUserTokens.Tokens != nil {
+			oldUserTokens, err := ps.loadUserTokens(existingDevice.MetaID)
+			if err == nil && oldUserTokens != nil && oldUserTokens.Tokens != nil {
 				// 移除旧用户在该平台的令牌（如果该令牌对应这个设备）
 				if _, exists := oldUserTokens.Tokens[platform]; exists {
 					delete(oldUserTokens.Tokens, platform)
-					if err := ps.SaveUserTokens(oldUserTokens); err != nil {
+					if err := ps.saveUserTokensLocked(oldUserTokens); err != nil {
 						log.Printf("⚠️ 更新旧用户令牌失败: %v", err)
 					} else {
 						log.Printf("✅ 已从旧用户 %s 中移除平台 %s 的令牌", existingDevice.MetaID, platform)
@@ -627,7 +961,7 @@ func (ps *PebbleService) SetDeviceInfo(deviceId, platform, metaId string) error
 		// 更新设备信息
 		existingDevice.Platform = platform
 		existingDevice.MetaID = metaId
-		return ps.SaveDeviceInfo(existingDevice)
+		return ps.saveDeviceInfoLocked(existingDevice)
 	}
 
 	// 设备不存在，创建新的设备信息
@@ -638,21 +972,28 @@ func (ps *PebbleService) SetDeviceInfo(deviceId, platform, metaId string) error
 		UpdatedAt: time.Now().Unix(),
 	}
 
-	return ps.SaveDeviceInfo(deviceInfo)
+	return ps.saveDeviceInfoLocked(deviceInfo)
 }
 
-// GetAllUserTokens 获取多个用户的推送令牌
+// GetAllUserTokens 获取多个用户的推送令牌，不存在或读取失败的用户返回空的令牌记录
+// 用户较多时（如大群）分段并发读取，见 readUserTokensBulk
 func (ps *PebbleService) GetAllUserTokens(metaIds []string) (map[string]*models.UserPushTokens, error) {
 	if len(metaIds) == 0 {
 		return make(map[string]*models.UserPushTokens), nil
 	}
 
-	result := make(map[string]*models.UserPushTokens)
+	bulk, err := ps.readUserTokensBulk(metaIds)
+	if err != nil {
+		return nil, err
+	}
 
+	result := make(map[string]*models.UserPushTokens, len(metaIds))
 	for _, metaId := range metaIds {
-		userTokens, err := ps.GetUserTokens(metaId)
-		if err != nil {
-			log.Printf("⚠️ 获取用户 %s 的令牌失败: %v", metaId, err)
+		userTokens := bulk.found[metaId]
+		if userTokens == nil {
+			if err := bulk.failed[metaId]; err != nil {
+				log.Printf("⚠️ 获取用户 %s 的令牌失败: %v", metaId, err)
+			}
 			// 创建空的令牌记录
 			userTokens = &models.UserPushTokens{
 				MetaID:    metaId,
@@ -712,8 +1053,8 @@ func InitializeGlobalService(config *Config) error {
 		config = DefaultConfig()
 	}
 
-	// 如果全局服务已存在且已初始化，直接返回
-	if globalService != nil && globalService.IsInitialized() {
+	// 如果全局服务已存在且未关闭，直接返回；已关闭的服务按新配置重新创建
+	if globalService != nil && globalService.IsInitialized() && !globalService.closed.Load() {
 		log.Printf("⚠️ 全局 Pebble 服务已存在，跳过重复初始化")
 		return nil
 	}
@@ -836,16 +1177,44 @@ func DeleteDeviceInfoGlobal(deviceId string) error {
 }
 
 // PaginatedUserTokens 分页的用户令牌结果
+// 游标分页时不统计总数，Total、Page、TotalPages 为 0，通过 NextCursor 继续读取
 type PaginatedUserTokens struct {
-	Users      []*models.UserPushTokens `json:"users"`      // 用户令牌列表
-	Total      int                      `json:"total"`      // 总数量
-	Page       int                      `json:"page"`       // 当前页码
-	PageSize   int                      `json:"pageSize"`   // 每页大小
-	TotalPages int                      `json:"totalPages"` // 总页数
-	HasNext    bool                     `json:"hasNext"`    // 是否有下一页
+	Users      []*models.UserPushTokens `json:"users"`                // 用户令牌列表
+	Total      int                      `json:"total"`                // 总数量
+	Page       int                      `json:"page"`                 // 当前页码
+	PageSize   int                      `json:"pageSize"`             // 每页大小
+	TotalPages int                      `json:"totalPages"`           // 总页数
+	HasNext    bool                     `json:"hasNext"`              // 是否有下一页
+	NextCursor string                   `json:"nextCursor,omitempty"` // 下一页游标（有下一页时返回）
 }
 
-// GetUserTokensList 获取用户推送令牌列表（支持分页）
+// normalizePageSize 限制每页大小，默认 10，最大 100
+func normalizePageSize(pageSize int) int {
+	if pageSize < 1 {
+		return 10
+	}
+	if pageSize > 100 {
+		return 100 // 限制最大页面大小
+	}
+	return pageSize
+}
+
+// EncodeTokensCursor 将当前页最后遍历的键（无筛选时即 metaId）编码为不透明的游标
+func EncodeTokensCursor(metaId string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(metaId))
+}
+
+// DecodeTokensCursor 解析游标，空游标表示从头开始
+func DecodeTokensCursor(cursor string) (string, error) {
+	metaId, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return "", fmt.Errorf("游标无效: %s", cursor)
+	}
+	return string(metaId), nil
+}
+
+// GetUserTokensList 获取用户推送令牌列表（页码分页）
+// 按 metaId 顺序跳过前面的页，只解析当前页的记录；总数通过遍历键统计，不加载全部记录
 func (ps *PebbleService) GetUserTokensList(page, pageSize int) (*PaginatedUserTokens, error) {
 	ps.mu.RLock()
 	defer ps.mu.RUnlock()
@@ -853,12 +1222,7 @@ func (ps *PebbleService) GetUserTokensList(page, pageSize int) (*PaginatedUserTo
 	if page < 1 {
 		page = 1
 	}
-	if pageSize < 1 {
-		pageSize = 10
-	}
-	if pageSize > 100 {
-		pageSize = 100 // 限制最大页面大小
-	}
+	pageSize = normalizePageSize(pageSize)
 
 	// 获取用户令牌集合的数据库
 	db, err := ps.getCollectionDB(CollectionUserTokens)
@@ -866,68 +1230,49 @@ func (ps *PebbleService) GetUserTokensList(page, pageSize int) (*PaginatedUserTo
 		return nil, fmt.Errorf("获取用户令牌集合数据库失败: %w", err)
 	}
 
-	// 创建迭代器
 	iter, err := db.NewIter(nil)
 	if err != nil {
 		return nil, fmt.Errorf("创建迭代器失败: %w", err)
 	}
 	defer iter.Close()
 
-	var allUsers []*models.UserPushTokens
-
-	// 遍历所有用户令牌
+	startIndex := (page - 1) * pageSize
+	pageUsers := []*models.UserPushTokens{}
+	total := 0
 	for iter.First(); iter.Valid(); iter.Next() {
-		// 解析值
+		index := total
+		total++
+		if index < startIndex || index >= startIndex+pageSize {
+			continue
+		}
+
 		var userTokens models.UserPushTokens
 		if err := json.Unmarshal(iter.Value(), &userTokens); err != nil {
 			log.Printf("⚠️ 跳过解析失败的记录: %s, 错误: %v", string(iter.Key()), err)
 			continue
 		}
-
-		allUsers = append(allUsers, &userTokens)
+		pageUsers = append(pageUsers, &userTokens)
 	}
-
 	if err := iter.Error(); err != nil {
 		return nil, fmt.Errorf("迭代器错误: %w", err)
 	}
 
-	// 计算分页
-	total := len(allUsers)
 	totalPages := (total + pageSize - 1) / pageSize
-	startIndex := (page - 1) * pageSize
-	endIndex := startIndex + pageSize
-
-	if startIndex >= total {
-		// 页码超出范围，返回空结果
-		return &PaginatedUserTokens{
-			Users:      []*models.UserPushTokens{},
-			Total:      total,
-			Page:       page,
-			PageSize:   pageSize,
-			TotalPages: totalPages,
-			HasNext:    false,
-		}, nil
-	}
-
-	if endIndex > total {
-		endIndex = total
-	}
-
-	// 获取当前页的数据
-	pageUsers := allUsers[startIndex:endIndex]
-	hasNext := page < totalPages
-
-	log.Printf("📖 已获取用户令牌列表: 第%d页/%d页, 每页%d条, 当前页%d条, 总共%d条",
-		page, totalPages, pageSize, len(pageUsers), total)
-
-	return &PaginatedUserTokens{
+	result := &PaginatedUserTokens{
 		Users:      pageUsers,
 		Total:      total,
 		Page:       page,
 		PageSize:   pageSize,
 		TotalPages: totalPages,
-		HasNext:    hasNext,
-	}, nil
+		HasNext:    page < totalPages,
+	}
+	if result.HasNext && len(pageUsers) > 0 {
+		result.NextCursor = EncodeTokensCursor(pageUsers[len(pageUsers)-1].MetaID)
+	}
+
+	log.Printf("📖 已获取用户令牌列表: 第%d页/%d页, 每页%d条, 当前页%d条, 总共%d条",
+		page, totalPages, pageSize, len(pageUsers), total)
+	return result, nil
 }
 
 // GetUserTokensListGlobal 全局方法：获取用户推送令牌列表（支持分页）
@@ -942,6 +1287,18 @@ func GetUserTokensListGlobal(page, pageSize int) (*PaginatedUserTokens, error) {
 	return service.GetUserTokensList(page, pageSize)
 }
 
+// GetUserTokensAfterGlobal 全局方法：获取用户推送令牌列表（游标分页，支持筛选）
+func GetUserTokensAfterGlobal(filter UserTokensFilter, cursor string, pageSize int) (*PaginatedUserTokens, error) {
+	service := GetGlobalService()
+	if service == nil {
+		return nil, fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return nil, fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.GetUserTokensAfter(filter, cursor, pageSize)
+}
+
 // CollectionInfo 集合信息
 type CollectionInfo struct {
 	Name  string `json:"name"`  // 集合名称
@@ -962,6 +1319,15 @@ func (ps *PebbleService) ListCollections() ([]*CollectionInfo, error) {
 		CollectionDevices,
 		CollectionBlockedChats,
 		CollectionNotifiedPins,
+		CollectionTenantHooks,
+		CollectionIdempotency,
+		CollectionScheduled,
+		CollectionTokenMetrics,
+		CollectionThrottle,
+		CollectionQAAccounts,
+		CollectionQAInbox,
+		CollectionPreferences,
+		CollectionTranslations,
 	}
 
 	var result []*CollectionInfo
@@ -1096,187 +1462,13 @@ func GetCollectionSizeGlobal(collectionName string) (int, error) {
 	return service.GetCollectionSize(collectionName)
 }
 
-// ===== 屏蔽聊天相关方法 =====
-
-// AddBlockedChat 添加屏蔽聊天
-func (ps *PebbleService) AddBlockedChat(userId, chatId, chatType, reason string) error {
-	ps.mu.RLock()
-	defer ps.mu.RUnlock()
-
-	if userId == "" || chatId == "" {
-		return fmt.Errorf("UserID 和 ChatID 不能为空")
-	}
-
-	// 获取屏蔽聊天集合的数据库
-	db, err := ps.getCollectionDB(CollectionBlockedChats)
-	if err != nil {
-		return fmt.Errorf("获取屏蔽聊天集合数据库失败: %w", err)
-	}
-
-	// 获取用户现有的屏蔽列表
-	userBlockedChats, err := ps.getUserBlockedChatsFromDB(db, userId)
-	if err != nil {
-		return fmt.Errorf("获取用户屏蔽列表失败: %w", err)
-	}
-
-	// 检查是否已经屏蔽过该聊天
-	for _, blockedChat := range userBlockedChats.BlockedChats {
-		if blockedChat.ChatID == chatId {
-			log.Printf("⚠️ 用户 %s 已经屏蔽了聊天 %s", userId, chatId)
-			return nil // 已经屏蔽，直接返回成功
-		}
-	}
-
-	// 添加新的屏蔽聊天
-	newBlockedChat := models.BlockedChat{
-		UserID:    userId,
-		ChatID:    chatId,
-		ChatType:  chatType,
-		BlockedAt: time.Now().Unix(),
-		Reason:    reason,
-	}
-
-	userBlockedChats.BlockedChats = append(userBlockedChats.BlockedChats, newBlockedChat)
-	userBlockedChats.UpdatedAt = time.Now().Unix()
-
-	// 序列化为 JSON
-	data, err := json.Marshal(userBlockedChats)
-	if err != nil {
-		return fmt.Errorf("序列化用户屏蔽列表失败: %w", err)
-	}
-
-	// 保存到数据库
-	key := getUserBlockedChatsKey(userId)
-	if err := db.Set(key, data, pebble.Sync); err != nil {
-		return fmt.Errorf("保存用户屏蔽列表失败: %w", err)
-	}
-
-	log.Printf("✅ 已添加屏蔽聊天: UserID=%s, ChatID=%s, ChatType=%s", userId, chatId, chatType)
-	return nil
-}
-
-// IsBlockedChat 检查聊天是否被屏蔽
-func (ps *PebbleService) IsBlockedChat(userId, chatId string) (bool, error) {
-	ps.mu.RLock()
-	defer ps.mu.RUnlock()
-
-	if userId == "" || chatId == "" {
-		return false, fmt.Errorf("UserID 和 ChatID 不能为空")
-	}
-
-	// 获取屏蔽聊天集合的数据库
-	db, err := ps.getCollectionDB(CollectionBlockedChats)
-	if err != nil {
-		return false, fmt.Errorf("获取屏蔽聊天集合数据库失败: %w", err)
-	}
-
-	// 获取用户屏蔽列表
-	userBlockedChats, err := ps.getUserBlockedChatsFromDB(db, userId)
-	if err != nil {
-		return false, fmt.Errorf("获取用户屏蔽列表失败: %w", err)
-	}
-
-	// 检查是否屏蔽了该聊天
-	for _, blockedChat := range userBlockedChats.BlockedChats {
-		if blockedChat.ChatID == chatId {
-			return true, nil // 已屏蔽
-		}
-	}
-
-	return false, nil // 未屏蔽
-}
-
-// RemoveBlockedChat 移除屏蔽聊天
-func (ps *PebbleService) RemoveBlockedChat(userId, chatId string) error {
-	ps.mu.RLock()
-	defer ps.mu.RUnlock()
-
-	if userId == "" || chatId == "" {
-		return fmt.Errorf("UserID 和 ChatID 不能为空")
-	}
-
-	// 获取屏蔽聊天集合的数据库
-	db, err := ps.getCollectionDB(CollectionBlockedChats)
-	if err != nil {
-		return fmt.Errorf("获取屏蔽聊天集合数据库失败: %w", err)
-	}
-
-	// 获取用户现有的屏蔽列表
-	userBlockedChats, err := ps.getUserBlockedChatsFromDB(db, userId)
-	if err != nil {
-		return fmt.Errorf("获取用户屏蔽列表失败: %w", err)
-	}
-
-	// 查找并移除指定的屏蔽聊天
-	found := false
-	var newBlockedChats []models.BlockedChat
-	for _, blockedChat := range userBlockedChats.BlockedChats {
-		if blockedChat.ChatID != chatId {
-			newBlockedChats = append(newBlockedChats, blockedChat)
-		} else {
-			found = true
-		}
-	}
-
-	if !found {
-		log.Printf("⚠️ 用户 %s 没有屏蔽聊天 %s", userId, chatId)
-		return nil // 没有屏蔽，直接返回成功
-	}
-
-	// 更新屏蔽列表
-	userBlockedChats.BlockedChats = newBlockedChats
-	userBlockedChats.UpdatedAt = time.Now().Unix()
-
-	// 如果列表为空，删除整个记录
-	if len(userBlockedChats.BlockedChats) == 0 {
-		key := getUserBlockedChatsKey(userId)
-		if err := db.Delete(key, pebble.Sync); err != nil {
-			return fmt.Errorf("删除用户屏蔽列表失败: %w", err)
-		}
-	} else {
-		// 序列化为 JSON 并保存
-		data, err := json.Marshal(userBlockedChats)
-		if err != nil {
-			return fmt.Errorf("序列化用户屏蔽列表失败: %w", err)
-		}
-
-		key := getUserBlockedChatsKey(userId)
-		if err := db.Set(key, data, pebble.Sync); err != nil {
-			return fmt.Errorf("保存用户屏蔽列表失败: %w", err)
-		}
-	}
-
-	log.Printf("✅ 已移除屏蔽聊天: UserID=%s, ChatID=%s", userId, chatId)
-	return nil
-}
-
-// GetUserBlockedChats 获取用户的所有屏蔽聊天
-func (ps *PebbleService) GetUserBlockedChats(userId string) (*models.UserBlockedChats, error) {
-	ps.mu.RLock()
-	defer ps.mu.RUnlock()
-
-	if userId == "" {
-		return nil, fmt.Errorf("UserID 不能为空")
-	}
-
-	// 获取屏蔽聊天集合的数据库
-	db, err := ps.getCollectionDB(CollectionBlockedChats)
-	if err != nil {
-		return nil, fmt.Errorf("获取屏蔽聊天集合数据库失败: %w", err)
-	}
-
-	// 获取用户屏蔽列表
-	userBlockedChats, err := ps.getUserBlockedChatsFromDB(db, userId)
-	if err != nil {
-		return nil, fmt.Errorf("获取用户屏蔽列表失败: %w", err)
-	}
+// ===== PIN通知相关方法 =====
 
-	log.Printf("📖 已获取用户屏蔽聊天列表: UserID=%s, 数量=%d", userId, len(userBlockedChats.BlockedChats))
-	return userBlockedChats, nil
+// notifiedPinsRepo 已通知PIN集合存储，键为 pinId
+func (ps *PebbleService) notifiedPinsRepo() *repository[models.NotifiedPin] {
+	return newRepository[models.NotifiedPin](ps, CollectionNotifiedPins, "已通知PIN信息")
 }
 
-// ===== PIN通知相关方法 =====
-
 // AddNotifiedPin 添加已通知的PIN
 func (ps *PebbleService) AddNotifiedPin(pinId string) error {
 	ps.mu.RLock()
@@ -1286,31 +1478,13 @@ func (ps *PebbleService) AddNotifiedPin(pinId string) error {
 		return fmt.Errorf("PinID 不能为空")
 	}
 
-	// 获取已通知PIN集合的数据库
-	db, err := ps.getCollectionDB(CollectionNotifiedPins)
-	if err != nil {
-		return fmt.Errorf("获取已通知PIN集合数据库失败: %w", err)
-	}
-
 	// 创建已通知PIN信息
 	notifiedPin := &models.NotifiedPin{
-		PinID: pinId,
-		// ChatID:      chatId,
-		// UserID:      userId,
+		PinID:      pinId,
 		NotifiedAt: time.Now().Unix(),
-		// MessageHash: messageHash,
-	}
-
-	// 序列化为 JSON
-	data, err := json.Marshal(notifiedPin)
-	if err != nil {
-		return fmt.Errorf("序列化已通知PIN信息失败: %w", err)
 	}
-
-	// 保存到数据库
-	key := getNotifiedPinKey(pinId)
-	if err := db.Set(key, data, pebble.Sync); err != nil {
-		return fmt.Errorf("保存已通知PIN信息失败: %w", err)
+	if err := ps.notifiedPinsRepo().Put(pinId, notifiedPin); err != nil {
+		return err
 	}
 
 	log.Printf("✅ 已添加已通知PIN: PinID=%s", pinId)
@@ -1326,23 +1500,27 @@ func (ps *PebbleService) IsNotifiedPin(pinId string) (bool, error) {
 		return false, fmt.Errorf("PinID 不能为空")
 	}
 
-	// 获取已通知PIN集合的数据库
-	db, err := ps.getCollectionDB(CollectionNotifiedPins)
-	if err != nil {
-		return false, fmt.Errorf("获取已通知PIN集合数据库失败: %w", err)
-	}
+	return ps.notifiedPinsRepo().Has(pinId)
+}
 
-	key := getNotifiedPinKey(pinId)
-	_, closer, err := db.Get(key)
-	if err != nil {
-		if err == pebble.ErrNotFound {
-			return false, nil // 未通知
-		}
-		return false, fmt.Errorf("检查PIN通知状态失败: %w", err)
-	}
-	closer.Close()
+// ScanNotifiedPins 遍历所有已通知PIN记录，fn 返回 false 时停止
+func (ps *PebbleService) ScanNotifiedPins(fn func(pinId string, notifiedAt int64) bool) error {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
 
-	return true, nil // 已通知
+	return ps.notifiedPinsRepo().ScanPrefix("", func(key string, pin *models.NotifiedPin) bool {
+		return fn(pin.PinID, pin.NotifiedAt)
+	})
+}
+
+// PurgeNotifiedPins 删除通知时间早于 before（Unix 秒）的已通知PIN记录，返回删除数量
+func (ps *PebbleService) PurgeNotifiedPins(before int64) (int, error) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	return ps.notifiedPinsRepo().DeleteWhere("", func(key string, pin *models.NotifiedPin) bool {
+		return pin.NotifiedAt < before
+	})
 }
 
 // RemoveNotifiedPin 移除已通知PIN记录
@@ -1354,15 +1532,8 @@ func (ps *PebbleService) RemoveNotifiedPin(pinId string) error {
 		return fmt.Errorf("PinID 不能为空")
 	}
 
-	// 获取已通知PIN集合的数据库
-	db, err := ps.getCollectionDB(CollectionNotifiedPins)
-	if err != nil {
-		return fmt.Errorf("获取已通知PIN集合数据库失败: %w", err)
-	}
-
-	key := getNotifiedPinKey(pinId)
-	if err := db.Delete(key, pebble.Sync); err != nil {
-		return fmt.Errorf("移除已通知PIN失败: %w", err)
+	if err := ps.notifiedPinsRepo().Delete(pinId); err != nil {
+		return err
 	}
 
 	log.Printf("✅ 已移除已通知PIN: PinID=%s", pinId)
@@ -1372,6 +1543,8 @@ func (ps *PebbleService) RemoveNotifiedPin(pinId string) error {
 // PebbleTokenStore 基于 Pebble 的用户令牌存储实现
 type PebbleTokenStore struct {
 	service *PebbleService
+	cache   *tokenCache     // 可选的 LRU 缓存，nil 表示不缓存
+	hot     *hotUserTracker // 可选的最近活跃用户记录，nil 表示不记录
 }
 
 // NewPebbleTokenStore 创建基于 Pebble 的令牌存储
@@ -1397,31 +1570,48 @@ func NewGlobalPebbleTokenStore() *PebbleTokenStore {
 	}
 }
 
-// convertToServiceUserTokens 将 models.UserPushTokens 转换为 push_service.UserPushTokens
-func convertToServiceUserTokens(modelTokens *models.UserPushTokens) *push_service.UserPushTokens {
-	return &push_service.UserPushTokens{
-		MetaID:    modelTokens.MetaID,
-		Tokens:    modelTokens.Tokens,
-		UpdatedAt: time.Unix(modelTokens.UpdatedAt, 0),
+// EnableCache 启用令牌 LRU 缓存
+// 令牌写入或删除（无论经由本存储还是直接调用 PebbleService）都会使对应用户的缓存失效
+func (pts *PebbleTokenStore) EnableCache(size int, ttl time.Duration) {
+	cache := newTokenCache(size, ttl)
+	pts.service.OnUserTokensChanged(cache.invalidate)
+	pts.cache = cache
+	log.Printf("✅ 令牌缓存已启用: 容量=%d, 过期时间=%v", cache.size, cache.ttl)
+}
+
+// PurgeCache 清空令牌缓存（如接管数据库后，其他实例可能已修改令牌）
+func (pts *PebbleTokenStore) PurgeCache() {
+	if pts.cache != nil {
+		pts.cache.purge()
 	}
 }
 
-// convertFromServiceUserTokens 将 push_service.UserPushTokens 转换为 models.UserPushTokens
-func convertFromServiceUserTokens(serviceTokens *push_service.UserPushTokens) *models.UserPushTokens {
-	return &models.UserPushTokens{
-		MetaID:    serviceTokens.MetaID,
-		Tokens:    serviceTokens.Tokens,
-		UpdatedAt: serviceTokens.UpdatedAt.Unix(),
+// CacheLen 返回令牌缓存中的条目数，未启用缓存时返回 0
+func (pts *PebbleTokenStore) CacheLen() int {
+	if pts.cache == nil {
+		return 0
 	}
+	return pts.cache.len()
 }
 
 // GetUserTokens 根据metaId获取用户的所有推送令牌 (实现 UserTokenStore 接口)
-func (pts *PebbleTokenStore) GetUserTokens(ctx context.Context, metaId string) (*push_service.UserPushTokens, error) {
-	modelTokens, err := pts.service.GetUserTokens(metaId)
+func (pts *PebbleTokenStore) GetUserTokens(ctx context.Context, metaId string) (*models.UserPushTokens, error) {
+	pts.hot.touch(metaId)
+	if pts.cache == nil {
+		return pts.service.GetUserTokens(metaId)
+	}
+
+	if cached := pts.cache.get(metaId); cached != nil {
+		return cached, nil
+	}
+
+	generation := pts.cache.currentGeneration()
+	userTokens, err := pts.service.GetUserTokens(metaId)
 	if err != nil {
 		return nil, err
 	}
-	return convertToServiceUserTokens(modelTokens), nil
+	pts.cache.put(metaId, userTokens, generation)
+	return userTokens, nil
 }
 
 // SetUserToken 设置用户在指定平台的推送令牌 (实现 UserTokenStore 接口)
@@ -1436,16 +1626,54 @@ func (pts *PebbleTokenStore) RemoveUserToken(ctx context.Context, metaId string,
 }
 
 // GetAllUserTokens 获取所有用户的令牌 (实现 UserTokenStore 接口)
-func (pts *PebbleTokenStore) GetAllUserTokens(ctx context.Context, metaIds []string) (map[string]*push_service.UserPushTokens, error) {
-	modelTokensMap, err := pts.service.GetAllUserTokens(metaIds)
-	if err != nil {
-		return nil, err
+func (pts *PebbleTokenStore) GetAllUserTokens(ctx context.Context, metaIds []string) (map[string]*models.UserPushTokens, error) {
+	for _, metaId := range metaIds {
+		pts.hot.touch(metaId)
+	}
+
+	if pts.cache == nil {
+		return pts.service.GetAllUserTokens(metaIds)
+	}
+
+	// 先从缓存读取，只有未命中的用户才批量访问 Pebble；读取失败的用户不写入缓存
+	result := make(map[string]*models.UserPushTokens)
+	hits := 0
+	var misses []string
+	for _, metaId := range metaIds {
+		if cached := pts.cache.get(metaId); cached != nil {
+			result[metaId] = cached
+			hits++
+			continue
+		}
+		misses = append(misses, metaId)
 	}
 
-	result := make(map[string]*push_service.UserPushTokens)
-	for metaId, modelTokens := range modelTokensMap {
-		result[metaId] = convertToServiceUserTokens(modelTokens)
+	if len(misses) > 0 {
+		generation := pts.cache.currentGeneration()
+		bulk, err := pts.service.readUserTokensBulk(misses)
+		if err != nil {
+			return nil, err
+		}
+		now := time.Now().Unix()
+		for _, metaId := range misses {
+			if err := bulk.failed[metaId]; err != nil {
+				log.Printf("⚠️ 获取用户 %s 的令牌失败: %v", metaId, err)
+				result[metaId] = &models.UserPushTokens{
+					MetaID:    metaId,
+					Tokens:    make(map[string]string),
+					UpdatedAt: now,
+				}
+				continue
+			}
+			userTokens := bulk.found[metaId]
+			if userTokens == nil {
+				userTokens = &models.UserPushTokens{MetaID: metaId, Tokens: make(map[string]string), UpdatedAt: now}
+			}
+			pts.cache.put(metaId, userTokens, generation)
+			result[metaId] = userTokens
+		}
 	}
 
+	log.Printf("📖 已获取 %d 个用户的令牌（缓存命中 %d）", len(result), hits)
 	return result, nil
 }
@@ -0,0 +1,115 @@
+package pebble_service
+
+import (
+	"fmt"
+	"log"
+	"push-base-service/models"
+	"time"
+)
+
+// preferencesRepo 用户偏好集合存储，键为 metaId
+func (ps *PebbleService) preferencesRepo() *repository[models.UserPreferences] {
+	return newRepository[models.UserPreferences](ps, CollectionPreferences, "用户偏好")
+}
+
+// SaveUserPreferences 保存用户推送偏好
+func (ps *PebbleService) SaveUserPreferences(preferences *models.UserPreferences) error {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	if preferences == nil || preferences.MetaID == "" {
+		return fmt.Errorf("MetaID 不能为空")
+	}
+
+	preferences.UpdatedAt = time.Now().Unix()
+	if err := ps.preferencesRepo().Put(preferences.MetaID, preferences); err != nil {
+		return err
+	}
+
+	log.Printf("✅ 已保存用户偏好: MetaID=%s, Locale=%s, TranslatePreviews=%v",
+		preferences.MetaID, preferences.Locale, preferences.TranslatePreviews)
+	return nil
+}
+
+// GetUserPreferences 获取用户推送偏好，不存在时返回 nil
+func (ps *PebbleService) GetUserPreferences(metaId string) (*models.UserPreferences, error) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	if metaId == "" {
+		return nil, fmt.Errorf("MetaID 不能为空")
+	}
+
+	return ps.preferencesRepo().Get(metaId)
+}
+
+// GetUserPreferencesBatch 批量获取用户推送偏好，只返回已设置偏好的用户
+func (ps *PebbleService) GetUserPreferencesBatch(metaIds []string) (map[string]*models.UserPreferences, error) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	repo := ps.preferencesRepo()
+	result := make(map[string]*models.UserPreferences)
+	for _, metaId := range metaIds {
+		if metaId == "" {
+			continue
+		}
+		preferences, err := repo.Get(metaId)
+		if err != nil {
+			log.Printf("⚠️ 获取用户 %s 的偏好失败: %v", metaId, err)
+			continue
+		}
+		if preferences != nil {
+			result[metaId] = preferences
+		}
+	}
+	return result, nil
+}
+
+// DeleteUserPreferences 删除用户推送偏好，不存在时不报错
+func (ps *PebbleService) DeleteUserPreferences(metaId string) error {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	if metaId == "" {
+		return fmt.Errorf("MetaID 不能为空")
+	}
+
+	return ps.preferencesRepo().Delete(metaId)
+}
+
+// SaveUserPreferences 全局方法：保存用户推送偏好
+func SaveUserPreferences(preferences *models.UserPreferences) error {
+	service := GetGlobalService()
+	if service == nil {
+		return fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.SaveUserPreferences(preferences)
+}
+
+// GetUserPreferences 全局方法：获取用户推送偏好
+func GetUserPreferences(metaId string) (*models.UserPreferences, error) {
+	service := GetGlobalService()
+	if service == nil {
+		return nil, fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return nil, fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.GetUserPreferences(metaId)
+}
+
+// GetUserPreferencesBatch 全局方法：批量获取用户推送偏好
+func GetUserPreferencesBatch(metaIds []string) (map[string]*models.UserPreferences, error) {
+	service := GetGlobalService()
+	if service == nil {
+		return nil, fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return nil, fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.GetUserPreferencesBatch(metaIds)
+}
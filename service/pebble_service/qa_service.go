@@ -0,0 +1,385 @@
+package pebble_service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"push-base-service/models"
+	"push-base-service/service/push_service"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultQAInboxLimit 每个 QA 账号虚拟收件箱默认保留的消息数
+const DefaultQAInboxLimit = 200
+
+var (
+	// qaInboxMu 保证收件箱"写入-裁剪"的原子性
+	qaInboxMu sync.Mutex
+	// qaInboxSeq 同一毫秒内的消息序号，保证消息ID唯一且有序
+	qaInboxSeq atomic.Uint64
+)
+
+// qaAccountsRepo QA 账号集合存储，键为 metaId
+func (ps *PebbleService) qaAccountsRepo() *repository[models.QAAccount] {
+	return newRepository[models.QAAccount](ps, CollectionQAAccounts, "QA账号")
+}
+
+// qaInboxRepo QA 收件箱集合存储（按配置压缩）
+func (ps *PebbleService) qaInboxRepo() *repository[models.QAInboxMessage] {
+	return newRepository[models.QAInboxMessage](ps, CollectionQAInbox, "收件箱消息")
+}
+
+// getQAInboxPrefix 生成用户收件箱的键前缀
+func getQAInboxPrefix(metaId string) string {
+	return metaId + ":"
+}
+
+// getQAInboxKey 生成收件箱消息的键
+func getQAInboxKey(metaId, messageId string) string {
+	return getQAInboxPrefix(metaId) + messageId
+}
+
+// newQAInboxMessageID 生成按时间递增的消息ID
+func newQAInboxMessageID(now time.Time) string {
+	return fmt.Sprintf("%020d-%06d", now.UnixMilli(), qaInboxSeq.Add(1)%1000000)
+}
+
+// ===== QA 账号 =====
+
+// SaveQAAccount 添加或更新 QA 账号
+func (ps *PebbleService) SaveQAAccount(metaId, note string) (*models.QAAccount, error) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	if metaId == "" {
+		return nil, fmt.Errorf("MetaID 不能为空")
+	}
+
+	account := &models.QAAccount{
+		MetaID:    metaId,
+		Note:      note,
+		CreatedAt: time.Now().Unix(),
+	}
+	if err := ps.qaAccountsRepo().Put(metaId, account); err != nil {
+		return nil, err
+	}
+
+	log.Printf("🧪 已设置QA账号: MetaID=%s", metaId)
+	return account, nil
+}
+
+// IsQAAccount 判断用户是否为 QA 账号
+func (ps *PebbleService) IsQAAccount(metaId string) (bool, error) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	if metaId == "" {
+		return false, nil
+	}
+
+	return ps.qaAccountsRepo().Has(metaId)
+}
+
+// RemoveQAAccount 移除 QA 账号，收件箱中的消息一并清空
+func (ps *PebbleService) RemoveQAAccount(metaId string) error {
+	if metaId == "" {
+		return fmt.Errorf("MetaID 不能为空")
+	}
+
+	if _, err := ps.ClearQAInbox(metaId); err != nil {
+		return err
+	}
+
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	if err := ps.qaAccountsRepo().Delete(metaId); err != nil {
+		return err
+	}
+
+	log.Printf("🗑️ 已移除QA账号: MetaID=%s", metaId)
+	return nil
+}
+
+// ListQAAccounts 列出所有 QA 账号
+func (ps *PebbleService) ListQAAccounts() ([]*models.QAAccount, error) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	accounts := []*models.QAAccount{}
+	err := ps.qaAccountsRepo().ScanPrefix("", func(key string, account *models.QAAccount) bool {
+		accounts = append(accounts, account)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return accounts, nil
+}
+
+// ===== QA 虚拟收件箱 =====
+
+// AddQAInboxMessage 写入一条收件箱消息，超过 limit 时丢弃最早的消息
+func (ps *PebbleService) AddQAInboxMessage(message *models.QAInboxMessage, limit int) error {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	if message == nil || message.MetaID == "" {
+		return fmt.Errorf("收件箱消息和 MetaID 不能为空")
+	}
+	if limit <= 0 {
+		limit = DefaultQAInboxLimit
+	}
+	if skipNonCriticalWrite(CollectionQAInbox) {
+		return nil
+	}
+
+	repo := ps.qaInboxRepo()
+
+	qaInboxMu.Lock()
+	defer qaInboxMu.Unlock()
+
+	now := time.Now()
+	if message.ID == "" {
+		message.ID = newQAInboxMessageID(now)
+	}
+	if message.ReceivedAt == 0 {
+		message.ReceivedAt = now.UnixMilli()
+	}
+
+	if err := repo.Put(getQAInboxKey(message.MetaID, message.ID), message); err != nil {
+		return err
+	}
+
+	// 裁剪超出保留数量的旧消息：从最新消息往前数，第 limit 条之前的全部删除
+	prefix := getQAInboxPrefix(message.MetaID)
+	count := 0
+	oldestKept := ""
+	err := repo.ScanPrefixReverse(prefix, func(key string, _ *models.QAInboxMessage) bool {
+		count++
+		if count == limit {
+			oldestKept = key
+			return false
+		}
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	if oldestKept == "" {
+		return nil
+	}
+
+	_, err = repo.DeleteWhere(prefix, func(key string, _ *models.QAInboxMessage) bool {
+		return key < oldestKept
+	})
+	return err
+}
+
+// GetQAInboxMessages 获取用户收件箱中接收时间晚于 since（Unix 毫秒）的消息，按接收时间升序
+// limit 大于 0 时只返回最新的 limit 条
+func (ps *PebbleService) GetQAInboxMessages(metaId string, since int64, limit int) ([]*models.QAInboxMessage, error) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	if metaId == "" {
+		return nil, fmt.Errorf("MetaID 不能为空")
+	}
+
+	messages := []*models.QAInboxMessage{}
+	err := ps.qaInboxRepo().ScanPrefix(getQAInboxPrefix(metaId), func(key string, message *models.QAInboxMessage) bool {
+		if message.ReceivedAt > since {
+			messages = append(messages, message)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if limit > 0 && len(messages) > limit {
+		messages = messages[len(messages)-limit:]
+	}
+	return messages, nil
+}
+
+// ClearQAInbox 清空用户收件箱，返回清理数量
+func (ps *PebbleService) ClearQAInbox(metaId string) (int, error) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	if metaId == "" {
+		return 0, fmt.Errorf("MetaID 不能为空")
+	}
+
+	qaInboxMu.Lock()
+	defer qaInboxMu.Unlock()
+
+	count, err := ps.qaInboxRepo().DeleteWhere(getQAInboxPrefix(metaId), func(string, *models.QAInboxMessage) bool {
+		return true
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if count > 0 {
+		log.Printf("🧹 已清空QA收件箱: MetaID=%s, 数量=%d", metaId, count)
+	}
+	return count, nil
+}
+
+// MoveQAInbox 将 from 收件箱中的消息迁移到 to 的收件箱（保留消息ID和接收时间），返回迁移数量
+func (ps *PebbleService) MoveQAInbox(from, to string) (int, error) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	if from == "" || to == "" {
+		return 0, fmt.Errorf("MetaID 不能为空")
+	}
+
+	repo := ps.qaInboxRepo()
+
+	qaInboxMu.Lock()
+	defer qaInboxMu.Unlock()
+
+	var messages []*models.QAInboxMessage
+	err := repo.ScanPrefix(getQAInboxPrefix(from), func(key string, message *models.QAInboxMessage) bool {
+		messages = append(messages, message)
+		return true
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for _, message := range messages {
+		message.MetaID = to
+		if err := repo.Put(getQAInboxKey(to, message.ID), message); err != nil {
+			return 0, err
+		}
+	}
+	if _, err := repo.DeleteWhere(getQAInboxPrefix(from), func(string, *models.QAInboxMessage) bool {
+		return true
+	}); err != nil {
+		return 0, err
+	}
+
+	return len(messages), nil
+}
+
+// ===== 虚拟收件箱实现 =====
+
+// PebbleQAInbox 基于 Pebble 的 QA 虚拟收件箱 (实现 push_service.VirtualInbox 接口)
+type PebbleQAInbox struct {
+	service *PebbleService
+	limit   int
+}
+
+// NewPebbleQAInbox 创建基于 Pebble 的虚拟收件箱
+func NewPebbleQAInbox(service *PebbleService, limit int) *PebbleQAInbox {
+	if limit <= 0 {
+		limit = DefaultQAInboxLimit
+	}
+	return &PebbleQAInbox{
+		service: service,
+		limit:   limit,
+	}
+}
+
+// NewGlobalPebbleQAInbox 创建基于全局 Pebble 服务的虚拟收件箱
+func NewGlobalPebbleQAInbox(limit int) *PebbleQAInbox {
+	service := GetGlobalService()
+	if service == nil {
+		log.Printf("❌ 全局 Pebble 服务未初始化，无法创建QA收件箱")
+		return nil
+	}
+	if !service.IsInitialized() {
+		log.Printf("❌ Pebble 服务未正确初始化，无法创建QA收件箱")
+		return nil
+	}
+	return NewPebbleQAInbox(service, limit)
+}
+
+// IsInboxUser 判断用户是否为 QA 账号 (实现 VirtualInbox 接口)
+func (qi *PebbleQAInbox) IsInboxUser(ctx context.Context, metaId string) (bool, error) {
+	return qi.service.IsQAAccount(metaId)
+}
+
+// Deliver 将通知写入用户的虚拟收件箱 (实现 VirtualInbox 接口)
+func (qi *PebbleQAInbox) Deliver(ctx context.Context, metaId string, notification *push_service.PushNotification) error {
+	message := &models.QAInboxMessage{
+		MetaID:   metaId,
+		Title:    notification.Title,
+		Body:     notification.Body,
+		Data:     notification.Data,
+		Sound:    notification.Sound,
+		Badge:    notification.Badge,
+		ImageURL: notification.ImageURL,
+		Priority: notification.Priority,
+	}
+	return qi.service.AddQAInboxMessage(message, qi.limit)
+}
+
+// ===== 全局方法 =====
+
+// SaveQAAccount 全局方法：添加或更新 QA 账号
+func SaveQAAccount(metaId, note string) (*models.QAAccount, error) {
+	service := GetGlobalService()
+	if service == nil {
+		return nil, fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return nil, fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.SaveQAAccount(metaId, note)
+}
+
+// RemoveQAAccount 全局方法：移除 QA 账号
+func RemoveQAAccount(metaId string) error {
+	service := GetGlobalService()
+	if service == nil {
+		return fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.RemoveQAAccount(metaId)
+}
+
+// ListQAAccounts 全局方法：列出所有 QA 账号
+func ListQAAccounts() ([]*models.QAAccount, error) {
+	service := GetGlobalService()
+	if service == nil {
+		return nil, fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return nil, fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.ListQAAccounts()
+}
+
+// GetQAInboxMessages 全局方法：获取 QA 账号收件箱消息
+func GetQAInboxMessages(metaId string, since int64, limit int) ([]*models.QAInboxMessage, error) {
+	service := GetGlobalService()
+	if service == nil {
+		return nil, fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return nil, fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.GetQAInboxMessages(metaId, since, limit)
+}
+
+// ClearQAInbox 全局方法：清空 QA 账号收件箱
+func ClearQAInbox(metaId string) (int, error) {
+	service := GetGlobalService()
+	if service == nil {
+		return 0, fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return 0, fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.ClearQAInbox(metaId)
+}
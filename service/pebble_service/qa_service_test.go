@@ -0,0 +1,58 @@
+package pebble_service
+
+import (
+	"context"
+	"push-base-service/service/push_service"
+	"testing"
+)
+
+func TestQAInboxDeliverAndTrim(t *testing.T) {
+	service := newTestPebbleService(t)
+	inbox := NewPebbleQAInbox(service, 2)
+	ctx := context.Background()
+
+	if isQA, _ := inbox.IsInboxUser(ctx, "qa-user"); isQA {
+		t.Fatal("IsInboxUser() before SaveQAAccount should be false")
+	}
+	if _, err := service.SaveQAAccount("qa-user", "e2e"); err != nil {
+		t.Fatalf("SaveQAAccount() failed, err: %v", err)
+	}
+	if isQA, err := inbox.IsInboxUser(ctx, "qa-user"); err != nil || !isQA {
+		t.Fatalf("IsInboxUser() = %v, %v; want true", isQA, err)
+	}
+
+	for _, title := range []string{"first", "second", "third"} {
+		if err := inbox.Deliver(ctx, "qa-user", &push_service.PushNotification{Title: title, Body: "body"}); err != nil {
+			t.Fatalf("Deliver(%s) failed, err: %v", title, err)
+		}
+	}
+	// 其他账号的消息不应混入
+	if err := inbox.Deliver(ctx, "qa-user2", &push_service.PushNotification{Title: "other"}); err != nil {
+		t.Fatalf("Deliver() failed, err: %v", err)
+	}
+
+	messages, err := service.GetQAInboxMessages("qa-user", 0, 0)
+	if err != nil {
+		t.Fatalf("GetQAInboxMessages() failed, err: %v", err)
+	}
+	if len(messages) != 2 || messages[0].Title != "second" || messages[1].Title != "third" {
+		t.Fatalf("GetQAInboxMessages() = %+v, want [second third]", messages)
+	}
+
+	since, err := service.GetQAInboxMessages("qa-user", messages[1].ReceivedAt, 0)
+	if err != nil || len(since) != 0 {
+		t.Errorf("GetQAInboxMessages(since last) = %d, %v; want 0", len(since), err)
+	}
+
+	if err := service.RemoveQAAccount("qa-user"); err != nil {
+		t.Fatalf("RemoveQAAccount() failed, err: %v", err)
+	}
+	messages, _ = service.GetQAInboxMessages("qa-user", 0, 0)
+	if len(messages) != 0 {
+		t.Errorf("inbox after RemoveQAAccount() has %d messages, want 0", len(messages))
+	}
+	others, _ := service.GetQAInboxMessages("qa-user2", 0, 0)
+	if len(others) != 1 {
+		t.Errorf("qa-user2 inbox has %d messages, want 1", len(others))
+	}
+}
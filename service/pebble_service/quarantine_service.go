@@ -0,0 +1,190 @@
+package pebble_service
+
+import (
+	"fmt"
+	"log"
+	"push-base-service/models"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultQuarantineLimit 隔离集合默认保留的消息数，超出后丢弃最早的消息
+const DefaultQuarantineLimit = 1000
+
+var (
+	// quarantineMu 保证隔离集合"写入-裁剪"的原子性
+	quarantineMu sync.Mutex
+	// quarantineSeq 同一纳秒内写入多条时区分条目ID
+	quarantineSeq atomic.Uint32
+)
+
+// quarantineRepo 隔离消息集合存储
+func (ps *PebbleService) quarantineRepo() *repository[models.QuarantinedMessage] {
+	return newRepository[models.QuarantinedMessage](ps, CollectionQuarantine, "隔离消息")
+}
+
+// AddQuarantinedMessage 写入一条隔离消息，超过 limit 时丢弃最早的消息
+func (ps *PebbleService) AddQuarantinedMessage(message *models.QuarantinedMessage, limit int) error {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	if message == nil {
+		return fmt.Errorf("隔离消息不能为空")
+	}
+	if limit <= 0 {
+		limit = DefaultQuarantineLimit
+	}
+	if skipNonCriticalWrite(CollectionQuarantine) {
+		return nil
+	}
+
+	repo := ps.quarantineRepo()
+
+	quarantineMu.Lock()
+	defer quarantineMu.Unlock()
+
+	now := time.Now()
+	if message.ID == "" {
+		message.ID = fmt.Sprintf("%020d:%05d", now.UnixNano(), quarantineSeq.Add(1)%100000)
+	}
+	if message.QuarantinedAt == 0 {
+		message.QuarantinedAt = now.UnixMilli()
+	}
+	if err := repo.Put(message.ID, message); err != nil {
+		return err
+	}
+
+	// 裁剪超出保留数量的旧消息
+	count := 0
+	oldestKept := ""
+	err := repo.ScanPrefixReverse("", func(key string, _ *models.QuarantinedMessage) bool {
+		count++
+		if count == limit {
+			oldestKept = key
+			return false
+		}
+		return true
+	})
+	if err != nil || oldestKept == "" {
+		return err
+	}
+
+	_, err = repo.DeleteWhere("", func(key string, _ *models.QuarantinedMessage) bool {
+		return key < oldestKept
+	})
+	return err
+}
+
+// ListQuarantinedMessages 按隔离时间倒序列出隔离消息，limit 大于 0 时只返回最新的 limit 条
+func (ps *PebbleService) ListQuarantinedMessages(limit int) ([]*models.QuarantinedMessage, error) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	messages := []*models.QuarantinedMessage{}
+	err := ps.quarantineRepo().ScanPrefixReverse("", func(key string, message *models.QuarantinedMessage) bool {
+		messages = append(messages, message)
+		return limit <= 0 || len(messages) < limit
+	})
+	return messages, err
+}
+
+// GetQuarantinedMessage 获取一条隔离消息，不存在时返回 nil
+func (ps *PebbleService) GetQuarantinedMessage(id string) (*models.QuarantinedMessage, error) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	return ps.quarantineRepo().Get(id)
+}
+
+// RemoveQuarantinedMessage 删除一条隔离消息，不存在时不报错
+func (ps *PebbleService) RemoveQuarantinedMessage(id string) error {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	quarantineMu.Lock()
+	defer quarantineMu.Unlock()
+
+	return ps.quarantineRepo().Delete(id)
+}
+
+// ClearQuarantine 清空隔离消息，返回清理数量
+func (ps *PebbleService) ClearQuarantine() (int, error) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	quarantineMu.Lock()
+	defer quarantineMu.Unlock()
+
+	count, err := ps.quarantineRepo().DeleteWhere("", func(string, *models.QuarantinedMessage) bool {
+		return true
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if count > 0 {
+		log.Printf("🧹 已清空隔离消息: 数量=%d", count)
+	}
+	return count, nil
+}
+
+// AddQuarantinedMessage 全局方法：写入隔离消息
+func AddQuarantinedMessage(message *models.QuarantinedMessage, limit int) error {
+	service := GetGlobalService()
+	if service == nil {
+		return fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.AddQuarantinedMessage(message, limit)
+}
+
+// ListQuarantinedMessages 全局方法：列出隔离消息
+func ListQuarantinedMessages(limit int) ([]*models.QuarantinedMessage, error) {
+	service := GetGlobalService()
+	if service == nil {
+		return nil, fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return nil, fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.ListQuarantinedMessages(limit)
+}
+
+// GetQuarantinedMessage 全局方法：获取一条隔离消息
+func GetQuarantinedMessage(id string) (*models.QuarantinedMessage, error) {
+	service := GetGlobalService()
+	if service == nil {
+		return nil, fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return nil, fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.GetQuarantinedMessage(id)
+}
+
+// RemoveQuarantinedMessage 全局方法：删除一条隔离消息
+func RemoveQuarantinedMessage(id string) error {
+	service := GetGlobalService()
+	if service == nil {
+		return fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.RemoveQuarantinedMessage(id)
+}
+
+// ClearQuarantine 全局方法：清空隔离消息
+func ClearQuarantine() (int, error) {
+	service := GetGlobalService()
+	if service == nil {
+		return 0, fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return 0, fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.ClearQuarantine()
+}
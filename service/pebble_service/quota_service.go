@@ -0,0 +1,314 @@
+package pebble_service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"push-base-service/models"
+	"push-base-service/service/push_service"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultQuotaWarnPercents 默认的配额用量告警阈值
+var DefaultQuotaWarnPercents = []int{80, 95}
+
+// tenantQuotasRepo 租户配额集合存储，键为 tenantId
+func (ps *PebbleService) tenantQuotasRepo() *repository[models.TenantQuota] {
+	return newRepository[models.TenantQuota](ps, CollectionTenantQuotas, "租户配额")
+}
+
+// tenantUsageRepo 租户用量集合存储，键为 tenantId:月份
+func (ps *PebbleService) tenantUsageRepo() *repository[models.TenantUsage] {
+	return newRepository[models.TenantUsage](ps, CollectionTenantUsage, "租户用量")
+}
+
+// getTenantUsageKey 生成租户月度用量的键
+func getTenantUsageKey(tenantId, month string) string {
+	return tenantId + ":" + month
+}
+
+// QuotaMonth 返回时间所在的配额月份（UTC），如 2026-01
+func QuotaMonth(t time.Time) string {
+	return t.UTC().Format("2006-01")
+}
+
+// SaveTenantQuota 设置租户每月推送上限，0 表示不限制
+func (ps *PebbleService) SaveTenantQuota(tenantId string, monthlyLimit int64) (*models.TenantQuota, error) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	if tenantId == "" {
+		return nil, fmt.Errorf("TenantID 不能为空")
+	}
+	if monthlyLimit < 0 {
+		return nil, fmt.Errorf("每月推送上限不能为负数")
+	}
+
+	repo := ps.tenantQuotasRepo()
+	quota, err := repo.Get(tenantId)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().Unix()
+	if quota == nil {
+		quota = &models.TenantQuota{TenantID: tenantId, CreatedAt: now}
+	}
+	quota.MonthlyLimit = monthlyLimit
+	quota.UpdatedAt = now
+
+	if err := repo.Put(tenantId, quota); err != nil {
+		return nil, err
+	}
+
+	log.Printf("✅ 已设置租户配额: TenantID=%s, 每月上限=%d", tenantId, monthlyLimit)
+	return quota, nil
+}
+
+// GetTenantQuota 获取租户配额，未单独设置时返回 nil
+func (ps *PebbleService) GetTenantQuota(tenantId string) (*models.TenantQuota, error) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	if tenantId == "" {
+		return nil, fmt.Errorf("TenantID 不能为空")
+	}
+	return ps.tenantQuotasRepo().Get(tenantId)
+}
+
+// ===== 配额检查实现 =====
+
+// PebbleQuotaGuard 基于 Pebble 的租户月度配额 (实现 push_service.QuotaGuard 接口)
+type PebbleQuotaGuard struct {
+	service         *PebbleService
+	defaultLimit    int64      // 未单独设置配额的租户使用的每月上限，0 表示不限制
+	overQuotaAction string     // 超出配额时的处理方式：reject / downgrade
+	warnPercents    []int      // 用量告警阈值（百分比，升序）
+	mu              sync.Mutex // 保证"读取-判断-写入"的原子性
+}
+
+// NewPebbleQuotaGuard 创建基于 Pebble 的租户配额
+func NewPebbleQuotaGuard(service *PebbleService, defaultLimit int64, overQuotaAction string, warnPercents []int) *PebbleQuotaGuard {
+	if overQuotaAction != push_service.QuotaActionDowngrade {
+		overQuotaAction = push_service.QuotaActionReject
+	}
+	if len(warnPercents) == 0 {
+		warnPercents = DefaultQuotaWarnPercents
+	}
+	percents := append([]int(nil), warnPercents...)
+	sort.Ints(percents)
+
+	return &PebbleQuotaGuard{
+		service:         service,
+		defaultLimit:    defaultLimit,
+		overQuotaAction: overQuotaAction,
+		warnPercents:    percents,
+	}
+}
+
+// NewGlobalPebbleQuotaGuard 创建基于全局 Pebble 服务的租户配额
+func NewGlobalPebbleQuotaGuard(defaultLimit int64, overQuotaAction string, warnPercents []int) *PebbleQuotaGuard {
+	service := GetGlobalService()
+	if service == nil {
+		log.Printf("❌ 全局 Pebble 服务未初始化，无法创建租户配额")
+		return nil
+	}
+	if !service.IsInitialized() {
+		log.Printf("❌ Pebble 服务未正确初始化，无法创建租户配额")
+		return nil
+	}
+	return NewPebbleQuotaGuard(service, defaultLimit, overQuotaAction, warnPercents)
+}
+
+// OverQuotaAction 返回超出配额时的处理方式
+func (qg *PebbleQuotaGuard) OverQuotaAction() string {
+	return qg.overQuotaAction
+}
+
+// limitFor 获取租户当前生效的每月上限（调用方持有 ps.mu 读锁）
+func (qg *PebbleQuotaGuard) limitFor(tenantId string) (int64, error) {
+	quota, err := qg.service.tenantQuotasRepo().Get(tenantId)
+	if err != nil {
+		return 0, err
+	}
+	if quota == nil {
+		return qg.defaultLimit, nil
+	}
+	return quota.MonthlyLimit, nil
+}
+
+// Reserve 为租户预占 count 条投递配额 (实现 QuotaGuard 接口)
+func (qg *PebbleQuotaGuard) Reserve(ctx context.Context, tenantId string, count int) (*push_service.QuotaDecision, error) {
+	if tenantId == "" {
+		return nil, fmt.Errorf("TenantID 不能为空")
+	}
+
+	qg.service.mu.RLock()
+	defer qg.service.mu.RUnlock()
+
+	qg.mu.Lock()
+	defer qg.mu.Unlock()
+
+	limit, err := qg.limitFor(tenantId)
+	if err != nil {
+		return nil, err
+	}
+
+	month := QuotaMonth(time.Now())
+	repo := qg.service.tenantUsageRepo()
+	usage, err := repo.Get(getTenantUsageKey(tenantId, month))
+	if err != nil {
+		return nil, err
+	}
+	if usage == nil {
+		usage = &models.TenantUsage{TenantID: tenantId, Month: month}
+	}
+
+	action := push_service.QuotaActionAllow
+	n := int64(count)
+	switch {
+	case limit <= 0 || usage.Sent+n <= limit:
+		usage.Sent += n
+	case qg.overQuotaAction == push_service.QuotaActionDowngrade:
+		action = push_service.QuotaActionDowngrade
+		usage.Sent += n
+		usage.Downgraded += n
+	default:
+		action = push_service.QuotaActionReject
+		usage.Rejected += n
+	}
+
+	usage.Limit = limit
+	usage.UsagePercent = usagePercent(usage.Sent, limit)
+	qg.checkWarning(usage)
+	usage.UpdatedAt = time.Now().Unix()
+
+	if err := repo.Put(getTenantUsageKey(tenantId, month), usage); err != nil {
+		return nil, err
+	}
+
+	return &push_service.QuotaDecision{Action: action, Used: usage.Sent, Limit: limit}, nil
+}
+
+// checkWarning 用量越过告警阈值时输出告警，每个阈值每月只告警一次
+func (qg *PebbleQuotaGuard) checkWarning(usage *models.TenantUsage) {
+	if usage.Limit <= 0 {
+		return
+	}
+
+	crossed := 0
+	for _, percent := range qg.warnPercents {
+		if usage.Sent*100 >= int64(percent)*usage.Limit {
+			crossed = percent
+		}
+	}
+	if crossed > usage.WarnedPercent {
+		usage.WarnedPercent = crossed
+		log.Printf("⚠️ 租户 %s 本月(%s)推送用量已达配额的 %d%%: %d/%d", usage.TenantID, usage.Month, crossed, usage.Sent, usage.Limit)
+	}
+}
+
+// GetUsage 获取租户某月用量，month 为空时取当月；无用量记录时返回零值记录
+func (qg *PebbleQuotaGuard) GetUsage(tenantId, month string) (*models.TenantUsage, error) {
+	if tenantId == "" {
+		return nil, fmt.Errorf("TenantID 不能为空")
+	}
+	if month == "" {
+		month = QuotaMonth(time.Now())
+	}
+
+	qg.service.mu.RLock()
+	defer qg.service.mu.RUnlock()
+
+	usage, err := qg.service.tenantUsageRepo().Get(getTenantUsageKey(tenantId, month))
+	if err != nil {
+		return nil, err
+	}
+	if usage == nil {
+		usage = &models.TenantUsage{TenantID: tenantId, Month: month}
+	}
+
+	// 按当前配置刷新上限，配额调整后立即反映在用量中
+	limit, err := qg.limitFor(tenantId)
+	if err != nil {
+		return nil, err
+	}
+	usage.Limit = limit
+	usage.UsagePercent = usagePercent(usage.Sent, limit)
+	return usage, nil
+}
+
+// ListUsage 列出所有租户某月的用量，month 为空时取当月
+func (qg *PebbleQuotaGuard) ListUsage(month string) ([]*models.TenantUsage, error) {
+	if month == "" {
+		month = QuotaMonth(time.Now())
+	}
+
+	qg.service.mu.RLock()
+	defer qg.service.mu.RUnlock()
+
+	var usages []*models.TenantUsage
+	err := qg.service.tenantUsageRepo().ScanPrefix("", func(key string, usage *models.TenantUsage) bool {
+		if strings.HasSuffix(key, ":"+month) {
+			usages = append(usages, usage)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, usage := range usages {
+		limit, err := qg.limitFor(usage.TenantID)
+		if err != nil {
+			return nil, err
+		}
+		usage.Limit = limit
+		usage.UsagePercent = usagePercent(usage.Sent, limit)
+	}
+	return usages, nil
+}
+
+// usagePercent 计算已用百分比，不限制时为 0
+func usagePercent(sent, limit int64) float64 {
+	if limit <= 0 {
+		return 0
+	}
+	return float64(sent) * 100 / float64(limit)
+}
+
+// ===== 全局方法 =====
+
+var (
+	globalQuotaGuard   *PebbleQuotaGuard
+	globalQuotaGuardMu sync.RWMutex
+)
+
+// SetGlobalQuotaGuard 设置全局租户配额（推送中心启用配额时调用）
+func SetGlobalQuotaGuard(guard *PebbleQuotaGuard) {
+	globalQuotaGuardMu.Lock()
+	defer globalQuotaGuardMu.Unlock()
+	globalQuotaGuard = guard
+}
+
+// GetGlobalQuotaGuard 获取全局租户配额，未启用时返回 nil
+func GetGlobalQuotaGuard() *PebbleQuotaGuard {
+	globalQuotaGuardMu.RLock()
+	defer globalQuotaGuardMu.RUnlock()
+	return globalQuotaGuard
+}
+
+// SaveTenantQuota 全局方法：设置租户每月推送上限
+func SaveTenantQuota(tenantId string, monthlyLimit int64) (*models.TenantQuota, error) {
+	service := GetGlobalService()
+	if service == nil {
+		return nil, fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return nil, fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.SaveTenantQuota(tenantId, monthlyLimit)
+}
@@ -0,0 +1,67 @@
+package pebble_service
+
+import (
+	"context"
+	"push-base-service/service/push_service"
+	"testing"
+)
+
+func TestPebbleQuotaGuardReject(t *testing.T) {
+	service := newTestPebbleService(t)
+	guard := NewPebbleQuotaGuard(service, 10, push_service.QuotaActionReject, nil)
+	ctx := context.Background()
+
+	decision, err := guard.Reserve(ctx, "tenant-a", 8)
+	if err != nil || decision.Action != push_service.QuotaActionAllow || decision.Used != 8 {
+		t.Fatalf("Reserve(8) = %+v, %v; want allow with used 8", decision, err)
+	}
+	decision, _ = guard.Reserve(ctx, "tenant-a", 3)
+	if decision.Action != push_service.QuotaActionReject {
+		t.Errorf("Reserve over the limit = %s, want reject", decision.Action)
+	}
+	decision, _ = guard.Reserve(ctx, "tenant-a", 2)
+	if decision.Action != push_service.QuotaActionAllow || decision.Used != 10 {
+		t.Errorf("Reserve up to the limit = %+v, want allow with used 10", decision)
+	}
+
+	usage, err := guard.GetUsage("tenant-a", "")
+	if err != nil {
+		t.Fatalf("GetUsage() failed, err: %v", err)
+	}
+	if usage.Sent != 10 || usage.Rejected != 3 || usage.Limit != 10 || usage.UsagePercent != 100 {
+		t.Errorf("usage = %+v, want sent 10, rejected 3, 100%%", usage)
+	}
+	if usage.WarnedPercent != 95 {
+		t.Errorf("WarnedPercent = %d, want 95", usage.WarnedPercent)
+	}
+}
+
+func TestPebbleQuotaGuardTenantOverride(t *testing.T) {
+	service := newTestPebbleService(t)
+	guard := NewPebbleQuotaGuard(service, 1, push_service.QuotaActionDowngrade, nil)
+	ctx := context.Background()
+
+	if _, err := service.SaveTenantQuota("unlimited", 0); err != nil {
+		t.Fatalf("SaveTenantQuota() failed, err: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if decision, _ := guard.Reserve(ctx, "unlimited", 5); decision.Action != push_service.QuotaActionAllow {
+			t.Fatalf("tenant with limit 0 should never be limited, got %s", decision.Action)
+		}
+	}
+
+	guard.Reserve(ctx, "default", 1)
+	if decision, _ := guard.Reserve(ctx, "default", 1); decision.Action != push_service.QuotaActionDowngrade {
+		t.Errorf("tenant on the default limit = %s, want downgrade", decision.Action)
+	}
+
+	usages, err := guard.ListUsage("")
+	if err != nil || len(usages) != 2 {
+		t.Fatalf("ListUsage() = %d usages, %v; want 2", len(usages), err)
+	}
+	for _, usage := range usages {
+		if usage.TenantID == "default" && (usage.Sent != 2 || usage.Downgraded != 1) {
+			t.Errorf("default tenant usage = %+v, want sent 2, downgraded 1", usage)
+		}
+	}
+}
@@ -0,0 +1,256 @@
+package pebble_service
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/cockroachdb/pebble"
+)
+
+// Codec 记录值编解码器
+type Codec[T any] interface {
+	Marshal(value *T) ([]byte, error)
+	Unmarshal(data []byte, value *T) error
+}
+
+// JSONCodec JSON 编解码器，集合默认使用
+type JSONCodec[T any] struct{}
+
+// Marshal 序列化为 JSON
+func (JSONCodec[T]) Marshal(value *T) ([]byte, error) {
+	return json.Marshal(value)
+}
+
+// Unmarshal 从 JSON 反序列化
+func (JSONCodec[T]) Unmarshal(data []byte, value *T) error {
+	return json.Unmarshal(data, value)
+}
+
+// repository 单个集合上的类型化存储，统一处理集合获取、编解码、压缩和前缀遍历
+// 方法不获取 ps.mu，由调用方（PebbleService 的方法）持有读锁，便于在同一把锁内组合多个操作
+type repository[T any] struct {
+	ps         *PebbleService
+	collection string
+	label      string // 错误信息中的记录名称，如 "用户偏好"
+	codec      Codec[T]
+}
+
+// newRepository 创建使用 JSON 编解码的集合存储
+func newRepository[T any](ps *PebbleService, collection, label string) *repository[T] {
+	return &repository[T]{
+		ps:         ps,
+		collection: collection,
+		label:      label,
+		codec:      JSONCodec[T]{},
+	}
+}
+
+// db 获取集合数据库
+func (r *repository[T]) db() (*CollectionDB, error) {
+	db, err := r.ps.getCollectionDB(r.collection)
+	if err != nil {
+		return nil, fmt.Errorf("获取%s集合数据库失败: %w", r.label, err)
+	}
+	return db, nil
+}
+
+// decode 解压并反序列化记录值
+func (r *repository[T]) decode(data []byte) (*T, error) {
+	data, err := decodeValue(data)
+	if err != nil {
+		return nil, fmt.Errorf("解压%s失败: %w", r.label, err)
+	}
+
+	var value T
+	if err := r.codec.Unmarshal(data, &value); err != nil {
+		return nil, fmt.Errorf("反序列化%s失败: %w", r.label, err)
+	}
+	return &value, nil
+}
+
+// Get 读取记录，不存在时返回 nil
+func (r *repository[T]) Get(key string) (*T, error) {
+	db, err := r.db()
+	if err != nil {
+		return nil, err
+	}
+
+	data, closer, err := db.Get(buildKey(key))
+	if err != nil {
+		if err == pebble.ErrNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("获取%s失败: %w", r.label, err)
+	}
+	defer closer.Close()
+
+	return r.decode(data)
+}
+
+// Has 判断记录是否存在
+func (r *repository[T]) Has(key string) (bool, error) {
+	db, err := r.db()
+	if err != nil {
+		return false, err
+	}
+
+	_, closer, err := db.Get(buildKey(key))
+	if err != nil {
+		if err == pebble.ErrNotFound {
+			return false, nil
+		}
+		return false, fmt.Errorf("获取%s失败: %w", r.label, err)
+	}
+	closer.Close()
+	return true, nil
+}
+
+// Put 写入记录（按集合配置压缩）
+func (r *repository[T]) Put(key string, value *T) error {
+	db, err := r.db()
+	if err != nil {
+		return err
+	}
+
+	data, err := r.codec.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("序列化%s失败: %w", r.label, err)
+	}
+	if err := db.Set(buildKey(key), r.ps.encodeValue(r.collection, data), pebble.Sync); err != nil {
+		return fmt.Errorf("保存%s失败: %w", r.label, r.ps.wrapDiskFull(err))
+	}
+	return nil
+}
+
+// Delete 删除记录，不存在时不报错
+func (r *repository[T]) Delete(key string) error {
+	db, err := r.db()
+	if err != nil {
+		return err
+	}
+
+	if err := db.Delete(buildKey(key), pebble.Sync); err != nil {
+		return fmt.Errorf("删除%s失败: %w", r.label, err)
+	}
+	return nil
+}
+
+// ScanPrefix 按键顺序遍历以 prefix 开头的记录（prefix 为空时遍历整个集合），fn 返回 false 时停止
+// 解析失败的记录记录日志后跳过
+func (r *repository[T]) ScanPrefix(prefix string, fn func(key string, value *T) bool) error {
+	return r.scan(prefix, false, fn)
+}
+
+// ScanPrefixReverse 按键逆序遍历以 prefix 开头的记录，fn 返回 false 时停止
+func (r *repository[T]) ScanPrefixReverse(prefix string, fn func(key string, value *T) bool) error {
+	return r.scan(prefix, true, fn)
+}
+
+func (r *repository[T]) scan(prefix string, reverse bool, fn func(key string, value *T) bool) error {
+	db, err := r.db()
+	if err != nil {
+		return err
+	}
+
+	iter, err := db.NewIter(prefixIterOptions(prefix))
+	if err != nil {
+		return fmt.Errorf("创建迭代器失败: %w", err)
+	}
+	defer iter.Close()
+
+	valid := iter.First()
+	if reverse {
+		valid = iter.Last()
+	}
+	for ; valid; valid = r.advance(iter, reverse) {
+		value, err := r.decode(iter.Value())
+		if err != nil {
+			log.Printf("⚠️ 跳过解析失败的%s: %s, 错误: %v", r.label, string(iter.Key()), err)
+			continue
+		}
+		if !fn(string(iter.Key()), value) {
+			break
+		}
+	}
+
+	if err := iter.Error(); err != nil {
+		return fmt.Errorf("遍历%s失败: %w", r.label, err)
+	}
+	return nil
+}
+
+func (r *repository[T]) advance(iter *CollectionIter, reverse bool) bool {
+	if reverse {
+		return iter.Prev()
+	}
+	return iter.Next()
+}
+
+// DeleteWhere 批量删除以 prefix 开头且满足条件的记录，解析失败的记录一并清理；返回删除数量
+func (r *repository[T]) DeleteWhere(prefix string, match func(key string, value *T) bool) (int, error) {
+	db, err := r.db()
+	if err != nil {
+		return 0, err
+	}
+
+	iter, err := db.NewIter(prefixIterOptions(prefix))
+	if err != nil {
+		return 0, fmt.Errorf("创建迭代器失败: %w", err)
+	}
+
+	var keys [][]byte
+	for iter.First(); iter.Valid(); iter.Next() {
+		value, err := r.decode(iter.Value())
+		if err != nil {
+			log.Printf("⚠️ %s解析失败，将其清理: %v", r.label, err)
+		} else if !match(string(iter.Key()), value) {
+			continue
+		}
+		keys = append(keys, append([]byte(nil), iter.Key()...))
+	}
+	if err := iter.Error(); err != nil {
+		iter.Close()
+		return 0, fmt.Errorf("遍历%s失败: %w", r.label, err)
+	}
+	iter.Close()
+
+	if len(keys) == 0 {
+		return 0, nil
+	}
+
+	batch := db.NewBatch()
+	defer batch.Close()
+	for _, key := range keys {
+		if err := batch.Delete(key, nil); err != nil {
+			return 0, fmt.Errorf("删除%s失败: %w", r.label, err)
+		}
+	}
+	if err := batch.Commit(pebble.Sync); err != nil {
+		return 0, fmt.Errorf("提交%s清理失败: %w", r.label, err)
+	}
+	return len(keys), nil
+}
+
+// prefixIterOptions 生成只遍历 prefix 开头键的迭代选项，prefix 为空时遍历全部
+func prefixIterOptions(prefix string) *pebble.IterOptions {
+	if prefix == "" {
+		return nil
+	}
+	return &pebble.IterOptions{
+		LowerBound: buildKey(prefix),
+		UpperBound: prefixUpperBound(buildKey(prefix)),
+	}
+}
+
+// prefixUpperBound 返回大于所有以 prefix 开头的键的最小键，prefix 全为 0xff 时返回 nil（无上界）
+func prefixUpperBound(prefix []byte) []byte {
+	upper := append([]byte(nil), prefix...)
+	for i := len(upper) - 1; i >= 0; i-- {
+		upper[i]++
+		if upper[i] != 0 {
+			return upper[:i+1]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package pebble_service
+
+import (
+	"bytes"
+	"push-base-service/models"
+	"testing"
+)
+
+func TestRepositoryGetPutDelete(t *testing.T) {
+	service := newTestPebbleService(t)
+	repo := newRepository[models.QAAccount](service, CollectionQAAccounts, "QA账号")
+
+	account, err := repo.Get("user1")
+	if err != nil || account != nil {
+		t.Fatalf("Get() on missing key = %v, %v; want nil, nil", account, err)
+	}
+
+	if err := repo.Put("user1", &models.QAAccount{MetaID: "user1", Note: "smoke"}); err != nil {
+		t.Fatalf("Put() failed, err: %v", err)
+	}
+	account, err = repo.Get("user1")
+	if err != nil || account == nil || account.Note != "smoke" {
+		t.Fatalf("Get() = %+v, %v; want stored account", account, err)
+	}
+	if exists, _ := repo.Has("user1"); !exists {
+		t.Errorf("Has() = false, want true")
+	}
+
+	if err := repo.Delete("user1"); err != nil {
+		t.Fatalf("Delete() failed, err: %v", err)
+	}
+	if exists, _ := repo.Has("user1"); exists {
+		t.Errorf("Has() after Delete() = true, want false")
+	}
+}
+
+func TestRepositoryScanPrefix(t *testing.T) {
+	service := newTestPebbleService(t)
+	repo := newRepository[models.QAInboxMessage](service, CollectionQAInbox, "收件箱消息")
+
+	for _, key := range []string{"a:1", "a:2", "a:3", "ab:1", "b:1"} {
+		if err := repo.Put(key, &models.QAInboxMessage{ID: key}); err != nil {
+			t.Fatalf("Put(%s) failed, err: %v", key, err)
+		}
+	}
+
+	var keys []string
+	repo.ScanPrefix("a:", func(key string, message *models.QAInboxMessage) bool {
+		keys = append(keys, message.ID)
+		return true
+	})
+	if len(keys) != 3 || keys[0] != "a:1" || keys[2] != "a:3" {
+		t.Errorf("ScanPrefix(a:) = %v, want [a:1 a:2 a:3]", keys)
+	}
+
+	keys = nil
+	repo.ScanPrefixReverse("a:", func(key string, _ *models.QAInboxMessage) bool {
+		keys = append(keys, key)
+		return len(keys) < 2
+	})
+	if len(keys) != 2 || keys[0] != "a:3" || keys[1] != "a:2" {
+		t.Errorf("ScanPrefixReverse(a:) stopped at %v, want [a:3 a:2]", keys)
+	}
+
+	count, err := repo.DeleteWhere("a:", func(key string, _ *models.QAInboxMessage) bool {
+		return key != "a:2"
+	})
+	if err != nil || count != 2 {
+		t.Fatalf("DeleteWhere() = %d, %v; want 2", count, err)
+	}
+
+	keys = nil
+	repo.ScanPrefix("", func(key string, _ *models.QAInboxMessage) bool {
+		keys = append(keys, key)
+		return true
+	})
+	if len(keys) != 3 || keys[0] != "a:2" {
+		t.Errorf("remaining keys = %v, want [a:2 ab:1 b:1]", keys)
+	}
+}
+
+func TestPrefixUpperBound(t *testing.T) {
+	cases := []struct {
+		prefix []byte
+		want   []byte
+	}{
+		{[]byte("user:"), []byte("user;")},
+		{[]byte{'a', 0xff}, []byte{'b'}},
+		{[]byte{0xff, 0xff}, nil},
+	}
+	for _, c := range cases {
+		if got := prefixUpperBound(c.prefix); !bytes.Equal(got, c.want) {
+			t.Errorf("prefixUpperBound(%q) = %q, want %q", c.prefix, got, c.want)
+		}
+	}
+}
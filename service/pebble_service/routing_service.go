@@ -0,0 +1,85 @@
+package pebble_service
+
+import (
+	"fmt"
+	"push-base-service/models"
+	"time"
+)
+
+// routingRulesKey 路由规则集合中唯一的记录键
+const routingRulesKey = "rules"
+
+// routingRulesRepo 通知路由规则集合存储
+func (ps *PebbleService) routingRulesRepo() *repository[models.RoutingRuleSet] {
+	return newRepository[models.RoutingRuleSet](ps, CollectionRoutingRules, "路由规则")
+}
+
+// SaveRoutingRules 保存通过管理接口设置的路由规则（整体替换）
+func (ps *PebbleService) SaveRoutingRules(rules []models.RoutingRule) (*models.RoutingRuleSet, error) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	if rules == nil {
+		rules = []models.RoutingRule{}
+	}
+	ruleSet := &models.RoutingRuleSet{
+		Rules:     rules,
+		UpdatedAt: time.Now().Unix(),
+	}
+	if err := ps.routingRulesRepo().Put(routingRulesKey, ruleSet); err != nil {
+		return nil, err
+	}
+	return ruleSet, nil
+}
+
+// GetRoutingRules 获取通过管理接口设置的路由规则，未设置时返回 nil
+func (ps *PebbleService) GetRoutingRules() (*models.RoutingRuleSet, error) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	return ps.routingRulesRepo().Get(routingRulesKey)
+}
+
+// DeleteRoutingRules 删除通过管理接口设置的路由规则，恢复使用配置文件中的规则
+func (ps *PebbleService) DeleteRoutingRules() error {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	return ps.routingRulesRepo().Delete(routingRulesKey)
+}
+
+// SaveRoutingRules 全局方法：保存路由规则
+func SaveRoutingRules(rules []models.RoutingRule) (*models.RoutingRuleSet, error) {
+	service := GetGlobalService()
+	if service == nil {
+		return nil, fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return nil, fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.SaveRoutingRules(rules)
+}
+
+// GetRoutingRules 全局方法：获取通过管理接口设置的路由规则
+func GetRoutingRules() (*models.RoutingRuleSet, error) {
+	service := GetGlobalService()
+	if service == nil {
+		return nil, fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return nil, fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.GetRoutingRules()
+}
+
+// DeleteRoutingRules 全局方法：删除通过管理接口设置的路由规则
+func DeleteRoutingRules() error {
+	service := GetGlobalService()
+	if service == nil {
+		return fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.DeleteRoutingRules()
+}
@@ -0,0 +1,260 @@
+package pebble_service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"push-base-service/models"
+	"sort"
+	"sync"
+	"time"
+)
+
+// scheduleMu 保证定时任务状态流转（领取、取消）的原子性
+var scheduleMu sync.Mutex
+
+// scheduledRepo 定时推送任务集合存储，键为任务ID（按配置压缩）
+func (ps *PebbleService) scheduledRepo() *repository[models.ScheduledPush] {
+	return newRepository[models.ScheduledPush](ps, CollectionScheduled, "定时推送任务")
+}
+
+// SaveScheduledPush 保存定时推送任务
+func (ps *PebbleService) SaveScheduledPush(job *models.ScheduledPush) error {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	if job.ID == "" {
+		return fmt.Errorf("任务ID 不能为空")
+	}
+
+	now := time.Now().Unix()
+	if job.CreatedAt == 0 {
+		job.CreatedAt = now
+	}
+	job.UpdatedAt = now
+
+	return ps.scheduledRepo().Put(job.ID, job)
+}
+
+// GetScheduledPush 获取定时推送任务，不存在时返回 nil
+func (ps *PebbleService) GetScheduledPush(id string) (*models.ScheduledPush, error) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	if id == "" {
+		return nil, fmt.Errorf("任务ID 不能为空")
+	}
+
+	return ps.scheduledRepo().Get(id)
+}
+
+// ListScheduledPushes 列出定时推送任务（按计划发送时间排序），status 为空时返回全部
+func (ps *PebbleService) ListScheduledPushes(status string) ([]*models.ScheduledPush, error) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	jobs, err := scanScheduledPushes(ps.scheduledRepo(), func(job *models.ScheduledPush) bool {
+		return status == "" || job.Status == status
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].SendAt < jobs[j].SendAt
+	})
+	return jobs, nil
+}
+
+// ClaimDueScheduledPushes 领取已到期的待发送任务，并将其状态置为发送中
+func (ps *PebbleService) ClaimDueScheduledPushes(now int64, limit int) ([]*models.ScheduledPush, error) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	repo := ps.scheduledRepo()
+
+	scheduleMu.Lock()
+	defer scheduleMu.Unlock()
+
+	jobs, err := scanScheduledPushes(repo, func(job *models.ScheduledPush) bool {
+		return job.Status == models.ScheduleStatusPending && job.SendAt <= now
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].SendAt < jobs[j].SendAt
+	})
+	if limit > 0 && len(jobs) > limit {
+		jobs = jobs[:limit]
+	}
+
+	for _, job := range jobs {
+		job.Status = models.ScheduleStatusSending
+		job.UpdatedAt = time.Now().Unix()
+		if err := repo.Put(job.ID, job); err != nil {
+			return nil, err
+		}
+	}
+
+	return jobs, nil
+}
+
+// ResetSendingScheduledPushes 将发送中的任务重置为待发送（用于服务重启后恢复中断的任务）
+func (ps *PebbleService) ResetSendingScheduledPushes() (int, error) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	repo := ps.scheduledRepo()
+
+	scheduleMu.Lock()
+	defer scheduleMu.Unlock()
+
+	jobs, err := scanScheduledPushes(repo, func(job *models.ScheduledPush) bool {
+		return job.Status == models.ScheduleStatusSending
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for _, job := range jobs {
+		job.Status = models.ScheduleStatusPending
+		job.UpdatedAt = time.Now().Unix()
+		if err := repo.Put(job.ID, job); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(jobs), nil
+}
+
+// 取消定时推送的错误，接口据此区分任务不存在和状态不允许取消
+var (
+	ErrScheduledPushNotFound      = errors.New("定时推送任务不存在")
+	ErrScheduledPushNotCancelable = errors.New("无法取消")
+)
+
+// CancelScheduledPush 取消待发送的定时推送任务
+func (ps *PebbleService) CancelScheduledPush(id string) (*models.ScheduledPush, error) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	if id == "" {
+		return nil, fmt.Errorf("任务ID 不能为空")
+	}
+
+	repo := ps.scheduledRepo()
+
+	scheduleMu.Lock()
+	defer scheduleMu.Unlock()
+
+	job, err := repo.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if job == nil {
+		return nil, fmt.Errorf("%w: %s", ErrScheduledPushNotFound, id)
+	}
+	if job.Status != models.ScheduleStatusPending {
+		return nil, fmt.Errorf("任务当前状态为 %s，%w", job.Status, ErrScheduledPushNotCancelable)
+	}
+
+	job.Status = models.ScheduleStatusCanceled
+	job.UpdatedAt = time.Now().Unix()
+	if err := repo.Put(job.ID, job); err != nil {
+		return nil, err
+	}
+
+	log.Printf("🚫 已取消定时推送任务: ID=%s", id)
+	return job, nil
+}
+
+// scanScheduledPushes 遍历定时推送任务，返回满足条件的任务
+func scanScheduledPushes(repo *repository[models.ScheduledPush], match func(job *models.ScheduledPush) bool) ([]*models.ScheduledPush, error) {
+	jobs := []*models.ScheduledPush{}
+	err := repo.ScanPrefix("", func(key string, job *models.ScheduledPush) bool {
+		if match(job) {
+			jobs = append(jobs, job)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// ===== 定时推送全局方法 =====
+
+// SaveScheduledPush 全局方法：保存定时推送任务
+func SaveScheduledPush(job *models.ScheduledPush) error {
+	service := GetGlobalService()
+	if service == nil {
+		return fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.SaveScheduledPush(job)
+}
+
+// GetScheduledPush 全局方法：获取定时推送任务
+func GetScheduledPush(id string) (*models.ScheduledPush, error) {
+	service := GetGlobalService()
+	if service == nil {
+		return nil, fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return nil, fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.GetScheduledPush(id)
+}
+
+// ListScheduledPushes 全局方法：列出定时推送任务
+func ListScheduledPushes(status string) ([]*models.ScheduledPush, error) {
+	service := GetGlobalService()
+	if service == nil {
+		return nil, fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return nil, fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.ListScheduledPushes(status)
+}
+
+// ClaimDueScheduledPushes 全局方法：领取已到期的待发送任务
+func ClaimDueScheduledPushes(now int64, limit int) ([]*models.ScheduledPush, error) {
+	service := GetGlobalService()
+	if service == nil {
+		return nil, fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return nil, fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.ClaimDueScheduledPushes(now, limit)
+}
+
+// ResetSendingScheduledPushes 全局方法：恢复中断的定时推送任务
+func ResetSendingScheduledPushes() (int, error) {
+	service := GetGlobalService()
+	if service == nil {
+		return 0, fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return 0, fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.ResetSendingScheduledPushes()
+}
+
+// CancelScheduledPush 全局方法：取消定时推送任务
+func CancelScheduledPush(id string) (*models.ScheduledPush, error) {
+	service := GetGlobalService()
+	if service == nil {
+		return nil, fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return nil, fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.CancelScheduledPush(id)
+}
@@ -0,0 +1,48 @@
+package pebble_service
+
+import (
+	"push-base-service/models"
+	"testing"
+	"time"
+)
+
+func TestClaimDueScheduledPushes(t *testing.T) {
+	service := newTestPebbleService(t)
+	now := time.Now().Unix()
+
+	for _, job := range []*models.ScheduledPush{
+		{ID: "due", MetaIDs: []string{"a"}, SendAt: now - 10, Status: models.ScheduleStatusPending},
+		{ID: "future", MetaIDs: []string{"b"}, SendAt: now + 3600, Status: models.ScheduleStatusPending},
+		{ID: "canceled", MetaIDs: []string{"c"}, SendAt: now - 10, Status: models.ScheduleStatusCanceled},
+	} {
+		if err := service.SaveScheduledPush(job); err != nil {
+			t.Fatalf("SaveScheduledPush() failed, err: %v", err)
+		}
+	}
+
+	jobs, err := service.ClaimDueScheduledPushes(now, 10)
+	if err != nil {
+		t.Fatalf("ClaimDueScheduledPushes() failed, err: %v", err)
+	}
+	if len(jobs) != 1 || jobs[0].ID != "due" {
+		t.Fatalf("ClaimDueScheduledPushes() = %+v, want only the due job", jobs)
+	}
+
+	// 已领取的任务不会被再次领取
+	jobs, _ = service.ClaimDueScheduledPushes(now, 10)
+	if len(jobs) != 0 {
+		t.Errorf("claimed job should not be claimed twice, got %d", len(jobs))
+	}
+
+	if _, err := service.CancelScheduledPush("due"); err == nil {
+		t.Errorf("CancelScheduledPush() on a sending job should fail")
+	}
+	if job, err := service.CancelScheduledPush("future"); err != nil || job.Status != models.ScheduleStatusCanceled {
+		t.Errorf("CancelScheduledPush() = %+v, %v; want canceled", job, err)
+	}
+
+	count, err := service.ResetSendingScheduledPushes()
+	if err != nil || count != 1 {
+		t.Errorf("ResetSendingScheduledPushes() = %d, %v; want 1", count, err)
+	}
+}
@@ -0,0 +1,56 @@
+package pebble_service
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/cockroachdb/pebble"
+)
+
+// CollectionSelfTest 启动自检使用的独立集合，不与业务集合共享数据库文件，运行中的实例不会占用其锁
+const CollectionSelfTest = "selftest"
+
+// SelfTest 在自检集合中执行一次 写入 -> 读取 -> 删除 往返，验证数据目录可写且编解码正常
+func (ps *PebbleService) SelfTest() error {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	db, err := ps.getCollectionDB(CollectionSelfTest)
+	if err != nil {
+		return err
+	}
+
+	key := buildKey(fmt.Sprintf("probe:%d", time.Now().UnixNano()))
+	value := []byte(fmt.Sprintf("push-base-service selftest %s", time.Now().Format(time.RFC3339Nano)))
+
+	if err := db.Set(key, ps.encodeValue(CollectionSelfTest, value), pebble.Sync); err != nil {
+		return fmt.Errorf("写入自检记录失败: %w", err)
+	}
+
+	data, closer, err := db.Get(key)
+	if err != nil {
+		return fmt.Errorf("读取自检记录失败: %w", err)
+	}
+	decoded, err := decodeValue(data)
+	if err != nil {
+		closer.Close()
+		return fmt.Errorf("解压自检记录失败: %w", err)
+	}
+	matched := bytes.Equal(decoded, value)
+	closer.Close()
+	if !matched {
+		return fmt.Errorf("自检记录读写不一致")
+	}
+
+	if err := db.Delete(key, pebble.Sync); err != nil {
+		return fmt.Errorf("删除自检记录失败: %w", err)
+	}
+	if _, closer, err := db.Get(key); err != pebble.ErrNotFound {
+		if err == nil {
+			closer.Close()
+		}
+		return fmt.Errorf("自检记录删除后仍可读取")
+	}
+	return nil
+}
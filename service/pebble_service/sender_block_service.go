@@ -0,0 +1,166 @@
+package pebble_service
+
+import (
+	"fmt"
+	"log"
+	"push-base-service/models"
+	"sort"
+	"time"
+)
+
+// blockedSendersRepo 屏蔽发送者集合存储，键为 metaId:senderId
+func (ps *PebbleService) blockedSendersRepo() *repository[models.BlockedSender] {
+	return newRepository[models.BlockedSender](ps, CollectionSenderBlocks, "屏蔽发送者")
+}
+
+// allGroupsBlockRepo 屏蔽所有群聊设置集合存储，键为 metaId
+func (ps *PebbleService) allGroupsBlockRepo() *repository[models.AllGroupsBlock] {
+	return newRepository[models.AllGroupsBlock](ps, CollectionGroupsBlock, "屏蔽所有群聊")
+}
+
+// getBlockedSenderKey 生成屏蔽发送者的键
+func getBlockedSenderKey(userId, senderId string) string {
+	return userId + ":" + senderId
+}
+
+// AddBlockedSender 屏蔽发送者，已屏蔽时更新屏蔽原因
+func (ps *PebbleService) AddBlockedSender(userId, senderId, reason string) error {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	if userId == "" || senderId == "" {
+		return fmt.Errorf("UserID 和 SenderID 不能为空")
+	}
+
+	repo := ps.blockedSendersRepo()
+	key := getBlockedSenderKey(userId, senderId)
+	blockedSender, err := repo.Get(key)
+	if err != nil {
+		return err
+	}
+	if blockedSender == nil {
+		blockedSender = &models.BlockedSender{
+			UserID:    userId,
+			SenderID:  senderId,
+			BlockedAt: time.Now().Unix(),
+		}
+	}
+	blockedSender.Reason = reason
+	if err := repo.Put(key, blockedSender); err != nil {
+		return err
+	}
+
+	log.Printf("✅ 已屏蔽发送者: UserID=%s, SenderID=%s", userId, senderId)
+	return nil
+}
+
+// RemoveBlockedSender 取消屏蔽发送者
+func (ps *PebbleService) RemoveBlockedSender(userId, senderId string) error {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	if userId == "" || senderId == "" {
+		return fmt.Errorf("UserID 和 SenderID 不能为空")
+	}
+
+	if err := ps.blockedSendersRepo().Delete(getBlockedSenderKey(userId, senderId)); err != nil {
+		return err
+	}
+
+	log.Printf("✅ 已取消屏蔽发送者: UserID=%s, SenderID=%s", userId, senderId)
+	return nil
+}
+
+// IsBlockedSender 检查用户是否屏蔽了该发送者
+func (ps *PebbleService) IsBlockedSender(userId, senderId string) (bool, error) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	if userId == "" || senderId == "" {
+		return false, nil
+	}
+	return ps.blockedSendersRepo().Has(getBlockedSenderKey(userId, senderId))
+}
+
+// GetUserBlockedSenders 获取用户屏蔽的所有发送者，按屏蔽时间排序
+func (ps *PebbleService) GetUserBlockedSenders(userId string) (*models.UserBlockedSenders, error) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	if userId == "" {
+		return nil, fmt.Errorf("UserID 不能为空")
+	}
+
+	userBlockedSenders := &models.UserBlockedSenders{
+		UserID:         userId,
+		BlockedSenders: []models.BlockedSender{},
+	}
+	err := ps.blockedSendersRepo().ScanPrefix(getBlockedSenderKey(userId, ""), func(_ string, blockedSender *models.BlockedSender) bool {
+		userBlockedSenders.BlockedSenders = append(userBlockedSenders.BlockedSenders, *blockedSender)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(userBlockedSenders.BlockedSenders, func(i, j int) bool {
+		return userBlockedSenders.BlockedSenders[i].BlockedAt < userBlockedSenders.BlockedSenders[j].BlockedAt
+	})
+	return userBlockedSenders, nil
+}
+
+// SetAllGroupsBlocked 开启或关闭"屏蔽所有群聊"，重复开启时保留最初的开启时间
+func (ps *PebbleService) SetAllGroupsBlocked(userId string, blocked bool) error {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	if userId == "" {
+		return fmt.Errorf("UserID 不能为空")
+	}
+
+	repo := ps.allGroupsBlockRepo()
+	if !blocked {
+		if err := repo.Delete(userId); err != nil {
+			return err
+		}
+		log.Printf("✅ 已关闭屏蔽所有群聊: UserID=%s", userId)
+		return nil
+	}
+
+	exists, err := repo.Has(userId)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+	if err := repo.Put(userId, &models.AllGroupsBlock{
+		UserID:    userId,
+		Blocked:   true,
+		BlockedAt: time.Now().Unix(),
+	}); err != nil {
+		return err
+	}
+
+	log.Printf("✅ 已开启屏蔽所有群聊: UserID=%s", userId)
+	return nil
+}
+
+// GetAllGroupsBlock 获取用户的"屏蔽所有群聊"设置，未开启时 Blocked 为 false
+func (ps *PebbleService) GetAllGroupsBlock(userId string) (*models.AllGroupsBlock, error) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	if userId == "" {
+		return nil, fmt.Errorf("UserID 不能为空")
+	}
+
+	allGroupsBlock, err := ps.allGroupsBlockRepo().Get(userId)
+	if err != nil {
+		return nil, err
+	}
+	if allGroupsBlock == nil {
+		return &models.AllGroupsBlock{UserID: userId}, nil
+	}
+	return allGroupsBlock, nil
+}
@@ -0,0 +1,187 @@
+package pebble_service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"push-base-service/models"
+	"sync"
+	"time"
+)
+
+// subscriptionMu 保证退订状态"读取-修改-写入"的原子性
+var subscriptionMu sync.Mutex
+
+// subscriptionsRepo 用户推送退订集合存储，键为 metaId
+func (ps *PebbleService) subscriptionsRepo() *repository[models.PushSubscription] {
+	return newRepository[models.PushSubscription](ps, CollectionSubscription, "推送退订")
+}
+
+// Unsubscribe 退订用户的全部推送，已退订时不重复记录
+func (ps *PebbleService) Unsubscribe(metaId, reason, source string) (*models.PushSubscription, error) {
+	return ps.setSubscription(metaId, true, reason, source)
+}
+
+// Resubscribe 恢复用户的推送，未退订时不记录
+func (ps *PebbleService) Resubscribe(metaId, source string) (*models.PushSubscription, error) {
+	return ps.setSubscription(metaId, false, "", source)
+}
+
+// setSubscription 修改用户的退订状态并追加操作记录，状态未变化时返回当前记录
+func (ps *PebbleService) setSubscription(metaId string, unsubscribed bool, reason, source string) (*models.PushSubscription, error) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	if metaId == "" {
+		return nil, fmt.Errorf("MetaID 不能为空")
+	}
+
+	subscriptionMu.Lock()
+	defer subscriptionMu.Unlock()
+
+	repo := ps.subscriptionsRepo()
+	subscription, err := repo.Get(metaId)
+	if err != nil {
+		return nil, err
+	}
+	if subscription == nil {
+		subscription = &models.PushSubscription{MetaID: metaId, History: []models.SubscriptionEvent{}}
+	}
+	if subscription.Unsubscribed == unsubscribed {
+		return subscription, nil
+	}
+
+	now := time.Now().Unix()
+	event := models.SubscriptionEvent{Action: models.SubscriptionActionResubscribe, Source: source, At: now}
+	if unsubscribed {
+		event.Action, event.Reason = models.SubscriptionActionUnsubscribe, reason
+		subscription.Reason = reason
+		subscription.UnsubscribedAt = now
+	}
+	subscription.Unsubscribed = unsubscribed
+	subscription.UpdatedAt = now
+	subscription.History = append(subscription.History, event)
+	if err := repo.Put(metaId, subscription); err != nil {
+		return nil, err
+	}
+
+	log.Printf("📭 用户推送订阅状态已变更: MetaID=%s, 操作=%s, 来源=%s", metaId, event.Action, source)
+	return subscription, nil
+}
+
+// GetSubscription 获取用户的退订记录，从未退订时返回 nil
+func (ps *PebbleService) GetSubscription(metaId string) (*models.PushSubscription, error) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	if metaId == "" {
+		return nil, fmt.Errorf("MetaID 不能为空")
+	}
+	return ps.subscriptionsRepo().Get(metaId)
+}
+
+// IsUnsubscribed 检查用户是否已退订推送
+func (ps *PebbleService) IsUnsubscribed(metaId string) (bool, error) {
+	subscription, err := ps.GetSubscription(metaId)
+	if err != nil {
+		return false, err
+	}
+	return subscription != nil && subscription.Unsubscribed, nil
+}
+
+// ListSubscriptions 列出所有有过退订记录的用户（含已重新订阅的），用于合规审计导出
+func (ps *PebbleService) ListSubscriptions() ([]*models.PushSubscription, error) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	var subscriptions []*models.PushSubscription
+	err := ps.subscriptionsRepo().ScanPrefix("", func(key string, subscription *models.PushSubscription) bool {
+		subscriptions = append(subscriptions, subscription)
+		return true
+	})
+	return subscriptions, err
+}
+
+// PebbleOptOutList 基于 Pebble 的全局退订名单
+type PebbleOptOutList struct {
+	service *PebbleService
+}
+
+// NewGlobalPebbleOptOutList 创建基于全局 Pebble 服务的退订名单
+func NewGlobalPebbleOptOutList() *PebbleOptOutList {
+	service := GetGlobalService()
+	if service == nil {
+		log.Printf("❌ 全局 Pebble 服务未初始化，无法创建退订名单")
+		return nil
+	}
+	if !service.IsInitialized() {
+		log.Printf("❌ Pebble 服务未正确初始化，无法创建退订名单")
+		return nil
+	}
+	return &PebbleOptOutList{service: service}
+}
+
+// IsOptedOut 判断用户是否已退订推送 (实现 OptOutList 接口)
+func (l *PebbleOptOutList) IsOptedOut(ctx context.Context, metaId string) (bool, error) {
+	return l.service.IsUnsubscribed(metaId)
+}
+
+// Unsubscribe 全局方法：退订用户的全部推送
+func Unsubscribe(metaId, reason, source string) (*models.PushSubscription, error) {
+	service := GetGlobalService()
+	if service == nil {
+		return nil, fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return nil, fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.Unsubscribe(metaId, reason, source)
+}
+
+// Resubscribe 全局方法：恢复用户的推送
+func Resubscribe(metaId, source string) (*models.PushSubscription, error) {
+	service := GetGlobalService()
+	if service == nil {
+		return nil, fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return nil, fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.Resubscribe(metaId, source)
+}
+
+// GetSubscription 全局方法：获取用户的退订记录
+func GetSubscription(metaId string) (*models.PushSubscription, error) {
+	service := GetGlobalService()
+	if service == nil {
+		return nil, fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return nil, fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.GetSubscription(metaId)
+}
+
+// IsUnsubscribed 全局方法：检查用户是否已退订推送
+func IsUnsubscribed(metaId string) (bool, error) {
+	service := GetGlobalService()
+	if service == nil {
+		return false, fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return false, fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.IsUnsubscribed(metaId)
+}
+
+// ListSubscriptions 全局方法：列出所有有过退订记录的用户
+func ListSubscriptions() ([]*models.PushSubscription, error) {
+	service := GetGlobalService()
+	if service == nil {
+		return nil, fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return nil, fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.ListSubscriptions()
+}
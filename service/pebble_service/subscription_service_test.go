@@ -0,0 +1,50 @@
+package pebble_service
+
+import (
+	"context"
+	"push-base-service/models"
+	"testing"
+)
+
+func TestSubscription(t *testing.T) {
+	service := newTestPebbleService(t)
+
+	if unsubscribed, err := service.IsUnsubscribed("user-a"); err != nil || unsubscribed {
+		t.Fatalf("IsUnsubscribed() before unsubscribe = %v, %v; want false", unsubscribed, err)
+	}
+
+	if _, err := service.Unsubscribe("user-a", "too many notifications", "user"); err != nil {
+		t.Fatalf("Unsubscribe() failed, err: %v", err)
+	}
+	// 重复退订不追加记录
+	subscription, err := service.Unsubscribe("user-a", "again", "user")
+	if err != nil {
+		t.Fatalf("Unsubscribe() failed, err: %v", err)
+	}
+	if !subscription.Unsubscribed || subscription.Reason != "too many notifications" || len(subscription.History) != 1 {
+		t.Errorf("Unsubscribe() = %+v, want one unsubscribe event", subscription)
+	}
+	optOut := &PebbleOptOutList{service: service}
+	if optedOut, _ := optOut.IsOptedOut(context.Background(), "user-a"); !optedOut {
+		t.Error("IsOptedOut() = false after unsubscribe")
+	}
+
+	subscription, err = service.Resubscribe("user-a", "api_key:support")
+	if err != nil {
+		t.Fatalf("Resubscribe() failed, err: %v", err)
+	}
+	if subscription.Unsubscribed || len(subscription.History) != 2 || subscription.History[1].Action != models.SubscriptionActionResubscribe ||
+		subscription.History[1].Source != "api_key:support" || subscription.UnsubscribedAt == 0 {
+		t.Errorf("Resubscribe() = %+v", subscription)
+	}
+
+	// 从未退订的用户重新订阅不产生记录
+	if subscription, err := service.Resubscribe("user-b", "user"); err != nil || subscription.Unsubscribed || len(subscription.History) != 0 {
+		t.Errorf("Resubscribe() of a subscribed user = %+v, %v", subscription, err)
+	}
+
+	subscriptions, err := service.ListSubscriptions()
+	if err != nil || len(subscriptions) != 1 || subscriptions[0].MetaID != "user-a" {
+		t.Errorf("ListSubscriptions() = %+v, %v; want only user-a", subscriptions, err)
+	}
+}
@@ -0,0 +1,178 @@
+package pebble_service
+
+import (
+	"fmt"
+	"log"
+	"push-base-service/models"
+	"time"
+)
+
+// tenantHooksRepo 租户Webhook集合存储，键为 tenantId
+func (ps *PebbleService) tenantHooksRepo() *repository[models.TenantWebhook] {
+	return newRepository[models.TenantWebhook](ps, CollectionTenantHooks, "租户Webhook")
+}
+
+// SetUserTenant 设置用户所属租户
+func (ps *PebbleService) SetUserTenant(metaId, tenantId string) error {
+	if metaId == "" {
+		return fmt.Errorf("MetaID 不能为空")
+	}
+
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+	defer ps.keyLocks.lock(userLockKey(metaId))()
+
+	userTokens, err := ps.loadUserTokens(metaId)
+	if err != nil {
+		return fmt.Errorf("获取现有用户令牌失败: %w", err)
+	}
+	if userTokens == nil {
+		userTokens = &models.UserPushTokens{MetaID: metaId, Tokens: make(map[string]string)}
+	}
+
+	if userTokens.TenantID == tenantId {
+		return nil
+	}
+
+	userTokens.TenantID = tenantId
+	if err := ps.saveUserTokensLocked(userTokens); err != nil {
+		return fmt.Errorf("保存用户租户信息失败: %w", err)
+	}
+
+	log.Printf("✅ 已设置用户租户: MetaID=%s, TenantID=%s", metaId, tenantId)
+	return nil
+}
+
+// SaveTenantWebhook 保存租户Webhook配置
+func (ps *PebbleService) SaveTenantWebhook(webhook *models.TenantWebhook) error {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	if webhook.TenantID == "" {
+		return fmt.Errorf("TenantID 不能为空")
+	}
+	if webhook.URL == "" {
+		return fmt.Errorf("Webhook URL 不能为空")
+	}
+
+	now := time.Now().Unix()
+	if webhook.CreatedAt == 0 {
+		webhook.CreatedAt = now
+	}
+	webhook.UpdatedAt = now
+
+	if err := ps.tenantHooksRepo().Put(webhook.TenantID, webhook); err != nil {
+		return err
+	}
+
+	log.Printf("✅ 已保存租户Webhook: TenantID=%s, URL=%s", webhook.TenantID, webhook.URL)
+	return nil
+}
+
+// GetTenantWebhook 获取租户Webhook配置，不存在时返回 nil
+func (ps *PebbleService) GetTenantWebhook(tenantId string) (*models.TenantWebhook, error) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	if tenantId == "" {
+		return nil, fmt.Errorf("TenantID 不能为空")
+	}
+
+	return ps.tenantHooksRepo().Get(tenantId)
+}
+
+// ListTenantWebhooks 列出所有租户Webhook配置
+func (ps *PebbleService) ListTenantWebhooks() ([]*models.TenantWebhook, error) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	webhooks := []*models.TenantWebhook{}
+	err := ps.tenantHooksRepo().ScanPrefix("", func(key string, webhook *models.TenantWebhook) bool {
+		webhooks = append(webhooks, webhook)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return webhooks, nil
+}
+
+// DeleteTenantWebhook 删除租户Webhook配置
+func (ps *PebbleService) DeleteTenantWebhook(tenantId string) error {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	if tenantId == "" {
+		return fmt.Errorf("TenantID 不能为空")
+	}
+
+	if err := ps.tenantHooksRepo().Delete(tenantId); err != nil {
+		return err
+	}
+
+	log.Printf("🗑️ 已删除租户Webhook: TenantID=%s", tenantId)
+	return nil
+}
+
+// ===== 租户相关全局方法 =====
+
+// SetUserTenant 全局方法：设置用户所属租户
+func SetUserTenant(metaId, tenantId string) error {
+	service := GetGlobalService()
+	if service == nil {
+		return fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.SetUserTenant(metaId, tenantId)
+}
+
+// SaveTenantWebhook 全局方法：保存租户Webhook配置
+func SaveTenantWebhook(webhook *models.TenantWebhook) error {
+	service := GetGlobalService()
+	if service == nil {
+		return fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.SaveTenantWebhook(webhook)
+}
+
+// GetTenantWebhook 全局方法：获取租户Webhook配置
+func GetTenantWebhook(tenantId string) (*models.TenantWebhook, error) {
+	service := GetGlobalService()
+	if service == nil {
+		return nil, fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return nil, fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.GetTenantWebhook(tenantId)
+}
+
+// ListTenantWebhooks 全局方法：列出所有租户Webhook配置
+func ListTenantWebhooks() ([]*models.TenantWebhook, error) {
+	service := GetGlobalService()
+	if service == nil {
+		return nil, fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return nil, fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.ListTenantWebhooks()
+}
+
+// DeleteTenantWebhook 全局方法：删除租户Webhook配置
+func DeleteTenantWebhook(tenantId string) error {
+	service := GetGlobalService()
+	if service == nil {
+		return fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.DeleteTenantWebhook(tenantId)
+}
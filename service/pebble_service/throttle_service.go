@@ -0,0 +1,113 @@
+package pebble_service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"push-base-service/models"
+	"push-base-service/service/push_service"
+	"time"
+
+	"github.com/cockroachdb/pebble"
+)
+
+// PebbleThrottler 基于 Pebble 的滑动窗口限流器，配额在重启后仍然有效
+// 窗口写入不等待 fsync：进程崩溃时已写入 WAL 的记录不会丢失，仅在机器掉电时可能丢失最近的少量配额记录
+type PebbleThrottler struct {
+	service *PebbleService
+	limit   int
+	window  time.Duration
+	locks   keyLocks // 保证同一个 key 上"读取-判断-写入"的原子性，不同 key 之间并行
+}
+
+// NewPebbleThrottler 创建基于 Pebble 的限流器
+func NewPebbleThrottler(service *PebbleService, limit int, window time.Duration) *PebbleThrottler {
+	return &PebbleThrottler{
+		service: service,
+		limit:   limit,
+		window:  window,
+	}
+}
+
+// NewGlobalPebbleThrottler 创建基于全局 Pebble 服务的限流器
+func NewGlobalPebbleThrottler(limit int, window time.Duration) *PebbleThrottler {
+	service := GetGlobalService()
+	if service == nil {
+		log.Printf("❌ 全局 Pebble 服务未初始化，无法创建限流器")
+		return nil
+	}
+	if !service.IsInitialized() {
+		log.Printf("❌ Pebble 服务未正确初始化，无法创建限流器")
+		return nil
+	}
+	return NewPebbleThrottler(service, limit, window)
+}
+
+// getThrottleKey 生成限流窗口的键
+func getThrottleKey(key string) []byte {
+	return buildKey(key)
+}
+
+// Name 返回限流器名称 (实现 Throttler 接口)
+func (pt *PebbleThrottler) Name() string {
+	return "pebble"
+}
+
+// Allow 判断 key 本次是否允许推送 (实现 Throttler 接口)
+func (pt *PebbleThrottler) Allow(ctx context.Context, key string) (*push_service.ThrottleDecision, error) {
+	if key == "" {
+		return nil, fmt.Errorf("限流键不能为空")
+	}
+
+	pt.service.mu.RLock()
+	defer pt.service.mu.RUnlock()
+
+	db, err := pt.service.getCollectionDB(CollectionThrottle)
+	if err != nil {
+		return nil, fmt.Errorf("获取限流集合数据库失败: %w", err)
+	}
+
+	defer pt.locks.lock(key)()
+
+	now := time.Now().UnixMilli()
+	windowStart := now - pt.window.Milliseconds()
+
+	record := &models.ThrottleWindow{Key: key}
+	value, closer, err := db.Get(getThrottleKey(key))
+	if err == nil {
+		unmarshalErr := json.Unmarshal(value, record)
+		closer.Close()
+		if unmarshalErr != nil {
+			log.Printf("⚠️ 反序列化限流窗口失败，将重置: %v", unmarshalErr)
+			record = &models.ThrottleWindow{Key: key}
+		}
+	} else if err != pebble.ErrNotFound {
+		return nil, fmt.Errorf("获取限流窗口失败: %w", err)
+	}
+
+	// 丢弃窗口外的记录
+	hits := record.Hits[:0]
+	for _, hit := range record.Hits {
+		if hit > windowStart {
+			hits = append(hits, hit)
+		}
+	}
+	record.Hits = hits
+
+	if len(record.Hits) >= pt.limit {
+		retryAfter := time.Duration(record.Hits[0]-windowStart) * time.Millisecond
+		return &push_service.ThrottleDecision{Allowed: false, RetryAfter: retryAfter}, nil
+	}
+
+	record.Hits = append(record.Hits, now)
+	data, err := json.Marshal(record)
+	if err != nil {
+		return nil, fmt.Errorf("序列化限流窗口失败: %w", err)
+	}
+	if err := db.Set(getThrottleKey(key), data, pebble.NoSync); err != nil {
+		return nil, fmt.Errorf("保存限流窗口失败: %w", err)
+	}
+
+	return &push_service.ThrottleDecision{Allowed: true, Remaining: pt.limit - len(record.Hits)}, nil
+}
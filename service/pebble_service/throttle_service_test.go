@@ -0,0 +1,71 @@
+package pebble_service
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPebbleThrottlerSlidingWindow(t *testing.T) {
+	service := newTestPebbleService(t)
+	throttler := NewPebbleThrottler(service, 2, time.Hour)
+
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		decision, err := throttler.Allow(ctx, "user-a")
+		if err != nil || !decision.Allowed {
+			t.Fatalf("Allow() #%d = %+v, %v; want allowed", i+1, decision, err)
+		}
+	}
+
+	decision, err := throttler.Allow(ctx, "user-a")
+	if err != nil || decision.Allowed {
+		t.Fatalf("Allow() over limit = %+v, %v; want throttled", decision, err)
+	}
+	if decision.RetryAfter <= 0 || decision.RetryAfter > time.Hour {
+		t.Errorf("RetryAfter = %v, want within the window", decision.RetryAfter)
+	}
+
+	// 配额持久化在 Pebble 中，新的限流器实例同样生效
+	again := NewPebbleThrottler(service, 2, time.Hour)
+	if decision, _ := again.Allow(ctx, "user-a"); decision.Allowed {
+		t.Error("new throttler instance should still throttle user-a")
+	}
+	if decision, _ := again.Allow(ctx, "user-b"); !decision.Allowed {
+		t.Error("Allow(user-b) should not be throttled")
+	}
+}
+
+func TestPebbleThrottlerConcurrentAllow(t *testing.T) {
+	service := newTestPebbleService(t)
+	throttler := NewPebbleThrottler(service, 5, time.Hour)
+
+	// 同一个 key 的并发请求不会超出配额，不同 key 互不影响
+	ctx := context.Background()
+	var allowed [2]atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 40; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			key := []string{"user-a", "user-b"}[i%2]
+			decision, err := throttler.Allow(ctx, key)
+			if err != nil {
+				t.Errorf("Allow(%s) failed, err: %v", key, err)
+				return
+			}
+			if decision.Allowed {
+				allowed[i%2].Add(1)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	for i, key := range []string{"user-a", "user-b"} {
+		if got := allowed[i].Load(); got != 5 {
+			t.Errorf("allowed for %s = %d, want 5", key, got)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package pebble_service
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"push-base-service/models"
+	"runtime"
+	"slices"
+	"sync"
+
+	"github.com/cockroachdb/pebble"
+)
+
+// bulkReadChunkSize 批量读取时每个任务负责的用户数，用户数不超过该值时不并发
+const bulkReadChunkSize = 256
+
+// bulkReadWorkers 批量读取的最大并发任务数
+var bulkReadWorkers = runtime.GOMAXPROCS(0)
+
+// bulkReadResult 批量读取的结果：found 为存在的用户，failed 为读取或反序列化失败的用户
+type bulkReadResult struct {
+	found  map[string]*models.UserPushTokens
+	failed map[string]error
+}
+
+// readUserTokensBulk 批量读取用户令牌：对 metaId 去重排序后切分为若干段，
+// 每段用一个有界迭代器按顺序 SeekGE，多段之间有界并发执行；不存在的用户不在结果中
+func (ps *PebbleService) readUserTokensBulk(metaIds []string) (*bulkReadResult, error) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	db, err := ps.getCollectionDB(CollectionUserTokens)
+	if err != nil {
+		return nil, fmt.Errorf("获取用户令牌集合数据库失败: %w", err)
+	}
+
+	sorted := make([]string, 0, len(metaIds))
+	for _, metaId := range metaIds {
+		if metaId != "" {
+			sorted = append(sorted, metaId)
+		}
+	}
+	slices.Sort(sorted)
+	sorted = slices.Compact(sorted)
+
+	result := &bulkReadResult{
+		found:  make(map[string]*models.UserPushTokens, len(sorted)),
+		failed: make(map[string]error),
+	}
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		firstErr error
+	)
+	workers := make(chan struct{}, max(bulkReadWorkers, 1))
+	for start := 0; start < len(sorted); start += bulkReadChunkSize {
+		chunk := sorted[start:min(start+bulkReadChunkSize, len(sorted))]
+		workers <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-workers }()
+
+			found, failed, err := readUserTokensChunk(db, chunk)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil && firstErr == nil {
+				firstErr = err
+			}
+			for metaId, userTokens := range found {
+				result.found[metaId] = userTokens
+			}
+			for metaId, err := range failed {
+				result.failed[metaId] = err
+			}
+		}()
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, fmt.Errorf("批量读取用户令牌失败: %w", firstErr)
+	}
+	return result, nil
+}
+
+// readUserTokensChunk 用一个迭代器按顺序读取一段已排序的用户
+func readUserTokensChunk(db *CollectionDB, metaIds []string) (map[string]*models.UserPushTokens, map[string]error, error) {
+	found := make(map[string]*models.UserPushTokens, len(metaIds))
+	failed := make(map[string]error)
+
+	last := getUserTokensKey(metaIds[len(metaIds)-1])
+	iter, err := db.NewIter(&pebble.IterOptions{LowerBound: getUserTokensKey(metaIds[0]), UpperBound: append(slices.Clone(last), 0)})
+	if err != nil {
+		return nil, nil, err
+	}
+	defer iter.Close()
+
+	for _, metaId := range metaIds {
+		key := getUserTokensKey(metaId)
+		if !iter.SeekGE(key) || !bytes.Equal(iter.Key(), key) {
+			continue
+		}
+		var userTokens models.UserPushTokens
+		if err := json.Unmarshal(iter.Value(), &userTokens); err != nil {
+			failed[metaId] = fmt.Errorf("反序列化用户令牌失败: %w", err)
+			continue
+		}
+		found[metaId] = &userTokens
+	}
+	return found, failed, iter.Error()
+}
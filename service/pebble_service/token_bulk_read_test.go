@@ -0,0 +1,87 @@
+package pebble_service
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"push-base-service/models"
+	"testing"
+)
+
+// seedUserTokens 写入 count 个用户的令牌，返回 metaId 列表
+func seedUserTokens(tb testing.TB, service *PebbleService, count int) []string {
+	tb.Helper()
+	metaIds := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		metaId := fmt.Sprintf("user-%05d", i)
+		if err := service.SaveUserTokens(&models.UserPushTokens{MetaID: metaId, Tokens: map[string]string{"expo": "token-" + metaId}}); err != nil {
+			tb.Fatalf("SaveUserTokens() failed, err: %v", err)
+		}
+		metaIds = append(metaIds, metaId)
+	}
+	return metaIds
+}
+
+func TestGetAllUserTokensBulk(t *testing.T) {
+	for _, keyspace := range []string{KeyspaceMulti, KeyspaceShared} {
+		t.Run(keyspace, func(t *testing.T) {
+			service := NewPebbleService(&Config{DBPath: t.TempDir(), Keyspace: keyspace})
+			if err := service.Initialize(); err != nil {
+				t.Fatalf("Initialize() failed, err: %v", err)
+			}
+			t.Cleanup(func() { service.Close() })
+
+			// 超过一段的用户数，覆盖多段并发读取
+			metaIds := seedUserTokens(t, service, bulkReadChunkSize*2+10)
+			// 乱序、重复和不存在的用户
+			query := append([]string{"missing", metaIds[300], metaIds[0]}, metaIds...)
+
+			result, err := service.GetAllUserTokens(query)
+			if err != nil {
+				t.Fatalf("GetAllUserTokens() failed, err: %v", err)
+			}
+			if len(result) != len(metaIds)+1 {
+				t.Errorf("len(result) = %d, want %d", len(result), len(metaIds)+1)
+			}
+			for _, metaId := range metaIds {
+				if got := result[metaId].Tokens["expo"]; got != "token-"+metaId {
+					t.Fatalf("%s token = %q", metaId, got)
+				}
+			}
+			if missing := result["missing"]; missing == nil || len(missing.Tokens) != 0 {
+				t.Errorf("missing user = %+v, want empty record", missing)
+			}
+		})
+	}
+}
+
+// BenchmarkGetAllUserTokens 对比 5000 人大群逐个读取与批量读取的耗时
+func BenchmarkGetAllUserTokens(b *testing.B) {
+	log.SetOutput(io.Discard)
+	b.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	service := NewPebbleService(&Config{DBPath: b.TempDir()})
+	if err := service.Initialize(); err != nil {
+		b.Fatalf("Initialize() failed, err: %v", err)
+	}
+	b.Cleanup(func() { service.Close() })
+	metaIds := seedUserTokens(b, service, 5000)
+
+	b.Run("serial", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for _, metaId := range metaIds {
+				if _, err := service.GetUserTokens(metaId); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+	b.Run("bulk", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := service.GetAllUserTokens(metaIds); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,168 @@
+package pebble_service
+
+import (
+	"container/list"
+	"push-base-service/models"
+	"push-base-service/service/metrics_service"
+	"sync"
+	"time"
+)
+
+// 令牌缓存默认配置
+const (
+	DefaultTokenCacheSize = 10000
+	DefaultTokenCacheTTL  = 5 * time.Minute
+)
+
+var (
+	tokenCacheHitsCounter = metrics_service.NewCounterVec(
+		"push_token_cache_hits_total", "Number of user token lookups served from the LRU cache")
+	tokenCacheMissesCounter = metrics_service.NewCounterVec(
+		"push_token_cache_misses_total", "Number of user token lookups that fell through to Pebble")
+)
+
+// tokenCache 用户令牌 LRU 缓存，带过期时间
+// 读取前记录 generation，失效发生在读取期间时放弃写回，避免把旧数据写回缓存
+type tokenCache struct {
+	size       int
+	ttl        time.Duration
+	items      map[string]*list.Element
+	order      *list.List // 最近使用的在前
+	generation uint64     // 每次失效递增
+	mu         sync.Mutex
+	now        func() time.Time
+}
+
+// tokenCacheEntry 缓存条目
+type tokenCacheEntry struct {
+	metaId    string
+	tokens    *models.UserPushTokens
+	expiresAt time.Time
+}
+
+// newTokenCache 创建令牌缓存
+func newTokenCache(size int, ttl time.Duration) *tokenCache {
+	if size <= 0 {
+		size = DefaultTokenCacheSize
+	}
+	if ttl <= 0 {
+		ttl = DefaultTokenCacheTTL
+	}
+	return &tokenCache{
+		size:  size,
+		ttl:   ttl,
+		items: make(map[string]*list.Element),
+		order: list.New(),
+		now:   time.Now,
+	}
+}
+
+// get 获取缓存的令牌（返回副本），未命中或已过期返回 nil
+func (c *tokenCache) get(metaId string) *models.UserPushTokens {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, exists := c.items[metaId]
+	if !exists {
+		tokenCacheMissesCounter.Inc()
+		return nil
+	}
+
+	entry := element.Value.(*tokenCacheEntry)
+	if !c.now().Before(entry.expiresAt) {
+		c.removeElement(element)
+		tokenCacheMissesCounter.Inc()
+		return nil
+	}
+
+	c.order.MoveToFront(element)
+	tokenCacheHitsCounter.Inc()
+	return cloneUserTokens(entry.tokens)
+}
+
+// currentGeneration 返回当前失效代数，读取数据库前调用
+func (c *tokenCache) currentGeneration() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.generation
+}
+
+// put 写入缓存，generation 已变化（读取期间发生过失效）时放弃写入
+func (c *tokenCache) put(metaId string, tokens *models.UserPushTokens, generation uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if generation != c.generation {
+		return
+	}
+
+	if element, exists := c.items[metaId]; exists {
+		entry := element.Value.(*tokenCacheEntry)
+		entry.tokens = cloneUserTokens(tokens)
+		entry.expiresAt = c.now().Add(c.ttl)
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.items[metaId] = c.order.PushFront(&tokenCacheEntry{
+		metaId:    metaId,
+		tokens:    cloneUserTokens(tokens),
+		expiresAt: c.now().Add(c.ttl),
+	})
+
+	for c.order.Len() > c.size {
+		c.removeElement(c.order.Back())
+	}
+}
+
+// invalidate 使指定用户的缓存失效，metaId 为空时清空全部缓存
+func (c *tokenCache) invalidate(metaId string) {
+	if metaId == "" {
+		c.purge()
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+	if element, exists := c.items[metaId]; exists {
+		c.removeElement(element)
+	}
+}
+
+// purge 清空缓存
+func (c *tokenCache) purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+	c.items = make(map[string]*list.Element)
+	c.order.Init()
+}
+
+// len 返回缓存条目数
+func (c *tokenCache) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.order.Len()
+}
+
+// removeElement 移除缓存条目，调用方需持有锁
+func (c *tokenCache) removeElement(element *list.Element) {
+	entry := element.Value.(*tokenCacheEntry)
+	delete(c.items, entry.metaId)
+	c.order.Remove(element)
+}
+
+// cloneUserTokens 复制令牌结构，避免调用方修改缓存内容
+func cloneUserTokens(tokens *models.UserPushTokens) *models.UserPushTokens {
+	clone := *tokens
+	clone.Tokens = make(map[string]string, len(tokens.Tokens))
+	for platform, token := range tokens.Tokens {
+		clone.Tokens[platform] = token
+	}
+	return &clone
+}
@@ -0,0 +1,150 @@
+package pebble_service
+
+import (
+	"context"
+	"push-base-service/models"
+	"testing"
+	"time"
+)
+
+func TestTokenCacheLRUAndTTL(t *testing.T) {
+	cache := newTokenCache(2, time.Minute)
+	now := time.Unix(1700000000, 0)
+	cache.now = func() time.Time { return now }
+
+	tokens := func(metaId string) *models.UserPushTokens {
+		return &models.UserPushTokens{MetaID: metaId, Tokens: map[string]string{"expo": metaId + "-token"}}
+	}
+
+	cache.put("a", tokens("a"), cache.currentGeneration())
+	cache.put("b", tokens("b"), cache.currentGeneration())
+	cache.get("a") // a 成为最近使用
+	cache.put("c", tokens("c"), cache.currentGeneration())
+
+	if cache.get("b") != nil {
+		t.Error("least recently used entry b should have been evicted")
+	}
+	if cache.get("a") == nil || cache.get("c") == nil {
+		t.Error("entries a and c should still be cached")
+	}
+
+	// 返回副本，修改不影响缓存
+	cache.get("a").Tokens["expo"] = "mutated"
+	if got := cache.get("a").Tokens["expo"]; got != "a-token" {
+		t.Errorf("cached token = %q, want a-token", got)
+	}
+
+	now = now.Add(time.Minute)
+	if cache.get("a") != nil {
+		t.Error("entry a should have expired")
+	}
+}
+
+func TestTokenCacheSkipsStalePut(t *testing.T) {
+	cache := newTokenCache(10, time.Minute)
+
+	generation := cache.currentGeneration()
+	cache.invalidate("a") // 读取期间发生写入
+	cache.put("a", &models.UserPushTokens{MetaID: "a"}, generation)
+
+	if cache.get("a") != nil {
+		t.Error("put after a concurrent invalidation should be dropped")
+	}
+}
+
+func TestPebbleTokenStoreCacheInvalidation(t *testing.T) {
+	service := newTestPebbleService(t)
+	store := NewPebbleTokenStore(service)
+	store.EnableCache(100, time.Minute)
+	ctx := context.Background()
+
+	if err := service.SetUserToken("user-a", "expo", "token-1"); err != nil {
+		t.Fatalf("SetUserToken() failed, err: %v", err)
+	}
+	tokens, err := store.GetUserTokens(ctx, "user-a")
+	if err != nil || tokens.Tokens["expo"] != "token-1" {
+		t.Fatalf("GetUserTokens() = %+v, %v", tokens, err)
+	}
+	if store.CacheLen() != 1 {
+		t.Fatalf("CacheLen() = %d, want 1", store.CacheLen())
+	}
+
+	// 直接通过 PebbleService 写入同样会使缓存失效
+	if err := service.SetUserToken("user-a", "expo", "token-2"); err != nil {
+		t.Fatalf("SetUserToken() failed, err: %v", err)
+	}
+	all, err := store.GetAllUserTokens(ctx, []string{"user-a", "user-b"})
+	if err != nil {
+		t.Fatalf("GetAllUserTokens() failed, err: %v", err)
+	}
+	if all["user-a"].Tokens["expo"] != "token-2" {
+		t.Errorf("user-a token = %q, want token-2", all["user-a"].Tokens["expo"])
+	}
+	if len(all["user-b"].Tokens) != 0 {
+		t.Errorf("user-b tokens = %v, want empty", all["user-b"].Tokens)
+	}
+
+	if err := store.RemoveUserToken(ctx, "user-a", "expo"); err != nil {
+		t.Fatalf("RemoveUserToken() failed, err: %v", err)
+	}
+	tokens, _ = store.GetUserTokens(ctx, "user-a")
+	if len(tokens.Tokens) != 0 {
+		t.Errorf("tokens after RemoveUserToken() = %v, want empty", tokens.Tokens)
+	}
+}
+
+func TestHotUserTrackerOrderAndEviction(t *testing.T) {
+	tracker := newHotUserTracker(2)
+	tracker.touch("a")
+	tracker.touch("b")
+	tracker.touch("a")
+	tracker.touch("c")
+
+	got := tracker.snapshot()
+	if len(got) != 2 || got[0] != "c" || got[1] != "a" {
+		t.Errorf("snapshot() = %v, want [c a]", got)
+	}
+
+	var disabled *hotUserTracker
+	disabled.touch("a") // 未启用时不应 panic
+}
+
+func TestPebbleTokenStoreWarmUp(t *testing.T) {
+	service := newTestPebbleService(t)
+	ctx := context.Background()
+	for _, metaId := range []string{"a", "b", "c"} {
+		if err := service.SetUserToken(metaId, "expo", metaId+"-token"); err != nil {
+			t.Fatalf("SetUserToken() failed, err: %v", err)
+		}
+	}
+
+	// 上一次运行：记录活跃用户并保存
+	previous := NewPebbleTokenStore(service)
+	previous.EnableHotUserTracking(10)
+	previous.GetUserTokens(ctx, "a")
+	previous.GetAllUserTokens(ctx, []string{"b", "c"})
+	if count, err := previous.SaveHotUsers(); err != nil || count != 3 {
+		t.Fatalf("SaveHotUsers() = %d, %v; want 3", count, err)
+	}
+
+	// 待命实例没有活跃用户，不应覆盖已保存的列表
+	standby := NewPebbleTokenStore(service)
+	standby.EnableHotUserTracking(10)
+	if count, _ := standby.SaveHotUsers(); count != 0 {
+		t.Fatalf("SaveHotUsers() with no activity = %d, want 0", count)
+	}
+
+	store := NewPebbleTokenStore(service)
+	store.EnableCache(100, time.Minute)
+	store.EnableHotUserTracking(2)
+	count, err := store.WarmUp(ctx)
+	if err != nil || count != 2 {
+		t.Fatalf("WarmUp() = %d, %v; want 2", count, err)
+	}
+	if store.CacheLen() != 2 {
+		t.Errorf("CacheLen() after WarmUp() = %d, want 2", store.CacheLen())
+	}
+	if got := store.hot.snapshot(); len(got) != 2 || got[0] != "c" || got[1] != "b" {
+		t.Errorf("hot users after WarmUp() = %v, want [c b]", got)
+	}
+}
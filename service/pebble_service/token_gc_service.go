@@ -0,0 +1,224 @@
+package pebble_service
+
+import (
+	"fmt"
+	"log"
+	"push-base-service/models"
+	"slices"
+	"sync"
+	"time"
+)
+
+// tokenGCStatsKey 过期令牌清理统计的键
+const tokenGCStatsKey = "stats"
+
+// tokenGCMu 保证同一时间只有一次过期令牌清理
+var tokenGCMu sync.Mutex
+
+// userTokensRepo 用户令牌集合存储（只用于遍历，写入仍通过 SaveUserTokens 维护索引和缓存）
+func (ps *PebbleService) userTokensRepo() *repository[models.UserPushTokens] {
+	return newRepository[models.UserPushTokens](ps, CollectionUserTokens, "用户令牌")
+}
+
+// tokenGCRepo 过期令牌清理统计集合存储
+func (ps *PebbleService) tokenGCRepo() *repository[models.TokenGCStats] {
+	return newRepository[models.TokenGCStats](ps, CollectionTokenGC, "令牌清理统计")
+}
+
+// CollectStaleTokens 清理长期未刷新的令牌：
+// 最后一次注册或刷新早于 staleBefore（Unix 秒）的令牌移入 StaleTokens 停止推送，
+// 标记时间早于 purgeBefore 的过期令牌连同设备信息一并删除；返回累计后的清理统计
+// 令牌的刷新时间取设备记录的更新时间和最近活跃时间，设备记录不存在时使用用户令牌的更新时间
+func (ps *PebbleService) CollectStaleTokens(staleBefore, purgeBefore int64) (*models.TokenGCStats, error) {
+	tokenGCMu.Lock()
+	defer tokenGCMu.Unlock()
+
+	ps.mu.RLock()
+	var metaIds []string
+	err := ps.userTokensRepo().ScanPrefix("", func(key string, userTokens *models.UserPushTokens) bool {
+		if len(userTokens.Tokens) > 0 || len(userTokens.StaleTokens) > 0 {
+			metaIds = append(metaIds, userTokens.MetaID)
+		}
+		return true
+	})
+	ps.mu.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+
+	run := &models.TokenGCStats{LastRunAt: time.Now().Unix(), LastScanned: int64(len(metaIds))}
+	for _, metaId := range metaIds {
+		if err := ps.collectUserStaleTokens(metaId, staleBefore, purgeBefore, run); err != nil {
+			log.Printf("⚠️ 清理用户 %s 的过期令牌失败: %v", metaId, err)
+		}
+	}
+
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	repo := ps.tokenGCRepo()
+	stats, err := repo.Get(tokenGCStatsKey)
+	if err != nil {
+		return nil, err
+	}
+	if stats == nil {
+		stats = &models.TokenGCStats{}
+	}
+	stats.LastRunAt = run.LastRunAt
+	stats.LastScanned = run.LastScanned
+	stats.LastMarked = run.LastMarked
+	stats.LastPurged = run.LastPurged
+	stats.StaleTokens = run.StaleTokens
+	stats.TotalMarked += run.LastMarked
+	stats.TotalPurged += run.LastPurged
+	if err := repo.Put(tokenGCStatsKey, stats); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// collectUserStaleTokens 标记并删除单个用户的过期令牌，结果累加到 run
+func (ps *PebbleService) collectUserStaleTokens(metaId string, staleBefore, purgeBefore int64, run *models.TokenGCStats) error {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	userTokens, unlock, err := ps.lockUserAndDevices(metaId)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	if userTokens == nil {
+		return nil
+	}
+
+	devices, err := ps.getDevicesInfoLocked(userTokenList(userTokens))
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().Unix()
+	changed := false
+	for platform, token := range userTokens.Tokens {
+		refreshedAt := userTokens.UpdatedAt
+		if device := devices[token]; device != nil && device.MetaID == metaId {
+			refreshedAt = max(device.UpdatedAt, device.LastSeenAt)
+		}
+		if refreshedAt >= staleBefore {
+			continue
+		}
+		if userTokens.StaleTokens == nil {
+			userTokens.StaleTokens = make(map[string]*models.StaleToken)
+		}
+		userTokens.StaleTokens[platform] = &models.StaleToken{Token: token, StaleAt: now}
+		delete(userTokens.Tokens, platform)
+		run.LastMarked++
+		changed = true
+	}
+
+	var purged []string
+	for platform, stale := range userTokens.StaleTokens {
+		if stale.StaleAt >= purgeBefore {
+			run.StaleTokens++
+			continue
+		}
+		delete(userTokens.StaleTokens, platform)
+		if device := devices[stale.Token]; device != nil && device.MetaID == metaId {
+			if err := ps.deleteDeviceInfoLocked(stale.Token); err != nil {
+				log.Printf("⚠️ 删除过期令牌的设备信息失败: %v", err)
+			}
+		}
+		purged = append(purged, platform)
+		run.LastPurged++
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+
+	if len(userTokens.Tokens) == 0 && len(userTokens.StaleTokens) == 0 {
+		if err := ps.deleteUserTokensLocked(metaId); err != nil {
+			return err
+		}
+	} else if err := ps.saveUserTokensLocked(userTokens); err != nil {
+		return err
+	}
+	for _, platform := range purged {
+		ps.recordTokenEventLocked(platform, TokenEventRemoval)
+	}
+	return nil
+}
+
+// lockUserAndDevices 锁定用户及其全部令牌（含已过期令牌）对应的设备，返回加锁后读取的用户令牌（不存在时为 nil）和解锁函数
+// 令牌列表需要先读取才能知道，加锁后重新读取，若令牌在此期间被修改则重试，调用方需持有 ps.mu 读锁
+func (ps *PebbleService) lockUserAndDevices(metaId string) (*models.UserPushTokens, func(), error) {
+	for {
+		userTokens, err := ps.loadUserTokens(metaId)
+		if err != nil {
+			return nil, nil, err
+		}
+		tokens := userTokenList(userTokens)
+		keys := []string{userLockKey(metaId)}
+		for _, token := range tokens {
+			keys = append(keys, deviceLockKey(token))
+		}
+		unlock := ps.keyLocks.lock(keys...)
+
+		current, err := ps.loadUserTokens(metaId)
+		if err != nil {
+			unlock()
+			return nil, nil, err
+		}
+		if slices.Equal(userTokenList(current), tokens) {
+			return current, unlock, nil
+		}
+		unlock()
+	}
+}
+
+// userTokenList 用户的全部令牌（含已过期令牌），排序后返回
+func userTokenList(userTokens *models.UserPushTokens) []string {
+	if userTokens == nil {
+		return nil
+	}
+	tokens := make([]string, 0, len(userTokens.Tokens)+len(userTokens.StaleTokens))
+	for _, token := range userTokens.Tokens {
+		tokens = append(tokens, token)
+	}
+	for _, stale := range userTokens.StaleTokens {
+		tokens = append(tokens, stale.Token)
+	}
+	slices.Sort(tokens)
+	return tokens
+}
+
+// GetTokenGCStats 获取过期令牌清理统计，从未清理过时返回 nil
+func (ps *PebbleService) GetTokenGCStats() (*models.TokenGCStats, error) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	return ps.tokenGCRepo().Get(tokenGCStatsKey)
+}
+
+// CollectStaleTokens 全局方法：清理长期未刷新的令牌
+func CollectStaleTokens(staleBefore, purgeBefore int64) (*models.TokenGCStats, error) {
+	service := GetGlobalService()
+	if service == nil {
+		return nil, fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return nil, fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.CollectStaleTokens(staleBefore, purgeBefore)
+}
+
+// GetTokenGCStats 全局方法：获取过期令牌清理统计
+func GetTokenGCStats() (*models.TokenGCStats, error) {
+	service := GetGlobalService()
+	if service == nil {
+		return nil, fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return nil, fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.GetTokenGCStats()
+}
@@ -0,0 +1,79 @@
+package pebble_service
+
+import (
+	"push-base-service/models"
+	"testing"
+	"time"
+)
+
+func TestCollectStaleTokens(t *testing.T) {
+	service := newTestPebbleService(t)
+
+	for _, platform := range []string{"expo", "fcm"} {
+		if err := service.SetUserToken("gc-alice", platform, "gc-"+platform); err != nil {
+			t.Fatalf("SetUserToken() failed, err: %v", err)
+		}
+	}
+	// fcm 令牌很久没有刷新
+	old := time.Now().AddDate(0, 0, -100).Unix()
+	if err := service.devicesRepo().Put("gc-fcm", &models.DeviceInfo{DeviceID: "gc-fcm", Platform: "fcm", MetaID: "gc-alice", UpdatedAt: old}); err != nil {
+		t.Fatalf("put device failed, err: %v", err)
+	}
+
+	now := time.Now()
+	staleBefore := now.AddDate(0, 0, -90).Unix()
+	stats, err := service.CollectStaleTokens(staleBefore, now.AddDate(0, 0, -30).Unix())
+	if err != nil {
+		t.Fatalf("CollectStaleTokens() failed, err: %v", err)
+	}
+	if stats.LastMarked != 1 || stats.LastPurged != 0 || stats.StaleTokens != 1 {
+		t.Errorf("first run stats = %+v", stats)
+	}
+	userTokens, _ := service.GetUserTokens("gc-alice")
+	if _, ok := userTokens.Tokens["fcm"]; ok || userTokens.Tokens["expo"] != "gc-expo" || userTokens.StaleTokens["fcm"] == nil {
+		t.Errorf("user tokens after marking = %+v", userTokens)
+	}
+
+	// 标记时间早于删除截止时间后删除令牌和设备信息
+	stats, err = service.CollectStaleTokens(staleBefore, now.Add(time.Minute).Unix())
+	if err != nil {
+		t.Fatalf("CollectStaleTokens() failed, err: %v", err)
+	}
+	if stats.LastPurged != 1 || stats.TotalMarked != 1 || stats.TotalPurged != 1 || stats.StaleTokens != 0 {
+		t.Errorf("second run stats = %+v", stats)
+	}
+	userTokens, _ = service.GetUserTokens("gc-alice")
+	if len(userTokens.StaleTokens) != 0 || userTokens.Tokens["expo"] != "gc-expo" {
+		t.Errorf("user tokens after purge = %+v", userTokens)
+	}
+	if device, _ := service.devicesRepo().Get("gc-fcm"); device != nil {
+		t.Errorf("device of purged token still exists: %+v", device)
+	}
+
+	saved, err := service.GetTokenGCStats()
+	if err != nil || saved == nil || saved.TotalPurged != 1 {
+		t.Errorf("GetTokenGCStats() = %+v, %v", saved, err)
+	}
+}
+
+func TestStaleTokenRestoredOnRegistration(t *testing.T) {
+	service := newTestPebbleService(t)
+
+	if err := service.SetUserToken("gc-bob", "apns", "gc-apns"); err != nil {
+		t.Fatalf("SetUserToken() failed, err: %v", err)
+	}
+	if err := service.devicesRepo().Put("gc-apns", &models.DeviceInfo{DeviceID: "gc-apns", Platform: "apns", MetaID: "gc-bob", UpdatedAt: 1}); err != nil {
+		t.Fatalf("put device failed, err: %v", err)
+	}
+	if _, err := service.CollectStaleTokens(time.Now().Unix()-60, 0); err != nil {
+		t.Fatalf("CollectStaleTokens() failed, err: %v", err)
+	}
+
+	if err := service.SetUserToken("gc-bob", "apns", "gc-apns"); err != nil {
+		t.Fatalf("SetUserToken() failed, err: %v", err)
+	}
+	userTokens, _ := service.GetUserTokens("gc-bob")
+	if userTokens.Tokens["apns"] != "gc-apns" || len(userTokens.StaleTokens) != 0 {
+		t.Errorf("user tokens after re-registration = %+v", userTokens)
+	}
+}
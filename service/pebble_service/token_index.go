@@ -0,0 +1,311 @@
+package pebble_service
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"push-base-service/models"
+	"strings"
+	"sync"
+
+	"github.com/cockroachdb/pebble"
+)
+
+// 用户令牌二级索引键前缀
+const (
+	tokenIndexPlatformPrefix = "p:"      // p:平台:metaId，按平台筛选
+	tokenIndexUpdatedPrefix  = "u:"      // u:更新时间(20位):metaId，按更新时间筛选
+	tokenIndexBuiltKey       = "m:built" // 索引已完整构建的标记，缺失时首次筛选查询前重建
+)
+
+// tokenIndexMu 保证索引重建只执行一次
+var tokenIndexMu sync.Mutex
+
+// UserTokensFilter 用户令牌列表筛选条件，零值表示不筛选
+type UserTokensFilter struct {
+	Platform      string `json:"platform,omitempty"`      // 拥有该平台令牌的用户
+	MetaIDPrefix  string `json:"metaIdPrefix,omitempty"`  // metaId 前缀
+	UpdatedAfter  int64  `json:"updatedAfter,omitempty"`  // 更新时间晚于（Unix 秒，不含）
+	UpdatedBefore int64  `json:"updatedBefore,omitempty"` // 更新时间早于（Unix 秒，不含）
+}
+
+// IsEmpty 是否没有任何筛选条件
+func (f UserTokensFilter) IsEmpty() bool {
+	return f.Platform == "" && f.MetaIDPrefix == "" && f.UpdatedAfter == 0 && f.UpdatedBefore == 0
+}
+
+// Matches 用户令牌是否满足筛选条件
+func (f UserTokensFilter) Matches(userTokens *models.UserPushTokens) bool {
+	if !strings.HasPrefix(userTokens.MetaID, f.MetaIDPrefix) {
+		return false
+	}
+	if f.Platform != "" && userTokens.Tokens[f.Platform] == "" {
+		return false
+	}
+	if f.UpdatedAfter > 0 && userTokens.UpdatedAt <= f.UpdatedAfter {
+		return false
+	}
+	if f.UpdatedBefore > 0 && userTokens.UpdatedAt >= f.UpdatedBefore {
+		return false
+	}
+	return true
+}
+
+// getTokenPlatformIndexKey 生成平台索引键
+func getTokenPlatformIndexKey(platform, metaId string) string {
+	return tokenIndexPlatformPrefix + platform + ":" + metaId
+}
+
+// getTokenUpdatedIndexKey 生成更新时间索引键，时间补齐为 20 位使字典序与时间顺序一致
+func getTokenUpdatedIndexKey(updatedAt int64, metaId string) string {
+	return fmt.Sprintf("%s%020d:%s", tokenIndexUpdatedPrefix, updatedAt, metaId)
+}
+
+// tokenIndexKeys 列出用户令牌对应的全部索引键
+func tokenIndexKeys(userTokens *models.UserPushTokens) map[string]bool {
+	keys := make(map[string]bool)
+	if userTokens == nil || userTokens.MetaID == "" {
+		return keys
+	}
+	for platform, token := range userTokens.Tokens {
+		if token != "" {
+			keys[getTokenPlatformIndexKey(platform, userTokens.MetaID)] = true
+		}
+	}
+	keys[getTokenUpdatedIndexKey(userTokens.UpdatedAt, userTokens.MetaID)] = true
+	return keys
+}
+
+// updateTokenIndex 按新旧令牌记录的差异更新二级索引，newTokens 为 nil 表示用户已删除
+// 写入失败时清除构建标记，下次筛选查询前重建索引
+func (ps *PebbleService) updateTokenIndex(oldTokens, newTokens *models.UserPushTokens) {
+	db, err := ps.getCollectionDB(CollectionTokenIndex)
+	if err != nil {
+		log.Printf("⚠️ 获取用户令牌索引集合数据库失败: %v", err)
+		return
+	}
+
+	oldKeys, newKeys := tokenIndexKeys(oldTokens), tokenIndexKeys(newTokens)
+	batch := db.NewBatch()
+	defer batch.Close()
+	for key := range oldKeys {
+		if !newKeys[key] {
+			batch.Delete(buildKey(key), nil)
+		}
+	}
+	for key := range newKeys {
+		if !oldKeys[key] {
+			batch.Set(buildKey(key), nil, nil)
+		}
+	}
+	if batch.Empty() {
+		return
+	}
+	if err := batch.Commit(pebble.Sync); err != nil {
+		log.Printf("⚠️ 更新用户令牌索引失败，将在下次筛选查询前重建: %v", err)
+		db.Delete(buildKey(tokenIndexBuiltKey), pebble.Sync)
+	}
+}
+
+// ensureTokenIndex 索引未构建时（升级后首次查询、索引写入失败）从用户令牌集合重建
+func (ps *PebbleService) ensureTokenIndex() (*CollectionDB, error) {
+	db, err := ps.getCollectionDB(CollectionTokenIndex)
+	if err != nil {
+		return nil, fmt.Errorf("获取用户令牌索引集合数据库失败: %w", err)
+	}
+
+	tokenIndexMu.Lock()
+	defer tokenIndexMu.Unlock()
+
+	if _, closer, err := db.Get(buildKey(tokenIndexBuiltKey)); err == nil {
+		closer.Close()
+		return db, nil
+	} else if err != pebble.ErrNotFound {
+		return nil, fmt.Errorf("读取用户令牌索引标记失败: %w", err)
+	}
+
+	tokensDB, err := ps.getCollectionDB(CollectionUserTokens)
+	if err != nil {
+		return nil, fmt.Errorf("获取用户令牌集合数据库失败: %w", err)
+	}
+	iter, err := tokensDB.NewIter(nil)
+	if err != nil {
+		return nil, fmt.Errorf("创建迭代器失败: %w", err)
+	}
+	defer iter.Close()
+
+	// 先清空旧索引，避免残留已删除用户的键
+	batch := db.NewBatch()
+	batch.DeleteRange([]byte(tokenIndexPlatformPrefix), prefixUpperBound([]byte(tokenIndexPlatformPrefix)), nil)
+	batch.DeleteRange([]byte(tokenIndexUpdatedPrefix), prefixUpperBound([]byte(tokenIndexUpdatedPrefix)), nil)
+	count := 0
+	for iter.First(); iter.Valid(); iter.Next() {
+		var userTokens models.UserPushTokens
+		if err := json.Unmarshal(iter.Value(), &userTokens); err != nil {
+			continue
+		}
+		for key := range tokenIndexKeys(&userTokens) {
+			batch.Set(buildKey(key), nil, nil)
+		}
+		count++
+	}
+	if err := iter.Error(); err != nil {
+		batch.Close()
+		return nil, fmt.Errorf("迭代器错误: %w", err)
+	}
+	batch.Set(buildKey(tokenIndexBuiltKey), nil, nil)
+	if err := batch.Commit(pebble.Sync); err != nil {
+		batch.Close()
+		return nil, fmt.Errorf("重建用户令牌索引失败: %w", err)
+	}
+	batch.Close()
+
+	log.Printf("🔧 已重建用户令牌索引: 用户数=%d", count)
+	return db, nil
+}
+
+// tokenListScan 一次筛选查询的遍历方式：遍历的集合、键范围，以及从键中取出 metaId 的方法
+type tokenListScan struct {
+	db       *CollectionDB
+	lower    []byte
+	upper    []byte
+	indexed  bool                    // 遍历的是二级索引，需再读取用户令牌
+	metaIdOf func(key []byte) string // 从索引键中取出 metaId
+}
+
+// planTokenListScan 选择遍历范围最小的方式：有时间条件时遍历更新时间索引，有平台条件时遍历平台索引，否则按 metaId 前缀遍历用户令牌集合
+func (ps *PebbleService) planTokenListScan(filter UserTokensFilter) (*tokenListScan, error) {
+	if filter.UpdatedAfter == 0 && filter.UpdatedBefore == 0 && filter.Platform == "" {
+		db, err := ps.getCollectionDB(CollectionUserTokens)
+		if err != nil {
+			return nil, fmt.Errorf("获取用户令牌集合数据库失败: %w", err)
+		}
+		scan := &tokenListScan{db: db, metaIdOf: func(key []byte) string { return string(key) }}
+		if filter.MetaIDPrefix != "" {
+			scan.lower = buildKey(filter.MetaIDPrefix)
+			scan.upper = prefixUpperBound(scan.lower)
+		}
+		return scan, nil
+	}
+
+	db, err := ps.ensureTokenIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	if filter.UpdatedAfter > 0 || filter.UpdatedBefore > 0 {
+		scan := &tokenListScan{
+			db:      db,
+			indexed: true,
+			lower:   buildKey(getTokenUpdatedIndexKey(filter.UpdatedAfter+1, "")),
+			upper:   prefixUpperBound([]byte(tokenIndexUpdatedPrefix)),
+			metaIdOf: func(key []byte) string {
+				return string(key[len(tokenIndexUpdatedPrefix)+21:])
+			},
+		}
+		if filter.UpdatedBefore > 0 {
+			scan.upper = buildKey(getTokenUpdatedIndexKey(filter.UpdatedBefore, ""))
+		}
+		return scan, nil
+	}
+
+	prefix := getTokenPlatformIndexKey(filter.Platform, "")
+	lower := buildKey(prefix + filter.MetaIDPrefix)
+	return &tokenListScan{
+		db:      db,
+		indexed: true,
+		lower:   lower,
+		upper:   prefixUpperBound(lower),
+		metaIdOf: func(key []byte) string {
+			return string(key[len(prefix):])
+		},
+	}, nil
+}
+
+// GetUserTokensAfter 获取用户推送令牌列表（游标分页），支持按平台、更新时间和 metaId 前缀筛选
+// 无时间和平台条件时按 metaId 顺序读取用户令牌集合；否则遍历对应的二级索引范围，并以用户令牌的当前值复核条件，
+// 有时间条件时按更新时间顺序返回。游标为上一页最后遍历的键，只在相同筛选条件下有效
+func (ps *PebbleService) GetUserTokensAfter(filter UserTokensFilter, cursor string, pageSize int) (*PaginatedUserTokens, error) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	after, err := DecodeTokensCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+	pageSize = normalizePageSize(pageSize)
+	if filter.UpdatedBefore > 0 && filter.UpdatedBefore <= filter.UpdatedAfter {
+		return nil, fmt.Errorf("updatedBefore 必须晚于 updatedAfter")
+	}
+
+	scan, err := ps.planTokenListScan(filter)
+	if err != nil {
+		return nil, err
+	}
+	if after != "" {
+		// 紧跟在游标键之后的最小键
+		if next := append(buildKey(after), 0); bytes.Compare(next, scan.lower) > 0 {
+			scan.lower = next
+		}
+	}
+
+	var tokensDB *CollectionDB
+	if scan.indexed {
+		if tokensDB, err = ps.getCollectionDB(CollectionUserTokens); err != nil {
+			return nil, fmt.Errorf("获取用户令牌集合数据库失败: %w", err)
+		}
+	}
+
+	iter, err := scan.db.NewIter(&pebble.IterOptions{LowerBound: scan.lower, UpperBound: scan.upper})
+	if err != nil {
+		return nil, fmt.Errorf("创建迭代器失败: %w", err)
+	}
+	defer iter.Close()
+
+	result := &PaginatedUserTokens{Users: []*models.UserPushTokens{}, PageSize: pageSize}
+	lastKey := ""
+	for iter.First(); iter.Valid(); iter.Next() {
+		key := string(iter.Key())
+		metaId := scan.metaIdOf(iter.Key())
+		if !strings.HasPrefix(metaId, filter.MetaIDPrefix) {
+			continue
+		}
+
+		value := iter.Value()
+		if scan.indexed {
+			stored, closer, err := tokensDB.Get(getUserTokensKey(metaId))
+			if err == pebble.ErrNotFound {
+				continue // 索引残留，用户已删除
+			}
+			if err != nil {
+				return nil, fmt.Errorf("获取用户令牌失败: %w", err)
+			}
+			value = append([]byte(nil), stored...)
+			closer.Close()
+		}
+
+		var userTokens models.UserPushTokens
+		if err := json.Unmarshal(value, &userTokens); err != nil {
+			log.Printf("⚠️ 跳过解析失败的记录: %s, 错误: %v", metaId, err)
+			continue
+		}
+		// 索引可能落后于用户令牌的当前值，以当前值为准
+		if !filter.Matches(&userTokens) {
+			continue
+		}
+
+		// 已满一页时再找到一条满足条件的记录，说明有下一页
+		if len(result.Users) == pageSize {
+			result.HasNext = true
+			result.NextCursor = EncodeTokensCursor(lastKey)
+			break
+		}
+		result.Users = append(result.Users, &userTokens)
+		lastKey = key
+	}
+	if err := iter.Error(); err != nil {
+		return nil, fmt.Errorf("迭代器错误: %w", err)
+	}
+	return result, nil
+}
@@ -0,0 +1,161 @@
+package pebble_service
+
+import (
+	"encoding/json"
+	"fmt"
+	"push-base-service/models"
+	"testing"
+
+	"github.com/cockroachdb/pebble"
+)
+
+func TestGetUserTokensPagination(t *testing.T) {
+	service := newTestPebbleService(t)
+	for _, metaId := range []string{"d", "b", "e", "a", "c"} {
+		if err := service.SetUserToken(metaId, "expo", "token-"+metaId); err != nil {
+			t.Fatalf("SetUserToken(%s) failed, err: %v", metaId, err)
+		}
+	}
+
+	// 游标分页：按 metaId 顺序逐页读取，直到没有下一页
+	var metaIds []string
+	cursor := ""
+	for pages := 0; ; pages++ {
+		if pages > 3 {
+			t.Fatal("cursor pagination did not terminate")
+		}
+		page, err := service.GetUserTokensAfter(UserTokensFilter{}, cursor, 2)
+		if err != nil {
+			t.Fatalf("GetUserTokensAfter(%q) failed, err: %v", cursor, err)
+		}
+		for _, user := range page.Users {
+			metaIds = append(metaIds, user.MetaID)
+		}
+		if !page.HasNext {
+			if page.NextCursor != "" {
+				t.Errorf("last page NextCursor = %q, want empty", page.NextCursor)
+			}
+			break
+		}
+		cursor = page.NextCursor
+	}
+	if got := len(metaIds); got != 5 || metaIds[0] != "a" || metaIds[4] != "e" {
+		t.Errorf("cursor pages = %v, want [a b c d e]", metaIds)
+	}
+
+	// 页码分页只解析当前页，并返回可切换到游标分页的 nextCursor
+	page, err := service.GetUserTokensList(2, 2)
+	if err != nil {
+		t.Fatalf("GetUserTokensList() failed, err: %v", err)
+	}
+	if page.Total != 5 || page.TotalPages != 3 || !page.HasNext || len(page.Users) != 2 || page.Users[0].MetaID != "c" {
+		t.Fatalf("GetUserTokensList(2, 2) = %+v", page)
+	}
+	next, err := service.GetUserTokensAfter(UserTokensFilter{}, page.NextCursor, 2)
+	if err != nil || len(next.Users) != 1 || next.Users[0].MetaID != "e" || next.HasNext {
+		t.Errorf("GetUserTokensAfter(nextCursor) = %+v, %v", next, err)
+	}
+
+	if _, err := service.GetUserTokensAfter(UserTokensFilter{}, "not base64!", 2); err == nil {
+		t.Error("GetUserTokensAfter() with invalid cursor should fail")
+	}
+}
+
+func TestGetUserTokensFiltered(t *testing.T) {
+	service := newTestPebbleService(t)
+	for _, c := range []struct{ metaId, platform string }{
+		{"alice", "expo"}, {"alan", "fcm"}, {"amy", "expo"}, {"bob", "expo"}, {"alex", "expo"},
+	} {
+		if err := service.SetUserToken(c.metaId, c.platform, "token-"+c.metaId); err != nil {
+			t.Fatalf("SetUserToken(%s) failed, err: %v", c.metaId, err)
+		}
+	}
+
+	listAll := func(filter UserTokensFilter) []string {
+		t.Helper()
+		var metaIds []string
+		cursor := ""
+		for pages := 0; ; pages++ {
+			if pages > 5 {
+				t.Fatal("cursor pagination did not terminate")
+			}
+			page, err := service.GetUserTokensAfter(filter, cursor, 1)
+			if err != nil {
+				t.Fatalf("GetUserTokensAfter(%+v) failed, err: %v", filter, err)
+			}
+			for _, user := range page.Users {
+				metaIds = append(metaIds, user.MetaID)
+			}
+			if !page.HasNext {
+				return metaIds
+			}
+			cursor = page.NextCursor
+		}
+	}
+
+	cases := []struct {
+		filter UserTokensFilter
+		want   string
+	}{
+		{UserTokensFilter{MetaIDPrefix: "al"}, "[alan alex alice]"},
+		{UserTokensFilter{Platform: "expo"}, "[alex alice amy bob]"},
+		{UserTokensFilter{Platform: "expo", MetaIDPrefix: "a"}, "[alex alice amy]"},
+		{UserTokensFilter{Platform: "fcm", MetaIDPrefix: "b"}, "[]"},
+	}
+	for _, c := range cases {
+		if got := fmt.Sprint(listAll(c.filter)); got != c.want {
+			t.Errorf("filter %+v = %s, want %s", c.filter, got, c.want)
+		}
+	}
+
+	// 令牌变更后索引同步更新：移除平台、删除用户
+	if err := service.RemoveUserToken("alice", "expo"); err != nil {
+		t.Fatalf("RemoveUserToken() failed, err: %v", err)
+	}
+	if err := service.DeleteUserTokens("bob"); err != nil {
+		t.Fatalf("DeleteUserTokens() failed, err: %v", err)
+	}
+	if got := fmt.Sprint(listAll(UserTokensFilter{Platform: "expo"})); got != "[alex amy]" {
+		t.Errorf("expo users after changes = %s, want [alex amy]", got)
+	}
+
+	if _, err := service.GetUserTokensAfter(UserTokensFilter{UpdatedAfter: 10, UpdatedBefore: 5}, "", 10); err == nil {
+		t.Error("GetUserTokensAfter() with updatedBefore <= updatedAfter should fail")
+	}
+}
+
+func TestGetUserTokensUpdatedRange(t *testing.T) {
+	service := newTestPebbleService(t)
+
+	// 直接写入指定更新时间的记录（模拟升级前的数据），筛选查询前重建索引
+	db, err := service.getCollectionDB(CollectionUserTokens)
+	if err != nil {
+		t.Fatalf("getCollectionDB() failed, err: %v", err)
+	}
+	for _, user := range []*models.UserPushTokens{
+		{MetaID: "a", Tokens: map[string]string{"expo": "t-a"}, UpdatedAt: 300},
+		{MetaID: "b", Tokens: map[string]string{"fcm": "t-b"}, UpdatedAt: 100},
+		{MetaID: "c", Tokens: map[string]string{"expo": "t-c"}, UpdatedAt: 200},
+		{MetaID: "d", Tokens: map[string]string{"expo": "t-d"}, UpdatedAt: 400},
+	} {
+		data, _ := json.Marshal(user)
+		if err := db.Set(getUserTokensKey(user.MetaID), data, pebble.Sync); err != nil {
+			t.Fatalf("Set() failed, err: %v", err)
+		}
+	}
+
+	// 按更新时间顺序返回 (100, 400) 范围内的用户
+	page, err := service.GetUserTokensAfter(UserTokensFilter{UpdatedAfter: 100, UpdatedBefore: 400}, "", 1)
+	if err != nil || len(page.Users) != 1 || page.Users[0].MetaID != "c" || !page.HasNext {
+		t.Fatalf("first page = %+v, %v", page, err)
+	}
+	page, err = service.GetUserTokensAfter(UserTokensFilter{UpdatedAfter: 100, UpdatedBefore: 400}, page.NextCursor, 1)
+	if err != nil || len(page.Users) != 1 || page.Users[0].MetaID != "a" || page.HasNext {
+		t.Fatalf("second page = %+v, %v", page, err)
+	}
+
+	page, _ = service.GetUserTokensAfter(UserTokensFilter{UpdatedAfter: 150, Platform: "expo"}, "", 10)
+	if got := len(page.Users); got != 3 || page.Users[0].MetaID != "c" || page.Users[2].MetaID != "d" {
+		t.Errorf("updatedAfter+platform users = %+v", page.Users)
+	}
+}
@@ -0,0 +1,162 @@
+package pebble_service
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"push-base-service/models"
+	"push-base-service/service/metrics_service"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cockroachdb/pebble"
+)
+
+// 令牌事件类型
+const (
+	TokenEventRegistration = "registration" // 新设备注册令牌
+	TokenEventRemoval      = "removal"      // 移除令牌
+	TokenEventTransfer     = "transfer"     // 令牌从一个用户转移到另一个用户
+)
+
+// tokenMetricsDateLayout 每日统计的日期格式（UTC）
+const tokenMetricsDateLayout = "2006-01-02"
+
+var (
+	// tokenMetricsMu 保证每日计数"读取-累加-写入"的原子性
+	tokenMetricsMu sync.Mutex
+
+	tokenEventsCounter = metrics_service.NewCounterVec(
+		"push_token_events_total", "Number of push token registrations, removals and transfers", "platform", "event")
+)
+
+// getTokenMetricsKey 生成每日令牌统计的键，日期在前以便按日期范围扫描
+func getTokenMetricsKey(date, platform string) []byte {
+	return buildKey(date + ":" + platform)
+}
+
+// recordTokenEvent 记录一次令牌事件，统计失败只记录日志，不影响令牌本身的写入
+func (ps *PebbleService) recordTokenEvent(platform, event string) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	ps.recordTokenEventLocked(platform, event)
+}
+
+// recordTokenEventLocked 记录一次令牌事件，调用方需已持有 ps.mu 读锁
+func (ps *PebbleService) recordTokenEventLocked(platform, event string) {
+	tokenEventsCounter.Inc(platform, event)
+	if skipNonCriticalWrite(CollectionTokenMetrics) {
+		return
+	}
+	if err := ps.incrementTokenMetrics(time.Now().UTC(), platform, event, 1); err != nil {
+		log.Printf("⚠️ 记录令牌统计失败: 平台=%s, 事件=%s, 错误=%v", platform, event, err)
+	}
+}
+
+// incrementTokenMetrics 累加指定日期、平台的令牌事件计数
+func (ps *PebbleService) incrementTokenMetrics(day time.Time, platform, event string, delta int64) error {
+	if platform == "" {
+		return fmt.Errorf("平台不能为空")
+	}
+
+	db, err := ps.getCollectionDB(CollectionTokenMetrics)
+	if err != nil {
+		return fmt.Errorf("获取令牌统计集合数据库失败: %w", err)
+	}
+
+	tokenMetricsMu.Lock()
+	defer tokenMetricsMu.Unlock()
+
+	date := day.UTC().Format(tokenMetricsDateLayout)
+	key := getTokenMetricsKey(date, platform)
+
+	metrics := &models.TokenDailyMetrics{Date: date, Platform: platform}
+	value, closer, err := db.Get(key)
+	if err == nil {
+		unmarshalErr := json.Unmarshal(value, metrics)
+		closer.Close()
+		if unmarshalErr != nil {
+			return fmt.Errorf("反序列化令牌统计失败: %w", unmarshalErr)
+		}
+	} else if err != pebble.ErrNotFound {
+		return fmt.Errorf("获取令牌统计失败: %w", err)
+	}
+
+	switch event {
+	case TokenEventRegistration:
+		metrics.Registrations += delta
+	case TokenEventRemoval:
+		metrics.Removals += delta
+	case TokenEventTransfer:
+		metrics.Transfers += delta
+	default:
+		return fmt.Errorf("未知的令牌事件类型: %s", event)
+	}
+	metrics.UpdatedAt = time.Now().Unix()
+
+	data, err := json.Marshal(metrics)
+	if err != nil {
+		return fmt.Errorf("序列化令牌统计失败: %w", err)
+	}
+	if err := db.Set(key, data, pebble.Sync); err != nil {
+		return fmt.Errorf("保存令牌统计失败: %w", err)
+	}
+	return nil
+}
+
+// GetTokenMetrics 获取最近 days 天（含今天，UTC）的每日令牌统计，按日期、平台排序
+func (ps *PebbleService) GetTokenMetrics(days int) ([]*models.TokenDailyMetrics, error) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	if days <= 0 {
+		return nil, fmt.Errorf("统计天数必须大于 0")
+	}
+
+	db, err := ps.getCollectionDB(CollectionTokenMetrics)
+	if err != nil {
+		return nil, fmt.Errorf("获取令牌统计集合数据库失败: %w", err)
+	}
+
+	since := time.Now().UTC().AddDate(0, 0, -(days - 1)).Format(tokenMetricsDateLayout)
+	iter, err := db.NewIter(&pebble.IterOptions{LowerBound: []byte(since)})
+	if err != nil {
+		return nil, fmt.Errorf("创建迭代器失败: %w", err)
+	}
+	defer iter.Close()
+
+	metricsList := make([]*models.TokenDailyMetrics, 0)
+	for iter.First(); iter.Valid(); iter.Next() {
+		var metrics models.TokenDailyMetrics
+		if err := json.Unmarshal(iter.Value(), &metrics); err != nil {
+			log.Printf("⚠️ 反序列化令牌统计失败，已跳过: %v", err)
+			continue
+		}
+		metricsList = append(metricsList, &metrics)
+	}
+	if err := iter.Error(); err != nil {
+		return nil, fmt.Errorf("遍历令牌统计失败: %w", err)
+	}
+
+	sort.Slice(metricsList, func(i, j int) bool {
+		if metricsList[i].Date != metricsList[j].Date {
+			return metricsList[i].Date < metricsList[j].Date
+		}
+		return metricsList[i].Platform < metricsList[j].Platform
+	})
+	return metricsList, nil
+}
+
+// GetTokenMetrics 全局方法：获取最近 days 天的每日令牌统计
+func GetTokenMetrics(days int) ([]*models.TokenDailyMetrics, error) {
+	service := GetGlobalService()
+	if service == nil {
+		return nil, fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return nil, fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.GetTokenMetrics(days)
+}
@@ -0,0 +1,80 @@
+package pebble_service
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTokenMetricsTrackEvents(t *testing.T) {
+	service := newTestPebbleService(t)
+
+	// 新注册
+	if err := service.SetUserToken("user-a", "fcm", "token-1"); err != nil {
+		t.Fatalf("SetUserToken() failed, err: %v", err)
+	}
+	// 同一用户重复注册同一令牌不计数
+	if err := service.SetUserToken("user-a", "fcm", "token-1"); err != nil {
+		t.Fatalf("SetUserToken() failed, err: %v", err)
+	}
+	// 令牌转移到另一个用户
+	if err := service.SetUserToken("user-b", "fcm", "token-1"); err != nil {
+		t.Fatalf("SetUserToken() failed, err: %v", err)
+	}
+	if err := service.SetUserToken("user-b", "apns", "token-2"); err != nil {
+		t.Fatalf("SetUserToken() failed, err: %v", err)
+	}
+	if err := service.RemoveUserToken("user-b", "apns"); err != nil {
+		t.Fatalf("RemoveUserToken() failed, err: %v", err)
+	}
+	if err := service.DeleteUserTokens("user-b"); err != nil {
+		t.Fatalf("DeleteUserTokens() failed, err: %v", err)
+	}
+
+	metrics, err := service.GetTokenMetrics(1)
+	if err != nil {
+		t.Fatalf("GetTokenMetrics() failed, err: %v", err)
+	}
+	if len(metrics) != 2 {
+		t.Fatalf("GetTokenMetrics() returned %d entries, want 2", len(metrics))
+	}
+
+	apns, fcm := metrics[0], metrics[1]
+	if apns.Platform != "apns" || apns.Registrations != 1 || apns.Removals != 1 || apns.Transfers != 0 {
+		t.Errorf("apns metrics = %+v", apns)
+	}
+	if fcm.Platform != "fcm" || fcm.Registrations != 1 || fcm.Removals != 1 || fcm.Transfers != 1 {
+		t.Errorf("fcm metrics = %+v", fcm)
+	}
+}
+
+func TestGetTokenMetricsRange(t *testing.T) {
+	service := newTestPebbleService(t)
+
+	now := time.Now().UTC()
+	if err := service.incrementTokenMetrics(now.AddDate(0, 0, -10), "fcm", TokenEventRegistration, 3); err != nil {
+		t.Fatalf("incrementTokenMetrics() failed, err: %v", err)
+	}
+	if err := service.incrementTokenMetrics(now, "fcm", TokenEventRegistration, 1); err != nil {
+		t.Fatalf("incrementTokenMetrics() failed, err: %v", err)
+	}
+
+	recent, err := service.GetTokenMetrics(7)
+	if err != nil {
+		t.Fatalf("GetTokenMetrics() failed, err: %v", err)
+	}
+	if len(recent) != 1 || recent[0].Registrations != 1 {
+		t.Errorf("GetTokenMetrics(7) = %+v, want only today's entry", recent)
+	}
+
+	all, err := service.GetTokenMetrics(30)
+	if err != nil {
+		t.Fatalf("GetTokenMetrics() failed, err: %v", err)
+	}
+	if len(all) != 2 {
+		t.Errorf("GetTokenMetrics(30) returned %d entries, want 2", len(all))
+	}
+
+	if _, err := service.GetTokenMetrics(0); err == nil {
+		t.Error("GetTokenMetrics(0) should fail")
+	}
+}
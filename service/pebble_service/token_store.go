@@ -0,0 +1,124 @@
+package pebble_service
+
+import (
+	"context"
+	"push-base-service/models"
+)
+
+// 以下方法使 PebbleTokenStore 同时作为管理接口的令牌存储、屏蔽聊天存储、聊天通知声音存储和已通知 PIN 存储，
+// 与 Redis 存储后端提供相同的操作集合
+
+// SetUserTenant 设置用户所属租户
+func (pts *PebbleTokenStore) SetUserTenant(ctx context.Context, metaId, tenantId string) error {
+	return pts.service.SetUserTenant(metaId, tenantId)
+}
+
+// DeleteUserTokens 删除用户的所有推送令牌
+func (pts *PebbleTokenStore) DeleteUserTokens(ctx context.Context, metaId string) error {
+	return pts.service.DeleteUserTokens(metaId)
+}
+
+// ListUserTokens 分页获取用户令牌列表
+func (pts *PebbleTokenStore) ListUserTokens(ctx context.Context, page, pageSize int) (*PaginatedUserTokens, error) {
+	return pts.service.GetUserTokensList(page, pageSize)
+}
+
+// ListUserTokensAfter 按游标分页获取用户令牌列表，支持筛选
+func (pts *PebbleTokenStore) ListUserTokensAfter(ctx context.Context, filter UserTokensFilter, cursor string, pageSize int) (*PaginatedUserTokens, error) {
+	return pts.service.GetUserTokensAfter(filter, cursor, pageSize)
+}
+
+// AddBlockedChat 添加屏蔽聊天
+func (pts *PebbleTokenStore) AddBlockedChat(ctx context.Context, userId, chatId, chatType, reason string, muteUntil int64) error {
+	return pts.service.AddBlockedChat(userId, chatId, chatType, reason, muteUntil)
+}
+
+// RemoveBlockedChat 移除屏蔽聊天
+func (pts *PebbleTokenStore) RemoveBlockedChat(ctx context.Context, userId, chatId string) error {
+	return pts.service.RemoveBlockedChat(userId, chatId)
+}
+
+// IsBlockedChat 检查聊天是否被屏蔽
+func (pts *PebbleTokenStore) IsBlockedChat(ctx context.Context, userId, chatId string) (bool, error) {
+	return pts.service.IsBlockedChat(userId, chatId)
+}
+
+// GetUserBlockedChats 获取用户的所有屏蔽聊天
+func (pts *PebbleTokenStore) GetUserBlockedChats(ctx context.Context, userId string) (*models.UserBlockedChats, error) {
+	return pts.service.GetUserBlockedChats(userId)
+}
+
+// ListBlockedChats 分页获取用户的屏蔽聊天
+func (pts *PebbleTokenStore) ListBlockedChats(ctx context.Context, userId, cursor string, pageSize int) (*models.BlockedChatsPage, error) {
+	return pts.service.ListBlockedChats(userId, cursor, pageSize)
+}
+
+// BatchUpdateBlockedChats 批量屏蔽和取消屏蔽聊天
+func (pts *PebbleTokenStore) BatchUpdateBlockedChats(ctx context.Context, userId string, block []models.BlockedChat, unblock []string) (*models.BlockedChatsBatchResult, error) {
+	return pts.service.BatchUpdateBlockedChats(userId, block, unblock)
+}
+
+// IsNotifiedPin 检查PIN是否已通知
+func (pts *PebbleTokenStore) IsNotifiedPin(ctx context.Context, pinId string) (bool, error) {
+	return pts.service.IsNotifiedPin(pinId)
+}
+
+// AddNotifiedPin 添加已通知的PIN
+func (pts *PebbleTokenStore) AddNotifiedPin(ctx context.Context, pinId string) error {
+	return pts.service.AddNotifiedPin(pinId)
+}
+
+// ScanNotifiedPins 遍历所有已通知的PIN
+func (pts *PebbleTokenStore) ScanNotifiedPins(ctx context.Context, fn func(pinId string, notifiedAt int64) bool) error {
+	return pts.service.ScanNotifiedPins(fn)
+}
+
+// PurgeNotifiedPins 删除通知时间早于 before 的已通知PIN
+func (pts *PebbleTokenStore) PurgeNotifiedPins(ctx context.Context, before int64) (int, error) {
+	return pts.service.PurgeNotifiedPins(before)
+}
+
+// SetChatSound 设置聊天通知声音
+func (pts *PebbleTokenStore) SetChatSound(ctx context.Context, userId, chatId, chatType, sound string) error {
+	return pts.service.SetChatSound(userId, chatId, chatType, sound)
+}
+
+// GetChatSounds 批量获取用户在某个聊天的通知声音
+func (pts *PebbleTokenStore) GetChatSounds(ctx context.Context, userIds []string, chatId string) (map[string]string, error) {
+	return pts.service.GetChatSounds(userIds, chatId)
+}
+
+// GetUserChatSounds 获取用户的所有聊天通知声音
+func (pts *PebbleTokenStore) GetUserChatSounds(ctx context.Context, userId string) (*models.UserChatSounds, error) {
+	return pts.service.GetUserChatSounds(userId)
+}
+
+// AddBlockedSender 屏蔽发送者
+func (pts *PebbleTokenStore) AddBlockedSender(ctx context.Context, userId, senderId, reason string) error {
+	return pts.service.AddBlockedSender(userId, senderId, reason)
+}
+
+// RemoveBlockedSender 取消屏蔽发送者
+func (pts *PebbleTokenStore) RemoveBlockedSender(ctx context.Context, userId, senderId string) error {
+	return pts.service.RemoveBlockedSender(userId, senderId)
+}
+
+// IsBlockedSender 检查用户是否屏蔽了该发送者
+func (pts *PebbleTokenStore) IsBlockedSender(ctx context.Context, userId, senderId string) (bool, error) {
+	return pts.service.IsBlockedSender(userId, senderId)
+}
+
+// GetUserBlockedSenders 获取用户屏蔽的所有发送者
+func (pts *PebbleTokenStore) GetUserBlockedSenders(ctx context.Context, userId string) (*models.UserBlockedSenders, error) {
+	return pts.service.GetUserBlockedSenders(userId)
+}
+
+// SetAllGroupsBlocked 开启或关闭屏蔽所有群聊
+func (pts *PebbleTokenStore) SetAllGroupsBlocked(ctx context.Context, userId string, blocked bool) error {
+	return pts.service.SetAllGroupsBlocked(userId, blocked)
+}
+
+// GetAllGroupsBlock 获取用户的屏蔽所有群聊设置
+func (pts *PebbleTokenStore) GetAllGroupsBlock(ctx context.Context, userId string) (*models.AllGroupsBlock, error) {
+	return pts.service.GetAllGroupsBlock(userId)
+}
@@ -0,0 +1,280 @@
+package pebble_service
+
+import (
+	"fmt"
+	"push-base-service/models"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultTraceEventLimit 每个追踪用户默认保留的事件数
+const DefaultTraceEventLimit = 1000
+
+var (
+	// traceEventsMu 保证追踪事件"写入-裁剪"的原子性
+	traceEventsMu sync.Mutex
+	// traceEventSeq 同一毫秒内的事件序号，保证事件ID唯一且有序
+	traceEventSeq atomic.Uint64
+)
+
+// userTracesRepo 推送追踪用户集合存储，键为 metaId
+func (ps *PebbleService) userTracesRepo() *repository[models.UserTrace] {
+	return newRepository[models.UserTrace](ps, CollectionUserTraces, "推送追踪")
+}
+
+// traceEventsRepo 推送追踪事件集合存储
+func (ps *PebbleService) traceEventsRepo() *repository[models.TraceEvent] {
+	return newRepository[models.TraceEvent](ps, CollectionTraceEvents, "追踪事件")
+}
+
+// getTraceEventsPrefix 生成用户追踪事件的键前缀
+func getTraceEventsPrefix(metaId string) string {
+	return metaId + ":"
+}
+
+// SaveUserTrace 保存推送追踪（已存在时覆盖截止时间和备注）
+func (ps *PebbleService) SaveUserTrace(trace *models.UserTrace) error {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	if trace == nil || trace.MetaID == "" {
+		return fmt.Errorf("MetaID 不能为空")
+	}
+	if trace.CreatedAt == 0 {
+		trace.CreatedAt = time.Now().Unix()
+	}
+	return ps.userTracesRepo().Put(trace.MetaID, trace)
+}
+
+// GetUserTrace 获取用户的推送追踪，不存在时返回 nil
+func (ps *PebbleService) GetUserTrace(metaId string) (*models.UserTrace, error) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	return ps.userTracesRepo().Get(metaId)
+}
+
+// DeleteUserTrace 停止推送追踪，已记录的事件保留到过期清理
+func (ps *PebbleService) DeleteUserTrace(metaId string) error {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	return ps.userTracesRepo().Delete(metaId)
+}
+
+// ListUserTraces 列出所有推送追踪（含已过期但尚未清理的）
+func (ps *PebbleService) ListUserTraces() ([]*models.UserTrace, error) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	traces := []*models.UserTrace{}
+	err := ps.userTracesRepo().ScanPrefix("", func(key string, trace *models.UserTrace) bool {
+		traces = append(traces, trace)
+		return true
+	})
+	return traces, err
+}
+
+// AddTraceEvents 写入追踪事件，每个用户超过 limit 时丢弃最早的事件
+func (ps *PebbleService) AddTraceEvents(events []*models.TraceEvent, limit int) error {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	if limit <= 0 {
+		limit = DefaultTraceEventLimit
+	}
+
+	repo := ps.traceEventsRepo()
+
+	traceEventsMu.Lock()
+	defer traceEventsMu.Unlock()
+
+	now := time.Now()
+	users := make(map[string]bool)
+	for _, event := range events {
+		if event.MetaID == "" {
+			continue
+		}
+		if event.Timestamp == 0 {
+			event.Timestamp = now.UnixMilli()
+		}
+		if event.ID == "" {
+			event.ID = fmt.Sprintf("%020d-%06d", event.Timestamp, traceEventSeq.Add(1)%1000000)
+		}
+		if err := repo.Put(getTraceEventsPrefix(event.MetaID)+event.ID, event); err != nil {
+			return err
+		}
+		users[event.MetaID] = true
+	}
+
+	// 裁剪超出保留数量的旧事件
+	for metaId := range users {
+		prefix := getTraceEventsPrefix(metaId)
+		count := 0
+		oldestKept := ""
+		err := repo.ScanPrefixReverse(prefix, func(key string, _ *models.TraceEvent) bool {
+			count++
+			if count == limit {
+				oldestKept = key
+				return false
+			}
+			return true
+		})
+		if err != nil {
+			return err
+		}
+		if oldestKept == "" {
+			continue
+		}
+		if _, err := repo.DeleteWhere(prefix, func(key string, _ *models.TraceEvent) bool {
+			return key < oldestKept
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetTraceEvents 获取用户发生时间晚于 since（Unix 毫秒）的追踪事件，按时间升序
+// limit 大于 0 时只返回最新的 limit 条
+func (ps *PebbleService) GetTraceEvents(metaId string, since int64, limit int) ([]*models.TraceEvent, error) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	if metaId == "" {
+		return nil, fmt.Errorf("MetaID 不能为空")
+	}
+
+	events := []*models.TraceEvent{}
+	err := ps.traceEventsRepo().ScanPrefix(getTraceEventsPrefix(metaId), func(key string, event *models.TraceEvent) bool {
+		if event.Timestamp > since {
+			events = append(events, event)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if limit > 0 && len(events) > limit {
+		events = events[len(events)-limit:]
+	}
+	return events, nil
+}
+
+// PurgeExpiredTraces 删除截止时间早于 before（Unix 秒）的推送追踪及其事件，返回删除的追踪数
+func (ps *PebbleService) PurgeExpiredTraces(before int64) (int, error) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	var expired []string
+	err := ps.userTracesRepo().ScanPrefix("", func(key string, trace *models.UserTrace) bool {
+		if trace.Until < before {
+			expired = append(expired, trace.MetaID)
+		}
+		return true
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	traceEventsMu.Lock()
+	defer traceEventsMu.Unlock()
+
+	for _, metaId := range expired {
+		if err := ps.userTracesRepo().Delete(metaId); err != nil {
+			return 0, err
+		}
+		if _, err := ps.traceEventsRepo().DeleteWhere(getTraceEventsPrefix(metaId), func(string, *models.TraceEvent) bool {
+			return true
+		}); err != nil {
+			return 0, err
+		}
+	}
+	return len(expired), nil
+}
+
+// SaveUserTrace 全局方法：保存推送追踪
+func SaveUserTrace(trace *models.UserTrace) error {
+	service := GetGlobalService()
+	if service == nil {
+		return fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.SaveUserTrace(trace)
+}
+
+// GetUserTrace 全局方法：获取用户的推送追踪
+func GetUserTrace(metaId string) (*models.UserTrace, error) {
+	service := GetGlobalService()
+	if service == nil {
+		return nil, fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return nil, fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.GetUserTrace(metaId)
+}
+
+// DeleteUserTrace 全局方法：停止推送追踪
+func DeleteUserTrace(metaId string) error {
+	service := GetGlobalService()
+	if service == nil {
+		return fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.DeleteUserTrace(metaId)
+}
+
+// ListUserTraces 全局方法：列出所有推送追踪
+func ListUserTraces() ([]*models.UserTrace, error) {
+	service := GetGlobalService()
+	if service == nil {
+		return nil, fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return nil, fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.ListUserTraces()
+}
+
+// AddTraceEvents 全局方法：写入追踪事件
+func AddTraceEvents(events []*models.TraceEvent, limit int) error {
+	service := GetGlobalService()
+	if service == nil {
+		return fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.AddTraceEvents(events, limit)
+}
+
+// GetTraceEvents 全局方法：获取用户的追踪事件
+func GetTraceEvents(metaId string, since int64, limit int) ([]*models.TraceEvent, error) {
+	service := GetGlobalService()
+	if service == nil {
+		return nil, fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return nil, fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.GetTraceEvents(metaId, since, limit)
+}
+
+// PurgeExpiredTraces 全局方法：删除过期的推送追踪及其事件
+func PurgeExpiredTraces(before int64) (int, error) {
+	service := GetGlobalService()
+	if service == nil {
+		return 0, fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return 0, fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.PurgeExpiredTraces(before)
+}
@@ -0,0 +1,98 @@
+package pebble_service
+
+import (
+	"fmt"
+	"push-base-service/models"
+	"time"
+)
+
+// translationsRepo 翻译缓存集合存储
+func (ps *PebbleService) translationsRepo() *repository[models.CachedTranslation] {
+	return newRepository[models.CachedTranslation](ps, CollectionTranslations, "翻译缓存")
+}
+
+// getTranslationKey 生成翻译缓存的键
+func getTranslationKey(messageId, locale string) string {
+	return messageId + ":" + locale
+}
+
+// GetCachedTranslation 获取已缓存的翻译，不存在或已过期时返回 nil
+func (ps *PebbleService) GetCachedTranslation(messageId, locale string) (*models.CachedTranslation, error) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	if messageId == "" || locale == "" {
+		return nil, fmt.Errorf("消息ID和语言不能为空")
+	}
+
+	translation, err := ps.translationsRepo().Get(getTranslationKey(messageId, locale))
+	if err != nil || translation == nil {
+		return nil, err
+	}
+	if translation.ExpiresAt > 0 && translation.ExpiresAt <= time.Now().Unix() {
+		return nil, nil
+	}
+	return translation, nil
+}
+
+// SaveCachedTranslation 保存翻译缓存
+func (ps *PebbleService) SaveCachedTranslation(translation *models.CachedTranslation) error {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	if translation == nil || translation.MessageID == "" || translation.Locale == "" {
+		return fmt.Errorf("消息ID和语言不能为空")
+	}
+	if skipNonCriticalWrite(CollectionTranslations) {
+		return nil
+	}
+
+	return ps.translationsRepo().Put(getTranslationKey(translation.MessageID, translation.Locale), translation)
+}
+
+// PurgeExpiredTranslations 清理已过期的翻译缓存，返回清理数量
+func (ps *PebbleService) PurgeExpiredTranslations() (int, error) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	now := time.Now().Unix()
+	return ps.translationsRepo().DeleteWhere("", func(key string, translation *models.CachedTranslation) bool {
+		return translation.ExpiresAt > 0 && translation.ExpiresAt <= now
+	})
+}
+
+// GetCachedTranslation 全局方法：获取已缓存的翻译
+func GetCachedTranslation(messageId, locale string) (*models.CachedTranslation, error) {
+	service := GetGlobalService()
+	if service == nil {
+		return nil, fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return nil, fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.GetCachedTranslation(messageId, locale)
+}
+
+// SaveCachedTranslation 全局方法：保存翻译缓存
+func SaveCachedTranslation(translation *models.CachedTranslation) error {
+	service := GetGlobalService()
+	if service == nil {
+		return fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.SaveCachedTranslation(translation)
+}
+
+// PurgeExpiredTranslations 全局方法：清理已过期的翻译缓存
+func PurgeExpiredTranslations() (int, error) {
+	service := GetGlobalService()
+	if service == nil {
+		return 0, fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	if !service.IsInitialized() {
+		return 0, fmt.Errorf("Pebble 服务未正确初始化")
+	}
+	return service.PurgeExpiredTranslations()
+}
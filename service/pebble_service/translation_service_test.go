@@ -0,0 +1,62 @@
+package pebble_service
+
+import (
+	"push-base-service/models"
+	"testing"
+	"time"
+)
+
+func TestUserPreferences(t *testing.T) {
+	service := newTestPebbleService(t)
+
+	if preferences, err := service.GetUserPreferences("user-a"); err != nil || preferences != nil {
+		t.Fatalf("GetUserPreferences() before save = %+v, %v; want nil", preferences, err)
+	}
+
+	if err := service.SaveUserPreferences(&models.UserPreferences{MetaID: "user-a", Locale: "ja", TranslatePreviews: true}); err != nil {
+		t.Fatalf("SaveUserPreferences() failed, err: %v", err)
+	}
+
+	batch, err := service.GetUserPreferencesBatch([]string{"user-a", "user-b"})
+	if err != nil {
+		t.Fatalf("GetUserPreferencesBatch() failed, err: %v", err)
+	}
+	if len(batch) != 1 || batch["user-a"] == nil || batch["user-a"].Locale != "ja" || !batch["user-a"].TranslatePreviews {
+		t.Errorf("GetUserPreferencesBatch() = %+v, want only user-a with locale ja", batch)
+	}
+}
+
+func TestCachedTranslationExpiry(t *testing.T) {
+	service := newTestPebbleService(t)
+	now := time.Now().Unix()
+
+	if err := service.SaveCachedTranslation(&models.CachedTranslation{
+		MessageID: "pin-1", Locale: "ja", Text: "こんにちは", CreatedAt: now, ExpiresAt: now + 3600,
+	}); err != nil {
+		t.Fatalf("SaveCachedTranslation() failed, err: %v", err)
+	}
+	if err := service.SaveCachedTranslation(&models.CachedTranslation{
+		MessageID: "pin-2", Locale: "ja", Text: "expired", CreatedAt: now - 7200, ExpiresAt: now - 3600,
+	}); err != nil {
+		t.Fatalf("SaveCachedTranslation() failed, err: %v", err)
+	}
+
+	cached, err := service.GetCachedTranslation("pin-1", "ja")
+	if err != nil || cached == nil || cached.Text != "こんにちは" {
+		t.Fatalf("GetCachedTranslation(pin-1) = %+v, %v; want こんにちは", cached, err)
+	}
+	if cached, _ := service.GetCachedTranslation("pin-1", "de"); cached != nil {
+		t.Errorf("GetCachedTranslation(pin-1, de) = %+v, want nil", cached)
+	}
+	if cached, _ := service.GetCachedTranslation("pin-2", "ja"); cached != nil {
+		t.Errorf("GetCachedTranslation(expired) = %+v, want nil", cached)
+	}
+
+	count, err := service.PurgeExpiredTranslations()
+	if err != nil || count != 1 {
+		t.Errorf("PurgeExpiredTranslations() = %d, %v; want 1", count, err)
+	}
+	if cached, _ := service.GetCachedTranslation("pin-1", "ja"); cached == nil {
+		t.Error("unexpired translation should survive purge")
+	}
+}
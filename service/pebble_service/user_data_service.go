@@ -0,0 +1,179 @@
+package pebble_service
+
+import (
+	"fmt"
+	"push-base-service/models"
+	"slices"
+	"time"
+
+	"github.com/cockroachdb/pebble"
+)
+
+// DeleteUserDevices 删除关联到该用户的所有设备信息，返回删除数量
+// 设备按设备ID存储，需要遍历整个设备集合
+func (ps *PebbleService) DeleteUserDevices(metaId string) (int, error) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	if metaId == "" {
+		return 0, fmt.Errorf("MetaID 不能为空")
+	}
+
+	var deviceIds []string
+	err := ps.devicesRepo().ScanPrefix("", func(key string, device *models.DeviceInfo) bool {
+		if device.MetaID == metaId {
+			deviceIds = append(deviceIds, key)
+		}
+		return true
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	deleted := 0
+	for _, deviceId := range deviceIds {
+		unlock := ps.keyLocks.lock(deviceLockKey(deviceId))
+		// 加锁后重新确认，期间设备可能已被其他用户重新注册
+		device, err := ps.loadDeviceInfo(deviceId)
+		if err == nil && device != nil && device.MetaID == metaId {
+			err = ps.deleteDeviceInfoLocked(deviceId)
+			if err == nil {
+				deleted++
+			}
+		}
+		unlock()
+		if err != nil {
+			return deleted, err
+		}
+	}
+	return deleted, nil
+}
+
+// DeleteUserDeliveries 删除发给该用户的投递记录（含提及通知的投递历史）和待查询回执，返回删除数量
+// 投递记录按 pinId 在前的键存储，需要遍历整个集合
+func (ps *PebbleService) DeleteUserDeliveries(metaId string) (records, receipts int, err error) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	if metaId == "" {
+		return 0, 0, fmt.Errorf("MetaID 不能为空")
+	}
+
+	records, err = ps.deliveriesRepo().DeleteWhere("", func(_ string, record *models.DeliveryRecord) bool {
+		return record.MetaID == metaId
+	})
+	if err != nil {
+		return records, 0, err
+	}
+	receipts, err = ps.receiptsRepo().DeleteWhere("", func(_ string, receipt *models.PendingReceipt) bool {
+		return receipt.MetaID == metaId
+	})
+	return records, receipts, err
+}
+
+// DeleteUserPushAudits 删除用户的全部推送审计记录，返回删除数量
+func (ps *PebbleService) DeleteUserPushAudits(metaId string) (int, error) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	if metaId == "" {
+		return 0, fmt.Errorf("MetaID 不能为空")
+	}
+
+	return ps.pushAuditRepo().DeleteWhere(getPushAuditPrefix(metaId), func(string, *models.PushAuditEntry) bool {
+		return true
+	})
+}
+
+// DeleteUserTraceData 停止推送追踪并删除已记录的追踪事件，返回删除的事件数
+func (ps *PebbleService) DeleteUserTraceData(metaId string) (int, error) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	if metaId == "" {
+		return 0, fmt.Errorf("MetaID 不能为空")
+	}
+
+	if err := ps.userTracesRepo().Delete(metaId); err != nil {
+		return 0, err
+	}
+	return ps.traceEventsRepo().DeleteWhere(getTraceEventsPrefix(metaId), func(string, *models.TraceEvent) bool {
+		return true
+	})
+}
+
+// EraseSubscription 删除用户的退订原因和变更历史，返回是否存在记录以及是否保留了退订标记；
+// 用户仍处于退订状态时记录缩减为只含退订标记，删除数据后不会重新向已退订的用户推送
+func (ps *PebbleService) EraseSubscription(metaId string) (existed, suppressed bool, err error) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	if metaId == "" {
+		return false, false, fmt.Errorf("MetaID 不能为空")
+	}
+
+	subscriptionMu.Lock()
+	defer subscriptionMu.Unlock()
+
+	repo := ps.subscriptionsRepo()
+	subscription, err := repo.Get(metaId)
+	if err != nil || subscription == nil {
+		return false, false, err
+	}
+	if !subscription.Unsubscribed {
+		return true, false, repo.Delete(metaId)
+	}
+	return true, true, repo.Put(metaId, &models.PushSubscription{
+		MetaID:       metaId,
+		Unsubscribed: true,
+		UpdatedAt:    time.Now().Unix(),
+		History:      []models.SubscriptionEvent{},
+	})
+}
+
+// RemoveHotUser 从已保存的最近活跃用户列表中移除用户，返回是否在列表中
+func (ps *PebbleService) RemoveHotUser(metaId string) (bool, error) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	if metaId == "" {
+		return false, fmt.Errorf("MetaID 不能为空")
+	}
+
+	repo := ps.hotUsersRepo()
+	hotUsers, err := repo.Get(hotUsersKey)
+	if err != nil || hotUsers == nil || !slices.Contains(hotUsers.MetaIDs, metaId) {
+		return false, err
+	}
+	hotUsers.MetaIDs = slices.DeleteFunc(hotUsers.MetaIDs, func(id string) bool { return id == metaId })
+	return true, repo.Put(hotUsersKey, hotUsers)
+}
+
+// DeleteUserMergeRecords 删除源或目标为该用户的合并审计记录，返回删除数量
+func (ps *PebbleService) DeleteUserMergeRecords(metaId string) (int, error) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	if metaId == "" {
+		return 0, fmt.Errorf("MetaID 不能为空")
+	}
+
+	return ps.userMergesRepo().DeleteWhere("", func(_ string, record *models.UserMergeRecord) bool {
+		return record.SourceMetaID == metaId || record.TargetMetaID == metaId
+	})
+}
+
+// DeleteThrottleWindow 删除用户的推送限流窗口
+func (ps *PebbleService) DeleteThrottleWindow(metaId string) error {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	db, err := ps.getCollectionDB(CollectionThrottle)
+	if err != nil {
+		return fmt.Errorf("获取限流集合数据库失败: %w", err)
+	}
+	if err := db.Delete(getThrottleKey(metaId), pebble.Sync); err != nil {
+		return fmt.Errorf("删除限流窗口失败: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,257 @@
+package pebble_service
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"github.com/cockroachdb/pebble"
+)
+
+// txnSeq 同一纳秒内提交多个事务时区分事务ID
+var txnSeq atomic.Uint32
+
+const (
+	// txnRetryAttempts 跨实例事务部分提交后，立即重试剩余写操作的次数
+	txnRetryAttempts = 5
+	// txnRetryBackoff 重试剩余写操作的初始间隔，每次翻倍
+	txnRetryBackoff = 20 * time.Millisecond
+)
+
+// txnBatch 同一个 Pebble 实例上的批次及其包含的写操作
+type txnBatch struct {
+	batch     *pebble.Batch
+	mutations []txnMutation
+}
+
+// txnMutation 事务中的单个写操作（整值覆盖或删除，重放是幂等的）
+type txnMutation struct {
+	Collection string `json:"c"`
+	Key        []byte `json:"k"`
+	Value      []byte `json:"v,omitempty"`
+	Delete     bool   `json:"d,omitempty"`
+}
+
+// writeTxn 跨集合写事务：先暂存全部写操作，Commit 时一起提交
+// 写操作都落在同一个 Pebble 实例上时（共享布局）直接作为一个批次原子提交；
+// 跨实例时先把全部写操作写入事务日志，再逐个实例提交批次，最后删除日志。
+// 运行中某个批次提交失败时立即重试剩余的写操作（日志同时缩减为剩余部分），中途崩溃时下次启动按日志重放
+type writeTxn struct {
+	ps        *PebbleService
+	mutations []txnMutation
+}
+
+// newWriteTxn 创建写事务
+func (ps *PebbleService) newWriteTxn() *writeTxn {
+	return &writeTxn{ps: ps}
+}
+
+// Set 暂存写入
+func (tx *writeTxn) Set(collection string, key, value []byte) {
+	tx.mutations = append(tx.mutations, txnMutation{Collection: collection, Key: key, Value: value})
+}
+
+// Delete 暂存删除
+func (tx *writeTxn) Delete(collection string, key []byte) {
+	tx.mutations = append(tx.mutations, txnMutation{Collection: collection, Key: key, Delete: true})
+}
+
+// Commit 提交事务，调用方需持有 ps.mu
+func (tx *writeTxn) Commit() error {
+	if len(tx.mutations) == 0 {
+		return nil
+	}
+
+	batches, err := tx.ps.buildTxnBatches(tx.mutations)
+	if err != nil {
+		return err
+	}
+	if len(batches) == 1 {
+		if _, err := commitBatches(batches); err != nil {
+			return fmt.Errorf("提交事务失败: %w", err)
+		}
+		return nil
+	}
+
+	id, err := tx.ps.appendTxnLog(tx.mutations)
+	if err != nil {
+		closeBatches(batches)
+		return err
+	}
+	if committed, err := commitBatches(batches); err != nil {
+		log.Printf("⚠️ 事务提交 %d/%d 个批次后失败，立即重试剩余写操作: %v", committed, len(batches), err)
+		if err := tx.ps.rollForward(id, pendingMutations(batches[committed:])); err != nil {
+			return fmt.Errorf("提交事务失败，剩余写操作将在下次启动时重放: %w", err)
+		}
+	}
+	if err := tx.ps.deleteTxnLog(id); err != nil {
+		log.Printf("⚠️ 删除事务日志失败，下次启动时将重放（幂等）: %v", err)
+	}
+	return nil
+}
+
+// appendTxnLog 写入事务日志，返回日志键
+func (ps *PebbleService) appendTxnLog(mutations []txnMutation) ([]byte, error) {
+	id := buildKey(fmt.Sprintf("%020d:%05d", time.Now().UnixNano(), txnSeq.Add(1)%100000))
+	if err := ps.writeTxnLog(id, mutations); err != nil {
+		return nil, err
+	}
+	return id, nil
+}
+
+// writeTxnLog 写入（或覆盖）事务日志条目
+func (ps *PebbleService) writeTxnLog(id []byte, mutations []txnMutation) error {
+	logDB, err := ps.getCollectionDB(CollectionTxnLog)
+	if err != nil {
+		return fmt.Errorf("获取事务日志集合数据库失败: %w", err)
+	}
+	data, err := json.Marshal(mutations)
+	if err != nil {
+		return fmt.Errorf("序列化事务失败: %w", err)
+	}
+	if err := logDB.Set(id, data, pebble.Sync); err != nil {
+		return fmt.Errorf("写入事务日志失败: %w", err)
+	}
+	return nil
+}
+
+// rollForward 事务部分提交后立即重试剩余的写操作，成功后由调用方删除事务日志
+// 先把事务日志缩减为剩余的写操作：已提交的批次不会在下次启动时被重放，从而不会覆盖之后写入的新值；
+// 调用方持有相关键的锁，重试期间这些键没有新的写入，重新提交整值覆盖是安全的
+func (ps *PebbleService) rollForward(id []byte, mutations []txnMutation) error {
+	if err := ps.writeTxnLog(id, mutations); err != nil {
+		log.Printf("⚠️ 缩减事务日志失败: %v", err)
+	}
+
+	backoff := txnRetryBackoff
+	var lastErr error
+	for attempt := 1; attempt <= txnRetryAttempts; attempt++ {
+		time.Sleep(backoff)
+		backoff *= 2
+
+		batches, err := ps.buildTxnBatches(mutations)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		committed, err := commitBatches(batches)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		log.Printf("⚠️ 第 %d 次重试事务剩余写操作失败: %v", attempt, err)
+		if committed > 0 {
+			mutations = pendingMutations(batches[committed:])
+			if err := ps.writeTxnLog(id, mutations); err != nil {
+				log.Printf("⚠️ 缩减事务日志失败: %v", err)
+			}
+		}
+	}
+	return lastErr
+}
+
+// deleteTxnLog 删除已完成事务的日志
+func (ps *PebbleService) deleteTxnLog(id []byte) error {
+	logDB, err := ps.getCollectionDB(CollectionTxnLog)
+	if err != nil {
+		return err
+	}
+	return logDB.Delete(id, pebble.Sync)
+}
+
+// commitBatches 依次提交并释放批次，遇到错误后不再提交剩余批次，返回已提交的批次数
+func commitBatches(batches []*txnBatch) (int, error) {
+	committed := 0
+	var firstErr error
+	for _, batch := range batches {
+		if firstErr == nil {
+			if firstErr = batch.batch.Commit(pebble.Sync); firstErr == nil {
+				committed++
+			}
+		}
+		batch.batch.Close()
+	}
+	return committed, firstErr
+}
+
+// closeBatches 释放未提交的批次
+func closeBatches(batches []*txnBatch) {
+	for _, batch := range batches {
+		batch.batch.Close()
+	}
+}
+
+// pendingMutations 未提交批次中的写操作
+func pendingMutations(batches []*txnBatch) []txnMutation {
+	var mutations []txnMutation
+	for _, batch := range batches {
+		mutations = append(mutations, batch.mutations...)
+	}
+	return mutations
+}
+
+// buildTxnBatches 按所在的 Pebble 实例将写操作分组为批次，按首次出现的顺序返回
+func (ps *PebbleService) buildTxnBatches(mutations []txnMutation) ([]*txnBatch, error) {
+	var batches []*txnBatch
+	byInstance := make(map[*pebble.DB]*txnBatch)
+	for _, mutation := range mutations {
+		collection, err := ps.getCollectionDB(mutation.Collection)
+		if err != nil {
+			closeBatches(batches)
+			return nil, fmt.Errorf("获取集合 %s 数据库失败: %w", mutation.Collection, err)
+		}
+
+		batch, exists := byInstance[collection.db]
+		if !exists {
+			batch = &txnBatch{batch: collection.db.NewBatch()}
+			byInstance[collection.db] = batch
+			batches = append(batches, batch)
+		}
+		if mutation.Delete {
+			batch.batch.Delete(collection.key(mutation.Key), nil)
+		} else {
+			batch.batch.Set(collection.key(mutation.Key), mutation.Value, nil)
+		}
+		batch.mutations = append(batch.mutations, mutation)
+	}
+	return batches, nil
+}
+
+// recoverPendingTxns 重放事务日志中未完成的事务，返回重放的事务数，调用方需持有 ps.mu；
+// Initialize 在取得数据目录的进程锁后才调用，部署交接的新实例使用独立的数据目录，不会打开旧实例的事务日志
+func (ps *PebbleService) recoverPendingTxns() (int, error) {
+	logDB, err := ps.getCollectionDB(CollectionTxnLog)
+	if err != nil {
+		return 0, fmt.Errorf("获取事务日志集合数据库失败: %w", err)
+	}
+
+	iter, err := logDB.NewIter(nil)
+	if err != nil {
+		return 0, err
+	}
+	defer iter.Close()
+
+	recovered := 0
+	for iter.First(); iter.Valid(); iter.Next() {
+		id := append([]byte(nil), iter.Key()...)
+		var mutations []txnMutation
+		if err := json.Unmarshal(iter.Value(), &mutations); err != nil {
+			log.Printf("⚠️ 事务日志 %s 已损坏，跳过: %v", id, err)
+		} else {
+			batches, err := ps.buildTxnBatches(mutations)
+			if err != nil {
+				return recovered, err
+			}
+			if _, err := commitBatches(batches); err != nil {
+				return recovered, fmt.Errorf("重放事务 %s 失败: %w", id, err)
+			}
+			recovered++
+		}
+		if err := logDB.Delete(id, pebble.Sync); err != nil {
+			return recovered, fmt.Errorf("删除事务日志失败: %w", err)
+		}
+	}
+	return recovered, iter.Error()
+}
@@ -0,0 +1,176 @@
+package pebble_service
+
+import (
+	"errors"
+	"push-base-service/service/lock_service"
+	"testing"
+)
+
+// txnLogCount 返回事务日志中的条目数
+func txnLogCount(t *testing.T, service *PebbleService) int {
+	t.Helper()
+	db, err := service.getCollectionDB(CollectionTxnLog)
+	if err != nil {
+		t.Fatalf("getCollectionDB() failed, err: %v", err)
+	}
+	iter, err := db.NewIter(nil)
+	if err != nil {
+		t.Fatalf("NewIter() failed, err: %v", err)
+	}
+	defer iter.Close()
+	count := 0
+	for iter.First(); iter.Valid(); iter.Next() {
+		count++
+	}
+	return count
+}
+
+func TestSetUserTokenTransferIsConsistent(t *testing.T) {
+	for _, keyspace := range []string{KeyspaceMulti, KeyspaceShared} {
+		t.Run(keyspace, func(t *testing.T) {
+			service := NewPebbleService(&Config{DBPath: t.TempDir(), Keyspace: keyspace})
+			if err := service.Initialize(); err != nil {
+				t.Fatalf("Initialize() failed, err: %v", err)
+			}
+			t.Cleanup(func() { service.Close() })
+
+			if err := service.SetUserToken("alice", "expo", "token-1"); err != nil {
+				t.Fatalf("SetUserToken() failed, err: %v", err)
+			}
+			if err := service.SetUserToken("bob", "expo", "token-1"); err != nil {
+				t.Fatalf("SetUserToken() transfer failed, err: %v", err)
+			}
+
+			alice, _ := service.GetUserTokens("alice")
+			bob, _ := service.GetUserTokens("bob")
+			device, _ := service.GetDeviceInfo("token-1")
+			if alice.Tokens["expo"] != "" || bob.Tokens["expo"] != "token-1" || device == nil || device.MetaID != "bob" {
+				t.Errorf("after transfer: alice = %v, bob = %v, device = %+v", alice.Tokens, bob.Tokens, device)
+			}
+
+			// 令牌索引与令牌同时更新
+			page, err := service.GetUserTokensAfter(UserTokensFilter{Platform: "expo"}, "", 10)
+			if err != nil || len(page.Users) != 1 || page.Users[0].MetaID != "bob" {
+				t.Errorf("expo users = %+v, %v, want only bob", page, err)
+			}
+			if count := txnLogCount(t, service); count != 0 {
+				t.Errorf("txn log entries after commit = %d, want 0", count)
+			}
+		})
+	}
+}
+
+func TestRecoverPendingTxns(t *testing.T) {
+	dbPath := t.TempDir()
+	service := NewPebbleService(&Config{DBPath: dbPath})
+	if err := service.Initialize(); err != nil {
+		t.Fatalf("Initialize() failed, err: %v", err)
+	}
+	if err := service.SetUserToken("alice", "expo", "token-1"); err != nil {
+		t.Fatalf("SetUserToken() failed, err: %v", err)
+	}
+
+	// 模拟写入事务日志后、提交批次前崩溃
+	txn := service.newWriteTxn()
+	txn.Set(CollectionDevices, getDeviceKey("token-1"), []byte(`{"deviceId":"token-1","platform":"expo","metaId":"bob"}`))
+	txn.Set(CollectionUserTokens, getUserTokensKey("bob"), []byte(`{"metaId":"bob","tokens":{"expo":"token-1"}}`))
+	txn.Set(CollectionUserTokens, getUserTokensKey("alice"), []byte(`{"metaId":"alice","tokens":{}}`))
+	if _, err := service.appendTxnLog(txn.mutations); err != nil {
+		t.Fatalf("appendTxnLog() failed, err: %v", err)
+	}
+	service.Close()
+
+	service = NewPebbleService(&Config{DBPath: dbPath})
+	if err := service.Initialize(); err != nil {
+		t.Fatalf("Initialize() failed, err: %v", err)
+	}
+	t.Cleanup(func() { service.Close() })
+
+	alice, _ := service.GetUserTokens("alice")
+	bob, _ := service.GetUserTokens("bob")
+	device, _ := service.GetDeviceInfo("token-1")
+	if alice.Tokens["expo"] != "" || bob.Tokens["expo"] != "token-1" || device == nil || device.MetaID != "bob" {
+		t.Errorf("after recovery: alice = %v, bob = %v, device = %+v", alice.Tokens, bob.Tokens, device)
+	}
+	if count := txnLogCount(t, service); count != 0 {
+		t.Errorf("txn log entries after recovery = %d, want 0", count)
+	}
+}
+
+func TestRecoverPendingTxnsRequiresProcessLock(t *testing.T) {
+	dbPath := t.TempDir()
+	owner := NewPebbleService(&Config{DBPath: dbPath, ProcessLock: true})
+	if err := owner.Initialize(); err != nil {
+		t.Fatalf("Initialize() failed, err: %v", err)
+	}
+	t.Cleanup(func() { owner.Close() })
+
+	txn := owner.newWriteTxn()
+	txn.Set(CollectionUserTokens, getUserTokensKey("bob"), []byte(`{"metaId":"bob","tokens":{"expo":"token-1"}}`))
+	if _, err := owner.appendTxnLog(txn.mutations); err != nil {
+		t.Fatalf("appendTxnLog() failed, err: %v", err)
+	}
+
+	// 第二个实例指向同一数据目录时在重放事务日志之前失败，不会打开或重放持有者的事务日志
+	other := NewPebbleService(&Config{DBPath: dbPath, ProcessLock: true})
+	if err := other.Initialize(); !errors.Is(err, lock_service.ErrProcessLocked) {
+		t.Fatalf("second Initialize() = %v, want ErrProcessLocked", err)
+	}
+	if count := txnLogCount(t, owner); count != 1 {
+		t.Errorf("owner txn log entries = %d, want 1", count)
+	}
+}
+
+func TestRollForwardNarrowsTxnLog(t *testing.T) {
+	dbPath := t.TempDir()
+	service := NewPebbleService(&Config{DBPath: dbPath})
+	if err := service.Initialize(); err != nil {
+		t.Fatalf("Initialize() failed, err: %v", err)
+	}
+	if err := service.SetUserToken("alice", "expo", "token-1"); err != nil {
+		t.Fatalf("SetUserToken() failed, err: %v", err)
+	}
+
+	txn := service.newWriteTxn()
+	txn.Set(CollectionUserTokens, getUserTokensKey("bob"), []byte(`{"metaId":"bob","tokens":{"expo":"token-1"}}`))
+	txn.Set(CollectionUserTokens, getUserTokensKey("alice"), []byte(`{"metaId":"alice","tokens":{}}`))
+	txn.Set(CollectionDevices, getDeviceKey("token-1"), []byte(`{"deviceId":"token-1","platform":"expo","metaId":"bob"}`))
+	id, err := service.appendTxnLog(txn.mutations)
+	if err != nil {
+		t.Fatalf("appendTxnLog() failed, err: %v", err)
+	}
+
+	// 模拟用户令牌批次已提交、设备批次提交失败后立即重试剩余写操作
+	batches, err := service.buildTxnBatches(txn.mutations)
+	if err != nil || len(batches) != 2 {
+		t.Fatalf("buildTxnBatches() = %d batches, err: %v", len(batches), err)
+	}
+	if _, err := commitBatches(batches[:1]); err != nil {
+		t.Fatalf("commitBatches() failed, err: %v", err)
+	}
+	closeBatches(batches[1:])
+	if err := service.rollForward(id, pendingMutations(batches[1:])); err != nil {
+		t.Fatalf("rollForward() failed, err: %v", err)
+	}
+	if device, _ := service.GetDeviceInfo("token-1"); device == nil || device.MetaID != "bob" {
+		t.Errorf("device after roll forward = %+v, want owned by bob", device)
+	}
+
+	// 日志删除前崩溃：重启重放时只包含剩余写操作，不会覆盖之后写入的新令牌
+	if err := service.SetUserToken("alice", "expo", "token-2"); err != nil {
+		t.Fatalf("SetUserToken() failed, err: %v", err)
+	}
+	service.Close()
+
+	service = NewPebbleService(&Config{DBPath: dbPath})
+	if err := service.Initialize(); err != nil {
+		t.Fatalf("Initialize() failed, err: %v", err)
+	}
+	t.Cleanup(func() { service.Close() })
+	if alice, _ := service.GetUserTokens("alice"); alice == nil || alice.Tokens["expo"] != "token-2" {
+		t.Errorf("alice after recovery = %+v, want token-2", alice)
+	}
+	if count := txnLogCount(t, service); count != 0 {
+		t.Errorf("txn log entries after recovery = %d, want 0", count)
+	}
+}
@@ -0,0 +1,75 @@
+package pushcenter
+
+import (
+	"context"
+	"log"
+	"push-base-service/service/metrics_service"
+	"push-base-service/service/socket_client_service"
+	"time"
+)
+
+// StageAck 推送确认环节名称，启用推送确认时追加在 Record 之后
+const StageAck = "ack"
+
+// pushAcksCounter 推送确认消息的发送次数
+var pushAcksCounter = metrics_service.NewCounterVec(
+	"push_acks_total", "Number of WS_PUSH_ACK messages sent to upstream sources by result", "result")
+
+// AckConfig 推送确认配置
+// 启用后聊天消息推送成功（至少一台设备推送成功）时，向收到该消息的上游发送 WS_PUSH_ACK 控制消息，
+// 携带 pinId 和推送成功/失败/跳过的数量，供上游统计推送覆盖率并在需要时重新下发（需上游协议支持）
+type AckConfig struct {
+	Enabled bool `yaml:"enabled" json:"enabled"` // 是否发送推送确认
+}
+
+// PushAcker 可接收推送确认消息的消息来源
+// socket_client_service.Manager 即为默认实现
+type PushAcker interface {
+	SendPushAck(upstream string, ack *socket_client_service.PushAck) error
+}
+
+// ackEnabled 是否启用推送确认
+func (pc *PushCenter) ackEnabled() bool {
+	return pc.config.AckConfig != nil && pc.config.AckConfig.Enabled
+}
+
+// ackStage 推送成功后向上游发送推送确认；演练、回放、非 Socket 来源（如 HTTP 进件）、没有 PinId 或没有推送成功的消息不发送
+func (pc *PushCenter) ackStage(ctx context.Context, msg *PipelineMessage, next PipelineHandler) error {
+	if msg.DryRun || msg.Replay || msg.ChatMsg.Upstream == "" || msg.Info.PinId == "" {
+		return next(ctx, msg)
+	}
+
+	ack := &socket_client_service.PushAck{
+		PinId:     msg.Info.PinId,
+		ChatType:  msg.Info.ChatType,
+		Skipped:   len(msg.Audience.Users()) - len(msg.Recipients),
+		Timestamp: time.Now().UnixMilli(),
+	}
+	for _, result := range msg.Results {
+		if result.Success {
+			ack.Delivered++
+		} else {
+			ack.Failed++
+		}
+	}
+	if ack.Delivered > 0 {
+		pc.sendPushAck(msg.ChatMsg.Upstream, ack)
+	}
+	return next(ctx, msg)
+}
+
+// sendPushAck 向支持推送确认的消息来源发送确认，发送失败只记录日志
+func (pc *PushCenter) sendPushAck(upstream string, ack *socket_client_service.PushAck) {
+	for _, source := range pc.sources {
+		acker, ok := source.(PushAcker)
+		if !ok {
+			continue
+		}
+		if err := acker.SendPushAck(upstream, ack); err != nil {
+			pushAcksCounter.Inc("error")
+			log.Printf("⚠️ 发送推送确认失败: pinId=%s, err=%v", ack.PinId, err)
+			continue
+		}
+		pushAcksCounter.Inc("sent")
+	}
+}
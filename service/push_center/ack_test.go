@@ -0,0 +1,57 @@
+package pushcenter
+
+import (
+	"push-base-service/service/socket_client_service"
+	"reflect"
+	"testing"
+)
+
+// ackingSource 记录收到的推送确认的消息来源
+type ackingSource struct {
+	stubSource
+	upstreams []string
+	acks      []*socket_client_service.PushAck
+}
+
+func (s *ackingSource) SendPushAck(upstream string, ack *socket_client_service.PushAck) error {
+	s.upstreams = append(s.upstreams, upstream)
+	s.acks = append(s.acks, ack)
+	return nil
+}
+
+func TestPushAckSentToUpstream(t *testing.T) {
+	ps := newTestStores(t)
+	ps.AddBlockedChat("carol", "group1", "group", "", 0)
+
+	source := &ackingSource{}
+	pc := NewPushCenter(&Config{AckConfig: &AckConfig{Enabled: true}})
+	pc.sources = []MessageSource{source}
+	pc.SetAudienceResolver(listResolver{"group1": {"alice", "bob", "carol"}, "group2": nil})
+	pc.SetDispatcher(&dryRunDispatcher{})
+	pc.SetChatMessageHandler()
+	pc.consuming = true
+	if names := pc.StageNames(); names[len(names)-1] != StageAck {
+		t.Fatalf("StageNames() = %v, want %s last", names, StageAck)
+	}
+
+	groupMessage := func(pinId, groupId string) *socket_client_service.ChatNotificationMessage {
+		return &socket_client_service.ChatNotificationMessage{
+			Type:     "group_chat",
+			Data:     &socket_client_service.ExtraServiceMessage{Message: map[string]interface{}{"pinId": pinId, "groupId": groupId}},
+			Upstream: "shard-1",
+		}
+	}
+	source.handler(groupMessage("pin-ack", "group1"))
+	// 重复的 PIN 被去重，没有推送的消息不发送确认
+	source.handler(groupMessage("pin-ack", "group1"))
+	source.handler(groupMessage("pin-empty", "group2"))
+	pc.inflight.Wait()
+
+	if !reflect.DeepEqual(source.upstreams, []string{"shard-1"}) {
+		t.Fatalf("acks sent to %v, want [shard-1]", source.upstreams)
+	}
+	ack := source.acks[0]
+	if ack.PinId != "pin-ack" || ack.ChatType != "group_chat" || ack.Delivered != 2 || ack.Failed != 0 || ack.Skipped != 1 {
+		t.Errorf("ack = %+v, want pin-ack delivered to 2, carol skipped", ack)
+	}
+}
@@ -0,0 +1,192 @@
+package pushcenter
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"push-base-service/models"
+	"push-base-service/service/metrics_service"
+	"push-base-service/service/pebble_service"
+	"push-base-service/service/push_service"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// 推送审计日志默认配置
+const (
+	DefaultAuditRetention = 30 * 24 * time.Hour
+	DefaultAuditQueueSize = 10000
+	auditBatchSize        = 200
+	auditFlushInterval    = time.Second
+)
+
+// auditEntriesCounter 推送审计记录写入和丢弃数
+var auditEntriesCounter = metrics_service.NewCounterVec(
+	"push_audit_entries_total", "Number of push audit entries by result (written, dropped, failed)", "result")
+
+// AuditConfig 推送审计日志配置
+// 开启后每条外发通知（接收用户、标题、内容哈希、推送平台、结果、PinId、时间）异步写入 push_audit 集合，
+// 每个用户只保留最近 PerUserLimit 条，超过 Retention 的记录定期清理
+type AuditConfig struct {
+	Enabled      bool          `yaml:"enabled" json:"enabled"`               // 是否启用推送审计日志
+	Retention    time.Duration `yaml:"retention" json:"retention"`           // 审计记录保留时长
+	PerUserLimit int           `yaml:"per_user_limit" json:"per_user_limit"` // 每个用户保留的记录数
+	QueueSize    int           `yaml:"queue_size" json:"queue_size"`         // 写入队列容量，队列满时丢弃记录
+}
+
+// pushAuditor 推送审计日志写入器，作为推送结果监听器收集记录，后台批量写入 Pebble
+type pushAuditor struct {
+	limit    int
+	queue    chan *models.PushAuditEntry
+	stopCh   chan struct{}
+	done     chan struct{}
+	started  atomic.Bool
+	stopOnce sync.Once
+}
+
+// newPushAuditor 创建推送审计日志写入器
+func newPushAuditor(config *AuditConfig) *pushAuditor {
+	queueSize := config.QueueSize
+	if queueSize <= 0 {
+		queueSize = DefaultAuditQueueSize
+	}
+	return &pushAuditor{
+		limit:  config.PerUserLimit,
+		queue:  make(chan *models.PushAuditEntry, queueSize),
+		stopCh: make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+}
+
+// auditEnabled 是否启用推送审计日志
+func (pc *PushCenter) auditEnabled() bool {
+	return pc.config.AuditConfig != nil && pc.config.AuditConfig.Enabled
+}
+
+// HandleResult 推送结果监听器，生成审计记录加入写入队列，队列满时丢弃
+func (a *pushAuditor) HandleResult(notification *push_service.PushNotification, result *push_service.PushResult) {
+	if notification == nil || result == nil || result.MetaID == "" {
+		return
+	}
+
+	select {
+	case a.queue <- buildAuditEntry(notification, result):
+	default:
+		auditEntriesCounter.Inc("dropped")
+	}
+}
+
+// buildAuditEntry 根据通知和推送结果生成审计记录，通知内容只保存哈希
+func buildAuditEntry(notification *push_service.PushNotification, result *push_service.PushResult) *models.PushAuditEntry {
+	entry := &models.PushAuditEntry{
+		MetaID:    result.MetaID,
+		TenantID:  result.TenantID,
+		Title:     notification.Title,
+		Provider:  result.Platform,
+		Success:   result.Success,
+		Simulated: result.Simulated,
+		ReceiptID: result.ReceiptID,
+		Timestamp: result.Timestamp.UnixMilli(),
+	}
+	if notification.Body != "" {
+		sum := sha256.Sum256([]byte(notification.Body))
+		entry.BodyHash = hex.EncodeToString(sum[:])
+	}
+	if result.Error != nil {
+		entry.Error = result.Error.Error()
+	}
+	if pinId, ok := notification.Data["pinId"].(string); ok {
+		entry.PinID = pinId
+	}
+	if result.Timestamp.IsZero() {
+		entry.Timestamp = time.Now().UnixMilli()
+	}
+	return entry
+}
+
+// run 批量写入审计记录，停止时写完队列中剩余的记录后关闭 done
+func (a *pushAuditor) run() {
+	defer close(a.done)
+
+	ticker := time.NewTicker(auditFlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]*models.PushAuditEntry, 0, auditBatchSize)
+	for {
+		select {
+		case entry := <-a.queue:
+			batch = append(batch, entry)
+			if len(batch) >= auditBatchSize {
+				batch = a.flush(batch)
+			}
+		case <-ticker.C:
+			batch = a.flush(batch)
+		case <-a.stopCh:
+			for {
+				select {
+				case entry := <-a.queue:
+					batch = append(batch, entry)
+				default:
+					a.flush(batch)
+					return
+				}
+			}
+		}
+	}
+}
+
+// flush 写入一批审计记录，返回清空后的批次
+func (a *pushAuditor) flush(batch []*models.PushAuditEntry) []*models.PushAuditEntry {
+	if len(batch) == 0 {
+		return batch
+	}
+	if err := pebble_service.AddPushAudits(batch, a.limit); err != nil {
+		auditEntriesCounter.Add(float64(len(batch)), "failed")
+		log.Printf("⚠️ 写入推送审计日志失败: 记录数=%d, 错误: %v", len(batch), err)
+	} else {
+		auditEntriesCounter.Add(float64(len(batch)), "written")
+	}
+	return batch[:0]
+}
+
+// Start 启动后台写入协程
+func (a *pushAuditor) Start() {
+	if a.started.CompareAndSwap(false, true) {
+		go a.run()
+	}
+}
+
+// Stop 停止写入协程并等待队列中的记录写完
+func (a *pushAuditor) Stop() {
+	if !a.started.Load() {
+		return
+	}
+	a.stopOnce.Do(func() { close(a.stopCh) })
+	<-a.done
+}
+
+// auditCleanupLoop 定期删除超过保留时长的推送审计记录
+func (pc *PushCenter) auditCleanupLoop(stopCh chan struct{}) {
+	retention := pc.config.AuditConfig.Retention
+	if retention <= 0 {
+		retention = DefaultAuditRetention
+	}
+
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+			count, err := pebble_service.PurgePushAudits(time.Now().Add(-retention).UnixMilli())
+			if err != nil {
+				log.Printf("⚠️ 清理过期推送审计日志失败: %v", err)
+			} else if count > 0 {
+				log.Printf("🧹 已清理 %d 条过期推送审计日志", count)
+			}
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package pushcenter
+
+import (
+	"log"
+	"push-base-service/service/metrics_service"
+	"push-base-service/service/socket_client_service"
+	"time"
+)
+
+// DefaultBackpressureHighWatermark 默认触发背压的积压消息数
+const DefaultBackpressureHighWatermark = 5000
+
+// 背压指标：积压消息数始终记录，是否处于背压状态及信号发送次数仅在启用背压时记录
+var (
+	backlogGauge = metrics_service.NewGaugeVec(
+		"push_backlog_messages", "Number of accepted chat messages not yet processed (in flight or queued)")
+	backpressureGauge = metrics_service.NewGaugeVec(
+		"push_backpressure_engaged", "Whether backpressure towards the upstream is engaged (1) or not (0)")
+	backpressureSignalsCounter = metrics_service.NewCounterVec(
+		"push_backpressure_signals_total", "Number of backpressure control messages sent to upstream sources by action and result", "action", "result")
+)
+
+// BackpressureConfig 背压配置
+// 积压（已接收未处理完成的消息）达到高水位时进入背压状态，降到低水位时解除；
+// 启用上游信号时向支持的消息来源发送 WS_CLIENT_BACKPRESSURE 控制消息，请求上游放慢或缓存消息
+type BackpressureConfig struct {
+	Enabled        bool `yaml:"enabled" json:"enabled"`                 // 是否启用背压
+	HighWatermark  int  `yaml:"high_watermark" json:"high_watermark"`   // 进入背压的积压消息数
+	LowWatermark   int  `yaml:"low_watermark" json:"low_watermark"`     // 解除背压的积压消息数，默认为高水位的一半
+	SignalUpstream bool `yaml:"signal_upstream" json:"signal_upstream"` // 是否向上游发送控制消息（需上游协议支持）
+}
+
+// BackpressureSignaler 可接收背压控制消息的消息来源
+// socket_client_service.Manager 即为默认实现
+type BackpressureSignaler interface {
+	SignalBackpressure(signal *socket_client_service.BackpressureSignal) error
+}
+
+// backpressureEnabled 是否启用背压
+func (pc *PushCenter) backpressureEnabled() bool {
+	return pc.config.Backpressure != nil && pc.config.Backpressure.Enabled
+}
+
+// backpressureWatermarks 读取高低水位配置，未配置时使用默认值
+func (pc *PushCenter) backpressureWatermarks() (high, low int64) {
+	high = int64(pc.config.Backpressure.HighWatermark)
+	if high <= 0 {
+		high = DefaultBackpressureHighWatermark
+	}
+	low = int64(pc.config.Backpressure.LowWatermark)
+	if low <= 0 || low >= high {
+		low = high / 2
+	}
+	return high, low
+}
+
+// addInflight 登记已接收、待处理的消息
+func (pc *PushCenter) addInflight(n int) {
+	pc.inflight.Add(n)
+	pc.updateBacklog(int64(n))
+}
+
+// doneInflight 一条消息处理完成
+func (pc *PushCenter) doneInflight() {
+	pc.updateBacklog(-1)
+	pc.inflight.Done()
+}
+
+// updateBacklog 更新积压消息数，跨过高低水位时切换背压状态
+func (pc *PushCenter) updateBacklog(delta int64) {
+	backlog := pc.backlog.Add(delta)
+	backlogGauge.Set(float64(backlog))
+	if !pc.backpressureEnabled() {
+		return
+	}
+
+	high, low := pc.backpressureWatermarks()
+	switch {
+	case backlog >= high && pc.backpressured.CompareAndSwap(false, true):
+		log.Printf("🚦 推送积压达到高水位（%d/%d），进入背压状态", backlog, high)
+	case backlog <= low && pc.backpressured.CompareAndSwap(true, false):
+		log.Printf("🟢 推送积压降至低水位（%d/%d），解除背压", backlog, low)
+	default:
+		return
+	}
+	// 在接收消息的路径上调用，信号异步发送
+	go pc.syncBackpressure()
+}
+
+// syncBackpressure 将当前背压状态同步到指标和上游；并发调用时按最新状态发送，状态未变化时不重复发送
+func (pc *PushCenter) syncBackpressure() {
+	pc.backpressureMu.Lock()
+	defer pc.backpressureMu.Unlock()
+
+	engaged := pc.backpressured.Load()
+	if engaged == pc.backpressureSent {
+		return
+	}
+	pc.backpressureSent = engaged
+
+	action := socket_client_service.BackpressureActionResume
+	if engaged {
+		action = socket_client_service.BackpressureActionSlow
+		backpressureGauge.Set(1)
+	} else {
+		backpressureGauge.Set(0)
+	}
+
+	if !pc.config.Backpressure.SignalUpstream {
+		return
+	}
+
+	high, _ := pc.backpressureWatermarks()
+	signal := &socket_client_service.BackpressureSignal{
+		Action:        action,
+		Pending:       pc.backlog.Load(),
+		HighWatermark: int(high),
+		Timestamp:     time.Now().UnixMilli(),
+	}
+	for _, source := range pc.sources {
+		signaler, ok := source.(BackpressureSignaler)
+		if !ok {
+			continue
+		}
+		if err := signaler.SignalBackpressure(signal); err != nil {
+			backpressureSignalsCounter.Inc(action, "error")
+			log.Printf("⚠️ 发送背压信号失败: action=%s, err=%v", action, err)
+			continue
+		}
+		backpressureSignalsCounter.Inc(action, "sent")
+	}
+}
+
+// QueueStatus 推送积压状态
+type QueueStatus struct {
+	Backlog       int64 `json:"backlog"`                 // 已接收未处理完成的消息数
+	Pending       int   `json:"pending"`                 // 部署交接待命期间缓存、接管后补发的消息数
+	Backpressured bool  `json:"backpressured"`           // 是否处于背压状态
+	HighWatermark int64 `json:"highWatermark,omitempty"` // 进入背压的积压消息数，未启用背压时为 0
+}
+
+// QueueStatus 获取当前推送积压状态
+func (pc *PushCenter) QueueStatus() *QueueStatus {
+	pc.consumeMu.Lock()
+	pending := len(pc.pending)
+	pc.consumeMu.Unlock()
+
+	status := &QueueStatus{
+		Backlog:       pc.backlog.Load(),
+		Pending:       pending,
+		Backpressured: pc.backpressured.Load(),
+	}
+	if pc.backpressureEnabled() {
+		status.HighWatermark, _ = pc.backpressureWatermarks()
+	}
+	return status
+}
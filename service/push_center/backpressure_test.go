@@ -0,0 +1,62 @@
+package pushcenter
+
+import (
+	"push-base-service/service/socket_client_service"
+	"testing"
+	"time"
+)
+
+// signalingSource 记录收到的背压信号的消息来源
+type signalingSource struct {
+	signals chan *socket_client_service.BackpressureSignal
+}
+
+func (s *signalingSource) SetChatMessageHandler(func(*socket_client_service.ChatNotificationMessage)) {
+}
+func (s *signalingSource) Start() error { return nil }
+func (s *signalingSource) Stop()        {}
+
+func (s *signalingSource) SignalBackpressure(signal *socket_client_service.BackpressureSignal) error {
+	s.signals <- signal
+	return nil
+}
+
+func TestBackpressureSignalsUpstream(t *testing.T) {
+	source := &signalingSource{signals: make(chan *socket_client_service.BackpressureSignal, 4)}
+	pc := NewPushCenter(&Config{Backpressure: &BackpressureConfig{Enabled: true, HighWatermark: 3, LowWatermark: 1, SignalUpstream: true}})
+	pc.sources = []MessageSource{source}
+
+	expect := func(action string) {
+		t.Helper()
+		select {
+		case signal := <-source.signals:
+			if signal.Action != action {
+				t.Errorf("signal action = %s, want %s", signal.Action, action)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("no %s signal sent", action)
+		}
+	}
+
+	pc.addInflight(2)
+	pc.addInflight(1)
+	expect(socket_client_service.BackpressureActionSlow)
+	if got := backlogGauge.Get(); got != 3 {
+		t.Errorf("backlog gauge = %v, want 3", got)
+	}
+
+	// 高低水位之间不切换状态
+	pc.doneInflight()
+	pc.addInflight(1)
+	pc.doneInflight()
+	pc.doneInflight()
+	expect(socket_client_service.BackpressureActionResume)
+	pc.doneInflight()
+	pc.inflight.Wait()
+
+	select {
+	case signal := <-source.signals:
+		t.Errorf("unexpected signal %+v", signal)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
@@ -0,0 +1,93 @@
+package pushcenter
+
+import (
+	"fmt"
+	"push-base-service/service/pebble_service"
+	"push-base-service/service/storage_service"
+	"reflect"
+	"testing"
+)
+
+// newTestStores 初始化全局 Pebble 服务并以它作为全局存储的后端，测试中的全局函数与存储使用同一个数据库
+func newTestStores(t *testing.T) *pebble_service.PebbleService {
+	t.Helper()
+
+	if err := pebble_service.InitializeGlobalService(&pebble_service.Config{DBPath: t.TempDir()}); err != nil {
+		t.Fatalf("初始化 Pebble 服务失败: %v", err)
+	}
+	t.Cleanup(func() { pebble_service.CloseGlobalService() })
+	ps := pebble_service.GetGlobalService()
+
+	stores, err := storage_service.NewStores(nil, pebble_service.NewPebbleTokenStore(ps))
+	if err != nil {
+		t.Fatalf("创建存储失败: %v", err)
+	}
+	storage_service.SetGlobalStores(stores)
+	t.Cleanup(func() { storage_service.SetGlobalStores(nil) })
+	return ps
+}
+
+func TestFilterBlockedUsersKeepsOrder(t *testing.T) {
+	ps := newTestStores(t)
+	pc := &PushCenter{config: &Config{}}
+
+	var metaIds, want []string
+	for i := 0; i < 50; i++ {
+		metaId := fmt.Sprintf("user%02d", i)
+		metaIds = append(metaIds, metaId)
+		if i%3 == 0 {
+			ps.AddBlockedChat(metaId, "group1", "group", "", 0)
+		} else {
+			want = append(want, metaId)
+		}
+	}
+
+	got := pc.filterBlockedUsers(metaIds, &ParsedMessageInfo{ChatType: "group_chat", GroupId: "group1"})
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("filterBlockedUsers() = %v, want %v", got, want)
+	}
+}
+
+func TestFilterBlockedUsersSkipsSenderInPrivateChat(t *testing.T) {
+	ps := newTestStores(t)
+	pc := &PushCenter{config: &Config{}}
+	ps.AddBlockedChat("c", "sender", "private", "", 0)
+
+	got := pc.filterBlockedUsers([]string{"a", "sender", "b", "c"}, &ParsedMessageInfo{ChatType: "private_chat", MetaId: "sender"})
+	if !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Errorf("filterBlockedUsers() = %v, want [a b]", got)
+	}
+}
+
+func TestFilterBlockedUsersAllGroupsAndSenders(t *testing.T) {
+	ps := newTestStores(t)
+	pc := &PushCenter{config: &Config{}}
+	ps.SetAllGroupsBlocked("no-groups", true)
+	ps.AddBlockedSender("no-spammer", "spammer", "spam")
+
+	users := []string{"a", "no-groups", "no-spammer"}
+	groupInfo := &ParsedMessageInfo{ChatType: "group_chat", GroupId: "group1", SenderId: "spammer"}
+	if got := pc.filterBlockedUsers(users, groupInfo); !reflect.DeepEqual(got, []string{"a"}) {
+		t.Errorf("群聊 filterBlockedUsers() = %v, want [a]", got)
+	}
+
+	// 屏蔽所有群聊不影响私聊，屏蔽发送者同样作用于私聊
+	privateInfo := &ParsedMessageInfo{ChatType: "private_chat", MetaId: "spammer", SenderId: "spammer"}
+	if got := pc.filterBlockedUsers(users, privateInfo); !reflect.DeepEqual(got, []string{"a", "no-groups"}) {
+		t.Errorf("私聊 filterBlockedUsers() = %v, want [a no-groups]", got)
+	}
+
+	// 其他发送者的群聊消息只受屏蔽所有群聊影响
+	groupInfo.SenderId = "friend"
+	if got := pc.filterBlockedUsers(users, groupInfo); !reflect.DeepEqual(got, []string{"a", "no-spammer"}) {
+		t.Errorf("filterBlockedUsers() = %v, want [a no-spammer]", got)
+	}
+
+	// 关闭屏蔽所有群聊、取消屏蔽发送者后恢复推送
+	ps.SetAllGroupsBlocked("no-groups", false)
+	ps.RemoveBlockedSender("no-spammer", "spammer")
+	groupInfo.SenderId = "spammer"
+	if got := pc.filterBlockedUsers(users, groupInfo); !reflect.DeepEqual(got, users) {
+		t.Errorf("取消屏蔽后 filterBlockedUsers() = %v, want %v", got, users)
+	}
+}
@@ -0,0 +1,147 @@
+package pushcenter
+
+import (
+	"log"
+	"push-base-service/models"
+	"push-base-service/service/pebble_service"
+	"push-base-service/service/push_service"
+	"push-base-service/service/socket_client_service"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 红包（Candy Bag）通知默认配置
+const (
+	DefaultCandyBagSound       = "candy_bag.wav"
+	DefaultCandyBagChannelID   = "candy_bag"
+	DefaultCandyBagRoute       = "/candy-bag/{pinId}"
+	DefaultCandyBagClaimWindow = 24 * time.Hour
+)
+
+// CandyBagConfig 红包通知配置
+// 开启后红包消息使用单独的声音和 Android 渠道、高优先级，并在 data.candyBag 中附带金额提示、领取截止时间和跳转路由
+type CandyBagConfig struct {
+	Enabled     bool          `yaml:"enabled" json:"enabled"`           // 是否启用红包通知增强
+	Sound       string        `yaml:"sound" json:"sound"`               // 通知声音
+	ChannelID   string        `yaml:"channel_id" json:"channel_id"`     // Android 通知渠道
+	Route       string        `yaml:"route" json:"route"`               // 客户端跳转路由，{pinId}、{groupId}、{metaId} 会被替换
+	ClaimWindow time.Duration `yaml:"claim_window" json:"claim_window"` // 消息未带截止时间时，按发送时间加该时长估算领取截止时间
+}
+
+// CandyBagInfo 从红包消息中解析的附加信息
+type CandyBagInfo struct {
+	AmountHint    string `json:"amountHint,omitempty"`    // 金额提示（消息中的 amount，原样透传）
+	ClaimDeadline int64  `json:"claimDeadline,omitempty"` // 领取截止时间（Unix 秒）
+}
+
+// isCandyBag chatInfoType 为 1 或 23 的消息是红包
+func isCandyBag(chatInfoType int64) bool {
+	return chatInfoType == 1 || chatInfoType == 23
+}
+
+// candyBagEnabled 是否启用红包通知增强
+func (pc *PushCenter) candyBagEnabled() bool {
+	return pc.config.CandyBagConfig != nil && pc.config.CandyBagConfig.Enabled
+}
+
+// parseCandyBag 解析红包消息的金额和领取截止时间（字段缺失时为空）
+func parseCandyBag(fields socket_client_service.CandyBagFields) *CandyBagInfo {
+	info := &CandyBagInfo{}
+	switch amount := fields.Amount.(type) {
+	case string:
+		info.AmountHint = amount
+	case float64:
+		info.AmountHint = strconv.FormatFloat(amount, 'f', -1, 64)
+	}
+	for _, deadline := range []int64{fields.ExpireTime, fields.Deadline} {
+		if deadline > 0 {
+			info.ClaimDeadline = deadline
+			break
+		}
+	}
+	return info
+}
+
+// applyCandyBag 为红包通知设置声音、渠道、高优先级和附加数据，路由规则仍可覆盖这些设置
+func (pc *PushCenter) applyCandyBag(notification *push_service.PushNotification, parsedInfo *ParsedMessageInfo) {
+	if !pc.candyBagEnabled() || !isCandyBag(parsedInfo.ChatInfoType) {
+		return
+	}
+	config := pc.config.CandyBagConfig
+
+	notification.Priority = push_service.PriorityHigh
+	notification.Sound = valueOrDefault(config.Sound, DefaultCandyBagSound)
+	notification.ChannelID = valueOrDefault(config.ChannelID, DefaultCandyBagChannelID)
+
+	info := CandyBagInfo{}
+	if parsedInfo.CandyBag != nil {
+		info = *parsedInfo.CandyBag
+	}
+	if info.ClaimDeadline == 0 {
+		window := config.ClaimWindow
+		if window <= 0 {
+			window = DefaultCandyBagClaimWindow
+		}
+		info.ClaimDeadline = time.Now().Add(window).Unix()
+	}
+
+	route := strings.NewReplacer("{pinId}", parsedInfo.PinId, "{groupId}", parsedInfo.GroupId, "{metaId}", parsedInfo.MetaId).
+		Replace(valueOrDefault(config.Route, DefaultCandyBagRoute))
+	candyBag := map[string]interface{}{
+		"claimDeadline": info.ClaimDeadline,
+		"route":         route,
+	}
+	if info.AmountHint != "" {
+		candyBag["amountHint"] = info.AmountHint
+	}
+
+	data := make(map[string]interface{}, len(notification.Data)+1)
+	for key, value := range notification.Data {
+		data[key] = value
+	}
+	data["candyBag"] = candyBag
+	notification.Data = data
+}
+
+// filterCandyBagOptOut 红包消息跳过关闭了红包通知的用户，返回仍需推送的用户和被跳过的用户
+func (pc *PushCenter) filterCandyBagOptOut(metaIds []string, parsedInfo *ParsedMessageInfo) (active, optedOut []string) {
+	if !isCandyBag(parsedInfo.ChatInfoType) {
+		return metaIds, nil
+	}
+	return pc.filterMutedUsers(metaIds, func(preference *models.UserPreferences) bool {
+		return preference.MuteCandyBags
+	})
+}
+
+// filterMutedUsers 按用户偏好跳过关闭了某类通知的用户，读取偏好失败时照常推送
+func (pc *PushCenter) filterMutedUsers(metaIds []string, muted func(*models.UserPreferences) bool) (active, optedOut []string) {
+	if len(metaIds) == 0 {
+		return metaIds, nil
+	}
+
+	preferences, err := pebble_service.GetUserPreferencesBatch(metaIds)
+	if err != nil {
+		log.Printf("⚠️ 获取用户偏好失败，照常推送: %v", err)
+		return metaIds, nil
+	}
+	for _, metaId := range metaIds {
+		if preference, exists := preferences[metaId]; exists && muted(preference) {
+			optedOut = append(optedOut, metaId)
+		} else {
+			active = append(active, metaId)
+		}
+	}
+	if len(optedOut) > 0 {
+		log.Printf("🔕 %d 个用户关闭了该类通知，跳过推送", len(optedOut))
+	}
+	return active, optedOut
+}
+
+// valueOrDefault 字符串为空时返回默认值
+func valueOrDefault(value, defaultValue string) string {
+	if value == "" {
+		return defaultValue
+	}
+	return value
+}
@@ -0,0 +1,70 @@
+package pushcenter
+
+import (
+	"push-base-service/models"
+	"push-base-service/service/pebble_service"
+	"push-base-service/service/push_service"
+	"push-base-service/service/socket_client_service"
+	"testing"
+	"time"
+)
+
+func TestApplyCandyBag(t *testing.T) {
+	pc := &PushCenter{config: &Config{CandyBagConfig: &CandyBagConfig{Enabled: true, Route: "/candy-bag/{groupId}/{pinId}"}}}
+	parsedInfo := &ParsedMessageInfo{
+		PinId:        "pin-candy",
+		GroupId:      "group1",
+		ChatInfoType: 23,
+		CandyBag:     parseCandyBag(socket_client_service.CandyBagFields{Amount: 8.8, ExpireTime: 1900000000}),
+	}
+
+	notification := pc.newRoutedNotification("title", "body", map[string]interface{}{"pinId": "pin-candy"}, parsedInfo, false)
+	if notification.Priority != push_service.PriorityHigh || notification.Sound != DefaultCandyBagSound || notification.ChannelID != DefaultCandyBagChannelID {
+		t.Fatalf("candy bag notification = %+v", notification)
+	}
+	candyBag, ok := notification.Data["candyBag"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("data.candyBag missing: %+v", notification.Data)
+	}
+	if candyBag["amountHint"] != "8.8" || candyBag["claimDeadline"] != int64(1900000000) || candyBag["route"] != "/candy-bag/group1/pin-candy" {
+		t.Errorf("data.candyBag = %+v", candyBag)
+	}
+	if notification.Data["pinId"] != "pin-candy" {
+		t.Errorf("original data lost: %+v", notification.Data)
+	}
+
+	// 消息未带截止时间时按领取时长估算
+	parsedInfo.CandyBag = parseCandyBag(socket_client_service.CandyBagFields{})
+	notification = pc.newRoutedNotification("title", "body", nil, parsedInfo, false)
+	deadline := notification.Data["candyBag"].(map[string]interface{})["claimDeadline"].(int64)
+	if expected := time.Now().Add(DefaultCandyBagClaimWindow).Unix(); deadline < expected-5 || deadline > expected+5 {
+		t.Errorf("claimDeadline = %d, want about %d", deadline, expected)
+	}
+
+	// 普通消息和未启用时不做处理
+	normal := pc.newRoutedNotification("title", "body", nil, &ParsedMessageInfo{ChatInfoType: 0}, false)
+	pc.config.CandyBagConfig.Enabled = false
+	disabled := pc.newRoutedNotification("title", "body", nil, parsedInfo, false)
+	for _, n := range []*push_service.PushNotification{normal, disabled} {
+		if n.Priority != "" || n.Sound != "default" || n.Data["candyBag"] != nil {
+			t.Errorf("notification should not be enriched: %+v", n)
+		}
+	}
+}
+
+func TestFilterCandyBagOptOut(t *testing.T) {
+	newTestStores(t)
+	pebble_service.SaveUserPreferences(&models.UserPreferences{MetaID: "candy-muted", MuteCandyBags: true})
+
+	pc := &PushCenter{config: &Config{}}
+	active, optedOut := pc.filterCandyBagOptOut([]string{"candy-alice", "candy-muted"}, &ParsedMessageInfo{ChatInfoType: 1})
+	if len(active) != 1 || active[0] != "candy-alice" || len(optedOut) != 1 || optedOut[0] != "candy-muted" {
+		t.Errorf("filterCandyBagOptOut() = %v, %v", active, optedOut)
+	}
+
+	// 普通消息照常推送给关闭了红包通知的用户
+	active, optedOut = pc.filterCandyBagOptOut([]string{"candy-alice", "candy-muted"}, &ParsedMessageInfo{ChatInfoType: 0})
+	if len(active) != 2 || len(optedOut) != 0 {
+		t.Errorf("filterCandyBagOptOut() for normal message = %v, %v", active, optedOut)
+	}
+}
@@ -0,0 +1,156 @@
+package pushcenter
+
+import (
+	"context"
+	"log"
+	"push-base-service/models"
+	"push-base-service/service/catchup_service"
+	"push-base-service/service/metrics_service"
+	"push-base-service/service/pebble_service"
+	"push-base-service/service/socket_client_service"
+)
+
+// 补拉指标
+var (
+	catchupRunsCounter = metrics_service.NewCounterVec(
+		"push_catchup_runs_total", "Number of catch-up runs after upstream (re)connects by result (completed, failed, skipped)", "result")
+	catchupMessagesCounter = metrics_service.NewCounterVec(
+		"push_catchup_messages_total", "Number of messages fetched by catch-up and fed into the pipeline", "upstream")
+)
+
+// initCatchup 启用补拉时创建补拉接口客户端，并在上游连接（含重新连接）成功后开始补拉
+func (pc *PushCenter) initCatchup() error {
+	config := pc.config.CatchupConfig
+	if config == nil || !config.Enabled {
+		return nil
+	}
+	config.ApplyDefaults()
+	if err := config.Validate(); err != nil {
+		return err
+	}
+
+	pc.catchup = catchup_service.NewFetcher(config)
+	pc.checkpoints = make(map[string]*models.CatchupCheckpoint)
+	pc.catchupActive = make(map[string]bool)
+	pc.socketManager.SetUpstreamConnectHandler(func(upstream string) {
+		go pc.runCatchup(upstream)
+	})
+	log.Printf("🔄 断线补拉已启用: %s", config.Endpoint)
+	return nil
+}
+
+// messageCheckpoint 读取消息内容中的时间戳和 PIN ID，没有时间戳时返回 0
+func messageCheckpoint(chatMsg *socket_client_service.ChatNotificationMessage) (int64, string) {
+	if chatMsg.Data == nil {
+		return 0, ""
+	}
+	fields, err := messageFields(chatMsg.Data.Message)
+	if err != nil {
+		return 0, ""
+	}
+	timestamp, _ := fields["timestamp"].(float64)
+	pinId, _ := fields["pinId"].(string)
+	return int64(timestamp), pinId
+}
+
+// recordCheckpoint 收到上游消息时推进该上游的补拉检查点（只前进不后退），未启用补拉或非 Socket 来源的消息不记录
+func (pc *PushCenter) recordCheckpoint(chatMsg *socket_client_service.ChatNotificationMessage) {
+	if pc.catchup == nil || chatMsg.Upstream == "" {
+		return
+	}
+	timestamp, pinId := messageCheckpoint(chatMsg)
+	if timestamp <= 0 {
+		return
+	}
+
+	pc.checkpointMu.Lock()
+	defer pc.checkpointMu.Unlock()
+
+	if current := pc.loadCheckpointLocked(chatMsg.Upstream); current != nil && timestamp <= current.Timestamp {
+		return
+	}
+	checkpoint := &models.CatchupCheckpoint{Upstream: chatMsg.Upstream, Timestamp: timestamp, PinId: pinId}
+	pc.checkpoints[chatMsg.Upstream] = checkpoint
+	if err := pebble_service.SaveCatchupCheckpoint(checkpoint); err != nil {
+		log.Printf("⚠️ 保存补拉检查点失败: upstream=%s, err=%v", chatMsg.Upstream, err)
+	}
+}
+
+// loadCheckpointLocked 读取上游的补拉检查点（首次读取时从 Pebble 加载），调用方需持有 checkpointMu
+func (pc *PushCenter) loadCheckpointLocked(upstream string) *models.CatchupCheckpoint {
+	if checkpoint, ok := pc.checkpoints[upstream]; ok {
+		return checkpoint
+	}
+	checkpoint, err := pebble_service.GetCatchupCheckpoint(upstream)
+	if err != nil {
+		log.Printf("⚠️ 读取补拉检查点失败: upstream=%s, err=%v", upstream, err)
+		return nil
+	}
+	pc.checkpoints[upstream] = checkpoint
+	return checkpoint
+}
+
+// runCatchup 上游连接成功后补拉检查点之后的消息，逐页取回并交给 HandleMessage（与 Socket 收到的消息走同一流程），
+// 已推送过的消息由去重跳过；同一上游同时只进行一次补拉，没有检查点（首次连接）时不补拉
+func (pc *PushCenter) runCatchup(upstream string) {
+	pc.checkpointMu.Lock()
+	if pc.catchupActive[upstream] {
+		pc.checkpointMu.Unlock()
+		return
+	}
+	pc.catchupActive[upstream] = true
+	var since models.CatchupCheckpoint
+	checkpoint := pc.loadCheckpointLocked(upstream)
+	if checkpoint != nil {
+		since = *checkpoint
+	}
+	pc.checkpointMu.Unlock()
+
+	defer func() {
+		pc.checkpointMu.Lock()
+		delete(pc.catchupActive, upstream)
+		pc.checkpointMu.Unlock()
+	}()
+
+	if checkpoint == nil {
+		catchupRunsCounter.Inc("skipped")
+		log.Printf("⏭️ 上游 %s 尚无补拉检查点，跳过补拉", upstream)
+		return
+	}
+
+	log.Printf("🔄 上游 %s 已连接，开始补拉 timestamp=%d, pinId=%s 之后的消息", upstream, since.Timestamp, since.PinId)
+	total, hasMore := 0, true
+	for hasMore && total < pc.catchup.MaxMessages() {
+		page, err := pc.catchup.Fetch(context.Background(), &since)
+		if err != nil {
+			catchupRunsCounter.Inc("failed")
+			log.Printf("❌ 上游 %s 补拉失败（已补拉 %d 条）: %v", upstream, total, err)
+			return
+		}
+
+		previous := since
+		for _, payload := range page.Messages {
+			chatMsg, err := socket_client_service.DecodeChatNotification(payload)
+			if err != nil {
+				log.Printf("⚠️ 跳过无法解析的补拉消息: %v", err)
+				continue
+			}
+			chatMsg.Upstream = upstream
+			if timestamp, pinId := messageCheckpoint(chatMsg); timestamp > 0 {
+				since.Timestamp, since.PinId = timestamp, pinId
+			}
+			pc.HandleMessage(chatMsg)
+			total++
+		}
+		catchupMessagesCounter.Add(float64(len(page.Messages)), upstream)
+
+		// 检查点没有前进时停止，避免接口忽略 since 参数时反复拉取同一页
+		hasMore = page.HasMore && len(page.Messages) > 0 && since != previous
+	}
+
+	if hasMore {
+		log.Printf("⚠️ 上游 %s 补拉达到上限 %d 条，更早断线期间的剩余消息未补拉", upstream, pc.catchup.MaxMessages())
+	}
+	catchupRunsCounter.Inc("completed")
+	log.Printf("✅ 上游 %s 补拉完成: %d 条消息", upstream, total)
+}
@@ -0,0 +1,91 @@
+package pushcenter
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"push-base-service/service/catchup_service"
+	"push-base-service/service/pebble_service"
+	"push-base-service/service/socket_client_service"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+)
+
+// catchupPayload 补拉接口返回的一条群聊消息（与上游 Socket 下发的载荷格式相同）
+func catchupPayload(pinId string, timestamp int64) json.RawMessage {
+	return json.RawMessage(fmt.Sprintf(`{"M":"WS_SERVER_NOTIFY_GROUP_CHAT","C":0,"D":{"message":{"pinId":%q,"groupId":"group1","timestamp":%d}}}`, pinId, timestamp))
+}
+
+func TestCatchupAfterReconnect(t *testing.T) {
+	newTestStores(t)
+
+	// 补拉接口：since=100 返回重复下发的 pin-live 和 pin-a，since=101 返回 pin-b
+	var (
+		mu       sync.Mutex
+		requests []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer catchup-key" || r.URL.Query().Get("upstream") != "shard-1" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		since := r.URL.Query().Get("since")
+		mu.Lock()
+		requests = append(requests, since+"/"+r.URL.Query().Get("sincePinId"))
+		mu.Unlock()
+
+		page := catchup_service.Page{}
+		switch since {
+		case "100":
+			page.Messages = []json.RawMessage{catchupPayload("pin-live", 100), catchupPayload("pin-a", 101)}
+			page.HasMore = true
+		case "101":
+			page.Messages = []json.RawMessage{catchupPayload("pin-b", 102)}
+		}
+		json.NewEncoder(w).Encode(page)
+	}))
+	t.Cleanup(server.Close)
+
+	dispatcher := &recordingDispatcher{}
+	pc := NewPushCenter(&Config{CatchupConfig: &catchup_service.Config{Enabled: true, Endpoint: server.URL, APIKey: "catchup-key", PageSize: 2}})
+	pc.SetAudienceResolver(listResolver{"group1": {"alice", "bob"}})
+	pc.SetDispatcher(dispatcher)
+	pc.consuming = true
+	if err := pc.initCatchup(); err != nil {
+		t.Fatalf("initCatchup() failed, err: %v", err)
+	}
+
+	// 首次连接没有检查点，不补拉
+	pc.runCatchup("shard-1")
+	if len(requests) != 0 {
+		t.Fatalf("catch-up requests without checkpoint = %v, want none", requests)
+	}
+
+	// 在线收到的消息推进检查点
+	chatMsg, err := socket_client_service.DecodeChatNotification(catchupPayload("pin-live", 100))
+	if err != nil {
+		t.Fatalf("DecodeChatNotification() failed, err: %v", err)
+	}
+	chatMsg.Upstream = "shard-1"
+	pc.HandleMessage(chatMsg)
+	pc.inflight.Wait()
+
+	// 重新连接后补拉，已推送的 pin-live 被去重
+	pc.runCatchup("shard-1")
+	pc.inflight.Wait()
+
+	if want := []string{"100/pin-live", "101/pin-a"}; !reflect.DeepEqual(requests, want) {
+		t.Errorf("catch-up requests = %v, want %v", requests, want)
+	}
+	sort.Strings(dispatcher.metaIds)
+	if want := []string{"alice", "alice", "alice", "bob", "bob", "bob"}; !reflect.DeepEqual(dispatcher.metaIds, want) {
+		t.Errorf("dispatched to %v, want pin-live, pin-a and pin-b pushed once each", dispatcher.metaIds)
+	}
+	checkpoint, err := pebble_service.GetCatchupCheckpoint("shard-1")
+	if err != nil || checkpoint == nil || checkpoint.Timestamp != 102 || checkpoint.PinId != "pin-b" {
+		t.Errorf("checkpoint = %+v, %v, want timestamp 102 pin-b", checkpoint, err)
+	}
+}
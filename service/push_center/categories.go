@@ -0,0 +1,132 @@
+package pushcenter
+
+import (
+	"context"
+	"log"
+	"push-base-service/models"
+	"push-base-service/service/pebble_service"
+	"slices"
+)
+
+// StageCategory 通知类别环节名称，启用可操作通知时插入在 Send 之前
+const StageCategory = "category"
+
+// 通知类别ID
+const (
+	CategoryMessage  = "message"   // 普通聊天通知：回复、标为已读、静音聊天
+	CategoryMention  = "mention"   // 提及通知：回复、标为已读
+	CategoryCandyBag = "candy_bag" // 红包通知：打开领取、静音聊天
+)
+
+// 通知操作ID，客户端处理操作时：reply 通过聊天接口发送回复，mute 调用 /v1/push/add_blocked_chat 屏蔽该聊天
+const (
+	ActionReply    = "reply"
+	ActionMarkRead = "mark_read"
+	ActionMute     = "mute"
+	ActionOpen     = "open"
+)
+
+// notificationCategories 可操作通知的类别定义
+var notificationCategories = []models.NotificationCategory{
+	{ID: CategoryMessage, Actions: []models.NotificationAction{
+		{ID: ActionReply, Title: "Reply", TextInput: true},
+		{ID: ActionMarkRead, Title: "Mark as Read"},
+		{ID: ActionMute, Title: "Mute", Destructive: true},
+	}},
+	{ID: CategoryMention, Actions: []models.NotificationAction{
+		{ID: ActionReply, Title: "Reply", TextInput: true},
+		{ID: ActionMarkRead, Title: "Mark as Read"},
+	}},
+	{ID: CategoryCandyBag, Actions: []models.NotificationAction{
+		{ID: ActionOpen, Title: "Open", Foreground: true},
+		{ID: ActionMute, Title: "Mute", Destructive: true},
+	}},
+}
+
+// ActionsConfig 可操作通知配置
+// 开启后聊天通知按类型设置 categoryId（iOS aps.category / Expo categoryId），仅对已通过
+// /v1/push/set_notification_categories 注册了该类别的用户设置，未注册的客户端收到的通知不变
+type ActionsConfig struct {
+	Enabled bool `yaml:"enabled" json:"enabled"` // 是否为聊天通知设置通知类别
+}
+
+// NotificationCategories 返回可操作通知的类别定义
+func NotificationCategories() []models.NotificationCategory {
+	return notificationCategories
+}
+
+// IsNotificationCategory 是否为已定义的通知类别
+func IsNotificationCategory(id string) bool {
+	return slices.ContainsFunc(notificationCategories, func(category models.NotificationCategory) bool {
+		return category.ID == id
+	})
+}
+
+// actionsEnabled 是否启用可操作通知
+func (pc *PushCenter) actionsEnabled() bool {
+	return pc.config.ActionsConfig != nil && pc.config.ActionsConfig.Enabled
+}
+
+// notificationCategory 聊天通知的类别：提及通知为 mention，红包为 candy_bag，其余为 message
+func notificationCategory(group *NotificationGroup, parsedInfo *ParsedMessageInfo) string {
+	switch {
+	case group.Mention:
+		return CategoryMention
+	case isCandyBag(parsedInfo.ChatInfoType):
+		return CategoryCandyBag
+	default:
+		return CategoryMessage
+	}
+}
+
+// categoryStage 各组中已注册对应通知类别的用户拆分为单独的分组，其通知带上类别ID；摘要通知不带操作
+func (pc *PushCenter) categoryStage(ctx context.Context, msg *PipelineMessage, next PipelineHandler) error {
+	var metaIds []string
+	for _, group := range msg.Groups {
+		if !group.Summary && group.Notification != nil {
+			metaIds = append(metaIds, group.Users...)
+		}
+	}
+	if len(metaIds) == 0 {
+		return next(ctx, msg)
+	}
+	preferences, err := pebble_service.GetUserPreferencesBatch(metaIds)
+	if err != nil {
+		log.Printf("⚠️ 获取用户通知类别失败，发送不带操作的通知: %v", err)
+		return next(ctx, msg)
+	}
+
+	var actionable []*NotificationGroup
+	for _, group := range msg.Groups {
+		if group.Summary || group.Notification == nil {
+			continue
+		}
+
+		category := notificationCategory(group, msg.Info)
+		var registered, others []string
+		for _, metaId := range group.Users {
+			if prefs := preferences[metaId]; prefs != nil && slices.Contains(prefs.Categories, category) {
+				registered = append(registered, metaId)
+			} else {
+				others = append(others, metaId)
+			}
+		}
+		if len(registered) == 0 {
+			continue
+		}
+
+		notification := *group.Notification
+		notification.CategoryID = category
+		if len(others) == 0 {
+			group.Notification = &notification
+			continue
+		}
+		split := *group
+		split.Users = registered
+		split.Notification = &notification
+		group.Users = others
+		actionable = append(actionable, &split)
+	}
+	msg.Groups = append(msg.Groups, actionable...)
+	return next(ctx, msg)
+}
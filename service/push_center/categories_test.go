@@ -0,0 +1,60 @@
+package pushcenter
+
+import (
+	"push-base-service/service/pebble_service"
+	"push-base-service/service/push_service"
+	"push-base-service/service/socket_client_service"
+	"testing"
+)
+
+func TestActionableNotifications(t *testing.T) {
+	newTestStores(t)
+
+	if _, err := pebble_service.SetNotificationCategories("bob", []string{CategoryMessage}); err != nil {
+		t.Fatalf("SetNotificationCategories() failed, err: %v", err)
+	}
+	if _, err := pebble_service.SetNotificationCategories("dave", []string{CategoryMention}); err != nil {
+		t.Fatalf("SetNotificationCategories() failed, err: %v", err)
+	}
+
+	source := &stubSource{}
+	dispatcher := &notificationDispatcher{notifications: make(map[string]*push_service.PushNotification)}
+	pc := NewPushCenter(&Config{ActionsConfig: &ActionsConfig{Enabled: true}})
+	pc.sources = []MessageSource{source}
+	pc.SetAudienceResolver(listResolver{"group1": {"bob", "carol", "dave"}})
+	pc.SetDispatcher(dispatcher)
+	pc.SetChatMessageHandler()
+	pc.consuming = true
+
+	source.handler(&socket_client_service.ChatNotificationMessage{
+		Type: "group_chat",
+		Data: &socket_client_service.ExtraServiceMessage{Message: map[string]interface{}{"pinId": "pin-1", "groupId": "group1"}},
+	})
+	pc.inflight.Wait()
+
+	// 只有注册了对应类别的用户收到带类别的通知，其余用户的通知不变
+	if notification := dispatcher.take("bob"); notification == nil || notification.CategoryID != CategoryMessage {
+		t.Errorf("bob's notification = %+v, want category %q", notification, CategoryMessage)
+	}
+	for _, metaId := range []string{"carol", "dave"} {
+		if notification := dispatcher.take(metaId); notification == nil || notification.CategoryID != "" {
+			t.Errorf("%s's notification = %+v, want no category", metaId, notification)
+		}
+	}
+}
+
+func TestNotificationCategory(t *testing.T) {
+	info := &ParsedMessageInfo{ChatInfoType: 23}
+	if category := notificationCategory(&NotificationGroup{Mention: true}, info); category != CategoryMention {
+		t.Errorf("mention category = %q", category)
+	}
+	if category := notificationCategory(&NotificationGroup{}, info); category != CategoryCandyBag {
+		t.Errorf("candy bag category = %q", category)
+	}
+	if category := notificationCategory(&NotificationGroup{}, &ParsedMessageInfo{}); category != CategoryMessage {
+		t.Errorf("message category = %q", category)
+	}
+	if !IsNotificationCategory(CategoryCandyBag) || IsNotificationCategory("unknown") {
+		t.Error("IsNotificationCategory() mismatch")
+	}
+}
@@ -0,0 +1,77 @@
+package pushcenter
+
+import (
+	"context"
+	"log"
+	"push-base-service/models"
+	"push-base-service/service/push_service"
+	"push-base-service/service/storage_service"
+	"sort"
+)
+
+// sendWithChatSounds 按用户为该聊天设置的通知声音分组发送，未设置的用户使用路由规则决定的声音，
+// 设置为 silent 的用户收到不播放声音的通知
+func (pc *PushCenter) sendWithChatSounds(ctx context.Context, metaIds []string, notification *push_service.PushNotification, hiddenBody string, parsedInfo *ParsedMessageInfo, isMention bool) (*push_service.BatchPushResult, error) {
+	groups := groupUsersByChatSound(metaIds, messageChatID(parsedInfo))
+	if len(groups) == 1 && groups[""] != nil {
+		return pc.sendWithPreview(ctx, metaIds, notification, hiddenBody, parsedInfo, isMention)
+	}
+
+	sounds := make([]string, 0, len(groups))
+	for sound := range groups {
+		sounds = append(sounds, sound)
+	}
+	sort.Strings(sounds)
+
+	var results []*push_service.BatchPushResult
+	var lastErr error
+	for _, sound := range sounds {
+		result, err := pc.sendWithPreview(ctx, groups[sound], withChatSound(notification, sound), hiddenBody, parsedInfo, isMention)
+		if err != nil {
+			log.Printf("❌ 推送自定义声音通知失败: Sound=%s, 错误: %v", sound, err)
+			lastErr = err
+			continue
+		}
+		results = append(results, result)
+	}
+	if len(results) == 0 {
+		return nil, lastErr
+	}
+	return mergeBatchResults(results), nil
+}
+
+// groupUsersByChatSound 按用户设置的聊天通知声音分组，未设置的用户归入 "" 组；获取失败时全部使用默认声音
+func groupUsersByChatSound(metaIds []string, chatId string) map[string][]string {
+	groups := make(map[string][]string)
+	if chatId == "" {
+		groups[""] = metaIds
+		return groups
+	}
+
+	sounds, err := storage_service.GetChatSounds(metaIds, chatId)
+	if err != nil {
+		log.Printf("⚠️ 获取聊天通知声音失败，使用默认声音: ChatID=%s, 错误: %v", chatId, err)
+		groups[""] = metaIds
+		return groups
+	}
+	for _, metaId := range metaIds {
+		sound := sounds[metaId]
+		groups[sound] = append(groups[sound], metaId)
+	}
+	return groups
+}
+
+// withChatSound 复制通知并使用用户设置的聊天通知声音，"" 表示保持原有声音
+func withChatSound(notification *push_service.PushNotification, sound string) *push_service.PushNotification {
+	if sound == "" {
+		return notification
+	}
+
+	copied := *notification
+	if sound == models.ChatSoundSilent {
+		copied.Sound = ""
+	} else {
+		copied.Sound = sound
+	}
+	return &copied
+}
@@ -0,0 +1,60 @@
+package pushcenter
+
+import (
+	"context"
+	"push-base-service/models"
+	"push-base-service/service/push_service"
+	"sync"
+	"testing"
+)
+
+// soundDispatcher 记录每个用户收到的通知声音
+type soundDispatcher struct {
+	mu     sync.Mutex
+	sounds map[string]string
+}
+
+func (d *soundDispatcher) SendCustomNotificationToUsers(ctx context.Context, metaIds []string, notification *push_service.PushNotification) (*push_service.BatchPushResult, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, metaId := range metaIds {
+		d.sounds[metaId] = notification.Sound
+	}
+	return &push_service.BatchPushResult{TotalUsers: len(metaIds), SuccessCount: len(metaIds)}, nil
+}
+
+func TestSendWithChatSounds(t *testing.T) {
+	ps := newTestStores(t)
+	ps.SetChatSound("alice", "group1", "group", "chime.wav")
+	ps.SetChatSound("bob", "group1", "group", models.ChatSoundSilent)
+	ps.SetChatSound("carol", "group2", "group", "bell.wav")
+
+	dispatcher := &soundDispatcher{sounds: make(map[string]string)}
+	pc := &PushCenter{config: &Config{}, dispatcher: dispatcher}
+
+	parsedInfo := &ParsedMessageInfo{ChatType: "group_chat", GroupId: "group1"}
+	notification := &push_service.PushNotification{Title: "t", Body: "b", Sound: "default"}
+	result, err := pc.sendWithChatSounds(context.Background(), []string{"alice", "bob", "carol"}, notification, "New message", parsedInfo, false)
+	if err != nil || result.SuccessCount != 3 {
+		t.Fatalf("sendWithChatSounds() = %+v, %v", result, err)
+	}
+
+	want := map[string]string{"alice": "chime.wav", "bob": "", "carol": "default"}
+	for metaId, sound := range want {
+		if got := dispatcher.sounds[metaId]; got != sound {
+			t.Errorf("%s sound = %q, want %q", metaId, got, sound)
+		}
+	}
+	if notification.Sound != "default" {
+		t.Errorf("原通知不应被修改: %q", notification.Sound)
+	}
+
+	// 恢复默认声音
+	ps.SetChatSound("alice", "group1", "group", "")
+	if sounds, _ := ps.GetUserChatSounds("alice"); len(sounds.ChatSounds) != 0 {
+		t.Errorf("GetUserChatSounds() = %+v, want empty after reset", sounds.ChatSounds)
+	}
+	if sounds, _ := ps.GetUserChatSounds("carol"); len(sounds.ChatSounds) != 1 || sounds.ChatSounds[0].Sound != "bell.wav" {
+		t.Errorf("GetUserChatSounds() = %+v, want bell.wav", sounds.ChatSounds)
+	}
+}
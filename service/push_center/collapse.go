@@ -0,0 +1,53 @@
+package pushcenter
+
+import (
+	"fmt"
+	"push-base-service/service/push_service"
+)
+
+// 同一聊天的通知在设备上的合并方式
+const (
+	CollapseModeNone    = "none"    // 不合并，每条消息单独展示
+	CollapseModeGroup   = "group"   // 同一聊天的通知归为一组（iOS thread-id / Android tag）
+	CollapseModeReplace = "replace" // 同一聊天的新通知替换旧通知，并归为一组
+)
+
+// ValidateCollapseMode 校验通知合并方式，空值等同于 none
+func ValidateCollapseMode(mode string) error {
+	switch mode {
+	case "", CollapseModeNone, CollapseModeGroup, CollapseModeReplace:
+		return nil
+	default:
+		return fmt.Errorf("未知的通知合并方式: %s（可选 none/group/replace）", mode)
+	}
+}
+
+// chatCollapseKey 返回消息所属聊天的合并键，无法确定聊天时返回空
+func chatCollapseKey(parsedInfo *ParsedMessageInfo) string {
+	switch {
+	case parsedInfo.ChatType == "group_chat" && parsedInfo.GroupId != "":
+		return "group:" + parsedInfo.GroupId
+	case parsedInfo.ChatType == "private_chat" && parsedInfo.MetaId != "":
+		return "private:" + parsedInfo.MetaId
+	default:
+		return ""
+	}
+}
+
+// applyCollapse 按配置的合并方式设置通知的折叠ID和分组ID
+// 提及通知只分组不替换，避免被同一聊天的后续普通消息覆盖
+func (pc *PushCenter) applyCollapse(notification *push_service.PushNotification, parsedInfo *ParsedMessageInfo, isMention bool) {
+	mode := pc.config.CollapseMode
+	if mode == "" || mode == CollapseModeNone {
+		return
+	}
+	key := chatCollapseKey(parsedInfo)
+	if key == "" {
+		return
+	}
+
+	notification.ThreadID = key
+	if mode == CollapseModeReplace && !isMention {
+		notification.CollapseID = key
+	}
+}
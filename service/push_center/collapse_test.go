@@ -0,0 +1,43 @@
+package pushcenter
+
+import "testing"
+
+func TestApplyCollapse(t *testing.T) {
+	groupInfo := &ParsedMessageInfo{ChatType: "group_chat", GroupId: "g1"}
+	privateInfo := &ParsedMessageInfo{ChatType: "private_chat", MetaId: "m1"}
+
+	cases := []struct {
+		mode         string
+		info         *ParsedMessageInfo
+		mention      bool
+		wantCollapse string
+		wantThread   string
+	}{
+		{"", groupInfo, false, "", ""},
+		{CollapseModeNone, groupInfo, false, "", ""},
+		{CollapseModeGroup, groupInfo, false, "", "group:g1"},
+		{CollapseModeReplace, groupInfo, false, "group:g1", "group:g1"},
+		{CollapseModeReplace, privateInfo, false, "private:m1", "private:m1"},
+		{CollapseModeReplace, groupInfo, true, "", "group:g1"},
+		{CollapseModeReplace, &ParsedMessageInfo{ChatType: "group_chat"}, false, "", ""},
+	}
+	for _, c := range cases {
+		pc := &PushCenter{config: &Config{CollapseMode: c.mode}}
+		notification := pc.newRoutedNotification("title", "body", nil, c.info, c.mention)
+		if notification.CollapseID != c.wantCollapse || notification.ThreadID != c.wantThread {
+			t.Errorf("mode=%q info=%+v mention=%v: collapse=%q thread=%q, want %q %q",
+				c.mode, c.info, c.mention, notification.CollapseID, notification.ThreadID, c.wantCollapse, c.wantThread)
+		}
+	}
+}
+
+func TestValidateCollapseMode(t *testing.T) {
+	for _, mode := range []string{"", CollapseModeNone, CollapseModeGroup, CollapseModeReplace} {
+		if err := ValidateCollapseMode(mode); err != nil {
+			t.Errorf("ValidateCollapseMode(%q) = %v, want nil", mode, err)
+		}
+	}
+	if err := ValidateCollapseMode("stack"); err == nil {
+		t.Errorf("ValidateCollapseMode(stack) = nil, want error")
+	}
+}
@@ -0,0 +1,222 @@
+package pushcenter
+
+import (
+	"context"
+	"errors"
+	"log"
+	"push-base-service/models"
+	"push-base-service/service/pebble_service"
+	"push-base-service/service/push_service"
+	"slices"
+	"time"
+)
+
+// 投递追踪默认配置
+const (
+	DefaultDeliveryRetention     = 7 * 24 * time.Hour
+	DefaultReceiptDelay          = 15 * time.Minute // Expo 建议推送 15 分钟后再查询回执
+	DefaultReceiptCheckInterval  = 5 * time.Minute
+	receiptMaxAge                = 24 * time.Hour // Expo 回执保留约 24 小时，超过后不再查询
+	receiptCheckBatchSize        = 1000
+	receiptCheckTimeout          = 30 * time.Second
+	deliveryErrorReceiptNotFound = "回执已过期或推送平台未返回回执"
+)
+
+// DeliveryConfig 投递追踪配置
+// 开启后为每条消息按接收用户和平台记录推送结果，并定期查询推送平台回执写入最终送达状态
+type DeliveryConfig struct {
+	Enabled       bool          `yaml:"enabled" json:"enabled"`               // 是否启用投递追踪
+	Retention     time.Duration `yaml:"retention" json:"retention"`           // 投递记录保留时长
+	ReceiptDelay  time.Duration `yaml:"receipt_delay" json:"receipt_delay"`   // 推送后多久查询回执
+	CheckInterval time.Duration `yaml:"check_interval" json:"check_interval"` // 查询回执的间隔
+}
+
+// receiptChecker 按平台查询投递回执，push_service.Manager 即为默认实现
+type receiptChecker interface {
+	CheckReceipts(ctx context.Context, platform string, receiptIDs []string) (map[string]*push_service.ReceiptOutcome, error)
+}
+
+// deliveryTrackingEnabled 是否启用投递追踪
+func (pc *PushCenter) deliveryTrackingEnabled() bool {
+	return pc.config.DeliveryConfig != nil && pc.config.DeliveryConfig.Enabled
+}
+
+// recordDeliveries 记录消息对每个接收用户的投递结果
+// recipients 为全部接收用户，sent 为实际发送的用户，skipped 为按偏好跳过的用户及原因（其余视为屏蔽了该聊天），results 为推送结果
+// 被追踪用户的投递结果同时写入推送追踪，不受投递追踪开关影响
+func (pc *PushCenter) recordDeliveries(pinId string, recipients, sent []string, skipped map[string]string, results []*push_service.PushResult) {
+	tracking := pc.deliveryTrackingEnabled() && pinId != ""
+	if !tracking && !globalTracer.active() {
+		return
+	}
+
+	records := buildDeliveryRecords(pinId, recipients, sent, skipped, results)
+	traceDeliveries(records)
+	if !tracking {
+		return
+	}
+
+	applyDeviceMetadata(records, results)
+	if err := pebble_service.SaveDeliveryRecords(records); err != nil {
+		log.Printf("⚠️ 保存投递记录失败: PinId=%s, 错误: %v", pinId, err)
+	}
+}
+
+// buildDeliveryRecords 生成消息对每个接收用户的投递结果，参数含义见 recordDeliveries
+func buildDeliveryRecords(pinId string, recipients, sent []string, skipped map[string]string, results []*push_service.PushResult) []*models.DeliveryRecord {
+	records := make([]*models.DeliveryRecord, 0, len(recipients))
+	attempted := make(map[string]bool)
+	for _, result := range results {
+		if result == nil || result.MetaID == "" {
+			continue
+		}
+		attempted[result.MetaID] = true
+
+		record := &models.DeliveryRecord{
+			PinID:        pinId,
+			MetaID:       result.MetaID,
+			Platform:     result.Platform,
+			Attempted:    true,
+			TicketStatus: models.DeliveryTicketOK,
+			ReceiptID:    result.ReceiptID,
+		}
+		if !result.Success {
+			record.TicketStatus = models.DeliveryTicketError
+			if result.Error != nil {
+				record.Error = result.Error.Error()
+			}
+		} else if result.ReceiptID != "" {
+			record.ReceiptStatus = models.DeliveryReceiptPending
+		}
+		records = append(records, record)
+	}
+
+	for _, metaId := range recipients {
+		if attempted[metaId] {
+			continue
+		}
+		attempted[metaId] = true
+
+		reason := models.DeliverySkipNotSent
+		if skipReason, exists := skipped[metaId]; exists {
+			reason = skipReason
+		} else if !slices.Contains(sent, metaId) {
+			reason = models.DeliverySkipBlocked
+		}
+		records = append(records, &models.DeliveryRecord{
+			PinID:        pinId,
+			MetaID:       metaId,
+			TicketStatus: models.DeliveryTicketSkipped,
+			SkipReason:   reason,
+		})
+	}
+	return records
+}
+
+// deliveryMaintenanceLoop 定期查询到期的投递回执并清理过期的投递记录；
+// 停止时最后查询一次到期回执，退出后关闭 done
+func (pc *PushCenter) deliveryMaintenanceLoop(stopCh, done chan struct{}) {
+	defer close(done)
+
+	interval := pc.config.DeliveryConfig.CheckInterval
+	if interval <= 0 {
+		interval = DefaultReceiptCheckInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stopCh:
+			pc.checkReceipts(pc.pushManager)
+			return
+		case <-ticker.C:
+			pc.checkReceipts(pc.pushManager)
+			pc.purgeDeliveryRecords()
+		}
+	}
+}
+
+// checkReceipts 按平台批量查询到期的回执并写入投递记录
+func (pc *PushCenter) checkReceipts(checker receiptChecker) {
+	delay := pc.config.DeliveryConfig.ReceiptDelay
+	if delay <= 0 {
+		delay = DefaultReceiptDelay
+	}
+
+	now := time.Now()
+	due, err := pebble_service.ListDueReceipts(now.Add(-delay).Unix(), receiptCheckBatchSize)
+	if err != nil {
+		log.Printf("⚠️ 读取待查询回执失败: %v", err)
+		return
+	}
+	if len(due) == 0 {
+		return
+	}
+
+	byPlatform := make(map[string][]*models.PendingReceipt)
+	for _, receipt := range due {
+		byPlatform[receipt.Platform] = append(byPlatform[receipt.Platform], receipt)
+	}
+
+	resolved := 0
+	for platform, receipts := range byPlatform {
+		receiptIDs := make([]string, len(receipts))
+		for i, receipt := range receipts {
+			receiptIDs[i] = receipt.ReceiptID
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), receiptCheckTimeout)
+		outcomes, err := checker.CheckReceipts(ctx, platform, receiptIDs)
+		cancel()
+		unsupported := errors.Is(err, push_service.ErrReceiptsUnsupported)
+		if err != nil && !unsupported {
+			log.Printf("⚠️ 查询投递回执失败: 平台=%s, 错误: %v", platform, err)
+			continue
+		}
+
+		for _, receipt := range receipts {
+			status, errMsg := models.DeliveryReceiptUnavailable, ""
+			if outcome, found := outcomes[receipt.ReceiptID]; found {
+				status = models.DeliveryReceiptDelivered
+				if !outcome.Delivered {
+					status, errMsg = models.DeliveryReceiptFailed, outcome.Error
+				}
+			} else if !unsupported {
+				// 回执尚未生成时下次再查，超过保留期仍没有则不再等待
+				if now.Sub(time.Unix(receipt.SentAt, 0)) < receiptMaxAge {
+					continue
+				}
+				errMsg = deliveryErrorReceiptNotFound
+			}
+
+			if err := pebble_service.ResolveReceipt(receipt, status, errMsg); err != nil {
+				log.Printf("⚠️ 写入投递回执失败: %v", err)
+				continue
+			}
+			traceReceipt(receipt, status, errMsg)
+			publishReceipt(receipt, status, errMsg)
+			deliveryOutcomesCounter.Inc(receipt.Platform, appVersionLabel(receipt.AppVersion), osLabel(receipt.OSVersion), status)
+			resolved++
+		}
+	}
+
+	if resolved > 0 {
+		log.Printf("📬 已更新 %d 条投递回执", resolved)
+	}
+}
+
+// purgeDeliveryRecords 清理超过保留时长的投递记录
+func (pc *PushCenter) purgeDeliveryRecords() {
+	retention := pc.config.DeliveryConfig.Retention
+	if retention <= 0 {
+		retention = DefaultDeliveryRetention
+	}
+
+	count, err := pebble_service.PurgeDeliveryRecords(time.Now().Add(-retention).Unix())
+	if err != nil {
+		log.Printf("⚠️ 清理过期投递记录失败: %v", err)
+	} else if count > 0 {
+		log.Printf("🧹 已清理 %d 条过期投递记录", count)
+	}
+}
@@ -0,0 +1,135 @@
+package pushcenter
+
+import (
+	"log"
+	"push-base-service/models"
+	"push-base-service/service/metrics_service"
+	"push-base-service/service/pebble_service"
+	"push-base-service/service/push_service"
+	"sort"
+	"strings"
+	"time"
+)
+
+// deliveryOutcomesCounter 投递结果数，按平台、客户端版本和系统分组：推送时记录 accepted / ticket_error，查询回执后记录回执状态
+var deliveryOutcomesCounter = metrics_service.NewCounterVec(
+	"push_delivery_outcomes_total", "Number of delivery outcomes by platform, app version, OS and outcome", "platform", "app_version", "os", "outcome")
+
+// 投递结果指标中推送平台受理的结果，回执结果沿用回执状态
+const (
+	deliveryOutcomeAccepted    = "accepted"
+	deliveryOutcomeTicketError = "ticket_error"
+)
+
+// unknownDeviceLabel 设备未上报客户端版本或系统版本时的分组值
+const unknownDeviceLabel = "unknown"
+
+// appVersionLabel 客户端版本的分组值
+func appVersionLabel(appVersion string) string {
+	if appVersion = strings.TrimSpace(appVersion); appVersion == "" {
+		return unknownDeviceLabel
+	}
+	return appVersion
+}
+
+// osLabel 系统版本的分组值，只保留系统名和主版本（如 "iOS 17.5" 为 "iOS 17"），避免按小版本拆分过细
+func osLabel(osVersion string) string {
+	osVersion = strings.TrimSpace(osVersion)
+	if osVersion == "" {
+		return unknownDeviceLabel
+	}
+	if i := strings.IndexByte(osVersion, '.'); i > 0 {
+		osVersion = osVersion[:i]
+	}
+	return osVersion
+}
+
+// applyDeviceMetadata 为尝试推送的投递记录填入接收设备的客户端版本和系统版本（设备ID即推送令牌），并记录推送平台受理结果指标
+func applyDeviceMetadata(records []*models.DeliveryRecord, results []*push_service.PushResult) {
+	tokens := make(map[string]string)
+	for _, result := range results {
+		if result != nil && result.MetaID != "" && result.Token != "" {
+			tokens[result.MetaID+":"+result.Platform] = result.Token
+		}
+	}
+	deviceIds := make([]string, 0, len(tokens))
+	for _, token := range tokens {
+		deviceIds = append(deviceIds, token)
+	}
+	devices, err := pebble_service.GetDevicesInfo(deviceIds)
+	if err != nil {
+		log.Printf("⚠️ 获取接收设备信息失败，投递记录不带设备版本: %v", err)
+	}
+
+	for _, record := range records {
+		if !record.Attempted {
+			continue
+		}
+		if device := devices[tokens[record.MetaID+":"+record.Platform]]; device != nil {
+			record.AppVersion = device.AppVersion
+			record.OSVersion = device.OSVersion
+		}
+		outcome := deliveryOutcomeAccepted
+		if record.TicketStatus == models.DeliveryTicketError {
+			outcome = deliveryOutcomeTicketError
+		}
+		deliveryOutcomesCounter.Inc(record.Platform, appVersionLabel(record.AppVersion), osLabel(record.OSVersion), outcome)
+	}
+}
+
+// DeliveryBreakdown 按平台、客户端版本和系统统计 since 之后的投递结果，platform 不为空时只统计该平台；按推送数从多到少排列
+func DeliveryBreakdown(since time.Time, platform string) ([]*models.DeliveryBreakdown, error) {
+	groups := make(map[[3]string]*models.DeliveryBreakdown)
+	err := pebble_service.ScanDeliveryRecords(since.Unix(), func(record *models.DeliveryRecord) {
+		if !record.Attempted || (platform != "" && record.Platform != platform) {
+			return
+		}
+
+		key := [3]string{record.Platform, appVersionLabel(record.AppVersion), osLabel(record.OSVersion)}
+		group, ok := groups[key]
+		if !ok {
+			group = &models.DeliveryBreakdown{Platform: key[0], AppVersion: key[1], OS: key[2]}
+			groups[key] = group
+		}
+		group.Sent++
+		if record.TicketStatus == models.DeliveryTicketError {
+			group.TicketErrors++
+			return
+		}
+		switch record.ReceiptStatus {
+		case models.DeliveryReceiptDelivered:
+			group.Delivered++
+		case models.DeliveryReceiptFailed:
+			group.Failed++
+		case models.DeliveryReceiptPending:
+			group.Pending++
+		case models.DeliveryReceiptUnavailable:
+			group.Unavailable++
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	breakdown := make([]*models.DeliveryBreakdown, 0, len(groups))
+	for _, group := range groups {
+		if resolved := group.Delivered + group.Failed + group.TicketErrors; resolved > 0 {
+			group.DeliveryRate = float64(group.Delivered) / float64(resolved)
+		}
+		breakdown = append(breakdown, group)
+	}
+	sort.Slice(breakdown, func(i, j int) bool {
+		a, b := breakdown[i], breakdown[j]
+		switch {
+		case a.Sent != b.Sent:
+			return a.Sent > b.Sent
+		case a.Platform != b.Platform:
+			return a.Platform < b.Platform
+		case a.AppVersion != b.AppVersion:
+			return a.AppVersion < b.AppVersion
+		default:
+			return a.OS < b.OS
+		}
+	})
+	return breakdown, nil
+}
@@ -0,0 +1,78 @@
+package pushcenter
+
+import (
+	"errors"
+	"push-base-service/models"
+	"push-base-service/service/pebble_service"
+	"push-base-service/service/push_service"
+	"testing"
+	"time"
+)
+
+func TestDeliveryBreakdown(t *testing.T) {
+	newTestStores(t)
+
+	devices := map[string]models.DeviceMetadata{
+		"token-alice": {AppVersion: "2.1.0", OSVersion: "iOS 17.5"},
+		"token-bob":   {AppVersion: "2.1.0", OSVersion: "iOS 17.4"},
+		"token-carol": {AppVersion: "2.0.3", OSVersion: "iOS 17.5"},
+	}
+	for token, metadata := range devices {
+		metaId := token[len("token-"):]
+		if _, err := pebble_service.SetDeviceMetadata(token, "expo", metaId, metadata); err != nil {
+			t.Fatalf("SetDeviceMetadata() failed, err: %v", err)
+		}
+	}
+
+	pc := NewPushCenter(&Config{DeliveryConfig: &DeliveryConfig{Enabled: true, ReceiptDelay: time.Nanosecond}})
+	pc.recordDeliveries("pin-breakdown", []string{"alice", "bob", "carol", "dave", "erin"}, []string{"alice", "bob", "carol", "dave"}, nil, []*push_service.PushResult{
+		{MetaID: "alice", Platform: "expo", Token: "token-alice", Success: true, ReceiptID: "r-alice"},
+		{MetaID: "bob", Platform: "expo", Token: "token-bob", Success: true, ReceiptID: "r-bob"},
+		{MetaID: "carol", Platform: "expo", Token: "token-carol", Success: false, Error: errors.New("InvalidCredentials")},
+		{MetaID: "dave", Platform: "expo", Token: "token-dave", Success: true, ReceiptID: "r-dave"},
+	})
+	pc.checkReceipts(stubReceiptChecker{
+		"r-alice": {Delivered: true},
+		"r-bob":   {Error: "DeviceNotRegistered", DeviceUnregistered: true},
+		"r-dave":  {Delivered: true},
+	})
+
+	records, _ := pebble_service.GetDeliveryRecords("pin-breakdown")
+	for _, record := range records {
+		if record.MetaID == "alice" && (record.AppVersion != "2.1.0" || record.OSVersion != "iOS 17.5") {
+			t.Errorf("alice's record = %+v, want device versions", record)
+		}
+	}
+
+	breakdown, err := DeliveryBreakdown(time.Now().Add(-time.Hour), "")
+	if err != nil {
+		t.Fatalf("DeliveryBreakdown() failed, err: %v", err)
+	}
+	got := make(map[string]*models.DeliveryBreakdown)
+	for _, group := range breakdown {
+		got[group.AppVersion+"/"+group.OS] = group
+	}
+	if len(got) != 3 {
+		t.Fatalf("DeliveryBreakdown() = %d groups, want 3 (skipped users are not counted)", len(breakdown))
+	}
+	// 系统版本按主版本归组
+	if group := got["2.1.0/iOS 17"]; group == nil || group.Sent != 2 || group.Delivered != 1 || group.Failed != 1 || group.DeliveryRate != 0.5 {
+		t.Errorf("2.1.0 group = %+v", group)
+	}
+	if group := got["2.0.3/iOS 17"]; group == nil || group.TicketErrors != 1 || group.DeliveryRate != 0 {
+		t.Errorf("2.0.3 group = %+v", group)
+	}
+	if group := got["unknown/unknown"]; group == nil || group.Delivered != 1 || group.DeliveryRate != 1 {
+		t.Errorf("unknown group = %+v", group)
+	}
+	if breakdown[0].AppVersion != "2.1.0" {
+		t.Errorf("first group = %+v, want the group with the most sends", breakdown[0])
+	}
+
+	if count := deliveryOutcomesCounter.Get("expo", "2.1.0", "iOS 17", models.DeliveryReceiptFailed); count < 1 {
+		t.Errorf("push_delivery_outcomes_total{outcome=failed} = %v", count)
+	}
+	if other, _ := DeliveryBreakdown(time.Now().Add(-time.Hour), "fcm"); len(other) != 0 {
+		t.Errorf("DeliveryBreakdown(fcm) = %+v, want empty", other)
+	}
+}
@@ -0,0 +1,73 @@
+package pushcenter
+
+import (
+	"context"
+	"errors"
+	"push-base-service/models"
+	"push-base-service/service/pebble_service"
+	"push-base-service/service/push_service"
+	"testing"
+	"time"
+)
+
+// stubReceiptChecker 返回固定回执结果的测试查询器
+type stubReceiptChecker map[string]*push_service.ReceiptOutcome
+
+func (s stubReceiptChecker) CheckReceipts(ctx context.Context, platform string, receiptIDs []string) (map[string]*push_service.ReceiptOutcome, error) {
+	return s, nil
+}
+
+func TestDeliveryTrackingRecordsAndReceipts(t *testing.T) {
+	newTestStores(t)
+
+	pc := NewPushCenter(&Config{DeliveryConfig: &DeliveryConfig{Enabled: true, ReceiptDelay: time.Nanosecond}})
+	pc.recordDeliveries("pin-delivery", []string{"alice", "bob", "carol", "dave"}, []string{"alice", "bob", "carol"}, nil, []*push_service.PushResult{
+		{MetaID: "alice", Platform: "expo", Success: true, ReceiptID: "r-alice"},
+		{MetaID: "bob", Platform: "expo", Success: true, ReceiptID: "r-bob"},
+		{MetaID: "carol", Platform: "expo", Success: false, Error: errors.New("InvalidCredentials")},
+	})
+
+	pc.checkReceipts(stubReceiptChecker{
+		"r-alice": {Delivered: true},
+		"r-bob":   {Error: "DeviceNotRegistered", DeviceUnregistered: true},
+	})
+
+	records, err := pebble_service.GetDeliveryRecords("pin-delivery")
+	if err != nil {
+		t.Fatalf("GetDeliveryRecords() failed, err: %v", err)
+	}
+	got := make(map[string]*models.DeliveryRecord)
+	for _, record := range records {
+		got[record.MetaID] = record
+	}
+
+	want := map[string]struct {
+		attempted     bool
+		ticketStatus  string
+		receiptStatus string
+		skipReason    string
+	}{
+		"alice": {true, models.DeliveryTicketOK, models.DeliveryReceiptDelivered, ""},
+		"bob":   {true, models.DeliveryTicketOK, models.DeliveryReceiptFailed, ""},
+		"carol": {true, models.DeliveryTicketError, "", ""},
+		"dave":  {false, models.DeliveryTicketSkipped, "", models.DeliverySkipBlocked},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d delivery records, want %d", len(got), len(want))
+	}
+	for metaId, w := range want {
+		record := got[metaId]
+		if record == nil {
+			t.Errorf("missing delivery record for %s", metaId)
+			continue
+		}
+		if record.Attempted != w.attempted || record.TicketStatus != w.ticketStatus ||
+			record.ReceiptStatus != w.receiptStatus || record.SkipReason != w.skipReason {
+			t.Errorf("%s: got %+v, want %+v", metaId, record, w)
+		}
+	}
+
+	if pending, _ := pebble_service.ListDueReceipts(time.Now().Unix(), 0); len(pending) != 0 {
+		t.Errorf("%d receipts still pending after check, want 0", len(pending))
+	}
+}
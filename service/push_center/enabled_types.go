@@ -0,0 +1,132 @@
+package pushcenter
+
+import (
+	"fmt"
+	"log"
+	"push-base-service/service/pebble_service"
+	"slices"
+	"sync"
+)
+
+// 启用消息类型的来源
+const (
+	EnabledTypesSourceConfig = "config" // 配置文件 push_center.enabled_types
+	EnabledTypesSourceAPI    = "api"    // 管理接口（保存在 Pebble 中，重启后仍生效）
+)
+
+// MessageTypeSwitch 启用的消息类型，可在运行时通过管理接口整体替换
+type MessageTypeSwitch struct {
+	types     []string
+	source    string
+	updatedAt int64
+	mu        sync.RWMutex
+}
+
+// NewMessageTypeSwitch 创建使用配置文件中消息类型的开关
+func NewMessageTypeSwitch(configTypes []string) *MessageTypeSwitch {
+	return &MessageTypeSwitch{
+		types:  configTypes,
+		source: EnabledTypesSourceConfig,
+	}
+}
+
+// Enabled 消息类型是否启用
+func (s *MessageTypeSwitch) Enabled(msgType string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return slices.Contains(s.types, msgType)
+}
+
+// Types 返回当前启用的消息类型、来源和最后更新时间（来源为配置文件时为 0）
+func (s *MessageTypeSwitch) Types() ([]string, string, int64) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return slices.Clone(s.types), s.source, s.updatedAt
+}
+
+// SetTypes 整体替换启用的消息类型（需先通过 ValidateEnabledTypes 校验）
+func (s *MessageTypeSwitch) SetTypes(types []string, updatedAt int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.types = types
+	s.source = EnabledTypesSourceAPI
+	s.updatedAt = updatedAt
+}
+
+// ValidateEnabledTypes 校验通过管理接口设置的消息类型：至少启用一种、不能重复且必须受支持
+func ValidateEnabledTypes(types []string) error {
+	if len(types) == 0 {
+		return fmt.Errorf("至少需要启用一种消息类型")
+	}
+	for i, msgType := range types {
+		if slices.Contains(types[:i], msgType) {
+			return fmt.Errorf("消息类型重复: %s", msgType)
+		}
+	}
+	return ValidateMessageTypes(types)
+}
+
+// 全局消息类型开关（供管理接口使用）
+var (
+	globalMessageTypes   *MessageTypeSwitch
+	globalMessageTypesMu sync.RWMutex
+)
+
+// SetGlobalMessageTypes 设置全局消息类型开关
+func SetGlobalMessageTypes(messageTypes *MessageTypeSwitch) {
+	globalMessageTypesMu.Lock()
+	defer globalMessageTypesMu.Unlock()
+
+	globalMessageTypes = messageTypes
+}
+
+// GetGlobalMessageTypes 获取全局消息类型开关，推送中心未启用时返回 nil
+func GetGlobalMessageTypes() *MessageTypeSwitch {
+	globalMessageTypesMu.RLock()
+	defer globalMessageTypesMu.RUnlock()
+
+	return globalMessageTypes
+}
+
+// UpdateEnabledTypes 校验并保存启用的消息类型，立即生效并覆盖配置文件中的设置
+func UpdateEnabledTypes(types []string) error {
+	messageTypes := GetGlobalMessageTypes()
+	if messageTypes == nil {
+		return fmt.Errorf("推送中心未启用")
+	}
+	if err := ValidateEnabledTypes(types); err != nil {
+		return err
+	}
+
+	setting, err := pebble_service.SaveEnabledTypes(types)
+	if err != nil {
+		return err
+	}
+	messageTypes.SetTypes(setting.Types, setting.UpdatedAt)
+	log.Printf("🔀 启用的消息类型已更新: %v", setting.Types)
+	return nil
+}
+
+// loadEnabledTypes 启动时加载通过管理接口保存的启用消息类型
+func (pc *PushCenter) loadEnabledTypes() error {
+	saved, err := pebble_service.GetEnabledTypes()
+	if err != nil {
+		return fmt.Errorf("加载启用的消息类型失败: %w", err)
+	}
+	if saved != nil {
+		// 保存后版本升级可能移除了某些消息类型，此时忽略保存的设置
+		if err := ValidateEnabledTypes(saved.Types); err != nil {
+			log.Printf("⚠️ 忽略保存的启用消息类型: %v，使用配置文件中的设置", err)
+		} else {
+			pc.messageTypes.SetTypes(saved.Types, saved.UpdatedAt)
+		}
+	}
+
+	types, source, _ := pc.messageTypes.Types()
+	log.Printf("🔀 启用的消息类型: %v, 来源=%s", types, source)
+	SetGlobalMessageTypes(pc.messageTypes)
+	return nil
+}
@@ -0,0 +1,41 @@
+package pushcenter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEnabledTypesUpdatedAndPersisted(t *testing.T) {
+	newTestStores(t)
+	t.Cleanup(func() { SetGlobalMessageTypes(nil) })
+
+	pc := NewPushCenter(&Config{})
+	if err := pc.loadEnabledTypes(); err != nil {
+		t.Fatalf("loadEnabledTypes() failed, err: %v", err)
+	}
+	if !pc.isMessageTypeEnabled("group_chat") || pc.isMessageTypeEnabled("payment") {
+		t.Fatal("未设置时应使用配置文件中的默认消息类型")
+	}
+
+	for _, invalid := range [][]string{nil, {"group_chat", "group_chat"}, {"unknown"}} {
+		if err := UpdateEnabledTypes(invalid); err == nil {
+			t.Errorf("UpdateEnabledTypes(%v) 应返回错误", invalid)
+		}
+	}
+	if err := UpdateEnabledTypes([]string{"private_chat", "payment"}); err != nil {
+		t.Fatalf("UpdateEnabledTypes() failed, err: %v", err)
+	}
+	if pc.isMessageTypeEnabled("group_chat") || !pc.isMessageTypeEnabled("payment") {
+		t.Error("更新后应立即生效")
+	}
+
+	// 重启后使用保存的设置
+	restarted := NewPushCenter(&Config{EnabledTypes: []string{"group_chat"}})
+	if err := restarted.loadEnabledTypes(); err != nil {
+		t.Fatalf("loadEnabledTypes() failed, err: %v", err)
+	}
+	types, source, updatedAt := restarted.messageTypes.Types()
+	if !reflect.DeepEqual(types, []string{"private_chat", "payment"}) || source != EnabledTypesSourceAPI || updatedAt == 0 {
+		t.Errorf("Types() = %v, %s, %d", types, source, updatedAt)
+	}
+}
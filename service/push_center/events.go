@@ -0,0 +1,105 @@
+package pushcenter
+
+import (
+	"push-base-service/models"
+	"push-base-service/service/event_service"
+	"push-base-service/service/push_service"
+	"push-base-service/service/socket_client_service"
+)
+
+// publishMessageReceived 发布收到消息事件
+func publishMessageReceived(chatMsg *socket_client_service.ChatNotificationMessage, parsedInfo *ParsedMessageInfo, audience *Audience) {
+	if !event_service.Active() {
+		return
+	}
+
+	event_service.Publish(&models.PipelineEvent{
+		Type:        models.PipelineEventMessageReceived,
+		PinID:       parsedInfo.PinId,
+		ChatType:    parsedInfo.ChatType,
+		MessageType: chatMsg.Type,
+		Count:       len(audience.Users()),
+	})
+}
+
+// publishDuplicate 发布消息被去重跳过的事件
+func publishDuplicate(parsedInfo *ParsedMessageInfo) {
+	if !event_service.Active() {
+		return
+	}
+
+	event_service.Publish(&models.PipelineEvent{
+		Type:     models.PipelineEventFiltered,
+		PinID:    parsedInfo.PinId,
+		ChatType: parsedInfo.ChatType,
+		Reason:   models.PipelineFilterDuplicate,
+	})
+}
+
+// publishFiltered 发布用户被跳过的事件：屏蔽了该聊天的用户（candidates 中不在 kept 里的）以及 skipped 中按偏好跳过的用户
+func publishFiltered(parsedInfo *ParsedMessageInfo, candidates, kept []string, skipped map[string]string) {
+	if !event_service.Active() {
+		return
+	}
+
+	keptSet := make(map[string]bool, len(kept))
+	for _, metaId := range kept {
+		keptSet[metaId] = true
+	}
+	var events []*models.PipelineEvent
+	for _, metaId := range candidates {
+		if !keptSet[metaId] {
+			events = append(events, &models.PipelineEvent{Type: models.PipelineEventFiltered, PinID: parsedInfo.PinId, MetaID: metaId, ChatType: parsedInfo.ChatType, Reason: models.DeliverySkipBlocked})
+		}
+	}
+	for metaId, reason := range skipped {
+		events = append(events, &models.PipelineEvent{Type: models.PipelineEventFiltered, PinID: parsedInfo.PinId, MetaID: metaId, ChatType: parsedInfo.ChatType, Reason: reason})
+	}
+	event_service.Publish(events...)
+}
+
+// publishPushResult 推送结果监听器，发布推送已受理或失败的事件（包括发送接口和定时推送的结果）
+// 没有订阅者时同样发布，事件总线保留最近的推送和失败供管理看板展示
+func publishPushResult(notification *push_service.PushNotification, result *push_service.PushResult) {
+	if result == nil {
+		return
+	}
+
+	event := &models.PipelineEvent{
+		Type:      models.PipelineEventSent,
+		MetaID:    result.MetaID,
+		Platform:  result.Platform,
+		Simulated: result.Simulated,
+	}
+	if !result.Timestamp.IsZero() {
+		event.Timestamp = result.Timestamp.UnixMilli()
+	}
+	if !result.Success {
+		event.Type = models.PipelineEventFailed
+	}
+	if result.Error != nil {
+		event.Error = result.Error.Error()
+	}
+	if notification != nil && notification.Data != nil {
+		if pinId, ok := notification.Data["pinId"].(string); ok {
+			event.PinID = pinId
+		}
+	}
+	event_service.Publish(event)
+}
+
+// publishReceipt 发布回执更新事件
+func publishReceipt(receipt *models.PendingReceipt, status, errMsg string) {
+	if !event_service.Active() {
+		return
+	}
+
+	event_service.Publish(&models.PipelineEvent{
+		Type:     models.PipelineEventReceiptUpdated,
+		PinID:    receipt.PinID,
+		MetaID:   receipt.MetaID,
+		Platform: receipt.Platform,
+		Reason:   status,
+		Error:    errMsg,
+	})
+}
@@ -0,0 +1,68 @@
+package pushcenter
+
+import (
+	"push-base-service/models"
+	"push-base-service/service/event_service"
+	"push-base-service/service/pebble_service"
+	"push-base-service/service/push_service"
+	"push-base-service/service/socket_client_service"
+	"testing"
+)
+
+func TestPipelinePublishesEvents(t *testing.T) {
+	ps := newTestStores(t)
+	ps.AddBlockedChat("carol", "group1", "group", "", 0)
+	if _, err := pebble_service.Unsubscribe("dave", "", "user"); err != nil {
+		t.Fatalf("Unsubscribe() failed, err: %v", err)
+	}
+
+	sub := event_service.Subscribe(100)
+	defer event_service.Unsubscribe(sub)
+
+	source := &stubSource{}
+	dispatcher := &notificationDispatcher{notifications: make(map[string]*push_service.PushNotification)}
+	pc := NewPushCenter(&Config{})
+	pc.sources = []MessageSource{source}
+	pc.SetAudienceResolver(listResolver{"group1": {"bob", "carol", "dave"}})
+	pc.SetDispatcher(dispatcher)
+	pc.SetChatMessageHandler()
+	pc.consuming = true
+
+	message := &socket_client_service.ChatNotificationMessage{
+		Type: "group_chat",
+		Data: &socket_client_service.ExtraServiceMessage{Message: map[string]interface{}{"pinId": "pin-events", "groupId": "group1"}},
+	}
+	source.handler(message)
+	source.handler(message)
+	pc.inflight.Wait()
+
+	var received, duplicates int
+	filtered := make(map[string]string)
+	for len(sub.Events()) > 0 {
+		event := <-sub.Events()
+		switch {
+		case event.Type == models.PipelineEventMessageReceived:
+			received++
+			if event.PinID != "pin-events" || event.Count != 3 {
+				t.Errorf("message_received = %+v, want pin-events with 3 recipients", event)
+			}
+		case event.Type == models.PipelineEventFiltered && event.Reason == models.PipelineFilterDuplicate:
+			duplicates++
+		case event.Type == models.PipelineEventFiltered:
+			filtered[event.MetaID] = event.Reason
+		}
+	}
+
+	if received != 2 || duplicates != 1 {
+		t.Errorf("received=%d, duplicates=%d, want 2 and 1", received, duplicates)
+	}
+	if filtered["carol"] != models.DeliverySkipBlocked || filtered["dave"] != models.DeliverySkipUnsubscribed || len(filtered) != 2 {
+		t.Errorf("filtered = %v, want carol blocked and dave unsubscribed", filtered)
+	}
+
+	publishPushResult(&push_service.PushNotification{Data: map[string]interface{}{"pinId": "pin-events"}},
+		&push_service.PushResult{MetaID: "bob", Platform: "expo", Success: false})
+	if event := <-sub.Events(); event.Type != models.PipelineEventFailed || event.MetaID != "bob" || event.PinID != "pin-events" {
+		t.Errorf("push result event = %+v, want bob's failure for pin-events", event)
+	}
+}
@@ -0,0 +1,201 @@
+package pushcenter
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"push-base-service/service/push_service"
+	"sort"
+	"sync"
+	"time"
+)
+
+// StageSummary 摘要通知环节名称，启用摘要通知时插入在 Send 之前
+const StageSummary = "summary"
+
+// 摘要通知默认配置
+const (
+	DefaultSummaryMinChats = 3
+	DefaultSummaryWindow   = 10 * time.Minute
+
+	// summaryThreadKey 摘要通知的折叠ID和分组ID，新的摘要替换设备上旧的摘要
+	summaryThreadKey = "summary"
+)
+
+// GroupingConfig 通知分组配置
+// 开启后聊天通知按聊天填充分组元数据：iOS thread-id 和 summary-arg（通知中心按会话归组并显示"还有 N 条来自 X 的通知"），
+// data.threadId 和 data.groupKey（Android 客户端据此设置通知分组），不依赖 collapse_mode
+type GroupingConfig struct {
+	Enabled bool           `yaml:"enabled" json:"enabled"` // 是否填充分组元数据
+	Summary *SummaryConfig `yaml:"summary" json:"summary"` // 摘要通知配置
+}
+
+// SummaryConfig 摘要通知配置
+// 用户在窗口内收到来自 MinChats 个及以上不同聊天的普通通知后，后续普通通知改为一条"N messages from M chats"摘要，
+// 摘要之间相互替换，窗口结束后重新计数；提及通知始终单独发送
+type SummaryConfig struct {
+	Enabled  bool          `yaml:"enabled" json:"enabled"`     // 是否启用摘要通知
+	MinChats int           `yaml:"min_chats" json:"min_chats"` // 改发摘要的最少聊天数
+	Window   time.Duration `yaml:"window" json:"window"`       // 计数窗口，从窗口内第一条通知开始计算
+}
+
+// groupingEnabled 是否填充分组元数据
+func (pc *PushCenter) groupingEnabled() bool {
+	return pc.config.GroupingConfig != nil && pc.config.GroupingConfig.Enabled
+}
+
+// summaryEnabled 是否启用摘要通知
+func (pc *PushCenter) summaryEnabled() bool {
+	grouping := pc.config.GroupingConfig
+	return grouping != nil && grouping.Summary != nil && grouping.Summary.Enabled
+}
+
+// applyGrouping 为聊天通知设置分组ID和摘要参数，已由合并方式设置的分组ID保持不变
+func (pc *PushCenter) applyGrouping(notification *push_service.PushNotification, parsedInfo *ParsedMessageInfo) {
+	if !pc.groupingEnabled() {
+		return
+	}
+	key := chatCollapseKey(parsedInfo)
+	if key == "" {
+		return
+	}
+
+	if notification.ThreadID == "" {
+		notification.ThreadID = key
+	}
+	notification.SummaryArg = parsedInfo.UserName
+	if notification.Data == nil {
+		notification.Data = map[string]interface{}{}
+	}
+	notification.Data["threadId"] = notification.ThreadID
+	notification.Data["groupKey"] = key
+}
+
+// summaryTracker 记录每个用户在窗口内收到的普通通知数和来源聊天（进程内，重启后重新计数）
+type summaryTracker struct {
+	mu        sync.Mutex
+	window    time.Duration
+	users     map[string]*unreadThreads
+	lastSweep time.Time
+}
+
+// unreadThreads 用户在当前窗口内收到的通知
+type unreadThreads struct {
+	since    time.Time
+	messages int
+	chats    map[string]struct{}
+}
+
+func newSummaryTracker(window time.Duration) *summaryTracker {
+	return &summaryTracker{window: window, users: make(map[string]*unreadThreads)}
+}
+
+// record 记录用户收到一条来自 chatKey 的通知，返回窗口内的通知数和聊天数
+func (t *summaryTracker) record(metaId, chatKey string, now time.Time) (messages, chats int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if now.Sub(t.lastSweep) > t.window {
+		for id, threads := range t.users {
+			if now.Sub(threads.since) > t.window {
+				delete(t.users, id)
+			}
+		}
+		t.lastSweep = now
+	}
+
+	threads, ok := t.users[metaId]
+	if !ok || now.Sub(threads.since) > t.window {
+		threads = &unreadThreads{since: now, chats: make(map[string]struct{})}
+		t.users[metaId] = threads
+	}
+	threads.messages++
+	threads.chats[chatKey] = struct{}{}
+	return threads.messages, len(threads.chats)
+}
+
+// summaryCount 摘要通知的计数
+type summaryCount struct {
+	messages int
+	chats    int
+}
+
+// summaryStage 普通通知的接收用户中，窗口内来源聊天数达到阈值的用户改收摘要通知；演练和回放的消息不计数
+func (pc *PushCenter) summaryStage(ctx context.Context, msg *PipelineMessage, next PipelineHandler) error {
+	key := chatCollapseKey(msg.Info)
+	if msg.DryRun || msg.Replay || key == "" {
+		return next(ctx, msg)
+	}
+
+	minChats := pc.config.GroupingConfig.Summary.MinChats
+	now := time.Now()
+	var summaryGroups []*NotificationGroup
+	for _, group := range msg.Groups {
+		if group.Mention || group.Notification == nil {
+			continue
+		}
+
+		summarized := make(map[summaryCount][]string)
+		var individual []string
+		for _, metaId := range group.Users {
+			messages, chats := pc.summaries.record(metaId, key, now)
+			if chats >= minChats {
+				count := summaryCount{messages: messages, chats: chats}
+				summarized[count] = append(summarized[count], metaId)
+			} else {
+				individual = append(individual, metaId)
+			}
+		}
+		if len(summarized) == 0 {
+			continue
+		}
+
+		group.Users = individual
+		counts := make([]summaryCount, 0, len(summarized))
+		for count := range summarized {
+			counts = append(counts, count)
+		}
+		sort.Slice(counts, func(i, j int) bool {
+			if counts[i].messages != counts[j].messages {
+				return counts[i].messages < counts[j].messages
+			}
+			return counts[i].chats < counts[j].chats
+		})
+		for _, count := range counts {
+			summaryGroups = append(summaryGroups, newSummaryGroup(group.Notification, summarized[count], count))
+		}
+	}
+	if len(summaryGroups) > 0 {
+		log.Printf("🗂️ %d 组用户改收摘要通知", len(summaryGroups))
+		msg.Groups = append(msg.Groups, summaryGroups...)
+	}
+	return next(ctx, msg)
+}
+
+// newSummaryGroup 基于普通通知（保留优先级、渠道、声音等路由结果）生成摘要通知的用户分组
+func newSummaryGroup(base *push_service.PushNotification, users []string, count summaryCount) *NotificationGroup {
+	body := fmt.Sprintf("%d messages from %d chats", count.messages, count.chats)
+	data := map[string]interface{}{
+		"type":      summaryThreadKey,
+		"messages":  count.messages,
+		"chats":     count.chats,
+		"timestamp": time.Now().Unix(),
+	}
+
+	notification := *base
+	notification.Title = "New Messages"
+	notification.Body = body
+	notification.Data = data
+	notification.CollapseID = summaryThreadKey
+	notification.ThreadID = summaryThreadKey
+	notification.SummaryArg = ""
+	return &NotificationGroup{
+		Summary:      true,
+		Users:        users,
+		Title:        notification.Title,
+		Body:         body,
+		HiddenBody:   body,
+		Data:         data,
+		Notification: &notification,
+	}
+}
@@ -0,0 +1,103 @@
+package pushcenter
+
+import (
+	"context"
+	"push-base-service/service/push_service"
+	"push-base-service/service/socket_client_service"
+	"sync"
+	"testing"
+	"time"
+)
+
+// notificationDispatcher 记录每个用户最近收到的通知
+type notificationDispatcher struct {
+	mu            sync.Mutex
+	notifications map[string]*push_service.PushNotification
+}
+
+func (d *notificationDispatcher) SendCustomNotificationToUsers(ctx context.Context, metaIds []string, notification *push_service.PushNotification) (*push_service.BatchPushResult, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	result := &push_service.BatchPushResult{TotalUsers: len(metaIds), SuccessCount: len(metaIds)}
+	for _, metaId := range metaIds {
+		d.notifications[metaId] = notification
+		result.Results = append(result.Results, &push_service.PushResult{MetaID: metaId, Platform: "expo", Success: true})
+	}
+	return result, nil
+}
+
+func (d *notificationDispatcher) take(metaId string) *push_service.PushNotification {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	notification := d.notifications[metaId]
+	delete(d.notifications, metaId)
+	return notification
+}
+
+func TestApplyGrouping(t *testing.T) {
+	pc := &PushCenter{config: &Config{GroupingConfig: &GroupingConfig{Enabled: true}}}
+	notification := &push_service.PushNotification{Data: map[string]interface{}{}}
+	pc.applyGrouping(notification, &ParsedMessageInfo{ChatType: "group_chat", GroupId: "group1", UserName: "alice"})
+
+	if notification.ThreadID != "group:group1" || notification.SummaryArg != "alice" {
+		t.Errorf("ThreadID = %q, SummaryArg = %q", notification.ThreadID, notification.SummaryArg)
+	}
+	if notification.Data["threadId"] != "group:group1" || notification.Data["groupKey"] != "group:group1" {
+		t.Errorf("Data = %v", notification.Data)
+	}
+
+	// 未开启时不填充
+	pc.config.GroupingConfig.Enabled = false
+	notification = &push_service.PushNotification{}
+	pc.applyGrouping(notification, &ParsedMessageInfo{ChatType: "group_chat", GroupId: "group1"})
+	if notification.ThreadID != "" || notification.Data != nil {
+		t.Errorf("grouping disabled: notification = %+v", notification)
+	}
+}
+
+func TestSummaryNotification(t *testing.T) {
+	newTestStores(t)
+
+	source := &stubSource{}
+	dispatcher := &notificationDispatcher{notifications: make(map[string]*push_service.PushNotification)}
+	pc := NewPushCenter(&Config{GroupingConfig: &GroupingConfig{Summary: &SummaryConfig{Enabled: true, MinChats: 3, Window: time.Minute}}})
+	pc.sources = []MessageSource{source}
+	pc.SetAudienceResolver(listResolver{"group1": {"bob", "carol"}, "group2": {"bob"}, "group3": {"bob", "carol"}})
+	pc.SetDispatcher(dispatcher)
+	pc.SetChatMessageHandler()
+	pc.consuming = true
+
+	send := func(pinId, groupId string) {
+		source.handler(&socket_client_service.ChatNotificationMessage{
+			Type: "group_chat",
+			Data: &socket_client_service.ExtraServiceMessage{Message: map[string]interface{}{"pinId": pinId, "groupId": groupId}},
+		})
+		pc.inflight.Wait()
+	}
+
+	send("pin-1", "group1")
+	send("pin-2", "group2")
+	if notification := dispatcher.take("bob"); notification == nil || notification.Title != "New Message in Group" {
+		t.Fatalf("bob's second notification = %+v, want an individual notification", notification)
+	}
+
+	// 第三个聊天起改发摘要，未达到阈值的用户照常收到单条通知
+	send("pin-3", "group3")
+	summary := dispatcher.take("bob")
+	if summary == nil || summary.Body != "3 messages from 3 chats" || summary.CollapseID != summaryThreadKey || summary.Data["type"] != summaryThreadKey {
+		t.Fatalf("bob's summary = %+v", summary)
+	}
+	if notification := dispatcher.take("carol"); notification == nil || notification.Title != "New Message in Group" {
+		t.Errorf("carol's notification = %+v, want an individual notification", notification)
+	}
+
+	// 提及通知始终单独发送
+	mention := &NotificationGroup{Mention: true, Users: []string{"bob"}, Notification: &push_service.PushNotification{Title: "You were mentioned"}}
+	msg := &PipelineMessage{Info: &ParsedMessageInfo{ChatType: "group_chat", GroupId: "group4"}, Groups: []*NotificationGroup{mention}}
+	pc.summaryStage(context.Background(), msg, func(ctx context.Context, msg *PipelineMessage) error { return nil })
+	if len(msg.Groups) != 1 || len(mention.Users) != 1 {
+		t.Errorf("mention groups = %+v, want the mention unchanged", msg.Groups)
+	}
+}
@@ -0,0 +1,106 @@
+package pushcenter
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"push-base-service/models"
+	"push-base-service/service/handoff_service"
+	"push-base-service/service/pebble_service"
+)
+
+// handoffStateEnabled 是否在交出/接管时通过共享协调目录交接本地状态（仅部署交接模式，新旧实例使用各自的数据目录）
+func (pc *PushCenter) handoffStateEnabled() bool {
+	handoff := pc.config.HandoffConfig
+	return handoff != nil && handoff.Enabled && handoff.Mode == handoff_service.ModeHandoff
+}
+
+// handoffStatePattern 协调目录中各实例交出状态文件的匹配模式
+func (pc *PushCenter) handoffStatePattern() string {
+	return filepath.Join(pc.config.HandoffConfig.Dir, pc.config.HandoffConfig.Name+".state.*.json")
+}
+
+// saveHandoffState 交出前将尚未发送的定时推送、未确认的进件日志和未过期的幂等键写入协调目录，
+// 写入成功后删除本地已交出的任务，调用方需已停止消费和调度器
+func (pc *PushCenter) saveHandoffState() {
+	if !pc.handoffStateEnabled() {
+		return
+	}
+
+	handoff := pc.config.HandoffConfig
+	state, err := pebble_service.ExportHandoffState(handoff.InstanceID)
+	if err != nil {
+		log.Printf("❌ 导出交接状态失败，定时推送和进件日志保留在本地: %v", err)
+		return
+	}
+	if err := writeHandoffState(handoff.Dir, filepath.Join(handoff.Dir, handoff.Name+".state."+handoff.InstanceID+".json"), state); err != nil {
+		log.Printf("❌ 写入交接状态失败，定时推送和进件日志保留在本地: %v", err)
+		return
+	}
+	if err := pebble_service.ClearHandoffState(state); err != nil {
+		log.Printf("⚠️ 删除本地已交出的状态失败: %v", err)
+	}
+
+	log.Printf("📦 已交出本地状态: 定时推送=%d, 进件日志=%d, 幂等键=%d",
+		len(state.ScheduledPushes), len(state.Intake), len(state.IdempotencyRecords))
+}
+
+// writeHandoffState 原子写入交接状态文件
+func writeHandoffState(dir, path string, state *models.HandoffState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
+// loadHandoffState 接管后、开始消费前导入协调目录中（旧实例或本实例上次）交出的状态，导入成功后删除状态文件；
+// 导入失败的文件保留，下次接管时重试
+func (pc *PushCenter) loadHandoffState() {
+	if !pc.handoffStateEnabled() {
+		return
+	}
+
+	paths, err := filepath.Glob(pc.handoffStatePattern())
+	if err != nil {
+		log.Printf("⚠️ 查找交接状态文件失败: %v", err)
+		return
+	}
+	for _, path := range paths {
+		state, err := readHandoffState(path)
+		if err == nil {
+			err = pebble_service.ImportHandoffState(state)
+		}
+		if err != nil {
+			log.Printf("❌ 导入交接状态失败: %s, %v", path, err)
+			continue
+		}
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			log.Printf("⚠️ 删除交接状态文件失败: %v", err)
+		}
+		log.Printf("📦 已接收实例 %s 交出的状态: 定时推送=%d, 进件日志=%d, 幂等键=%d",
+			state.InstanceID, len(state.ScheduledPushes), len(state.Intake), len(state.IdempotencyRecords))
+	}
+}
+
+// readHandoffState 读取交接状态文件
+func readHandoffState(path string) (*models.HandoffState, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var state models.HandoffState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("解析交接状态失败: %w", err)
+	}
+	return &state, nil
+}
@@ -0,0 +1,221 @@
+//go:build !noredis
+
+package pushcenter
+
+import (
+	"errors"
+	"path/filepath"
+	"push-base-service/models"
+	"push-base-service/service/handoff_service"
+	"push-base-service/service/lock_service"
+	"push-base-service/service/pebble_service"
+	"push-base-service/service/socket_client_service"
+	"push-base-service/service/storage_service"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+// newCoordinatedPushCenter 按部署交接/主备选举配置初始化并启动推送中心，返回推送中心及其数据目录
+func newCoordinatedPushCenter(t *testing.T, handoff *handoff_service.Config, storage *storage_service.Config) (*PushCenter, string) {
+	t.Helper()
+	socketServer := newFakeSocketServer(t)
+	dbPath := t.TempDir()
+
+	pc := NewPushCenter(&Config{
+		SocketConfig:  &socket_client_service.Config{ServerURL: socketServer.URL, ExtraPushAuthKey: integrationAuthKey},
+		PebbleConfig:  &pebble_service.Config{DBPath: dbPath, ProcessLock: true},
+		StorageConfig: storage,
+		HandoffConfig: handoff,
+	})
+	t.Cleanup(func() { pebble_service.CloseGlobalService() })
+	if err := pc.Initialize(); err != nil {
+		t.Fatalf("Initialize() failed, err: %v", err)
+	}
+	if err := pc.Run(); err != nil {
+		t.Fatalf("Run() failed, err: %v", err)
+	}
+	t.Cleanup(func() { pc.Stop() })
+	return pc, dbPath
+}
+
+// redisStorageConfig 使用 Redis 共享存储的配置
+func redisStorageConfig(server *miniredis.Miniredis) *storage_service.Config {
+	config := storage_service.DefaultConfig()
+	config.Backend = storage_service.BackendRedis
+	config.Redis.Addr = server.Addr()
+	return config
+}
+
+// isConsuming 推送中心是否正在消费消息
+func (pc *PushCenter) isConsuming() bool {
+	pc.consumeMu.Lock()
+	defer pc.consumeMu.Unlock()
+	return pc.consuming
+}
+
+// waitForCondition 等待条件成立，超时则测试失败
+func waitForCondition(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// assertDatabaseOwned 交出主实例后本地数据库仍由本进程打开并持有数据目录的进程锁
+func assertDatabaseOwned(t *testing.T, ps *pebble_service.PebbleService, dbPath string) {
+	t.Helper()
+	if pebble_service.GetGlobalService() != ps || !ps.IsInitialized() {
+		t.Fatalf("Pebble service should stay open after stepping down")
+	}
+	if lock, err := lock_service.AcquireProcessLock(dbPath); !errors.Is(err, lock_service.ErrProcessLocked) {
+		if lock != nil {
+			lock.Release()
+		}
+		t.Fatalf("AcquireProcessLock() after stepping down = %v, want ErrProcessLocked", err)
+	}
+	if err := ps.AddBlockedChat("alice", "group1", "group", "", 0); err != nil {
+		t.Errorf("AddBlockedChat() after stepping down failed, err: %v", err)
+	}
+}
+
+func TestHandoffRequiresSharedStorage(t *testing.T) {
+	pc := NewPushCenter(&Config{
+		PebbleConfig:  &pebble_service.Config{DBPath: t.TempDir(), ProcessLock: true},
+		HandoffConfig: &handoff_service.Config{Enabled: true, Mode: handoff_service.ModeHandoff, Dir: t.TempDir()},
+	})
+	t.Cleanup(func() { pebble_service.CloseGlobalService() })
+	if err := pc.Initialize(); err == nil {
+		t.Fatalf("handoff mode with the pebble storage backend should be rejected")
+	}
+}
+
+func TestHandoffKeepsDatabaseOpen(t *testing.T) {
+	server := miniredis.RunT(t)
+	dir := t.TempDir()
+	handoffConfig := func(instanceID string) *handoff_service.Config {
+		return &handoff_service.Config{
+			Enabled:      true,
+			Mode:         handoff_service.ModeHandoff,
+			Dir:          dir,
+			InstanceID:   instanceID,
+			LeaseTTL:     500 * time.Millisecond,
+			PollInterval: 20 * time.Millisecond,
+		}
+	}
+
+	pc, dbPath := newCoordinatedPushCenter(t, handoffConfig("old"), redisStorageConfig(server))
+	waitForCondition(t, "old instance to lead", pc.coordinator.IsLeader)
+	ps := pebble_service.GetGlobalService()
+
+	// 新实例（使用自己的数据目录）就绪后请求接管
+	newInstance, err := handoff_service.NewBackendCoordinator(handoffConfig("new"), handoff_service.Callbacks{
+		Ready: func() bool { return true },
+	})
+	if err != nil {
+		t.Fatalf("NewBackendCoordinator() failed, err: %v", err)
+	}
+	newInstance.Start()
+	defer newInstance.Stop()
+	waitForCondition(t, "new instance to take over", newInstance.IsLeader)
+
+	if pc.coordinator.IsLeader() || pc.isConsuming() {
+		t.Fatalf("old instance should stop consuming after handing off")
+	}
+	assertDatabaseOwned(t, ps, dbPath)
+}
+
+func TestHandoffPassesLocalState(t *testing.T) {
+	server := miniredis.RunT(t)
+	dir := t.TempDir()
+	handoffConfig := func(instanceID string) *handoff_service.Config {
+		return &handoff_service.Config{
+			Enabled:      true,
+			Mode:         handoff_service.ModeHandoff,
+			Dir:          dir,
+			InstanceID:   instanceID,
+			LeaseTTL:     500 * time.Millisecond,
+			PollInterval: 20 * time.Millisecond,
+		}
+	}
+
+	pc, _ := newCoordinatedPushCenter(t, handoffConfig("old"), redisStorageConfig(server))
+	waitForCondition(t, "old instance to lead", pc.coordinator.IsLeader)
+	ps := pebble_service.GetGlobalService()
+	job := &models.ScheduledPush{ID: "job1", MetaIDs: []string{"alice"}, SendAt: time.Now().Add(time.Hour).Unix(), Status: models.ScheduleStatusPending}
+	if err := ps.SaveScheduledPush(job); err != nil {
+		t.Fatalf("SaveScheduledPush() failed, err: %v", err)
+	}
+
+	newInstance, err := handoff_service.NewBackendCoordinator(handoffConfig("new"), handoff_service.Callbacks{
+		Ready: func() bool { return true },
+	})
+	if err != nil {
+		t.Fatalf("NewBackendCoordinator() failed, err: %v", err)
+	}
+	newInstance.Start()
+	defer newInstance.Stop()
+	waitForCondition(t, "new instance to take over", newInstance.IsLeader)
+
+	// 旧实例交出后本地不再保留待发送任务，任务写入协调目录中的状态文件
+	if saved, _ := ps.GetScheduledPush("job1"); saved != nil {
+		t.Errorf("handed over job should be removed from the old instance, got %+v", saved)
+	}
+	state, err := readHandoffState(filepath.Join(dir, "push-center.state.old.json"))
+	if err != nil {
+		t.Fatalf("readHandoffState() failed, err: %v", err)
+	}
+	if len(state.ScheduledPushes) != 1 || state.ScheduledPushes[0].ID != "job1" {
+		t.Fatalf("handoff state scheduled pushes = %+v, want job1", state.ScheduledPushes)
+	}
+
+	// 接管实例导入状态后删除状态文件
+	pc.loadHandoffState()
+	if saved, _ := ps.GetScheduledPush("job1"); saved == nil || saved.Status != models.ScheduleStatusPending {
+		t.Errorf("imported job = %+v, want pending job1", saved)
+	}
+	if paths, _ := filepath.Glob(pc.handoffStatePattern()); len(paths) != 0 {
+		t.Errorf("handoff state files should be removed after import, got %v", paths)
+	}
+}
+
+func TestElectionLeaseLostAndReacquired(t *testing.T) {
+	server := miniredis.RunT(t)
+	pc, dbPath := newCoordinatedPushCenter(t, &handoff_service.Config{
+		Enabled:      true,
+		Mode:         handoff_service.ModeElection,
+		Backend:      handoff_service.BackendRedis,
+		Redis:        lock_service.RedisConfig{Addr: server.Addr(), Key: "push:leader"},
+		InstanceID:   "leader",
+		LeaseTTL:     500 * time.Millisecond,
+		PollInterval: 20 * time.Millisecond,
+	}, nil)
+	waitForCondition(t, "instance to lead", pc.coordinator.IsLeader)
+	ps := pebble_service.GetGlobalService()
+
+	// 租约被其他实例抢占（如网络分区期间过期），主实例转为待命但保留本地数据库
+	server.Set("push:leader", "intruder")
+	waitForCondition(t, "leader to step down", func() bool { return !pc.coordinator.IsLeader() })
+	if pc.isConsuming() {
+		t.Fatalf("instance should stop consuming after losing its lease")
+	}
+	assertDatabaseOwned(t, ps, dbPath)
+
+	// 抢占方的租约消失后重新接管，继续使用同一个数据库
+	server.Del("push:leader")
+	waitForCondition(t, "instance to lead again", pc.coordinator.IsLeader)
+	if !pc.isConsuming() {
+		t.Fatalf("instance should consume after re-acquiring the lease")
+	}
+	if pebble_service.GetGlobalService() != ps {
+		t.Fatalf("Pebble service should not be reopened on re-acquire")
+	}
+	if blocked, err := ps.IsBlockedChat("alice", "group1"); err != nil || !blocked {
+		t.Errorf("IsBlockedChat() after re-acquire = %v, %v, want true", blocked, err)
+	}
+}
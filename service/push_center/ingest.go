@@ -0,0 +1,59 @@
+package pushcenter
+
+import (
+	"fmt"
+	"log"
+	"push-base-service/service/metrics_service"
+	"push-base-service/service/socket_client_service"
+)
+
+// ingestMessagesCounter HTTP 进件接口收到的消息数
+var ingestMessagesCounter = metrics_service.NewCounterVec(
+	"push_ingest_messages_total", "Number of chat messages received on the HTTP ingestion endpoint by result", "result")
+
+// IngestResult HTTP 进件一条消息的结果
+type IngestResult struct {
+	Accepted bool   `json:"accepted"`         // 是否已交给推送中心处理（处理为异步，结果见投递记录）
+	ChatType string `json:"chatType"`         // 消息类型
+	Reason   string `json:"reason,omitempty"` // 未处理的原因
+}
+
+// IngestMessage 将 HTTP 进件接口收到的 SocketData 载荷交给全局推送中心处理，见 PushCenter.IngestMessage
+func IngestMessage(payload []byte) (*IngestResult, error) {
+	pc := GetGlobalPushCenter()
+	if pc == nil {
+		return nil, fmt.Errorf("推送中心未启用")
+	}
+
+	chatMsg, err := socket_client_service.DecodeChatNotification(payload)
+	if err != nil {
+		ingestMessagesCounter.Inc("invalid")
+		return nil, err
+	}
+	return pc.IngestMessage(chatMsg)
+}
+
+// IngestMessage 处理不经过 Socket.IO 投递的上游消息（供没有 Socket.IO 的上游使用，也可在 Socket 断开时作为备用通道）
+// 与 Socket 消息走相同的处理流程（去重、过滤、推送、记录），结构校验失败时直接返回错误，便于上游修正后重新投递；
+// 消息没有来源上游，不推进补拉检查点，也不发送推送确认
+func (pc *PushCenter) IngestMessage(chatMsg *socket_client_service.ChatNotificationMessage) (*IngestResult, error) {
+	if chatMsg == nil || chatMsg.Data == nil {
+		ingestMessagesCounter.Inc("invalid")
+		return nil, fmt.Errorf("消息内容为空")
+	}
+	chatMsg.Upstream = ""
+
+	if err := validateMessage(chatMsg); err != nil {
+		ingestMessagesCounter.Inc("invalid")
+		return nil, err
+	}
+	if !pc.isMessageTypeEnabled(chatMsg.Type) {
+		ingestMessagesCounter.Inc("disabled")
+		return &IngestResult{ChatType: chatMsg.Type, Reason: fmt.Sprintf("消息类型 %s 未启用", chatMsg.Type)}, nil
+	}
+
+	log.Printf("📥 HTTP 进件收到消息: Type=%s", chatMsg.Type)
+	ingestMessagesCounter.Inc("accepted")
+	pc.HandleMessage(chatMsg)
+	return &IngestResult{Accepted: true, ChatType: chatMsg.Type}, nil
+}
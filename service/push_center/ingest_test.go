@@ -0,0 +1,47 @@
+package pushcenter
+
+import "testing"
+
+func TestIngestMessage(t *testing.T) {
+	newTestStores(t)
+
+	source := &ackingSource{}
+	dispatcher := &dryRunDispatcher{}
+	pc := NewPushCenter(&Config{AckConfig: &AckConfig{Enabled: true}})
+	pc.sources = []MessageSource{source}
+	pc.SetAudienceResolver(listResolver{"group1": {"alice", "bob", "carol"}})
+	pc.SetDispatcher(dispatcher)
+	pc.consuming = true
+	SetGlobalPushCenter(pc)
+	t.Cleanup(func() { SetGlobalPushCenter(nil) })
+
+	result, err := IngestMessage([]byte(`{"M":"WS_SERVER_NOTIFY_GROUP_CHAT","D":{"message":{"pinId":"pin-ingest","groupId":"group1","metaId":"alice"}}}`))
+	if err != nil {
+		t.Fatalf("IngestMessage() failed, err: %v", err)
+	}
+	pc.inflight.Wait()
+	if !result.Accepted || result.ChatType != "group_chat" {
+		t.Errorf("result = %+v, want accepted group_chat", result)
+	}
+	if len(dispatcher.dryRun) != 1 {
+		t.Errorf("dispatched %d notifications, want 1", len(dispatcher.dryRun))
+	}
+	// HTTP 进件的消息没有来源上游，不发送推送确认
+	if len(source.acks) != 0 {
+		t.Errorf("acks sent to %v, want none", source.upstreams)
+	}
+
+	// 结构校验失败时返回错误，不进入流水线
+	if _, err := IngestMessage([]byte(`{"M":"WS_SERVER_NOTIFY_GROUP_CHAT","D":{"message":{"pinId":"pin-invalid"}}}`)); err == nil {
+		t.Error("IngestMessage() without groupId succeeded, want schema error")
+	}
+	if _, err := IngestMessage([]byte(`{"M":"UNKNOWN","D":{}}`)); err == nil {
+		t.Error("IngestMessage() with unknown method succeeded, want error")
+	}
+
+	// 未启用的消息类型不处理
+	result, err = IngestMessage([]byte(`{"M":"WS_SERVER_NOTIFY_FRIEND_REQUEST","D":{"message":{"pinId":"pin-friend"}}}`))
+	if err != nil || result.Accepted || result.Reason == "" {
+		t.Errorf("IngestMessage(friend request) = %+v, %v, want not accepted with reason", result, err)
+	}
+}
@@ -0,0 +1,169 @@
+package pushcenter
+
+import (
+	"encoding/json"
+	"log"
+	"push-base-service/service/metrics_service"
+	"push-base-service/service/pebble_service"
+	"push-base-service/service/socket_client_service"
+	"push-base-service/service/storage_service"
+)
+
+// DefaultIntakeMaxAttempts 进件日志条目默认最多恢复处理的次数
+const DefaultIntakeMaxAttempts = 3
+
+// intakeRecoveryCounter 重启后恢复处理的进件日志条目数
+var intakeRecoveryCounter = metrics_service.NewCounterVec(
+	"push_intake_recovered_total", "Number of journaled chat messages found on startup by outcome", "result")
+
+// IntakeConfig 进件日志配置
+// 收到的消息先写入 Pebble 再处理，推送完成后删除；服务重启（或接管）时重新处理未确认的消息
+type IntakeConfig struct {
+	Enabled     bool `yaml:"enabled" json:"enabled"`           // 是否启用进件日志
+	MaxAttempts int  `yaml:"max_attempts" json:"max_attempts"` // 每条消息最多恢复处理的次数，超出后丢弃
+}
+
+// queuedMessage 待处理的消息及其进件日志条目ID（未记录日志时为空）
+type queuedMessage struct {
+	chatMsg *socket_client_service.ChatNotificationMessage
+	entryID string
+}
+
+// intakeEnabled 是否启用进件日志
+func (pc *PushCenter) intakeEnabled() bool {
+	return pc.config.IntakeConfig != nil && pc.config.IntakeConfig.Enabled
+}
+
+// dispatchMessage 记录进件日志后异步处理消息，调用方需已为该消息执行 pc.addInflight(1)
+func (pc *PushCenter) dispatchMessage(chatMsg *socket_client_service.ChatNotificationMessage) {
+	pc.processQueued(&queuedMessage{chatMsg: chatMsg, entryID: pc.journalMessage(chatMsg)})
+}
+
+// processQueued 异步处理消息，处理完成后确认进件日志；启用聊天顺序推送时加入所属聊天的串行队列
+func (pc *PushCenter) processQueued(queued *queuedMessage) {
+	if cq := pc.chatQueues; cq != nil {
+		pc.enqueueOrdered(cq, queued)
+		return
+	}
+
+	go func() {
+		defer pc.doneInflight()
+		pc.ackMessage(queued.entryID, pc.processChatMessage(queued.chatMsg))
+	}()
+}
+
+// journalMessage 将消息写入进件日志，未启用或写入失败时返回空（消息照常处理，只是重启后无法恢复）
+func (pc *PushCenter) journalMessage(chatMsg *socket_client_service.ChatNotificationMessage) string {
+	if !pc.intakeEnabled() {
+		return ""
+	}
+
+	data, err := json.Marshal(chatMsg)
+	if err != nil {
+		log.Printf("⚠️ 序列化进件消息失败: %v", err)
+		return ""
+	}
+	entryID, err := pebble_service.AppendIntake(data)
+	if err != nil {
+		log.Printf("⚠️ 写入进件日志失败: %v", err)
+		return ""
+	}
+	return entryID
+}
+
+// ackMessage 消息处理完成后删除进件日志条目；处理因临时故障失败时保留，重启后恢复处理
+func (pc *PushCenter) ackMessage(entryID string, processErr error) {
+	if processErr != nil {
+		if entryID != "" {
+			log.Printf("❌ %v（进件日志已保留，重启后重新处理）", processErr)
+		} else {
+			log.Printf("❌ %v", processErr)
+		}
+		return
+	}
+	if entryID == "" {
+		return
+	}
+	if err := pebble_service.DeleteIntake(entryID); err != nil {
+		log.Printf("⚠️ 删除进件日志失败: %v", err)
+	}
+}
+
+// recoverIntake 读取上次运行未确认的进件日志，返回需要重新处理的消息
+// 上次可能已占用幂等键但未完成推送，PIN 尚未记录为已通知时释放幂等键，保证消息能被重新处理
+func (pc *PushCenter) recoverIntake() []*queuedMessage {
+	if !pc.intakeEnabled() {
+		return nil
+	}
+
+	entries, err := pebble_service.ListIntake()
+	if err != nil {
+		log.Printf("⚠️ 读取进件日志失败: %v", err)
+		return nil
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+
+	maxAttempts := pc.config.IntakeConfig.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultIntakeMaxAttempts
+	}
+
+	var recovered []*queuedMessage
+	for _, entry := range entries {
+		var chatMsg socket_client_service.ChatNotificationMessage
+		if err := json.Unmarshal(entry.Message, &chatMsg); err != nil || chatMsg.Data == nil {
+			log.Printf("⚠️ 丢弃无法解析的进件日志: %s", entry.ID)
+			pc.dropIntake(entry.ID)
+			continue
+		}
+
+		entry.Attempts++
+		if entry.Attempts > maxAttempts {
+			log.Printf("⚠️ 进件消息已恢复处理 %d 次仍未完成，丢弃: %s", maxAttempts, entry.ID)
+			pc.dropIntake(entry.ID)
+			continue
+		}
+		if err := pebble_service.UpdateIntake(entry); err != nil {
+			log.Printf("⚠️ 更新进件日志失败: %v", err)
+		}
+
+		pc.releaseUnfinishedClaim(&chatMsg)
+		recovered = append(recovered, &queuedMessage{chatMsg: &chatMsg, entryID: entry.ID})
+		intakeRecoveryCounter.Inc("recovered")
+	}
+
+	log.Printf("📥 从进件日志恢复 %d 条未完成的消息", len(recovered))
+	return recovered
+}
+
+// dropIntake 删除无法恢复的进件日志条目
+func (pc *PushCenter) dropIntake(entryID string) {
+	intakeRecoveryCounter.Inc("dropped")
+	if err := pebble_service.DeleteIntake(entryID); err != nil {
+		log.Printf("⚠️ 删除进件日志失败: %v", err)
+	}
+}
+
+// releaseUnfinishedClaim PIN 尚未记录为已通知时释放本实例对 PIN 的推送权，消息的幂等键尚未记录处理结果时一并释放
+func (pc *PushCenter) releaseUnfinishedClaim(chatMsg *socket_client_service.ChatNotificationMessage) {
+	parsedInfo, err := pc.parseMessageInfo(chatMsg)
+	if err != nil {
+		return
+	}
+	if parsedInfo.PinId != "" {
+		if notified, err := storage_service.IsNotifiedPin(parsedInfo.PinId); err != nil || notified {
+			return
+		}
+		pc.releasePinClaim(parsedInfo.PinId)
+	}
+
+	idempotencyKey, err := pc.buildIdempotencyKey(chatMsg, parsedInfo)
+	if err != nil {
+		return
+	}
+	if _, err := pebble_service.ReleasePendingIdempotencyKey(idempotencyKey); err != nil {
+		log.Printf("⚠️ 释放幂等键失败: %v", err)
+	}
+}
@@ -0,0 +1,198 @@
+package pushcenter
+
+import (
+	"context"
+	"errors"
+	"push-base-service/service/pebble_service"
+	"push-base-service/service/socket_client_service"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+)
+
+// failingResolver 模拟存储故障的接收用户解析
+type failingResolver struct{}
+
+func (failingResolver) Resolve(ctx context.Context, chatMsg *socket_client_service.ChatNotificationMessage, parsedInfo *ParsedMessageInfo) (*Audience, error) {
+	return nil, errors.New("storage unavailable")
+}
+
+// memoryPinClaimer 进程内的 PIN 推送权抢占记录，模拟多个实例共享的 Redis
+type memoryPinClaimer struct {
+	mu      sync.Mutex
+	claimed map[string]bool
+}
+
+func (c *memoryPinClaimer) Name() string { return "memory" }
+
+func (c *memoryPinClaimer) Claim(ctx context.Context, pinId string) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.claimed[pinId] {
+		return false, nil
+	}
+	c.claimed[pinId] = true
+	return true, nil
+}
+
+func (c *memoryPinClaimer) Release(ctx context.Context, pinId string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.claimed, pinId)
+	return nil
+}
+
+func (c *memoryPinClaimer) isClaimed(pinId string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.claimed[pinId]
+}
+
+func TestIntakeReplaysUnfinishedMessages(t *testing.T) {
+	newTestStores(t)
+
+	config := &Config{IntakeConfig: &IntakeConfig{Enabled: true, MaxAttempts: 2}}
+
+	// 第一次运行：处理因临时故障失败，条目保留在进件日志中
+	crashed := NewPushCenter(config)
+	crashed.SetAudienceResolver(failingResolver{})
+	crashed.SetDispatcher(&recordingDispatcher{})
+	crashed.consuming = true
+	crashed.HandleMessage(&socket_client_service.ChatNotificationMessage{
+		Type: "group_chat",
+		Data: &socket_client_service.ExtraServiceMessage{
+			Message: map[string]interface{}{"pinId": "pin-intake", "groupId": "group1"},
+		},
+	})
+	crashed.inflight.Wait()
+
+	entries, err := pebble_service.ListIntake()
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("ListIntake() = %d entries, err: %v, want 1", len(entries), err)
+	}
+
+	// 重启后恢复处理，完成后删除条目
+	dispatcher := &recordingDispatcher{}
+	restarted := NewPushCenter(config)
+	restarted.SetAudienceResolver(listResolver{"group1": {"alice", "bob"}})
+	restarted.SetDispatcher(dispatcher)
+	recovered := restarted.recoverIntake()
+	if len(recovered) != 1 {
+		t.Fatalf("recoverIntake() = %d messages, want 1", len(recovered))
+	}
+	restarted.addInflight(len(recovered))
+	for _, queued := range recovered {
+		restarted.processQueued(queued)
+	}
+	restarted.inflight.Wait()
+
+	sort.Strings(dispatcher.metaIds)
+	if want := []string{"alice", "bob"}; !reflect.DeepEqual(dispatcher.metaIds, want) {
+		t.Errorf("dispatched to %v, want %v", dispatcher.metaIds, want)
+	}
+	if entries, _ := pebble_service.ListIntake(); len(entries) != 0 {
+		t.Errorf("intake has %d entries after replay, want 0", len(entries))
+	}
+}
+
+func TestIntakeDropsAfterMaxAttempts(t *testing.T) {
+	newTestStores(t)
+
+	if _, err := pebble_service.AppendIntake([]byte(`{"type":"group_chat","data":{"message":{"pinId":"pin-2"}}}`)); err != nil {
+		t.Fatalf("AppendIntake() failed, err: %v", err)
+	}
+
+	pc := NewPushCenter(&Config{IntakeConfig: &IntakeConfig{Enabled: true, MaxAttempts: 1}})
+	if recovered := pc.recoverIntake(); len(recovered) != 1 {
+		t.Fatalf("first recoverIntake() = %d messages, want 1", len(recovered))
+	}
+	if recovered := pc.recoverIntake(); len(recovered) != 0 {
+		t.Errorf("second recoverIntake() = %d messages, want 0", len(recovered))
+	}
+	if entries, _ := pebble_service.ListIntake(); len(entries) != 0 {
+		t.Errorf("intake has %d entries after exceeding max attempts, want 0", len(entries))
+	}
+}
+
+func TestPinClaimReleasedForUnfinishedMessages(t *testing.T) {
+	newTestStores(t)
+
+	claimer := &memoryPinClaimer{claimed: make(map[string]bool)}
+	config := &Config{IntakeConfig: &IntakeConfig{Enabled: true, MaxAttempts: 2}}
+
+	// 处理失败时释放推送权，重试（或其他实例）可以重新抢占
+	failed := NewPushCenter(config)
+	failed.pinClaimer = claimer
+	failed.SetAudienceResolver(failingResolver{})
+	failed.SetDispatcher(&recordingDispatcher{})
+	failed.consuming = true
+	failed.HandleMessage(&socket_client_service.ChatNotificationMessage{
+		Type: "group_chat",
+		Data: &socket_client_service.ExtraServiceMessage{
+			Message: map[string]interface{}{"pinId": "pin-failed", "groupId": "group1"},
+		},
+	})
+	failed.inflight.Wait()
+	if claimer.isClaimed("pin-failed") {
+		t.Errorf("pin claim should be released after a processing error")
+	}
+
+	// 进程在处理中崩溃时抢占仍在，重启恢复进件日志时释放
+	claimer.Claim(context.Background(), "pin-failed")
+	restarted := NewPushCenter(config)
+	restarted.pinClaimer = claimer
+	if recovered := restarted.recoverIntake(); len(recovered) != 1 {
+		t.Fatalf("recoverIntake() = %d messages, want 1", len(recovered))
+	}
+	if claimer.isClaimed("pin-failed") {
+		t.Errorf("pin claim should be released when recovering the intake entry")
+	}
+}
+
+func TestIdempotencyKeyReleasedOnStageError(t *testing.T) {
+	newTestStores(t)
+
+	chatMsg := func() *socket_client_service.ChatNotificationMessage {
+		return &socket_client_service.ChatNotificationMessage{
+			Type: "group_chat",
+			Data: &socket_client_service.ExtraServiceMessage{
+				Message: map[string]interface{}{"groupId": "group1", "content": "hello"},
+			},
+		}
+	}
+	process := func(pc *PushCenter) {
+		pc.consuming = true
+		pc.HandleMessage(chatMsg())
+		pc.inflight.Wait()
+	}
+
+	// Dedup 之后的环节失败时释放幂等键，重试可以再次处理
+	failed := NewPushCenter(&Config{})
+	failed.SetAudienceResolver(listResolver{"group1": {"alice"}})
+	failed.SetDispatcher(&recordingDispatcher{})
+	failed.AddStage(NewPipelineStage("broken", func(ctx context.Context, msg *PipelineMessage, next PipelineHandler) error {
+		return errors.New("send failed")
+	}), StageFilter)
+	process(failed)
+
+	dispatcher := &recordingDispatcher{}
+	retried := NewPushCenter(&Config{})
+	retried.SetAudienceResolver(listResolver{"group1": {"alice"}})
+	retried.SetDispatcher(dispatcher)
+	process(retried)
+	if want := []string{"alice"}; !reflect.DeepEqual(dispatcher.metaIds, want) {
+		t.Fatalf("retry dispatched to %v, want %v", dispatcher.metaIds, want)
+	}
+
+	// 处理完成后记录结果，重复消息被跳过，恢复进件日志时也不会释放
+	process(retried)
+	if len(dispatcher.metaIds) != 1 {
+		t.Errorf("duplicate message dispatched to %v", dispatcher.metaIds)
+	}
+	retried.releaseUnfinishedClaim(chatMsg())
+	process(retried)
+	if len(dispatcher.metaIds) != 1 {
+		t.Errorf("completed message dispatched again after recovery: %v", dispatcher.metaIds)
+	}
+}
@@ -0,0 +1,313 @@
+package pushcenter
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"push-base-service/models"
+	"push-base-service/service/expo_service"
+	"push-base-service/service/pebble_service"
+	"push-base-service/service/push_service"
+	"push-base-service/service/socket_client_service"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	socketio "github.com/zishang520/socket.io/servers/socket/v3"
+)
+
+// integrationAuthKey 测试上游要求的 extraPushAuthKey
+const integrationAuthKey = "integration-auth-key"
+
+// integrationSettle 收到预期的推送后继续等待的时间，确认没有多余的推送
+const integrationSettle = 300 * time.Millisecond
+
+// fakeSocketServer 进程内的 Socket.IO 上游，按聊天服务的格式向已连接的客户端下发 WS_SERVER_NOTIFY_* 消息
+type fakeSocketServer struct {
+	*httptest.Server
+	io        *socketio.Server
+	connected chan *socketio.Socket
+}
+
+// newFakeSocketServer 启动上游，只接受携带正确 extraPushAuthKey 的连接
+func newFakeSocketServer(t *testing.T) *fakeSocketServer {
+	t.Helper()
+	f := &fakeSocketServer{io: socketio.NewServer(nil, nil), connected: make(chan *socketio.Socket, 1)}
+	f.io.On("connection", func(clients ...any) {
+		client := clients[0].(*socketio.Socket)
+		if client.Handshake().Query.Query().Get("extraPushAuthKey") != integrationAuthKey {
+			client.Disconnect(true)
+			return
+		}
+		f.connected <- client
+	})
+	f.Server = httptest.NewServer(f.io.ServeHandler(nil))
+	t.Cleanup(func() {
+		f.io.Close(nil)
+		f.Server.Close()
+	})
+	return f
+}
+
+// waitClient 等待推送中心连接上来
+func (f *fakeSocketServer) waitClient(t *testing.T) *socketio.Socket {
+	t.Helper()
+	select {
+	case client := <-f.connected:
+		return client
+	case <-time.After(10 * time.Second):
+		t.Fatal("推送中心未连接到测试上游")
+		return nil
+	}
+}
+
+// fakeExpoPushServer 记录推送请求的 Expo 推送接口，每个令牌返回一个成功的回执
+type fakeExpoPushServer struct {
+	*httptest.Server
+
+	mu     sync.Mutex
+	pushed []*expo_service.PushMessage
+}
+
+func newFakeExpoPushServer(t *testing.T) *fakeExpoPushServer {
+	t.Helper()
+	f := &fakeExpoPushServer{}
+	mux := http.NewServeMux()
+	mux.HandleFunc(expo_service.PushPath, f.handlePush)
+	f.Server = httptest.NewServer(mux)
+	t.Cleanup(f.Close)
+	return f
+}
+
+func (f *fakeExpoPushServer) handlePush(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	var messages []*expo_service.PushMessage
+	if err := json.Unmarshal(body, &messages); err != nil {
+		var message expo_service.PushMessage
+		if err := json.Unmarshal(body, &message); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		messages = []*expo_service.PushMessage{&message}
+	}
+
+	f.mu.Lock()
+	response := expo_service.PushResponse{}
+	for _, message := range messages {
+		f.pushed = append(f.pushed, message)
+		response.Data = append(response.Data, expo_service.PushTicket{Status: "ok", ID: "ticket-" + strconv.Itoa(len(f.pushed))})
+	}
+	f.mu.Unlock()
+
+	json.NewEncoder(w).Encode(response)
+}
+
+// take 等待收到 count 条推送，再等待一段时间确认没有多余的推送，返回并清空收到的推送（按令牌排序）
+func (f *fakeExpoPushServer) take(t *testing.T, count int) []*expo_service.PushMessage {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for f.count() < count && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	time.Sleep(integrationSettle)
+
+	f.mu.Lock()
+	pushed := f.pushed
+	f.pushed = nil
+	f.mu.Unlock()
+
+	sort.Slice(pushed, func(i, j int) bool { return pushed[i].To[0] < pushed[j].To[0] })
+	if len(pushed) != count {
+		var tokens []string
+		for _, message := range pushed {
+			tokens = append(tokens, message.To...)
+		}
+		t.Fatalf("Expo 收到 %d 条推送 %v，期望 %d 条", len(pushed), tokens, count)
+	}
+	return pushed
+}
+
+func (f *fakeExpoPushServer) count() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.pushed)
+}
+
+// integrationHarness 完整的推送中心：测试上游 → 推送中心（临时目录 Pebble）→ 测试 Expo
+type integrationHarness struct {
+	pc       *PushCenter
+	upstream *socketio.Socket
+	expo     *fakeExpoPushServer
+}
+
+// newIntegrationHarness 按 main 的方式初始化并启动推送中心，users 为需要登记 Expo 令牌的用户
+func newIntegrationHarness(t *testing.T, users ...string) *integrationHarness {
+	t.Helper()
+	socketServer := newFakeSocketServer(t)
+	expo := newFakeExpoPushServer(t)
+
+	pc := NewPushCenter(&Config{
+		SocketConfig:   &socket_client_service.Config{ServerURL: socketServer.URL, ExtraPushAuthKey: integrationAuthKey},
+		PebbleConfig:   &pebble_service.Config{DBPath: t.TempDir()},
+		CandyBagConfig: &CandyBagConfig{Enabled: true},
+	})
+	if err := pc.Initialize(); err != nil {
+		t.Fatalf("Initialize() failed, err: %v", err)
+	}
+	if err := pc.GetPushManager().RegisterExpoProvider(&expo_service.Config{BaseURL: expo.URL, MaxRetries: 1, BaseDelay: time.Millisecond}); err != nil {
+		t.Fatalf("RegisterExpoProvider() failed, err: %v", err)
+	}
+	for _, metaId := range users {
+		if err := pebble_service.SetUserToken(metaId, push_service.ProviderTypeExpo, integrationToken(metaId)); err != nil {
+			t.Fatalf("SetUserToken(%s) failed, err: %v", metaId, err)
+		}
+	}
+	if err := pc.Run(); err != nil {
+		t.Fatalf("Run() failed, err: %v", err)
+	}
+	t.Cleanup(func() {
+		// 停止消息来源不应等到超时
+		start := time.Now()
+		pc.Stop()
+		if elapsed := time.Since(start); elapsed > DefaultShutdownSourceTimeout/2 {
+			t.Errorf("Stop() took %v", elapsed)
+		}
+	})
+
+	return &integrationHarness{pc: pc, upstream: socketServer.waitClient(t), expo: expo}
+}
+
+// integrationToken 用户的 Expo 令牌
+func integrationToken(metaId string) string {
+	return "ExponentPushToken[" + metaId + "]"
+}
+
+// emit 以聊天服务的格式下发一条消息（D 为 {message, repostMetaIds, mentionMetaIds}）
+func (h *integrationHarness) emit(t *testing.T, method string, message map[string]interface{}, repostMetaIds, mentionMetaIds []string) {
+	t.Helper()
+	payload, err := json.Marshal(socket_client_service.SocketData{M: method, C: 0, D: map[string]interface{}{
+		"message":        message,
+		"repostMetaIds":  repostMetaIds,
+		"mentionMetaIds": mentionMetaIds,
+	}})
+	if err != nil {
+		t.Fatalf("序列化上游消息失败: %v", err)
+	}
+	if err := h.upstream.Emit("message", string(payload)); err != nil {
+		t.Fatalf("下发上游消息失败: %v", err)
+	}
+}
+
+// groupMessage 群聊消息
+func groupMessage(pinId, groupId, sender string, chatType int64) map[string]interface{} {
+	return map[string]interface{}{
+		"pinId":    pinId,
+		"groupId":  groupId,
+		"metaId":   sender,
+		"userInfo": map[string]interface{}{"name": "Alice"},
+		"content":  "hello",
+		"chatType": chatType,
+	}
+}
+
+// pushedTokens 推送的目标令牌
+func pushedTokens(pushed []*expo_service.PushMessage) []string {
+	var tokens []string
+	for _, message := range pushed {
+		tokens = append(tokens, message.To...)
+	}
+	return tokens
+}
+
+func TestIntegrationSocketToExpo(t *testing.T) {
+	h := newIntegrationHarness(t, "bob", "carol", "dave", "erin", "frank")
+
+	t.Run("group chat", func(t *testing.T) {
+		h.emit(t, socket_client_service.WS_SERVER_NOTIFY_GROUP_CHAT, groupMessage("pin-group", "group1", "alice", 0), []string{"bob", "carol"}, nil)
+
+		pushed := h.expo.take(t, 2)
+		if got := pushedTokens(pushed); got[0] != integrationToken("bob") || got[1] != integrationToken("carol") {
+			t.Errorf("pushed to %v", got)
+		}
+		for _, message := range pushed {
+			if message.Title != "New Message in Group" || message.Data["pinId"] != "pin-group" || message.Data["groupId"] != "group1" {
+				t.Errorf("notification = %+v", message)
+			}
+		}
+	})
+
+	t.Run("dedup", func(t *testing.T) {
+		// 上游重复下发同一 PIN（含已推送过的 PIN），每个用户只收到一次
+		for i := 0; i < 3; i++ {
+			h.emit(t, socket_client_service.WS_SERVER_NOTIFY_GROUP_CHAT, groupMessage("pin-dup", "group1", "alice", 0), []string{"bob"}, nil)
+		}
+		h.emit(t, socket_client_service.WS_SERVER_NOTIFY_GROUP_CHAT, groupMessage("pin-group", "group1", "alice", 0), []string{"bob", "carol"}, nil)
+
+		if got := pushedTokens(h.expo.take(t, 1)); got[0] != integrationToken("bob") {
+			t.Errorf("pushed to %v", got)
+		}
+	})
+
+	t.Run("blocked", func(t *testing.T) {
+		if err := pebble_service.AddBlockedChat("carol", "group2", "group", "", 0); err != nil {
+			t.Fatalf("AddBlockedChat() failed, err: %v", err)
+		}
+		h.emit(t, socket_client_service.WS_SERVER_NOTIFY_GROUP_CHAT, groupMessage("pin-blocked", "group2", "alice", 0), []string{"bob", "carol"}, nil)
+
+		if got := pushedTokens(h.expo.take(t, 1)); got[0] != integrationToken("bob") {
+			t.Errorf("pushed to %v", got)
+		}
+	})
+
+	t.Run("mentions", func(t *testing.T) {
+		// 被提及的用户只收到提及通知，其余用户收到普通通知
+		h.emit(t, socket_client_service.WS_SERVER_NOTIFY_GROUP_CHAT, groupMessage("pin-mention", "group1", "alice", 0), []string{"bob", "dave"}, []string{"dave"})
+
+		pushed := h.expo.take(t, 2)
+		bob, dave := pushed[0], pushed[1]
+		if bob.To[0] != integrationToken("bob") || bob.Title != "New Message in Group" || bob.Data["isMention"] != nil {
+			t.Errorf("normal notification = %+v", bob)
+		}
+		if dave.To[0] != integrationToken("dave") || dave.Title != "You were mentioned" || dave.Data["isMention"] != true {
+			t.Errorf("mention notification = %+v", dave)
+		}
+	})
+
+	t.Run("candy bag", func(t *testing.T) {
+		// 关闭红包通知的用户不会收到红包消息
+		if err := pebble_service.SaveUserPreferences(&models.UserPreferences{MetaID: "frank", MuteCandyBags: true}); err != nil {
+			t.Fatalf("SaveUserPreferences() failed, err: %v", err)
+		}
+		message := groupMessage("pin-candy", "group1", "alice", 23)
+		message["amount"] = "8.8"
+		h.emit(t, socket_client_service.WS_SERVER_NOTIFY_GROUP_CHAT, message, []string{"erin", "frank"}, nil)
+
+		pushed := h.expo.take(t, 1)[0]
+		if pushed.To[0] != integrationToken("erin") || pushed.Priority != push_service.PriorityHigh || pushed.Sound != DefaultCandyBagSound || pushed.ChannelID != DefaultCandyBagChannelID {
+			t.Errorf("candy bag notification = %+v", pushed)
+		}
+		candyBag, ok := pushed.Data["candyBag"].(map[string]interface{})
+		if !ok || candyBag["amountHint"] != "8.8" {
+			t.Errorf("data.candyBag = %+v", pushed.Data["candyBag"])
+		}
+	})
+
+	t.Run("private chat", func(t *testing.T) {
+		h.emit(t, socket_client_service.WS_SERVER_NOTIFY_PRIVATE_CHAT, map[string]interface{}{
+			"pinId":    "pin-private",
+			"from":     "alice",
+			"to":       "dave",
+			"metaId":   "alice",
+			"userInfo": map[string]interface{}{"name": "Alice"},
+			"content":  "hi",
+		}, []string{"dave"}, nil)
+
+		pushed := h.expo.take(t, 1)[0]
+		if pushed.To[0] != integrationToken("dave") || pushed.Title != "New Message" || pushed.Data["metaId"] != "alice" {
+			t.Errorf("private notification = %+v", pushed)
+		}
+	})
+}
@@ -0,0 +1,98 @@
+package pushcenter
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"push-base-service/service/membership_service"
+	"push-base-service/service/metrics_service"
+	"push-base-service/service/socket_client_service"
+)
+
+// membershipRejectedCounter 不属于该聊天而被剔除的上游接收用户数
+var membershipRejectedCounter = metrics_service.NewCounterVec(
+	"push_membership_rejected_total", "Number of upstream recipients dropped because they are not members of the chat", "message_type")
+
+// verifyAudience 开启接收用户校验时剔除不属于该聊天的用户：群聊按群成员接口校验，私聊只保留会话双方
+// 群组接口不可用时默认照常推送，配置 fail_closed 后返回错误（启用进件日志时重启后重新处理）
+func (pc *PushCenter) verifyAudience(ctx context.Context, chatMsg *socket_client_service.ChatNotificationMessage, parsedInfo *ParsedMessageInfo, audience *Audience) (*Audience, error) {
+	if pc.membership == nil || !pc.membership.Verifies(chatMsg.Type) {
+		return audience, nil
+	}
+	candidates := audience.Users()
+	if len(candidates) == 0 {
+		return audience, nil
+	}
+
+	var members map[string]bool
+	switch chatMsg.Type {
+	case socket_client_service.MessageTypeGroupChat:
+		allowed, _, err := pc.membership.FilterGroupMembers(ctx, parsedInfo.GroupId, candidates)
+		if err != nil {
+			if pc.membership.FailClosed() {
+				return nil, fmt.Errorf("校验群聊接收用户失败: %w", err)
+			}
+			log.Printf("⚠️ 校验群聊接收用户失败，照常推送: %v", err)
+			return audience, nil
+		}
+		members = make(map[string]bool, len(allowed))
+		for _, metaId := range allowed {
+			members[metaId] = true
+		}
+	case socket_client_service.MessageTypePrivateChat:
+		members = privateChatParticipants(chatMsg)
+		if len(members) == 0 {
+			log.Printf("⚠️ 私聊消息缺少会话双方，无法校验接收用户，跳过推送")
+			return &Audience{}, nil
+		}
+	}
+
+	verified := &Audience{
+		Recipients: keepMembers(audience.Recipients, members),
+		Mentioned:  keepMembers(audience.Mentioned, members),
+	}
+	if rejected := len(candidates) - len(verified.Users()); rejected > 0 {
+		membershipRejectedCounter.Add(float64(rejected), chatMsg.Type)
+		traceRejected(parsedInfo.PinId, candidates, verified)
+		log.Printf("🛡️ %d 个上游接收用户不属于该聊天，已剔除: PinId=%s", rejected, parsedInfo.PinId)
+	}
+	return verified, nil
+}
+
+// privateChatParticipants 私聊消息的会话双方（from / to，以及消息创建者 metaId）
+func privateChatParticipants(chatMsg *socket_client_service.ChatNotificationMessage) map[string]bool {
+	participants := make(map[string]bool)
+	messageMap, ok := chatMsg.Data.Message.(map[string]interface{})
+	if !ok {
+		return participants
+	}
+	for _, key := range []string{"from", "to", "metaId"} {
+		if metaId := stringField(messageMap, key); metaId != "" {
+			participants[metaId] = true
+		}
+	}
+	return participants
+}
+
+// keepMembers 保留属于该聊天的用户
+func keepMembers(metaIds []string, members map[string]bool) []string {
+	var kept []string
+	for _, metaId := range metaIds {
+		if members[metaId] {
+			kept = append(kept, metaId)
+		}
+	}
+	return kept
+}
+
+// newMembershipChecker 根据配置创建接收用户校验器，未启用时返回 nil
+func newMembershipChecker(config *membership_service.Config) (*membership_service.Checker, error) {
+	if config == nil || !config.Enabled {
+		return nil, nil
+	}
+	config.ApplyDefaults()
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return membership_service.NewChecker(config), nil
+}
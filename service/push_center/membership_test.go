@@ -0,0 +1,62 @@
+package pushcenter
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"push-base-service/service/membership_service"
+	"push-base-service/service/socket_client_service"
+	"testing"
+)
+
+func TestVerifyAudience(t *testing.T) {
+	available := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !available {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"data":{"list":[{"metaId":"alice"},{"metaId":"bob"}]}}`))
+	}))
+	defer server.Close()
+
+	config := &membership_service.Config{
+		Enabled:      true,
+		Endpoint:     server.URL + "?groupId={groupId}",
+		MessageTypes: []string{membership_service.MessageTypeGroupChat, membership_service.MessageTypePrivateChat},
+	}
+	checker, err := newMembershipChecker(config)
+	if err != nil {
+		t.Fatalf("newMembershipChecker() error = %v", err)
+	}
+	pc := &PushCenter{config: &Config{}, membership: checker}
+
+	groupMsg := &socket_client_service.ChatNotificationMessage{Type: "group_chat", Data: &socket_client_service.ExtraServiceMessage{}}
+	audience := &Audience{Recipients: []string{"alice", "mallory"}, Mentioned: []string{"bob", "eve"}}
+	verified, err := pc.verifyAudience(context.Background(), groupMsg, &ParsedMessageInfo{GroupId: "group1"}, audience)
+	if err != nil {
+		t.Fatalf("verifyAudience() error = %v", err)
+	}
+	if len(verified.Recipients) != 1 || verified.Recipients[0] != "alice" || len(verified.Mentioned) != 1 || verified.Mentioned[0] != "bob" {
+		t.Errorf("verified group audience = %+v", verified)
+	}
+
+	privateMsg := &socket_client_service.ChatNotificationMessage{Type: "private_chat", Data: &socket_client_service.ExtraServiceMessage{
+		Message: map[string]interface{}{"from": "alice", "to": "bob"},
+	}}
+	verified, _ = pc.verifyAudience(context.Background(), privateMsg, &ParsedMessageInfo{}, &Audience{Recipients: []string{"bob", "mallory"}})
+	if len(verified.Recipients) != 1 || verified.Recipients[0] != "bob" {
+		t.Errorf("verified private audience = %+v", verified)
+	}
+
+	// 群组接口不可用：默认照常推送，fail_closed 时返回错误（使用未缓存的群）
+	available = false
+	verified, err = pc.verifyAudience(context.Background(), groupMsg, &ParsedMessageInfo{GroupId: "group2"}, audience)
+	if err != nil || len(verified.Recipients) != 2 {
+		t.Errorf("fail-open verifyAudience() = %+v, %v", verified, err)
+	}
+	config.FailClosed = true
+	if _, err := pc.verifyAudience(context.Background(), groupMsg, &ParsedMessageInfo{GroupId: "group3"}, audience); err == nil {
+		t.Error("fail-closed verifyAudience() should return error")
+	}
+}
@@ -0,0 +1,155 @@
+package pushcenter
+
+import (
+	"fmt"
+	"push-base-service/models"
+	"push-base-service/service/socket_client_service"
+	"strconv"
+	"time"
+)
+
+// NotifyMessageInfo 好友请求、付款通知解析后的信息
+type NotifyMessageInfo struct {
+	Type       string   `json:"type"`                 // 消息类型：friend_request / payment
+	ID         string   `json:"id,omitempty"`         // 好友请求ID或付款交易ID，用于去重
+	PinId      string   `json:"pinId,omitempty"`      // PIN ID
+	FromMetaId string   `json:"fromMetaId,omitempty"` // 发起用户
+	FromName   string   `json:"fromName,omitempty"`   // 发起用户名称
+	Recipients []string `json:"recipients"`           // 接收用户：消息中的 to 与 repostMetaIds 合并
+	Amount     string   `json:"amount,omitempty"`     // 付款金额（仅付款通知）
+	Currency   string   `json:"currency,omitempty"`   // 付款币种（仅付款通知）
+}
+
+// isNotifyMessageType 是否为好友请求、付款等非聊天通知
+func isNotifyMessageType(msgType string) bool {
+	return msgType == socket_client_service.MessageTypeFriendRequest || msgType == socket_client_service.MessageTypePayment
+}
+
+// parseNotifyMessage 解析好友请求、付款通知
+// 好友请求按 requestId 去重，付款按 txId 去重；发起用户取 from（或 metaId），接收用户取 to
+func parseNotifyMessage(chatMsg *socket_client_service.ChatNotificationMessage) (*NotifyMessageInfo, error) {
+	if chatMsg == nil || chatMsg.Data == nil {
+		return nil, fmt.Errorf("通知消息为空")
+	}
+	messageMap, ok := chatMsg.Data.Message.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("通知内容格式无效: %T", chatMsg.Data.Message)
+	}
+
+	info := &NotifyMessageInfo{
+		Type:       chatMsg.Type,
+		PinId:      stringField(messageMap, "pinId"),
+		FromMetaId: stringField(messageMap, "from", "metaId"),
+		FromName:   stringField(messageMap, "nickName"),
+	}
+	if userInfo, ok := messageMap["userInfo"].(map[string]interface{}); ok {
+		if name := stringField(userInfo, "name"); name != "" {
+			info.FromName = name
+		}
+	}
+
+	switch chatMsg.Type {
+	case socket_client_service.MessageTypeFriendRequest:
+		info.ID = stringField(messageMap, "requestId")
+	case socket_client_service.MessageTypePayment:
+		info.ID = stringField(messageMap, "txId")
+		info.Currency = stringField(messageMap, "currency", "symbol")
+		switch amount := messageMap["amount"].(type) {
+		case string:
+			info.Amount = amount
+		case float64:
+			info.Amount = strconv.FormatFloat(amount, 'f', -1, 64)
+		}
+	default:
+		return nil, fmt.Errorf("不支持的通知类型: %s", chatMsg.Type)
+	}
+
+	var to []string
+	if toMetaId := stringField(messageMap, "to"); toMetaId != "" {
+		to = append(to, toMetaId)
+	}
+	info.Recipients = mergeUserIds(to, chatMsg.Data.RepostMetaIds)
+	return info, nil
+}
+
+// stringField 按顺序取第一个非空的字符串字段
+func stringField(fields map[string]interface{}, keys ...string) string {
+	for _, key := range keys {
+		if value, ok := fields[key].(string); ok && value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// generateNotifyText 生成好友请求、付款通知的标题和内容，hideContent 为 true 时内容不含发起用户和金额
+func (pc *PushCenter) generateNotifyText(info *NotifyMessageInfo, hideContent bool) (title, body string) {
+	userName := pc.truncateUserName(info.FromName)
+	if userName == "" {
+		userName = "Someone"
+	}
+
+	switch info.Type {
+	case socket_client_service.MessageTypeFriendRequest:
+		title = "Friend Request"
+		if hideContent {
+			body = "New friend request"
+		} else {
+			body = fmt.Sprintf("%s sent you a friend request", userName)
+		}
+	case socket_client_service.MessageTypePayment:
+		title = "Payment Received"
+		switch {
+		case hideContent:
+			body = "New payment"
+		case info.Amount != "" && info.Currency != "":
+			body = fmt.Sprintf("%s sent you %s %s", userName, info.Amount, info.Currency)
+		case info.Amount != "":
+			body = fmt.Sprintf("%s sent you %s", userName, info.Amount)
+		default:
+			body = fmt.Sprintf("%s sent you a payment", userName)
+		}
+	}
+	return title, body
+}
+
+// notifyMuted 用户是否关闭了该类通知
+func notifyMuted(msgType string, preference *models.UserPreferences) bool {
+	switch msgType {
+	case socket_client_service.MessageTypeFriendRequest:
+		return preference.MuteFriendRequests
+	case socket_client_service.MessageTypePayment:
+		return preference.MutePayments
+	default:
+		return false
+	}
+}
+
+// filterNotifyOptOut 跳过关闭了好友请求或付款通知的用户，其他消息原样返回
+func (pc *PushCenter) filterNotifyOptOut(metaIds []string, parsedInfo *ParsedMessageInfo) (active, optedOut []string) {
+	if parsedInfo.Notify == nil {
+		return metaIds, nil
+	}
+	return pc.filterMutedUsers(metaIds, func(preference *models.UserPreferences) bool {
+		return notifyMuted(parsedInfo.Notify.Type, preference)
+	})
+}
+
+// notifyData 好友请求、付款通知的自定义数据
+func notifyData(info *NotifyMessageInfo) map[string]interface{} {
+	data := map[string]interface{}{
+		"type":       info.Type,
+		"timestamp":  time.Now().Unix(),
+		"pinId":      info.PinId,
+		"fromMetaId": info.FromMetaId,
+	}
+	switch info.Type {
+	case socket_client_service.MessageTypeFriendRequest:
+		data["requestId"] = info.ID
+	case socket_client_service.MessageTypePayment:
+		data["txId"] = info.ID
+		data["amount"] = info.Amount
+		data["currency"] = info.Currency
+	}
+	return data
+}
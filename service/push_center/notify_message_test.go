@@ -0,0 +1,88 @@
+package pushcenter
+
+import (
+	"push-base-service/models"
+	"push-base-service/service/pebble_service"
+	"push-base-service/service/socket_client_service"
+	"push-base-service/service/storage_service"
+	"testing"
+)
+
+func TestParseNotifyMessage(t *testing.T) {
+	payment := &socket_client_service.ChatNotificationMessage{
+		Type: socket_client_service.MessageTypePayment,
+		Data: &socket_client_service.ExtraServiceMessage{
+			Message: map[string]interface{}{
+				"from": "payer", "to": "payee", "txId": "tx1", "amount": 1.5, "symbol": "SPACE",
+				"userInfo": map[string]interface{}{"name": "alice"},
+			},
+			RepostMetaIds: []string{"payee", "watcher"},
+		},
+	}
+	info, err := parseNotifyMessage(payment)
+	if err != nil {
+		t.Fatalf("parseNotifyMessage() error = %v", err)
+	}
+	if info.ID != "tx1" || info.FromMetaId != "payer" || info.FromName != "alice" || info.Amount != "1.5" || info.Currency != "SPACE" {
+		t.Errorf("payment info = %+v", info)
+	}
+	if len(info.Recipients) != 2 || info.Recipients[0] != "payee" || info.Recipients[1] != "watcher" {
+		t.Errorf("recipients = %v", info.Recipients)
+	}
+
+	pc := &PushCenter{config: &Config{}}
+	if key, _ := pc.buildIdempotencyKey(payment, &ParsedMessageInfo{PinId: "pin1"}); key != "payment:tx1" {
+		t.Errorf("idempotency key = %q, want payment:tx1", key)
+	}
+	if title, body := pc.generateNotifyText(info, false); title != "Payment Received" || body != "alice sent you 1.5 SPACE" {
+		t.Errorf("payment text = %q, %q", title, body)
+	}
+	if _, body := pc.generateNotifyText(info, true); body != "New payment" {
+		t.Errorf("hidden payment body = %q", body)
+	}
+
+	friendRequest := &NotifyMessageInfo{Type: socket_client_service.MessageTypeFriendRequest}
+	if title, body := pc.generateNotifyText(friendRequest, false); title != "Friend Request" || body != "Someone sent you a friend request" {
+		t.Errorf("friend request text = %q, %q", title, body)
+	}
+}
+
+func TestNotifyMessagePipelineSkipsMutedUsers(t *testing.T) {
+	newTestStores(t)
+	pebble_service.SaveUserPreferences(&models.UserPreferences{MetaID: "friend-muted", MuteFriendRequests: true})
+	pebble_service.SaveUserPreferences(&models.UserPreferences{MetaID: "friend-payments-muted", MutePayments: true})
+	if err := storage_service.AddBlockedSender("friend-blocker", "friend-bob", ""); err != nil {
+		t.Fatalf("AddBlockedSender() failed, err: %v", err)
+	}
+
+	dispatcher := &bodyDispatcher{bodies: make(map[string]string)}
+	pc := NewPushCenter(&Config{})
+	pc.SetDispatcher(dispatcher)
+	chatMsg := &socket_client_service.ChatNotificationMessage{
+		Type: socket_client_service.MessageTypeFriendRequest,
+		Data: &socket_client_service.ExtraServiceMessage{
+			Message:       map[string]interface{}{"from": "friend-bob", "requestId": "req1", "nickName": "bob"},
+			RepostMetaIds: []string{"friend-muted", "friend-payments-muted", "friend-blocker"},
+		},
+	}
+
+	if err := pc.processChatMessage(chatMsg); err != nil {
+		t.Fatalf("processChatMessage() error = %v", err)
+	}
+	if _, sent := dispatcher.bodies["friend-muted"]; sent {
+		t.Error("user who muted friend requests should be skipped")
+	}
+	if _, sent := dispatcher.bodies["friend-blocker"]; sent {
+		t.Error("user who blocked the sender should be skipped")
+	}
+	if got := dispatcher.bodies["friend-payments-muted"]; got != "bob sent you a friend request" {
+		t.Errorf("friend request body = %q", got)
+	}
+
+	// 同一好友请求只推送一次
+	delete(dispatcher.bodies, "friend-payments-muted")
+	pc.processChatMessage(chatMsg)
+	if _, sent := dispatcher.bodies["friend-payments-muted"]; sent {
+		t.Error("duplicate friend request should not be pushed again")
+	}
+}
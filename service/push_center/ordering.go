@@ -0,0 +1,121 @@
+package pushcenter
+
+import (
+	"hash/fnv"
+	"log"
+	"push-base-service/service/metrics_service"
+	"sync"
+	"time"
+)
+
+// 聊天顺序推送默认配置
+const (
+	DefaultOrderingQueues    = 32
+	DefaultOrderingQueueSize = 1000
+)
+
+// 聊天队列指标：排队等待总时长与消息数之比即平均排队延迟
+var (
+	chatQueueWaitSeconds = metrics_service.NewCounterVec(
+		"push_chat_queue_wait_seconds_total", "Total time chat messages waited in per-chat ordered queues")
+	chatQueueMessages = metrics_service.NewCounterVec(
+		"push_chat_queue_messages_total", "Number of chat messages dispatched through per-chat ordered queues")
+	chatQueueDepth = metrics_service.NewGaugeVec(
+		"push_chat_queue_depth", "Number of chat messages currently waiting in per-chat ordered queues")
+)
+
+// OrderingConfig 聊天顺序推送配置
+// 同一聊天的消息按聊天ID哈希到同一个串行队列依次处理，保证后一条消息的通知不会先于前一条发出
+type OrderingConfig struct {
+	Enabled   bool `yaml:"enabled" json:"enabled"`       // 是否按聊天顺序推送
+	Queues    int  `yaml:"queues" json:"queues"`         // 串行队列数，即不同聊天的最大并发处理数
+	QueueSize int  `yaml:"queue_size" json:"queue_size"` // 每个队列的容量，队列满时接收消息会等待
+}
+
+// orderedMessage 队列中的消息及其入队时间
+type orderedMessage struct {
+	queued     *queuedMessage
+	enqueuedAt time.Time
+}
+
+// chatQueues 按聊天分配的串行处理队列
+type chatQueues struct {
+	queues []chan *orderedMessage
+	wg     sync.WaitGroup
+}
+
+// orderingEnabled 是否按聊天顺序推送
+func (pc *PushCenter) orderingEnabled() bool {
+	return pc.config.OrderingConfig != nil && pc.config.OrderingConfig.Enabled
+}
+
+// startChatQueues 启动聊天串行队列，需在开始消费消息之前调用
+func (pc *PushCenter) startChatQueues() {
+	if !pc.orderingEnabled() {
+		return
+	}
+
+	count := pc.config.OrderingConfig.Queues
+	if count <= 0 {
+		count = DefaultOrderingQueues
+	}
+	size := pc.config.OrderingConfig.QueueSize
+	if size <= 0 {
+		size = DefaultOrderingQueueSize
+	}
+
+	cq := &chatQueues{queues: make([]chan *orderedMessage, count)}
+	for i := range cq.queues {
+		cq.queues[i] = make(chan *orderedMessage, size)
+		cq.wg.Add(1)
+		go pc.runChatQueue(cq, cq.queues[i])
+	}
+	pc.chatQueues = cq
+	log.Printf("✅ 聊天顺序推送已启用: 队列数=%d, 队列容量=%d", count, size)
+}
+
+// stopChatQueues 停止聊天串行队列，需在在途消息处理完成之后调用
+func (pc *PushCenter) stopChatQueues() {
+	cq := pc.chatQueues
+	if cq == nil {
+		return
+	}
+	pc.chatQueues = nil
+
+	for _, queue := range cq.queues {
+		close(queue)
+	}
+	cq.wg.Wait()
+}
+
+// runChatQueue 依次处理队列中的消息
+func (pc *PushCenter) runChatQueue(cq *chatQueues, queue chan *orderedMessage) {
+	defer cq.wg.Done()
+
+	for message := range queue {
+		chatQueueDepth.Dec()
+		chatQueueWaitSeconds.Add(time.Since(message.enqueuedAt).Seconds())
+		chatQueueMessages.Inc()
+
+		pc.ackMessage(message.queued.entryID, pc.processChatMessage(message.queued.chatMsg))
+		pc.doneInflight()
+	}
+}
+
+// enqueueOrdered 将消息加入所属聊天的串行队列，无法确定聊天的消息统一进入第一个队列
+func (pc *PushCenter) enqueueOrdered(cq *chatQueues, queued *queuedMessage) {
+	var key string
+	if parsedInfo, err := pc.parseMessageInfo(queued.chatMsg); err == nil {
+		key = chatCollapseKey(parsedInfo)
+	}
+
+	index := 0
+	if key != "" {
+		hash := fnv.New32a()
+		hash.Write([]byte(key))
+		index = int(hash.Sum32() % uint32(len(cq.queues)))
+	}
+
+	chatQueueDepth.Inc()
+	cq.queues[index] <- &orderedMessage{queued: queued, enqueuedAt: time.Now()}
+}
@@ -0,0 +1,62 @@
+package pushcenter
+
+import (
+	"context"
+	"fmt"
+	"push-base-service/service/push_service"
+	"push-base-service/service/socket_client_service"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// slowDispatcher 按发送顺序记录 PinId，越早的消息发送越慢
+type slowDispatcher struct {
+	mu     sync.Mutex
+	pinIds []string
+}
+
+func (d *slowDispatcher) SendCustomNotificationToUsers(ctx context.Context, metaIds []string, notification *push_service.PushNotification) (*push_service.BatchPushResult, error) {
+	pinId, _ := notification.Data["pinId"].(string)
+	var index int
+	fmt.Sscanf(pinId, "pin-order-%d", &index)
+	time.Sleep(time.Duration(5-index) * 10 * time.Millisecond)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.pinIds = append(d.pinIds, pinId)
+	return &push_service.BatchPushResult{TotalUsers: len(metaIds), SuccessCount: len(metaIds)}, nil
+}
+
+func TestOrderingKeepsChatOrder(t *testing.T) {
+	newTestStores(t)
+
+	dispatcher := &slowDispatcher{}
+	pc := NewPushCenter(&Config{OrderingConfig: &OrderingConfig{Enabled: true, Queues: 4}})
+	pc.SetAudienceResolver(listResolver{"group-order": {"alice"}})
+	pc.SetDispatcher(dispatcher)
+	pc.startChatQueues()
+	pc.consuming = true
+
+	var want []string
+	for i := 1; i <= 4; i++ {
+		pinId := fmt.Sprintf("pin-order-%d", i)
+		want = append(want, pinId)
+		pc.HandleMessage(&socket_client_service.ChatNotificationMessage{
+			Type: "group_chat",
+			Data: &socket_client_service.ExtraServiceMessage{
+				Message: map[string]interface{}{"pinId": pinId, "groupId": "group-order"},
+			},
+		})
+	}
+	pc.inflight.Wait()
+	pc.stopChatQueues()
+
+	if !reflect.DeepEqual(dispatcher.pinIds, want) {
+		t.Errorf("dispatched %v, want %v", dispatcher.pinIds, want)
+	}
+	if got := chatQueueMessages.Get(); got < 4 {
+		t.Errorf("chat queue messages = %v, want at least 4", got)
+	}
+}
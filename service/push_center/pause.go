@@ -0,0 +1,101 @@
+package pushcenter
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"push-base-service/service/pebble_service"
+	"push-base-service/service/push_service"
+	"time"
+)
+
+// pauseResumeInterval 检查暂停到期的间隔
+const pauseResumeInterval = time.Minute
+
+// filterPausedUsers 过滤掉暂停通知的用户（同时为其累计错过的消息数），返回仍需推送的用户和被暂停的用户
+func (pc *PushCenter) filterPausedUsers(metaIds []string) (active, paused []string) {
+	if len(metaIds) == 0 {
+		return metaIds, nil
+	}
+
+	paused, err := pebble_service.RecordPausedMessages(metaIds, time.Now().Unix())
+	if err != nil {
+		log.Printf("⚠️ 检查用户暂停状态失败，照常推送: %v", err)
+		return metaIds, nil
+	}
+	if len(paused) == 0 {
+		return metaIds, nil
+	}
+
+	pausedSet := make(map[string]bool, len(paused))
+	for _, metaId := range paused {
+		pausedSet[metaId] = true
+	}
+	for _, metaId := range metaIds {
+		if !pausedSet[metaId] {
+			active = append(active, metaId)
+		}
+	}
+	log.Printf("⏸️ %d 个用户已暂停通知，跳过推送", len(paused))
+	return active, paused
+}
+
+// pauseResumeLoop 定期恢复暂停到期的用户，并按需发送错过消息的汇总通知
+func (pc *PushCenter) pauseResumeLoop(stopCh chan struct{}) {
+	ticker := time.NewTicker(pauseResumeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+			pc.resumeDuePauses()
+		}
+	}
+}
+
+// resumeDuePauses 恢复所有暂停到期的用户
+func (pc *PushCenter) resumeDuePauses() {
+	due, err := pebble_service.ListDuePauses(time.Now().Unix())
+	if err != nil {
+		log.Printf("⚠️ 读取到期的通知暂停失败: %v", err)
+		return
+	}
+
+	for _, preferences := range due {
+		previous, err := pebble_service.ResumeNotifications(preferences.MetaID)
+		if err != nil {
+			log.Printf("⚠️ 恢复用户通知失败: MetaID=%s, 错误: %v", preferences.MetaID, err)
+			continue
+		}
+		if previous == nil {
+			continue
+		}
+		log.Printf("▶️ 用户通知已自动恢复: MetaID=%s, 暂停期间消息数=%d", previous.MetaID, previous.PausedMissed)
+		if previous.PauseSummary && previous.PausedMissed > 0 {
+			pc.sendResumeSummary(previous.MetaID, previous.PausedMissed)
+		}
+	}
+}
+
+// sendResumeSummary 发送"通知已恢复，错过 N 条消息"的汇总通知
+func (pc *PushCenter) sendResumeSummary(metaId string, missed int) {
+	body := "You missed 1 message"
+	if missed > 1 {
+		body = fmt.Sprintf("You missed %d messages", missed)
+	}
+	notification := &push_service.PushNotification{
+		Title: "Notifications resumed",
+		Body:  body,
+		Data: map[string]interface{}{
+			"type":      "notifications_resumed",
+			"missed":    missed,
+			"timestamp": time.Now().Unix(),
+		},
+		Priority: push_service.PriorityNormal,
+	}
+	if _, err := pc.dispatcher.SendCustomNotificationToUsers(context.Background(), []string{metaId}, notification); err != nil {
+		log.Printf("⚠️ 发送通知恢复汇总失败: MetaID=%s, 错误: %v", metaId, err)
+	}
+}
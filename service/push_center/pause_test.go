@@ -0,0 +1,48 @@
+package pushcenter
+
+import (
+	"push-base-service/service/pebble_service"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPausedUsersAreSkippedAndSummarized(t *testing.T) {
+	newTestStores(t)
+
+	dispatcher := &bodyDispatcher{bodies: make(map[string]string)}
+	pc := &PushCenter{config: &Config{}, dispatcher: dispatcher}
+
+	now := time.Now()
+	pebble_service.PauseNotifications("paused-bob", now.Add(time.Hour).Unix(), true)
+	pebble_service.PauseNotifications("paused-carol", now.Add(-time.Minute).Unix(), true)
+
+	for i := 0; i < 2; i++ {
+		active, paused := pc.filterPausedUsers([]string{"alice", "paused-bob", "paused-carol"})
+		if !reflect.DeepEqual(active, []string{"alice", "paused-carol"}) || !reflect.DeepEqual(paused, []string{"paused-bob"}) {
+			t.Fatalf("filterPausedUsers() = %v, %v", active, paused)
+		}
+	}
+
+	// paused-carol 已到期，恢复时没有错过消息，不发送汇总
+	pc.resumeDuePauses()
+	if preferences, _ := pebble_service.GetUserPreferences("paused-carol"); preferences.PausedUntil != 0 {
+		t.Errorf("paused-carol should be resumed, got %+v", preferences)
+	}
+	if len(dispatcher.bodies) != 0 {
+		t.Errorf("unexpected summaries: %v", dispatcher.bodies)
+	}
+
+	// paused-bob 到期后收到错过 2 条消息的汇总
+	pebble_service.PauseNotifications("paused-bob", now.Add(time.Hour).Unix(), true)
+	pc.filterPausedUsers([]string{"paused-bob"})
+	pc.filterPausedUsers([]string{"paused-bob"})
+	preferences, _ := pebble_service.GetUserPreferences("paused-bob")
+	preferences.PausedUntil = now.Add(-time.Second).Unix()
+	pebble_service.SaveUserPreferences(preferences)
+
+	pc.resumeDuePauses()
+	if got := dispatcher.bodies["paused-bob"]; got != "You missed 2 messages" {
+		t.Errorf("summary body = %q", got)
+	}
+}
@@ -0,0 +1,78 @@
+package pushcenter
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// updateGolden 重新生成 testdata 下的期望文件：go test ./service/push_center -run Golden -update
+var updateGolden = flag.Bool("update", false, "rewrite golden notification text files")
+
+// notificationText 一种发送者/内容组合生成的通知文案
+type notificationText struct {
+	Case           string `json:"case"`
+	Title          string `json:"title"`
+	Body           string `json:"body"`
+	MentionBody    string `json:"mentionBody"`
+	PreviewBody    string `json:"previewBody"`
+	MentionPreview string `json:"mentionPreview"`
+	CandyBagBody   string `json:"candyBagBody"`
+}
+
+func TestNotificationTextGolden(t *testing.T) {
+	cases := []struct {
+		name, msgType, userName, content string
+	}{
+		{"ascii", "group_chat", "alice", "see you at 8"},
+		{"long_ascii_name", "private_chat", "a_very_long_username_that_overflows", "hi"},
+		{"cjk_name_and_preview", "group_chat", "一个非常非常非常非常非常非常长的中文用户名字", strings.Repeat("今天晚上一起吃饭吗？", 20)},
+		{"emoji_only_name", "private_chat", "🎉🔥🦄", "🙂🙃😉"},
+		{"long_emoji_name", "group_chat", strings.Repeat("🐱", 30), strings.Repeat("👍", 150)},
+		{"zwj_family_name", "group_chat", strings.Repeat("👨‍👩‍👧‍👦", 6), "family"},
+		{"long_zwj_family_name", "private_chat", strings.Repeat("👨‍👩‍👧‍👦", 25), strings.Repeat("🇯🇵", 120)},
+		{"empty_name", "group_chat", "", "anonymous"},
+		{"json_content", "group_chat", "dave", "{\"text\": \"line one\\nline\\u202etwo\"}"},
+	}
+
+	pc := &PushCenter{config: &Config{}}
+	var texts []notificationText
+	for _, tc := range cases {
+		text := notificationText{
+			Case:           tc.name,
+			Title:          pc.generateNotificationTitle(tc.msgType, false),
+			Body:           pc.GenerateNotificationBody(tc.msgType, tc.userName, 0, false, "", false, ""),
+			MentionBody:    pc.GenerateNotificationBody(tc.msgType, tc.userName, 0, true, "", false, ""),
+			PreviewBody:    pc.GenerateNotificationBody(tc.msgType, tc.userName, 0, false, "", false, pc.extractPreview(tc.content, "")),
+			MentionPreview: pc.GenerateNotificationBody(tc.msgType, tc.userName, 0, true, "", false, pc.extractPreview(tc.content, "")),
+			CandyBagBody:   pc.GenerateNotificationBody(tc.msgType, tc.userName, 23, false, "", false, ""),
+		}
+		for _, value := range []string{text.Title, text.Body, text.MentionBody, text.PreviewBody, text.MentionPreview, text.CandyBagBody} {
+			if !utf8.ValidString(value) {
+				t.Errorf("%s: %q is not valid UTF-8", tc.name, value)
+			}
+		}
+		texts = append(texts, text)
+	}
+
+	got, _ := json.MarshalIndent(texts, "", "  ")
+	got = append(got, '\n')
+	path := filepath.Join("testdata", "notification_text.golden.json")
+	if *updateGolden {
+		if err := os.WriteFile(path, got, 0644); err != nil {
+			t.Fatalf("写入期望文件失败: %v", err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("读取期望文件失败（使用 -update 生成）: %v", err)
+	}
+	if string(want) != string(got) {
+		t.Errorf("通知文案与 %s 不一致:\n--- want\n%s\n--- got\n%s", path, want, got)
+	}
+}
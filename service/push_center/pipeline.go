@@ -0,0 +1,237 @@
+package pushcenter
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"push-base-service/service/push_service"
+	"push-base-service/service/socket_client_service"
+	"push-base-service/service/tracing_service"
+	"slices"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// 推送流水线的三个可替换环节：消息来源 → 接收用户解析 → 通知发送
+// 默认使用上游 Socket、消息自带的转发/提及用户列表和推送服务管理器，
+// 其他前端（CLI 回放、HTTP 接入、Kafka）或接收用户逻辑（如邮件列表）实现对应接口后在 Initialize 之前注册即可
+//
+// 解析出接收用户后，消息依次经过 PipelineStage 中间件链（默认 Dedup → Filter → Classify → Template → Route → Send → Record），
+// 限流、摘要合并、审计等新环节通过 AddStage 插入，不需要改动核心处理逻辑
+
+// MessageSource 聊天消息来源，收到消息后交给推送中心设置的处理器
+// socket_client_service.Manager 即为默认实现
+type MessageSource interface {
+	SetChatMessageHandler(handler func(*socket_client_service.ChatNotificationMessage))
+	Start() error
+	Stop()
+}
+
+// Audience 一条消息的接收用户
+type Audience struct {
+	Recipients []string // 接收推送的用户（屏蔽该聊天的用户稍后过滤）
+	Mentioned  []string // 被提及、需要发送提及通知的用户（不在 Recipients 中的同样推送）
+}
+
+// Users 所有接收用户，每个用户只出现一次
+func (a *Audience) Users() []string {
+	return mergeUserIds(a.Recipients, a.Mentioned)
+}
+
+// AudienceResolver 解析消息的接收用户
+type AudienceResolver interface {
+	Resolve(ctx context.Context, chatMsg *socket_client_service.ChatNotificationMessage, parsedInfo *ParsedMessageInfo) (*Audience, error)
+}
+
+// Dispatcher 通知发送器，push_service.Manager 即为默认实现
+type Dispatcher interface {
+	SendCustomNotificationToUsers(ctx context.Context, metaIds []string, notification *push_service.PushNotification) (*push_service.BatchPushResult, error)
+}
+
+// PipelineMessage 在流水线各环节之间传递的消息状态，各环节按需读取和填充
+type PipelineMessage struct {
+	ChatMsg  *socket_client_service.ChatNotificationMessage
+	Info     *ParsedMessageInfo
+	Audience *Audience // 解析并校验后的接收用户
+
+	Recipients []string                   // 过滤后需要推送的用户（Filter 填充，每个用户只出现一次，包含被提及的用户）
+	Mentioned  []string                   // 其中需要发送提及通知的用户（Filter 填充）
+	Skipped    map[string]string          // 被跳过的用户及原因 DeliverySkip*（Filter 填充）
+	Groups     []*NotificationGroup       // 使用同一条通知的用户分组（Classify 填充，Template、Route 补全）
+	Results    []*push_service.PushResult // 推送结果（Send 填充）
+
+	Replay bool // 管理接口回放的消息，跳过去重检查
+	DryRun bool // 演练：通知不调用推送平台，也不记录 PIN 已通知和投递结果
+
+	blocked chan []string // 与去重检查并发进行的屏蔽检查结果
+}
+
+// NotificationGroup 使用同一条通知的一组用户
+type NotificationGroup struct {
+	Mention      bool                           // 是否为提及通知
+	Summary      bool                           // 是否为摘要通知（Summary 填充，直接发送，不按聊天声音和预览设置拆分）
+	Users        []string                       // 接收用户
+	Title        string                         // 通知标题（Template 填充）
+	Body         string                         // 通知内容（Template 填充）
+	HiddenBody   string                         // 隐藏内容时的通用文案（Template 填充）
+	Data         map[string]interface{}         // 自定义数据（Template 填充）
+	Notification *push_service.PushNotification // 应用路由规则后的通知（Route 填充）
+}
+
+// PipelineHandler 处理流水线消息，即某个环节之后的剩余链路
+type PipelineHandler func(ctx context.Context, msg *PipelineMessage) error
+
+// PipelineStage 流水线环节（中间件）：处理消息后调用 next 交给后续环节，不调用 next 即结束该消息的处理（如去重跳过），
+// 也可以在 next 返回后执行收尾逻辑；返回错误表示存储等临时故障，消息可稍后重新处理
+type PipelineStage interface {
+	Name() string
+	Process(ctx context.Context, msg *PipelineMessage, next PipelineHandler) error
+}
+
+// pipelineStageFunc 函数形式的流水线环节
+type pipelineStageFunc struct {
+	name string
+	fn   func(ctx context.Context, msg *PipelineMessage, next PipelineHandler) error
+}
+
+// NewPipelineStage 用函数创建流水线环节
+func NewPipelineStage(name string, fn func(ctx context.Context, msg *PipelineMessage, next PipelineHandler) error) PipelineStage {
+	return &pipelineStageFunc{name: name, fn: fn}
+}
+
+// Name 实现 PipelineStage 接口
+func (s *pipelineStageFunc) Name() string {
+	return s.name
+}
+
+// Process 实现 PipelineStage 接口
+func (s *pipelineStageFunc) Process(ctx context.Context, msg *PipelineMessage, next PipelineHandler) error {
+	return s.fn(ctx, msg, next)
+}
+
+// MessageAudienceResolver 默认接收用户解析：使用消息中的转发用户和提及用户（metaId 与 globalMetaId 合并去重），
+// 好友请求、付款通知使用解析出的接收用户
+type MessageAudienceResolver struct{}
+
+// Resolve 实现 AudienceResolver 接口
+func (MessageAudienceResolver) Resolve(ctx context.Context, chatMsg *socket_client_service.ChatNotificationMessage, parsedInfo *ParsedMessageInfo) (*Audience, error) {
+	if parsedInfo.Notify != nil {
+		return &Audience{Recipients: parsedInfo.Notify.Recipients}, nil
+	}
+	return &Audience{
+		Recipients: mergeUserIds(chatMsg.Data.RepostMetaIds, chatMsg.Data.RepostGlobalMetaIds),
+		Mentioned:  mergeUserIds(chatMsg.Data.MentionMetaIds, chatMsg.Data.MentionGlobalMetaIds),
+	}, nil
+}
+
+// AddMessageSource 添加消息来源，需在 Initialize 之前调用
+func (pc *PushCenter) AddMessageSource(source MessageSource) {
+	pc.sources = append(pc.sources, source)
+}
+
+// SetAudienceResolver 替换接收用户解析，需在 Run 之前调用
+func (pc *PushCenter) SetAudienceResolver(resolver AudienceResolver) {
+	pc.audience = resolver
+}
+
+// SetDispatcher 替换通知发送器，需在 Run 之前调用
+func (pc *PushCenter) SetDispatcher(dispatcher Dispatcher) {
+	pc.dispatcher = dispatcher
+}
+
+// AddStage 在名为 before 的环节之前插入流水线环节，before 为空时追加到末尾，需在 Run 之前调用
+func (pc *PushCenter) AddStage(stage PipelineStage, before string) error {
+	if before == "" {
+		pc.stages = append(pc.stages, stage)
+		return nil
+	}
+	index := slices.IndexFunc(pc.stages, func(existing PipelineStage) bool {
+		return existing.Name() == before
+	})
+	if index < 0 {
+		return fmt.Errorf("流水线环节不存在: %s", before)
+	}
+	pc.stages = slices.Insert(pc.stages, index, stage)
+	return nil
+}
+
+// StageNames 按执行顺序返回流水线环节名称
+func (pc *PushCenter) StageNames() []string {
+	names := make([]string, len(pc.stages))
+	for i, stage := range pc.stages {
+		names[i] = stage.Name()
+	}
+	return names
+}
+
+// newPipelineMessage 创建流水线消息，并立即开始屏蔽检查，使其与 Dedup 环节的去重检查并发进行
+func (pc *PushCenter) newPipelineMessage(chatMsg *socket_client_service.ChatNotificationMessage, parsedInfo *ParsedMessageInfo, audience *Audience) *PipelineMessage {
+	msg := &PipelineMessage{
+		ChatMsg:  chatMsg,
+		Info:     parsedInfo,
+		Audience: audience,
+		Skipped:  make(map[string]string),
+		blocked:  make(chan []string, 1),
+	}
+	if users := audience.Users(); len(users) > 0 {
+		go func() {
+			msg.blocked <- pc.filterBlockedUsers(users, parsedInfo)
+		}()
+	} else {
+		msg.blocked <- nil
+	}
+	return msg
+}
+
+// blockedRecipients 等待屏蔽检查结果，返回过滤掉屏蔽用户后的接收用户；消息被去重跳过时结果直接丢弃
+func (msg *PipelineMessage) blockedRecipients() []string {
+	return <-msg.blocked
+}
+
+// runPipeline 让消息依次经过流水线各环节，每个环节记录一个 span（环节调用 next 时结束，各环节的 span 并列）
+func (pc *PushCenter) runPipeline(ctx context.Context, msg *PipelineMessage) error {
+	handler := PipelineHandler(func(context.Context, *PipelineMessage) error { return nil })
+	for i := len(pc.stages) - 1; i >= 0; i-- {
+		stage, next := pc.stages[i], handler
+		handler = func(ctx context.Context, msg *PipelineMessage) error {
+			parent := trace.SpanFromContext(ctx)
+			stageCtx, span := tracing_service.Start(ctx, "pipeline."+stage.Name())
+			calledNext := false
+			err := stage.Process(stageCtx, msg, func(nextCtx context.Context, msg *PipelineMessage) error {
+				span.End()
+				calledNext = true
+				// 保留环节对 ctx 的修改，但后续环节的 span 挂在流水线的 span 下
+				return next(trace.ContextWithSpan(nextCtx, parent), msg)
+			})
+			if !calledNext {
+				tracing_service.RecordError(span, err)
+				span.End()
+			}
+			return err
+		}
+	}
+	return handler(ctx, msg)
+}
+
+// startSources 启动所有消息来源，任一来源启动失败时停止已启动的来源
+func (pc *PushCenter) startSources() error {
+	for i, source := range pc.sources {
+		if err := source.Start(); err != nil {
+			for _, started := range pc.sources[:i] {
+				started.Stop()
+			}
+			return fmt.Errorf("启动第 %d 个消息来源失败: %w", i+1, err)
+		}
+	}
+	if len(pc.sources) > 1 {
+		log.Printf("✅ 已启动 %d 个消息来源", len(pc.sources))
+	}
+	return nil
+}
+
+// stopSources 停止所有消息来源
+func (pc *PushCenter) stopSources() {
+	for _, source := range pc.sources {
+		source.Stop()
+	}
+}
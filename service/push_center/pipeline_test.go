@@ -0,0 +1,183 @@
+package pushcenter
+
+import (
+	"context"
+	"push-base-service/service/push_service"
+	"push-base-service/service/socket_client_service"
+	"reflect"
+	"slices"
+	"sort"
+	"sync"
+	"testing"
+)
+
+// stubSource 测试用消息来源，由测试直接投递消息
+type stubSource struct {
+	handler func(*socket_client_service.ChatNotificationMessage)
+	started bool
+}
+
+func (s *stubSource) SetChatMessageHandler(handler func(*socket_client_service.ChatNotificationMessage)) {
+	s.handler = handler
+}
+
+func (s *stubSource) Start() error { s.started = true; return nil }
+
+func (s *stubSource) Stop() { s.started = false }
+
+// listResolver 测试用接收用户解析，按群组返回固定的成员列表
+type listResolver map[string][]string
+
+func (r listResolver) Resolve(ctx context.Context, chatMsg *socket_client_service.ChatNotificationMessage, parsedInfo *ParsedMessageInfo) (*Audience, error) {
+	return &Audience{Recipients: r[parsedInfo.GroupId]}, nil
+}
+
+// recordingDispatcher 记录发送请求的测试发送器
+type recordingDispatcher struct {
+	mu      sync.Mutex
+	metaIds []string
+}
+
+func (d *recordingDispatcher) SendCustomNotificationToUsers(ctx context.Context, metaIds []string, notification *push_service.PushNotification) (*push_service.BatchPushResult, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.metaIds = append(d.metaIds, metaIds...)
+	return &push_service.BatchPushResult{TotalUsers: len(metaIds), SuccessCount: len(metaIds)}, nil
+}
+
+func TestPipelineComponentsCompose(t *testing.T) {
+	ps := newTestStores(t)
+	ps.AddBlockedChat("carol", "group1", "group", "", 0)
+
+	source := &stubSource{}
+	dispatcher := &recordingDispatcher{}
+	pc := NewPushCenter(&Config{})
+	pc.AddMessageSource(source)
+	pc.SetAudienceResolver(listResolver{"group1": {"alice", "bob", "carol"}})
+	pc.SetDispatcher(dispatcher)
+	pc.SetChatMessageHandler()
+	pc.consuming = true
+
+	source.handler(&socket_client_service.ChatNotificationMessage{
+		Type: "group_chat",
+		Data: &socket_client_service.ExtraServiceMessage{
+			Message: map[string]interface{}{"pinId": "pin-1", "groupId": "group1"},
+		},
+	})
+	pc.inflight.Wait()
+
+	sort.Strings(dispatcher.metaIds)
+	if want := []string{"alice", "bob"}; !reflect.DeepEqual(dispatcher.metaIds, want) {
+		t.Errorf("dispatched to %v, want %v", dispatcher.metaIds, want)
+	}
+}
+
+func TestMessageAudienceResolverMergesIds(t *testing.T) {
+	audience, err := MessageAudienceResolver{}.Resolve(context.Background(), &socket_client_service.ChatNotificationMessage{
+		Data: &socket_client_service.ExtraServiceMessage{
+			RepostMetaIds:        []string{"a", "b", ""},
+			RepostGlobalMetaIds:  []string{"b", "c"},
+			MentionGlobalMetaIds: []string{"c"},
+		},
+	}, &ParsedMessageInfo{})
+	if err != nil {
+		t.Fatalf("Resolve() failed, err: %v", err)
+	}
+	if !reflect.DeepEqual(audience.Recipients, []string{"a", "b", "c"}) || !reflect.DeepEqual(audience.Mentioned, []string{"c"}) {
+		t.Errorf("Resolve() = %+v", audience)
+	}
+}
+
+func TestPipelineCustomStages(t *testing.T) {
+	newTestStores(t)
+
+	dispatcher := &recordingDispatcher{}
+	pc := NewPushCenter(&Config{})
+	pc.SetAudienceResolver(listResolver{"group1": {"alice", "bob"}})
+	pc.SetDispatcher(dispatcher)
+
+	// 发送前剔除 bob，发送后读取推送结果
+	dropBob := NewPipelineStage("drop-bob", func(ctx context.Context, msg *PipelineMessage, next PipelineHandler) error {
+		for _, group := range msg.Groups {
+			group.Users = slices.DeleteFunc(group.Users, func(metaId string) bool { return metaId == "bob" })
+		}
+		return next(ctx, msg)
+	})
+	var sentGroups int
+	observe := NewPipelineStage("observe", func(ctx context.Context, msg *PipelineMessage, next PipelineHandler) error {
+		sentGroups = len(msg.Groups)
+		return next(ctx, msg)
+	})
+	if err := pc.AddStage(dropBob, StageSend); err != nil {
+		t.Fatalf("AddStage() failed, err: %v", err)
+	}
+	if err := pc.AddStage(observe, ""); err != nil {
+		t.Fatalf("AddStage() failed, err: %v", err)
+	}
+	if err := pc.AddStage(observe, "missing"); err == nil {
+		t.Error("插入到不存在的环节之前应返回错误")
+	}
+
+	want := []string{StageDedup, StageFilter, StageClassify, StageTemplate, StageRoute, "drop-bob", StageSend, StageRecord, "observe"}
+	if got := pc.StageNames(); !reflect.DeepEqual(got, want) {
+		t.Errorf("StageNames() = %v, want %v", got, want)
+	}
+
+	err := pc.processChatMessage(&socket_client_service.ChatNotificationMessage{
+		Type: "group_chat",
+		Data: &socket_client_service.ExtraServiceMessage{
+			Message: map[string]interface{}{"pinId": "pin-custom", "groupId": "group1"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("processChatMessage() failed, err: %v", err)
+	}
+	if !reflect.DeepEqual(dispatcher.metaIds, []string{"alice"}) || sentGroups != 1 {
+		t.Errorf("dispatched to %v (groups=%d), want [alice]", dispatcher.metaIds, sentGroups)
+	}
+}
+
+// mentionDispatcher 按是否为提及通知记录每个用户收到的推送
+type mentionDispatcher struct {
+	mu    sync.Mutex
+	sends map[string][]bool
+}
+
+func (d *mentionDispatcher) SendCustomNotificationToUsers(ctx context.Context, metaIds []string, notification *push_service.PushNotification) (*push_service.BatchPushResult, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	mention, _ := notification.Data["isMention"].(bool)
+	for _, metaId := range metaIds {
+		d.sends[metaId] = append(d.sends[metaId], mention)
+	}
+	return &push_service.BatchPushResult{TotalUsers: len(metaIds), SuccessCount: len(metaIds)}, nil
+}
+
+func TestPipelineMentionOverridesNormal(t *testing.T) {
+	ps := newTestStores(t)
+	ps.AddBlockedChat("carol", "group1", "group", "", 0)
+
+	dispatcher := &mentionDispatcher{sends: make(map[string][]bool)}
+	pc := NewPushCenter(&Config{})
+	pc.SetDispatcher(dispatcher)
+
+	// alice 同时在转发和提及名单中（带空白），dave 只被提及，carol 被提及但已屏蔽该群
+	err := pc.processChatMessage(&socket_client_service.ChatNotificationMessage{
+		Type: "group_chat",
+		Data: &socket_client_service.ExtraServiceMessage{
+			Message:              map[string]interface{}{"pinId": "pin-mention", "groupId": "group1"},
+			RepostMetaIds:        []string{"alice", "bob", "carol"},
+			RepostGlobalMetaIds:  []string{" alice "},
+			MentionMetaIds:       []string{"alice ", "carol"},
+			MentionGlobalMetaIds: []string{"dave", "alice"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("processChatMessage() failed, err: %v", err)
+	}
+
+	want := map[string][]bool{"alice": {true}, "bob": {false}, "dave": {true}}
+	if !reflect.DeepEqual(dispatcher.sends, want) {
+		t.Errorf("推送记录 = %v, want %v（每个用户一条，提及优先，屏蔽对提及同样生效）", dispatcher.sends, want)
+	}
+}
@@ -0,0 +1,317 @@
+package pushcenter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"push-base-service/service/pebble_service"
+	"push-base-service/service/push_service"
+	"push-base-service/service/translate_service"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// isEncryptedMessage 消息是否加密
+func isEncryptedMessage(encryption string) bool {
+	return encryption != "" && encryption != "0"
+}
+
+// maxMessageContentDepth 从嵌套 JSON 中提取消息文本的最大层数
+const maxMessageContentDepth = 3
+
+// extractPreview 从消息内容中提取预览文本，加密消息不提供预览
+func (pc *PushCenter) extractPreview(content, encryption string) string {
+	if isEncryptedMessage(encryption) {
+		return ""
+	}
+	return pc.extractMessageContent(content)
+}
+
+// extractMessageContent 提取消息的纯文本用于预览：支持字符串、JSON 字符串和带 text/content/message 字段的对象，
+// 去除控制字符和双向文本控制符、合并换行等空白后按预览长度截取；提取不到文本时返回空字符串，不回退为原始 JSON
+func (pc *PushCenter) extractMessageContent(message interface{}) string {
+	return pc.truncatePreview(sanitizePreviewText(messageText(message, maxMessageContentDepth)))
+}
+
+// messageText 提取消息中的文本，depth 为剩余可解析的对象嵌套层数
+func messageText(message interface{}, depth int) string {
+	switch value := message.(type) {
+	case string:
+		if trimmed := strings.TrimSpace(value); strings.HasPrefix(trimmed, "{") {
+			var decoded map[string]interface{}
+			if json.Unmarshal([]byte(trimmed), &decoded) == nil {
+				return messageText(decoded, depth)
+			}
+		}
+		return value
+	case map[string]interface{}:
+		if depth <= 0 {
+			return ""
+		}
+		for _, key := range []string{"text", "content", "message"} {
+			if text := messageText(value[key], depth-1); text != "" {
+				return text
+			}
+		}
+	}
+	return ""
+}
+
+// sanitizePreviewText 去除控制字符和双向文本控制符（避免伪造通知文本显示顺序），连续空白合并为一个空格
+func sanitizePreviewText(text string) string {
+	var builder strings.Builder
+	pendingSpace := false
+	for _, r := range text {
+		switch {
+		case unicode.IsSpace(r):
+			pendingSpace = builder.Len() > 0
+			continue
+		case unicode.IsControl(r) || isBidiControl(r):
+			continue
+		}
+		if pendingSpace {
+			builder.WriteByte(' ')
+			pendingSpace = false
+		}
+		builder.WriteRune(r)
+	}
+	return builder.String()
+}
+
+// isBidiControl 是否为双向文本控制符
+func isBidiControl(r rune) bool {
+	return r == 0x061c || r == 0x200e || r == 0x200f || (r >= 0x202a && r <= 0x202e) || (r >= 0x2066 && r <= 0x2069)
+}
+
+// previewBody 生成带预览的通知内容："{用户名}: {预览}"，提及消息为 "{用户名} mentioned you: {预览}"，
+// 翻译后的预览同样按长度限制截取
+func (pc *PushCenter) previewBody(userName, preview string, isMention bool) string {
+	preview = pc.truncatePreview(preview)
+	truncatedName := pc.truncateUserName(userName)
+	switch {
+	case isMention:
+		if truncatedName == "" {
+			truncatedName = "Someone"
+		}
+		return pc.truncateBody(fmt.Sprintf("%s mentioned you: %s", truncatedName, preview))
+	case truncatedName == "":
+		return pc.truncateBody(preview)
+	default:
+		return pc.truncateBody(fmt.Sprintf("%s: %s", truncatedName, preview))
+	}
+}
+
+// sendWithPreview 发送推送；隐藏通知内容的用户（个人偏好，或开启 hide_encrypted 时的加密消息）收到 hiddenBody，
+// 其余用户在消息带预览时收到带预览的通知内容
+func (pc *PushCenter) sendWithPreview(ctx context.Context, metaIds []string, notification *push_service.PushNotification, hiddenBody string, parsedInfo *ParsedMessageInfo, isMention bool) (*push_service.BatchPushResult, error) {
+	visible, hidden := pc.splitHiddenPreviewUsers(metaIds, parsedInfo)
+	if len(hidden) == 0 {
+		return pc.sendVisiblePreview(ctx, visible, notification, parsedInfo, isMention)
+	}
+
+	hiddenResult, hiddenErr := pc.dispatcher.SendCustomNotificationToUsers(ctx, hidden, withBody(notification, hiddenBody))
+	if len(visible) == 0 {
+		return hiddenResult, hiddenErr
+	}
+	visibleResult, visibleErr := pc.sendVisiblePreview(ctx, visible, notification, parsedInfo, isMention)
+
+	var results []*push_service.BatchPushResult
+	for _, result := range []*push_service.BatchPushResult{hiddenResult, visibleResult} {
+		if result != nil {
+			results = append(results, result)
+		}
+	}
+	if len(results) == 0 {
+		if visibleErr != nil {
+			return nil, visibleErr
+		}
+		return nil, hiddenErr
+	}
+	return mergeBatchResults(results), nil
+}
+
+// splitHiddenPreviewUsers 区分正常显示和隐藏通知内容的用户
+func (pc *PushCenter) splitHiddenPreviewUsers(metaIds []string, parsedInfo *ParsedMessageInfo) (visible, hidden []string) {
+	if parsedInfo.Encrypted && pc.config.HideEncrypted {
+		return nil, metaIds
+	}
+
+	preferences, err := pebble_service.GetUserPreferencesBatch(metaIds)
+	if err != nil {
+		log.Printf("⚠️ 获取用户偏好失败，按默认方式显示通知内容: %v", err)
+		return metaIds, nil
+	}
+	for _, metaId := range metaIds {
+		if preference, exists := preferences[metaId]; exists && preference.HidePreviews {
+			hidden = append(hidden, metaId)
+		} else {
+			visible = append(visible, metaId)
+		}
+	}
+	return visible, hidden
+}
+
+// sendVisiblePreview 消息带预览时发送带预览的通知内容，
+// 并为开启翻译的用户按语言分组翻译预览，每种语言只翻译一次
+func (pc *PushCenter) sendVisiblePreview(ctx context.Context, metaIds []string, notification *push_service.PushNotification, parsedInfo *ParsedMessageInfo, isMention bool) (*push_service.BatchPushResult, error) {
+	// 红包消息保持原有文案
+	if parsedInfo.Preview == "" || isCandyBag(parsedInfo.ChatInfoType) {
+		return pc.dispatcher.SendCustomNotificationToUsers(ctx, metaIds, notification)
+	}
+
+	groups := pc.groupUsersByLocale(metaIds)
+	if len(groups) == 1 && groups[""] != nil {
+		return pc.dispatcher.SendCustomNotificationToUsers(ctx, metaIds, withBody(notification, pc.previewNotificationBody(parsedInfo, isMention, parsedInfo.Preview)))
+	}
+
+	translator := translate_service.GetGlobalService()
+	var results []*push_service.BatchPushResult
+	var lastErr error
+	for locale, users := range groups {
+		preview := parsedInfo.Preview
+		if locale != "" {
+			translated, err := translator.Translate(ctx, parsedInfo.PinId, parsedInfo.Preview, locale)
+			if err != nil {
+				log.Printf("⚠️ 翻译消息预览失败，使用原文: Locale=%s, 错误: %v", locale, err)
+			} else {
+				preview = translated
+			}
+		}
+
+		result, err := pc.dispatcher.SendCustomNotificationToUsers(ctx, users, withBody(notification, pc.previewNotificationBody(parsedInfo, isMention, preview)))
+		if err != nil {
+			log.Printf("❌ 推送预览消息失败: Locale=%s, 错误: %v", locale, err)
+			lastErr = err
+			continue
+		}
+		results = append(results, result)
+	}
+
+	if len(results) == 0 {
+		return nil, lastErr
+	}
+	return mergeBatchResults(results), nil
+}
+
+// previewNotificationBody 按消息信息生成带预览的通知内容
+func (pc *PushCenter) previewNotificationBody(parsedInfo *ParsedMessageInfo, isMention bool, preview string) string {
+	return pc.GenerateNotificationBody(parsedInfo.ChatType, parsedInfo.UserName, parsedInfo.ChatInfoType, isMention, parsedInfo.GroupId, false, preview)
+}
+
+// withBody 复制通知并替换通知内容
+func withBody(notification *push_service.PushNotification, body string) *push_service.PushNotification {
+	copied := *notification
+	copied.Body = body
+	return &copied
+}
+
+// groupUsersByLocale 按用户语言分组，未开启翻译的用户归入 "" 组
+func (pc *PushCenter) groupUsersByLocale(metaIds []string) map[string][]string {
+	groups := make(map[string][]string)
+	if translate_service.GetGlobalService() == nil {
+		groups[""] = metaIds
+		return groups
+	}
+
+	preferences, err := pebble_service.GetUserPreferencesBatch(metaIds)
+	if err != nil {
+		log.Printf("⚠️ 获取用户偏好失败，跳过预览翻译: %v", err)
+		groups[""] = metaIds
+		return groups
+	}
+
+	// 开启翻译但未设置语言的用户使用最近活跃设备上报的语言
+	var withoutLocale []string
+	for _, metaId := range metaIds {
+		if preference, exists := preferences[metaId]; exists && preference.TranslatePreviews && preference.Locale == "" {
+			withoutLocale = append(withoutLocale, metaId)
+		}
+	}
+	deviceLocales := pc.deviceLocales(withoutLocale)
+
+	for _, metaId := range metaIds {
+		locale := ""
+		if preference, exists := preferences[metaId]; exists && preference.TranslatePreviews {
+			locale = preference.Locale
+			if locale == "" {
+				locale = deviceLocales[metaId]
+			}
+			locale = translate_service.NormalizeLocale(locale)
+		}
+		groups[locale] = append(groups[locale], metaId)
+	}
+	return groups
+}
+
+// deviceLocales 获取用户最近活跃（lastSeenAt 最大）且上报了语言的设备的语言
+func (pc *PushCenter) deviceLocales(metaIds []string) map[string]string {
+	locales := make(map[string]string)
+	if len(metaIds) == 0 || pc.stores == nil {
+		return locales
+	}
+
+	userTokens, err := pc.stores.Tokens.GetAllUserTokens(context.Background(), metaIds)
+	if err != nil {
+		log.Printf("⚠️ 获取用户令牌失败，跳过设备语言: %v", err)
+		return locales
+	}
+	for metaId, tokens := range userTokens {
+		if tokens == nil {
+			continue
+		}
+		deviceIds := make([]string, 0, len(tokens.Tokens))
+		for _, token := range tokens.Tokens {
+			deviceIds = append(deviceIds, token)
+		}
+		devices, err := pebble_service.GetDevicesInfo(deviceIds)
+		if err != nil {
+			log.Printf("⚠️ 获取设备信息失败，跳过设备语言: MetaID=%s, 错误: %v", metaId, err)
+			continue
+		}
+
+		var lastSeenAt int64
+		for _, device := range devices {
+			if device.Locale != "" && device.LastSeenAt >= lastSeenAt {
+				locales[metaId], lastSeenAt = device.Locale, device.LastSeenAt
+			}
+		}
+	}
+	return locales
+}
+
+// mergeBatchResults 合并多次批量推送的结果
+func mergeBatchResults(results []*push_service.BatchPushResult) *push_service.BatchPushResult {
+	merged := &push_service.BatchPushResult{Timestamp: time.Now()}
+	for _, result := range results {
+		merged.TotalUsers += result.TotalUsers
+		merged.TotalPlatforms += result.TotalPlatforms
+		merged.SuccessCount += result.SuccessCount
+		merged.FailureCount += result.FailureCount
+		merged.ThrottledCount += result.ThrottledCount
+		merged.Results = append(merged.Results, result.Results...)
+		merged.Duration += result.Duration
+	}
+	return merged
+}
+
+// translationCleanupLoop 定期清理过期的翻译缓存
+func (pc *PushCenter) translationCleanupLoop(stopCh chan struct{}) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+			count, err := pebble_service.PurgeExpiredTranslations()
+			if err != nil {
+				log.Printf("⚠️ 清理过期翻译缓存失败: %v", err)
+			} else if count > 0 {
+				log.Printf("🧹 已清理 %d 条过期翻译缓存", count)
+			}
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package pushcenter
+
+import (
+	"context"
+	"push-base-service/models"
+	"push-base-service/service/pebble_service"
+	"push-base-service/service/push_service"
+	"push-base-service/service/storage_service"
+	"sync"
+	"testing"
+)
+
+// bodyDispatcher 记录每个用户收到的通知内容
+type bodyDispatcher struct {
+	mu     sync.Mutex
+	bodies map[string]string
+}
+
+func (d *bodyDispatcher) SendCustomNotificationToUsers(ctx context.Context, metaIds []string, notification *push_service.PushNotification) (*push_service.BatchPushResult, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, metaId := range metaIds {
+		d.bodies[metaId] = notification.Body
+	}
+	return &push_service.BatchPushResult{TotalUsers: len(metaIds), SuccessCount: len(metaIds)}, nil
+}
+
+func TestSendWithPreviewHidesContent(t *testing.T) {
+	newTestStores(t)
+	pebble_service.SaveUserPreferences(&models.UserPreferences{MetaID: "private-bob", HidePreviews: true})
+
+	dispatcher := &bodyDispatcher{bodies: make(map[string]string)}
+	pc := &PushCenter{config: &Config{}, dispatcher: dispatcher}
+	hiddenBody := pc.GenerateNotificationBody("group_chat", "", 0, false, "", true, "")
+	parsedInfo := &ParsedMessageInfo{UserName: "alice", Preview: "see you at 8"}
+	notification := &push_service.PushNotification{Body: pc.GenerateNotificationBody("group_chat", "alice", 0, false, "", false, "")}
+
+	result, err := pc.sendWithPreview(context.Background(), []string{"carol", "private-bob"}, notification, hiddenBody, parsedInfo, false)
+	if err != nil || result.TotalUsers != 2 {
+		t.Fatalf("sendWithPreview() = %+v, %v", result, err)
+	}
+	if got := dispatcher.bodies["carol"]; got != "alice: see you at 8" {
+		t.Errorf("carol body = %q", got)
+	}
+	if got := dispatcher.bodies["private-bob"]; got != "New message" {
+		t.Errorf("private-bob body = %q, want %q", got, "New message")
+	}
+
+	// 开启 hide_encrypted 后加密消息对所有用户隐藏发送者
+	pc.config.HideEncrypted = true
+	encrypted := &ParsedMessageInfo{UserName: "alice", Encrypted: true}
+	pc.sendWithPreview(context.Background(), []string{"carol"}, notification, hiddenBody, encrypted, false)
+	if got := dispatcher.bodies["carol"]; got != "New message" {
+		t.Errorf("encrypted message body = %q, want %q", got, "New message")
+	}
+}
+
+func TestDeviceLocalesUsesLatestDevice(t *testing.T) {
+	newTestStores(t)
+	ps := pebble_service.GetGlobalService()
+	stores, err := storage_service.NewStores(nil, pebble_service.NewPebbleTokenStore(ps))
+	if err != nil {
+		t.Fatalf("创建存储失败: %v", err)
+	}
+
+	for _, device := range []*models.DeviceInfo{
+		{DeviceID: "locale-phone", Platform: "expo", DeviceMetadata: models.DeviceMetadata{Locale: "ja"}, LastSeenAt: 100},
+		{DeviceID: "locale-tablet", Platform: "fcm", DeviceMetadata: models.DeviceMetadata{Locale: "ko"}, LastSeenAt: 200},
+		{DeviceID: "locale-desktop", Platform: "macos", LastSeenAt: 300}, // 最近活跃但未上报语言
+	} {
+		if err := ps.SetUserToken("locale-dana", device.Platform, device.DeviceID); err != nil {
+			t.Fatalf("SetUserToken() failed, err: %v", err)
+		}
+		device.MetaID = "locale-dana"
+		if err := ps.SaveDeviceInfo(device); err != nil {
+			t.Fatalf("SaveDeviceInfo() failed, err: %v", err)
+		}
+	}
+
+	pc := &PushCenter{config: &Config{}, stores: stores}
+	locales := pc.deviceLocales([]string{"locale-dana", "locale-nobody"})
+	if len(locales) != 1 || locales["locale-dana"] != "ko" {
+		t.Errorf("deviceLocales() = %v, want map[locale-dana:ko]", locales)
+	}
+}
+
+func TestExtractMessageContent(t *testing.T) {
+	pc := &PushCenter{config: &Config{}}
+
+	cases := []struct {
+		name    string
+		message interface{}
+		want    string
+	}{
+		{"plain", "  hello\n\tworld  ", "hello world"},
+		{"json_text", `{"text":"hi there"}`, "hi there"},
+		{"nested_json", `{"content":{"message":"deep"}}`, "deep"},
+		{"map", map[string]interface{}{"message": "from map"}, "from map"},
+		{"controls_and_bidi", "pay‮usd\u0007 now⁦", "payusd now"},
+		{"non_text_json", `{"amount":10}`, ""},
+		{"too_deep", `{"text":{"text":{"text":{"text":"x"}}}}`, ""},
+		{"invalid_json", "{not json", "{not json"},
+	}
+	for _, tc := range cases {
+		if got := pc.extractMessageContent(tc.message); got != tc.want {
+			t.Errorf("%s: extractMessageContent() = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+
+	if got := pc.extractPreview(`{"text":"secret"}`, "aes"); got != "" {
+		t.Errorf("加密消息不应提取预览: %q", got)
+	}
+}
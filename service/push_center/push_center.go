@@ -2,41 +2,180 @@ package pushcenter
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log"
+	"push-base-service/models"
+	"push-base-service/service/backup_service"
+	"push-base-service/service/catchup_service"
+	"push-base-service/service/dedup_service"
+	"push-base-service/service/disk_service"
+	"push-base-service/service/handoff_service"
+	"push-base-service/service/job_service"
+	"push-base-service/service/membership_service"
 	"push-base-service/service/pebble_service"
 	"push-base-service/service/push_service"
+	"push-base-service/service/sampling_service"
+	"push-base-service/service/schedule_service"
 	"push-base-service/service/socket_client_service"
+	"push-base-service/service/storage_service"
+	"push-base-service/service/throttle_service"
+	"push-base-service/service/tracing_service"
+	"push-base-service/service/translate_service"
+	"push-base-service/service/webhook_service"
 	"slices"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // PushCenter 推送中心管理器
 type PushCenter struct {
-	socketManager *socket_client_service.Manager
-	pushManager   *push_service.Manager
-	config        *Config
-	running       bool
-	mu            sync.RWMutex
+	socketManager     *socket_client_service.Manager
+	pushManager       *push_service.Manager
+	webhookDispatcher *webhook_service.Dispatcher
+	sampler           *sampling_service.Sampler
+	auditor           *pushAuditor
+	scheduler         *schedule_service.Scheduler
+	backupScheduler   *backup_service.Scheduler
+	jobRunner         *job_service.Runner
+	diskMonitor       *disk_service.Monitor
+	coordinator       *handoff_service.Coordinator
+	tokenStore        *pebble_service.PebbleTokenStore
+	stores            *storage_service.Stores
+	pinClaimer        dedup_service.PinClaimer    // 多实例 PIN 推送权抢占，local 模式为 nil
+	router            *Router                     // 通知路由规则
+	messageTypes      *MessageTypeSwitch          // 启用的消息类型（可通过管理接口在运行时修改）
+	sources           []MessageSource             // 消息来源，默认为上游 Socket
+	audience          AudienceResolver            // 接收用户解析
+	membership        *membership_service.Checker // 上游接收用户校验（未启用时为 nil）
+	catchup           *catchup_service.Fetcher    // 重新连接后补拉断线期间的消息（未启用时为 nil）
+	dispatcher        Dispatcher                  // 通知发送器
+	stages            []PipelineStage             // 推送流水线环节
+	summaries         *summaryTracker             // 摘要通知计数，未启用摘要通知时为 nil
+	config            *Config
+	running           bool
+	mu                sync.RWMutex
+
+	// 消费状态：启用部署交接时仅主实例消费消息，待命期间的消息先缓存，接管后补发
+	consuming    bool
+	pending      []*pendingMessage
+	inflight     sync.WaitGroup
+	leaderStopCh chan struct{}
+	chatQueues   *chatQueues // 按聊天顺序推送的串行队列，未启用时为 nil
+	consumeMu    sync.Mutex
+
+	// 背压状态：backlog 为已接收未处理完成的消息数，backpressureSent 为最近一次同步到上游的状态
+	backlog          atomic.Int64
+	backpressured    atomic.Bool
+	backpressureSent bool
+	backpressureMu   sync.Mutex
+
+	// 补拉检查点：每个上游最新消息的时间戳和 PIN，以及正在补拉的上游
+	checkpoints   map[string]*models.CatchupCheckpoint
+	catchupActive map[string]bool
+	checkpointMu  sync.Mutex
+
+	// 回执轮询单独停止：停止时最后查询一次到期回执，完成后关闭 receiptDone
+	receiptStopCh chan struct{}
+	receiptDone   chan struct{}
+}
+
+// pendingMessage 待命期间缓存的消息
+type pendingMessage struct {
+	chatMsg    *socket_client_service.ChatNotificationMessage
+	receivedAt time.Time
 }
 
 // Config 推送中心配置
 type Config struct {
-	SocketConfig *socket_client_service.Config `yaml:"socket" json:"socket"`
-	PebbleConfig *pebble_service.Config        `yaml:"pebble" json:"pebble"`               // Pebble 数据库配置
-	EnabledTypes []string                      `yaml:"enabled_types" json:"enabled_types"` // 启用的消息类型
+	SocketConfig      *socket_client_service.Config   `yaml:"socket" json:"socket"`
+	SocketConfigs     []*socket_client_service.Config `yaml:"sockets" json:"sockets"`                   // 额外的上游 Socket 服务器（如聊天集群分片）
+	PebbleConfig      *pebble_service.Config          `yaml:"pebble" json:"pebble"`                     // Pebble 数据库配置
+	EnabledTypes      []string                        `yaml:"enabled_types" json:"enabled_types"`       // 启用的消息类型
+	WebhookConfig     *webhook_service.Config         `yaml:"webhook" json:"webhook"`                   // 租户投递事件 Webhook 配置
+	ScheduleConfig    *schedule_service.Config        `yaml:"schedule" json:"schedule"`                 // 定时推送调度器配置
+	IdempotencyTTL    time.Duration                   `yaml:"idempotency_ttl" json:"idempotency_ttl"`   // 幂等键保留时长，默认 24 小时
+	TokenCacheSize    int                             `yaml:"token_cache_size" json:"token_cache_size"` // 令牌 LRU 缓存容量，<= 0 表示不缓存
+	TokenCacheTTL     time.Duration                   `yaml:"token_cache_ttl" json:"token_cache_ttl"`   // 令牌缓存过期时间
+	HandoffConfig     *handoff_service.Config         `yaml:"handoff" json:"handoff"`                   // 部署交接配置
+	ThrottleConfig    *throttle_service.Config        `yaml:"throttle" json:"throttle"`                 // 推送限流配置
+	QAConfig          *QAConfig                       `yaml:"qa" json:"qa"`                             // QA 虚拟收件箱配置
+	QuotaConfig       *QuotaConfig                    `yaml:"quota" json:"quota"`                       // 租户月度推送配额配置
+	PreviewEnabled    bool                            `yaml:"preview_enabled" json:"preview_enabled"`   // 是否在通知中展示消息预览（仅未加密消息）
+	HideEncrypted     bool                            `yaml:"hide_encrypted" json:"hide_encrypted"`     // 加密消息的通知一律隐藏发送者名称，只显示通用文案
+	CandyBagConfig    *CandyBagConfig                 `yaml:"candy_bag" json:"candy_bag"`               // 红包通知增强配置
+	GroupingConfig    *GroupingConfig                 `yaml:"grouping" json:"grouping"`                 // 通知分组与摘要通知配置
+	ActionsConfig     *ActionsConfig                  `yaml:"actions" json:"actions"`                   // 可操作通知（通知类别）配置
+	TranslationConfig *translate_service.Config       `yaml:"translation" json:"translation"`           // 消息预览翻译配置
+	BackupConfig      *backup_service.Config          `yaml:"backup" json:"backup"`                     // Pebble 备份配置
+	StorageConfig     *storage_service.Config         `yaml:"storage" json:"storage"`                   // 令牌、屏蔽聊天、已通知 PIN 的存储后端配置
+	DedupConfig       *dedup_service.Config           `yaml:"dedup" json:"dedup"`                       // 多实例 PIN 去重协调配置
+	SamplingConfig    *sampling_service.Config        `yaml:"sampling" json:"sampling"`                 // 外发通知抽样镜像配置
+	RoutingRules      []models.RoutingRule            `yaml:"routing_rules" json:"routing_rules"`       // 通知路由规则（可通过管理接口覆盖）
+	CollapseMode      string                          `yaml:"collapse_mode" json:"collapse_mode"`       // 同一聊天通知的合并方式：none / group / replace
+	WarmupConfig      *WarmupConfig                   `yaml:"warmup" json:"warmup"`                     // 活跃用户令牌预热配置
+	IntakeConfig      *IntakeConfig                   `yaml:"intake" json:"intake"`                     // 进件日志配置（重启后恢复未处理完成的消息）
+	DeliveryConfig    *DeliveryConfig                 `yaml:"delivery" json:"delivery"`                 // 按消息追踪投递结果和回执的配置
+	ShutdownConfig    *ShutdownConfig                 `yaml:"shutdown" json:"shutdown"`                 // 有序停止各阶段的超时配置
+	OrderingConfig    *OrderingConfig                 `yaml:"ordering" json:"ordering"`                 // 同一聊天按顺序推送的配置
+	DiskConfig        *disk_service.Config            `yaml:"disk_monitor" json:"disk_monitor"`         // 数据目录磁盘空间监控配置
+	MembershipConfig  *membership_service.Config      `yaml:"membership" json:"membership"`             // 推送前校验上游接收用户是否属于该聊天的配置
+	CatchupConfig     *catchup_service.Config         `yaml:"catchup" json:"catchup"`                   // 上游重新连接后补拉断线期间消息的配置
+	Backpressure      *BackpressureConfig             `yaml:"backpressure" json:"backpressure"`         // 积压过多时进入背压并通知上游的配置
+	AckConfig         *AckConfig                      `yaml:"ack" json:"ack"`                           // 推送成功后向上游发送确认的配置
+	TokenGCConfig     *TokenGCConfig                  `yaml:"token_gc" json:"token_gc"`                 // 长期未刷新令牌的标记和清理配置
+	AuditConfig       *AuditConfig                    `yaml:"audit" json:"audit"`                       // 外发通知审计日志配置
+	JobConfig         *job_service.Config             `yaml:"jobs" json:"jobs"`                         // 后台任务（异步备份等）配置
+	TextLimits        *TextLimitsConfig               `yaml:"text_limits" json:"text_limits"`           // 通知标题、内容、用户名和消息预览的长度限制
+}
+
+// WarmupConfig 活跃用户令牌预热配置（仅 Pebble 存储后端）
+// 运行期间记录最近查询过令牌的用户并定期保存，接管消费时按保存的列表预加载令牌和偏好
+type WarmupConfig struct {
+	Enabled      bool          `yaml:"enabled" json:"enabled"`             // 是否启用预热
+	Size         int           `yaml:"size" json:"size"`                   // 记录和预热的活跃用户数
+	SaveInterval time.Duration `yaml:"save_interval" json:"save_interval"` // 保存活跃用户列表的间隔
+}
+
+// DefaultHotUsersSaveInterval 默认保存活跃用户列表的间隔
+const DefaultHotUsersSaveInterval = 5 * time.Minute
+
+// QAConfig QA 虚拟收件箱配置
+type QAConfig struct {
+	Enabled    bool     `yaml:"enabled" json:"enabled"`         // 是否启用 QA 模式
+	MetaIDs    []string `yaml:"meta_ids" json:"meta_ids"`       // 启动时登记的 QA 账号（也可通过管理接口增删）
+	InboxLimit int      `yaml:"inbox_limit" json:"inbox_limit"` // 每个账号收件箱保留的消息数
+}
+
+// QuotaConfig 租户月度推送配额配置
+type QuotaConfig struct {
+	Enabled         bool   `yaml:"enabled" json:"enabled"`                     // 是否启用租户配额
+	DefaultLimit    int64  `yaml:"default_limit" json:"default_limit"`         // 未单独设置配额的租户每月上限，0 表示不限制
+	OverQuotaAction string `yaml:"over_quota_action" json:"over_quota_action"` // 超出配额的处理方式：reject / downgrade
+	WarnPercents    []int  `yaml:"warn_percents" json:"warn_percents"`         // 用量告警阈值（百分比），默认 80、95
 }
 
 // ParsedMessageInfo 解析后的消息信息
 type ParsedMessageInfo struct {
-	PinId        string `json:"pinId"`        // PIN ID
-	GroupId      string `json:"groupId"`      // 群聊ID（群聊消息时使用）
-	MetaId       string `json:"metaId"`       // 私聊的MetaId（私聊消息时使用）
-	ChatType     string `json:"chatType"`     // 聊天类型：private_chat 或 group_chat
-	UserName     string `json:"userName"`     // 用户名
-	ChatInfoType int64  `json:"chatInfoType"` // 聊天信息类型：1/23-红包
+	PinId        string             `json:"pinId"`              // PIN ID
+	GroupId      string             `json:"groupId"`            // 群聊ID（群聊消息时使用）
+	MetaId       string             `json:"metaId"`             // 私聊的MetaId（私聊消息时使用）
+	SenderId     string             `json:"senderId"`           // 消息发送者MetaId（用于屏蔽发送者检查）
+	ChatType     string             `json:"chatType"`           // 聊天类型：private_chat 或 group_chat
+	UserName     string             `json:"userName"`           // 用户名
+	ChatInfoType int64              `json:"chatInfoType"`       // 聊天信息类型：1/23-红包
+	Preview      string             `json:"preview"`            // 消息预览（启用预览且消息未加密时）
+	Encrypted    bool               `json:"encrypted"`          // 消息是否加密
+	CandyBag     *CandyBagInfo      `json:"candyBag,omitempty"` // 红包附加信息（红包消息时使用）
+	Notify       *NotifyMessageInfo `json:"notify,omitempty"`   // 好友请求、付款通知的附加信息
+	ParseError   error              `json:"-"`                  // 严格解析失败的原因（未知字段、类型不匹配等），消息会被隔离备查但仍照常推送
 }
 
 // NewPushCenter 创建推送中心实例
@@ -46,12 +185,44 @@ func NewPushCenter(config *Config) *PushCenter {
 		config.EnabledTypes = []string{"private_chat", "group_chat"}
 	}
 
-	return &PushCenter{
-		socketManager: socket_client_service.NewManager(config.SocketConfig),
-		pushManager:   push_service.NewManager(),
+	// 汇总所有上游 Socket 服务器配置，消息统一进入同一个处理器
+	var socketConfigs []*socket_client_service.Config
+	if config.SocketConfig != nil && config.SocketConfig.ServerURL != "" {
+		socketConfigs = append(socketConfigs, config.SocketConfig)
+	}
+	socketConfigs = append(socketConfigs, config.SocketConfigs...)
+
+	socketManager := socket_client_service.NewMultiManager(socketConfigs)
+	pushManager := push_service.NewManager()
+	pc := &PushCenter{
+		socketManager: socketManager,
+		pushManager:   pushManager,
+		sources:       []MessageSource{socketManager},
+		audience:      MessageAudienceResolver{},
+		dispatcher:    pushManager,
+		messageTypes:  NewMessageTypeSwitch(config.EnabledTypes),
 		config:        config,
 		running:       false,
 	}
+	pc.stages = pc.defaultStages()
+	if pc.summaryEnabled() {
+		summary := config.GroupingConfig.Summary
+		if summary.MinChats < 2 {
+			summary.MinChats = DefaultSummaryMinChats
+		}
+		if summary.Window <= 0 {
+			summary.Window = DefaultSummaryWindow
+		}
+		pc.summaries = newSummaryTracker(summary.Window)
+		pc.AddStage(NewPipelineStage(StageSummary, pc.summaryStage), StageSend)
+	}
+	if pc.actionsEnabled() {
+		pc.AddStage(NewPipelineStage(StageCategory, pc.categoryStage), StageSend)
+	}
+	if pc.ackEnabled() {
+		pc.stages = append(pc.stages, NewPipelineStage(StageAck, pc.ackStage))
+	}
+	return pc
 }
 
 // Initialize 初始化推送中心
@@ -77,13 +248,210 @@ func (pc *PushCenter) Initialize() error {
 		log.Printf("✅ 默认 Pebble 数据库服务已初始化")
 	}
 
-	// 设置推送服务使用 Pebble 令牌存储
+	// 监控数据目录磁盘空间，紧急时跳过非关键写入
+	if pc.config.DiskConfig != nil && pc.config.DiskConfig.Enabled {
+		if err := pc.config.DiskConfig.Validate(); err != nil {
+			log.Printf("❌ 磁盘空间监控配置无效: %v", err)
+			return fmt.Errorf("磁盘空间监控配置无效: %w", err)
+		}
+		dbPath := pebble_service.DefaultConfig().DBPath
+		if pc.config.PebbleConfig != nil && pc.config.PebbleConfig.DBPath != "" {
+			dbPath = pc.config.PebbleConfig.DBPath
+		}
+		pc.diskMonitor = disk_service.NewMonitor(pc.config.DiskConfig, dbPath)
+		pc.diskMonitor.Start()
+	}
+
+	// 设置令牌、屏蔽聊天和已通知 PIN 的存储后端（默认 Pebble，多实例部署可使用 Redis 共享）
 	pebbleTokenStore := pebble_service.NewGlobalPebbleTokenStore()
 	if pebbleTokenStore == nil {
 		return fmt.Errorf("无法创建 Pebble 令牌存储，全局服务未正确初始化")
 	}
-	pc.pushManager.SetTokenStore(pebbleTokenStore)
-	log.Printf("✅ 推送服务已配置使用 Pebble 令牌存储")
+	stores, err := storage_service.NewStores(pc.config.StorageConfig, pebbleTokenStore)
+	if err != nil {
+		log.Printf("❌ 创建存储后端失败: %v", err)
+		return fmt.Errorf("创建存储后端失败: %w", err)
+	}
+	// 部署交接的新旧实例各自打开独立的数据目录，令牌、屏蔽聊天等数据必须通过共享的 Redis 存储后端交接
+	//（定时推送、进件日志和幂等键在交出时通过协调目录交接，见 saveHandoffState）
+	if handoff := pc.config.HandoffConfig; handoff != nil && handoff.Enabled {
+		handoff.ApplyDefaults()
+		if handoff.Mode == handoff_service.ModeHandoff && stores.Backend != storage_service.BackendRedis {
+			stores.Close()
+			log.Printf("❌ 部署交接模式需要共享的存储后端，当前为 %s", stores.Backend)
+			return fmt.Errorf("部署交接模式需要共享的存储后端（storage.backend: redis），当前为 %s", stores.Backend)
+		}
+	}
+	if stores.Backend == storage_service.BackendPebble {
+		if pc.config.TokenCacheSize > 0 {
+			pebbleTokenStore.EnableCache(pc.config.TokenCacheSize, pc.config.TokenCacheTTL)
+		}
+		if pc.warmupEnabled() {
+			pebbleTokenStore.EnableHotUserTracking(pc.config.WarmupConfig.Size)
+		}
+		pc.tokenStore = pebbleTokenStore
+	} else if pc.warmupEnabled() {
+		log.Printf("⚠️ 活跃用户预热仅支持 Pebble 存储后端，已忽略")
+	}
+	if stores.Backend != storage_service.BackendPebble && pc.tokenGCEnabled() {
+		log.Printf("⚠️ 过期令牌清理仅支持 Pebble 存储后端，已忽略")
+	}
+	pc.stores = stores
+	storage_service.SetGlobalStores(stores)
+	pc.pushManager.SetTokenStore(stores.Tokens)
+	push_service.SetGlobalManager(pc.pushManager)
+	socket_client_service.SetGlobalManager(pc.socketManager)
+	log.Printf("✅ 推送服务已配置使用 %s 存储后端", stores.Backend)
+
+	// 设置多实例 PIN 去重协调（多个实例消费同一上游时只有抢占成功的实例推送）
+	pinClaimer, err := dedup_service.NewPinClaimer(pc.config.DedupConfig)
+	if err != nil {
+		log.Printf("❌ 创建 PIN 去重协调器失败: %v", err)
+		return fmt.Errorf("创建 PIN 去重协调器失败: %w", err)
+	}
+	if pinClaimer != nil {
+		pc.pinClaimer = pinClaimer
+		log.Printf("✅ PIN 去重协调已启用: 模式=%s, 实例=%s", pinClaimer.Name(), pc.config.DedupConfig.InstanceID)
+	}
+
+	if err := ValidateCollapseMode(pc.config.CollapseMode); err != nil {
+		log.Printf("❌ %v", err)
+		return err
+	}
+
+	// 设置上游接收用户校验（推送前剔除不属于该聊天的用户）
+	membership, err := newMembershipChecker(pc.config.MembershipConfig)
+	if err != nil {
+		log.Printf("❌ 接收用户校验配置无效: %v", err)
+		return fmt.Errorf("接收用户校验配置无效: %w", err)
+	}
+	if membership != nil {
+		pc.membership = membership
+		log.Printf("🛡️ 上游接收用户校验已启用: 消息类型=%v", pc.config.MembershipConfig.MessageTypes)
+	}
+
+	// 设置断线补拉（上游连接成功后按检查点补拉断线期间的消息）
+	if err := pc.initCatchup(); err != nil {
+		log.Printf("❌ 补拉配置无效: %v", err)
+		return fmt.Errorf("补拉配置无效: %w", err)
+	}
+
+	// 加载通知路由规则（管理接口保存的规则优先于配置文件）
+	if err := pc.loadRoutingRules(); err != nil {
+		log.Printf("❌ %v", err)
+		return err
+	}
+
+	// 加载启用的消息类型（管理接口保存的设置优先于配置文件）
+	if err := pc.loadEnabledTypes(); err != nil {
+		log.Printf("❌ %v", err)
+		return err
+	}
+
+	// 加载推送追踪（管理接口开启的用户追踪）
+	pc.loadUserTraces()
+
+	// 设置幂等键保留时长
+	pebble_service.SetIdempotencyTTL(pc.config.IdempotencyTTL)
+
+	// 设置全局退订名单，已退订的用户不接收任何推送
+	optOut := pebble_service.NewGlobalPebbleOptOutList()
+	if optOut == nil {
+		return fmt.Errorf("无法创建退订名单，全局服务未正确初始化")
+	}
+	pc.pushManager.SetOptOutList(optOut)
+
+	// 设置推送限流器
+	throttler, err := throttle_service.NewThrottler(pc.config.ThrottleConfig)
+	if err != nil {
+		log.Printf("❌ 创建推送限流器失败: %v", err)
+		return fmt.Errorf("创建推送限流器失败: %w", err)
+	}
+	if throttler != nil {
+		pc.pushManager.SetThrottler(throttler)
+		log.Printf("✅ 推送限流已启用: 后端=%s, 每 %v 最多 %d 条", throttler.Name(), pc.config.ThrottleConfig.Window, pc.config.ThrottleConfig.Limit)
+	}
+
+	// 设置 QA 虚拟收件箱
+	if pc.config.QAConfig != nil && pc.config.QAConfig.Enabled {
+		inbox := pebble_service.NewGlobalPebbleQAInbox(pc.config.QAConfig.InboxLimit)
+		if inbox == nil {
+			return fmt.Errorf("无法创建QA收件箱，全局服务未正确初始化")
+		}
+		for _, metaId := range pc.config.QAConfig.MetaIDs {
+			if _, err := pebble_service.SaveQAAccount(metaId, "配置文件登记"); err != nil {
+				log.Printf("⚠️ 登记QA账号 %s 失败: %v", metaId, err)
+			}
+		}
+		pc.pushManager.SetVirtualInbox(inbox)
+		log.Printf("🧪 QA 虚拟收件箱已启用，配置登记账号 %d 个", len(pc.config.QAConfig.MetaIDs))
+	}
+
+	// 设置租户月度推送配额
+	if pc.config.QuotaConfig != nil && pc.config.QuotaConfig.Enabled {
+		quotaConfig := pc.config.QuotaConfig
+		guard := pebble_service.NewGlobalPebbleQuotaGuard(quotaConfig.DefaultLimit, quotaConfig.OverQuotaAction, quotaConfig.WarnPercents)
+		if guard == nil {
+			return fmt.Errorf("无法创建租户配额，全局服务未正确初始化")
+		}
+		pebble_service.SetGlobalQuotaGuard(guard)
+		pc.pushManager.SetQuotaGuard(guard)
+		log.Printf("📊 租户推送配额已启用: 默认每月上限=%d, 超出后=%s", quotaConfig.DefaultLimit, guard.OverQuotaAction())
+	}
+
+	// 设置消息预览翻译
+	if pc.config.TranslationConfig != nil && pc.config.TranslationConfig.Enabled {
+		if !pc.config.PreviewEnabled {
+			log.Printf("⚠️ 消息预览未启用，预览翻译不会生效")
+		} else {
+			translator, err := translate_service.InitializeGlobalService(pc.config.TranslationConfig)
+			if err != nil {
+				log.Printf("❌ 初始化预览翻译服务失败: %v", err)
+				return fmt.Errorf("初始化预览翻译服务失败: %w", err)
+			}
+			if translator != nil {
+				log.Printf("🌐 消息预览翻译已启用: 提供者=%s", pc.config.TranslationConfig.Provider)
+			}
+		}
+	}
+
+	// 设置租户投递事件 Webhook
+	if pc.config.WebhookConfig != nil && pc.config.WebhookConfig.Enabled {
+		pc.webhookDispatcher = webhook_service.InitializeGlobalDispatcher(pc.config.WebhookConfig)
+		pc.pushManager.AddResultListener(pc.webhookDispatcher.HandleResult)
+		log.Printf("✅ 租户投递事件 Webhook 已启用")
+	}
+
+	// 设置外发通知抽样（脱敏后镜像到内部审阅 Webhook）
+	if pc.config.SamplingConfig != nil && pc.config.SamplingConfig.Enabled {
+		if pc.config.SamplingConfig.WebhookURL == "" {
+			return fmt.Errorf("已启用外发通知抽样但未配置审阅 Webhook 地址")
+		}
+		pc.sampler = sampling_service.NewSampler(pc.config.SamplingConfig)
+		pc.pushManager.AddResultListener(pc.sampler.HandleResult)
+	}
+
+	// 推送结果发布到流水线事件总线（管理接口 /v1/admin/events 订阅）
+	pc.pushManager.AddResultListener(publishPushResult)
+
+	// 设置推送审计日志（每条外发通知写入 push_audit 集合，供排查用户未收到推送的原因）
+	if pc.auditEnabled() {
+		pc.auditor = newPushAuditor(pc.config.AuditConfig)
+		pc.pushManager.AddResultListener(pc.auditor.HandleResult)
+	}
+
+	// 创建定时推送调度器
+	pc.scheduler = schedule_service.NewScheduler(pc.config.ScheduleConfig, pc.pushManager)
+
+	// 设置 Pebble 备份（管理接口使用同一备份目录）
+	backup_service.SetGlobalConfig(pc.config.BackupConfig)
+	if pc.config.BackupConfig != nil && pc.config.BackupConfig.Schedule {
+		pc.backupScheduler = backup_service.NewScheduler(pc.config.BackupConfig)
+	}
+
+	// 创建后台任务执行器（管理接口提交的异步任务）
+	pc.jobRunner = job_service.NewRunner(pc.config.JobConfig)
+	job_service.SetGlobalRunner(pc.jobRunner)
 
 	// 设置 socket 连接处理器
 	pc.socketManager.SetConnectHandler(func() {
@@ -100,6 +468,7 @@ func (pc *PushCenter) Initialize() error {
 
 	// 设置聊天消息处理器
 	pc.SetChatMessageHandler()
+	SetGlobalPushCenter(pc)
 
 	log.Printf("✅ 推送中心初始化完成")
 	return nil
@@ -116,10 +485,10 @@ func (pc *PushCenter) Run() error {
 
 	log.Printf("🚀 启动推送中心...")
 
-	// 启动 socket 客户端连接
-	if err := pc.socketManager.Start(); err != nil {
-		log.Printf("❌ 启动 Socket 客户端失败: %v", err)
-		return fmt.Errorf("启动 Socket 客户端失败: %w", err)
+	// 启动消息来源（socket 客户端连接及注册的其他来源）
+	if err := pc.startSources(); err != nil {
+		log.Printf("❌ 启动消息来源失败: %v", err)
+		return fmt.Errorf("启动消息来源失败: %w", err)
 	}
 
 	// 启动推送服务
@@ -128,6 +497,43 @@ func (pc *PushCenter) Run() error {
 		return fmt.Errorf("启动推送服务失败: %w", err)
 	}
 
+	// 启动后台任务执行器
+	pc.jobRunner.Start()
+
+	// 启动租户 Webhook 分发器
+	if pc.webhookDispatcher != nil {
+		pc.webhookDispatcher.Start()
+	}
+
+	// 启动外发通知抽样
+	if pc.sampler != nil {
+		pc.sampler.Start()
+	}
+
+	// 启动推送审计日志写入
+	if pc.auditor != nil {
+		pc.auditor.Start()
+		log.Printf("📝 推送审计日志已启用")
+	}
+
+	if pc.config.HandoffConfig != nil && pc.config.HandoffConfig.Enabled {
+		// 启用部署交接或主备选举：以待命状态启动，拿到主实例锁后才开始消费
+		coordinator, err := handoff_service.NewBackendCoordinator(pc.config.HandoffConfig, handoff_service.Callbacks{
+			Ready:     pc.socketManager.IsRunning,
+			OnAcquire: pc.startConsuming,
+			OnRelease: pc.stopConsuming,
+		})
+		if err != nil {
+			log.Printf("❌ 创建部署交接协调器失败: %v", err)
+			return fmt.Errorf("创建部署交接协调器失败: %w", err)
+		}
+		pc.coordinator = coordinator
+		pc.coordinator.Start()
+		log.Printf("🤝 已启用部署交接（%s），实例 %s 以待命状态启动", pc.coordinator.Mode(), pc.coordinator.InstanceID())
+	} else if err := pc.startConsuming(); err != nil {
+		return err
+	}
+
 	pc.running = true
 	log.Printf("✅ 推送中心已启动，正在监听消息...")
 
@@ -135,6 +541,7 @@ func (pc *PushCenter) Run() error {
 }
 
 // Stop 停止推送中心
+// 各阶段的顺序和超时见 shutdownStages
 func (pc *PushCenter) Stop() error {
 	pc.mu.Lock()
 	defer pc.mu.Unlock()
@@ -144,13 +551,196 @@ func (pc *PushCenter) Stop() error {
 	}
 
 	log.Printf("🛑 正在停止推送中心...")
+	runShutdownStages(pc.shutdownStages())
 
-	// 停止 socket 客户端
-	pc.socketManager.Stop()
+	pc.running = false
+	log.Printf("✅ 推送中心已停止")
 
-	// 停止推送服务
-	if err := pc.pushManager.Stop(); err != nil {
-		log.Printf("⚠️ 停止推送服务时出现错误: %v", err)
+	return nil
+}
+
+// IsRunning 检查推送中心是否正在运行
+func (pc *PushCenter) IsRunning() bool {
+	pc.mu.RLock()
+	defer pc.mu.RUnlock()
+	return pc.running && pc.socketManager.IsRunning()
+}
+
+// startConsuming 开始消费消息：启动调度器等仅主实例运行的任务，并补发待命期间缓存的消息
+func (pc *PushCenter) startConsuming() error {
+	pc.consumeMu.Lock()
+	if pc.consuming {
+		pc.consumeMu.Unlock()
+		return nil
+	}
+
+	// 待命期间其他实例可能修改过令牌，接管后丢弃本地令牌缓存
+	if pc.tokenStore != nil {
+		pc.tokenStore.PurgeCache()
+	}
+
+	// 预加载上次运行时活跃用户的令牌和偏好，避免接管后第一波推送全部读盘
+	if pc.tokenStore != nil && pc.warmupEnabled() {
+		if _, err := pc.tokenStore.WarmUp(context.Background()); err != nil {
+			log.Printf("⚠️ 预热活跃用户失败: %v", err)
+		}
+	}
+
+	// 待命期间其他实例可能记录过已通知 PIN，接管后重建布隆过滤器（构建完成前直接查询存储）
+	if err := pc.stores.MaintainNotifiedPins(context.Background()); err != nil {
+		log.Printf("⚠️ %v", err)
+	}
+
+	// 旧实例交出的定时推送、进件日志和幂等键只保存在其本地数据目录，接管后先导入再开始调度和恢复
+	pc.loadHandoffState()
+
+	// 启动定时推送调度器
+	if pc.scheduler != nil {
+		pc.scheduler.Start()
+	}
+
+	// 启动定时备份（仅主实例）
+	if pc.backupScheduler != nil {
+		pc.backupScheduler.Start()
+	}
+
+	// 启动过期幂等键清理
+	pc.leaderStopCh = make(chan struct{})
+	go pc.idempotencyCleanupLoop(pc.leaderStopCh)
+	if pc.stores.Backend == storage_service.BackendPebble {
+		go pc.notifiedPinMaintenanceLoop(pc.leaderStopCh)
+	}
+	if pc.tokenStore != nil && pc.warmupEnabled() {
+		go pc.hotUsersSaveLoop(pc.leaderStopCh)
+	}
+	if pc.tokenGCEnabled() && pc.stores.Backend == storage_service.BackendPebble {
+		go pc.tokenGCLoop(pc.leaderStopCh)
+	}
+	if translate_service.GetGlobalService() != nil {
+		go pc.translationCleanupLoop(pc.leaderStopCh)
+	}
+	if pc.deliveryTrackingEnabled() {
+		pc.receiptStopCh, pc.receiptDone = make(chan struct{}), make(chan struct{})
+		go pc.deliveryMaintenanceLoop(pc.receiptStopCh, pc.receiptDone)
+	}
+	go pc.pauseResumeLoop(pc.leaderStopCh)
+	go pc.traceCleanupLoop(pc.leaderStopCh)
+	if pc.auditor != nil {
+		go pc.auditCleanupLoop(pc.leaderStopCh)
+	}
+
+	// 取出仍在缓存窗口内的消息，旧实例已处理过的会被幂等键过滤
+	var replay []*socket_client_service.ChatNotificationMessage
+	if len(pc.pending) > 0 {
+		window := pc.config.HandoffConfig.BufferWindow
+		for _, pending := range pc.pending {
+			if time.Since(pending.receivedAt) <= window {
+				replay = append(replay, pending.chatMsg)
+			}
+		}
+		log.Printf("📬 补发待命期间缓存的消息: 缓存=%d, 补发=%d", len(pc.pending), len(replay))
+		pc.pending = nil
+	}
+
+	// 上次运行（或旧实例）未处理完成的进件消息
+	recovered := pc.recoverIntake()
+
+	pc.startChatQueues()
+	pc.consuming = true
+	pc.addInflight(len(recovered) + len(replay))
+	pc.consumeMu.Unlock()
+
+	for _, queued := range recovered {
+		pc.processQueued(queued)
+	}
+	for _, chatMsg := range replay {
+		pc.dispatchMessage(chatMsg)
+	}
+
+	log.Printf("▶️ 推送中心开始消费消息")
+	return nil
+}
+
+// stopConsuming 停止消费消息：等待在途消息处理完成、停止调度器和回执轮询；
+// 本地数据库保持打开（交出后仍可能重新接管）。接管实例通过共享的存储后端读取令牌和屏蔽聊天，
+// 部署交接模式下尚未发送的定时推送、未确认的进件日志和幂等键通过协调目录中的状态文件交给接管实例
+func (pc *PushCenter) stopConsuming() {
+	if !pc.drainConsumers() {
+		return
+	}
+	pc.stopReceiptPoller()
+	pc.saveHandoffState()
+
+	log.Printf("⏸️ 推送中心已停止消费消息")
+}
+
+// drainConsumers 停止接收新消息，等待在途消息和串行队列处理完成，并停止调度器和后台清理任务；
+// 回执轮询不在此停止，返回是否原本正在消费
+func (pc *PushCenter) drainConsumers() bool {
+	pc.consumeMu.Lock()
+	if !pc.consuming {
+		pc.consumeMu.Unlock()
+		return false
+	}
+	pc.consuming = false
+	pc.consumeMu.Unlock()
+
+	// 等待在途消息处理完成
+	pc.inflight.Wait()
+	pc.stopChatQueues()
+
+	// 停止定时推送调度器（等待正在发送的任务完成）
+	if pc.scheduler != nil {
+		pc.scheduler.Stop()
+	}
+
+	// 停止定时备份（等待进行中的备份完成）
+	if pc.backupScheduler != nil {
+		pc.backupScheduler.Stop()
+	}
+
+	// 停止后台清理任务
+	if pc.leaderStopCh != nil {
+		close(pc.leaderStopCh)
+		pc.leaderStopCh = nil
+	}
+	return true
+}
+
+// stopReceiptPoller 停止回执轮询并等待其最后一次查询到期回执完成
+func (pc *PushCenter) stopReceiptPoller() {
+	if pc.receiptStopCh == nil {
+		return
+	}
+	close(pc.receiptStopCh)
+	<-pc.receiptDone
+	pc.receiptStopCh, pc.receiptDone = nil, nil
+}
+
+// closeStorage 保存活跃用户列表并关闭存储后端、PIN 去重协调器、磁盘监控和 Pebble 服务
+func (pc *PushCenter) closeStorage() {
+	// 保存活跃用户列表，供下次启动预热
+	if pc.tokenStore != nil && pc.warmupEnabled() {
+		pc.saveHotUsers()
+	}
+
+	// 关闭存储后端连接
+	if pc.stores != nil {
+		if err := pc.stores.Close(); err != nil {
+			log.Printf("⚠️ 关闭存储后端时出现错误: %v", err)
+		}
+	}
+
+	// 关闭 PIN 去重协调器连接
+	if closer, ok := pc.pinClaimer.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			log.Printf("⚠️ 关闭 PIN 去重协调器时出现错误: %v", err)
+		}
+	}
+
+	// 停止磁盘空间监控
+	if pc.diskMonitor != nil {
+		pc.diskMonitor.Stop()
 	}
 
 	// 关闭 Pebble 服务
@@ -159,18 +749,35 @@ func (pc *PushCenter) Stop() error {
 	} else {
 		log.Printf("✅ Pebble 数据库服务已关闭")
 	}
+}
 
-	pc.running = false
-	log.Printf("✅ 推送中心已停止")
+// acceptMessage 判断消息是否立即处理；待命期间将消息加入缓存
+func (pc *PushCenter) acceptMessage(chatMsg *socket_client_service.ChatNotificationMessage) bool {
+	pc.consumeMu.Lock()
+	defer pc.consumeMu.Unlock()
 
-	return nil
+	if pc.consuming {
+		pc.addInflight(1)
+		return true
+	}
+	if pc.coordinator == nil {
+		return false
+	}
+
+	// 超出缓存上限时丢弃最旧的消息
+	if len(pc.pending) >= pc.config.HandoffConfig.BufferSize {
+		pc.pending = pc.pending[1:]
+	}
+	pc.pending = append(pc.pending, &pendingMessage{
+		chatMsg:    chatMsg,
+		receivedAt: time.Now(),
+	})
+	return false
 }
 
-// IsRunning 检查推送中心是否正在运行
-func (pc *PushCenter) IsRunning() bool {
-	pc.mu.RLock()
-	defer pc.mu.RUnlock()
-	return pc.running && pc.socketManager.IsRunning()
+// GetUpstreamHealth 获取所有上游 Socket 连接的健康状态
+func (pc *PushCenter) GetUpstreamHealth() []socket_client_service.UpstreamHealth {
+	return pc.socketManager.GetUpstreamHealth()
 }
 
 // GetPushManager 获取推送服务管理器
@@ -178,76 +785,234 @@ func (pc *PushCenter) GetPushManager() *push_service.Manager {
 	return pc.pushManager
 }
 
-// SetChatMessageHandler 设置聊天消息处理器
+// SetChatMessageHandler 为所有消息来源设置聊天消息处理器
 func (pc *PushCenter) SetChatMessageHandler() {
-	pc.socketManager.SetChatMessageHandler(func(chatMsg *socket_client_service.ChatNotificationMessage) {
-		if chatMsg == nil || chatMsg.Data == nil {
-			log.Printf("⚠️ 收到空的聊天消息")
-			return
-		}
+	for _, source := range pc.sources {
+		source.SetChatMessageHandler(pc.HandleMessage)
+	}
+}
 
-		log.Printf("📨 收到聊天消息: Type=%s", chatMsg.Type)
+// HandleMessage 接收一条聊天消息：检查类型，待命期间缓存，否则异步处理并推送
+// 消息来源收到消息后调用，也可由其他入口（如 HTTP 接入）直接调用
+func (pc *PushCenter) HandleMessage(chatMsg *socket_client_service.ChatNotificationMessage) {
+	if chatMsg == nil || chatMsg.Data == nil {
+		log.Printf("⚠️ 收到空的聊天消息")
+		return
+	}
+	pc.recordCheckpoint(chatMsg)
 
-		// 检查消息类型是否启用
-		if !pc.isMessageTypeEnabled(chatMsg.Type) {
-			log.Printf("⚠️ 消息类型 %s 未启用，跳过处理", chatMsg.Type)
-			return
-		}
+	log.Printf("📨 收到聊天消息: Type=%s", chatMsg.Type)
 
-		// 处理聊天消息并转发推送
-		go pc.processChatMessage(chatMsg)
-	})
+	// 检查消息类型是否启用
+	if !pc.isMessageTypeEnabled(chatMsg.Type) {
+		log.Printf("⚠️ 消息类型 %s 未启用，跳过处理", chatMsg.Type)
+		return
+	}
+
+	// 待命期间缓存消息，接管后补发
+	if !pc.acceptMessage(chatMsg) {
+		log.Printf("⏸️ 当前实例未消费消息，已缓存待接管后处理")
+		return
+	}
+
+	// 写入进件日志后处理聊天消息并转发推送
+	pc.dispatchMessage(chatMsg)
 }
 
 // isMessageTypeEnabled 检查消息类型是否启用
 func (pc *PushCenter) isMessageTypeEnabled(msgType string) bool {
-	for _, enabledType := range pc.config.EnabledTypes {
-		if enabledType == msgType {
-			return true
+	if pc.messageTypes != nil {
+		return pc.messageTypes.Enabled(msgType)
+	}
+	return slices.Contains(pc.config.EnabledTypes, msgType)
+}
+
+// SupportedMessageTypes 推送中心支持的消息类型
+var SupportedMessageTypes = []string{
+	socket_client_service.MessageTypePrivateChat,
+	socket_client_service.MessageTypeGroupChat,
+	socket_client_service.MessageTypeFriendRequest,
+	socket_client_service.MessageTypePayment,
+}
+
+// ValidateMessageTypes 校验启用的消息类型
+func ValidateMessageTypes(types []string) error {
+	for _, msgType := range types {
+		if !slices.Contains(SupportedMessageTypes, msgType) {
+			return fmt.Errorf("不支持的消息类型: %s（可选 %s）", msgType, strings.Join(SupportedMessageTypes, "/"))
 		}
 	}
-	return false
+	return nil
 }
 
-// processChatMessage 处理聊天消息
-func (pc *PushCenter) processChatMessage(chatMsg *socket_client_service.ChatNotificationMessage) {
+// processChatMessage 处理聊天消息（包括好友请求、付款通知）；返回错误表示存储等临时故障导致未能推送，消息可稍后重新处理
+func (pc *PushCenter) processChatMessage(chatMsg *socket_client_service.ChatNotificationMessage) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	// 解析消息信息，获取 pinId、groupId 和私聊的 metaId
+	// 每条消息一个 trace：解析、各流水线环节、令牌查询和推送平台调用都是其子 span
+	ctx, span := tracing_service.Start(ctx, "socket.message", attribute.String("message.type", chatMsg.Type))
+	defer span.End()
+	if traceID := tracing_service.TraceID(ctx); traceID != "" {
+		log.Printf("🧵 开始处理消息: type=%s, traceId=%s", chatMsg.Type, traceID)
+	}
+
+	err := pc.handleChatMessage(ctx, chatMsg)
+	tracing_service.RecordError(span, err)
+	return err
+}
+
+// handleChatMessage 解析消息和接收用户后交给流水线处理
+func (pc *PushCenter) handleChatMessage(ctx context.Context, chatMsg *socket_client_service.ChatNotificationMessage) error {
+	// 解析消息信息，获取 pinId、groupId 和私聊的 metaId；结构校验失败的消息隔离后不推送
+	_, parseSpan := tracing_service.Start(ctx, "parse")
+	if err := validateMessage(chatMsg); err != nil {
+		tracing_service.RecordError(parseSpan, err)
+		parseSpan.End()
+		log.Printf("❌ %v，消息已隔离", err)
+		pc.quarantineMessage(chatMsg, err)
+		return nil
+	}
 	parsedInfo, err := pc.parseMessageInfo(chatMsg)
 	if err != nil {
+		tracing_service.RecordError(parseSpan, err)
+		parseSpan.End()
 		log.Printf("❌ 解析消息信息失败: %v", err)
-		return
+		return nil
+	}
+	parseSpan.SetAttributes(attribute.String("chat.type", parsedInfo.ChatType), attribute.String("pin.id", parsedInfo.PinId))
+	tracing_service.RecordError(parseSpan, parsedInfo.ParseError)
+	parseSpan.End()
+	if parsedInfo.ParseError != nil {
+		pc.quarantineMessage(chatMsg, parsedInfo.ParseError)
 	}
 
+	// 解析接收用户（默认合并 RepostMetaIds/RepostGlobalMetaIds 和 MentionMetaIds/MentionGlobalMetaIds）
+	audience, err := pc.audience.Resolve(ctx, chatMsg, parsedInfo)
+	if err != nil {
+		return fmt.Errorf("解析接收用户失败: %w", err)
+	}
+	if audience, err = pc.verifyAudience(ctx, chatMsg, parsedInfo, audience); err != nil {
+		return err
+	}
+
+	publishMessageReceived(chatMsg, parsedInfo, audience)
+
+	// 屏蔽检查与 Dedup 环节的去重检查并发进行，之后依次经过流水线各环节
+	return pc.runPipeline(ctx, pc.newPipelineMessage(chatMsg, parsedInfo, audience))
+}
+
+// buildIdempotencyKey 生成消息幂等键
+func (pc *PushCenter) buildIdempotencyKey(chatMsg *socket_client_service.ChatNotificationMessage, parsedInfo *ParsedMessageInfo) (string, error) {
+	// 好友请求、付款通知按请求ID、交易ID去重
+	if isNotifyMessageType(chatMsg.Type) {
+		if info, err := parseNotifyMessage(chatMsg); err == nil && info.ID != "" {
+			return chatMsg.Type + ":" + info.ID, nil
+		}
+	}
 	if parsedInfo.PinId != "" {
-		isNotified, err := pebble_service.IsNotifiedPin(parsedInfo.PinId)
-		if err != nil {
-			log.Printf("❌ 检查PIN通知状态失败: %v", err)
+		return "pin:" + parsedInfo.PinId, nil
+	}
+
+	// 没有 PinId 时对消息内容做哈希（map 序列化时键有序，结果稳定）
+	content, err := json.Marshal(struct {
+		Type                 string      `json:"type"`
+		Message              interface{} `json:"message"`
+		RepostMetaIds        []string    `json:"repostMetaIds"`
+		MentionMetaIds       []string    `json:"mentionMetaIds"`
+		RepostGlobalMetaIds  []string    `json:"repostGlobalMetaIds"`
+		MentionGlobalMetaIds []string    `json:"mentionGlobalMetaIds"`
+	}{
+		Type:                 chatMsg.Type,
+		Message:              chatMsg.Data.Message,
+		RepostMetaIds:        chatMsg.Data.RepostMetaIds,
+		MentionMetaIds:       chatMsg.Data.MentionMetaIds,
+		RepostGlobalMetaIds:  chatMsg.Data.RepostGlobalMetaIds,
+		MentionGlobalMetaIds: chatMsg.Data.MentionGlobalMetaIds,
+	})
+	if err != nil {
+		return "", fmt.Errorf("序列化消息内容失败: %w", err)
+	}
+
+	sum := sha256.Sum256(content)
+	return "msg:" + hex.EncodeToString(sum[:]), nil
+}
+
+// idempotencyCleanupLoop 定期清理过期的幂等键
+func (pc *PushCenter) idempotencyCleanupLoop(stopCh chan struct{}) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stopCh:
 			return
+		case <-ticker.C:
+			count, err := pebble_service.PurgeExpiredIdempotencyKeys()
+			if err != nil {
+				log.Printf("⚠️ 清理过期幂等键失败: %v", err)
+			} else if count > 0 {
+				log.Printf("🧹 已清理 %d 个过期幂等键", count)
+			}
 		}
-		if isNotified {
-			log.Printf("📌 PIN已通知，跳过推送")
+	}
+}
+
+// notifiedPinMaintenanceLoop 定期清理超过保留时长的已通知 PIN 并重建布隆过滤器
+func (pc *PushCenter) notifiedPinMaintenanceLoop(stopCh chan struct{}) {
+	interval := storage_service.DefaultConfig().PinFilter.RebuildInterval
+	if pc.config.StorageConfig != nil && pc.config.StorageConfig.PinFilter.RebuildInterval > 0 {
+		interval = pc.config.StorageConfig.PinFilter.RebuildInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stopCh:
 			return
+		case <-ticker.C:
+			if err := pc.stores.MaintainNotifiedPins(context.Background()); err != nil {
+				log.Printf("⚠️ %v", err)
+			}
 		}
 	}
+}
 
-	// 合并 RepostMetaIds 和 RepostGlobalMetaIds
-	repostUserIds := pc.mergeUserIds(chatMsg.Data.RepostMetaIds, chatMsg.Data.RepostGlobalMetaIds)
-	if len(repostUserIds) == 0 {
-		log.Printf("⚠️ 没有需要推送的用户ID")
-		return
+// warmupEnabled 是否启用活跃用户令牌预热
+func (pc *PushCenter) warmupEnabled() bool {
+	return pc.config.WarmupConfig != nil && pc.config.WarmupConfig.Enabled
+}
+
+// hotUsersSaveLoop 定期保存活跃用户列表
+func (pc *PushCenter) hotUsersSaveLoop(stopCh chan struct{}) {
+	interval := pc.config.WarmupConfig.SaveInterval
+	if interval <= 0 {
+		interval = DefaultHotUsersSaveInterval
 	}
 
-	// 合并 MentionMetaIds 和 MentionGlobalMetaIds
-	mentionUserIds := pc.mergeUserIds(chatMsg.Data.MentionMetaIds, chatMsg.Data.MentionGlobalMetaIds)
-	if len(mentionUserIds) > 0 {
-		log.Printf("📝 合并后的提及用户ID: %+v", mentionUserIds)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+			pc.saveHotUsers()
+		}
 	}
+}
 
-	// 处理用户推送逻辑
-	pc.processUserPush(ctx, repostUserIds, mentionUserIds, chatMsg, parsedInfo)
+// saveHotUsers 保存活跃用户列表
+func (pc *PushCenter) saveHotUsers() {
+	count, err := pc.tokenStore.SaveHotUsers()
+	if err != nil {
+		log.Printf("⚠️ 保存活跃用户列表失败: %v", err)
+	} else if count > 0 {
+		log.Printf("💾 已保存 %d 个活跃用户", count)
+	}
 }
 
 // generateNotificationTitle 生成通知标题
@@ -275,8 +1040,20 @@ func (pc *PushCenter) generateNotificationTitle(msgType string, isMention bool)
 	}
 }
 
-// GenerateNotificationBody 生成通知内容
-func (pc *PushCenter) GenerateNotificationBody(msgType, userName string, chatInfoType int64, isMention bool, groupId string) string {
+// GenerateNotificationBody 生成通知内容，hideContent 为 true 时返回不含发送者和内容的通用文案；
+// preview 不为空时（开启 notification.preview_enabled 的未加密消息）通知内容带消息预览，红包消息保持原有文案
+func (pc *PushCenter) GenerateNotificationBody(msgType, userName string, chatInfoType int64, isMention bool, groupId string, hideContent bool, preview string) string {
+	if hideContent {
+		if isMention {
+			return "New mention"
+		}
+		return "New message"
+	}
+
+	if preview != "" && !isCandyBag(chatInfoType) {
+		return pc.previewBody(userName, preview, isMention)
+	}
+
 	if isMention {
 		// 提及消息的内容（参考 Telegram 的提及消息格式）
 		truncatedName := pc.truncateUserName(userName)
@@ -339,205 +1116,101 @@ func (pc *PushCenter) GenerateNotificationBody(msgType, userName string, chatInf
 	}
 }
 
-// truncateUserName 截取用户名，参考 Telegram 的处理方式
-func (pc *PushCenter) truncateUserName(userName string) string {
-	if userName == "" {
-		return userName
-	}
-
-	// Telegram 通常将用户名限制在 20-25 个字符左右
-	// 考虑到通知的显示空间，我们设置为 20 个字符
-	const maxLength = 20
-
-	if len(userName) <= maxLength {
-		return userName
+// parseMessageInfo 解析 ExtraServiceMessage.Message 获取 pinId、groupId 和私聊的 metaId，好友请求、付款通知解析到 Notify
+func (pc *PushCenter) parseMessageInfo(chatMsg *socket_client_service.ChatNotificationMessage) (*ParsedMessageInfo, error) {
+	if chatMsg == nil || chatMsg.Data == nil || chatMsg.Data.Message == nil {
+		return nil, fmt.Errorf("聊天消息或消息内容为空")
 	}
 
-	// 截取到 maxLength-3 个字符，然后添加 "..."
-	// 这样总长度不会超过 maxLength
-	truncated := userName[:maxLength-3] + "..."
-	return truncated
-}
-
-// extractMessageContent 提取消息内容
-func (pc *PushCenter) extractMessageContent(message interface{}) string {
-	if message == nil {
-		return ""
+	parsedInfo := &ParsedMessageInfo{
+		ChatType: chatMsg.Type,
 	}
 
-	// 尝试转换为字符串
-	if msgStr, ok := message.(string); ok {
-		// 限制消息长度，避免推送内容过长
-		if len(msgStr) > 100 {
-			return msgStr[:100] + "..."
+	// 按聊天类型解析为 PrivateChatItem / GroupChatItem，严格解析失败时记录 ParseError，
+	// 已解析出的字段照常使用
+	var (
+		userInfo   *socket_client_service.UserInfo
+		content    string
+		encryption string
+		candyBag   socket_client_service.CandyBagFields
+	)
+	switch chatMsg.Type {
+	case socket_client_service.MessageTypePrivateChat:
+		item, err := socket_client_service.DecodePrivateChatItem(chatMsg.Data.Message)
+		parsedInfo.ParseError = err
+		parsedInfo.PinId = item.PinId
+		// 私聊的 metaId 依次取消息创建者、发送者、接收者
+		parsedInfo.MetaId = firstNonEmpty(item.MetaId, item.From, item.To)
+		parsedInfo.SenderId = firstNonEmpty(item.MetaId, item.From)
+		userInfo, content, encryption, candyBag = item.UserInfo, item.Content, item.Encryption, item.CandyBagFields
+
+	case socket_client_service.MessageTypeGroupChat:
+		item, err := socket_client_service.DecodeGroupChatItem(chatMsg.Data.Message)
+		parsedInfo.ParseError = err
+		parsedInfo.PinId = item.PinId
+		parsedInfo.GroupId = firstNonEmpty(item.GroupId, item.ChannelId)
+		parsedInfo.SenderId = item.MetaId
+		parsedInfo.ChatInfoType = item.ChatType
+		userInfo, content, encryption, candyBag = item.UserInfo, item.Content, item.Encryption, item.CandyBagFields
+
+	case socket_client_service.MessageTypeFriendRequest, socket_client_service.MessageTypePayment:
+		info, err := parseNotifyMessage(chatMsg)
+		if err != nil {
+			return nil, err
 		}
-		return msgStr
-	}
+		parsedInfo.Notify = info
+		parsedInfo.PinId = info.PinId
+		parsedInfo.MetaId = info.FromMetaId
+		parsedInfo.SenderId = info.FromMetaId
+		parsedInfo.UserName = info.FromName
+		return parsedInfo, nil
 
-	// 尝试解析为 JSON 并提取文本内容
-	if msgMap, ok := message.(map[string]interface{}); ok {
-		if text, exists := msgMap["text"]; exists {
-			if textStr, ok := text.(string); ok {
-				if len(textStr) > 100 {
-					return textStr[:100] + "..."
-				}
-				return textStr
-			}
-		}
-		if content, exists := msgMap["content"]; exists {
-			if contentStr, ok := content.(string); ok {
-				if len(contentStr) > 100 {
-					return contentStr[:100] + "..."
-				}
-				return contentStr
-			}
-		}
+	default:
+		return parsedInfo, nil
 	}
 
-	// 尝试 JSON 序列化
-	if jsonBytes, err := json.Marshal(message); err == nil {
-		jsonStr := string(jsonBytes)
-		if len(jsonStr) > 100 {
-			return jsonStr[:100] + "..."
-		}
-		return jsonStr
+	if parsedInfo.ParseError != nil {
+		log.Printf("⚠️ 消息严格解析失败，使用已解析的字段: ChatType=%s, 错误: %v", parsedInfo.ChatType, parsedInfo.ParseError)
 	}
-
-	return ""
-}
-
-// parseMessageInfo 解析 ExtraServiceMessage.Message 获取 pinId、groupId 和私聊的 metaId
-func (pc *PushCenter) parseMessageInfo(chatMsg *socket_client_service.ChatNotificationMessage) (*ParsedMessageInfo, error) {
-	if chatMsg == nil || chatMsg.Data == nil || chatMsg.Data.Message == nil {
-		return nil, fmt.Errorf("聊天消息或消息内容为空")
+	if userInfo != nil {
+		parsedInfo.UserName = userInfo.Name
 	}
 
-	parsedInfo := &ParsedMessageInfo{
-		ChatType:     chatMsg.Type,
-		PinId:        "", // 从 ExtraServiceMessage 直接获取 PinId
-		ChatInfoType: 0,
+	// 提取消息预览
+	parsedInfo.Encrypted = isEncryptedMessage(encryption)
+	if pc.config.PreviewEnabled {
+		parsedInfo.Preview = pc.extractPreview(content, encryption)
 	}
 
-	// 尝试解析 Message 字段
-	message := chatMsg.Data.Message
+	// 提取红包金额和领取截止时间
+	if isCandyBag(parsedInfo.ChatInfoType) {
+		parsedInfo.CandyBag = parseCandyBag(candyBag)
+	}
 
-	// 方法1: 如果是 map 格式，直接解析
-	if messageMap, ok := message.(map[string]interface{}); ok {
-		// 解析 pinId（如果 Message 中有的话，会覆盖 ExtraServiceMessage 中的 PinId）
-		if pinId, exists := messageMap["pinId"]; exists {
-			if pinIdStr, ok := pinId.(string); ok {
-				parsedInfo.PinId = pinIdStr
-			}
-		}
+	log.Printf("📋 解析消息信息成功: PinId=%s, GroupId=%s, MetaId=%s, UserName=%s, ChatType=%s, ChatInfoType=%d",
+		parsedInfo.PinId, parsedInfo.GroupId, parsedInfo.MetaId, parsedInfo.UserName, parsedInfo.ChatType, parsedInfo.ChatInfoType)
+	return parsedInfo, nil
+}
 
-		// 解析 userInfo.name
-		if userInfo, exists := messageMap["userInfo"]; exists {
-			if userInfoMap, ok := userInfo.(map[string]interface{}); ok {
-				if name, exists := userInfoMap["name"]; exists {
-					if nameStr, ok := name.(string); ok {
-						parsedInfo.UserName = nameStr
-					}
-				}
-			}
+// firstNonEmpty 返回第一个非空字符串
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
 		}
-
-		// 根据聊天类型解析不同的字段
-		switch chatMsg.Type {
-		case "private_chat":
-			// 私聊消息：解析 metaId（发送者或接收者）
-			if metaId, exists := messageMap["metaId"]; exists {
-				if metaIdStr, ok := metaId.(string); ok {
-					parsedInfo.MetaId = metaIdStr
-				}
-			}
-			// 如果没有 metaId，尝试从 from 或 to 字段获取
-			if parsedInfo.MetaId == "" {
-				if from, exists := messageMap["from"]; exists {
-					if fromStr, ok := from.(string); ok {
-						parsedInfo.MetaId = fromStr
-					}
-				}
-			}
-			if parsedInfo.MetaId == "" {
-				if to, exists := messageMap["to"]; exists {
-					if toStr, ok := to.(string); ok {
-						parsedInfo.MetaId = toStr
-					}
-				}
-			}
-
-		case "group_chat":
-			// 群聊消息：解析 groupId
-			if groupId, exists := messageMap["groupId"]; exists {
-				if groupIdStr, ok := groupId.(string); ok {
-					parsedInfo.GroupId = groupIdStr
-				}
-			}
-			// 如果没有 groupId，尝试从其他可能的字段获取
-			if parsedInfo.GroupId == "" {
-				if channelId, exists := messageMap["channelId"]; exists {
-					if channelIdStr, ok := channelId.(string); ok {
-						parsedInfo.GroupId = channelIdStr
-					}
-				}
-			}
-
-			fmt.Printf("messageMap: %+v\n", messageMap)
-			if chatInfoType, exists := messageMap["chatType"]; exists {
-				// 尝试多种数字类型转换
-				switch v := chatInfoType.(type) {
-				case int64:
-					parsedInfo.ChatInfoType = v
-				case int:
-					parsedInfo.ChatInfoType = int64(v)
-				case float64:
-					parsedInfo.ChatInfoType = int64(v)
-				case int32:
-					parsedInfo.ChatInfoType = int64(v)
-				case int16:
-					parsedInfo.ChatInfoType = int64(v)
-				case int8:
-					parsedInfo.ChatInfoType = int64(v)
-				default:
-					log.Printf("⚠️ 无法转换 chatType 类型: %T, 值: %v", v, v)
-				}
-			}
-		}
-
-		log.Printf("📋 解析消息信息成功: PinId=%s, GroupId=%s, MetaId=%s, UserName=%s, ChatType=%s, ChatInfoType=%d",
-			parsedInfo.PinId, parsedInfo.GroupId, parsedInfo.MetaId, parsedInfo.UserName, parsedInfo.ChatType, parsedInfo.ChatInfoType)
-		return parsedInfo, nil
 	}
-
-	// // 方法2: 如果是字符串，尝试 JSON 解析
-	// if messageStr, ok := message.(string); ok {
-	// 	var messageMap map[string]interface{}
-	// 	if err := json.Unmarshal([]byte(messageStr), &messageMap); err == nil {
-	// 		// 递归调用，使用解析后的 map
-	// 		tempChatMsg := &socket_client_service.ChatNotificationMessage{
-	// 			Type: chatMsg.Type,
-	// 			Data: &socket_client_service.ExtraServiceMessage{
-	// 				Message:       messageMap,
-	// 				RepostMetaIds: chatMsg.Data.RepostMetaIds,
-	// 			},
-	// 		}
-	// 		return pc.parseMessageInfo(tempChatMsg)
-	// 	}
-	// }
-
-	// 方法3: 如果无法解析，返回基本信息
-	log.Printf("⚠️ 无法解析消息内容，使用基本信息: PinId=%s, ChatType=%s", parsedInfo.PinId, parsedInfo.ChatType)
-	return parsedInfo, nil
+	return ""
 }
 
-// mergeUserIds 合并 metaIds 和 globalMetaIds 列表并去重
-func (pc *PushCenter) mergeUserIds(metaIds, globalMetaIds []string) []string {
+// mergeUserIds 合并 metaIds 和 globalMetaIds 列表并去重，忽略 ID 前后的空白和空 ID
+func mergeUserIds(metaIds, globalMetaIds []string) []string {
 	// 使用 map 来去重
 	userMap := make(map[string]bool)
 	var merged []string
 
 	// 添加 metaIds
 	for _, id := range metaIds {
+		id = strings.TrimSpace(id)
 		if id != "" && !userMap[id] {
 			userMap[id] = true
 			merged = append(merged, id)
@@ -546,6 +1219,7 @@ func (pc *PushCenter) mergeUserIds(metaIds, globalMetaIds []string) []string {
 
 	// 添加 globalMetaIds
 	for _, id := range globalMetaIds {
+		id = strings.TrimSpace(id)
 		if id != "" && !userMap[id] {
 			userMap[id] = true
 			merged = append(merged, id)
@@ -555,163 +1229,106 @@ func (pc *PushCenter) mergeUserIds(metaIds, globalMetaIds []string) []string {
 	return merged
 }
 
-// processUserPush 处理用户推送逻辑（支持 metaId 和 globalMetaId）
-func (pc *PushCenter) processUserPush(ctx context.Context, repostUserIds []string, mentionUserIds []string, chatMsg *socket_client_service.ChatNotificationMessage, parsedInfo *ParsedMessageInfo) {
-	// 过滤掉已屏蔽该聊天的用户
-	filteredMetaIds := pc.filterBlockedUsers(repostUserIds, parsedInfo)
-	// if len(filteredMetaIds) == 0 {
-	// 	log.Printf("⚠️ 所有用户都已屏蔽该聊天，跳过推送")
-	// 	return
-	// }
+// blockedCheckConcurrency 并发检查用户屏蔽状态的最大协程数
+const blockedCheckConcurrency = 16
 
-	// 将用户分为两组：被提及的用户和普通用户
-	var mentionedUsers []string
-	var normalUsers []string
-	mentionedUsers = mentionUserIds
-
-	// filteredMetaIds里面去重mentionUserIds,如果有重复的，则只保留一个
-	for _, metaId := range filteredMetaIds {
-		if !slices.Contains(mentionUserIds, metaId) {
-			normalUsers = append(normalUsers, metaId)
-		}
+// messageChatID 消息所属聊天的ID，与屏蔽聊天、聊天通知声音中的 chatId 对应：
+// 私聊使用私聊的metaId，群聊使用groupId，其他消息返回空字符串
+func messageChatID(parsedInfo *ParsedMessageInfo) string {
+	switch parsedInfo.ChatType {
+	case "private_chat":
+		return parsedInfo.MetaId
+	case "group_chat":
+		return parsedInfo.GroupId
+	default:
+		return ""
 	}
+}
 
-	// 为被提及的用户生成通知（参考 Telegram 的提及消息格式）
-	if len(mentionedUsers) > 0 {
-		mentionTitle := pc.generateNotificationTitle(chatMsg.Type, true)
-		mentionBody := pc.GenerateNotificationBody(chatMsg.Type, parsedInfo.UserName, parsedInfo.ChatInfoType, true, parsedInfo.GroupId)
-
-		// 构造提及消息的自定义数据
-		mentionData := map[string]interface{}{
-			"type":      chatMsg.Type,
-			"message":   chatMsg.Data.Message,
-			"timestamp": time.Now().Unix(),
-			"pinId":     parsedInfo.PinId,
-			"isMention": true,
-		}
-
-		// 根据聊天类型添加特定信息
-		if parsedInfo.ChatType == "private_chat" && parsedInfo.MetaId != "" {
-			mentionData["metaId"] = parsedInfo.MetaId
-		} else if parsedInfo.ChatType == "group_chat" && parsedInfo.GroupId != "" {
-			mentionData["groupId"] = parsedInfo.GroupId
-		}
-
-		log.Printf("🔔 开始推送提及消息给 %d 个用户", len(mentionedUsers))
-		mentionResult, err := pc.pushManager.SendToUsersWithData(ctx, mentionedUsers, mentionTitle, mentionBody, mentionData)
-		if err != nil {
-			log.Printf("❌ 推送提及消息失败: %v", err)
-		} else {
-			log.Printf("✅ 提及消息推送完成: 总用户=%d, 成功=%d, 失败=%d, 耗时=%v",
-				mentionResult.TotalUsers, mentionResult.SuccessCount, mentionResult.FailureCount, mentionResult.Duration)
-		}
+// filterBlockedUsers 过滤掉已屏蔽该聊天、所有群聊或该发送者的用户，各用户的屏蔽状态并发检查，结果保持原有顺序
+func (pc *PushCenter) filterBlockedUsers(metaIds []string, parsedInfo *ParsedMessageInfo) []string {
+	if len(metaIds) == 0 {
+		return metaIds
 	}
 
-	// 为普通用户生成通知
-	if len(normalUsers) > 0 {
-		title := pc.generateNotificationTitle(chatMsg.Type, false)
-		body := pc.GenerateNotificationBody(chatMsg.Type, parsedInfo.UserName, parsedInfo.ChatInfoType, false, "")
+	// 确定要检查的聊天ID
+	chatID := messageChatID(parsedInfo)
 
-		// 构造自定义数据，包含解析后的信息
-		normalData := map[string]interface{}{
-			"type":      chatMsg.Type,
-			"message":   chatMsg.Data.Message,
-			"timestamp": time.Now().Unix(),
-			"pinId":     parsedInfo.PinId,
-		}
+	// 既没有聊天ID也没有发送者时，跳过屏蔽检查（好友请求、付款通知没有聊天ID，只检查发送者）
+	if chatID == "" && parsedInfo.SenderId == "" {
+		return metaIds
+	}
 
-		// 根据聊天类型添加特定信息
-		if parsedInfo.ChatType == "private_chat" && parsedInfo.MetaId != "" {
-			normalData["metaId"] = parsedInfo.MetaId
-			log.Printf("📱 私聊消息 - 发送者/接收者MetaId: %s, 用户名: %s", parsedInfo.MetaId, parsedInfo.UserName)
-		} else if parsedInfo.ChatType == "group_chat" && parsedInfo.GroupId != "" {
-			normalData["groupId"] = parsedInfo.GroupId
-			log.Printf("👥 群聊消息 - 群组ID: %s, 用户名: %s", parsedInfo.GroupId, parsedInfo.UserName)
+	blocked := make([]bool, len(metaIds))
+	semaphore := make(chan struct{}, blockedCheckConcurrency)
+	var wg sync.WaitGroup
+	for i, metaId := range metaIds {
+		// 私聊时自己不用给自己推送
+		if parsedInfo.ChatType == "private_chat" && metaId == chatID {
+			blocked[i] = true
+			continue
 		}
 
-		log.Printf("🚀 开始推送普通消息给 %d 个用户", len(normalUsers))
-		log.Printf("📋 消息详情 - PinId: %s, ChatType: %s, UserName: %s", parsedInfo.PinId, parsedInfo.ChatType, parsedInfo.UserName)
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(i int, metaId string) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
 
-		// 调用 push_service.SendToUsers 发送推送
-		normalResult, err := pc.pushManager.SendToUsersWithData(ctx, normalUsers, title, body, normalData)
-		if err != nil {
-			log.Printf("❌ 推送普通消息失败: %v", err)
-		} else {
-			// 记录推送结果
-			log.Printf("✅ 普通消息推送完成: 总用户=%d, 成功=%d, 失败=%d, 耗时=%v",
-				normalResult.TotalUsers, normalResult.SuccessCount, normalResult.FailureCount, normalResult.Duration)
-
-			// 如果有失败的推送，记录详细信息
-			if normalResult.FailureCount > 0 {
-				for _, pushResult := range normalResult.Results {
-					if !pushResult.Success && pushResult.Error != nil {
-						log.Printf("⚠️ 推送失败 - 用户: %s, 平台: %s, 错误: %v",
-							pushResult.MetaID, pushResult.Platform, pushResult.Error)
-					}
-				}
+			if reason := userBlockReason(metaId, chatID, parsedInfo); reason != "" {
+				log.Printf("🚫 用户 %s %s，跳过推送", metaId, reason)
+				blocked[i] = true
 			}
-		}
-	}
-
-	// 添加已通知PIN记录（使用解析后的 PinId）
-	if parsedInfo.PinId != "" {
-		go pebble_service.AddNotifiedPin(parsedInfo.PinId)
-		log.Printf("📌 已记录PIN通知状态: %s", parsedInfo.PinId)
-	} else {
-		log.Printf("⚠️ PinId为空，跳过PIN通知记录")
-	}
-}
-
-// filterBlockedUsers 过滤掉已屏蔽该聊天的用户
-func (pc *PushCenter) filterBlockedUsers(metaIds []string, parsedInfo *ParsedMessageInfo) []string {
-	if len(metaIds) == 0 {
-		return metaIds
+		}(i, metaId)
 	}
+	wg.Wait()
 
 	var filteredMetaIds []string
 	blockedCount := 0
-
-	for _, metaId := range metaIds {
-		// 确定要检查的聊天ID
-		var chatID string
-		if parsedInfo.ChatType == "private_chat" {
-			// 私聊：使用私聊的metaId作为聊天ID
-			chatID = parsedInfo.MetaId
-			if metaId == chatID {
-				// 自己不用给自己推送
-				continue
-			}
-		} else if parsedInfo.ChatType == "group_chat" {
-			// 群聊：使用groupId作为聊天ID
-			chatID = parsedInfo.GroupId
-		}
-
-		// 如果没有聊天ID，跳过屏蔽检查
-		if chatID == "" {
+	for i, metaId := range metaIds {
+		if !blocked[i] {
 			filteredMetaIds = append(filteredMetaIds, metaId)
-			continue
+		} else if metaId != chatID {
+			blockedCount++
 		}
+	}
+
+	if blockedCount > 0 {
+		log.Printf("📊 屏蔽统计: %d 个用户已屏蔽该聊天、所有群聊或该发送者", blockedCount)
+	}
 
-		// 检查用户是否屏蔽了该聊天
-		isBlocked, err := pebble_service.IsUserBlockedChat(metaId, chatID)
+	return filteredMetaIds
+}
+
+// userBlockReason 检查用户是否屏蔽了该消息：屏蔽了该聊天、开启了屏蔽所有群聊（群聊消息）或屏蔽了发送者，
+// 返回屏蔽原因，未屏蔽时返回空；检查出错时默认不屏蔽，继续推送
+func userBlockReason(metaId, chatID string, parsedInfo *ParsedMessageInfo) string {
+	// 已过期的临时静音视为未屏蔽，并由存储层顺带清理；好友请求、付款等不属于聊天的消息只检查发送者
+	if chatID != "" {
+		isBlocked, err := storage_service.IsUserBlockedChat(metaId, chatID)
 		if err != nil {
 			log.Printf("⚠️ 检查用户 %s 屏蔽状态失败: %v，默认不屏蔽", metaId, err)
-			// 出错时默认不屏蔽，继续推送
-			filteredMetaIds = append(filteredMetaIds, metaId)
-			continue
+		} else if isBlocked {
+			return "已屏蔽聊天 " + chatID
 		}
+	}
 
-		if isBlocked {
-			blockedCount++
-			log.Printf("🚫 用户 %s 已屏蔽聊天 %s，跳过推送", metaId, chatID)
-		} else {
-			filteredMetaIds = append(filteredMetaIds, metaId)
+	if parsedInfo.ChatType == "group_chat" {
+		allGroupsBlock, err := storage_service.GetAllGroupsBlock(metaId)
+		if err != nil {
+			log.Printf("⚠️ 检查用户 %s 屏蔽所有群聊状态失败: %v，默认不屏蔽", metaId, err)
+		} else if allGroupsBlock.Blocked {
+			return "已屏蔽所有群聊"
 		}
 	}
 
-	if blockedCount > 0 {
-		log.Printf("📊 屏蔽统计: %d 个用户已屏蔽该聊天", blockedCount)
+	if parsedInfo.SenderId != "" && parsedInfo.SenderId != metaId {
+		isBlocked, err := storage_service.IsUserBlockedSender(metaId, parsedInfo.SenderId)
+		if err != nil {
+			log.Printf("⚠️ 检查用户 %s 屏蔽发送者状态失败: %v，默认不屏蔽", metaId, err)
+		} else if isBlocked {
+			return "已屏蔽发送者 " + parsedInfo.SenderId
+		}
 	}
-
-	return filteredMetaIds
+	return ""
 }
@@ -0,0 +1,133 @@
+package pushcenter
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"push-base-service/models"
+	"push-base-service/service/metrics_service"
+	"push-base-service/service/pebble_service"
+	"push-base-service/service/socket_client_service"
+	"slices"
+	"strings"
+)
+
+// messageParseErrorCounter 上游消息严格解析失败次数
+var messageParseErrorCounter = metrics_service.NewCounterVec(
+	"push_message_parse_errors_total", "Number of upstream chat messages that failed strict decoding by chat type and reason", "chat_type", "reason")
+
+// parseErrorReason 解析错误分类：schema 结构校验失败、unknown_field 未知字段、invalid_type 类型不匹配、invalid 其他
+func parseErrorReason(err error) string {
+	var (
+		schemaErr *SchemaError
+		typeErr   *json.UnmarshalTypeError
+	)
+	switch {
+	case errors.As(err, &schemaErr):
+		return "schema"
+	case strings.Contains(err.Error(), "unknown field"):
+		return "unknown_field"
+	case errors.As(err, &typeErr):
+		return "invalid_type"
+	default:
+		return "invalid"
+	}
+}
+
+// quarantineMessage 记录解析或校验失败的消息到指标和隔离集合。严格解析失败的消息仍按已解析的字段推送；
+// 结构校验失败的消息不推送，同时保存完整的消息数据以便修复后重新入队
+func (pc *PushCenter) quarantineMessage(chatMsg *socket_client_service.ChatNotificationMessage, parseErr error) {
+	messageParseErrorCounter.Inc(chatMsg.Type, parseErrorReason(parseErr))
+
+	data, err := json.Marshal(chatMsg.Data.Message)
+	if err != nil {
+		log.Printf("⚠️ 序列化隔离消息失败: %v", err)
+		return
+	}
+	quarantined := &models.QuarantinedMessage{
+		ChatType: chatMsg.Type,
+		Message:  data,
+		Error:    parseErr.Error(),
+	}
+	var schemaErr *SchemaError
+	if errors.As(parseErr, &schemaErr) {
+		quarantined.Rejected = true
+		if quarantined.Data, err = json.Marshal(chatMsg.Data); err != nil {
+			log.Printf("⚠️ 序列化隔离消息失败: %v", err)
+			return
+		}
+	}
+	if err := pebble_service.AddQuarantinedMessage(quarantined, pebble_service.DefaultQuarantineLimit); err != nil {
+		log.Printf("⚠️ 写入隔离消息失败: %v", err)
+	}
+}
+
+// RequeueResult 隔离消息重新入队的结果
+type RequeueResult struct {
+	Requeued []string `json:"requeued"` // 已重新入队的条目ID
+	Skipped  []string `json:"skipped"`  // 已照常推送过（严格解析失败）、无需重新入队的条目ID
+	NotFound []string `json:"notFound"` // 不存在的条目ID
+}
+
+// RequeueQuarantined 将全局推送中心隔离的未推送消息重新入队，见 PushCenter.RequeueQuarantined
+func RequeueQuarantined(ids []string) (*RequeueResult, error) {
+	pc := GetGlobalPushCenter()
+	if pc == nil {
+		return nil, fmt.Errorf("推送中心未启用")
+	}
+	return pc.RequeueQuarantined(ids)
+}
+
+// RequeueQuarantined 将因结构校验失败未推送的隔离消息按隔离时间顺序重新交给推送中心处理，ids 为空时重新入队所有未推送的消息。
+// 入队前从隔离集合删除，仍校验失败的消息会重新隔离为新条目
+func (pc *PushCenter) RequeueQuarantined(ids []string) (*RequeueResult, error) {
+	result := &RequeueResult{Requeued: []string{}, Skipped: []string{}, NotFound: []string{}}
+
+	var entries []*models.QuarantinedMessage
+	if len(ids) == 0 {
+		messages, err := pebble_service.ListQuarantinedMessages(0)
+		if err != nil {
+			return nil, err
+		}
+		for _, message := range messages {
+			if message.Rejected {
+				entries = append(entries, message)
+			}
+		}
+	} else {
+		for _, id := range ids {
+			message, err := pebble_service.GetQuarantinedMessage(id)
+			if err != nil {
+				return nil, err
+			}
+			if message == nil {
+				result.NotFound = append(result.NotFound, id)
+				continue
+			}
+			entries = append(entries, message)
+		}
+	}
+	// 条目ID按隔离时间排序，按隔离的先后重新入队
+	slices.SortFunc(entries, func(a, b *models.QuarantinedMessage) int { return strings.Compare(a.ID, b.ID) })
+	entries = slices.CompactFunc(entries, func(a, b *models.QuarantinedMessage) bool { return a.ID == b.ID })
+
+	for _, entry := range entries {
+		if !entry.Rejected || len(entry.Data) == 0 {
+			result.Skipped = append(result.Skipped, entry.ID)
+			continue
+		}
+
+		var data socket_client_service.ExtraServiceMessage
+		if err := json.Unmarshal(entry.Data, &data); err != nil {
+			return result, fmt.Errorf("反序列化隔离消息 %s 失败: %w", entry.ID, err)
+		}
+		if err := pebble_service.RemoveQuarantinedMessage(entry.ID); err != nil {
+			return result, err
+		}
+		log.Printf("🔁 隔离消息重新入队: ID=%s, ChatType=%s", entry.ID, entry.ChatType)
+		pc.HandleMessage(&socket_client_service.ChatNotificationMessage{Type: entry.ChatType, Data: &data})
+		result.Requeued = append(result.Requeued, entry.ID)
+	}
+	return result, nil
+}
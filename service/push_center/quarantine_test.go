@@ -0,0 +1,180 @@
+package pushcenter
+
+import (
+	"push-base-service/service/pebble_service"
+	"push-base-service/service/socket_client_service"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseMessageInfoTyped(t *testing.T) {
+	pc := &PushCenter{config: &Config{PreviewEnabled: true}}
+
+	parsedInfo, err := pc.parseMessageInfo(&socket_client_service.ChatNotificationMessage{
+		Type: "group_chat",
+		Data: &socket_client_service.ExtraServiceMessage{Message: map[string]interface{}{
+			"pinId":      "pin-typed",
+			"channelId":  "channel1",
+			"chatType":   float64(23),
+			"content":    "hello",
+			"encryption": "0",
+			"amount":     "8.8",
+			"expireTime": float64(1900000000),
+			"userInfo":   map[string]interface{}{"name": "alice"},
+		}},
+	})
+	if err != nil || parsedInfo.ParseError != nil {
+		t.Fatalf("parseMessageInfo() failed, err: %v, parse error: %v", err, parsedInfo.ParseError)
+	}
+	want := &ParsedMessageInfo{
+		PinId:        "pin-typed",
+		GroupId:      "channel1",
+		ChatType:     "group_chat",
+		UserName:     "alice",
+		ChatInfoType: 23,
+		Preview:      "hello",
+		CandyBag:     &CandyBagInfo{AmountHint: "8.8", ClaimDeadline: 1900000000},
+	}
+	if !reflect.DeepEqual(parsedInfo, want) {
+		t.Errorf("parseMessageInfo() = %+v, want %+v", parsedInfo, want)
+	}
+
+	// 私聊 metaId 依次取 metaId、from、to，加密消息不提取预览
+	parsedInfo, _ = pc.parseMessageInfo(&socket_client_service.ChatNotificationMessage{
+		Type: "private_chat",
+		Data: &socket_client_service.ExtraServiceMessage{Message: map[string]interface{}{
+			"from": "alice", "to": "bob", "content": "secret", "encryption": "ecdh",
+		}},
+	})
+	if parsedInfo.MetaId != "alice" || !parsedInfo.Encrypted || parsedInfo.Preview != "" {
+		t.Errorf("private chat parsedInfo = %+v", parsedInfo)
+	}
+}
+
+func TestMalformedMessageQuarantined(t *testing.T) {
+	newTestStores(t)
+
+	dispatcher := &recordingDispatcher{}
+	pc := NewPushCenter(&Config{})
+	pc.SetAudienceResolver(listResolver{"group-quarantine": {"alice"}})
+	pc.SetDispatcher(dispatcher)
+
+	// 未知字段和类型不匹配：消息隔离备查，已解析的字段照常推送
+	err := pc.processChatMessage(&socket_client_service.ChatNotificationMessage{
+		Type: "group_chat",
+		Data: &socket_client_service.ExtraServiceMessage{Message: map[string]interface{}{
+			"pinId":    "pin-quarantine",
+			"groupId":  "group-quarantine",
+			"newField": true,
+		}},
+	})
+	if err != nil {
+		t.Fatalf("processChatMessage() failed, err: %v", err)
+	}
+	if !reflect.DeepEqual(dispatcher.metaIds, []string{"alice"}) {
+		t.Errorf("dispatched to %v, want [alice]", dispatcher.metaIds)
+	}
+
+	parsedInfo, _ := pc.parseMessageInfo(&socket_client_service.ChatNotificationMessage{
+		Type: "group_chat",
+		Data: &socket_client_service.ExtraServiceMessage{Message: map[string]interface{}{"groupId": "group1", "chatType": "red"}},
+	})
+	if parsedInfo.ParseError == nil || parseErrorReason(parsedInfo.ParseError) != "invalid_type" || parsedInfo.GroupId != "group1" {
+		t.Errorf("type mismatch parsedInfo = %+v", parsedInfo)
+	}
+
+	messages, err := pebble_service.ListQuarantinedMessages(0)
+	if err != nil {
+		t.Fatalf("ListQuarantinedMessages() failed, err: %v", err)
+	}
+	if len(messages) != 1 || messages[0].ChatType != "group_chat" || !strings.Contains(messages[0].Error, `unknown field "newField"`) ||
+		!strings.Contains(string(messages[0].Message), "pin-quarantine") {
+		t.Fatalf("quarantined messages = %+v", messages)
+	}
+
+	if cleared, err := pebble_service.ClearQuarantine(); err != nil || cleared != 1 {
+		t.Errorf("ClearQuarantine() = %d, %v", cleared, err)
+	}
+}
+
+func TestInvalidMessageRejectedAndRequeued(t *testing.T) {
+	newTestStores(t)
+
+	dispatcher := &recordingDispatcher{}
+	pc := NewPushCenter(&Config{})
+	pc.SetAudienceResolver(listResolver{"": {"alice"}})
+	pc.SetDispatcher(dispatcher)
+	pc.consuming = true
+	SetGlobalPushCenter(pc)
+	t.Cleanup(func() { SetGlobalPushCenter(nil) })
+
+	// 缺少群聊ID、消息内容不是对象：隔离且不推送
+	for _, message := range []interface{}{
+		map[string]interface{}{"pinId": "pin-invalid", "userInfo": map[string]interface{}{"name": "bob"}},
+		"not an object",
+	} {
+		err := pc.processChatMessage(&socket_client_service.ChatNotificationMessage{
+			Type: "group_chat",
+			Data: &socket_client_service.ExtraServiceMessage{Message: message, RepostMetaIds: []string{"alice"}},
+		})
+		if err != nil {
+			t.Fatalf("processChatMessage() failed, err: %v", err)
+		}
+	}
+	if len(dispatcher.metaIds) != 0 {
+		t.Fatalf("dispatched to %v, want none", dispatcher.metaIds)
+	}
+
+	messages, err := pebble_service.ListQuarantinedMessages(0)
+	if err != nil {
+		t.Fatalf("ListQuarantinedMessages() failed, err: %v", err)
+	}
+	if len(messages) != 2 || !messages[0].Rejected || !strings.Contains(messages[0].Error, "不是 JSON 对象") ||
+		!messages[1].Rejected || !strings.Contains(messages[1].Error, "groupId/channelId") || len(messages[1].Data) == 0 {
+		t.Fatalf("quarantined messages = %+v", messages)
+	}
+	invalidId, notObjectId := messages[1].ID, messages[0].ID
+
+	// 严格解析失败的消息已照常推送，不重新入队
+	pc.processChatMessage(&socket_client_service.ChatNotificationMessage{
+		Type: "group_chat",
+		Data: &socket_client_service.ExtraServiceMessage{Message: map[string]interface{}{"groupId": "group1", "newField": true}},
+	})
+	messages, _ = pebble_service.ListQuarantinedMessages(1)
+	parsedId := messages[0].ID
+	dispatcher.metaIds = nil
+
+	// 放宽校验后重新入队：原条目删除，消息按原接收用户推送
+	saved := messageSchemas["group_chat"]
+	delete(messageSchemas, "group_chat")
+	t.Cleanup(func() { messageSchemas["group_chat"] = saved })
+
+	result, err := RequeueQuarantined([]string{invalidId, parsedId, "missing"})
+	pc.inflight.Wait()
+	if err != nil {
+		t.Fatalf("RequeueQuarantined() failed, err: %v", err)
+	}
+	want := &RequeueResult{Requeued: []string{invalidId}, Skipped: []string{parsedId}, NotFound: []string{"missing"}}
+	if !reflect.DeepEqual(result, want) {
+		t.Errorf("RequeueQuarantined() = %+v, want %+v", result, want)
+	}
+	if !reflect.DeepEqual(dispatcher.metaIds, []string{"alice"}) {
+		t.Errorf("requeued message dispatched to %v, want [alice]", dispatcher.metaIds)
+	}
+	if message, _ := pebble_service.GetQuarantinedMessage(invalidId); message != nil {
+		t.Errorf("requeued message %s still quarantined", invalidId)
+	}
+
+	// ids 为空时重新入队所有未推送的消息，仍校验失败的消息重新隔离
+	messageSchemas["group_chat"] = saved
+	result, err = RequeueQuarantined(nil)
+	pc.inflight.Wait()
+	if err != nil || !reflect.DeepEqual(result.Requeued, []string{notObjectId}) {
+		t.Fatalf("RequeueQuarantined(nil) = %+v, %v", result, err)
+	}
+	messages, _ = pebble_service.ListQuarantinedMessages(0)
+	if len(messages) != 2 || messages[0].ID == notObjectId || !messages[0].Rejected {
+		t.Errorf("quarantined messages after requeue = %+v", messages)
+	}
+}
@@ -0,0 +1,121 @@
+package pushcenter
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"push-base-service/models"
+	"push-base-service/service/socket_client_service"
+	"push-base-service/service/storage_service"
+	"sync"
+	"time"
+)
+
+// 全局推送中心（供管理接口回放消息使用）
+var (
+	globalPushCenter   *PushCenter
+	globalPushCenterMu sync.RWMutex
+)
+
+// SetGlobalPushCenter 设置全局推送中心
+func SetGlobalPushCenter(pc *PushCenter) {
+	globalPushCenterMu.Lock()
+	defer globalPushCenterMu.Unlock()
+
+	globalPushCenter = pc
+}
+
+// GetGlobalPushCenter 获取全局推送中心，未启用时返回 nil
+func GetGlobalPushCenter() *PushCenter {
+	globalPushCenterMu.RLock()
+	defer globalPushCenterMu.RUnlock()
+
+	return globalPushCenter
+}
+
+// ReplayResult 回放一条上游消息的结果
+type ReplayResult struct {
+	ChatType        string                   `json:"chatType"`                  // 消息类型
+	Info            *ParsedMessageInfo       `json:"info"`                      // 解析出的消息信息
+	ValidationError string                   `json:"validationError,omitempty"` // 结构校验失败的原因（校验失败时不经过流水线，也不写入隔离集合）
+	ParseError      string                   `json:"parseError,omitempty"`      // 严格解析失败的原因（回放不写入隔离集合）
+	DryRun          bool                     `json:"dryRun"`                    // 是否为演练
+	AlreadyNotified bool                     `json:"alreadyNotified"`           // PIN 此前已推送过（回放不做去重，照常处理）
+	Notifications   []*ReplayNotification    `json:"notifications"`             // 生成的通知及其接收用户
+	Deliveries      []*models.DeliveryRecord `json:"deliveries"`                // 每个接收用户的投递结果或跳过原因
+}
+
+// ReplayNotification 回放时生成的一条通知
+type ReplayNotification struct {
+	Mention bool                   `json:"mention"` // 是否为提及通知
+	Users   []string               `json:"users"`   // 接收用户
+	Title   string                 `json:"title"`   // 应用路由规则后的标题
+	Body    string                 `json:"body"`    // 应用路由规则后的内容
+	Data    map[string]interface{} `json:"data"`    // 自定义数据
+}
+
+// ReplayMessage 将抓取到的原始 SocketData 载荷交给全局推送中心重新处理，见 PushCenter.ReplayMessage
+func ReplayMessage(payload []byte, dryRun bool) (*ReplayResult, error) {
+	pc := GetGlobalPushCenter()
+	if pc == nil {
+		return nil, fmt.Errorf("推送中心未启用")
+	}
+
+	chatMsg, err := socket_client_service.DecodeChatNotification(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	return pc.ReplayMessage(ctx, chatMsg, dryRun)
+}
+
+// ReplayMessage 让一条聊天消息完整经过解析、接收用户解析和流水线各环节，用于复现线上的解析和推送问题
+// 与正常处理的区别：不做 PIN 和幂等去重、解析或校验失败不写入隔离集合、同步返回每个用户的处理结果；
+// dryRun 时通知不调用推送平台，也不记录 PIN 已通知和投递结果
+func (pc *PushCenter) ReplayMessage(ctx context.Context, chatMsg *socket_client_service.ChatNotificationMessage, dryRun bool) (*ReplayResult, error) {
+	if err := validateMessage(chatMsg); err != nil {
+		return &ReplayResult{ChatType: chatMsg.Type, ValidationError: err.Error(), DryRun: dryRun}, nil
+	}
+
+	parsedInfo, err := pc.parseMessageInfo(chatMsg)
+	if err != nil {
+		return nil, fmt.Errorf("解析消息信息失败: %w", err)
+	}
+	result := &ReplayResult{ChatType: chatMsg.Type, Info: parsedInfo, DryRun: dryRun}
+	if parsedInfo.ParseError != nil {
+		result.ParseError = parsedInfo.ParseError.Error()
+	}
+	if parsedInfo.PinId != "" {
+		if result.AlreadyNotified, err = storage_service.IsNotifiedPin(parsedInfo.PinId); err != nil {
+			return nil, fmt.Errorf("检查PIN通知状态失败: %w", err)
+		}
+	}
+
+	audience, err := pc.audience.Resolve(ctx, chatMsg, parsedInfo)
+	if err != nil {
+		return nil, fmt.Errorf("解析接收用户失败: %w", err)
+	}
+	if audience, err = pc.verifyAudience(ctx, chatMsg, parsedInfo, audience); err != nil {
+		return nil, err
+	}
+
+	log.Printf("🔁 回放消息: Type=%s, PinId=%s, 演练=%v", chatMsg.Type, parsedInfo.PinId, dryRun)
+	msg := pc.newPipelineMessage(chatMsg, parsedInfo, audience)
+	msg.Replay, msg.DryRun = true, dryRun
+	if err := pc.runPipeline(ctx, msg); err != nil {
+		return nil, err
+	}
+
+	for _, group := range msg.Groups {
+		notification := &ReplayNotification{Mention: group.Mention, Users: group.Users, Title: group.Title, Body: group.Body, Data: group.Data}
+		if group.Notification != nil {
+			notification.Title, notification.Body, notification.Data = group.Notification.Title, group.Notification.Body, group.Notification.Data
+		}
+		result.Notifications = append(result.Notifications, notification)
+	}
+	result.Deliveries = buildDeliveryRecords(parsedInfo.PinId, audience.Users(),
+		msg.Recipients, msg.Skipped, msg.Results)
+	return result, nil
+}
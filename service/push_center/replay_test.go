@@ -0,0 +1,84 @@
+package pushcenter
+
+import (
+	"context"
+	"push-base-service/models"
+	"push-base-service/service/pebble_service"
+	"push-base-service/service/push_service"
+	"push-base-service/service/storage_service"
+	"sync"
+	"testing"
+)
+
+// dryRunDispatcher 记录通知是否为演练，并按演练返回模拟结果的测试发送器
+type dryRunDispatcher struct {
+	mu     sync.Mutex
+	dryRun []bool
+}
+
+func (d *dryRunDispatcher) SendCustomNotificationToUsers(ctx context.Context, metaIds []string, notification *push_service.PushNotification) (*push_service.BatchPushResult, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.dryRun = append(d.dryRun, notification.DryRun)
+
+	result := &push_service.BatchPushResult{TotalUsers: len(metaIds), SuccessCount: len(metaIds), DryRun: notification.DryRun}
+	for _, metaId := range metaIds {
+		result.Results = append(result.Results, &push_service.PushResult{MetaID: metaId, Platform: "expo", Success: true})
+	}
+	return result, nil
+}
+
+func TestReplayMessageDryRun(t *testing.T) {
+	ps := newTestStores(t)
+	ps.AddBlockedChat("carol", "group1", "group", "", 0)
+	if err := storage_service.AddNotifiedPin("pin-replay"); err != nil {
+		t.Fatalf("AddNotifiedPin() failed, err: %v", err)
+	}
+
+	dispatcher := &dryRunDispatcher{}
+	pc := NewPushCenter(&Config{DeliveryConfig: &DeliveryConfig{Enabled: true}})
+	pc.SetAudienceResolver(listResolver{"group1": {"alice", "bob", "carol"}})
+	pc.SetDispatcher(dispatcher)
+	SetGlobalPushCenter(pc)
+	t.Cleanup(func() { SetGlobalPushCenter(nil) })
+
+	// 抓取到的载荷可能是 JSON 字符串形式
+	payload := []byte(`"{\"M\":\"WS_SERVER_NOTIFY_GROUP_CHAT\",\"C\":0,\"D\":{\"message\":{\"pinId\":\"pin-replay\",\"groupId\":\"group1\",\"userInfo\":{\"name\":\"Dave\"}}}}"`)
+	result, err := ReplayMessage(payload, true)
+	if err != nil {
+		t.Fatalf("ReplayMessage() failed, err: %v", err)
+	}
+
+	if result.ChatType != "group_chat" || !result.AlreadyNotified || !result.DryRun {
+		t.Errorf("result = %+v, want group_chat, already notified, dry run", result)
+	}
+	if len(dispatcher.dryRun) != 1 || !dispatcher.dryRun[0] {
+		t.Errorf("dispatched dryRun flags = %v, want [true]", dispatcher.dryRun)
+	}
+	if len(result.Notifications) != 1 || len(result.Notifications[0].Users) != 2 {
+		t.Fatalf("notifications = %+v, want one group for alice and bob", result.Notifications)
+	}
+
+	got := make(map[string]*models.DeliveryRecord)
+	for _, record := range result.Deliveries {
+		got[record.MetaID] = record
+	}
+	if len(got) != 3 || !got["alice"].Attempted || !got["bob"].Attempted || got["carol"].SkipReason != models.DeliverySkipBlocked {
+		t.Errorf("deliveries = %+v, want alice and bob attempted, carol skipped as blocked", result.Deliveries)
+	}
+
+	// 演练不写入投递记录
+	if records, _ := pebble_service.GetDeliveryRecords("pin-replay"); len(records) != 0 {
+		t.Errorf("%d delivery records written by dry run, want 0", len(records))
+	}
+
+	// 结构校验失败时返回原因，不经过流水线
+	result, err = ReplayMessage([]byte(`{"M":"WS_SERVER_NOTIFY_GROUP_CHAT","C":0,"D":{"message":{"pinId":"pin-invalid"}}}`), true)
+	if err != nil || result.ValidationError == "" || len(dispatcher.dryRun) != 1 {
+		t.Errorf("ReplayMessage() invalid message = %+v, %v, want validation error without dispatch", result, err)
+	}
+
+	if _, err := ReplayMessage([]byte(`{"M":"WS_SERVER_NOTIFY_UNKNOWN","C":0,"D":{}}`), true); err == nil {
+		t.Error("ReplayMessage() with unsupported method succeeded, want error")
+	}
+}
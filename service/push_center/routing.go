@@ -0,0 +1,301 @@
+package pushcenter
+
+import (
+	"fmt"
+	"log"
+	"push-base-service/models"
+	"push-base-service/service/pebble_service"
+	"push-base-service/service/push_service"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// 路由规则来源
+const (
+	RoutingSourceConfig = "config" // 配置文件
+	RoutingSourceAPI    = "api"    // 管理接口（保存在 Pebble 中，重启后仍生效）
+)
+
+// RoutingInput 路由匹配输入
+type RoutingInput struct {
+	MessageType  string                 // 消息类型：private_chat / group_chat / friend_request / payment
+	ChatInfoType int64                  // 聊天信息类型
+	Mention      bool                   // 是否为提及通知
+	Data         map[string]interface{} // 通知自定义数据
+}
+
+// Router 通知路由器：按规则决定通知的优先级、声音、有效期、Android 渠道和推送提供者
+type Router struct {
+	configRules []models.RoutingRule
+	rules       []models.RoutingRule
+	source      string
+	mu          sync.RWMutex
+}
+
+// NewRouter 创建使用配置文件规则的路由器
+func NewRouter(configRules []models.RoutingRule) *Router {
+	return &Router{
+		configRules: configRules,
+		rules:       configRules,
+		source:      RoutingSourceConfig,
+	}
+}
+
+// Rules 返回当前生效的规则及其来源
+func (r *Router) Rules() ([]models.RoutingRule, string) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	rules := r.rules
+	if rules == nil {
+		rules = []models.RoutingRule{}
+	}
+	return rules, r.source
+}
+
+// SetRules 替换当前生效的规则（规则需先通过 ValidateRoutingRules 校验）
+func (r *Router) SetRules(rules []models.RoutingRule) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.rules = rules
+	r.source = RoutingSourceAPI
+}
+
+// ResetRules 恢复使用配置文件中的规则
+func (r *Router) ResetRules() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.rules = r.configRules
+	r.source = RoutingSourceConfig
+}
+
+// Route 返回第一条匹配的规则，没有匹配时返回 nil
+func (r *Router) Route(input *RoutingInput) *models.RoutingRule {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for i := range r.rules {
+		if matchRoutingRule(&r.rules[i], input) {
+			return &r.rules[i]
+		}
+	}
+	return nil
+}
+
+// Apply 按匹配的规则设置通知的路由属性，返回命中的规则名称（未命中时为空）
+func (r *Router) Apply(notification *push_service.PushNotification, input *RoutingInput) string {
+	rule := r.Route(input)
+	if rule == nil {
+		return ""
+	}
+
+	if rule.Priority != "" {
+		notification.Priority = rule.Priority
+	}
+	if rule.Sound != "" {
+		notification.Sound = rule.Sound
+	}
+	if rule.TTL > 0 {
+		notification.TTL = rule.TTL
+	}
+	if rule.ChannelID != "" {
+		notification.ChannelID = rule.ChannelID
+	}
+	if len(rule.Providers) > 0 {
+		notification.Providers = rule.Providers
+	}
+	return rule.Name
+}
+
+// matchRoutingRule 判断输入是否满足规则的所有匹配条件
+func matchRoutingRule(rule *models.RoutingRule, input *RoutingInput) bool {
+	if len(rule.MessageTypes) > 0 && !slices.Contains(rule.MessageTypes, input.MessageType) {
+		return false
+	}
+	if len(rule.ChatInfoTypes) > 0 && !slices.Contains(rule.ChatInfoTypes, input.ChatInfoType) {
+		return false
+	}
+	if rule.Mention != nil && *rule.Mention != input.Mention {
+		return false
+	}
+	for path, expected := range rule.DataFields {
+		value, exists := lookupDataField(input.Data, path)
+		if !exists || value != expected {
+			return false
+		}
+	}
+	return true
+}
+
+// lookupDataField 按 a.b.c 路径读取数据字段并转为字符串
+func lookupDataField(data map[string]interface{}, path string) (string, bool) {
+	var current interface{} = data
+	for _, key := range strings.Split(path, ".") {
+		fields, ok := current.(map[string]interface{})
+		if !ok {
+			return "", false
+		}
+		if current, ok = fields[key]; !ok {
+			return "", false
+		}
+	}
+
+	switch v := current.(type) {
+	case string:
+		return v, true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case nil:
+		return "", false
+	default:
+		return fmt.Sprint(v), true
+	}
+}
+
+// ValidateRoutingRules 校验路由规则
+func ValidateRoutingRules(rules []models.RoutingRule) error {
+	names := make(map[string]bool, len(rules))
+	for i, rule := range rules {
+		if rule.Name == "" {
+			return fmt.Errorf("第 %d 条路由规则缺少名称", i+1)
+		}
+		if names[rule.Name] {
+			return fmt.Errorf("路由规则名称重复: %s", rule.Name)
+		}
+		names[rule.Name] = true
+
+		for _, messageType := range rule.MessageTypes {
+			if !slices.Contains(SupportedMessageTypes, messageType) {
+				return fmt.Errorf("路由规则 %s 的消息类型无效: %s", rule.Name, messageType)
+			}
+		}
+		if rule.Priority != "" && rule.Priority != push_service.PriorityHigh && rule.Priority != push_service.PriorityNormal {
+			return fmt.Errorf("路由规则 %s 的优先级无效: %s（可选 high/normal）", rule.Name, rule.Priority)
+		}
+		if rule.TTL < 0 {
+			return fmt.Errorf("路由规则 %s 的有效期不能为负数", rule.Name)
+		}
+		for path := range rule.DataFields {
+			if path == "" || strings.HasPrefix(path, ".") || strings.HasSuffix(path, ".") {
+				return fmt.Errorf("路由规则 %s 的数据字段路径无效: %q", rule.Name, path)
+			}
+		}
+		for _, provider := range rule.Providers {
+			if provider == "" {
+				return fmt.Errorf("路由规则 %s 的推送提供者不能为空", rule.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// 全局路由器实例（供管理接口使用）
+var (
+	globalRouter   *Router
+	globalRouterMu sync.RWMutex
+)
+
+// SetGlobalRouter 设置全局路由器
+func SetGlobalRouter(router *Router) {
+	globalRouterMu.Lock()
+	defer globalRouterMu.Unlock()
+
+	globalRouter = router
+}
+
+// GetGlobalRouter 获取全局路由器，推送中心未启用时返回 nil
+func GetGlobalRouter() *Router {
+	globalRouterMu.RLock()
+	defer globalRouterMu.RUnlock()
+
+	return globalRouter
+}
+
+// UpdateRoutingRules 校验并保存路由规则，立即生效并覆盖配置文件中的规则
+func UpdateRoutingRules(rules []models.RoutingRule) error {
+	router := GetGlobalRouter()
+	if router == nil {
+		return fmt.Errorf("推送中心未启用")
+	}
+	if err := ValidateRoutingRules(rules); err != nil {
+		return err
+	}
+
+	ruleSet, err := pebble_service.SaveRoutingRules(rules)
+	if err != nil {
+		return err
+	}
+	router.SetRules(ruleSet.Rules)
+	log.Printf("🧭 路由规则已更新: %d 条", len(ruleSet.Rules))
+	return nil
+}
+
+// ResetRoutingRules 删除通过管理接口设置的路由规则，恢复使用配置文件中的规则
+func ResetRoutingRules() error {
+	router := GetGlobalRouter()
+	if router == nil {
+		return fmt.Errorf("推送中心未启用")
+	}
+	if err := pebble_service.DeleteRoutingRules(); err != nil {
+		return err
+	}
+	router.ResetRules()
+	log.Printf("🧭 路由规则已恢复为配置文件中的规则")
+	return nil
+}
+
+// loadRoutingRules 启动时加载通过管理接口保存的路由规则
+func (pc *PushCenter) loadRoutingRules() error {
+	if err := ValidateRoutingRules(pc.config.RoutingRules); err != nil {
+		return fmt.Errorf("配置文件中的路由规则无效: %w", err)
+	}
+	pc.router = NewRouter(pc.config.RoutingRules)
+
+	saved, err := pebble_service.GetRoutingRules()
+	if err != nil {
+		return fmt.Errorf("加载路由规则失败: %w", err)
+	}
+	if saved != nil {
+		pc.router.SetRules(saved.Rules)
+	}
+
+	rules, source := pc.router.Rules()
+	if len(rules) > 0 {
+		log.Printf("🧭 通知路由规则已加载: %d 条, 来源=%s", len(rules), source)
+	}
+	SetGlobalRouter(pc.router)
+	return nil
+}
+
+// newRoutedNotification 创建推送通知，设置同一聊天的合并方式和红包通知属性，并按路由规则设置优先级、声音等属性
+func (pc *PushCenter) newRoutedNotification(title, body string, data map[string]interface{}, parsedInfo *ParsedMessageInfo, isMention bool) *push_service.PushNotification {
+	notification := &push_service.PushNotification{
+		Title: title,
+		Body:  body,
+		Data:  data,
+		Sound: "default",
+	}
+	pc.limitNotificationText(notification)
+	pc.applyCollapse(notification, parsedInfo, isMention)
+	pc.applyGrouping(notification, parsedInfo)
+	pc.applyCandyBag(notification, parsedInfo)
+	if pc.router == nil {
+		return notification
+	}
+
+	ruleName := pc.router.Apply(notification, &RoutingInput{
+		MessageType:  parsedInfo.ChatType,
+		ChatInfoType: parsedInfo.ChatInfoType,
+		Mention:      isMention,
+		Data:         data,
+	})
+	if ruleName != "" {
+		log.Printf("🧭 命中路由规则 %s: 优先级=%s, 声音=%s, TTL=%d, 渠道=%s, 提供者=%v",
+			ruleName, notification.Priority, notification.Sound, notification.TTL, notification.ChannelID, notification.Providers)
+	}
+	return notification
+}
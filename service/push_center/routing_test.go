@@ -0,0 +1,113 @@
+package pushcenter
+
+import (
+	"push-base-service/models"
+	"push-base-service/service/push_service"
+	"testing"
+)
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+func TestRouterFirstMatchWins(t *testing.T) {
+	router := NewRouter([]models.RoutingRule{
+		{Name: "red-packet", ChatInfoTypes: []int64{1, 23}, Priority: push_service.PriorityHigh, Sound: "candy.wav", ChannelID: "red-packet"},
+		{Name: "mentions", Mention: boolPtr(true), Priority: push_service.PriorityHigh, TTL: 86400},
+		{Name: "group", MessageTypes: []string{"group_chat"}, Priority: push_service.PriorityNormal, Providers: []string{"expo"}},
+	})
+
+	cases := []struct {
+		input *RoutingInput
+		want  string
+	}{
+		{&RoutingInput{MessageType: "group_chat", ChatInfoType: 23, Mention: true}, "red-packet"},
+		{&RoutingInput{MessageType: "group_chat", Mention: true}, "mentions"},
+		{&RoutingInput{MessageType: "group_chat"}, "group"},
+		{&RoutingInput{MessageType: "private_chat"}, ""},
+	}
+	for _, c := range cases {
+		got := ""
+		if rule := router.Route(c.input); rule != nil {
+			got = rule.Name
+		}
+		if got != c.want {
+			t.Errorf("Route(%+v) = %q, want %q", c.input, got, c.want)
+		}
+	}
+
+	notification := &push_service.PushNotification{Sound: "default"}
+	if name := router.Apply(notification, &RoutingInput{MessageType: "group_chat", ChatInfoType: 1}); name != "red-packet" {
+		t.Fatalf("Apply() = %q, want red-packet", name)
+	}
+	if notification.Priority != push_service.PriorityHigh || notification.Sound != "candy.wav" || notification.ChannelID != "red-packet" || notification.TTL != 0 {
+		t.Errorf("Apply() notification = %+v", notification)
+	}
+
+	notification = &push_service.PushNotification{Sound: "default"}
+	router.Apply(notification, &RoutingInput{MessageType: "group_chat"})
+	if notification.Sound != "default" || len(notification.Providers) != 1 {
+		t.Errorf("未设置的路由项应保持默认值: %+v", notification)
+	}
+}
+
+func TestRouterMatchesDataFields(t *testing.T) {
+	router := NewRouter([]models.RoutingRule{
+		{Name: "images", DataFields: map[string]string{"message.contentType": "image/jpeg", "message.encryption": "0"}, Priority: push_service.PriorityNormal},
+	})
+
+	data := map[string]interface{}{
+		"type":    "group_chat",
+		"message": map[string]interface{}{"contentType": "image/jpeg", "encryption": float64(0)},
+	}
+	if rule := router.Route(&RoutingInput{Data: data}); rule == nil || rule.Name != "images" {
+		t.Errorf("嵌套字段应匹配: %+v", rule)
+	}
+
+	data["message"] = map[string]interface{}{"contentType": "text/plain", "encryption": float64(0)}
+	if rule := router.Route(&RoutingInput{Data: data}); rule != nil {
+		t.Errorf("字段值不同不应匹配: %+v", rule)
+	}
+	if rule := router.Route(&RoutingInput{Data: map[string]interface{}{"message": "text"}}); rule != nil {
+		t.Errorf("非对象字段不应匹配: %+v", rule)
+	}
+}
+
+func TestRouterSetAndResetRules(t *testing.T) {
+	router := NewRouter([]models.RoutingRule{{Name: "config"}})
+
+	router.SetRules([]models.RoutingRule{})
+	if rules, source := router.Rules(); len(rules) != 0 || source != RoutingSourceAPI {
+		t.Errorf("SetRules() rules=%v source=%s", rules, source)
+	}
+	if rule := router.Route(&RoutingInput{}); rule != nil {
+		t.Errorf("清空规则后不应匹配: %+v", rule)
+	}
+
+	router.ResetRules()
+	if rules, source := router.Rules(); len(rules) != 1 || source != RoutingSourceConfig {
+		t.Errorf("ResetRules() rules=%v source=%s", rules, source)
+	}
+}
+
+func TestValidateRoutingRules(t *testing.T) {
+	invalid := [][]models.RoutingRule{
+		{{Priority: push_service.PriorityHigh}},
+		{{Name: "a"}, {Name: "a"}},
+		{{Name: "a", MessageTypes: []string{"channel"}}},
+		{{Name: "a", Priority: "urgent"}},
+		{{Name: "a", TTL: -1}},
+		{{Name: "a", DataFields: map[string]string{"message.": "x"}}},
+		{{Name: "a", Providers: []string{""}}},
+	}
+	for _, rules := range invalid {
+		if err := ValidateRoutingRules(rules); err == nil {
+			t.Errorf("ValidateRoutingRules(%+v) 应返回错误", rules)
+		}
+	}
+
+	valid := []models.RoutingRule{{Name: "a", MessageTypes: []string{"private_chat"}, Priority: push_service.PriorityHigh, TTL: 60}}
+	if err := ValidateRoutingRules(valid); err != nil {
+		t.Errorf("ValidateRoutingRules() failed, err: %v", err)
+	}
+}
@@ -0,0 +1,132 @@
+package pushcenter
+
+import (
+	"log"
+	"push-base-service/service/metrics_service"
+	"time"
+)
+
+// 停止阶段默认超时
+const (
+	DefaultShutdownSourceTimeout  = 10 * time.Second
+	DefaultShutdownDrainTimeout   = 30 * time.Second
+	DefaultShutdownReceiptTimeout = 30 * time.Second
+	DefaultShutdownStageTimeout   = 10 * time.Second
+)
+
+// shutdownTimeoutCounter 停止时超时的阶段数
+var shutdownTimeoutCounter = metrics_service.NewCounterVec(
+	"push_shutdown_stage_timeouts_total", "Number of shutdown stages that exceeded their timeout", "stage")
+
+// ShutdownConfig 有序停止配置
+// 停止顺序：消息来源 → 在途消息和队列 → Webhook/抽样/审计日志 → 投递回执 → 推送服务 → 存储；
+// 阶段超时后记录日志并继续后续阶段，存储关闭阶段始终等待完成以免丢失数据
+type ShutdownConfig struct {
+	SourceTimeout  time.Duration `yaml:"source_timeout" json:"source_timeout"`   // 停止消息来源（断开上游连接）的超时
+	DrainTimeout   time.Duration `yaml:"drain_timeout" json:"drain_timeout"`     // 等待在途消息、串行队列和调度器完成的超时
+	ReceiptTimeout time.Duration `yaml:"receipt_timeout" json:"receipt_timeout"` // 回执轮询最后一次查询到期回执的超时
+	StageTimeout   time.Duration `yaml:"stage_timeout" json:"stage_timeout"`     // 其他阶段的超时
+}
+
+// shutdownStage 停止过程中的一个阶段
+type shutdownStage struct {
+	name    string
+	timeout time.Duration // 0 表示一直等待完成
+	run     func()
+}
+
+// shutdownTimeout 读取阶段超时配置，未配置时使用默认值
+func (pc *PushCenter) shutdownTimeout(pick func(*ShutdownConfig) time.Duration, fallback time.Duration) time.Duration {
+	if pc.config.ShutdownConfig != nil {
+		if timeout := pick(pc.config.ShutdownConfig); timeout > 0 {
+			return timeout
+		}
+	}
+	return fallback
+}
+
+// shutdownStages 按顺序列出停止阶段：先停止接收上游消息，再排空处理队列，投递回执在推送服务停止前最后查询
+func (pc *PushCenter) shutdownStages() []shutdownStage {
+	stageTimeout := pc.shutdownTimeout(func(c *ShutdownConfig) time.Duration { return c.StageTimeout }, DefaultShutdownStageTimeout)
+
+	stages := []shutdownStage{
+		{
+			name:    "停止消息来源",
+			timeout: pc.shutdownTimeout(func(c *ShutdownConfig) time.Duration { return c.SourceTimeout }, DefaultShutdownSourceTimeout),
+			run:     pc.stopSources,
+		},
+		{
+			name:    "排空在途消息",
+			timeout: pc.shutdownTimeout(func(c *ShutdownConfig) time.Duration { return c.DrainTimeout }, DefaultShutdownDrainTimeout),
+			run: func() {
+				// 启用部署交接时由协调器交出主实例：排空并查询回执后才释放锁
+				if pc.coordinator != nil {
+					pc.coordinator.Stop()
+				} else {
+					pc.drainConsumers()
+				}
+			},
+		},
+	}
+
+	if pc.webhookDispatcher != nil {
+		stages = append(stages, shutdownStage{name: "停止租户 Webhook 分发器", timeout: stageTimeout, run: pc.webhookDispatcher.Stop})
+	}
+	if pc.sampler != nil {
+		stages = append(stages, shutdownStage{name: "停止外发通知抽样", timeout: stageTimeout, run: pc.sampler.Stop})
+	}
+	if pc.auditor != nil {
+		stages = append(stages, shutdownStage{name: "写入推送审计日志", timeout: stageTimeout, run: pc.auditor.Stop})
+	}
+
+	if pc.jobRunner != nil {
+		stages = append(stages, shutdownStage{name: "取消后台任务", timeout: stageTimeout, run: pc.jobRunner.Stop})
+	}
+
+	return append(stages,
+		shutdownStage{
+			name:    "刷新投递回执",
+			timeout: pc.shutdownTimeout(func(c *ShutdownConfig) time.Duration { return c.ReceiptTimeout }, DefaultShutdownReceiptTimeout),
+			run:     pc.stopReceiptPoller,
+		},
+		shutdownStage{
+			name:    "停止推送服务",
+			timeout: stageTimeout,
+			run: func() {
+				if err := pc.pushManager.Stop(); err != nil {
+					log.Printf("⚠️ 停止推送服务时出现错误: %v", err)
+				}
+			},
+		},
+		shutdownStage{name: "关闭存储", run: pc.closeStorage},
+	)
+}
+
+// runShutdownStages 依次执行停止阶段，阶段超时后记录日志并继续下一阶段
+func runShutdownStages(stages []shutdownStage) {
+	for i, stage := range stages {
+		start := time.Now()
+		log.Printf("🛑 [%d/%d] %s...", i+1, len(stages), stage.name)
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			stage.run()
+		}()
+
+		if stage.timeout <= 0 {
+			<-done
+		} else {
+			timer := time.NewTimer(stage.timeout)
+			select {
+			case <-done:
+				timer.Stop()
+			case <-timer.C:
+				shutdownTimeoutCounter.Inc(stage.name)
+				log.Printf("⚠️ [%d/%d] %s 超时（%v），继续后续阶段", i+1, len(stages), stage.name, stage.timeout)
+				continue
+			}
+		}
+		log.Printf("✅ [%d/%d] %s 完成，耗时 %v", i+1, len(stages), stage.name, time.Since(start).Round(time.Millisecond))
+	}
+}
@@ -0,0 +1,73 @@
+package pushcenter
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRunShutdownStagesContinuesAfterTimeout(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		order []string
+	)
+	record := func(name string) func() {
+		return func() {
+			mu.Lock()
+			defer mu.Unlock()
+			order = append(order, name)
+		}
+	}
+
+	release := make(chan struct{})
+	defer close(release)
+	runShutdownStages([]shutdownStage{
+		{name: "sources", timeout: time.Second, run: record("sources")},
+		{name: "drain", timeout: 20 * time.Millisecond, run: func() { <-release }},
+		{name: "receipts", timeout: time.Second, run: record("receipts")},
+		{name: "storage", run: record("storage")},
+	})
+
+	mu.Lock()
+	defer mu.Unlock()
+	if want := []string{"sources", "receipts", "storage"}; !reflect.DeepEqual(order, want) {
+		t.Errorf("stage order = %v, want %v", order, want)
+	}
+}
+
+func TestShutdownStagesOrder(t *testing.T) {
+	pc := NewPushCenter(&Config{ShutdownConfig: &ShutdownConfig{DrainTimeout: time.Minute}})
+
+	var names []string
+	for _, stage := range pc.shutdownStages() {
+		names = append(names, stage.name)
+		if stage.name == "排空在途消息" && stage.timeout != time.Minute {
+			t.Errorf("drain timeout = %v, want 1m", stage.timeout)
+		}
+	}
+	want := []string{"停止消息来源", "排空在途消息", "刷新投递回执", "停止推送服务", "关闭存储"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("shutdown stages = %v, want %v", names, want)
+	}
+}
+
+func TestStopReceiptPollerWaitsForFinalCheck(t *testing.T) {
+	pc := NewPushCenter(&Config{})
+	stopCh, done := make(chan struct{}), make(chan struct{})
+	pc.receiptStopCh, pc.receiptDone = stopCh, done
+
+	flushed := false
+	go func() {
+		<-stopCh
+		time.Sleep(10 * time.Millisecond)
+		flushed = true
+		close(done)
+	}()
+
+	pc.stopReceiptPoller()
+	if !flushed || pc.receiptStopCh != nil {
+		t.Errorf("stopReceiptPoller() returned before the poller finished")
+	}
+	pc.stopReceiptPoller() // 重复调用无副作用
+}
@@ -0,0 +1,316 @@
+package pushcenter
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"push-base-service/models"
+	"push-base-service/service/pebble_service"
+	"push-base-service/service/storage_service"
+	"push-base-service/service/tracing_service"
+	"time"
+)
+
+// 默认流水线环节名称，按执行顺序排列
+const (
+	StageDedup    = "dedup"    // PIN 去重、多实例抢占和消息幂等检查
+	StageFilter   = "filter"   // 过滤屏蔽、关闭该类通知（红包、好友请求、付款）和暂停通知的用户
+	StageClassify = "classify" // 将用户分为提及通知和普通通知两组
+	StageTemplate = "template" // 生成各组的通知标题、内容和自定义数据
+	StageRoute    = "route"    // 按路由规则生成各组的通知
+	StageSend     = "send"     // 按聊天声音和预览设置发送通知
+	StageRecord   = "record"   // 记录 PIN 已通知和每个接收用户的投递结果
+)
+
+// defaultStages 默认流水线：Dedup → Filter → Classify → Template → Route → Send → Record
+func (pc *PushCenter) defaultStages() []PipelineStage {
+	return []PipelineStage{
+		NewPipelineStage(StageDedup, pc.dedupStage),
+		NewPipelineStage(StageFilter, pc.filterStage),
+		NewPipelineStage(StageClassify, pc.classifyStage),
+		NewPipelineStage(StageTemplate, pc.templateStage),
+		NewPipelineStage(StageRoute, pc.routeStage),
+		NewPipelineStage(StageSend, pc.sendStage),
+		NewPipelineStage(StageRecord, pc.recordStage),
+	}
+}
+
+// dedupStage PIN 已通知或已由其他实例处理、消息已处理过时结束处理；回放的消息不做去重
+func (pc *PushCenter) dedupStage(ctx context.Context, msg *PipelineMessage, next PipelineHandler) (err error) {
+	if msg.Replay {
+		return next(ctx, msg)
+	}
+
+	parsedInfo := msg.Info
+	if parsedInfo.PinId != "" {
+		isNotified, err := storage_service.IsNotifiedPin(parsedInfo.PinId)
+		if err != nil {
+			return fmt.Errorf("检查PIN通知状态失败: %w", err)
+		}
+		if isNotified {
+			log.Printf("📌 PIN已通知，跳过推送")
+			publishDuplicate(parsedInfo)
+			return nil
+		}
+
+		// 多实例部署时抢占推送权，未抢到说明其他实例已在处理
+		if pc.pinClaimer != nil {
+			won, err := pc.pinClaimer.Claim(ctx, parsedInfo.PinId)
+			if err != nil {
+				return fmt.Errorf("抢占PIN推送权失败: %w", err)
+			}
+			if !won {
+				log.Printf("📌 PIN已由其他实例处理，跳过推送: %s", parsedInfo.PinId)
+				return nil
+			}
+			// 后续处理失败时释放推送权，否则在抢占过期前其他实例和本实例的重试都会跳过该 PIN
+			defer func() {
+				if err != nil {
+					pc.releasePinClaim(parsedInfo.PinId)
+				}
+			}()
+		}
+	}
+
+	// 幂等检查：有 PinId 时按 PinId 去重，否则按消息内容哈希去重
+	// 多个上游或上游重试推送同一条消息时只处理一次
+	idempotencyKey, err := pc.buildIdempotencyKey(msg.ChatMsg, parsedInfo)
+	if err != nil {
+		log.Printf("❌ 生成消息幂等键失败: %v", err)
+		return nil
+	}
+	if _, claimed, err := pebble_service.ClaimIdempotencyKey(idempotencyKey, "socket"); err != nil {
+		return fmt.Errorf("检查消息幂等键失败: %w", err)
+	} else if !claimed {
+		log.Printf("🔁 消息已处理过，跳过推送: %s", idempotencyKey)
+		publishDuplicate(parsedInfo)
+		return nil
+	}
+	// 后续环节失败时释放幂等键，重试和上游重新投递时可以再次处理；处理完成后记录结果，有效期内的重复消息才会被跳过
+	defer func() {
+		if err != nil {
+			if releaseErr := pebble_service.ReleaseIdempotencyKey(idempotencyKey); releaseErr != nil {
+				log.Printf("⚠️ 释放幂等键失败: %v", releaseErr)
+			}
+			return
+		}
+		if completeErr := pebble_service.CompleteIdempotencyKey(idempotencyKey, map[string]interface{}{"pinId": parsedInfo.PinId}); completeErr != nil {
+			log.Printf("⚠️ 记录幂等键结果失败: %v", completeErr)
+		}
+	}()
+	return next(ctx, msg)
+}
+
+// releasePinClaim 释放本实例对 PIN 的推送权
+func (pc *PushCenter) releasePinClaim(pinId string) {
+	if pc.pinClaimer == nil || pinId == "" {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := pc.pinClaimer.Release(ctx, pinId); err != nil {
+		log.Printf("⚠️ 释放PIN推送权失败: %v", err)
+	}
+}
+
+// filterStage 过滤屏蔽该消息、退订推送、关闭该类通知（红包、好友请求、付款）和暂停通知的用户，被跳过的用户记入 Skipped
+// 转发用户和提及用户合并为同一份名单过滤，屏蔽和偏好设置对被提及的用户同样生效
+func (pc *PushCenter) filterStage(ctx context.Context, msg *PipelineMessage, next PipelineHandler) error {
+	candidates := msg.Audience.Users()
+	if len(candidates) == 0 {
+		log.Printf("⚠️ 没有需要推送的用户ID")
+		return nil
+	}
+
+	if len(msg.Audience.Mentioned) > 0 {
+		log.Printf("📝 合并后的提及用户ID: %+v", msg.Audience.Mentioned)
+	}
+
+	filteredUserIds := msg.blockedRecipients()
+	blockedFiltered := filteredUserIds
+	filteredUserIds, unsubscribedUserIds := filterUnsubscribed(filteredUserIds)
+	filteredUserIds, optedOutUserIds := pc.filterCandyBagOptOut(filteredUserIds, msg.Info)
+	filteredUserIds, mutedUserIds := pc.filterNotifyOptOut(filteredUserIds, msg.Info)
+	optedOutUserIds = append(optedOutUserIds, mutedUserIds...)
+	filteredUserIds, pausedUserIds := pc.filterPausedUsers(filteredUserIds)
+	for _, metaId := range unsubscribedUserIds {
+		msg.Skipped[metaId] = models.DeliverySkipUnsubscribed
+	}
+	for _, metaId := range optedOutUserIds {
+		msg.Skipped[metaId] = models.DeliverySkipOptedOut
+	}
+	for _, metaId := range pausedUserIds {
+		msg.Skipped[metaId] = models.DeliverySkipPaused
+	}
+	publishFiltered(msg.Info, candidates, blockedFiltered, msg.Skipped)
+
+	mentioned := make(map[string]bool, len(msg.Audience.Mentioned))
+	for _, metaId := range msg.Audience.Mentioned {
+		mentioned[metaId] = true
+	}
+	msg.Recipients = filteredUserIds
+	msg.Mentioned = nil
+	for _, metaId := range filteredUserIds {
+		if mentioned[metaId] {
+			msg.Mentioned = append(msg.Mentioned, metaId)
+		}
+	}
+	return next(ctx, msg)
+}
+
+// classifyStage 被提及的用户收到提及通知，其余用户收到普通通知（同一用户只收到一条，提及优先）
+func (pc *PushCenter) classifyStage(ctx context.Context, msg *PipelineMessage, next PipelineHandler) error {
+	mentioned := make(map[string]bool, len(msg.Mentioned))
+	for _, metaId := range msg.Mentioned {
+		mentioned[metaId] = true
+	}
+
+	var mentionUsers, normalUsers []string
+	for _, metaId := range mergeUserIds(msg.Recipients, msg.Mentioned) {
+		if mentioned[metaId] {
+			mentionUsers = append(mentionUsers, metaId)
+		} else {
+			normalUsers = append(normalUsers, metaId)
+		}
+	}
+
+	msg.Groups = msg.Groups[:0]
+	if len(mentionUsers) > 0 {
+		msg.Groups = append(msg.Groups, &NotificationGroup{Mention: true, Users: mentionUsers})
+	}
+	if len(normalUsers) > 0 {
+		msg.Groups = append(msg.Groups, &NotificationGroup{Users: normalUsers})
+	}
+	return next(ctx, msg)
+}
+
+// templateStage 生成各组的通知标题、内容、隐藏内容时的通用文案和自定义数据
+func (pc *PushCenter) templateStage(ctx context.Context, msg *PipelineMessage, next PipelineHandler) error {
+	chatMsg, parsedInfo := msg.ChatMsg, msg.Info
+	for _, group := range msg.Groups {
+		if notify := parsedInfo.Notify; notify != nil {
+			// 好友请求、付款通知使用各自的文案，隐藏内容时不含发起用户和金额
+			group.Title, group.Body = pc.generateNotifyText(notify, false)
+			_, group.HiddenBody = pc.generateNotifyText(notify, true)
+			group.Data = notifyData(notify)
+		} else {
+			// 提及通知参考 Telegram 的提及消息格式，内容中带群组
+			groupId := ""
+			if group.Mention {
+				groupId = parsedInfo.GroupId
+			}
+			group.Title = pc.generateNotificationTitle(chatMsg.Type, group.Mention)
+			group.Body = pc.GenerateNotificationBody(chatMsg.Type, parsedInfo.UserName, parsedInfo.ChatInfoType, group.Mention, groupId, false, "")
+			group.HiddenBody = pc.GenerateNotificationBody(chatMsg.Type, "", parsedInfo.ChatInfoType, group.Mention, groupId, true, "")
+
+			// 构造自定义数据，包含解析后的信息
+			group.Data = map[string]interface{}{
+				"type":      chatMsg.Type,
+				"message":   chatMsg.Data.Message,
+				"timestamp": time.Now().Unix(),
+				"pinId":     parsedInfo.PinId,
+			}
+			if group.Mention {
+				group.Data["isMention"] = true
+			}
+
+			// 根据聊天类型添加特定信息
+			if parsedInfo.ChatType == "private_chat" && parsedInfo.MetaId != "" {
+				group.Data["metaId"] = parsedInfo.MetaId
+			} else if parsedInfo.ChatType == "group_chat" && parsedInfo.GroupId != "" {
+				group.Data["groupId"] = parsedInfo.GroupId
+			}
+		}
+		if tracing_service.PayloadEnabled() {
+			if traceID := tracing_service.TraceID(ctx); traceID != "" {
+				group.Data[tracing_service.PayloadKey] = traceID
+			}
+		}
+	}
+	return next(ctx, msg)
+}
+
+// routeStage 按路由规则为各组生成通知（声音、优先级、渠道等）
+func (pc *PushCenter) routeStage(ctx context.Context, msg *PipelineMessage, next PipelineHandler) error {
+	for _, group := range msg.Groups {
+		group.Notification = pc.newRoutedNotification(group.Title, group.Body, group.Data, msg.Info, group.Mention)
+		group.Notification.DryRun = msg.DryRun
+	}
+	return next(ctx, msg)
+}
+
+// sendStage 发送各组通知（按用户的聊天声音分组，带预览时按用户语言翻译），结果记入 Results
+func (pc *PushCenter) sendStage(ctx context.Context, msg *PipelineMessage, next PipelineHandler) error {
+	parsedInfo := msg.Info
+	for _, group := range msg.Groups {
+		if len(group.Users) == 0 {
+			continue
+		}
+		if group.Summary {
+			result, err := pc.dispatcher.SendCustomNotificationToUsers(ctx, group.Users, group.Notification)
+			if err != nil {
+				log.Printf("❌ 推送摘要通知失败: %v%s", err, traceLogSuffix(ctx))
+				continue
+			}
+			msg.Results = append(msg.Results, result.Results...)
+			continue
+		}
+
+		kind := "普通"
+		if group.Mention {
+			kind = "提及"
+		} else if parsedInfo.ChatType == "private_chat" && parsedInfo.MetaId != "" {
+			log.Printf("📱 私聊消息 - 发送者/接收者MetaId: %s, 用户名: %s", parsedInfo.MetaId, parsedInfo.UserName)
+		} else if parsedInfo.ChatType == "group_chat" && parsedInfo.GroupId != "" {
+			log.Printf("👥 群聊消息 - 群组ID: %s, 用户名: %s", parsedInfo.GroupId, parsedInfo.UserName)
+		}
+
+		log.Printf("🚀 开始推送%s消息给 %d 个用户 - PinId: %s, ChatType: %s%s", kind, len(group.Users), parsedInfo.PinId, parsedInfo.ChatType, traceLogSuffix(ctx))
+		result, err := pc.sendWithChatSounds(ctx, group.Users, group.Notification, group.HiddenBody, parsedInfo, group.Mention)
+		if err != nil {
+			log.Printf("❌ 推送%s消息失败: %v%s", kind, err, traceLogSuffix(ctx))
+			continue
+		}
+		log.Printf("✅ %s消息推送完成: 总用户=%d, 成功=%d, 失败=%d, 耗时=%v%s",
+			kind, result.TotalUsers, result.SuccessCount, result.FailureCount, result.Duration, traceLogSuffix(ctx))
+		msg.Results = append(msg.Results, result.Results...)
+
+		// 如果有失败的推送，记录详细信息
+		for _, pushResult := range result.Results {
+			if !pushResult.Success && pushResult.Error != nil {
+				log.Printf("⚠️ 推送失败 - 用户: %s, 平台: %s, 错误: %v",
+					pushResult.MetaID, pushResult.Platform, pushResult.Error)
+			}
+		}
+	}
+	return next(ctx, msg)
+}
+
+// recordStage 记录 PIN 已通知（同步写入，保证部署交接排空在途消息时记录已落盘）和每个接收用户的投递结果；演练时不记录
+func (pc *PushCenter) recordStage(ctx context.Context, msg *PipelineMessage, next PipelineHandler) error {
+	if msg.DryRun {
+		return next(ctx, msg)
+	}
+
+	if pinId := msg.Info.PinId; pinId != "" {
+		if err := storage_service.AddNotifiedPin(pinId); err != nil {
+			log.Printf("⚠️ 记录PIN通知状态失败: %v", err)
+		} else {
+			log.Printf("📌 已记录PIN通知状态: %s", pinId)
+		}
+	} else {
+		log.Printf("⚠️ PinId为空，跳过PIN通知记录")
+	}
+
+	pc.recordDeliveries(msg.Info.PinId, msg.Audience.Users(),
+		msg.Recipients, msg.Skipped, msg.Results)
+	return next(ctx, msg)
+}
+
+// traceLogSuffix 启用链路追踪时追加到日志末尾的 traceId，便于从日志定位到 trace
+func traceLogSuffix(ctx context.Context) string {
+	if traceID := tracing_service.TraceID(ctx); traceID != "" {
+		return ", traceId: " + traceID
+	}
+	return ""
+}
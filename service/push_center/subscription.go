@@ -0,0 +1,26 @@
+package pushcenter
+
+import (
+	"log"
+	"push-base-service/service/pebble_service"
+)
+
+// filterUnsubscribed 过滤掉已退订推送的用户，返回仍订阅的用户和已退订的用户
+// 推送服务发送前同样会跳过退订用户，这里提前过滤以便投递记录注明跳过原因
+func filterUnsubscribed(metaIds []string) (subscribed, unsubscribed []string) {
+	for _, metaId := range metaIds {
+		isUnsubscribed, err := pebble_service.IsUnsubscribed(metaId)
+		if err != nil {
+			log.Printf("⚠️ 检查用户 %s 退订状态失败: %v", metaId, err)
+		}
+		if isUnsubscribed {
+			unsubscribed = append(unsubscribed, metaId)
+			continue
+		}
+		subscribed = append(subscribed, metaId)
+	}
+	if len(unsubscribed) > 0 {
+		log.Printf("📭 %d 个用户已退订推送，跳过推送", len(unsubscribed))
+	}
+	return subscribed, unsubscribed
+}
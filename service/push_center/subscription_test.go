@@ -0,0 +1,50 @@
+package pushcenter
+
+import (
+	"push-base-service/models"
+	"push-base-service/service/pebble_service"
+	"push-base-service/service/push_service"
+	"push-base-service/service/socket_client_service"
+	"testing"
+)
+
+func TestUnsubscribedUsersSkipped(t *testing.T) {
+	newTestStores(t)
+
+	if _, err := pebble_service.Unsubscribe("carol", "", "user"); err != nil {
+		t.Fatalf("Unsubscribe() failed, err: %v", err)
+	}
+
+	source := &stubSource{}
+	dispatcher := &notificationDispatcher{notifications: make(map[string]*push_service.PushNotification)}
+	pc := NewPushCenter(&Config{DeliveryConfig: &DeliveryConfig{Enabled: true}})
+	pc.sources = []MessageSource{source}
+	pc.SetAudienceResolver(listResolver{"group1": {"bob", "carol"}})
+	pc.SetDispatcher(dispatcher)
+	pc.SetChatMessageHandler()
+	pc.consuming = true
+
+	source.handler(&socket_client_service.ChatNotificationMessage{
+		Type: "group_chat",
+		Data: &socket_client_service.ExtraServiceMessage{Message: map[string]interface{}{"pinId": "pin-unsub", "groupId": "group1"}},
+	})
+	pc.inflight.Wait()
+
+	if dispatcher.take("bob") == nil {
+		t.Error("bob did not receive the notification")
+	}
+	if notification := dispatcher.take("carol"); notification != nil {
+		t.Errorf("unsubscribed carol received %+v", notification)
+	}
+
+	records, _ := pebble_service.GetDeliveryRecords("pin-unsub")
+	skipReason := ""
+	for _, record := range records {
+		if record.MetaID == "carol" {
+			skipReason = record.SkipReason
+		}
+	}
+	if skipReason != models.DeliverySkipUnsubscribed {
+		t.Errorf("carol's skip reason = %q, want %q", skipReason, models.DeliverySkipUnsubscribed)
+	}
+}
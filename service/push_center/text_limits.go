@@ -0,0 +1,53 @@
+package pushcenter
+
+import (
+	"push-base-service/service/push_service"
+	"push-base-service/tool"
+)
+
+// 通知文本默认长度限制（用户可见字符数，emoji 组合序列算一个字符）
+const (
+	DefaultMaxTitleLength   = 64
+	DefaultMaxBodyLength    = 200
+	DefaultMaxNameLength    = 20 // 参考 Telegram，通知中的用户名限制在 20 个字符左右
+	DefaultMaxPreviewLength = 100
+)
+
+// TextLimitsConfig 通知文本长度限制，超出时截取并追加 "..."，不会截断多字节字符或 emoji 组合序列
+type TextLimitsConfig struct {
+	MaxTitle   int `yaml:"max_title" json:"max_title"`     // 通知标题最大字符数
+	MaxBody    int `yaml:"max_body" json:"max_body"`       // 通知内容最大字符数（含用户名和消息预览）
+	MaxName    int `yaml:"max_name" json:"max_name"`       // 通知中发送者用户名最大字符数
+	MaxPreview int `yaml:"max_preview" json:"max_preview"` // 消息预览最大字符数
+}
+
+// textLimit 返回配置的长度限制，未配置时返回默认值
+func (pc *PushCenter) textLimit(pick func(*TextLimitsConfig) int, fallback int) int {
+	if pc.config.TextLimits != nil {
+		if limit := pick(pc.config.TextLimits); limit > 0 {
+			return limit
+		}
+	}
+	return fallback
+}
+
+// truncateUserName 截取通知中的发送者用户名
+func (pc *PushCenter) truncateUserName(userName string) string {
+	return tool.TruncateText(userName, pc.textLimit(func(c *TextLimitsConfig) int { return c.MaxName }, DefaultMaxNameLength))
+}
+
+// truncatePreview 截取消息预览
+func (pc *PushCenter) truncatePreview(preview string) string {
+	return tool.TruncateText(preview, pc.textLimit(func(c *TextLimitsConfig) int { return c.MaxPreview }, DefaultMaxPreviewLength))
+}
+
+// truncateBody 截取通知内容
+func (pc *PushCenter) truncateBody(body string) string {
+	return tool.TruncateText(body, pc.textLimit(func(c *TextLimitsConfig) int { return c.MaxBody }, DefaultMaxBodyLength))
+}
+
+// limitNotificationText 按长度限制截取通知标题和内容
+func (pc *PushCenter) limitNotificationText(notification *push_service.PushNotification) {
+	notification.Title = tool.TruncateText(notification.Title, pc.textLimit(func(c *TextLimitsConfig) int { return c.MaxTitle }, DefaultMaxTitleLength))
+	notification.Body = pc.truncateBody(notification.Body)
+}
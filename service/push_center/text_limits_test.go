@@ -0,0 +1,30 @@
+package pushcenter
+
+import (
+	"push-base-service/service/push_service"
+	"strings"
+	"testing"
+)
+
+func TestConfiguredTextLimits(t *testing.T) {
+	pc := &PushCenter{config: &Config{TextLimits: &TextLimitsConfig{MaxTitle: 8, MaxBody: 20, MaxName: 6, MaxPreview: 10}}}
+
+	if got := pc.truncateUserName("alice_in_wonderland"); got != "ali..." {
+		t.Errorf("truncateUserName() = %q, want ali...", got)
+	}
+	if got := pc.previewBody("bob", "see you at eight tonight", false); got != "bob: see you..." {
+		t.Errorf("previewBody() = %q, want bob: see you...", got)
+	}
+
+	notification := &push_service.PushNotification{Title: "New Message in Group", Body: strings.Repeat("🐱", 30)}
+	pc.limitNotificationText(notification)
+	if notification.Title != "New M..." || notification.Body != strings.Repeat("🐱", 17)+"..." {
+		t.Errorf("limitNotificationText() = %q / %q", notification.Title, notification.Body)
+	}
+
+	// 未配置时使用默认限制
+	pc = &PushCenter{config: &Config{}}
+	if got := pc.truncateUserName(strings.Repeat("a", DefaultMaxNameLength)); len(got) != DefaultMaxNameLength {
+		t.Errorf("truncateUserName() = %q, want unchanged", got)
+	}
+}
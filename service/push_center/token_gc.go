@@ -0,0 +1,83 @@
+package pushcenter
+
+import (
+	"log"
+	"push-base-service/service/metrics_service"
+	"push-base-service/service/pebble_service"
+	"time"
+)
+
+// 过期令牌清理默认配置
+const (
+	DefaultTokenStaleAfterDays  = 90
+	DefaultTokenDeleteAfterDays = 30
+	DefaultTokenGCInterval      = 6 * time.Hour
+)
+
+// tokenGCCounter 过期令牌清理标记和删除的令牌数
+var tokenGCCounter = metrics_service.NewCounterVec(
+	"push_token_gc_tokens_total", "Number of push tokens marked stale or purged by the stale token collector", "action")
+
+// TokenGCConfig 过期令牌清理配置（仅 Pebble 存储后端）
+// 超过 StaleAfterDays 天未注册或刷新的令牌标记为过期并停止推送，客户端重新注册后恢复；
+// 标记后再过 DeleteAfterDays 天仍未刷新的令牌连同设备信息一并删除
+type TokenGCConfig struct {
+	Enabled         bool          `yaml:"enabled" json:"enabled"`                     // 是否启用过期令牌清理
+	StaleAfterDays  int           `yaml:"stale_after_days" json:"stale_after_days"`   // 令牌多少天未刷新视为过期
+	DeleteAfterDays int           `yaml:"delete_after_days" json:"delete_after_days"` // 过期令牌保留多少天后删除
+	Interval        time.Duration `yaml:"interval" json:"interval"`                   // 清理间隔
+}
+
+// tokenGCEnabled 是否启用过期令牌清理
+func (pc *PushCenter) tokenGCEnabled() bool {
+	return pc.config.TokenGCConfig != nil && pc.config.TokenGCConfig.Enabled
+}
+
+// collectStaleTokens 执行一次过期令牌清理
+func (pc *PushCenter) collectStaleTokens(now time.Time) {
+	staleAfter := pc.config.TokenGCConfig.StaleAfterDays
+	if staleAfter <= 0 {
+		staleAfter = DefaultTokenStaleAfterDays
+	}
+	deleteAfter := pc.config.TokenGCConfig.DeleteAfterDays
+	if deleteAfter <= 0 {
+		deleteAfter = DefaultTokenDeleteAfterDays
+	}
+
+	staleBefore := now.AddDate(0, 0, -staleAfter).Unix()
+	purgeBefore := now.AddDate(0, 0, -deleteAfter).Unix()
+	stats, err := pebble_service.CollectStaleTokens(staleBefore, purgeBefore)
+	if err != nil {
+		log.Printf("⚠️ 清理过期令牌失败: %v", err)
+		return
+	}
+
+	tokenGCCounter.Add(float64(stats.LastMarked), "marked")
+	tokenGCCounter.Add(float64(stats.LastPurged), "purged")
+	if stats.LastMarked > 0 || stats.LastPurged > 0 {
+		log.Printf("🧹 过期令牌清理完成: 扫描用户=%d, 标记过期=%d, 删除=%d, 待删除=%d",
+			stats.LastScanned, stats.LastMarked, stats.LastPurged, stats.StaleTokens)
+	}
+}
+
+// tokenGCLoop 定期清理过期令牌，接管消费时先执行一次
+func (pc *PushCenter) tokenGCLoop(stopCh chan struct{}) {
+	interval := pc.config.TokenGCConfig.Interval
+	if interval <= 0 {
+		interval = DefaultTokenGCInterval
+	}
+
+	pc.collectStaleTokens(time.Now())
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+			pc.collectStaleTokens(time.Now())
+		}
+	}
+}
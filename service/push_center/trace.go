@@ -0,0 +1,222 @@
+package pushcenter
+
+import (
+	"fmt"
+	"log"
+	"push-base-service/models"
+	"push-base-service/service/pebble_service"
+	"sync"
+	"time"
+)
+
+// 推送追踪默认配置
+const (
+	DefaultTraceDuration = time.Hour
+	MaxTraceDuration     = 24 * time.Hour
+	traceRetention       = 7 * 24 * time.Hour // 追踪结束后事件保留时长，超过后连同追踪记录一并清理
+)
+
+// userTracer 正在追踪的用户及截止时间的内存副本，推送流程据此判断是否记录事件，避免每条消息都查询数据库
+type userTracer struct {
+	mu     sync.RWMutex
+	traces map[string]int64 // metaId → 追踪截止时间 (Unix 秒)
+}
+
+// globalTracer 全局推送追踪，管理接口开启或停止追踪时同步更新
+var globalTracer = &userTracer{traces: make(map[string]int64)}
+
+// set 开始或更新用户的追踪
+func (t *userTracer) set(metaId string, until int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.traces[metaId] = until
+}
+
+// remove 停止用户的追踪
+func (t *userTracer) remove(metaId string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.traces, metaId)
+}
+
+// tracing 用户当前是否在追踪期内
+func (t *userTracer) tracing(metaId string, now time.Time) bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	until, exists := t.traces[metaId]
+	return exists && now.Unix() < until
+}
+
+// active 是否有用户正在被追踪（没有时推送流程跳过事件构造）
+func (t *userTracer) active() bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return len(t.traces) > 0
+}
+
+// replace 用数据库中的追踪记录替换内存副本，只保留未过期的
+func (t *userTracer) replace(traces []*models.UserTrace, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.traces = make(map[string]int64, len(traces))
+	for _, trace := range traces {
+		if now.Unix() < trace.Until {
+			t.traces[trace.MetaID] = trace.Until
+		}
+	}
+}
+
+// StartUserTrace 开始追踪用户的推送决策，duration 为 0 时使用默认时长；已在追踪时更新截止时间
+func StartUserTrace(metaId, note string, duration time.Duration) (*models.UserTrace, error) {
+	if metaId == "" {
+		return nil, fmt.Errorf("MetaID 不能为空")
+	}
+	if duration <= 0 {
+		duration = DefaultTraceDuration
+	}
+	if duration > MaxTraceDuration {
+		return nil, fmt.Errorf("追踪时长不能超过 %v", MaxTraceDuration)
+	}
+
+	now := time.Now()
+	trace := &models.UserTrace{
+		MetaID:    metaId,
+		Note:      note,
+		Until:     now.Add(duration).Unix(),
+		CreatedAt: now.Unix(),
+	}
+	if err := pebble_service.SaveUserTrace(trace); err != nil {
+		return nil, err
+	}
+	globalTracer.set(metaId, trace.Until)
+	log.Printf("🔬 开始追踪用户推送: MetaID=%s, 截止=%s", metaId, time.Unix(trace.Until, 0).Format(time.RFC3339))
+	return trace, nil
+}
+
+// StopUserTrace 停止追踪用户，已记录的事件保留到过期清理
+func StopUserTrace(metaId string) error {
+	if metaId == "" {
+		return fmt.Errorf("MetaID 不能为空")
+	}
+	if err := pebble_service.DeleteUserTrace(metaId); err != nil {
+		return err
+	}
+	globalTracer.remove(metaId)
+	log.Printf("🔬 停止追踪用户推送: MetaID=%s", metaId)
+	return nil
+}
+
+// loadUserTraces 启动时加载未过期的推送追踪
+func (pc *PushCenter) loadUserTraces() {
+	traces, err := pebble_service.ListUserTraces()
+	if err != nil {
+		log.Printf("⚠️ 加载推送追踪失败: %v", err)
+		return
+	}
+	globalTracer.replace(traces, time.Now())
+}
+
+// recordTraceEvents 只保留正在追踪的用户的事件并写入
+func recordTraceEvents(events []*models.TraceEvent) {
+	if len(events) == 0 {
+		return
+	}
+
+	now := time.Now()
+	var traced []*models.TraceEvent
+	for _, event := range events {
+		if globalTracer.tracing(event.MetaID, now) {
+			traced = append(traced, event)
+		}
+	}
+	if len(traced) == 0 {
+		return
+	}
+	if err := pebble_service.AddTraceEvents(traced, pebble_service.DefaultTraceEventLimit); err != nil {
+		log.Printf("⚠️ 写入推送追踪事件失败: %v", err)
+	}
+}
+
+// traceDeliveries 将投递记录转换为追踪事件：已受理、发送失败或未发送的原因
+func traceDeliveries(records []*models.DeliveryRecord) {
+	if !globalTracer.active() {
+		return
+	}
+
+	events := make([]*models.TraceEvent, 0, len(records))
+	for _, record := range records {
+		event := &models.TraceEvent{MetaID: record.MetaID, PinID: record.PinID, Platform: record.Platform}
+		switch record.TicketStatus {
+		case models.DeliveryTicketOK:
+			event.Stage = models.TraceStageSent
+			if record.ReceiptID != "" {
+				event.Detail = "receipt " + record.ReceiptID
+			}
+		case models.DeliveryTicketError:
+			event.Stage, event.Detail = models.TraceStageFailed, record.Error
+		default:
+			event.Stage, event.Detail = models.TraceStageSkipped, record.SkipReason
+		}
+		events = append(events, event)
+	}
+	recordTraceEvents(events)
+}
+
+// traceRejected 记录被接收用户校验剔除的用户
+func traceRejected(pinId string, candidates []string, verified *Audience) {
+	if !globalTracer.active() {
+		return
+	}
+
+	kept := make(map[string]bool)
+	for _, metaId := range verified.Users() {
+		kept[metaId] = true
+	}
+	var events []*models.TraceEvent
+	for _, metaId := range candidates {
+		if !kept[metaId] {
+			events = append(events, &models.TraceEvent{MetaID: metaId, PinID: pinId, Stage: models.TraceStageRejected, Detail: "not a chat member"})
+		}
+	}
+	recordTraceEvents(events)
+}
+
+// traceReceipt 记录回执查询结果
+func traceReceipt(receipt *models.PendingReceipt, status, errMsg string) {
+	if !globalTracer.active() {
+		return
+	}
+
+	detail := status
+	if errMsg != "" {
+		detail += ": " + errMsg
+	}
+	recordTraceEvents([]*models.TraceEvent{{
+		MetaID:   receipt.MetaID,
+		PinID:    receipt.PinID,
+		Stage:    models.TraceStageReceipt,
+		Platform: receipt.Platform,
+		Detail:   detail,
+	}})
+}
+
+// traceCleanupLoop 定期清理结束超过保留时长的推送追踪及其事件，并刷新内存中的追踪列表
+func (pc *PushCenter) traceCleanupLoop(stopCh chan struct{}) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+			count, err := pebble_service.PurgeExpiredTraces(time.Now().Add(-traceRetention).Unix())
+			if err != nil {
+				log.Printf("⚠️ 清理过期推送追踪失败: %v", err)
+			} else if count > 0 {
+				log.Printf("🧹 已清理 %d 个过期推送追踪", count)
+			}
+			pc.loadUserTraces()
+		}
+	}
+}
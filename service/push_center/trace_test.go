@@ -0,0 +1,108 @@
+package pushcenter
+
+import (
+	"errors"
+	"push-base-service/models"
+	"push-base-service/service/pebble_service"
+	"push-base-service/service/push_service"
+	"testing"
+	"time"
+)
+
+func TestUserTraceRecordsDecisions(t *testing.T) {
+	newTestStores(t)
+	t.Cleanup(func() { globalTracer.replace(nil, time.Now()) })
+
+	if _, err := StartUserTrace("alice", "ticket-1", 0); err != nil {
+		t.Fatalf("StartUserTrace() failed, err: %v", err)
+	}
+	if _, err := StartUserTrace("carol", "", time.Hour); err != nil {
+		t.Fatalf("StartUserTrace() failed, err: %v", err)
+	}
+	if _, err := StartUserTrace("bob", "", 2*MaxTraceDuration); err == nil {
+		t.Errorf("StartUserTrace() with duration over max succeeded, want error")
+	}
+
+	// 未启用投递追踪时仍记录被追踪用户的推送决策
+	pc := NewPushCenter(&Config{})
+	traceRejected("pin-1", []string{"alice", "bob", "mallory"}, &Audience{Recipients: []string{"bob"}})
+	pc.recordDeliveries("pin-1", []string{"bob", "carol", "dave"}, []string{"bob", "dave"}, map[string]string{"carol": models.DeliverySkipPaused}, []*push_service.PushResult{
+		{MetaID: "bob", Platform: "expo", Success: true, ReceiptID: "r-bob"},
+		{MetaID: "dave", Platform: "fcm", Success: false, Error: errors.New("Unregistered")},
+	})
+	pc.recordDeliveries("pin-2", []string{"alice"}, []string{"alice"}, nil, []*push_service.PushResult{
+		{MetaID: "alice", Platform: "fcm", Success: true},
+	})
+
+	events, err := pebble_service.GetTraceEvents("alice", 0, 0)
+	if err != nil {
+		t.Fatalf("GetTraceEvents() failed, err: %v", err)
+	}
+	if len(events) != 2 ||
+		events[0].PinID != "pin-1" || events[0].Stage != models.TraceStageRejected ||
+		events[1].PinID != "pin-2" || events[1].Stage != models.TraceStageSent || events[1].Platform != "fcm" {
+		t.Errorf("alice trace events = %+v", events)
+	}
+
+	events, _ = pebble_service.GetTraceEvents("carol", 0, 0)
+	if len(events) != 1 || events[0].Stage != models.TraceStageSkipped || events[0].Detail != models.DeliverySkipPaused {
+		t.Errorf("carol trace events = %+v", events)
+	}
+
+	// 未被追踪的用户不记录事件
+	for _, metaId := range []string{"bob", "dave", "mallory"} {
+		if events, _ := pebble_service.GetTraceEvents(metaId, 0, 0); len(events) != 0 {
+			t.Errorf("%s has %d trace events, want 0", metaId, len(events))
+		}
+	}
+
+	// 停止追踪后不再记录，已有事件保留
+	if err := StopUserTrace("alice"); err != nil {
+		t.Fatalf("StopUserTrace() failed, err: %v", err)
+	}
+	pc.recordDeliveries("pin-3", []string{"alice"}, []string{"alice"}, nil, []*push_service.PushResult{
+		{MetaID: "alice", Platform: "fcm", Success: true},
+	})
+	if events, _ := pebble_service.GetTraceEvents("alice", 0, 0); len(events) != 2 {
+		t.Errorf("alice has %d trace events after stop, want 2", len(events))
+	}
+}
+
+func TestUserTraceExpiry(t *testing.T) {
+	newTestStores(t)
+	t.Cleanup(func() { globalTracer.replace(nil, time.Now()) })
+
+	now := time.Now()
+	expired := &models.UserTrace{MetaID: "erin", Until: now.Add(-time.Minute).Unix()}
+	active := &models.UserTrace{MetaID: "frank", Until: now.Add(time.Hour).Unix()}
+	for _, trace := range []*models.UserTrace{expired, active} {
+		if err := pebble_service.SaveUserTrace(trace); err != nil {
+			t.Fatalf("SaveUserTrace() failed, err: %v", err)
+		}
+	}
+
+	pc := NewPushCenter(&Config{})
+	pc.loadUserTraces()
+	if globalTracer.tracing("erin", now) || !globalTracer.tracing("frank", now) {
+		t.Errorf("loadUserTraces() should keep only unexpired traces")
+	}
+
+	traceRejected("pin-1", []string{"erin", "frank"}, &Audience{})
+	if events, _ := pebble_service.GetTraceEvents("erin", 0, 0); len(events) != 0 {
+		t.Errorf("expired trace recorded %d events, want 0", len(events))
+	}
+	if events, _ := pebble_service.GetTraceEvents("frank", 0, 0); len(events) != 1 {
+		t.Errorf("active trace recorded %d events, want 1", len(events))
+	}
+
+	count, err := pebble_service.PurgeExpiredTraces(now.Unix())
+	if err != nil || count != 1 {
+		t.Fatalf("PurgeExpiredTraces() = %d, %v, want 1, nil", count, err)
+	}
+	if trace, _ := pebble_service.GetUserTrace("erin"); trace != nil {
+		t.Errorf("expired trace still exists after purge")
+	}
+	if trace, _ := pebble_service.GetUserTrace("frank"); trace == nil {
+		t.Errorf("active trace removed by purge")
+	}
+}
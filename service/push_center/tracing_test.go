@@ -0,0 +1,46 @@
+package pushcenter
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestPipelineStageSpans(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	pass := func(ctx context.Context, msg *PipelineMessage, next PipelineHandler) error { return next(ctx, msg) }
+	stop := func(ctx context.Context, msg *PipelineMessage, next PipelineHandler) error { return nil }
+	pc := &PushCenter{stages: []PipelineStage{
+		NewPipelineStage("first", pass),
+		NewPipelineStage("second", stop),
+		NewPipelineStage("third", pass),
+	}}
+
+	ctx, root := otel.Tracer("test").Start(context.Background(), "socket.message")
+	if err := pc.runPipeline(ctx, &PipelineMessage{}); err != nil {
+		t.Fatalf("runPipeline failed: %v", err)
+	}
+	root.End()
+
+	// 未执行的环节没有 span，执行过的环节都直接挂在根 span 下
+	var names []string
+	for _, span := range recorder.Ended() {
+		if span.Name() == "socket.message" {
+			continue
+		}
+		names = append(names, span.Name())
+		if span.Parent().SpanID() != root.SpanContext().SpanID() {
+			t.Errorf("span %s parent = %s, want root", span.Name(), span.Parent().SpanID())
+		}
+	}
+	if len(names) != 2 || names[0] != "pipeline.first" || names[1] != "pipeline.second" {
+		t.Errorf("stage spans = %v, want [pipeline.first pipeline.second]", names)
+	}
+}
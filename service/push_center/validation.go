@@ -0,0 +1,95 @@
+package pushcenter
+
+import (
+	"encoding/json"
+	"fmt"
+	"push-base-service/service/socket_client_service"
+	"strings"
+)
+
+// SchemaError 消息结构校验失败：消息内容不是对象，或缺少推送必需的字段。
+// 与严格解析失败（未知字段等，消息仍照常推送）不同，校验失败的消息不推送，写入隔离集合等待修复后重新入队
+type SchemaError struct {
+	ChatType string
+	Reason   string
+}
+
+func (e *SchemaError) Error() string {
+	return fmt.Sprintf("%s 消息结构校验失败: %s", e.ChatType, e.Reason)
+}
+
+// requiredField 必填字段约束：Fields 中至少有一个为非空字符串
+type requiredField struct {
+	Fields []string
+}
+
+// messageSchemas 各聊天类型消息内容的必填字段
+var messageSchemas = map[string][]requiredField{
+	socket_client_service.MessageTypePrivateChat: {
+		{Fields: []string{"metaId", "from", "to"}}, // 私聊对象，用于通知数据和屏蔽检查
+	},
+	socket_client_service.MessageTypeGroupChat: {
+		{Fields: []string{"groupId", "channelId"}}, // 群聊ID，用于通知数据、屏蔽检查和成员校验
+	},
+}
+
+// validateMessage 按聊天类型校验消息内容的结构，未定义约束的类型不校验
+func validateMessage(chatMsg *socket_client_service.ChatNotificationMessage) error {
+	schema, ok := messageSchemas[chatMsg.Type]
+	if !ok {
+		return nil
+	}
+
+	var message interface{}
+	if chatMsg.Data != nil {
+		message = chatMsg.Data.Message
+	}
+	fields, err := messageFields(message)
+	if err != nil {
+		return &SchemaError{ChatType: chatMsg.Type, Reason: err.Error()}
+	}
+
+	for _, rule := range schema {
+		satisfied := false
+		for _, name := range rule.Fields {
+			if value, ok := fields[name].(string); ok && value != "" {
+				satisfied = true
+				break
+			}
+		}
+		if !satisfied {
+			return &SchemaError{ChatType: chatMsg.Type, Reason: fmt.Sprintf("缺少必填字段 %s", strings.Join(rule.Fields, "/"))}
+		}
+	}
+	return nil
+}
+
+// messageFields 将消息内容转换为字段表，消息内容为空或不是 JSON 对象时返回错误
+func messageFields(message interface{}) (map[string]interface{}, error) {
+	switch v := message.(type) {
+	case nil:
+		return nil, fmt.Errorf("消息内容为空")
+	case map[string]interface{}:
+		return v, nil
+	}
+
+	var data []byte
+	switch v := message.(type) {
+	case json.RawMessage:
+		data = v
+	case []byte:
+		data = v
+	default:
+		var err error
+		if data, err = json.Marshal(message); err != nil {
+			return nil, fmt.Errorf("消息内容无法序列化: %w", err)
+		}
+	}
+
+	// 字符串、数组等其他形状的消息内容无法解析出任何字段
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil || fields == nil {
+		return nil, fmt.Errorf("消息内容不是 JSON 对象")
+	}
+	return fields, nil
+}
@@ -0,0 +1,226 @@
+package push_service
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"hash/fnv"
+	"push-base-service/service/apns_service"
+	"push-base-service/service/wns_service"
+	"strings"
+	"time"
+)
+
+// 推送平台对折叠标识的长度限制
+const (
+	maxAPNsCollapseIDLength = 64
+	maxWNSTagLength         = 16
+)
+
+// APNSProvider APNs 推送提供者，以平台名称注册（如 macOS 桌面应用使用 "macos"），使用该平台应用的 Bundle ID
+type APNSProvider struct {
+	platform string
+	client   *apns_service.Client
+}
+
+// NewAPNSProvider 创建 APNs 推送提供者
+func NewAPNSProvider(platform string, config *apns_service.Config) (*APNSProvider, error) {
+	client, err := apns_service.NewClient(config)
+	if err != nil {
+		return nil, err
+	}
+	return &APNSProvider{platform: platform, client: client}, nil
+}
+
+// GetName 返回提供者名称（即令牌登记的平台名称）
+func (p *APNSProvider) GetName() string {
+	return p.platform
+}
+
+// SendNotification 发送单个通知
+func (p *APNSProvider) SendNotification(ctx context.Context, token string, notification *PushNotification) (*PushResult, error) {
+	startTime := time.Now()
+
+	payload, err := buildAPNsPayload(notification)
+	if err != nil {
+		return nil, err
+	}
+	request := &apns_service.Request{
+		DeviceToken: token,
+		Payload:     payload,
+		PushType:    apns_service.PushTypeAlert,
+		Priority:    10,
+		CollapseID:  truncateCollapseID(notification.CollapseID, maxAPNsCollapseIDLength),
+	}
+	if notification.IsDataOnly() {
+		// 静默推送只能使用节能优先级
+		request.PushType = apns_service.PushTypeBackground
+		request.Priority = 5
+	} else if notification.Priority == PriorityNormal {
+		request.Priority = 5
+	}
+	if notification.TTL > 0 {
+		request.Expiration = time.Now().Add(time.Duration(notification.TTL) * time.Second)
+	}
+
+	receiptID, err := p.client.Send(ctx, request)
+	return &PushResult{
+		Token:     token,
+		Success:   err == nil,
+		ReceiptID: receiptID,
+		Error:     err,
+		Duration:  time.Since(startTime),
+		Timestamp: time.Now(),
+	}, nil
+}
+
+// ValidateToken 验证设备令牌格式
+func (p *APNSProvider) ValidateToken(token string) bool {
+	return apns_service.ValidateDeviceToken(token)
+}
+
+// HealthCheck 健康检查
+func (p *APNSProvider) HealthCheck(ctx context.Context) error {
+	return p.client.HealthCheck(ctx)
+}
+
+// buildAPNsPayload 构建 APNs 负载：标准 aps 字典，自定义数据放在 data 中
+func buildAPNsPayload(notification *PushNotification) ([]byte, error) {
+	aps := map[string]interface{}{}
+	if !notification.IsDataOnly() {
+		alert := map[string]string{"title": notification.Title, "body": notification.Body}
+		if notification.SummaryArg != "" {
+			alert["summary-arg"] = notification.SummaryArg
+		}
+		aps["alert"] = alert
+		if notification.Sound != "" {
+			aps["sound"] = notification.Sound
+		}
+	}
+	if notification.Badge != nil {
+		aps["badge"] = *notification.Badge
+	}
+	if notification.ThreadID != "" {
+		aps["thread-id"] = notification.ThreadID
+	}
+	if notification.CategoryID != "" {
+		aps["category"] = notification.CategoryID
+	}
+	if notification.ContentAvailable {
+		aps["content-available"] = 1
+	}
+
+	payload := map[string]interface{}{"aps": aps}
+	if len(notification.Data) > 0 {
+		payload["data"] = notification.Data
+	}
+	return json.Marshal(payload)
+}
+
+// WNSProvider Windows 推送通知服务提供者，桌面应用以平台 "windows" 登记通道 URI
+type WNSProvider struct {
+	client *wns_service.Client
+}
+
+// NewWNSProvider 创建 WNS 推送提供者
+func NewWNSProvider(config *wns_service.Config) (*WNSProvider, error) {
+	client, err := wns_service.NewClient(config)
+	if err != nil {
+		return nil, err
+	}
+	return &WNSProvider{client: client}, nil
+}
+
+// GetName 返回提供者名称
+func (p *WNSProvider) GetName() string {
+	return PlatformWindows
+}
+
+// SendNotification 发送单个通知：展示通知使用 Toast，静默推送使用原始数据
+func (p *WNSProvider) SendNotification(ctx context.Context, token string, notification *PushNotification) (*PushResult, error) {
+	startTime := time.Now()
+
+	request := &wns_service.Request{
+		ChannelURI: token,
+		Type:       wns_service.TypeToast,
+		TTL:        notification.TTL,
+		Tag:        truncateCollapseID(notification.CollapseID, maxWNSTagLength),
+		Group:      notification.ThreadID,
+	}
+	if notification.IsDataOnly() {
+		data, err := json.Marshal(notification.Data)
+		if err != nil {
+			return nil, err
+		}
+		request.Type = wns_service.TypeRaw
+		request.Payload = data
+	} else {
+		toast, err := buildWNSToast(notification)
+		if err != nil {
+			return nil, err
+		}
+		request.Payload = toast
+	}
+
+	receiptID, err := p.client.Send(ctx, request)
+	return &PushResult{
+		Token:     token,
+		Success:   err == nil,
+		ReceiptID: receiptID,
+		Error:     err,
+		Duration:  time.Since(startTime),
+		Timestamp: time.Now(),
+	}, nil
+}
+
+// ValidateToken 验证通道 URI
+func (p *WNSProvider) ValidateToken(token string) bool {
+	return wns_service.ValidateChannelURI(token)
+}
+
+// HealthCheck 健康检查
+func (p *WNSProvider) HealthCheck(ctx context.Context) error {
+	return p.client.HealthCheck(ctx)
+}
+
+// buildWNSToast 构建 Toast XML：标题和内容两行文本，自定义数据以 JSON 放在 launch 参数中供应用打开时读取
+func buildWNSToast(notification *PushNotification) ([]byte, error) {
+	var builder strings.Builder
+	builder.WriteString("<toast")
+	if len(notification.Data) > 0 {
+		launch, err := json.Marshal(notification.Data)
+		if err != nil {
+			return nil, err
+		}
+		builder.WriteString(` launch="`)
+		if err := xml.EscapeText(&builder, launch); err != nil {
+			return nil, err
+		}
+		builder.WriteString(`"`)
+	}
+	builder.WriteString(`><visual><binding template="ToastGeneric">`)
+	for _, text := range []string{notification.Title, notification.Body} {
+		builder.WriteString("<text>")
+		if err := xml.EscapeText(&builder, []byte(text)); err != nil {
+			return nil, err
+		}
+		builder.WriteString("</text>")
+	}
+	builder.WriteString("</binding></visual>")
+	if notification.Sound == "" {
+		builder.WriteString(`<audio silent="true"/>`)
+	}
+	builder.WriteString("</toast>")
+	return []byte(builder.String()), nil
+}
+
+// truncateCollapseID 折叠标识超出平台长度限制时改用其哈希
+func truncateCollapseID(collapseID string, maxLength int) string {
+	if len(collapseID) <= maxLength {
+		return collapseID
+	}
+	hash := fnv.New64a()
+	hash.Write([]byte(collapseID))
+	return fmt.Sprintf("%016x", hash.Sum64())[:min(16, maxLength)]
+}
@@ -0,0 +1,54 @@
+package push_service
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestBuildAPNsPayload(t *testing.T) {
+	badge := 3
+	payload, err := buildAPNsPayload(&PushNotification{
+		Title: "New Message", Body: "alice: hi", Sound: "default", Badge: &badge, ThreadID: "group:g1", CategoryID: "message",
+		Data: map[string]interface{}{"groupId": "g1"},
+	})
+	if err != nil {
+		t.Fatalf("buildAPNsPayload() error = %v", err)
+	}
+	want := `{"aps":{"alert":{"body":"alice: hi","title":"New Message"},"badge":3,"category":"message","sound":"default","thread-id":"group:g1"},"data":{"groupId":"g1"}}`
+	if string(payload) != want {
+		t.Errorf("payload = %s\nwant %s", payload, want)
+	}
+
+	// 静默推送不带 alert
+	payload, _ = buildAPNsPayload(&PushNotification{ContentAvailable: true, Data: map[string]interface{}{"sync": true}})
+	var decoded map[string]map[string]interface{}
+	json.Unmarshal(payload, &decoded)
+	if decoded["aps"]["alert"] != nil || decoded["aps"]["content-available"] != float64(1) {
+		t.Errorf("data-only payload = %s", payload)
+	}
+}
+
+func TestBuildWNSToast(t *testing.T) {
+	toast, err := buildWNSToast(&PushNotification{
+		Title: "New Message", Body: `<b>&"hi"</b>`,
+		Data: map[string]interface{}{"groupId": "g1"},
+	})
+	if err != nil {
+		t.Fatalf("buildWNSToast() error = %v", err)
+	}
+	want := `<toast launch="{&#34;groupId&#34;:&#34;g1&#34;}"><visual><binding template="ToastGeneric"><text>New Message</text><text>&lt;b&gt;&amp;&#34;hi&#34;&lt;/b&gt;</text></binding></visual><audio silent="true"/></toast>`
+	if string(toast) != want {
+		t.Errorf("toast = %s\nwant %s", toast, want)
+	}
+}
+
+func TestTruncateCollapseID(t *testing.T) {
+	if got := truncateCollapseID("group:g1", maxWNSTagLength); got != "group:g1" {
+		t.Errorf("short collapse id changed: %q", got)
+	}
+	long := "group:" + strings.Repeat("x", 80)
+	if got := truncateCollapseID(long, maxWNSTagLength); len(got) != maxWNSTagLength || got != truncateCollapseID(long, maxWNSTagLength) {
+		t.Errorf("truncateCollapseID() = %q", got)
+	}
+}
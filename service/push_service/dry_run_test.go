@@ -0,0 +1,63 @@
+package push_service
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+func TestDryRunSkipsProviders(t *testing.T) {
+	mobile := &stubProvider{name: ProviderTypeExpo}
+	service := NewPushService()
+	service.RegisterProvider(mobile)
+
+	store := NewMemoryTokenStore()
+	ctx := context.Background()
+	store.SetUserToken(ctx, "a", ProviderTypeExpo, "expo-a")
+	store.SetUserToken(ctx, "b", ProviderTypeExpo, "expo-b")
+	service.SetUserTokenStore(store)
+
+	// 多个用户并发推送，监听器可能同时被调用
+	var listenedMu sync.Mutex
+	var listened []*PushResult
+	service.AddResultListener(func(notification *PushNotification, result *PushResult) {
+		listenedMu.Lock()
+		defer listenedMu.Unlock()
+		listened = append(listened, result)
+	})
+
+	// 单次请求演练
+	result, err := service.SendToUsers(ctx, []string{"a", "b"}, &PushNotification{Title: "t", Body: "b", DryRun: true})
+	if err != nil {
+		t.Fatalf("SendToUsers() failed, err: %v", err)
+	}
+	if len(mobile.sent) != 0 {
+		t.Errorf("演练模式不应调用推送平台: %v", mobile.sent)
+	}
+	if !result.DryRun || result.SuccessCount != 2 {
+		t.Errorf("result = %+v, want dry run with 2 simulated successes", result)
+	}
+	for _, r := range result.Results {
+		if !r.Simulated || !r.Success {
+			t.Errorf("result for %s = %+v, want simulated success", r.MetaID, r)
+		}
+	}
+	listenedMu.Lock()
+	if len(listened) != 2 || !listened[0].Simulated {
+		t.Errorf("监听器应收到模拟结果: %v", listened)
+	}
+	listenedMu.Unlock()
+
+	// 未要求演练时正常推送
+	result, _ = service.SendToUser(ctx, "a", &PushNotification{Title: "t", Body: "b"})
+	if result.DryRun || len(mobile.sent) != 1 {
+		t.Errorf("正常推送应调用推送平台: dryRun=%v, sent=%v", result.DryRun, mobile.sent)
+	}
+
+	// 全局演练模式
+	service.SetDryRun(true)
+	result, _ = service.SendToUser(ctx, "b", &PushNotification{Title: "t", Body: "b"})
+	if !result.DryRun || len(mobile.sent) != 1 || !result.Results[0].Simulated {
+		t.Errorf("全局演练模式不应调用推送平台: dryRun=%v, sent=%v", result.DryRun, mobile.sent)
+	}
+}
@@ -0,0 +1,73 @@
+package push_service
+
+import (
+	"context"
+	"push-base-service/service/email_service"
+	"strings"
+	"time"
+)
+
+// EmailProvider 邮件推送提供者，作为移动平台推送失败时的兜底渠道
+// 用户的邮箱地址以平台 "email" 的令牌形式登记
+type EmailProvider struct {
+	sender        email_service.Sender
+	subjectPrefix string
+}
+
+// NewEmailProvider 创建邮件推送提供者
+func NewEmailProvider(config *email_service.Config) (*EmailProvider, error) {
+	sender, err := email_service.NewSender(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &EmailProvider{
+		sender:        sender,
+		subjectPrefix: config.SubjectPrefix,
+	}, nil
+}
+
+// GetName 返回提供者名称
+func (p *EmailProvider) GetName() string {
+	return ProviderTypeEmail
+}
+
+// SendNotification 将通知以邮件摘要形式发送到用户邮箱
+func (p *EmailProvider) SendNotification(ctx context.Context, token string, notification *PushNotification) (*PushResult, error) {
+	startTime := time.Now()
+
+	err := p.sender.Send(ctx, &email_service.Message{
+		To:      token,
+		Subject: p.subjectPrefix + notification.Title,
+		Text:    buildEmailDigest(notification),
+	})
+
+	return &PushResult{
+		Token:     token,
+		Success:   err == nil,
+		Error:     err,
+		Duration:  time.Since(startTime),
+		Timestamp: time.Now(),
+	}, nil
+}
+
+// ValidateToken 验证邮箱地址格式
+func (p *EmailProvider) ValidateToken(token string) bool {
+	return email_service.ValidateAddress(token)
+}
+
+// HealthCheck 健康检查
+func (p *EmailProvider) HealthCheck(ctx context.Context) error {
+	return p.sender.HealthCheck(ctx)
+}
+
+// buildEmailDigest 构建邮件正文：通知标题、内容及兜底说明
+func buildEmailDigest(notification *PushNotification) string {
+	var builder strings.Builder
+	builder.WriteString(notification.Title)
+	builder.WriteString("\n\n")
+	builder.WriteString(notification.Body)
+	builder.WriteString("\n\n--\n")
+	builder.WriteString("This notification could not be delivered to your devices, so it was sent by email instead.\n")
+	return builder.String()
+}
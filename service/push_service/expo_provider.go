@@ -25,6 +25,11 @@ func NewExpoProvider(config *expo_service.Config) *ExpoProvider {
 	}
 }
 
+// UpdateConfig 热更新 Expo 配置，正在进行的限流暂停保持不变
+func (p *ExpoProvider) UpdateConfig(config *expo_service.Config) error {
+	return p.manager.UpdateConfig(config)
+}
+
 // GetName 返回提供者名称
 func (p *ExpoProvider) GetName() string {
 	return ProviderTypeExpo
@@ -54,6 +59,7 @@ func (p *ExpoProvider) SendNotification(ctx context.Context, token string, notif
 		Token:     token,
 		Success:   expoResult.Success,
 		ReceiptID: expoResult.ReceiptID,
+		Retries:   expoResult.Retry,
 		Duration:  time.Since(startTime),
 		Timestamp: time.Now(),
 	}
@@ -75,15 +81,47 @@ func (p *ExpoProvider) HealthCheck(ctx context.Context) error {
 	return p.manager.HealthCheck(ctx)
 }
 
+// CheckReceipts 查询Expo投递回执
+func (p *ExpoProvider) CheckReceipts(ctx context.Context, receiptIDs []string) (map[string]*ReceiptOutcome, error) {
+	receipts, err := p.manager.CheckReceipts(ctx, receiptIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	outcomes := make(map[string]*ReceiptOutcome, len(receipts))
+	for receiptID, receipt := range receipts {
+		outcome := &ReceiptOutcome{
+			Delivered:          receipt.Delivered,
+			DeviceUnregistered: receipt.DeviceUnregistered,
+		}
+		if receipt.Error != nil {
+			outcome.Error = receipt.Error.Error()
+		}
+		outcomes[receiptID] = outcome
+	}
+	return outcomes, nil
+}
+
 // buildExpoMessage 构建Expo消息
 func (p *ExpoProvider) buildExpoMessage(token string, notification *PushNotification) *expo_service.PushMessage {
 	message := &expo_service.PushMessage{
-		To:       []string{token},
-		Title:    notification.Title,
-		Body:     notification.Body,
-		Data:     notification.Data,
-		Sound:    notification.Sound,
-		Priority: notification.Priority,
+		To:               []string{token},
+		Title:            notification.Title,
+		Body:             notification.Body,
+		Data:             notification.Data,
+		Sound:            notification.Sound,
+		TTL:              notification.TTL,
+		Priority:         notification.Priority,
+		ChannelID:        notification.ChannelID,
+		CollapseID:       notification.CollapseID,
+		ThreadID:         notification.ThreadID,
+		CategoryID:       notification.CategoryID,
+		ContentAvailable: notification.ContentAvailable,
+	}
+
+	// 静默数据推送不播放声音
+	if notification.IsDataOnly() {
+		message.Sound = ""
 	}
 
 	// 设置徽章
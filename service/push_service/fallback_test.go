@@ -0,0 +1,138 @@
+package push_service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+)
+
+// stubProvider 按令牌返回成功或失败的测试推送提供者
+type stubProvider struct {
+	name string
+	fail map[string]bool
+	mu   sync.Mutex
+	sent []string
+}
+
+func (p *stubProvider) GetName() string { return p.name }
+
+func (p *stubProvider) SendNotification(ctx context.Context, token string, notification *PushNotification) (*PushResult, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.sent = append(p.sent, token)
+	if p.fail[token] {
+		return &PushResult{Success: false, Error: errors.New("send failed")}, nil
+	}
+	return &PushResult{Success: true}, nil
+}
+
+func (p *stubProvider) ValidateToken(token string) bool { return true }
+
+func (p *stubProvider) HealthCheck(ctx context.Context) error { return nil }
+
+func TestSendToUsersFallsBackToEmail(t *testing.T) {
+	mobile := &stubProvider{name: ProviderTypeExpo, fail: map[string]bool{"expo-b": true}}
+	email := &stubProvider{name: ProviderTypeEmail, fail: map[string]bool{"d@example.com": true}}
+
+	service := NewPushService()
+	service.RegisterProvider(mobile)
+	service.RegisterFallbackProvider(email)
+	service.SetFallbackChains(map[string][]string{PriorityHigh: {ProviderTypeEmail}})
+
+	store := NewMemoryTokenStore()
+	ctx := context.Background()
+	store.SetUserToken(ctx, "a", ProviderTypeExpo, "expo-a") // 推送成功，不兜底
+	store.SetUserToken(ctx, "a", ProviderTypeEmail, "a@example.com")
+	store.SetUserToken(ctx, "b", ProviderTypeExpo, "expo-b") // 推送失败，邮件兜底
+	store.SetUserToken(ctx, "b", ProviderTypeEmail, "b@example.com")
+	store.SetUserToken(ctx, "c", ProviderTypeEmail, "c@example.com") // 没有移动平台令牌，邮件兜底
+	store.SetUserToken(ctx, "d", ProviderTypeEmail, "d@example.com") // 邮件兜底失败
+	service.SetUserTokenStore(store)
+
+	result, err := service.SendToUsers(ctx, []string{"a", "b", "c", "d"}, &PushNotification{Title: "t", Body: "b", Priority: PriorityHigh})
+	if err != nil {
+		t.Fatalf("SendToUsers() failed, err: %v", err)
+	}
+	if result.FallbackCount != 2 {
+		t.Errorf("FallbackCount = %d, want 2", result.FallbackCount)
+	}
+	if len(email.sent) != 3 {
+		t.Errorf("email sent to %v, want b, c and d", email.sent)
+	}
+	for _, address := range email.sent {
+		if address == "a@example.com" {
+			t.Errorf("用户 a 已送达设备，不应发送邮件")
+		}
+	}
+	if len(mobile.sent) != 2 {
+		t.Errorf("邮箱令牌不应发送到移动平台: %v", mobile.sent)
+	}
+
+	// 普通优先级没有配置兜底链
+	email.sent = nil
+	result, _ = service.SendToUser(ctx, "c", &PushNotification{Title: "t", Body: "b"})
+	if len(email.sent) != 0 || result.FallbackCount != 0 {
+		t.Errorf("普通优先级不应兜底: sent=%v, fallback=%d", email.sent, result.FallbackCount)
+	}
+}
+
+func TestSendToUsersRespectsTargetProviders(t *testing.T) {
+	expo := &stubProvider{name: ProviderTypeExpo}
+	other := &stubProvider{name: "fcm"}
+
+	service := NewPushService()
+	service.RegisterProvider(expo)
+	service.RegisterProvider(other)
+
+	store := NewMemoryTokenStore()
+	ctx := context.Background()
+	store.SetUserToken(ctx, "a", ProviderTypeExpo, "expo-a")
+	store.SetUserToken(ctx, "a", "fcm", "fcm-a")
+	service.SetUserTokenStore(store)
+
+	result, err := service.SendToUsers(ctx, []string{"a"}, &PushNotification{Title: "t", Body: "b", Providers: []string{ProviderTypeExpo}})
+	if err != nil {
+		t.Fatalf("SendToUsers() failed, err: %v", err)
+	}
+	if result.SuccessCount != 1 || len(expo.sent) != 1 || len(other.sent) != 0 {
+		t.Errorf("限定提供者后应只发送到 expo: expo=%v, fcm=%v", expo.sent, other.sent)
+	}
+
+	service.SendToUser(ctx, "a", &PushNotification{Title: "t", Body: "b"})
+	if len(expo.sent) != 2 || len(other.sent) != 1 {
+		t.Errorf("未限定提供者时应发送到所有平台: expo=%v, fcm=%v", expo.sent, other.sent)
+	}
+}
+
+func TestDataOnlyPushSkipsFallback(t *testing.T) {
+	mobile := &stubProvider{name: ProviderTypeExpo, fail: map[string]bool{"expo-a": true}}
+	email := &stubProvider{name: ProviderTypeEmail}
+
+	service := NewPushService()
+	service.RegisterProvider(mobile)
+	service.RegisterFallbackProvider(email)
+	service.SetFallbackChains(map[string][]string{PriorityNormal: {ProviderTypeEmail}})
+
+	store := NewMemoryTokenStore()
+	ctx := context.Background()
+	store.SetUserToken(ctx, "a", ProviderTypeExpo, "expo-a")
+	store.SetUserToken(ctx, "a", ProviderTypeEmail, "a@example.com")
+	service.SetUserTokenStore(store)
+
+	notification := &PushNotification{Data: map[string]interface{}{"sync": "inbox"}, ContentAvailable: true}
+	result, err := service.SendToUsers(ctx, []string{"a"}, notification)
+	if err != nil {
+		t.Fatalf("SendToUsers() failed, err: %v", err)
+	}
+	if len(mobile.sent) != 1 || len(email.sent) != 0 || result.FallbackCount != 0 {
+		t.Errorf("静默数据推送不应走兜底渠道: mobile=%v, email=%v", mobile.sent, email.sent)
+	}
+
+	message := NewExpoProvider(nil).buildExpoMessage("ExponentPushToken[a]", &PushNotification{
+		Data: notification.Data, Sound: "default", ContentAvailable: true,
+	})
+	if !message.ContentAvailable || message.Sound != "" || message.Title != "" || message.Body != "" {
+		t.Errorf("Expo 静默数据推送消息 = %+v", message)
+	}
+}
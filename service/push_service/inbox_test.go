@@ -0,0 +1,58 @@
+package push_service
+
+import (
+	"context"
+	"testing"
+)
+
+// memoryInbox 测试用虚拟收件箱
+type memoryInbox struct {
+	users    map[string]bool
+	messages map[string][]*PushNotification
+}
+
+func (m *memoryInbox) IsInboxUser(ctx context.Context, metaId string) (bool, error) {
+	return m.users[metaId], nil
+}
+
+func (m *memoryInbox) Deliver(ctx context.Context, metaId string, notification *PushNotification) error {
+	m.messages[metaId] = append(m.messages[metaId], notification)
+	return nil
+}
+
+func TestSendToUsersRoutesQAAccountsToInbox(t *testing.T) {
+	inbox := &memoryInbox{
+		users:    map[string]bool{"qa-user": true},
+		messages: make(map[string][]*PushNotification),
+	}
+	service := NewPushService()
+	service.SetVirtualInbox(inbox)
+
+	var listened []*PushResult
+	service.AddResultListener(func(notification *PushNotification, result *PushResult) {
+		listened = append(listened, result)
+	})
+
+	ctx := context.Background()
+	result, err := service.SendToUsers(ctx, []string{"qa-user", "real-user"}, &PushNotification{Title: "hello", Body: "b"})
+	if err != nil {
+		t.Fatalf("SendToUsers() failed, err: %v", err)
+	}
+	if result.SuccessCount != 1 || len(result.Results) != 1 || result.Results[0].Platform != ProviderTypeQAInbox {
+		t.Errorf("SendToUsers() = %+v, want one qa_inbox result", result)
+	}
+	if len(inbox.messages["qa-user"]) != 1 || len(inbox.messages["real-user"]) != 0 {
+		t.Errorf("inbox messages = %+v, want only qa-user", inbox.messages)
+	}
+	if len(listened) != 1 {
+		t.Errorf("result listener called %d times, want 1", len(listened))
+	}
+
+	result, err = service.SendToUser(ctx, "qa-user", &PushNotification{Title: "again", Body: "b"})
+	if err != nil {
+		t.Fatalf("SendToUser() failed, err: %v", err)
+	}
+	if result.SuccessCount != 1 || len(inbox.messages["qa-user"]) != 2 {
+		t.Errorf("SendToUser() = %+v, inbox = %d; want delivered to inbox", result, len(inbox.messages["qa-user"]))
+	}
+}
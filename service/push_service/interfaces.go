@@ -2,6 +2,7 @@ package push_service
 
 import (
 	"context"
+	"push-base-service/models"
 	"time"
 )
 
@@ -20,10 +21,16 @@ type PushProvider interface {
 	HealthCheck(ctx context.Context) error
 }
 
+// ReceiptChecker 支持查询投递回执的推送提供者（如 Expo）
+type ReceiptChecker interface {
+	// CheckReceipts 查询回执，结果中没有的回执ID表示回执尚未生成或已过期
+	CheckReceipts(ctx context.Context, receiptIDs []string) (map[string]*ReceiptOutcome, error)
+}
+
 // UserTokenStore 用户令牌存储接口
 type UserTokenStore interface {
 	// GetUserTokens 根据metaId获取用户的所有推送令牌
-	GetUserTokens(ctx context.Context, metaId string) (*UserPushTokens, error)
+	GetUserTokens(ctx context.Context, metaId string) (*models.UserPushTokens, error)
 
 	// SetUserToken 设置用户在指定平台的推送令牌
 	SetUserToken(ctx context.Context, metaId string, platform string, token string) error
@@ -32,45 +39,119 @@ type UserTokenStore interface {
 	RemoveUserToken(ctx context.Context, metaId string, platform string) error
 
 	// GetAllUserTokens 获取所有用户的令牌（用于批量推送）
-	GetAllUserTokens(ctx context.Context, metaIds []string) (map[string]*UserPushTokens, error)
+	GetAllUserTokens(ctx context.Context, metaIds []string) (map[string]*models.UserPushTokens, error)
+}
+
+// Throttler 推送限流器接口，按 key（接收用户 metaId）控制推送频率
+// 实现包括内存令牌桶、Pebble 滑动窗口、Redis（多实例共享）等，由配置选择
+type Throttler interface {
+	// Name 返回限流器名称（用于日志和监控）
+	Name() string
+
+	// Allow 判断 key 本次是否允许推送，允许时消耗一次配额
+	Allow(ctx context.Context, key string) (*ThrottleDecision, error)
+}
+
+// VirtualInbox QA 虚拟收件箱接口
+// 指定的 QA 账号收到的推送写入虚拟收件箱而不是发送到真实设备，便于端到端测试断言
+type VirtualInbox interface {
+	// IsInboxUser 判断用户是否为 QA 账号
+	IsInboxUser(ctx context.Context, metaId string) (bool, error)
+
+	// Deliver 将通知写入用户的虚拟收件箱
+	Deliver(ctx context.Context, metaId string, notification *PushNotification) error
 }
 
-// UserPushTokens 用户推送令牌信息
-type UserPushTokens struct {
-	MetaID    string            `json:"metaId" binding:"required"` // 用户唯一标识
-	Tokens    map[string]string `json:"tokens"`                    // 平台->令牌映射 {"expo": "ExponentPushToken[...]", "fcm": "fcm_token_123"}
-	UpdatedAt time.Time         `json:"updatedAt"`                 // 最后更新时间
+// OptOutList 全局退订名单，已退订的用户不接收任何推送
+type OptOutList interface {
+	// IsOptedOut 判断用户是否已退订推送
+	IsOptedOut(ctx context.Context, metaId string) (bool, error)
+}
+
+// QuotaGuard 租户推送配额，按用户所属租户统计每月推送量
+type QuotaGuard interface {
+	// Reserve 为租户预占 count 条投递配额，返回本次投递的处理方式
+	Reserve(ctx context.Context, tenantId string, count int) (*QuotaDecision, error)
+}
+
+// ThrottleDecision 限流判断结果
+type ThrottleDecision struct {
+	Allowed    bool          `json:"allowed"`    // 是否允许
+	Remaining  int           `json:"remaining"`  // 当前窗口剩余配额
+	RetryAfter time.Duration `json:"retryAfter"` // 被限流时建议的重试等待时间
+}
+
+// QuotaDecision 配额判断结果
+type QuotaDecision struct {
+	Action string `json:"action"` // 处理方式：allow / downgrade / reject
+	Used   int64  `json:"used"`   // 本月已用量（含本次）
+	Limit  int64  `json:"limit"`  // 本月上限，0 表示不限制
 }
 
 // PushNotification 推送通知内容
 type PushNotification struct {
-	Title    string                 `json:"title" binding:"required"` // 通知标题
-	Body     string                 `json:"body" binding:"required"`  // 通知内容
-	Data     map[string]interface{} `json:"data,omitempty"`           // 自定义数据
-	Sound    string                 `json:"sound,omitempty"`          // 声音
-	Badge    *int                   `json:"badge,omitempty"`          // 徽章数字
-	ImageURL string                 `json:"imageUrl,omitempty"`       // 图片URL
-	Priority string                 `json:"priority,omitempty"`       // 优先级 (normal/high)
+	Title            string                 `json:"title" binding:"required"`   // 通知标题
+	Body             string                 `json:"body" binding:"required"`    // 通知内容
+	Data             map[string]interface{} `json:"data,omitempty"`             // 自定义数据
+	Sound            string                 `json:"sound,omitempty"`            // 声音
+	Badge            *int                   `json:"badge,omitempty"`            // 徽章数字
+	ImageURL         string                 `json:"imageUrl,omitempty"`         // 图片URL
+	Priority         string                 `json:"priority,omitempty"`         // 优先级 (normal/high)
+	TTL              int                    `json:"ttl,omitempty"`              // 通知有效期（秒），0 表示使用推送平台默认值
+	ChannelID        string                 `json:"channelId,omitempty"`        // Android 通知渠道ID
+	Providers        []string               `json:"providers,omitempty"`        // 限定发送的推送提供者，空表示所有已注册的提供者
+	CollapseID       string                 `json:"collapseId,omitempty"`       // 折叠ID，相同折叠ID的新通知替换设备上的旧通知
+	ThreadID         string                 `json:"threadId,omitempty"`         // 分组ID，相同分组ID的通知在通知中心归为一组
+	SummaryArg       string                 `json:"summaryArg,omitempty"`       // 分组摘要参数（iOS summary-arg，如发送者名称），通知中心折叠分组时显示
+	CategoryID       string                 `json:"categoryId,omitempty"`       // 通知类别ID（iOS category / Expo categoryId），客户端据此显示回复、静音等操作按钮
+	ContentAvailable bool                   `json:"contentAvailable,omitempty"` // 后台静默推送（iOS content-available），不带标题和内容时为仅数据推送
+	DryRun           bool                   `json:"dryRun,omitempty"`           // 演练模式，完整执行推送流程但不调用推送平台，结果记为模拟
+	// TenantID 发起推送的调用方所属租户（API Key 绑定的租户，不从请求体读取），推送配额计入该租户；为空时按接收用户所属租户计
+	TenantID string `json:"-"`
+}
+
+// IsDataOnly 是否为不展示通知的静默数据推送
+func (n *PushNotification) IsDataOnly() bool {
+	return n.ContentAvailable && n.Title == "" && n.Body == ""
 }
 
 // PushResult 推送结果
 type PushResult struct {
 	MetaID    string        `json:"metaId"`              // 用户MetaID
+	TenantID  string        `json:"tenantId,omitempty"`  // 用户所属租户ID
 	Platform  string        `json:"platform"`            // 推送平台
 	Token     string        `json:"token"`               // 推送令牌
 	Success   bool          `json:"success"`             // 是否成功
 	ReceiptID string        `json:"receiptId,omitempty"` // 回执ID
 	Error     error         `json:"error,omitempty"`     // 错误信息
+	Simulated bool          `json:"simulated,omitempty"` // 演练模式下的模拟结果，未实际调用推送平台
+	Retries   int           `json:"retries,omitempty"`   // 推送平台内部的重试次数
 	Duration  time.Duration `json:"duration"`            // 处理耗时
 	Timestamp time.Time     `json:"timestamp"`           // 时间戳
 }
 
+// ReceiptOutcome 投递回执结果
+type ReceiptOutcome struct {
+	Delivered          bool   `json:"delivered"`                    // 是否已送达设备
+	Error              string `json:"error,omitempty"`              // 送达失败原因
+	DeviceUnregistered bool   `json:"deviceUnregistered,omitempty"` // 设备已注销（令牌失效）
+}
+
+// ResultListener 推送结果监听器，每产生一条推送结果时回调（需快速返回，避免阻塞推送）
+type ResultListener func(notification *PushNotification, result *PushResult)
+
 // BatchPushResult 批量推送结果
 type BatchPushResult struct {
 	TotalUsers     int           `json:"totalUsers"`     // 总用户数
 	TotalPlatforms int           `json:"totalPlatforms"` // 总平台数
 	SuccessCount   int           `json:"successCount"`   // 成功数
 	FailureCount   int           `json:"failureCount"`   // 失败数
+	ThrottledCount int           `json:"throttledCount"` // 被限流跳过的用户数
+	Unsubscribed   int           `json:"unsubscribed"`   // 已退订推送而跳过的用户数
+	QuotaRejected  int           `json:"quotaRejected"`  // 租户超出配额被拒绝的用户数
+	Downgraded     int           `json:"downgraded"`     // 租户超出配额降级发送的用户数
+	FallbackCount  int           `json:"fallbackCount"`  // 通过兜底渠道（如邮件）送达的用户数
+	DryRun         bool          `json:"dryRun"`         // 是否为演练模式（推送平台调用均为模拟）
 	Results        []*PushResult `json:"results"`        // 详细结果
 	Duration       time.Duration `json:"duration"`       // 总耗时
 	Timestamp      time.Time     `json:"timestamp"`      // 时间戳
@@ -90,6 +171,30 @@ type PushService interface {
 	// SetUserTokenStore 设置用户令牌存储
 	SetUserTokenStore(store UserTokenStore)
 
+	// AddResultListener 添加推送结果监听器
+	AddResultListener(listener ResultListener)
+
+	// SetThrottler 设置推送限流器，nil 表示不限流
+	SetThrottler(throttler Throttler)
+
+	// SetVirtualInbox 设置 QA 虚拟收件箱，nil 表示关闭 QA 模式
+	SetVirtualInbox(inbox VirtualInbox)
+
+	// SetQuotaGuard 设置租户推送配额，nil 表示不限制
+	SetQuotaGuard(guard QuotaGuard)
+
+	// SetOptOutList 设置全局退订名单，nil 表示不检查退订
+	SetOptOutList(list OptOutList)
+
+	// RegisterFallbackProvider 注册兜底提供者，仅在移动平台推送全部失败或用户没有移动平台令牌时使用
+	RegisterFallbackProvider(provider PushProvider) error
+
+	// SetFallbackChains 设置各通知优先级的兜底链（按顺序尝试，成功即止），nil 表示不兜底
+	SetFallbackChains(chains map[string][]string)
+
+	// SetDryRun 设置全局演练模式，开启后所有推送只执行流程、不调用推送平台
+	SetDryRun(enabled bool)
+
 	// HealthCheck 健康检查
 	HealthCheck(ctx context.Context) map[string]error
 
@@ -102,22 +207,22 @@ type PushService interface {
 
 // MemoryTokenStore 内存存储实现（用于测试和简单场景）
 type MemoryTokenStore struct {
-	tokens map[string]*UserPushTokens
+	tokens map[string]*models.UserPushTokens
 }
 
 // NewMemoryTokenStore 创建内存存储
 func NewMemoryTokenStore() *MemoryTokenStore {
 	return &MemoryTokenStore{
-		tokens: make(map[string]*UserPushTokens),
+		tokens: make(map[string]*models.UserPushTokens),
 	}
 }
 
 // GetUserTokens 获取用户令牌
-func (m *MemoryTokenStore) GetUserTokens(ctx context.Context, metaId string) (*UserPushTokens, error) {
+func (m *MemoryTokenStore) GetUserTokens(ctx context.Context, metaId string) (*models.UserPushTokens, error) {
 	if tokens, exists := m.tokens[metaId]; exists {
 		return tokens, nil
 	}
-	return &UserPushTokens{
+	return &models.UserPushTokens{
 		MetaID: metaId,
 		Tokens: make(map[string]string),
 	}, nil
@@ -126,13 +231,13 @@ func (m *MemoryTokenStore) GetUserTokens(ctx context.Context, metaId string) (*U
 // SetUserToken 设置用户令牌
 func (m *MemoryTokenStore) SetUserToken(ctx context.Context, metaId string, platform string, token string) error {
 	if m.tokens[metaId] == nil {
-		m.tokens[metaId] = &UserPushTokens{
+		m.tokens[metaId] = &models.UserPushTokens{
 			MetaID: metaId,
 			Tokens: make(map[string]string),
 		}
 	}
 	m.tokens[metaId].Tokens[platform] = token
-	m.tokens[metaId].UpdatedAt = time.Now()
+	m.tokens[metaId].UpdatedAt = time.Now().Unix()
 	return nil
 }
 
@@ -140,19 +245,19 @@ func (m *MemoryTokenStore) SetUserToken(ctx context.Context, metaId string, plat
 func (m *MemoryTokenStore) RemoveUserToken(ctx context.Context, metaId string, platform string) error {
 	if m.tokens[metaId] != nil {
 		delete(m.tokens[metaId].Tokens, platform)
-		m.tokens[metaId].UpdatedAt = time.Now()
+		m.tokens[metaId].UpdatedAt = time.Now().Unix()
 	}
 	return nil
 }
 
 // GetAllUserTokens 获取多个用户的令牌
-func (m *MemoryTokenStore) GetAllUserTokens(ctx context.Context, metaIds []string) (map[string]*UserPushTokens, error) {
-	result := make(map[string]*UserPushTokens)
+func (m *MemoryTokenStore) GetAllUserTokens(ctx context.Context, metaIds []string) (map[string]*models.UserPushTokens, error) {
+	result := make(map[string]*models.UserPushTokens)
 	for _, metaId := range metaIds {
 		if tokens, exists := m.tokens[metaId]; exists {
 			result[metaId] = tokens
 		} else {
-			result[metaId] = &UserPushTokens{
+			result[metaId] = &models.UserPushTokens{
 				MetaID: metaId,
 				Tokens: make(map[string]string),
 			}
@@ -169,4 +274,22 @@ const (
 	ProviderTypeExpo = "expo"
 	ProviderTypeFCM  = "fcm"
 	ProviderTypeAPNS = "apns"
+
+	// 桌面客户端平台：macOS 通过 APNs（桌面应用 Bundle ID）推送，Windows 通过 WNS 推送
+	// 桌面应用以这两个平台名称调用 set_user_tokens 登记令牌，对应的提供者以平台名称注册
+	PlatformMacOS   = "macos"
+	PlatformWindows = "windows"
+
+	// ProviderTypeEmail 邮件兜底渠道，用户邮箱以该平台的令牌形式登记
+	ProviderTypeEmail = "email"
+
+	// ProviderTypeMock 模拟推送平台，仅在预发环境注册，用于压测和演示数据
+	ProviderTypeMock = "mock"
+
+	// ProviderTypeQAInbox QA 虚拟收件箱（非真实推送平台，仅出现在推送结果中）
+	ProviderTypeQAInbox = "qa_inbox"
+
+	QuotaActionAllow     = "allow"     // 配额内，正常发送
+	QuotaActionDowngrade = "downgrade" // 超出配额，降级发送（普通优先级、不带图片）
+	QuotaActionReject    = "reject"    // 超出配额，不发送
 )
@@ -3,13 +3,19 @@ package push_service
 import (
 	"context"
 	"fmt"
+	"push-base-service/models"
+	"push-base-service/service/apns_service"
+	"push-base-service/service/email_service"
 	"push-base-service/service/expo_service"
+	"push-base-service/service/wns_service"
 	"sync"
+	"time"
 )
 
 // Manager 推送服务管理器
 type Manager struct {
 	service PushService
+	expo    *ExpoProvider // 已注册的 Expo 提供者，用于热更新配置
 	mu      sync.RWMutex
 }
 
@@ -25,10 +31,93 @@ func (m *Manager) RegisterExpoProvider(config *expo_service.Config) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	// 出站代理和证书等配置错误时注册失败，而不是绕过出口网关直连
+	if config != nil {
+		config.ApplyDefaults()
+		if err := config.Validate(); err != nil {
+			return err
+		}
+	}
+
 	provider := NewExpoProvider(config)
+	if err := m.service.RegisterProvider(provider); err != nil {
+		return err
+	}
+	m.expo = provider
+	return nil
+}
+
+// UpdateExpoConfig 热更新已注册的 Expo 提供者配置（超时、重试、批量大小等），无需重新注册
+func (m *Manager) UpdateExpoConfig(config *expo_service.Config) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.expo == nil {
+		return fmt.Errorf("Expo 推送提供者未注册")
+	}
+	return m.expo.UpdateConfig(config)
+}
+
+// RegisterEmailProvider 注册邮件兜底提供者
+func (m *Manager) RegisterEmailProvider(config *email_service.Config) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	provider, err := NewEmailProvider(config)
+	if err != nil {
+		return err
+	}
+	return m.service.RegisterFallbackProvider(provider)
+}
+
+// RegisterAPNSProvider 注册 APNs 推送提供者，platform 为令牌登记的平台名称（如 PlatformMacOS）
+func (m *Manager) RegisterAPNSProvider(platform string, config *apns_service.Config) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	provider, err := NewAPNSProvider(platform, config)
+	if err != nil {
+		return err
+	}
 	return m.service.RegisterProvider(provider)
 }
 
+// RegisterWNSProvider 注册 Windows 推送通知服务提供者（平台 PlatformWindows）
+func (m *Manager) RegisterWNSProvider(config *wns_service.Config) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	provider, err := NewWNSProvider(config)
+	if err != nil {
+		return err
+	}
+	return m.service.RegisterProvider(provider)
+}
+
+// RegisterMockProvider 注册模拟推送提供者（仅预发环境使用）
+func (m *Manager) RegisterMockProvider(latency time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.service.RegisterProvider(NewMockProvider(latency))
+}
+
+// SetFallbackChains 设置各通知优先级的兜底链（优先级 -> 兜底提供者名称），nil 表示不兜底
+func (m *Manager) SetFallbackChains(chains map[string][]string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.service.SetFallbackChains(chains)
+}
+
+// SetDryRun 设置全局演练模式，开启后推送流程照常执行但不调用推送平台
+func (m *Manager) SetDryRun(enabled bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.service.SetDryRun(enabled)
+}
+
 // SendToUser 发送通知给指定用户的所有平台
 func (m *Manager) SendToUser(ctx context.Context, metaId, title, body string) (*BatchPushResult, error) {
 	notification := &PushNotification{
@@ -95,7 +184,7 @@ func (m *Manager) SetUserToken(ctx context.Context, metaId, platform, token stri
 }
 
 // GetUserTokens 获取用户的所有推送令牌
-func (m *Manager) GetUserTokens(ctx context.Context, metaId string) (*UserPushTokens, error) {
+func (m *Manager) GetUserTokens(ctx context.Context, metaId string) (*models.UserPushTokens, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
@@ -126,6 +215,43 @@ func (m *Manager) SetTokenStore(store UserTokenStore) {
 	m.service.SetUserTokenStore(store)
 }
 
+// SetThrottler 设置推送限流器，nil 表示不限流
+func (m *Manager) SetThrottler(throttler Throttler) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.service.SetThrottler(throttler)
+}
+
+// SetVirtualInbox 设置 QA 虚拟收件箱，nil 表示关闭 QA 模式
+func (m *Manager) SetVirtualInbox(inbox VirtualInbox) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.service.SetVirtualInbox(inbox)
+}
+
+// SetQuotaGuard 设置租户推送配额，nil 表示不限制
+func (m *Manager) SetQuotaGuard(guard QuotaGuard) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.service.SetQuotaGuard(guard)
+}
+
+// SetOptOutList 设置全局退订名单，nil 表示不检查退订
+func (m *Manager) SetOptOutList(list OptOutList) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.service.SetOptOutList(list)
+}
+
+// AddResultListener 添加推送结果监听器（如投递 Webhook、审计等）
+func (m *Manager) AddResultListener(listener ResultListener) {
+	m.service.AddResultListener(listener)
+}
+
 // GetProviders 获取所有注册的提供者
 func (m *Manager) GetProviders() []string {
 	m.mu.RLock()
@@ -138,6 +264,30 @@ func (m *Manager) GetProviders() []string {
 	return []string{}
 }
 
+// CheckReceipts 查询指定平台的投递回执
+func (m *Manager) CheckReceipts(ctx context.Context, platform string, receiptIDs []string) (map[string]*ReceiptOutcome, error) {
+	if defaultService, ok := m.service.(*DefaultPushService); ok {
+		return defaultService.CheckReceipts(ctx, platform, receiptIDs)
+	}
+	return nil, fmt.Errorf("%w: %s", ErrReceiptsUnsupported, platform)
+}
+
+// SendTestPush 发送测试推送，不经过限流、配额、QA 收件箱和兜底渠道
+func (m *Manager) SendTestPush(ctx context.Context, target *TestPushTarget, notification *PushNotification) ([]*PushResult, error) {
+	if defaultService, ok := m.service.(*DefaultPushService); ok {
+		return defaultService.SendTestPush(ctx, target, notification)
+	}
+	return nil, fmt.Errorf("推送服务不支持测试推送")
+}
+
+// WaitForReceipts 等待推送结果的投递回执，最多等待 wait
+func (m *Manager) WaitForReceipts(ctx context.Context, results []*PushResult, wait time.Duration) map[string]*ReceiptOutcome {
+	if defaultService, ok := m.service.(*DefaultPushService); ok {
+		return defaultService.WaitForReceipts(ctx, results, wait)
+	}
+	return map[string]*ReceiptOutcome{}
+}
+
 // HealthCheck 健康检查
 func (m *Manager) HealthCheck(ctx context.Context) map[string]error {
 	return m.service.HealthCheck(ctx)
@@ -152,3 +302,25 @@ func (m *Manager) Start() error {
 func (m *Manager) Stop() error {
 	return m.service.Stop()
 }
+
+// 全局推送服务管理器实例（供 HTTP 接口等非推送中心模块使用）
+var (
+	globalManager   *Manager
+	globalManagerMu sync.RWMutex
+)
+
+// SetGlobalManager 设置全局推送服务管理器
+func SetGlobalManager(manager *Manager) {
+	globalManagerMu.Lock()
+	defer globalManagerMu.Unlock()
+
+	globalManager = manager
+}
+
+// GetGlobalManager 获取全局推送服务管理器，推送中心未启用时返回 nil
+func GetGlobalManager() *Manager {
+	globalManagerMu.RLock()
+	defer globalManagerMu.RUnlock()
+
+	return globalManager
+}
@@ -0,0 +1,56 @@
+package push_service
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// MockTokenPrefix 模拟提供者接受的令牌前缀
+const MockTokenPrefix = "mock-"
+
+// MockProvider 模拟推送提供者，仅用于预发/压测环境：不发出任何请求，直接返回成功
+// 测试数据以平台 "mock" 登记令牌，推送链路（过滤、限流、投递记录等）照常执行
+type MockProvider struct {
+	latency time.Duration
+}
+
+// NewMockProvider 创建模拟推送提供者，latency 为每次发送模拟的耗时
+func NewMockProvider(latency time.Duration) *MockProvider {
+	return &MockProvider{latency: latency}
+}
+
+// GetName 返回提供者名称
+func (p *MockProvider) GetName() string {
+	return ProviderTypeMock
+}
+
+// SendNotification 模拟发送，等待 latency 后返回成功
+func (p *MockProvider) SendNotification(ctx context.Context, token string, notification *PushNotification) (*PushResult, error) {
+	startTime := time.Now()
+
+	if p.latency > 0 {
+		select {
+		case <-ctx.Done():
+			return &PushResult{Token: token, Success: false, Error: ctx.Err(), Duration: time.Since(startTime), Timestamp: time.Now()}, nil
+		case <-time.After(p.latency):
+		}
+	}
+
+	return &PushResult{
+		Token:     token,
+		Success:   true,
+		Duration:  time.Since(startTime),
+		Timestamp: time.Now(),
+	}, nil
+}
+
+// ValidateToken 只接受 mock- 开头的令牌，避免误把真实令牌登记到模拟平台
+func (p *MockProvider) ValidateToken(token string) bool {
+	return strings.HasPrefix(token, MockTokenPrefix)
+}
+
+// HealthCheck 健康检查
+func (p *MockProvider) HealthCheck(ctx context.Context) error {
+	return nil
+}
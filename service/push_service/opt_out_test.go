@@ -0,0 +1,58 @@
+package push_service
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// stubOptOutList 指定用户已退订的测试退订名单，failing 中的用户检查出错
+type stubOptOutList struct {
+	optedOut map[string]bool
+	failing  map[string]bool
+}
+
+func (s *stubOptOutList) IsOptedOut(ctx context.Context, metaId string) (bool, error) {
+	if s.failing[metaId] {
+		return false, errors.New("storage unavailable")
+	}
+	return s.optedOut[metaId], nil
+}
+
+func TestSendSkipsUnsubscribedUsers(t *testing.T) {
+	mobile := &stubProvider{name: ProviderTypeExpo}
+	service := NewPushService()
+	service.RegisterProvider(mobile)
+
+	store := NewMemoryTokenStore()
+	ctx := context.Background()
+	for _, metaId := range []string{"a", "b", "c"} {
+		store.SetUserToken(ctx, metaId, ProviderTypeExpo, "expo-"+metaId)
+	}
+	service.SetUserTokenStore(store)
+	service.SetOptOutList(&stubOptOutList{optedOut: map[string]bool{"b": true}, failing: map[string]bool{"c": true}})
+
+	// 退订名单出错时同样不推送
+	result, err := service.SendToUsers(ctx, []string{"a", "b", "c"}, &PushNotification{Title: "t", Body: "b"})
+	if err != nil {
+		t.Fatalf("SendToUsers() failed, err: %v", err)
+	}
+	if result.TotalUsers != 3 || result.Unsubscribed != 2 || result.SuccessCount != 1 {
+		t.Errorf("SendToUsers() = %+v, want 3 users, 2 unsubscribed, 1 sent", result)
+	}
+	if len(mobile.sent) != 1 || mobile.sent[0] != "expo-a" {
+		t.Errorf("sent = %v, want only expo-a", mobile.sent)
+	}
+
+	result, err = service.SendToUser(ctx, "b", &PushNotification{Title: "t", Body: "b"})
+	if err != nil {
+		t.Fatalf("SendToUser() failed, err: %v", err)
+	}
+	if result.Unsubscribed != 1 || len(result.Results) != 0 {
+		t.Errorf("SendToUser() = %+v, want skipped as unsubscribed", result)
+	}
+
+	if _, err := service.SendTestPush(ctx, &TestPushTarget{MetaID: "b"}, &PushNotification{Title: "t", Body: "b"}); err == nil {
+		t.Error("SendTestPush() to an unsubscribed user succeeded, want error")
+	}
+}
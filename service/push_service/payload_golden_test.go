@@ -0,0 +1,139 @@
+package push_service
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"push-base-service/service/expo_service"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// updateGolden 重新生成 testdata 下的期望文件：go test ./service/push_service -run Golden -update
+var updateGolden = flag.Bool("update", false, "rewrite golden payload files")
+
+// expoPayloadLimit APNs 对单条通知负载的限制（FCM 为 4000 字节），普通聊天通知不应超过
+const expoPayloadLimit = 4096
+
+// payloadCase 一个负载兼容性用例
+type payloadCase struct {
+	name         string
+	notification *PushNotification
+	withinLimit  bool // 是否要求负载不超过 expoPayloadLimit
+}
+
+func payloadCases() []payloadCase {
+	badge := 3
+	hugeData := make(map[string]interface{})
+	for i := 0; i < 200; i++ {
+		hugeData[fmt.Sprintf("field_%03d", i)] = strings.Repeat("x", 32)
+	}
+
+	return []payloadCase{
+		{
+			name: "plain_chat",
+			notification: &PushNotification{
+				Title: "New Message in Group", Body: "alice sent a message", Sound: "default", Priority: PriorityNormal,
+				Data: map[string]interface{}{"type": "group_chat", "groupId": "group1", "pinId": "pin-1"},
+			},
+			withinLimit: true,
+		},
+		{
+			name: "max_length_cjk_title",
+			notification: &PushNotification{
+				Title: strings.Repeat("新消息通知", 40), Body: strings.Repeat("这是一条很长的中文预览内容", 8) + "...",
+				Priority: PriorityHigh, ChannelID: "chat", ThreadID: "group:group1", CollapseID: "group:group1",
+			},
+			withinLimit: true,
+		},
+		{
+			name: "emoji_only_name",
+			notification: &PushNotification{
+				Title: "New Message", Body: "🎉🔥👨‍👩‍👧‍👦: 🙂🙃😉", Sound: "default", Badge: &badge,
+				Data: map[string]interface{}{"type": "private_chat", "metaId": "🦄"},
+			},
+			withinLimit: true,
+		},
+		{
+			name: "data_only",
+			notification: &PushNotification{
+				Sound: "default", ContentAvailable: true,
+				Data: map[string]interface{}{"type": "sync", "cursor": 42},
+			},
+			withinLimit: true,
+		},
+		{
+			name: "rich_image",
+			notification: &PushNotification{
+				Title: "图片", Body: "alice: [图片]", ImageURL: "https://example.com/a.jpg?w=1080&h=720", TTL: 3600,
+			},
+			withinLimit: true,
+		},
+		{
+			name: "huge_data_map",
+			notification: &PushNotification{
+				Title: "New Message", Body: "bulk", Data: hugeData,
+			},
+		},
+	}
+}
+
+// checkGolden 比较输出与 testdata 下的期望文件，-update 时改为写入
+func checkGolden(t *testing.T, path string, got []byte) {
+	t.Helper()
+
+	if *updateGolden {
+		if err := os.WriteFile(path, got, 0644); err != nil {
+			t.Fatalf("写入期望文件失败: %v", err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("读取期望文件失败（使用 -update 生成）: %v", err)
+	}
+	if string(want) != string(got) {
+		t.Errorf("%s 与期望不一致:\n--- want\n%s\n--- got\n%s", path, want, got)
+	}
+}
+
+func TestExpoPayloadGolden(t *testing.T) {
+	provider := NewExpoProvider(expo_service.DefaultConfig())
+
+	for _, tc := range payloadCases() {
+		t.Run(tc.name, func(t *testing.T) {
+			message := provider.buildExpoMessage("ExponentPushToken[golden]", tc.notification)
+			wire, err := json.Marshal(message)
+			if err != nil {
+				t.Fatalf("序列化 Expo 消息失败: %v", err)
+			}
+			if !utf8.Valid(wire) {
+				t.Errorf("payload is not valid UTF-8")
+			}
+			if tc.withinLimit && len(wire) > expoPayloadLimit {
+				t.Errorf("payload size = %d bytes, want <= %d", len(wire), expoPayloadLimit)
+			}
+
+			pretty, _ := json.MarshalIndent(message, "", "  ")
+			checkGolden(t, filepath.Join("testdata", "payloads", "expo_"+tc.name+".golden.json"), append(pretty, '\n'))
+		})
+	}
+}
+
+func TestEmailDigestGolden(t *testing.T) {
+	for _, tc := range payloadCases() {
+		if tc.notification.IsDataOnly() || tc.name == "huge_data_map" {
+			continue
+		}
+		t.Run(tc.name, func(t *testing.T) {
+			digest := buildEmailDigest(tc.notification)
+			if !utf8.ValidString(digest) {
+				t.Errorf("digest is not valid UTF-8")
+			}
+			checkGolden(t, filepath.Join("testdata", "payloads", "email_"+tc.name+".golden.txt"), []byte(digest))
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package push_service
+
+import (
+	"push-base-service/service/metrics_service"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	// providerSendsCounter 推送平台调用次数
+	providerSendsCounter = metrics_service.NewCounterVec(
+		"push_provider_sends_total", "Number of provider send calls by provider and result", "provider", "result")
+	// providerRetriesCounter 推送平台内部重试次数
+	providerRetriesCounter = metrics_service.NewCounterVec(
+		"push_provider_retries_total", "Number of retries made inside providers", "provider")
+	// providerLatencySeconds 推送平台调用的累计耗时，除以调用次数即平均耗时
+	providerLatencySeconds = metrics_service.NewCounterVec(
+		"push_provider_latency_seconds_total", "Total time spent in provider send calls", "provider")
+	// providerLastSuccessGauge 最近一次推送成功的时间
+	providerLastSuccessGauge = metrics_service.NewGaugeVec(
+		"push_provider_last_success_timestamp_seconds", "Unix time of the last successful send per provider", "provider")
+)
+
+// ProviderStats 推送提供者的发送统计（进程启动以来）
+type ProviderStats struct {
+	Provider      string  `json:"provider"`                // 提供者名称
+	Sent          int64   `json:"sent"`                    // 发送成功次数
+	Failed        int64   `json:"failed"`                  // 发送失败次数
+	Retried       int64   `json:"retried"`                 // 提供者内部重试次数
+	AvgLatencyMs  float64 `json:"avgLatencyMs"`            // 平均耗时（毫秒）
+	LastError     string  `json:"lastError,omitempty"`     // 最近一次失败原因
+	LastErrorAt   int64   `json:"lastErrorAt,omitempty"`   // 最近一次失败时间（Unix 秒）
+	LastSuccessAt int64   `json:"lastSuccessAt,omitempty"` // 最近一次成功时间（Unix 秒）
+}
+
+// providerStatsEntry 单个提供者的累计统计
+type providerStatsEntry struct {
+	stats        ProviderStats
+	totalLatency time.Duration
+}
+
+var (
+	providerStats   = make(map[string]*providerStatsEntry)
+	providerStatsMu sync.Mutex
+)
+
+// recordProviderSend 记录一次推送平台调用的结果
+func recordProviderSend(provider string, result *PushResult) {
+	status := "success"
+	if !result.Success {
+		status = "failure"
+	}
+	providerSendsCounter.Inc(provider, status)
+	providerLatencySeconds.Add(result.Duration.Seconds(), provider)
+	if result.Retries > 0 {
+		providerRetriesCounter.Add(float64(result.Retries), provider)
+	}
+	if result.Success {
+		providerLastSuccessGauge.Set(float64(result.Timestamp.Unix()), provider)
+	}
+
+	providerStatsMu.Lock()
+	defer providerStatsMu.Unlock()
+
+	entry, exists := providerStats[provider]
+	if !exists {
+		entry = &providerStatsEntry{stats: ProviderStats{Provider: provider}}
+		providerStats[provider] = entry
+	}
+	entry.stats.Retried += int64(result.Retries)
+	entry.totalLatency += result.Duration
+	if result.Success {
+		entry.stats.Sent++
+		entry.stats.LastSuccessAt = result.Timestamp.Unix()
+		return
+	}
+	entry.stats.Failed++
+	entry.stats.LastErrorAt = result.Timestamp.Unix()
+	entry.stats.LastError = "unknown error"
+	if result.Error != nil {
+		entry.stats.LastError = result.Error.Error()
+	}
+}
+
+// GetProviderStats 获取各推送提供者的发送统计，按提供者名称排序（只包含已调用过的提供者）
+func GetProviderStats() []*ProviderStats {
+	providerStatsMu.Lock()
+	defer providerStatsMu.Unlock()
+
+	list := make([]*ProviderStats, 0, len(providerStats))
+	for _, entry := range providerStats {
+		stats := entry.stats
+		if calls := stats.Sent + stats.Failed; calls > 0 {
+			stats.AvgLatencyMs = float64(entry.totalLatency.Microseconds()) / 1000 / float64(calls)
+		}
+		list = append(list, &stats)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Provider < list[j].Provider
+	})
+	return list
+}
@@ -0,0 +1,38 @@
+package push_service
+
+import (
+	"context"
+	"testing"
+)
+
+func TestProviderStats(t *testing.T) {
+	provider := &stubProvider{name: "stats-test", fail: map[string]bool{"bad": true}}
+	service := NewPushService()
+	ctx := context.Background()
+	notification := &PushNotification{Title: "t", Body: "b"}
+
+	service.sendSingleNotification(ctx, "a", provider.name, "good", provider, notification)
+	service.sendSingleNotification(ctx, "b", provider.name, "good", provider, notification)
+	service.sendSingleNotification(ctx, "c", provider.name, "bad", provider, notification)
+	// 演练模式不调用推送平台，不计入统计
+	service.sendSingleNotification(ctx, "d", provider.name, "good", provider, &PushNotification{Title: "t", DryRun: true})
+
+	var stats *ProviderStats
+	for _, s := range GetProviderStats() {
+		if s.Provider == provider.name {
+			stats = s
+		}
+	}
+	if stats == nil {
+		t.Fatalf("GetProviderStats() has no entry for %s", provider.name)
+	}
+	if stats.Sent != 2 || stats.Failed != 1 {
+		t.Errorf("sent/failed = %d/%d, want 2/1", stats.Sent, stats.Failed)
+	}
+	if stats.LastError != "send failed" || stats.LastErrorAt == 0 || stats.LastSuccessAt == 0 {
+		t.Errorf("last error/success = %q at %d, success at %d", stats.LastError, stats.LastErrorAt, stats.LastSuccessAt)
+	}
+	if got := providerSendsCounter.Get(provider.name, "success"); got != 2 {
+		t.Errorf("push_provider_sends_total{result=success} = %v, want 2", got)
+	}
+}
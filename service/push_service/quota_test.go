@@ -0,0 +1,124 @@
+package push_service
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+// recordingProvider 记录发送内容的测试推送提供者
+type recordingProvider struct {
+	mu   sync.Mutex
+	sent map[string]*PushNotification
+}
+
+func (p *recordingProvider) GetName() string { return "recording" }
+
+func (p *recordingProvider) SendNotification(ctx context.Context, token string, notification *PushNotification) (*PushResult, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.sent[token] = notification
+	return &PushResult{Success: true}, nil
+}
+
+func (p *recordingProvider) ValidateToken(token string) bool { return true }
+
+func (p *recordingProvider) HealthCheck(ctx context.Context) error { return nil }
+
+// fixedQuota 按租户返回固定处理方式的测试配额
+type fixedQuota struct {
+	actions  map[string]string
+	reserved map[string]int
+}
+
+func (q *fixedQuota) Reserve(ctx context.Context, tenantId string, count int) (*QuotaDecision, error) {
+	q.reserved[tenantId] += count
+	action := q.actions[tenantId]
+	if action == "" {
+		action = QuotaActionAllow
+	}
+	return &QuotaDecision{Action: action}, nil
+}
+
+func newQuotaTestService(t *testing.T, quota QuotaGuard) (*DefaultPushService, *recordingProvider) {
+	t.Helper()
+
+	provider := &recordingProvider{sent: make(map[string]*PushNotification)}
+	service := NewPushService()
+	if err := service.RegisterProvider(provider); err != nil {
+		t.Fatalf("RegisterProvider() failed, err: %v", err)
+	}
+	service.SetQuotaGuard(quota)
+
+	store := NewMemoryTokenStore()
+	ctx := context.Background()
+	for _, user := range []struct{ metaId, tenantId string }{{"a", "ok"}, {"b", "over"}, {"c", "slow"}, {"d", ""}} {
+		store.SetUserToken(ctx, user.metaId, "recording", "token-"+user.metaId)
+		store.tokens[user.metaId].TenantID = user.tenantId
+	}
+	service.SetUserTokenStore(store)
+	return service, provider
+}
+
+func TestSendToUsersAppliesTenantQuota(t *testing.T) {
+	quota := &fixedQuota{
+		actions:  map[string]string{"over": QuotaActionReject, "slow": QuotaActionDowngrade},
+		reserved: make(map[string]int),
+	}
+	service, provider := newQuotaTestService(t, quota)
+
+	notification := &PushNotification{Title: "t", Body: "b", Priority: PriorityHigh, ImageURL: "https://example.com/a.png"}
+	result, err := service.SendToUsers(context.Background(), []string{"a", "b", "c", "d"}, notification)
+	if err != nil {
+		t.Fatalf("SendToUsers() failed, err: %v", err)
+	}
+	if result.QuotaRejected != 1 || result.Downgraded != 1 || result.SuccessCount != 3 {
+		t.Errorf("SendToUsers() = rejected %d, downgraded %d, success %d; want 1, 1, 3", result.QuotaRejected, result.Downgraded, result.SuccessCount)
+	}
+
+	if _, sent := provider.sent["token-b"]; sent {
+		t.Errorf("over-quota tenant should not be sent to")
+	}
+	if got := provider.sent["token-a"]; got.Priority != PriorityHigh || got.ImageURL == "" {
+		t.Errorf("tenant within quota should get the original notification, got %+v", got)
+	}
+	if got := provider.sent["token-c"]; got.Priority != PriorityNormal || got.ImageURL != "" {
+		t.Errorf("downgraded tenant should get normal priority without image, got %+v", got)
+	}
+	if notification.Priority != PriorityHigh {
+		t.Errorf("downgrade must not modify the caller's notification")
+	}
+	if _, reserved := quota.reserved[""]; reserved {
+		t.Errorf("users without a tenant should not be counted")
+	}
+}
+
+func TestSendToUserRejectedByTenantQuota(t *testing.T) {
+	quota := &fixedQuota{actions: map[string]string{"over": QuotaActionReject}, reserved: make(map[string]int)}
+	service, provider := newQuotaTestService(t, quota)
+
+	result, err := service.SendToUser(context.Background(), "b", &PushNotification{Title: "t", Body: "b"})
+	if err != nil {
+		t.Fatalf("SendToUser() failed, err: %v", err)
+	}
+	if result.QuotaRejected != 1 || len(provider.sent) != 0 {
+		t.Errorf("SendToUser() = %+v, sent %d; want rejected without sending", result, len(provider.sent))
+	}
+}
+
+func TestSendToUsersChargesCallerTenant(t *testing.T) {
+	quota := &fixedQuota{actions: map[string]string{"partner": QuotaActionReject}, reserved: make(map[string]int)}
+	service, provider := newQuotaTestService(t, quota)
+
+	// 调用方绑定了租户时，全部投递（包括属于其他租户和不属于任何租户的用户）计入调用方租户
+	result, err := service.SendToUsers(context.Background(), []string{"a", "d"}, &PushNotification{Title: "t", Body: "b", TenantID: "partner"})
+	if err != nil {
+		t.Fatalf("SendToUsers() failed, err: %v", err)
+	}
+	if result.QuotaRejected != 2 || len(provider.sent) != 0 {
+		t.Errorf("SendToUsers() = rejected %d, sent %d; want 2 rejected without sending", result.QuotaRejected, len(provider.sent))
+	}
+	if quota.reserved["partner"] != 2 || quota.reserved["ok"] != 0 {
+		t.Errorf("reserved = %v, want 2 deliveries charged to partner only", quota.reserved)
+	}
+}
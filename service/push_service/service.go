@@ -2,24 +2,68 @@ package push_service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log"
+	"push-base-service/models"
+	"push-base-service/service/metrics_service"
+	"push-base-service/service/tracing_service"
+	"slices"
 	"sync"
 	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// throttledCounter 被限流跳过的推送用户数
+var throttledCounter = metrics_service.NewCounterVec(
+	"push_throttled_total", "Number of recipients skipped by the push throttler", "throttler")
+
+// unsubscribedCounter 已退订推送而跳过的用户数
+var unsubscribedCounter = metrics_service.NewCounterVec(
+	"push_unsubscribed_skipped_total", "Number of recipients skipped because they unsubscribed from push")
+
+// fallbackCounter 兜底渠道投递次数
+var fallbackCounter = metrics_service.NewCounterVec(
+	"push_fallback_total", "Number of fallback deliveries by provider and result", "provider", "result")
+
+// dryRunCounter 演练模式下跳过的推送平台调用次数
+var dryRunCounter = metrics_service.NewCounterVec(
+	"push_dry_run_total", "Number of provider calls skipped and simulated in dry-run mode", "platform")
+
+// ErrReceiptsUnsupported 推送平台不支持查询投递回执
+var ErrReceiptsUnsupported = errors.New("推送平台不支持查询投递回执")
+
+// 测试推送目标错误，接口据此区分参数错误和目标用户不存在
+var (
+	ErrInvalidTestTarget  = errors.New("测试推送目标无效")
+	ErrNoRegisteredTokens = errors.New("没有登记推送令牌")
+	ErrUserUnsubscribed   = errors.New("已退订推送")
 )
 
 // DefaultPushService 默认推送服务实现
 type DefaultPushService struct {
 	providers  map[string]PushProvider
 	tokenStore UserTokenStore
-	mu         sync.RWMutex
-	running    bool
+	throttler  Throttler
+	inbox      VirtualInbox
+	quota      QuotaGuard
+	optOut     OptOutList
+	listeners  []ResultListener
+
+	fallbackProviders map[string]PushProvider // 兜底提供者，不参与常规推送
+	fallbackChains    map[string][]string     // 通知优先级 -> 兜底提供者名称
+	dryRun            bool                    // 全局演练模式
+	mu                sync.RWMutex
+	running           bool
 }
 
 // NewPushService 创建新的推送服务
 func NewPushService() *DefaultPushService {
 	return &DefaultPushService{
-		providers:  make(map[string]PushProvider),
-		tokenStore: NewMemoryTokenStore(), // 默认使用内存存储
+		providers:         make(map[string]PushProvider),
+		tokenStore:        NewMemoryTokenStore(), // 默认使用内存存储
+		fallbackProviders: make(map[string]PushProvider),
 	}
 }
 
@@ -27,8 +71,43 @@ func NewPushService() *DefaultPushService {
 func (s *DefaultPushService) SendToUser(ctx context.Context, metaId string, notification *PushNotification) (*BatchPushResult, error) {
 	startTime := time.Now()
 
+	// 已退订推送的用户不推送
+	if subscribed, _ := s.applyOptOut(ctx, []string{metaId}); len(subscribed) == 0 {
+		return &BatchPushResult{
+			TotalUsers:   1,
+			Unsubscribed: 1,
+			Results:      []*PushResult{},
+			Duration:     time.Since(startTime),
+			Timestamp:    time.Now(),
+		}, nil
+	}
+
+	// QA 账号写入虚拟收件箱，不发送到真实设备
+	inboxResults, _, inboxThrottled := s.deliverToInboxUsers(ctx, []string{metaId}, notification)
+	if inboxThrottled > 0 {
+		return throttledResult(startTime), nil
+	}
+	if len(inboxResults) > 0 {
+		successCount := 0
+		if inboxResults[0].Success {
+			successCount = 1
+		}
+		return &BatchPushResult{
+			TotalUsers:     1,
+			TotalPlatforms: 1,
+			SuccessCount:   successCount,
+			FailureCount:   1 - successCount,
+			Results:        inboxResults,
+			Duration:       time.Since(startTime),
+			Timestamp:      time.Now(),
+		}, nil
+	}
+
 	// 获取用户的推送令牌
-	userTokens, err := s.tokenStore.GetUserTokens(ctx, metaId)
+	tokenCtx, span := tracing_service.Start(ctx, "token_lookup", attribute.Int("users", 1))
+	userTokens, err := s.tokenStore.GetUserTokens(tokenCtx, metaId)
+	tracing_service.RecordError(span, err)
+	span.End()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user tokens for metaId %s: %w", metaId, err)
 	}
@@ -45,6 +124,27 @@ func (s *DefaultPushService) SendToUser(ctx context.Context, metaId string, noti
 		}, nil
 	}
 
+	// 限流检查放在令牌查询成功之后，查询失败或没有令牌时不消耗配额
+	if allowed, _ := s.applyThrottle(ctx, []string{metaId}); len(allowed) == 0 {
+		return throttledResult(startTime), nil
+	}
+
+	// 租户配额检查
+	quotaTokens, downgraded, quotaRejected := s.applyQuota(ctx, notification, map[string]*models.UserPushTokens{metaId: userTokens})
+	if quotaRejected > 0 {
+		return &BatchPushResult{
+			TotalUsers:    1,
+			QuotaRejected: quotaRejected,
+			Results:       []*PushResult{},
+			Duration:      time.Since(startTime),
+			Timestamp:     time.Now(),
+		}, nil
+	}
+	userTokens = quotaTokens[metaId]
+	if downgraded[metaId] {
+		notification = downgradeNotification(notification)
+	}
+
 	// 并发发送到所有平台
 	var results []*PushResult
 	var mu sync.Mutex
@@ -52,12 +152,14 @@ func (s *DefaultPushService) SendToUser(ctx context.Context, metaId string, noti
 
 	s.mu.RLock()
 	for platform, token := range userTokens.Tokens {
-		if provider, exists := s.providers[platform]; exists {
+		if provider, exists := s.providerFor(platform, notification); exists {
 			wg.Add(1)
 			go func(p string, t string, prov PushProvider) {
 				defer wg.Done()
 
 				result := s.sendSingleNotification(ctx, metaId, p, t, prov, notification)
+				result.TenantID = userTokens.TenantID
+				s.notifyResultListeners(notification, result)
 
 				mu.Lock()
 				results = append(results, result)
@@ -69,6 +171,12 @@ func (s *DefaultPushService) SendToUser(ctx context.Context, metaId string, noti
 
 	wg.Wait()
 
+	// 移动平台全部失败或没有移动平台令牌时走兜底渠道
+	fallbackResults, fallbackCount := s.sendFallback(ctx, map[string]*models.UserPushTokens{metaId: userTokens}, results, func(string) *PushNotification {
+		return notification
+	})
+	results = append(results, fallbackResults...)
+
 	// 统计结果
 	successCount := 0
 	failureCount := 0
@@ -85,6 +193,9 @@ func (s *DefaultPushService) SendToUser(ctx context.Context, metaId string, noti
 		TotalPlatforms: len(results),
 		SuccessCount:   successCount,
 		FailureCount:   failureCount,
+		Downgraded:     len(downgraded),
+		FallbackCount:  fallbackCount,
+		DryRun:         s.isDryRun(notification),
 		Results:        results,
 		Duration:       time.Since(startTime),
 		Timestamp:      time.Now(),
@@ -107,31 +218,68 @@ func (s *DefaultPushService) SendToUsers(ctx context.Context, metaIds []string,
 		}, nil
 	}
 
+	// 已退订推送的用户不推送
+	subscribedMetaIds, unsubscribedCount := s.applyOptOut(ctx, metaIds)
+	if len(subscribedMetaIds) == 0 {
+		return &BatchPushResult{
+			TotalUsers:   len(metaIds),
+			Unsubscribed: unsubscribedCount,
+			Results:      []*PushResult{},
+			Duration:     time.Since(startTime),
+			Timestamp:    time.Now(),
+		}, nil
+	}
+
+	// QA 账号写入虚拟收件箱，其余用户正常推送
+	results, realMetaIds, throttledCount := s.deliverToInboxUsers(ctx, subscribedMetaIds, notification)
+
 	// 获取所有用户的推送令牌
-	allUserTokens, err := s.tokenStore.GetAllUserTokens(ctx, metaIds)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get user tokens: %w", err)
+	allUserTokens := make(map[string]*models.UserPushTokens)
+	if len(realMetaIds) > 0 {
+		tokenCtx, span := tracing_service.Start(ctx, "token_lookup", attribute.Int("users", len(realMetaIds)))
+		var err error
+		allUserTokens, err = s.tokenStore.GetAllUserTokens(tokenCtx, realMetaIds)
+		tracing_service.RecordError(span, err)
+		span.End()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get user tokens: %w", err)
+		}
+	}
+
+	// 被限流的用户本次不推送，限流放在令牌查询成功之后，查询失败时不消耗配额
+	throttledCount += s.throttleUserTokens(ctx, allUserTokens)
+
+	// 租户配额检查，超出配额的租户用户按配置拒绝或降级发送
+	allUserTokens, downgraded, quotaRejected := s.applyQuota(ctx, notification, allUserTokens)
+	downgradedNotification := notification
+	if len(downgraded) > 0 {
+		downgradedNotification = downgradeNotification(notification)
 	}
 
 	// 并发发送到所有用户的所有平台
-	var results []*PushResult
 	var mu sync.Mutex
 	var wg sync.WaitGroup
 
 	s.mu.RLock()
 	for metaId, userTokens := range allUserTokens {
 		for platform, token := range userTokens.Tokens {
-			if provider, exists := s.providers[platform]; exists {
+			if provider, exists := s.providerFor(platform, notification); exists {
 				wg.Add(1)
-				go func(mid string, p string, t string, prov PushProvider) {
+				userNotification := notification
+				if downgraded[metaId] {
+					userNotification = downgradedNotification
+				}
+				go func(mid string, tenantId string, p string, t string, prov PushProvider, n *PushNotification) {
 					defer wg.Done()
 
-					result := s.sendSingleNotification(ctx, mid, p, t, prov, notification)
+					result := s.sendSingleNotification(ctx, mid, p, t, prov, n)
+					result.TenantID = tenantId
+					s.notifyResultListeners(n, result)
 
 					mu.Lock()
 					results = append(results, result)
 					mu.Unlock()
-				}(metaId, platform, token, provider)
+				}(metaId, userTokens.TenantID, platform, token, provider, userNotification)
 			}
 		}
 	}
@@ -139,6 +287,15 @@ func (s *DefaultPushService) SendToUsers(ctx context.Context, metaIds []string,
 
 	wg.Wait()
 
+	// 移动平台全部失败或没有移动平台令牌的用户走兜底渠道
+	fallbackResults, fallbackCount := s.sendFallback(ctx, allUserTokens, results, func(metaId string) *PushNotification {
+		if downgraded[metaId] {
+			return downgradedNotification
+		}
+		return notification
+	})
+	results = append(results, fallbackResults...)
+
 	// 统计结果
 	successCount := 0
 	failureCount := 0
@@ -164,12 +321,336 @@ func (s *DefaultPushService) SendToUsers(ctx context.Context, metaIds []string,
 		TotalPlatforms: platformCount,
 		SuccessCount:   successCount,
 		FailureCount:   failureCount,
+		ThrottledCount: throttledCount,
+		Unsubscribed:   unsubscribedCount,
+		QuotaRejected:  quotaRejected,
+		Downgraded:     len(downgraded),
+		FallbackCount:  fallbackCount,
+		DryRun:         s.isDryRun(notification),
 		Results:        results,
 		Duration:       time.Since(startTime),
 		Timestamp:      time.Now(),
 	}, nil
 }
 
+// sendFallback 对本次没有任何移动平台推送成功的用户，按通知优先级的兜底链依次尝试兜底提供者，成功即止（静默数据推送除外）
+// 用户没有对应兜底平台的令牌（如未登记邮箱）时跳过该提供者；返回兜底推送结果和兜底送达的用户数
+func (s *DefaultPushService) sendFallback(ctx context.Context, userTokens map[string]*models.UserPushTokens, results []*PushResult, notificationFor func(metaId string) *PushNotification) ([]*PushResult, int) {
+	s.mu.RLock()
+	chains := s.fallbackChains
+	providers := s.fallbackProviders
+	s.mu.RUnlock()
+
+	if len(chains) == 0 || len(providers) == 0 {
+		return nil, 0
+	}
+
+	delivered := make(map[string]bool)
+	for _, result := range results {
+		if result.Success {
+			delivered[result.MetaID] = true
+		}
+	}
+
+	var fallbackResults []*PushResult
+	fallbackCount := 0
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for metaId, tokens := range userTokens {
+		if delivered[metaId] {
+			continue
+		}
+		// 静默数据推送只对设备有意义，不走兜底渠道
+		notification := notificationFor(metaId)
+		if notification.IsDataOnly() {
+			continue
+		}
+		chain := chains[notificationPriority(notification)]
+		if len(chain) == 0 {
+			continue
+		}
+
+		wg.Add(1)
+		go func(mid string, tokens *models.UserPushTokens, n *PushNotification, chain []string) {
+			defer wg.Done()
+
+			for _, name := range chain {
+				// 调用方已取消或超时时不再尝试后续兜底渠道
+				if ctx.Err() != nil {
+					return
+				}
+				provider, exists := providers[name]
+				token := tokens.Tokens[name]
+				if !exists || token == "" {
+					continue
+				}
+
+				result := s.sendSingleNotification(ctx, mid, name, token, provider, n)
+				result.TenantID = tokens.TenantID
+				s.notifyResultListeners(n, result)
+
+				status := "success"
+				if !result.Success {
+					status = "failure"
+				}
+				fallbackCounter.Inc(name, status)
+
+				mu.Lock()
+				fallbackResults = append(fallbackResults, result)
+				if result.Success {
+					fallbackCount++
+				}
+				mu.Unlock()
+
+				if result.Success {
+					log.Printf("📧 用户 %s 的推送未送达设备，已通过 %s 兜底送达", mid, name)
+					return
+				}
+				log.Printf("⚠️ 用户 %s 的 %s 兜底推送失败: %v", mid, name, result.Error)
+			}
+		}(metaId, tokens, notification, chain)
+	}
+
+	wg.Wait()
+
+	return fallbackResults, fallbackCount
+}
+
+// notificationPriority 返回通知优先级，未设置时视为普通优先级
+func notificationPriority(notification *PushNotification) string {
+	if notification.Priority == "" {
+		return PriorityNormal
+	}
+	return notification.Priority
+}
+
+// applyQuota 按租户检查推送配额，每个设备令牌计一次投递：调用方的 API Key 绑定了租户时全部投递计入该租户，
+// 包括不属于任何租户的接收用户；否则计入接收用户所属的租户（如上游 Socket 消息）
+// 返回允许发送的用户令牌、需要降级发送的用户和被拒绝的用户数；配额检查出错时放行
+func (s *DefaultPushService) applyQuota(ctx context.Context, notification *PushNotification, userTokens map[string]*models.UserPushTokens) (map[string]*models.UserPushTokens, map[string]bool, int) {
+	s.mu.RLock()
+	guard := s.quota
+	s.mu.RUnlock()
+
+	if guard == nil {
+		return userTokens, nil, 0
+	}
+
+	// 按租户汇总本次投递数（只计算已注册推送平台的令牌，兜底渠道令牌如邮箱不计入）
+	tenantUsers := make(map[string][]string)
+	tenantCounts := make(map[string]int)
+	s.mu.RLock()
+	for metaId, tokens := range userTokens {
+		deliveries := 0
+		for platform := range tokens.Tokens {
+			if _, exists := s.providers[platform]; exists {
+				deliveries++
+			}
+		}
+		tenantId := notification.TenantID
+		if tenantId == "" {
+			tenantId = tokens.TenantID
+		}
+		if tenantId == "" || deliveries == 0 {
+			continue
+		}
+		tenantUsers[tenantId] = append(tenantUsers[tenantId], metaId)
+		tenantCounts[tenantId] += deliveries
+	}
+	s.mu.RUnlock()
+
+	downgraded := make(map[string]bool)
+	rejected := 0
+	for tenantId, metaIds := range tenantUsers {
+		decision, err := guard.Reserve(ctx, tenantId, tenantCounts[tenantId])
+		if err != nil {
+			log.Printf("⚠️ 检查租户 %s 推送配额失败，默认放行: %v", tenantId, err)
+			continue
+		}
+		switch decision.Action {
+		case QuotaActionReject:
+			for _, metaId := range metaIds {
+				delete(userTokens, metaId)
+			}
+			rejected += len(metaIds)
+			log.Printf("🚫 租户 %s 已超出本月推送配额 (%d/%d)，拒绝推送 %d 个用户", tenantId, decision.Used, decision.Limit, len(metaIds))
+		case QuotaActionDowngrade:
+			for _, metaId := range metaIds {
+				downgraded[metaId] = true
+			}
+			log.Printf("⬇️ 租户 %s 已超出本月推送配额 (%d/%d)，降级推送 %d 个用户", tenantId, decision.Used, decision.Limit, len(metaIds))
+		}
+	}
+
+	return userTokens, downgraded, rejected
+}
+
+// providerFor 返回平台对应的推送提供者，通知限定了提供者时只返回限定范围内的提供者（调用方需持有 s.mu 读锁）
+func (s *DefaultPushService) providerFor(platform string, notification *PushNotification) (PushProvider, bool) {
+	if len(notification.Providers) > 0 && !slices.Contains(notification.Providers, platform) {
+		return nil, false
+	}
+	provider, exists := s.providers[platform]
+	return provider, exists
+}
+
+// downgradeNotification 生成降级通知：普通优先级、不带图片
+func downgradeNotification(notification *PushNotification) *PushNotification {
+	downgraded := *notification
+	downgraded.Priority = PriorityNormal
+	downgraded.ImageURL = ""
+	return &downgraded
+}
+
+// applyOptOut 过滤已退订推送的用户，返回仍订阅的用户和已退订的用户数
+// 退订名单出错时不推送该用户，避免违背用户的退订意愿
+func (s *DefaultPushService) applyOptOut(ctx context.Context, metaIds []string) ([]string, int) {
+	s.mu.RLock()
+	optOut := s.optOut
+	s.mu.RUnlock()
+
+	if optOut == nil {
+		return metaIds, 0
+	}
+
+	subscribed := make([]string, 0, len(metaIds))
+	unsubscribedCount := 0
+	for _, metaId := range metaIds {
+		optedOut, err := optOut.IsOptedOut(ctx, metaId)
+		if err != nil {
+			log.Printf("⚠️ 检查用户 %s 退订状态失败，本次不推送: %v", metaId, err)
+		}
+		if err != nil || optedOut {
+			unsubscribedCount++
+			unsubscribedCounter.Inc()
+			continue
+		}
+		subscribed = append(subscribed, metaId)
+	}
+
+	return subscribed, unsubscribedCount
+}
+
+// applyThrottle 按限流器过滤用户，返回允许推送的用户和被限流的用户数
+// 限流器出错时放行，避免限流后端故障导致推送全部中断
+func (s *DefaultPushService) applyThrottle(ctx context.Context, metaIds []string) ([]string, int) {
+	s.mu.RLock()
+	throttler := s.throttler
+	s.mu.RUnlock()
+
+	if throttler == nil {
+		return metaIds, 0
+	}
+
+	allowed := make([]string, 0, len(metaIds))
+	throttledCount := 0
+	for _, metaId := range metaIds {
+		decision, err := throttler.Allow(ctx, metaId)
+		if err != nil {
+			log.Printf("⚠️ 限流器 %s 检查用户 %s 失败，默认放行: %v", throttler.Name(), metaId, err)
+			allowed = append(allowed, metaId)
+			continue
+		}
+		if !decision.Allowed {
+			throttledCount++
+			throttledCounter.Inc(throttler.Name())
+			log.Printf("🚦 用户 %s 推送已被限流，%v 后可重试", metaId, decision.RetryAfter)
+			continue
+		}
+		allowed = append(allowed, metaId)
+	}
+
+	return allowed, throttledCount
+}
+
+// throttleUserTokens 对查到令牌记录的用户执行限流检查，从 userTokens 中移除被限流的用户，返回被限流的用户数
+func (s *DefaultPushService) throttleUserTokens(ctx context.Context, userTokens map[string]*models.UserPushTokens) int {
+	metaIds := make([]string, 0, len(userTokens))
+	for metaId := range userTokens {
+		metaIds = append(metaIds, metaId)
+	}
+	allowed, throttledCount := s.applyThrottle(ctx, metaIds)
+	if throttledCount == 0 {
+		return 0
+	}
+
+	allowedSet := make(map[string]bool, len(allowed))
+	for _, metaId := range allowed {
+		allowedSet[metaId] = true
+	}
+	for _, metaId := range metaIds {
+		if !allowedSet[metaId] {
+			delete(userTokens, metaId)
+		}
+	}
+	return throttledCount
+}
+
+// throttledResult 单个用户被限流时的推送结果
+func throttledResult(startTime time.Time) *BatchPushResult {
+	return &BatchPushResult{
+		TotalUsers:     1,
+		ThrottledCount: 1,
+		Results:        []*PushResult{},
+		Duration:       time.Since(startTime),
+		Timestamp:      time.Now(),
+	}
+}
+
+// deliverToInboxUsers 将 QA 账号的通知写入虚拟收件箱
+// 返回 QA 账号的推送结果、需要正常推送的其他用户以及被限流的 QA 账号数，QA 账号在写入收件箱前执行限流检查
+func (s *DefaultPushService) deliverToInboxUsers(ctx context.Context, metaIds []string, notification *PushNotification) ([]*PushResult, []string, int) {
+	s.mu.RLock()
+	inbox := s.inbox
+	tokenStore := s.tokenStore
+	s.mu.RUnlock()
+
+	if inbox == nil {
+		return nil, metaIds, 0
+	}
+
+	var results []*PushResult
+	realMetaIds := make([]string, 0, len(metaIds))
+	throttledCount := 0
+	for _, metaId := range metaIds {
+		isInboxUser, err := inbox.IsInboxUser(ctx, metaId)
+		if err != nil {
+			log.Printf("⚠️ 检查用户 %s 是否为 QA 账号失败，按普通用户处理: %v", metaId, err)
+		}
+		if !isInboxUser {
+			realMetaIds = append(realMetaIds, metaId)
+			continue
+		}
+		if allowed, _ := s.applyThrottle(ctx, []string{metaId}); len(allowed) == 0 {
+			throttledCount++
+			continue
+		}
+
+		startTime := time.Now()
+		result := &PushResult{
+			MetaID:    metaId,
+			Platform:  ProviderTypeQAInbox,
+			Timestamp: startTime,
+		}
+		if userTokens, err := tokenStore.GetUserTokens(ctx, metaId); err == nil {
+			result.TenantID = userTokens.TenantID
+		}
+		if err := inbox.Deliver(ctx, metaId, notification); err != nil {
+			result.Error = fmt.Errorf("failed to deliver to qa inbox: %w", err)
+		} else {
+			result.Success = true
+		}
+		result.Duration = time.Since(startTime)
+
+		log.Printf("🧪 QA 账号 %s 的推送已写入虚拟收件箱: %s", metaId, notification.Title)
+		s.notifyResultListeners(notification, result)
+		results = append(results, result)
+	}
+
+	return results, realMetaIds, throttledCount
+}
+
 // sendSingleNotification 发送单个通知（内部方法）
 func (s *DefaultPushService) sendSingleNotification(ctx context.Context, metaId, platform, token string, provider PushProvider, notification *PushNotification) *PushResult {
 	startTime := time.Now()
@@ -188,22 +669,84 @@ func (s *DefaultPushService) sendSingleNotification(ctx context.Context, metaId,
 		return result
 	}
 
+	// 调用方已取消或超时时不再发送
+	if err := ctx.Err(); err != nil {
+		result.Error = err
+		result.Duration = time.Since(startTime)
+		return result
+	}
+
+	// 演练模式不调用推送平台，按成功记录模拟结果
+	if s.isDryRun(notification) {
+		dryRunCounter.Inc(platform)
+		log.Printf("🧪 演练模式，跳过 %s 推送: metaId=%s, title=%s", platform, metaId, notification.Title)
+		result.Success = true
+		result.Simulated = true
+		result.Duration = time.Since(startTime)
+		return result
+	}
+
 	// 发送通知
-	providerResult, err := provider.SendNotification(ctx, token, notification)
+	providerCtx, span := tracing_service.Start(ctx, "provider.send",
+		attribute.String("provider", provider.GetName()), attribute.String("platform", platform))
+	defer span.End()
+	providerResult, err := provider.SendNotification(providerCtx, token, notification)
 	if err != nil {
 		result.Error = err
 		result.Duration = time.Since(startTime)
+		tracing_service.RecordError(span, err)
+		recordProviderSend(provider.GetName(), result)
 		return result
 	}
 
 	result.Success = providerResult.Success
 	result.ReceiptID = providerResult.ReceiptID
 	result.Error = providerResult.Error
+	result.Retries = providerResult.Retries
 	result.Duration = time.Since(startTime)
+	span.SetAttributes(attribute.Bool("success", result.Success), attribute.Int("retries", result.Retries))
+	tracing_service.RecordError(span, result.Error)
+	recordProviderSend(provider.GetName(), result)
 
 	return result
 }
 
+// CheckReceipts 通过平台对应的推送提供者查询投递回执，提供者不支持查询时返回 ErrReceiptsUnsupported
+func (s *DefaultPushService) CheckReceipts(ctx context.Context, platform string, receiptIDs []string) (map[string]*ReceiptOutcome, error) {
+	s.mu.RLock()
+	provider, exists := s.providers[platform]
+	s.mu.RUnlock()
+
+	checker, ok := provider.(ReceiptChecker)
+	if !exists || !ok {
+		return nil, fmt.Errorf("%w: %s", ErrReceiptsUnsupported, platform)
+	}
+	return checker.CheckReceipts(ctx, receiptIDs)
+}
+
+// AddResultListener 添加推送结果监听器
+func (s *DefaultPushService) AddResultListener(listener ResultListener) {
+	if listener == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.listeners = append(s.listeners, listener)
+}
+
+// notifyResultListeners 将推送结果通知所有监听器
+func (s *DefaultPushService) notifyResultListeners(notification *PushNotification, result *PushResult) {
+	s.mu.RLock()
+	listeners := s.listeners
+	s.mu.RUnlock()
+
+	for _, listener := range listeners {
+		listener(notification, result)
+	}
+}
+
 // RegisterProvider 注册推送提供者
 func (s *DefaultPushService) RegisterProvider(provider PushProvider) error {
 	if provider == nil {
@@ -222,6 +765,82 @@ func (s *DefaultPushService) RegisterProvider(provider PushProvider) error {
 	return nil
 }
 
+// SetThrottler 设置推送限流器，nil 表示不限流
+func (s *DefaultPushService) SetThrottler(throttler Throttler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.throttler = throttler
+}
+
+// SetVirtualInbox 设置 QA 虚拟收件箱，nil 表示关闭 QA 模式
+func (s *DefaultPushService) SetVirtualInbox(inbox VirtualInbox) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.inbox = inbox
+}
+
+// SetQuotaGuard 设置租户推送配额，nil 表示不限制
+func (s *DefaultPushService) SetQuotaGuard(guard QuotaGuard) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.quota = guard
+}
+
+// SetOptOutList 设置全局退订名单，nil 表示不检查退订
+func (s *DefaultPushService) SetOptOutList(list OptOutList) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.optOut = list
+}
+
+// RegisterFallbackProvider 注册兜底提供者，兜底提供者不参与常规推送
+func (s *DefaultPushService) RegisterFallbackProvider(provider PushProvider) error {
+	if provider == nil {
+		return fmt.Errorf("provider cannot be nil")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	name := provider.GetName()
+	if name == "" {
+		return fmt.Errorf("provider name cannot be empty")
+	}
+
+	s.fallbackProviders[name] = provider
+	return nil
+}
+
+// SetFallbackChains 设置各通知优先级的兜底链，nil 表示不兜底
+func (s *DefaultPushService) SetFallbackChains(chains map[string][]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.fallbackChains = chains
+}
+
+// SetDryRun 设置全局演练模式
+func (s *DefaultPushService) SetDryRun(enabled bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.dryRun = enabled
+}
+
+// isDryRun 全局演练模式或通知要求演练时不调用推送平台
+func (s *DefaultPushService) isDryRun(notification *PushNotification) bool {
+	if notification != nil && notification.DryRun {
+		return true
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.dryRun
+}
+
 // SetUserTokenStore 设置用户令牌存储
 func (s *DefaultPushService) SetUserTokenStore(store UserTokenStore) {
 	s.mu.Lock()
@@ -0,0 +1,152 @@
+package push_service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// testPushReceiptPollInterval 测试推送等待回执时的查询间隔
+var testPushReceiptPollInterval = time.Second
+
+// TestPushTarget 测试推送目标：指定用户时发送到该用户登记的所有平台，指定令牌时只发送到该令牌
+type TestPushTarget struct {
+	MetaID   string // 用户MetaID
+	Platform string // 令牌所属平台（仅指定令牌时使用，为空时按令牌格式识别）
+	Token    string // 推送令牌
+}
+
+// SendTestPush 发送测试推送，用于排查设备推送配置
+// 直接调用推送平台，不经过限流、租户配额、QA 收件箱和兜底渠道，也不通知结果监听器；按 metaId 发送时不发给已退订推送的用户
+func (s *DefaultPushService) SendTestPush(ctx context.Context, target *TestPushTarget, notification *PushNotification) ([]*PushResult, error) {
+	tokens := make(map[string]string)
+	if target.Token != "" {
+		platform := target.Platform
+		if platform == "" {
+			platform = s.detectPlatform(target.Token)
+			if platform == "" {
+				return nil, fmt.Errorf("%w: 无法识别令牌所属平台，请指定 platform", ErrInvalidTestTarget)
+			}
+		}
+		tokens[platform] = target.Token
+	} else if target.MetaID != "" {
+		if subscribed, _ := s.applyOptOut(ctx, []string{target.MetaID}); len(subscribed) == 0 {
+			return nil, fmt.Errorf("用户 %s %w", target.MetaID, ErrUserUnsubscribed)
+		}
+		userTokens, err := s.tokenStore.GetUserTokens(ctx, target.MetaID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get user tokens for metaId %s: %w", target.MetaID, err)
+		}
+		if len(userTokens.Tokens) == 0 {
+			return nil, fmt.Errorf("用户 %s %w", target.MetaID, ErrNoRegisteredTokens)
+		}
+		tokens = userTokens.Tokens
+	} else {
+		return nil, fmt.Errorf("%w: 需要指定 metaId 或 token", ErrInvalidTestTarget)
+	}
+
+	platforms := make([]string, 0, len(tokens))
+	for platform := range tokens {
+		platforms = append(platforms, platform)
+	}
+	sort.Strings(platforms)
+
+	results := make([]*PushResult, 0, len(platforms))
+	for _, platform := range platforms {
+		s.mu.RLock()
+		provider, exists := s.providers[platform]
+		s.mu.RUnlock()
+
+		if !exists {
+			results = append(results, &PushResult{
+				MetaID:    target.MetaID,
+				Platform:  platform,
+				Token:     tokens[platform],
+				Error:     fmt.Errorf("platform %s has no registered provider", platform),
+				Timestamp: time.Now(),
+			})
+			continue
+		}
+		results = append(results, s.sendSingleNotification(ctx, target.MetaID, platform, tokens[platform], provider, notification))
+	}
+	return results, nil
+}
+
+// detectPlatform 按令牌格式识别所属平台，多个提供者都接受时按名称顺序取第一个
+func (s *DefaultPushService) detectPlatform(token string) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	names := make([]string, 0, len(s.providers))
+	for name := range s.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if s.providers[name].ValidateToken(token) {
+			return name
+		}
+	}
+	return ""
+}
+
+// WaitForReceipts 轮询查询推送结果的投递回执，直到全部回执生成、等待超过 wait 或 ctx 结束
+// 返回回执ID到回执结果的映射，平台不支持回执查询或回执尚未生成的推送不在结果中
+func (s *DefaultPushService) WaitForReceipts(ctx context.Context, results []*PushResult, wait time.Duration) map[string]*ReceiptOutcome {
+	outcomes := make(map[string]*ReceiptOutcome)
+	pending := make(map[string][]string)
+	for _, result := range results {
+		if result.Success && result.ReceiptID != "" {
+			pending[result.Platform] = append(pending[result.Platform], result.ReceiptID)
+		}
+	}
+	if len(pending) == 0 || wait <= 0 {
+		return outcomes
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	ticker := time.NewTicker(testPushReceiptPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return outcomes
+		case <-timer.C:
+			return outcomes
+		case <-ticker.C:
+		}
+
+		for platform, receiptIDs := range pending {
+			checked, err := s.CheckReceipts(ctx, platform, receiptIDs)
+			if err != nil {
+				// 不支持回执查询的平台不再等待，查询失败的下次重试
+				if errors.Is(err, ErrReceiptsUnsupported) {
+					delete(pending, platform)
+				}
+				continue
+			}
+
+			remaining := receiptIDs[:0]
+			for _, receiptID := range receiptIDs {
+				if outcome, ok := checked[receiptID]; ok {
+					outcomes[receiptID] = outcome
+				} else {
+					remaining = append(remaining, receiptID)
+				}
+			}
+			if len(remaining) == 0 {
+				delete(pending, platform)
+			} else {
+				pending[platform] = remaining
+			}
+		}
+		if len(pending) == 0 {
+			return outcomes
+		}
+	}
+}
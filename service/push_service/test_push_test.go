@@ -0,0 +1,87 @@
+package push_service
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// receiptProvider 受理时返回回执ID、第二次查询时才生成回执的测试推送提供者
+type receiptProvider struct {
+	stubProvider
+	mu      sync.Mutex
+	checked int
+}
+
+func (p *receiptProvider) SendNotification(ctx context.Context, token string, notification *PushNotification) (*PushResult, error) {
+	p.stubProvider.SendNotification(ctx, token, notification)
+	return &PushResult{Success: true, ReceiptID: "receipt-" + token}, nil
+}
+
+func (p *receiptProvider) CheckReceipts(ctx context.Context, receiptIDs []string) (map[string]*ReceiptOutcome, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.checked++
+	if p.checked < 2 {
+		return map[string]*ReceiptOutcome{}, nil
+	}
+	outcomes := make(map[string]*ReceiptOutcome)
+	for _, receiptID := range receiptIDs {
+		outcomes[receiptID] = &ReceiptOutcome{Delivered: true}
+	}
+	return outcomes, nil
+}
+
+func TestSendTestPush(t *testing.T) {
+	testPushReceiptPollInterval = time.Millisecond
+	defer func() { testPushReceiptPollInterval = time.Second }()
+
+	expo := &receiptProvider{stubProvider: stubProvider{name: ProviderTypeExpo}}
+	service := NewPushService()
+	service.RegisterProvider(expo)
+	service.SetThrottler(&denyThrottler{denied: map[string]bool{"a": true}})
+
+	store := NewMemoryTokenStore()
+	ctx := context.Background()
+	store.SetUserToken(ctx, "a", ProviderTypeExpo, "expo-a")
+	store.SetUserToken(ctx, "a", PlatformWindows, "wns-a")
+	service.SetUserTokenStore(store)
+
+	listened := 0
+	service.AddResultListener(func(notification *PushNotification, result *PushResult) { listened++ })
+
+	// 按用户发送：不受限流影响，未注册提供者的平台返回错误
+	results, err := service.SendTestPush(ctx, &TestPushTarget{MetaID: "a"}, &PushNotification{Title: "t", Body: "b"})
+	if err != nil {
+		t.Fatalf("SendTestPush() failed, err: %v", err)
+	}
+	if len(results) != 2 || !results[0].Success || results[0].ReceiptID != "receipt-expo-a" {
+		t.Fatalf("results = %+v, want accepted expo ticket first", results)
+	}
+	if results[1].Platform != PlatformWindows || results[1].Error == nil {
+		t.Errorf("results[1] = %+v, want missing provider error", results[1])
+	}
+	if listened != 0 {
+		t.Errorf("测试推送不应通知结果监听器")
+	}
+
+	// 等待回执
+	receipts := service.WaitForReceipts(ctx, results, time.Second)
+	if outcome := receipts["receipt-expo-a"]; outcome == nil || !outcome.Delivered {
+		t.Errorf("receipts = %v, want delivered receipt", receipts)
+	}
+
+	// 按令牌发送，按令牌格式识别平台
+	results, err = service.SendTestPush(ctx, &TestPushTarget{Token: "raw-token"}, &PushNotification{Title: "t", Body: "b"})
+	if err != nil || len(results) != 1 || results[0].Platform != ProviderTypeExpo {
+		t.Fatalf("SendTestPush(token) = %+v, %v", results, err)
+	}
+	if expo.sent[len(expo.sent)-1] != "raw-token" {
+		t.Errorf("sent = %v, want raw-token", expo.sent)
+	}
+
+	if _, err := service.SendTestPush(ctx, &TestPushTarget{MetaID: "nobody"}, &PushNotification{}); err == nil {
+		t.Error("没有令牌的用户应返回错误")
+	}
+}
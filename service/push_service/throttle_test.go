@@ -0,0 +1,77 @@
+package push_service
+
+import (
+	"context"
+	"errors"
+	"push-base-service/models"
+	"testing"
+)
+
+// denyThrottler 拒绝指定用户的测试限流器
+type denyThrottler struct {
+	denied  map[string]bool
+	checked []string
+}
+
+func (d *denyThrottler) Name() string { return "deny" }
+
+func (d *denyThrottler) Allow(ctx context.Context, key string) (*ThrottleDecision, error) {
+	d.checked = append(d.checked, key)
+	return &ThrottleDecision{Allowed: !d.denied[key]}, nil
+}
+
+// failingTokenStore 令牌查询总是失败的测试存储
+type failingTokenStore struct {
+	*MemoryTokenStore
+}
+
+func (f *failingTokenStore) GetUserTokens(ctx context.Context, metaId string) (*models.UserPushTokens, error) {
+	return nil, errors.New("token store unavailable")
+}
+
+func (f *failingTokenStore) GetAllUserTokens(ctx context.Context, metaIds []string) (map[string]*models.UserPushTokens, error) {
+	return nil, errors.New("token store unavailable")
+}
+
+func TestSendToUsersSkipsThrottledUsers(t *testing.T) {
+	service := NewPushService()
+	service.SetThrottler(&denyThrottler{denied: map[string]bool{"user-b": true}})
+
+	ctx := context.Background()
+	store := NewMemoryTokenStore()
+	store.SetUserToken(ctx, "user-b", ProviderTypeExpo, "expo-b")
+	service.SetUserTokenStore(store)
+	result, err := service.SendToUsers(ctx, []string{"user-a", "user-b"}, &PushNotification{Title: "t", Body: "b"})
+	if err != nil {
+		t.Fatalf("SendToUsers() failed, err: %v", err)
+	}
+	if result.TotalUsers != 2 || result.ThrottledCount != 1 {
+		t.Errorf("SendToUsers() = total %d, throttled %d; want 2, 1", result.TotalUsers, result.ThrottledCount)
+	}
+
+	result, err = service.SendToUser(ctx, "user-b", &PushNotification{Title: "t", Body: "b"})
+	if err != nil {
+		t.Fatalf("SendToUser() failed, err: %v", err)
+	}
+	if result.ThrottledCount != 1 {
+		t.Errorf("SendToUser() throttled = %d, want 1", result.ThrottledCount)
+	}
+}
+
+func TestThrottleSkippedWhenTokenLookupFails(t *testing.T) {
+	service := NewPushService()
+	throttler := &denyThrottler{}
+	service.SetThrottler(throttler)
+	service.SetUserTokenStore(&failingTokenStore{NewMemoryTokenStore()})
+
+	ctx := context.Background()
+	if _, err := service.SendToUser(ctx, "user-a", &PushNotification{Title: "t", Body: "b"}); err == nil {
+		t.Fatal("SendToUser() should fail when the token lookup fails")
+	}
+	if _, err := service.SendToUsers(ctx, []string{"user-a", "user-b"}, &PushNotification{Title: "t", Body: "b"}); err == nil {
+		t.Fatal("SendToUsers() should fail when the token lookup fails")
+	}
+	if len(throttler.checked) != 0 {
+		t.Errorf("throttler consulted for %v, want no quota consumed before the token lookup succeeds", throttler.checked)
+	}
+}
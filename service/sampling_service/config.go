@@ -0,0 +1,48 @@
+package sampling_service
+
+import "time"
+
+// Config 外发通知抽样配置：按比例抽取外发通知（脱敏后）镜像到内部审阅 Webhook
+type Config struct {
+	Enabled      bool          `yaml:"enabled" json:"enabled"`             // 是否启用
+	Rate         float64       `yaml:"rate" json:"rate"`                   // 抽样比例（0~1），如 0.001 表示 0.1%
+	WebhookURL   string        `yaml:"webhook_url" json:"webhook_url"`     // 内部审阅渠道的 Webhook 地址
+	Secret       string        `yaml:"secret" json:"secret"`               // 签名密钥，为空时不签名
+	Timeout      time.Duration `yaml:"timeout" json:"timeout"`             // 单次投递超时
+	QueueSize    int           `yaml:"queue_size" json:"queue_size"`       // 待投递队列长度，满时丢弃样本
+	RedactFields []string      `yaml:"redact_fields" json:"redact_fields"` // 需要脱敏的 data 字段（不区分大小写）
+}
+
+// DefaultRedactFields 默认脱敏的 data 字段：用户、会话和链上标识
+var DefaultRedactFields = []string{"metaId", "fromMetaId", "toMetaId", "pinId", "groupId", "channelId", "address", "txId"}
+
+// DefaultConfig 返回默认配置
+func DefaultConfig() *Config {
+	return &Config{
+		Rate:         0.001,
+		Timeout:      5 * time.Second,
+		QueueSize:    100,
+		RedactFields: DefaultRedactFields,
+	}
+}
+
+// ApplyDefaults 为缺失的配置项填充默认值
+func (c *Config) ApplyDefaults() {
+	defaults := DefaultConfig()
+
+	if c.Rate < 0 {
+		c.Rate = 0
+	}
+	if c.Rate > 1 {
+		c.Rate = 1
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = defaults.Timeout
+	}
+	if c.QueueSize <= 0 {
+		c.QueueSize = defaults.QueueSize
+	}
+	if len(c.RedactFields) == 0 {
+		c.RedactFields = defaults.RedactFields
+	}
+}
@@ -0,0 +1,235 @@
+package sampling_service
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"net/http"
+	"push-base-service/service/metrics_service"
+	"push-base-service/service/push_service"
+	"push-base-service/service/webhook_service"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EventNotificationSample 抽样事件类型
+const EventNotificationSample = "notification.sample"
+
+// RedactedValue 脱敏后的字段值
+const RedactedValue = "[redacted]"
+
+// samplesCounter 抽样投递结果统计
+var samplesCounter = metrics_service.NewCounterVec(
+	"push_notification_samples_total", "Number of sampled outbound notifications by result", "result")
+
+// Sample 脱敏后的外发通知样本（不含推送令牌，用户 MetaID 只保留指纹）
+type Sample struct {
+	Event      string                 `json:"event"`              // 事件类型
+	UserHash   string                 `json:"userHash"`           // 用户 MetaID 的 SHA-256 前 12 位，用于区分同一用户的多条样本
+	TenantID   string                 `json:"tenantId,omitempty"` // 租户ID
+	Platform   string                 `json:"platform"`           // 推送平台
+	Success    bool                   `json:"success"`            // 是否推送成功
+	Error      string                 `json:"error,omitempty"`    // 推送错误
+	Title      string                 `json:"title"`              // 通知标题
+	Body       string                 `json:"body"`               // 通知内容
+	Sound      string                 `json:"sound,omitempty"`    // 声音
+	Badge      *int                   `json:"badge,omitempty"`    // 徽章数字
+	ImageURL   string                 `json:"imageUrl,omitempty"` // 图片URL
+	Priority   string                 `json:"priority,omitempty"` // 优先级
+	Data       map[string]interface{} `json:"data,omitempty"`     // 自定义数据（敏感字段已脱敏）
+	PayloadLen int                    `json:"payloadLen"`         // 通知序列化后的字节数，便于发现超大载荷
+	Timestamp  int64                  `json:"timestamp"`          // 推送时间
+}
+
+// Sampler 外发通知抽样器，作为推送结果监听器按比例抽样，异步投递到内部审阅 Webhook
+type Sampler struct {
+	config       *Config
+	httpClient   *http.Client
+	queue        chan *Sample
+	redactFields map[string]bool
+	random       func() float64
+	stopCh       chan struct{}
+	wg           sync.WaitGroup
+	running      bool
+	mu           sync.Mutex
+}
+
+// NewSampler 创建抽样器
+func NewSampler(config *Config) *Sampler {
+	if config == nil {
+		config = DefaultConfig()
+	}
+	config.ApplyDefaults()
+
+	redactFields := make(map[string]bool, len(config.RedactFields))
+	for _, field := range config.RedactFields {
+		redactFields[strings.ToLower(field)] = true
+	}
+
+	return &Sampler{
+		config:       config,
+		httpClient:   &http.Client{Timeout: config.Timeout},
+		queue:        make(chan *Sample, config.QueueSize),
+		redactFields: redactFields,
+		random:       rand.Float64,
+		stopCh:       make(chan struct{}),
+	}
+}
+
+// Start 启动投递协程
+func (s *Sampler) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.running {
+		return
+	}
+
+	s.wg.Add(1)
+	go s.worker()
+
+	s.running = true
+	log.Printf("✅ 外发通知抽样已启动: 比例=%g", s.config.Rate)
+}
+
+// Stop 停止投递协程（队列中未投递的样本将被丢弃）
+func (s *Sampler) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.running {
+		return
+	}
+
+	close(s.stopCh)
+	s.wg.Wait()
+	s.running = false
+	log.Printf("🛑 外发通知抽样已停止")
+}
+
+// HandleResult 推送结果监听器，按比例抽样后加入投递队列
+func (s *Sampler) HandleResult(notification *push_service.PushNotification, result *push_service.PushResult) {
+	if notification == nil || result == nil || s.random() >= s.config.Rate {
+		return
+	}
+
+	select {
+	case s.queue <- s.buildSample(notification, result):
+	default:
+		samplesCounter.Inc("dropped")
+	}
+}
+
+// buildSample 构建脱敏样本
+func (s *Sampler) buildSample(notification *push_service.PushNotification, result *push_service.PushResult) *Sample {
+	sample := &Sample{
+		Event:     EventNotificationSample,
+		UserHash:  userHash(result.MetaID),
+		TenantID:  result.TenantID,
+		Platform:  result.Platform,
+		Success:   result.Success,
+		Title:     notification.Title,
+		Body:      notification.Body,
+		Sound:     notification.Sound,
+		Badge:     notification.Badge,
+		ImageURL:  notification.ImageURL,
+		Priority:  notification.Priority,
+		Data:      s.redactData(notification.Data),
+		Timestamp: result.Timestamp.Unix(),
+	}
+	if result.Error != nil {
+		sample.Error = result.Error.Error()
+	}
+	if payload, err := json.Marshal(notification); err == nil {
+		sample.PayloadLen = len(payload)
+	}
+	return sample
+}
+
+// redactData 复制 data 并脱敏敏感字段，嵌套对象同样处理
+func (s *Sampler) redactData(data map[string]interface{}) map[string]interface{} {
+	if data == nil {
+		return nil
+	}
+
+	redacted := make(map[string]interface{}, len(data))
+	for key, value := range data {
+		if s.redactFields[strings.ToLower(key)] {
+			redacted[key] = RedactedValue
+			continue
+		}
+		if nested, ok := value.(map[string]interface{}); ok {
+			redacted[key] = s.redactData(nested)
+			continue
+		}
+		redacted[key] = value
+	}
+	return redacted
+}
+
+// userHash 计算用户 MetaID 指纹
+func userHash(metaId string) string {
+	if metaId == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(metaId))
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+// worker 投递协程，样本只投递一次，失败不重试
+func (s *Sampler) worker() {
+	defer s.wg.Done()
+
+	for {
+		select {
+		case <-s.stopCh:
+			return
+		case sample := <-s.queue:
+			if err := s.post(sample); err != nil {
+				samplesCounter.Inc("failed")
+				log.Printf("⚠️ 外发通知样本投递失败: %v", err)
+				continue
+			}
+			samplesCounter.Inc("sent")
+		}
+	}
+}
+
+// post 发送样本，配置了密钥时按租户 Webhook 相同的方式签名
+func (s *Sampler) post(sample *Sample) error {
+	body, err := json.Marshal(sample)
+	if err != nil {
+		return fmt.Errorf("序列化样本失败: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, s.config.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("创建请求失败: %w", err)
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(webhook_service.HeaderEvent, EventNotificationSample)
+	req.Header.Set(webhook_service.HeaderTimestamp, timestamp)
+	if s.config.Secret != "" {
+		req.Header.Set(webhook_service.HeaderSignature, webhook_service.Sign(s.config.Secret, timestamp, body))
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("发送请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("审阅 Webhook 返回状态码 %d", resp.StatusCode)
+	}
+	return nil
+}
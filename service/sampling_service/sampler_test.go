@@ -0,0 +1,74 @@
+package sampling_service
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"push-base-service/service/push_service"
+	"push-base-service/service/webhook_service"
+	"testing"
+	"time"
+)
+
+func TestSamplerSamplesAndRedacts(t *testing.T) {
+	received := make(chan *Sample, 1)
+	var signature string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		signature = r.Header.Get(webhook_service.HeaderSignature)
+		var sample Sample
+		json.NewDecoder(r.Body).Decode(&sample)
+		received <- &sample
+	}))
+	defer server.Close()
+
+	sampler := NewSampler(&Config{Rate: 0.5, WebhookURL: server.URL, Secret: "s"})
+	values := []float64{0.7, 0.2}
+	sampler.random = func() float64 {
+		value := values[0]
+		values = values[1:]
+		return value
+	}
+	sampler.Start()
+	defer sampler.Stop()
+
+	notification := &push_service.PushNotification{
+		Title: "新消息",
+		Body:  "Alice: hi",
+		Data: map[string]interface{}{
+			"pinId":    "pin-1",
+			"chatType": "group_chat",
+			"extra":    map[string]interface{}{"MetaId": "user-2"},
+		},
+	}
+	result := &push_service.PushResult{MetaID: "user-1", Platform: "expo", Token: "ExponentPushToken[x]", Success: true, Timestamp: time.Now()}
+
+	sampler.HandleResult(notification, result) // 0.7 >= 0.5，不抽样
+	sampler.HandleResult(notification, result) // 0.2 < 0.5，抽样
+
+	select {
+	case sample := <-received:
+		if sample.Title != "新消息" || sample.Body != "Alice: hi" || sample.Platform != "expo" {
+			t.Errorf("样本内容不符合预期: %+v", sample)
+		}
+		if sample.UserHash == "" || sample.UserHash == "user-1" {
+			t.Errorf("UserHash = %q, want fingerprint", sample.UserHash)
+		}
+		if sample.Data["pinId"] != RedactedValue || sample.Data["chatType"] != "group_chat" {
+			t.Errorf("data 脱敏不符合预期: %+v", sample.Data)
+		}
+		if nested := sample.Data["extra"].(map[string]interface{}); nested["MetaId"] != RedactedValue {
+			t.Errorf("嵌套字段未脱敏: %+v", nested)
+		}
+		if signature == "" {
+			t.Errorf("配置密钥后应签名")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("未收到抽样样本")
+	}
+
+	select {
+	case sample := <-received:
+		t.Fatalf("只应投递一条样本，多收到: %+v", sample)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
@@ -0,0 +1,34 @@
+package schedule_service
+
+import "time"
+
+// Config 定时推送调度器配置
+type Config struct {
+	PollInterval time.Duration `yaml:"poll_interval" json:"poll_interval"` // 扫描到期任务的间隔
+	BatchSize    int           `yaml:"batch_size" json:"batch_size"`       // 每次最多领取的任务数
+	SendTimeout  time.Duration `yaml:"send_timeout" json:"send_timeout"`   // 单个任务的发送超时
+}
+
+// DefaultConfig 返回默认配置
+func DefaultConfig() *Config {
+	return &Config{
+		PollInterval: 5 * time.Second,
+		BatchSize:    100,
+		SendTimeout:  30 * time.Second,
+	}
+}
+
+// ApplyDefaults 为缺失的配置项填充默认值
+func (c *Config) ApplyDefaults() {
+	defaults := DefaultConfig()
+
+	if c.PollInterval <= 0 {
+		c.PollInterval = defaults.PollInterval
+	}
+	if c.BatchSize <= 0 {
+		c.BatchSize = defaults.BatchSize
+	}
+	if c.SendTimeout <= 0 {
+		c.SendTimeout = defaults.SendTimeout
+	}
+}
@@ -0,0 +1,126 @@
+package schedule_service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"push-base-service/service/pebble_service"
+	"push-base-service/service/storage_service"
+	"slices"
+	"sort"
+	"time"
+)
+
+// LocalTimeBatch 按当地时间发送时，同一时刻到达指定整点的一组用户
+type LocalTimeBatch struct {
+	SendAt    time.Time // 计划发送时间
+	Timezones []string  // 该组覆盖的时区
+	MetaIDs   []string  // 接收用户
+}
+
+// PlanLocalTime 按用户设备上报的时区将接收用户分组，每组在当地下一次到达 hour 点整时发送；
+// 未上报时区的用户使用 fallback 时区。不同时区在同一时刻到达该整点时合并为一组，结果按发送时间排序
+func PlanLocalTime(metaIds []string, hour int, fallback *time.Location, now time.Time) ([]*LocalTimeBatch, error) {
+	if hour < 0 || hour > 23 {
+		return nil, fmt.Errorf("当地发送时间必须在 0-23 点之间: %d", hour)
+	}
+
+	timezones, err := userTimezones(metaIds)
+	if err != nil {
+		return nil, err
+	}
+	return planBatches(metaIds, timezones, hour, fallback, now), nil
+}
+
+// planBatches 按时区计算每个用户的发送时间并合并为批次
+func planBatches(metaIds []string, timezones map[string]string, hour int, fallback *time.Location, now time.Time) []*LocalTimeBatch {
+	locations := make(map[string]*time.Location)
+	batches := make(map[int64]*LocalTimeBatch)
+	seen := make(map[string]bool, len(metaIds))
+	for _, metaId := range metaIds {
+		if metaId == "" || seen[metaId] {
+			continue
+		}
+		seen[metaId] = true
+
+		name := timezones[metaId]
+		location, ok := locations[name]
+		if !ok {
+			location = fallback
+			if name != "" {
+				if loaded, err := time.LoadLocation(name); err == nil {
+					location = loaded
+				} else {
+					log.Printf("⚠️ 用户时区无效，使用默认时区: 时区=%s, 错误: %v", name, err)
+				}
+			}
+			locations[name] = location
+		}
+
+		sendAt := nextLocalHour(now, location, hour)
+		batch, exists := batches[sendAt.Unix()]
+		if !exists {
+			batch = &LocalTimeBatch{SendAt: sendAt}
+			batches[sendAt.Unix()] = batch
+		}
+		batch.MetaIDs = append(batch.MetaIDs, metaId)
+		if zone := location.String(); !slices.Contains(batch.Timezones, zone) {
+			batch.Timezones = append(batch.Timezones, zone)
+		}
+	}
+
+	result := make([]*LocalTimeBatch, 0, len(batches))
+	for _, batch := range batches {
+		sort.Strings(batch.Timezones)
+		result = append(result, batch)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].SendAt.Before(result[j].SendAt)
+	})
+	return result
+}
+
+// nextLocalHour 返回 now 之后该时区下一次到达 hour 点整的时刻（夏令时跳过的整点按 time.Date 的规则顺延）
+func nextLocalHour(now time.Time, location *time.Location, hour int) time.Time {
+	local := now.In(location)
+	next := time.Date(local.Year(), local.Month(), local.Day(), hour, 0, 0, 0, location)
+	if !next.After(now) {
+		next = time.Date(local.Year(), local.Month(), local.Day()+1, hour, 0, 0, 0, location)
+	}
+	return next
+}
+
+// userTimezones 获取用户最近活跃（lastSeenAt 最大）且上报了时区的设备的时区，未上报的用户不在结果中
+func userTimezones(metaIds []string) (map[string]string, error) {
+	timezones := make(map[string]string)
+	stores := storage_service.GetGlobalStores()
+	if stores == nil || len(metaIds) == 0 {
+		return timezones, nil
+	}
+
+	userTokens, err := stores.Tokens.GetAllUserTokens(context.Background(), metaIds)
+	if err != nil {
+		return nil, fmt.Errorf("获取用户令牌失败: %w", err)
+	}
+	for metaId, tokens := range userTokens {
+		if tokens == nil {
+			continue
+		}
+		deviceIds := make([]string, 0, len(tokens.Tokens))
+		for _, token := range tokens.Tokens {
+			deviceIds = append(deviceIds, token)
+		}
+		devices, err := pebble_service.GetDevicesInfo(deviceIds)
+		if err != nil {
+			return nil, fmt.Errorf("获取设备信息失败: MetaID=%s, %w", metaId, err)
+		}
+
+		var lastSeenAt int64
+		for _, device := range devices {
+			if device.Timezone != "" && device.LastSeenAt >= lastSeenAt {
+				timezones[metaId], lastSeenAt = device.Timezone, device.LastSeenAt
+			}
+		}
+	}
+	return timezones, nil
+}
@@ -0,0 +1,90 @@
+package schedule_service
+
+import (
+	"push-base-service/models"
+	"push-base-service/service/pebble_service"
+	"push-base-service/service/storage_service"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func mustLoadLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		t.Skipf("时区数据不可用: %v", err)
+	}
+	return location
+}
+
+func TestNextLocalHour(t *testing.T) {
+	shanghai := mustLoadLocation(t, "Asia/Shanghai")
+	now := time.Date(2026, 3, 1, 8, 30, 0, 0, time.UTC) // 上海 16:30
+
+	if got, want := nextLocalHour(now, shanghai, 18), time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("当天未到整点: nextLocalHour() = %v, want %v", got, want)
+	}
+	if got, want := nextLocalHour(now, shanghai, 9), time.Date(2026, 3, 2, 1, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("当天已过整点: nextLocalHour() = %v, want %v", got, want)
+	}
+	if got, want := nextLocalHour(now, time.UTC, 8), time.Date(2026, 3, 2, 8, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("整点已过半小时: nextLocalHour() = %v, want %v", got, want)
+	}
+}
+
+func TestPlanLocalTimeGroupsByTimezone(t *testing.T) {
+	mustLoadLocation(t, "Asia/Shanghai")
+
+	if err := pebble_service.InitializeGlobalService(&pebble_service.Config{DBPath: t.TempDir()}); err != nil {
+		t.Fatalf("初始化 Pebble 服务失败: %v", err)
+	}
+	t.Cleanup(func() { pebble_service.CloseGlobalService() })
+	ps := pebble_service.GetGlobalService()
+	stores, err := storage_service.NewStores(nil, pebble_service.NewPebbleTokenStore(ps))
+	if err != nil {
+		t.Fatalf("创建存储失败: %v", err)
+	}
+	storage_service.SetGlobalStores(stores)
+	t.Cleanup(func() { storage_service.SetGlobalStores(nil) })
+
+	// alice 在上海、carol 在香港（同为 UTC+8，合并为一组），bob 在东京，dave 未上报时区，erin 的时区无效
+	devices := map[string]string{"alice": "Asia/Shanghai", "bob": "Asia/Tokyo", "carol": "Asia/Hong_Kong", "erin": "Mars/Base"}
+	for metaId, timezone := range devices {
+		token := "token-" + metaId
+		if err := storage_service.SetUserToken(metaId, "expo", token); err != nil {
+			t.Fatalf("登记令牌失败: %v", err)
+		}
+		if _, err := ps.SetDeviceMetadata(token, "expo", metaId, models.DeviceMetadata{Timezone: timezone}); err != nil {
+			t.Fatalf("记录设备信息失败: %v", err)
+		}
+	}
+
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC) // 上海 8:00，东京 9:00
+	batches, err := PlanLocalTime([]string{"alice", "bob", "carol", "dave", "erin", "alice"}, 9, time.UTC, now)
+	if err != nil {
+		t.Fatalf("PlanLocalTime() failed, err: %v", err)
+	}
+
+	type plan struct {
+		SendAt    time.Time
+		Timezones []string
+		MetaIDs   []string
+	}
+	var got []plan
+	for _, batch := range batches {
+		got = append(got, plan{batch.SendAt.UTC(), batch.Timezones, batch.MetaIDs})
+	}
+	want := []plan{
+		{time.Date(2026, 3, 1, 1, 0, 0, 0, time.UTC), []string{"Asia/Hong_Kong", "Asia/Shanghai"}, []string{"alice", "carol"}},
+		{time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC), []string{"UTC"}, []string{"dave", "erin"}},
+		{time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC), []string{"Asia/Tokyo"}, []string{"bob"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("PlanLocalTime() = %+v, want %+v", got, want)
+	}
+
+	if _, err := PlanLocalTime([]string{"alice"}, 24, time.UTC, now); err == nil {
+		t.Error("localHour 超出 0-23 应返回错误")
+	}
+}
@@ -0,0 +1,198 @@
+package schedule_service
+
+import (
+	"context"
+	"log"
+	"push-base-service/models"
+	"push-base-service/service/pebble_service"
+	"push-base-service/service/push_service"
+	"sync"
+	"time"
+)
+
+// Scheduler 定时推送调度器，定期领取到期任务并发送
+type Scheduler struct {
+	config      *Config
+	pushManager *push_service.Manager
+	stopCh      chan struct{}
+	wg          sync.WaitGroup
+	running     bool
+	mu          sync.Mutex
+}
+
+// NewScheduler 创建调度器
+func NewScheduler(config *Config, pushManager *push_service.Manager) *Scheduler {
+	if config == nil {
+		config = DefaultConfig()
+	}
+	config.ApplyDefaults()
+
+	return &Scheduler{
+		config:      config,
+		pushManager: pushManager,
+	}
+}
+
+// Start 启动调度器
+func (s *Scheduler) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.running {
+		return
+	}
+
+	// 恢复上次退出时中断的任务：幂等键已记录结果的任务不再发送，未记录结果的任务重新发送
+	if count, err := pebble_service.ResetSendingScheduledPushes(); err != nil {
+		log.Printf("⚠️ 恢复中断的定时推送任务失败: %v", err)
+	} else if count > 0 {
+		log.Printf("♻️ 已恢复 %d 个中断的定时推送任务", count)
+	}
+
+	s.stopCh = make(chan struct{})
+	s.wg.Add(1)
+	go s.loop()
+
+	s.running = true
+	log.Printf("✅ 定时推送调度器已启动: interval=%v", s.config.PollInterval)
+}
+
+// Stop 停止调度器，等待正在发送的任务完成
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.running {
+		return
+	}
+
+	close(s.stopCh)
+	s.wg.Wait()
+	s.running = false
+	log.Printf("🛑 定时推送调度器已停止")
+}
+
+// loop 调度主循环
+func (s *Scheduler) loop() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stopCh:
+			return
+		case <-ticker.C:
+			s.drain()
+		}
+	}
+}
+
+// drain 领取并发送所有到期任务
+func (s *Scheduler) drain() {
+	for {
+		jobs, err := pebble_service.ClaimDueScheduledPushes(time.Now().Unix(), s.config.BatchSize)
+		if err != nil {
+			log.Printf("❌ 领取到期定时推送任务失败: %v", err)
+			return
+		}
+		if len(jobs) == 0 {
+			return
+		}
+
+		for _, job := range jobs {
+			select {
+			case <-s.stopCh:
+				// 未发送的任务在下次启动时恢复
+				return
+			default:
+			}
+			s.execute(job)
+		}
+
+		if len(jobs) < s.config.BatchSize {
+			return
+		}
+	}
+}
+
+// execute 发送单个定时推送任务
+func (s *Scheduler) execute(job *models.ScheduledPush) {
+	// 幂等保护：服务在发送完成后、更新任务状态前重启时，恢复的任务不会被重复发送
+	idempotencyKey := "schedule:" + job.ID
+	record, claimed, err := pebble_service.ClaimIdempotencyKey(idempotencyKey, "schedule")
+	if err == nil && !claimed && record.Result == nil {
+		// 上次发送中途退出，没有记录结果，无法确认已送达：释放幂等键后重新发送
+		log.Printf("♻️ 定时推送任务上次未发送完成，重新发送: ID=%s", job.ID)
+		if _, err = pebble_service.ReleasePendingIdempotencyKey(idempotencyKey); err == nil {
+			record, claimed, err = pebble_service.ClaimIdempotencyKey(idempotencyKey, "schedule")
+		}
+	}
+	if err != nil {
+		log.Printf("❌ 检查定时推送任务幂等键失败: ID=%s, 错误: %v", job.ID, err)
+		s.finish(job, nil, err)
+		return
+	}
+	if !claimed {
+		log.Printf("🔁 定时推送任务已发送过，跳过: ID=%s", job.ID)
+		s.finish(job, record.Result, nil)
+		return
+	}
+
+	notification := &push_service.PushNotification{
+		Title:    job.Title,
+		Body:     job.Body,
+		Data:     job.Data,
+		Sound:    job.Sound,
+		Priority: job.Priority,
+		TenantID: job.TenantID,
+	}
+	if notification.Sound == "" {
+		notification.Sound = "default"
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.config.SendTimeout)
+	defer cancel()
+
+	batchResult, err := s.pushManager.SendCustomNotificationToUsers(ctx, job.MetaIDs, notification)
+	if err != nil {
+		log.Printf("❌ 定时推送任务发送失败: ID=%s, 错误: %v", job.ID, err)
+		if releaseErr := pebble_service.ReleaseIdempotencyKey(idempotencyKey); releaseErr != nil {
+			log.Printf("⚠️ 释放定时推送任务幂等键失败: ID=%s, 错误: %v", job.ID, releaseErr)
+		}
+		s.finish(job, nil, err)
+		return
+	}
+
+	result := map[string]interface{}{
+		"totalUsers":     batchResult.TotalUsers,
+		"totalPlatforms": batchResult.TotalPlatforms,
+		"successCount":   batchResult.SuccessCount,
+		"failureCount":   batchResult.FailureCount,
+	}
+	if err := pebble_service.CompleteIdempotencyKey(idempotencyKey, result); err != nil {
+		log.Printf("⚠️ 记录定时推送任务幂等结果失败: ID=%s, 错误: %v", job.ID, err)
+	}
+
+	log.Printf("✅ 定时推送任务已发送: ID=%s, 总用户=%d, 成功=%d, 失败=%d",
+		job.ID, batchResult.TotalUsers, batchResult.SuccessCount, batchResult.FailureCount)
+	s.finish(job, result, nil)
+}
+
+// finish 更新任务的最终状态
+func (s *Scheduler) finish(job *models.ScheduledPush, result map[string]interface{}, err error) {
+	job.SentAt = time.Now().Unix()
+	job.Result = result
+	if err != nil {
+		job.Status = models.ScheduleStatusFailed
+		job.Error = err.Error()
+	} else {
+		job.Status = models.ScheduleStatusSent
+		job.Error = ""
+	}
+
+	if err := pebble_service.SaveScheduledPush(job); err != nil {
+		log.Printf("❌ 更新定时推送任务状态失败: ID=%s, 错误: %v", job.ID, err)
+	}
+}
@@ -0,0 +1,43 @@
+package schedule_service
+
+import (
+	"context"
+	"push-base-service/models"
+	"push-base-service/service/pebble_service"
+	"push-base-service/service/push_service"
+	"testing"
+	"time"
+)
+
+func TestExecuteResendsInterruptedJob(t *testing.T) {
+	if err := pebble_service.InitializeGlobalService(&pebble_service.Config{DBPath: t.TempDir()}); err != nil {
+		t.Fatalf("初始化 Pebble 服务失败: %v", err)
+	}
+	t.Cleanup(func() { pebble_service.CloseGlobalService() })
+
+	manager := push_service.NewManager()
+	if err := manager.RegisterMockProvider(0); err != nil {
+		t.Fatalf("RegisterMockProvider() failed, err: %v", err)
+	}
+	store := push_service.NewMemoryTokenStore()
+	store.SetUserToken(context.Background(), "alice", push_service.ProviderTypeMock, "mock-alice")
+	manager.SetTokenStore(store)
+	scheduler := NewScheduler(&Config{PollInterval: time.Hour}, manager)
+
+	// 上次发送中途退出：幂等键已占用但没有记录结果，恢复后应重新发送而不是直接标记为已发送
+	if _, _, err := pebble_service.ClaimIdempotencyKey("schedule:job-1", "schedule"); err != nil {
+		t.Fatalf("ClaimIdempotencyKey() failed, err: %v", err)
+	}
+	job := &models.ScheduledPush{ID: "job-1", MetaIDs: []string{"alice"}, Title: "t", Body: "b", Status: models.ScheduleStatusSending}
+	scheduler.execute(job)
+	if job.Status != models.ScheduleStatusSent || job.Result == nil || job.Result["successCount"] != 1 {
+		t.Fatalf("interrupted job = status %s, result %v; want resent", job.Status, job.Result)
+	}
+
+	// 已记录结果的任务再次恢复时不重复发送，沿用首次结果
+	again := &models.ScheduledPush{ID: "job-1", MetaIDs: []string{"alice"}, Title: "t", Body: "b", Status: models.ScheduleStatusSending}
+	scheduler.execute(again)
+	if again.Status != models.ScheduleStatusSent || again.Result["successCount"] != float64(1) {
+		t.Errorf("completed job = status %s, result %v; want stored result", again.Status, again.Result)
+	}
+}
@@ -0,0 +1,82 @@
+package selftest_service
+
+import (
+	"context"
+	"fmt"
+	"push-base-service/service/expo_service"
+	"push-base-service/service/pebble_service"
+	"push-base-service/service/socket_client_service"
+	"sync"
+)
+
+// NewPebbleCheck 打开 Pebble 数据目录并执行一次写入/读取/删除往返
+func NewPebbleCheck(config *pebble_service.Config) Check {
+	return Check{
+		Name: "pebble",
+		Run: func(ctx context.Context) error {
+			service := pebble_service.NewPebbleService(config)
+			if err := service.Initialize(); err != nil {
+				return err
+			}
+			defer service.Close()
+
+			return service.SelfTest()
+		},
+	}
+}
+
+// NewProviderCheck 对 Expo 推送接口做一次空跑（发送无效令牌），确认网络与鉴权配置可用，不会投递到任何设备
+func NewProviderCheck(config *expo_service.Config) Check {
+	return Check{
+		Name: "provider:expo",
+		Run: func(ctx context.Context) error {
+			return expo_service.NewManagerWithConfig(config).HealthCheck(ctx)
+		},
+	}
+}
+
+// NewSocketCheck 与上游 Socket 服务器完成一次握手后立即断开
+// 客户端会自动重试连接，因此连接错误不立即判定失败，超时后报告最后一次错误
+func NewSocketCheck(config *socket_client_service.Config) Check {
+	name := config.Name
+	if name == "" {
+		name = config.ServerURL
+	}
+
+	return Check{
+		Name: "socket:" + name,
+		Run: func(ctx context.Context) error {
+			connected := make(chan struct{})
+			var once sync.Once
+			var mu sync.Mutex
+			var lastErr error
+
+			client := socket_client_service.NewClient(config)
+			client.OnConnect = func() {
+				once.Do(func() { close(connected) })
+			}
+			client.OnError = func(err error) {
+				mu.Lock()
+				lastErr = err
+				mu.Unlock()
+			}
+
+			if err := client.Start(); err != nil {
+				return fmt.Errorf("连接 Socket 服务器失败: %w", err)
+			}
+			defer client.Stop()
+
+			select {
+			case <-connected:
+				return nil
+			case <-ctx.Done():
+				mu.Lock()
+				defer mu.Unlock()
+				if lastErr != nil {
+					return fmt.Errorf("Socket 握手超时，最后一次错误: %w", lastErr)
+				}
+				return fmt.Errorf("Socket 握手超时: %w", ctx.Err())
+			}
+		},
+	}
+}
@@ -0,0 +1,110 @@
+package selftest_service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// 检查结果状态
+const (
+	StatusPass = "pass" // 通过
+	StatusFail = "fail" // 失败
+	StatusSkip = "skip" // 跳过（如功能未启用）
+)
+
+// Check 单项自检
+type Check struct {
+	Name string                          // 检查名称，如 "pebble"、"socket"
+	Run  func(ctx context.Context) error // 返回 nil 表示通过，返回 Skip() 表示跳过
+}
+
+// CheckResult 单项自检结果
+type CheckResult struct {
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	Message    string `json:"message,omitempty"`
+	DurationMs int64  `json:"durationMs"`
+}
+
+// Report 自检报告，以 JSON 输出供部署流水线判断
+type Report struct {
+	Passed     bool           `json:"passed"`
+	StartedAt  time.Time      `json:"startedAt"`
+	DurationMs int64          `json:"durationMs"`
+	Checks     []*CheckResult `json:"checks"`
+}
+
+// skipError 表示检查被跳过
+type skipError struct {
+	reason string
+}
+
+func (e *skipError) Error() string {
+	return e.reason
+}
+
+// Skip 返回表示跳过检查的错误，reason 写入报告
+func Skip(reason string) error {
+	return &skipError{reason: reason}
+}
+
+// Run 依次执行所有检查，每项检查使用独立的超时时间；任一检查失败则报告不通过
+func Run(ctx context.Context, checks []Check, timeout time.Duration) *Report {
+	report := &Report{
+		Passed:    true,
+		StartedAt: time.Now(),
+		Checks:    make([]*CheckResult, 0, len(checks)),
+	}
+
+	for _, check := range checks {
+		result := runCheck(ctx, check, timeout)
+		if result.Status == StatusFail {
+			report.Passed = false
+		}
+		report.Checks = append(report.Checks, result)
+	}
+
+	report.DurationMs = time.Since(report.StartedAt).Milliseconds()
+	return report
+}
+
+// runCheck 执行单项检查，检查中的 panic 视为失败
+func runCheck(ctx context.Context, check Check, timeout time.Duration) (result *CheckResult) {
+	start := time.Now()
+	result = &CheckResult{Name: check.Name, Status: StatusPass}
+
+	checkCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	defer func() {
+		if r := recover(); r != nil {
+			result.Status = StatusFail
+			result.Message = fmt.Sprintf("panic: %v", r)
+		}
+		result.DurationMs = time.Since(start).Milliseconds()
+	}()
+
+	err := check.Run(checkCtx)
+	var skip *skipError
+	switch {
+	case err == nil:
+	case errors.As(err, &skip):
+		result.Status = StatusSkip
+		result.Message = skip.reason
+	default:
+		result.Status = StatusFail
+		result.Message = err.Error()
+	}
+	return result
+}
+
+// Write 将报告以缩进 JSON 写出
+func (r *Report) Write(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
@@ -0,0 +1,64 @@
+package selftest_service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"push-base-service/service/pebble_service"
+	"testing"
+	"time"
+)
+
+func TestRunReportsEachStatus(t *testing.T) {
+	checks := []Check{
+		{Name: "ok", Run: func(ctx context.Context) error { return nil }},
+		{Name: "skipped", Run: func(ctx context.Context) error { return Skip("disabled") }},
+		{Name: "broken", Run: func(ctx context.Context) error { return errors.New("boom") }},
+		{Name: "panics", Run: func(ctx context.Context) error { panic("bad") }},
+		{Name: "slow", Run: func(ctx context.Context) error { <-ctx.Done(); return ctx.Err() }},
+	}
+
+	report := Run(context.Background(), checks, 50*time.Millisecond)
+	if report.Passed {
+		t.Fatalf("report should fail when any check fails")
+	}
+
+	want := map[string]string{"ok": StatusPass, "skipped": StatusSkip, "broken": StatusFail, "panics": StatusFail, "slow": StatusFail}
+	for _, result := range report.Checks {
+		if result.Status != want[result.Name] {
+			t.Errorf("check %s status = %s, want %s (message: %s)", result.Name, result.Status, want[result.Name], result.Message)
+		}
+	}
+	if report.Checks[1].Message != "disabled" {
+		t.Errorf("skip message = %q, want %q", report.Checks[1].Message, "disabled")
+	}
+}
+
+func TestRunPassesWithOnlySkips(t *testing.T) {
+	report := Run(context.Background(), []Check{
+		{Name: "skipped", Run: func(ctx context.Context) error { return Skip("disabled") }},
+	}, time.Second)
+	if !report.Passed {
+		t.Fatalf("skipped checks should not fail the report")
+	}
+
+	var buf bytes.Buffer
+	if err := report.Write(&buf); err != nil {
+		t.Fatalf("Write() failed, err: %v", err)
+	}
+	var decoded Report
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("report is not valid JSON: %v", err)
+	}
+	if !decoded.Passed || len(decoded.Checks) != 1 {
+		t.Errorf("decoded report = %+v", decoded)
+	}
+}
+
+func TestPebbleCheck(t *testing.T) {
+	check := NewPebbleCheck(&pebble_service.Config{DBPath: t.TempDir()})
+	if err := check.Run(context.Background()); err != nil {
+		t.Fatalf("pebble check failed, err: %v", err)
+	}
+}
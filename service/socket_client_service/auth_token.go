@@ -0,0 +1,184 @@
+package socket_client_service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+)
+
+const (
+	// DefaultAuthRefreshBefore 令牌过期前提前刷新的时间
+	DefaultAuthRefreshBefore = 60 * time.Second
+
+	// authRetryDelay 获取令牌失败后重试的间隔
+	authRetryDelay = 10 * time.Second
+
+	// authErrorRefreshInterval 连接出错时重新获取令牌的最短间隔（连接错误可能由令牌失效引起）
+	authErrorRefreshInterval = 30 * time.Second
+)
+
+// authMinRefreshDelay 两次刷新之间的最短间隔，令牌有效期短于提前刷新时间时避免连续刷新
+var authMinRefreshDelay = 5 * time.Second
+
+// AuthToken 上游连接使用的短期认证令牌，作为 extraPushAuthKey 发送
+type AuthToken struct {
+	Token     string    // 令牌
+	ExpiresAt time.Time // 过期时间，零值表示不过期（不定时刷新）
+}
+
+// AuthTokenProvider 获取上游连接的认证令牌，代替静态的 ExtraPushAuthKey。
+// 每次连接、令牌即将过期以及连接出错时调用，config 为所连接上游的配置
+type AuthTokenProvider func(ctx context.Context, config *Config) (*AuthToken, error)
+
+// authTokenResponse 令牌接口的响应
+type authTokenResponse struct {
+	Token     string `json:"token"`
+	ExpiresIn int64  `json:"expiresIn"` // 有效秒数
+	ExpiresAt int64  `json:"expiresAt"` // 过期时间 (Unix 秒)，与 expiresIn 二选一
+}
+
+// NewHTTPTokenProvider 从令牌接口获取认证令牌：POST tokenURL，请求体为 {"name": 上游名称, "serverUrl": 服务器地址}，
+// secret 不为空时带 Authorization: Bearer secret；响应为 {"token": "...", "expiresIn": 秒} 或 {"token": "...", "expiresAt": Unix 秒}
+func NewHTTPTokenProvider(tokenURL, secret string, timeout time.Duration) AuthTokenProvider {
+	client := &http.Client{Timeout: timeout}
+	return func(ctx context.Context, config *Config) (*AuthToken, error) {
+		body, err := json.Marshal(map[string]string{"name": config.Name, "serverUrl": config.ServerURL})
+		if err != nil {
+			return nil, err
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, bytes.NewReader(body))
+		if err != nil {
+			return nil, fmt.Errorf("创建令牌请求失败: %w", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		if secret != "" {
+			req.Header.Set("Authorization", "Bearer "+secret)
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("请求令牌接口失败: %w", err)
+		}
+		defer resp.Body.Close()
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("令牌接口返回 HTTP %d: %s", resp.StatusCode, data)
+		}
+
+		var result authTokenResponse
+		if err := json.Unmarshal(data, &result); err != nil {
+			return nil, fmt.Errorf("解析令牌接口响应失败: %w", err)
+		}
+		token := &AuthToken{Token: result.Token}
+		switch {
+		case result.ExpiresAt > 0:
+			token.ExpiresAt = time.Unix(result.ExpiresAt, 0)
+		case result.ExpiresIn > 0:
+			token.ExpiresAt = time.Now().Add(time.Duration(result.ExpiresIn) * time.Second)
+		}
+		return token, nil
+	}
+}
+
+// SetAuthTokenProvider 设置认证令牌来源，下次连接时生效；nil 表示使用静态的 ExtraPushAuthKey
+func (c *Client) SetAuthTokenProvider(provider AuthTokenProvider) {
+	c.authMu.Lock()
+	defer c.authMu.Unlock()
+
+	c.tokenProvider = provider
+}
+
+// resolveAuthKey 返回本次连接使用的 extraPushAuthKey：配置了令牌来源时获取新令牌并安排过期前刷新，
+// 获取失败时安排稍后重试；否则使用静态的 ExtraPushAuthKey
+func (c *Client) resolveAuthKey() (string, error) {
+	c.authMu.Lock()
+	provider := c.tokenProvider
+	c.authMu.Unlock()
+	if provider == nil {
+		return c.config.ExtraPushAuthKey, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(c.config.Timeout)*time.Second)
+	defer cancel()
+	token, err := provider(ctx, c.config)
+	if err == nil && (token == nil || token.Token == "") {
+		err = errors.New("令牌为空")
+	}
+
+	c.authMu.Lock()
+	defer c.authMu.Unlock()
+	c.tokenFetchedAt = time.Now()
+	if err != nil {
+		c.scheduleReauthLocked(authRetryDelay)
+		return "", fmt.Errorf("获取上游认证令牌失败: %w", err)
+	}
+	if !token.ExpiresAt.IsZero() {
+		c.scheduleReauthLocked(max(time.Until(token.ExpiresAt)-c.authRefreshBefore(), authMinRefreshDelay))
+	} else if c.reauthTimer != nil {
+		c.reauthTimer.Stop()
+	}
+	return token.Token, nil
+}
+
+// authRefreshBefore 令牌过期前提前刷新的时间
+func (c *Client) authRefreshBefore() time.Duration {
+	if c.config.AuthRefreshBefore > 0 {
+		return time.Duration(c.config.AuthRefreshBefore) * time.Second
+	}
+	return DefaultAuthRefreshBefore
+}
+
+// scheduleReauthLocked 安排 delay 后重新获取令牌并重连（替换已安排的刷新），调用方需持有 authMu
+func (c *Client) scheduleReauthLocked(delay time.Duration) {
+	if !c.active {
+		return
+	}
+	if c.reauthTimer != nil {
+		c.reauthTimer.Stop()
+	}
+	c.reauthTimer = time.AfterFunc(delay, c.reauthenticate)
+}
+
+// stopReauth 停止令牌刷新，客户端停止时调用
+func (c *Client) stopReauth() {
+	c.authMu.Lock()
+	defer c.authMu.Unlock()
+
+	c.active = false
+	if c.reauthTimer != nil {
+		c.reauthTimer.Stop()
+		c.reauthTimer = nil
+	}
+}
+
+// reauthenticate 获取新令牌后断开当前连接并以新令牌重新连接，客户端已停止时不处理
+func (c *Client) reauthenticate() {
+	if !c.isActive() {
+		return
+	}
+
+	log.Printf("🔑 刷新上游认证令牌并重新连接: %s", c.config.ServerURL)
+	if err := c.reconnect(); err != nil {
+		log.Printf("❌ 使用新令牌重新连接失败，将稍后重试: %v", err)
+	}
+}
+
+// onConnectError 配置了令牌来源时，连接出错可能是令牌已失效，距上次获取令牌超过一定间隔后重新获取并重连
+func (c *Client) onConnectError() {
+	c.authMu.Lock()
+	refresh := c.tokenProvider != nil && c.active && time.Since(c.tokenFetchedAt) >= authErrorRefreshInterval
+	if refresh {
+		c.tokenFetchedAt = time.Now()
+	}
+	c.authMu.Unlock()
+
+	if refresh {
+		go c.reauthenticate()
+	}
+}
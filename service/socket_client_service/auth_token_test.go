@@ -0,0 +1,86 @@
+package socket_client_service
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	socketio "github.com/zishang520/socket.io/servers/socket/v3"
+)
+
+func TestAuthTokenRefreshReauthenticates(t *testing.T) {
+	authMinRefreshDelay = 200 * time.Millisecond
+	defer func() { authMinRefreshDelay = 5 * time.Second }()
+
+	// 令牌接口：每次签发新的令牌，有效期 1 秒
+	var (
+		mu     sync.Mutex
+		issued []string
+	)
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		if r.Header.Get("Authorization") != "Bearer token-secret" || body["name"] != "shard-1" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		mu.Lock()
+		token := fmt.Sprintf("token-%d", len(issued)+1)
+		issued = append(issued, token)
+		mu.Unlock()
+		json.NewEncoder(w).Encode(map[string]interface{}{"token": token, "expiresIn": 1})
+	}))
+	t.Cleanup(tokenServer.Close)
+
+	// 上游：记录每次连接携带的 extraPushAuthKey
+	connected := make(chan string, 10)
+	io := socketio.NewServer(nil, nil)
+	io.On("connection", func(clients ...any) {
+		connected <- clients[0].(*socketio.Socket).Handshake().Query.Query().Get("extraPushAuthKey")
+	})
+	upstream := httptest.NewServer(io.ServeHandler(nil))
+	t.Cleanup(func() {
+		io.Close(nil)
+		upstream.Close()
+	})
+
+	client := NewClient(&Config{
+		Name:              "shard-1",
+		ServerURL:         upstream.URL,
+		AuthTokenURL:      tokenServer.URL,
+		AuthTokenSecret:   "token-secret",
+		AuthRefreshBefore: 1,
+	})
+	if err := client.Start(); err != nil {
+		t.Fatalf("Start() failed, err: %v", err)
+	}
+	t.Cleanup(client.Stop)
+
+	// 首次连接使用第一个令牌，令牌过期前刷新并以新令牌重新连接
+	for _, want := range []string{"token-1", "token-2"} {
+		select {
+		case got := <-connected:
+			if got != want {
+				t.Fatalf("upstream received extraPushAuthKey %q, want %q", got, want)
+			}
+		case <-time.After(10 * time.Second):
+			t.Fatalf("no connection with %s", want)
+		}
+	}
+
+	// 停止后不再刷新
+	client.Stop()
+	mu.Lock()
+	count := len(issued)
+	mu.Unlock()
+	time.Sleep(500 * time.Millisecond)
+	mu.Lock()
+	defer mu.Unlock()
+	if len(issued) != count {
+		t.Errorf("%d tokens issued after Stop(), want 0", len(issued)-count)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"net/url"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/zishang520/socket.io/clients/engine/v3/transports"
@@ -17,10 +18,16 @@ import (
 
 // Config Socket.IO 客户端配置
 type Config struct {
+	Name             string `yaml:"name" json:"name"`                               // 上游名称，用于日志和监控指标，默认使用服务器地址
 	ServerURL        string `yaml:"server_url" json:"server_url"`                   // 服务器地址
 	ExtraPushAuthKey string `yaml:"extra_push_auth_key" json:"extra_push_auth_key"` // 用户MetaID
 	Path             string `yaml:"path" json:"path"`                               // Socket.IO路径，默认 "/socket.io/"
 	Timeout          int    `yaml:"timeout" json:"timeout"`                         // 连接超时秒数，默认10秒
+	StaleTimeout     int    `yaml:"stale_timeout" json:"stale_timeout"`             // 超过多少秒未收到上游任何消息（含心跳）视为连接失活并强制重连，0 表示不检测
+
+	AuthTokenURL      string `yaml:"auth_token_url" json:"auth_token_url"`           // 令牌接口地址，配置后每次连接前获取短期令牌代替 ExtraPushAuthKey
+	AuthTokenSecret   string `yaml:"auth_token_secret" json:"auth_token_secret"`     // 请求令牌接口的凭证（Bearer）
+	AuthRefreshBefore int    `yaml:"auth_refresh_before" json:"auth_refresh_before"` // 令牌过期前多少秒刷新并重新认证，默认60秒
 }
 
 // SocketData WebSocket generic data structure
@@ -41,6 +48,8 @@ type PushMessage struct {
 type ChatNotificationMessage struct {
 	Type string               `json:"type"`
 	Data *ExtraServiceMessage `json:"data"`
+
+	Upstream string `json:"-"` // 收到消息的上游名称，用于向该上游发送推送确认；非 Socket 来源的消息为空
 }
 
 // ExtraChatMessage 聊天消息
@@ -62,11 +71,57 @@ const (
 	WS_SERVER_NOTIFY_GROUP_CHAT   = "WS_SERVER_NOTIFY_GROUP_CHAT"
 	WS_SERVER_NOTIFY_GROUP_ROLE   = "WS_SERVER_NOTIFY_GROUP_ROLE"
 
+	// Friend requests and payments
+	WS_SERVER_NOTIFY_FRIEND_REQUEST = "WS_SERVER_NOTIFY_FRIEND_REQUEST"
+	WS_SERVER_NOTIFY_PAYMENT        = "WS_SERVER_NOTIFY_PAYMENT"
+
 	// Generic response
 	WS_RESPONSE_SUCCESS = "WS_RESPONSE_SUCCESS"
 	WS_RESPONSE_ERROR   = "WS_RESPONSE_ERROR"
+
+	// Backpressure control (sent to the upstream)
+	WS_CLIENT_BACKPRESSURE = "WS_CLIENT_BACKPRESSURE"
+
+	// Push acknowledgement (sent to the upstream)
+	WS_PUSH_ACK = "WS_PUSH_ACK"
 )
 
+// 推送中心处理的消息类型（ChatNotificationMessage.Type）
+const (
+	MessageTypePrivateChat   = "private_chat"
+	MessageTypeGroupChat     = "group_chat"
+	MessageTypeFriendRequest = "friend_request"
+	MessageTypePayment       = "payment"
+)
+
+// 入站消息统计的方法分类
+const (
+	InboundMethodHeartBeat     = "HEART_BEAT"
+	InboundMethodPrivateChat   = "PRIVATE_CHAT"
+	InboundMethodGroupChat     = "GROUP_CHAT"
+	InboundMethodFriendRequest = "FRIEND_REQUEST"
+	InboundMethodPayment       = "PAYMENT"
+	InboundMethodUnknown       = "unknown"
+)
+
+// InboundMethod 将 SocketData 的 M 字段归类为入站统计的方法分类，避免未知方法产生无限多的指标标签
+func InboundMethod(m string) string {
+	switch strings.ToUpper(m) {
+	case HEART_BEAT, PONG:
+		return InboundMethodHeartBeat
+	case WS_SERVER_NOTIFY_PRIVATE_CHAT:
+		return InboundMethodPrivateChat
+	case WS_SERVER_NOTIFY_GROUP_CHAT, WS_SERVER_NOTIFY_GROUP_ROLE:
+		return InboundMethodGroupChat
+	case WS_SERVER_NOTIFY_FRIEND_REQUEST:
+		return InboundMethodFriendRequest
+	case WS_SERVER_NOTIFY_PAYMENT:
+		return InboundMethodPayment
+	default:
+		return InboundMethodUnknown
+	}
+}
+
 // WebSocket code constants
 const (
 	WS_CODE_HEART_BEAT      = 10
@@ -74,8 +129,35 @@ const (
 	WS_CODE_SERVER          = 0
 	WS_CODE_SEND_SUCCESS    = 200
 	WS_CODE_SEND_ERROR      = 400
+
+	WS_CODE_BACKPRESSURE_SLOW   = 429 // 请求上游放慢或缓存消息
+	WS_CODE_BACKPRESSURE_RESUME = 200 // 积压已消化，上游可恢复正常发送
 )
 
+// 背压信号动作
+const (
+	BackpressureActionSlow   = "slow"
+	BackpressureActionResume = "resume"
+)
+
+// BackpressureSignal 发送给上游的背压控制消息内容（SocketData.D）
+type BackpressureSignal struct {
+	Action        string `json:"action"`        // slow / resume
+	Pending       int64  `json:"pending"`       // 当前积压的消息数
+	HighWatermark int    `json:"highWatermark"` // 触发背压的积压阈值
+	Timestamp     int64  `json:"timestamp"`     // 发送时间 (Unix 毫秒)
+}
+
+// PushAck 推送成功后发送给上游的确认消息内容（SocketData.D），上游据此统计推送覆盖率并决定是否重新下发
+type PushAck struct {
+	PinId     string `json:"pinId"`     // 消息 PIN ID
+	ChatType  string `json:"chatType"`  // private_chat / group_chat
+	Delivered int    `json:"delivered"` // 推送成功的设备数
+	Failed    int    `json:"failed"`    // 推送失败的设备数
+	Skipped   int    `json:"skipped"`   // 被跳过（屏蔽、关闭红包通知、暂停通知）的用户数
+	Timestamp int64  `json:"timestamp"` // 发送时间 (Unix 毫秒)
+}
+
 // Client Socket.IO 客户端
 type Client struct {
 	config    *Config
@@ -83,10 +165,22 @@ type Client struct {
 	connected bool
 	mu        sync.RWMutex
 
+	// 运行状态、认证令牌刷新和失活检测（authMu 保护）
+	authMu         sync.Mutex
+	tokenProvider  AuthTokenProvider
+	reauthTimer    *time.Timer
+	tokenFetchedAt time.Time     // 上次获取令牌的时间
+	active         bool          // Start 之后、Stop 之前为 true，停止后不再刷新令牌、不再检测失活
+	watchdogStop   chan struct{} // 关闭时停止失活检测
+
+	lastReceivedAt atomic.Int64 // 最近收到上游消息（或发起连接）的时间 (Unix 纳秒)
+
 	// 消息处理回调
 	OnMessage                 func(*PushMessage)
 	OnChatNotificationMessage func(*ChatNotificationMessage) // 聊天消息回调
 	OnHeartbeat               func()                         // 心跳回调
+	OnSocketData              func(method string, size int)  // 收到上游 SocketData 消息时的回调（方法分类、字节数），用于流量统计
+	OnStale                   func(idle time.Duration)       // 连接失活被强制重连时的回调（距最近收到消息的时长）
 	OnConnect                 func()
 	OnDisconnect              func()
 	OnError                   func(error)
@@ -101,13 +195,40 @@ func NewClient(config *Config) *Client {
 		config.Timeout = 10
 	}
 
-	return &Client{
+	client := &Client{
 		config: config,
 	}
+	if config.AuthTokenURL != "" {
+		client.tokenProvider = NewHTTPTokenProvider(config.AuthTokenURL, config.AuthTokenSecret, time.Duration(config.Timeout)*time.Second)
+	}
+	return client
 }
 
 // Start 启动客户端连接
 func (c *Client) Start() error {
+	c.mu.RLock()
+	running := c.socket != nil && c.connected
+	c.mu.RUnlock()
+	if running {
+		return nil
+	}
+
+	c.authMu.Lock()
+	c.active = true
+	usingToken := c.tokenProvider != nil
+	c.startWatchdogLocked()
+	c.authMu.Unlock()
+
+	// 获取令牌可能需要请求令牌接口，不能在持有锁时进行
+	authKey, err := c.resolveAuthKey()
+	if err != nil {
+		log.Printf("❌ %v", err)
+		if c.OnError != nil {
+			go c.OnError(err)
+		}
+		return err
+	}
+
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -127,7 +248,7 @@ func (c *Client) Start() error {
 	options.SetPath(c.config.Path)
 	options.SetQuery(
 		url.Values{
-			"extraPushAuthKey": {c.config.ExtraPushAuthKey},
+			"extraPushAuthKey": {authKey},
 		},
 	)
 	options.SetTimeout(time.Duration(c.config.Timeout) * time.Second)
@@ -143,27 +264,36 @@ func (c *Client) Start() error {
 	}
 
 	c.socket = socket
+	c.markReceived()
 
 	// 设置事件处理器
 	c.setupEventHandlers()
 
-	log.Printf("🚀 Socket.IO client connecting to %s with ExtraPushAuthKey: %s", serverURL, c.config.ExtraPushAuthKey)
+	if usingToken {
+		log.Printf("🚀 Socket.IO client connecting to %s with auth token", serverURL)
+	} else {
+		log.Printf("🚀 Socket.IO client connecting to %s with ExtraPushAuthKey: %s", serverURL, c.config.ExtraPushAuthKey)
+	}
 
 	return nil
 }
 
 // Stop 停止客户端
 func (c *Client) Stop() {
+	c.stopReauth()
+	c.stopWatchdog()
+
 	c.mu.Lock()
-	defer c.mu.Unlock()
+	socket := c.socket
+	c.socket = nil
+	c.connected = false
+	c.mu.Unlock()
 
-	if c.socket != nil {
-		c.socket.Disconnect()
-		c.socket = nil
+	// 断开连接会同步触发 disconnect 事件处理器（其中需要加锁），不能在持有锁时调用
+	if socket != nil {
+		socket.Disconnect()
 	}
 
-	c.connected = false
-
 	if c.OnDisconnect != nil {
 		go c.OnDisconnect()
 	}
@@ -171,6 +301,29 @@ func (c *Client) Stop() {
 	log.Println("📴 Socket.IO client stopped")
 }
 
+// isActive 客户端是否处于运行中（已 Start 且未 Stop）
+func (c *Client) isActive() bool {
+	c.authMu.Lock()
+	defer c.authMu.Unlock()
+
+	return c.active
+}
+
+// reconnect 断开当前连接并重新连接（令牌刷新、连接失活时使用），不触发 Stop 的停止逻辑
+func (c *Client) reconnect() error {
+	c.mu.Lock()
+	socket := c.socket
+	c.socket = nil
+	c.connected = false
+	c.mu.Unlock()
+
+	// 断开连接会同步触发 disconnect 事件处理器（其中需要加锁），不能在持有锁时调用
+	if socket != nil {
+		socket.Disconnect()
+	}
+	return c.Start()
+}
+
 // IsConnected 检查是否已连接
 func (c *Client) IsConnected() bool {
 	c.mu.RLock()
@@ -212,6 +365,7 @@ func (c *Client) setupEventHandlers() {
 		c.mu.Lock()
 		c.connected = true
 		c.mu.Unlock()
+		c.markReceived()
 
 		log.Printf("✅ Socket.IO connected successfully")
 
@@ -272,6 +426,9 @@ func (c *Client) setupEventHandlers() {
 		if c.OnError != nil {
 			go c.OnError(err)
 		}
+
+		// 令牌可能已失效，重新获取后重连
+		c.onConnectError()
 	})
 
 	// 通用错误事件（捕获其他类型的错误）
@@ -348,6 +505,7 @@ func (c *Client) setupEventHandlers() {
 
 // handlePushMessage 处理推送消息
 func (c *Client) handlePushMessage(data []interface{}, eventType string) {
+	c.markReceived()
 	if c.OnMessage == nil || len(data) == 0 {
 		return
 	}
@@ -371,6 +529,7 @@ func (c *Client) handleSocketData(data []interface{}) {
 		}
 	}()
 
+	c.markReceived()
 	if len(data) == 0 {
 		return
 	}
@@ -383,9 +542,11 @@ func (c *Client) handleSocketData(data []interface{}) {
 		socketData = &SocketData{}
 		err := json.Unmarshal([]byte(msgStr), socketData)
 		if err != nil {
+			c.recordSocketData(InboundMethodUnknown, len(msgStr))
 			log.Printf("⚠️ Failed to parse SocketData from string: %v", err)
 			return
 		}
+		c.recordSocketData(InboundMethod(socketData.M), len(msgStr))
 	} else if msgMap, ok := data[0].(map[string]interface{}); ok {
 		// 如果是map，转换为SocketData
 		socketData = &SocketData{}
@@ -398,7 +559,9 @@ func (c *Client) handleSocketData(data []interface{}) {
 		if d, ok := msgMap["D"]; ok {
 			socketData.D = d
 		}
+		c.recordSocketData(InboundMethod(socketData.M), encodedSize(msgMap))
 	} else {
+		c.recordSocketData(InboundMethodUnknown, encodedSize(data[0]))
 		log.Printf("⚠️ Unknown SocketData format: %v", data[0])
 		return
 	}
@@ -413,11 +576,31 @@ func (c *Client) handleSocketData(data []interface{}) {
 		c.handlePrivateChatMessage(socketData)
 	case WS_SERVER_NOTIFY_GROUP_CHAT, WS_SERVER_NOTIFY_GROUP_ROLE:
 		c.handleGroupChatMessage(socketData)
+	case WS_SERVER_NOTIFY_FRIEND_REQUEST:
+		c.handleNotifyMessage(socketData, MessageTypeFriendRequest)
+	case WS_SERVER_NOTIFY_PAYMENT:
+		c.handleNotifyMessage(socketData, MessageTypePayment)
 	default:
 		log.Printf("📨 未知方法: %s, 数据: %v", socketData.M, socketData.D)
 	}
 }
 
+// recordSocketData 上报收到的 SocketData 消息
+func (c *Client) recordSocketData(method string, size int) {
+	if c.OnSocketData != nil {
+		c.OnSocketData(method, size)
+	}
+}
+
+// encodedSize 估算非字符串消息的字节数（按 JSON 编码长度计算）
+func encodedSize(value interface{}) int {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return 0
+	}
+	return len(data)
+}
+
 // handleHeartbeatMessage 处理心跳消息
 func (c *Client) handleHeartbeatMessage(socketData *SocketData) {
 	log.Printf("💓 收到服务端心跳: M=%s, C=%v, D=%v", socketData.M, socketData.C, socketData.D)
@@ -432,7 +615,7 @@ func (c *Client) handlePrivateChatMessage(socketData *SocketData) {
 	log.Printf("💬 收到私聊消息: %v", socketData.M)
 
 	// 序列化 socketData.D 为 ExtraServiceMessage
-	data, err := c.parseExtraServiceMessage(socketData.D)
+	data, err := parseExtraServiceMessage(socketData.D)
 	if err != nil {
 		log.Printf("⚠️ 解析私聊消息失败: %v", err)
 		return
@@ -440,7 +623,7 @@ func (c *Client) handlePrivateChatMessage(socketData *SocketData) {
 
 	if c.OnChatNotificationMessage != nil {
 		chatMessage := &ChatNotificationMessage{
-			Type: "private_chat",
+			Type: MessageTypePrivateChat,
 			Data: data,
 		}
 		go c.OnChatNotificationMessage(chatMessage)
@@ -452,7 +635,7 @@ func (c *Client) handleGroupChatMessage(socketData *SocketData) {
 	log.Printf("👥 收到群聊消息: %v", socketData.M)
 
 	// 序列化 socketData.D 为 ExtraServiceMessage
-	data, err := c.parseExtraServiceMessage(socketData.D)
+	data, err := parseExtraServiceMessage(socketData.D)
 	if err != nil {
 		log.Printf("⚠️ 解析群聊消息失败: %v", err)
 		return
@@ -460,7 +643,26 @@ func (c *Client) handleGroupChatMessage(socketData *SocketData) {
 
 	if c.OnChatNotificationMessage != nil {
 		chatMessage := &ChatNotificationMessage{
-			Type: "group_chat",
+			Type: MessageTypeGroupChat,
+			Data: data,
+		}
+		go c.OnChatNotificationMessage(chatMessage)
+	}
+}
+
+// handleNotifyMessage 处理好友请求、付款等非聊天通知，消息格式与聊天消息相同（message 为通知内容）
+func (c *Client) handleNotifyMessage(socketData *SocketData, msgType string) {
+	log.Printf("🔔 收到%s通知: %v", msgType, socketData.M)
+
+	data, err := parseExtraServiceMessage(socketData.D)
+	if err != nil {
+		log.Printf("⚠️ 解析%s通知失败: %v", msgType, err)
+		return
+	}
+
+	if c.OnChatNotificationMessage != nil {
+		chatMessage := &ChatNotificationMessage{
+			Type: msgType,
 			Data: data,
 		}
 		go c.OnChatNotificationMessage(chatMessage)
@@ -468,7 +670,7 @@ func (c *Client) handleGroupChatMessage(socketData *SocketData) {
 }
 
 // parseExtraServiceMessage 解析 socketData.D 为 ExtraServiceMessage
-func (c *Client) parseExtraServiceMessage(data interface{}) (*ExtraServiceMessage, error) {
+func parseExtraServiceMessage(data interface{}) (*ExtraServiceMessage, error) {
 	if data == nil {
 		return &ExtraServiceMessage{
 			Message:              nil,
@@ -576,6 +778,39 @@ func (c *Client) parseExtraServiceMessage(data interface{}) (*ExtraServiceMessag
 	}, nil
 }
 
+// chatMessageTypes 上游通知方法对应的聊天消息类型
+var chatMessageTypes = map[string]string{
+	WS_SERVER_NOTIFY_PRIVATE_CHAT:   MessageTypePrivateChat,
+	WS_SERVER_NOTIFY_GROUP_CHAT:     MessageTypeGroupChat,
+	WS_SERVER_NOTIFY_GROUP_ROLE:     MessageTypeGroupChat,
+	WS_SERVER_NOTIFY_FRIEND_REQUEST: MessageTypeFriendRequest,
+	WS_SERVER_NOTIFY_PAYMENT:        MessageTypePayment,
+}
+
+// DecodeChatNotification 将抓取到的原始 SocketData 载荷（JSON 对象，或上游下发的 JSON 字符串）
+// 按客户端收到消息时的方式转换为聊天消息，用于回放线上消息
+func DecodeChatNotification(payload []byte) (*ChatNotificationMessage, error) {
+	var raw string
+	if err := json.Unmarshal(payload, &raw); err == nil {
+		payload = []byte(raw)
+	}
+
+	var socketData SocketData
+	if err := json.Unmarshal(payload, &socketData); err != nil {
+		return nil, fmt.Errorf("解析 SocketData 失败: %w", err)
+	}
+	msgType, ok := chatMessageTypes[strings.ToUpper(socketData.M)]
+	if !ok {
+		return nil, fmt.Errorf("不支持的消息方法: %q", socketData.M)
+	}
+
+	data, err := parseExtraServiceMessage(socketData.D)
+	if err != nil {
+		return nil, fmt.Errorf("解析消息内容失败: %w", err)
+	}
+	return &ChatNotificationMessage{Type: msgType, Data: data}, nil
+}
+
 // sendSocketData 发送SocketData格式消息
 func (c *Client) sendSocketData(socketData *SocketData) error {
 	defer func() {
@@ -597,6 +832,20 @@ func (c *Client) sendSocketData(socketData *SocketData) error {
 	return nil
 }
 
+// SendBackpressure 向上游发送背压控制消息，上游协议不支持时会忽略该消息
+func (c *Client) SendBackpressure(signal *BackpressureSignal) error {
+	code := WS_CODE_BACKPRESSURE_RESUME
+	if signal.Action == BackpressureActionSlow {
+		code = WS_CODE_BACKPRESSURE_SLOW
+	}
+	return c.sendSocketData(&SocketData{M: WS_CLIENT_BACKPRESSURE, C: code, D: signal})
+}
+
+// SendPushAck 向上游发送推送确认消息，上游协议不支持时会忽略该消息
+func (c *Client) SendPushAck(ack *PushAck) error {
+	return c.sendSocketData(&SocketData{M: WS_PUSH_ACK, C: WS_CODE_SEND_SUCCESS, D: ack})
+}
+
 // startHeartbeat 启动心跳
 func (c *Client) startHeartbeat() {
 	defer func() {
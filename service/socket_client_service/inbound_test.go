@@ -0,0 +1,44 @@
+package socket_client_service
+
+import "testing"
+
+func TestInboundMethod(t *testing.T) {
+	cases := map[string]string{
+		"HEART_BEAT":                      InboundMethodHeartBeat,
+		"pong":                            InboundMethodHeartBeat,
+		"WS_SERVER_NOTIFY_PRIVATE_CHAT":   InboundMethodPrivateChat,
+		"WS_SERVER_NOTIFY_GROUP_CHAT":     InboundMethodGroupChat,
+		"WS_SERVER_NOTIFY_GROUP_ROLE":     InboundMethodGroupChat,
+		"WS_SERVER_NOTIFY_FRIEND_REQUEST": InboundMethodFriendRequest,
+		"WS_SERVER_NOTIFY_PAYMENT":        InboundMethodPayment,
+		"WS_RESPONSE_SUCCESS":             InboundMethodUnknown,
+		"":                                InboundMethodUnknown,
+	}
+	for m, want := range cases {
+		if got := InboundMethod(m); got != want {
+			t.Errorf("InboundMethod(%q) = %s, want %s", m, got, want)
+		}
+	}
+}
+
+func TestManagerCountsInboundMessages(t *testing.T) {
+	manager := NewManager(&Config{Name: "test", ServerURL: "http://localhost"})
+	client := manager.upstreams[0].client
+
+	heartbeat := `{"M":"HEART_BEAT","C":10}`
+	client.handleSocketData([]interface{}{heartbeat})
+	client.handleSocketData([]interface{}{heartbeat})
+	client.handleSocketData([]interface{}{map[string]interface{}{"M": "WS_SERVER_NOTIFY_GROUP_ROLE"}})
+	client.handleSocketData([]interface{}{"not json"})
+
+	health := manager.GetUpstreamHealth()[0]
+	if health.InboundMessages[InboundMethodHeartBeat] != 2 || health.InboundBytes[InboundMethodHeartBeat] != int64(2*len(heartbeat)) {
+		t.Errorf("心跳统计不符合预期: messages=%v, bytes=%v", health.InboundMessages, health.InboundBytes)
+	}
+	if health.InboundMessages[InboundMethodGroupChat] != 1 || health.InboundBytes[InboundMethodGroupChat] == 0 {
+		t.Errorf("群聊统计不符合预期: messages=%v, bytes=%v", health.InboundMessages, health.InboundBytes)
+	}
+	if health.InboundMessages[InboundMethodUnknown] != 1 || health.LastInboundAt == 0 {
+		t.Errorf("未知消息统计不符合预期: %+v", health)
+	}
+}
@@ -2,73 +2,324 @@ package socket_client_service
 
 import (
 	"errors"
+	"fmt"
 	"log"
+	"push-base-service/service/metrics_service"
 	"sync"
+	"time"
 )
 
-// Manager 简化的Socket.IO客户端管理器
-type Manager struct {
-	client *Client
+// 上游连接监控指标
+var (
+	upstreamConnectedGauge = metrics_service.NewGaugeVec(
+		"push_socket_upstream_connected", "Whether the upstream Socket.IO connection is up (1) or down (0)", "upstream")
+	upstreamConnectsCounter = metrics_service.NewCounterVec(
+		"push_socket_upstream_connects_total", "Number of successful upstream Socket.IO connections", "upstream")
+	upstreamDisconnectsCounter = metrics_service.NewCounterVec(
+		"push_socket_upstream_disconnects_total", "Number of upstream Socket.IO disconnections", "upstream")
+	upstreamErrorsCounter = metrics_service.NewCounterVec(
+		"push_socket_upstream_errors_total", "Number of upstream Socket.IO errors", "upstream")
+	upstreamStaleReconnectsCounter = metrics_service.NewCounterVec(
+		"push_socket_upstream_stale_reconnects_total", "Number of reconnects forced because the upstream connection went silent", "upstream")
+	upstreamMessagesCounter = metrics_service.NewCounterVec(
+		"push_socket_upstream_messages_total", "Number of chat messages received from the upstream", "upstream", "type")
+	upstreamInboundMessagesCounter = metrics_service.NewCounterVec(
+		"push_socket_inbound_messages_total", "Number of raw socket messages received from the upstream by method", "upstream", "method")
+	upstreamInboundBytesCounter = metrics_service.NewCounterVec(
+		"push_socket_inbound_bytes_total", "Bytes of raw socket messages received from the upstream by method", "upstream", "method")
+)
+
+// UpstreamHealth 单个上游连接的健康状态
+type UpstreamHealth struct {
+	Name               string           `json:"name"`                // 上游名称
+	ServerURL          string           `json:"serverUrl"`           // 服务器地址
+	Connected          bool             `json:"connected"`           // 当前是否已连接
+	Connects           int64            `json:"connects"`            // 连接成功次数
+	Disconnects        int64            `json:"disconnects"`         // 断开次数
+	Errors             int64            `json:"errors"`              // 错误次数
+	StaleReconnects    int64            `json:"staleReconnects"`     // 连接失活（长时间未收到任何消息）被强制重连的次数
+	Messages           int64            `json:"messages"`            // 收到的聊天消息数
+	InboundMessages    map[string]int64 `json:"inboundMessages"`     // 按方法分类统计的入站消息数（含心跳），与推送量无关，用于发现上游流量骤降
+	InboundBytes       map[string]int64 `json:"inboundBytes"`        // 按方法分类统计的入站字节数
+	LastInboundAt      int64            `json:"lastInboundAt"`       // 最近收到任意入站消息的时间
+	LastError          string           `json:"lastError,omitempty"` // 最近一次错误
+	LastConnectedAt    int64            `json:"lastConnectedAt"`     // 最近连接时间
+	LastDisconnectedAt int64            `json:"lastDisconnectedAt"`  // 最近断开时间
+	LastMessageAt      int64            `json:"lastMessageAt"`       // 最近收到消息时间
+}
+
+// upstream 单个上游连接
+type upstream struct {
+	name   string
 	config *Config
+	client *Client
+	health UpstreamHealth
 	mu     sync.RWMutex
 }
 
-// NewManager 创建管理器
+// Manager Socket.IO客户端管理器，支持同时连接多个上游服务器
+type Manager struct {
+	upstreams []*upstream
+	configs   []*Config
+
+	// 所有上游共用的处理器
+	onMessage     func(*PushMessage)
+	onChatMessage func(*ChatNotificationMessage)
+	onConnect     func()
+	onDisconnect  func()
+	onError       func(error)
+	onHeartbeat   func()
+
+	onUpstreamConnect func(name string)
+
+	mu sync.RWMutex
+}
+
+// NewManager 创建管理器（单个上游）
 func NewManager(config *Config) *Manager {
-	return &Manager{
-		config: config,
-		client: NewClient(config),
+	return NewMultiManager([]*Config{config})
+}
+
+// NewMultiManager 创建管理器（多个上游），所有上游的消息汇聚到同一组处理器
+func NewMultiManager(configs []*Config) *Manager {
+	m := &Manager{}
+
+	for i, config := range configs {
+		if config == nil {
+			continue
+		}
+
+		name := config.Name
+		if name == "" {
+			name = config.ServerURL
+		}
+		if name == "" {
+			name = fmt.Sprintf("upstream-%d", i)
+		}
+
+		u := &upstream{
+			name:   name,
+			config: config,
+			client: NewClient(config),
+			health: UpstreamHealth{
+				Name:            name,
+				ServerURL:       config.ServerURL,
+				InboundMessages: make(map[string]int64),
+				InboundBytes:    make(map[string]int64),
+			},
+		}
+		m.configs = append(m.configs, config)
+		m.upstreams = append(m.upstreams, u)
+		m.bindClient(u)
 	}
+
+	return m
 }
 
-// Start 启动Socket.IO客户端
-func (m *Manager) Start() error {
-	m.mu.Lock()
-	defer m.mu.Unlock()
+// bindClient 将客户端回调绑定到管理器，先记录健康状态再转发给处理器
+func (m *Manager) bindClient(u *upstream) {
+	u.client.OnConnect = func() {
+		u.mu.Lock()
+		u.health.Connected = true
+		u.health.Connects++
+		u.health.LastConnectedAt = time.Now().Unix()
+		u.mu.Unlock()
+
+		upstreamConnectedGauge.Set(1, u.name)
+		upstreamConnectsCounter.Inc(u.name)
+
+		if handler := m.getConnectHandler(); handler != nil {
+			handler()
+		} else {
+			log.Printf("🚀 Socket.IO client connected [%s] for ExtraPushAuthKey: %s", u.name, u.config.ExtraPushAuthKey)
+		}
 
-	// 设置默认回调函数
-	m.client.OnConnect = func() {
-		log.Printf("🚀 Socket.IO client connected for ExtraPushAuthKey: %s", m.config.ExtraPushAuthKey)
+		m.mu.RLock()
+		upstreamHandler := m.onUpstreamConnect
+		m.mu.RUnlock()
+		if upstreamHandler != nil {
+			upstreamHandler(u.name)
+		}
 	}
 
-	m.client.OnDisconnect = func() {
-		log.Printf("📴 Socket.IO client disconnected")
+	u.client.OnDisconnect = func() {
+		u.mu.Lock()
+		u.health.Connected = false
+		u.health.Disconnects++
+		u.health.LastDisconnectedAt = time.Now().Unix()
+		u.mu.Unlock()
+
+		upstreamConnectedGauge.Set(0, u.name)
+		upstreamDisconnectsCounter.Inc(u.name)
+
+		if handler := m.getDisconnectHandler(); handler != nil {
+			handler()
+		} else {
+			log.Printf("📴 Socket.IO client disconnected [%s]", u.name)
+		}
 	}
 
-	m.client.OnError = func(err error) {
-		log.Printf("🔥 Socket.IO client error: %v", err)
+	u.client.OnError = func(err error) {
+		u.mu.Lock()
+		u.health.Errors++
+		if err != nil {
+			u.health.LastError = err.Error()
+		}
+		u.mu.Unlock()
+
+		upstreamErrorsCounter.Inc(u.name)
+
+		if handler := m.getErrorHandler(); handler != nil {
+			handler(err)
+		} else {
+			log.Printf("🔥 Socket.IO client error [%s]: %v", u.name, err)
+		}
 	}
 
-	// 设置默认消息处理器
-	if m.client.OnMessage == nil {
-		m.client.OnMessage = func(message *PushMessage) {
-			log.Printf("📨 Received push message:")
-			log.Printf("   Type: %s", message.Type)
-			if message.Data != nil {
-				log.Printf("   Data: %+v", message.Data)
-			}
+	u.client.OnStale = func(idle time.Duration) {
+		u.mu.Lock()
+		u.health.StaleReconnects++
+		u.mu.Unlock()
+
+		upstreamStaleReconnectsCounter.Inc(u.name)
+		log.Printf("⏰ Socket.IO connection stale [%s], idle %s, reconnecting", u.name, idle.Round(time.Second))
+	}
+
+	u.client.OnMessage = func(message *PushMessage) {
+		m.mu.RLock()
+		handler := m.onMessage
+		m.mu.RUnlock()
+
+		if handler != nil {
+			handler(message)
+			return
+		}
+
+		log.Printf("📨 Received push message [%s]:", u.name)
+		log.Printf("   Type: %s", message.Type)
+		if message.Data != nil {
+			log.Printf("   Data: %+v", message.Data)
 		}
 	}
 
-	return m.client.Start()
+	u.client.OnChatNotificationMessage = func(chatMessage *ChatNotificationMessage) {
+		if chatMessage != nil {
+			chatMessage.Upstream = u.name
+		}
+
+		u.mu.Lock()
+		u.health.Messages++
+		u.health.LastMessageAt = time.Now().Unix()
+		u.mu.Unlock()
+
+		if chatMessage != nil {
+			upstreamMessagesCounter.Inc(u.name, chatMessage.Type)
+		}
+
+		m.mu.RLock()
+		handler := m.onChatMessage
+		m.mu.RUnlock()
+
+		if handler != nil {
+			handler(chatMessage)
+		}
+	}
+
+	u.client.OnSocketData = func(method string, size int) {
+		u.mu.Lock()
+		u.health.InboundMessages[method]++
+		u.health.InboundBytes[method] += int64(size)
+		u.health.LastInboundAt = time.Now().Unix()
+		u.mu.Unlock()
+
+		upstreamInboundMessagesCounter.Inc(u.name, method)
+		upstreamInboundBytesCounter.Add(float64(size), u.name, method)
+	}
+
+	u.client.OnHeartbeat = func() {
+		m.mu.RLock()
+		handler := m.onHeartbeat
+		m.mu.RUnlock()
+
+		if handler != nil {
+			handler()
+		}
+	}
+}
+
+// Start 启动所有上游Socket.IO客户端，只要有一个上游启动成功即视为成功
+func (m *Manager) Start() error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if len(m.upstreams) == 0 {
+		return errors.New("no upstream socket server configured")
+	}
+
+	var errs []error
+	for _, u := range m.upstreams {
+		upstreamConnectedGauge.Set(0, u.name)
+		if err := u.client.Start(); err != nil {
+			log.Printf("❌ 启动上游 [%s] 失败: %v", u.name, err)
+			errs = append(errs, fmt.Errorf("%s: %w", u.name, err))
+		}
+	}
+
+	if len(errs) == len(m.upstreams) {
+		return errors.Join(errs...)
+	}
+	return nil
 }
 
-// Stop 停止Socket.IO客户端
+// Stop 停止所有Socket.IO客户端
 func (m *Manager) Stop() {
-	m.mu.Lock()
-	defer m.mu.Unlock()
+	m.mu.RLock()
+	defer m.mu.RUnlock()
 
-	if m.client != nil {
-		m.client.Stop()
+	for _, u := range m.upstreams {
+		u.client.Stop()
 	}
 }
 
-// IsRunning 检查是否运行中
+// IsRunning 检查是否运行中（任一上游已连接即视为运行中）
 func (m *Manager) IsRunning() bool {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
-	return m.client != nil && m.client.IsConnected()
+	for _, u := range m.upstreams {
+		if u.client.IsConnected() {
+			return true
+		}
+	}
+	return false
+}
+
+// GetUpstreamHealth 获取所有上游连接的健康状态
+func (m *Manager) GetUpstreamHealth() []UpstreamHealth {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	result := make([]UpstreamHealth, 0, len(m.upstreams))
+	for _, u := range m.upstreams {
+		u.mu.RLock()
+		health := u.health
+		health.InboundMessages = copyCounts(u.health.InboundMessages)
+		health.InboundBytes = copyCounts(u.health.InboundBytes)
+		u.mu.RUnlock()
+
+		health.Connected = u.client.IsConnected()
+		result = append(result, health)
+	}
+	return result
+}
+
+// copyCounts 复制计数表，避免调用方读取时与统计写入竞争
+func copyCounts(counts map[string]int64) map[string]int64 {
+	result := make(map[string]int64, len(counts))
+	for key, value := range counts {
+		result[key] = value
+	}
+	return result
 }
 
 // SetMessageHandler 设置消息处理器
@@ -76,7 +327,7 @@ func (m *Manager) SetMessageHandler(handler func(*PushMessage)) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	m.client.OnMessage = handler
+	m.onMessage = handler
 }
 
 // SetChatMessageHandler 设置聊天消息处理器
@@ -84,7 +335,7 @@ func (m *Manager) SetChatMessageHandler(handler func(*ChatNotificationMessage))
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	m.client.OnChatNotificationMessage = handler
+	m.onChatMessage = handler
 }
 
 // SetConnectHandler 设置连接处理器
@@ -92,7 +343,7 @@ func (m *Manager) SetConnectHandler(handler func()) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	m.client.OnConnect = handler
+	m.onConnect = handler
 }
 
 // SetDisconnectHandler 设置断开连接处理器
@@ -100,7 +351,7 @@ func (m *Manager) SetDisconnectHandler(handler func()) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	m.client.OnDisconnect = handler
+	m.onDisconnect = handler
 }
 
 // SetErrorHandler 设置错误处理器
@@ -108,7 +359,7 @@ func (m *Manager) SetErrorHandler(handler func(error)) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	m.client.OnError = handler
+	m.onError = handler
 }
 
 // SetHeartbeatHandler 设置心跳处理器
@@ -116,26 +367,156 @@ func (m *Manager) SetHeartbeatHandler(handler func()) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	m.client.OnHeartbeat = handler
+	m.onHeartbeat = handler
+}
+
+// SetAuthTokenProvider 设置所有上游的认证令牌来源（代替静态的 ExtraPushAuthKey 和令牌接口配置），下次连接时生效
+func (m *Manager) SetAuthTokenProvider(provider AuthTokenProvider) {
+	for _, u := range m.upstreams {
+		u.client.SetAuthTokenProvider(provider)
+	}
+}
+
+// SetUpstreamConnectHandler 设置单个上游连接（含重新连接）成功时的处理器，参数为上游名称
+func (m *Manager) SetUpstreamConnectHandler(handler func(name string)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.onUpstreamConnect = handler
 }
 
-// SendMessage 发送消息
+// getConnectHandler 获取连接处理器
+func (m *Manager) getConnectHandler() func() {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.onConnect
+}
+
+// getDisconnectHandler 获取断开连接处理器
+func (m *Manager) getDisconnectHandler() func() {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.onDisconnect
+}
+
+// getErrorHandler 获取错误处理器
+func (m *Manager) getErrorHandler() func(error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.onError
+}
+
+// SendMessage 向所有已连接的上游发送消息
 func (m *Manager) SendMessage(event string, data interface{}) error {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
-	if m.client == nil {
+	if len(m.upstreams) == 0 {
 		log.Printf("❌ Client not initialized")
 		return errors.New("client not initialized")
 	}
 
-	return m.client.SendMessage(event, data)
+	var errs []error
+	for _, u := range m.upstreams {
+		if err := u.client.SendMessage(event, data); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", u.name, err))
+		}
+	}
+
+	if len(errs) == len(m.upstreams) {
+		return errors.Join(errs...)
+	}
+	return nil
 }
 
-// GetConfig 获取配置
+// SignalBackpressure 向所有已连接的上游发送背压控制消息，全部发送失败时返回错误
+func (m *Manager) SignalBackpressure(signal *BackpressureSignal) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if len(m.upstreams) == 0 {
+		return errors.New("client not initialized")
+	}
+
+	var errs []error
+	for _, u := range m.upstreams {
+		if err := u.client.SendBackpressure(signal); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", u.name, err))
+		}
+	}
+
+	if len(errs) == len(m.upstreams) {
+		return errors.Join(errs...)
+	}
+	return nil
+}
+
+// SendPushAck 向收到消息的上游（name）发送推送确认；name 为空或不存在时（如进件日志恢复的消息）发送给所有已连接的上游，全部发送失败时返回错误
+func (m *Manager) SendPushAck(name string, ack *PushAck) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	targets := m.upstreams
+	for _, u := range m.upstreams {
+		if name != "" && u.name == name {
+			targets = []*upstream{u}
+			break
+		}
+	}
+	if len(targets) == 0 {
+		return errors.New("client not initialized")
+	}
+
+	var errs []error
+	for _, u := range targets {
+		if err := u.client.SendPushAck(ack); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", u.name, err))
+		}
+	}
+
+	if len(errs) == len(targets) {
+		return errors.Join(errs...)
+	}
+	return nil
+}
+
+// GetConfig 获取第一个上游的配置
 func (m *Manager) GetConfig() *Config {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
-	return m.config
+	if len(m.configs) == 0 {
+		return nil
+	}
+	return m.configs[0]
+}
+
+// GetConfigs 获取所有上游的配置
+func (m *Manager) GetConfigs() []*Config {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.configs
+}
+
+// 全局 Socket 客户端管理器（供管理接口读取上游状态）
+var (
+	globalManager   *Manager
+	globalManagerMu sync.RWMutex
+)
+
+// SetGlobalManager 设置全局 Socket 客户端管理器
+func SetGlobalManager(manager *Manager) {
+	globalManagerMu.Lock()
+	defer globalManagerMu.Unlock()
+
+	globalManager = manager
+}
+
+// GetGlobalManager 获取全局 Socket 客户端管理器，推送中心未启用时返回 nil
+func GetGlobalManager() *Manager {
+	globalManagerMu.RLock()
+	defer globalManagerMu.RUnlock()
+
+	return globalManager
 }
@@ -1,59 +1,66 @@
 package socket_client_service
 
+import (
+	"bytes"
+	"encoding/json"
+)
+
 // PrivateChatItem Private chat record item
 type PrivateChatItem struct {
-	From string `json:"from"` // Sender MetaId
-	// FromUserInfo *UserInfo   `json:"fromUserInfo"`
-	To string `json:"to"` // Receiver MetaId
-	// ToUserInfo   *UserInfo   `json:"toUserInfo"`
+	From         string      `json:"from"` // Sender MetaId
+	FromUserInfo *UserInfo   `json:"fromUserInfo"`
+	To           string      `json:"to"` // Receiver MetaId
+	ToUserInfo   *UserInfo   `json:"toUserInfo"`
+	TxId         string      `json:"txId"`
+	PinId        string      `json:"pinId"`
+	MetaId       string      `json:"metaId"`   // Message creator MetaId
+	Address      string      `json:"address"`  // Message creator address
+	UserInfo     *UserInfo   `json:"userInfo"` // User info
+	NickName     string      `json:"nickName"`
+	Protocol     string      `json:"protocol"`
+	Content      string      `json:"content"`
+	ContentType  string      `json:"contentType"`
+	Encryption   string      `json:"encryption"`
+	Version      string      `json:"version"`  // Version
+	ChatType     int64       `json:"chatType"` // 0-msg, 1-red, 2-img
+	Data         interface{} `json:"data"`
+	ReplyPin     string      `json:"replyPin"`
+	ReplyInfo    interface{} `json:"replyInfo"`
+	ReplyMetaId  string      `json:"replyMetaId"`
+	Timestamp    int64       `json:"timestamp"`   // Chat record timestamp
+	Params       string      `json:"params"`      // General field for future parameter additions
+	Chain        string      `json:"chain"`       // Chain type
+	BlockHeight  int64       `json:"blockHeight"` // Block height
+	Index        int64       `json:"index"`       //Index default -1
+	CandyBagFields
+}
+
+type GroupChatItem struct {
+	GroupId     string      `json:"groupId"`   //Room ID, unique
+	ChannelId   string      `json:"channelId"` //Channel ID, unique
+	MetanetId   string      `json:"metanetId"` //
 	TxId        string      `json:"txId"`
 	PinId       string      `json:"pinId"`
-	MetaId      string      `json:"metaId"`   // Message creator MetaId
-	Address     string      `json:"address"`  // Message creator address
-	UserInfo    *UserInfo   `json:"userInfo"` // User info
+	MetaId      string      `json:"metaId"`
+	Address     string      `json:"address"`
+	UserInfo    *UserInfo   `json:"userInfo"`
 	NickName    string      `json:"nickName"`
 	Protocol    string      `json:"protocol"`
 	Content     string      `json:"content"`
 	ContentType string      `json:"contentType"`
 	Encryption  string      `json:"encryption"`
 	Version     string      `json:"version"`  // Version
-	ChatType    int64       `json:"chatType"` // 0-msg, 1-red, 2-img
+	ChatType    int64       `json:"chatType"` //0-msg, 1-red, 2-img
 	Data        interface{} `json:"data"`
 	ReplyPin    string      `json:"replyPin"`
-	// ReplyInfo   *ReplyInfo  `json:"replyInfo"`
-	ReplyMetaId string `json:"replyMetaId"`
-	Timestamp   int64  `json:"timestamp"`   // Chat record timestamp
-	Params      string `json:"params"`      // General field for future parameter additions
-	Chain       string `json:"chain"`       // Chain type
-	BlockHeight int64  `json:"blockHeight"` // Block height
-	Index       int64  `json:"index"`       //Index default -1
-}
-
-type GroupChatItem struct {
-	GroupId     string    `json:"groupId"`   //Room ID, unique
-	ChannelId   string    `json:"channelId"` //Channel ID, unique
-	MetanetId   string    `json:"metanetId"` //
-	TxId        string    `json:"txId"`
-	PinId       string    `json:"pinId"`
-	MetaId      string    `json:"metaId"`
-	Address     string    `json:"address"`
-	UserInfo    *UserInfo `json:"userInfo"`
-	NickName    string    `json:"nickName"`
-	Protocol    string    `json:"protocol"`
-	Content     string    `json:"content"`
-	ContentType string    `json:"contentType"`
-	Encryption  string    `json:"encryption"`
-	Version     string    `json:"version"` // Version
-	// ChatType    models.ChatType `json:"chatType"` //0-msg, 1-red, 2-img
-	Data     interface{} `json:"data"`
-	ReplyPin string      `json:"replyPin"`
-	// ReplyInfo   *ReplyInfo      `json:"replyInfo"`
-	ReplyMetaId string `json:"replyMetaId"`
-	Timestamp   int64  `json:"timestamp"`   //Chat record timestamp
-	Params      string `json:"params"`      //General field for future parameter additions
-	Chain       string `json:"chain"`       //Chain type
-	BlockHeight int64  `json:"blockHeight"` //Block height
-	Index       int64  `json:"index"`       //Index default -1
+	ReplyInfo   interface{} `json:"replyInfo"`
+	ReplyMetaId string      `json:"replyMetaId"`
+	Timestamp   int64       `json:"timestamp"`   //Chat record timestamp
+	Params      string      `json:"params"`      //General field for future parameter additions
+	Chain       string      `json:"chain"`       //Chain type
+	BlockHeight int64       `json:"blockHeight"` //Block height
+	Index       int64       `json:"index"`       //Index default -1
+	CandyBagFields
 }
 
 type UserInfo struct {
@@ -65,3 +72,48 @@ type UserInfo struct {
 	ChatPublicKey   string `json:"chatPublicKey"`
 	ChatPublicKeyId string `json:"chatPublicKeyId"`
 }
+
+// CandyBagFields Candy bag (red packet) fields, present when chatType is 1 or 23
+type CandyBagFields struct {
+	Amount     interface{} `json:"amount"`     // Amount hint, string or number
+	ExpireTime int64       `json:"expireTime"` // Claim deadline (Unix seconds)
+	Deadline   int64       `json:"deadline"`   // Claim deadline, older field name
+}
+
+// DecodePrivateChatItem decodes a private chat message strictly: unknown fields and type mismatches are errors.
+// On error the returned item still holds every field that could be decoded.
+func DecodePrivateChatItem(message interface{}) (*PrivateChatItem, error) {
+	item := &PrivateChatItem{}
+	return item, decodeChatItem(message, item)
+}
+
+// DecodeGroupChatItem decodes a group chat message strictly, see DecodePrivateChatItem.
+func DecodeGroupChatItem(message interface{}) (*GroupChatItem, error) {
+	item := &GroupChatItem{}
+	return item, decodeChatItem(message, item)
+}
+
+// decodeChatItem decodes message (a map from the socket payload or raw JSON) into item.
+// If strict decoding fails, item is filled leniently so callers can still push with the fields that parsed.
+func decodeChatItem(message interface{}, item interface{}) error {
+	var data []byte
+	switch v := message.(type) {
+	case json.RawMessage:
+		data = v
+	case []byte:
+		data = v
+	default:
+		var err error
+		if data, err = json.Marshal(message); err != nil {
+			return err
+		}
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	strictErr := decoder.Decode(item)
+	if strictErr != nil {
+		_ = json.Unmarshal(data, item)
+	}
+	return strictErr
+}
@@ -0,0 +1,76 @@
+package socket_client_service
+
+import (
+	"log"
+	"time"
+)
+
+// markReceived 记录收到上游消息的时间，连接建立和发起连接时也会记录，作为失活检测的起点
+func (c *Client) markReceived() {
+	c.lastReceivedAt.Store(time.Now().UnixNano())
+}
+
+// staleTimeout 失活检测窗口，未配置时返回 0（不检测）
+func (c *Client) staleTimeout() time.Duration {
+	if c.config.StaleTimeout <= 0 {
+		return 0
+	}
+	return time.Duration(c.config.StaleTimeout) * time.Second
+}
+
+// startWatchdogLocked 启动失活检测（已启动或未配置时不处理），调用方需持有 authMu
+func (c *Client) startWatchdogLocked() {
+	timeout := c.staleTimeout()
+	if timeout == 0 || c.watchdogStop != nil {
+		return
+	}
+
+	c.watchdogStop = make(chan struct{})
+	go c.runWatchdog(timeout, c.watchdogStop)
+}
+
+// stopWatchdog 停止失活检测，客户端停止时调用
+func (c *Client) stopWatchdog() {
+	c.authMu.Lock()
+	defer c.authMu.Unlock()
+
+	if c.watchdogStop != nil {
+		close(c.watchdogStop)
+		c.watchdogStop = nil
+	}
+}
+
+// runWatchdog 定期检查距最近收到上游消息的时间，超过 timeout 时视为半开连接（服务端已不再响应但连接未断开），
+// 强制断开并重新连接；上次重连失败时也会在下一个窗口重试
+func (c *Client) runWatchdog(timeout time.Duration, stop <-chan struct{}) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("⚠️ Panic recovered in runWatchdog: %v", r)
+		}
+	}()
+
+	ticker := time.NewTicker(timeout / 4)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+
+		idle := time.Since(time.Unix(0, c.lastReceivedAt.Load()))
+		if idle < timeout || !c.isActive() {
+			continue
+		}
+
+		log.Printf("⏰ 上游 %s 已 %s 未收到任何消息，连接可能已失活，强制重新连接", c.config.ServerURL, idle.Round(time.Second))
+		c.markReceived()
+		if c.OnStale != nil {
+			go c.OnStale(idle)
+		}
+		if err := c.reconnect(); err != nil {
+			log.Printf("❌ 失活连接重新连接失败，将在下个检测窗口重试: %v", err)
+		}
+	}
+}
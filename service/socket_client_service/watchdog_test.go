@@ -0,0 +1,83 @@
+package socket_client_service
+
+import (
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	socketio "github.com/zishang520/socket.io/servers/socket/v3"
+)
+
+// newSilentSocketServer 启动只接受连接的上游，heartbeat 大于 0 时按该间隔向客户端发送服务端心跳
+func newSilentSocketServer(t *testing.T, heartbeat time.Duration) (*httptest.Server, *atomic.Int64) {
+	t.Helper()
+	var connections atomic.Int64
+	io := socketio.NewServer(nil, nil)
+	io.On("connection", func(clients ...any) {
+		connections.Add(1)
+		client := clients[0].(*socketio.Socket)
+		if heartbeat <= 0 {
+			return
+		}
+		go func() {
+			for client.Connected() {
+				client.Emit("message", `{"M":"HEART_BEAT","C":10}`)
+				time.Sleep(heartbeat)
+			}
+		}()
+	})
+	server := httptest.NewServer(io.ServeHandler(nil))
+	t.Cleanup(func() {
+		io.Close(nil)
+		server.Close()
+	})
+	return server, &connections
+}
+
+func TestWatchdogReconnectsStaleConnection(t *testing.T) {
+	server, connections := newSilentSocketServer(t, 0)
+
+	var stale atomic.Int64
+	client := NewClient(&Config{ServerURL: server.URL, StaleTimeout: 1})
+	client.OnStale = func(time.Duration) { stale.Add(1) }
+	if err := client.Start(); err != nil {
+		t.Fatalf("Start() failed, err: %v", err)
+	}
+	t.Cleanup(client.Stop)
+
+	// 上游一直不发送任何消息，超过失活窗口后强制重连
+	deadline := time.Now().Add(10 * time.Second)
+	for connections.Load() < 2 && time.Now().Before(deadline) {
+		time.Sleep(50 * time.Millisecond)
+	}
+	if connections.Load() < 2 || stale.Load() < 1 {
+		t.Fatalf("connections = %d, stale reconnects = %d, want a forced reconnect", connections.Load(), stale.Load())
+	}
+
+	// 停止后不再重连
+	client.Stop()
+	count := connections.Load()
+	time.Sleep(1500 * time.Millisecond)
+	if connections.Load() != count {
+		t.Errorf("%d connections after Stop(), want 0", connections.Load()-count)
+	}
+}
+
+func TestWatchdogKeepsActiveConnection(t *testing.T) {
+	server, connections := newSilentSocketServer(t, 200*time.Millisecond)
+
+	var stale atomic.Int64
+	client := NewClient(&Config{ServerURL: server.URL, StaleTimeout: 1})
+	client.OnStale = func(time.Duration) { stale.Add(1) }
+	if err := client.Start(); err != nil {
+		t.Fatalf("Start() failed, err: %v", err)
+	}
+	t.Cleanup(client.Stop)
+
+	// 服务端持续发送心跳，连接不视为失活
+	time.Sleep(2500 * time.Millisecond)
+	if connections.Load() != 1 || stale.Load() != 0 {
+		t.Errorf("connections = %d, stale reconnects = %d, want 1 connection without reconnects", connections.Load(), stale.Load())
+	}
+}
@@ -0,0 +1,78 @@
+package storage_service
+
+import (
+	"hash/fnv"
+	"math"
+	"sync/atomic"
+)
+
+// BloomFilter 布隆过滤器：MayContain 返回 false 时元素一定未加入过，返回 true 时可能加入过
+// 并发安全，Add 与 MayContain 均不加锁
+type BloomFilter struct {
+	words []uint64
+	bits  uint64 // 位数
+	k     uint64 // 哈希函数个数
+	count atomic.Int64
+}
+
+// NewBloomFilter 按预期元素数量和误判率创建布隆过滤器
+func NewBloomFilter(expectedItems int, falsePositiveRate float64) *BloomFilter {
+	if expectedItems < 1 {
+		expectedItems = 1
+	}
+	if falsePositiveRate <= 0 || falsePositiveRate >= 1 {
+		falsePositiveRate = 0.01
+	}
+
+	n := float64(expectedItems)
+	bits := uint64(math.Ceil(-n * math.Log(falsePositiveRate) / (math.Ln2 * math.Ln2)))
+	if bits < 64 {
+		bits = 64
+	}
+	k := uint64(math.Round(float64(bits) / n * math.Ln2))
+	if k < 1 {
+		k = 1
+	}
+
+	return &BloomFilter{
+		words: make([]uint64, (bits+63)/64),
+		bits:  bits,
+		k:     k,
+	}
+}
+
+// Add 加入元素
+func (f *BloomFilter) Add(item string) {
+	h1, h2 := bloomHashes(item)
+	for i := uint64(0); i < f.k; i++ {
+		position := (h1 + i*h2) % f.bits
+		atomic.OrUint64(&f.words[position/64], 1<<(position%64))
+	}
+	f.count.Add(1)
+}
+
+// MayContain 判断元素是否可能已加入
+func (f *BloomFilter) MayContain(item string) bool {
+	h1, h2 := bloomHashes(item)
+	for i := uint64(0); i < f.k; i++ {
+		position := (h1 + i*h2) % f.bits
+		if atomic.LoadUint64(&f.words[position/64])&(1<<(position%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Count 返回加入的元素数量（重复加入会重复计数）
+func (f *BloomFilter) Count() int64 {
+	return f.count.Load()
+}
+
+// bloomHashes 计算双重哈希的两个基础哈希值（Kirsch-Mitzenmacher）
+func bloomHashes(item string) (uint64, uint64) {
+	a := fnv.New64a()
+	a.Write([]byte(item))
+	b := fnv.New64()
+	b.Write([]byte(item))
+	return a.Sum64(), b.Sum64() | 1
+}
@@ -0,0 +1,78 @@
+package storage_service
+
+import "time"
+
+// 存储后端类型
+const (
+	BackendPebble = "pebble" // 本地 Pebble 数据库，适合单实例部署
+	BackendRedis  = "redis"  // Redis，多实例共享令牌、屏蔽列表和已通知 PIN
+)
+
+// Config 用户数据存储配置
+type Config struct {
+	Backend   string          `yaml:"backend" json:"backend"`         // 存储后端：pebble / redis
+	Redis     RedisConfig     `yaml:"redis" json:"redis"`             // Redis 后端配置
+	PinMaxAge time.Duration   `yaml:"pin_max_age" json:"pin_max_age"` // Pebble 后端已通知 PIN 记录保留时长，更早的记录定期清理，<= 0 表示永久保留
+	PinFilter PinFilterConfig `yaml:"pin_filter" json:"pin_filter"`   // 已通知 PIN 布隆过滤器配置（仅 Pebble 后端）
+}
+
+// PinFilterConfig 已通知 PIN 查询的布隆过滤器配置
+type PinFilterConfig struct {
+	Enabled           bool          `yaml:"enabled" json:"enabled"`                         // 是否启用
+	ExpectedItems     int           `yaml:"expected_items" json:"expected_items"`           // 预期记录数，决定过滤器大小
+	FalsePositiveRate float64       `yaml:"false_positive_rate" json:"false_positive_rate"` // 目标误判率
+	RebuildInterval   time.Duration `yaml:"rebuild_interval" json:"rebuild_interval"`       // 重建间隔（同时清理超过保留时长的记录）
+}
+
+// RedisConfig Redis 存储后端配置
+type RedisConfig struct {
+	Addr      string        `yaml:"addr" json:"addr"`             // Redis 地址，如 127.0.0.1:6379
+	Password  string        `yaml:"password" json:"password"`     // Redis 密码
+	DB        int           `yaml:"db" json:"db"`                 // Redis 数据库编号
+	KeyPrefix string        `yaml:"key_prefix" json:"key_prefix"` // 键前缀
+	PinTTL    time.Duration `yaml:"pin_ttl" json:"pin_ttl"`       // 已通知 PIN 记录保留时长
+}
+
+// DefaultConfig 返回默认配置
+func DefaultConfig() *Config {
+	return &Config{
+		Backend: BackendPebble,
+		Redis: RedisConfig{
+			Addr:      "127.0.0.1:6379",
+			KeyPrefix: "push:store:",
+			PinTTL:    30 * 24 * time.Hour,
+		},
+		PinFilter: PinFilterConfig{
+			ExpectedItems:     1000000,
+			FalsePositiveRate: 0.01,
+			RebuildInterval:   time.Hour,
+		},
+	}
+}
+
+// ApplyDefaults 为缺失的配置项填充默认值
+func (c *Config) ApplyDefaults() {
+	defaults := DefaultConfig()
+
+	if c.Backend == "" {
+		c.Backend = defaults.Backend
+	}
+	if c.Redis.Addr == "" {
+		c.Redis.Addr = defaults.Redis.Addr
+	}
+	if c.Redis.KeyPrefix == "" {
+		c.Redis.KeyPrefix = defaults.Redis.KeyPrefix
+	}
+	if c.Redis.PinTTL <= 0 {
+		c.Redis.PinTTL = defaults.Redis.PinTTL
+	}
+	if c.PinFilter.ExpectedItems <= 0 {
+		c.PinFilter.ExpectedItems = defaults.PinFilter.ExpectedItems
+	}
+	if c.PinFilter.FalsePositiveRate <= 0 || c.PinFilter.FalsePositiveRate >= 1 {
+		c.PinFilter.FalsePositiveRate = defaults.PinFilter.FalsePositiveRate
+	}
+	if c.PinFilter.RebuildInterval <= 0 {
+		c.PinFilter.RebuildInterval = defaults.PinFilter.RebuildInterval
+	}
+}
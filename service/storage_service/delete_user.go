@@ -0,0 +1,211 @@
+package storage_service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"push-base-service/models"
+	"push-base-service/service/pebble_service"
+	"sort"
+	"time"
+)
+
+// DeleteUserData 删除用户在所有集合中的数据（被遗忘权请求），返回删除报告
+//
+// 删除范围：令牌和设备、屏蔽聊天、屏蔽发送者和屏蔽所有群聊设置、聊天通知声音、推送偏好、退订原因和历史
+// （仍处于退订状态时保留不含其他信息的退订标记，避免重新推送）、
+// 投递记录（含提及通知的投递历史）和待查询回执、推送审计、推送追踪、QA 收件箱和 QA 账号登记、推送限流窗口、
+// 最近活跃用户列表中的记录和该用户参与的合并记录
+//
+// 删除不是事务：中途失败时已完成的步骤不会回滚，报告中会写明错误，可再次删除（删除是幂等的）
+func DeleteUserData(ctx context.Context, stores *Stores, ps *pebble_service.PebbleService, metaId string) (*models.UserDataDeletionReport, error) {
+	if metaId == "" {
+		return nil, fmt.Errorf("MetaID 不能为空")
+	}
+
+	report := &models.UserDataDeletionReport{
+		MetaID:    metaId,
+		Tokens:    []string{},
+		DeletedAt: time.Now().Unix(),
+	}
+	deleter := &userDataDeleter{ctx: ctx, stores: stores, ps: ps, report: report}
+	if err := deleter.run(); err != nil {
+		report.Error = err.Error()
+		log.Printf("❌ 删除用户数据失败: MetaID=%s, 错误: %v", metaId, err)
+		return report, err
+	}
+
+	log.Printf("🗑️ 已删除用户数据: MetaID=%s, 令牌=%d, 设备=%d, 屏蔽聊天=%d, 投递记录=%d, 审计记录=%d",
+		metaId, len(report.Tokens), report.Devices, report.BlockedChats, report.DeliveryRecords, report.AuditEntries)
+	return report, nil
+}
+
+// userDataDeleter 单次删除的执行状态
+type userDataDeleter struct {
+	ctx    context.Context
+	stores *Stores
+	ps     *pebble_service.PebbleService
+	report *models.UserDataDeletionReport
+}
+
+func (d *userDataDeleter) run() error {
+	if err := d.deleteTokens(); err != nil {
+		return fmt.Errorf("删除令牌失败: %w", err)
+	}
+	if err := d.deleteBlocks(); err != nil {
+		return fmt.Errorf("删除屏蔽设置失败: %w", err)
+	}
+	if err := d.deleteChatSounds(); err != nil {
+		return fmt.Errorf("删除聊天通知声音失败: %w", err)
+	}
+	return d.deletePebbleData()
+}
+
+// deleteTokens 删除令牌和关联的设备信息
+func (d *userDataDeleter) deleteTokens() error {
+	metaId := d.report.MetaID
+
+	userTokens, err := d.stores.Tokens.GetUserTokens(d.ctx, metaId)
+	if err != nil {
+		return err
+	}
+	if userTokens != nil && (len(userTokens.Tokens) > 0 || userTokens.TenantID != "") {
+		for platform := range userTokens.Tokens {
+			d.report.Tokens = append(d.report.Tokens, platform)
+		}
+		sort.Strings(d.report.Tokens)
+		if err := d.stores.Tokens.DeleteUserTokens(d.ctx, metaId); err != nil {
+			return err
+		}
+	}
+
+	// Redis 存储后端的设备信息同样保存在 Pebble 中
+	d.report.Devices, err = d.ps.DeleteUserDevices(metaId)
+	return err
+}
+
+// deleteBlocks 删除屏蔽聊天、屏蔽发送者和屏蔽所有群聊设置
+func (d *userDataDeleter) deleteBlocks() error {
+	metaId := d.report.MetaID
+
+	blockedChats, err := d.stores.BlockedChats.GetUserBlockedChats(d.ctx, metaId)
+	if err != nil {
+		return err
+	}
+	for _, chat := range blockedChats.BlockedChats {
+		if err := d.stores.BlockedChats.RemoveBlockedChat(d.ctx, metaId, chat.ChatID); err != nil {
+			return err
+		}
+		d.report.BlockedChats++
+	}
+
+	blockedSenders, err := d.stores.SenderBlocks.GetUserBlockedSenders(d.ctx, metaId)
+	if err != nil {
+		return err
+	}
+	for _, sender := range blockedSenders.BlockedSenders {
+		if err := d.stores.SenderBlocks.RemoveBlockedSender(d.ctx, metaId, sender.SenderID); err != nil {
+			return err
+		}
+		d.report.BlockedSenders++
+	}
+
+	allGroups, err := d.stores.SenderBlocks.GetAllGroupsBlock(d.ctx, metaId)
+	if err != nil {
+		return err
+	}
+	if allGroups.Blocked {
+		if err := d.stores.SenderBlocks.SetAllGroupsBlocked(d.ctx, metaId, false); err != nil {
+			return err
+		}
+		d.report.AllGroupsBlock = true
+	}
+	return nil
+}
+
+// deleteChatSounds 删除聊天通知声音（恢复默认声音即删除记录）
+func (d *userDataDeleter) deleteChatSounds() error {
+	metaId := d.report.MetaID
+
+	chatSounds, err := d.stores.ChatSounds.GetUserChatSounds(d.ctx, metaId)
+	if err != nil {
+		return err
+	}
+	for _, chatSound := range chatSounds.ChatSounds {
+		if err := d.stores.ChatSounds.SetChatSound(d.ctx, metaId, chatSound.ChatID, chatSound.ChatType, ""); err != nil {
+			return err
+		}
+		d.report.ChatSounds++
+	}
+	return nil
+}
+
+// deletePebbleData 删除只保存在 Pebble 中的数据
+func (d *userDataDeleter) deletePebbleData() error {
+	metaId := d.report.MetaID
+
+	preferences, err := d.ps.GetUserPreferences(metaId)
+	if err != nil {
+		return fmt.Errorf("删除推送偏好失败: %w", err)
+	}
+	if preferences != nil {
+		if err := d.ps.DeleteUserPreferences(metaId); err != nil {
+			return fmt.Errorf("删除推送偏好失败: %w", err)
+		}
+		d.report.Preferences = true
+	}
+
+	if d.report.Subscription, d.report.SuppressionKept, err = d.ps.EraseSubscription(metaId); err != nil {
+		return fmt.Errorf("删除退订记录失败: %w", err)
+	}
+	if d.report.DeliveryRecords, d.report.PendingReceipts, err = d.ps.DeleteUserDeliveries(metaId); err != nil {
+		return fmt.Errorf("删除投递记录失败: %w", err)
+	}
+	if d.report.AuditEntries, err = d.ps.DeleteUserPushAudits(metaId); err != nil {
+		return fmt.Errorf("删除推送审计失败: %w", err)
+	}
+	if d.report.TraceEvents, err = d.ps.DeleteUserTraceData(metaId); err != nil {
+		return fmt.Errorf("删除推送追踪失败: %w", err)
+	}
+
+	if d.report.InboxMessages, err = d.ps.ClearQAInbox(metaId); err != nil {
+		return fmt.Errorf("删除QA收件箱失败: %w", err)
+	}
+	if d.report.QAAccount, err = d.ps.IsQAAccount(metaId); err != nil {
+		return fmt.Errorf("移除QA账号失败: %w", err)
+	}
+	if d.report.QAAccount {
+		if err := d.ps.RemoveQAAccount(metaId); err != nil {
+			return fmt.Errorf("移除QA账号失败: %w", err)
+		}
+	}
+
+	if err := d.ps.DeleteThrottleWindow(metaId); err != nil {
+		return fmt.Errorf("删除限流窗口失败: %w", err)
+	}
+
+	// 内存中的活跃用户记录也要移除，否则下次定时保存时会重新写入
+	if tokenStore, ok := d.stores.Tokens.(*pebble_service.PebbleTokenStore); ok {
+		tokenStore.ForgetHotUser(metaId)
+	}
+	if d.report.HotUser, err = d.ps.RemoveHotUser(metaId); err != nil {
+		return fmt.Errorf("移除活跃用户记录失败: %w", err)
+	}
+	if d.report.MergeRecords, err = d.ps.DeleteUserMergeRecords(metaId); err != nil {
+		return fmt.Errorf("删除用户合并记录失败: %w", err)
+	}
+	return nil
+}
+
+// DeleteUserState 全局方法：删除用户在所有集合中的数据
+func DeleteUserState(metaId string) (*models.UserDataDeletionReport, error) {
+	stores, err := getGlobalStores()
+	if err != nil {
+		return nil, err
+	}
+	ps := pebble_service.GetGlobalService()
+	if ps == nil || !ps.IsInitialized() {
+		return nil, fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	return DeleteUserData(context.Background(), stores, ps, metaId)
+}
@@ -0,0 +1,96 @@
+package storage_service
+
+import (
+	"context"
+	"push-base-service/models"
+	"push-base-service/service/pebble_service"
+	"testing"
+)
+
+func TestDeleteUserDataRemovesAllCollections(t *testing.T) {
+	ps := pebble_service.NewPebbleService(&pebble_service.Config{DBPath: t.TempDir()})
+	if err := ps.Initialize(); err != nil {
+		t.Fatalf("初始化 Pebble 服务失败: %v", err)
+	}
+	t.Cleanup(func() { ps.Close() })
+	stores, err := NewStores(&Config{Backend: BackendPebble}, pebble_service.NewPebbleTokenStore(ps))
+	if err != nil {
+		t.Fatalf("创建存储失败: %v", err)
+	}
+	ctx := context.Background()
+
+	for _, metaId := range []string{"alice", "bob"} {
+		ps.SetUserTokenWithDevice(metaId, "expo", "expo-"+metaId, "expo-"+metaId)
+		stores.BlockedChats.AddBlockedChat(ctx, metaId, "group1", "group", "", 0)
+		stores.SenderBlocks.AddBlockedSender(ctx, metaId, "spammer", "")
+		stores.ChatSounds.SetChatSound(ctx, metaId, "group1", "group", "chime")
+		ps.SaveUserPreferences(&models.UserPreferences{MetaID: metaId, Locale: "ja"})
+		ps.SaveDeliveryRecords([]*models.DeliveryRecord{{PinID: "pin1", MetaID: metaId, Platform: "expo", Attempted: true}})
+		ps.AddPushAudits([]*models.PushAuditEntry{{MetaID: metaId, Title: "hi"}}, 0)
+	}
+	ps.SetUserTokenWithDevice("alice", "fcm", "fcm-alice", "fcm-alice")
+	stores.SenderBlocks.SetAllGroupsBlocked(ctx, "alice", true)
+	ps.Unsubscribe("alice", "spam", "user")
+	ps.SaveQAAccount("alice", "测试账号")
+	ps.AddQAInboxMessage(&models.QAInboxMessage{MetaID: "alice", Title: "hello"}, 0)
+	ps.SaveHotUsers([]string{"bob", "alice"})
+	ps.SaveUserMergeRecord(&models.UserMergeRecord{SourceMetaID: "alice-old", TargetMetaID: "alice"})
+	ps.SaveUserMergeRecord(&models.UserMergeRecord{SourceMetaID: "bob-old", TargetMetaID: "bob"})
+
+	report, err := DeleteUserData(ctx, stores, ps, "alice")
+	if err != nil {
+		t.Fatalf("删除用户数据失败: %v", err)
+	}
+	if len(report.Tokens) != 2 || report.Devices != 2 || report.BlockedChats != 1 || report.BlockedSenders != 1 ||
+		!report.AllGroupsBlock || report.ChatSounds != 1 || !report.Preferences || !report.Subscription || !report.SuppressionKept ||
+		report.DeliveryRecords != 1 || report.AuditEntries != 1 || report.InboxMessages != 1 || !report.QAAccount ||
+		!report.HotUser || report.MergeRecords != 1 {
+		t.Fatalf("删除报告不符合预期: %+v", report)
+	}
+
+	if tokens, _ := stores.Tokens.GetUserTokens(ctx, "alice"); len(tokens.Tokens) != 0 {
+		t.Errorf("令牌未删除: %+v", tokens.Tokens)
+	}
+	if device, _ := ps.GetDeviceInfo("expo-alice"); device != nil {
+		t.Errorf("设备未删除: %+v", device)
+	}
+	if chats, _ := stores.BlockedChats.GetUserBlockedChats(ctx, "alice"); len(chats.BlockedChats) != 0 {
+		t.Errorf("屏蔽聊天未删除: %+v", chats.BlockedChats)
+	}
+	if preferences, _ := ps.GetUserPreferences("alice"); preferences != nil {
+		t.Errorf("偏好未删除: %+v", preferences)
+	}
+	// 退订原因和历史被删除，但退订标记保留，删除数据不会让用户重新收到推送
+	if subscription, _ := ps.GetSubscription("alice"); subscription == nil || !subscription.Unsubscribed ||
+		subscription.Reason != "" || len(subscription.History) != 0 {
+		t.Errorf("退订记录应只保留退订标记: %+v", subscription)
+	}
+	if audits, _ := ps.GetPushAudits("alice", 0, 0); len(audits) != 0 {
+		t.Errorf("审计记录未删除: %d 条", len(audits))
+	}
+	if hotUsers, _ := ps.GetHotUsers(); len(hotUsers.MetaIDs) != 1 || hotUsers.MetaIDs[0] != "bob" {
+		t.Errorf("活跃用户列表应只剩 bob: %+v", hotUsers.MetaIDs)
+	}
+	if merges, _ := ps.ListUserMergeRecords("", 0); len(merges) != 1 || merges[0].TargetMetaID != "bob" {
+		t.Errorf("合并记录应只剩 bob: %+v", merges)
+	}
+
+	// 其他用户的数据不受影响
+	if tokens, _ := stores.Tokens.GetUserTokens(ctx, "bob"); tokens.Tokens["expo"] != "expo-bob" {
+		t.Errorf("bob 的令牌被误删: %+v", tokens.Tokens)
+	}
+	if device, _ := ps.GetDeviceInfo("expo-bob"); device == nil {
+		t.Error("bob 的设备被误删")
+	}
+	if records, _ := ps.GetDeliveryRecords("pin1"); len(records) != 1 || records[0].MetaID != "bob" {
+		t.Errorf("投递记录应只剩 bob: %+v", records)
+	}
+	if audits, _ := ps.GetPushAudits("bob", 0, 0); len(audits) != 1 {
+		t.Errorf("bob 的审计记录被误删: %d 条", len(audits))
+	}
+
+	// 再次删除是幂等的
+	if report, err := DeleteUserData(ctx, stores, ps, "alice"); err != nil || len(report.Tokens) != 0 || report.Devices != 0 {
+		t.Errorf("再次删除 = %+v, %v", report, err)
+	}
+}
@@ -0,0 +1,326 @@
+package storage_service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"push-base-service/models"
+	"push-base-service/service/pebble_service"
+	"sort"
+	"time"
+)
+
+// MergeRequest 用户合并请求：身份服务将 SourceMetaID 合并到 TargetMetaID 后，迁移源用户的推送状态
+type MergeRequest struct {
+	SourceMetaID string // 被合并的旧 MetaID
+	TargetMetaID string // 保留的 MetaID
+	Prefer       string // 冲突时保留的一方：target（默认）/ source
+	Reason       string // 合并原因，写入审计记录
+}
+
+// MergeUsers 将源用户的令牌、租户、偏好、屏蔽聊天和 QA 收件箱合并到目标用户，并写入审计记录
+//
+// 冲突处理规则：
+//   - 令牌：同一平台两边令牌不同时保留 Prefer 一方，另一方的令牌丢弃；其余平台的令牌迁移到目标用户
+//   - 租户：目标用户没有租户时使用源用户的租户；两边不同时保留 Prefer 一方
+//   - 偏好：目标用户没有偏好时直接迁移；两边都有时保留 Prefer 一方，其语言为空时使用另一方的语言，任一方隐藏通知内容时保持隐藏
+//   - 屏蔽聊天：取并集；同一聊天两边都屏蔽时取更严格的结果（永久屏蔽优先，否则取更晚的静音截止时间）
+//   - QA 收件箱：消息迁移到目标用户；源用户是 QA 账号时目标用户也登记为 QA 账号
+//
+// 合并不是事务：中途失败时已完成的步骤不会回滚，审计记录中会写明错误，可修复后再次合并（合并是幂等的）
+func MergeUsers(ctx context.Context, stores *Stores, ps *pebble_service.PebbleService, request *MergeRequest) (*models.UserMergeRecord, error) {
+	if request == nil || request.SourceMetaID == "" || request.TargetMetaID == "" {
+		return nil, fmt.Errorf("源和目标 MetaID 不能为空")
+	}
+	if request.SourceMetaID == request.TargetMetaID {
+		return nil, fmt.Errorf("源和目标 MetaID 不能相同")
+	}
+	prefer := request.Prefer
+	if prefer == "" {
+		prefer = models.MergePreferTarget
+	}
+	if prefer != models.MergePreferTarget && prefer != models.MergePreferSource {
+		return nil, fmt.Errorf("不支持的冲突处理方式: %s", prefer)
+	}
+
+	record := &models.UserMergeRecord{
+		SourceMetaID: request.SourceMetaID,
+		TargetMetaID: request.TargetMetaID,
+		Prefer:       prefer,
+		Reason:       request.Reason,
+		MovedTokens:  []string{},
+		Conflicts:    []models.MergeConflict{},
+		MergedAt:     time.Now().Unix(),
+	}
+
+	merger := &userMerger{ctx: ctx, stores: stores, ps: ps, record: record}
+	err := merger.run()
+	if err != nil {
+		record.Error = err.Error()
+	}
+
+	if saveErr := ps.SaveUserMergeRecord(record); saveErr != nil {
+		log.Printf("⚠️ 保存用户合并审计记录失败: %v", saveErr)
+		if err == nil {
+			err = fmt.Errorf("保存合并审计记录失败: %w", saveErr)
+		}
+	}
+
+	if err != nil {
+		log.Printf("❌ 合并用户 %s -> %s 失败: %v", record.SourceMetaID, record.TargetMetaID, err)
+		return record, err
+	}
+	log.Printf("🔀 已合并用户 %s -> %s: 令牌=%d, 屏蔽聊天=%d, 收件箱消息=%d, 冲突=%d",
+		record.SourceMetaID, record.TargetMetaID, len(record.MovedTokens), record.BlockedChatsMerged, record.InboxMessagesMoved, len(record.Conflicts))
+	return record, nil
+}
+
+// userMerger 单次合并的执行状态
+type userMerger struct {
+	ctx    context.Context
+	stores *Stores
+	ps     *pebble_service.PebbleService
+	record *models.UserMergeRecord
+}
+
+func (m *userMerger) run() error {
+	if err := m.mergeTokens(); err != nil {
+		return fmt.Errorf("合并令牌失败: %w", err)
+	}
+	if err := m.mergePreferences(); err != nil {
+		return fmt.Errorf("合并偏好失败: %w", err)
+	}
+	if err := m.mergeBlockedChats(); err != nil {
+		return fmt.Errorf("合并屏蔽聊天失败: %w", err)
+	}
+	if err := m.mergeQAInbox(); err != nil {
+		return fmt.Errorf("合并QA收件箱失败: %w", err)
+	}
+	return nil
+}
+
+func (m *userMerger) preferSource() bool {
+	return m.record.Prefer == models.MergePreferSource
+}
+
+func (m *userMerger) addConflict(conflictType, key, kept, detail string) {
+	m.record.Conflicts = append(m.record.Conflicts, models.MergeConflict{
+		Type:   conflictType,
+		Key:    key,
+		Kept:   kept,
+		Detail: detail,
+	})
+}
+
+// mergeTokens 迁移令牌和租户，源用户的令牌最后整体删除
+func (m *userMerger) mergeTokens() error {
+	source, target := m.record.SourceMetaID, m.record.TargetMetaID
+
+	sourceTokens, err := m.stores.Tokens.GetUserTokens(m.ctx, source)
+	if err != nil {
+		return err
+	}
+	targetTokens, err := m.stores.Tokens.GetUserTokens(m.ctx, target)
+	if err != nil {
+		return err
+	}
+
+	platforms := make([]string, 0, len(sourceTokens.Tokens))
+	for platform := range sourceTokens.Tokens {
+		platforms = append(platforms, platform)
+	}
+	sort.Strings(platforms)
+
+	for _, platform := range platforms {
+		token := sourceTokens.Tokens[platform]
+		existing, exists := targetTokens.Tokens[platform]
+		if exists && existing == token {
+			continue
+		}
+		if exists {
+			kept := models.MergePreferTarget
+			if m.preferSource() {
+				kept = models.MergePreferSource
+			}
+			m.addConflict(models.MergeConflictToken, platform, kept, "两个用户在该平台都有令牌，保留一方，另一方的令牌被丢弃")
+			if !m.preferSource() {
+				continue
+			}
+		}
+		if err := m.stores.Tokens.SetUserToken(m.ctx, target, platform, token); err != nil {
+			return err
+		}
+		m.record.MovedTokens = append(m.record.MovedTokens, platform)
+	}
+
+	tenantId := targetTokens.TenantID
+	switch {
+	case sourceTokens.TenantID == "" || sourceTokens.TenantID == targetTokens.TenantID:
+	case targetTokens.TenantID == "":
+		tenantId = sourceTokens.TenantID
+	case m.preferSource():
+		tenantId = sourceTokens.TenantID
+		m.addConflict(models.MergeConflictTenant, "tenantId", models.MergePreferSource,
+			fmt.Sprintf("源租户 %s，目标租户 %s", sourceTokens.TenantID, targetTokens.TenantID))
+	default:
+		m.addConflict(models.MergeConflictTenant, "tenantId", models.MergePreferTarget,
+			fmt.Sprintf("源租户 %s，目标租户 %s", sourceTokens.TenantID, targetTokens.TenantID))
+	}
+	if tenantId != targetTokens.TenantID {
+		if err := m.stores.Tokens.SetUserTenant(m.ctx, target, tenantId); err != nil {
+			return err
+		}
+	}
+	m.record.TenantID = tenantId
+
+	if len(sourceTokens.Tokens) > 0 || sourceTokens.TenantID != "" {
+		return m.stores.Tokens.DeleteUserTokens(m.ctx, source)
+	}
+	return nil
+}
+
+// mergePreferences 迁移偏好并删除源用户的偏好
+func (m *userMerger) mergePreferences() error {
+	source, target := m.record.SourceMetaID, m.record.TargetMetaID
+
+	sourcePreferences, err := m.ps.GetUserPreferences(source)
+	if err != nil || sourcePreferences == nil {
+		return err
+	}
+	targetPreferences, err := m.ps.GetUserPreferences(target)
+	if err != nil {
+		return err
+	}
+
+	merged := *sourcePreferences
+	if targetPreferences != nil {
+		if targetPreferences.Locale != sourcePreferences.Locale || targetPreferences.TranslatePreviews != sourcePreferences.TranslatePreviews ||
+			targetPreferences.HidePreviews != sourcePreferences.HidePreviews ||
+			targetPreferences.MuteCandyBags != sourcePreferences.MuteCandyBags || targetPreferences.MuteFriendRequests != sourcePreferences.MuteFriendRequests ||
+			targetPreferences.MutePayments != sourcePreferences.MutePayments {
+			kept := models.MergePreferTarget
+			if m.preferSource() {
+				kept = models.MergePreferSource
+			}
+			m.addConflict(models.MergeConflictPreferences, "preferences", kept,
+				fmt.Sprintf("源语言 %q，目标语言 %q", sourcePreferences.Locale, targetPreferences.Locale))
+		}
+
+		preferred, other := targetPreferences, sourcePreferences
+		if m.preferSource() {
+			preferred, other = sourcePreferences, targetPreferences
+		}
+		merged = *preferred
+		if merged.Locale == "" {
+			merged.Locale = other.Locale
+		}
+		// 任一方隐藏了通知内容时保持隐藏
+		merged.HidePreviews = preferred.HidePreviews || other.HidePreviews
+		// 任一方关闭了红包、好友请求或付款通知时保持关闭
+		merged.MuteCandyBags = preferred.MuteCandyBags || other.MuteCandyBags
+		merged.MuteFriendRequests = preferred.MuteFriendRequests || other.MuteFriendRequests
+		merged.MutePayments = preferred.MutePayments || other.MutePayments
+	}
+	merged.MetaID = target
+
+	if err := m.ps.SaveUserPreferences(&merged); err != nil {
+		return err
+	}
+	m.record.PreferencesMerged = true
+	return m.ps.DeleteUserPreferences(source)
+}
+
+// mergeBlockedChats 合并屏蔽聊天并移除源用户的屏蔽记录
+func (m *userMerger) mergeBlockedChats() error {
+	source, target := m.record.SourceMetaID, m.record.TargetMetaID
+
+	sourceChats, err := m.stores.BlockedChats.GetUserBlockedChats(m.ctx, source)
+	if err != nil {
+		return err
+	}
+	targetChats, err := m.stores.BlockedChats.GetUserBlockedChats(m.ctx, target)
+	if err != nil {
+		return err
+	}
+
+	existing := make(map[string]models.BlockedChat, len(targetChats.BlockedChats))
+	for _, chat := range targetChats.BlockedChats {
+		existing[chat.ChatID] = chat
+	}
+
+	for _, chat := range sourceChats.BlockedChats {
+		muteUntil := chat.MuteUntil
+		reason := chat.Reason
+		if current, blocked := existing[chat.ChatID]; blocked {
+			muteUntil = stricterMuteUntil(current.MuteUntil, chat.MuteUntil)
+			if current.Reason != "" {
+				reason = current.Reason
+			}
+			if current.MuteUntil != chat.MuteUntil {
+				m.addConflict(models.MergeConflictBlockedChat, chat.ChatID, "merged",
+					fmt.Sprintf("源静音截止 %d，目标静音截止 %d，取 %d（0 表示永久屏蔽）", chat.MuteUntil, current.MuteUntil, muteUntil))
+			}
+		}
+
+		if err := m.stores.BlockedChats.AddBlockedChat(m.ctx, target, chat.ChatID, chat.ChatType, reason, muteUntil); err != nil {
+			return err
+		}
+		if err := m.stores.BlockedChats.RemoveBlockedChat(m.ctx, source, chat.ChatID); err != nil {
+			return err
+		}
+		m.record.BlockedChatsMerged++
+	}
+	return nil
+}
+
+// stricterMuteUntil 返回更严格的静音截止时间：永久屏蔽（0）优先，否则取更晚者
+func stricterMuteUntil(a, b int64) int64 {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// mergeQAInbox 迁移 QA 收件箱消息和 QA 账号登记
+func (m *userMerger) mergeQAInbox() error {
+	source, target := m.record.SourceMetaID, m.record.TargetMetaID
+
+	moved, err := m.ps.MoveQAInbox(source, target)
+	if err != nil {
+		return err
+	}
+	m.record.InboxMessagesMoved = moved
+
+	isQA, err := m.ps.IsQAAccount(source)
+	if err != nil || !isQA {
+		return err
+	}
+	m.record.QAAccountMerged = true
+
+	targetIsQA, err := m.ps.IsQAAccount(target)
+	if err != nil {
+		return err
+	}
+	if !targetIsQA {
+		if _, err := m.ps.SaveQAAccount(target, "合并自 "+source); err != nil {
+			return err
+		}
+	}
+	return m.ps.RemoveQAAccount(source)
+}
+
+// MergeUserState 全局方法：合并两个 MetaID 的推送状态
+func MergeUserState(request *MergeRequest) (*models.UserMergeRecord, error) {
+	stores, err := getGlobalStores()
+	if err != nil {
+		return nil, err
+	}
+	ps := pebble_service.GetGlobalService()
+	if ps == nil || !ps.IsInitialized() {
+		return nil, fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	return MergeUsers(context.Background(), stores, ps, request)
+}
@@ -0,0 +1,154 @@
+//go:build !noredis
+
+package storage_service
+
+import (
+	"context"
+	"push-base-service/models"
+	"push-base-service/service/pebble_service"
+	"testing"
+	"time"
+)
+
+func newTestMergeEnv(t *testing.T) (*Stores, *pebble_service.PebbleService) {
+	t.Helper()
+
+	ps := pebble_service.NewPebbleService(&pebble_service.Config{DBPath: t.TempDir()})
+	if err := ps.Initialize(); err != nil {
+		t.Fatalf("初始化 Pebble 服务失败: %v", err)
+	}
+	t.Cleanup(func() { ps.Close() })
+
+	store, _ := newTestRedisStore(t)
+	return &Stores{Backend: BackendRedis, Tokens: store, BlockedChats: store, NotifiedPins: store}, ps
+}
+
+func TestMergeUsersMovesState(t *testing.T) {
+	stores, ps := newTestMergeEnv(t)
+	ctx := context.Background()
+
+	stores.Tokens.SetUserToken(ctx, "old", "expo", "expo-old")
+	stores.Tokens.SetUserToken(ctx, "old", "fcm", "fcm-old")
+	stores.Tokens.SetUserTenant(ctx, "old", "tenant-a")
+	stores.Tokens.SetUserToken(ctx, "new", "expo", "expo-new")
+
+	ps.SaveUserPreferences(&models.UserPreferences{MetaID: "old", Locale: "ja"})
+	ps.SaveUserPreferences(&models.UserPreferences{MetaID: "new", TranslatePreviews: true})
+
+	later := time.Now().Add(2 * time.Hour).Unix()
+	stores.BlockedChats.AddBlockedChat(ctx, "old", "group1", "group", "旧原因", 0)
+	stores.BlockedChats.AddBlockedChat(ctx, "old", "group2", "group", "", later)
+	stores.BlockedChats.AddBlockedChat(ctx, "new", "group1", "group", "新原因", time.Now().Add(time.Hour).Unix())
+
+	ps.SaveQAAccount("old", "测试账号")
+	ps.AddQAInboxMessage(&models.QAInboxMessage{MetaID: "old", Title: "hello"}, 0)
+
+	record, err := MergeUsers(ctx, stores, ps, &MergeRequest{SourceMetaID: "old", TargetMetaID: "new", Reason: "link-1"})
+	if err != nil {
+		t.Fatalf("合并失败: %v", err)
+	}
+
+	tokens, _ := stores.Tokens.GetUserTokens(ctx, "new")
+	if tokens.Tokens["expo"] != "expo-new" || tokens.Tokens["fcm"] != "fcm-old" || tokens.TenantID != "tenant-a" {
+		t.Fatalf("合并后令牌不符合预期: %+v", tokens)
+	}
+	if old, _ := stores.Tokens.GetUserTokens(ctx, "old"); len(old.Tokens) != 0 {
+		t.Fatalf("源用户令牌未删除: %+v", old.Tokens)
+	}
+	if len(record.MovedTokens) != 1 || record.MovedTokens[0] != "fcm" {
+		t.Errorf("MovedTokens = %v, want [fcm]", record.MovedTokens)
+	}
+
+	preferences, _ := ps.GetUserPreferences("new")
+	if preferences == nil || preferences.Locale != "ja" || !preferences.TranslatePreviews {
+		t.Errorf("合并后偏好不符合预期: %+v", preferences)
+	}
+	if old, _ := ps.GetUserPreferences("old"); old != nil {
+		t.Errorf("源用户偏好未删除: %+v", old)
+	}
+
+	chats, _ := stores.BlockedChats.GetUserBlockedChats(ctx, "new")
+	byId := map[string]models.BlockedChat{}
+	for _, chat := range chats.BlockedChats {
+		byId[chat.ChatID] = chat
+	}
+	if chat := byId["group1"]; chat.MuteUntil != 0 || chat.Reason != "新原因" {
+		t.Errorf("group1 应为永久屏蔽并保留目标原因: %+v", chat)
+	}
+	if byId["group2"].MuteUntil != later {
+		t.Errorf("group2 未迁移: %+v", byId["group2"])
+	}
+	if old, _ := stores.BlockedChats.GetUserBlockedChats(ctx, "old"); len(old.BlockedChats) != 0 {
+		t.Errorf("源用户屏蔽未移除: %+v", old.BlockedChats)
+	}
+
+	messages, _ := ps.GetQAInboxMessages("new", 0, 0)
+	if len(messages) != 1 || messages[0].MetaID != "new" || record.InboxMessagesMoved != 1 {
+		t.Errorf("收件箱消息未迁移: %+v", messages)
+	}
+	if isQA, _ := ps.IsQAAccount("new"); !isQA || !record.QAAccountMerged {
+		t.Errorf("目标用户应成为 QA 账号")
+	}
+	if isQA, _ := ps.IsQAAccount("old"); isQA {
+		t.Errorf("源用户 QA 账号未移除")
+	}
+
+	conflicts := map[string]string{}
+	for _, conflict := range record.Conflicts {
+		conflicts[conflict.Type+":"+conflict.Key] = conflict.Kept
+		if conflict.Detail == "" {
+			t.Errorf("冲突缺少说明: %+v", conflict)
+		}
+	}
+	if conflicts["token:expo"] != models.MergePreferTarget || conflicts["blocked_chat:group1"] != "merged" {
+		t.Errorf("冲突记录不符合预期: %+v", record.Conflicts)
+	}
+
+	records, err := ps.ListUserMergeRecords("old", 10)
+	if err != nil || len(records) != 1 || records[0].Reason != "link-1" {
+		t.Fatalf("审计记录不符合预期: %+v, %v", records, err)
+	}
+}
+
+func TestMergeUsersPreferSource(t *testing.T) {
+	stores, ps := newTestMergeEnv(t)
+	ctx := context.Background()
+
+	stores.Tokens.SetUserToken(ctx, "old", "expo", "expo-old")
+	stores.Tokens.SetUserTenant(ctx, "old", "tenant-a")
+	stores.Tokens.SetUserToken(ctx, "new", "expo", "expo-new")
+	stores.Tokens.SetUserTenant(ctx, "new", "tenant-b")
+
+	record, err := MergeUsers(ctx, stores, ps, &MergeRequest{SourceMetaID: "old", TargetMetaID: "new", Prefer: models.MergePreferSource})
+	if err != nil {
+		t.Fatalf("合并失败: %v", err)
+	}
+
+	tokens, _ := stores.Tokens.GetUserTokens(ctx, "new")
+	if tokens.Tokens["expo"] != "expo-old" || tokens.TenantID != "tenant-a" {
+		t.Fatalf("prefer=source 时应保留源用户数据: %+v", tokens)
+	}
+	if len(record.Conflicts) != 2 {
+		t.Errorf("应记录令牌和租户两个冲突: %+v", record.Conflicts)
+	}
+}
+
+func TestMergeUsersValidatesRequest(t *testing.T) {
+	stores, ps := newTestMergeEnv(t)
+	ctx := context.Background()
+
+	cases := []*MergeRequest{
+		nil,
+		{SourceMetaID: "a"},
+		{SourceMetaID: "a", TargetMetaID: "a"},
+		{SourceMetaID: "a", TargetMetaID: "b", Prefer: "newest"},
+	}
+	for _, request := range cases {
+		if _, err := MergeUsers(ctx, stores, ps, request); err == nil {
+			t.Errorf("MergeUsers(%+v) 应返回错误", request)
+		}
+	}
+	if records, _ := ps.ListUserMergeRecords("", 10); len(records) != 0 {
+		t.Errorf("无效请求不应写入审计记录: %+v", records)
+	}
+}
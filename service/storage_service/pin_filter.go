@@ -0,0 +1,132 @@
+package storage_service
+
+import (
+	"context"
+	"log"
+	"push-base-service/service/metrics_service"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// pinFilterLookups 布隆过滤器前置的已通知 PIN 查询次数
+// result: skipped（过滤器判定未通知，未查询存储）/ hit（存储确认已通知）/ false_positive（过滤器误判，存储中不存在）
+var pinFilterLookups = metrics_service.NewCounterVec(
+	"push_pin_filter_lookups_total", "Notified-pin lookups answered by the bloom filter front", "result")
+
+// NotifiedPinScanner 可遍历和清理的已通知 PIN 存储（Pebble 后端）
+type NotifiedPinScanner interface {
+	// ScanNotifiedPins 遍历所有已通知 PIN，fn 返回 false 时停止
+	ScanNotifiedPins(ctx context.Context, fn func(pinId string, notifiedAt int64) bool) error
+
+	// PurgeNotifiedPins 删除通知时间早于 before（Unix 秒）的记录，返回删除数量
+	PurgeNotifiedPins(ctx context.Context, before int64) (int, error)
+}
+
+// FilteredPinStore 在已通知 PIN 存储前加一层布隆过滤器，绝大多数“未通知”的查询无需读取存储
+// 过滤器首次构建完成前直接查询存储；重建期间新增的 PIN 同时写入新旧过滤器，不会漏判
+// 仅适用于单实例写入的存储（Pebble），多实例共享的存储中其他实例写入的 PIN 不会进入本地过滤器
+type FilteredPinStore struct {
+	store   NotifiedPinStore
+	scanner NotifiedPinScanner
+	config  PinFilterConfig
+
+	filter    atomic.Pointer[BloomFilter] // 当前过滤器，nil 表示尚未构建
+	rebuildMu sync.Mutex                  // 保证同一时间只有一个重建
+	pendingMu sync.Mutex
+	pending   *BloomFilter // 重建中的新过滤器
+	lastCount int64
+}
+
+// NewFilteredPinStore 创建带布隆过滤器前置的已通知 PIN 存储，需调用 Rebuild 构建过滤器后才生效
+func NewFilteredPinStore(store NotifiedPinStore, scanner NotifiedPinScanner, config PinFilterConfig) *FilteredPinStore {
+	return &FilteredPinStore{
+		store:   store,
+		scanner: scanner,
+		config:  config,
+	}
+}
+
+// IsNotifiedPin 检查 PIN 是否已通知，过滤器判定未通知时直接返回
+func (s *FilteredPinStore) IsNotifiedPin(ctx context.Context, pinId string) (bool, error) {
+	filter := s.filter.Load()
+	if filter != nil && !filter.MayContain(pinId) {
+		pinFilterLookups.Inc("skipped")
+		return false, nil
+	}
+
+	notified, err := s.store.IsNotifiedPin(ctx, pinId)
+	if err != nil || filter == nil {
+		return notified, err
+	}
+	if notified {
+		pinFilterLookups.Inc("hit")
+	} else {
+		pinFilterLookups.Inc("false_positive")
+	}
+	return notified, nil
+}
+
+// AddNotifiedPin 记录 PIN 已通知并加入过滤器
+func (s *FilteredPinStore) AddNotifiedPin(ctx context.Context, pinId string) error {
+	if err := s.store.AddNotifiedPin(ctx, pinId); err != nil {
+		return err
+	}
+
+	s.pendingMu.Lock()
+	defer s.pendingMu.Unlock()
+
+	if filter := s.filter.Load(); filter != nil {
+		filter.Add(pinId)
+	}
+	if s.pending != nil {
+		s.pending.Add(pinId)
+	}
+	return nil
+}
+
+// Rebuild 从存储重新构建过滤器，跳过通知时间早于 notBefore（Unix 秒，0 表示不限）的记录
+// 过滤器容量取配置的预期数量与上次记录数两倍中的较大者，记录数增长后误判率随下次重建恢复
+func (s *FilteredPinStore) Rebuild(ctx context.Context, notBefore int64) error {
+	s.rebuildMu.Lock()
+	defer s.rebuildMu.Unlock()
+
+	startTime := time.Now()
+	capacity := max(int64(s.config.ExpectedItems), s.lastCount*2)
+	filter := NewBloomFilter(int(capacity), s.config.FalsePositiveRate)
+
+	s.pendingMu.Lock()
+	s.pending = filter
+	s.pendingMu.Unlock()
+
+	var count int64
+	err := s.scanner.ScanNotifiedPins(ctx, func(pinId string, notifiedAt int64) bool {
+		if notifiedAt >= notBefore {
+			filter.Add(pinId)
+			count++
+		}
+		return ctx.Err() == nil
+	})
+	if err == nil {
+		err = ctx.Err()
+	}
+
+	s.pendingMu.Lock()
+	s.pending = nil
+	if err == nil {
+		s.filter.Store(filter)
+		s.lastCount = count
+	}
+	s.pendingMu.Unlock()
+
+	if err != nil {
+		return err
+	}
+	log.Printf("🧮 已重建已通知PIN布隆过滤器: 记录=%d, 容量=%d, 耗时=%v", count, capacity, time.Since(startTime))
+	return nil
+}
+
+// Ready 过滤器是否已构建
+func (s *FilteredPinStore) Ready() bool {
+	return s.filter.Load() != nil
+}
@@ -0,0 +1,136 @@
+package storage_service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memoryPinStore 记录查询次数的内存已通知 PIN 存储
+type memoryPinStore struct {
+	mu      sync.Mutex
+	pins    map[string]int64
+	lookups int
+}
+
+func newMemoryPinStore() *memoryPinStore {
+	return &memoryPinStore{pins: make(map[string]int64)}
+}
+
+func (s *memoryPinStore) IsNotifiedPin(ctx context.Context, pinId string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lookups++
+	_, exists := s.pins[pinId]
+	return exists, nil
+}
+
+func (s *memoryPinStore) AddNotifiedPin(ctx context.Context, pinId string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pins[pinId] = time.Now().Unix()
+	return nil
+}
+
+func (s *memoryPinStore) ScanNotifiedPins(ctx context.Context, fn func(pinId string, notifiedAt int64) bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for pinId, notifiedAt := range s.pins {
+		if !fn(pinId, notifiedAt) {
+			break
+		}
+	}
+	return nil
+}
+
+func (s *memoryPinStore) PurgeNotifiedPins(ctx context.Context, before int64) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	count := 0
+	for pinId, notifiedAt := range s.pins {
+		if notifiedAt < before {
+			delete(s.pins, pinId)
+			count++
+		}
+	}
+	return count, nil
+}
+
+func TestBloomFilterFalsePositiveRate(t *testing.T) {
+	filter := NewBloomFilter(10000, 0.01)
+	for i := 0; i < 10000; i++ {
+		filter.Add(fmt.Sprintf("pin-%d", i))
+	}
+	for i := 0; i < 10000; i++ {
+		if !filter.MayContain(fmt.Sprintf("pin-%d", i)) {
+			t.Fatalf("已加入的元素 pin-%d 被判定为不存在", i)
+		}
+	}
+
+	falsePositives := 0
+	for i := 0; i < 10000; i++ {
+		if filter.MayContain(fmt.Sprintf("other-%d", i)) {
+			falsePositives++
+		}
+	}
+	if falsePositives > 300 {
+		t.Errorf("误判数 = %d / 10000，远高于目标误判率 1%%", falsePositives)
+	}
+}
+
+func TestFilteredPinStoreSkipsStoreForUnseenPins(t *testing.T) {
+	store := newMemoryPinStore()
+	filtered := NewFilteredPinStore(store, store, DefaultConfig().PinFilter)
+	ctx := context.Background()
+
+	store.AddNotifiedPin(ctx, "old")
+
+	// 过滤器构建前直接查询存储
+	if notified, _ := filtered.IsNotifiedPin(ctx, "old"); !notified || store.lookups != 1 {
+		t.Fatalf("构建前应查询存储: notified=%v, lookups=%d", notified, store.lookups)
+	}
+
+	if err := filtered.Rebuild(ctx, 0); err != nil {
+		t.Fatalf("Rebuild() failed, err: %v", err)
+	}
+	store.lookups = 0
+	if notified, _ := filtered.IsNotifiedPin(ctx, "unseen"); notified || store.lookups != 0 {
+		t.Errorf("未通知的 PIN 不应查询存储: notified=%v, lookups=%d", notified, store.lookups)
+	}
+	if notified, _ := filtered.IsNotifiedPin(ctx, "old"); !notified || store.lookups != 1 {
+		t.Errorf("已通知的 PIN 应由存储确认: notified=%v, lookups=%d", notified, store.lookups)
+	}
+
+	filtered.AddNotifiedPin(ctx, "new")
+	if notified, _ := filtered.IsNotifiedPin(ctx, "new"); !notified {
+		t.Errorf("新记录的 PIN 应判定为已通知")
+	}
+}
+
+func TestMaintainNotifiedPinsPurgesOldPins(t *testing.T) {
+	store := newMemoryPinStore()
+	store.pins["expired"] = time.Now().Add(-48 * time.Hour).Unix()
+	store.pins["recent"] = time.Now().Unix()
+
+	filtered := NewFilteredPinStore(store, store, DefaultConfig().PinFilter)
+	stores := &Stores{Backend: BackendPebble, NotifiedPins: filtered, pinMaxAge: 24 * time.Hour}
+	ctx := context.Background()
+
+	if err := stores.MaintainNotifiedPins(ctx); err != nil {
+		t.Fatalf("MaintainNotifiedPins() failed, err: %v", err)
+	}
+	if _, exists := store.pins["expired"]; exists {
+		t.Errorf("超过保留时长的 PIN 应被清理")
+	}
+	if !filtered.Ready() {
+		t.Fatalf("维护后过滤器应已构建")
+	}
+	if notified, _ := filtered.IsNotifiedPin(ctx, "recent"); !notified {
+		t.Errorf("保留时长内的 PIN 应判定为已通知")
+	}
+	if notified, _ := filtered.IsNotifiedPin(ctx, "expired"); notified {
+		t.Errorf("已清理的 PIN 不应判定为已通知")
+	}
+}
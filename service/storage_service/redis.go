@@ -0,0 +1,1047 @@
+//go:build !noredis
+
+package storage_service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"push-base-service/models"
+	"push-base-service/service/pebble_service"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Redis 键布局（均带配置的键前缀）：
+//   user:{metaId}    HASH  token:{platform} -> 令牌, tenantId, updatedAt
+//   device:{token}   STRING 令牌当前所属用户（Token 即设备ID）
+//   users            ZSET  所有用户（分值均为 0，按 metaId 字典序分页）
+//   blocked:{userId} HASH  chatId -> 屏蔽记录 JSON
+//   sounds:{userId}  HASH  chatId -> 聊天通知声音 JSON
+//   senders:{userId} HASH  senderId -> 屏蔽发送者 JSON
+//   groups:{userId}  STRING 屏蔽所有群聊设置 JSON
+//   pin:{pinId}      STRING 已通知时间，带过期时间
+
+const (
+	tokenFieldPrefix = "token:"
+	fieldTenantID    = "tenantId"
+	fieldUpdatedAt   = "updatedAt"
+)
+
+// setUserTokenScript 原子地设置令牌，令牌属于其他用户时先从旧用户移除
+// KEYS: 用户键、设备键、用户索引；ARGV: metaId、平台、令牌、当前时间、键前缀
+// 返回 0=新注册, 1=从其他用户转移, 2=同一用户更新
+var setUserTokenScript = redis.NewScript(`
+local owner = redis.call('GET', KEYS[2])
+local field = 'token:' .. ARGV[2]
+local result = 0
+if owner then
+	result = 2
+	if owner ~= ARGV[1] then
+		result = 1
+		local oldKey = ARGV[5] .. 'user:' .. owner
+		if redis.call('HGET', oldKey, field) == ARGV[3] then
+			redis.call('HDEL', oldKey, field)
+			redis.call('HSET', oldKey, 'updatedAt', ARGV[4])
+		end
+	end
+end
+redis.call('HSET', KEYS[1], field, ARGV[3], 'updatedAt', ARGV[4])
+redis.call('SET', KEYS[2], ARGV[1])
+redis.call('ZADD', KEYS[3], 0, ARGV[1])
+return result
+`)
+
+// removeUserTokenScript 移除用户指定平台的令牌，并释放仍属于该用户的设备键
+// KEYS: 用户键；ARGV: 平台、当前时间、键前缀、metaId
+// 返回 1=已移除, 0=不存在
+var removeUserTokenScript = redis.NewScript(`
+local field = 'token:' .. ARGV[1]
+local token = redis.call('HGET', KEYS[1], field)
+if not token then
+	return 0
+end
+redis.call('HDEL', KEYS[1], field)
+redis.call('HSET', KEYS[1], 'updatedAt', ARGV[2])
+local deviceKey = ARGV[3] .. 'device:' .. token
+if redis.call('GET', deviceKey) == ARGV[4] then
+	redis.call('DEL', deviceKey)
+end
+return 1
+`)
+
+// deleteUserTokensScript 删除用户的所有令牌及其设备键，并从用户索引中移除
+// KEYS: 用户键、用户索引；ARGV: 键前缀、metaId
+var deleteUserTokensScript = redis.NewScript(`
+local fields = redis.call('HGETALL', KEYS[1])
+for i = 1, #fields, 2 do
+	if string.sub(fields[i], 1, 6) == 'token:' then
+		local deviceKey = ARGV[1] .. 'device:' .. fields[i + 1]
+		if redis.call('GET', deviceKey) == ARGV[2] then
+			redis.call('DEL', deviceKey)
+		end
+	end
+end
+redis.call('DEL', KEYS[1])
+redis.call('ZREM', KEYS[2], ARGV[2])
+return 1
+`)
+
+// RedisEnabled 当前构建是否包含 Redis 支持（使用 noredis 构建标签时为 false）
+const RedisEnabled = true
+
+// RedisTokenStore 基于 Redis 的用户令牌、屏蔽聊天、聊天通知声音和已通知 PIN 存储，多个实例可共享同一份数据
+type RedisTokenStore struct {
+	client    *redis.Client
+	keyPrefix string
+	pinTTL    time.Duration
+}
+
+// newRedisStore 创建 Redis 存储后端
+func newRedisStore(config *RedisConfig) (redisStore, error) {
+	store, err := NewRedisTokenStore(config)
+	if err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// NewRedisTokenStore 创建 Redis 存储并检查连接
+func NewRedisTokenStore(config *RedisConfig) (*RedisTokenStore, error) {
+	client := redis.NewClient(&redis.Options{
+		Addr:     config.Addr,
+		Password: config.Password,
+		DB:       config.DB,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("连接 Redis 失败: %w", err)
+	}
+
+	return &RedisTokenStore{
+		client:    client,
+		keyPrefix: config.KeyPrefix,
+		pinTTL:    config.PinTTL,
+	}, nil
+}
+
+// Close 关闭 Redis 连接
+func (s *RedisTokenStore) Close() error {
+	return s.client.Close()
+}
+
+func (s *RedisTokenStore) userKey(metaId string) string {
+	return s.keyPrefix + "user:" + metaId
+}
+
+func (s *RedisTokenStore) deviceKey(token string) string {
+	return s.keyPrefix + "device:" + token
+}
+
+func (s *RedisTokenStore) usersKey() string {
+	return s.keyPrefix + "users"
+}
+
+func (s *RedisTokenStore) blockedKey(userId string) string {
+	return s.keyPrefix + "blocked:" + userId
+}
+
+func (s *RedisTokenStore) soundsKey(userId string) string {
+	return s.keyPrefix + "sounds:" + userId
+}
+
+func (s *RedisTokenStore) blockedSendersKey(userId string) string {
+	return s.keyPrefix + "senders:" + userId
+}
+
+func (s *RedisTokenStore) allGroupsBlockKey(userId string) string {
+	return s.keyPrefix + "groups:" + userId
+}
+
+func (s *RedisTokenStore) pinKey(pinId string) string {
+	return s.keyPrefix + "pin:" + pinId
+}
+
+// parseUserTokens 将用户 HASH 解析为令牌信息，用户不存在时返回空令牌
+func parseUserTokens(metaId string, fields map[string]string) *models.UserPushTokens {
+	userTokens := &models.UserPushTokens{
+		MetaID: metaId,
+		Tokens: make(map[string]string),
+	}
+	if len(fields) == 0 {
+		userTokens.UpdatedAt = time.Now().Unix()
+		return userTokens
+	}
+
+	for field, value := range fields {
+		switch {
+		case strings.HasPrefix(field, tokenFieldPrefix):
+			userTokens.Tokens[strings.TrimPrefix(field, tokenFieldPrefix)] = value
+		case field == fieldTenantID:
+			userTokens.TenantID = value
+		case field == fieldUpdatedAt:
+			userTokens.UpdatedAt, _ = strconv.ParseInt(value, 10, 64)
+		}
+	}
+	return userTokens
+}
+
+// GetUserTokens 根据metaId获取用户的所有推送令牌 (实现 UserTokenStore 接口)
+func (s *RedisTokenStore) GetUserTokens(ctx context.Context, metaId string) (*models.UserPushTokens, error) {
+	if metaId == "" {
+		return nil, fmt.Errorf("MetaID 不能为空")
+	}
+
+	fields, err := s.client.HGetAll(ctx, s.userKey(metaId)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("获取用户令牌失败: %w", err)
+	}
+	return parseUserTokens(metaId, fields), nil
+}
+
+// SetUserToken 设置用户在指定平台的推送令牌 (实现 UserTokenStore 接口)
+// Token 作为设备ID，已属于其他用户时原子地从旧用户移除
+func (s *RedisTokenStore) SetUserToken(ctx context.Context, metaId string, platform string, token string) error {
+	if metaId == "" || platform == "" || token == "" {
+		return fmt.Errorf("MetaID、平台和令牌都不能为空")
+	}
+
+	result, err := setUserTokenScript.Run(ctx, s.client,
+		[]string{s.userKey(metaId), s.deviceKey(token), s.usersKey()},
+		metaId, platform, token, time.Now().Unix(), s.keyPrefix).Int()
+	if err != nil {
+		return fmt.Errorf("保存用户令牌失败: %w", err)
+	}
+
+	if result == 1 {
+		log.Printf("⚠️ Token %s 已转移到用户 %s", token, metaId)
+	}
+	log.Printf("✅ 已设置用户令牌: MetaID=%s, 平台=%s, Token(DeviceID)=%s", metaId, platform, token)
+	return nil
+}
+
+// RemoveUserToken 移除用户在指定平台的推送令牌 (实现 UserTokenStore 接口)
+func (s *RedisTokenStore) RemoveUserToken(ctx context.Context, metaId string, platform string) error {
+	if metaId == "" || platform == "" {
+		return fmt.Errorf("MetaID 和平台不能为空")
+	}
+
+	removed, err := removeUserTokenScript.Run(ctx, s.client, []string{s.userKey(metaId)},
+		platform, time.Now().Unix(), s.keyPrefix, metaId).Int()
+	if err != nil {
+		return fmt.Errorf("移除用户令牌失败: %w", err)
+	}
+
+	if removed == 0 {
+		log.Printf("⚠️ 用户 %s 在平台 %s 上没有令牌", metaId, platform)
+		return nil
+	}
+	log.Printf("✅ 已移除用户令牌: MetaID=%s, 平台=%s", metaId, platform)
+	return nil
+}
+
+// GetAllUserTokens 获取多个用户的令牌 (实现 UserTokenStore 接口)
+func (s *RedisTokenStore) GetAllUserTokens(ctx context.Context, metaIds []string) (map[string]*models.UserPushTokens, error) {
+	users, err := s.getUserTokensBatch(ctx, metaIds)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]*models.UserPushTokens, len(users))
+	for _, userTokens := range users {
+		result[userTokens.MetaID] = userTokens
+	}
+	return result, nil
+}
+
+// getUserTokensBatch 通过管道批量读取用户令牌，结果顺序与 metaIds 一致
+func (s *RedisTokenStore) getUserTokensBatch(ctx context.Context, metaIds []string) ([]*models.UserPushTokens, error) {
+	if len(metaIds) == 0 {
+		return nil, nil
+	}
+
+	pipe := s.client.Pipeline()
+	cmds := make([]*redis.MapStringStringCmd, len(metaIds))
+	for i, metaId := range metaIds {
+		cmds[i] = pipe.HGetAll(ctx, s.userKey(metaId))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, fmt.Errorf("批量获取用户令牌失败: %w", err)
+	}
+
+	users := make([]*models.UserPushTokens, len(metaIds))
+	for i, metaId := range metaIds {
+		users[i] = parseUserTokens(metaId, cmds[i].Val())
+	}
+	return users, nil
+}
+
+// SetUserTenant 设置用户所属租户
+func (s *RedisTokenStore) SetUserTenant(ctx context.Context, metaId, tenantId string) error {
+	if metaId == "" {
+		return fmt.Errorf("MetaID 不能为空")
+	}
+
+	pipe := s.client.TxPipeline()
+	pipe.HSet(ctx, s.userKey(metaId), fieldTenantID, tenantId, fieldUpdatedAt, time.Now().Unix())
+	pipe.ZAdd(ctx, s.usersKey(), redis.Z{Member: metaId})
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("保存用户租户信息失败: %w", err)
+	}
+
+	log.Printf("✅ 已设置用户租户: MetaID=%s, TenantID=%s", metaId, tenantId)
+	return nil
+}
+
+// DeleteUserTokens 删除用户的所有推送令牌
+func (s *RedisTokenStore) DeleteUserTokens(ctx context.Context, metaId string) error {
+	if metaId == "" {
+		return fmt.Errorf("MetaID 不能为空")
+	}
+
+	if err := deleteUserTokensScript.Run(ctx, s.client, []string{s.userKey(metaId), s.usersKey()},
+		s.keyPrefix, metaId).Err(); err != nil {
+		return fmt.Errorf("删除用户令牌失败: %w", err)
+	}
+
+	log.Printf("✅ 已删除用户所有令牌: MetaID=%s", metaId)
+	return nil
+}
+
+// ListUserTokens 分页获取用户令牌列表，按 metaId 排序
+func (s *RedisTokenStore) ListUserTokens(ctx context.Context, page, pageSize int) (*pebble_service.PaginatedUserTokens, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 10
+	}
+	if pageSize > 100 {
+		pageSize = 100 // 限制最大页面大小
+	}
+
+	total, err := s.client.ZCard(ctx, s.usersKey()).Result()
+	if err != nil {
+		return nil, fmt.Errorf("获取用户总数失败: %w", err)
+	}
+
+	start := int64((page - 1) * pageSize)
+	metaIds, err := s.client.ZRange(ctx, s.usersKey(), start, start+int64(pageSize)-1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("获取用户列表失败: %w", err)
+	}
+
+	users, err := s.getUserTokensBatch(ctx, metaIds)
+	if err != nil {
+		return nil, err
+	}
+	if users == nil {
+		users = []*models.UserPushTokens{}
+	}
+
+	totalPages := (int(total) + pageSize - 1) / pageSize
+	result := &pebble_service.PaginatedUserTokens{
+		Users:      users,
+		Total:      int(total),
+		Page:       page,
+		PageSize:   pageSize,
+		TotalPages: totalPages,
+		HasNext:    page < totalPages,
+	}
+	if result.HasNext && len(metaIds) > 0 {
+		result.NextCursor = pebble_service.EncodeTokensCursor(metaIds[len(metaIds)-1])
+	}
+	return result, nil
+}
+
+// ListUserTokensAfter 按游标分页获取用户令牌列表：用户集合的分数均为 0，按 metaId 字典序范围读取
+// metaId 前缀通过字典序范围限定；Redis 存储没有平台和更新时间的二级索引，这两个条件在读取范围内的用户时逐个判断
+func (s *RedisTokenStore) ListUserTokensAfter(ctx context.Context, filter pebble_service.UserTokensFilter, cursor string, pageSize int) (*pebble_service.PaginatedUserTokens, error) {
+	after, err := pebble_service.DecodeTokensCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+	if pageSize < 1 {
+		pageSize = 10
+	}
+	if pageSize > 100 {
+		pageSize = 100 // 限制最大页面大小
+	}
+	if filter.UpdatedBefore > 0 && filter.UpdatedBefore <= filter.UpdatedAfter {
+		return nil, fmt.Errorf("updatedBefore 必须晚于 updatedAfter")
+	}
+
+	lower, upper := "-", "+"
+	if filter.MetaIDPrefix != "" {
+		lower = "[" + filter.MetaIDPrefix
+		if bound := prefixUpperBound(filter.MetaIDPrefix); bound != "" {
+			upper = "(" + bound
+		}
+	}
+	if after != "" && after >= filter.MetaIDPrefix {
+		lower = "(" + after
+	}
+
+	result := &pebble_service.PaginatedUserTokens{Users: []*models.UserPushTokens{}, PageSize: pageSize}
+	// 多取一个判断是否有下一页；有筛选条件时按批读取直到凑满一页或范围读完
+	batchSize := int64(pageSize) + 1
+	lastKey := ""
+	for {
+		metaIds, err := s.client.ZRangeByLex(ctx, s.usersKey(), &redis.ZRangeBy{Min: lower, Max: upper, Count: batchSize}).Result()
+		if err != nil {
+			return nil, fmt.Errorf("获取用户列表失败: %w", err)
+		}
+		users, err := s.getUserTokensBatch(ctx, metaIds)
+		if err != nil {
+			return nil, err
+		}
+		for _, userTokens := range users {
+			if !filter.Matches(userTokens) {
+				continue
+			}
+			if len(result.Users) == pageSize {
+				result.HasNext = true
+				result.NextCursor = pebble_service.EncodeTokensCursor(lastKey)
+				return result, nil
+			}
+			result.Users = append(result.Users, userTokens)
+			lastKey = userTokens.MetaID
+		}
+		if int64(len(metaIds)) < batchSize {
+			return result, nil
+		}
+		lower = "(" + metaIds[len(metaIds)-1]
+	}
+}
+
+// prefixUpperBound 返回大于所有以 prefix 开头的字符串的最小字符串，prefix 全为 0xff 时返回空字符串（无上界）
+func prefixUpperBound(prefix string) string {
+	upper := []byte(prefix)
+	for i := len(upper) - 1; i >= 0; i-- {
+		upper[i]++
+		if upper[i] != 0 {
+			return string(upper[:i+1])
+		}
+	}
+	return ""
+}
+
+// ===== 屏蔽聊天 =====
+
+// AddBlockedChat 添加屏蔽聊天，已屏蔽时更新静音截止时间
+func (s *RedisTokenStore) AddBlockedChat(ctx context.Context, userId, chatId, chatType, reason string, muteUntil int64) error {
+	if userId == "" || chatId == "" {
+		return fmt.Errorf("UserID 和 ChatID 不能为空")
+	}
+	if muteUntil < 0 {
+		return fmt.Errorf("静音截止时间不能为负数")
+	}
+
+	key := s.blockedKey(userId)
+	now := time.Now().Unix()
+
+	// 乐观锁保证"读取-修改-写入"的原子性，并发修改时重试
+	update := func(tx *redis.Tx) error {
+		blockedChat := models.BlockedChat{
+			UserID:    userId,
+			ChatID:    chatId,
+			ChatType:  chatType,
+			BlockedAt: now,
+			Reason:    reason,
+		}
+
+		raw, err := tx.HGet(ctx, key, chatId).Bytes()
+		if err != nil && !errors.Is(err, redis.Nil) {
+			return err
+		}
+		if err == nil {
+			var existing models.BlockedChat
+			if json.Unmarshal(raw, &existing) == nil && !existing.IsExpired(now) {
+				blockedChat = existing
+				if reason != "" {
+					blockedChat.Reason = reason
+				}
+			}
+		}
+		blockedChat.MuteUntil = muteUntil
+
+		data, err := json.Marshal(blockedChat)
+		if err != nil {
+			return fmt.Errorf("序列化屏蔽记录失败: %w", err)
+		}
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.HSet(ctx, key, chatId, data)
+			return nil
+		})
+		return err
+	}
+
+	for attempt := 0; attempt < 3; attempt++ {
+		err := s.client.Watch(ctx, update, key)
+		if errors.Is(err, redis.TxFailedErr) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("保存屏蔽聊天失败: %w", err)
+		}
+		log.Printf("✅ 已添加屏蔽聊天: UserID=%s, ChatID=%s, ChatType=%s, MuteUntil=%d", userId, chatId, chatType, muteUntil)
+		return nil
+	}
+	return fmt.Errorf("保存屏蔽聊天失败: 并发修改冲突")
+}
+
+// RemoveBlockedChat 移除屏蔽聊天
+func (s *RedisTokenStore) RemoveBlockedChat(ctx context.Context, userId, chatId string) error {
+	if userId == "" || chatId == "" {
+		return fmt.Errorf("UserID 和 ChatID 不能为空")
+	}
+
+	removed, err := s.client.HDel(ctx, s.blockedKey(userId), chatId).Result()
+	if err != nil {
+		return fmt.Errorf("移除屏蔽聊天失败: %w", err)
+	}
+
+	if removed == 0 {
+		log.Printf("⚠️ 用户 %s 没有屏蔽聊天 %s", userId, chatId)
+		return nil
+	}
+	log.Printf("✅ 已移除屏蔽聊天: UserID=%s, ChatID=%s", userId, chatId)
+	return nil
+}
+
+// IsBlockedChat 检查聊天是否被屏蔽，已过期的临时静音视为未屏蔽并顺带清理
+func (s *RedisTokenStore) IsBlockedChat(ctx context.Context, userId, chatId string) (bool, error) {
+	if userId == "" || chatId == "" {
+		return false, fmt.Errorf("UserID 和 ChatID 不能为空")
+	}
+
+	raw, err := s.client.HGet(ctx, s.blockedKey(userId), chatId).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("获取屏蔽记录失败: %w", err)
+	}
+
+	var blockedChat models.BlockedChat
+	if err := json.Unmarshal(raw, &blockedChat); err != nil {
+		return false, fmt.Errorf("反序列化屏蔽记录失败: %w", err)
+	}
+
+	if blockedChat.IsExpired(time.Now().Unix()) {
+		s.cleanupExpiredBlockedChats(ctx, userId, []string{chatId})
+		return false, nil
+	}
+	return true, nil
+}
+
+// GetUserBlockedChats 获取用户的所有屏蔽聊天（不含已过期的临时静音），按屏蔽时间排序
+func (s *RedisTokenStore) GetUserBlockedChats(ctx context.Context, userId string) (*models.UserBlockedChats, error) {
+	if userId == "" {
+		return nil, fmt.Errorf("UserID 不能为空")
+	}
+
+	fields, err := s.client.HGetAll(ctx, s.blockedKey(userId)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("获取用户屏蔽列表失败: %w", err)
+	}
+
+	now := time.Now().Unix()
+	userBlockedChats := &models.UserBlockedChats{
+		UserID:       userId,
+		BlockedChats: []models.BlockedChat{},
+	}
+	var expired []string
+	for chatId, raw := range fields {
+		var blockedChat models.BlockedChat
+		if err := json.Unmarshal([]byte(raw), &blockedChat); err != nil {
+			log.Printf("⚠️ 跳过解析失败的屏蔽记录: UserID=%s, ChatID=%s, 错误: %v", userId, chatId, err)
+			continue
+		}
+		if blockedChat.IsExpired(now) {
+			expired = append(expired, chatId)
+			continue
+		}
+		userBlockedChats.BlockedChats = append(userBlockedChats.BlockedChats, blockedChat)
+		if blockedChat.BlockedAt > userBlockedChats.UpdatedAt {
+			userBlockedChats.UpdatedAt = blockedChat.BlockedAt
+		}
+	}
+
+	sort.Slice(userBlockedChats.BlockedChats, func(i, j int) bool {
+		a, b := userBlockedChats.BlockedChats[i], userBlockedChats.BlockedChats[j]
+		if a.BlockedAt != b.BlockedAt {
+			return a.BlockedAt < b.BlockedAt
+		}
+		return a.ChatID < b.ChatID
+	})
+
+	if len(expired) > 0 {
+		s.cleanupExpiredBlockedChats(ctx, userId, expired)
+	}
+	return userBlockedChats, nil
+}
+
+// ListBlockedChats 按聊天ID顺序分页获取用户的屏蔽聊天（不含已过期的临时静音）
+// 用户的屏蔽记录在一个 HASH 中，读取全部字段后排序分页
+func (s *RedisTokenStore) ListBlockedChats(ctx context.Context, userId, cursor string, pageSize int) (*models.BlockedChatsPage, error) {
+	after, err := pebble_service.DecodeTokensCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+	userBlockedChats, err := s.GetUserBlockedChats(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	blockedChats := userBlockedChats.BlockedChats
+	sort.Slice(blockedChats, func(i, j int) bool {
+		return blockedChats[i].ChatID < blockedChats[j].ChatID
+	})
+	start := sort.Search(len(blockedChats), func(i int) bool {
+		return blockedChats[i].ChatID > after
+	})
+
+	pageSize = pebble_service.NormalizeBlockedChatsPageSize(pageSize)
+	page := &models.BlockedChatsPage{
+		UserID:       userId,
+		BlockedChats: blockedChats[start:],
+		PageSize:     pageSize,
+	}
+	if len(page.BlockedChats) > pageSize {
+		page.BlockedChats = page.BlockedChats[:pageSize]
+		page.HasNext = true
+		page.NextCursor = pebble_service.EncodeTokensCursor(page.BlockedChats[pageSize-1].ChatID)
+	}
+	return page, nil
+}
+
+// BatchUpdateBlockedChats 批量屏蔽和取消屏蔽聊天，在一个事务中写入，并发修改时重试
+func (s *RedisTokenStore) BatchUpdateBlockedChats(ctx context.Context, userId string, block []models.BlockedChat, unblock []string) (*models.BlockedChatsBatchResult, error) {
+	if userId == "" {
+		return nil, fmt.Errorf("UserID 不能为空")
+	}
+	if err := pebble_service.ValidateBlockedChatsBatch(block, unblock); err != nil {
+		return nil, err
+	}
+
+	unblocked := make(map[string]bool, len(unblock))
+	for _, chatId := range unblock {
+		unblocked[chatId] = true
+	}
+	var chatIds []string
+	for _, request := range block {
+		if !unblocked[request.ChatID] {
+			chatIds = append(chatIds, request.ChatID)
+		}
+	}
+
+	key := s.blockedKey(userId)
+	var result *models.BlockedChatsBatchResult
+	update := func(tx *redis.Tx) error {
+		existing := make(map[string]models.BlockedChat)
+		if len(chatIds) > 0 {
+			values, err := tx.HMGet(ctx, key, chatIds...).Result()
+			if err != nil {
+				return err
+			}
+			for i, value := range values {
+				var blockedChat models.BlockedChat
+				if raw, ok := value.(string); ok && json.Unmarshal([]byte(raw), &blockedChat) == nil {
+					existing[chatIds[i]] = blockedChat
+				}
+			}
+		}
+
+		now := time.Now().Unix()
+		result = &models.BlockedChatsBatchResult{}
+		fields := make(map[string]interface{})
+		for _, request := range block {
+			if unblocked[request.ChatID] {
+				continue
+			}
+			blockedChat := models.BlockedChat{
+				UserID:    userId,
+				ChatID:    request.ChatID,
+				ChatType:  request.ChatType,
+				BlockedAt: now,
+				Reason:    request.Reason,
+			}
+			if current, ok := existing[request.ChatID]; ok && !current.IsExpired(now) {
+				blockedChat = current
+				if request.Reason != "" {
+					blockedChat.Reason = request.Reason
+				}
+			}
+			blockedChat.MuteUntil = request.MuteUntil
+
+			data, err := json.Marshal(blockedChat)
+			if err != nil {
+				return fmt.Errorf("序列化屏蔽记录失败: %w", err)
+			}
+			fields[request.ChatID] = data
+			result.Blocked++
+		}
+		result.Unblocked = len(unblocked)
+
+		_, err := tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			if len(fields) > 0 {
+				pipe.HSet(ctx, key, fields)
+			}
+			if len(unblocked) > 0 {
+				removed := make([]string, 0, len(unblocked))
+				for chatId := range unblocked {
+					removed = append(removed, chatId)
+				}
+				pipe.HDel(ctx, key, removed...)
+			}
+			return nil
+		})
+		return err
+	}
+
+	for attempt := 0; attempt < 3; attempt++ {
+		err := s.client.Watch(ctx, update, key)
+		if errors.Is(err, redis.TxFailedErr) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("批量更新屏蔽聊天失败: %w", err)
+		}
+		log.Printf("✅ 已批量更新屏蔽聊天: UserID=%s, 屏蔽=%d, 取消屏蔽=%d", userId, result.Blocked, result.Unblocked)
+		return result, nil
+	}
+	return nil, fmt.Errorf("批量更新屏蔽聊天失败: 并发修改冲突")
+}
+
+// cleanupExpiredBlockedChats 清理已过期的临时静音，删除前重新检查，避免覆盖并发写入的新记录；失败只记录日志
+func (s *RedisTokenStore) cleanupExpiredBlockedChats(ctx context.Context, userId string, chatIds []string) {
+	key := s.blockedKey(userId)
+	now := time.Now().Unix()
+
+	err := s.client.Watch(ctx, func(tx *redis.Tx) error {
+		values, err := tx.HMGet(ctx, key, chatIds...).Result()
+		if err != nil {
+			return err
+		}
+
+		var remove []string
+		for i, value := range values {
+			raw, ok := value.(string)
+			if !ok {
+				continue
+			}
+			var blockedChat models.BlockedChat
+			if json.Unmarshal([]byte(raw), &blockedChat) == nil && blockedChat.IsExpired(now) {
+				remove = append(remove, chatIds[i])
+			}
+		}
+		if len(remove) == 0 {
+			return nil
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.HDel(ctx, key, remove...)
+			return nil
+		})
+		return err
+	}, key)
+	if err != nil {
+		log.Printf("⚠️ 清理过期静音失败: UserID=%s, 错误=%v", userId, err)
+	}
+}
+
+// ===== 聊天通知声音 =====
+
+// SetChatSound 设置用户某个聊天的通知声音，sound 为空时恢复默认声音
+func (s *RedisTokenStore) SetChatSound(ctx context.Context, userId, chatId, chatType, sound string) error {
+	if userId == "" || chatId == "" {
+		return fmt.Errorf("UserID 和 ChatID 不能为空")
+	}
+
+	key := s.soundsKey(userId)
+	if sound == "" {
+		if err := s.client.HDel(ctx, key, chatId).Err(); err != nil {
+			return fmt.Errorf("恢复聊天默认通知声音失败: %w", err)
+		}
+		log.Printf("✅ 已恢复聊天默认通知声音: UserID=%s, ChatID=%s", userId, chatId)
+		return nil
+	}
+
+	data, err := json.Marshal(models.ChatSound{
+		UserID:    userId,
+		ChatID:    chatId,
+		ChatType:  chatType,
+		Sound:     sound,
+		UpdatedAt: time.Now().Unix(),
+	})
+	if err != nil {
+		return fmt.Errorf("序列化聊天通知声音失败: %w", err)
+	}
+	if err := s.client.HSet(ctx, key, chatId, data).Err(); err != nil {
+		return fmt.Errorf("保存聊天通知声音失败: %w", err)
+	}
+
+	log.Printf("✅ 已设置聊天通知声音: UserID=%s, ChatID=%s, Sound=%s", userId, chatId, sound)
+	return nil
+}
+
+// GetChatSounds 批量获取用户在某个聊天的通知声音，只返回设置了声音的用户
+func (s *RedisTokenStore) GetChatSounds(ctx context.Context, userIds []string, chatId string) (map[string]string, error) {
+	result := make(map[string]string)
+	if len(userIds) == 0 || chatId == "" {
+		return result, nil
+	}
+
+	cmds := make([]*redis.StringCmd, len(userIds))
+	_, err := s.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, userId := range userIds {
+			cmds[i] = pipe.HGet(ctx, s.soundsKey(userId), chatId)
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, fmt.Errorf("批量获取聊天通知声音失败: %w", err)
+	}
+
+	for i, cmd := range cmds {
+		raw, err := cmd.Bytes()
+		if err != nil {
+			continue
+		}
+		var chatSound models.ChatSound
+		if err := json.Unmarshal(raw, &chatSound); err != nil {
+			log.Printf("⚠️ 跳过解析失败的聊天通知声音: UserID=%s, ChatID=%s, 错误: %v", userIds[i], chatId, err)
+			continue
+		}
+		if chatSound.Sound != "" {
+			result[userIds[i]] = chatSound.Sound
+		}
+	}
+	return result, nil
+}
+
+// GetUserChatSounds 获取用户设置的所有聊天通知声音，按设置时间排序
+func (s *RedisTokenStore) GetUserChatSounds(ctx context.Context, userId string) (*models.UserChatSounds, error) {
+	if userId == "" {
+		return nil, fmt.Errorf("UserID 不能为空")
+	}
+
+	fields, err := s.client.HGetAll(ctx, s.soundsKey(userId)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("获取用户聊天通知声音失败: %w", err)
+	}
+
+	userChatSounds := &models.UserChatSounds{
+		UserID:     userId,
+		ChatSounds: []models.ChatSound{},
+	}
+	for chatId, raw := range fields {
+		var chatSound models.ChatSound
+		if err := json.Unmarshal([]byte(raw), &chatSound); err != nil {
+			log.Printf("⚠️ 跳过解析失败的聊天通知声音: UserID=%s, ChatID=%s, 错误: %v", userId, chatId, err)
+			continue
+		}
+		userChatSounds.ChatSounds = append(userChatSounds.ChatSounds, chatSound)
+	}
+
+	sort.Slice(userChatSounds.ChatSounds, func(i, j int) bool {
+		a, b := userChatSounds.ChatSounds[i], userChatSounds.ChatSounds[j]
+		if a.UpdatedAt != b.UpdatedAt {
+			return a.UpdatedAt < b.UpdatedAt
+		}
+		return a.ChatID < b.ChatID
+	})
+	return userChatSounds, nil
+}
+
+// ===== 屏蔽发送者和屏蔽所有群聊 =====
+
+// AddBlockedSender 屏蔽发送者，已屏蔽时更新屏蔽原因
+func (s *RedisTokenStore) AddBlockedSender(ctx context.Context, userId, senderId, reason string) error {
+	if userId == "" || senderId == "" {
+		return fmt.Errorf("UserID 和 SenderID 不能为空")
+	}
+
+	key := s.blockedSendersKey(userId)
+	blockedSender := models.BlockedSender{
+		UserID:    userId,
+		SenderID:  senderId,
+		BlockedAt: time.Now().Unix(),
+	}
+	raw, err := s.client.HGet(ctx, key, senderId).Bytes()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return fmt.Errorf("获取屏蔽发送者失败: %w", err)
+	}
+	if err == nil {
+		var existing models.BlockedSender
+		if json.Unmarshal(raw, &existing) == nil && existing.BlockedAt > 0 {
+			blockedSender.BlockedAt = existing.BlockedAt
+		}
+	}
+	blockedSender.Reason = reason
+
+	data, err := json.Marshal(blockedSender)
+	if err != nil {
+		return fmt.Errorf("序列化屏蔽发送者失败: %w", err)
+	}
+	if err := s.client.HSet(ctx, key, senderId, data).Err(); err != nil {
+		return fmt.Errorf("保存屏蔽发送者失败: %w", err)
+	}
+
+	log.Printf("✅ 已屏蔽发送者: UserID=%s, SenderID=%s", userId, senderId)
+	return nil
+}
+
+// RemoveBlockedSender 取消屏蔽发送者
+func (s *RedisTokenStore) RemoveBlockedSender(ctx context.Context, userId, senderId string) error {
+	if userId == "" || senderId == "" {
+		return fmt.Errorf("UserID 和 SenderID 不能为空")
+	}
+
+	if err := s.client.HDel(ctx, s.blockedSendersKey(userId), senderId).Err(); err != nil {
+		return fmt.Errorf("取消屏蔽发送者失败: %w", err)
+	}
+
+	log.Printf("✅ 已取消屏蔽发送者: UserID=%s, SenderID=%s", userId, senderId)
+	return nil
+}
+
+// IsBlockedSender 检查用户是否屏蔽了该发送者
+func (s *RedisTokenStore) IsBlockedSender(ctx context.Context, userId, senderId string) (bool, error) {
+	if userId == "" || senderId == "" {
+		return false, nil
+	}
+
+	exists, err := s.client.HExists(ctx, s.blockedSendersKey(userId), senderId).Result()
+	if err != nil {
+		return false, fmt.Errorf("检查屏蔽发送者失败: %w", err)
+	}
+	return exists, nil
+}
+
+// GetUserBlockedSenders 获取用户屏蔽的所有发送者，按屏蔽时间排序
+func (s *RedisTokenStore) GetUserBlockedSenders(ctx context.Context, userId string) (*models.UserBlockedSenders, error) {
+	if userId == "" {
+		return nil, fmt.Errorf("UserID 不能为空")
+	}
+
+	fields, err := s.client.HGetAll(ctx, s.blockedSendersKey(userId)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("获取用户屏蔽发送者失败: %w", err)
+	}
+
+	userBlockedSenders := &models.UserBlockedSenders{
+		UserID:         userId,
+		BlockedSenders: []models.BlockedSender{},
+	}
+	for senderId, raw := range fields {
+		var blockedSender models.BlockedSender
+		if err := json.Unmarshal([]byte(raw), &blockedSender); err != nil {
+			log.Printf("⚠️ 跳过解析失败的屏蔽发送者: UserID=%s, SenderID=%s, 错误: %v", userId, senderId, err)
+			continue
+		}
+		userBlockedSenders.BlockedSenders = append(userBlockedSenders.BlockedSenders, blockedSender)
+	}
+
+	sort.Slice(userBlockedSenders.BlockedSenders, func(i, j int) bool {
+		a, b := userBlockedSenders.BlockedSenders[i], userBlockedSenders.BlockedSenders[j]
+		if a.BlockedAt != b.BlockedAt {
+			return a.BlockedAt < b.BlockedAt
+		}
+		return a.SenderID < b.SenderID
+	})
+	return userBlockedSenders, nil
+}
+
+// SetAllGroupsBlocked 开启或关闭屏蔽所有群聊，重复开启时保留最初的开启时间
+func (s *RedisTokenStore) SetAllGroupsBlocked(ctx context.Context, userId string, blocked bool) error {
+	if userId == "" {
+		return fmt.Errorf("UserID 不能为空")
+	}
+
+	key := s.allGroupsBlockKey(userId)
+	if !blocked {
+		if err := s.client.Del(ctx, key).Err(); err != nil {
+			return fmt.Errorf("关闭屏蔽所有群聊失败: %w", err)
+		}
+		log.Printf("✅ 已关闭屏蔽所有群聊: UserID=%s", userId)
+		return nil
+	}
+
+	data, err := json.Marshal(models.AllGroupsBlock{
+		UserID:    userId,
+		Blocked:   true,
+		BlockedAt: time.Now().Unix(),
+	})
+	if err != nil {
+		return fmt.Errorf("序列化屏蔽所有群聊设置失败: %w", err)
+	}
+	created, err := s.client.SetNX(ctx, key, data, 0).Result()
+	if err != nil {
+		return fmt.Errorf("开启屏蔽所有群聊失败: %w", err)
+	}
+	if created {
+		log.Printf("✅ 已开启屏蔽所有群聊: UserID=%s", userId)
+	}
+	return nil
+}
+
+// GetAllGroupsBlock 获取用户的屏蔽所有群聊设置，未开启时 Blocked 为 false
+func (s *RedisTokenStore) GetAllGroupsBlock(ctx context.Context, userId string) (*models.AllGroupsBlock, error) {
+	if userId == "" {
+		return nil, fmt.Errorf("UserID 不能为空")
+	}
+
+	raw, err := s.client.Get(ctx, s.allGroupsBlockKey(userId)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return &models.AllGroupsBlock{UserID: userId}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("获取屏蔽所有群聊设置失败: %w", err)
+	}
+
+	var allGroupsBlock models.AllGroupsBlock
+	if err := json.Unmarshal(raw, &allGroupsBlock); err != nil {
+		return nil, fmt.Errorf("解析屏蔽所有群聊设置失败: %w", err)
+	}
+	return &allGroupsBlock, nil
+}
+
+// ===== 已通知 PIN =====
+
+// AddNotifiedPin 记录 PIN 已通知，记录在配置的保留时长后过期
+func (s *RedisTokenStore) AddNotifiedPin(ctx context.Context, pinId string) error {
+	if pinId == "" {
+		return fmt.Errorf("PinID 不能为空")
+	}
+
+	if err := s.client.Set(ctx, s.pinKey(pinId), time.Now().Unix(), s.pinTTL).Err(); err != nil {
+		return fmt.Errorf("保存已通知PIN信息失败: %w", err)
+	}
+
+	log.Printf("✅ 已添加已通知PIN: PinID=%s", pinId)
+	return nil
+}
+
+// IsNotifiedPin 检查 PIN 是否已通知
+func (s *RedisTokenStore) IsNotifiedPin(ctx context.Context, pinId string) (bool, error) {
+	if pinId == "" {
+		return false, fmt.Errorf("PinID 不能为空")
+	}
+
+	count, err := s.client.Exists(ctx, s.pinKey(pinId)).Result()
+	if err != nil {
+		return false, fmt.Errorf("检查PIN通知状态失败: %w", err)
+	}
+	return count > 0, nil
+}
@@ -0,0 +1,13 @@
+//go:build noredis
+
+package storage_service
+
+import "fmt"
+
+// RedisEnabled 当前构建是否包含 Redis 支持（使用 noredis 构建标签时为 false）
+const RedisEnabled = false
+
+// newRedisStore 当前构建未包含 Redis 支持
+func newRedisStore(config *RedisConfig) (redisStore, error) {
+	return nil, fmt.Errorf("当前构建未包含 Redis 支持（noredis），无法使用 redis 存储后端")
+}
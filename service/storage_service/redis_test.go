@@ -0,0 +1,285 @@
+//go:build !noredis
+
+package storage_service
+
+import (
+	"context"
+	"push-base-service/models"
+	"push-base-service/service/pebble_service"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+func newTestRedisStore(t *testing.T) (*RedisTokenStore, *miniredis.Miniredis) {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	config := DefaultConfig()
+	config.Redis.Addr = server.Addr()
+	config.ApplyDefaults()
+
+	store, err := NewRedisTokenStore(&config.Redis)
+	if err != nil {
+		t.Fatalf("创建 Redis 存储失败: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store, server
+}
+
+func TestRedisTokenStoreSetAndRemove(t *testing.T) {
+	store, _ := newTestRedisStore(t)
+	ctx := context.Background()
+
+	if err := store.SetUserToken(ctx, "user1", "expo", "ExponentPushToken[a]"); err != nil {
+		t.Fatalf("设置令牌失败: %v", err)
+	}
+	if err := store.SetUserToken(ctx, "user1", "fcm", "fcm-a"); err != nil {
+		t.Fatalf("设置令牌失败: %v", err)
+	}
+	if err := store.SetUserTenant(ctx, "user1", "tenant-a"); err != nil {
+		t.Fatalf("设置租户失败: %v", err)
+	}
+
+	tokens, err := store.GetUserTokens(ctx, "user1")
+	if err != nil {
+		t.Fatalf("获取令牌失败: %v", err)
+	}
+	if len(tokens.Tokens) != 2 || tokens.Tokens["expo"] != "ExponentPushToken[a]" || tokens.TenantID != "tenant-a" {
+		t.Fatalf("令牌不符合预期: %+v", tokens)
+	}
+
+	if err := store.RemoveUserToken(ctx, "user1", "expo"); err != nil {
+		t.Fatalf("移除令牌失败: %v", err)
+	}
+	tokens, _ = store.GetUserTokens(ctx, "user1")
+	if _, exists := tokens.Tokens["expo"]; exists || tokens.Tokens["fcm"] != "fcm-a" {
+		t.Fatalf("移除后令牌不符合预期: %+v", tokens.Tokens)
+	}
+
+	if err := store.DeleteUserTokens(ctx, "user1"); err != nil {
+		t.Fatalf("删除令牌失败: %v", err)
+	}
+	tokens, _ = store.GetUserTokens(ctx, "user1")
+	if len(tokens.Tokens) != 0 {
+		t.Fatalf("删除后仍有令牌: %+v", tokens.Tokens)
+	}
+	list, _ := store.ListUserTokens(ctx, 1, 10)
+	if list.Total != 0 {
+		t.Fatalf("删除后用户索引未清理: total=%d", list.Total)
+	}
+}
+
+func TestRedisTokenStoreTransfersToken(t *testing.T) {
+	store, _ := newTestRedisStore(t)
+	ctx := context.Background()
+
+	store.SetUserToken(ctx, "old", "expo", "shared-device")
+	if err := store.SetUserToken(ctx, "new", "expo", "shared-device"); err != nil {
+		t.Fatalf("设置令牌失败: %v", err)
+	}
+
+	all, err := store.GetAllUserTokens(ctx, []string{"old", "new"})
+	if err != nil {
+		t.Fatalf("批量获取令牌失败: %v", err)
+	}
+	if len(all["old"].Tokens) != 0 {
+		t.Fatalf("令牌未从旧用户移除: %+v", all["old"].Tokens)
+	}
+	if all["new"].Tokens["expo"] != "shared-device" {
+		t.Fatalf("令牌未转移到新用户: %+v", all["new"].Tokens)
+	}
+
+	// 旧用户移除同平台令牌不应释放已转移的设备
+	store.RemoveUserToken(ctx, "old", "expo")
+	tokens, _ := store.GetUserTokens(ctx, "new")
+	if tokens.Tokens["expo"] != "shared-device" {
+		t.Fatalf("新用户令牌被误删: %+v", tokens.Tokens)
+	}
+}
+
+func TestRedisTokenStoreListUserTokens(t *testing.T) {
+	store, _ := newTestRedisStore(t)
+	ctx := context.Background()
+
+	for _, metaId := range []string{"c", "a", "b"} {
+		store.SetUserToken(ctx, metaId, "expo", "token-"+metaId)
+	}
+
+	page, err := store.ListUserTokens(ctx, 1, 2)
+	if err != nil {
+		t.Fatalf("获取用户列表失败: %v", err)
+	}
+	if page.Total != 3 || page.TotalPages != 2 || !page.HasNext || len(page.Users) != 2 {
+		t.Fatalf("分页结果不符合预期: %+v", page)
+	}
+	if page.Users[0].MetaID != "a" || page.Users[1].MetaID != "b" {
+		t.Fatalf("用户未按 metaId 排序: %s, %s", page.Users[0].MetaID, page.Users[1].MetaID)
+	}
+
+	page, _ = store.ListUserTokens(ctx, 2, 2)
+	if len(page.Users) != 1 || page.Users[0].MetaID != "c" || page.HasNext {
+		t.Fatalf("第二页结果不符合预期: %+v", page)
+	}
+
+	// 游标分页
+	page, err = store.ListUserTokensAfter(ctx, pebble_service.UserTokensFilter{}, "", 2)
+	if err != nil || len(page.Users) != 2 || !page.HasNext || page.NextCursor == "" {
+		t.Fatalf("游标分页首页结果不符合预期: %+v, %v", page, err)
+	}
+	page, _ = store.ListUserTokensAfter(ctx, pebble_service.UserTokensFilter{}, page.NextCursor, 2)
+	if len(page.Users) != 1 || page.Users[0].MetaID != "c" || page.HasNext {
+		t.Fatalf("游标分页第二页结果不符合预期: %+v", page)
+	}
+
+	// 筛选：metaId 前缀限定字典序范围，平台条件逐个判断
+	store.SetUserToken(ctx, "ab", "fcm", "token-ab")
+	store.SetUserToken(ctx, "ac", "fcm", "token-ac")
+	page, err = store.ListUserTokensAfter(ctx, pebble_service.UserTokensFilter{MetaIDPrefix: "a", Platform: "fcm"}, "", 1)
+	if err != nil || len(page.Users) != 1 || page.Users[0].MetaID != "ab" || !page.HasNext {
+		t.Fatalf("筛选首页结果不符合预期: %+v, %v", page, err)
+	}
+	page, _ = store.ListUserTokensAfter(ctx, pebble_service.UserTokensFilter{MetaIDPrefix: "a", Platform: "fcm"}, page.NextCursor, 1)
+	if len(page.Users) != 1 || page.Users[0].MetaID != "ac" || page.HasNext {
+		t.Fatalf("筛选第二页结果不符合预期: %+v", page)
+	}
+}
+
+func TestRedisTokenStoreBlockedChats(t *testing.T) {
+	store, _ := newTestRedisStore(t)
+	ctx := context.Background()
+
+	if err := store.AddBlockedChat(ctx, "user1", "group1", "group", "太吵", 0); err != nil {
+		t.Fatalf("添加屏蔽失败: %v", err)
+	}
+	if err := store.AddBlockedChat(ctx, "user1", "group2", "group", "", time.Now().Add(-time.Minute).Unix()); err != nil {
+		t.Fatalf("添加屏蔽失败: %v", err)
+	}
+
+	blocked, err := store.IsBlockedChat(ctx, "user1", "group1")
+	if err != nil || !blocked {
+		t.Fatalf("group1 应被屏蔽: blocked=%v, err=%v", blocked, err)
+	}
+	blocked, _ = store.IsBlockedChat(ctx, "user1", "group2")
+	if blocked {
+		t.Fatalf("已过期的静音不应视为屏蔽")
+	}
+
+	chats, err := store.GetUserBlockedChats(ctx, "user1")
+	if err != nil {
+		t.Fatalf("获取屏蔽列表失败: %v", err)
+	}
+	if len(chats.BlockedChats) != 1 || chats.BlockedChats[0].ChatID != "group1" || chats.BlockedChats[0].Reason != "太吵" {
+		t.Fatalf("屏蔽列表不符合预期: %+v", chats.BlockedChats)
+	}
+
+	// 再次屏蔽只更新截止时间，保留原因
+	muteUntil := time.Now().Add(time.Hour).Unix()
+	store.AddBlockedChat(ctx, "user1", "group1", "group", "", muteUntil)
+	chats, _ = store.GetUserBlockedChats(ctx, "user1")
+	if chats.BlockedChats[0].MuteUntil != muteUntil || chats.BlockedChats[0].Reason != "太吵" {
+		t.Fatalf("更新屏蔽记录不符合预期: %+v", chats.BlockedChats[0])
+	}
+
+	if err := store.RemoveBlockedChat(ctx, "user1", "group1"); err != nil {
+		t.Fatalf("移除屏蔽失败: %v", err)
+	}
+	if blocked, _ := store.IsBlockedChat(ctx, "user1", "group1"); blocked {
+		t.Fatalf("移除后仍被屏蔽")
+	}
+}
+
+func TestRedisTokenStoreBatchAndListBlockedChats(t *testing.T) {
+	store, _ := newTestRedisStore(t)
+	ctx := context.Background()
+
+	store.AddBlockedChat(ctx, "user1", "group1", "group", "太吵", 0)
+	block := []models.BlockedChat{{ChatID: "group1", ChatType: "group"}, {ChatID: "group2"}, {ChatID: "group3"}, {ChatID: "group4"}}
+	result, err := store.BatchUpdateBlockedChats(ctx, "user1", block, []string{"group4"})
+	if err != nil || result.Blocked != 3 || result.Unblocked != 1 {
+		t.Fatalf("批量更新结果不符合预期: %+v, err=%v", result, err)
+	}
+
+	page, err := store.ListBlockedChats(ctx, "user1", "", 2)
+	if err != nil || len(page.BlockedChats) != 2 || !page.HasNext || page.BlockedChats[0].Reason != "太吵" {
+		t.Fatalf("第一页不符合预期: %+v, err=%v", page, err)
+	}
+	page, err = store.ListBlockedChats(ctx, "user1", page.NextCursor, 2)
+	if err != nil || len(page.BlockedChats) != 1 || page.HasNext || page.BlockedChats[0].ChatID != "group3" {
+		t.Fatalf("第二页不符合预期: %+v, err=%v", page, err)
+	}
+}
+
+func TestRedisTokenStoreSenderBlocks(t *testing.T) {
+	store, _ := newTestRedisStore(t)
+	ctx := context.Background()
+
+	store.AddBlockedSender(ctx, "user1", "spammer", "spam")
+	store.AddBlockedSender(ctx, "user1", "bot", "")
+	if blocked, _ := store.IsBlockedSender(ctx, "user1", "spammer"); !blocked {
+		t.Error("spammer 应被屏蔽")
+	}
+	store.RemoveBlockedSender(ctx, "user1", "bot")
+	senders, err := store.GetUserBlockedSenders(ctx, "user1")
+	if err != nil || len(senders.BlockedSenders) != 1 || senders.BlockedSenders[0].Reason != "spam" {
+		t.Fatalf("屏蔽发送者列表不符合预期: %+v, err=%v", senders, err)
+	}
+
+	if status, _ := store.GetAllGroupsBlock(ctx, "user1"); status.Blocked {
+		t.Error("未开启时不应屏蔽所有群聊")
+	}
+	store.SetAllGroupsBlocked(ctx, "user1", true)
+	if status, _ := store.GetAllGroupsBlock(ctx, "user1"); !status.Blocked || status.BlockedAt == 0 {
+		t.Errorf("开启后状态不符合预期: %+v", status)
+	}
+	store.SetAllGroupsBlocked(ctx, "user1", false)
+	if status, _ := store.GetAllGroupsBlock(ctx, "user1"); status.Blocked {
+		t.Error("关闭后不应屏蔽所有群聊")
+	}
+}
+
+func TestRedisTokenStoreNotifiedPins(t *testing.T) {
+	store, server := newTestRedisStore(t)
+	ctx := context.Background()
+
+	if notified, _ := store.IsNotifiedPin(ctx, "pin1"); notified {
+		t.Fatalf("未记录的 PIN 不应为已通知")
+	}
+	if err := store.AddNotifiedPin(ctx, "pin1"); err != nil {
+		t.Fatalf("记录 PIN 失败: %v", err)
+	}
+	if notified, _ := store.IsNotifiedPin(ctx, "pin1"); !notified {
+		t.Fatalf("PIN 应为已通知")
+	}
+
+	server.FastForward(31 * 24 * time.Hour)
+	if notified, _ := store.IsNotifiedPin(ctx, "pin1"); notified {
+		t.Fatalf("PIN 记录应在保留时长后过期")
+	}
+}
+
+func TestNewStoresRejectsUnknownBackend(t *testing.T) {
+	if _, err := NewStores(&Config{Backend: "mysql"}, nil); err == nil {
+		t.Fatalf("未知存储后端应返回错误")
+	}
+	if _, err := NewStores(&Config{Backend: BackendPebble}, nil); err == nil {
+		t.Fatalf("缺少 Pebble 令牌存储时应返回错误")
+	}
+}
+
+func TestNewStoresSkipsPinFilterForRedis(t *testing.T) {
+	config := DefaultConfig()
+	config.Backend = BackendRedis
+	config.Redis.Addr = miniredis.RunT(t).Addr()
+	config.PinFilter.Enabled = true
+
+	stores, err := NewStores(config, nil)
+	if err != nil {
+		t.Fatalf("NewStores() failed, err: %v", err)
+	}
+	defer stores.Close()
+	if _, filtered := stores.NotifiedPins.(*FilteredPinStore); filtered {
+		t.Errorf("Redis 后端由多个实例共享，不应使用本地布隆过滤器")
+	}
+}
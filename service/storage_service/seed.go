@@ -0,0 +1,118 @@
+package storage_service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/rand"
+	"push-base-service/models"
+	"push-base-service/service/pebble_service"
+	"push-base-service/service/push_service"
+	"time"
+)
+
+// 测试数据生成的上限与默认值
+const (
+	MaxSeedUsers           = 10000
+	DefaultSeedPrefix      = "seed-"
+	DefaultSeedBlockedMax  = 3
+	seedChatPoolSize       = 50
+	seedTranslateShareRate = 0.3
+)
+
+// seedLocales 测试用户随机使用的语言
+var seedLocales = []string{"", "en", "zh-CN", "ja"}
+
+// SeedRequest 测试数据生成请求
+type SeedRequest struct {
+	Count           int    // 生成的用户数
+	Prefix          string // 用户 MetaID 前缀，默认 seed-
+	MaxBlockedChats int    // 每个用户最多屏蔽的聊天数（从固定聊天池随机选取）
+	Seed            int64  // 随机种子，相同种子生成相同数据；0 表示使用当前时间
+}
+
+// SeedResult 测试数据生成结果
+type SeedResult struct {
+	MetaIDs      []string `json:"metaIds"`      // 生成的用户
+	Tokens       int      `json:"tokens"`       // 写入的令牌数（平台 mock）
+	BlockedChats int      `json:"blockedChats"` // 写入的屏蔽聊天数
+	Preferences  int      `json:"preferences"`  // 写入的偏好设置数
+	Seed         int64    `json:"seed"`         // 实际使用的随机种子
+}
+
+// SeedUsers 生成测试用户：每个用户一个 mock 平台令牌、随机的屏蔽聊天（群聊 seed-group-N / 私聊 seed-user-N，
+// 部分为限时静音）和随机的语言偏好。用户 MetaID 为 前缀+序号，重复执行会覆盖同名用户的数据
+func SeedUsers(ctx context.Context, stores *Stores, ps *pebble_service.PebbleService, request *SeedRequest) (*SeedResult, error) {
+	if request.Count <= 0 || request.Count > MaxSeedUsers {
+		return nil, fmt.Errorf("用户数必须在 1 到 %d 之间", MaxSeedUsers)
+	}
+	prefix := request.Prefix
+	if prefix == "" {
+		prefix = DefaultSeedPrefix
+	}
+	maxBlocked := request.MaxBlockedChats
+	if maxBlocked < 0 {
+		maxBlocked = 0
+	}
+	seed := request.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	rng := rand.New(rand.NewSource(seed))
+
+	result := &SeedResult{MetaIDs: make([]string, 0, request.Count), Seed: seed}
+	now := time.Now()
+	for i := 1; i <= request.Count; i++ {
+		metaId := fmt.Sprintf("%s%d", prefix, i)
+
+		token := fmt.Sprintf("%s%s-%08x", push_service.MockTokenPrefix, metaId, rng.Uint32())
+		if err := stores.Tokens.SetUserToken(ctx, metaId, push_service.ProviderTypeMock, token); err != nil {
+			return result, fmt.Errorf("写入测试令牌失败 (%s): %w", metaId, err)
+		}
+		result.Tokens++
+
+		for _, n := range rng.Perm(seedChatPoolSize)[:rng.Intn(maxBlocked+1)] {
+			chatId, chatType := fmt.Sprintf("seed-group-%d", n), "group"
+			if n%2 == 1 {
+				chatId, chatType = fmt.Sprintf("seed-user-%d", n), "private"
+			}
+			var muteUntil int64
+			if rng.Intn(2) == 0 {
+				muteUntil = now.Add(time.Duration(1+rng.Intn(72)) * time.Hour).Unix()
+			}
+			if err := stores.BlockedChats.AddBlockedChat(ctx, metaId, chatId, chatType, "seed", muteUntil); err != nil {
+				return result, fmt.Errorf("写入测试屏蔽聊天失败 (%s): %w", metaId, err)
+			}
+			result.BlockedChats++
+		}
+
+		preferences := &models.UserPreferences{
+			MetaID:            metaId,
+			Locale:            seedLocales[rng.Intn(len(seedLocales))],
+			TranslatePreviews: rng.Float64() < seedTranslateShareRate,
+		}
+		if err := ps.SaveUserPreferences(preferences); err != nil {
+			return result, fmt.Errorf("写入测试偏好设置失败 (%s): %w", metaId, err)
+		}
+		result.Preferences++
+
+		result.MetaIDs = append(result.MetaIDs, metaId)
+	}
+
+	log.Printf("🌱 已生成 %d 个测试用户: 令牌=%d, 屏蔽聊天=%d, 偏好=%d, 种子=%d",
+		len(result.MetaIDs), result.Tokens, result.BlockedChats, result.Preferences, seed)
+	return result, nil
+}
+
+// SeedTestData 全局方法：生成测试用户数据
+func SeedTestData(request *SeedRequest) (*SeedResult, error) {
+	stores, err := getGlobalStores()
+	if err != nil {
+		return nil, err
+	}
+	ps := pebble_service.GetGlobalService()
+	if ps == nil || !ps.IsInitialized() {
+		return nil, fmt.Errorf("全局 Pebble 服务未初始化，请先初始化推送中心")
+	}
+	return SeedUsers(context.Background(), stores, ps, request)
+}
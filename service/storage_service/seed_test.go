@@ -0,0 +1,42 @@
+//go:build !noredis
+
+package storage_service
+
+import (
+	"context"
+	"push-base-service/service/push_service"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSeedUsersIsDeterministic(t *testing.T) {
+	stores, ps := newTestMergeEnv(t)
+	ctx := context.Background()
+
+	request := &SeedRequest{Count: 5, Prefix: "load-", MaxBlockedChats: 4, Seed: 42}
+	first, err := SeedUsers(ctx, stores, ps, request)
+	if err != nil {
+		t.Fatalf("SeedUsers() failed, err: %v", err)
+	}
+	if len(first.MetaIDs) != 5 || first.Tokens != 5 || first.Preferences != 5 || first.MetaIDs[0] != "load-1" {
+		t.Fatalf("SeedUsers() = %+v", first)
+	}
+
+	tokens, _ := stores.Tokens.GetUserTokens(ctx, "load-3")
+	if token := tokens.Tokens[push_service.ProviderTypeMock]; !strings.HasPrefix(token, push_service.MockTokenPrefix) {
+		t.Errorf("load-3 mock token = %q", token)
+	}
+	if preferences, err := ps.GetUserPreferences("load-3"); err != nil || preferences == nil {
+		t.Errorf("load-3 preferences = %+v, %v", preferences, err)
+	}
+
+	second, _ := SeedUsers(ctx, stores, ps, request)
+	if second.BlockedChats != first.BlockedChats || !reflect.DeepEqual(second.MetaIDs, first.MetaIDs) {
+		t.Errorf("same seed produced different data: %+v vs %+v", second, first)
+	}
+
+	if _, err := SeedUsers(ctx, stores, ps, &SeedRequest{Count: MaxSeedUsers + 1}); err == nil {
+		t.Error("SeedUsers() should reject counts above the limit")
+	}
+}
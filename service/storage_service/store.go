@@ -0,0 +1,560 @@
+package storage_service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"push-base-service/models"
+	"push-base-service/service/pebble_service"
+	"push-base-service/service/push_service"
+	"sync"
+	"time"
+)
+
+// TokenStore 用户令牌存储，在推送使用的 UserTokenStore 之外提供管理接口需要的操作
+type TokenStore interface {
+	push_service.UserTokenStore
+
+	// SetUserTenant 设置用户所属租户
+	SetUserTenant(ctx context.Context, metaId, tenantId string) error
+
+	// DeleteUserTokens 删除用户的所有推送令牌
+	DeleteUserTokens(ctx context.Context, metaId string) error
+
+	// ListUserTokens 分页获取用户令牌列表
+	ListUserTokens(ctx context.Context, page, pageSize int) (*pebble_service.PaginatedUserTokens, error)
+
+	// ListUserTokensAfter 获取游标之后满足筛选条件的一页用户令牌，空游标从头开始
+	ListUserTokensAfter(ctx context.Context, filter pebble_service.UserTokensFilter, cursor string, pageSize int) (*pebble_service.PaginatedUserTokens, error)
+}
+
+// BlockedChatStore 屏蔽聊天存储
+type BlockedChatStore interface {
+	// AddBlockedChat 添加屏蔽聊天，muteUntil 为 0 表示永久屏蔽；已屏蔽时更新截止时间
+	AddBlockedChat(ctx context.Context, userId, chatId, chatType, reason string, muteUntil int64) error
+
+	// RemoveBlockedChat 取消屏蔽聊天
+	RemoveBlockedChat(ctx context.Context, userId, chatId string) error
+
+	// IsBlockedChat 检查用户是否屏蔽了该聊天，已过期的临时静音视为未屏蔽
+	IsBlockedChat(ctx context.Context, userId, chatId string) (bool, error)
+
+	// GetUserBlockedChats 获取用户的所有屏蔽聊天（不含已过期的临时静音）
+	GetUserBlockedChats(ctx context.Context, userId string) (*models.UserBlockedChats, error)
+
+	// ListBlockedChats 按聊天ID顺序分页获取用户的屏蔽聊天（不含已过期的临时静音），空游标从头开始
+	ListBlockedChats(ctx context.Context, userId, cursor string, pageSize int) (*models.BlockedChatsPage, error)
+
+	// BatchUpdateBlockedChats 批量屏蔽和取消屏蔽聊天，同一聊天同时出现在两个列表中时以取消屏蔽为准
+	BatchUpdateBlockedChats(ctx context.Context, userId string, block []models.BlockedChat, unblock []string) (*models.BlockedChatsBatchResult, error)
+}
+
+// ChatSoundStore 聊天通知声音存储
+type ChatSoundStore interface {
+	// SetChatSound 设置用户某个聊天的通知声音，sound 为空时恢复默认声音
+	SetChatSound(ctx context.Context, userId, chatId, chatType, sound string) error
+
+	// GetChatSounds 批量获取用户在某个聊天的通知声音，只返回设置了声音的用户
+	GetChatSounds(ctx context.Context, userIds []string, chatId string) (map[string]string, error)
+
+	// GetUserChatSounds 获取用户设置的所有聊天通知声音
+	GetUserChatSounds(ctx context.Context, userId string) (*models.UserChatSounds, error)
+}
+
+// SenderBlockStore 屏蔽发送者和屏蔽所有群聊存储
+type SenderBlockStore interface {
+	// AddBlockedSender 屏蔽发送者，已屏蔽时更新屏蔽原因
+	AddBlockedSender(ctx context.Context, userId, senderId, reason string) error
+
+	// RemoveBlockedSender 取消屏蔽发送者
+	RemoveBlockedSender(ctx context.Context, userId, senderId string) error
+
+	// IsBlockedSender 检查用户是否屏蔽了该发送者
+	IsBlockedSender(ctx context.Context, userId, senderId string) (bool, error)
+
+	// GetUserBlockedSenders 获取用户屏蔽的所有发送者
+	GetUserBlockedSenders(ctx context.Context, userId string) (*models.UserBlockedSenders, error)
+
+	// SetAllGroupsBlocked 开启或关闭屏蔽所有群聊
+	SetAllGroupsBlocked(ctx context.Context, userId string, blocked bool) error
+
+	// GetAllGroupsBlock 获取用户的屏蔽所有群聊设置，未开启时 Blocked 为 false
+	GetAllGroupsBlock(ctx context.Context, userId string) (*models.AllGroupsBlock, error)
+}
+
+// NotifiedPinStore 已通知 PIN 存储
+type NotifiedPinStore interface {
+	// IsNotifiedPin 检查 PIN 是否已通知
+	IsNotifiedPin(ctx context.Context, pinId string) (bool, error)
+
+	// AddNotifiedPin 记录 PIN 已通知
+	AddNotifiedPin(ctx context.Context, pinId string) error
+}
+
+// redisStore Redis 存储后端，同时提供令牌、屏蔽聊天、屏蔽发送者、聊天通知声音和已通知 PIN 存储
+type redisStore interface {
+	TokenStore
+	BlockedChatStore
+	SenderBlockStore
+	ChatSoundStore
+	NotifiedPinStore
+}
+
+// Stores 当前后端的各类存储
+type Stores struct {
+	Backend      string
+	Tokens       TokenStore
+	BlockedChats BlockedChatStore
+	SenderBlocks SenderBlockStore
+	ChatSounds   ChatSoundStore
+	NotifiedPins NotifiedPinStore
+
+	pinMaxAge time.Duration // 已通知 PIN 保留时长（Pebble 后端定期清理）
+}
+
+// NewStores 根据配置创建存储
+// pebble 后端直接使用传入的 Pebble 令牌存储（可带缓存）；redis 后端连接 Redis
+func NewStores(config *Config, pebbleStore *pebble_service.PebbleTokenStore) (*Stores, error) {
+	if config == nil {
+		config = DefaultConfig()
+	}
+	config.ApplyDefaults()
+
+	switch config.Backend {
+	case BackendPebble:
+		if pebbleStore == nil {
+			return nil, fmt.Errorf("Pebble 令牌存储未初始化")
+		}
+		stores := &Stores{
+			Backend:      BackendPebble,
+			Tokens:       pebbleStore,
+			BlockedChats: pebbleStore,
+			SenderBlocks: pebbleStore,
+			ChatSounds:   pebbleStore,
+			NotifiedPins: pebbleStore,
+			pinMaxAge:    config.PinMaxAge,
+		}
+		if config.PinFilter.Enabled {
+			stores.NotifiedPins = NewFilteredPinStore(pebbleStore, pebbleStore, config.PinFilter)
+		}
+		return stores, nil
+	case BackendRedis:
+		store, err := newRedisStore(&config.Redis)
+		if err != nil {
+			return nil, err
+		}
+		if config.PinFilter.Enabled {
+			log.Printf("⚠️ Redis 存储后端由多个实例共享，已通知 PIN 布隆过滤器不生效")
+		}
+		return &Stores{
+			Backend:      BackendRedis,
+			Tokens:       store,
+			BlockedChats: store,
+			SenderBlocks: store,
+			ChatSounds:   store,
+			NotifiedPins: store,
+		}, nil
+	default:
+		return nil, fmt.Errorf("不支持的存储后端: %s", config.Backend)
+	}
+}
+
+// MaintainNotifiedPins 清理超过保留时长的已通知 PIN，并重建布隆过滤器（启用时）
+// 仅对 Pebble 后端生效，Redis 后端的记录按 pin_ttl 自动过期
+func (s *Stores) MaintainNotifiedPins(ctx context.Context) error {
+	var notBefore int64
+	if s.pinMaxAge > 0 {
+		notBefore = time.Now().Add(-s.pinMaxAge).Unix()
+	}
+
+	filtered, isFiltered := s.NotifiedPins.(*FilteredPinStore)
+	scanner, isScanner := s.NotifiedPins.(NotifiedPinScanner)
+	if isFiltered {
+		scanner, isScanner = filtered.scanner, true
+	}
+	if !isScanner {
+		return nil
+	}
+
+	if notBefore > 0 {
+		count, err := scanner.PurgeNotifiedPins(ctx, notBefore)
+		if err != nil {
+			return fmt.Errorf("清理过期已通知PIN失败: %w", err)
+		}
+		if count > 0 {
+			log.Printf("🧹 已清理 %d 条超过保留时长的已通知PIN", count)
+		}
+	}
+	if isFiltered {
+		if err := filtered.Rebuild(ctx, notBefore); err != nil {
+			return fmt.Errorf("重建已通知PIN布隆过滤器失败: %w", err)
+		}
+	}
+	return nil
+}
+
+// Close 关闭存储持有的连接（Pebble 由推送中心单独关闭）
+func (s *Stores) Close() error {
+	if closer, ok := s.Tokens.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+var (
+	globalStores   *Stores
+	globalStoresMu sync.RWMutex
+)
+
+// SetGlobalStores 设置全局存储
+func SetGlobalStores(stores *Stores) {
+	globalStoresMu.Lock()
+	defer globalStoresMu.Unlock()
+	globalStores = stores
+}
+
+// GetGlobalStores 获取全局存储，未设置时返回 nil
+func GetGlobalStores() *Stores {
+	globalStoresMu.RLock()
+	defer globalStoresMu.RUnlock()
+	return globalStores
+}
+
+// getGlobalStores 获取全局存储，未设置时返回错误
+func getGlobalStores() (*Stores, error) {
+	stores := GetGlobalStores()
+	if stores == nil {
+		return nil, fmt.Errorf("全局存储未初始化，请先初始化推送中心")
+	}
+	return stores, nil
+}
+
+// ===== 全局方法（供接口层和推送中心使用） =====
+
+// SetUserToken 设置用户推送令牌（Token作为设备ID）
+func SetUserToken(metaID, platform, token string) error {
+	stores, err := getGlobalStores()
+	if err != nil {
+		return err
+	}
+	return stores.Tokens.SetUserToken(context.Background(), metaID, platform, token)
+}
+
+// SetUserTenant 设置用户所属租户
+func SetUserTenant(metaID, tenantID string) error {
+	if metaID == "" {
+		return fmt.Errorf("MetaID 不能为空")
+	}
+
+	stores, err := getGlobalStores()
+	if err != nil {
+		return err
+	}
+	return stores.Tokens.SetUserTenant(context.Background(), metaID, tenantID)
+}
+
+// GetUserTokenByMetaID 根据 metaId 获取用户推送令牌
+func GetUserTokenByMetaID(metaID string) (*models.UserPushTokens, error) {
+	if metaID == "" {
+		return nil, fmt.Errorf("MetaID 不能为空")
+	}
+
+	stores, err := getGlobalStores()
+	if err != nil {
+		return nil, err
+	}
+	return stores.Tokens.GetUserTokens(context.Background(), metaID)
+}
+
+// GetUserTokensList 获取用户推送令牌列表（支持分页）
+func GetUserTokensList(page, pageSize int) (*pebble_service.PaginatedUserTokens, error) {
+	stores, err := getGlobalStores()
+	if err != nil {
+		return nil, err
+	}
+	return stores.Tokens.ListUserTokens(context.Background(), page, pageSize)
+}
+
+// GetUserTokensAfter 获取用户推送令牌列表（游标分页，支持筛选）
+func GetUserTokensAfter(filter pebble_service.UserTokensFilter, cursor string, pageSize int) (*pebble_service.PaginatedUserTokens, error) {
+	stores, err := getGlobalStores()
+	if err != nil {
+		return nil, err
+	}
+	return stores.Tokens.ListUserTokensAfter(context.Background(), filter, cursor, pageSize)
+}
+
+// RemoveUserToken 移除用户指定平台的推送令牌
+func RemoveUserToken(metaID, platform string) error {
+	if metaID == "" {
+		return fmt.Errorf("MetaID 不能为空")
+	}
+	if platform == "" {
+		return fmt.Errorf("平台不能为空")
+	}
+
+	stores, err := getGlobalStores()
+	if err != nil {
+		return err
+	}
+	return stores.Tokens.RemoveUserToken(context.Background(), metaID, platform)
+}
+
+// RemoveUserAllTokens 移除用户的所有推送令牌
+func RemoveUserAllTokens(metaID string) error {
+	if metaID == "" {
+		return fmt.Errorf("MetaID 不能为空")
+	}
+
+	stores, err := getGlobalStores()
+	if err != nil {
+		return err
+	}
+	return stores.Tokens.DeleteUserTokens(context.Background(), metaID)
+}
+
+// GetUserBlockedChats 根据metaId获取用户屏蔽列表
+func GetUserBlockedChats(metaID string) (*models.UserBlockedChats, error) {
+	if metaID == "" {
+		return nil, fmt.Errorf("MetaID不能为空")
+	}
+
+	stores, err := getGlobalStores()
+	if err != nil {
+		return nil, err
+	}
+	return stores.BlockedChats.GetUserBlockedChats(context.Background(), metaID)
+}
+
+// AddBlockedChat 新增屏蔽某个群或某个私聊，muteUntil 为 0 表示永久屏蔽
+func AddBlockedChat(metaID, chatID, chatType, reason string, muteUntil int64) error {
+	if metaID == "" {
+		return fmt.Errorf("MetaID不能为空")
+	}
+	if chatID == "" {
+		return fmt.Errorf("ChatID不能为空")
+	}
+	if chatType == "" {
+		return fmt.Errorf("ChatType不能为空")
+	}
+
+	stores, err := getGlobalStores()
+	if err != nil {
+		return err
+	}
+	return stores.BlockedChats.AddBlockedChat(context.Background(), metaID, chatID, chatType, reason, muteUntil)
+}
+
+// RemoveBlockedChat 取消屏蔽某个群或某个私聊
+func RemoveBlockedChat(metaID, chatID string) error {
+	if metaID == "" {
+		return fmt.Errorf("MetaID不能为空")
+	}
+	if chatID == "" {
+		return fmt.Errorf("ChatID不能为空")
+	}
+
+	stores, err := getGlobalStores()
+	if err != nil {
+		return err
+	}
+	return stores.BlockedChats.RemoveBlockedChat(context.Background(), metaID, chatID)
+}
+
+// IsUserBlockedChat 检查用户是否屏蔽了某个聊天（群聊或私聊）
+func IsUserBlockedChat(metaID, chatID string) (bool, error) {
+	if metaID == "" {
+		return false, fmt.Errorf("MetaID不能为空")
+	}
+	if chatID == "" {
+		return false, fmt.Errorf("ChatID不能为空")
+	}
+
+	stores, err := getGlobalStores()
+	if err != nil {
+		return false, err
+	}
+	return stores.BlockedChats.IsBlockedChat(context.Background(), metaID, chatID)
+}
+
+// ListBlockedChats 分页获取用户的屏蔽聊天，cursor 为上一页返回的游标
+func ListBlockedChats(metaID, cursor string, pageSize int) (*models.BlockedChatsPage, error) {
+	if metaID == "" {
+		return nil, fmt.Errorf("MetaID不能为空")
+	}
+
+	stores, err := getGlobalStores()
+	if err != nil {
+		return nil, err
+	}
+	return stores.BlockedChats.ListBlockedChats(context.Background(), metaID, cursor, pageSize)
+}
+
+// BatchUpdateBlockedChats 批量屏蔽和取消屏蔽聊天（如客户端重装后同步屏蔽列表）
+func BatchUpdateBlockedChats(metaID string, block []models.BlockedChat, unblock []string) (*models.BlockedChatsBatchResult, error) {
+	if metaID == "" {
+		return nil, fmt.Errorf("MetaID不能为空")
+	}
+
+	stores, err := getGlobalStores()
+	if err != nil {
+		return nil, err
+	}
+	return stores.BlockedChats.BatchUpdateBlockedChats(context.Background(), metaID, block, unblock)
+}
+
+// AddBlockedSender 屏蔽发送者，用户不再收到该发送者在任何聊天中的消息推送
+func AddBlockedSender(metaID, senderID, reason string) error {
+	if metaID == "" {
+		return fmt.Errorf("MetaID不能为空")
+	}
+	if senderID == "" {
+		return fmt.Errorf("SenderID不能为空")
+	}
+
+	stores, err := getGlobalStores()
+	if err != nil {
+		return err
+	}
+	return stores.SenderBlocks.AddBlockedSender(context.Background(), metaID, senderID, reason)
+}
+
+// RemoveBlockedSender 取消屏蔽发送者
+func RemoveBlockedSender(metaID, senderID string) error {
+	if metaID == "" {
+		return fmt.Errorf("MetaID不能为空")
+	}
+	if senderID == "" {
+		return fmt.Errorf("SenderID不能为空")
+	}
+
+	stores, err := getGlobalStores()
+	if err != nil {
+		return err
+	}
+	return stores.SenderBlocks.RemoveBlockedSender(context.Background(), metaID, senderID)
+}
+
+// IsUserBlockedSender 检查用户是否屏蔽了某个发送者
+func IsUserBlockedSender(metaID, senderID string) (bool, error) {
+	if metaID == "" {
+		return false, fmt.Errorf("MetaID不能为空")
+	}
+	if senderID == "" {
+		return false, fmt.Errorf("SenderID不能为空")
+	}
+
+	stores, err := getGlobalStores()
+	if err != nil {
+		return false, err
+	}
+	return stores.SenderBlocks.IsBlockedSender(context.Background(), metaID, senderID)
+}
+
+// GetUserBlockedSenders 根据metaId获取用户屏蔽的所有发送者
+func GetUserBlockedSenders(metaID string) (*models.UserBlockedSenders, error) {
+	if metaID == "" {
+		return nil, fmt.Errorf("MetaID不能为空")
+	}
+
+	stores, err := getGlobalStores()
+	if err != nil {
+		return nil, err
+	}
+	return stores.SenderBlocks.GetUserBlockedSenders(context.Background(), metaID)
+}
+
+// SetAllGroupsBlocked 开启或关闭屏蔽所有群聊
+func SetAllGroupsBlocked(metaID string, blocked bool) error {
+	if metaID == "" {
+		return fmt.Errorf("MetaID不能为空")
+	}
+
+	stores, err := getGlobalStores()
+	if err != nil {
+		return err
+	}
+	return stores.SenderBlocks.SetAllGroupsBlocked(context.Background(), metaID, blocked)
+}
+
+// GetAllGroupsBlock 根据metaId获取用户的屏蔽所有群聊设置
+func GetAllGroupsBlock(metaID string) (*models.AllGroupsBlock, error) {
+	if metaID == "" {
+		return nil, fmt.Errorf("MetaID不能为空")
+	}
+
+	stores, err := getGlobalStores()
+	if err != nil {
+		return nil, err
+	}
+	return stores.SenderBlocks.GetAllGroupsBlock(context.Background(), metaID)
+}
+
+// SetChatSound 设置用户某个聊天的通知声音，sound 为空时恢复默认声音
+func SetChatSound(metaID, chatID, chatType, sound string) error {
+	if metaID == "" {
+		return fmt.Errorf("MetaID不能为空")
+	}
+	if chatID == "" {
+		return fmt.Errorf("ChatID不能为空")
+	}
+
+	stores, err := getGlobalStores()
+	if err != nil {
+		return err
+	}
+	return stores.ChatSounds.SetChatSound(context.Background(), metaID, chatID, chatType, sound)
+}
+
+// GetChatSounds 批量获取用户在某个聊天的通知声音，只返回设置了声音的用户
+func GetChatSounds(metaIDs []string, chatID string) (map[string]string, error) {
+	if chatID == "" {
+		return nil, fmt.Errorf("ChatID不能为空")
+	}
+
+	stores, err := getGlobalStores()
+	if err != nil {
+		return nil, err
+	}
+	return stores.ChatSounds.GetChatSounds(context.Background(), metaIDs, chatID)
+}
+
+// GetUserChatSounds 根据metaId获取用户设置的所有聊天通知声音
+func GetUserChatSounds(metaID string) (*models.UserChatSounds, error) {
+	if metaID == "" {
+		return nil, fmt.Errorf("MetaID不能为空")
+	}
+
+	stores, err := getGlobalStores()
+	if err != nil {
+		return nil, err
+	}
+	return stores.ChatSounds.GetUserChatSounds(context.Background(), metaID)
+}
+
+// AddNotifiedPin 添加PIN已通知记录
+func AddNotifiedPin(pinID string) error {
+	if pinID == "" {
+		return fmt.Errorf("PinID不能为空")
+	}
+
+	stores, err := getGlobalStores()
+	if err != nil {
+		return err
+	}
+	return stores.NotifiedPins.AddNotifiedPin(context.Background(), pinID)
+}
+
+// IsNotifiedPin 根据pinID获取是否已通知
+func IsNotifiedPin(pinID string) (bool, error) {
+	if pinID == "" {
+		return false, fmt.Errorf("PinID不能为空")
+	}
+
+	stores, err := getGlobalStores()
+	if err != nil {
+		return false, err
+	}
+	return stores.NotifiedPins.IsNotifiedPin(context.Background(), pinID)
+}
@@ -0,0 +1,61 @@
+package throttle_service
+
+import "time"
+
+// 限流后端类型
+const (
+	BackendMemory = "memory" // 进程内令牌桶，适合单实例部署
+	BackendPebble = "pebble" // Pebble 持久化滑动窗口，重启后配额不丢失
+	BackendRedis  = "redis"  // Redis 滑动窗口，多实例共享配额
+)
+
+// Config 推送限流配置
+type Config struct {
+	Enabled bool          `yaml:"enabled" json:"enabled"` // 是否启用限流
+	Backend string        `yaml:"backend" json:"backend"` // 限流后端：memory / pebble / redis
+	Limit   int           `yaml:"limit" json:"limit"`     // 每个用户在一个窗口内最多接收的推送次数
+	Window  time.Duration `yaml:"window" json:"window"`   // 限流窗口
+	Redis   RedisConfig   `yaml:"redis" json:"redis"`     // Redis 后端配置
+}
+
+// RedisConfig Redis 限流后端配置
+type RedisConfig struct {
+	Addr      string `yaml:"addr" json:"addr"`             // Redis 地址，如 127.0.0.1:6379
+	Password  string `yaml:"password" json:"password"`     // Redis 密码
+	DB        int    `yaml:"db" json:"db"`                 // Redis 数据库编号
+	KeyPrefix string `yaml:"key_prefix" json:"key_prefix"` // 限流键前缀
+}
+
+// DefaultConfig 返回默认配置
+func DefaultConfig() *Config {
+	return &Config{
+		Backend: BackendMemory,
+		Limit:   30,
+		Window:  time.Minute,
+		Redis: RedisConfig{
+			Addr:      "127.0.0.1:6379",
+			KeyPrefix: "push:throttle:",
+		},
+	}
+}
+
+// ApplyDefaults 为缺失的配置项填充默认值
+func (c *Config) ApplyDefaults() {
+	defaults := DefaultConfig()
+
+	if c.Backend == "" {
+		c.Backend = defaults.Backend
+	}
+	if c.Limit <= 0 {
+		c.Limit = defaults.Limit
+	}
+	if c.Window <= 0 {
+		c.Window = defaults.Window
+	}
+	if c.Redis.Addr == "" {
+		c.Redis.Addr = defaults.Redis.Addr
+	}
+	if c.Redis.KeyPrefix == "" {
+		c.Redis.KeyPrefix = defaults.Redis.KeyPrefix
+	}
+}
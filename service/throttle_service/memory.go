@@ -0,0 +1,99 @@
+package throttle_service
+
+import (
+	"context"
+	"push-base-service/service/push_service"
+	"sync"
+	"time"
+)
+
+// MemoryThrottler 进程内令牌桶限流器
+// 每个 key 一个桶，容量为 burst（默认等于 limit），按 limit/window 的速率匀速补充
+type MemoryThrottler struct {
+	limit     int
+	burst     int
+	window    time.Duration
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+	mu        sync.Mutex
+	now       func() time.Time
+}
+
+// tokenBucket 单个 key 的令牌桶
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewMemoryThrottler 创建内存令牌桶限流器
+func NewMemoryThrottler(limit int, window time.Duration) *MemoryThrottler {
+	return &MemoryThrottler{
+		limit:   limit,
+		burst:   limit,
+		window:  window,
+		buckets: make(map[string]*tokenBucket),
+		now:     time.Now,
+	}
+}
+
+// NewBurstThrottler 创建桶容量与补充速率分开配置的内存令牌桶限流器：每个窗口补充 limit 个令牌，最多累积 burst 个
+func NewBurstThrottler(limit int, window time.Duration, burst int) *MemoryThrottler {
+	t := NewMemoryThrottler(limit, window)
+	if burst > 0 {
+		t.burst = burst
+	}
+	return t
+}
+
+// Name 返回限流器名称
+func (t *MemoryThrottler) Name() string {
+	return BackendMemory
+}
+
+// Allow 判断 key 本次是否允许推送
+func (t *MemoryThrottler) Allow(ctx context.Context, key string) (*push_service.ThrottleDecision, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	t.sweep(now)
+
+	rate := float64(t.limit) / t.window.Seconds() // 每秒补充的令牌数
+	bucket, exists := t.buckets[key]
+	if !exists {
+		bucket = &tokenBucket{tokens: float64(t.burst), last: now}
+		t.buckets[key] = bucket
+	} else {
+		bucket.tokens += now.Sub(bucket.last).Seconds() * rate
+		if bucket.tokens > float64(t.burst) {
+			bucket.tokens = float64(t.burst)
+		}
+		bucket.last = now
+	}
+
+	if bucket.tokens < 1 {
+		retryAfter := time.Duration((1 - bucket.tokens) / rate * float64(time.Second))
+		return &push_service.ThrottleDecision{Allowed: false, RetryAfter: retryAfter}, nil
+	}
+
+	bucket.tokens--
+	return &push_service.ThrottleDecision{Allowed: true, Remaining: int(bucket.tokens)}, nil
+}
+
+// sweep 清理足够长时间未使用的桶（此时桶已补满，删除不影响结果）
+func (t *MemoryThrottler) sweep(now time.Time) {
+	if now.Sub(t.lastSweep) < t.window {
+		return
+	}
+	t.lastSweep = now
+
+	idle := t.window
+	if t.burst > t.limit {
+		idle = time.Duration(float64(t.window) * float64(t.burst) / float64(t.limit))
+	}
+	for key, bucket := range t.buckets {
+		if now.Sub(bucket.last) >= idle {
+			delete(t.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package throttle_service
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryThrottlerTokenBucket(t *testing.T) {
+	throttler := NewMemoryThrottler(2, time.Minute)
+	now := time.Unix(1700000000, 0)
+	throttler.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		decision, err := throttler.Allow(ctx, "user-a")
+		if err != nil || !decision.Allowed {
+			t.Fatalf("Allow() #%d = %+v, %v; want allowed", i+1, decision, err)
+		}
+	}
+
+	decision, err := throttler.Allow(ctx, "user-a")
+	if err != nil || decision.Allowed {
+		t.Fatalf("Allow() over limit = %+v, %v; want throttled", decision, err)
+	}
+	if decision.RetryAfter != 30*time.Second {
+		t.Errorf("RetryAfter = %v, want 30s", decision.RetryAfter)
+	}
+
+	// 其他用户不受影响
+	if decision, _ := throttler.Allow(ctx, "user-b"); !decision.Allowed {
+		t.Error("Allow(user-b) should not be throttled")
+	}
+
+	// 补充一个令牌后恢复
+	now = now.Add(30 * time.Second)
+	if decision, _ := throttler.Allow(ctx, "user-a"); !decision.Allowed {
+		t.Error("Allow(user-a) should be allowed after refill")
+	}
+}
+
+func TestMemoryThrottlerSweepsIdleBuckets(t *testing.T) {
+	throttler := NewMemoryThrottler(5, time.Minute)
+	now := time.Unix(1700000000, 0)
+	throttler.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	throttler.Allow(ctx, "user-a")
+	now = now.Add(2 * time.Minute)
+	throttler.Allow(ctx, "user-b")
+
+	if _, exists := throttler.buckets["user-a"]; exists {
+		t.Error("idle bucket user-a should have been swept")
+	}
+	if len(throttler.buckets) != 1 {
+		t.Errorf("len(buckets) = %d, want 1", len(throttler.buckets))
+	}
+}
+
+func TestNewThrottlerDisabled(t *testing.T) {
+	throttler, err := NewThrottler(&Config{Enabled: false})
+	if err != nil || throttler != nil {
+		t.Errorf("NewThrottler(disabled) = %v, %v; want nil, nil", throttler, err)
+	}
+
+	if _, err := NewThrottler(&Config{Enabled: true, Backend: "unknown"}); err == nil {
+		t.Error("NewThrottler(unknown backend) should fail")
+	}
+}
+
+func TestBurstThrottlerAllowsBurstAboveRate(t *testing.T) {
+	throttler := NewBurstThrottler(1, time.Second, 3)
+	now := time.Unix(1700000000, 0)
+	throttler.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		if decision, _ := throttler.Allow(ctx, "ip-a"); !decision.Allowed {
+			t.Fatalf("Allow() #%d within burst should be allowed", i+1)
+		}
+	}
+	if decision, _ := throttler.Allow(ctx, "ip-a"); decision.Allowed {
+		t.Fatal("Allow() over burst should be throttled")
+	}
+
+	// 按速率补充：一秒一个令牌，且最多累积 burst 个
+	now = now.Add(10 * time.Second)
+	for i := 0; i < 3; i++ {
+		throttler.Allow(ctx, "ip-a")
+	}
+	if decision, _ := throttler.Allow(ctx, "ip-a"); decision.Allowed {
+		t.Error("tokens should be capped at burst")
+	}
+}
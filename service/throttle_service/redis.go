@@ -0,0 +1,123 @@
+//go:build !noredis
+
+package throttle_service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"push-base-service/service/push_service"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// slidingWindowScript 基于有序集合的滑动窗口限流脚本
+// KEYS[1]: 限流键；ARGV: 当前时间(毫秒)、窗口(毫秒)、上限、本次成员
+// 返回 {是否允许, 剩余配额, 重试等待(毫秒)}
+var slidingWindowScript = redis.NewScript(`
+local key = KEYS[1]
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+
+redis.call('ZREMRANGEBYSCORE', key, 0, now - window)
+local count = redis.call('ZCARD', key)
+if count < limit then
+	redis.call('ZADD', key, now, ARGV[4])
+	redis.call('PEXPIRE', key, window)
+	return {1, limit - count - 1, 0}
+end
+
+local retry = window
+local oldest = redis.call('ZRANGE', key, 0, 0, 'WITHSCORES')
+if oldest[2] then
+	retry = tonumber(oldest[2]) + window - now
+end
+return {0, 0, retry}
+`)
+
+// RedisEnabled 当前构建是否包含 Redis 支持（使用 noredis 构建标签时为 false）
+const RedisEnabled = true
+
+// RedisThrottler 基于 Redis 的滑动窗口限流器，多实例共享同一份配额
+type RedisThrottler struct {
+	client    *redis.Client
+	keyPrefix string
+	limit     int
+	window    time.Duration
+	instance  string // 实例随机标识，避免多实例同一毫秒写入相同成员
+	seq       atomic.Uint64
+}
+
+// newRedisThrottler 创建 Redis 限流器
+func newRedisThrottler(config *RedisConfig, limit int, window time.Duration) (push_service.Throttler, error) {
+	throttler, err := NewRedisThrottler(config, limit, window)
+	if err != nil {
+		return nil, err
+	}
+	return throttler, nil
+}
+
+// NewRedisThrottler 创建 Redis 限流器并检查连接
+func NewRedisThrottler(config *RedisConfig, limit int, window time.Duration) (*RedisThrottler, error) {
+	client := redis.NewClient(&redis.Options{
+		Addr:     config.Addr,
+		Password: config.Password,
+		DB:       config.DB,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("连接 Redis 失败: %w", err)
+	}
+
+	instance := make([]byte, 4)
+	if _, err := rand.Read(instance); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("生成实例标识失败: %w", err)
+	}
+
+	return &RedisThrottler{
+		client:    client,
+		keyPrefix: config.KeyPrefix,
+		limit:     limit,
+		window:    window,
+		instance:  hex.EncodeToString(instance),
+	}, nil
+}
+
+// Name 返回限流器名称
+func (t *RedisThrottler) Name() string {
+	return BackendRedis
+}
+
+// Allow 判断 key 本次是否允许推送
+func (t *RedisThrottler) Allow(ctx context.Context, key string) (*push_service.ThrottleDecision, error) {
+	now := time.Now().UnixMilli()
+	member := fmt.Sprintf("%d-%s-%d", now, t.instance, t.seq.Add(1))
+
+	values, err := slidingWindowScript.Run(ctx, t.client, []string{t.keyPrefix + key},
+		now, t.window.Milliseconds(), t.limit, member).Int64Slice()
+	if err != nil {
+		return nil, fmt.Errorf("执行 Redis 限流脚本失败: %w", err)
+	}
+	if len(values) != 3 {
+		return nil, fmt.Errorf("Redis 限流脚本返回值异常: %v", values)
+	}
+
+	return &push_service.ThrottleDecision{
+		Allowed:    values[0] == 1,
+		Remaining:  int(values[1]),
+		RetryAfter: time.Duration(values[2]) * time.Millisecond,
+	}, nil
+}
+
+// Close 关闭 Redis 连接
+func (t *RedisThrottler) Close() error {
+	return t.client.Close()
+}
@@ -0,0 +1,17 @@
+//go:build noredis
+
+package throttle_service
+
+import (
+	"fmt"
+	"push-base-service/service/push_service"
+	"time"
+)
+
+// RedisEnabled 当前构建是否包含 Redis 支持（使用 noredis 构建标签时为 false）
+const RedisEnabled = false
+
+// newRedisThrottler 当前构建未包含 Redis 支持
+func newRedisThrottler(config *RedisConfig, limit int, window time.Duration) (push_service.Throttler, error) {
+	return nil, fmt.Errorf("当前构建未包含 Redis 支持（noredis），无法使用 redis 限流后端")
+}
@@ -0,0 +1,30 @@
+package throttle_service
+
+import (
+	"fmt"
+	"push-base-service/service/pebble_service"
+	"push-base-service/service/push_service"
+)
+
+// NewThrottler 根据配置创建限流器，未启用时返回 nil
+func NewThrottler(config *Config) (push_service.Throttler, error) {
+	if config == nil || !config.Enabled {
+		return nil, nil
+	}
+	config.ApplyDefaults()
+
+	switch config.Backend {
+	case BackendMemory:
+		return NewMemoryThrottler(config.Limit, config.Window), nil
+	case BackendPebble:
+		throttler := pebble_service.NewGlobalPebbleThrottler(config.Limit, config.Window)
+		if throttler == nil {
+			return nil, fmt.Errorf("无法创建 Pebble 限流器，全局服务未正确初始化")
+		}
+		return throttler, nil
+	case BackendRedis:
+		return newRedisThrottler(&config.Redis, config.Limit, config.Window)
+	default:
+		return nil, fmt.Errorf("不支持的限流后端: %s", config.Backend)
+	}
+}
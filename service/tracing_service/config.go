@@ -0,0 +1,37 @@
+package tracing_service
+
+// Config OpenTelemetry 链路追踪配置：通过 OTLP/HTTP 导出到 Jaeger、OpenTelemetry Collector 等
+type Config struct {
+	Enabled       bool              `yaml:"enabled" json:"enabled"`               // 是否启用
+	Endpoint      string            `yaml:"endpoint" json:"endpoint"`             // OTLP/HTTP 接收地址（host:port），Jaeger 默认 4318 端口
+	URLPath       string            `yaml:"url_path" json:"url_path"`             // 接收路径，为空时使用 /v1/traces
+	Insecure      bool              `yaml:"insecure" json:"insecure"`             // 使用 HTTP 而不是 HTTPS
+	Headers       map[string]string `yaml:"headers" json:"headers"`               // 额外的请求头（如鉴权）
+	ServiceName   string            `yaml:"service_name" json:"service_name"`     // 上报的服务名
+	SampleRate    float64           `yaml:"sample_rate" json:"sample_rate"`       // 采样比例（0~1）
+	InjectPayload bool              `yaml:"inject_payload" json:"inject_payload"` // 是否在推送的自定义数据中携带 traceId
+}
+
+// DefaultConfig 返回默认配置
+func DefaultConfig() *Config {
+	return &Config{
+		Endpoint:    "localhost:4318",
+		ServiceName: "push-base-service",
+		SampleRate:  1,
+	}
+}
+
+// ApplyDefaults 为缺失的配置项填充默认值
+func (c *Config) ApplyDefaults() {
+	defaults := DefaultConfig()
+
+	if c.Endpoint == "" {
+		c.Endpoint = defaults.Endpoint
+	}
+	if c.ServiceName == "" {
+		c.ServiceName = defaults.ServiceName
+	}
+	if c.SampleRate <= 0 || c.SampleRate > 1 {
+		c.SampleRate = defaults.SampleRate
+	}
+}
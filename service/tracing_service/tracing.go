@@ -0,0 +1,97 @@
+package tracing_service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName 本服务创建的 span 所属的 instrumentation scope
+const instrumentationName = "push-base-service"
+
+// PayloadKey 推送自定义数据中携带 traceId 的字段名
+const PayloadKey = "traceId"
+
+// injectPayload 是否在推送的自定义数据中携带 traceId
+var injectPayload atomic.Bool
+
+// Init 按配置创建 OTLP 导出器并设置为全局 TracerProvider，返回关闭函数（导出剩余的 span）；
+// 未启用时保持 OpenTelemetry 默认的空实现，Start 创建的 span 不记录也不导出
+func Init(config *Config) (func(context.Context) error, error) {
+	noop := func(context.Context) error { return nil }
+	if config == nil || !config.Enabled {
+		return noop, nil
+	}
+	config.ApplyDefaults()
+
+	options := []otlptracehttp.Option{otlptracehttp.WithEndpoint(config.Endpoint)}
+	if config.URLPath != "" {
+		options = append(options, otlptracehttp.WithURLPath(config.URLPath))
+	}
+	if config.Insecure {
+		options = append(options, otlptracehttp.WithInsecure())
+	}
+	if len(config.Headers) > 0 {
+		options = append(options, otlptracehttp.WithHeaders(config.Headers))
+	}
+	exporter, err := otlptracehttp.New(context.Background(), options...)
+	if err != nil {
+		return noop, fmt.Errorf("创建 OTLP 导出器失败: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.SampleRate))),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(config.ServiceName))),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	injectPayload.Store(config.InjectPayload)
+
+	log.Printf("🧵 链路追踪已启用: endpoint=%s, service=%s, 采样比例=%v", config.Endpoint, config.ServiceName, config.SampleRate)
+	return provider.Shutdown, nil
+}
+
+// Start 创建 span，ctx 中已有 span 时作为其子 span
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// RecordError 在 span 上记录错误并标记为失败，err 为 nil 时不做处理
+func RecordError(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
+
+// TraceID 返回 ctx 中 span 的 traceId，未启用追踪或未被采样时返回空
+func TraceID(ctx context.Context) string {
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.IsSampled() {
+		return ""
+	}
+	return spanContext.TraceID().String()
+}
+
+// PayloadEnabled 是否在推送的自定义数据中携带 traceId
+func PayloadEnabled() bool {
+	return injectPayload.Load()
+}
+
+// SetPayloadEnabled 设置是否在推送的自定义数据中携带 traceId（Init 时按配置设置）
+func SetPayloadEnabled(enabled bool) {
+	injectPayload.Store(enabled)
+}
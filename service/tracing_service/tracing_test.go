@@ -0,0 +1,49 @@
+package tracing_service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestStartAndTraceID(t *testing.T) {
+	// 未启用时 span 不被采样，TraceID 为空
+	if _, err := Init(&Config{}); err != nil {
+		t.Fatalf("Init(disabled) failed: %v", err)
+	}
+	ctx, span := Start(context.Background(), "noop")
+	span.End()
+	if id := TraceID(ctx); id != "" {
+		t.Errorf("TraceID without tracing = %q, want empty", id)
+	}
+
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	ctx, parent := Start(context.Background(), "parent")
+	_, child := Start(ctx, "child")
+	RecordError(child, errors.New("boom"))
+	child.End()
+	parent.End()
+
+	if id := TraceID(ctx); id != parent.SpanContext().TraceID().String() {
+		t.Errorf("TraceID = %q, want %q", id, parent.SpanContext().TraceID())
+	}
+	spans := recorder.Ended()
+	if len(spans) != 2 || spans[0].Name() != "child" {
+		t.Fatalf("ended spans = %d, want child then parent", len(spans))
+	}
+	if spans[0].Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Errorf("child span is not a child of parent")
+	}
+	if spans[0].Status().Code != codes.Error || len(spans[0].Events()) == 0 {
+		t.Errorf("child status = %+v, want error with recorded event", spans[0].Status())
+	}
+}
@@ -0,0 +1,42 @@
+package translate_service
+
+import "time"
+
+// 翻译服务提供者类型
+const (
+	ProviderLibreTranslate = "libretranslate" // LibreTranslate 兼容接口
+)
+
+// Config 消息预览翻译配置
+type Config struct {
+	Enabled  bool          `yaml:"enabled" json:"enabled"`     // 是否启用预览翻译（功能开关）
+	Provider string        `yaml:"provider" json:"provider"`   // 翻译服务提供者
+	Endpoint string        `yaml:"endpoint" json:"endpoint"`   // 翻译接口地址，如 https://libretranslate.example.com/translate
+	APIKey   string        `yaml:"api_key" json:"api_key"`     // 翻译接口密钥
+	Timeout  time.Duration `yaml:"timeout" json:"timeout"`     // 单次翻译超时
+	CacheTTL time.Duration `yaml:"cache_ttl" json:"cache_ttl"` // 翻译结果缓存时长
+}
+
+// DefaultConfig 返回默认配置
+func DefaultConfig() *Config {
+	return &Config{
+		Provider: ProviderLibreTranslate,
+		Timeout:  3 * time.Second,
+		CacheTTL: 24 * time.Hour,
+	}
+}
+
+// ApplyDefaults 为缺失的配置项填充默认值
+func (c *Config) ApplyDefaults() {
+	defaults := DefaultConfig()
+
+	if c.Provider == "" {
+		c.Provider = defaults.Provider
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = defaults.Timeout
+	}
+	if c.CacheTTL <= 0 {
+		c.CacheTTL = defaults.CacheTTL
+	}
+}
@@ -0,0 +1,132 @@
+package translate_service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"push-base-service/models"
+	"push-base-service/service/metrics_service"
+	"push-base-service/service/pebble_service"
+	"time"
+)
+
+var translationsCounter = metrics_service.NewCounterVec(
+	"push_translations_total", "Number of preview translations by provider and result (cached, translated, failed)",
+	"provider", "result")
+
+// Cache 翻译结果缓存，按 (消息, 语言) 存储
+type Cache interface {
+	GetCachedTranslation(messageId, locale string) (*models.CachedTranslation, error)
+	SaveCachedTranslation(translation *models.CachedTranslation) error
+}
+
+// Service 消息预览翻译服务
+type Service struct {
+	config     *Config
+	translator Translator
+	cache      Cache
+	now        func() time.Time
+}
+
+// NewService 创建翻译服务，cache 为 nil 时不缓存翻译结果
+func NewService(config *Config, translator Translator, cache Cache) *Service {
+	if config == nil {
+		config = DefaultConfig()
+	}
+	config.ApplyDefaults()
+
+	return &Service{
+		config:     config,
+		translator: translator,
+		cache:      cache,
+		now:        time.Now,
+	}
+}
+
+// Translate 将预览文本翻译为目标语言，优先使用缓存
+// messageId 为空时使用文本哈希作为缓存键
+func (s *Service) Translate(ctx context.Context, messageId, text, locale string) (string, error) {
+	locale = NormalizeLocale(locale)
+	if text == "" || locale == "" {
+		return "", fmt.Errorf("翻译文本和目标语言不能为空")
+	}
+	if messageId == "" {
+		sum := sha256.Sum256([]byte(text))
+		messageId = "text:" + hex.EncodeToString(sum[:])
+	}
+
+	provider := s.translator.Name()
+
+	if s.cache != nil {
+		cached, err := s.cache.GetCachedTranslation(messageId, locale)
+		if err != nil {
+			log.Printf("⚠️ 读取翻译缓存失败: %v", err)
+		} else if cached != nil {
+			translationsCounter.Inc(provider, "cached")
+			return cached.Text, nil
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.config.Timeout)
+	defer cancel()
+
+	translated, err := s.translator.Translate(ctx, text, locale)
+	if err != nil {
+		translationsCounter.Inc(provider, "failed")
+		return "", fmt.Errorf("翻译消息预览失败: %w", err)
+	}
+	translationsCounter.Inc(provider, "translated")
+
+	if s.cache != nil {
+		now := s.now()
+		if err := s.cache.SaveCachedTranslation(&models.CachedTranslation{
+			MessageID: messageId,
+			Locale:    locale,
+			Text:      translated,
+			Provider:  provider,
+			CreatedAt: now.Unix(),
+			ExpiresAt: now.Add(s.config.CacheTTL).Unix(),
+		}); err != nil {
+			log.Printf("⚠️ 保存翻译缓存失败: %v", err)
+		}
+	}
+
+	return translated, nil
+}
+
+// 全局翻译服务实例
+var globalService *Service
+
+// InitializeGlobalService 根据配置初始化全局翻译服务，未启用时返回 nil
+func InitializeGlobalService(config *Config) (*Service, error) {
+	if config == nil || !config.Enabled {
+		return nil, nil
+	}
+	if globalService != nil {
+		log.Printf("⚠️ 全局翻译服务已存在，跳过重复初始化")
+		return globalService, nil
+	}
+	config.ApplyDefaults()
+
+	translator, err := NewTranslator(config)
+	if err != nil {
+		return nil, err
+	}
+
+	var cache Cache
+	if service := pebble_service.GetGlobalService(); service != nil && service.IsInitialized() {
+		cache = service
+	} else {
+		log.Printf("⚠️ 全局 Pebble 服务未初始化，翻译结果将不会缓存")
+	}
+
+	globalService = NewService(config, translator, cache)
+	return globalService, nil
+}
+
+// GetGlobalService 获取全局翻译服务（未启用时返回 nil）
+func GetGlobalService() *Service {
+	return globalService
+}
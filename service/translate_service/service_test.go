@@ -0,0 +1,118 @@
+package translate_service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"push-base-service/models"
+	"testing"
+	"time"
+)
+
+// fakeTranslator 记录调用次数的测试翻译器
+type fakeTranslator struct {
+	calls int
+	err   error
+}
+
+func (f *fakeTranslator) Name() string { return "fake" }
+
+func (f *fakeTranslator) Translate(ctx context.Context, text, locale string) (string, error) {
+	f.calls++
+	if f.err != nil {
+		return "", f.err
+	}
+	return "[" + locale + "] " + text, nil
+}
+
+// memoryCache 内存翻译缓存
+type memoryCache map[string]*models.CachedTranslation
+
+func (m memoryCache) GetCachedTranslation(messageId, locale string) (*models.CachedTranslation, error) {
+	return m[messageId+":"+locale], nil
+}
+
+func (m memoryCache) SaveCachedTranslation(translation *models.CachedTranslation) error {
+	m[translation.MessageID+":"+translation.Locale] = translation
+	return nil
+}
+
+func TestServiceTranslateCachesPerMessageAndLocale(t *testing.T) {
+	translator := &fakeTranslator{}
+	cache := memoryCache{}
+	service := NewService(&Config{CacheTTL: time.Hour}, translator, cache)
+	ctx := context.Background()
+
+	text, err := service.Translate(ctx, "pin-1", "hello", "zh_CN")
+	if err != nil || text != "[zh-cn] hello" {
+		t.Fatalf("Translate() = %q, %v; want %q", text, err, "[zh-cn] hello")
+	}
+	if _, err := service.Translate(ctx, "pin-1", "hello", "zh-CN"); err != nil {
+		t.Fatalf("Translate() second call failed, err: %v", err)
+	}
+	if translator.calls != 1 {
+		t.Errorf("translator called %d times, want 1 (second call should hit cache)", translator.calls)
+	}
+
+	// 不同语言、不同消息各自翻译
+	service.Translate(ctx, "pin-1", "hello", "ja")
+	service.Translate(ctx, "pin-2", "hello", "ja")
+	if translator.calls != 3 {
+		t.Errorf("translator called %d times, want 3", translator.calls)
+	}
+
+	// 没有消息ID时按文本哈希缓存
+	service.Translate(ctx, "", "hi", "ja")
+	service.Translate(ctx, "", "hi", "ja")
+	if translator.calls != 4 {
+		t.Errorf("translator called %d times, want 4", translator.calls)
+	}
+
+	cached := cache["pin-1:zh-cn"]
+	if cached == nil || cached.Provider != "fake" || cached.ExpiresAt-cached.CreatedAt != int64(time.Hour/time.Second) {
+		t.Errorf("cached translation = %+v, want provider fake with 1h TTL", cached)
+	}
+}
+
+func TestServiceTranslateErrorNotCached(t *testing.T) {
+	translator := &fakeTranslator{err: errors.New("quota exceeded")}
+	cache := memoryCache{}
+	service := NewService(nil, translator, cache)
+
+	if _, err := service.Translate(context.Background(), "pin-1", "hello", "de"); err == nil {
+		t.Fatal("Translate() should fail when translator fails")
+	}
+	if len(cache) != 0 {
+		t.Errorf("cache has %d entries after failure, want 0", len(cache))
+	}
+}
+
+func TestLibreTranslator(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req libreTranslateRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode request failed, err: %v", err)
+		}
+		if req.Target != "zh" || req.Source != "auto" || req.APIKey != "secret" {
+			t.Errorf("request = %+v, want target zh, source auto, api_key secret", req)
+		}
+		if req.Q == "fail" {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(libreTranslateResponse{Error: "bad request"})
+			return
+		}
+		json.NewEncoder(w).Encode(libreTranslateResponse{TranslatedText: "你好"})
+	}))
+	defer server.Close()
+
+	translator := NewLibreTranslator(server.URL, "secret", time.Second)
+	text, err := translator.Translate(context.Background(), "hello", "zh-CN")
+	if err != nil || text != "你好" {
+		t.Fatalf("Translate() = %q, %v; want 你好", text, err)
+	}
+	if _, err := translator.Translate(context.Background(), "fail", "zh-CN"); err == nil {
+		t.Error("Translate() should fail on HTTP 400")
+	}
+}
@@ -0,0 +1,126 @@
+package translate_service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Translator 翻译接口，不同翻译服务实现该接口即可接入
+type Translator interface {
+	// Name 返回翻译服务名称
+	Name() string
+	// Translate 将文本翻译为目标语言
+	Translate(ctx context.Context, text, locale string) (string, error)
+}
+
+// NewTranslator 根据配置创建翻译器
+func NewTranslator(config *Config) (Translator, error) {
+	switch config.Provider {
+	case ProviderLibreTranslate:
+		if config.Endpoint == "" {
+			return nil, fmt.Errorf("翻译接口地址不能为空")
+		}
+		return NewLibreTranslator(config.Endpoint, config.APIKey, config.Timeout), nil
+	default:
+		return nil, fmt.Errorf("不支持的翻译服务: %s", config.Provider)
+	}
+}
+
+// LibreTranslator LibreTranslate 兼容接口的翻译器
+type LibreTranslator struct {
+	endpoint string
+	apiKey   string
+	client   *http.Client
+}
+
+// NewLibreTranslator 创建 LibreTranslate 翻译器
+func NewLibreTranslator(endpoint, apiKey string, timeout time.Duration) *LibreTranslator {
+	return &LibreTranslator{
+		endpoint: endpoint,
+		apiKey:   apiKey,
+		client:   &http.Client{Timeout: timeout},
+	}
+}
+
+// libreTranslateRequest LibreTranslate 请求体
+type libreTranslateRequest struct {
+	Q      string `json:"q"`
+	Source string `json:"source"`
+	Target string `json:"target"`
+	Format string `json:"format"`
+	APIKey string `json:"api_key,omitempty"`
+}
+
+// libreTranslateResponse LibreTranslate 响应体
+type libreTranslateResponse struct {
+	TranslatedText string `json:"translatedText"`
+	Error          string `json:"error"`
+}
+
+// Name 返回翻译服务名称
+func (lt *LibreTranslator) Name() string {
+	return ProviderLibreTranslate
+}
+
+// Translate 调用 LibreTranslate 接口翻译文本
+func (lt *LibreTranslator) Translate(ctx context.Context, text, locale string) (string, error) {
+	body, err := json.Marshal(libreTranslateRequest{
+		Q:      text,
+		Source: "auto",
+		Target: baseLanguage(locale),
+		Format: "text",
+		APIKey: lt.apiKey,
+	})
+	if err != nil {
+		return "", fmt.Errorf("序列化翻译请求失败: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, lt.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("创建翻译请求失败: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := lt.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("请求翻译接口失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", fmt.Errorf("读取翻译响应失败: %w", err)
+	}
+
+	var result libreTranslateResponse
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return "", fmt.Errorf("解析翻译响应失败 (HTTP %d): %w", resp.StatusCode, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("翻译接口返回错误 (HTTP %d): %s", resp.StatusCode, result.Error)
+	}
+	if result.TranslatedText == "" {
+		return "", fmt.Errorf("翻译接口返回空结果")
+	}
+	return result.TranslatedText, nil
+}
+
+// NormalizeLocale 规范化语言区域，如 "zh_CN" -> "zh-cn"
+func NormalizeLocale(locale string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"))
+}
+
+// baseLanguage 返回语言区域的主语言部分，如 "zh-cn" -> "zh"
+func baseLanguage(locale string) string {
+	locale = NormalizeLocale(locale)
+	if idx := strings.Index(locale, "-"); idx > 0 {
+		return locale[:idx]
+	}
+	return locale
+}
@@ -0,0 +1,46 @@
+package webhook_service
+
+import "time"
+
+// Config 投递事件 Webhook 配置
+type Config struct {
+	Enabled    bool          `yaml:"enabled" json:"enabled"`         // 是否启用
+	Timeout    time.Duration `yaml:"timeout" json:"timeout"`         // 单次回调超时
+	MaxRetries int           `yaml:"max_retries" json:"max_retries"` // 最大重试次数
+	BaseDelay  time.Duration `yaml:"base_delay" json:"base_delay"`   // 指数退避基础延迟
+	QueueSize  int           `yaml:"queue_size" json:"queue_size"`   // 事件队列长度
+	Workers    int           `yaml:"workers" json:"workers"`         // 并发投递协程数
+}
+
+// DefaultConfig 返回默认配置
+func DefaultConfig() *Config {
+	return &Config{
+		Enabled:    true,
+		Timeout:    10 * time.Second,
+		MaxRetries: 3,
+		BaseDelay:  2 * time.Second,
+		QueueSize:  1000,
+		Workers:    4,
+	}
+}
+
+// ApplyDefaults 为缺失的配置项填充默认值
+func (c *Config) ApplyDefaults() {
+	defaults := DefaultConfig()
+
+	if c.Timeout <= 0 {
+		c.Timeout = defaults.Timeout
+	}
+	if c.MaxRetries < 0 {
+		c.MaxRetries = 0
+	}
+	if c.BaseDelay <= 0 {
+		c.BaseDelay = defaults.BaseDelay
+	}
+	if c.QueueSize <= 0 {
+		c.QueueSize = defaults.QueueSize
+	}
+	if c.Workers <= 0 {
+		c.Workers = defaults.Workers
+	}
+}
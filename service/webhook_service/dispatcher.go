@@ -0,0 +1,311 @@
+package webhook_service
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"push-base-service/service/pebble_service"
+	"push-base-service/service/push_service"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// 投递事件类型
+const (
+	EventPushDelivered = "push.delivered"
+	EventPushFailed    = "push.failed"
+)
+
+// 回调请求头
+const (
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+	HeaderEvent     = "X-Webhook-Event"
+)
+
+// DeliveryEvent 推送投递事件（回调给租户的内容）
+type DeliveryEvent struct {
+	Event     string `json:"event"`               // 事件类型
+	TenantID  string `json:"tenantId"`            // 租户ID
+	MetaID    string `json:"metaId"`              // 用户MetaID
+	Platform  string `json:"platform"`            // 推送平台
+	Success   bool   `json:"success"`             // 是否成功
+	ReceiptID string `json:"receiptId,omitempty"` // 回执ID
+	Error     string `json:"error,omitempty"`     // 错误信息
+	PinID     string `json:"pinId,omitempty"`     // 关联的PIN ID
+	Simulated bool   `json:"simulated,omitempty"` // 演练模式下的模拟推送，未实际发送到设备
+	Timestamp int64  `json:"timestamp"`           // 事件时间
+}
+
+// TenantStats 租户 Webhook 投递统计
+type TenantStats struct {
+	TenantID            string `json:"tenantId"`            // 租户ID
+	Delivered           int64  `json:"delivered"`           // 投递成功次数
+	Failed              int64  `json:"failed"`              // 最终投递失败次数
+	Retried             int64  `json:"retried"`             // 重试次数
+	Dropped             int64  `json:"dropped"`             // 队列已满丢弃次数
+	ConsecutiveFailures int64  `json:"consecutiveFailures"` // 连续失败次数
+	LastError           string `json:"lastError,omitempty"` // 最近一次错误
+	LastFailureAt       int64  `json:"lastFailureAt"`       // 最近失败时间
+	LastSuccessAt       int64  `json:"lastSuccessAt"`       // 最近成功时间
+}
+
+// Dispatcher 租户投递事件分发器
+type Dispatcher struct {
+	config     *Config
+	httpClient *http.Client
+	queue      chan *DeliveryEvent
+	stats      map[string]*TenantStats
+	statsMu    sync.RWMutex
+	stopCh     chan struct{}
+	wg         sync.WaitGroup
+	running    bool
+	mu         sync.Mutex
+}
+
+// NewDispatcher 创建分发器
+func NewDispatcher(config *Config) *Dispatcher {
+	if config == nil {
+		config = DefaultConfig()
+	}
+	config.ApplyDefaults()
+
+	return &Dispatcher{
+		config:     config,
+		httpClient: &http.Client{Timeout: config.Timeout},
+		queue:      make(chan *DeliveryEvent, config.QueueSize),
+		stats:      make(map[string]*TenantStats),
+		stopCh:     make(chan struct{}),
+	}
+}
+
+// Start 启动投递协程
+func (d *Dispatcher) Start() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.running {
+		return
+	}
+
+	for i := 0; i < d.config.Workers; i++ {
+		d.wg.Add(1)
+		go d.worker()
+	}
+
+	d.running = true
+	log.Printf("✅ 租户Webhook分发器已启动: workers=%d", d.config.Workers)
+}
+
+// Stop 停止投递协程（队列中未投递的事件将被丢弃）
+func (d *Dispatcher) Stop() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if !d.running {
+		return
+	}
+
+	close(d.stopCh)
+	d.wg.Wait()
+	d.running = false
+	log.Printf("🛑 租户Webhook分发器已停止")
+}
+
+// HandleResult 推送结果监听器，只处理带租户ID的结果
+func (d *Dispatcher) HandleResult(notification *push_service.PushNotification, result *push_service.PushResult) {
+	if result == nil || result.TenantID == "" {
+		return
+	}
+
+	event := &DeliveryEvent{
+		Event:     EventPushDelivered,
+		TenantID:  result.TenantID,
+		MetaID:    result.MetaID,
+		Platform:  result.Platform,
+		Success:   result.Success,
+		ReceiptID: result.ReceiptID,
+		Simulated: result.Simulated,
+		Timestamp: result.Timestamp.Unix(),
+	}
+	if !result.Success {
+		event.Event = EventPushFailed
+	}
+	if result.Error != nil {
+		event.Error = result.Error.Error()
+	}
+	if notification != nil && notification.Data != nil {
+		if pinId, ok := notification.Data["pinId"].(string); ok {
+			event.PinID = pinId
+		}
+	}
+
+	select {
+	case d.queue <- event:
+	default:
+		d.updateStats(event.TenantID, func(s *TenantStats) {
+			s.Dropped++
+		})
+		log.Printf("⚠️ 租户Webhook队列已满，丢弃事件: TenantID=%s, MetaID=%s", event.TenantID, event.MetaID)
+	}
+}
+
+// worker 投递协程
+func (d *Dispatcher) worker() {
+	defer d.wg.Done()
+
+	for {
+		select {
+		case <-d.stopCh:
+			return
+		case event := <-d.queue:
+			d.deliver(event)
+		}
+	}
+}
+
+// deliver 投递单个事件（带指数退避重试）
+func (d *Dispatcher) deliver(event *DeliveryEvent) {
+	webhook, err := pebble_service.GetTenantWebhook(event.TenantID)
+	if err != nil {
+		log.Printf("⚠️ 获取租户 %s 的Webhook配置失败: %v", event.TenantID, err)
+		return
+	}
+	if webhook == nil || !webhook.Enabled {
+		return
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("❌ 序列化投递事件失败: %v", err)
+		return
+	}
+
+	var lastErr error
+	for attempt := 0; attempt <= d.config.MaxRetries; attempt++ {
+		if attempt > 0 {
+			d.updateStats(event.TenantID, func(s *TenantStats) {
+				s.Retried++
+			})
+
+			delay := d.config.BaseDelay * time.Duration(1<<(attempt-1))
+			select {
+			case <-d.stopCh:
+				return
+			case <-time.After(delay):
+			}
+		}
+
+		lastErr = d.post(webhook.URL, webhook.Secret, event.Event, body)
+		if lastErr == nil {
+			d.updateStats(event.TenantID, func(s *TenantStats) {
+				s.Delivered++
+				s.ConsecutiveFailures = 0
+				s.LastSuccessAt = time.Now().Unix()
+			})
+			return
+		}
+	}
+
+	d.updateStats(event.TenantID, func(s *TenantStats) {
+		s.Failed++
+		s.ConsecutiveFailures++
+		s.LastError = lastErr.Error()
+		s.LastFailureAt = time.Now().Unix()
+	})
+	log.Printf("❌ 租户 %s Webhook投递失败: %v", event.TenantID, lastErr)
+}
+
+// post 发送签名后的回调请求
+func (d *Dispatcher) post(url, secret, eventType string, body []byte) error {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("创建请求失败: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, eventType)
+	req.Header.Set(HeaderTimestamp, timestamp)
+	if secret != "" {
+		req.Header.Set(HeaderSignature, Sign(secret, timestamp, body))
+	}
+
+	resp, err := d.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("发送请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("回调返回状态码 %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Sign 计算回调签名：sha256=hex(HMAC-SHA256(secret, timestamp + "." + body))
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// updateStats 更新租户统计
+func (d *Dispatcher) updateStats(tenantId string, update func(s *TenantStats)) {
+	d.statsMu.Lock()
+	defer d.statsMu.Unlock()
+
+	stats, exists := d.stats[tenantId]
+	if !exists {
+		stats = &TenantStats{TenantID: tenantId}
+		d.stats[tenantId] = stats
+	}
+	update(stats)
+}
+
+// GetStats 获取所有租户的投递统计
+func (d *Dispatcher) GetStats() map[string]*TenantStats {
+	d.statsMu.RLock()
+	defer d.statsMu.RUnlock()
+
+	result := make(map[string]*TenantStats, len(d.stats))
+	for tenantId, stats := range d.stats {
+		statsCopy := *stats
+		result[tenantId] = &statsCopy
+	}
+	return result
+}
+
+// QueueLength 当前队列中待投递的事件数
+func (d *Dispatcher) QueueLength() int {
+	return len(d.queue)
+}
+
+// 全局分发器实例
+var globalDispatcher *Dispatcher
+
+// InitializeGlobalDispatcher 初始化全局分发器
+func InitializeGlobalDispatcher(config *Config) *Dispatcher {
+	if globalDispatcher != nil {
+		log.Printf("⚠️ 全局租户Webhook分发器已存在，跳过重复初始化")
+		return globalDispatcher
+	}
+
+	globalDispatcher = NewDispatcher(config)
+	return globalDispatcher
+}
+
+// GetGlobalDispatcher 获取全局分发器（未启用时返回 nil）
+func GetGlobalDispatcher() *Dispatcher {
+	return globalDispatcher
+}
@@ -0,0 +1,51 @@
+package webhook_service
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSign(t *testing.T) {
+	got := Sign("secret", "1700000000", []byte(`{"event":"push.delivered"}`))
+	// 固定向量：HMAC-SHA256("secret", `1700000000.{"event":"push.delivered"}`)，接收方按文档自行实现时应得到相同结果
+	if want := "sha256=d53c2543cb00ba57f1a559d37ec8cc2b1cdca5d85d0d3bf52d985b01bc2827a6"; got != want {
+		t.Errorf("Sign() = %s, want %s", got, want)
+	}
+	if got != Sign("secret", "1700000000", []byte(`{"event":"push.delivered"}`)) {
+		t.Errorf("Sign() should be deterministic")
+	}
+	if got == Sign("other", "1700000000", []byte(`{"event":"push.delivered"}`)) {
+		t.Errorf("Sign() should depend on secret")
+	}
+	if len(got) != len("sha256=")+64 {
+		t.Errorf("Sign() unexpected length: %s", got)
+	}
+}
+
+func TestPostSignature(t *testing.T) {
+	body := []byte(`{"event":"push.failed"}`)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received, _ := io.ReadAll(r.Body)
+		expected := Sign("secret", r.Header.Get(HeaderTimestamp), received)
+		if r.Header.Get(HeaderSignature) != expected {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.Header.Get(HeaderEvent) != EventPushFailed {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	d := NewDispatcher(DefaultConfig())
+	if err := d.post(server.URL, "secret", EventPushFailed, body); err != nil {
+		t.Errorf("post() failed, err: %v", err)
+	}
+	if err := d.post(server.URL, "wrong", EventPushFailed, body); err == nil {
+		t.Errorf("post() with wrong secret should fail")
+	}
+}
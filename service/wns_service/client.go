@@ -0,0 +1,206 @@
+package wns_service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"push-base-service/service/egress_service"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 通知类型
+const (
+	TypeToast = "wns/toast" // 展示通知（Toast XML）
+	TypeRaw   = "wns/raw"   // 应用自行处理的原始数据
+)
+
+// Request 一次 WNS 推送请求
+type Request struct {
+	ChannelURI string // 设备的通道 URI（即推送令牌）
+	Type       string // 通知类型：wns/toast / wns/raw
+	Payload    []byte // Toast XML 或原始数据
+	TTL        int    // 有效期（秒），0 表示不过期
+	Tag        string // 标签（最多 16 个字符），相同标签和分组的通知相互替换
+	Group      string // 分组
+}
+
+// Error WNS 拒绝推送时返回的错误
+type Error struct {
+	StatusCode int    // HTTP 状态码
+	Reason     string // X-WNS-Error-Description 或 X-WNS-Notificationstatus
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("WNS 推送失败: HTTP %d %s", e.StatusCode, e.Reason)
+}
+
+// IsUnregistered 通道 URI 是否已失效
+func (e *Error) IsUnregistered() bool {
+	return e.StatusCode == http.StatusNotFound || e.StatusCode == http.StatusGone
+}
+
+// Client WNS 客户端，按 OAuth 客户端凭据获取访问令牌
+type Client struct {
+	config *Config
+	client *http.Client
+	now    func() time.Time
+
+	mu          sync.Mutex
+	accessToken string
+	expiresAt   time.Time
+}
+
+// NewClient 创建 WNS 客户端
+func NewClient(config *Config) (*Client, error) {
+	if config == nil {
+		return nil, fmt.Errorf("WNS 配置不能为空")
+	}
+	config.ApplyDefaults()
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	transport, err := egress_service.NewTransport(config.Egress)
+	if err != nil {
+		return nil, fmt.Errorf("创建 WNS 出站连接失败: %w", err)
+	}
+
+	return &Client{
+		config: config,
+		client: &http.Client{Timeout: config.Timeout, Transport: transport},
+		now:    time.Now,
+	}, nil
+}
+
+// Send 发送推送，访问令牌过期时刷新后重试一次，成功时返回 X-WNS-Msg-ID
+func (c *Client) Send(ctx context.Context, request *Request) (string, error) {
+	msgID, err := c.send(ctx, request)
+	if wnsErr, ok := err.(*Error); ok && wnsErr.StatusCode == http.StatusUnauthorized {
+		c.resetAccessToken()
+		msgID, err = c.send(ctx, request)
+	}
+	return msgID, err
+}
+
+// send 发送一次推送
+func (c *Client) send(ctx context.Context, request *Request) (string, error) {
+	accessToken, err := c.currentAccessToken(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, request.ChannelURI, bytes.NewReader(request.Payload))
+	if err != nil {
+		return "", fmt.Errorf("创建 WNS 请求失败: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("X-WNS-Type", request.Type)
+	if request.Type == TypeRaw {
+		req.Header.Set("Content-Type", "application/octet-stream")
+	} else {
+		req.Header.Set("Content-Type", "text/xml")
+	}
+	if request.TTL > 0 {
+		req.Header.Set("X-WNS-TTL", strconv.Itoa(request.TTL))
+	}
+	if request.Tag != "" {
+		req.Header.Set("X-WNS-Tag", request.Tag)
+	}
+	if request.Group != "" {
+		req.Header.Set("X-WNS-Group", request.Group)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("发送 WNS 请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	if resp.StatusCode == http.StatusOK {
+		return resp.Header.Get("X-WNS-Msg-ID"), nil
+	}
+	reason := resp.Header.Get("X-WNS-Error-Description")
+	if reason == "" {
+		reason = resp.Header.Get("X-WNS-Notificationstatus")
+	}
+	return "", &Error{StatusCode: resp.StatusCode, Reason: reason}
+}
+
+// HealthCheck 检查能否获取访问令牌
+func (c *Client) HealthCheck(ctx context.Context) error {
+	_, err := c.currentAccessToken(ctx)
+	return err
+}
+
+// currentAccessToken 返回当前访问令牌，过期前 1 分钟重新获取
+func (c *Client) currentAccessToken(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.accessToken != "" && c.now().Before(c.expiresAt) {
+		return c.accessToken, nil
+	}
+
+	form := url.Values{
+		"grant_type":    {"client_credentials"},
+		"client_id":     {c.config.PackageSID},
+		"client_secret": {c.config.ClientSecret},
+		"scope":         {"notify.windows.com"},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.config.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("创建 WNS 令牌请求失败: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("获取 WNS 访问令牌失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if err != nil {
+		return "", fmt.Errorf("读取 WNS 令牌响应失败: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("获取 WNS 访问令牌失败: HTTP %d", resp.StatusCode)
+	}
+
+	var result struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil || result.AccessToken == "" {
+		return "", fmt.Errorf("解析 WNS 令牌响应失败")
+	}
+
+	c.accessToken = result.AccessToken
+	c.expiresAt = c.now().Add(time.Duration(result.ExpiresIn)*time.Second - time.Minute)
+	return c.accessToken, nil
+}
+
+// resetAccessToken WNS 返回 401 时丢弃缓存的访问令牌
+func (c *Client) resetAccessToken() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.accessToken = ""
+}
+
+// ValidateChannelURI 验证通道 URI（https，且为 WNS 通知域名）
+func ValidateChannelURI(channelURI string) bool {
+	parsed, err := url.Parse(channelURI)
+	if err != nil || parsed.Scheme != "https" {
+		return false
+	}
+	host := parsed.Hostname()
+	return host == "notify.windows.com" || strings.HasSuffix(host, ".notify.windows.com")
+}
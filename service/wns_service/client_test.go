@@ -0,0 +1,76 @@
+package wns_service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestClientSendRefreshesToken(t *testing.T) {
+	var tokenRequests int32
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.Form.Get("client_id") != "ms-app://sid" || r.Form.Get("scope") != "notify.windows.com" {
+			t.Errorf("unexpected token request: %v", r.Form)
+		}
+		n := atomic.AddInt32(&tokenRequests, 1)
+		fmt.Fprintf(w, `{"access_token":"token-%d","token_type":"bearer","expires_in":86400}`, n)
+	}))
+	defer tokenServer.Close()
+
+	var sends int32
+	channelServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/expired":
+			w.WriteHeader(http.StatusGone)
+			return
+		}
+		// 第一次推送模拟访问令牌过期
+		if atomic.AddInt32(&sends, 1) == 1 {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer token-2" || r.Header.Get("X-WNS-Type") != TypeToast || r.Header.Get("X-WNS-Tag") != "chat" {
+			t.Errorf("unexpected headers: %v", r.Header)
+		}
+		w.Header().Set("X-WNS-Msg-ID", "msg-1")
+	}))
+	defer channelServer.Close()
+
+	client, err := NewClient(&Config{PackageSID: "ms-app://sid", ClientSecret: "secret", TokenEndpoint: tokenServer.URL})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	id, err := client.Send(context.Background(), &Request{ChannelURI: channelServer.URL + "/channel", Type: TypeToast, Payload: []byte("<toast/>"), Tag: "chat"})
+	if err != nil || id != "msg-1" {
+		t.Fatalf("Send() = %q, %v", id, err)
+	}
+	if tokenRequests != 2 {
+		t.Errorf("token requested %d times, want 2", tokenRequests)
+	}
+
+	_, err = client.Send(context.Background(), &Request{ChannelURI: channelServer.URL + "/expired", Type: TypeToast})
+	var wnsErr *Error
+	if !errors.As(err, &wnsErr) || !wnsErr.IsUnregistered() {
+		t.Errorf("Send() to expired channel error = %v", err)
+	}
+}
+
+func TestValidateChannelURI(t *testing.T) {
+	cases := map[string]bool{
+		"https://db5p.notify.windows.com/?token=AwYAAAB": true,
+		"http://db5p.notify.windows.com/?token=AwYAAAB":  false,
+		"https://notify.windows.com.evil.com/?token=a":   false,
+		"ExponentPushToken[abc]":                         false,
+	}
+	for channelURI, want := range cases {
+		if got := ValidateChannelURI(channelURI); got != want {
+			t.Errorf("ValidateChannelURI(%q) = %v, want %v", channelURI, got, want)
+		}
+	}
+}
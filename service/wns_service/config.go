@@ -0,0 +1,50 @@
+package wns_service
+
+import (
+	"fmt"
+	"push-base-service/service/egress_service"
+	"time"
+)
+
+// DefaultTokenEndpoint WNS 访问令牌接口地址
+const DefaultTokenEndpoint = "https://login.live.com/accesstoken.srf"
+
+// Config Windows 推送通知服务（WNS）配置
+type Config struct {
+	PackageSID    string                 `yaml:"package_sid" json:"package_sid"`       // 应用包 SID（ms-app://...），作为 client_id
+	ClientSecret  string                 `yaml:"client_secret" json:"client_secret"`   // 应用密钥
+	TokenEndpoint string                 `yaml:"token_endpoint" json:"token_endpoint"` // 访问令牌接口地址，默认 DefaultTokenEndpoint
+	Timeout       time.Duration          `yaml:"timeout" json:"timeout"`               // 单次推送超时
+	Egress        *egress_service.Config `yaml:"egress" json:"egress"`                 // 出站代理和 TLS 配置，为空时使用默认传输层（沿用 HTTP_PROXY 等环境变量）
+}
+
+// DefaultConfig 返回默认配置
+func DefaultConfig() *Config {
+	return &Config{
+		TokenEndpoint: DefaultTokenEndpoint,
+		Timeout:       10 * time.Second,
+	}
+}
+
+// ApplyDefaults 为缺失的配置项填充默认值
+func (c *Config) ApplyDefaults() {
+	defaults := DefaultConfig()
+
+	if c.TokenEndpoint == "" {
+		c.TokenEndpoint = defaults.TokenEndpoint
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = defaults.Timeout
+	}
+}
+
+// Validate 校验配置
+func (c *Config) Validate() error {
+	if c.PackageSID == "" || c.ClientSecret == "" {
+		return fmt.Errorf("WNS 包 SID 和应用密钥不能为空")
+	}
+	if err := c.Egress.Validate(); err != nil {
+		return fmt.Errorf("WNS 出站连接配置无效: %w", err)
+	}
+	return nil
+}
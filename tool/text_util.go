@@ -0,0 +1,96 @@
+package tool
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// textEllipsis 截断文本时追加的省略号
+const textEllipsis = "..."
+
+// TextLength 返回文本的用户可见字符数：组合符号、变体选择符、emoji 肤色修饰符、
+// ZWJ 连接的 emoji 序列和国旗（成对的区域指示符）都只算一个字符
+func TextLength(text string) int {
+	count := 0
+	for len(text) > 0 {
+		text = text[graphemeLength(text):]
+		count++
+	}
+	return count
+}
+
+// TruncateText 将文本截取为最多 maxLength 个用户可见字符，超出时保留 maxLength-3 个字符并追加 "..."，
+// 不会截断多字节字符或 emoji 组合序列；maxLength <= 0 表示不限制
+func TruncateText(text string, maxLength int) string {
+	if maxLength <= 0 {
+		return text
+	}
+
+	keep := maxLength - len(textEllipsis)
+	if keep <= 0 {
+		// 长度太小放不下省略号时直接截取
+		keep = maxLength
+	}
+
+	offset, count, keepOffset := 0, 0, 0
+	for offset < len(text) {
+		if count == keep {
+			keepOffset = offset
+		}
+		if count == maxLength {
+			break
+		}
+		offset += graphemeLength(text[offset:])
+		count++
+	}
+	if offset >= len(text) {
+		return text
+	}
+	if keep == maxLength {
+		return text[:offset]
+	}
+	return strings.TrimRightFunc(text[:keepOffset], unicode.IsSpace) + textEllipsis
+}
+
+// graphemeLength 返回文本第一个用户可见字符（字形簇）的字节数
+func graphemeLength(text string) int {
+	r, size := utf8.DecodeRuneInString(text)
+	if r == '\r' && size < len(text) && text[size] == '\n' {
+		return size + 1
+	}
+
+	regional := isRegionalIndicator(r)
+	for size < len(text) {
+		next, n := utf8.DecodeRuneInString(text[size:])
+		switch {
+		case isGraphemeExtender(next):
+			size += n
+		case next == '\u200d':
+			// ZWJ 与其后的字符连成一个 emoji 序列
+			size += n
+			if size < len(text) {
+				_, joined := utf8.DecodeRuneInString(text[size:])
+				size += joined
+			}
+		case regional && isRegionalIndicator(next):
+			size += n
+			regional = false
+		default:
+			return size
+		}
+	}
+	return size
+}
+
+// isGraphemeExtender 是否为附加在前一字符上的码点：组合符号、变体选择符、emoji 肤色修饰符、标签字符
+func isGraphemeExtender(r rune) bool {
+	return unicode.In(r, unicode.Mn, unicode.Me, unicode.Mc) ||
+		(r >= 0x1f3fb && r <= 0x1f3ff) ||
+		(r >= 0xe0020 && r <= 0xe007f)
+}
+
+// isRegionalIndicator 是否为区域指示符（两个组成一面国旗）
+func isRegionalIndicator(r rune) bool {
+	return r >= 0x1f1e6 && r <= 0x1f1ff
+}
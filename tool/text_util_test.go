@@ -0,0 +1,54 @@
+package tool
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTextLength(t *testing.T) {
+	cases := map[string]int{
+		"":        0,
+		"hello":   5,
+		"中文名字":    4,
+		"👨‍👩‍👧‍👦": 1, // ZWJ 家庭
+		"👍🏽":      1, // 肤色修饰符
+		"🇨🇳🇯🇵":    2, // 两面国旗
+		"é":       1, // e + 组合重音符
+		"❤️":      1, // 变体选择符
+		"🏴󠁧󠁢󠁳󠁣󠁴󠁿": 1, // 标签序列旗帜
+		"a\r\nb":  3,
+		"1️⃣":     1, // 键帽
+	}
+	for text, want := range cases {
+		if got := TextLength(text); got != want {
+			t.Errorf("TextLength(%q) = %d, want %d", text, got, want)
+		}
+	}
+}
+
+func TestTruncateText(t *testing.T) {
+	cases := []struct {
+		text      string
+		maxLength int
+		want      string
+	}{
+		{"short", 10, "short"},
+		{"exactly10!", 10, "exactly10!"},
+		{"a_very_long_username", 10, "a_very_..."},
+		{"see you at eight", 11, "see you..."},
+		{"一个非常长的中文用户名字", 8, "一个非常长..."},
+		{strings.Repeat("👨‍👩‍👧‍👦", 6), 5, "👨‍👩‍👧‍👦👨‍👩‍👧‍👦..."},
+		{"🇨🇳🇯🇵🇺🇸🇬🇧", 3, "🇨🇳🇯🇵🇺🇸"},
+		{"anything", 0, "anything"},
+	}
+	for _, tc := range cases {
+		got := TruncateText(tc.text, tc.maxLength)
+		if got != tc.want {
+			t.Errorf("TruncateText(%q, %d) = %q, want %q", tc.text, tc.maxLength, got, tc.want)
+		}
+		if !utf8.ValidString(got) || (tc.maxLength > 0 && TextLength(got) > tc.maxLength) {
+			t.Errorf("TruncateText(%q, %d) = %q exceeds limit or is invalid UTF-8", tc.text, tc.maxLength, got)
+		}
+	}
+}